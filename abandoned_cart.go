@@ -0,0 +1,111 @@
+package shop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+
+	"gofalre.io/shop/models"
+	"gofalre.io/shop/models/enum"
+)
+
+const (
+	// defaultCartAbandonThreshold 為預設的購物車閒置門檻，超過此時間沒有異動即視為閒置
+	defaultCartAbandonThreshold = 24 * time.Hour
+	// abandonedCartBatchSize 為每次偵測處理的購物車上限
+	abandonedCartBatchSize = 100
+)
+
+// errCartAlreadyNotified 表示購物車在列出後已有異動或已被其他排程通知
+var errCartAlreadyNotified = errors.New("cart abandonment already notified")
+
+// WithCartAbandonThreshold 設定購物車閒置多久沒有異動後視為閒置並通知行銷服務
+func WithCartAbandonThreshold(d time.Duration) Option {
+	return func(s *service) {
+		if d > 0 {
+			s.abandonThreshold = d
+		}
+	}
+}
+
+// DetectAbandonedCarts 找出超過閒置門檻沒有異動的 active 購物車並發佈 SubjectCartAbandoned 事件，
+// 每個購物車在再次異動前只通知一次，購物車維持 active 且不釋放庫存；應定期執行，回傳通知的數量
+func (s *service) DetectAbandonedCarts(ctx context.Context) (int, error) {
+	now := time.Now()
+	since := now.Add(-s.abandonThreshold)
+
+	cartIDs, err := s.cart.ListInactiveCartIDs(ctx, nil, since, abandonedCartBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list inactive carts: %w", err)
+	}
+
+	notified := 0
+	for _, cartID := range cartIDs {
+		if err = s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+			return s.notifyInactiveCart(ctx, tx, cartID, since, now)
+		}); err != nil {
+			if !errors.Is(err, errCartAlreadyNotified) {
+				s.log(ctx).Error("Failed to notify abandoned cart", zap.Uint64("cart_id", cartID), zap.Error(err))
+			}
+			continue
+		}
+		notified++
+	}
+
+	return notified, nil
+}
+
+// notifyInactiveCart 先標記購物車已通知再發佈事件，發佈失敗時回傳錯誤讓標記與產生的挽回優惠券回復，下次執行時重試
+func (s *service) notifyInactiveCart(ctx context.Context, tx pgx.Tx, cartID uint64, since, now time.Time) error {
+	// 1. 標記已通知，避免同時執行時重複發佈
+	ok, err := s.cart.MarkCartAbandonedNotified(ctx, tx, cartID, since, now)
+	if err != nil {
+		return fmt.Errorf("failed to mark cart abandoned notified: %w", err)
+	}
+	if !ok {
+		return errCartAlreadyNotified
+	}
+
+	// 2. 獲取購物車與項目
+	cartModel, err := s.cart.GetCart(ctx, tx, cartID)
+	if err != nil {
+		return fmt.Errorf("failed to get cart: %w", err)
+	}
+	items, err := s.cart.ListCartItems(ctx, tx, cartID)
+	if err != nil {
+		return fmt.Errorf("failed to list cart items: %w", err)
+	}
+
+	// 3. 依設定產生挽回優惠券
+	recoveryCoupon, err := s.issueRecoveryCoupon(ctx, tx, cartModel, now)
+	if err != nil {
+		return err
+	}
+
+	// 4. 發佈閒置事件
+	event := newInactiveCartEvent(cartModel, items, now)
+	event.RecoveryCoupon = recoveryCoupon
+	if err = s.eventManager.Publish(ctx, SubjectCartAbandoned, event); err != nil {
+		return fmt.Errorf("failed to publish abandoned cart event: %w", err)
+	}
+
+	return nil
+}
+
+func newInactiveCartEvent(cartModel *models.Cart, items []*models.CartItem, now time.Time) *CartEvent {
+	lastActivityAt := cartModel.UpdatedAt
+	return &CartEvent{
+		CartID:         cartModel.ID,
+		CustomerID:     cartModel.CustomerID,
+		Status:         enum.CartStatusActive,
+		Items:          items,
+		Total:          cartModel.Total,
+		Currency:       cartModel.Currency,
+		LastActivityAt: &lastActivityAt,
+		OccurredAt:     now,
+	}
+}
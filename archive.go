@@ -0,0 +1,75 @@
+package shop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// orderArchiveBatchSize 每個交易搬移的訂單數，避免單一交易鎖定過多資料列
+const orderArchiveBatchSize = 500
+
+// ArchiveOrders 將最後更新超過 olderThanMonths 個月的已完成或已取消訂單搬移到封存表，回傳封存的訂單數；
+// 封存後 GetOrder 仍可讀取，但訂單不再能被修改
+func (s *service) ArchiveOrders(ctx context.Context, olderThanMonths int) (uint64, error) {
+	if olderThanMonths <= 0 {
+		return 0, errors.New("archive age must be at least one month")
+	}
+
+	cutoff := time.Now().AddDate(0, -olderThanMonths, 0)
+	var total uint64
+
+	for {
+		var archived int64
+		if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+			var err error
+			archived, err = s.order.ArchiveOrders(ctx, tx, cutoff, orderArchiveBatchSize)
+			return err
+		}); err != nil {
+			return total, fmt.Errorf("failed to archive orders: %w", err)
+		}
+
+		total += uint64(archived)
+		if archived < orderArchiveBatchSize {
+			break
+		}
+	}
+
+	s.log(ctx).Info("Archived orders", zap.Time("cutoff", cutoff), zap.Uint64("count", total))
+
+	return total, nil
+}
+
+// PurgeDeletedOrders 永久刪除軟刪除超過 retention 的訂單及其項目，回傳刪除的訂單數；應依資料保留政策定期執行
+func (s *service) PurgeDeletedOrders(ctx context.Context, retention time.Duration) (uint64, error) {
+	if retention <= 0 {
+		return 0, errors.New("deleted order retention must be positive")
+	}
+
+	cutoff := time.Now().Add(-retention)
+	var total uint64
+
+	for {
+		var purged int64
+		if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+			var err error
+			purged, err = s.order.PurgeDeletedOrders(ctx, tx, cutoff, orderArchiveBatchSize)
+			return err
+		}); err != nil {
+			return total, fmt.Errorf("failed to purge deleted orders: %w", err)
+		}
+
+		total += uint64(purged)
+		if purged < orderArchiveBatchSize {
+			break
+		}
+	}
+
+	s.log(ctx).Info("Purged deleted orders", zap.Time("cutoff", cutoff), zap.Uint64("count", total))
+
+	return total, nil
+}
@@ -0,0 +1,71 @@
+package shop
+
+import (
+	"context"
+	"errors"
+)
+
+// Action 表示需要授權檢查的操作
+type Action string
+
+const (
+	ActionClearCart        Action = "cart.clear"
+	ActionAbandonCart      Action = "cart.abandon"
+	ActionExtendCartExpiry Action = "cart.extend_expiry"
+	ActionDeleteOrder      Action = "order.delete"
+	ActionRestoreOrder     Action = "order.restore"
+	ActionReorderOrder     Action = "order.reorder"
+
+	ActionCancelOrdersForRecall Action = "order.cancel_for_recall"
+
+	ActionUpdateNotificationPreferences Action = "notification_preferences.update"
+
+	ActionRequestOrderCancellation Action = "order.request_cancellation"
+	ActionReviewOrderCancellation  Action = "order_cancellation.review"
+	ActionReviewOrderRepricing     Action = "order_repricing.review"
+
+	ActionApproveStockAdjustment Action = "stock_adjustment.approve"
+	ActionRejectStockAdjustment  Action = "stock_adjustment.reject"
+	ActionApproveStockTransfer   Action = "stock_transfer.approve"
+	ActionRejectStockTransfer    Action = "stock_transfer.reject"
+	ActionCreateManualMovement   Action = "stock_movement.create_manual"
+	ActionResolveFraudReview     Action = "fraud_review.resolve"
+)
+
+// ErrForbidden 表示 Authorizer 拒絕了操作
+var ErrForbidden = errors.New("operation not permitted")
+
+// AuthorizationRequest 描述一次授權檢查，CustomerID 為資源擁有者，Actor 為呼叫端明確指定的操作人員（如審核者）
+type AuthorizationRequest struct {
+	Action     Action
+	ResourceID uint64
+	CustomerID string
+	Actor      string
+}
+
+// Authorizer 在執行操作前被呼叫，回傳錯誤即拒絕操作；呼叫者身分由 ctx 提供
+type Authorizer func(ctx context.Context, req AuthorizationRequest) error
+
+// allowAll 為預設的 Authorizer，不做任何限制
+func allowAll(context.Context, AuthorizationRequest) error {
+	return nil
+}
+
+// WithAuthorizer 設定破壞性操作（清空購物車、刪除訂單等）的授權檢查
+func WithAuthorizer(authorizer Authorizer) Option {
+	return func(s *service) {
+		if authorizer != nil {
+			s.authorize = authorizer
+		}
+	}
+}
+
+func (s *service) checkAuthorization(ctx context.Context, req AuthorizationRequest) error {
+	if err := s.authorize(ctx, req); err != nil {
+		if errors.Is(err, ErrForbidden) {
+			return err
+		}
+		return errors.Join(ErrForbidden, err)
+	}
+	return nil
+}
@@ -0,0 +1,275 @@
+package shop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"gofalre.io/shop/campaign"
+	"gofalre.io/shop/models"
+	"gofalre.io/shop/models/enum"
+)
+
+// cartPricing 為套用優惠與稅額後的購物車金額，Promotions 為依疊加規則實際套用的優惠
+type cartPricing struct {
+	Subtotal    float64
+	Tax         float64
+	Discount    float64
+	Redemptions []campaign.CreateCampaignRedemptionParams
+	Promotions  []*models.AppliedPromotion
+	// Coupon 為實際套用的優惠券，沒有套用時為 nil；CouponDiscount 為優惠券折抵的金額
+	Coupon         *models.Coupon
+	CouponDiscount float64
+	// LineDiscounts 與傳入的項目順序相同
+	LineDiscounts []float64
+	// Exemption 為客戶在寄送地適用的免稅證明，沒有時為 nil
+	Exemption *models.TaxExemptionCertificate
+}
+
+// CreateDiscountCampaign 建立分類折扣活動，includeSubcategories 為 true 時整個分類子樹的商品都適用
+func (s *service) CreateDiscountCampaign(ctx context.Context, name string, categoryID uint64, includeSubcategories bool, percentOff float64, startsAt, endsAt time.Time) (*models.DiscountCampaign, error) {
+	if name == "" {
+		return nil, errors.New("campaign name is required")
+	}
+	if percentOff <= 0 || percentOff > 100 {
+		return nil, errors.New("campaign discount must be between 0 and 100 percent")
+	}
+	if !endsAt.After(startsAt) {
+		return nil, errors.New("campaign must end after it starts")
+	}
+
+	var discountCampaign *models.DiscountCampaign
+
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		// 1. 確認分類存在
+		if _, err := s.category.GetByID(ctx, tx, categoryID); err != nil {
+			return fmt.Errorf("failed to get category: %w", err)
+		}
+
+		// 2. 建立活動
+		var err error
+		discountCampaign, err = s.campaign.CreateDiscountCampaign(ctx, tx, campaign.CreateDiscountCampaignParams{
+			Name:                 name,
+			CategoryID:           categoryID,
+			IncludeSubcategories: includeSubcategories,
+			PercentOff:           percentOff,
+			StartsAt:             startsAt,
+			EndsAt:               endsAt,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create discount campaign: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return discountCampaign, nil
+}
+
+// ListDiscountCampaigns 列出折扣活動，依開始時間由新到舊排序
+func (s *service) ListDiscountCampaigns(ctx context.Context, limit, offset uint64) ([]*models.DiscountCampaign, error) {
+	return s.campaign.ListDiscountCampaigns(ctx, nil, limit, offset)
+}
+
+// GetCampaignReport 回傳活動的成效報表（訂單數、售出件數、營收與折扣金額）
+func (s *service) GetCampaignReport(ctx context.Context, campaignID uint64) (*models.CampaignReport, error) {
+	discountCampaign, err := s.campaign.GetDiscountCampaign(ctx, nil, campaignID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get discount campaign: %w", err)
+	}
+
+	report, err := s.campaign.GetCampaignReport(ctx, nil, campaignID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get campaign report: %w", err)
+	}
+	report.Campaign = discountCampaign
+	report.Currency = s.reportingCurrency
+
+	return report, nil
+}
+
+// priceCartItems 計算購物車項目在指定時間依疊加規則套用優惠後的小計、折扣與稅額，並將每個項目依尾數規則調整後的單價、
+// 小計與稅率、稅額寫回 items
+func (s *service) priceCartItems(ctx context.Context, tx pgx.Tx, cartModel *models.Cart, items []*models.CartItem, at time.Time) (*cartPricing, error) {
+	pricing := new(cartPricing)
+	if len(items) == 0 {
+		return pricing, nil
+	}
+
+	// 1. 依尾數規則調整單價，收集適用的分類折扣活動與其他優惠
+	s.roundUnitPrices(ctx, cartModel.Currency, items)
+	productIDs := make([]string, 0, len(items))
+	for _, item := range items {
+		productIDs = append(productIDs, item.ProductID)
+	}
+
+	campaigns, err := s.campaign.ListActiveCampaignsForProducts(ctx, tx, productIDs, at)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list active campaigns: %w", err)
+	}
+
+	promotions, err := s.listPromotions(ctx, cartModel, items, campaigns, at)
+	if err != nil {
+		return nil, err
+	}
+	couponModel, couponPromotion, err := s.cartCouponPromotion(ctx, tx, cartModel, items, at)
+	if err != nil {
+		return nil, err
+	}
+	if couponPromotion != nil {
+		promotions = append(promotions, couponPromotion)
+	}
+
+	// 2. 依疊加規則決定套用的優惠，並依尾數規則調整折扣後的單價
+	resolution := s.promotionPolicy.resolve(items, promotions, cartModel.Currency)
+	s.roundDiscountedPrices(ctx, cartModel.Currency, items, resolution)
+	pricing.Promotions = resolution.Applied
+
+	// 3. 活動報表只記錄實際套用的分類折扣活動，優惠券在結帳時記錄兌換
+	for j, applied := range resolution.Applied {
+		if couponPromotion != nil && applied.Kind == enum.PromotionKindCoupon && applied.Reference == couponPromotion.Reference {
+			pricing.Coupon, pricing.CouponDiscount = couponModel, applied.Discount
+			continue
+		}
+		if applied.Kind != enum.PromotionKindCampaign {
+			continue
+		}
+		campaignID, err := strconv.ParseUint(applied.Reference, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid campaign reference %q: %w", applied.Reference, err)
+		}
+		for _, item := range items {
+			if discount, ok := resolution.Lines[j][item.ID]; ok {
+				pricing.Redemptions = append(pricing.Redemptions, campaign.CreateCampaignRedemptionParams{
+					CampaignID: campaignID,
+					ProductID:  item.ProductID,
+					Quantity:   item.Quantity,
+					Subtotal:   item.Subtotal,
+					Discount:   discount,
+				})
+			}
+		}
+	}
+
+	// 4. 計算各項目的折扣與稅額，客戶有適用的免稅證明時涵蓋的項目以零稅率計算
+	pricing.Exemption, err = s.taxExemption(ctx, tx, cartModel.CustomerID, cartModel.ShippingAddress, cartModel.BillingAddress)
+	if err != nil {
+		return nil, err
+	}
+	pricing.LineDiscounts = make([]float64, len(items))
+	for i, item := range items {
+		pricing.Subtotal += item.Subtotal
+
+		discount := resolution.LineDiscounts[item.ID]
+		pricing.Discount += discount
+		pricing.LineDiscounts[i] = discount
+
+		// 稅額以折扣後的金額與商品目錄的稅別計算，與下單時的項目快照一致
+		taxClass := s.productTaxClass(ctx, item.ProductID, item.PriceID)
+		item.TaxRate, item.TaxAmount, err = s.lineTax(ctx, pricing.Exemption, item.ProductID, taxClass, item.Subtotal-discount, cartModel.Currency)
+		if err != nil {
+			return nil, err
+		}
+		pricing.Tax += item.TaxAmount
+	}
+
+	pricing.Subtotal = roundCurrencyAmount(pricing.Subtotal, cartModel.Currency)
+	pricing.Tax = roundCurrencyAmount(pricing.Tax, cartModel.Currency)
+	pricing.Discount = roundCurrencyAmount(pricing.Discount, cartModel.Currency)
+
+	return pricing, nil
+}
+
+// RecalculateCart 依目前的項目、優惠與稅率重新計算購物車金額並寫回，回傳重新計算後的購物車；
+// 購物車異動時會自動重新計算，此方法供價格或活動變動後手動刷新金額
+func (s *service) RecalculateCart(ctx context.Context, cartID uint64) (*models.Cart, error) {
+	var cartModel *models.Cart
+
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		// 1. 檢查購物車狀態
+		current, err := s.cart.GetCart(ctx, tx, cartID)
+		if err != nil {
+			return fmt.Errorf("failed to get cart: %w", err)
+		}
+		if current.Status != enum.CartStatusActive {
+			return fmt.Errorf("%w: cart %d is %s", ErrCartNotActive, cartID, current.Status)
+		}
+
+		// 2. 重新計算並寫回金額
+		if err = s.recalculateCartTotals(ctx, tx, cartID); err != nil {
+			return err
+		}
+
+		cartModel, err = s.cart.GetCart(ctx, tx, cartID)
+		if err != nil {
+			return fmt.Errorf("failed to get cart: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return cartModel, nil
+}
+
+// recalculateCartTotals 在購物車項目變動後重新計算小計、優惠折扣與稅額，並將金額與套用的優惠寫回購物車及各項目
+func (s *service) recalculateCartTotals(ctx context.Context, tx pgx.Tx, cartID uint64) error {
+	cartModel, err := s.cart.GetCart(ctx, tx, cartID)
+	if err != nil {
+		return fmt.Errorf("failed to get cart: %w", err)
+	}
+
+	items, err := s.cart.ListCartItems(ctx, tx, cartID)
+	if err != nil {
+		return fmt.Errorf("failed to list cart items: %w", err)
+	}
+
+	pricing, err := s.priceCartItems(ctx, tx, cartModel, items, time.Now())
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		if err = s.cart.UpdateCartItemTax(ctx, tx, item); err != nil {
+			return fmt.Errorf("failed to update cart item tax: %w", err)
+		}
+	}
+
+	if err = s.cart.UpdateCartTotals(ctx, tx, cartID, pricing.Subtotal, pricing.Tax, pricing.Discount); err != nil {
+		return fmt.Errorf("failed to update cart totals: %w", err)
+	}
+
+	if err = s.cart.SetCartPromotions(ctx, tx, cartID, pricing.Promotions); err != nil {
+		return fmt.Errorf("failed to set cart promotions: %w", err)
+	}
+
+	return nil
+}
+
+// recordCampaignRedemptions 記錄訂單套用的活動折扣，供活動報表使用
+func (s *service) recordCampaignRedemptions(ctx context.Context, tx pgx.Tx, orderID uint64, pricing *cartPricing) error {
+	if len(pricing.Redemptions) == 0 {
+		return nil
+	}
+
+	for i := range pricing.Redemptions {
+		pricing.Redemptions[i].OrderID = orderID
+	}
+
+	if err := s.campaign.CreateCampaignRedemptions(ctx, tx, pricing.Redemptions); err != nil {
+		return fmt.Errorf("failed to create campaign redemptions: %w", err)
+	}
+
+	return nil
+}
+
+func roundCurrency(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,162 @@
+package campaign
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+	"gofalre.io/shop/driver"
+	"gofalre.io/shop/models"
+	"gofalre.io/shop/sqlc"
+	"time"
+)
+
+type Repository interface {
+	CreateDiscountCampaign(ctx context.Context, tx pgx.Tx, params CreateDiscountCampaignParams) (*models.DiscountCampaign, error)
+	GetDiscountCampaign(ctx context.Context, tx pgx.Tx, campaignID uint64) (*models.DiscountCampaign, error)
+	ListDiscountCampaigns(ctx context.Context, tx pgx.Tx, limit, offset uint64) ([]*models.DiscountCampaign, error)
+	ListActiveCampaignsForProducts(ctx context.Context, tx pgx.Tx, productIDs []string, at time.Time) (map[string]*models.ProductCampaign, error)
+	CreateCampaignRedemptions(ctx context.Context, tx pgx.Tx, params []CreateCampaignRedemptionParams) error
+	GetCampaignReport(ctx context.Context, tx pgx.Tx, campaignID uint64) (*models.CampaignReport, error)
+}
+
+type repository struct {
+	queries *driver.Queries
+	logger  *zap.Logger
+}
+
+func NewRepository(conn driver.PostgresPool, logger *zap.Logger) Repository {
+	return NewRepositoryWithQuerier(sqlc.New(conn), logger)
+}
+
+// NewRepositoryWithQuerier 以注入的 sqlc.Querier 建立 repository，交易中的查詢見 driver.TxBinder
+func NewRepositoryWithQuerier(querier sqlc.Querier, logger *zap.Logger) Repository {
+	return &repository{
+		queries: driver.NewQueries(querier),
+		logger:  logger,
+	}
+}
+
+var _ Repository = (*repository)(nil)
+
+func (r *repository) CreateDiscountCampaign(ctx context.Context, tx pgx.Tx, params CreateDiscountCampaignParams) (*models.DiscountCampaign, error) {
+	sqlcCampaign, err := r.queries.WithTx(tx).CreateDiscountCampaign(ctx, sqlc.CreateDiscountCampaignParams{
+		Name:                 params.Name,
+		CategoryID:           int32(params.CategoryID),
+		IncludeSubcategories: params.IncludeSubcategories,
+		PercentOff:           params.PercentOff,
+		StartsAt:             pgtype.Timestamptz{Time: params.StartsAt, Valid: true},
+		EndsAt:               pgtype.Timestamptz{Time: params.EndsAt, Valid: true},
+	})
+	if err != nil {
+		r.logger.Error("failed to create discount campaign", zap.Uint64("category_id", params.CategoryID), zap.Error(err))
+		return nil, err
+	}
+
+	return new(models.DiscountCampaign).ConvertSqlcDiscountCampaign(sqlcCampaign), nil
+}
+
+func (r *repository) GetDiscountCampaign(ctx context.Context, tx pgx.Tx, campaignID uint64) (*models.DiscountCampaign, error) {
+	sqlcCampaign, err := r.queries.WithTx(tx).GetDiscountCampaign(ctx, int32(campaignID))
+	if err != nil {
+		r.logger.Error("failed to get discount campaign", zap.Uint64("campaign_id", campaignID), zap.Error(err))
+		return nil, err
+	}
+
+	return new(models.DiscountCampaign).ConvertSqlcDiscountCampaign(sqlcCampaign), nil
+}
+
+func (r *repository) ListDiscountCampaigns(ctx context.Context, tx pgx.Tx, limit, offset uint64) ([]*models.DiscountCampaign, error) {
+	sqlcCampaigns, err := r.queries.WithTx(tx).ListDiscountCampaigns(ctx, sqlc.ListDiscountCampaignsParams{
+		Limit:  int64(limit),
+		Offset: int64(offset),
+	})
+	if err != nil {
+		r.logger.Error("failed to list discount campaigns", zap.Error(err))
+		return nil, err
+	}
+
+	campaigns := make([]*models.DiscountCampaign, 0, len(sqlcCampaigns))
+	for _, sqlcCampaign := range sqlcCampaigns {
+		campaigns = append(campaigns, new(models.DiscountCampaign).ConvertSqlcDiscountCampaign(sqlcCampaign))
+	}
+
+	return campaigns, nil
+}
+
+// ListActiveCampaignsForProducts 回傳各商品在指定時間適用的活動，商品有多個活動時取折扣最高者，沒有活動的商品不會出現在結果中
+func (r *repository) ListActiveCampaignsForProducts(ctx context.Context, tx pgx.Tx, productIDs []string, at time.Time) (map[string]*models.ProductCampaign, error) {
+	campaigns := make(map[string]*models.ProductCampaign, len(productIDs))
+	if len(productIDs) == 0 {
+		return campaigns, nil
+	}
+
+	rows, err := r.queries.WithTx(tx).ListActiveCampaignsForProducts(ctx, sqlc.ListActiveCampaignsForProductsParams{
+		At:         pgtype.Timestamptz{Time: at, Valid: true},
+		ProductIds: productIDs,
+	})
+	if err != nil {
+		r.logger.Error("failed to list active campaigns for products", zap.Strings("product_ids", productIDs), zap.Error(err))
+		return nil, err
+	}
+
+	// 查詢結果已依折扣由高到低排序，每個商品只保留第一筆
+	for _, row := range rows {
+		if _, ok := campaigns[row.ProductID]; ok {
+			continue
+		}
+		campaigns[row.ProductID] = &models.ProductCampaign{
+			ProductID:  row.ProductID,
+			CampaignID: uint64(row.CampaignID),
+			PercentOff: row.PercentOff,
+		}
+	}
+
+	return campaigns, nil
+}
+
+func (r *repository) CreateCampaignRedemptions(ctx context.Context, tx pgx.Tx, params []CreateCampaignRedemptionParams) error {
+	var batchError error
+	batch := make([]sqlc.CreateCampaignRedemptionsParams, 0, len(params))
+	for _, param := range params {
+		batch = append(batch, sqlc.CreateCampaignRedemptionsParams{
+			CampaignID: int32(param.CampaignID),
+			OrderID:    int32(param.OrderID),
+			ProductID:  param.ProductID,
+			Quantity:   param.Quantity,
+			Subtotal:   param.Subtotal,
+			Discount:   param.Discount,
+		})
+	}
+	batchResults := r.queries.WithTx(tx).CreateCampaignRedemptions(ctx, batch)
+	defer func(batchResults *sqlc.CreateCampaignRedemptionsBatchResults) {
+		if err := batchResults.Close(); err != nil {
+			r.logger.Error("failed to close batch", zap.Error(err))
+		}
+	}(batchResults)
+
+	batchResults.Exec(func(index int, err error) {
+		if err != nil {
+			r.logger.Error("failed to create campaign redemption", zap.Uint64("campaign_id", params[index].CampaignID), zap.Error(err))
+			batchError = err
+		}
+	})
+
+	return batchError
+}
+
+func (r *repository) GetCampaignReport(ctx context.Context, tx pgx.Tx, campaignID uint64) (*models.CampaignReport, error) {
+	row, err := r.queries.WithTx(tx).GetCampaignReport(ctx, int32(campaignID))
+	if err != nil {
+		r.logger.Error("failed to get campaign report", zap.Uint64("campaign_id", campaignID), zap.Error(err))
+		return nil, err
+	}
+
+	return &models.CampaignReport{
+		Orders:       uint64(row.Orders),
+		Units:        uint64(row.Units),
+		GrossRevenue: row.GrossRevenue,
+		Discount:     row.DiscountTotal,
+		NetRevenue:   row.GrossRevenue - row.DiscountTotal,
+	}, nil
+}
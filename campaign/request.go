@@ -0,0 +1,21 @@
+package campaign
+
+import "time"
+
+type CreateDiscountCampaignParams struct {
+	Name                 string
+	CategoryID           uint64
+	IncludeSubcategories bool
+	PercentOff           float64
+	StartsAt             time.Time
+	EndsAt               time.Time
+}
+
+type CreateCampaignRedemptionParams struct {
+	CampaignID uint64
+	OrderID    uint64
+	ProductID  string
+	Quantity   uint64
+	Subtotal   float64
+	Discount   float64
+}
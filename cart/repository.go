@@ -2,8 +2,11 @@ package cart
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
 	"go.uber.org/zap"
 	"gofalre.io/shop/driver"
@@ -16,51 +19,106 @@ import (
 
 var _ Repository = (*repository)(nil)
 
+// ErrCartNameTaken 表示客戶已有同名的 active 購物車
+var ErrCartNameTaken = errors.New("cart name is already taken")
+
 type Repository interface {
 	CreateCart(ctx context.Context, tx pgx.Tx, cart *models.Cart) error
 	GetCart(ctx context.Context, tx pgx.Tx, id uint64) (*models.Cart, error)
 	GetActiveCartByCustomerID(ctx context.Context, tx pgx.Tx, customerID string) (*models.Cart, error)
+	GetActiveCartByName(ctx context.Context, tx pgx.Tx, customerID, name string) (*models.Cart, error)
+	ListActiveCarts(ctx context.Context, tx pgx.Tx, customerID string) ([]*models.Cart, error)
+	SetDefaultCart(ctx context.Context, tx pgx.Tx, customerID string, cartID uint64) (bool, error)
 	GetCartItemByProductID(ctx context.Context, tx pgx.Tx, cartID uint64, productID string) (*models.CartItem, error)
 	AddCartItem(ctx context.Context, tx pgx.Tx, cartID uint64, item *models.CartItem) error
 	RemoveCartItem(ctx context.Context, tx pgx.Tx, cartItemID uint64) error
 	ListCartItems(ctx context.Context, tx pgx.Tx, cartID uint64) ([]*models.CartItem, error)
 	ClearCartItems(ctx context.Context, tx pgx.Tx, cartID uint64) error
 	UpdateCartStatus(ctx context.Context, tx pgx.Tx, id uint64, status enum.CartStatus) error
+	UpdateCartTotals(ctx context.Context, tx pgx.Tx, id uint64, subtotal, tax, discount float64) error
+	SetCartPromotions(ctx context.Context, tx pgx.Tx, id uint64, promotions []*models.AppliedPromotion) error
+	ExtendCartExpiry(ctx context.Context, tx pgx.Tx, id uint64, expiresAt time.Time) (bool, error)
+	ReactivateCart(ctx context.Context, tx pgx.Tx, id uint64, expiresAt time.Time) (bool, error)
+	SetCartAddresses(ctx context.Context, tx pgx.Tx, id uint64, addresses *models.CartAddresses) (bool, error)
+	ClearCartAddresses(ctx context.Context, tx pgx.Tx, id uint64) (bool, error)
+	SetCartChannel(ctx context.Context, tx pgx.Tx, id uint64, channel string) (bool, error)
+	GetCartAddresses(ctx context.Context, tx pgx.Tx, id uint64) (*models.CartAddresses, error)
 	GetCartItem(ctx context.Context, tx pgx.Tx, id uint64) (*models.CartItem, error)
 	UpdateCartItem(ctx context.Context, tx pgx.Tx, cartItem *models.CartItem) error
+	UpdateCartItemTax(ctx context.Context, tx pgx.Tx, cartItem *models.CartItem) error
+	UpdateCartItemMetadata(ctx context.Context, tx pgx.Tx, cartItem *models.CartItem) (bool, error)
+
+	ListOrphanedCartItems(ctx context.Context, tx pgx.Tx) ([]*models.OrphanedItem, error)
+	ListConvertedCartsWithoutOrder(ctx context.Context, tx pgx.Tx) ([]*models.Cart, error)
+	ListCartTotalMismatches(ctx context.Context, tx pgx.Tx) ([]*models.TotalMismatch, error)
+	ListExpiredActiveCartIDs(ctx context.Context, tx pgx.Tx, now time.Time, limit int32) ([]uint64, error)
+	ListInactiveCartIDs(ctx context.Context, tx pgx.Tx, since time.Time, limit int32) ([]uint64, error)
+	MarkCartAbandonedNotified(ctx context.Context, tx pgx.Tx, id uint64, since, notifiedAt time.Time) (bool, error)
+
+	GetNotificationPreferences(ctx context.Context, tx pgx.Tx, customerID string) (*models.NotificationPreferences, error)
+	SaveNotificationPreferences(ctx context.Context, tx pgx.Tx, preferences *models.NotificationPreferences) error
+
+	AssignCartExperiment(ctx context.Context, tx pgx.Tx, cartID uint64, experiment, variant string) (bool, error)
+	ListCartExperimentAssignments(ctx context.Context, tx pgx.Tx, cartID uint64) ([]*models.ExperimentAssignment, error)
+	ListOrderExperimentAssignments(ctx context.Context, tx pgx.Tx, orderID uint64) ([]*models.ExperimentAssignment, error)
+	AttachExperimentAssignmentsToOrder(ctx context.Context, tx pgx.Tx, cartID, orderID uint64) error
 }
 
 type repository struct {
-	conn   driver.PostgresPool
-	cache  *ember.Ember
-	logger *zap.Logger
+	queries *driver.Queries
+	cache   *ember.Ember
+	shadow  *driver.CacheShadow
+	logger  *zap.Logger
+}
+
+func NewRepository(conn driver.PostgresPool, cache *ember.Ember, logger *zap.Logger, opts ...driver.RepositoryOption) Repository {
+	return NewRepositoryWithQuerier(sqlc.New(conn), cache, logger, opts...)
 }
 
-func NewRepository(conn driver.PostgresPool, cache *ember.Ember, logger *zap.Logger) Repository {
+// NewRepositoryWithQuerier 以注入的 sqlc.Querier 建立 repository，交易中的查詢見 driver.TxBinder
+func NewRepositoryWithQuerier(querier sqlc.Querier, cache *ember.Ember, logger *zap.Logger, opts ...driver.RepositoryOption) Repository {
 	return &repository{
-		conn:   conn,
-		cache:  cache,
-		logger: logger,
+		queries: driver.NewQueries(querier),
+		cache:   cache,
+		shadow:  driver.NewRepositoryOptions(opts...).CacheShadow,
+		logger:  logger,
 	}
 }
 
+// CreateCart 建立購物車，並將產生的 ID 與時間戳寫回 cart；客戶已有同名的 active 購物車時回傳 ErrCartNameTaken
 func (r *repository) CreateCart(ctx context.Context, tx pgx.Tx, cart *models.Cart) error {
-	err := sqlc.New(r.conn).WithTx(tx).CreateCart(ctx, sqlc.CreateCartParams{
+	created, err := r.queries.WithTx(tx).CreateCart(ctx, sqlc.CreateCartParams{
 		CustomerID: cart.CustomerID,
 		Status:     sqlc.CartStatus(cart.Status),
 		Currency:   sqlc.Currency(cart.Currency),
 		ExpiresAt:  pgtype.Timestamptz{Time: cart.ExpiresAt, Valid: true},
+		Name:       cart.Name,
+		IsDefault:  cart.IsDefault,
 	})
 	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "idx_carts_active_customer_name" {
+			return ErrCartNameTaken
+		}
 		r.logger.Error("Failed to create cart", zap.Error(err))
 		return err
 	}
 
+	cart.ID = uint64(created.ID)
+	cart.CreatedAt = created.CreatedAt.Time
+	cart.UpdatedAt = created.UpdatedAt.Time
+
 	// 更新快取
 	cacheKey := fmt.Sprintf("cart:%d", cart.ID)
 	if err := r.cache.Set(ctx, cacheKey, cart, 30*time.Minute); err != nil {
 		r.logger.Warn("Failed to cache cart", zap.Error(err))
 	}
+	if cart.Status == enum.CartStatusActive && cart.IsDefault {
+		activeCacheKey := fmt.Sprintf("active_cart:%s", cart.CustomerID)
+		if err := r.cache.Set(ctx, activeCacheKey, cart, 30*time.Minute); err != nil {
+			r.logger.Warn("Failed to cache active cart", zap.Error(err))
+		}
+	}
 
 	return nil
 }
@@ -75,10 +133,17 @@ func (r *repository) GetCart(ctx context.Context, tx pgx.Tx, id uint64) (*models
 		r.logger.Warn("Failed to get cart from cache", zap.Error(err))
 	}
 	if found {
+		r.shadow.Verify(ctx, cacheKey, &cart, func(ctx context.Context) (any, error) {
+			sqlcCart, err := r.queries.Querier().GetCart(ctx, int32(id))
+			if err != nil {
+				return nil, err
+			}
+			return new(models.Cart).ConvertSqlcCart(sqlcCart), nil
+		})
 		return &cart, nil
 	}
 
-	sqlcCart, err := sqlc.New(r.conn).WithTx(tx).GetCart(ctx, int32(id))
+	sqlcCart, err := r.queries.WithTx(tx).GetCart(ctx, int32(id))
 	if err != nil {
 		r.logger.Error("Failed to get cart", zap.Error(err))
 		return nil, err
@@ -94,6 +159,7 @@ func (r *repository) GetCart(ctx context.Context, tx pgx.Tx, id uint64) (*models
 	return &cart, nil
 }
 
+// GetActiveCartByCustomerID 取得客戶的預設 active 購物車，沒有時回傳 pgx.ErrNoRows
 func (r *repository) GetActiveCartByCustomerID(ctx context.Context, tx pgx.Tx, customerID string) (*models.Cart, error) {
 	cacheKey := fmt.Sprintf("active_cart:%s", customerID)
 	var cart models.Cart
@@ -104,10 +170,17 @@ func (r *repository) GetActiveCartByCustomerID(ctx context.Context, tx pgx.Tx, c
 		r.logger.Warn("Failed to get active cart from cache", zap.Error(err))
 	}
 	if found {
+		r.shadow.Verify(ctx, cacheKey, &cart, func(ctx context.Context) (any, error) {
+			sqlcCart, err := r.queries.Querier().FindActiveCartByCustomerID(ctx, customerID)
+			if err != nil {
+				return nil, err
+			}
+			return new(models.Cart).ConvertSqlcCart(sqlcCart), nil
+		})
 		return &cart, nil
 	}
 
-	sqlcCart, err := sqlc.New(r.conn).WithTx(tx).FindActiveCartByCustomerID(ctx, customerID)
+	sqlcCart, err := r.queries.WithTx(tx).FindActiveCartByCustomerID(ctx, customerID)
 	if err != nil {
 		r.logger.Error("Failed to get active cart", zap.Error(err))
 		return nil, err
@@ -123,8 +196,70 @@ func (r *repository) GetActiveCartByCustomerID(ctx context.Context, tx pgx.Tx, c
 	return &cart, nil
 }
 
+// GetActiveCartByName 取得客戶指定名稱的 active 購物車，沒有時回傳 pgx.ErrNoRows
+func (r *repository) GetActiveCartByName(ctx context.Context, tx pgx.Tx, customerID, name string) (*models.Cart, error) {
+	sqlcCart, err := r.queries.WithTx(tx).FindActiveCartByName(ctx, sqlc.FindActiveCartByNameParams{
+		CustomerID: customerID,
+		Name:       name,
+	})
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			r.logger.Error("Failed to get active cart by name", zap.String("customer_id", customerID), zap.String("name", name), zap.Error(err))
+		}
+		return nil, err
+	}
+
+	return new(models.Cart).ConvertSqlcCart(sqlcCart), nil
+}
+
+// ListActiveCarts 列出客戶所有 active 購物車，預設購物車在最前面，其餘依名稱排序
+func (r *repository) ListActiveCarts(ctx context.Context, tx pgx.Tx, customerID string) ([]*models.Cart, error) {
+	rows, err := r.queries.WithTx(tx).ListActiveCartsByCustomerID(ctx, customerID)
+	if err != nil {
+		r.logger.Error("Failed to list active carts", zap.String("customer_id", customerID), zap.Error(err))
+		return nil, err
+	}
+
+	carts := make([]*models.Cart, 0, len(rows))
+	for _, row := range rows {
+		carts = append(carts, new(models.Cart).ConvertSqlcCart(row))
+	}
+
+	return carts, nil
+}
+
+// SetDefaultCart 將客戶的 active 購物車設為預設購物車，原本的預設購物車改為一般購物車；
+// 回傳 false 表示購物車不存在、不屬於該客戶或已非 active，此時原本的預設購物車已被清除，呼叫端應回復交易
+func (r *repository) SetDefaultCart(ctx context.Context, tx pgx.Tx, customerID string, cartID uint64) (bool, error) {
+	previousIDs, err := r.queries.WithTx(tx).ClearDefaultCart(ctx, customerID)
+	if err != nil {
+		r.logger.Error("Failed to clear default cart", zap.String("customer_id", customerID), zap.Error(err))
+		return false, err
+	}
+
+	rows, err := r.queries.WithTx(tx).SetDefaultCart(ctx, sqlc.SetDefaultCartParams{
+		ID:         int32(cartID),
+		CustomerID: customerID,
+	})
+	if err != nil {
+		r.logger.Error("Failed to set default cart", zap.Uint64("cart_id", cartID), zap.Error(err))
+		return false, err
+	}
+
+	// 更新快取
+	for _, id := range previousIDs {
+		r.invalidateCartCache(ctx, uint64(id))
+	}
+	r.invalidateCartCache(ctx, cartID)
+	if err := r.cache.Delete(ctx, fmt.Sprintf("active_cart:%s", customerID)); err != nil {
+		r.logger.Warn("Failed to invalidate active cart cache", zap.Error(err))
+	}
+
+	return rows > 0, nil
+}
+
 func (r *repository) UpdateCartStatus(ctx context.Context, tx pgx.Tx, id uint64, status enum.CartStatus) error {
-	err := sqlc.New(r.conn).WithTx(tx).UpdateCartStatus(ctx, sqlc.UpdateCartStatusParams{
+	err := r.queries.WithTx(tx).UpdateCartStatus(ctx, sqlc.UpdateCartStatusParams{
 		ID:     int32(id),
 		Status: sqlc.CartStatus(status),
 	})
@@ -139,21 +274,183 @@ func (r *repository) UpdateCartStatus(ctx context.Context, tx pgx.Tx, id uint64,
 	return nil
 }
 
+// UpdateCartTotals 寫入重新計算後的小計、稅額與折扣，總額由資料庫一併更新
+func (r *repository) UpdateCartTotals(ctx context.Context, tx pgx.Tx, id uint64, subtotal, tax, discount float64) error {
+	err := r.queries.WithTx(tx).UpdateCartTotals(ctx, sqlc.UpdateCartTotalsParams{
+		ID:       int32(id),
+		Subtotal: subtotal,
+		Tax:      tax,
+		Discount: discount,
+	})
+	if err != nil {
+		r.logger.Error("Failed to update cart totals", zap.Error(err))
+		return err
+	}
+
+	// 更新快取
+	r.invalidateCartCache(ctx, id)
+
+	return nil
+}
+
+// SetCartPromotions 記錄購物車目前套用的優惠，promotions 為空時清除
+func (r *repository) SetCartPromotions(ctx context.Context, tx pgx.Tx, id uint64, promotions []*models.AppliedPromotion) error {
+	raw := []byte("[]")
+	if len(promotions) > 0 {
+		var err error
+		if raw, err = json.Marshal(promotions); err != nil {
+			return fmt.Errorf("failed to marshal cart promotions: %w", err)
+		}
+	}
+
+	if err := r.queries.WithTx(tx).SetCartPromotions(ctx, sqlc.SetCartPromotionsParams{
+		ID:                int32(id),
+		AppliedPromotions: raw,
+	}); err != nil {
+		r.logger.Error("Failed to set cart promotions", zap.Error(err))
+		return err
+	}
+
+	// 更新快取
+	r.invalidateCartCache(ctx, id)
+
+	return nil
+}
+
+// ExtendCartExpiry 將 active 購物車的到期時間延後到 expiresAt，已晚於 expiresAt 時保持不變；
+// 回傳 false 表示購物車不存在或已不是 active 狀態
+func (r *repository) ExtendCartExpiry(ctx context.Context, tx pgx.Tx, id uint64, expiresAt time.Time) (bool, error) {
+	rows, err := r.queries.WithTx(tx).ExtendCartExpiry(ctx, sqlc.ExtendCartExpiryParams{
+		ID:        int32(id),
+		ExpiresAt: pgtype.Timestamptz{Time: expiresAt, Valid: true},
+	})
+	if err != nil {
+		r.logger.Error("Failed to extend cart expiry", zap.Uint64("cart_id", id), zap.Error(err))
+		return false, err
+	}
+
+	// 更新快取
+	r.invalidateCartCache(ctx, id)
+
+	return rows > 0, nil
+}
+
+// ReactivateCart 將已轉換為訂單的購物車恢復為 active 並設定新的期限，回傳 false 表示購物車不存在或不是 converted 狀態；
+// 客戶已有同名的 active 購物車時以「名稱-ID」重新命名，已有預設購物車時不再是預設購物車
+func (r *repository) ReactivateCart(ctx context.Context, tx pgx.Tx, id uint64, expiresAt time.Time) (bool, error) {
+	rows, err := r.queries.WithTx(tx).ReactivateCart(ctx, sqlc.ReactivateCartParams{
+		ID:        int32(id),
+		ExpiresAt: pgtype.Timestamptz{Time: expiresAt, Valid: true},
+	})
+	if err != nil {
+		r.logger.Error("Failed to reactivate cart", zap.Uint64("cart_id", id), zap.Error(err))
+		return false, err
+	}
+
+	// 更新快取
+	r.invalidateCartCache(ctx, id)
+
+	return rows > 0, nil
+}
+
+// SetCartAddresses 寫入 active 購物車的寄送與帳單地址，回傳 false 表示購物車不存在或已不是 active 狀態
+func (r *repository) SetCartAddresses(ctx context.Context, tx pgx.Tx, id uint64, addresses *models.CartAddresses) (bool, error) {
+	rows, err := r.queries.WithTx(tx).SetCartAddresses(ctx, sqlc.SetCartAddressesParams{
+		ID:              int32(id),
+		ShippingAddress: addresses.ShippingAddress,
+		BillingAddress:  addresses.BillingAddress,
+	})
+	if err != nil {
+		r.logger.Error("Failed to set cart addresses", zap.Uint64("cart_id", id), zap.Error(err))
+		return false, err
+	}
+
+	// 更新快取
+	r.invalidateCartCache(ctx, id)
+
+	return rows > 0, nil
+}
+
+// SetCartChannel 設定 active 購物車的銷售通路，channel 為空字串時清除；回傳 false 表示購物車不存在或已不是 active 狀態
+func (r *repository) SetCartChannel(ctx context.Context, tx pgx.Tx, id uint64, channel string) (bool, error) {
+	var channelValue *string
+	if channel != "" {
+		channelValue = &channel
+	}
+
+	rows, err := r.queries.WithTx(tx).SetCartChannel(ctx, sqlc.SetCartChannelParams{
+		ID:      id,
+		Channel: channelValue,
+	})
+	if err != nil {
+		r.logger.Error("Failed to set cart channel", zap.Uint64("cart_id", id), zap.Error(err))
+		return false, err
+	}
+
+	// 更新快取
+	r.invalidateCartCache(ctx, id)
+
+	return rows > 0, nil
+}
+
+// ClearCartAddresses 清除 active 購物車的寄送與帳單地址，回傳 false 表示購物車不存在或已不是 active 狀態
+func (r *repository) ClearCartAddresses(ctx context.Context, tx pgx.Tx, id uint64) (bool, error) {
+	rows, err := r.queries.WithTx(tx).ClearCartAddresses(ctx, int32(id))
+	if err != nil {
+		r.logger.Error("Failed to clear cart addresses", zap.Uint64("cart_id", id), zap.Error(err))
+		return false, err
+	}
+
+	// 更新快取
+	r.invalidateCartCache(ctx, id)
+
+	return rows > 0, nil
+}
+
+func (r *repository) GetCartAddresses(ctx context.Context, tx pgx.Tx, id uint64) (*models.CartAddresses, error) {
+	row, err := r.queries.WithTx(tx).GetCartAddresses(ctx, int32(id))
+	if err != nil {
+		r.logger.Error("Failed to get cart addresses", zap.Uint64("cart_id", id), zap.Error(err))
+		return nil, err
+	}
+
+	return &models.CartAddresses{
+		ShippingAddress: row.ShippingAddress,
+		BillingAddress:  row.BillingAddress,
+	}, nil
+}
+
+// AddCartItem 新增購物車項目，並將產生的 ID 寫回 item
 func (r *repository) AddCartItem(ctx context.Context, tx pgx.Tx, cartID uint64, item *models.CartItem) error {
-	err := sqlc.New(r.conn).WithTx(tx).AddCartItem(ctx, sqlc.AddCartItemParams{
-		CartID:    cartID,
-		ProductID: item.ProductID,
-		PriceID:   item.PriceID,
-		StockID:   item.StockID,
-		Quantity:  item.Quantity,
-		UnitPrice: item.UnitPrice,
-		Subtotal:  item.Subtotal,
+	var location, note *string
+	if item.Location != "" {
+		location = &item.Location
+	}
+	if item.Note != "" {
+		note = &item.Note
+	}
+
+	id, err := r.queries.WithTx(tx).AddCartItem(ctx, sqlc.AddCartItemParams{
+		CartID:        cartID,
+		ProductID:     item.ProductID,
+		PriceID:       item.PriceID,
+		StockID:       item.StockID,
+		Quantity:      item.Quantity,
+		UnitPrice:     item.UnitPrice,
+		Subtotal:      item.Subtotal,
+		Location:      location,
+		Customization: item.Customization,
+		Note:          note,
+		Metadata:      models.MarshalMetadata(item.Metadata),
 	})
 	if err != nil {
 		r.logger.Error("Failed to add cart item", zap.Error(err))
 		return err
 	}
 
+	item.ID = uint64(id)
+	item.CartID = cartID
+
 	// 更新快取
 	r.invalidateCartCache(ctx, cartID)
 	r.invalidateCartItemsCache(ctx, cartID)
@@ -171,10 +468,17 @@ func (r *repository) GetCartItem(ctx context.Context, tx pgx.Tx, id uint64) (*mo
 		r.logger.Warn("Failed to get cart item from cache", zap.Error(err))
 	}
 	if found {
+		r.shadow.Verify(ctx, cacheKey, &cartItem, func(ctx context.Context) (any, error) {
+			sqlcCartItem, err := r.queries.Querier().GetCartItem(ctx, int32(id))
+			if err != nil {
+				return nil, err
+			}
+			return new(models.CartItem).ConvertSqlcCartItem(sqlcCartItem), nil
+		})
 		return &cartItem, nil
 	}
 
-	sqlcCartItem, err := sqlc.New(r.conn).WithTx(tx).GetCartItem(ctx, int32(id))
+	sqlcCartItem, err := r.queries.WithTx(tx).GetCartItem(ctx, int32(id))
 	if err != nil {
 		r.logger.Error("Failed to get cart item", zap.Error(err))
 		return nil, err
@@ -191,7 +495,7 @@ func (r *repository) GetCartItem(ctx context.Context, tx pgx.Tx, id uint64) (*mo
 }
 
 func (r *repository) UpdateCartItem(ctx context.Context, tx pgx.Tx, item *models.CartItem) error {
-	err := sqlc.New(r.conn).WithTx(tx).UpdateCartItem(ctx, sqlc.UpdateCartItemParams{
+	err := r.queries.WithTx(tx).UpdateCartItem(ctx, sqlc.UpdateCartItemParams{
 		ID:       int32(item.ID),
 		Quantity: item.Quantity,
 		Subtotal: item.Subtotal,
@@ -212,8 +516,59 @@ func (r *repository) UpdateCartItem(ctx context.Context, tx pgx.Tx, item *models
 	return nil
 }
 
+// UpdateCartItemTax 寫入購物車項目的稅率與稅額
+func (r *repository) UpdateCartItemTax(ctx context.Context, tx pgx.Tx, item *models.CartItem) error {
+	err := r.queries.WithTx(tx).UpdateCartItemTax(ctx, sqlc.UpdateCartItemTaxParams{
+		ID:        int32(item.ID),
+		TaxRate:   item.TaxRate,
+		TaxAmount: item.TaxAmount,
+	})
+	if err != nil {
+		r.logger.Error("Failed to update cart item tax", zap.Error(err))
+		return err
+	}
+
+	// 更新快取
+	r.invalidateCartItemsCache(ctx, item.CartID)
+
+	return nil
+}
+
+// UpdateCartItemMetadata 更新購物車項目的備註與自訂屬性，項目不在該購物車時回傳 false
+func (r *repository) UpdateCartItemMetadata(ctx context.Context, tx pgx.Tx, item *models.CartItem) (bool, error) {
+	var note *string
+	if item.Note != "" {
+		note = &item.Note
+	}
+
+	rows, err := r.queries.WithTx(tx).UpdateCartItemMetadata(ctx, sqlc.UpdateCartItemMetadataParams{
+		ID:       int32(item.ID),
+		CartID:   item.CartID,
+		Note:     note,
+		Metadata: models.MarshalMetadata(item.Metadata),
+	})
+	if err != nil {
+		r.logger.Error("Failed to update cart item metadata", zap.Error(err))
+		return false, err
+	}
+
+	// 更新快取，帶有備註的項目不再與同商品的項目合併，需一併清除以商品查詢的快取
+	r.invalidateCartCache(ctx, item.CartID)
+	r.invalidateCartItemsCache(ctx, item.CartID)
+	for _, cacheKey := range []string{
+		fmt.Sprintf("cart_item:%d", item.ID),
+		fmt.Sprintf("cart_item:%d:%s", item.CartID, item.ProductID),
+	} {
+		if err := r.cache.Delete(ctx, cacheKey); err != nil {
+			r.logger.Warn("Failed to remove cart item from cache", zap.Error(err))
+		}
+	}
+
+	return rows > 0, nil
+}
+
 func (r *repository) RemoveCartItem(ctx context.Context, tx pgx.Tx, itemID uint64) error {
-	err := sqlc.New(r.conn).WithTx(tx).RemoveCartItem(ctx, int32(itemID))
+	err := r.queries.WithTx(tx).RemoveCartItem(ctx, int32(itemID))
 	if err != nil {
 		r.logger.Error("Failed to remove cart item", zap.Error(err))
 		return err
@@ -228,6 +583,7 @@ func (r *repository) RemoveCartItem(ctx context.Context, tx pgx.Tx, itemID uint6
 	return nil
 }
 
+// GetCartItemByProductID 取得購物車中該商品未客製化且沒有備註、自訂屬性的項目，其餘項目各自獨立，不會被找到
 func (r *repository) GetCartItemByProductID(ctx context.Context, tx pgx.Tx, cartID uint64, productID string) (*models.CartItem, error) {
 	cacheKey := fmt.Sprintf("cart_item:%d:%s", cartID, productID)
 	var cartItem models.CartItem
@@ -237,14 +593,22 @@ func (r *repository) GetCartItemByProductID(ctx context.Context, tx pgx.Tx, cart
 	if err != nil {
 		r.logger.Warn("Failed to get cart item by product ID from cache", zap.Error(err))
 	}
+	params := sqlc.FindCartItemByProductIDParams{
+		CartID:    cartID,
+		ProductID: productID,
+	}
 	if found {
+		r.shadow.Verify(ctx, cacheKey, &cartItem, func(ctx context.Context) (any, error) {
+			sqlcCartItem, err := r.queries.Querier().FindCartItemByProductID(ctx, params)
+			if err != nil {
+				return nil, err
+			}
+			return new(models.CartItem).ConvertSqlcCartItem(sqlcCartItem), nil
+		})
 		return &cartItem, nil
 	}
 
-	sqlcCartItem, err := sqlc.New(r.conn).WithTx(tx).FindCartItemByProductID(ctx, sqlc.FindCartItemByProductIDParams{
-		CartID:    cartID,
-		ProductID: productID,
-	})
+	sqlcCartItem, err := r.queries.WithTx(tx).FindCartItemByProductID(ctx, params)
 	if err != nil {
 		r.logger.Error("Failed to get cart item by product ID", zap.Error(err))
 		return nil, err
@@ -270,10 +634,21 @@ func (r *repository) ListCartItems(ctx context.Context, tx pgx.Tx, cartID uint64
 		r.logger.Warn("Failed to get cart items from cache", zap.Error(err))
 	}
 	if found {
+		r.shadow.Verify(ctx, cacheKey, &cartItems, func(ctx context.Context) (any, error) {
+			sqlcCartItems, err := r.queries.Querier().ListCartItems(ctx, cartID)
+			if err != nil {
+				return nil, err
+			}
+			items := make([]*models.CartItem, 0, len(sqlcCartItems))
+			for _, sqlcCartItem := range sqlcCartItems {
+				items = append(items, new(models.CartItem).ConvertSqlcCartItem(sqlcCartItem))
+			}
+			return items, nil
+		})
 		return cartItems, nil
 	}
 
-	sqlcCartItems, err := sqlc.New(r.conn).WithTx(tx).ListCartItems(ctx, cartID)
+	sqlcCartItems, err := r.queries.WithTx(tx).ListCartItems(ctx, cartID)
 	if err != nil {
 		r.logger.Error("Failed to list cart items", zap.Error(err))
 		return nil, err
@@ -293,7 +668,7 @@ func (r *repository) ListCartItems(ctx context.Context, tx pgx.Tx, cartID uint64
 }
 
 func (r *repository) ClearCartItems(ctx context.Context, tx pgx.Tx, cartID uint64) error {
-	err := sqlc.New(r.conn).WithTx(tx).ClearCartItems(ctx, cartID)
+	err := r.queries.WithTx(tx).ClearCartItems(ctx, cartID)
 	if err != nil {
 		r.logger.Error("Failed to clear cart items", zap.Error(err))
 		return err
@@ -319,3 +694,206 @@ func (r *repository) invalidateCartItemsCache(ctx context.Context, cartID uint64
 		r.logger.Warn("Failed to invalidate cart items cache", zap.Error(err))
 	}
 }
+
+// ListOrphanedCartItems 列出指向不存在或屬於其他商品之庫存的購物車項目
+func (r *repository) ListOrphanedCartItems(ctx context.Context, tx pgx.Tx) ([]*models.OrphanedItem, error) {
+	rows, err := r.queries.WithTx(tx).ListOrphanedCartItems(ctx)
+	if err != nil {
+		r.logger.Error("failed to list orphaned cart items", zap.Error(err))
+		return nil, err
+	}
+
+	result := make([]*models.OrphanedItem, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, new(models.OrphanedItem).ConvertSqlcOrphanedItem(row))
+	}
+
+	return result, nil
+}
+
+// ListConvertedCartsWithoutOrder 列出狀態為 converted 但沒有對應訂單的購物車
+func (r *repository) ListConvertedCartsWithoutOrder(ctx context.Context, tx pgx.Tx) ([]*models.Cart, error) {
+	rows, err := r.queries.WithTx(tx).ListConvertedCartsWithoutOrder(ctx)
+	if err != nil {
+		r.logger.Error("failed to list converted carts without order", zap.Error(err))
+		return nil, err
+	}
+
+	result := make([]*models.Cart, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, new(models.Cart).ConvertSqlcCart(row))
+	}
+
+	return result, nil
+}
+
+// ListCartTotalMismatches 列出金額與項目不一致的 active 購物車
+func (r *repository) ListCartTotalMismatches(ctx context.Context, tx pgx.Tx) ([]*models.TotalMismatch, error) {
+	rows, err := r.queries.WithTx(tx).ListCartTotalMismatches(ctx)
+	if err != nil {
+		r.logger.Error("failed to list cart total mismatches", zap.Error(err))
+		return nil, err
+	}
+
+	result := make([]*models.TotalMismatch, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, new(models.TotalMismatch).ConvertSqlcTotalMismatch(row))
+	}
+
+	return result, nil
+}
+
+// ListExpiredActiveCartIDs 依到期時間先後列出在 now 之前已到期但仍為 active 的購物車，最多 limit 筆
+func (r *repository) ListExpiredActiveCartIDs(ctx context.Context, tx pgx.Tx, now time.Time, limit int32) ([]uint64, error) {
+	rows, err := r.queries.WithTx(tx).ListExpiredActiveCartIDs(ctx, sqlc.ListExpiredActiveCartIDsParams{
+		ExpiresAt: pgtype.Timestamptz{Time: now, Valid: true},
+		Limit:     limit,
+	})
+	if err != nil {
+		r.logger.Error("Failed to list expired active carts", zap.Error(err))
+		return nil, err
+	}
+
+	ids := make([]uint64, 0, len(rows))
+	for _, id := range rows {
+		ids = append(ids, uint64(id))
+	}
+
+	return ids, nil
+}
+
+// ListInactiveCartIDs 依最後異動時間先後列出自 since 之後沒有異動、仍有項目且尚未通知過閒置的 active 購物車，最多 limit 筆；
+// 通知後又有異動的購物車會再次列出
+func (r *repository) ListInactiveCartIDs(ctx context.Context, tx pgx.Tx, since time.Time, limit int32) ([]uint64, error) {
+	rows, err := r.queries.WithTx(tx).ListInactiveCartIDs(ctx, sqlc.ListInactiveCartIDsParams{
+		UpdatedAt: pgtype.Timestamptz{Time: since, Valid: true},
+		Limit:     limit,
+	})
+	if err != nil {
+		r.logger.Error("Failed to list inactive carts", zap.Error(err))
+		return nil, err
+	}
+
+	ids := make([]uint64, 0, len(rows))
+	for _, id := range rows {
+		ids = append(ids, uint64(id))
+	}
+
+	return ids, nil
+}
+
+// MarkCartAbandonedNotified 記錄閒置購物車已通知的時間，回傳 false 表示購物車已不是 active、
+// 在 since 之後有異動或已經通知過
+func (r *repository) MarkCartAbandonedNotified(ctx context.Context, tx pgx.Tx, id uint64, since, notifiedAt time.Time) (bool, error) {
+	rows, err := r.queries.WithTx(tx).MarkCartAbandonedNotified(ctx, sqlc.MarkCartAbandonedNotifiedParams{
+		ID:                  int32(id),
+		AbandonedNotifiedAt: pgtype.Timestamptz{Time: notifiedAt, Valid: true},
+		UpdatedAt:           pgtype.Timestamptz{Time: since, Valid: true},
+	})
+	if err != nil {
+		r.logger.Error("Failed to mark cart abandoned notified", zap.Uint64("cart_id", id), zap.Error(err))
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+// GetNotificationPreferences 取得客戶的通知偏好，客戶沒有記錄時回傳 pgx.ErrNoRows
+func (r *repository) GetNotificationPreferences(ctx context.Context, tx pgx.Tx, customerID string) (*models.NotificationPreferences, error) {
+	row, err := r.queries.WithTx(tx).GetCustomerNotificationPreference(ctx, customerID)
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			r.logger.Error("Failed to get notification preferences", zap.String("customer_id", customerID), zap.Error(err))
+		}
+		return nil, err
+	}
+
+	return new(models.NotificationPreferences).ConvertSqlcNotificationPreferences(row), nil
+}
+
+// SaveNotificationPreferences 新增或覆寫客戶的通知偏好，並寫回更新時間
+func (r *repository) SaveNotificationPreferences(ctx context.Context, tx pgx.Tx, preferences *models.NotificationPreferences) error {
+	params := sqlc.UpsertCustomerNotificationPreferenceParams{
+		CustomerID:   preferences.CustomerID,
+		EmailEnabled: preferences.Email,
+		SmsEnabled:   preferences.SMS,
+		PushEnabled:  preferences.Push,
+	}
+	if preferences.MarketingOptedOutAt != nil {
+		params.MarketingOptedOutAt = pgtype.Timestamptz{Time: *preferences.MarketingOptedOutAt, Valid: true}
+	}
+	if preferences.TransactionalOptedOutAt != nil {
+		params.TransactionalOptedOutAt = pgtype.Timestamptz{Time: *preferences.TransactionalOptedOutAt, Valid: true}
+	}
+
+	row, err := r.queries.WithTx(tx).UpsertCustomerNotificationPreference(ctx, params)
+	if err != nil {
+		r.logger.Error("Failed to save notification preferences", zap.String("customer_id", preferences.CustomerID), zap.Error(err))
+		return err
+	}
+
+	preferences.UpdatedAt = row.UpdatedAt.Time
+	return nil
+}
+
+// AssignCartExperiment 記錄購物車在實驗中的組別，購物車已分配過該實驗時保留原本的組別並回傳 false
+func (r *repository) AssignCartExperiment(ctx context.Context, tx pgx.Tx, cartID uint64, experiment, variant string) (bool, error) {
+	rows, err := r.queries.WithTx(tx).AssignCartExperiment(ctx, sqlc.AssignCartExperimentParams{
+		CartID:     cartID,
+		Experiment: experiment,
+		Variant:    variant,
+	})
+	if err != nil {
+		r.logger.Error("Failed to assign cart experiment", zap.Uint64("cart_id", cartID), zap.String("experiment", experiment), zap.Error(err))
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+// ListCartExperimentAssignments 依實驗名稱列出購物車被分配到的組別
+func (r *repository) ListCartExperimentAssignments(ctx context.Context, tx pgx.Tx, cartID uint64) ([]*models.ExperimentAssignment, error) {
+	rows, err := r.queries.WithTx(tx).ListCartExperimentAssignments(ctx, cartID)
+	if err != nil {
+		r.logger.Error("Failed to list cart experiment assignments", zap.Uint64("cart_id", cartID), zap.Error(err))
+		return nil, err
+	}
+
+	result := make([]*models.ExperimentAssignment, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, new(models.ExperimentAssignment).ConvertSqlcExperimentAssignment(row))
+	}
+
+	return result, nil
+}
+
+// ListOrderExperimentAssignments 依實驗名稱列出訂單結帳時購物車所屬的組別
+func (r *repository) ListOrderExperimentAssignments(ctx context.Context, tx pgx.Tx, orderID uint64) ([]*models.ExperimentAssignment, error) {
+	id := int32(orderID)
+	rows, err := r.queries.WithTx(tx).ListOrderExperimentAssignments(ctx, &id)
+	if err != nil {
+		r.logger.Error("Failed to list order experiment assignments", zap.Uint64("order_id", orderID), zap.Error(err))
+		return nil, err
+	}
+
+	result := make([]*models.ExperimentAssignment, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, new(models.ExperimentAssignment).ConvertSqlcExperimentAssignment(row))
+	}
+
+	return result, nil
+}
+
+// AttachExperimentAssignmentsToOrder 將購物車的實驗組別記錄到結帳成立的訂單
+func (r *repository) AttachExperimentAssignmentsToOrder(ctx context.Context, tx pgx.Tx, cartID, orderID uint64) error {
+	id := int32(orderID)
+	if err := r.queries.WithTx(tx).AttachExperimentAssignmentsToOrder(ctx, sqlc.AttachExperimentAssignmentsToOrderParams{
+		CartID:  cartID,
+		OrderID: &id,
+	}); err != nil {
+		r.logger.Error("Failed to attach experiment assignments to order", zap.Uint64("cart_id", cartID), zap.Uint64("order_id", orderID), zap.Error(err))
+		return err
+	}
+
+	return nil
+}
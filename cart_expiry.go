@@ -0,0 +1,178 @@
+package shop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+
+	"gofalre.io/shop/models"
+	"gofalre.io/shop/models/enum"
+)
+
+const (
+	// defaultCartTTL 為預設的購物車閒置期限，每次異動購物車都會重新計算
+	defaultCartTTL = 7 * 24 * time.Hour
+	// defaultCartExpirySweepInterval 為預設的到期購物車清理間隔
+	defaultCartExpirySweepInterval = 5 * time.Minute
+	// cartExpiryBatchSize 為每次清理處理的購物車上限，避免單次清理佔用過久
+	cartExpiryBatchSize = 100
+)
+
+// ErrCartNotActive 表示購物車已結帳、放棄或不存在，無法再延長期限
+var ErrCartNotActive = errors.New("cart is not active")
+
+// WithCartTTL 設定購物車的閒置期限，購物車在最後一次異動後經過此時間才會到期
+func WithCartTTL(ttl time.Duration) Option {
+	return func(s *service) {
+		if ttl > 0 {
+			s.cartTTL = ttl
+		}
+	}
+}
+
+// WithCartExpirySweepInterval 設定 RunCartExpirySweeper 清理到期購物車的間隔
+func WithCartExpirySweepInterval(d time.Duration) Option {
+	return func(s *service) {
+		if d > 0 {
+			s.cartSweepInterval = d
+		}
+	}
+}
+
+// ExtendCartExpiry 供客服人員手動延長購物車的期限，從目前的到期時間（已過期則從現在）再延長 d
+func (s *service) ExtendCartExpiry(ctx context.Context, cartID uint64, d time.Duration) (time.Time, error) {
+	if d <= 0 {
+		return time.Time{}, errors.New("extension must be positive")
+	}
+
+	var expiresAt time.Time
+
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		// 1. 獲取購物車並檢查權限
+		cartModel, err := s.cart.GetCart(ctx, tx, cartID)
+		if err != nil {
+			return fmt.Errorf("failed to get cart: %w", err)
+		}
+		if err = s.checkAuthorization(ctx, AuthorizationRequest{
+			Action:     ActionExtendCartExpiry,
+			ResourceID: cartID,
+			CustomerID: cartModel.CustomerID,
+		}); err != nil {
+			return err
+		}
+		if cartModel.Status != enum.CartStatusActive {
+			return fmt.Errorf("%w: cart %d is %s", ErrCartNotActive, cartID, cartModel.Status)
+		}
+
+		// 2. 計算新的到期時間並寫回
+		expiresAt = cartModel.ExpiresAt
+		if now := time.Now(); expiresAt.Before(now) {
+			expiresAt = now
+		}
+		expiresAt = expiresAt.Add(d)
+
+		ok, err := s.cart.ExtendCartExpiry(ctx, tx, cartID, expiresAt)
+		if err != nil {
+			return fmt.Errorf("failed to extend cart expiry: %w", err)
+		}
+		if !ok {
+			return fmt.Errorf("%w: cart %d", ErrCartNotActive, cartID)
+		}
+		return nil
+	}); err != nil {
+		return time.Time{}, err
+	}
+
+	return expiresAt, nil
+}
+
+// touchCart 在購物車異動後以滑動視窗延長期限，不會縮短客服手動延長過的期限
+func (s *service) touchCart(ctx context.Context, tx pgx.Tx, cartID uint64) error {
+	if _, err := s.cart.ExtendCartExpiry(ctx, tx, cartID, time.Now().Add(s.cartTTL)); err != nil {
+		return fmt.Errorf("failed to extend cart expiry: %w", err)
+	}
+	return nil
+}
+
+// ExpireCarts 釋放已到期的 active 購物車預留的庫存、記錄 release 庫存變動並標記為 abandoned，
+// 每次最多處理 cartExpiryBatchSize 個購物車，回傳處理的數量
+func (s *service) ExpireCarts(ctx context.Context) (int, error) {
+	now := time.Now()
+
+	cartIDs, err := s.cart.ListExpiredActiveCartIDs(ctx, nil, now, cartExpiryBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list expired carts: %w", err)
+	}
+
+	expired := 0
+	for _, cartID := range cartIDs {
+		cartModel, err := s.expireCart(ctx, cartID, now)
+		if err != nil {
+			s.log(ctx).Error("Failed to expire cart", zap.Uint64("cart_id", cartID), zap.Error(err))
+			continue
+		}
+		if cartModel == nil {
+			continue
+		}
+		expired++
+
+		s.publishCartEvent(ctx, SubjectCartExpired, cartModel)
+	}
+
+	return expired, nil
+}
+
+// RunCartExpirySweeper 依 WithCartExpirySweepInterval 設定的間隔定期執行 ExpireCarts，直到 ctx 結束
+func (s *service) RunCartExpirySweeper(ctx context.Context) error {
+	ticker := time.NewTicker(s.cartSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			expired, err := s.ExpireCarts(ctx)
+			if err != nil {
+				s.log(ctx).Error("Failed to sweep expired carts", zap.Error(err))
+				continue
+			}
+			if expired > 0 {
+				s.log(ctx).Info("Expired carts", zap.Int("count", expired))
+			}
+		}
+	}
+}
+
+// expireCart 在交易內重新確認購物車仍為 active 且已到期後釋放購物車；
+// 購物車在列出後已被結帳、放棄或延長期限時回傳 nil
+func (s *service) expireCart(ctx context.Context, cartID uint64, now time.Time) (*models.Cart, error) {
+	var cartModel *models.Cart
+
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		// 1. 重新獲取購物車，確認仍需清理
+		var err error
+		cartModel, err = s.cart.GetCart(ctx, tx, cartID)
+		if err != nil {
+			return fmt.Errorf("failed to get cart: %w", err)
+		}
+		if cartModel.Status != enum.CartStatusActive || !cartModel.ExpiresAt.Before(now) {
+			cartModel = nil
+			return nil
+		}
+
+		// 2. 釋放預留庫存、清空購物車並標記為 abandoned
+		return s.releaseCart(ctx, tx, cartID, enum.CartStatusAbandoned)
+	}); err != nil {
+		return nil, err
+	}
+
+	if cartModel != nil {
+		cartModel.Status = enum.CartStatusAbandoned
+	}
+	return cartModel, nil
+}
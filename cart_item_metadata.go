@@ -0,0 +1,110 @@
+package shop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5"
+
+	"gofalre.io/shop/models"
+	"gofalre.io/shop/models/enum"
+)
+
+const (
+	// maxCartItemNoteLength 為項目備註的最大字元數
+	maxCartItemNoteLength = 500
+	// maxCartItemMetadataKeys 為項目自訂屬性的最大鍵數
+	maxCartItemMetadataKeys = 20
+	// maxCartItemMetadataKeyLength、maxCartItemMetadataValueLength 為自訂屬性鍵與值的最大字元數
+	maxCartItemMetadataKeyLength   = 40
+	maxCartItemMetadataValueLength = 500
+)
+
+// findCartItemWithMetadata 尋找購物車中同一商品、未客製化且備註與自訂屬性完全相同的項目，
+// 找不到時回傳 pgx.ErrNoRows
+func (s *service) findCartItemWithMetadata(ctx context.Context, tx pgx.Tx, cartID uint64, item *models.CartItem) (*models.CartItem, error) {
+	items, err := s.cart.ListCartItems(ctx, tx, cartID)
+	if err != nil {
+		return nil, err
+	}
+	for _, existing := range items {
+		if existing.ProductID == item.ProductID && existing.Customization == nil &&
+			existing.Note == item.Note && maps.Equal(existing.Metadata, item.Metadata) {
+			return existing, nil
+		}
+	}
+	return nil, pgx.ErrNoRows
+}
+
+// UpdateCartItemMetadata 更新購物車項目的備註與自訂屬性，note 為空且 metadata 沒有鍵值時清除；
+// 購物車已非 active 時回傳包裝後的 ErrCartNotActive
+func (s *service) UpdateCartItemMetadata(ctx context.Context, cartID, itemID uint64, note string, metadata map[string]string) error {
+	// 1. 驗證備註與自訂屬性
+	note, err := validateCartItemMetadata(note, metadata)
+	if err != nil {
+		return err
+	}
+
+	return s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		// 2. 檢查購物車狀態
+		cartModel, err := s.cart.GetCart(ctx, tx, cartID)
+		if err != nil {
+			return fmt.Errorf("failed to get cart: %w", err)
+		}
+		if cartModel.Status != enum.CartStatusActive {
+			return fmt.Errorf("%w: cart %d is %s", ErrCartNotActive, cartID, cartModel.Status)
+		}
+
+		// 3. 獲取購物車項目
+		item, err := s.cart.GetCartItem(ctx, tx, itemID)
+		if err != nil {
+			return fmt.Errorf("failed to get cart item: %w", err)
+		}
+		if item.CartID != cartID {
+			return fmt.Errorf("item %d does not belong to cart %d", itemID, cartID)
+		}
+
+		// 4. 更新備註與自訂屬性
+		item.Note = note
+		item.Metadata = metadata
+		updated, err := s.cart.UpdateCartItemMetadata(ctx, tx, item)
+		if err != nil {
+			return fmt.Errorf("failed to update cart item metadata: %w", err)
+		}
+		if !updated {
+			return fmt.Errorf("item %d does not belong to cart %d", itemID, cartID)
+		}
+
+		// 5. 延長購物車期限
+		return s.touchCart(ctx, tx, cartID)
+	})
+}
+
+// validateCartItemMetadata 檢查項目備註與自訂屬性的長度與數量，回傳去除前後空白後的備註
+func validateCartItemMetadata(note string, metadata map[string]string) (string, error) {
+	note = strings.TrimSpace(note)
+	if utf8.RuneCountInString(note) > maxCartItemNoteLength {
+		return "", fmt.Errorf("note exceeds %d characters", maxCartItemNoteLength)
+	}
+
+	if len(metadata) > maxCartItemMetadataKeys {
+		return "", fmt.Errorf("metadata exceeds %d keys", maxCartItemMetadataKeys)
+	}
+	for key, value := range metadata {
+		if strings.TrimSpace(key) == "" {
+			return "", errors.New("metadata key is required")
+		}
+		if utf8.RuneCountInString(key) > maxCartItemMetadataKeyLength {
+			return "", fmt.Errorf("metadata key %q exceeds %d characters", key, maxCartItemMetadataKeyLength)
+		}
+		if utf8.RuneCountInString(value) > maxCartItemMetadataValueLength {
+			return "", fmt.Errorf("metadata value for %q exceeds %d characters", key, maxCartItemMetadataValueLength)
+		}
+	}
+
+	return note, nil
+}
@@ -0,0 +1,159 @@
+package shop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"gofalre.io/shop/models"
+	"gofalre.io/shop/models/enum"
+	"gofalre.io/shop/stock"
+)
+
+// CartItemUpdate 描述一筆購物車項目的數量變更，Quantity 為 0 時移除該項目
+type CartItemUpdate struct {
+	ItemID   uint64
+	Quantity uint64
+}
+
+// UpdateCartItems 在同一個交易內套用多筆項目的數量變更與移除，同一庫存的預留量合併後一次調整，
+// 任一筆失敗（例如庫存不足）時全部不套用
+func (s *service) UpdateCartItems(ctx context.Context, cartID uint64, updates []CartItemUpdate) error {
+	if len(updates) == 0 {
+		return nil
+	}
+
+	return s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		// 1. 獲取並檢查所有要變更的項目
+		items := make([]*models.CartItem, len(updates))
+		seen := make(map[uint64]struct{}, len(updates))
+		for i, update := range updates {
+			if _, ok := seen[update.ItemID]; ok {
+				return fmt.Errorf("cart item %d is updated more than once", update.ItemID)
+			}
+			seen[update.ItemID] = struct{}{}
+
+			item, err := s.cart.GetCartItem(ctx, tx, update.ItemID)
+			if err != nil {
+				return fmt.Errorf("failed to get cart item %d: %w", update.ItemID, err)
+			}
+			if item.CartID != cartID {
+				return fmt.Errorf("item %d does not belong to cart %d", update.ItemID, cartID)
+			}
+			if update.Quantity > 0 {
+				if err = s.checkFlashSaleLimit(ctx, item.ProductID, update.Quantity); err != nil {
+					return err
+				}
+			}
+			items[i] = item
+		}
+
+		// 2. 依庫存合併預留量的變化
+		stockDiffs := make(map[uint64]int64)
+		var stockIDs []uint64
+		for i, item := range items {
+			if _, ok := stockDiffs[item.StockID]; !ok {
+				stockIDs = append(stockIDs, item.StockID)
+			}
+			stockDiffs[item.StockID] += int64(updates[i].Quantity) - int64(item.Quantity)
+		}
+
+		// 3. 檢查庫存並準備批量調整參數
+		var (
+			adjustParams  []stock.AdjustStockParams
+			releaseParams []stock.ReleaseStockParams
+		)
+		for _, stockID := range stockIDs {
+			diff := stockDiffs[stockID]
+			if diff == 0 {
+				continue
+			}
+
+			stockModel, err := s.stock.GetStock(ctx, tx, stockID)
+			if err != nil {
+				return fmt.Errorf("failed to get stock %d: %w", stockID, err)
+			}
+
+			if diff > 0 {
+				if stockModel.Quantity < stockModel.ReservedQuantity || stockModel.Quantity-stockModel.ReservedQuantity < uint64(diff) {
+					return errors.New("insufficient stock")
+				}
+				adjustParams = append(adjustParams, stock.AdjustStockParams{
+					StockID:     stockID,
+					Quantity:    uint64(diff),
+					LastUpdated: stockModel.UpdatedAt,
+				})
+			} else {
+				releaseParams = append(releaseParams, stock.ReleaseStockParams{
+					StockID:     stockID,
+					Quantity:    uint64(-diff),
+					LastUpdated: stockModel.UpdatedAt,
+				})
+			}
+		}
+
+		// 4. 更新或移除購物車項目，每個項目各自記錄庫存變動
+		var moveParams []stock.CreateStockMovementParams
+		for i, item := range items {
+			newQuantity := updates[i].Quantity
+			if newQuantity == item.Quantity {
+				continue
+			}
+
+			moveType := enum.StockMovementTypeReserve
+			moved := newQuantity - item.Quantity
+			if newQuantity < item.Quantity {
+				moveType = enum.StockMovementTypeRelease
+				moved = item.Quantity - newQuantity
+			}
+			moveParams = append(moveParams, stock.CreateStockMovementParams{
+				StockID:         item.StockID,
+				Quantity:        moved,
+				Type:            moveType,
+				ReferenceID:     cartID,
+				ReferenceType:   enum.StockMovementReferenceTypeCart,
+				ReferenceItemID: item.ID,
+			})
+
+			if newQuantity == 0 {
+				if err := s.cart.RemoveCartItem(ctx, tx, item.ID); err != nil {
+					return fmt.Errorf("failed to remove cart item: %w", err)
+				}
+				continue
+			}
+
+			item.Quantity = newQuantity
+			item.Subtotal = float64(newQuantity) * item.UnitPrice
+			if err := s.cart.UpdateCartItem(ctx, tx, item); err != nil {
+				return fmt.Errorf("failed to update cart item: %w", err)
+			}
+		}
+
+		// 5. 批量調整庫存並創建庫存變動記錄
+		if len(adjustParams) > 0 {
+			if err := s.stock.AdjustStock(ctx, tx, adjustParams); err != nil {
+				return fmt.Errorf("failed to adjust stock: %w", err)
+			}
+		}
+		if len(releaseParams) > 0 {
+			if err := s.stock.ReleaseStock(ctx, tx, releaseParams); err != nil {
+				return fmt.Errorf("failed to release stock: %w", err)
+			}
+		}
+		if len(moveParams) > 0 {
+			if err := s.stock.CreateStockMovements(ctx, tx, moveParams); err != nil {
+				return fmt.Errorf("failed to create stock movements: %w", err)
+			}
+		}
+
+		// 6. 延長購物車期限
+		if err := s.touchCart(ctx, tx, cartID); err != nil {
+			return err
+		}
+
+		// 7. 重新計算購物車金額
+		return s.recalculateCartTotals(ctx, tx, cartID)
+	})
+}
@@ -0,0 +1,119 @@
+package shop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stripe/stripe-go/v79"
+
+	"gofalre.io/shop/models"
+	"gofalre.io/shop/models/enum"
+)
+
+const (
+	// defaultCartName 為預設購物車的名稱，未指定購物車時加入的商品都放在預設購物車
+	defaultCartName = "default"
+	// maxCartNameLength 為購物車名稱的長度上限（字元數）
+	maxCartNameLength = 100
+)
+
+// CreateNamedCart 為客戶建立指定名稱的 active 購物車，讓客戶（如 B2B 採購）同時準備多筆訂單；
+// 客戶沒有預設購物車時新購物車成為預設購物車。已有同名的 active 購物車時回傳 cart.ErrCartNameTaken
+func (s *service) CreateNamedCart(ctx context.Context, customerID, name string, currency stripe.Currency) (*models.Cart, error) {
+	name, err := normalizeCartName(name)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.enforceQuota(ctx); err != nil {
+		return nil, err
+	}
+
+	var cartModel *models.Cart
+
+	if err = s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		// 1. 檢查客戶是否已有預設購物車
+		hasDefault, err := s.hasDefaultCart(ctx, tx, customerID)
+		if err != nil {
+			return err
+		}
+
+		// 2. 建立購物車
+		cartModel, err = s.createCart(ctx, tx, customerID, name, !hasDefault, currency)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return cartModel, nil
+}
+
+// ListCarts 列出客戶所有 active 購物車，預設購物車在最前面，其餘依名稱排序
+func (s *service) ListCarts(ctx context.Context, customerID string) ([]*models.Cart, error) {
+	carts, err := s.cart.ListActiveCarts(ctx, nil, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list carts: %w", err)
+	}
+	return carts, nil
+}
+
+// SetDefaultCart 將客戶的 active 購物車設為預設購物車，之後未指定購物車的操作（如再次購買、稍後購買移回）使用此購物車
+func (s *service) SetDefaultCart(ctx context.Context, customerID string, cartID uint64) error {
+	return s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		ok, err := s.cart.SetDefaultCart(ctx, tx, customerID, cartID)
+		if err != nil {
+			return fmt.Errorf("failed to set default cart: %w", err)
+		}
+		if !ok {
+			return fmt.Errorf("%w: cart %d of customer %s", ErrCartNotActive, cartID, customerID)
+		}
+		return nil
+	})
+}
+
+// getOrCreateNamedCart 在呼叫端的交易內取得或建立客戶指定名稱的 active 購物車，名稱為預設名稱時等同 getOrCreateActiveCart
+func (s *service) getOrCreateNamedCart(ctx context.Context, tx pgx.Tx, customerID, name string, currency stripe.Currency) (*models.Cart, error) {
+	if name == "" || name == defaultCartName {
+		return s.getOrCreateActiveCart(ctx, tx, customerID, currency)
+	}
+
+	existingCart, err := s.cart.GetActiveCartByName(ctx, tx, customerID, name)
+	if err == nil {
+		return existingCart, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get cart %q: %w", name, err)
+	}
+
+	hasDefault, err := s.hasDefaultCart(ctx, tx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	return s.createCart(ctx, tx, customerID, name, !hasDefault, currency)
+}
+
+// hasDefaultCart 回傳客戶是否已有預設的 active 購物車
+func (s *service) hasDefaultCart(ctx context.Context, tx pgx.Tx, customerID string) (bool, error) {
+	defaultCart, err := s.cart.GetActiveCartByCustomerID(ctx, tx, customerID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get active cart: %w", err)
+	}
+	return defaultCart.Status == enum.CartStatusActive, nil
+}
+
+// normalizeCartName 去除名稱前後空白並檢查長度
+func normalizeCartName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", errors.New("cart name is required")
+	}
+	if len([]rune(name)) > maxCartNameLength {
+		return "", fmt.Errorf("cart name exceeds %d characters", maxCartNameLength)
+	}
+	return name, nil
+}
@@ -0,0 +1,56 @@
+package shop
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"gofalre.io/shop/models"
+)
+
+// recordCartSnapshot 將結帳當下的購物車項目、價格與套用的優惠寫入訂單，快照寫入後不再變更
+func (s *service) recordCartSnapshot(ctx context.Context, tx pgx.Tx, orderID uint64, cartModel *models.Cart, items []*models.CartItem, pricing *cartPricing, now time.Time) (*models.CartSnapshot, error) {
+	snapshot := &models.CartSnapshot{
+		CartID:     cartModel.ID,
+		Name:       cartModel.Name,
+		Channel:    cartModel.Channel,
+		Currency:   cartModel.Currency,
+		Items:      make([]*models.CartSnapshotItem, len(items)),
+		Subtotal:   pricing.Subtotal,
+		Tax:        pricing.Tax,
+		Discount:   pricing.Discount,
+		Total:      roundCurrency(pricing.Subtotal + pricing.Tax - pricing.Discount),
+		Promotions: pricing.Promotions,
+		CapturedAt: now,
+	}
+	if pricing.Coupon != nil {
+		snapshot.CouponCode = pricing.Coupon.Code
+	}
+
+	for i, item := range items {
+		snapshot.Items[i] = &models.CartSnapshotItem{
+			CartItemID: item.ID,
+			ProductID:  item.ProductID,
+			PriceID:    item.PriceID,
+			StockID:    item.StockID,
+			Location:   item.Location,
+			Quantity:   item.Quantity,
+			UnitPrice:  item.UnitPrice,
+			Subtotal:   item.Subtotal,
+
+			Customization: item.Customization,
+			Note:          item.Note,
+			Metadata:      item.Metadata,
+		}
+		if i < len(pricing.LineDiscounts) {
+			snapshot.Items[i].Discount = pricing.LineDiscounts[i]
+		}
+	}
+
+	if err := s.order.SetOrderCartSnapshot(ctx, tx, orderID, snapshot); err != nil {
+		return nil, fmt.Errorf("failed to set order cart snapshot: %w", err)
+	}
+	return snapshot, nil
+}
@@ -0,0 +1,54 @@
+package shop
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"gofalre.io/shop/models"
+)
+
+// ProductCatalog 提供商品目錄的資訊，商品資料由外部的商品服務維護
+type ProductCatalog interface {
+	GetProductSnapshot(ctx context.Context, productID, priceID string) (*models.ProductSnapshot, error)
+}
+
+// WithProductCatalog 設定下單時用來建立商品資訊快照的商品目錄
+func WithProductCatalog(catalog ProductCatalog) Option {
+	return func(s *service) {
+		s.catalog = catalog
+	}
+}
+
+// snapshotOrderItem 將商品目前的名稱、SKU、圖片、稅別、重量尺寸與報關資訊寫入訂單項目；已有快照或未設定商品目錄時略過
+func (s *service) snapshotOrderItem(ctx context.Context, item *models.OrderItem) {
+	if s.catalog == nil || item.ProductName != "" {
+		return
+	}
+
+	snapshot, err := s.catalog.GetProductSnapshot(ctx, item.ProductID, item.PriceID)
+	if err != nil {
+		// 快照失敗不阻擋下單，只記錄警告
+		s.log(ctx).Warn("Failed to snapshot product for order item",
+			zap.String("product_id", item.ProductID), zap.String("price_id", item.PriceID), zap.Error(err))
+		return
+	}
+
+	item.ApplySnapshot(snapshot)
+}
+
+// productTaxClass 回傳商品目錄中商品的稅別，與建立訂單項目快照時相同；未設定商品目錄或讀取失敗時回傳空字串
+func (s *service) productTaxClass(ctx context.Context, productID, priceID string) string {
+	if s.catalog == nil {
+		return ""
+	}
+
+	snapshot, err := s.catalog.GetProductSnapshot(ctx, productID, priceID)
+	if err != nil {
+		s.log(ctx).Warn("Failed to get product tax class",
+			zap.String("product_id", productID), zap.String("price_id", priceID), zap.Error(err))
+		return ""
+	}
+
+	return snapshot.TaxClass
+}
@@ -2,12 +2,14 @@ package category
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"go.uber.org/zap"
 	"gofalre.io/shop/driver"
 	"gofalre.io/shop/models"
+	"gofalre.io/shop/price"
 	"gofalre.io/shop/sqlc"
 	"goflare.io/ember"
 	"time"
@@ -15,46 +17,92 @@ import (
 
 var _ Repository = (*repository)(nil)
 
+// ErrStaleCategory 表示更新時分類的 updated_at 已被其他交易修改，呼叫端應重新讀取後重試
+var ErrStaleCategory = errors.New("category was modified concurrently")
+
 type Repository interface {
 	Create(ctx context.Context, tx pgx.Tx, category *models.Category) error
 	GetByID(ctx context.Context, tx pgx.Tx, id uint64) (*models.Category, error)
 	Update(ctx context.Context, tx pgx.Tx, category *models.Category) error
 	Delete(ctx context.Context, tx pgx.Tx, id uint64) error
 	List(ctx context.Context, tx pgx.Tx, limit, offset uint64) ([]*models.Category, error)
+	Count(ctx context.Context, tx pgx.Tx) (uint64, error)
+	ListAll(ctx context.Context, tx pgx.Tx) ([]*models.Category, error)
 	ListSubcategories(ctx context.Context, tx pgx.Tx, parentID uint64) ([]*models.Category, error)
 	AssignProductToCategory(ctx context.Context, tx pgx.Tx, productID string, categoryID uint64) error
 	RemoveProductFromCategory(ctx context.Context, tx pgx.Tx, productID string, categoryID uint64) error
+	ListProductsInCategories(ctx context.Context, tx pgx.Tx, productIDs []string, categoryIDs []uint64) ([]string, error)
+
+	UpsertCategoryTranslation(ctx context.Context, tx pgx.Tx, translation *models.CategoryTranslation) error
+	DeleteCategoryTranslation(ctx context.Context, tx pgx.Tx, categoryID uint64, locale string) (bool, error)
+	ListCategoryTranslations(ctx context.Context, tx pgx.Tx, categoryIDs []uint64, locales []string) ([]*models.CategoryTranslation, error)
+	UpsertProductTranslation(ctx context.Context, tx pgx.Tx, translation *models.ProductTranslation) error
+	DeleteProductTranslation(ctx context.Context, tx pgx.Tx, productID, locale string) (bool, error)
+	ListProductTranslations(ctx context.Context, tx pgx.Tx, productIDs []string, locales []string) ([]*models.ProductTranslation, error)
+	ListProductTranslationsAfter(ctx context.Context, tx pgx.Tx, afterProductID, afterLocale string, limit int32) ([]*models.ProductTranslation, error)
+	SearchProductIDs(ctx context.Context, tx pgx.Tx, locale, pattern string, limit, offset uint64) ([]string, error)
+	CountSearchProducts(ctx context.Context, tx pgx.Tx, locale, pattern string) (uint64, error)
+
+	CreateProductMedia(ctx context.Context, tx pgx.Tx, media *models.ProductMedia) error
+	GetProductMedia(ctx context.Context, tx pgx.Tx, mediaID uint64) (*models.ProductMedia, error)
+	UpdateProductMedia(ctx context.Context, tx pgx.Tx, media *models.ProductMedia) error
+	DeleteProductMedia(ctx context.Context, tx pgx.Tx, mediaID uint64) (*models.ProductMedia, error)
+	ListProductMedia(ctx context.Context, tx pgx.Tx, productID, priceID string) ([]*models.ProductMedia, error)
 }
 
 type repository struct {
-	conn   driver.PostgresPool
-	cache  *ember.Ember
-	logger *zap.Logger
+	queries *driver.Queries
+	cache   *ember.Ember
+	shadow  *driver.CacheShadow
+	logger  *zap.Logger
+}
+
+func NewRepository(conn driver.PostgresPool, cache *ember.Ember, logger *zap.Logger, opts ...driver.RepositoryOption) Repository {
+	return NewRepositoryWithQuerier(sqlc.New(conn), cache, logger, opts...)
 }
 
-func NewRepository(conn driver.PostgresPool, cache *ember.Ember, logger *zap.Logger) Repository {
+// NewRepositoryWithQuerier 以注入的 sqlc.Querier 建立 repository，交易中的查詢見 driver.TxBinder
+func NewRepositoryWithQuerier(querier sqlc.Querier, cache *ember.Ember, logger *zap.Logger, opts ...driver.RepositoryOption) Repository {
 	return &repository{
-		conn:   conn,
-		cache:  cache,
-		logger: logger,
+		queries: driver.NewQueries(querier),
+		cache:   cache,
+		shadow:  driver.NewRepositoryOptions(opts...).CacheShadow,
+		logger:  logger,
 	}
 }
 
+// Create 新增分類，並將產生的 ID 與時間寫回 category
 func (r *repository) Create(ctx context.Context, tx pgx.Tx, category *models.Category) error {
-	err := sqlc.New(r.conn).WithTx(tx).CreateCategory(ctx, sqlc.CreateCategoryParams{
-		Name: category.Name,
+	var description *string
+	if category.Description != "" {
+		description = &category.Description
+	}
+
+	row, err := r.queries.WithTx(tx).CreateCategory(ctx, sqlc.CreateCategoryParams{
+		Name:        category.Name,
+		Slug:        category.Slug,
+		Description: description,
+		ParentID:    categoryParentID(category.ParentID),
 	})
 	if err != nil {
 		r.logger.Error("Failed to create category", zap.Error(err))
 		return err
 	}
 
+	category.ID = uint64(row.ID)
+	category.CreatedAt = row.CreatedAt.Time
+	category.UpdatedAt = row.UpdatedAt.Time
+
 	// 更新快取
 	cacheKey := fmt.Sprintf("category:%d", category.ID)
 	if err := r.cache.Set(ctx, cacheKey, category, 30*time.Minute); err != nil {
 		r.logger.Warn("Failed to cache category", zap.Error(err))
 	}
 
+	// 上層分類的子分類列表已過時
+	if category.ParentID != nil {
+		return r.invalidateCache(ctx, tx, fmt.Sprintf("subcategories:%d", *category.ParentID))
+	}
 	return nil
 }
 
@@ -68,10 +116,17 @@ func (r *repository) GetByID(ctx context.Context, tx pgx.Tx, id uint64) (*models
 		r.logger.Warn("Failed to get category from cache", zap.Error(err))
 	}
 	if found {
+		r.shadow.Verify(ctx, cacheKey, &category, func(ctx context.Context) (any, error) {
+			sqlcCategory, err := r.queries.Querier().GetCategoryByID(ctx, int32(id))
+			if err != nil {
+				return nil, err
+			}
+			return new(models.Category).ConvertSqlcCategory(sqlcCategory), nil
+		})
 		return &category, nil
 	}
 
-	sqlcCategory, err := sqlc.New(r.conn).WithTx(tx).GetCategoryByID(ctx, int32(id))
+	sqlcCategory, err := r.queries.WithTx(tx).GetCategoryByID(ctx, int32(id))
 	if err != nil {
 		r.logger.Error("Failed to get category", zap.Error(err))
 		return nil, err
@@ -88,46 +143,43 @@ func (r *repository) GetByID(ctx context.Context, tx pgx.Tx, id uint64) (*models
 }
 
 func (r *repository) Update(ctx context.Context, tx pgx.Tx, category *models.Category) error {
-	var parentID int32
-	if category.ParentID != nil {
-		parentID = int32(*category.ParentID)
-	}
-
-	err := sqlc.New(r.conn).WithTx(tx).UpdateCategory(ctx, sqlc.UpdateCategoryParams{
+	rows, err := r.queries.WithTx(tx).UpdateCategory(ctx, sqlc.UpdateCategoryParams{
 		ID:          int32(category.ID),
 		Name:        category.Name,
+		Slug:        category.Slug,
 		Description: &category.Description,
-		ParentID:    &parentID,
+		ParentID:    categoryParentID(category.ParentID),
 		UpdatedAt:   pgtype.Timestamptz{Time: category.UpdatedAt, Valid: true},
 	})
 	if err != nil {
 		r.logger.Error("Failed to update category", zap.Error(err))
 		return err
 	}
+	if rows == 0 {
+		r.logger.Warn("Category update conflicted with a concurrent change", zap.Uint64("category_id", category.ID))
+		return ErrStaleCategory
+	}
 
-	// 更新快取
-	cacheKey := fmt.Sprintf("category:%d", category.ID)
-	if err := r.cache.Set(ctx, cacheKey, category, 30*time.Minute); err != nil {
-		r.logger.Warn("Failed to update category in cache", zap.Error(err))
+	// updated_at 由資料庫設定，刪除快取讓下次讀取取得新的 updated_at，並通知其他實例刪除舊的分類與上層分類的子分類列表
+	if err := r.invalidateCache(ctx, tx, fmt.Sprintf("category:%d", category.ID)); err != nil {
+		return err
 	}
 
+	if category.ParentID != nil {
+		return r.invalidateCache(ctx, tx, fmt.Sprintf("subcategories:%d", *category.ParentID))
+	}
 	return nil
 }
 
 func (r *repository) Delete(ctx context.Context, tx pgx.Tx, id uint64) error {
-	err := sqlc.New(r.conn).WithTx(tx).DeleteCategory(ctx, int32(id))
+	err := r.queries.WithTx(tx).DeleteCategory(ctx, int32(id))
 	if err != nil {
 		r.logger.Error("Failed to delete category", zap.Error(err))
 		return err
 	}
 
 	// 從快取中刪除
-	cacheKey := fmt.Sprintf("category:%d", id)
-	if err := r.cache.Delete(ctx, cacheKey); err != nil {
-		r.logger.Warn("Failed to delete category from cache", zap.Error(err))
-	}
-
-	return nil
+	return r.invalidateCategoryCache(ctx, tx, id)
 }
 
 func (r *repository) List(ctx context.Context, tx pgx.Tx, limit, offset uint64) ([]*models.Category, error) {
@@ -139,14 +191,26 @@ func (r *repository) List(ctx context.Context, tx pgx.Tx, limit, offset uint64)
 	if err != nil {
 		r.logger.Warn("Failed to get categories from cache", zap.Error(err))
 	}
+	params := sqlc.ListCategoriesParams{
+		Limit:  int64(limit),
+		Offset: int64(offset),
+	}
 	if found {
+		r.shadow.Verify(ctx, cacheKey, &categories, func(ctx context.Context) (any, error) {
+			sqlcCategories, err := r.queries.Querier().ListCategories(ctx, params)
+			if err != nil {
+				return nil, err
+			}
+			results := make([]*models.Category, 0, len(sqlcCategories))
+			for _, sqlcCategory := range sqlcCategories {
+				results = append(results, new(models.Category).ConvertSqlcCategory(sqlcCategory))
+			}
+			return results, nil
+		})
 		return categories, nil
 	}
 
-	sqlcCategories, err := sqlc.New(r.conn).WithTx(tx).ListCategories(ctx, sqlc.ListCategoriesParams{
-		Limit:  int64(limit),
-		Offset: int64(offset),
-	})
+	sqlcCategories, err := r.queries.WithTx(tx).ListCategories(ctx, params)
 	if err != nil {
 		r.logger.Error("Failed to list categories", zap.Error(err))
 		return nil, err
@@ -165,6 +229,33 @@ func (r *repository) List(ctx context.Context, tx pgx.Tx, limit, offset uint64)
 	return categories, nil
 }
 
+// Count 計算分類總數，用於分頁
+func (r *repository) Count(ctx context.Context, tx pgx.Tx) (uint64, error) {
+	count, err := r.queries.WithTx(tx).CountCategories(ctx)
+	if err != nil {
+		r.logger.Error("Failed to count categories", zap.Error(err))
+		return 0, err
+	}
+
+	return uint64(count), nil
+}
+
+// ListAll 列出所有分類（不分頁、不經過快取），用於建立分類樹與匯入比對
+func (r *repository) ListAll(ctx context.Context, tx pgx.Tx) ([]*models.Category, error) {
+	sqlcCategories, err := r.queries.WithTx(tx).ListAllCategories(ctx)
+	if err != nil {
+		r.logger.Error("Failed to list all categories", zap.Error(err))
+		return nil, err
+	}
+
+	categories := make([]*models.Category, 0, len(sqlcCategories))
+	for _, sqlcCategory := range sqlcCategories {
+		categories = append(categories, new(models.Category).ConvertSqlcCategory(sqlcCategory))
+	}
+
+	return categories, nil
+}
+
 func (r *repository) ListSubcategories(ctx context.Context, tx pgx.Tx, parentID uint64) ([]*models.Category, error) {
 	cacheKey := fmt.Sprintf("subcategories:%d", parentID)
 	var categories []*models.Category
@@ -174,12 +265,23 @@ func (r *repository) ListSubcategories(ctx context.Context, tx pgx.Tx, parentID
 	if err != nil {
 		r.logger.Warn("Failed to get subcategories from cache", zap.Error(err))
 	}
+	categoryParentID := int32(parentID)
 	if found {
+		r.shadow.Verify(ctx, cacheKey, &categories, func(ctx context.Context) (any, error) {
+			sqlcCategories, err := r.queries.Querier().ListSubcategories(ctx, &categoryParentID)
+			if err != nil {
+				return nil, err
+			}
+			results := make([]*models.Category, 0, len(sqlcCategories))
+			for _, sqlcCategory := range sqlcCategories {
+				results = append(results, new(models.Category).ConvertSqlcCategory(sqlcCategory))
+			}
+			return results, nil
+		})
 		return categories, nil
 	}
 
-	categoryParentID := int32(parentID)
-	sqlcCategories, err := sqlc.New(r.conn).WithTx(tx).ListSubcategories(ctx, &categoryParentID)
+	sqlcCategories, err := r.queries.WithTx(tx).ListSubcategories(ctx, &categoryParentID)
 	if err != nil {
 		r.logger.Error("Failed to list subcategories", zap.Error(err))
 		return nil, err
@@ -199,7 +301,7 @@ func (r *repository) ListSubcategories(ctx context.Context, tx pgx.Tx, parentID
 }
 
 func (r *repository) AssignProductToCategory(ctx context.Context, tx pgx.Tx, productID string, categoryID uint64) error {
-	err := sqlc.New(r.conn).WithTx(tx).AssignProductToCategory(ctx, sqlc.AssignProductToCategoryParams{
+	err := r.queries.WithTx(tx).AssignProductToCategory(ctx, sqlc.AssignProductToCategoryParams{
 		ProductID:  productID,
 		CategoryID: int32(categoryID),
 	})
@@ -209,12 +311,11 @@ func (r *repository) AssignProductToCategory(ctx context.Context, tx pgx.Tx, pro
 	}
 
 	// 使相關的快取失效
-	r.invalidateCategoryCache(ctx, categoryID)
-	return nil
+	return r.invalidateCategoryCache(ctx, tx, categoryID, productID)
 }
 
 func (r *repository) RemoveProductFromCategory(ctx context.Context, tx pgx.Tx, productID string, categoryID uint64) error {
-	err := sqlc.New(r.conn).WithTx(tx).RemoveProductFromCategory(ctx, sqlc.RemoveProductFromCategoryParams{
+	err := r.queries.WithTx(tx).RemoveProductFromCategory(ctx, sqlc.RemoveProductFromCategoryParams{
 		ProductID:  productID,
 		CategoryID: int32(categoryID),
 	})
@@ -223,18 +324,333 @@ func (r *repository) RemoveProductFromCategory(ctx context.Context, tx pgx.Tx, p
 		return err
 	}
 
-	r.invalidateCategoryCache(ctx, categoryID)
+	return r.invalidateCategoryCache(ctx, tx, categoryID, productID)
+}
+
+// ListProductsInCategories 回傳 productIDs 中屬於 categoryIDs 任一分類（包含其所有子分類）的商品
+func (r *repository) ListProductsInCategories(ctx context.Context, tx pgx.Tx, productIDs []string, categoryIDs []uint64) ([]string, error) {
+	if len(productIDs) == 0 || len(categoryIDs) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]int32, len(categoryIDs))
+	for i, id := range categoryIDs {
+		ids[i] = int32(id)
+	}
+
+	matched, err := r.queries.WithTx(tx).ListProductsInCategories(ctx, sqlc.ListProductsInCategoriesParams{
+		CategoryIds: ids,
+		ProductIds:  productIDs,
+	})
+	if err != nil {
+		r.logger.Error("Failed to list products in categories", zap.Error(err))
+		return nil, err
+	}
+
+	return matched, nil
+}
+
+// UpsertCategoryTranslation 新增或更新分類在指定語系的翻譯，並將更新時間寫回 translation
+func (r *repository) UpsertCategoryTranslation(ctx context.Context, tx pgx.Tx, translation *models.CategoryTranslation) error {
+	row, err := r.queries.WithTx(tx).UpsertCategoryTranslation(ctx, sqlc.UpsertCategoryTranslationParams{
+		CategoryID:  int32(translation.CategoryID),
+		Locale:      translation.Locale,
+		Name:        translation.Name,
+		Description: translationDescription(translation.Description),
+	})
+	if err != nil {
+		r.logger.Error("Failed to upsert category translation", zap.Uint64("category_id", translation.CategoryID), zap.String("locale", translation.Locale), zap.Error(err))
+		return err
+	}
+
+	translation.UpdatedAt = row.UpdatedAt.Time
+	return nil
+}
+
+// DeleteCategoryTranslation 刪除分類在指定語系的翻譯，回傳 false 表示翻譯不存在
+func (r *repository) DeleteCategoryTranslation(ctx context.Context, tx pgx.Tx, categoryID uint64, locale string) (bool, error) {
+	rows, err := r.queries.WithTx(tx).DeleteCategoryTranslation(ctx, sqlc.DeleteCategoryTranslationParams{
+		CategoryID: int32(categoryID),
+		Locale:     locale,
+	})
+	if err != nil {
+		r.logger.Error("Failed to delete category translation", zap.Uint64("category_id", categoryID), zap.String("locale", locale), zap.Error(err))
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+// ListCategoryTranslations 取得多個分類在 locales 中各語系的翻譯
+func (r *repository) ListCategoryTranslations(ctx context.Context, tx pgx.Tx, categoryIDs []uint64, locales []string) ([]*models.CategoryTranslation, error) {
+	if len(categoryIDs) == 0 || len(locales) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]int32, len(categoryIDs))
+	for i, id := range categoryIDs {
+		ids[i] = int32(id)
+	}
+
+	rows, err := r.queries.WithTx(tx).ListCategoryTranslations(ctx, sqlc.ListCategoryTranslationsParams{
+		CategoryIds: ids,
+		Locales:     locales,
+	})
+	if err != nil {
+		r.logger.Error("Failed to list category translations", zap.Error(err))
+		return nil, err
+	}
+
+	translations := make([]*models.CategoryTranslation, 0, len(rows))
+	for _, row := range rows {
+		translations = append(translations, new(models.CategoryTranslation).ConvertSqlcCategoryTranslation(row))
+	}
+
+	return translations, nil
+}
+
+// UpsertProductTranslation 新增或更新商品在指定語系的翻譯，並將更新時間寫回 translation
+func (r *repository) UpsertProductTranslation(ctx context.Context, tx pgx.Tx, translation *models.ProductTranslation) error {
+	row, err := r.queries.WithTx(tx).UpsertProductTranslation(ctx, sqlc.UpsertProductTranslationParams{
+		ProductID:   translation.ProductID,
+		Locale:      translation.Locale,
+		Name:        translation.Name,
+		Description: translationDescription(translation.Description),
+	})
+	if err != nil {
+		r.logger.Error("Failed to upsert product translation", zap.String("product_id", translation.ProductID), zap.String("locale", translation.Locale), zap.Error(err))
+		return err
+	}
+
+	translation.UpdatedAt = row.UpdatedAt.Time
+	return nil
+}
+
+// DeleteProductTranslation 刪除商品在指定語系的翻譯，回傳 false 表示翻譯不存在
+func (r *repository) DeleteProductTranslation(ctx context.Context, tx pgx.Tx, productID, locale string) (bool, error) {
+	rows, err := r.queries.WithTx(tx).DeleteProductTranslation(ctx, sqlc.DeleteProductTranslationParams{
+		ProductID: productID,
+		Locale:    locale,
+	})
+	if err != nil {
+		r.logger.Error("Failed to delete product translation", zap.String("product_id", productID), zap.String("locale", locale), zap.Error(err))
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+// ListProductTranslations 取得多個商品在 locales 中各語系的翻譯
+func (r *repository) ListProductTranslations(ctx context.Context, tx pgx.Tx, productIDs []string, locales []string) ([]*models.ProductTranslation, error) {
+	if len(productIDs) == 0 || len(locales) == 0 {
+		return nil, nil
+	}
+
+	rows, err := r.queries.WithTx(tx).ListProductTranslations(ctx, sqlc.ListProductTranslationsParams{
+		ProductIds: productIDs,
+		Locales:    locales,
+	})
+	if err != nil {
+		r.logger.Error("Failed to list product translations", zap.Error(err))
+		return nil, err
+	}
+
+	translations := make([]*models.ProductTranslation, 0, len(rows))
+	for _, row := range rows {
+		translations = append(translations, new(models.ProductTranslation).ConvertSqlcProductTranslation(row))
+	}
+
+	return translations, nil
+}
+
+// ListProductTranslationsAfter 依商品 ID 與語系排序，列出排在 (afterProductID, afterLocale) 之後的翻譯，最多 limit 筆
+func (r *repository) ListProductTranslationsAfter(ctx context.Context, tx pgx.Tx, afterProductID, afterLocale string, limit int32) ([]*models.ProductTranslation, error) {
+	rows, err := r.queries.WithTx(tx).ListProductTranslationsAfter(ctx, sqlc.ListProductTranslationsAfterParams{
+		AfterProductID: afterProductID,
+		AfterLocale:    afterLocale,
+		RowLimit:       limit,
+	})
+	if err != nil {
+		r.logger.Error("Failed to list product translations", zap.Error(err))
+		return nil, err
+	}
+
+	translations := make([]*models.ProductTranslation, 0, len(rows))
+	for _, row := range rows {
+		translations = append(translations, new(models.ProductTranslation).ConvertSqlcProductTranslation(row))
+	}
+
+	return translations, nil
+}
+
+// SearchProductIDs 列出名稱或描述符合 pattern（ILIKE）的商品 ID，locale 為空時搜尋所有語系
+func (r *repository) SearchProductIDs(ctx context.Context, tx pgx.Tx, locale, pattern string, limit, offset uint64) ([]string, error) {
+	ids, err := r.queries.WithTx(tx).SearchProductIDs(ctx, sqlc.SearchProductIDsParams{
+		Locale:    locale,
+		Pattern:   pattern,
+		RowLimit:  int32(limit),
+		RowOffset: int32(offset),
+	})
+	if err != nil {
+		r.logger.Error("Failed to search products", zap.Error(err))
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// CountSearchProducts 計算名稱或描述符合 pattern（ILIKE）的商品數
+func (r *repository) CountSearchProducts(ctx context.Context, tx pgx.Tx, locale, pattern string) (uint64, error) {
+	count, err := r.queries.WithTx(tx).CountSearchProducts(ctx, sqlc.CountSearchProductsParams{
+		Locale:  locale,
+		Pattern: pattern,
+	})
+	if err != nil {
+		r.logger.Error("Failed to count searched products", zap.Error(err))
+		return 0, err
+	}
+
+	return uint64(count), nil
+}
+
+// CreateProductMedia 新增商品媒體，並將產生的 ID 與時間寫回 media
+func (r *repository) CreateProductMedia(ctx context.Context, tx pgx.Tx, media *models.ProductMedia) error {
+	row, err := r.queries.WithTx(tx).CreateProductMedia(ctx, sqlc.CreateProductMediaParams{
+		ProductID:   media.ProductID,
+		PriceID:     nullableString(media.PriceID),
+		Type:        sqlc.ProductMediaType(media.Type),
+		Url:         media.URL,
+		StorageKey:  nullableString(media.StorageKey),
+		AltText:     nullableString(media.AltText),
+		Position:    int32(media.Position),
+		ContentType: nullableString(media.ContentType),
+	})
+	if err != nil {
+		r.logger.Error("Failed to create product media", zap.String("product_id", media.ProductID), zap.Error(err))
+		return err
+	}
+
+	media.ID = uint64(row.ID)
+	media.CreatedAt = row.CreatedAt.Time
+	media.UpdatedAt = row.UpdatedAt.Time
 	return nil
 }
 
-func (r *repository) invalidateCategoryCache(ctx context.Context, categoryID uint64) {
+// GetProductMedia 取得商品媒體，不存在時回傳 pgx.ErrNoRows
+func (r *repository) GetProductMedia(ctx context.Context, tx pgx.Tx, mediaID uint64) (*models.ProductMedia, error) {
+	row, err := r.queries.WithTx(tx).GetProductMedia(ctx, int32(mediaID))
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			r.logger.Error("Failed to get product media", zap.Uint64("media_id", mediaID), zap.Error(err))
+		}
+		return nil, err
+	}
+
+	return new(models.ProductMedia).ConvertSqlcProductMedia(row), nil
+}
+
+// UpdateProductMedia 更新商品媒體的變體、替代文字與排序，並將完整的資料寫回 media；不存在時回傳 pgx.ErrNoRows
+func (r *repository) UpdateProductMedia(ctx context.Context, tx pgx.Tx, media *models.ProductMedia) error {
+	row, err := r.queries.WithTx(tx).UpdateProductMedia(ctx, sqlc.UpdateProductMediaParams{
+		ID:       int32(media.ID),
+		PriceID:  nullableString(media.PriceID),
+		AltText:  nullableString(media.AltText),
+		Position: int32(media.Position),
+	})
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			r.logger.Error("Failed to update product media", zap.Uint64("media_id", media.ID), zap.Error(err))
+		}
+		return err
+	}
+
+	media.ConvertSqlcProductMedia(row)
+	return nil
+}
+
+// DeleteProductMedia 刪除商品媒體並回傳被刪除的資料，呼叫端依 StorageKey 刪除檔案；不存在時回傳 pgx.ErrNoRows
+func (r *repository) DeleteProductMedia(ctx context.Context, tx pgx.Tx, mediaID uint64) (*models.ProductMedia, error) {
+	row, err := r.queries.WithTx(tx).DeleteProductMedia(ctx, int32(mediaID))
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			r.logger.Error("Failed to delete product media", zap.Uint64("media_id", mediaID), zap.Error(err))
+		}
+		return nil, err
+	}
+
+	return new(models.ProductMedia).ConvertSqlcProductMedia(row), nil
+}
+
+// ListProductMedia 依排序列出商品的媒體；priceID 不為空時只列出共用的媒體與該變體的媒體
+func (r *repository) ListProductMedia(ctx context.Context, tx pgx.Tx, productID, priceID string) ([]*models.ProductMedia, error) {
+	rows, err := r.queries.WithTx(tx).ListProductMedia(ctx, sqlc.ListProductMediaParams{
+		ProductID: productID,
+		PriceID:   nullableString(priceID),
+	})
+	if err != nil {
+		r.logger.Error("Failed to list product media", zap.String("product_id", productID), zap.Error(err))
+		return nil, err
+	}
+
+	media := make([]*models.ProductMedia, 0, len(rows))
+	for _, row := range rows {
+		media = append(media, new(models.ProductMedia).ConvertSqlcProductMedia(row))
+	}
+
+	return media, nil
+}
+
+// translationDescription 將空白的描述存為 NULL
+func translationDescription(description string) *string {
+	if description == "" {
+		return nil
+	}
+	return &description
+}
+
+// invalidateCategoryCache 使分類及其子分類列表的快取失效，productIDs 的商品目錄快照包含分類 slug，一併失效
+func (r *repository) invalidateCategoryCache(ctx context.Context, tx pgx.Tx, categoryID uint64, productIDs ...string) error {
 	cacheKeys := []string{
 		fmt.Sprintf("category:%d", categoryID),
 		fmt.Sprintf("subcategories:%d", categoryID),
 	}
+	for _, productID := range productIDs {
+		cacheKeys = append(cacheKeys, price.CatalogCacheKey(productID))
+	}
+	return r.invalidateCache(ctx, tx, cacheKeys...)
+}
+
+// invalidateCache 刪除本實例的快取，並通知其他實例一併刪除
+func (r *repository) invalidateCache(ctx context.Context, tx pgx.Tx, cacheKeys ...string) error {
 	for _, key := range cacheKeys {
 		if err := r.cache.Delete(ctx, key); err != nil {
 			r.logger.Warn("Failed to invalidate category cache", zap.Error(err), zap.String("key", key))
 		}
 	}
+	return r.notifyCacheInvalidation(ctx, tx, cacheKeys...)
+}
+
+// notifyCacheInvalidation 在交易內通知其他實例刪除快取，交易提交後才會送出
+func (r *repository) notifyCacheInvalidation(ctx context.Context, tx pgx.Tx, cacheKeys ...string) error {
+	if err := driver.NotifyCacheInvalidation(ctx, tx, cacheKeys...); err != nil {
+		r.logger.Error("Failed to notify category cache invalidation", zap.Strings("keys", cacheKeys), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func categoryParentID(parentID *uint64) *int32 {
+	if parentID == nil {
+		return nil
+	}
+	id := int32(*parentID)
+	return &id
+}
+
+// nullableString 將空字串存為 NULL
+func nullableString(v string) *string {
+	if v == "" {
+		return nil
+	}
+	return &v
 }
@@ -0,0 +1,263 @@
+package shop
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/jackc/pgx/v5"
+
+	"gofalre.io/shop/category"
+	"gofalre.io/shop/models"
+	"gofalre.io/shop/models/enum"
+)
+
+// categoryTreeDocumentVersion 目前的分類樹匯入匯出格式版本
+const categoryTreeDocumentVersion = 1
+
+// ErrInvalidCategoryTree 表示匯入的分類樹文件內容不合法
+var ErrInvalidCategoryTree = errors.New("invalid category tree document")
+
+// ExportCategoryTree 將完整的分類樹以 JSON 寫入 w，節點以 slug 識別以便匯入其他環境
+func (s *service) ExportCategoryTree(ctx context.Context, w io.Writer) error {
+	tree, err := s.GetCategoryTree(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get category tree: %w", err)
+	}
+
+	document := models.CategoryTreeDocument{
+		Version:    categoryTreeDocumentVersion,
+		ExportedAt: time.Now(),
+		Categories: categoryNodes(tree),
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(document); err != nil {
+		return fmt.Errorf("failed to encode category tree: %w", err)
+	}
+
+	return nil
+}
+
+// ImportCategoryTree 從 r 讀取分類樹 JSON 並依 slug 比對現有分類；dryRun 時只驗證並回傳將會執行的變更
+func (s *service) ImportCategoryTree(ctx context.Context, r io.Reader, mode enum.CategoryImportMode, dryRun bool) (*models.CategoryImportResult, error) {
+	if mode != enum.CategoryImportModeMerge && mode != enum.CategoryImportModeReplace {
+		return nil, fmt.Errorf("unsupported category import mode: %s", mode)
+	}
+
+	// 1. 解析並驗證文件
+	var document models.CategoryTreeDocument
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&document); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCategoryTree, err)
+	}
+	if document.Version != categoryTreeDocumentVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidCategoryTree, document.Version)
+	}
+	if err := validateCategoryNodes(document.Categories, make(map[string]bool)); err != nil {
+		return nil, err
+	}
+
+	result := &models.CategoryImportResult{
+		DryRun:    dryRun,
+		Created:   []string{},
+		Updated:   []string{},
+		Deleted:   []string{},
+		Unchanged: []string{},
+		Stale:     []string{},
+	}
+
+	err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		// 2. 讀取現有分類並以 slug 建立索引
+		existing, err := s.category.ListAll(ctx, tx)
+		if err != nil {
+			return fmt.Errorf("failed to list categories: %w", err)
+		}
+		bySlug := make(map[string]*models.Category, len(existing))
+		for _, category := range existing {
+			bySlug[category.Slug] = category
+		}
+
+		// 3. 由上而下新增或更新分類，父分類一律先於子分類處理
+		imported := make(map[string]bool)
+		var apply func(nodes []*models.CategoryNode, parentID *uint64) error
+		apply = func(nodes []*models.CategoryNode, parentID *uint64) error {
+			for _, node := range nodes {
+				imported[node.Slug] = true
+
+				current, found := bySlug[node.Slug]
+				switch {
+				case !found:
+					current = &models.Category{
+						Slug:        node.Slug,
+						Name:        node.Name,
+						Description: node.Description,
+						ParentID:    parentID,
+					}
+					if !dryRun {
+						if err := s.category.Create(ctx, tx, current); err != nil {
+							return fmt.Errorf("failed to create category %s: %w", node.Slug, err)
+						}
+					}
+					result.Created = append(result.Created, node.Slug)
+				case current.Name != node.Name || current.Description != node.Description || !sameCategoryParent(current.ParentID, parentID):
+					current.Name = node.Name
+					current.Description = node.Description
+					current.ParentID = parentID
+					if !dryRun {
+						// 以讀取時的 updated_at 更新，讀取後被其他交易修改的分類不覆寫，列在 Stale 中
+						err := s.category.Update(ctx, tx, current)
+						if errors.Is(err, category.ErrStaleCategory) {
+							result.Stale = append(result.Stale, node.Slug)
+							break
+						}
+						if err != nil {
+							return fmt.Errorf("failed to update category %s: %w", node.Slug, err)
+						}
+					}
+					result.Updated = append(result.Updated, node.Slug)
+				default:
+					result.Unchanged = append(result.Unchanged, node.Slug)
+				}
+
+				// dry-run 時新分類的 ID 為 0，其下既有子分類的父分類比對仍會視為變更
+				if err := apply(node.Children, &current.ID); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		if err := apply(document.Categories, nil); err != nil {
+			return err
+		}
+
+		// 4. 取代模式下刪除文件中不存在的分類，子分類先於父分類刪除
+		if mode == enum.CategoryImportModeReplace {
+			var removed []*models.Category
+			for _, category := range existing {
+				if !imported[category.Slug] {
+					removed = append(removed, category)
+				}
+			}
+			depths := categoryDepths(existing)
+			sort.SliceStable(removed, func(i, j int) bool {
+				return depths[removed[i].ID] > depths[removed[j].ID]
+			})
+			for _, category := range removed {
+				if !dryRun {
+					if err := s.category.Delete(ctx, tx, category.ID); err != nil {
+						return fmt.Errorf("failed to delete category %s: %w", category.Slug, err)
+					}
+				}
+				result.Deleted = append(result.Deleted, category.Slug)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// categoryNodes 將分類樹轉換為匯出用的節點
+func categoryNodes(tree []*models.CategoryTree) []*models.CategoryNode {
+	nodes := make([]*models.CategoryNode, 0, len(tree))
+	for _, branch := range tree {
+		nodes = append(nodes, &models.CategoryNode{
+			Slug:        branch.Slug,
+			Name:        branch.Name,
+			Description: branch.Description,
+			Children:    categoryNodes(branch.Children),
+		})
+	}
+	return nodes
+}
+
+// validateCategoryNodes 檢查每個節點都有名稱與合法的 slug，且 slug 在整份文件中不重複
+func validateCategoryNodes(nodes []*models.CategoryNode, seen map[string]bool) error {
+	for _, node := range nodes {
+		if node == nil {
+			return fmt.Errorf("%w: empty category node", ErrInvalidCategoryTree)
+		}
+		if strings.TrimSpace(node.Name) == "" {
+			return fmt.Errorf("%w: category %q has no name", ErrInvalidCategoryTree, node.Slug)
+		}
+		if node.Slug == "" || slugify(node.Slug) != node.Slug {
+			return fmt.Errorf("%w: category %q has invalid slug %q", ErrInvalidCategoryTree, node.Name, node.Slug)
+		}
+		if seen[node.Slug] {
+			return fmt.Errorf("%w: duplicate slug %q", ErrInvalidCategoryTree, node.Slug)
+		}
+		seen[node.Slug] = true
+
+		if err := validateCategoryNodes(node.Children, seen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// categoryDepths 計算每個分類在樹中的深度，根分類為 0
+func categoryDepths(categories []*models.Category) map[uint64]int {
+	parents := make(map[uint64]*uint64, len(categories))
+	for _, category := range categories {
+		parents[category.ID] = category.ParentID
+	}
+
+	depths := make(map[uint64]int, len(categories))
+	for _, category := range categories {
+		depth := 0
+		for parentID := category.ParentID; parentID != nil && depth <= len(categories); parentID = parents[*parentID] {
+			depth++
+		}
+		depths[category.ID] = depth
+	}
+	return depths
+}
+
+func sameCategoryParent(a, b *uint64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// normalizeCategorySlug 在 slug 為空時由名稱產生，並檢查 slug 只含小寫英數字與連字號
+func normalizeCategorySlug(category *models.Category) error {
+	if category.Slug == "" {
+		category.Slug = slugify(category.Name)
+	}
+	if category.Slug == "" || slugify(category.Slug) != category.Slug {
+		return fmt.Errorf("invalid category slug %q", category.Slug)
+	}
+	return nil
+}
+
+// slugify 將名稱轉換為小寫英數字與連字號組成的 slug
+func slugify(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
@@ -0,0 +1,163 @@
+package shop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stripe/stripe-go/v79"
+
+	"gofalre.io/shop/models"
+)
+
+// maxChannelLength 為銷售通路名稱的最大長度
+const maxChannelLength = 64
+
+// ErrProductNotInChannel 表示商品沒有上架到購物車的銷售通路，或在該通路暫停供貨
+var ErrProductNotInChannel = errors.New("product is not available in channel")
+
+// PublishProductToChannel 將商品上架到銷售通路，available 為 false 時商品保留在通路目錄中但暫停供貨
+func (s *service) PublishProductToChannel(ctx context.Context, channel, productID string, available bool) error {
+	if err := validateChannel(channel); err != nil {
+		return err
+	}
+	if productID == "" {
+		return errors.New("product ID is required")
+	}
+
+	if err := s.price.SetChannelProduct(ctx, nil, channel, productID, available); err != nil {
+		return fmt.Errorf("failed to set channel product: %w", err)
+	}
+	return nil
+}
+
+// UnpublishProductFromChannel 將商品從銷售通路下架，已在購物車中的商品會在結帳檢查時被阻擋
+func (s *service) UnpublishProductFromChannel(ctx context.Context, channel, productID string) error {
+	removed, err := s.price.RemoveChannelProduct(ctx, nil, channel, productID)
+	if err != nil {
+		return fmt.Errorf("failed to remove channel product: %w", err)
+	}
+	if !removed {
+		return fmt.Errorf("%w: product %s is not in channel %q", ErrProductNotInChannel, productID, channel)
+	}
+	return nil
+}
+
+// ListChannelProducts 列出上架到銷售通路的商品
+func (s *service) ListChannelProducts(ctx context.Context, channel string) ([]*models.ChannelProduct, error) {
+	products, err := s.price.ListChannelProducts(ctx, nil, channel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list channel products: %w", err)
+	}
+	return products, nil
+}
+
+// SetChannelPrice 設定價格在銷售通路與幣別下的單價，單價依幣別的尾數規則調整；該通路的購物車加入商品時以此單價計算
+func (s *service) SetChannelPrice(ctx context.Context, channel, priceID string, currency stripe.Currency, unitPrice float64) error {
+	if err := validateChannel(channel); err != nil {
+		return err
+	}
+	if priceID == "" {
+		return errors.New("price ID is required")
+	}
+	if currency == "" {
+		return errors.New("currency is required")
+	}
+	if unitPrice < 0 {
+		return errors.New("unit price must not be negative")
+	}
+
+	if err := s.price.SetChannelPrice(ctx, nil, channel, priceID, currency, s.RoundPrice(ctx, unitPrice, currency)); err != nil {
+		return fmt.Errorf("failed to set channel price: %w", err)
+	}
+	return nil
+}
+
+// RemoveChannelPrice 移除銷售通路的價格，之後該通路改以目前生效的價格計算
+func (s *service) RemoveChannelPrice(ctx context.Context, channel, priceID string, currency stripe.Currency) error {
+	removed, err := s.price.RemoveChannelPrice(ctx, nil, channel, priceID, currency)
+	if err != nil {
+		return fmt.Errorf("failed to remove channel price: %w", err)
+	}
+	if !removed {
+		return fmt.Errorf("price %s has no %s price in channel %q", priceID, currency, channel)
+	}
+	return nil
+}
+
+// ListChannelPrices 列出銷售通路的價格表
+func (s *service) ListChannelPrices(ctx context.Context, channel string) ([]*models.ChannelPrice, error) {
+	prices, err := s.price.ListChannelPrices(ctx, nil, channel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list channel prices: %w", err)
+	}
+	return prices, nil
+}
+
+// SetCartChannel 設定購物車的銷售通路，channel 為空字串時清除；之後加入的商品以該通路的目錄與價格表計算，
+// 已在購物車中的商品會在結帳檢查時比對；購物車已非 active 時回傳包裝後的 ErrCartNotActive
+func (s *service) SetCartChannel(ctx context.Context, cartID uint64, channel string) error {
+	if channel != "" {
+		if err := validateChannel(channel); err != nil {
+			return err
+		}
+	}
+
+	return s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		updated, err := s.cart.SetCartChannel(ctx, tx, cartID, channel)
+		if err != nil {
+			return fmt.Errorf("failed to set cart channel: %w", err)
+		}
+		if !updated {
+			return fmt.Errorf("%w: cart %d", ErrCartNotActive, cartID)
+		}
+		return s.touchCart(ctx, tx, cartID)
+	})
+}
+
+// channelUnitPrice 確認商品已上架到購物車的銷售通路且可供貨，未上架或暫停供貨時回傳包裝後的 ErrProductNotInChannel；
+// 通路價格表有該價格在購物車幣別的單價時 ok 為 true
+func (s *service) channelUnitPrice(ctx context.Context, tx pgx.Tx, cartModel *models.Cart, productID, priceID string) (unitPrice float64, ok bool, err error) {
+	channelProduct, err := s.price.GetChannelProduct(ctx, tx, cartModel.Channel, productID)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return 0, false, fmt.Errorf("%w: product %s is not in channel %q", ErrProductNotInChannel, productID, cartModel.Channel)
+	case err != nil:
+		return 0, false, fmt.Errorf("failed to get channel product %s: %w", productID, err)
+	case !channelProduct.Available:
+		return 0, false, fmt.Errorf("%w: product %s is unavailable in channel %q", ErrProductNotInChannel, productID, cartModel.Channel)
+	}
+
+	channelPrice, err := s.price.GetChannelPrice(ctx, tx, cartModel.Channel, priceID, cartModel.Currency)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return 0, false, nil
+	case err != nil:
+		return 0, false, fmt.Errorf("failed to get channel price %s: %w", priceID, err)
+	}
+
+	return s.RoundPrice(ctx, channelPrice.UnitPrice, cartModel.Currency), true, nil
+}
+
+// carryCartChannel 將原購物車的銷售通路帶到改用的新購物車，新購物車已有通路時保留
+func (s *service) carryCartChannel(ctx context.Context, tx pgx.Tx, cartModel *models.Cart, channel string) error {
+	if channel == "" || cartModel.Channel != "" {
+		return nil
+	}
+	if _, err := s.cart.SetCartChannel(ctx, tx, cartModel.ID, channel); err != nil {
+		return fmt.Errorf("failed to set cart channel: %w", err)
+	}
+	cartModel.Channel = channel
+	return nil
+}
+
+func validateChannel(channel string) error {
+	if channel == "" {
+		return errors.New("channel is required")
+	}
+	if len(channel) > maxChannelLength {
+		return fmt.Errorf("channel exceeds %d characters", maxChannelLength)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package shop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ErrCheckoutSaturated 表示結帳流量已達上限，呼叫端應稍後重試
+var ErrCheckoutSaturated = errors.New("checkout capacity is saturated")
+
+// CheckoutSaturatedError 結帳流量達上限時回傳的錯誤，RetryAfter 為建議的重試等待時間，
+// 可用 errors.Is(err, ErrCheckoutSaturated) 判斷並以 errors.As 取得等待時間
+type CheckoutSaturatedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *CheckoutSaturatedError) Error() string {
+	return fmt.Sprintf("%s, retry after %s", ErrCheckoutSaturated, e.RetryAfter)
+}
+
+func (e *CheckoutSaturatedError) Is(target error) bool {
+	return target == ErrCheckoutSaturated
+}
+
+// CheckoutGate 限制全域的結帳流量，例如 driver.RedisTokenBucket；
+// Take 取得名額時回傳 true，否則回傳 false 與建議的重試等待時間
+type CheckoutGate interface {
+	Take(ctx context.Context) (bool, time.Duration, error)
+}
+
+// WithCheckoutGate 設定結帳流量的上限，未設定時不限制；限時搶購時用來保護資料庫
+func WithCheckoutGate(gate CheckoutGate) Option {
+	return func(s *service) {
+		s.checkoutGate = gate
+	}
+}
+
+// enterCheckout 在建立訂單前向 CheckoutGate 取得名額；
+// gate 本身無法使用時（例如 Redis 中斷）記錄警告後放行，避免限流元件故障造成無法結帳
+func (s *service) enterCheckout(ctx context.Context) error {
+	if s.checkoutGate == nil {
+		return nil
+	}
+
+	ok, retryAfter, err := s.checkoutGate.Take(ctx)
+	if err != nil {
+		s.log(ctx).Warn("Checkout gate unavailable, allowing checkout", zap.Error(err))
+		return nil
+	}
+	if !ok {
+		s.log(ctx).Info("Checkout rejected by capacity gate", zap.Duration("retry_after", retryAfter))
+		return &CheckoutSaturatedError{RetryAfter: retryAfter}
+	}
+
+	return nil
+}
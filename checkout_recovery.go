@@ -0,0 +1,213 @@
+package shop
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+
+	"gofalre.io/shop/models"
+	"gofalre.io/shop/models/enum"
+	"gofalre.io/shop/stock"
+)
+
+const (
+	// checkoutRecoveryMetadataKey 記錄付款失敗後採用的處理方式
+	checkoutRecoveryMetadataKey = "checkout_recovery"
+	// recoveredCartMetadataKey 記錄付款失敗後恢復或建立的購物車 ID
+	recoveredCartMetadataKey = "recovered_cart_id"
+)
+
+// WithCheckoutRecoveryPolicy 設定結帳付款失敗後的處理方式，未設定時訂單直接標記為失敗
+func WithCheckoutRecoveryPolicy(policy enum.CheckoutRecoveryPolicy) Option {
+	return func(s *service) {
+		if policy != "" {
+			s.checkoutRecovery = policy
+		}
+	}
+}
+
+// recoverFailedCheckout 依設定的處理方式讓付款失敗的訂單可以繼續結帳；
+// 訂單已不是待付款狀態時代表事件重複或已被其他流程處理，只合併 metadata
+func (s *service) recoverFailedCheckout(ctx context.Context, tx pgx.Tx, order *models.Order, metadata map[string]string) error {
+	if order.Status != enum.OrderStatusPending {
+		s.log(ctx).Info("Skipping checkout recovery for non-pending order",
+			zap.Uint64("order_id", order.ID), zap.String("status", string(order.Status)))
+		return s.mergeStripeMetadata(ctx, tx, order.ID, metadata)
+	}
+
+	recovery := map[string]string{checkoutRecoveryMetadataKey: string(s.checkoutRecovery)}
+	for key, value := range metadata {
+		recovery[key] = value
+	}
+
+	// 1. 重新付款：訂單保持待付款，庫存維持扣除，Stripe 會讓同一筆 PaymentIntent 接受新的付款方式
+	if s.checkoutRecovery == enum.CheckoutRecoveryPolicyRetryPayment {
+		return s.mergeStripeMetadata(ctx, tx, order.ID, recovery)
+	}
+
+	// 2. 訂單標記為失敗
+	if err := s.order.UpdateOrderStatus(ctx, tx, order.ID, enum.OrderStatusFailed, order.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to update order status: %w", err)
+	}
+
+	// 3. 取得訂單項目，鎖定訂單時不會一併載入
+	items, err := s.order.ListOrderItems(ctx, tx, order.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list order items: %w", err)
+	}
+
+	// 4. 恢復原購物車，無法恢復或設定為複製時以訂單項目建立新的購物車
+	cartID, err := s.recoverCheckoutCart(ctx, tx, order, items)
+	if err != nil {
+		return err
+	}
+
+	// 5. 將扣除的庫存加回並改為購物車的預留
+	if err = s.restoreReservedStock(ctx, tx, order.ID, cartID, items); err != nil {
+		return err
+	}
+
+	// 6. 重新計算購物車金額
+	if err = s.recalculateCartTotals(ctx, tx, cartID); err != nil {
+		return err
+	}
+
+	recovery[recoveredCartMetadataKey] = strconv.FormatUint(cartID, 10)
+	if err = s.mergeStripeMetadata(ctx, tx, order.ID, recovery); err != nil {
+		return err
+	}
+
+	s.log(ctx).Info("Checkout recovered after payment failure",
+		zap.Uint64("order_id", order.ID), zap.Uint64("cart_id", cartID), zap.String("policy", string(s.checkoutRecovery)))
+
+	return nil
+}
+
+// recoverCheckoutCart 回傳可以繼續結帳的購物車 ID
+func (s *service) recoverCheckoutCart(ctx context.Context, tx pgx.Tx, order *models.Order, items []*models.OrderItem) (uint64, error) {
+	expiresAt := time.Now().Add(s.cartTTL)
+
+	if s.checkoutRecovery == enum.CheckoutRecoveryPolicyRestoreCart && order.CartID != nil && *order.CartID != 0 {
+		restored, err := s.cart.ReactivateCart(ctx, tx, *order.CartID, expiresAt)
+		if err != nil {
+			return 0, fmt.Errorf("failed to reactivate cart: %w", err)
+		}
+		if restored {
+			return *order.CartID, nil
+		}
+		s.log(ctx).Warn("Cart cannot be reactivated, cloning order items into a new cart",
+			zap.Uint64("order_id", order.ID), zap.Uint64("cart_id", *order.CartID))
+	}
+
+	// 複製的購物車以訂單命名，避免與客戶現有的購物車同名；客戶沒有預設購物車時成為預設購物車
+	hasDefault, err := s.hasDefaultCart(ctx, tx, order.CustomerID)
+	if err != nil {
+		return 0, err
+	}
+	cartModel := &models.Cart{
+		CustomerID: order.CustomerID,
+		Name:       fmt.Sprintf("order-%d", order.ID),
+		IsDefault:  !hasDefault,
+		Currency:   order.Currency,
+		Status:     enum.CartStatusActive,
+		CreatedAt:  time.Now(),
+		ExpiresAt:  expiresAt,
+	}
+	if err = s.cart.CreateCart(ctx, tx, cartModel); err != nil {
+		return 0, fmt.Errorf("failed to create cart: %w", err)
+	}
+	if err = s.carryCartChannel(ctx, tx, cartModel, order.Channel); err != nil {
+		return 0, err
+	}
+	if err = s.assignExperiments(ctx, tx, cartModel); err != nil {
+		return 0, err
+	}
+
+	for _, item := range items {
+		if err := s.cart.AddCartItem(ctx, tx, cartModel.ID, &models.CartItem{
+			ProductID: item.ProductID,
+			PriceID:   item.PriceID,
+			StockID:   item.StockID,
+			Quantity:  item.Quantity,
+			UnitPrice: item.UnitPrice,
+			Subtotal:  item.Subtotal,
+			Location:  item.Location,
+
+			Customization: item.Customization,
+			Note:          item.Note,
+			Metadata:      item.Metadata,
+		}); err != nil {
+			return 0, fmt.Errorf("failed to add cart item %s: %w", item.ProductID, err)
+		}
+	}
+
+	if _, err := s.cart.SetCartAddresses(ctx, tx, cartModel.ID, &models.CartAddresses{
+		ShippingAddress: order.ShippingAddress,
+		BillingAddress:  order.BillingAddress,
+	}); err != nil {
+		return 0, fmt.Errorf("failed to set cart addresses: %w", err)
+	}
+
+	return cartModel.ID, nil
+}
+
+// restoreReservedStock 將訂單扣除的庫存加回並重新預留給購物車，
+// 以每個訂單項目的入庫與每個購物車項目的預留記錄變動，啟用事件溯源的庫存由投影套用
+func (s *service) restoreReservedStock(ctx context.Context, tx pgx.Tx, orderID, cartID uint64, items []*models.OrderItem) error {
+	cartItems, err := s.cart.ListCartItems(ctx, tx, cartID)
+	if err != nil {
+		return fmt.Errorf("failed to list cart items: %w", err)
+	}
+
+	restoreParams := make([]stock.RestoreReservedStockParams, 0, len(items))
+	moveParams := make([]stock.CreateStockMovementParams, 0, len(items)+len(cartItems))
+
+	for _, item := range items {
+		stockModel, err := s.stock.GetStock(ctx, tx, item.StockID)
+		if err != nil {
+			return fmt.Errorf("failed to get stock for item %s: %w", item.ProductID, err)
+		}
+
+		restoreParams = append(restoreParams, stock.RestoreReservedStockParams{
+			StockID:     item.StockID,
+			Quantity:    item.Quantity,
+			LastUpdated: stockModel.UpdatedAt,
+		})
+
+		moveParams = append(moveParams, stock.CreateStockMovementParams{
+			StockID:         item.StockID,
+			Quantity:        item.Quantity,
+			Type:            enum.StockMovementTypeIn,
+			ReferenceID:     orderID,
+			ReferenceType:   enum.StockMovementReferenceTypeOrder,
+			ReferenceItemID: item.ID,
+		})
+	}
+
+	// 購物車項目與訂單項目的內容相同，預留記錄對應到恢復後的購物車項目
+	for _, cartItem := range cartItems {
+		moveParams = append(moveParams, stock.CreateStockMovementParams{
+			StockID:         cartItem.StockID,
+			Quantity:        cartItem.Quantity,
+			Type:            enum.StockMovementTypeReserve,
+			ReferenceID:     cartID,
+			ReferenceType:   enum.StockMovementReferenceTypeCart,
+			ReferenceItemID: cartItem.ID,
+		})
+	}
+
+	// 同一庫存的訂單項目合併後一次加回
+	if err = s.stock.RestoreReservedStock(ctx, tx, restoreParams); err != nil {
+		return fmt.Errorf("failed to restore reserved stock: %w", err)
+	}
+
+	if err = s.stock.CreateStockMovements(ctx, tx, moveParams); err != nil {
+		return fmt.Errorf("failed to create stock movements: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,259 @@
+package shop
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stripe/stripe-go/v79"
+
+	"gofalre.io/shop/models"
+	"gofalre.io/shop/models/enum"
+)
+
+// ErrCartNotReadyForCheckout 表示結帳前檢查發現阻擋結帳的問題，詳細內容可透過 ValidateCartForCheckout 取得
+var ErrCartNotReadyForCheckout = errors.New("cart is not ready for checkout")
+
+// WithMinimumOrderValue 設定幣別的最低訂單金額（含稅、扣除折扣後），未設定的幣別不限制
+func WithMinimumOrderValue(currency stripe.Currency, amount float64) Option {
+	return func(s *service) {
+		if s.minimumOrderValues == nil {
+			s.minimumOrderValues = make(map[stripe.Currency]float64)
+		}
+		s.minimumOrderValues[currency] = amount
+	}
+}
+
+// SetCartAddresses 設定購物車的寄送與帳單地址，結帳時會複製到訂單；billingAddress 為空時與寄送地址相同
+func (s *service) SetCartAddresses(ctx context.Context, cartID uint64, shippingAddress, billingAddress json.RawMessage) error {
+	if !hasAddress(shippingAddress) {
+		return errors.New("shipping address is required")
+	}
+	if !hasAddress(billingAddress) {
+		billingAddress = shippingAddress
+	}
+	if !json.Valid(shippingAddress) || !json.Valid(billingAddress) {
+		return errors.New("address must be valid JSON")
+	}
+
+	return s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		ok, err := s.cart.SetCartAddresses(ctx, tx, cartID, &models.CartAddresses{
+			ShippingAddress: shippingAddress,
+			BillingAddress:  billingAddress,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to set cart addresses: %w", err)
+		}
+		if !ok {
+			return fmt.Errorf("%w: cart %d", ErrCartNotActive, cartID)
+		}
+		return nil
+	})
+}
+
+// ClearCartAddresses 清除購物車的寄送與帳單地址，清除後需重新填寫才能結帳
+func (s *service) ClearCartAddresses(ctx context.Context, cartID uint64) error {
+	return s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		ok, err := s.cart.ClearCartAddresses(ctx, tx, cartID)
+		if err != nil {
+			return fmt.Errorf("failed to clear cart addresses: %w", err)
+		}
+		if !ok {
+			return fmt.Errorf("%w: cart %d", ErrCartNotActive, cartID)
+		}
+		return nil
+	})
+}
+
+// ValidateCartForCheckout 執行結帳的所有前置檢查（庫存、價格、數量上限、地址與最低金額），
+// 回傳阻擋結帳的問題與提醒，不會修改購物車或預留庫存
+func (s *service) ValidateCartForCheckout(ctx context.Context, cartID uint64) (*models.CartValidation, error) {
+	var validation *models.CartValidation
+
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		// 1. 獲取購物車、項目與地址
+		cartModel, err := s.cart.GetCart(ctx, tx, cartID)
+		if err != nil {
+			return fmt.Errorf("failed to get cart: %w", err)
+		}
+		items, err := s.cart.ListCartItems(ctx, tx, cartID)
+		if err != nil {
+			return fmt.Errorf("failed to list cart items: %w", err)
+		}
+		addresses, err := s.cart.GetCartAddresses(ctx, tx, cartID)
+		if err != nil {
+			return fmt.Errorf("failed to get cart addresses: %w", err)
+		}
+
+		// 2. 以目前有效的優惠與稅率計算結帳金額
+		now := time.Now()
+		pricing, err := s.priceCartItems(ctx, tx, cartModel, items, now)
+		if err != nil {
+			return err
+		}
+
+		// 3. 執行檢查
+		validation, err = s.validateCart(ctx, tx, cartModel, items, addresses, pricing, now)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return validation, nil
+}
+
+// validateCart 檢查購物車是否可以結帳，結帳流程與 ValidateCartForCheckout 共用；pricing 為以 now 計算的金額
+func (s *service) validateCart(ctx context.Context, tx pgx.Tx, cartModel *models.Cart, items []*models.CartItem, addresses *models.CartAddresses, pricing *cartPricing, now time.Time) (*models.CartValidation, error) {
+	validation := &models.CartValidation{
+		CartID:     cartModel.ID,
+		Currency:   cartModel.Currency,
+		Subtotal:   pricing.Subtotal,
+		Tax:        pricing.Tax,
+		Discount:   pricing.Discount,
+		Total:      roundCurrency(pricing.Subtotal + pricing.Tax - pricing.Discount),
+		Promotions: pricing.Promotions,
+		CheckedAt:  now,
+	}
+
+	// 1. 購物車狀態
+	if cartModel.Status != enum.CartStatusActive {
+		validation.Block(&models.CartIssue{
+			Code:    enum.CartIssueCodeCartNotActive,
+			Message: fmt.Sprintf("cart is %s", cartModel.Status),
+		})
+		return validation, nil
+	}
+	if !cartModel.ExpiresAt.IsZero() && cartModel.ExpiresAt.Before(now) {
+		validation.Block(&models.CartIssue{
+			Code:    enum.CartIssueCodeCartExpired,
+			Message: fmt.Sprintf("cart expired at %s", cartModel.ExpiresAt.Format(time.RFC3339)),
+		})
+	}
+	if len(items) == 0 {
+		validation.Block(&models.CartIssue{
+			Code:    enum.CartIssueCodeCartEmpty,
+			Message: "cart is empty",
+		})
+	}
+
+	// 2. 逐項檢查庫存、數量上限與價格
+	for _, item := range items {
+		issue := func(code enum.CartIssueCode, format string, args ...any) *models.CartIssue {
+			return &models.CartIssue{
+				Code:       code,
+				Message:    fmt.Sprintf(format, args...),
+				CartItemID: item.ID,
+				ProductID:  item.ProductID,
+			}
+		}
+
+		stockIssue := func(code enum.CartIssueCode, available uint64, format string, args ...any) *models.CartIssue {
+			cartIssue := issue(code, format, args...)
+			cartIssue.CartQuantity = item.Quantity
+			cartIssue.AvailableQuantity = &available
+			return cartIssue
+		}
+
+		stockModel, err := s.stock.GetStock(ctx, tx, item.StockID)
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			validation.Block(stockIssue(enum.CartIssueCodeStockUnavailable, 0, "stock %d no longer exists", item.StockID))
+		case err != nil:
+			return nil, fmt.Errorf("failed to get stock for item %s: %w", item.ProductID, err)
+		case stockModel.RentalEnabled:
+			validation.Block(stockIssue(enum.CartIssueCodeStockUnavailable, 0, "stock %d is rental-only", item.StockID))
+		case stockModel.Quantity < item.Quantity:
+			// 購物車已預留自己的數量，只需確認實際庫存仍足夠
+			validation.Block(stockIssue(enum.CartIssueCodeInsufficientStock, stockModel.Quantity, "only %d available at location %q, %d in cart", stockModel.Quantity, stockModel.Location, item.Quantity))
+		}
+
+		if err = s.checkFlashSaleLimit(ctx, item.ProductID, item.Quantity); err != nil {
+			validation.Block(issue(enum.CartIssueCodeQuantityLimitExceeded, "%s", err.Error()))
+		}
+
+		// 有銷售通路時商品須仍在通路目錄中，有通路價格時以通路價格比對
+		var currentUnitPrice float64
+		var hasChannelPrice bool
+		if cartModel.Channel != "" {
+			currentUnitPrice, hasChannelPrice, err = s.channelUnitPrice(ctx, tx, cartModel, item.ProductID, item.PriceID)
+			switch {
+			case errors.Is(err, ErrProductNotInChannel):
+				validation.Block(issue(enum.CartIssueCodeNotInChannel, "product is not available in channel %q", cartModel.Channel))
+				continue
+			case err != nil:
+				return nil, err
+			}
+		}
+
+		if !hasChannelPrice {
+			inEffect, err := s.price.GetPriceInEffect(ctx, tx, item.PriceID, now)
+			switch {
+			case errors.Is(err, pgx.ErrNoRows):
+				// 沒有價格記錄時無從比對
+				continue
+			case err != nil:
+				return nil, fmt.Errorf("failed to get price in effect for %s: %w", item.PriceID, err)
+			}
+			currentUnitPrice = inEffect.UnitPrice
+		}
+		if math.Abs(currentUnitPrice-item.UnitPrice) >= 0.005 {
+			priceIssue := issue(enum.CartIssueCodePriceChanged, "unit price is now %.2f, cart has %.2f", currentUnitPrice, item.UnitPrice)
+			priceIssue.CartUnitPrice = item.UnitPrice
+			priceIssue.CurrentUnitPrice = currentUnitPrice
+			validation.Warn(priceIssue)
+		}
+	}
+
+	// 3. 活動或稅率變動造成的金額差異
+	if len(items) > 0 && math.Abs(validation.Total-cartModel.Total) >= 0.005 {
+		validation.Warn(&models.CartIssue{
+			Code:    enum.CartIssueCodeTotalsChanged,
+			Message: fmt.Sprintf("checkout total is %.2f, cart shows %.2f", validation.Total, cartModel.Total),
+		})
+	}
+
+	// 4. 地址與最低訂單金額
+	if addresses == nil || !hasAddress(addresses.ShippingAddress) {
+		validation.Block(&models.CartIssue{
+			Code:    enum.CartIssueCodeMissingShippingAddress,
+			Message: "shipping address is required",
+		})
+	}
+	if minimum, ok := s.minimumOrderValues[cartModel.Currency]; ok && validation.Total < minimum {
+		validation.Block(&models.CartIssue{
+			Code:    enum.CartIssueCodeBelowMinimumOrderValue,
+			Message: fmt.Sprintf("order total %.2f is below the minimum of %.2f %s", validation.Total, minimum, cartModel.Currency),
+		})
+	}
+
+	// 5. 優惠券
+	if err := s.validateCartCoupon(ctx, tx, cartModel, validation, now); err != nil {
+		return nil, err
+	}
+
+	validation.Ready = len(validation.Blocking) == 0
+	return validation, nil
+}
+
+// cartNotReadyError 將阻擋結帳的問題組成 ErrCartNotReadyForCheckout
+func cartNotReadyError(validation *models.CartValidation) error {
+	codes := make([]string, 0, len(validation.Blocking))
+	for _, issue := range validation.Blocking {
+		codes = append(codes, string(issue.Code))
+	}
+	return fmt.Errorf("%w: %s", ErrCartNotReadyForCheckout, strings.Join(codes, ", "))
+}
+
+// hasAddress 判斷地址 JSON 是否有內容，空字串、null 與空物件皆視為未填寫
+func hasAddress(address json.RawMessage) bool {
+	switch strings.TrimSpace(string(address)) {
+	case "", "null", "{}":
+		return false
+	}
+	return true
+}
@@ -0,0 +1,99 @@
+package shop
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+
+	"gofalre.io/shop/models"
+	"gofalre.io/shop/models/enum"
+)
+
+// RunConsistencyChecks 檢查購物車、訂單與庫存之間的資料一致性並回傳報告；
+// autoFix 為 true 時會修正可安全修正的問題：移除 active 購物車中指向不存在庫存的項目，並重新計算金額不符的 active 購物車，
+// 其餘問題（超額預留、無訂單的 converted 購物車、訂單金額不符）牽涉實際庫存或已成立的訂單，只回報不修正
+func (s *service) RunConsistencyChecks(ctx context.Context, autoFix bool) (*models.ConsistencyReport, error) {
+	report := &models.ConsistencyReport{
+		CheckedAt: time.Now(),
+		AutoFix:   autoFix,
+	}
+
+	// 1. 在同一個快照中執行所有檢查
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		var err error
+		if report.OrphanedCartItems, err = s.cart.ListOrphanedCartItems(ctx, tx); err != nil {
+			return fmt.Errorf("failed to list orphaned cart items: %w", err)
+		}
+		if report.OrphanedOrderItems, err = s.order.ListOrphanedOrderItems(ctx, tx); err != nil {
+			return fmt.Errorf("failed to list orphaned order items: %w", err)
+		}
+		if report.OverReservedStocks, err = s.stock.ListOverReservedStocks(ctx, tx); err != nil {
+			return fmt.Errorf("failed to list over-reserved stocks: %w", err)
+		}
+		if report.ConvertedCartsWithoutOrder, err = s.cart.ListConvertedCartsWithoutOrder(ctx, tx); err != nil {
+			return fmt.Errorf("failed to list converted carts without order: %w", err)
+		}
+		if report.CartTotalMismatches, err = s.cart.ListCartTotalMismatches(ctx, tx); err != nil {
+			return fmt.Errorf("failed to list cart total mismatches: %w", err)
+		}
+		if report.OrderTotalMismatches, err = s.order.ListOrderTotalMismatches(ctx, tx); err != nil {
+			return fmt.Errorf("failed to list order total mismatches: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	if !autoFix {
+		return report, nil
+	}
+
+	// 2. 依購物車分組可修正的問題，每個購物車在各自的交易中修正，單一購物車失敗不影響其他購物車
+	orphansByCart := make(map[uint64][]*models.OrphanedItem)
+	var cartIDs []uint64
+	for _, item := range report.OrphanedCartItems {
+		if item.ParentStatus != string(enum.CartStatusActive) || !item.StockMissing() {
+			continue
+		}
+		if _, ok := orphansByCart[item.ParentID]; !ok {
+			cartIDs = append(cartIDs, item.ParentID)
+		}
+		orphansByCart[item.ParentID] = append(orphansByCart[item.ParentID], item)
+	}
+	mismatchByCart := make(map[uint64]*models.TotalMismatch)
+	for _, mismatch := range report.CartTotalMismatches {
+		if _, ok := orphansByCart[mismatch.ID]; !ok {
+			cartIDs = append(cartIDs, mismatch.ID)
+		}
+		mismatchByCart[mismatch.ID] = mismatch
+	}
+
+	for _, cartID := range cartIDs {
+		if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+			// 2.1 移除指向不存在庫存的項目，這些項目沒有可釋放的預留庫存
+			for _, item := range orphansByCart[cartID] {
+				if err := s.cart.RemoveCartItem(ctx, tx, item.ID); err != nil {
+					return fmt.Errorf("failed to remove orphaned cart item %d: %w", item.ID, err)
+				}
+			}
+
+			// 2.2 重新計算購物車金額
+			return s.recalculateCartTotals(ctx, tx, cartID)
+		}); err != nil {
+			s.log(ctx).Warn("Failed to fix cart consistency", zap.Uint64("cart_id", cartID), zap.Error(err))
+			continue
+		}
+
+		for _, item := range orphansByCart[cartID] {
+			item.Fixed = true
+		}
+		if mismatch, ok := mismatchByCart[cartID]; ok {
+			mismatch.Fixed = true
+		}
+	}
+
+	return report, nil
+}
@@ -0,0 +1,352 @@
+package shop
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"gofalre.io/shop/coupon"
+	"gofalre.io/shop/models"
+	"gofalre.io/shop/models/enum"
+)
+
+var (
+	// ErrCouponNotFound 表示優惠券代碼不存在
+	ErrCouponNotFound = errors.New("coupon not found")
+	// ErrCouponNotApplicable 表示優惠券尚未開始、已過期、已作廢，或不適用購物車的客戶或幣別
+	ErrCouponNotApplicable = errors.New("coupon is not applicable")
+	// ErrCouponUsageLimitReached 表示優惠券已達兌換次數上限
+	ErrCouponUsageLimitReached = errors.New("coupon usage limit reached")
+)
+
+// defaultRecoveryCouponValidity 為挽回優惠券未設定期限時的預設有效期間
+const defaultRecoveryCouponValidity = 7 * 24 * time.Hour
+
+// RecoveryCoupon 設定偵測到閒置購物車時自動產生的挽回優惠券：PercentOff 為折扣百分比，0 表示不產生；
+// ValidFor 為優惠券的有效期間，0 表示使用 defaultRecoveryCouponValidity
+type RecoveryCoupon struct {
+	PercentOff float64
+	ValidFor   time.Duration
+}
+
+// WithRecoveryCoupon 設定閒置購物車的挽回優惠券，產生的優惠券只能使用一次，且只限該客戶用於該購物車
+func WithRecoveryCoupon(recovery RecoveryCoupon) Option {
+	return func(s *service) {
+		if recovery.PercentOff >= 0 && recovery.PercentOff <= 100 {
+			s.recoveryCoupon = recovery
+		}
+	}
+}
+
+// CreateCoupon 建立優惠券，代碼不分大小寫，一律以大寫儲存
+func (s *service) CreateCoupon(ctx context.Context, couponModel *models.Coupon) error {
+	couponModel.Code = normalizeCouponCode(couponModel.Code)
+	if couponModel.Code == "" {
+		return errors.New("coupon code is required")
+	}
+	switch couponModel.DiscountType {
+	case enum.CouponDiscountTypePercentage:
+		if couponModel.Amount <= 0 || couponModel.Amount > 100 {
+			return errors.New("percentage coupon amount must be between 0 and 100")
+		}
+	case enum.CouponDiscountTypeFixed:
+		if couponModel.Amount <= 0 {
+			return errors.New("fixed coupon amount must be greater than zero")
+		}
+		if couponModel.Currency == "" {
+			return errors.New("fixed coupon requires a currency")
+		}
+	default:
+		return fmt.Errorf("invalid coupon discount type: %q", couponModel.DiscountType)
+	}
+	if couponModel.StartsAt != nil && couponModel.ExpiresAt != nil && !couponModel.ExpiresAt.After(*couponModel.StartsAt) {
+		return errors.New("coupon must expire after it starts")
+	}
+	if couponModel.UsageLimit != nil && *couponModel.UsageLimit == 0 {
+		return errors.New("coupon usage limit must be greater than zero")
+	}
+
+	return s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		if err := s.coupon.CreateCoupon(ctx, tx, couponModel); err != nil {
+			return fmt.Errorf("failed to create coupon: %w", err)
+		}
+		return nil
+	})
+}
+
+// ApplyCoupon 將優惠券套用到購物車並重新計算折扣與總額，購物車已套用其他優惠券時取代之；
+// 優惠券是否實際折抵仍依優惠疊加規則決定，回傳重新計算後的購物車
+func (s *service) ApplyCoupon(ctx context.Context, cartID uint64, code string) (*models.Cart, error) {
+	code = normalizeCouponCode(code)
+	if code == "" {
+		return nil, errors.New("coupon code is required")
+	}
+
+	var cartModel *models.Cart
+
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		// 1. 檢查購物車狀態
+		current, err := s.cart.GetCart(ctx, tx, cartID)
+		if err != nil {
+			return fmt.Errorf("failed to get cart: %w", err)
+		}
+		if current.Status != enum.CartStatusActive {
+			return fmt.Errorf("%w: cart %d is %s", ErrCartNotActive, cartID, current.Status)
+		}
+
+		// 2. 檢查優惠券是否可以使用
+		couponModel, err := s.coupon.GetCouponByCode(ctx, tx, code)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("%w: %s", ErrCouponNotFound, code)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get coupon: %w", err)
+		}
+		if err = checkCoupon(couponModel, current, time.Now()); err != nil {
+			return err
+		}
+
+		// 3. 套用並重新計算金額
+		if err = s.coupon.SetCartCoupon(ctx, tx, cartID, couponModel.ID); err != nil {
+			return fmt.Errorf("failed to set cart coupon: %w", err)
+		}
+		if err = s.recalculateCartTotals(ctx, tx, cartID); err != nil {
+			return err
+		}
+
+		cartModel, err = s.cart.GetCart(ctx, tx, cartID)
+		if err != nil {
+			return fmt.Errorf("failed to get cart: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return cartModel, nil
+}
+
+// RemoveCoupon 移除購物車套用的優惠券並重新計算折扣與總額，回傳重新計算後的購物車
+func (s *service) RemoveCoupon(ctx context.Context, cartID uint64) (*models.Cart, error) {
+	var cartModel *models.Cart
+
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		current, err := s.cart.GetCart(ctx, tx, cartID)
+		if err != nil {
+			return fmt.Errorf("failed to get cart: %w", err)
+		}
+		if current.Status != enum.CartStatusActive {
+			return fmt.Errorf("%w: cart %d is %s", ErrCartNotActive, cartID, current.Status)
+		}
+
+		removed, err := s.coupon.RemoveCartCoupon(ctx, tx, cartID)
+		if err != nil {
+			return fmt.Errorf("failed to remove cart coupon: %w", err)
+		}
+		if removed {
+			if err = s.recalculateCartTotals(ctx, tx, cartID); err != nil {
+				return err
+			}
+			if current, err = s.cart.GetCart(ctx, tx, cartID); err != nil {
+				return fmt.Errorf("failed to get cart: %w", err)
+			}
+		}
+
+		cartModel = current
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return cartModel, nil
+}
+
+// cartCouponPromotion 將購物車套用的優惠券轉換為優惠，購物車沒有優惠券或優惠券在 at 時無法使用時回傳 nil
+func (s *service) cartCouponPromotion(ctx context.Context, tx pgx.Tx, cartModel *models.Cart, items []*models.CartItem, at time.Time) (*models.Coupon, *models.Promotion, error) {
+	couponModel, err := s.coupon.GetCartCoupon(ctx, tx, cartModel.ID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get cart coupon: %w", err)
+	}
+	if checkCoupon(couponModel, cartModel, at) != nil {
+		return nil, nil, nil
+	}
+
+	promotion := &models.Promotion{
+		Kind:          enum.PromotionKindCoupon,
+		Reference:     couponModel.Code,
+		Name:          couponModel.Name,
+		LineDiscounts: make(map[uint64]float64, len(items)),
+	}
+
+	switch couponModel.DiscountType {
+	case enum.CouponDiscountTypePercentage:
+		for _, item := range items {
+			promotion.LineDiscounts[item.ID] = roundCurrencyAmount(item.Subtotal*couponModel.Amount/100, cartModel.Currency)
+		}
+	case enum.CouponDiscountTypeFixed:
+		// 整筆折抵的金額依項目小計比例分攤，尾差由最後一個項目吸收
+		var subtotal float64
+		for _, item := range items {
+			subtotal += item.Subtotal
+		}
+		if subtotal <= 0 {
+			return couponModel, nil, nil
+		}
+		amount := min(couponModel.Amount, subtotal)
+		allocated := 0.0
+		for i, item := range items {
+			discount := roundCurrencyAmount(amount*item.Subtotal/subtotal, cartModel.Currency)
+			if i == len(items)-1 {
+				discount = roundCurrencyAmount(amount-allocated, cartModel.Currency)
+			}
+			promotion.LineDiscounts[item.ID] = discount
+			allocated += discount
+		}
+	}
+
+	return couponModel, promotion, nil
+}
+
+// validateCartCoupon 購物車套用的優惠券已無法使用時加入阻擋結帳的問題，結帳前須移除或更換優惠券
+func (s *service) validateCartCoupon(ctx context.Context, tx pgx.Tx, cartModel *models.Cart, validation *models.CartValidation, now time.Time) error {
+	couponModel, err := s.coupon.GetCartCoupon(ctx, tx, cartModel.ID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get cart coupon: %w", err)
+	}
+
+	if err = checkCoupon(couponModel, cartModel, now); err != nil {
+		code := enum.CartIssueCodeCouponUnavailable
+		if couponModel.ExpiresAt != nil && !now.Before(*couponModel.ExpiresAt) {
+			code = enum.CartIssueCodeCouponExpired
+		}
+		validation.Block(&models.CartIssue{
+			Code:       code,
+			Message:    err.Error(),
+			CouponCode: couponModel.Code,
+		})
+	}
+	return nil
+}
+
+// redeemCoupon 記錄訂單兌換的優惠券並累加兌換次數，優惠券沒有實際折抵時不記錄；
+// 其他訂單已用完兌換次數時回傳 ErrCouponUsageLimitReached
+func (s *service) redeemCoupon(ctx context.Context, tx pgx.Tx, order *models.Order, pricing *cartPricing) error {
+	if pricing.Coupon == nil {
+		return nil
+	}
+
+	ok, err := s.coupon.RedeemCoupon(ctx, tx, coupon.RedeemCouponParams{
+		CouponID:   pricing.Coupon.ID,
+		OrderID:    order.ID,
+		CustomerID: order.CustomerID,
+		Discount:   pricing.CouponDiscount,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to redeem coupon: %w", err)
+	}
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrCouponUsageLimitReached, pricing.Coupon.Code)
+	}
+
+	return nil
+}
+
+// issueRecoveryCoupon 為閒置購物車產生挽回優惠券並作廢先前未使用的挽回優惠券；
+// 未設定挽回優惠券或購物車已套用優惠券時回傳 nil
+func (s *service) issueRecoveryCoupon(ctx context.Context, tx pgx.Tx, cartModel *models.Cart, now time.Time) (*models.Coupon, error) {
+	if s.recoveryCoupon.PercentOff <= 0 {
+		return nil, nil
+	}
+
+	// 1. 購物車已套用優惠券時不另外產生，避免作廢客戶正在使用的挽回優惠券
+	if _, err := s.coupon.GetCartCoupon(ctx, tx, cartModel.ID); err == nil {
+		return nil, nil
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get cart coupon: %w", err)
+	}
+
+	// 2. 作廢先前通知時產生但未使用的挽回優惠券
+	if err := s.revokeRecoveryCoupons(ctx, tx, cartModel.ID); err != nil {
+		return nil, err
+	}
+
+	// 3. 產生只能使用一次、綁定客戶與購物車的優惠券
+	code, err := newRecoveryCouponCode()
+	if err != nil {
+		return nil, err
+	}
+	validFor := s.recoveryCoupon.ValidFor
+	if validFor <= 0 {
+		validFor = defaultRecoveryCouponValidity
+	}
+	expiresAt := now.Add(validFor)
+	usageLimit := uint64(1)
+	cartID := cartModel.ID
+
+	couponModel := &models.Coupon{
+		Code:         code,
+		Name:         "Abandoned cart recovery",
+		DiscountType: enum.CouponDiscountTypePercentage,
+		Amount:       s.recoveryCoupon.PercentOff,
+		ExpiresAt:    &expiresAt,
+		UsageLimit:   &usageLimit,
+		CustomerID:   cartModel.CustomerID,
+		CartID:       &cartID,
+	}
+	if err = s.coupon.CreateCoupon(ctx, tx, couponModel); err != nil {
+		return nil, fmt.Errorf("failed to create recovery coupon: %w", err)
+	}
+
+	return couponModel, nil
+}
+
+// revokeRecoveryCoupons 作廢購物車尚未兌換的挽回優惠券，購物車未使用挽回優惠券即結帳時也一併作廢
+func (s *service) revokeRecoveryCoupons(ctx context.Context, tx pgx.Tx, cartID uint64) error {
+	if _, err := s.coupon.RevokeCartRecoveryCoupons(ctx, tx, cartID); err != nil {
+		return fmt.Errorf("failed to revoke recovery coupons: %w", err)
+	}
+	return nil
+}
+
+// newRecoveryCouponCode 產生挽回優惠券的代碼，例如 CART-9F86D081884C
+func newRecoveryCouponCode() (string, error) {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate recovery coupon code: %w", err)
+	}
+	return "CART-" + strings.ToUpper(hex.EncodeToString(b)), nil
+}
+
+// checkCoupon 檢查優惠券在 at 時是否可以用於購物車
+func checkCoupon(couponModel *models.Coupon, cartModel *models.Cart, at time.Time) error {
+	if !couponModel.BoundTo(cartModel) {
+		return fmt.Errorf("%w: %s is not valid for this cart", ErrCouponNotApplicable, couponModel.Code)
+	}
+	if !couponModel.Active(at) {
+		return fmt.Errorf("%w: %s is not active", ErrCouponNotApplicable, couponModel.Code)
+	}
+	if couponModel.Exhausted() {
+		return fmt.Errorf("%w: %s", ErrCouponUsageLimitReached, couponModel.Code)
+	}
+	if couponModel.DiscountType == enum.CouponDiscountTypeFixed && couponModel.Currency != cartModel.Currency {
+		return fmt.Errorf("%w: %s is only valid for %s", ErrCouponNotApplicable, couponModel.Code, couponModel.Currency)
+	}
+	return nil
+}
+
+// normalizeCouponCode 去除前後空白並轉為大寫
+func normalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
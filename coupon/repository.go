@@ -0,0 +1,168 @@
+package coupon
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+
+	"gofalre.io/shop/driver"
+	"gofalre.io/shop/models"
+	"gofalre.io/shop/sqlc"
+)
+
+type Repository interface {
+	CreateCoupon(ctx context.Context, tx pgx.Tx, coupon *models.Coupon) error
+	GetCouponByCode(ctx context.Context, tx pgx.Tx, code string) (*models.Coupon, error)
+	GetCartCoupon(ctx context.Context, tx pgx.Tx, cartID uint64) (*models.Coupon, error)
+	SetCartCoupon(ctx context.Context, tx pgx.Tx, cartID, couponID uint64) error
+	RemoveCartCoupon(ctx context.Context, tx pgx.Tx, cartID uint64) (bool, error)
+	RedeemCoupon(ctx context.Context, tx pgx.Tx, params RedeemCouponParams) (bool, error)
+	RevokeCartRecoveryCoupons(ctx context.Context, tx pgx.Tx, cartID uint64) (int64, error)
+}
+
+type repository struct {
+	queries *driver.Queries
+	logger  *zap.Logger
+}
+
+func NewRepository(conn driver.PostgresPool, logger *zap.Logger) Repository {
+	return NewRepositoryWithQuerier(sqlc.New(conn), logger)
+}
+
+// NewRepositoryWithQuerier 以注入的 sqlc.Querier 建立 repository，交易中的查詢見 driver.TxBinder
+func NewRepositoryWithQuerier(querier sqlc.Querier, logger *zap.Logger) Repository {
+	return &repository{
+		queries: driver.NewQueries(querier),
+		logger:  logger,
+	}
+}
+
+var _ Repository = (*repository)(nil)
+
+// CreateCoupon 建立優惠券，並寫回 ID 與建立時間
+func (r *repository) CreateCoupon(ctx context.Context, tx pgx.Tx, coupon *models.Coupon) error {
+	params := sqlc.CreateCouponParams{
+		Code:         coupon.Code,
+		Name:         coupon.Name,
+		DiscountType: sqlc.CouponDiscountType(coupon.DiscountType),
+		Amount:       coupon.Amount,
+	}
+	if coupon.Currency != "" {
+		params.Currency = sqlc.NullCurrency{Currency: sqlc.Currency(coupon.Currency), Valid: true}
+	}
+	if coupon.StartsAt != nil {
+		params.StartsAt = pgtype.Timestamptz{Time: *coupon.StartsAt, Valid: true}
+	}
+	if coupon.ExpiresAt != nil {
+		params.ExpiresAt = pgtype.Timestamptz{Time: *coupon.ExpiresAt, Valid: true}
+	}
+	if coupon.UsageLimit != nil {
+		usageLimit := int32(*coupon.UsageLimit)
+		params.UsageLimit = &usageLimit
+	}
+	if coupon.CustomerID != "" {
+		params.CustomerID = &coupon.CustomerID
+	}
+	if coupon.CartID != nil {
+		cartID := int32(*coupon.CartID)
+		params.RecoveryCartID = &cartID
+	}
+
+	sqlcCoupon, err := r.queries.WithTx(tx).CreateCoupon(ctx, params)
+	if err != nil {
+		r.logger.Error("Failed to create coupon", zap.String("code", coupon.Code), zap.Error(err))
+		return err
+	}
+
+	coupon.ConvertSqlcCoupon(sqlcCoupon)
+	return nil
+}
+
+// GetCouponByCode 以代碼取得優惠券，代碼不存在時回傳 pgx.ErrNoRows
+func (r *repository) GetCouponByCode(ctx context.Context, tx pgx.Tx, code string) (*models.Coupon, error) {
+	sqlcCoupon, err := r.queries.WithTx(tx).GetCouponByCode(ctx, code)
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			r.logger.Error("Failed to get coupon", zap.String("code", code), zap.Error(err))
+		}
+		return nil, err
+	}
+
+	return new(models.Coupon).ConvertSqlcCoupon(sqlcCoupon), nil
+}
+
+// GetCartCoupon 取得購物車套用的優惠券，沒有套用時回傳 pgx.ErrNoRows
+func (r *repository) GetCartCoupon(ctx context.Context, tx pgx.Tx, cartID uint64) (*models.Coupon, error) {
+	sqlcCoupon, err := r.queries.WithTx(tx).GetCartCoupon(ctx, cartID)
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			r.logger.Error("Failed to get cart coupon", zap.Uint64("cart_id", cartID), zap.Error(err))
+		}
+		return nil, err
+	}
+
+	return new(models.Coupon).ConvertSqlcCoupon(sqlcCoupon), nil
+}
+
+// SetCartCoupon 設定購物車套用的優惠券，已套用其他優惠券時取代之
+func (r *repository) SetCartCoupon(ctx context.Context, tx pgx.Tx, cartID, couponID uint64) error {
+	if err := r.queries.WithTx(tx).SetCartCoupon(ctx, sqlc.SetCartCouponParams{
+		CartID:   cartID,
+		CouponID: int32(couponID),
+	}); err != nil {
+		r.logger.Error("Failed to set cart coupon", zap.Uint64("cart_id", cartID), zap.Uint64("coupon_id", couponID), zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// RemoveCartCoupon 移除購物車套用的優惠券，購物車沒有套用優惠券時回傳 false
+func (r *repository) RemoveCartCoupon(ctx context.Context, tx pgx.Tx, cartID uint64) (bool, error) {
+	rows, err := r.queries.WithTx(tx).DeleteCartCoupon(ctx, cartID)
+	if err != nil {
+		r.logger.Error("Failed to remove cart coupon", zap.Uint64("cart_id", cartID), zap.Error(err))
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+// RedeemCoupon 累加優惠券的兌換次數並記錄訂單的兌換明細，已達兌換次數上限時不記錄並回傳 false
+func (r *repository) RedeemCoupon(ctx context.Context, tx pgx.Tx, params RedeemCouponParams) (bool, error) {
+	rows, err := r.queries.WithTx(tx).IncrementCouponRedemptions(ctx, int32(params.CouponID))
+	if err != nil {
+		r.logger.Error("Failed to increment coupon redemptions", zap.Uint64("coupon_id", params.CouponID), zap.Error(err))
+		return false, err
+	}
+	if rows == 0 {
+		return false, nil
+	}
+
+	if err = r.queries.WithTx(tx).CreateCouponRedemption(ctx, sqlc.CreateCouponRedemptionParams{
+		CouponID:   int32(params.CouponID),
+		OrderID:    int32(params.OrderID),
+		CustomerID: params.CustomerID,
+		Discount:   params.Discount,
+	}); err != nil {
+		r.logger.Error("Failed to create coupon redemption", zap.Uint64("coupon_id", params.CouponID), zap.Uint64("order_id", params.OrderID), zap.Error(err))
+		return false, err
+	}
+
+	return true, nil
+}
+
+// RevokeCartRecoveryCoupons 作廢綁定購物車且尚未兌換的挽回優惠券，回傳作廢的數量
+func (r *repository) RevokeCartRecoveryCoupons(ctx context.Context, tx pgx.Tx, cartID uint64) (int64, error) {
+	id := int32(cartID)
+	rows, err := r.queries.WithTx(tx).RevokeCartRecoveryCoupons(ctx, &id)
+	if err != nil {
+		r.logger.Error("Failed to revoke cart recovery coupons", zap.Uint64("cart_id", cartID), zap.Error(err))
+		return 0, err
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,8 @@
+package coupon
+
+type RedeemCouponParams struct {
+	CouponID   uint64
+	OrderID    uint64
+	CustomerID string
+	Discount   float64
+}
@@ -0,0 +1,219 @@
+package shop
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"unicode/utf8"
+)
+
+// maxCustomizationBytes 為單一項目客製化內容的大小上限
+const maxCustomizationBytes = 16 << 10
+
+// ErrCustomizationNotAllowed 表示商品不接受客製化，或未設定 WithCustomizationSchemas
+var ErrCustomizationNotAllowed = errors.New("product does not accept customization")
+
+// ErrInvalidCustomization 表示客製化內容不符合商品的 schema
+var ErrInvalidCustomization = errors.New("invalid customization")
+
+// CustomizationSchemas 提供商品的客製化 JSON schema，商品資料由外部的商品服務維護；
+// 商品不接受客製化時回傳 nil
+type CustomizationSchemas interface {
+	GetCustomizationSchema(ctx context.Context, productID string) (json.RawMessage, error)
+}
+
+// WithCustomizationSchemas 設定驗證購物車與訂單項目客製化內容使用的 schema 來源，未設定時不接受客製化
+func WithCustomizationSchemas(schemas CustomizationSchemas) Option {
+	return func(s *service) {
+		s.customizationSchemas = schemas
+	}
+}
+
+// validateCustomization 依商品的 schema 驗證客製化內容並回傳壓縮後的 JSON，沒有客製化時回傳 nil；
+// 客製化內容必須是 JSON 物件
+func (s *service) validateCustomization(ctx context.Context, productID string, customization json.RawMessage) (json.RawMessage, error) {
+	customization = bytes.TrimSpace(customization)
+	if len(customization) == 0 || bytes.Equal(customization, []byte("null")) {
+		return nil, nil
+	}
+	if len(customization) > maxCustomizationBytes {
+		return nil, fmt.Errorf("%w: exceeds %d bytes", ErrInvalidCustomization, maxCustomizationBytes)
+	}
+
+	var value any
+	if err := json.Unmarshal(customization, &value); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCustomization, err)
+	}
+	if _, ok := value.(map[string]any); !ok {
+		return nil, fmt.Errorf("%w: must be a JSON object", ErrInvalidCustomization)
+	}
+
+	if s.customizationSchemas == nil {
+		return nil, fmt.Errorf("%w: %s", ErrCustomizationNotAllowed, productID)
+	}
+	rawSchema, err := s.customizationSchemas.GetCustomizationSchema(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get customization schema for %s: %w", productID, err)
+	}
+	if len(rawSchema) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrCustomizationNotAllowed, productID)
+	}
+
+	var schema customizationSchema
+	if err = json.Unmarshal(rawSchema, &schema); err != nil {
+		return nil, fmt.Errorf("invalid customization schema for %s: %w", productID, err)
+	}
+	if err = schema.validate("$", value); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCustomization, err)
+	}
+
+	var compacted bytes.Buffer
+	if err = json.Compact(&compacted, customization); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCustomization, err)
+	}
+	return compacted.Bytes(), nil
+}
+
+// customizationSchema 為 JSON Schema 中客製化常用的子集：type、properties、required、
+// additionalProperties（僅布林值）、enum、minLength、maxLength、pattern、minimum、maximum、items、minItems、maxItems；
+// 其他關鍵字會被忽略
+type customizationSchema struct {
+	Type                 schemaTypes                     `json:"type"`
+	Properties           map[string]*customizationSchema `json:"properties"`
+	Required             []string                        `json:"required"`
+	AdditionalProperties *bool                           `json:"additionalProperties"`
+	Enum                 []any                           `json:"enum"`
+	MinLength            *int                            `json:"minLength"`
+	MaxLength            *int                            `json:"maxLength"`
+	Pattern              string                          `json:"pattern"`
+	Minimum              *float64                        `json:"minimum"`
+	Maximum              *float64                        `json:"maximum"`
+	Items                *customizationSchema            `json:"items"`
+	MinItems             *int                            `json:"minItems"`
+	MaxItems             *int                            `json:"maxItems"`
+}
+
+// schemaTypes 為 type 關鍵字，可以是單一型別或型別陣列
+type schemaTypes []string
+
+func (t *schemaTypes) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = schemaTypes{single}
+		return nil
+	}
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return fmt.Errorf("type must be a string or an array of strings")
+	}
+	*t = multiple
+	return nil
+}
+
+// validate 檢查 value 是否符合 schema，path 為錯誤訊息中的欄位路徑
+func (cs *customizationSchema) validate(path string, value any) error {
+	if len(cs.Type) > 0 && !slices.ContainsFunc(cs.Type, func(t string) bool { return jsonTypeMatches(t, value) }) {
+		return fmt.Errorf("%s must be %v", path, []string(cs.Type))
+	}
+
+	if len(cs.Enum) > 0 && !slices.ContainsFunc(cs.Enum, func(candidate any) bool { return reflect.DeepEqual(candidate, value) }) {
+		return fmt.Errorf("%s must be one of %v", path, cs.Enum)
+	}
+
+	switch v := value.(type) {
+	case string:
+		length := utf8.RuneCountInString(v)
+		if cs.MinLength != nil && length < *cs.MinLength {
+			return fmt.Errorf("%s must be at least %d characters", path, *cs.MinLength)
+		}
+		if cs.MaxLength != nil && length > *cs.MaxLength {
+			return fmt.Errorf("%s must be at most %d characters", path, *cs.MaxLength)
+		}
+		if cs.Pattern != "" {
+			re, err := regexp.Compile(cs.Pattern)
+			if err != nil {
+				return fmt.Errorf("%s has an invalid pattern: %w", path, err)
+			}
+			if !re.MatchString(v) {
+				return fmt.Errorf("%s does not match pattern %q", path, cs.Pattern)
+			}
+		}
+	case float64:
+		if cs.Minimum != nil && v < *cs.Minimum {
+			return fmt.Errorf("%s must be at least %v", path, *cs.Minimum)
+		}
+		if cs.Maximum != nil && v > *cs.Maximum {
+			return fmt.Errorf("%s must be at most %v", path, *cs.Maximum)
+		}
+	case []any:
+		if cs.MinItems != nil && len(v) < *cs.MinItems {
+			return fmt.Errorf("%s must have at least %d items", path, *cs.MinItems)
+		}
+		if cs.MaxItems != nil && len(v) > *cs.MaxItems {
+			return fmt.Errorf("%s must have at most %d items", path, *cs.MaxItems)
+		}
+		if cs.Items != nil {
+			for i, item := range v {
+				if err := cs.Items.validate(fmt.Sprintf("%s[%d]", path, i), item); err != nil {
+					return err
+				}
+			}
+		}
+	case map[string]any:
+		for _, name := range cs.Required {
+			if _, ok := v[name]; !ok {
+				return fmt.Errorf("%s.%s is required", path, name)
+			}
+		}
+		// 依欄位名稱排序，讓錯誤訊息固定
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			property, ok := cs.Properties[name]
+			if !ok {
+				if cs.AdditionalProperties != nil && !*cs.AdditionalProperties {
+					return fmt.Errorf("%s.%s is not allowed", path, name)
+				}
+				continue
+			}
+			if property == nil {
+				continue
+			}
+			if err := property.validate(path+"."+name, v[name]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// jsonTypeMatches 回傳解碼後的 JSON 值是否為 JSON Schema 的型別
+func jsonTypeMatches(schemaType string, value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return schemaType == "null"
+	case bool:
+		return schemaType == "boolean"
+	case string:
+		return schemaType == "string"
+	case float64:
+		return schemaType == "number" || (schemaType == "integer" && v == math.Trunc(v))
+	case []any:
+		return schemaType == "array"
+	case map[string]any:
+		return schemaType == "object"
+	default:
+		return false
+	}
+}
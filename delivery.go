@@ -0,0 +1,361 @@
+package shop
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+
+	"gofalre.io/shop/models"
+)
+
+// defaultCutoffHour 為未設定倉庫出貨時程時的當日出貨截止時間（UTC）
+const defaultCutoffHour = 14
+
+var (
+	// ErrDeliveryEstimationNotConfigured 表示未設定物流商運送天數表，無法估算送達日期
+	ErrDeliveryEstimationNotConfigured = errors.New("delivery estimation is not configured")
+	// ErrDeliveryDestinationRequired 表示未指定目的地，且寄送地址中沒有國碼
+	ErrDeliveryDestinationRequired = errors.New("delivery destination is required")
+	// ErrNoTransitRoute 表示運送天數表中沒有符合的路線
+	ErrNoTransitRoute = errors.New("no transit route")
+)
+
+// CarrierTransitTable 提供物流商從出貨倉庫到目的地的運送工作天數，回傳最短與最長天數
+type CarrierTransitTable interface {
+	TransitDays(ctx context.Context, origin string, destination models.DeliveryDestination) (int, int, error)
+}
+
+// TransitRoute 為靜態運送天數表的一條路線，Origin 或 Country 為空字串時符合任何出貨倉庫或目的地國家
+type TransitRoute struct {
+	Origin  string
+	Country string
+	MinDays int
+	MaxDays int
+}
+
+// StaticTransitTable 以固定的路線表提供運送天數；多條路線符合時，
+// 同時指定倉庫與國家的路線優先，其次為只指定國家、只指定倉庫，最後才是兩者皆未指定的預設路線
+type StaticTransitTable []TransitRoute
+
+func (t StaticTransitTable) TransitDays(_ context.Context, origin string, destination models.DeliveryDestination) (int, int, error) {
+	var best *TransitRoute
+	bestScore := -1
+	for i := range t {
+		route := &t[i]
+		if route.Origin != "" && route.Origin != origin {
+			continue
+		}
+		if route.Country != "" && !strings.EqualFold(route.Country, destination.Country) {
+			continue
+		}
+
+		score := 0
+		if route.Country != "" {
+			score += 2
+		}
+		if route.Origin != "" {
+			score++
+		}
+		if score > bestScore {
+			best, bestScore = route, score
+		}
+	}
+	if best == nil {
+		return 0, 0, fmt.Errorf("%w from %s to %s", ErrNoTransitRoute, origin, destination.Country)
+	}
+
+	return best.MinDays, best.MaxDays, nil
+}
+
+// WarehouseSchedule 為倉庫的出貨時程：CutoffHour 前（TimeZone 的當地時間）成立的訂單於當日開始備貨，
+// 之後成立的順延一個出貨日，CutoffHour 為 0 時不限截止時間；HandlingDays 為備貨所需的出貨日數，0 表示當日出貨
+type WarehouseSchedule struct {
+	CutoffHour      int
+	TimeZone        *time.Location
+	HandlingDays    int
+	ShipsOnWeekends bool
+}
+
+// DefaultWarehouseSchedule 為未設定出貨時程的倉庫使用的預設值
+var DefaultWarehouseSchedule = WarehouseSchedule{CutoffHour: defaultCutoffHour}
+
+// ShipDate 回傳在 orderedAt 成立的訂單預計出貨的日期（以 UTC 零時表示當地日期）
+func (w WarehouseSchedule) ShipDate(orderedAt time.Time) time.Time {
+	zone := w.TimeZone
+	if zone == nil {
+		zone = time.UTC
+	}
+	local := orderedAt.In(zone)
+	day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+
+	if !w.shipsOn(day) || (w.CutoffHour > 0 && local.Hour() >= w.CutoffHour) {
+		day = w.nextShipDay(day)
+	}
+	for i := 0; i < w.HandlingDays; i++ {
+		day = w.nextShipDay(day)
+	}
+
+	return day
+}
+
+func (w WarehouseSchedule) shipsOn(day time.Time) bool {
+	return w.ShipsOnWeekends || isBusinessDay(day)
+}
+
+func (w WarehouseSchedule) nextShipDay(day time.Time) time.Time {
+	day = day.AddDate(0, 0, 1)
+	for !w.shipsOn(day) {
+		day = day.AddDate(0, 0, 1)
+	}
+	return day
+}
+
+// WithCarrierTransitTable 設定估算送達日期使用的物流商運送天數表，未設定時不估算送達日期
+func WithCarrierTransitTable(table CarrierTransitTable) Option {
+	return func(s *service) {
+		s.transitTable = table
+	}
+}
+
+// WithWarehouseSchedule 設定倉庫的出貨時程，location 為空字串時作為未個別設定的倉庫的預設值
+func WithWarehouseSchedule(location string, schedule WarehouseSchedule) Option {
+	return func(s *service) {
+		if s.warehouseSchedules == nil {
+			s.warehouseSchedules = make(map[string]WarehouseSchedule)
+		}
+		s.warehouseSchedules[location] = schedule
+	}
+}
+
+// DeliverySource 指定估算送達日期的對象，CartID 與 OrderID 須擇一設定
+type DeliverySource struct {
+	CartID  uint64
+	OrderID uint64
+}
+
+// EstimateDelivery 依商品分配的出貨倉庫、倉庫的出貨截止時間與物流商運送天數，估算購物車或訂單的送達日期區間；
+// destination 為 nil 時使用寄送地址中的國碼與郵遞區號
+func (s *service) EstimateDelivery(ctx context.Context, source DeliverySource, destination *models.DeliveryDestination) (*models.DeliveryEstimate, error) {
+	if (source.CartID == 0) == (source.OrderID == 0) {
+		return nil, errors.New("exactly one of cart ID or order ID is required")
+	}
+	if s.transitTable == nil {
+		return nil, ErrDeliveryEstimationNotConfigured
+	}
+
+	var estimate *models.DeliveryEstimate
+
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		// 1. 獲取各項目的出貨倉庫與寄送地址
+		var items []*models.ShippingItem
+		var address json.RawMessage
+		var err error
+		if source.CartID != 0 {
+			items, address, err = s.cartShippingItems(ctx, tx, source.CartID)
+		} else {
+			items, address, err = s.orderShippingItems(ctx, tx, source.OrderID)
+		}
+		if err != nil {
+			return err
+		}
+
+		// 2. 決定目的地
+		target, err := deliveryDestination(destination, address)
+		if err != nil {
+			return err
+		}
+
+		// 3. 估算各倉庫的出貨日與送達區間
+		estimate, err = s.estimateDelivery(ctx, items, target, time.Now())
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return estimate, nil
+}
+
+// cartShippingItems 回傳購物車項目的出貨倉庫與寄送地址，項目沒有記錄地點時使用庫存的地點
+func (s *service) cartShippingItems(ctx context.Context, tx pgx.Tx, cartID uint64) ([]*models.ShippingItem, json.RawMessage, error) {
+	cartItems, err := s.cart.ListCartItems(ctx, tx, cartID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list cart items: %w", err)
+	}
+	if len(cartItems) == 0 {
+		return nil, nil, fmt.Errorf("cart is empty")
+	}
+
+	items := make([]*models.ShippingItem, 0, len(cartItems))
+	for _, item := range cartItems {
+		location := item.Location
+		if location == "" {
+			stockModel, err := s.stock.GetStock(ctx, tx, item.StockID)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to get stock for item %s: %w", item.ProductID, err)
+			}
+			location = stockModel.Location
+		}
+		items = append(items, &models.ShippingItem{
+			ProductID: item.ProductID,
+			Location:  location,
+			Quantity:  item.Quantity,
+			UnitPrice: item.UnitPrice,
+			Subtotal:  item.Subtotal,
+		})
+	}
+
+	addresses, err := s.cart.GetCartAddresses(ctx, tx, cartID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get cart addresses: %w", err)
+	}
+
+	return items, addresses.ShippingAddress, nil
+}
+
+// orderShippingItems 回傳訂單項目的出貨倉庫與寄送地址，門市自取的訂單回傳 ErrShipmentNotRequired
+func (s *service) orderShippingItems(ctx context.Context, tx pgx.Tx, orderID uint64) ([]*models.ShippingItem, json.RawMessage, error) {
+	orderModel, err := s.order.GetOrder(ctx, tx, orderID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get order: %w", err)
+	}
+	if !orderModel.RequiresShipping() {
+		return nil, nil, ErrShipmentNotRequired
+	}
+
+	orderItems, err := s.order.ListOrderItems(ctx, tx, orderID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list order items: %w", err)
+	}
+	items, err := s.shippingItems(ctx, tx, orderItems)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return items, orderModel.ShippingAddress, nil
+}
+
+// estimateDelivery 將項目依出貨倉庫分組，以各倉庫的出貨日加上運送天數估算送達區間；
+// 整體區間取各倉庫中最晚的最早送達日與最晚的最晚送達日，表示所有出貨都送達的時間
+func (s *service) estimateDelivery(ctx context.Context, items []*models.ShippingItem, destination models.DeliveryDestination, orderedAt time.Time) (*models.DeliveryEstimate, error) {
+	estimate := &models.DeliveryEstimate{
+		Destination: destination,
+		EstimatedAt: orderedAt,
+	}
+
+	byLocation := make(map[string]*models.ShipmentEstimate)
+	for _, item := range items {
+		shipment, ok := byLocation[item.Location]
+		if !ok {
+			shipment = &models.ShipmentEstimate{Location: item.Location}
+			byLocation[item.Location] = shipment
+			estimate.Shipments = append(estimate.Shipments, shipment)
+		}
+		shipment.ProductIDs = append(shipment.ProductIDs, item.ProductID)
+	}
+
+	for _, shipment := range estimate.Shipments {
+		minDays, maxDays, err := s.transitTable.TransitDays(ctx, shipment.Location, destination)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get transit days from %s: %w", shipment.Location, err)
+		}
+		if minDays < 0 || maxDays < minDays {
+			return nil, fmt.Errorf("invalid transit days %d-%d from %s to %s", minDays, maxDays, shipment.Location, destination.Country)
+		}
+
+		shipment.ShipDate = s.warehouseSchedule(shipment.Location).ShipDate(orderedAt)
+		shipment.TransitMinDays = minDays
+		shipment.TransitMaxDays = maxDays
+		shipment.Window = models.DeliveryWindow{
+			Earliest: addBusinessDays(shipment.ShipDate, minDays),
+			Latest:   addBusinessDays(shipment.ShipDate, maxDays),
+		}
+
+		if shipment.Window.Earliest.After(estimate.Window.Earliest) {
+			estimate.Window.Earliest = shipment.Window.Earliest
+		}
+		if shipment.Window.Latest.After(estimate.Window.Latest) {
+			estimate.Window.Latest = shipment.Window.Latest
+		}
+	}
+
+	return estimate, nil
+}
+
+// recordDeliveryEstimate 在結帳時記錄訂單的預估送達區間；未設定運送天數表時略過，
+// 估算失敗只記錄警告，不影響下單
+func (s *service) recordDeliveryEstimate(ctx context.Context, tx pgx.Tx, order *models.Order, items []*models.OrderItem) error {
+	if s.transitTable == nil || !order.RequiresShipping() {
+		return nil
+	}
+
+	destination, err := deliveryDestination(nil, order.ShippingAddress)
+	if err != nil {
+		s.log(ctx).Warn("Skipping delivery estimate for order", zap.Uint64("order_id", order.ID), zap.Error(err))
+		return nil
+	}
+	shippingItems, err := s.shippingItems(ctx, tx, items)
+	if err != nil {
+		return err
+	}
+	estimate, err := s.estimateDelivery(ctx, shippingItems, destination, order.CreatedAt)
+	if err != nil {
+		s.log(ctx).Warn("Failed to estimate delivery for order", zap.Uint64("order_id", order.ID), zap.Error(err))
+		return nil
+	}
+
+	if err := s.order.SetOrderDeliveryEstimate(ctx, tx, order.ID, estimate.Window); err != nil {
+		return fmt.Errorf("failed to set order delivery estimate: %w", err)
+	}
+	order.EstimatedDelivery = &estimate.Window
+
+	return nil
+}
+
+// warehouseSchedule 回傳倉庫的出貨時程，未個別設定時使用預設值
+func (s *service) warehouseSchedule(location string) WarehouseSchedule {
+	if schedule, ok := s.warehouseSchedules[location]; ok {
+		return schedule
+	}
+	if schedule, ok := s.warehouseSchedules[""]; ok {
+		return schedule
+	}
+	return DefaultWarehouseSchedule
+}
+
+// deliveryDestination 優先使用指定的目的地，未指定國碼時從寄送地址解析
+func deliveryDestination(destination *models.DeliveryDestination, address json.RawMessage) (models.DeliveryDestination, error) {
+	if destination != nil && strings.TrimSpace(destination.Country) != "" {
+		return models.DeliveryDestination{
+			Country:    strings.ToUpper(strings.TrimSpace(destination.Country)),
+			PostalCode: strings.TrimSpace(destination.PostalCode),
+		}, nil
+	}
+	parsed, ok := models.ParseDeliveryDestination(address)
+	if !ok {
+		return models.DeliveryDestination{}, ErrDeliveryDestinationRequired
+	}
+	return parsed, nil
+}
+
+// isBusinessDay 週一至週五為工作天
+func isBusinessDay(day time.Time) bool {
+	weekday := day.Weekday()
+	return weekday != time.Saturday && weekday != time.Sunday
+}
+
+// addBusinessDays 回傳 day 之後第 n 個工作天，n 為 0 時回傳 day 本身
+func addBusinessDays(day time.Time, n int) time.Time {
+	for n > 0 {
+		day = day.AddDate(0, 0, 1)
+		if isBusinessDay(day) {
+			n--
+		}
+	}
+	return day
+}
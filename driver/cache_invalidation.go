@@ -0,0 +1,114 @@
+package driver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+	"goflare.io/ember"
+
+	"gofalre.io/shop/sqlc"
+)
+
+const (
+	// CacheInvalidationChannel 為各 shop 實例之間同步快取失效的 LISTEN/NOTIFY 頻道
+	CacheInvalidationChannel = "shop_cache_invalidation"
+	// cacheListenRetryDelay 監聽連線中斷後等待重新連線的時間
+	cacheListenRetryDelay = 5 * time.Second
+)
+
+// cacheInvalidation 為快取失效通知的內容
+type cacheInvalidation struct {
+	Keys []string `json:"keys"`
+}
+
+// NotifyCacheInvalidation 在交易內通知所有實例刪除指定的快取；通知在交易提交後才會送出，交易回滾時不會送出
+func NotifyCacheInvalidation(ctx context.Context, tx pgx.Tx, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(cacheInvalidation{Keys: keys})
+	if err != nil {
+		return fmt.Errorf("failed to encode cache invalidation: %w", err)
+	}
+
+	return sqlc.New(tx).NotifyCacheInvalidation(ctx, sqlc.NotifyCacheInvalidationParams{
+		Channel: CacheInvalidationChannel,
+		Payload: string(payload),
+	})
+}
+
+// CacheInvalidationListener 以專用連線 LISTEN 快取失效頻道，收到通知時刪除本實例的 ember 快取，
+// 讓其他實例的異動不必等到快取過期才生效；監聽中斷期間遺漏的通知仍依快取的 TTL 過期
+type CacheInvalidationListener struct {
+	conn   PostgresPool
+	cache  *ember.Ember
+	logger *zap.Logger
+}
+
+// NewCacheInvalidationListener 建立快取失效監聽器，cache 須與 repository 使用同一個 ember 實例
+func NewCacheInvalidationListener(conn PostgresPool, cache *ember.Ember, logger *zap.Logger) *CacheInvalidationListener {
+	return &CacheInvalidationListener{
+		conn:   conn,
+		cache:  cache,
+		logger: logger,
+	}
+}
+
+// Run 持續監聽直到 ctx 結束，連線中斷時自動重新連線
+func (l *CacheInvalidationListener) Run(ctx context.Context) error {
+	for {
+		err := l.listen(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		l.logger.Warn("Cache invalidation listener disconnected, reconnecting", zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(cacheListenRetryDelay):
+		}
+	}
+}
+
+// listen 從連線池取出一條連線專門 LISTEN，結束時關閉該連線而不是放回連線池
+func (l *CacheInvalidationListener) listen(ctx context.Context) error {
+	pooled, err := l.conn.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err = conn.Exec(ctx, "LISTEN "+pgx.Identifier{CacheInvalidationChannel}.Sanitize()); err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", CacheInvalidationChannel, err)
+	}
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		l.invalidate(ctx, notification.Payload)
+	}
+}
+
+// invalidate 刪除通知中列出的快取，格式錯誤的通知只記錄警告
+func (l *CacheInvalidationListener) invalidate(ctx context.Context, payload string) {
+	var invalidation cacheInvalidation
+	if err := json.Unmarshal([]byte(payload), &invalidation); err != nil {
+		l.logger.Warn("Ignoring malformed cache invalidation", zap.String("payload", payload), zap.Error(err))
+		return
+	}
+
+	for _, key := range invalidation.Keys {
+		if err := l.cache.Delete(ctx, key); err != nil {
+			l.logger.Warn("Failed to invalidate cache", zap.String("key", key), zap.Error(err))
+		}
+	}
+}
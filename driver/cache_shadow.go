@@ -0,0 +1,300 @@
+package driver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"math/rand/v2"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+	"goflare.io/ember"
+)
+
+const (
+	// defaultShadowConcurrency 未設定時同時進行的比對上限
+	defaultShadowConcurrency = 8
+	// defaultShadowTimeout 未設定時每次比對讀取資料庫的時限
+	defaultShadowTimeout = 2 * time.Second
+)
+
+// CacheShadowOptions 設定快取影子讀取
+type CacheShadowOptions struct {
+	// SampleRate 快取命中時進行比對的比例，介於 0 與 1 之間，0 表示每次命中都比對
+	SampleRate float64
+	// Concurrency 同時進行的比對上限，已滿時略過該次比對
+	Concurrency int
+	// Timeout 每次比對讀取資料庫的時限
+	Timeout time.Duration
+}
+
+// ShadowStats 單一快取種類（快取 key 的第一段，例如 order、cart_item）的比對統計；
+// Raced 為比對期間快取已被更新或刪除，無法判斷是否不一致的次數
+type ShadowStats struct {
+	Prefix     string `json:"prefix"`
+	Checks     uint64 `json:"checks"`
+	Mismatches uint64 `json:"mismatches"`
+	Raced      uint64 `json:"raced"`
+	Errors     uint64 `json:"errors"`
+}
+
+// ShadowMetrics 快取影子讀取的累計指標快照，Skipped 為比對已達上限而略過的次數
+type ShadowMetrics struct {
+	Checks     uint64         `json:"checks"`
+	Mismatches uint64         `json:"mismatches"`
+	Skipped    uint64         `json:"skipped"`
+	ByPrefix   []*ShadowStats `json:"by_prefix"`
+}
+
+// CacheShadow 在 repository 的快取命中時於背景從資料庫讀取同一筆資料並比對，不一致時記錄警告與指標，
+// 回傳給呼叫端的仍是快取的結果；用於驗證快取一致性的修正，確認沒有不一致後再延長快取的 TTL。
+// 比對讀取的是已提交的資料，不使用呼叫端的交易
+type CacheShadow struct {
+	cache *ember.Ember
+	opts  CacheShadowOptions
+	slots chan struct{}
+
+	checks     atomic.Uint64
+	mismatches atomic.Uint64
+	skipped    atomic.Uint64
+
+	mu    sync.Mutex
+	stats map[string]*ShadowStats
+
+	logger *zap.Logger
+}
+
+// NewCacheShadow 建立快取影子讀取，cache 須與 repository 使用同一個 ember 實例；
+// 以 WithCacheShadow 傳給各 repository 的建構函式後啟用
+func NewCacheShadow(cache *ember.Ember, opts CacheShadowOptions, logger *zap.Logger) *CacheShadow {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = defaultShadowConcurrency
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultShadowTimeout
+	}
+
+	return &CacheShadow{
+		cache:  cache,
+		opts:   opts,
+		slots:  make(chan struct{}, opts.Concurrency),
+		stats:  make(map[string]*ShadowStats),
+		logger: logger,
+	}
+}
+
+// Metrics 回傳目前的累計指標，ByPrefix 依不一致次數由高到低排序
+func (c *CacheShadow) Metrics() ShadowMetrics {
+	c.mu.Lock()
+	byPrefix := make([]*ShadowStats, 0, len(c.stats))
+	for _, stats := range c.stats {
+		snapshot := *stats
+		byPrefix = append(byPrefix, &snapshot)
+	}
+	c.mu.Unlock()
+
+	sort.Slice(byPrefix, func(i, j int) bool {
+		if byPrefix[i].Mismatches != byPrefix[j].Mismatches {
+			return byPrefix[i].Mismatches > byPrefix[j].Mismatches
+		}
+		return byPrefix[i].Prefix < byPrefix[j].Prefix
+	})
+
+	return ShadowMetrics{
+		Checks:     c.checks.Load(),
+		Mismatches: c.mismatches.Load(),
+		Skipped:    c.skipped.Load(),
+		ByPrefix:   byPrefix,
+	}
+}
+
+// ExpvarFunc 以 expvar 形式輸出指標，可透過 expvar.Publish 掛到 /debug/vars
+func (c *CacheShadow) ExpvarFunc() expvar.Func {
+	return func() any {
+		return c.Metrics()
+	}
+}
+
+// Verify 在快取命中後呼叫，cached 為從快取解出的值（指標），fetch 不使用交易從資料庫讀取同一筆資料，
+// 查無資料時回傳 pgx.ErrNoRows；比對在背景進行，不影響呼叫端。c 為 nil 時不做任何事
+func (c *CacheShadow) Verify(ctx context.Context, key string, cached any, fetch func(ctx context.Context) (any, error)) {
+	if c == nil {
+		return
+	}
+	if c.opts.SampleRate > 0 && c.opts.SampleRate < 1 && rand.Float64() >= c.opts.SampleRate {
+		return
+	}
+
+	// 呼叫端可能修改回傳的值，先在此取得快取內容
+	cachedJSON, err := json.Marshal(cached)
+	if err != nil {
+		c.logger.Warn("Failed to encode cached value for shadow read", zap.String("key", key), zap.Error(err))
+		return
+	}
+
+	select {
+	case c.slots <- struct{}{}:
+	default:
+		c.skipped.Add(1)
+		return
+	}
+
+	go func() {
+		defer func() { <-c.slots }()
+
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.opts.Timeout)
+		defer cancel()
+
+		c.compare(ctx, key, reflect.TypeOf(cached), cachedJSON, fetch)
+	}()
+}
+
+// compare 讀取資料庫並與快取內容比對
+func (c *CacheShadow) compare(ctx context.Context, key string, cachedType reflect.Type, cachedJSON []byte, fetch func(ctx context.Context) (any, error)) {
+	c.checks.Add(1)
+	c.record(key, func(stats *ShadowStats) { stats.Checks++ })
+
+	fresh, err := fetch(ctx)
+	if errors.Is(err, pgx.ErrNoRows) {
+		fresh, err = nil, nil
+	}
+	if err != nil {
+		c.record(key, func(stats *ShadowStats) { stats.Errors++ })
+		c.logger.Warn("Shadow read failed", zap.String("key", key), zap.Error(err))
+		return
+	}
+
+	freshJSON, err := json.Marshal(fresh)
+	if err != nil {
+		c.record(key, func(stats *ShadowStats) { stats.Errors++ })
+		c.logger.Warn("Failed to encode shadow read result", zap.String("key", key), zap.Error(err))
+		return
+	}
+	if bytes.Equal(cachedJSON, freshJSON) {
+		return
+	}
+
+	// 比對期間快取已被更新或刪除時，差異可能來自同時進行的寫入
+	if c.cacheChanged(ctx, key, cachedType, cachedJSON) {
+		c.record(key, func(stats *ShadowStats) { stats.Raced++ })
+		return
+	}
+
+	c.mismatches.Add(1)
+	c.record(key, func(stats *ShadowStats) { stats.Mismatches++ })
+	c.logger.Warn("Cache mismatch detected by shadow read",
+		zap.String("key", key), zap.Strings("fields", diffFields(cachedJSON, freshJSON)))
+}
+
+// cacheChanged 回傳快取目前的內容是否已與比對開始時不同
+func (c *CacheShadow) cacheChanged(ctx context.Context, key string, cachedType reflect.Type, cachedJSON []byte) bool {
+	if cachedType == nil || cachedType.Kind() != reflect.Pointer {
+		return false
+	}
+
+	current := reflect.New(cachedType.Elem())
+	found, err := c.cache.Get(ctx, key, current.Interface())
+	if err != nil || !found {
+		return true
+	}
+
+	currentJSON, err := json.Marshal(current.Interface())
+	return err != nil || !bytes.Equal(currentJSON, cachedJSON)
+}
+
+// record 以 key 的第一段累計統計
+func (c *CacheShadow) record(key string, update func(*ShadowStats)) {
+	prefix, _, _ := strings.Cut(key, ":")
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats, ok := c.stats[prefix]
+	if !ok {
+		stats = &ShadowStats{Prefix: prefix}
+		c.stats[prefix] = stats
+	}
+	update(stats)
+}
+
+// diffFields 列出兩個 JSON 物件中值不同的欄位，不是物件時以 value 表示整個值不同；
+// 陣列逐一比對，欄位以索引為前綴，例如 [2].quantity
+func diffFields(a, b []byte) []string {
+	var left, right any
+	if json.Unmarshal(a, &left) != nil || json.Unmarshal(b, &right) != nil {
+		return []string{"value"}
+	}
+
+	var fields []string
+	var walk func(path string, left, right any)
+	walk = func(path string, left, right any) {
+		leftMap, leftIsMap := left.(map[string]any)
+		rightMap, rightIsMap := right.(map[string]any)
+		leftList, leftIsList := left.([]any)
+		rightList, rightIsList := right.([]any)
+
+		switch {
+		case leftIsMap && rightIsMap:
+			names := make([]string, 0, len(leftMap)+len(rightMap))
+			for name := range leftMap {
+				names = append(names, name)
+			}
+			for name := range rightMap {
+				if _, ok := leftMap[name]; !ok {
+					names = append(names, name)
+				}
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				if !reflect.DeepEqual(leftMap[name], rightMap[name]) {
+					fields = append(fields, strings.TrimPrefix(path+"."+name, "."))
+				}
+			}
+		case leftIsList && rightIsList && len(leftList) == len(rightList):
+			for i := range leftList {
+				walk(path+"["+strconv.Itoa(i)+"]", leftList[i], rightList[i])
+			}
+		case !reflect.DeepEqual(left, right):
+			if path == "" {
+				path = "value"
+			}
+			fields = append(fields, path)
+		}
+	}
+	walk("", left, right)
+
+	return fields
+}
+
+// RepositoryOptions 為 repository 的選用設定
+type RepositoryOptions struct {
+	CacheShadow *CacheShadow
+}
+
+// RepositoryOption 用於調整 repository 的選用設定
+type RepositoryOption func(*RepositoryOptions)
+
+// WithCacheShadow 讓 repository 在快取命中時以影子讀取比對資料庫
+func WithCacheShadow(shadow *CacheShadow) RepositoryOption {
+	return func(o *RepositoryOptions) {
+		o.CacheShadow = shadow
+	}
+}
+
+// NewRepositoryOptions 套用 opts 後回傳 repository 的設定
+func NewRepositoryOptions(opts ...RepositoryOption) RepositoryOptions {
+	var options RepositoryOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
@@ -0,0 +1,331 @@
+package driver
+
+import (
+	"context"
+	"expvar"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+)
+
+const (
+	// defaultSlowQueryThreshold 未設定時超過此耗時的查詢會被記錄為慢查詢
+	defaultSlowQueryThreshold = 200 * time.Millisecond
+	// defaultRepeatedQueryThreshold 未設定時同一筆交易內相同查詢執行達此次數會被視為 N+1
+	defaultRepeatedQueryThreshold = 10
+)
+
+// InstrumentOptions 設定查詢監測的門檻
+type InstrumentOptions struct {
+	// SlowQueryThreshold 查詢耗時超過此值時記錄警告
+	SlowQueryThreshold time.Duration
+	// RepeatedQueryThreshold 同一筆交易內相同的 sqlc 查詢執行達此次數時記錄 N+1 警告
+	RepeatedQueryThreshold int
+}
+
+// QueryStats 單一 sqlc 查詢的累計統計
+type QueryStats struct {
+	Name          string        `json:"name"`
+	Calls         uint64        `json:"calls"`
+	Slow          uint64        `json:"slow"`
+	NPlusOne      uint64        `json:"n_plus_one"`
+	TotalDuration time.Duration `json:"total_duration"`
+	MaxDuration   time.Duration `json:"max_duration"`
+}
+
+// QueryMetrics 查詢監測的累計指標快照
+type QueryMetrics struct {
+	Queries     uint64        `json:"queries"`
+	SlowQueries uint64        `json:"slow_queries"`
+	NPlusOne    uint64        `json:"n_plus_one"`
+	ByQuery     []*QueryStats `json:"by_query"`
+}
+
+// InstrumentedPool 包裝 PostgresPool，記錄慢查詢並以交易為單位計算各查詢的執行次數，
+// 交易結束時若同一查詢重複執行過多次（例如逐項 GetStock）即記錄為 N+1
+type InstrumentedPool struct {
+	PostgresPool
+
+	opts InstrumentOptions
+
+	queries     atomic.Uint64
+	slowQueries atomic.Uint64
+	nPlusOne    atomic.Uint64
+
+	mu    sync.Mutex
+	stats map[string]*QueryStats
+
+	logger *zap.Logger
+}
+
+var _ PostgresPool = (*InstrumentedPool)(nil)
+
+// NewInstrumentedPool 建立監測用的連線池，傳給 NewTransactionManager 與各 repository 取代原本的 pool
+func NewInstrumentedPool(pool PostgresPool, opts InstrumentOptions, logger *zap.Logger) *InstrumentedPool {
+	if opts.SlowQueryThreshold <= 0 {
+		opts.SlowQueryThreshold = defaultSlowQueryThreshold
+	}
+	if opts.RepeatedQueryThreshold <= 0 {
+		opts.RepeatedQueryThreshold = defaultRepeatedQueryThreshold
+	}
+
+	return &InstrumentedPool{
+		PostgresPool: pool,
+		opts:         opts,
+		stats:        make(map[string]*QueryStats),
+		logger:       logger,
+	}
+}
+
+// Metrics 回傳目前的累計指標，ByQuery 依總耗時由高到低排序
+func (p *InstrumentedPool) Metrics() QueryMetrics {
+	p.mu.Lock()
+	byQuery := make([]*QueryStats, 0, len(p.stats))
+	for _, stats := range p.stats {
+		snapshot := *stats
+		byQuery = append(byQuery, &snapshot)
+	}
+	p.mu.Unlock()
+
+	sort.Slice(byQuery, func(i, j int) bool {
+		return byQuery[i].TotalDuration > byQuery[j].TotalDuration
+	})
+
+	return QueryMetrics{
+		Queries:     p.queries.Load(),
+		SlowQueries: p.slowQueries.Load(),
+		NPlusOne:    p.nPlusOne.Load(),
+		ByQuery:     byQuery,
+	}
+}
+
+// ExpvarFunc 以 expvar 形式輸出指標，可透過 expvar.Publish 掛到 /debug/vars
+func (p *InstrumentedPool) ExpvarFunc() expvar.Func {
+	return func() any {
+		return p.Metrics()
+	}
+}
+
+func (p *InstrumentedPool) BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error) {
+	tx, err := p.PostgresPool.BeginTx(ctx, txOptions)
+	if err != nil {
+		return nil, err
+	}
+	return &instrumentedTx{Tx: tx, pool: p, scope: newQueryScope()}, nil
+}
+
+func (p *InstrumentedPool) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
+	defer p.observe(ctx, nil, sql, time.Now())
+	return p.PostgresPool.Exec(ctx, sql, arguments...)
+}
+
+func (p *InstrumentedPool) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	defer p.observe(ctx, nil, sql, time.Now())
+	return p.PostgresPool.Query(ctx, sql, args...)
+}
+
+func (p *InstrumentedPool) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	defer p.observe(ctx, nil, sql, time.Now())
+	return p.PostgresPool.QueryRow(ctx, sql, args...)
+}
+
+func (p *InstrumentedPool) SendBatch(ctx context.Context, batch *pgx.Batch) pgx.BatchResults {
+	defer p.observeBatch(ctx, nil, batch, time.Now())
+	return p.PostgresPool.SendBatch(ctx, batch)
+}
+
+// observe 記錄一次查詢，scope 為 nil 表示不在交易內，不參與 N+1 計算
+func (p *InstrumentedPool) observe(ctx context.Context, scope *queryScope, sql string, start time.Time) {
+	elapsed := time.Since(start)
+	name := queryName(sql)
+	slow := elapsed >= p.opts.SlowQueryThreshold
+
+	p.queries.Add(1)
+	if slow {
+		p.slowQueries.Add(1)
+		p.logger.Warn("Slow query",
+			zap.String("query", name),
+			zap.Duration("elapsed", elapsed),
+			zap.Duration("threshold", p.opts.SlowQueryThreshold),
+			zap.Bool("context_done", ctx.Err() != nil))
+	}
+
+	p.mu.Lock()
+	stats := p.statsFor(name)
+	stats.Calls++
+	stats.TotalDuration += elapsed
+	if elapsed > stats.MaxDuration {
+		stats.MaxDuration = elapsed
+	}
+	if slow {
+		stats.Slow++
+	}
+	p.mu.Unlock()
+
+	if scope != nil {
+		scope.add(name, 1)
+	}
+}
+
+// observeBatch 批次中的每個查詢各計一次，耗時以整個批次送出的時間計算
+func (p *InstrumentedPool) observeBatch(ctx context.Context, scope *queryScope, batch *pgx.Batch, start time.Time) {
+	elapsed := time.Since(start)
+	p.queries.Add(uint64(batch.Len()))
+	if elapsed >= p.opts.SlowQueryThreshold {
+		p.slowQueries.Add(1)
+		p.logger.Warn("Slow batch",
+			zap.Int("queries", batch.Len()),
+			zap.Duration("elapsed", elapsed),
+			zap.Duration("threshold", p.opts.SlowQueryThreshold),
+			zap.Bool("context_done", ctx.Err() != nil))
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, queued := range batch.QueuedQueries {
+		name := queryName(queued.SQL)
+		stats := p.statsFor(name)
+		stats.Calls++
+		if scope != nil {
+			scope.add(name, 1)
+		}
+	}
+}
+
+// report 在交易結束時檢查重複執行的查詢
+func (p *InstrumentedPool) report(scope *queryScope) {
+	for name, count := range scope.finish() {
+		if count < p.opts.RepeatedQueryThreshold {
+			continue
+		}
+
+		p.nPlusOne.Add(1)
+		p.mu.Lock()
+		p.statsFor(name).NPlusOne++
+		p.mu.Unlock()
+
+		p.logger.Warn("Possible N+1 query pattern",
+			zap.String("query", name),
+			zap.Int("count", count),
+			zap.Int("threshold", p.opts.RepeatedQueryThreshold))
+	}
+}
+
+// statsFor 呼叫端需持有 p.mu
+func (p *InstrumentedPool) statsFor(name string) *QueryStats {
+	stats, ok := p.stats[name]
+	if !ok {
+		stats = &QueryStats{Name: name}
+		p.stats[name] = stats
+	}
+	return stats
+}
+
+// queryScope 累計單一交易內各查詢的執行次數
+type queryScope struct {
+	mu       sync.Mutex
+	counts   map[string]int
+	finished bool
+}
+
+func newQueryScope() *queryScope {
+	return &queryScope{counts: make(map[string]int)}
+}
+
+func (s *queryScope) add(name string, n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.counts[name] += n
+}
+
+// finish 回傳累計的次數，只有第一次呼叫會回傳結果，避免 Commit 後再 Rollback 時重複回報
+func (s *queryScope) finish() map[string]int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.finished {
+		return nil
+	}
+	s.finished = true
+	return s.counts
+}
+
+// instrumentedTx 包裝 pgx.Tx，交易內的查詢計入同一個 queryScope
+type instrumentedTx struct {
+	pgx.Tx
+	pool  *InstrumentedPool
+	scope *queryScope
+}
+
+func (t *instrumentedTx) Begin(ctx context.Context) (pgx.Tx, error) {
+	tx, err := t.Tx.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// 巢狀交易（savepoint）與外層交易共用計數
+	return &nestedInstrumentedTx{instrumentedTx{Tx: tx, pool: t.pool, scope: t.scope}}, nil
+}
+
+func (t *instrumentedTx) Commit(ctx context.Context) error {
+	defer t.pool.report(t.scope)
+	return t.Tx.Commit(ctx)
+}
+
+func (t *instrumentedTx) Rollback(ctx context.Context) error {
+	defer t.pool.report(t.scope)
+	return t.Tx.Rollback(ctx)
+}
+
+func (t *instrumentedTx) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
+	defer t.pool.observe(ctx, t.scope, sql, time.Now())
+	return t.Tx.Exec(ctx, sql, arguments...)
+}
+
+func (t *instrumentedTx) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	defer t.pool.observe(ctx, t.scope, sql, time.Now())
+	return t.Tx.Query(ctx, sql, args...)
+}
+
+func (t *instrumentedTx) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	defer t.pool.observe(ctx, t.scope, sql, time.Now())
+	return t.Tx.QueryRow(ctx, sql, args...)
+}
+
+func (t *instrumentedTx) SendBatch(ctx context.Context, batch *pgx.Batch) pgx.BatchResults {
+	defer t.pool.observeBatch(ctx, t.scope, batch, time.Now())
+	return t.Tx.SendBatch(ctx, batch)
+}
+
+// nestedInstrumentedTx 為 savepoint，結束時不回報，由外層交易統一回報
+type nestedInstrumentedTx struct {
+	instrumentedTx
+}
+
+func (t *nestedInstrumentedTx) Commit(ctx context.Context) error {
+	return t.Tx.Commit(ctx)
+}
+
+func (t *nestedInstrumentedTx) Rollback(ctx context.Context) error {
+	return t.Tx.Rollback(ctx)
+}
+
+// queryName 從 sqlc 產生的 "-- name: X :one" 註解取出查詢名稱，非 sqlc 的查詢則使用第一行
+func queryName(sql string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(sql), "\n")
+	if rest, ok := strings.CutPrefix(line, "-- name: "); ok {
+		name, _, _ := strings.Cut(rest, " ")
+		return name
+	}
+	if len(line) > 80 {
+		line = line[:80]
+	}
+	return line
+}
@@ -0,0 +1,65 @@
+package driver
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// 各模組的名稱，用於 LogOptions.Modules 的索引與 logger 名稱
+const (
+	LogModuleCart     = "cart"
+	LogModuleCategory = "category"
+	LogModuleOrder    = "order"
+	LogModuleStock    = "stock"
+	LogModulePrice    = "price"
+	LogModuleCampaign = "campaign"
+	LogModuleEvent    = "event"
+	LogModuleShop     = "shop"
+	LogModuleNats     = "nats"
+	LogModuleWorker   = "worker"
+)
+
+// LogSampling 設定記錄取樣：每個 Tick 內相同等級與訊息的記錄只保留前 First 筆，之後每 Thereafter 筆保留一筆
+type LogSampling struct {
+	Tick       time.Duration
+	First      int
+	Thereafter int
+}
+
+// LogConfig 單一模組的記錄設定，Level 的零值為 Info；Sampling 為 nil 時不取樣
+type LogConfig struct {
+	Level    zapcore.Level
+	Sampling *LogSampling
+}
+
+// LogOptions 各模組的記錄設定，未列在 Modules 中的模組套用 Default
+type LogOptions struct {
+	Default LogConfig
+	Modules map[string]LogConfig
+}
+
+// For 回傳模組適用的記錄設定
+func (o LogOptions) For(module string) LogConfig {
+	if config, ok := o.Modules[module]; ok {
+		return config
+	}
+	return o.Default
+}
+
+// ModuleLogger 以 base 建立模組專用的 logger，再透過各 repository 與 service 的建構函式注入；
+// 設定的等級只能比 base 更嚴格，低於 base 的等級會被忽略
+func ModuleLogger(base *zap.Logger, module string, opts LogOptions) *zap.Logger {
+	config := opts.For(module)
+
+	return base.Named(module).WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		if leveled, err := zapcore.NewIncreaseLevelCore(core, config.Level); err == nil {
+			core = leveled
+		}
+		if sampling := config.Sampling; sampling != nil {
+			core = zapcore.NewSamplerWithOptions(core, sampling.Tick, sampling.First, sampling.Thereafter)
+		}
+		return core
+	}))
+}
@@ -0,0 +1,311 @@
+package driver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"gofalre.io/shop/models"
+)
+
+const openSearchTimeout = 10 * time.Second
+
+// OpenSearchConfig 連線到 OpenSearch（或相容的 Elasticsearch）的設定，
+// 訂單與商品分別存放在 <IndexPrefix>-orders 與 <IndexPrefix>-products 兩個索引
+type OpenSearchConfig struct {
+	Endpoint    string
+	IndexPrefix string
+	Username    string
+	Password    string
+	// Client 為空時使用逾時 10 秒的 http.Client
+	Client *http.Client
+}
+
+// OpenSearchIndexer 以 OpenSearch 索引與搜尋訂單及商品，搜尋時允許拼字錯誤（fuzziness AUTO）
+type OpenSearchIndexer struct {
+	config OpenSearchConfig
+	client *http.Client
+}
+
+func NewOpenSearchIndexer(config OpenSearchConfig) *OpenSearchIndexer {
+	client := config.Client
+	if client == nil {
+		client = &http.Client{Timeout: openSearchTimeout}
+	}
+	config.Endpoint = strings.TrimRight(config.Endpoint, "/")
+
+	return &OpenSearchIndexer{
+		config: config,
+		client: client,
+	}
+}
+
+func (o *OpenSearchIndexer) ordersIndex() string {
+	return o.config.IndexPrefix + "-orders"
+}
+
+func (o *OpenSearchIndexer) productsIndex() string {
+	return o.config.IndexPrefix + "-products"
+}
+
+// CreateIndices 以搜尋需要的 mapping 建立訂單與商品索引，索引已存在時略過
+func (o *OpenSearchIndexer) CreateIndices(ctx context.Context) error {
+	keyword := map[string]any{"type": "keyword"}
+	text := map[string]any{"type": "text"}
+	indices := map[string]map[string]any{
+		o.ordersIndex(): {
+			"id":              map[string]any{"type": "long"},
+			"order_number":    keyword,
+			"customer_id":     keyword,
+			"status":          keyword,
+			"currency":        keyword,
+			"total":           map[string]any{"type": "double"},
+			"product_names":   text,
+			"metadata_values": text,
+			"created_at":      map[string]any{"type": "date"},
+		},
+		o.productsIndex(): {
+			"product_id":  keyword,
+			"locale":      keyword,
+			"name":        text,
+			"description": text,
+			"updated_at":  map[string]any{"type": "date"},
+		},
+	}
+
+	for index, properties := range indices {
+		body := map[string]any{"mappings": map[string]any{"properties": properties}}
+		err := o.do(ctx, http.MethodPut, "/"+index, body, nil)
+		if err != nil && !strings.Contains(err.Error(), "resource_already_exists_exception") {
+			return fmt.Errorf("failed to create index %s: %w", index, err)
+		}
+	}
+
+	return nil
+}
+
+// IndexOrders 新增或覆寫訂單文件
+func (o *OpenSearchIndexer) IndexOrders(ctx context.Context, docs []*models.OrderDocument) error {
+	ops := make([]bulkOperation, 0, len(docs))
+	for _, doc := range docs {
+		ops = append(ops, bulkOperation{action: "index", index: o.ordersIndex(), id: doc.DocumentID(), doc: doc})
+	}
+	return o.bulk(ctx, ops)
+}
+
+// DeleteOrders 刪除訂單文件，文件不存在時略過
+func (o *OpenSearchIndexer) DeleteOrders(ctx context.Context, ids []string) error {
+	ops := make([]bulkOperation, 0, len(ids))
+	for _, id := range ids {
+		ops = append(ops, bulkOperation{action: "delete", index: o.ordersIndex(), id: id})
+	}
+	return o.bulk(ctx, ops)
+}
+
+// SearchOrders 以訂單編號、客戶 ID、商品名稱與 metadata 搜尋訂單，依相關度排序
+func (o *OpenSearchIndexer) SearchOrders(ctx context.Context, query models.SearchQuery) (*models.SearchHits, error) {
+	body := map[string]any{
+		"from":             query.Offset,
+		"size":             query.Limit,
+		"track_total_hits": true,
+		"_source":          []string{"id"},
+		"query": map[string]any{
+			"multi_match": map[string]any{
+				"query":     query.Text,
+				"fields":    []string{"order_number^3", "customer_id^2", "product_names", "metadata_values"},
+				"fuzziness": "AUTO",
+			},
+		},
+	}
+
+	var resp searchResponse[struct {
+		ID uint64 `json:"id"`
+	}]
+	if err := o.do(ctx, http.MethodPost, "/"+o.ordersIndex()+"/_search", body, &resp); err != nil {
+		return nil, err
+	}
+
+	hits := &models.SearchHits{IDs: make([]string, 0, len(resp.Hits.Hits)), TotalCount: resp.Hits.Total.Value}
+	for _, hit := range resp.Hits.Hits {
+		hits.IDs = append(hits.IDs, models.OrderDocumentID(hit.Source.ID))
+	}
+
+	return hits, nil
+}
+
+// IndexProducts 新增或覆寫商品翻譯文件
+func (o *OpenSearchIndexer) IndexProducts(ctx context.Context, docs []*models.ProductDocument) error {
+	ops := make([]bulkOperation, 0, len(docs))
+	for _, doc := range docs {
+		ops = append(ops, bulkOperation{action: "index", index: o.productsIndex(), id: doc.DocumentID(), doc: doc})
+	}
+	return o.bulk(ctx, ops)
+}
+
+// DeleteProducts 刪除商品翻譯文件，文件不存在時略過
+func (o *OpenSearchIndexer) DeleteProducts(ctx context.Context, ids []string) error {
+	ops := make([]bulkOperation, 0, len(ids))
+	for _, id := range ids {
+		ops = append(ops, bulkOperation{action: "delete", index: o.productsIndex(), id: id})
+	}
+	return o.bulk(ctx, ops)
+}
+
+// SearchProducts 以商品名稱與描述搜尋商品，同一商品的多個語系只回傳一次，依相關度排序
+func (o *OpenSearchIndexer) SearchProducts(ctx context.Context, query models.SearchQuery) (*models.SearchHits, error) {
+	boolQuery := map[string]any{
+		"must": map[string]any{
+			"multi_match": map[string]any{
+				"query":     query.Text,
+				"fields":    []string{"name^2", "description"},
+				"fuzziness": "AUTO",
+			},
+		},
+	}
+	if query.Locale != "" {
+		boolQuery["filter"] = map[string]any{"term": map[string]any{"locale": query.Locale}}
+	}
+	body := map[string]any{
+		"from":     query.Offset,
+		"size":     query.Limit,
+		"_source":  []string{"product_id"},
+		"query":    map[string]any{"bool": boolQuery},
+		"collapse": map[string]any{"field": "product_id"},
+		"aggs": map[string]any{
+			"products": map[string]any{"cardinality": map[string]any{"field": "product_id"}},
+		},
+	}
+
+	var resp searchResponse[struct {
+		ProductID string `json:"product_id"`
+	}]
+	if err := o.do(ctx, http.MethodPost, "/"+o.productsIndex()+"/_search", body, &resp); err != nil {
+		return nil, err
+	}
+
+	// collapse 後 hits.total 仍是文件數，商品數以 cardinality 彙總（近似值）為準
+	hits := &models.SearchHits{IDs: make([]string, 0, len(resp.Hits.Hits)), TotalCount: resp.Aggregations.Products.Value}
+	for _, hit := range resp.Hits.Hits {
+		hits.IDs = append(hits.IDs, hit.Source.ProductID)
+	}
+
+	return hits, nil
+}
+
+type searchResponse[T any] struct {
+	Hits struct {
+		Total struct {
+			Value uint64 `json:"value"`
+		} `json:"total"`
+		Hits []struct {
+			Source T `json:"_source"`
+		} `json:"hits"`
+	} `json:"hits"`
+	Aggregations struct {
+		Products struct {
+			Value uint64 `json:"value"`
+		} `json:"products"`
+	} `json:"aggregations"`
+}
+
+type bulkOperation struct {
+	action string
+	index  string
+	id     string
+	doc    any
+}
+
+type bulkResponse struct {
+	Errors bool                                `json:"errors"`
+	Items  []map[string]bulkResponseItemResult `json:"items"`
+}
+
+type bulkResponseItemResult struct {
+	ID     string          `json:"_id"`
+	Status int             `json:"status"`
+	Error  json.RawMessage `json:"error"`
+}
+
+// bulk 以 _bulk API 一次送出多個新增或刪除，任一文件失敗時回傳錯誤；刪除不存在的文件不視為失敗
+func (o *OpenSearchIndexer) bulk(ctx context.Context, ops []bulkOperation) error {
+	if len(ops) == 0 {
+		return nil
+	}
+
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, op := range ops {
+		meta := map[string]any{op.action: map[string]string{"_index": op.index, "_id": op.id}}
+		if err := encoder.Encode(meta); err != nil {
+			return err
+		}
+		if op.doc != nil {
+			if err := encoder.Encode(op.doc); err != nil {
+				return err
+			}
+		}
+	}
+
+	var resp bulkResponse
+	if err := o.send(ctx, http.MethodPost, "/_bulk", "application/x-ndjson", &body, &resp); err != nil {
+		return err
+	}
+	if !resp.Errors {
+		return nil
+	}
+
+	var errs []error
+	for _, item := range resp.Items {
+		for action, result := range item {
+			if result.Status < 300 || (action == "delete" && result.Status == http.StatusNotFound) {
+				continue
+			}
+			errs = append(errs, fmt.Errorf("%s %s: status %d: %s", action, result.ID, result.Status, result.Error))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// do 以 JSON 送出 request，out 不為 nil 時解析回應
+func (o *OpenSearchIndexer) do(ctx context.Context, method, path string, in, out any) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return o.send(ctx, method, path, "application/json", bytes.NewReader(body), out)
+}
+
+func (o *OpenSearchIndexer) send(ctx context.Context, method, path, contentType string, body io.Reader, out any) error {
+	req, err := http.NewRequestWithContext(ctx, method, o.config.Endpoint+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	if o.config.Username != "" {
+		req.SetBasicAuth(o.config.Username, o.config.Password)
+	}
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("opensearch %s %s failed: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("opensearch %s %s failed: status %d: %s", method, path, resp.StatusCode, detail)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode opensearch response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package driver
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+
+	"gofalre.io/shop/sqlc"
+)
+
+// ErrStreamingUnsupported 表示注入的 Querier 沒有實作 sqlc.Streamer，無法在交易外串流查詢結果
+var ErrStreamingUnsupported = errors.New("querier does not support streaming")
+
+// TxBinder 由注入的 sqlc.Querier 實作，回傳在 tx 中執行查詢的 Querier；
+// 未實作時 repository 在交易中改以 tx 直接建立 sqlc.Queries，注入的 Querier 只用於交易外的查詢
+type TxBinder interface {
+	BindTx(tx pgx.Tx) sqlc.Querier
+}
+
+// Queries 提供 repository 執行查詢使用的 sqlc.Querier，可注入包裝過的 Querier（例如攔截查詢、讀寫分離或測試替身）
+type Queries struct {
+	querier sqlc.Querier
+}
+
+// NewQueries 以 querier 建立 Queries，querier 可以是以連線池建立的 sqlc.Queries
+func NewQueries(querier sqlc.Querier) *Queries {
+	return &Queries{querier: querier}
+}
+
+// Querier 回傳交易外使用的 Querier
+func (q *Queries) Querier() sqlc.Querier {
+	return q.querier
+}
+
+// WithTx 回傳在 tx 中執行查詢的 Querier，tx 為 nil 時與 Querier 相同
+func (q *Queries) WithTx(tx pgx.Tx) sqlc.Querier {
+	if tx == nil {
+		return q.querier
+	}
+
+	switch querier := q.querier.(type) {
+	case *sqlc.Queries:
+		return querier.WithTx(tx)
+	case TxBinder:
+		return querier.BindTx(tx)
+	default:
+		return sqlc.New(tx)
+	}
+}
+
+// Streamer 回傳在 tx 中串流查詢結果的 sqlc.Streamer；注入的 Querier 沒有實作時在交易中改以 tx 直接執行，
+// 交易外回傳 ErrStreamingUnsupported
+func (q *Queries) Streamer(tx pgx.Tx) (sqlc.Streamer, error) {
+	if streamer, ok := q.WithTx(tx).(sqlc.Streamer); ok {
+		return streamer, nil
+	}
+	if tx != nil {
+		return sqlc.New(tx), nil
+	}
+	return nil, ErrStreamingUnsupported
+}
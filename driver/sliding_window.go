@@ -0,0 +1,84 @@
+package driver
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// slidingWindowScript 以 Redis 的時間移除視窗外的請求紀錄，未達上限時記錄本次請求；
+// 回傳 {是否允許, 視窗內剩餘的次數, 需要等待的毫秒數}，紀錄在視窗結束後自動過期
+var slidingWindowScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local windowMs = tonumber(ARGV[2])
+local now = redis.call('TIME')
+local nowMs = tonumber(now[1]) * 1000 + math.floor(tonumber(now[2]) / 1000)
+
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', nowMs - windowMs)
+local count = redis.call('ZCARD', KEYS[1])
+
+local allowed = 0
+local retryMs = 0
+if count < limit then
+  redis.call('ZADD', KEYS[1], nowMs, ARGV[3])
+  count = count + 1
+  allowed = 1
+else
+  local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+  retryMs = math.max(1, tonumber(oldest[2]) + windowMs - nowMs)
+end
+
+redis.call('PEXPIRE', KEYS[1], windowMs + 1000)
+return {allowed, limit - count, retryMs}
+`)
+
+// RedisSlidingWindow 以 Redis sorted set 實作跨實例共用的滑動視窗限流，每個 key 各自計數
+type RedisSlidingWindow struct {
+	client redis.Scripter
+	prefix string
+}
+
+// NewRedisSlidingWindow 建立滑動視窗限流，prefix 加在每個 key 前面以區隔不同用途
+func NewRedisSlidingWindow(client redis.Scripter, prefix string) *RedisSlidingWindow {
+	return &RedisSlidingWindow{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+// Take 在 key 最近 window 內的請求數未達 limit 時記錄本次請求並回傳 true 與剩餘次數，
+// 否則回傳 false 與視窗內最早一筆請求過期前需要等待的時間
+func (w *RedisSlidingWindow) Take(ctx context.Context, key string, limit int, window time.Duration) (bool, int, time.Duration, error) {
+	if limit <= 0 || window < time.Millisecond {
+		return false, 0, 0, errors.New("sliding window limit must be greater than zero and window at least one millisecond")
+	}
+
+	member, err := slidingWindowMember()
+	if err != nil {
+		return false, 0, 0, err
+	}
+
+	result, err := slidingWindowScript.Run(ctx, w.client, []string{w.prefix + key}, limit, window.Milliseconds(), member).Int64Slice()
+	if err != nil {
+		return false, 0, 0, fmt.Errorf("sliding window script failed: %w", err)
+	}
+	if len(result) != 3 {
+		return false, 0, 0, fmt.Errorf("unexpected sliding window result: %v", result)
+	}
+
+	return result[0] == 1, int(result[1]), time.Duration(result[2]) * time.Millisecond, nil
+}
+
+// slidingWindowMember 產生請求紀錄的唯一識別，同一毫秒內的請求才不會互相覆蓋
+func slidingWindowMember() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate sliding window member: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
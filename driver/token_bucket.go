@@ -0,0 +1,72 @@
+package driver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// tokenBucketScript 以 Redis 的時間計算補充的令牌並嘗試取用一個，
+// 回傳 {是否取得, 需要等待的毫秒數}；狀態在令牌補滿所需的時間後自動過期
+var tokenBucketScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = redis.call('TIME')
+local nowMs = tonumber(now[1]) * 1000 + math.floor(tonumber(now[2]) / 1000)
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1]) or burst
+local ts = tonumber(state[2]) or nowMs
+tokens = math.min(burst, tokens + math.max(0, nowMs - ts) / 1000 * rate)
+
+local allowed = 0
+local retryMs = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+else
+  retryMs = math.ceil((1 - tokens) / rate * 1000)
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', nowMs)
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst / rate * 1000) + 1000)
+return {allowed, retryMs}
+`)
+
+// RedisTokenBucket 以 Redis 實作跨實例共用的令牌桶，每秒補充 rate 個令牌，最多累積 burst 個
+type RedisTokenBucket struct {
+	client redis.Scripter
+	key    string
+	rate   float64
+	burst  int
+}
+
+// NewRedisTokenBucket 建立令牌桶，rate 與 burst 必須大於 0
+func NewRedisTokenBucket(client redis.Scripter, key string, rate float64, burst int) (*RedisTokenBucket, error) {
+	if rate <= 0 || burst <= 0 {
+		return nil, errors.New("token bucket rate and burst must be greater than zero")
+	}
+
+	return &RedisTokenBucket{
+		client: client,
+		key:    key,
+		rate:   rate,
+		burst:  burst,
+	}, nil
+}
+
+// Take 嘗試取用一個令牌，令牌不足時回傳 false 及下一個令牌補充前需要等待的時間
+func (b *RedisTokenBucket) Take(ctx context.Context) (bool, time.Duration, error) {
+	result, err := tokenBucketScript.Run(ctx, b.client, []string{b.key}, b.rate, b.burst).Int64Slice()
+	if err != nil {
+		return false, 0, fmt.Errorf("token bucket script failed: %w", err)
+	}
+	if len(result) != 2 {
+		return false, 0, fmt.Errorf("unexpected token bucket result: %v", result)
+	}
+
+	return result[0] == 1, time.Duration(result[1]) * time.Millisecond, nil
+}
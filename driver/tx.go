@@ -2,7 +2,9 @@ package driver
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -12,15 +14,53 @@ import (
 type TransactionManager struct {
 	conn   PostgresPool
 	logger *zap.Logger
+
+	hooksMu sync.Mutex
+	hooks   map[pgx.Tx]*commitHooks
+}
+
+// commitHooks 為交易 commit 成功後要執行的函式，同一個 key 只保留第一次註冊的函式
+type commitHooks struct {
+	keys map[string]struct{}
+	fns  []func()
 }
 
 func NewTransactionManager(conn PostgresPool, logger *zap.Logger) *TransactionManager {
 	return &TransactionManager{
 		conn:   conn,
 		logger: logger,
+		hooks:  make(map[pgx.Tx]*commitHooks),
 	}
 }
 
+// AfterCommit 註冊在 tx commit 成功後執行的 fn，rollback 時不會執行；同一個交易中相同 key 的 fn 只執行一次。
+// tx 為 nil 或不是由 TransactionManager 開啟的交易時直接執行 fn
+func (m *TransactionManager) AfterCommit(tx pgx.Tx, key string, fn func()) {
+	m.hooksMu.Lock()
+	hooks, ok := m.hooks[tx]
+	if ok {
+		if _, registered := hooks.keys[key]; !registered {
+			hooks.keys[key] = struct{}{}
+			hooks.fns = append(hooks.fns, fn)
+		}
+	}
+	m.hooksMu.Unlock()
+
+	if !ok {
+		fn()
+	}
+}
+
+// takeHooks 取出並移除交易註冊的 commit hook
+func (m *TransactionManager) takeHooks(tx pgx.Tx) *commitHooks {
+	m.hooksMu.Lock()
+	defer m.hooksMu.Unlock()
+
+	hooks := m.hooks[tx]
+	delete(m.hooks, tx)
+	return hooks
+}
+
 func (m *TransactionManager) ExecuteTransaction(ctx context.Context, fn func(tx pgx.Tx) error) error {
 	return m.ExecuteTransactionWithOptions(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead}, fn)
 }
@@ -29,13 +69,18 @@ func (m *TransactionManager) ExecuteSerializableTransaction(ctx context.Context,
 	return m.ExecuteTransactionWithRetry(ctx, pgx.TxOptions{IsoLevel: pgx.Serializable}, fn, 3)
 }
 
-func (m *TransactionManager) ExecuteTransactionWithOptions(ctx context.Context, opts pgx.TxOptions, fn func(tx pgx.Tx) error) error {
+func (m *TransactionManager) ExecuteTransactionWithOptions(ctx context.Context, opts pgx.TxOptions, fn func(tx pgx.Tx) error) (err error) {
 	dbTx, err := m.conn.BeginTx(ctx, opts)
 	if err != nil {
 		return fmt.Errorf("begin transaction failed: %w", err)
 	}
 
+	m.hooksMu.Lock()
+	m.hooks[dbTx] = &commitHooks{keys: make(map[string]struct{})}
+	m.hooksMu.Unlock()
+
 	defer func() {
+		hooks := m.takeHooks(dbTx)
 		if p := recover(); p != nil {
 			m.rollback(ctx, dbTx)
 			m.logger.Error("panic in transaction", zap.Any("panic", p))
@@ -45,11 +90,17 @@ func (m *TransactionManager) ExecuteTransactionWithOptions(ctx context.Context,
 		} else {
 			if err = dbTx.Commit(ctx); err != nil {
 				m.logger.Error("commit transaction failed", zap.Error(err))
+				return
+			}
+			for _, fn := range hooks.fns {
+				fn()
 			}
 		}
 	}()
 
-	return fn(dbTx)
+	// fn 的錯誤必須寫回 err，defer 才會 rollback 而不是 commit
+	err = fn(dbTx)
+	return err
 }
 
 func (m *TransactionManager) ExecuteTransactionWithRetry(ctx context.Context, opts pgx.TxOptions, fn func(tx pgx.Tx) error, maxRetries int) error {
@@ -78,3 +129,36 @@ func (m *TransactionManager) isRetryableError(err error) bool {
 	// 例如，可以檢查是否是由於並發衝突導致的錯誤
 	return true // 這裡簡化處理，實際使用時需要更精確的判斷
 }
+
+// Ping 確認資料庫可以連線並執行查詢
+func (m *TransactionManager) Ping(ctx context.Context) error {
+	if _, err := m.conn.Exec(ctx, "SELECT 1"); err != nil {
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
+	return nil
+}
+
+// ErrLockNotAcquired 表示 advisory lock 已被其他 session 持有
+var ErrLockNotAcquired = errors.New("advisory lock is held by another session")
+
+// TryAdvisoryXactLock 在 tx 內取得交易級別的 advisory lock，不等待：鎖已被其他交易持有時立即回傳 ErrLockNotAcquired；
+// 鎖在 tx commit 或 rollback 時釋放。應在交易的第一個查詢取得，交易快照才會包含前一個持有者提交的資料
+func (m *TransactionManager) TryAdvisoryXactLock(ctx context.Context, tx pgx.Tx, key string) error {
+	var acquired bool
+	if err := tx.QueryRow(ctx, "SELECT pg_try_advisory_xact_lock(hashtext($1))", key).Scan(&acquired); err != nil {
+		return fmt.Errorf("try advisory lock failed: %w", err)
+	}
+	if !acquired {
+		return ErrLockNotAcquired
+	}
+	return nil
+}
+
+// AdvisoryXactLock 在 tx 內取得交易級別的 advisory lock，鎖已被其他交易持有時等待對方 commit 或 rollback；
+// 鎖在 tx commit 或 rollback 時釋放。應在交易的第一個查詢取得，交易快照才會包含前一個持有者提交的資料
+func (m *TransactionManager) AdvisoryXactLock(ctx context.Context, tx pgx.Tx, key string) error {
+	if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock(hashtext($1))", key); err != nil {
+		return fmt.Errorf("acquire advisory lock failed: %w", err)
+	}
+	return nil
+}
@@ -6,9 +6,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/jackc/pgx/v5"
+	"gofalre.io/shop/event"
 	"gofalre.io/shop/models"
 	"gofalre.io/shop/models/enum"
 	"gofalre.io/shop/stock"
@@ -24,6 +27,16 @@ type EventManager struct {
 	natsConn *nats.Conn
 	handlers map[stripe.EventType]EventHandler
 	logger   *zap.Logger
+
+	mu            sync.Mutex
+	subscriptions []*eventSubscription
+}
+
+// eventSubscription 記錄訂閱的 subject 與處理函式，訂閱失效時可以重新建立
+type eventSubscription struct {
+	subject string
+	handler nats.MsgHandler
+	sub     *nats.Subscription
 }
 
 func NewEventManager(natsConn *nats.Conn, logger *zap.Logger) *EventManager {
@@ -44,16 +57,88 @@ func (em *EventManager) GetHandler(eventType stripe.EventType) (EventHandler, bo
 }
 
 func (em *EventManager) SubscribeToEvents(wp *WorkerPool) error {
-	if _, err := em.natsConn.Subscribe("payment.service.event.>", func(msg *nats.Msg) {
+	return em.subscribe(SubjectPaymentEvents, func(msg *nats.Msg) {
+		ctx := extractRequestMetadata(context.Background(), msg.Header)
+
 		var event stripe.Event
 		if err := json.Unmarshal(msg.Data, &event); err != nil {
-			em.logger.Error("Failed to unmarshal event", zap.Error(err))
+			LoggerFromContext(ctx, em.logger).Error("Failed to unmarshal event", zap.Error(err))
 			return
 		}
 
-		wp.Submit(context.Background(), &event)
-	}); err != nil {
-		em.logger.Error("Failed to subscribe", zap.Error(err))
+		wp.Submit(ctx, &event)
+	})
+}
+
+// SubscribeToReturnTracking 訂閱物流商的退貨追蹤事件，處理失敗只記錄錯誤
+func (em *EventManager) SubscribeToReturnTracking(handler func(context.Context, *models.ReturnTrackingEvent) error) error {
+	return em.subscribe(SubjectReturnTracking, func(msg *nats.Msg) {
+		ctx := extractRequestMetadata(context.Background(), msg.Header)
+
+		var event models.ReturnTrackingEvent
+		if err := json.Unmarshal(msg.Data, &event); err != nil {
+			LoggerFromContext(ctx, em.logger).Error("Failed to unmarshal return tracking event", zap.Error(err))
+			return
+		}
+
+		if err := handler(ctx, &event); err != nil {
+			LoggerFromContext(ctx, em.logger).Error("Failed to handle return tracking event",
+				zap.String("carrier", event.Carrier), zap.String("tracking_number", event.TrackingNumber), zap.Error(err))
+		}
+	})
+}
+
+// subscribe 訂閱 subject 並記錄下來，訂閱失效後由 EnsureSubscriptions 重新建立
+func (em *EventManager) subscribe(subject string, handler nats.MsgHandler) error {
+	sub, err := em.natsConn.Subscribe(subject, handler)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", subject, err)
+	}
+
+	em.mu.Lock()
+	em.subscriptions = append(em.subscriptions, &eventSubscription{subject: subject, handler: handler, sub: sub})
+	em.mu.Unlock()
+
+	return nil
+}
+
+// EnsureSubscriptions 重新建立已失效的訂閱（例如被伺服器取消或連線關閉後重新建立），回傳重新建立的數量
+func (em *EventManager) EnsureSubscriptions() (int, error) {
+	em.mu.Lock()
+	defer em.mu.Unlock()
+
+	var errs []error
+	restored := 0
+	for _, subscription := range em.subscriptions {
+		if subscription.sub.IsValid() {
+			continue
+		}
+		sub, err := em.natsConn.Subscribe(subscription.subject, subscription.handler)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to resubscribe to %s: %w", subscription.subject, err))
+			continue
+		}
+		subscription.sub = sub
+		restored++
+	}
+
+	return restored, errors.Join(errs...)
+}
+
+// Publish 將 payload 以 JSON 編碼後發佈到指定的 NATS subject，context 中的請求資訊會寫入訊息 header
+func (em *EventManager) Publish(ctx context.Context, subject string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	msg := nats.NewMsg(subject)
+	msg.Data = data
+	injectRequestMetadata(ctx, msg.Header)
+
+	if err = em.natsConn.PublishMsg(msg); err != nil {
+		LoggerFromContext(ctx, em.logger).Error("Failed to publish event", zap.String("subject", subject), zap.Error(err))
+		return err
 	}
 
 	return nil
@@ -62,6 +147,7 @@ func (em *EventManager) SubscribeToEvents(wp *WorkerPool) error {
 func (s *service) registerEventHandlers() {
 	eventHandlers := map[stripe.EventType]EventHandler{
 		// Payment Intent Events
+		stripe.EventTypePaymentIntentCreated:       s.handlePaymentIntentCreated,
 		stripe.EventTypePaymentIntentSucceeded:     s.handlePaymentIntentSucceeded,
 		stripe.EventTypePaymentIntentPaymentFailed: s.handlePaymentIntentPaymentFailed,
 		stripe.EventTypePaymentIntentCanceled:      s.handlePaymentIntentCanceled,
@@ -91,55 +177,111 @@ func (s *service) registerEventHandlers() {
 	}
 }
 
+// handlePaymentIntentCreated 不需要更新訂單，處理後用於讓等待此事件的後續事件繼續處理
+func (s *service) handlePaymentIntentCreated(ctx context.Context, event *stripe.Event) error {
+	s.log(ctx).Info("Handling PaymentIntent created event", zap.String("event_id", event.ID))
+	return nil
+}
+
 func (s *service) handlePaymentIntentSucceeded(ctx context.Context, event *stripe.Event) error {
-	s.logger.Info("Handling PaymentIntent succeeded event", zap.String("event_id", event.ID))
+	s.log(ctx).Info("Handling PaymentIntent succeeded event", zap.String("event_id", event.ID))
 
 	var paymentIntent stripe.PaymentIntent
 	if err := json.Unmarshal(event.Data.Raw, &paymentIntent); err != nil {
-		s.logger.Error("Failed to unmarshal PaymentIntent", zap.Error(err))
+		s.log(ctx).Error("Failed to unmarshal PaymentIntent", zap.Error(err))
 		return err
 	}
 
-	return s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+	var invoice *models.Invoice
+	if err := s.executeOrderTransaction(ctx, func(tx pgx.Tx) error {
 		// 根據 PaymentIntent ID 獲取訂單
 		order, err := s.order.GetOrderByPaymentIntentID(ctx, tx, paymentIntent.ID)
 		if err != nil {
-			s.logger.Error("Order not found for PaymentIntent", zap.String("payment_intent_id", paymentIntent.ID), zap.Error(err))
+			s.log(ctx).Error("Order not found for PaymentIntent", zap.String("payment_intent_id", paymentIntent.ID), zap.Error(err))
+			return err
+		}
+
+		// 略過快取重新讀取並鎖定訂單，避免以過期的 updated_at 更新
+		if order, err = s.order.GetOrderForUpdate(ctx, tx, order.ID); err != nil {
+			return fmt.Errorf("failed to get order for update: %w", err)
+		}
+
+		// 記錄卡片指紋，同一張卡被過多客戶使用時列入詐欺審核
+		if err = s.checkPaymentFingerprint(ctx, tx, order, &paymentIntent); err != nil {
 			return err
 		}
 
 		// 更新訂單狀態為已支付
 		if err = s.order.UpdateOrderStatus(ctx, tx, order.ID, enum.OrderStatusPaid, order.UpdatedAt); err != nil {
-			s.logger.Error("Failed to update order status to 'paid'", zap.Error(err))
+			s.log(ctx).Error("Failed to update order status to 'paid'", zap.Error(err))
+			return err
+		}
+		if err = s.startFulfillmentSLA(ctx, tx, order, time.Now()); err != nil {
+			return err
+		}
+
+		if err = s.mergeStripeMetadata(ctx, tx, order.ID, paymentIntent.Metadata); err != nil {
 			return err
 		}
 
-		s.logger.Info("Order status updated to 'paid'", zap.Uint64("order_id", order.ID))
+		// 付款成功後提交稅務交易
+		s.commitOrderTax(ctx, tx, order)
+
+		// 開立發票，文件在交易完成後產生
+		if invoice, err = s.issueInvoice(ctx, tx, order); err != nil {
+			return fmt.Errorf("failed to issue invoice: %w", err)
+		}
+
+		s.log(ctx).Info("Order status updated to 'paid'", zap.Uint64("order_id", order.ID))
 
 		return err
-	})
+	}); err != nil {
+		return err
+	}
+
+	s.renderInvoiceDocument(ctx, invoice)
+	return nil
 }
 
 func (s *service) handlePaymentIntentPaymentFailed(ctx context.Context, event *stripe.Event) error {
 
-	s.logger.Info("Handling PaymentIntent payment failed event", zap.String("event_id", event.ID))
+	s.log(ctx).Info("Handling PaymentIntent payment failed event", zap.String("event_id", event.ID))
 
 	var paymentIntent stripe.PaymentIntent
 	if err := json.Unmarshal(event.Data.Raw, &paymentIntent); err != nil {
-		s.logger.Error("Failed to unmarshal PaymentIntent", zap.Error(err))
+		s.log(ctx).Error("Failed to unmarshal PaymentIntent", zap.Error(err))
 		return err
 	}
 
-	return s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+	return s.executeOrderTransaction(ctx, func(tx pgx.Tx) error {
 		orderModel, err := s.order.GetOrderByPaymentIntentID(ctx, tx, paymentIntent.ID)
 		if err != nil {
 			return fmt.Errorf("獲取訂單失敗: %w", err)
 		}
 
+		// 略過快取重新讀取並鎖定訂單，避免以過期的 updated_at 更新
+		if orderModel, err = s.order.GetOrderForUpdate(ctx, tx, orderModel.ID); err != nil {
+			return fmt.Errorf("failed to get order for update: %w", err)
+		}
+
+		// 失敗的付款也記錄卡片指紋，盜刷測卡通常伴隨大量失敗的嘗試
+		if err = s.checkPaymentFingerprint(ctx, tx, orderModel, &paymentIntent); err != nil {
+			return err
+		}
+
+		// 設定了付款失敗的處理方式時，讓客戶可以繼續結帳
+		if s.checkoutRecovery != enum.CheckoutRecoveryPolicyFail {
+			return s.recoverFailedCheckout(ctx, tx, orderModel, paymentIntent.Metadata)
+		}
+
 		if err = s.order.UpdateOrderStatus(ctx, tx, orderModel.ID, enum.OrderStatusFailed, orderModel.UpdatedAt); err != nil {
 			return fmt.Errorf("更新訂單狀態失敗: %w", err)
 		}
 
+		if err = s.mergeStripeMetadata(ctx, tx, orderModel.ID, paymentIntent.Metadata); err != nil {
+			return err
+		}
+
 		adjustParams := make([]stock.AdjustStockParams, 0, len(orderModel.Items))
 		for _, item := range orderModel.Items {
 			stockModel, err := s.stock.GetStock(ctx, tx, item.StockID)
@@ -162,23 +304,38 @@ func (s *service) handlePaymentIntentPaymentFailed(ctx context.Context, event *s
 }
 
 func (s *service) handlePaymentIntentCanceled(ctx context.Context, event *stripe.Event) error {
-	s.logger.Info("Handling PaymentIntent canceled event", zap.String("event_id", event.ID))
+	s.log(ctx).Info("Handling PaymentIntent canceled event", zap.String("event_id", event.ID))
 
 	var paymentIntent stripe.PaymentIntent
 	if err := json.Unmarshal(event.Data.Raw, &paymentIntent); err != nil {
-		s.logger.Error("Failed to unmarshal PaymentIntent", zap.Error(err))
+		s.log(ctx).Error("Failed to unmarshal PaymentIntent", zap.Error(err))
 		return err
 	}
 
-	return s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+	return s.executeOrderTransaction(ctx, func(tx pgx.Tx) error {
 		order, err := s.order.GetOrderByPaymentIntentID(ctx, tx, paymentIntent.ID)
 		if err != nil {
-			s.logger.Error("Order not found for PaymentIntent", zap.String("payment_intent_id", paymentIntent.ID), zap.Error(err))
+			s.log(ctx).Error("Order not found for PaymentIntent", zap.String("payment_intent_id", paymentIntent.ID), zap.Error(err))
 			return err
 		}
 
+		// 略過快取重新讀取並鎖定訂單，避免以過期的 updated_at 更新
+		if order, err = s.order.GetOrderForUpdate(ctx, tx, order.ID); err != nil {
+			return fmt.Errorf("failed to get order for update: %w", err)
+		}
+
+		// 客戶自助取消的訂單在取消 payment intent 前已取消並恢復庫存
+		if order.Status == enum.OrderStatusCancelled {
+			s.log(ctx).Info("Order already cancelled", zap.Uint64("order_id", order.ID))
+			return nil
+		}
+
 		if err = s.order.UpdateOrderStatus(ctx, tx, order.ID, enum.OrderStatusCancelled, order.UpdatedAt); err != nil {
-			s.logger.Error("Failed to update order status to 'cancelled'", zap.Error(err))
+			s.log(ctx).Error("Failed to update order status to 'cancelled'", zap.Error(err))
+			return err
+		}
+
+		if err = s.mergeStripeMetadata(ctx, tx, order.ID, paymentIntent.Metadata); err != nil {
 			return err
 		}
 
@@ -200,21 +357,21 @@ func (s *service) handlePaymentIntentCanceled(ctx context.Context, event *stripe
 			return fmt.Errorf("failed to adjust stock: %w", err)
 		}
 
-		s.logger.Info("Order status updated to 'cancelled' and stock restored", zap.Uint64("order_id", order.ID))
+		s.log(ctx).Info("Order status updated to 'cancelled' and stock restored", zap.Uint64("order_id", order.ID))
 		return err
 	})
 }
 
 func (s *service) handleRefundCreated(ctx context.Context, event *stripe.Event) error {
-	s.logger.Info("Handling Refund created event", zap.String("event_id", event.ID))
+	s.log(ctx).Info("Handling Refund created event", zap.String("event_id", event.ID))
 
 	var refund stripe.Refund
 	if err := json.Unmarshal(event.Data.Raw, &refund); err != nil {
-		s.logger.Error("Failed to unmarshal Refund", zap.Error(err))
+		s.log(ctx).Error("Failed to unmarshal Refund", zap.Error(err))
 		return err
 	}
 
-	return s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+	return s.executeOrderTransaction(ctx, func(tx pgx.Tx) error {
 		// 獲取相關訂單
 		order, err := s.order.GetOrderByPaymentIntentID(ctx, tx, refund.PaymentIntent.ID)
 		if err != nil {
@@ -223,57 +380,72 @@ func (s *service) handleRefundCreated(ctx context.Context, event *stripe.Event)
 
 		// 更新訂單狀態
 		newStatus := enum.OrderStatusRefundPending
-		if refund.Amount == int64(order.Total*100) {
+		if refund.Amount == toStripeAmount(order.Total, order.Currency) {
 			newStatus = enum.OrderStatusRefunded
 		}
 
+		// 略過快取重新讀取並鎖定訂單，避免以過期的 updated_at 更新
+		if order, err = s.order.GetOrderForUpdate(ctx, tx, order.ID); err != nil {
+			return fmt.Errorf("failed to get order for update: %w", err)
+		}
+
 		if err = s.order.UpdateOrderStatus(ctx, tx, order.ID, newStatus, order.UpdatedAt); err != nil {
 			return fmt.Errorf("failed to update order status: %w", err)
 		}
 
-		s.logger.Info("Refund created processed", zap.String("refund_id", refund.ID))
+		s.log(ctx).Info("Refund created processed", zap.String("refund_id", refund.ID))
 		return err
 	})
 }
 
 func (s *service) handleRefundUpdated(ctx context.Context, event *stripe.Event) error {
-	s.logger.Info("Handling Refund updated event", zap.String("event_id", event.ID))
+	s.log(ctx).Info("Handling Refund updated event", zap.String("event_id", event.ID))
 
 	var refund stripe.Refund
 	if err := json.Unmarshal(event.Data.Raw, &refund); err != nil {
-		s.logger.Error("Failed to unmarshal Refund", zap.Error(err))
+		s.log(ctx).Error("Failed to unmarshal Refund", zap.Error(err))
 		return err
 	}
 
-	return s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+	return s.executeOrderTransaction(ctx, func(tx pgx.Tx) error {
 		// 獲取相關訂單
 		order, err := s.order.GetOrderByRefundID(ctx, tx, refund.ID)
 		if err != nil {
 			return fmt.Errorf("failed to get order by refund ID: %w", err)
 		}
 
-		// 如果退款狀態變為成功，更新訂單的退款狀態
+		// 如果退款狀態變為成功，更新訂單的退款狀態；退款政策扣除重新上架費或只退部分項目時為部分退款
 		if refund.Status == stripe.RefundStatusSucceeded {
-			if err := s.order.UpdateOrderStatus(ctx, tx, order.ID, enum.OrderStatusRefunded, order.UpdatedAt); err != nil {
+			// 略過快取重新讀取並鎖定訂單，避免以過期的 updated_at 更新
+			if order, err = s.order.GetOrderForUpdate(ctx, tx, order.ID); err != nil {
+				return fmt.Errorf("failed to get order for update: %w", err)
+			}
+
+			newStatus := enum.OrderStatusRefunded
+			if refund.Amount < toStripeAmount(order.Total, order.Currency) {
+				newStatus = enum.OrderStatusPartiallyRefunded
+			}
+
+			if err := s.order.UpdateOrderStatus(ctx, tx, order.ID, newStatus, order.UpdatedAt); err != nil {
 				return fmt.Errorf("failed to update order refund status: %w", err)
 			}
 		}
 
-		s.logger.Info("Refund updated processed", zap.String("refund_id", refund.ID))
+		s.log(ctx).Info("Refund updated processed", zap.String("refund_id", refund.ID))
 		return err
 	})
 }
 
 func (s *service) handleChargeRefunded(ctx context.Context, event *stripe.Event) error {
-	s.logger.Info("Handling Charge refunded event", zap.String("event_id", event.ID))
+	s.log(ctx).Info("Handling Charge refunded event", zap.String("event_id", event.ID))
 
 	var charge stripe.Charge
 	if err := json.Unmarshal(event.Data.Raw, &charge); err != nil {
-		s.logger.Error("Failed to unmarshal Charge", zap.Error(err))
+		s.log(ctx).Error("Failed to unmarshal Charge", zap.Error(err))
 		return err
 	}
 
-	return s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+	return s.executeOrderTransaction(ctx, func(tx pgx.Tx) error {
 		// 獲取相關訂單
 		order, err := s.order.GetOrderByPaymentIntentID(ctx, tx, charge.PaymentIntent.ID)
 		if err != nil {
@@ -286,6 +458,11 @@ func (s *service) handleChargeRefunded(ctx context.Context, event *stripe.Event)
 			newStatus = enum.OrderStatusRefunded
 		}
 
+		// 略過快取重新讀取並鎖定訂單，避免以過期的 updated_at 更新
+		if order, err = s.order.GetOrderForUpdate(ctx, tx, order.ID); err != nil {
+			return fmt.Errorf("failed to get order for update: %w", err)
+		}
+
 		if err = s.order.UpdateOrderStatus(ctx, tx, order.ID, newStatus, order.UpdatedAt); err != nil {
 			return fmt.Errorf("failed to update order status: %w", err)
 		}
@@ -306,77 +483,108 @@ func (s *service) handleChargeRefunded(ctx context.Context, event *stripe.Event)
 			}
 		}
 
-		s.logger.Info("Charge refunded processed", zap.String("charge_id", charge.ID))
+		s.log(ctx).Info("Charge refunded processed", zap.String("charge_id", charge.ID))
 		return err
 	})
 }
 
 func (s *service) handleChargeDisputeCreated(ctx context.Context, event *stripe.Event) error {
-	s.logger.Info("Handling Charge dispute created event", zap.String("event_id", event.ID))
+	s.log(ctx).Info("Handling Charge dispute created event", zap.String("event_id", event.ID))
 
 	var dispute stripe.Dispute
 	if err := json.Unmarshal(event.Data.Raw, &dispute); err != nil {
-		s.logger.Error("Failed to unmarshal Dispute", zap.Error(err))
+		s.log(ctx).Error("Failed to unmarshal Dispute", zap.Error(err))
 		return err
 	}
 
-	return s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+	return s.executeOrderTransaction(ctx, func(tx pgx.Tx) error {
 		// 通過 PaymentIntent ID 獲取訂單
 		order, err := s.order.GetOrderByRefundID(ctx, tx, dispute.PaymentIntent.ID)
 		if err != nil {
-			s.logger.Error("Order not found for Charge", zap.String("charge_id", dispute.Charge.ID), zap.Error(err))
+			s.log(ctx).Error("Order not found for Charge", zap.String("charge_id", dispute.Charge.ID), zap.Error(err))
 			return err
 		}
 
+		// 略過快取重新讀取並鎖定訂單，避免以過期的 updated_at 更新
+		if order, err = s.order.GetOrderForUpdate(ctx, tx, order.ID); err != nil {
+			return fmt.Errorf("failed to get order for update: %w", err)
+		}
+
 		// 更新訂單狀態為爭議中
 		if err = s.order.UpdateOrderStatus(ctx, tx, order.ID, enum.OrderStatusDispute, order.UpdatedAt); err != nil {
-			s.logger.Error("Failed to update order status to 'disputed'", zap.Error(err))
+			s.log(ctx).Error("Failed to update order status to 'disputed'", zap.Error(err))
 			return err
 		}
 
-		s.logger.Info("Order status updated to 'disputed'", zap.Uint64("order_id", order.ID))
+		s.log(ctx).Info("Order status updated to 'disputed'", zap.Uint64("order_id", order.ID))
 		return err
 	})
 }
 
 func (s *service) handleCheckoutSessionCompleted(ctx context.Context, event *stripe.Event) error {
-	s.logger.Info("Handling Checkout Session completed event", zap.String("event_id", event.ID))
+	s.log(ctx).Info("Handling Checkout Session completed event", zap.String("event_id", event.ID))
 
 	var session stripe.CheckoutSession
 	if err := json.Unmarshal(event.Data.Raw, &session); err != nil {
-		s.logger.Error("Failed to unmarshal Checkout Session", zap.Error(err))
+		s.log(ctx).Error("Failed to unmarshal Checkout Session", zap.Error(err))
 		return err
 	}
 
-	return s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+	var invoice *models.Invoice
+	if err := s.executeOrderTransaction(ctx, func(tx pgx.Tx) error {
 		// 根據 Session ID 或 PaymentIntent ID 獲取訂單
 		order, err := s.order.GetOrderByPaymentIntentID(ctx, tx, session.PaymentIntent.ID)
 		if err != nil {
-			s.logger.Error("Order not found for PaymentIntent", zap.String("payment_intent_id", session.PaymentIntent.ID), zap.Error(err))
+			s.log(ctx).Error("Order not found for PaymentIntent", zap.String("payment_intent_id", session.PaymentIntent.ID), zap.Error(err))
 			return err
 		}
 
+		// 略過快取重新讀取並鎖定訂單，避免以過期的 updated_at 更新
+		if order, err = s.order.GetOrderForUpdate(ctx, tx, order.ID); err != nil {
+			return fmt.Errorf("failed to get order for update: %w", err)
+		}
+
 		// 更新訂單狀態為已支付
 		if err = s.order.UpdateOrderStatus(ctx, tx, order.ID, enum.OrderStatusPaid, order.UpdatedAt); err != nil {
-			s.logger.Error("Failed to update order status to 'paid'", zap.Error(err))
+			s.log(ctx).Error("Failed to update order status to 'paid'", zap.Error(err))
+			return err
+		}
+		if err = s.startFulfillmentSLA(ctx, tx, order, time.Now()); err != nil {
+			return err
+		}
+
+		if err = s.mergeStripeMetadata(ctx, tx, order.ID, session.Metadata); err != nil {
 			return err
 		}
 
-		s.logger.Info("Order status updated to 'paid'", zap.Uint64("order_id", order.ID))
+		// 付款成功後提交稅務交易
+		s.commitOrderTax(ctx, tx, order)
+
+		// 開立發票，文件在交易完成後產生
+		if invoice, err = s.issueInvoice(ctx, tx, order); err != nil {
+			return fmt.Errorf("failed to issue invoice: %w", err)
+		}
+
+		s.log(ctx).Info("Order status updated to 'paid'", zap.Uint64("order_id", order.ID))
 		return err
-	})
+	}); err != nil {
+		return err
+	}
+
+	s.renderInvoiceDocument(ctx, invoice)
+	return nil
 }
 
 func (s *service) handleInvoicePaymentSucceeded(ctx context.Context, event *stripe.Event) error {
-	s.logger.Info("Handling Invoice payment succeeded event", zap.String("event_id", event.ID))
+	s.log(ctx).Info("Handling Invoice payment succeeded event", zap.String("event_id", event.ID))
 
 	var invoice stripe.Invoice
 	if err := json.Unmarshal(event.Data.Raw, &invoice); err != nil {
-		s.logger.Error("Failed to unmarshal Invoice", zap.Error(err))
+		s.log(ctx).Error("Failed to unmarshal Invoice", zap.Error(err))
 		return err
 	}
 
-	return s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+	return s.executeOrderTransaction(ctx, func(tx pgx.Tx) error {
 		// 檢查是否存在相關訂單
 		order, err := s.order.GetOrderByInvoiceID(ctx, tx, invoice.ID)
 		if err != nil {
@@ -385,38 +593,46 @@ func (s *service) handleInvoicePaymentSucceeded(ctx context.Context, event *stri
 				order = &models.Order{
 					CustomerID: invoice.Customer.ID,
 					Status:     enum.OrderStatusPaid,
-					Total:      float64(invoice.Total) / 100, // 轉換為元
+					Total:      fromStripeAmount(invoice.Total, invoice.Currency),
 					Currency:   invoice.Currency,
 					InvoiceID:  invoice.ID,
 				}
-				if _, err = s.order.CreateOrder(ctx, tx, order); err != nil {
+				if _, err = s.createOrder(ctx, tx, order); err != nil {
 					return fmt.Errorf("failed to create order for invoice: %w", err)
 				}
 			} else {
 				return fmt.Errorf("failed to get order by invoice ID: %w", err)
 			}
 		} else {
+			// 略過快取重新讀取並鎖定訂單，避免以過期的 updated_at 更新
+			if order, err = s.order.GetOrderForUpdate(ctx, tx, order.ID); err != nil {
+				return fmt.Errorf("failed to get order for update: %w", err)
+			}
+
 			// 如果訂單存在,更新狀態
 			if err = s.order.UpdateOrderStatus(ctx, tx, order.ID, enum.OrderStatusPaid, order.UpdatedAt); err != nil {
 				return fmt.Errorf("failed to update order status: %w", err)
 			}
+			if err = s.startFulfillmentSLA(ctx, tx, order, time.Now()); err != nil {
+				return err
+			}
 		}
 
-		s.logger.Info("Invoice payment succeeded processed", zap.String("invoice_id", invoice.ID))
+		s.log(ctx).Info("Invoice payment succeeded processed", zap.String("invoice_id", invoice.ID))
 		return nil
 	})
 }
 
 func (s *service) handleInvoicePaymentFailed(ctx context.Context, event *stripe.Event) error {
-	s.logger.Info("Handling Invoice payment failed event", zap.String("event_id", event.ID))
+	s.log(ctx).Info("Handling Invoice payment failed event", zap.String("event_id", event.ID))
 
 	var invoice stripe.Invoice
 	if err := json.Unmarshal(event.Data.Raw, &invoice); err != nil {
-		s.logger.Error("Failed to unmarshal Invoice", zap.Error(err))
+		s.log(ctx).Error("Failed to unmarshal Invoice", zap.Error(err))
 		return err
 	}
 
-	return s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+	return s.executeOrderTransaction(ctx, func(tx pgx.Tx) error {
 		// 檢查是否存在相關訂單
 		order, err := s.order.GetOrderByInvoiceID(ctx, tx, invoice.ID)
 		if err != nil {
@@ -425,23 +641,28 @@ func (s *service) handleInvoicePaymentFailed(ctx context.Context, event *stripe.
 			}
 			// 如果沒有相關訂單,可能是訂閱付款失敗,不需要創建新訂單
 		} else {
+			// 略過快取重新讀取並鎖定訂單，避免以過期的 updated_at 更新
+			if order, err = s.order.GetOrderForUpdate(ctx, tx, order.ID); err != nil {
+				return fmt.Errorf("failed to get order for update: %w", err)
+			}
+
 			// 如果訂單存在,更新狀態
 			if err = s.order.UpdateOrderStatus(ctx, tx, order.ID, enum.OrderStatusFailed, order.UpdatedAt); err != nil {
 				return fmt.Errorf("failed to update order status: %w", err)
 			}
 		}
 
-		s.logger.Info("Invoice payment failed processed", zap.String("invoice_id", invoice.ID))
+		s.log(ctx).Info("Invoice payment failed processed", zap.String("invoice_id", invoice.ID))
 		return nil
 	})
 }
 
 func (s *service) handleSubscriptionCreated(ctx context.Context, event *stripe.Event) error {
-	s.logger.Info("Handling Subscription created event", zap.String("event_id", event.ID))
+	s.log(ctx).Info("Handling Subscription created event", zap.String("event_id", event.ID))
 
 	var subscription stripe.Subscription
 	if err := json.Unmarshal(event.Data.Raw, &subscription); err != nil {
-		s.logger.Error("Failed to unmarshal Subscription", zap.Error(err))
+		s.log(ctx).Error("Failed to unmarshal Subscription", zap.Error(err))
 		return err
 	}
 
@@ -450,12 +671,12 @@ func (s *service) handleSubscriptionCreated(ctx context.Context, event *stripe.E
 		order := &models.Order{
 			CustomerID:     subscription.Customer.ID,
 			Status:         enum.OrderStatusPaid,
-			Total:          float64(subscription.Items.Data[0].Price.UnitAmount) / 100,
+			Total:          fromStripeAmount(subscription.Items.Data[0].Price.UnitAmount, subscription.Items.Data[0].Price.Currency),
 			Currency:       subscription.Items.Data[0].Price.Currency,
 			SubscriptionID: subscription.ID,
 		}
 
-		if _, err := s.order.CreateOrder(ctx, tx, order); err != nil {
+		if _, err := s.createOrder(ctx, tx, order); err != nil {
 			return fmt.Errorf("failed to create order for subscription: %w", err)
 		}
 
@@ -464,11 +685,11 @@ func (s *service) handleSubscriptionCreated(ctx context.Context, event *stripe.E
 }
 
 func (s *service) handleSubscriptionUpdated(ctx context.Context, event *stripe.Event) error {
-	s.logger.Info("Handling Subscription updated event", zap.String("event_id", event.ID))
+	s.log(ctx).Info("Handling Subscription updated event", zap.String("event_id", event.ID))
 
 	var subscription stripe.Subscription
 	if err := json.Unmarshal(event.Data.Raw, &subscription); err != nil {
-		s.logger.Error("Failed to unmarshal Subscription", zap.Error(err))
+		s.log(ctx).Error("Failed to unmarshal Subscription", zap.Error(err))
 		return err
 	}
 
@@ -478,12 +699,12 @@ func (s *service) handleSubscriptionUpdated(ctx context.Context, event *stripe.E
 			order := &models.Order{
 				CustomerID:     subscription.Customer.ID,
 				Status:         enum.OrderStatusPaid,
-				Total:          float64(subscription.Items.Data[0].Price.UnitAmount) / 100,
+				Total:          fromStripeAmount(subscription.Items.Data[0].Price.UnitAmount, subscription.Items.Data[0].Price.Currency),
 				Currency:       subscription.Items.Data[0].Price.Currency,
 				SubscriptionID: subscription.ID,
 			}
 
-			if _, err := s.order.CreateOrder(ctx, tx, order); err != nil {
+			if _, err := s.createOrder(ctx, tx, order); err != nil {
 				return fmt.Errorf("failed to create order for updated subscription: %w", err)
 			}
 		}
@@ -494,25 +715,30 @@ func (s *service) handleSubscriptionUpdated(ctx context.Context, event *stripe.E
 }
 
 func (s *service) handleSubscriptionDeleted(ctx context.Context, event *stripe.Event) error {
-	s.logger.Info("Handling Subscription deleted event", zap.String("event_id", event.ID))
+	s.log(ctx).Info("Handling Subscription deleted event", zap.String("event_id", event.ID))
 
 	var subscription stripe.Subscription
 	if err := json.Unmarshal(event.Data.Raw, &subscription); err != nil {
-		s.logger.Error("Failed to unmarshal Subscription", zap.Error(err))
+		s.log(ctx).Error("Failed to unmarshal Subscription", zap.Error(err))
 		return err
 	}
 
-	return s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+	return s.executeOrderTransaction(ctx, func(tx pgx.Tx) error {
 
 		order, err := s.order.GetOrderByCustomerIDAndSubscriptionID(ctx, tx, subscription.Customer.ID, subscription.ID)
 		if err != nil {
 			if !errors.Is(err, sql.ErrNoRows) {
 				return fmt.Errorf("failed to get order by customer ID: %w", err)
 			}
-			s.logger.Error("Failed to get order by customer ID", zap.Error(err))
+			s.log(ctx).Error("Failed to get order by customer ID", zap.Error(err))
 			return err
 		}
 
+		// 略過快取重新讀取並鎖定訂單，避免以過期的 updated_at 更新
+		if order, err = s.order.GetOrderForUpdate(ctx, tx, order.ID); err != nil {
+			return fmt.Errorf("failed to get order for update: %w", err)
+		}
+
 		if err = s.order.UpdateOrderStatus(ctx, tx, order.ID, enum.OrderStatusCancelled, order.UpdatedAt); err != nil {
 			return fmt.Errorf("failed to update orders for cancelled subscription: %w", err)
 		}
@@ -523,8 +749,37 @@ func (s *service) handleSubscriptionDeleted(ctx context.Context, event *stripe.E
 
 func (s *service) ProcessEvent(ctx context.Context, event *stripe.Event) error {
 
+	// 同一個 PaymentIntent 的事件需要串行處理，避免不同 worker 同時更新訂單狀態
+	paymentIntentID := paymentIntentIDFromEvent(event)
+	if paymentIntentID == "" {
+		return s.processEvent(ctx, event)
+	}
+
+	unlock := s.eventLocks.Lock(paymentIntentID)
+	defer unlock()
+
+	// 多個實例之間由訂單交易的第一個查詢取得 advisory lock 串行，鎖與訂單更新使用同一個連線
+	return s.processEvent(contextWithOrderLock(ctx, "payment_intent:"+paymentIntentID), event)
+}
+
+// orderLockContextKey 為 context 中保存訂單交易 advisory lock key 的 key
+type orderLockContextKey struct{}
+
+// contextWithOrderLock 回傳帶有 advisory lock key 的 context，executeOrderTransaction 會在交易開始時取得此鎖
+func contextWithOrderLock(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, orderLockContextKey{}, key)
+}
+
+// orderLockFromContext 取得 context 中的 advisory lock key，未設定時回傳空字串
+func orderLockFromContext(ctx context.Context) string {
+	key, _ := ctx.Value(orderLockContextKey{}).(string)
+	return key
+}
+
+func (s *service) processEvent(ctx context.Context, event *stripe.Event) error {
+
 	if _, err := s.event.GetByID(ctx, event.ID); err == nil {
-		s.logger.Info("Event already processed", zap.String("event_id", event.ID))
+		s.log(ctx).Info("Event already processed", zap.String("event_id", event.ID))
 		return nil
 	}
 
@@ -533,19 +788,37 @@ func (s *service) ProcessEvent(ctx context.Context, event *stripe.Event) error {
 		return fmt.Errorf("no handler registered for event type: %s", event.Type)
 	}
 
+	// 同一物件的事件依建立時間處理，過期的事件略過，前一個事件尚未處理的事件暫緩
+	objectID := eventObjectID(event)
+	if objectID != "" {
+		proceed, err := s.sequenceEvent(ctx, objectID, event)
+		if err != nil {
+			return err
+		}
+		if !proceed {
+			return nil
+		}
+	}
+
+	var payload json.RawMessage
+	if event.Data != nil {
+		payload = event.Data.Raw
+	}
+
 	if err := s.event.Create(ctx, &models.Event{
 		ID:        event.ID,
 		Type:      event.Type,
 		Processed: false,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
+		Payload:   payload,
 	}); err != nil {
-		s.logger.Error("Failed to create event", zap.Error(err))
+		s.log(ctx).Error("Failed to create event", zap.Error(err))
 		return err
 	}
 
 	if err := handler(ctx, event); err != nil {
-		s.logger.Error("處理事件時出錯",
+		s.log(ctx).Error("處理事件時出錯",
 			zap.String("event_id", event.ID),
 			zap.String("event_type", string(event.Type)),
 			zap.Error(err),
@@ -553,7 +826,98 @@ func (s *service) ProcessEvent(ctx context.Context, event *stripe.Event) error {
 		return err
 	}
 
-	s.logger.Info("Stripe event processed", zap.String("event_id", event.ID))
+	if objectID != "" {
+		if err := s.event.RecordObjectEvent(ctx, objectID, event); err != nil {
+			return err
+		}
+		s.redriveObjectEvents(ctx, objectID, event.Type)
+	}
+
+	s.log(ctx).Info("Stripe event processed", zap.String("event_id", event.ID))
+
+	return nil
+}
+
+// mergeStripeMetadata 將 Stripe 物件上的 metadata（例如內部訂單編號、活動代碼）寫入訂單，沒有 metadata 時略過
+func (s *service) mergeStripeMetadata(ctx context.Context, tx pgx.Tx, orderID uint64, metadata map[string]string) error {
+	if len(metadata) == 0 {
+		return nil
+	}
 
+	if err := s.order.MergeOrderMetadata(ctx, tx, orderID, metadata); err != nil {
+		return fmt.Errorf("failed to merge order metadata: %w", err)
+	}
 	return nil
 }
+
+// ErrEventPayloadUnavailable 表示事件的原始內容未保存或已超過保存期限被清除
+var ErrEventPayloadUnavailable = errors.New("event payload is not available")
+
+// WithEventRepository 設定記錄已處理 Stripe 事件（含原始內容）的 repository
+func WithEventRepository(repo event.Repository) Option {
+	return func(s *service) {
+		if repo != nil {
+			s.event = repo
+		}
+	}
+}
+
+// GetEventPayload 取得 Stripe 事件的原始 data 內容，供 handler 行為異常時排查
+func (s *service) GetEventPayload(ctx context.Context, eventID string) (json.RawMessage, error) {
+	if s.event == nil {
+		return nil, errors.New("event repository is not configured")
+	}
+
+	payload, err := s.event.GetPayload(ctx, eventID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("event %s not found: %w", eventID, err)
+		}
+		return nil, fmt.Errorf("failed to get event payload: %w", err)
+	}
+	if payload == nil {
+		return nil, ErrEventPayloadUnavailable
+	}
+
+	return payload, nil
+}
+
+// PurgeEventPayloads 清除超過保存期限的事件原始內容，回傳清除的筆數；事件記錄保留以維持重複事件的檢查
+func (s *service) PurgeEventPayloads(ctx context.Context, retention time.Duration) (int64, error) {
+	if s.event == nil {
+		return 0, errors.New("event repository is not configured")
+	}
+	if retention <= 0 {
+		return 0, errors.New("event payload retention must be positive")
+	}
+
+	purged, err := s.event.PurgePayloads(ctx, time.Now().Add(-retention))
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge event payloads: %w", err)
+	}
+
+	return purged, nil
+}
+
+// paymentIntentIDFromEvent 從事件中取出關聯的 PaymentIntent ID，沒有關聯時回傳空字串
+func paymentIntentIDFromEvent(event *stripe.Event) string {
+	if event.Data == nil || event.Data.Object == nil {
+		return ""
+	}
+
+	if strings.HasPrefix(string(event.Type), "payment_intent.") {
+		id, _ := event.Data.Object["id"].(string)
+		return id
+	}
+
+	// charge、refund、dispute、checkout session 等物件會帶有 payment_intent 欄位，可能是 ID 或展開後的物件
+	switch pi := event.Data.Object["payment_intent"].(type) {
+	case string:
+		return pi
+	case map[string]interface{}:
+		id, _ := pi["id"].(string)
+		return id
+	default:
+		return ""
+	}
+}
@@ -1,7 +1,13 @@
 package event
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
 	"github.com/jackc/pgx/v5/pgtype"
 	"gofalre.io/shop/driver"
 	"gofalre.io/shop/models"
@@ -18,32 +24,66 @@ type Repository interface {
 	Create(ctx context.Context, customer *models.Event) error
 	GetByID(ctx context.Context, id string) (*models.Event, error)
 	MarkAsProcessed(ctx context.Context, id string) error
+	GetPayload(ctx context.Context, id string) (json.RawMessage, error)
+	PurgePayloads(ctx context.Context, before time.Time) (int64, error)
+	GetBacklog(ctx context.Context, before time.Time) (*models.EventBacklog, error)
+
+	RecordObjectEvent(ctx context.Context, objectID string, event *stripe.Event) error
+	GetLastObjectEventCreated(ctx context.Context, objectID string) (time.Time, bool, error)
+	HasObjectEvent(ctx context.Context, objectID string, eventType stripe.EventType) (bool, error)
+	Park(ctx context.Context, parked *models.ParkedEvent) error
+	ListParkedByObject(ctx context.Context, objectID string, waitingFor stripe.EventType) ([]*models.ParkedEvent, error)
+	ListExpiredParked(ctx context.Context, createdBefore time.Time, limit uint64) ([]*models.ParkedEvent, error)
+	DeleteParked(ctx context.Context, eventID string) (bool, error)
 }
 
+// payloadCompressionThreshold 超過此大小（bytes）的事件內容以 gzip 壓縮後儲存
+const payloadCompressionThreshold = 4 << 10
+
 type repository struct {
-	conn   driver.PostgresPool
-	logger *zap.Logger
+	queries *driver.Queries
+	logger  *zap.Logger
 }
 
 func NewRepository(conn driver.PostgresPool, logger *zap.Logger) (Repository, error) {
+	return NewRepositoryWithQuerier(sqlc.New(conn), logger)
+}
+
+// NewRepositoryWithQuerier 以注入的 sqlc.Querier 建立 repository，事件的存取不使用交易
+func NewRepositoryWithQuerier(querier sqlc.Querier, logger *zap.Logger) (Repository, error) {
 	return &repository{
-		conn:   conn,
-		logger: logger,
+		queries: driver.NewQueries(querier),
+		logger:  logger,
 	}, nil
 }
 
 func (r *repository) Create(ctx context.Context, event *models.Event) error {
-	return sqlc.New(r.conn).CreateEvent(ctx, sqlc.CreateEventParams{
-		ID:        event.ID,
-		Type:      sqlc.EventType(event.Type),
-		Processed: event.Processed,
-		CreatedAt: pgtype.Timestamptz{Time: event.CreatedAt, Valid: true},
-		UpdatedAt: pgtype.Timestamptz{Time: event.UpdatedAt, Valid: true},
+	payload, compressed := []byte(event.Payload), false
+	if len(payload) > payloadCompressionThreshold {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(payload); err != nil {
+			return fmt.Errorf("failed to compress event payload: %w", err)
+		}
+		if err := zw.Close(); err != nil {
+			return fmt.Errorf("failed to compress event payload: %w", err)
+		}
+		payload, compressed = buf.Bytes(), true
+	}
+
+	return r.queries.Querier().CreateEvent(ctx, sqlc.CreateEventParams{
+		ID:                event.ID,
+		Type:              sqlc.EventType(event.Type),
+		Processed:         event.Processed,
+		CreatedAt:         pgtype.Timestamptz{Time: event.CreatedAt, Valid: true},
+		UpdatedAt:         pgtype.Timestamptz{Time: event.UpdatedAt, Valid: true},
+		Payload:           payload,
+		PayloadCompressed: compressed,
 	})
 }
 
 func (r *repository) GetByID(ctx context.Context, id string) (*models.Event, error) {
-	sqlcEvent, err := sqlc.New(r.conn).GetEventByID(ctx, id)
+	sqlcEvent, err := r.queries.Querier().GetEventByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -55,8 +95,171 @@ func (r *repository) GetByID(ctx context.Context, id string) (*models.Event, err
 }
 
 func (r *repository) MarkAsProcessed(ctx context.Context, id string) error {
-	return sqlc.New(r.conn).MarkEventAsProcessed(ctx, sqlc.MarkEventAsProcessedParams{
+	return r.queries.Querier().MarkEventAsProcessed(ctx, sqlc.MarkEventAsProcessedParams{
 		ID:        id,
 		UpdatedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
 	})
 }
+
+// GetPayload 取得事件的原始內容，內容已超過保存期限被清除時回傳 nil
+func (r *repository) GetPayload(ctx context.Context, id string) (json.RawMessage, error) {
+	row, err := r.queries.Querier().GetEventPayload(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if row.Payload == nil || !row.PayloadCompressed {
+		return row.Payload, nil
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(row.Payload))
+	if err != nil {
+		r.logger.Error("failed to read compressed event payload", zap.String("event_id", id), zap.Error(err))
+		return nil, err
+	}
+	defer zr.Close()
+
+	payload, err := io.ReadAll(zr)
+	if err != nil {
+		r.logger.Error("failed to decompress event payload", zap.String("event_id", id), zap.Error(err))
+		return nil, err
+	}
+
+	return payload, nil
+}
+
+// PurgePayloads 清除 before 之前建立之事件的原始內容，事件記錄本身保留以維持冪等檢查
+func (r *repository) PurgePayloads(ctx context.Context, before time.Time) (int64, error) {
+	purged, err := r.queries.Querier().PurgeEventPayloads(ctx, pgtype.Timestamptz{Time: before, Valid: true})
+	if err != nil {
+		r.logger.Error("failed to purge event payloads", zap.Error(err))
+		return 0, err
+	}
+
+	return purged, nil
+}
+
+// GetBacklog 計算在 before 之前收到但仍未處理完成的事件數，以及暫緩中的事件數
+func (r *repository) GetBacklog(ctx context.Context, before time.Time) (*models.EventBacklog, error) {
+	row, err := r.queries.Querier().GetEventBacklog(ctx, pgtype.Timestamptz{Time: before, Valid: true})
+	if err != nil {
+		r.logger.Error("failed to get event backlog", zap.Error(err))
+		return nil, err
+	}
+
+	return &models.EventBacklog{
+		Unprocessed: uint64(row.Unprocessed),
+		Parked:      uint64(row.Parked),
+	}, nil
+}
+
+// RecordObjectEvent 記錄已處理的事件及其所屬的 Stripe 物件，用於判斷後續事件的順序
+func (r *repository) RecordObjectEvent(ctx context.Context, objectID string, event *stripe.Event) error {
+	if err := r.queries.Querier().RecordStripeObjectEvent(ctx, sqlc.RecordStripeObjectEventParams{
+		ObjectID:     objectID,
+		EventID:      event.ID,
+		EventType:    string(event.Type),
+		EventCreated: pgtype.Timestamptz{Time: time.Unix(event.Created, 0), Valid: true},
+	}); err != nil {
+		r.logger.Error("failed to record object event", zap.String("object_id", objectID), zap.String("event_id", event.ID), zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// GetLastObjectEventCreated 取得物件最後處理之事件的建立時間，物件尚未有已處理的事件時回傳 false
+func (r *repository) GetLastObjectEventCreated(ctx context.Context, objectID string) (time.Time, bool, error) {
+	created, err := r.queries.Querier().GetLastStripeObjectEventCreated(ctx, objectID)
+	if err != nil {
+		r.logger.Error("failed to get last object event", zap.String("object_id", objectID), zap.Error(err))
+		return time.Time{}, false, err
+	}
+
+	return created.Time, created.Valid, nil
+}
+
+// HasObjectEvent 檢查物件是否已處理過指定類型的事件
+func (r *repository) HasObjectEvent(ctx context.Context, objectID string, eventType stripe.EventType) (bool, error) {
+	exists, err := r.queries.Querier().HasStripeObjectEvent(ctx, sqlc.HasStripeObjectEventParams{
+		ObjectID:  objectID,
+		EventType: string(eventType),
+	})
+	if err != nil {
+		r.logger.Error("failed to check object event", zap.String("object_id", objectID), zap.Error(err))
+		return false, err
+	}
+
+	return exists, nil
+}
+
+// Park 暫緩處理事件，同一個事件重複暫緩時略過
+func (r *repository) Park(ctx context.Context, parked *models.ParkedEvent) error {
+	if err := r.queries.Querier().ParkEvent(ctx, sqlc.ParkEventParams{
+		EventID:      parked.EventID,
+		ObjectID:     parked.ObjectID,
+		EventType:    string(parked.Type),
+		WaitingFor:   string(parked.WaitingFor),
+		EventCreated: pgtype.Timestamptz{Time: parked.EventCreated, Valid: true},
+		Payload:      parked.Payload,
+	}); err != nil {
+		r.logger.Error("failed to park event", zap.String("event_id", parked.EventID), zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// ListParkedByObject 列出物件中等待指定類型事件的暫緩事件，依事件建立時間排序
+func (r *repository) ListParkedByObject(ctx context.Context, objectID string, waitingFor stripe.EventType) ([]*models.ParkedEvent, error) {
+	rows, err := r.queries.Querier().ListParkedEventsByObject(ctx, sqlc.ListParkedEventsByObjectParams{
+		ObjectID:   objectID,
+		WaitingFor: string(waitingFor),
+	})
+	if err != nil {
+		r.logger.Error("failed to list parked events", zap.String("object_id", objectID), zap.Error(err))
+		return nil, err
+	}
+
+	return convertParkedEvents(rows), nil
+}
+
+// ListExpiredParked 列出事件建立時間早於 createdBefore 的暫緩事件，依事件建立時間排序
+func (r *repository) ListExpiredParked(ctx context.Context, createdBefore time.Time, limit uint64) ([]*models.ParkedEvent, error) {
+	rows, err := r.queries.Querier().ListExpiredParkedEvents(ctx, sqlc.ListExpiredParkedEventsParams{
+		EventCreated: pgtype.Timestamptz{Time: createdBefore, Valid: true},
+		Limit:        int64(limit),
+	})
+	if err != nil {
+		r.logger.Error("failed to list expired parked events", zap.Error(err))
+		return nil, err
+	}
+
+	return convertParkedEvents(rows), nil
+}
+
+// DeleteParked 移除暫緩事件，回傳 false 表示事件已被其他流程取出
+func (r *repository) DeleteParked(ctx context.Context, eventID string) (bool, error) {
+	rows, err := r.queries.Querier().DeleteParkedEvent(ctx, eventID)
+	if err != nil {
+		r.logger.Error("failed to delete parked event", zap.String("event_id", eventID), zap.Error(err))
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+func convertParkedEvents(rows []*sqlc.ParkedEvent) []*models.ParkedEvent {
+	parked := make([]*models.ParkedEvent, 0, len(rows))
+	for _, row := range rows {
+		parked = append(parked, &models.ParkedEvent{
+			EventID:      row.EventID,
+			ObjectID:     row.ObjectID,
+			Type:         stripe.EventType(row.EventType),
+			WaitingFor:   stripe.EventType(row.WaitingFor),
+			EventCreated: row.EventCreated.Time,
+			Payload:      row.Payload,
+			ParkedAt:     row.ParkedAt.Time,
+		})
+	}
+	return parked
+}
@@ -0,0 +1,145 @@
+package shop
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/stripe/stripe-go/v79"
+	"go.uber.org/zap"
+
+	"gofalre.io/shop/models"
+)
+
+// parkedEventBatchSize 每次 RedriveParkedEvents 最多處理的暫緩事件數
+const parkedEventBatchSize = 100
+
+// eventPredecessors 列出需要等待同一物件前一個事件處理完成的事件類型
+var eventPredecessors = map[stripe.EventType]stripe.EventType{
+	stripe.EventTypePaymentIntentSucceeded:      stripe.EventTypePaymentIntentCreated,
+	stripe.EventTypePaymentIntentPaymentFailed:  stripe.EventTypePaymentIntentCreated,
+	stripe.EventTypePaymentIntentCanceled:       stripe.EventTypePaymentIntentCreated,
+	stripe.EventTypeRefundUpdated:               stripe.EventTypeRefundCreated,
+	stripe.EventTypeCustomerSubscriptionUpdated: stripe.EventTypeCustomerSubscriptionCreated,
+	stripe.EventTypeCustomerSubscriptionDeleted: stripe.EventTypeCustomerSubscriptionCreated,
+}
+
+// WithEventParkTimeout 設定事件等待同一物件前一個事件的最長時間（以事件建立時間計算），
+// 超過後由 RedriveParkedEvents 直接處理；未設定時不暫緩事件，只略過比已處理事件更舊的事件
+func WithEventParkTimeout(timeout time.Duration) Option {
+	return func(s *service) {
+		s.eventParkTimeout = timeout
+	}
+}
+
+// sequenceEvent 依同一物件已處理的事件決定是否處理此事件，回傳 false 表示事件已過期被略過或已暫緩
+func (s *service) sequenceEvent(ctx context.Context, objectID string, event *stripe.Event) (bool, error) {
+	created := time.Unix(event.Created, 0)
+
+	// 1. 比已處理事件更舊的事件已被後續狀態取代
+	last, found, err := s.event.GetLastObjectEventCreated(ctx, objectID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get last object event: %w", err)
+	}
+	if found && created.Before(last) {
+		s.log(ctx).Info("Skipping out-of-order event",
+			zap.String("event_id", event.ID), zap.String("event_type", string(event.Type)), zap.String("object_id", objectID))
+		return false, nil
+	}
+
+	// 2. 前一個事件尚未處理時暫緩，等待超過時限後照常處理
+	predecessor, ok := eventPredecessors[event.Type]
+	if !ok || s.eventParkTimeout <= 0 || time.Since(created) >= s.eventParkTimeout {
+		return true, nil
+	}
+
+	processed, err := s.event.HasObjectEvent(ctx, objectID, predecessor)
+	if err != nil {
+		return false, fmt.Errorf("failed to check predecessor event: %w", err)
+	}
+	if processed {
+		return true, nil
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal event: %w", err)
+	}
+	if err = s.event.Park(ctx, &models.ParkedEvent{
+		EventID:      event.ID,
+		ObjectID:     objectID,
+		Type:         event.Type,
+		WaitingFor:   predecessor,
+		EventCreated: created,
+		Payload:      payload,
+	}); err != nil {
+		return false, fmt.Errorf("failed to park event: %w", err)
+	}
+
+	s.log(ctx).Info("Parked event until predecessor arrives",
+		zap.String("event_id", event.ID), zap.String("event_type", string(event.Type)), zap.String("waiting_for", string(predecessor)))
+
+	return false, nil
+}
+
+// redriveObjectEvents 處理等待此事件的暫緩事件，呼叫端已持有同一物件的鎖；重新處理失敗只記錄日誌
+func (s *service) redriveObjectEvents(ctx context.Context, objectID string, eventType stripe.EventType) {
+	parked, err := s.event.ListParkedByObject(ctx, objectID, eventType)
+	if err != nil {
+		s.log(ctx).Warn("Failed to list parked events", zap.String("object_id", objectID), zap.Error(err))
+		return
+	}
+
+	for _, p := range parked {
+		if err = s.redriveParkedEvent(ctx, p, s.processEvent); err != nil {
+			s.log(ctx).Error("Failed to redrive parked event", zap.String("event_id", p.EventID), zap.Error(err))
+		}
+	}
+}
+
+// RedriveParkedEvents 處理等待超過時限的暫緩事件，供排程定期呼叫，回傳處理的事件數
+func (s *service) RedriveParkedEvents(ctx context.Context) (int, error) {
+	parked, err := s.event.ListExpiredParked(ctx, time.Now().Add(-s.eventParkTimeout), parkedEventBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list expired parked events: %w", err)
+	}
+
+	redriven := 0
+	for _, p := range parked {
+		if err = s.redriveParkedEvent(ctx, p, s.ProcessEvent); err != nil {
+			s.log(ctx).Error("Failed to redrive parked event", zap.String("event_id", p.EventID), zap.Error(err))
+			continue
+		}
+		redriven++
+	}
+
+	return redriven, nil
+}
+
+// redriveParkedEvent 取出暫緩事件並交給 process 處理，事件已被其他流程取出時略過
+func (s *service) redriveParkedEvent(ctx context.Context, parked *models.ParkedEvent, process func(context.Context, *stripe.Event) error) error {
+	ok, err := s.event.DeleteParked(ctx, parked.EventID)
+	if err != nil {
+		return fmt.Errorf("failed to delete parked event: %w", err)
+	}
+	if !ok {
+		return nil
+	}
+
+	var event stripe.Event
+	if err = json.Unmarshal(parked.Payload, &event); err != nil {
+		return fmt.Errorf("failed to unmarshal parked event: %w", err)
+	}
+
+	return process(ctx, &event)
+}
+
+// eventObjectID 取得事件所屬的 Stripe 物件 ID
+func eventObjectID(event *stripe.Event) string {
+	if event.Data == nil || event.Data.Object == nil {
+		return ""
+	}
+	id, _ := event.Data.Object["id"].(string)
+	return id
+}
@@ -0,0 +1,127 @@
+package shop
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stripe/stripe-go/v79"
+	"go.uber.org/zap"
+
+	"gofalre.io/shop/models"
+)
+
+// maxExperimentNameLength 為實驗與組別名稱的長度上限，對應 experiment_assignments 的欄位長度
+const maxExperimentNameLength = 100
+
+// ExperimentSubject 為分配實驗組別時提供給 ExperimentAssigner 的購物車資訊
+type ExperimentSubject struct {
+	CartID     uint64
+	CustomerID string
+	Currency   stripe.Currency
+	Tenant     string
+}
+
+// ExperimentAssigner 決定新購物車參與的實驗與組別，回傳實驗名稱對應的組別，不參與任何實驗時回傳 nil；
+// 實驗內容（例如加入購物車時或結帳時預留庫存、不同的免運門檻）由使用端依 ExperimentVariant 的結果自行切換，
+// shop 只負責記錄分配結果並在結帳時帶到訂單上供事後分析
+type ExperimentAssigner interface {
+	AssignExperiments(ctx context.Context, subject ExperimentSubject) (map[string]string, error)
+}
+
+// noExperiments 為預設的 ExperimentAssigner，購物車不參與任何實驗
+type noExperiments struct{}
+
+func (noExperiments) AssignExperiments(context.Context, ExperimentSubject) (map[string]string, error) {
+	return nil, nil
+}
+
+// WithExperimentAssigner 設定新購物車分配實驗組別的來源
+func WithExperimentAssigner(assigner ExperimentAssigner) Option {
+	return func(s *service) {
+		if assigner != nil {
+			s.experiments = assigner
+		}
+	}
+}
+
+// ListCartExperiments 依實驗名稱列出購物車被分配到的組別
+func (s *service) ListCartExperiments(ctx context.Context, cartID uint64) ([]*models.ExperimentAssignment, error) {
+	var assignments []*models.ExperimentAssignment
+
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		var err error
+		assignments, err = s.cart.ListCartExperimentAssignments(ctx, tx, cartID)
+		return err
+	}); err != nil {
+		return nil, fmt.Errorf("failed to list cart experiments: %w", err)
+	}
+
+	return assignments, nil
+}
+
+// ListOrderExperiments 依實驗名稱列出訂單結帳時購物車所屬的組別，手動建立或匯入的訂單沒有實驗組別
+func (s *service) ListOrderExperiments(ctx context.Context, orderID uint64) ([]*models.ExperimentAssignment, error) {
+	var assignments []*models.ExperimentAssignment
+
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		var err error
+		assignments, err = s.cart.ListOrderExperimentAssignments(ctx, tx, orderID)
+		return err
+	}); err != nil {
+		return nil, fmt.Errorf("failed to list order experiments: %w", err)
+	}
+
+	return assignments, nil
+}
+
+// ExperimentVariant 回傳購物車在實驗中的組別，購物車沒有參與該實驗時回傳 false
+func (s *service) ExperimentVariant(ctx context.Context, cartID uint64, experiment string) (string, bool, error) {
+	assignments, err := s.ListCartExperiments(ctx, cartID)
+	if err != nil {
+		return "", false, err
+	}
+
+	for _, assignment := range assignments {
+		if assignment.Experiment == experiment {
+			return assignment.Variant, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// assignExperiments 在建立購物車的交易內記錄 ExperimentAssigner 分配的組別；
+// 分配失敗或名稱不合法時只記錄警告，不影響購物車的建立
+func (s *service) assignExperiments(ctx context.Context, tx pgx.Tx, cartModel *models.Cart) error {
+	variants, err := s.experiments.AssignExperiments(ctx, ExperimentSubject{
+		CartID:     cartModel.ID,
+		CustomerID: cartModel.CustomerID,
+		Currency:   cartModel.Currency,
+		Tenant:     TenantFromContext(ctx),
+	})
+	if err != nil {
+		s.log(ctx).Warn("Failed to assign experiments", zap.Uint64("cart_id", cartModel.ID), zap.Error(err))
+		return nil
+	}
+
+	experiments := make([]string, 0, len(variants))
+	for experiment := range variants {
+		experiments = append(experiments, experiment)
+	}
+	sort.Strings(experiments)
+
+	for _, experiment := range experiments {
+		variant := variants[experiment]
+		if experiment == "" || variant == "" || len(experiment) > maxExperimentNameLength || len(variant) > maxExperimentNameLength {
+			s.log(ctx).Warn("Ignoring invalid experiment assignment",
+				zap.Uint64("cart_id", cartModel.ID), zap.String("experiment", experiment), zap.String("variant", variant))
+			continue
+		}
+		if _, err = s.cart.AssignCartExperiment(ctx, tx, cartModel.ID, experiment, variant); err != nil {
+			return fmt.Errorf("failed to assign experiment %s: %w", experiment, err)
+		}
+	}
+
+	return nil
+}
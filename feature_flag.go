@@ -0,0 +1,227 @@
+package shop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+)
+
+// FeatureFlag 為功能開關的名稱，同時也是 NATS KV 中的 key
+type FeatureFlag string
+
+const (
+	// FlagFlashSaleMaxLineQuantity 搶購模式下單一購物車項目可購買的上限，0 或未設定表示未開啟搶購模式
+	FlagFlashSaleMaxLineQuantity FeatureFlag = "flash_sale.max_line_quantity"
+	// FlagNewTaxEngine 是否使用 WithTaxCalculator 設定的稅率來源，關閉時退回預設的固定稅率
+	FlagNewTaxEngine FeatureFlag = "tax.new_engine"
+)
+
+// featureFlagTenantPrefix 租戶覆寫值的 key 前綴，完整格式為 tenants.<tenant>.<flag>
+const featureFlagTenantPrefix = "tenants."
+
+// FeatureFlags 提供功能開關的目前值，tenant 為空字串時只讀取全域值
+type FeatureFlags interface {
+	Value(ctx context.Context, flag FeatureFlag, tenant string) (string, bool)
+}
+
+// noFeatureFlags 為預設的 FeatureFlags，所有開關皆使用程式內的預設值
+type noFeatureFlags struct{}
+
+func (noFeatureFlags) Value(context.Context, FeatureFlag, string) (string, bool) {
+	return "", false
+}
+
+// WithFeatureFlags 設定 service 查詢功能開關的來源
+func WithFeatureFlags(flags FeatureFlags) Option {
+	return func(s *service) {
+		if flags != nil {
+			s.featureFlags = flags
+		}
+	}
+}
+
+type tenantContextKey struct{}
+
+// WithTenant 將租戶識別放入 ctx，功能開關會優先使用該租戶的覆寫值
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenant)
+}
+
+// TenantFromContext 取得 ctx 中的租戶識別，未設定時回傳空字串
+func TenantFromContext(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantContextKey{}).(string)
+	return tenant
+}
+
+// flagBool 讀取布林開關，未設定或格式錯誤時回傳 fallback
+func (s *service) flagBool(ctx context.Context, flag FeatureFlag, fallback bool) bool {
+	value, ok := s.featureFlags.Value(ctx, flag, TenantFromContext(ctx))
+	if !ok {
+		return fallback
+	}
+
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		s.log(ctx).Warn("Invalid boolean feature flag", zap.String("flag", string(flag)), zap.String("value", value))
+		return fallback
+	}
+	return enabled
+}
+
+// flagUint 讀取數值開關，未設定或格式錯誤時回傳 fallback
+func (s *service) flagUint(ctx context.Context, flag FeatureFlag, fallback uint64) uint64 {
+	value, ok := s.featureFlags.Value(ctx, flag, TenantFromContext(ctx))
+	if !ok {
+		return fallback
+	}
+
+	n, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		s.log(ctx).Warn("Invalid numeric feature flag", zap.String("flag", string(flag)), zap.String("value", value))
+		return fallback
+	}
+	return n
+}
+
+// ErrFlashSaleLimitExceeded 表示搶購模式下購物車項目數量超過上限
+var ErrFlashSaleLimitExceeded = errors.New("flash sale quantity limit exceeded")
+
+// checkFlashSaleLimit 在搶購模式開啟時檢查單一購物車項目的數量上限
+func (s *service) checkFlashSaleLimit(ctx context.Context, productID string, quantity uint64) error {
+	limit := s.flagUint(ctx, FlagFlashSaleMaxLineQuantity, 0)
+	if limit > 0 && quantity > limit {
+		return fmt.Errorf("%w: item %s allows at most %d, requested %d", ErrFlashSaleLimitExceeded, productID, limit, quantity)
+	}
+	return nil
+}
+
+// FeatureFlagChange 描述一次功能開關的變更，Deleted 為 true 時表示該值已被移除
+type FeatureFlagChange struct {
+	Flag    FeatureFlag
+	Tenant  string
+	Value   string
+	Deleted bool
+}
+
+// NATSFeatureFlags 以 NATS KV bucket 儲存功能開關，啟動時載入全部的值並持續監看變更，查詢只讀取記憶體
+type NATSFeatureFlags struct {
+	watcher nats.KeyWatcher
+
+	mu        sync.RWMutex
+	values    map[string]string
+	listeners []func(FeatureFlagChange)
+
+	logger *zap.Logger
+}
+
+// NewNATSFeatureFlags 開啟（不存在時建立）bucket 並開始監看，回傳前已載入 bucket 中現有的值
+func NewNATSFeatureFlags(natsConn *nats.Conn, bucket string, logger *zap.Logger) (*NATSFeatureFlags, error) {
+	js, err := natsConn.JetStream()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get jetstream context: %w", err)
+	}
+
+	kv, err := js.KeyValue(bucket)
+	if errors.Is(err, nats.ErrBucketNotFound) {
+		kv, err = js.CreateKeyValue(&nats.KeyValueConfig{Bucket: bucket})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open feature flag bucket %s: %w", bucket, err)
+	}
+
+	watcher, err := kv.WatchAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to watch feature flag bucket %s: %w", bucket, err)
+	}
+
+	f := &NATSFeatureFlags{
+		watcher: watcher,
+		values:  make(map[string]string),
+		logger:  logger,
+	}
+
+	// 監看開始時會先送出現有的值，以 nil 表示初始載入完成
+	for entry := range watcher.Updates() {
+		if entry == nil {
+			break
+		}
+		f.apply(entry)
+	}
+
+	go func() {
+		for entry := range watcher.Updates() {
+			if entry != nil {
+				f.apply(entry)
+			}
+		}
+	}()
+
+	return f, nil
+}
+
+// Value 先查詢租戶覆寫值，再退回全域值
+func (f *NATSFeatureFlags) Value(_ context.Context, flag FeatureFlag, tenant string) (string, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if tenant != "" {
+		if value, ok := f.values[featureFlagTenantPrefix+tenant+"."+string(flag)]; ok {
+			return value, true
+		}
+	}
+	value, ok := f.values[string(flag)]
+	return value, ok
+}
+
+// OnChange 註冊變更通知，回呼在監看的 goroutine 中執行，不應阻塞
+func (f *NATSFeatureFlags) OnChange(listener func(FeatureFlagChange)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.listeners = append(f.listeners, listener)
+}
+
+// Close 停止監看 bucket
+func (f *NATSFeatureFlags) Close() error {
+	return f.watcher.Stop()
+}
+
+func (f *NATSFeatureFlags) apply(entry nats.KeyValueEntry) {
+	change := FeatureFlagChange{Flag: FeatureFlag(entry.Key())}
+	if strings.HasPrefix(entry.Key(), featureFlagTenantPrefix) {
+		tenant, flag, ok := strings.Cut(strings.TrimPrefix(entry.Key(), featureFlagTenantPrefix), ".")
+		if !ok {
+			f.logger.Warn("Ignoring malformed tenant feature flag key", zap.String("key", entry.Key()))
+			return
+		}
+		change.Tenant, change.Flag = tenant, FeatureFlag(flag)
+	}
+
+	f.mu.Lock()
+	switch entry.Operation() {
+	case nats.KeyValueDelete, nats.KeyValuePurge:
+		delete(f.values, entry.Key())
+		change.Deleted = true
+	default:
+		change.Value = string(entry.Value())
+		f.values[entry.Key()] = change.Value
+	}
+	listeners := append([]func(FeatureFlagChange){}, f.listeners...)
+	f.mu.Unlock()
+
+	f.logger.Info("Feature flag changed",
+		zap.String("flag", string(change.Flag)),
+		zap.String("tenant", change.Tenant),
+		zap.String("value", change.Value),
+		zap.Bool("deleted", change.Deleted))
+
+	for _, listener := range listeners {
+		listener(change)
+	}
+}
@@ -0,0 +1,143 @@
+package shop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stripe/stripe-go/v79"
+	"go.uber.org/zap"
+
+	"gofalre.io/shop/models"
+	"gofalre.io/shop/models/enum"
+)
+
+const (
+	// defaultFingerprintVelocityLimit 同一張卡在時間窗內可被使用的客戶數上限，超過時訂單列入詐欺審核
+	defaultFingerprintVelocityLimit uint64 = 3
+	// defaultFingerprintVelocityWindow 計算卡片使用客戶數的時間窗
+	defaultFingerprintVelocityWindow = 24 * time.Hour
+)
+
+// WithFingerprintVelocityLimit 設定同一張卡在 window 內可被多少個客戶使用，超過時訂單列入詐欺審核；limit 設為 0 表示停用檢查
+func WithFingerprintVelocityLimit(limit uint64, window time.Duration) Option {
+	return func(s *service) {
+		s.fingerprintVelocityLimit = limit
+		if window > 0 {
+			s.fingerprintVelocityWindow = window
+		}
+	}
+}
+
+// checkPaymentFingerprint 記錄 PaymentIntent 使用的卡片指紋，同一張卡在時間窗內被過多客戶使用時將訂單列入詐欺審核；
+// webhook 沒有帶卡片資訊（未展開 latest_charge 或 payment_method）時略過
+func (s *service) checkPaymentFingerprint(ctx context.Context, tx pgx.Tx, order *models.Order, paymentIntent *stripe.PaymentIntent) error {
+	if s.fingerprintVelocityLimit == 0 {
+		return nil
+	}
+
+	fingerprint := paymentFingerprint(paymentIntent)
+	if fingerprint == "" {
+		return nil
+	}
+
+	// 1. 記錄卡片指紋
+	if err := s.order.RecordPaymentFingerprint(ctx, tx, paymentIntent.ID, fingerprint, order.CustomerID, order.ID); err != nil {
+		return fmt.Errorf("failed to record payment fingerprint: %w", err)
+	}
+
+	// 2. 計算時間窗內使用同一張卡的客戶數
+	customers, err := s.order.CountFingerprintCustomers(ctx, tx, fingerprint, time.Now().Add(-s.fingerprintVelocityWindow))
+	if err != nil {
+		return fmt.Errorf("failed to count fingerprint customers: %w", err)
+	}
+	if customers <= s.fingerprintVelocityLimit {
+		return nil
+	}
+
+	// 3. 列入詐欺審核，訂單已有待審核的記錄時略過
+	detail := fmt.Sprintf("card fingerprint used by %d customers within %s", customers, s.fingerprintVelocityWindow)
+	created, err := s.order.CreateFraudReview(ctx, tx, order.ID, enum.FraudReviewReasonFingerprintVelocity, detail)
+	if err != nil {
+		return fmt.Errorf("failed to create fraud review: %w", err)
+	}
+	if created {
+		s.log(ctx).Warn("Order flagged for fraud review",
+			zap.Uint64("order_id", order.ID), zap.String("payment_intent_id", paymentIntent.ID), zap.Uint64("customers", customers))
+	}
+
+	return nil
+}
+
+// paymentFingerprint 取得 PaymentIntent 所用卡片的指紋，優先使用最後一筆 charge 的付款資訊
+func paymentFingerprint(paymentIntent *stripe.PaymentIntent) string {
+	if charge := paymentIntent.LatestCharge; charge != nil && charge.PaymentMethodDetails != nil && charge.PaymentMethodDetails.Card != nil {
+		return charge.PaymentMethodDetails.Card.Fingerprint
+	}
+	if pm := paymentIntent.PaymentMethod; pm != nil && pm.Card != nil {
+		return pm.Card.Fingerprint
+	}
+	// 付款失敗時 payment_method 可能已被解除，改從失敗原因中取得
+	if lastErr := paymentIntent.LastPaymentError; lastErr != nil && lastErr.PaymentMethod != nil && lastErr.PaymentMethod.Card != nil {
+		return lastErr.PaymentMethod.Card.Fingerprint
+	}
+	return ""
+}
+
+// ListPendingFraudReviews 列出所有待審核的可疑訂單
+func (s *service) ListPendingFraudReviews(ctx context.Context) ([]*models.FraudReview, error) {
+	var reviews []*models.FraudReview
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		var err error
+		if reviews, err = s.order.ListFraudReviewsByStatus(ctx, tx, enum.FraudReviewStatusPending); err != nil {
+			return fmt.Errorf("failed to list fraud reviews: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return reviews, nil
+}
+
+// ResolveFraudReview 記錄可疑訂單的審核結果，status 只能是 cleared 或 confirmed；訂單本身的狀態由審核人員另外處理
+func (s *service) ResolveFraudReview(ctx context.Context, reviewID uint64, status enum.FraudReviewStatus, resolvedBy, note string) error {
+	if status != enum.FraudReviewStatusCleared && status != enum.FraudReviewStatusConfirmed {
+		return fmt.Errorf("invalid fraud review status: %q", status)
+	}
+	if resolvedBy == "" {
+		return errors.New("fraud review resolver is required")
+	}
+
+	return s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		// 1. 獲取審核記錄並檢查權限
+		review, err := s.order.GetFraudReview(ctx, tx, reviewID)
+		if err != nil {
+			return fmt.Errorf("failed to get fraud review: %w", err)
+		}
+		if review.Status != enum.FraudReviewStatusPending {
+			return fmt.Errorf("fraud review %d is already %s", review.ID, review.Status)
+		}
+
+		if err = s.checkAuthorization(ctx, AuthorizationRequest{
+			Action:     ActionResolveFraudReview,
+			ResourceID: review.ID,
+			Actor:      resolvedBy,
+		}); err != nil {
+			return err
+		}
+
+		// 2. 記錄審核結果
+		ok, err := s.order.ResolveFraudReview(ctx, tx, review.ID, status, resolvedBy, note)
+		if err != nil {
+			return fmt.Errorf("failed to resolve fraud review: %w", err)
+		}
+		if !ok {
+			return fmt.Errorf("fraud review %d is already resolved", review.ID)
+		}
+
+		return nil
+	})
+}
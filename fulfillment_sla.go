@@ -0,0 +1,192 @@
+package shop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+
+	"gofalre.io/shop/models"
+	"gofalre.io/shop/models/enum"
+)
+
+// FulfillmentSLA 訂單付款後到出貨（門市自取為備妥）的時限，距離期限 Warning 內尚未出貨的訂單列為有違反風險
+type FulfillmentSLA struct {
+	Target  time.Duration
+	Warning time.Duration
+}
+
+// DefaultFulfillmentSLA 為未設定時使用的履約時限：付款後 48 小時內出貨，剩下 12 小時時警示
+var DefaultFulfillmentSLA = FulfillmentSLA{
+	Target:  48 * time.Hour,
+	Warning: 12 * time.Hour,
+}
+
+// fulfillmentSLAOpenStatuses 為計入履約時限的訂單狀態，暫停、爭議或退款中的訂單不列為有違反風險
+var fulfillmentSLAOpenStatuses = []enum.OrderStatus{
+	enum.OrderStatusPaid,
+	enum.OrderStatusAwaitingStock,
+}
+
+// errFulfillmentSLAAlreadyAlerted 表示該訂單的警示已由其他執行送出
+var errFulfillmentSLAAlreadyAlerted = errors.New("fulfillment SLA alert already published")
+
+// WithFulfillmentSLA 設定履約方式的履約時限，fulfillmentType 為空字串時套用到所有未個別設定的履約方式；
+// 只影響之後付款的訂單，已付款訂單的期限在付款時決定
+func WithFulfillmentSLA(fulfillmentType enum.FulfillmentType, sla FulfillmentSLA) Option {
+	return func(s *service) {
+		if sla.Target <= 0 {
+			return
+		}
+		if s.fulfillmentSLAs == nil {
+			s.fulfillmentSLAs = make(map[enum.FulfillmentType]FulfillmentSLA)
+		}
+		s.fulfillmentSLAs[fulfillmentType] = sla
+	}
+}
+
+// GetOrderFulfillmentSLA 取得訂單的履約時限，訂單尚未付款時回傳 pgx.ErrNoRows
+func (s *service) GetOrderFulfillmentSLA(ctx context.Context, orderID uint64) (*models.OrderFulfillmentSLA, error) {
+	var sla *models.OrderFulfillmentSLA
+
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		var err error
+		sla, err = s.order.GetOrderFulfillmentSLA(ctx, tx, orderID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return sla, nil
+}
+
+// ListOrdersAtRiskOfSLABreach 依期限先後列出已進入警示期間但尚未出貨的訂單，包含已超過期限的訂單，供營運儀表板使用
+func (s *service) ListOrdersAtRiskOfSLABreach(ctx context.Context) ([]*models.OrderFulfillmentSLA, error) {
+	var slas []*models.OrderFulfillmentSLA
+
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		var err error
+		slas, err = s.order.ListOrdersAtRiskOfSLABreach(ctx, tx, time.Now(), fulfillmentSLAOpenStatuses)
+		return err
+	}); err != nil {
+		return nil, fmt.Errorf("failed to list orders at risk of SLA breach: %w", err)
+	}
+
+	return slas, nil
+}
+
+// CheckFulfillmentSLAs 為進入警示期間或超過期限的訂單發佈警示事件，每筆訂單的每種警示只發佈一次，應定期執行；
+// 回傳發佈的警示數
+func (s *service) CheckFulfillmentSLAs(ctx context.Context) (int, error) {
+	now := time.Now()
+
+	slas, err := s.ListOrdersAtRiskOfSLABreach(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	published := 0
+	for _, sla := range slas {
+		subject := SubjectFulfillmentSLAAtRisk
+		if sla.Breached(now) {
+			subject = SubjectFulfillmentSLABreached
+		}
+
+		if err = s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+			return s.alertFulfillmentSLA(ctx, tx, sla, subject, now)
+		}); err != nil {
+			if !errors.Is(err, errFulfillmentSLAAlreadyAlerted) {
+				s.log(ctx).Error("Failed to publish fulfillment SLA alert", zap.Uint64("order_id", sla.OrderID), zap.Error(err))
+			}
+			continue
+		}
+		published++
+	}
+
+	return published, nil
+}
+
+// alertFulfillmentSLA 先標記警示已送出再發佈事件，發佈失敗時回傳錯誤讓標記回復，下次執行時重試
+func (s *service) alertFulfillmentSLA(ctx context.Context, tx pgx.Tx, sla *models.OrderFulfillmentSLA, subject string, now time.Time) error {
+	// 1. 標記警示已送出，避免同時執行時重複發佈
+	var (
+		ok  bool
+		err error
+	)
+	if subject == SubjectFulfillmentSLABreached {
+		ok, err = s.order.MarkFulfillmentSLABreachAlerted(ctx, tx, sla.OrderID)
+	} else {
+		ok, err = s.order.MarkFulfillmentSLAAtRiskAlerted(ctx, tx, sla.OrderID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to mark fulfillment SLA alert: %w", err)
+	}
+	if !ok {
+		return errFulfillmentSLAAlreadyAlerted
+	}
+
+	// 2. 發佈警示
+	if err = s.eventManager.Publish(ctx, subject, &FulfillmentSLAAlertEvent{
+		OrderID:         sla.OrderID,
+		OrderNumber:     sla.OrderNumber,
+		CustomerID:      sla.CustomerID,
+		Status:          sla.Status,
+		FulfillmentType: sla.FulfillmentType,
+		PaidAt:          sla.PaidAt,
+		DueAt:           sla.DueAt,
+		OccurredAt:      now,
+	}); err != nil {
+		return fmt.Errorf("failed to publish fulfillment SLA alert: %w", err)
+	}
+
+	return nil
+}
+
+// startFulfillmentSLA 在訂單付款時依履約方式記錄履約時限，訂單已有時限時（例如爭議後恢復為已付款）保留原本的時限
+func (s *service) startFulfillmentSLA(ctx context.Context, tx pgx.Tx, order *models.Order, paidAt time.Time) error {
+	sla := s.fulfillmentSLA(order.FulfillmentType)
+
+	dueAt := paidAt.Add(sla.Target)
+	warnAt := dueAt.Add(-sla.Warning)
+	if warnAt.Before(paidAt) {
+		warnAt = paidAt
+	}
+
+	if err := s.order.StartOrderFulfillmentSLA(ctx, tx, &models.OrderFulfillmentSLA{
+		OrderID: order.ID,
+		PaidAt:  paidAt,
+		WarnAt:  warnAt,
+		DueAt:   dueAt,
+	}); err != nil {
+		return fmt.Errorf("failed to start fulfillment SLA: %w", err)
+	}
+
+	return nil
+}
+
+// completeFulfillmentSLA 在訂單出貨或備妥取貨時記錄完成時間，分批出貨時以第一批出貨為準
+func (s *service) completeFulfillmentSLA(ctx context.Context, tx pgx.Tx, orderID uint64, fulfilledAt time.Time) error {
+	ok, err := s.order.CompleteOrderFulfillmentSLA(ctx, tx, orderID, fulfilledAt)
+	if err != nil {
+		return fmt.Errorf("failed to complete fulfillment SLA: %w", err)
+	}
+
+	if ok {
+		s.log(ctx).Debug("Order fulfillment SLA completed", zap.Uint64("order_id", orderID))
+	}
+	return nil
+}
+
+// fulfillmentSLA 回傳履約方式的履約時限，依序使用個別設定、所有履約方式的設定與 DefaultFulfillmentSLA
+func (s *service) fulfillmentSLA(fulfillmentType enum.FulfillmentType) FulfillmentSLA {
+	if sla, ok := s.fulfillmentSLAs[fulfillmentType]; ok {
+		return sla
+	}
+	if sla, ok := s.fulfillmentSLAs[""]; ok {
+		return sla
+	}
+	return DefaultFulfillmentSLA
+}
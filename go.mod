@@ -8,6 +8,7 @@ require (
 	github.com/redis/go-redis/v9 v9.6.1
 	github.com/stripe/stripe-go/v79 v79.11.0
 	go.uber.org/zap v1.27.0
+	goflare.io/ember v0.0.1
 )
 
 require (
@@ -33,7 +34,6 @@ require (
 	go.opentelemetry.io/otel/trace v1.30.0 // indirect
 	go.uber.org/atomic v1.11.0 // indirect
 	go.uber.org/multierr v1.11.0 // indirect
-	goflare.io/ember v0.0.1 // indirect
 	golang.org/x/crypto v0.27.0 // indirect
 	golang.org/x/sync v0.8.0 // indirect
 	golang.org/x/sys v0.25.0 // indirect
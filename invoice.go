@@ -0,0 +1,318 @@
+package shop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+
+	"gofalre.io/shop/models"
+	"gofalre.io/shop/models/enum"
+)
+
+// defaultInvoiceDigits 為單據流水號預設補零的位數
+const defaultInvoiceDigits = 8
+
+// ErrInvoicingNotConfigured 表示未設定 WithInvoicing，不會開立發票與折讓單
+var ErrInvoicingNotConfigured = errors.New("invoicing is not configured")
+
+// ErrInvoiceNotFound 表示發票或折讓單不存在
+var ErrInvoiceNotFound = errors.New("invoice not found")
+
+// InvoiceRenderer 產生單據的文件（例如 PDF）並回傳存放的網址，order 包含訂單項目與加購服務
+type InvoiceRenderer interface {
+	RenderInvoice(ctx context.Context, invoice *models.Invoice, order *models.Order) (string, error)
+}
+
+// InvoiceSeries 單據編號的格式，編號為前綴加上補零到 Digits 位的流水號，例如 DE-INV-00000042；
+// 前綴為空時使用「國碼-INV-」與「國碼-CN-」，Digits 為 0 時補零到 8 位
+type InvoiceSeries struct {
+	InvoicePrefix    string
+	CreditNotePrefix string
+	Digits           int
+}
+
+// InvoiceConfig 開立單據的設定；編號依租戶（TenantFromContext）、國家與單據種類各自連續，
+// 國家取自訂單的帳單地址，沒有時依序改用寄送地址與 DefaultCountry
+type InvoiceConfig struct {
+	Series         map[string]InvoiceSeries // 以國碼為索引，未列出的國家使用 DefaultSeries
+	DefaultSeries  InvoiceSeries
+	DefaultCountry string
+	Renderer       InvoiceRenderer // 為 nil 時不產生文件
+}
+
+// WithInvoicing 啟用單據開立：訂單付款成功時開立發票，RefundOrder 發起退款時開立折讓單
+func WithInvoicing(config InvoiceConfig) Option {
+	return func(s *service) {
+		s.invoicing = &config
+	}
+}
+
+// number 以流水號組成單據編號
+func (c *InvoiceConfig) number(country string, invoiceType enum.InvoiceType, sequence uint64) string {
+	series, ok := c.Series[country]
+	if !ok {
+		series = c.DefaultSeries
+	}
+
+	prefix := series.InvoicePrefix
+	if invoiceType == enum.InvoiceTypeCreditNote {
+		prefix = series.CreditNotePrefix
+	}
+	if prefix == "" {
+		prefix = country + "-INV-"
+		if invoiceType == enum.InvoiceTypeCreditNote {
+			prefix = country + "-CN-"
+		}
+	}
+
+	digits := series.Digits
+	if digits <= 0 {
+		digits = defaultInvoiceDigits
+	}
+
+	return fmt.Sprintf("%s%0*d", prefix, digits, sequence)
+}
+
+// country 回傳訂單適用的國碼
+func (c *InvoiceConfig) country(order *models.Order) (string, error) {
+	for _, address := range [][]byte{order.BillingAddress, order.ShippingAddress} {
+		if destination, ok := models.ParseDeliveryDestination(address); ok {
+			return destination.Country, nil
+		}
+	}
+	if c.DefaultCountry != "" {
+		return strings.ToUpper(c.DefaultCountry), nil
+	}
+	return "", fmt.Errorf("order %d has no country for invoicing", order.ID)
+}
+
+// IssueInvoice 為已付款的訂單開立發票，已開立時回傳原本的發票；付款成功的事件會自動開立，
+// 此方法用於補開立或啟用單據開立前付款的訂單
+func (s *service) IssueInvoice(ctx context.Context, orderID uint64) (*models.Invoice, error) {
+	if s.invoicing == nil {
+		return nil, ErrInvoicingNotConfigured
+	}
+
+	var invoice *models.Invoice
+
+	if err := s.executeOrderTransaction(ctx, func(tx pgx.Tx) error {
+		orderModel, err := s.order.GetOrderForUpdate(ctx, tx, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to get order for update: %w", err)
+		}
+		if orderModel.PaymentIntentID == "" || !invoiceableOrderStatuses[orderModel.Status] {
+			return fmt.Errorf("order %d is %s and cannot be invoiced", orderID, orderModel.Status)
+		}
+
+		invoice, err = s.issueInvoice(ctx, tx, orderModel)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	s.renderInvoiceDocument(ctx, invoice)
+	return invoice, nil
+}
+
+// ListOrderInvoices 依開立順序列出訂單的發票與折讓單
+func (s *service) ListOrderInvoices(ctx context.Context, orderID uint64) ([]*models.Invoice, error) {
+	var invoices []*models.Invoice
+
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		var err error
+		if invoices, err = s.order.ListInvoices(ctx, tx, orderID); err != nil {
+			return fmt.Errorf("failed to list invoices: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return invoices, nil
+}
+
+// RenderInvoiceDocument 重新產生單據的文件並更新網址，用於文件產生失敗後重試
+func (s *service) RenderInvoiceDocument(ctx context.Context, invoiceID uint64) (*models.Invoice, error) {
+	if s.invoicing == nil || s.invoicing.Renderer == nil {
+		return nil, ErrInvoicingNotConfigured
+	}
+
+	invoice, err := s.order.GetInvoice(ctx, nil, invoiceID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%w: %d", ErrInvoiceNotFound, invoiceID)
+		}
+		return nil, fmt.Errorf("failed to get invoice: %w", err)
+	}
+
+	if err = s.renderInvoice(ctx, invoice); err != nil {
+		return nil, err
+	}
+
+	return invoice, nil
+}
+
+// ListInvoiceNumberGaps 列出各組單據編號中缺少的區間；正常情況下應為空，有缺號時需人工稽核
+func (s *service) ListInvoiceNumberGaps(ctx context.Context) ([]*models.InvoiceNumberGap, error) {
+	var gaps []*models.InvoiceNumberGap
+
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		var err error
+		if gaps, err = s.order.ListInvoiceNumberGaps(ctx, tx); err != nil {
+			return fmt.Errorf("failed to list invoice number gaps: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	for _, gap := range gaps {
+		s.log(ctx).Warn("Invoice number gap detected",
+			zap.String("tenant", gap.Tenant), zap.String("country", gap.Country), zap.String("type", string(gap.Type)),
+			zap.Uint64("from", gap.From), zap.Uint64("to", gap.To))
+	}
+
+	return gaps, nil
+}
+
+// invoiceableOrderStatuses 為可以開立發票的訂單狀態，退款中或已退款的訂單仍可補開立
+var invoiceableOrderStatuses = map[enum.OrderStatus]bool{
+	enum.OrderStatusPaid:              true,
+	enum.OrderStatusReadyForPickup:    true,
+	enum.OrderStatusCompleted:         true,
+	enum.OrderStatusRefundPending:     true,
+	enum.OrderStatusPartiallyRefunded: true,
+	enum.OrderStatusRefunded:          true,
+}
+
+// issueInvoice 在交易中為訂單開立發票，未啟用單據開立時回傳 nil；已開立時回傳原本的發票
+func (s *service) issueInvoice(ctx context.Context, tx pgx.Tx, order *models.Order) (*models.Invoice, error) {
+	if s.invoicing == nil {
+		return nil, nil
+	}
+
+	existing, err := s.order.GetOrderInvoice(ctx, tx, order.ID)
+	if err == nil {
+		return existing, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get order invoice: %w", err)
+	}
+
+	invoice := &models.Invoice{
+		Type:     enum.InvoiceTypeInvoice,
+		OrderID:  order.ID,
+		Currency: order.Currency,
+		Subtotal: roundCurrency(order.Subtotal - order.Discount),
+		Tax:      order.Tax,
+		Total:    order.Total,
+	}
+	if err = s.createInvoice(ctx, tx, order, invoice); err != nil {
+		return nil, err
+	}
+
+	return invoice, nil
+}
+
+// issueCreditNote 在交易中為退款開立折讓單，稅額依訂單的稅額比例自退款金額拆出；
+// 未啟用單據開立或訂單沒有發票時回傳 nil
+func (s *service) issueCreditNote(ctx context.Context, tx pgx.Tx, order *models.Order, refund *models.Refund) (*models.Invoice, error) {
+	if s.invoicing == nil {
+		return nil, nil
+	}
+
+	original, err := s.order.GetOrderInvoice(ctx, tx, order.ID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.log(ctx).Warn("Order has no invoice, skipping credit note", zap.Uint64("order_id", order.ID), zap.Uint64("refund_id", refund.ID))
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get order invoice: %w", err)
+	}
+
+	var tax float64
+	if order.Total > 0 {
+		tax = roundCurrency(refund.Amount * order.Tax / order.Total)
+	}
+	creditNote := &models.Invoice{
+		Type:              enum.InvoiceTypeCreditNote,
+		OrderID:           order.ID,
+		RefundID:          refund.ID,
+		OriginalInvoiceID: original.ID,
+		Currency:          refund.Currency,
+		Subtotal:          roundCurrency(refund.Amount - tax),
+		Tax:               tax,
+		Total:             refund.Amount,
+	}
+	if err = s.createInvoice(ctx, tx, order, creditNote); err != nil {
+		return nil, err
+	}
+
+	return creditNote, nil
+}
+
+// createInvoice 取得下一個流水號並新增單據，流水號與單據在同一個交易中寫入，因此不會跳號
+func (s *service) createInvoice(ctx context.Context, tx pgx.Tx, order *models.Order, invoice *models.Invoice) error {
+	country, err := s.invoicing.country(order)
+	if err != nil {
+		return err
+	}
+
+	invoice.Tenant = TenantFromContext(ctx)
+	invoice.Country = country
+	invoice.SequenceNumber, err = s.order.NextInvoiceSequenceNumber(ctx, tx, invoice.Tenant, country, invoice.Type)
+	if err != nil {
+		return fmt.Errorf("failed to get next invoice number: %w", err)
+	}
+	invoice.InvoiceNumber = s.invoicing.number(country, invoice.Type, invoice.SequenceNumber)
+
+	// 訂單下單時套用免稅證明時，單據註記證明編號
+	invoice.TaxExemptionNumber, err = s.order.GetOrderTaxExemptionNumber(ctx, tx, order.ID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("failed to get order tax exemption: %w", err)
+	}
+
+	if err = s.order.CreateInvoice(ctx, tx, invoice); err != nil {
+		return fmt.Errorf("failed to create invoice: %w", err)
+	}
+
+	s.log(ctx).Info("Invoice issued",
+		zap.Uint64("order_id", order.ID), zap.String("invoice_number", invoice.InvoiceNumber), zap.String("type", string(invoice.Type)))
+	return nil
+}
+
+// renderInvoiceDocument 在交易完成後產生單據的文件，失敗時只記錄警告，可透過 RenderInvoiceDocument 重試
+func (s *service) renderInvoiceDocument(ctx context.Context, invoice *models.Invoice) {
+	if invoice == nil || invoice.DocumentURL != "" || s.invoicing == nil || s.invoicing.Renderer == nil {
+		return
+	}
+
+	if err := s.renderInvoice(ctx, invoice); err != nil {
+		s.log(ctx).Warn("Failed to render invoice document",
+			zap.Uint64("invoice_id", invoice.ID), zap.String("invoice_number", invoice.InvoiceNumber), zap.Error(err))
+	}
+}
+
+// renderInvoice 產生單據的文件並記錄網址
+func (s *service) renderInvoice(ctx context.Context, invoice *models.Invoice) error {
+	orderModel, err := s.GetOrder(ctx, invoice.OrderID)
+	if err != nil {
+		return err
+	}
+
+	documentURL, err := s.invoicing.Renderer.RenderInvoice(ctx, invoice, orderModel)
+	if err != nil {
+		return fmt.Errorf("failed to render invoice %s: %w", invoice.InvoiceNumber, err)
+	}
+
+	if err = s.order.SetInvoiceDocument(ctx, nil, invoice.ID, documentURL); err != nil {
+		return fmt.Errorf("failed to set invoice document: %w", err)
+	}
+	invoice.DocumentURL = documentURL
+
+	return nil
+}
@@ -0,0 +1,209 @@
+package shop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"gofalre.io/shop/models"
+)
+
+// ErrTranslationNotFound 表示指定語系的翻譯不存在
+var ErrTranslationNotFound = errors.New("translation not found")
+
+// localeContextKey 為 context 中保存請求語系的 key
+type localeContextKey struct{}
+
+// ContextWithLocale 回傳帶有請求語系的 context，分類與商品目錄會依此語系回傳翻譯後的名稱與描述
+func ContextWithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeContextKey{}, models.NormalizeLocale(locale))
+}
+
+// LocaleFromContext 取得 context 中的請求語系，未設定時回傳空字串
+func LocaleFromContext(ctx context.Context) string {
+	locale, _ := ctx.Value(localeContextKey{}).(string)
+	return locale
+}
+
+// WithDefaultLocale 設定找不到請求語系的翻譯時改用的預設語系，預設語系也沒有翻譯時使用分類本身的名稱
+func WithDefaultLocale(locale string) Option {
+	return func(s *service) {
+		s.defaultLocale = models.NormalizeLocale(locale)
+	}
+}
+
+// SetCategoryTranslation 新增或更新分類在指定語系的名稱與描述
+func (s *service) SetCategoryTranslation(ctx context.Context, categoryID uint64, locale, name, description string) (*models.CategoryTranslation, error) {
+	translation := &models.CategoryTranslation{
+		CategoryID:  categoryID,
+		Locale:      models.NormalizeLocale(locale),
+		Name:        strings.TrimSpace(name),
+		Description: description,
+	}
+	if err := validateTranslation(translation.Locale, translation.Name); err != nil {
+		return nil, err
+	}
+
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		if err := s.category.UpsertCategoryTranslation(ctx, tx, translation); err != nil {
+			return fmt.Errorf("failed to set category translation: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return translation, nil
+}
+
+// DeleteCategoryTranslation 刪除分類在指定語系的翻譯，刪除後該語系改用後備語系
+func (s *service) DeleteCategoryTranslation(ctx context.Context, categoryID uint64, locale string) error {
+	return s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		deleted, err := s.category.DeleteCategoryTranslation(ctx, tx, categoryID, models.NormalizeLocale(locale))
+		if err != nil {
+			return fmt.Errorf("failed to delete category translation: %w", err)
+		}
+		if !deleted {
+			return ErrTranslationNotFound
+		}
+		return nil
+	})
+}
+
+// SetProductTranslation 新增或更新商品在指定語系的名稱與描述
+func (s *service) SetProductTranslation(ctx context.Context, productID, locale, name, description string) (*models.ProductTranslation, error) {
+	translation := &models.ProductTranslation{
+		ProductID:   productID,
+		Locale:      models.NormalizeLocale(locale),
+		Name:        strings.TrimSpace(name),
+		Description: description,
+	}
+	if err := validateTranslation(translation.Locale, translation.Name); err != nil {
+		return nil, err
+	}
+
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		if err := s.category.UpsertProductTranslation(ctx, tx, translation); err != nil {
+			return fmt.Errorf("failed to set product translation: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return translation, nil
+}
+
+// DeleteProductTranslation 刪除商品在指定語系的翻譯，刪除後該語系改用後備語系
+func (s *service) DeleteProductTranslation(ctx context.Context, productID, locale string) error {
+	return s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		deleted, err := s.category.DeleteProductTranslation(ctx, tx, productID, models.NormalizeLocale(locale))
+		if err != nil {
+			return fmt.Errorf("failed to delete product translation: %w", err)
+		}
+		if !deleted {
+			return ErrTranslationNotFound
+		}
+		return nil
+	})
+}
+
+// localeFallbacks 回傳請求語系依序嘗試的語系；context 未指定語系時為空，回傳分類本身的名稱，
+// 後台讀取後再寫回的資料因此不會被翻譯覆蓋
+func (s *service) localeFallbacks(ctx context.Context) []string {
+	locale := LocaleFromContext(ctx)
+	if locale == "" {
+		return nil
+	}
+	return models.LocaleFallbacks(locale, s.defaultLocale)
+}
+
+// localizeCategories 以請求語系的翻譯取代分類的名稱與描述，沒有任何後備語系的翻譯時保留原本的名稱
+func (s *service) localizeCategories(ctx context.Context, tx pgx.Tx, categories []*models.Category) error {
+	locales := s.localeFallbacks(ctx)
+	if len(locales) == 0 || len(categories) == 0 {
+		return nil
+	}
+
+	ids := make([]uint64, len(categories))
+	for i, category := range categories {
+		ids[i] = category.ID
+	}
+	translations, err := s.category.ListCategoryTranslations(ctx, tx, ids, locales)
+	if err != nil {
+		return fmt.Errorf("failed to list category translations: %w", err)
+	}
+
+	byCategory := make(map[uint64]map[string]*models.CategoryTranslation)
+	for _, translation := range translations {
+		if byCategory[translation.CategoryID] == nil {
+			byCategory[translation.CategoryID] = make(map[string]*models.CategoryTranslation)
+		}
+		byCategory[translation.CategoryID][translation.Locale] = translation
+	}
+
+	for _, category := range categories {
+		for _, locale := range locales {
+			if translation, ok := byCategory[category.ID][locale]; ok {
+				category.Name = translation.Name
+				category.Description = translation.Description
+				category.Locale = locale
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+// localizeCatalogEntries 寫入商品在請求語系的名稱與描述
+func (s *service) localizeCatalogEntries(ctx context.Context, tx pgx.Tx, entries []*models.CatalogEntry) error {
+	locales := s.localeFallbacks(ctx)
+	if len(locales) == 0 || len(entries) == 0 {
+		return nil
+	}
+
+	productIDs := make([]string, len(entries))
+	for i, entry := range entries {
+		productIDs[i] = entry.ProductID
+	}
+	translations, err := s.category.ListProductTranslations(ctx, tx, productIDs, locales)
+	if err != nil {
+		return fmt.Errorf("failed to list product translations: %w", err)
+	}
+
+	byProduct := make(map[string]map[string]*models.ProductTranslation)
+	for _, translation := range translations {
+		if byProduct[translation.ProductID] == nil {
+			byProduct[translation.ProductID] = make(map[string]*models.ProductTranslation)
+		}
+		byProduct[translation.ProductID][translation.Locale] = translation
+	}
+
+	for _, entry := range entries {
+		for _, locale := range locales {
+			if translation, ok := byProduct[entry.ProductID][locale]; ok {
+				entry.Name = translation.Name
+				entry.Description = translation.Description
+				entry.Locale = locale
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateTranslation 檢查翻譯的語系與名稱
+func validateTranslation(locale, name string) error {
+	if locale == "" {
+		return errors.New("translation locale is required")
+	}
+	if name == "" {
+		return errors.New("translation name is required")
+	}
+	return nil
+}
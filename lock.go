@@ -0,0 +1,45 @@
+package shop
+
+import "sync"
+
+// keyedMutex 提供以字串為 key 的互斥鎖，同一個 key 的呼叫會依序執行
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*keyedLock
+}
+
+type keyedLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+func newKeyedMutex() *keyedMutex {
+	return &keyedMutex{
+		locks: make(map[string]*keyedLock),
+	}
+}
+
+// Lock 取得 key 對應的鎖，回傳的函式用來釋放
+func (km *keyedMutex) Lock(key string) func() {
+	km.mu.Lock()
+	lock, exists := km.locks[key]
+	if !exists {
+		lock = &keyedLock{}
+		km.locks[key] = lock
+	}
+	lock.refs++
+	km.mu.Unlock()
+
+	lock.mu.Lock()
+
+	return func() {
+		lock.mu.Unlock()
+
+		km.mu.Lock()
+		lock.refs--
+		if lock.refs == 0 {
+			delete(km.locks, key)
+		}
+		km.mu.Unlock()
+	}
+}
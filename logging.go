@@ -0,0 +1,22 @@
+package shop
+
+import (
+	"go.uber.org/zap"
+
+	"gofalre.io/shop/driver"
+)
+
+// WithLogOptions 設定 service、NATS 事件與 worker 各自的記錄等級與取樣，未設定時沿用傳入的 logger
+func WithLogOptions(opts driver.LogOptions) Option {
+	return func(s *service) {
+		s.logOptions = &opts
+	}
+}
+
+// moduleLogger 依記錄設定回傳模組專用的 logger
+func (s *service) moduleLogger(base *zap.Logger, module string) *zap.Logger {
+	if s.logOptions == nil {
+		return base
+	}
+	return driver.ModuleLogger(base, module, *s.logOptions)
+}
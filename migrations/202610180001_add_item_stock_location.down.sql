@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_stocks_product_id_location;
+
+ALTER TABLE order_items DROP COLUMN IF EXISTS location;
+ALTER TABLE cart_items DROP COLUMN IF EXISTS location;
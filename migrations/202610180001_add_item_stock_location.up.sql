@@ -0,0 +1,5 @@
+-- 購物車及訂單項目記錄出貨 / 取貨地點
+ALTER TABLE cart_items ADD COLUMN location VARCHAR(255);
+ALTER TABLE order_items ADD COLUMN location VARCHAR(255);
+
+CREATE INDEX idx_stocks_product_id_location ON stocks(product_id, location);
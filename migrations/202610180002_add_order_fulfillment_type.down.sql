@@ -0,0 +1,6 @@
+ALTER TABLE orders DROP COLUMN IF EXISTS pickup_location;
+ALTER TABLE orders DROP COLUMN IF EXISTS fulfillment_type;
+
+-- PostgreSQL 無法從 enum 中移除值，ready_for_pickup 會保留在 order_status 中
+
+DROP TYPE IF EXISTS fulfillment_type;
@@ -0,0 +1,7 @@
+CREATE TYPE fulfillment_type AS ENUM ('ship', 'pickup');
+
+ALTER TYPE order_status ADD VALUE IF NOT EXISTS 'ready_for_pickup';
+
+-- 訂單的履約方式：寄送或門市自取
+ALTER TABLE orders ADD COLUMN fulfillment_type fulfillment_type NOT NULL DEFAULT 'ship';
+ALTER TABLE orders ADD COLUMN pickup_location VARCHAR(255);
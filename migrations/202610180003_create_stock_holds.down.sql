@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_stock_holds_expires_at;
+DROP INDEX IF EXISTS idx_stock_holds_stock_id;
+
+DROP TABLE IF EXISTS stock_holds;
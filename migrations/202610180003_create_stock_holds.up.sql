@@ -0,0 +1,15 @@
+ALTER TYPE stock_movement_reference_type ADD VALUE IF NOT EXISTS 'hold';
+
+-- 人工保留庫存（活動、拍攝、品檢等）
+CREATE TABLE stock_holds (
+                             id SERIAL PRIMARY KEY,
+                             stock_id INTEGER NOT NULL REFERENCES stocks(id) ON DELETE CASCADE,
+                             quantity INTEGER NOT NULL CHECK (quantity > 0),
+                             reason VARCHAR(255) NOT NULL,
+                             expires_at TIMESTAMP WITH TIME ZONE,
+                             released_at TIMESTAMP WITH TIME ZONE,
+                             created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_stock_holds_stock_id ON stock_holds(stock_id);
+CREATE INDEX idx_stock_holds_expires_at ON stock_holds(expires_at) WHERE released_at IS NULL;
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_price_changes_status_effective_at;
+DROP INDEX IF EXISTS idx_price_changes_price_id_effective_at;
+
+DROP TABLE IF EXISTS price_changes;
+
+DROP TYPE IF EXISTS price_change_status;
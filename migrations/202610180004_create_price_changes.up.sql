@@ -0,0 +1,17 @@
+CREATE TYPE price_change_status AS ENUM ('scheduled', 'applied', 'cancelled');
+
+-- 價格變動記錄（含排程中的變價）
+CREATE TABLE price_changes (
+                               id SERIAL PRIMARY KEY,
+                               price_id VARCHAR(255) NOT NULL REFERENCES prices(id) ON DELETE CASCADE,
+                               product_id VARCHAR(255) NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+                               unit_price DECIMAL(10, 2) NOT NULL,
+                               status price_change_status NOT NULL DEFAULT 'scheduled',
+                               reason VARCHAR(255),
+                               effective_at TIMESTAMP WITH TIME ZONE NOT NULL,
+                               applied_at TIMESTAMP WITH TIME ZONE,
+                               created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_price_changes_price_id_effective_at ON price_changes(price_id, effective_at);
+CREATE INDEX idx_price_changes_status_effective_at ON price_changes(status, effective_at);
@@ -0,0 +1,7 @@
+ALTER TABLE stock_movements
+    DROP COLUMN IF EXISTS reason,
+    DROP COLUMN IF EXISTS unit_cost,
+    DROP COLUMN IF EXISTS note,
+    DROP COLUMN IF EXISTS actor;
+
+DROP TYPE IF EXISTS stock_movement_reason;
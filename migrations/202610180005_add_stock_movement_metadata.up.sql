@@ -0,0 +1,8 @@
+CREATE TYPE stock_movement_reason AS ENUM ('received', 'damaged', 'lost', 'found', 'recount', 'correction', 'other');
+
+-- 庫存變動的補充資訊（操作人員、備註、單位成本、調整原因）
+ALTER TABLE stock_movements
+    ADD COLUMN actor VARCHAR(255),
+    ADD COLUMN note TEXT,
+    ADD COLUMN unit_cost DECIMAL(10, 2),
+    ADD COLUMN reason stock_movement_reason;
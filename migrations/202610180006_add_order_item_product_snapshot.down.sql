@@ -0,0 +1,5 @@
+ALTER TABLE order_items
+    DROP COLUMN IF EXISTS tax_class,
+    DROP COLUMN IF EXISTS image_url,
+    DROP COLUMN IF EXISTS sku,
+    DROP COLUMN IF EXISTS product_name;
@@ -0,0 +1,6 @@
+-- 下單當時的商品資訊快照，避免商品目錄變動後歷史訂單無法辨識
+ALTER TABLE order_items
+    ADD COLUMN product_name VARCHAR(255),
+    ADD COLUMN sku VARCHAR(255),
+    ADD COLUMN image_url TEXT,
+    ADD COLUMN tax_class VARCHAR(64);
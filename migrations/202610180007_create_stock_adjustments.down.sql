@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_stock_adjustments_status;
+DROP INDEX IF EXISTS idx_stock_adjustments_stock_id;
+
+DROP TABLE IF EXISTS stock_adjustments;
+
+DROP TYPE IF EXISTS stock_adjustment_status;
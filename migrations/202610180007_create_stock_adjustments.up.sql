@@ -0,0 +1,19 @@
+CREATE TYPE stock_adjustment_status AS ENUM ('pending', 'approved', 'rejected');
+
+-- 人工庫存調整申請，超過門檻的調整需要第二位人員核准後才會入帳
+CREATE TABLE stock_adjustments (
+                                   id SERIAL PRIMARY KEY,
+                                   stock_id INTEGER NOT NULL REFERENCES stocks(id) ON DELETE CASCADE,
+                                   quantity_delta INTEGER NOT NULL CHECK (quantity_delta <> 0),
+                                   reason stock_movement_reason NOT NULL,
+                                   note TEXT,
+                                   status stock_adjustment_status NOT NULL DEFAULT 'pending',
+                                   requested_by VARCHAR(255) NOT NULL,
+                                   reviewed_by VARCHAR(255),
+                                   review_note TEXT,
+                                   created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                                   reviewed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_stock_adjustments_stock_id ON stock_adjustments(stock_id);
+CREATE INDEX idx_stock_adjustments_status ON stock_adjustments(status);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_campaign_redemptions_order_id;
+DROP INDEX IF EXISTS idx_campaign_redemptions_campaign_id;
+DROP INDEX IF EXISTS idx_discount_campaigns_window;
+
+DROP TABLE IF EXISTS campaign_redemptions;
+DROP TABLE IF EXISTS discount_campaigns;
@@ -0,0 +1,29 @@
+-- 分類折扣活動，活動期間內該分類（可包含子分類）的商品自動折扣
+CREATE TABLE discount_campaigns (
+                                    id SERIAL PRIMARY KEY,
+                                    name VARCHAR(255) NOT NULL,
+                                    category_id INTEGER NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+                                    include_subcategories BOOLEAN NOT NULL DEFAULT TRUE,
+                                    percent_off DECIMAL(5, 2) NOT NULL CHECK (percent_off > 0 AND percent_off <= 100),
+                                    starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+                                    ends_at TIMESTAMP WITH TIME ZONE NOT NULL,
+                                    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                                    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                                    CHECK (ends_at > starts_at)
+);
+
+-- 訂單實際套用活動折扣的明細，用於活動成效報表
+CREATE TABLE campaign_redemptions (
+                                      id SERIAL PRIMARY KEY,
+                                      campaign_id INTEGER NOT NULL REFERENCES discount_campaigns(id) ON DELETE CASCADE,
+                                      order_id INTEGER NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+                                      product_id VARCHAR(255) NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+                                      quantity INTEGER NOT NULL,
+                                      subtotal DECIMAL(10, 2) NOT NULL,
+                                      discount DECIMAL(10, 2) NOT NULL,
+                                      created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_discount_campaigns_window ON discount_campaigns(starts_at, ends_at);
+CREATE INDEX idx_campaign_redemptions_campaign_id ON campaign_redemptions(campaign_id);
+CREATE INDEX idx_campaign_redemptions_order_id ON campaign_redemptions(order_id);
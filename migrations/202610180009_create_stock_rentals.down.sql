@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_stock_rentals_customer_id;
+DROP INDEX IF EXISTS idx_stock_rentals_stock_id_dates;
+
+DROP TABLE IF EXISTS stock_rentals;
+
+DROP TYPE IF EXISTS stock_rental_status;
+
+ALTER TABLE stocks DROP COLUMN IF EXISTS rental_enabled;
@@ -0,0 +1,21 @@
+ALTER TABLE stocks ADD COLUMN rental_enabled BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TYPE stock_rental_status AS ENUM ('reserved', 'returned', 'cancelled');
+
+-- 租借預約，庫存在 starts_on 至 ends_on（不含）期間被佔用
+CREATE TABLE stock_rentals (
+                               id SERIAL PRIMARY KEY,
+                               stock_id INTEGER NOT NULL REFERENCES stocks(id) ON DELETE CASCADE,
+                               customer_id VARCHAR(255) NOT NULL,
+                               quantity INTEGER NOT NULL CHECK (quantity > 0),
+                               starts_on DATE NOT NULL,
+                               ends_on DATE NOT NULL,
+                               status stock_rental_status NOT NULL DEFAULT 'reserved',
+                               note TEXT,
+                               created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                               updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                               CHECK (ends_on > starts_on)
+);
+
+CREATE INDEX idx_stock_rentals_stock_id_dates ON stock_rentals(stock_id, starts_on, ends_on);
+CREATE INDEX idx_stock_rentals_customer_id ON stock_rentals(customer_id);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_stock_movements_reversal_of_id;
+
+ALTER TABLE stock_movements
+    DROP COLUMN IF EXISTS reversal_of_id;
@@ -0,0 +1,5 @@
+-- 沖銷記錄指向被沖銷的原始庫存變動，每筆變動最多只能被沖銷一次
+ALTER TABLE stock_movements
+    ADD COLUMN reversal_of_id INTEGER REFERENCES stock_movements(id);
+
+CREATE UNIQUE INDEX idx_stock_movements_reversal_of_id ON stock_movements(reversal_of_id) WHERE reversal_of_id IS NOT NULL;
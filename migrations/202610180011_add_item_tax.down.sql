@@ -0,0 +1,7 @@
+ALTER TABLE order_items
+    DROP COLUMN IF EXISTS tax_amount,
+    DROP COLUMN IF EXISTS tax_rate;
+
+ALTER TABLE cart_items
+    DROP COLUMN IF EXISTS tax_amount,
+    DROP COLUMN IF EXISTS tax_rate;
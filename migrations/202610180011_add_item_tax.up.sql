@@ -0,0 +1,8 @@
+-- 每個項目的稅率與稅額，供需要逐項列示稅額的地區使用
+ALTER TABLE cart_items
+    ADD COLUMN tax_rate DECIMAL(6, 4) NOT NULL DEFAULT 0,
+    ADD COLUMN tax_amount DECIMAL(10, 2) NOT NULL DEFAULT 0;
+
+ALTER TABLE order_items
+    ADD COLUMN tax_rate DECIMAL(6, 4) NOT NULL DEFAULT 0,
+    ADD COLUMN tax_amount DECIMAL(10, 2) NOT NULL DEFAULT 0;
@@ -0,0 +1,9 @@
+-- 分類的 slug 用於跨環境匯入匯出時比對分類
+ALTER TABLE categories ADD COLUMN slug VARCHAR(255);
+
+UPDATE categories
+SET slug = TRIM(BOTH '-' FROM LOWER(REGEXP_REPLACE(name, '[^a-zA-Z0-9]+', '-', 'g'))) || '-' || id;
+
+ALTER TABLE categories ALTER COLUMN slug SET NOT NULL;
+
+CREATE UNIQUE INDEX idx_categories_slug ON categories(slug);
@@ -0,0 +1,19 @@
+-- 將封存的訂單搬回 orders / order_items，避免回滾時遺失資料
+INSERT INTO orders (id, customer_id, cart_id, status, currency, subtotal, tax, discount, total, payment_intent_id, invoice_id, subscription_id, refund_id, shipping_address, billing_address, fulfillment_type, pickup_location, created_at, updated_at)
+SELECT id, customer_id, cart_id, status, currency, subtotal, tax, discount, total, payment_intent_id, invoice_id, subscription_id, refund_id, shipping_address, billing_address, fulfillment_type, pickup_location, created_at, updated_at
+FROM orders_archive;
+
+INSERT INTO order_items (id, order_id, product_id, price_id, stock_id, quantity, unit_price, subtotal, location, product_name, sku, image_url, tax_class, tax_rate, tax_amount, created_at, updated_at)
+SELECT id, order_id, product_id, price_id, stock_id, quantity, unit_price, subtotal, location, product_name, sku, image_url, tax_class, tax_rate, tax_amount, created_at, updated_at
+FROM order_items_archive;
+
+DELETE FROM campaign_redemptions cr WHERE NOT EXISTS (SELECT 1 FROM orders o WHERE o.id = cr.order_id);
+ALTER TABLE campaign_redemptions
+    ADD CONSTRAINT campaign_redemptions_order_id_fkey FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE;
+
+DROP INDEX IF EXISTS idx_orders_status_updated_at;
+DROP INDEX IF EXISTS idx_order_items_archive_order_id;
+DROP INDEX IF EXISTS idx_orders_archive_customer_id;
+
+DROP TABLE IF EXISTS order_items_archive;
+DROP TABLE IF EXISTS orders_archive;
@@ -0,0 +1,50 @@
+-- 已完成或已取消的舊訂單搬移到封存表，保持 orders / order_items 的資料量
+CREATE TABLE orders_archive (
+                                id INTEGER PRIMARY KEY,
+                                customer_id VARCHAR(255) NOT NULL,
+                                cart_id INTEGER,
+                                status order_status NOT NULL,
+                                currency currency NOT NULL,
+                                subtotal DECIMAL(10, 2) NOT NULL,
+                                tax DECIMAL(10, 2) NOT NULL,
+                                discount DECIMAL(10, 2) NOT NULL,
+                                total DECIMAL(10, 2) NOT NULL,
+                                payment_intent_id VARCHAR(255),
+                                invoice_id VARCHAR(255),
+                                subscription_id VARCHAR(255),
+                                refund_id VARCHAR(255),
+                                shipping_address JSONB NOT NULL,
+                                billing_address JSONB NOT NULL,
+                                fulfillment_type fulfillment_type NOT NULL,
+                                pickup_location VARCHAR(255),
+                                created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+                                updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+                                archived_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE order_items_archive (
+                                     id INTEGER PRIMARY KEY,
+                                     order_id INTEGER NOT NULL REFERENCES orders_archive(id) ON DELETE CASCADE,
+                                     product_id VARCHAR(255) NOT NULL,
+                                     price_id VARCHAR(255) NOT NULL,
+                                     stock_id INTEGER,
+                                     quantity INTEGER NOT NULL,
+                                     unit_price DECIMAL(10, 2) NOT NULL,
+                                     subtotal DECIMAL(10, 2) NOT NULL,
+                                     location VARCHAR(255),
+                                     product_name VARCHAR(255),
+                                     sku VARCHAR(255),
+                                     image_url TEXT,
+                                     tax_class VARCHAR(64),
+                                     tax_rate DECIMAL(6, 4) NOT NULL,
+                                     tax_amount DECIMAL(10, 2) NOT NULL,
+                                     created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+                                     updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX idx_orders_archive_customer_id ON orders_archive(customer_id);
+CREATE INDEX idx_order_items_archive_order_id ON order_items_archive(order_id);
+CREATE INDEX idx_orders_status_updated_at ON orders(status, updated_at);
+
+-- 活動折扣明細需在訂單封存後保留，改為不受 orders 外鍵約束
+ALTER TABLE campaign_redemptions DROP CONSTRAINT IF EXISTS campaign_redemptions_order_id_fkey;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_events_created_at;
+
+ALTER TABLE events
+    DROP COLUMN IF EXISTS payload_compressed,
+    DROP COLUMN IF EXISTS payload;
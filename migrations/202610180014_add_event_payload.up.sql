@@ -0,0 +1,6 @@
+-- 保存 Stripe 送來的原始事件內容，供處理異常時排查；內容較大時以 gzip 壓縮
+ALTER TABLE events
+    ADD COLUMN payload BYTEA,
+    ADD COLUMN payload_compressed BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX idx_events_created_at ON events(created_at) WHERE payload IS NOT NULL;
@@ -0,0 +1,5 @@
+-- 從 Stripe PaymentIntent / Checkout Session 帶入的 metadata（例如內部訂單編號、活動代碼）
+ALTER TABLE orders ADD COLUMN metadata JSONB NOT NULL DEFAULT '{}'::jsonb;
+ALTER TABLE orders_archive ADD COLUMN metadata JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+CREATE INDEX idx_orders_metadata ON orders USING GIN (metadata jsonb_path_ops);
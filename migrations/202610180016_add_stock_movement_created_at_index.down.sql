@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_stock_movements_stock_id_created_at;
@@ -0,0 +1,2 @@
+-- 依時間回推庫存水位時只需掃描指定時間點之後的變動
+CREATE INDEX idx_stock_movements_stock_id_created_at ON stock_movements(stock_id, created_at);
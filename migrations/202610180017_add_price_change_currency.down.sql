@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_price_changes_product_id_effective_at;
+
+ALTER TABLE price_changes DROP COLUMN IF EXISTS currency;
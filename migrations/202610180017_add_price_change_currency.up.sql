@@ -0,0 +1,5 @@
+-- 價格變動記錄的幣別，舊資料沒有幣別資訊因此允許為 NULL
+ALTER TABLE price_changes ADD COLUMN currency currency;
+
+-- 商品列表頁依商品查詢目前生效的價格
+CREATE INDEX idx_price_changes_product_id_effective_at ON price_changes(product_id, effective_at) WHERE status = 'applied';
@@ -0,0 +1,7 @@
+ALTER TABLE orders_archive
+    DROP COLUMN IF EXISTS tax_transaction_id,
+    DROP COLUMN IF EXISTS tax_calculation_id;
+
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS tax_transaction_id,
+    DROP COLUMN IF EXISTS tax_calculation_id;
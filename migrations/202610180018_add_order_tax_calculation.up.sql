@@ -0,0 +1,8 @@
+-- 外部稅務服務（Stripe Tax）的計算與交易記錄，付款成功後以計算結果建立稅務交易
+ALTER TABLE orders
+    ADD COLUMN tax_calculation_id VARCHAR(255),
+    ADD COLUMN tax_transaction_id VARCHAR(255);
+
+ALTER TABLE orders_archive
+    ADD COLUMN tax_calculation_id VARCHAR(255),
+    ADD COLUMN tax_transaction_id VARCHAR(255);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_stock_movements_stock_id_id;
+
+DROP TABLE IF EXISTS stock_projections;
@@ -0,0 +1,13 @@
+-- 事件溯源模式的庫存：stock_movements 為唯一的寫入來源，stocks 的 quantity 與 reserved_quantity 只是投影，
+-- 由投影程序依 projected_movement_id 之後的變動非同步更新；base_* 為啟用時的快照，重播時由此重建
+CREATE TABLE stock_projections (
+                                   stock_id INTEGER PRIMARY KEY REFERENCES stocks(id) ON DELETE CASCADE,
+                                   base_quantity INTEGER NOT NULL,
+                                   base_reserved_quantity INTEGER NOT NULL,
+                                   base_movement_id INTEGER NOT NULL,
+                                   projected_movement_id INTEGER NOT NULL,
+                                   enabled_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                                   projected_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_stock_movements_stock_id_id ON stock_movements(stock_id, id);
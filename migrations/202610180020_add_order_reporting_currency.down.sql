@@ -0,0 +1,18 @@
+DROP INDEX IF EXISTS idx_orders_archive_created_at;
+DROP INDEX IF EXISTS idx_orders_created_at;
+
+ALTER TABLE orders_archive
+    DROP COLUMN IF EXISTS reporting_total,
+    DROP COLUMN IF EXISTS reporting_discount,
+    DROP COLUMN IF EXISTS reporting_tax,
+    DROP COLUMN IF EXISTS reporting_subtotal,
+    DROP COLUMN IF EXISTS exchange_rate,
+    DROP COLUMN IF EXISTS reporting_currency;
+
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS reporting_total,
+    DROP COLUMN IF EXISTS reporting_discount,
+    DROP COLUMN IF EXISTS reporting_tax,
+    DROP COLUMN IF EXISTS reporting_subtotal,
+    DROP COLUMN IF EXISTS exchange_rate,
+    DROP COLUMN IF EXISTS reporting_currency;
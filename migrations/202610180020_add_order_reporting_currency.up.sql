@@ -0,0 +1,20 @@
+-- 訂單成立時換算成報表幣別的匯率快照，報表以換算後的金額彙整不同幣別的訂單；未設定報表幣別時為 NULL
+ALTER TABLE orders
+    ADD COLUMN reporting_currency currency,
+    ADD COLUMN exchange_rate NUMERIC(18, 8),
+    ADD COLUMN reporting_subtotal DECIMAL(10, 2),
+    ADD COLUMN reporting_tax DECIMAL(10, 2),
+    ADD COLUMN reporting_discount DECIMAL(10, 2),
+    ADD COLUMN reporting_total DECIMAL(10, 2);
+
+ALTER TABLE orders_archive
+    ADD COLUMN reporting_currency currency,
+    ADD COLUMN exchange_rate NUMERIC(18, 8),
+    ADD COLUMN reporting_subtotal DECIMAL(10, 2),
+    ADD COLUMN reporting_tax DECIMAL(10, 2),
+    ADD COLUMN reporting_discount DECIMAL(10, 2),
+    ADD COLUMN reporting_total DECIMAL(10, 2);
+
+-- 銷售報表依下單時間查詢
+CREATE INDEX idx_orders_created_at ON orders(created_at);
+CREATE INDEX idx_orders_archive_created_at ON orders_archive(created_at);
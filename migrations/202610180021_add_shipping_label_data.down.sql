@@ -0,0 +1,17 @@
+DROP TABLE IF EXISTS shipments;
+
+ALTER TABLE order_items_archive
+    DROP COLUMN IF EXISTS origin_country,
+    DROP COLUMN IF EXISTS hs_code,
+    DROP COLUMN IF EXISTS height_mm,
+    DROP COLUMN IF EXISTS width_mm,
+    DROP COLUMN IF EXISTS length_mm,
+    DROP COLUMN IF EXISTS weight_grams;
+
+ALTER TABLE order_items
+    DROP COLUMN IF EXISTS origin_country,
+    DROP COLUMN IF EXISTS hs_code,
+    DROP COLUMN IF EXISTS height_mm,
+    DROP COLUMN IF EXISTS width_mm,
+    DROP COLUMN IF EXISTS length_mm,
+    DROP COLUMN IF EXISTS weight_grams;
@@ -0,0 +1,26 @@
+-- 下單當時的商品重量、尺寸與報關資訊快照，供揀貨單及物流標籤使用；重量以公克、尺寸以公釐為單位
+ALTER TABLE order_items
+    ADD COLUMN weight_grams INTEGER CHECK (weight_grams >= 0),
+    ADD COLUMN length_mm INTEGER CHECK (length_mm >= 0),
+    ADD COLUMN width_mm INTEGER CHECK (width_mm >= 0),
+    ADD COLUMN height_mm INTEGER CHECK (height_mm >= 0),
+    ADD COLUMN hs_code VARCHAR(16),
+    ADD COLUMN origin_country VARCHAR(2);
+
+ALTER TABLE order_items_archive
+    ADD COLUMN weight_grams INTEGER,
+    ADD COLUMN length_mm INTEGER,
+    ADD COLUMN width_mm INTEGER,
+    ADD COLUMN height_mm INTEGER,
+    ADD COLUMN hs_code VARCHAR(16),
+    ADD COLUMN origin_country VARCHAR(2);
+
+-- 出貨單，每個訂單在每個出貨地點最多一筆，包含該地點出貨的所有訂單項目
+-- 出貨紀錄需在訂單封存後保留，因此不受 orders 外鍵約束
+CREATE TABLE shipments (
+                           id SERIAL PRIMARY KEY,
+                           order_id INTEGER NOT NULL,
+                           location VARCHAR(255) NOT NULL,
+                           created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                           UNIQUE (order_id, location)
+);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_orders_archive_order_number;
+DROP INDEX IF EXISTS idx_orders_order_number;
+
+ALTER TABLE orders_archive DROP COLUMN IF EXISTS order_number;
+ALTER TABLE orders DROP COLUMN IF EXISTS order_number;
@@ -0,0 +1,11 @@
+-- 對外顯示的訂單編號，避免以流水號 ID 洩漏銷售量；既有訂單以 ID 作為編號
+ALTER TABLE orders ADD COLUMN order_number VARCHAR(64);
+UPDATE orders SET order_number = id::text;
+ALTER TABLE orders ALTER COLUMN order_number SET NOT NULL;
+
+ALTER TABLE orders_archive ADD COLUMN order_number VARCHAR(64);
+UPDATE orders_archive SET order_number = id::text;
+ALTER TABLE orders_archive ALTER COLUMN order_number SET NOT NULL;
+
+CREATE UNIQUE INDEX idx_orders_order_number ON orders(order_number);
+CREATE UNIQUE INDEX idx_orders_archive_order_number ON orders_archive(order_number);
@@ -0,0 +1,3 @@
+ALTER TABLE carts
+    DROP COLUMN IF EXISTS billing_address,
+    DROP COLUMN IF EXISTS shipping_address;
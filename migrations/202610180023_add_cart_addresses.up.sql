@@ -0,0 +1,4 @@
+-- 結帳前在購物車上填寫的寄送與帳單地址，結帳時複製到訂單
+ALTER TABLE carts
+    ADD COLUMN shipping_address JSONB,
+    ADD COLUMN billing_address JSONB;
@@ -0,0 +1,27 @@
+-- 退款紀錄，policy_version 為退款當時套用的退款政策版本，供稽核使用
+-- 退款紀錄需在訂單封存後保留，因此不受 orders 外鍵約束
+CREATE TABLE refunds (
+                         id SERIAL PRIMARY KEY,
+                         order_id INTEGER NOT NULL,
+                         stripe_refund_id VARCHAR(255),
+                         currency currency NOT NULL,
+                         amount DECIMAL(10, 2) NOT NULL CHECK (amount >= 0),
+                         restocking_fee DECIMAL(10, 2) NOT NULL DEFAULT 0 CHECK (restocking_fee >= 0),
+                         reason TEXT,
+                         policy_version VARCHAR(64) NOT NULL,
+                         created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- 退款涵蓋的訂單項目及其套用的退款規則，每個訂單項目只能退款一次
+CREATE TABLE refund_items (
+                              id SERIAL PRIMARY KEY,
+                              refund_id INTEGER NOT NULL REFERENCES refunds(id) ON DELETE CASCADE,
+                              order_item_id INTEGER NOT NULL UNIQUE,
+                              quantity INTEGER NOT NULL CHECK (quantity > 0),
+                              amount DECIMAL(10, 2) NOT NULL CHECK (amount >= 0),
+                              restocking_fee DECIMAL(10, 2) NOT NULL DEFAULT 0 CHECK (restocking_fee >= 0),
+                              rule VARCHAR(32) NOT NULL
+);
+
+CREATE INDEX idx_refunds_order_id ON refunds(order_id);
+CREATE INDEX idx_refund_items_refund_id ON refund_items(refund_id);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_stock_transfers_status;
+
+DROP TABLE IF EXISTS stock_transfers;
+
+DROP TYPE IF EXISTS stock_transfer_status;
@@ -0,0 +1,21 @@
+ALTER TYPE stock_movement_reference_type ADD VALUE IF NOT EXISTS 'transfer';
+
+CREATE TYPE stock_transfer_status AS ENUM ('draft', 'approved', 'rejected');
+
+-- 倉庫間的調撥單，由調撥建議自動建立的草稿需經人員核准後才會移動庫存
+CREATE TABLE stock_transfers (
+                                 id SERIAL PRIMARY KEY,
+                                 from_stock_id INTEGER NOT NULL REFERENCES stocks(id) ON DELETE CASCADE,
+                                 to_stock_id INTEGER NOT NULL REFERENCES stocks(id) ON DELETE CASCADE,
+                                 quantity INTEGER NOT NULL CHECK (quantity > 0),
+                                 note TEXT,
+                                 status stock_transfer_status NOT NULL DEFAULT 'draft',
+                                 requested_by VARCHAR(255) NOT NULL,
+                                 reviewed_by VARCHAR(255),
+                                 review_note TEXT,
+                                 created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                                 reviewed_at TIMESTAMP WITH TIME ZONE,
+                                 CHECK (from_stock_id <> to_stock_id)
+);
+
+CREATE INDEX idx_stock_transfers_status ON stock_transfers(status);
@@ -0,0 +1,7 @@
+ALTER TABLE orders_archive
+    DROP COLUMN IF EXISTS estimated_delivery_latest,
+    DROP COLUMN IF EXISTS estimated_delivery_earliest;
+
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS estimated_delivery_latest,
+    DROP COLUMN IF EXISTS estimated_delivery_earliest;
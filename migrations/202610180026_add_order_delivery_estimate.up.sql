@@ -0,0 +1,8 @@
+-- 下單時估算的送達日期區間，由倉庫出貨截止時間與物流商運送天數推算；自取或未設定估算時為 NULL
+ALTER TABLE orders
+    ADD COLUMN estimated_delivery_earliest DATE,
+    ADD COLUMN estimated_delivery_latest DATE;
+
+ALTER TABLE orders_archive
+    ADD COLUMN estimated_delivery_earliest DATE,
+    ADD COLUMN estimated_delivery_latest DATE;
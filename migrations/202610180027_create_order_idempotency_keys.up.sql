@@ -0,0 +1,8 @@
+-- 後台手動建立訂單的 idempotency key，相同 key 的重試回傳第一次建立的訂單；
+-- request_hash 為請求內容的 SHA-256，同一個 key 搭配不同內容時拒絕。order_id 不設外鍵，訂單封存後仍保留記錄
+CREATE TABLE order_idempotency_keys (
+                                        idempotency_key VARCHAR(255) PRIMARY KEY,
+                                        request_hash CHAR(64) NOT NULL,
+                                        order_id INTEGER,
+                                        created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_order_holds_active;
+DROP INDEX IF EXISTS idx_order_holds_order_id;
+
+DROP TABLE IF EXISTS order_holds;
+
+-- PostgreSQL 無法從 enum 中移除值，on_hold 會保留在 order_status 中
@@ -0,0 +1,15 @@
+ALTER TYPE order_status ADD VALUE IF NOT EXISTS 'on_hold';
+
+-- 人員暫停訂單履約的記錄，previous_status 為暫停前的狀態，解除時恢復；每筆訂單同時最多一筆未解除的暫停
+-- 暫停記錄需在訂單封存後保留，因此不受 orders 外鍵約束
+CREATE TABLE order_holds (
+                             id SERIAL PRIMARY KEY,
+                             order_id INTEGER NOT NULL,
+                             reason TEXT NOT NULL,
+                             previous_status order_status NOT NULL,
+                             created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                             released_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_order_holds_order_id ON order_holds(order_id);
+CREATE UNIQUE INDEX idx_order_holds_active ON order_holds(order_id) WHERE released_at IS NULL;
@@ -0,0 +1,19 @@
+-- 分類與商品名稱、描述的翻譯，locale 為 BCP 47 語系標籤（例如 zh-TW、en）；
+-- 找不到指定語系時依序改用語言代碼與預設語系，都沒有時使用分類本身的名稱
+CREATE TABLE category_translations (
+                                       category_id INTEGER NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+                                       locale VARCHAR(35) NOT NULL,
+                                       name VARCHAR(255) NOT NULL,
+                                       description TEXT,
+                                       updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                                       PRIMARY KEY (category_id, locale)
+);
+
+CREATE TABLE product_translations (
+                                      product_id VARCHAR(255) NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+                                      locale VARCHAR(35) NOT NULL,
+                                      name VARCHAR(255) NOT NULL,
+                                      description TEXT,
+                                      updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                                      PRIMARY KEY (product_id, locale)
+);
@@ -0,0 +1 @@
+-- PostgreSQL 無法從 enum 中移除值，theft 與 manual 會保留在 stock_movement_reason 與 stock_movement_reference_type 中
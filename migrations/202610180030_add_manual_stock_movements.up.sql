@@ -0,0 +1,2 @@
+ALTER TYPE stock_movement_reason ADD VALUE IF NOT EXISTS 'theft';
+ALTER TYPE stock_movement_reference_type ADD VALUE IF NOT EXISTS 'manual';
@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_fraud_reviews_pending;
+DROP INDEX IF EXISTS idx_fraud_reviews_status;
+
+DROP TABLE IF EXISTS fraud_reviews;
+
+DROP INDEX IF EXISTS idx_payment_fingerprints_fingerprint;
+
+DROP TABLE IF EXISTS payment_fingerprints;
+
+DROP TYPE IF EXISTS fraud_review_status;
+DROP TYPE IF EXISTS fraud_review_reason;
@@ -0,0 +1,31 @@
+CREATE TYPE fraud_review_reason AS ENUM ('fingerprint_velocity');
+CREATE TYPE fraud_review_status AS ENUM ('pending', 'cleared', 'confirmed');
+
+-- 付款使用的卡片指紋，用於偵測同一張卡在短時間內被多個客戶使用
+CREATE TABLE payment_fingerprints (
+                                      payment_intent_id VARCHAR(255) NOT NULL,
+                                      fingerprint VARCHAR(255) NOT NULL,
+                                      customer_id VARCHAR(255) NOT NULL,
+                                      order_id INTEGER NOT NULL,
+                                      created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                                      PRIMARY KEY (payment_intent_id, fingerprint)
+);
+
+CREATE INDEX idx_payment_fingerprints_fingerprint ON payment_fingerprints(fingerprint, created_at);
+
+-- 待人員審核的可疑訂單，每筆訂單每種原因同時最多一筆待審核的記錄
+-- 審核記錄需在訂單封存後保留，因此不受 orders 外鍵約束
+CREATE TABLE fraud_reviews (
+                               id SERIAL PRIMARY KEY,
+                               order_id INTEGER NOT NULL,
+                               reason fraud_review_reason NOT NULL,
+                               detail TEXT NOT NULL,
+                               status fraud_review_status NOT NULL DEFAULT 'pending',
+                               created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                               resolved_at TIMESTAMP WITH TIME ZONE,
+                               resolved_by VARCHAR(255),
+                               resolution_note TEXT
+);
+
+CREATE INDEX idx_fraud_reviews_status ON fraud_reviews(status);
+CREATE UNIQUE INDEX idx_fraud_reviews_pending ON fraud_reviews(order_id, reason) WHERE status = 'pending';
@@ -0,0 +1,13 @@
+-- 實體門市，location 對應 stocks.location，用於查詢附近有庫存的門市
+-- hours 為每週營業時間，格式為 [{"weekday": 1, "opens": "09:00", "closes": "21:00"}]
+CREATE TABLE stores (
+                        id SERIAL PRIMARY KEY,
+                        name VARCHAR(255) NOT NULL,
+                        location VARCHAR(255) NOT NULL UNIQUE,
+                        address JSONB,
+                        latitude DOUBLE PRECISION NOT NULL CHECK (latitude BETWEEN -90 AND 90),
+                        longitude DOUBLE PRECISION NOT NULL CHECK (longitude BETWEEN -180 AND 180),
+                        hours JSONB NOT NULL DEFAULT '[]',
+                        created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                        updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
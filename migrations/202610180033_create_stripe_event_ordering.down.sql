@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_parked_events_event_created;
+DROP INDEX IF EXISTS idx_parked_events_object_id;
+
+DROP TABLE IF EXISTS parked_events;
+
+DROP INDEX IF EXISTS idx_stripe_object_events_type;
+
+DROP TABLE IF EXISTS stripe_object_events;
@@ -0,0 +1,24 @@
+-- 已處理的 Stripe 事件依物件記錄，用於判斷事件是否過期或前一個事件是否已處理
+CREATE TABLE stripe_object_events (
+                                      object_id VARCHAR(255) NOT NULL,
+                                      event_id VARCHAR(255) NOT NULL,
+                                      event_type VARCHAR(255) NOT NULL,
+                                      event_created TIMESTAMP WITH TIME ZONE NOT NULL,
+                                      PRIMARY KEY (object_id, event_id)
+);
+
+CREATE INDEX idx_stripe_object_events_type ON stripe_object_events(object_id, event_type);
+
+-- 前一個事件尚未到達而暫緩處理的 Stripe 事件，payload 為完整的事件 JSON
+CREATE TABLE parked_events (
+                               event_id VARCHAR(255) PRIMARY KEY,
+                               object_id VARCHAR(255) NOT NULL,
+                               event_type VARCHAR(255) NOT NULL,
+                               waiting_for VARCHAR(255) NOT NULL,
+                               event_created TIMESTAMP WITH TIME ZONE NOT NULL,
+                               payload JSONB NOT NULL,
+                               parked_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_parked_events_object_id ON parked_events(object_id, waiting_for);
+CREATE INDEX idx_parked_events_event_created ON parked_events(event_created);
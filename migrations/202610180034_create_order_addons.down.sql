@@ -0,0 +1,17 @@
+DELETE FROM refund_items WHERE order_addon_id IS NOT NULL;
+
+ALTER TABLE refund_items
+    DROP CONSTRAINT IF EXISTS refund_items_line_check,
+    DROP COLUMN IF EXISTS order_addon_id,
+    ALTER COLUMN order_item_id SET NOT NULL;
+
+DROP INDEX IF EXISTS idx_order_addons_archive_order_id;
+
+DROP TABLE IF EXISTS order_addons_archive;
+
+DROP INDEX IF EXISTS idx_order_addons_unique;
+DROP INDEX IF EXISTS idx_order_addons_order_id;
+
+DROP TABLE IF EXISTS order_addons;
+
+DROP TYPE IF EXISTS order_addon_type;
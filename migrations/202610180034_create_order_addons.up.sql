@@ -0,0 +1,43 @@
+-- 訂單加購服務（禮品包裝、組裝、延長保固）
+CREATE TYPE order_addon_type AS ENUM ('gift_wrap', 'assembly', 'extended_warranty');
+
+-- 加購服務不佔用庫存，以自身的價格計入訂單金額；order_item_id 為空表示整筆訂單的服務
+CREATE TABLE order_addons (
+                              id SERIAL PRIMARY KEY,
+                              order_id INTEGER NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+                              order_item_id INTEGER REFERENCES order_items(id) ON DELETE CASCADE,
+                              type order_addon_type NOT NULL,
+                              description TEXT,
+                              quantity INTEGER NOT NULL CHECK (quantity > 0),
+                              unit_price DECIMAL(10, 2) NOT NULL CHECK (unit_price >= 0),
+                              subtotal DECIMAL(10, 2) NOT NULL,
+                              tax_rate DECIMAL(6, 4) NOT NULL DEFAULT 0,
+                              tax_amount DECIMAL(10, 2) NOT NULL DEFAULT 0,
+                              created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_order_addons_order_id ON order_addons(order_id);
+-- 同一個訂單項目（或整筆訂單）每種服務只能加購一次
+CREATE UNIQUE INDEX idx_order_addons_unique ON order_addons(order_id, COALESCE(order_item_id, 0), type);
+
+CREATE TABLE order_addons_archive (
+                                      id INTEGER PRIMARY KEY,
+                                      order_id INTEGER NOT NULL REFERENCES orders_archive(id) ON DELETE CASCADE,
+                                      order_item_id INTEGER,
+                                      type order_addon_type NOT NULL,
+                                      description TEXT,
+                                      quantity INTEGER NOT NULL,
+                                      unit_price DECIMAL(10, 2) NOT NULL,
+                                      subtotal DECIMAL(10, 2) NOT NULL,
+                                      tax_rate DECIMAL(6, 4) NOT NULL,
+                                      tax_amount DECIMAL(10, 2) NOT NULL,
+                                      created_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX idx_order_addons_archive_order_id ON order_addons_archive(order_id);
+
+-- 退款項目可以是訂單項目或加購服務，兩者擇一
+ALTER TABLE refund_items
+    ALTER COLUMN order_item_id DROP NOT NULL,
+    ADD COLUMN order_addon_id INTEGER UNIQUE,
+    ADD CONSTRAINT refund_items_line_check CHECK ((order_item_id IS NULL) <> (order_addon_id IS NULL));
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS product_media;
+
+DROP TYPE IF EXISTS product_media_type;
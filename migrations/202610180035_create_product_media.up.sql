@@ -0,0 +1,20 @@
+-- 商品的圖片與影片，檔案本身存放在物件儲存（S3、GCS 等），這裡只保存 metadata
+CREATE TYPE product_media_type AS ENUM ('image', 'video');
+
+-- price_id 為空表示商品共用的媒體，有值時只屬於該價格對應的變體；
+-- storage_key 為物件儲存中的路徑，外部網址的媒體沒有 storage_key，刪除時不會刪除檔案
+CREATE TABLE product_media (
+                               id SERIAL PRIMARY KEY,
+                               product_id VARCHAR(255) NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+                               price_id VARCHAR(255) REFERENCES prices(id) ON DELETE CASCADE,
+                               type product_media_type NOT NULL,
+                               url TEXT NOT NULL,
+                               storage_key TEXT UNIQUE,
+                               alt_text TEXT,
+                               position INTEGER NOT NULL DEFAULT 0 CHECK (position >= 0),
+                               content_type VARCHAR(255),
+                               created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                               updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_product_media_product_id_position ON product_media(product_id, position);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS invoices;
+
+DROP TABLE IF EXISTS invoice_sequences;
+
+DROP TYPE IF EXISTS invoice_type;
@@ -0,0 +1,40 @@
+-- 發票與折讓單（退款時開立），兩者各自使用獨立的連續編號
+CREATE TYPE invoice_type AS ENUM ('invoice', 'credit_note');
+
+-- 每個租戶、國家與單據種類各一組連續編號，next_number 在開立單據的交易中遞增，
+-- 交易失敗時一併回復，因此編號不會跳號
+CREATE TABLE invoice_sequences (
+                                   tenant VARCHAR(255) NOT NULL DEFAULT '',
+                                   country VARCHAR(2) NOT NULL,
+                                   type invoice_type NOT NULL,
+                                   next_number BIGINT NOT NULL DEFAULT 1 CHECK (next_number > 0),
+                                   updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                                   PRIMARY KEY (tenant, country, type)
+);
+
+-- 單據需在訂單封存後保留，因此不受 orders 外鍵約束；折讓單以 refund_id 與 original_invoice_id 對應退款與原發票
+CREATE TABLE invoices (
+                          id SERIAL PRIMARY KEY,
+                          tenant VARCHAR(255) NOT NULL DEFAULT '',
+                          country VARCHAR(2) NOT NULL,
+                          type invoice_type NOT NULL,
+                          sequence_number BIGINT NOT NULL CHECK (sequence_number > 0),
+                          invoice_number VARCHAR(64) NOT NULL,
+                          order_id INTEGER NOT NULL,
+                          refund_id INTEGER UNIQUE REFERENCES refunds(id),
+                          original_invoice_id INTEGER REFERENCES invoices(id),
+                          currency currency NOT NULL,
+                          subtotal DECIMAL(10, 2) NOT NULL,
+                          tax DECIMAL(10, 2) NOT NULL,
+                          total DECIMAL(10, 2) NOT NULL,
+                          document_url TEXT,
+                          issued_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                          UNIQUE (tenant, country, type, sequence_number),
+                          UNIQUE (tenant, invoice_number),
+                          CHECK ((type = 'invoice') = (refund_id IS NULL)),
+                          CHECK ((type = 'invoice') = (original_invoice_id IS NULL))
+);
+
+CREATE INDEX idx_invoices_order_id ON invoices(order_id);
+-- 每筆訂單只開立一張發票
+CREATE UNIQUE INDEX idx_invoices_order_invoice ON invoices(order_id) WHERE type = 'invoice';
@@ -0,0 +1,8 @@
+ALTER TABLE order_items_archive
+    DROP COLUMN IF EXISTS customization;
+
+ALTER TABLE order_items
+    DROP COLUMN IF EXISTS customization;
+
+ALTER TABLE cart_items
+    DROP COLUMN IF EXISTS customization;
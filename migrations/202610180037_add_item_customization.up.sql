@@ -0,0 +1,9 @@
+-- 購物車與訂單項目的客製化內容（例如刻字、組態），依商品的客製化 schema 驗證後寫入；未客製化時為 NULL
+ALTER TABLE cart_items
+    ADD COLUMN customization JSONB CHECK (jsonb_typeof(customization) = 'object');
+
+ALTER TABLE order_items
+    ADD COLUMN customization JSONB CHECK (jsonb_typeof(customization) = 'object');
+
+ALTER TABLE order_items_archive
+    ADD COLUMN customization JSONB;
@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS idx_orders_archive_external_id;
+
+ALTER TABLE orders_archive
+    DROP COLUMN IF EXISTS external_order_id,
+    DROP COLUMN IF EXISTS external_source;
+
+DROP INDEX IF EXISTS idx_orders_external_id;
+
+ALTER TABLE orders
+    DROP CONSTRAINT IF EXISTS orders_external_id_check,
+    DROP COLUMN IF EXISTS external_order_id,
+    DROP COLUMN IF EXISTS external_source;
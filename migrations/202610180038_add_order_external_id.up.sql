@@ -0,0 +1,13 @@
+-- 外部通路（例如 Amazon、eBay 等電商平台）的訂單編號，匯入的訂單以通路與編號識別，避免重複匯入
+ALTER TABLE orders
+    ADD COLUMN external_source VARCHAR(64),
+    ADD COLUMN external_order_id VARCHAR(255),
+    ADD CONSTRAINT orders_external_id_check CHECK ((external_source IS NULL) = (external_order_id IS NULL));
+
+CREATE UNIQUE INDEX idx_orders_external_id ON orders(external_source, external_order_id) WHERE external_source IS NOT NULL;
+
+ALTER TABLE orders_archive
+    ADD COLUMN external_source VARCHAR(64),
+    ADD COLUMN external_order_id VARCHAR(255);
+
+CREATE UNIQUE INDEX idx_orders_archive_external_id ON orders_archive(external_source, external_order_id) WHERE external_source IS NOT NULL;
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_order_returns_tracking;
+DROP INDEX IF EXISTS idx_order_returns_open;
+DROP INDEX IF EXISTS idx_order_returns_order_id;
+
+DROP TABLE IF EXISTS order_returns;
+
+DROP TYPE IF EXISTS return_status;
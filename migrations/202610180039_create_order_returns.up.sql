@@ -0,0 +1,22 @@
+-- 退貨申請的狀態：核准後向物流商取得退貨標籤（label_issued），之後依物流追蹤更新為運送中與已送達
+CREATE TYPE return_status AS ENUM ('requested', 'approved', 'label_issued', 'in_transit', 'received', 'rejected');
+
+-- 退貨需在訂單封存後保留，因此不受 orders 外鍵約束；carrier、tracking_number 與 label_url 在取得標籤後寫入
+CREATE TABLE order_returns (
+                               id SERIAL PRIMARY KEY,
+                               order_id INTEGER NOT NULL,
+                               status return_status NOT NULL DEFAULT 'requested',
+                               reason TEXT NOT NULL,
+                               carrier VARCHAR(64),
+                               tracking_number VARCHAR(255),
+                               label_url TEXT,
+                               created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                               updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                               CHECK ((carrier IS NULL) = (tracking_number IS NULL))
+);
+
+CREATE INDEX idx_order_returns_order_id ON order_returns(order_id);
+-- 每筆訂單同時最多一筆處理中的退貨
+CREATE UNIQUE INDEX idx_order_returns_open ON order_returns(order_id) WHERE status NOT IN ('received', 'rejected');
+-- 依物流追蹤編號對應退貨
+CREATE UNIQUE INDEX idx_order_returns_tracking ON order_returns(carrier, tracking_number) WHERE tracking_number IS NOT NULL;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_stock_movements_reference_item;
+
+ALTER TABLE stock_movements
+    DROP COLUMN IF EXISTS reference_item_id;
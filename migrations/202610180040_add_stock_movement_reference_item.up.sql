@@ -0,0 +1,6 @@
+-- 庫存變動對應的購物車項目或訂單項目，依 reference_type 決定指向 cart_items 或 order_items，
+-- 用於逐項核對預留與扣除的數量；項目會被刪除或封存，因此不設外鍵
+ALTER TABLE stock_movements
+    ADD COLUMN reference_item_id INTEGER;
+
+CREATE INDEX idx_stock_movements_reference_item ON stock_movements(reference_type, reference_item_id) WHERE reference_item_id IS NOT NULL;
@@ -0,0 +1,3 @@
+ALTER TABLE orders_archive DROP COLUMN IF EXISTS applied_promotions;
+ALTER TABLE orders DROP COLUMN IF EXISTS applied_promotions;
+ALTER TABLE carts DROP COLUMN IF EXISTS applied_promotions;
@@ -0,0 +1,4 @@
+-- 依疊加規則實際套用的優惠（活動、優惠券、數量折扣），購物車每次重新計算金額時更新，結帳時帶入訂單
+ALTER TABLE carts ADD COLUMN applied_promotions JSONB NOT NULL DEFAULT '[]'::jsonb;
+ALTER TABLE orders ADD COLUMN applied_promotions JSONB NOT NULL DEFAULT '[]'::jsonb;
+ALTER TABLE orders_archive ADD COLUMN applied_promotions JSONB NOT NULL DEFAULT '[]'::jsonb;
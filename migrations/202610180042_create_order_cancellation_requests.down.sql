@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_order_cancellation_requests_pending;
+DROP INDEX IF EXISTS idx_order_cancellation_requests_created_at;
+DROP INDEX IF EXISTS idx_order_cancellation_requests_order_id;
+
+DROP TABLE IF EXISTS order_cancellation_requests;
+
+DROP TYPE IF EXISTS cancellation_request_status;
+DROP TYPE IF EXISTS cancellation_reason;
@@ -0,0 +1,21 @@
+-- 客戶自助取消訂單時選擇的原因，供分析取消原因使用
+CREATE TYPE cancellation_reason AS ENUM ('changed_mind', 'ordered_by_mistake', 'found_better_price', 'delivery_too_slow', 'payment_issue', 'other');
+-- 取消申請的結果：符合條件時直接取消（auto_cancelled），否則等待人員審核
+CREATE TYPE cancellation_request_status AS ENUM ('auto_cancelled', 'pending_review', 'approved', 'rejected');
+
+-- 取消申請需在訂單封存後保留供分析，因此不受 orders 外鍵約束；reviewed_by 在人員審核後寫入
+CREATE TABLE order_cancellation_requests (
+                                             id SERIAL PRIMARY KEY,
+                                             order_id INTEGER NOT NULL,
+                                             customer_id VARCHAR(255) NOT NULL,
+                                             reason cancellation_reason NOT NULL,
+                                             status cancellation_request_status NOT NULL,
+                                             reviewed_by VARCHAR(255),
+                                             created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                                             updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_order_cancellation_requests_order_id ON order_cancellation_requests(order_id);
+CREATE INDEX idx_order_cancellation_requests_created_at ON order_cancellation_requests(created_at);
+-- 每筆訂單同時最多一筆等待審核的取消申請
+CREATE UNIQUE INDEX idx_order_cancellation_requests_pending ON order_cancellation_requests(order_id) WHERE status = 'pending_review';
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_order_repricings_pending;
+DROP INDEX IF EXISTS idx_order_repricings_order_price_change;
+
+DROP TABLE IF EXISTS order_repricings;
+
+DROP TYPE IF EXISTS order_repricing_status;
@@ -0,0 +1,20 @@
+-- 待付款訂單因降價重新計價的結果：applied 為已套用新價格，pending_review 為等待人員審核，dismissed 為人員決定不調整
+CREATE TYPE order_repricing_status AS ENUM ('applied', 'pending_review', 'dismissed');
+
+-- 每筆價格變動對同一筆訂單最多重新計價一次；previous_total 與 new_total 為重新計價前後的訂單總額
+CREATE TABLE order_repricings (
+                                  id SERIAL PRIMARY KEY,
+                                  order_id INTEGER NOT NULL,
+                                  price_change_id INTEGER NOT NULL,
+                                  price_id VARCHAR(255) NOT NULL,
+                                  new_unit_price DECIMAL(10, 2) NOT NULL,
+                                  previous_total DECIMAL(10, 2) NOT NULL,
+                                  new_total DECIMAL(10, 2) NOT NULL,
+                                  status order_repricing_status NOT NULL,
+                                  reviewed_by VARCHAR(255),
+                                  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                                  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_order_repricings_order_price_change ON order_repricings(order_id, price_change_id);
+CREATE INDEX idx_order_repricings_pending ON order_repricings(created_at) WHERE status = 'pending_review';
@@ -0,0 +1,12 @@
+-- 客戶的通知偏好：各通知管道是否啟用，以及退訂行銷或交易通知的時間（NULL 表示未退訂）；
+-- 沒有記錄的客戶使用預設偏好
+CREATE TABLE customer_notification_preferences (
+                                                   customer_id VARCHAR(255) PRIMARY KEY,
+                                                   email_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+                                                   sms_enabled BOOLEAN NOT NULL DEFAULT FALSE,
+                                                   push_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+                                                   marketing_opted_out_at TIMESTAMP WITH TIME ZONE,
+                                                   transactional_opted_out_at TIMESTAMP WITH TIME ZONE,
+                                                   created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                                                   updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_order_fulfillment_slas_open;
+
+DROP TABLE IF EXISTS order_fulfillment_slas;
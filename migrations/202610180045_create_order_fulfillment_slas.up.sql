@@ -0,0 +1,15 @@
+-- 訂單付款後到出貨（門市自取為備妥）的履約時限：warn_at 起列為有違反風險，due_at 為期限；
+-- 各警示送出後記錄時間避免重複通知。記錄需在訂單封存後保留供分析，因此不受 orders 外鍵約束
+CREATE TABLE order_fulfillment_slas (
+                                        order_id INTEGER PRIMARY KEY,
+                                        paid_at TIMESTAMP WITH TIME ZONE NOT NULL,
+                                        warn_at TIMESTAMP WITH TIME ZONE NOT NULL,
+                                        due_at TIMESTAMP WITH TIME ZONE NOT NULL,
+                                        fulfilled_at TIMESTAMP WITH TIME ZONE,
+                                        at_risk_alerted_at TIMESTAMP WITH TIME ZONE,
+                                        breach_alerted_at TIMESTAMP WITH TIME ZONE,
+                                        created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- 只需要掃描尚未履約的訂單
+CREATE INDEX idx_order_fulfillment_slas_open ON order_fulfillment_slas(warn_at) WHERE fulfilled_at IS NULL;
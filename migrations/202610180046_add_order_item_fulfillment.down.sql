@@ -0,0 +1,9 @@
+ALTER TABLE order_items_archive
+    DROP COLUMN IF EXISTS returned_quantity,
+    DROP COLUMN IF EXISTS shipped_quantity;
+
+ALTER TABLE order_items
+    DROP CONSTRAINT IF EXISTS order_items_returned_quantity_check,
+    DROP CONSTRAINT IF EXISTS order_items_shipped_quantity_check,
+    DROP COLUMN IF EXISTS returned_quantity,
+    DROP COLUMN IF EXISTS shipped_quantity;
@@ -0,0 +1,24 @@
+-- 每個訂單項目的出貨與退貨數量，分批出貨與部分退貨時可以看出各項目的履約狀態
+ALTER TABLE order_items
+    ADD COLUMN shipped_quantity INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN returned_quantity INTEGER NOT NULL DEFAULT 0,
+    ADD CONSTRAINT order_items_shipped_quantity_check CHECK (shipped_quantity >= 0 AND shipped_quantity <= quantity),
+    ADD CONSTRAINT order_items_returned_quantity_check CHECK (returned_quantity >= 0 AND returned_quantity <= shipped_quantity);
+
+-- 既有訂單：已完成的訂單或已從項目的地點出貨的項目視為已出貨，退貨已送達倉庫的訂單視為全部退回
+UPDATE order_items oi
+SET shipped_quantity = oi.quantity
+FROM orders o
+WHERE o.id = oi.order_id
+  AND (o.status = 'completed' OR EXISTS (SELECT 1 FROM shipments s WHERE s.order_id = oi.order_id AND s.location = oi.location));
+UPDATE order_items oi
+SET returned_quantity = oi.shipped_quantity
+WHERE EXISTS (SELECT 1 FROM order_returns r WHERE r.order_id = oi.order_id AND r.status = 'received');
+
+ALTER TABLE order_items_archive
+    ADD COLUMN shipped_quantity INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN returned_quantity INTEGER NOT NULL DEFAULT 0;
+UPDATE order_items_archive oi
+SET shipped_quantity = oi.quantity
+FROM orders_archive o
+WHERE o.id = oi.order_id AND o.status = 'completed';
@@ -0,0 +1,4 @@
+-- 軟刪除的訂單保留給會計報表使用，不再出現在客戶的訂單列表，超過保留期限後才真正刪除
+ALTER TABLE orders ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_orders_deleted_at ON orders(deleted_at) WHERE deleted_at IS NOT NULL;
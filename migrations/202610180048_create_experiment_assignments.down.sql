@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_experiment_assignments_experiment;
+DROP INDEX IF EXISTS idx_experiment_assignments_order_id;
+
+DROP TABLE IF EXISTS experiment_assignments;
@@ -0,0 +1,13 @@
+-- 購物車被分配到的實驗組別，結帳時記錄成立的訂單供事後分析；實驗本身由外部的 ExperimentAssigner 決定，
+-- 記錄需在購物車清除與訂單封存後保留，因此不受 carts 與 orders 外鍵約束
+CREATE TABLE experiment_assignments (
+                                        cart_id INTEGER NOT NULL,
+                                        experiment VARCHAR(100) NOT NULL,
+                                        variant VARCHAR(100) NOT NULL,
+                                        order_id INTEGER,
+                                        assigned_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                                        PRIMARY KEY (cart_id, experiment)
+);
+
+CREATE INDEX idx_experiment_assignments_order_id ON experiment_assignments(order_id) WHERE order_id IS NOT NULL;
+CREATE INDEX idx_experiment_assignments_experiment ON experiment_assignments(experiment, variant);
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_coupon_redemptions_coupon_id;
+DROP INDEX IF EXISTS idx_cart_coupons_coupon_id;
+
+DROP TABLE IF EXISTS coupon_redemptions;
+DROP TABLE IF EXISTS cart_coupons;
+DROP TABLE IF EXISTS coupons;
+
+DROP TYPE IF EXISTS coupon_discount_type;
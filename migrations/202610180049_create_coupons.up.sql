@@ -0,0 +1,41 @@
+CREATE TYPE coupon_discount_type AS ENUM ('percentage', 'fixed');
+
+-- 優惠券定義：percentage 以 amount 為折扣百分比，fixed 以 amount 為 currency 幣別的折抵金額；
+-- starts_at 與 expires_at 為 NULL 表示不限，usage_limit 為可兌換的總次數（NULL 表示不限），redeemed_count 於結帳成立訂單時累加
+CREATE TABLE coupons (
+                         id SERIAL PRIMARY KEY,
+                         code VARCHAR(64) NOT NULL UNIQUE,
+                         name VARCHAR(255) NOT NULL DEFAULT '',
+                         discount_type coupon_discount_type NOT NULL,
+                         amount DECIMAL(10, 2) NOT NULL CHECK (amount > 0),
+                         currency currency,
+                         starts_at TIMESTAMP WITH TIME ZONE,
+                         expires_at TIMESTAMP WITH TIME ZONE,
+                         usage_limit INTEGER CHECK (usage_limit > 0),
+                         redeemed_count INTEGER NOT NULL DEFAULT 0,
+                         created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                         updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                         CHECK (discount_type <> 'percentage' OR amount <= 100),
+                         CHECK (discount_type <> 'fixed' OR currency IS NOT NULL),
+                         CHECK (expires_at IS NULL OR starts_at IS NULL OR expires_at > starts_at)
+);
+
+-- 購物車套用的優惠券，每個購物車最多一張
+CREATE TABLE cart_coupons (
+                              cart_id INTEGER PRIMARY KEY REFERENCES carts(id) ON DELETE CASCADE,
+                              coupon_id INTEGER NOT NULL REFERENCES coupons(id) ON DELETE CASCADE,
+                              applied_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- 訂單兌換優惠券的記錄，discount 為結帳時實際折抵的金額
+CREATE TABLE coupon_redemptions (
+                                    id SERIAL PRIMARY KEY,
+                                    coupon_id INTEGER NOT NULL REFERENCES coupons(id) ON DELETE CASCADE,
+                                    order_id INTEGER NOT NULL UNIQUE REFERENCES orders(id) ON DELETE CASCADE,
+                                    customer_id VARCHAR(255) NOT NULL,
+                                    discount DECIMAL(10, 2) NOT NULL,
+                                    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_cart_coupons_coupon_id ON cart_coupons(coupon_id);
+CREATE INDEX idx_coupon_redemptions_coupon_id ON coupon_redemptions(coupon_id);
@@ -0,0 +1,2 @@
+-- 到期掃描只需要 active 的購物車
+CREATE INDEX idx_carts_active_expires_at ON carts(expires_at) WHERE status = 'active';
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_carts_active_updated_at;
+ALTER TABLE carts DROP COLUMN IF EXISTS abandoned_notified_at;
@@ -0,0 +1,4 @@
+-- 閒置購物車通知後記錄時間，購物車再次異動前不重複通知
+ALTER TABLE carts ADD COLUMN abandoned_notified_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_carts_active_updated_at ON carts(updated_at) WHERE status = 'active';
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_coupons_recovery_cart_id;
+ALTER TABLE coupons
+    DROP COLUMN IF EXISTS revoked_at,
+    DROP COLUMN IF EXISTS recovery_cart_id,
+    DROP COLUMN IF EXISTS customer_id;
@@ -0,0 +1,8 @@
+-- 閒置購物車的挽回優惠券只限 customer_id 的客戶用於 recovery_cart_id 購物車；
+-- revoked_at 為購物車未使用優惠券即結帳而作廢的時間
+ALTER TABLE coupons
+    ADD COLUMN customer_id VARCHAR(255),
+    ADD COLUMN recovery_cart_id INTEGER REFERENCES carts(id) ON DELETE CASCADE,
+    ADD COLUMN revoked_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_coupons_recovery_cart_id ON coupons(recovery_cart_id) WHERE recovery_cart_id IS NOT NULL;
@@ -0,0 +1,14 @@
+-- 商品在所有地點加總的庫存，供商品列表與搜尋頁顯示庫存狀態，避免逐筆查詢 stocks；
+-- 由排程或手動以 REFRESH MATERIALIZED VIEW CONCURRENTLY 更新，refreshed_at 為最後一次更新的時間
+CREATE MATERIALIZED VIEW product_availability AS
+SELECT product_id,
+       SUM(quantity)::INTEGER AS quantity,
+       SUM(reserved_quantity)::INTEGER AS reserved_quantity,
+       GREATEST(SUM(quantity - reserved_quantity), 0)::INTEGER AS available_quantity,
+       COUNT(*)::INTEGER AS location_count,
+       NOW()::TIMESTAMP WITH TIME ZONE AS refreshed_at
+FROM stocks
+GROUP BY product_id;
+
+-- CONCURRENTLY 更新需要唯一索引
+CREATE UNIQUE INDEX idx_product_availability_product_id ON product_availability(product_id);
@@ -0,0 +1,19 @@
+-- 客戶「稍後購買」清單，不預留庫存；移回購物車時才重新檢查庫存並預留。
+-- 沒有客製化的相同商品（同一庫存與價格）合併為一筆，客製化的商品各自獨立
+CREATE TABLE saved_items (
+    id SERIAL PRIMARY KEY,
+    customer_id VARCHAR(255) NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    product_id VARCHAR(255) NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    price_id VARCHAR(255) NOT NULL REFERENCES prices(id) ON DELETE CASCADE,
+    stock_id INTEGER NOT NULL REFERENCES stocks(id) ON DELETE CASCADE,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    unit_price DECIMAL(10, 2) NOT NULL,
+    currency currency NOT NULL,
+    location VARCHAR(255),
+    customization JSONB,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_saved_items_customer_id ON saved_items(customer_id, created_at);
+CREATE UNIQUE INDEX idx_saved_items_customer_stock_price ON saved_items(customer_id, stock_id, price_id) WHERE customization IS NULL;
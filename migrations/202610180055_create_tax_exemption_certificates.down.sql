@@ -0,0 +1,3 @@
+ALTER TABLE invoices DROP COLUMN IF EXISTS tax_exemption_number;
+DROP TABLE IF EXISTS order_tax_exemptions;
+DROP TABLE IF EXISTS tax_exemption_certificates;
@@ -0,0 +1,30 @@
+-- 客戶的免稅證明，jurisdiction 為適用的國家代碼（ISO 3166-1 alpha-2）；tax_classes 為適用的稅別，空陣列表示所有稅別。
+-- valid_until 為空表示沒有到期日，revoked_at 為證明被撤銷的時間
+CREATE TABLE tax_exemption_certificates (
+    id SERIAL PRIMARY KEY,
+    customer_id VARCHAR(255) NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    certificate_number VARCHAR(255) NOT NULL,
+    jurisdiction VARCHAR(2) NOT NULL,
+    tax_classes TEXT[] NOT NULL DEFAULT '{}',
+    valid_from TIMESTAMP WITH TIME ZONE NOT NULL,
+    valid_until TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (customer_id, jurisdiction, certificate_number),
+    CHECK (valid_until IS NULL OR valid_until > valid_from)
+);
+
+CREATE INDEX idx_tax_exemption_certificates_active ON tax_exemption_certificates(customer_id, jurisdiction, valid_from) WHERE revoked_at IS NULL;
+
+-- 訂單下單時套用的免稅證明，certificate_number 為當時的證明編號，開立發票時帶入；
+-- 記錄需在訂單封存後保留，因此不受 orders 外鍵約束
+CREATE TABLE order_tax_exemptions (
+    order_id INTEGER PRIMARY KEY,
+    certificate_id INTEGER NOT NULL REFERENCES tax_exemption_certificates(id),
+    certificate_number VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- 發票上註記的免稅證明編號
+ALTER TABLE invoices ADD COLUMN tax_exemption_number VARCHAR(255);
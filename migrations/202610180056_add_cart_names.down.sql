@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_carts_active_customer_default;
+DROP INDEX IF EXISTS idx_carts_active_customer_name;
+ALTER TABLE carts
+    DROP COLUMN IF EXISTS is_default,
+    DROP COLUMN IF EXISTS name;
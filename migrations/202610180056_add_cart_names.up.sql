@@ -0,0 +1,22 @@
+-- 客戶可同時有多個以名稱區分的 active 購物車（如 "work"、"personal"），is_default 標記未指定購物車時使用的預設購物車；
+-- 同一客戶的 active 購物車名稱不重複，且最多一個預設購物車
+ALTER TABLE carts
+    ADD COLUMN name VARCHAR(100) NOT NULL DEFAULT 'default',
+    ADD COLUMN is_default BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- 既有的 active 購物車中，每位客戶最近更新的一個成為預設購物車，其餘以 ID 命名避免名稱重複
+UPDATE carts
+SET is_default = TRUE
+WHERE id IN (
+    SELECT DISTINCT ON (customer_id) id
+    FROM carts
+    WHERE status = 'active'
+    ORDER BY customer_id, updated_at DESC, id DESC
+);
+
+UPDATE carts
+SET name = 'cart-' || id
+WHERE status = 'active' AND NOT is_default;
+
+CREATE UNIQUE INDEX idx_carts_active_customer_name ON carts(customer_id, name) WHERE status = 'active';
+CREATE UNIQUE INDEX idx_carts_active_customer_default ON carts(customer_id) WHERE status = 'active' AND is_default;
@@ -0,0 +1,11 @@
+ALTER TABLE order_items_archive
+    DROP COLUMN IF EXISTS metadata,
+    DROP COLUMN IF EXISTS note;
+
+ALTER TABLE order_items
+    DROP COLUMN IF EXISTS metadata,
+    DROP COLUMN IF EXISTS note;
+
+ALTER TABLE cart_items
+    DROP COLUMN IF EXISTS metadata,
+    DROP COLUMN IF EXISTS note;
@@ -0,0 +1,12 @@
+-- 購物車與訂單項目的備註與自訂屬性（例如刻字文字、尺寸選擇），metadata 為字串鍵值；未填寫時為 NULL
+ALTER TABLE cart_items
+    ADD COLUMN note TEXT,
+    ADD COLUMN metadata JSONB CHECK (jsonb_typeof(metadata) = 'object');
+
+ALTER TABLE order_items
+    ADD COLUMN note TEXT,
+    ADD COLUMN metadata JSONB CHECK (jsonb_typeof(metadata) = 'object');
+
+ALTER TABLE order_items_archive
+    ADD COLUMN note TEXT,
+    ADD COLUMN metadata JSONB;
@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_stocktake_scans_serial;
+DROP INDEX IF EXISTS idx_stocktake_scans_stock;
+DROP INDEX IF EXISTS idx_stocktakes_open_location;
+DROP INDEX IF EXISTS idx_product_barcodes_product_id;
+
+DROP TABLE IF EXISTS stocktake_scans;
+DROP TABLE IF EXISTS stocktakes;
+DROP TABLE IF EXISTS product_barcodes;
+
+DROP TYPE IF EXISTS stocktake_status;
@@ -0,0 +1,41 @@
+CREATE TYPE stocktake_status AS ENUM ('open', 'completed', 'cancelled');
+
+-- 商品條碼，盤點時掃描器回傳的條碼經由此表對應到商品
+CREATE TABLE product_barcodes (
+                                  barcode VARCHAR(128) PRIMARY KEY,
+                                  product_id VARCHAR(255) NOT NULL,
+                                  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_product_barcodes_product_id ON product_barcodes(product_id);
+
+-- 盤點單，同一個地點同時只能有一張進行中的盤點，完成時依盤點數量建立庫存調整
+CREATE TABLE stocktakes (
+                            id SERIAL PRIMARY KEY,
+                            location VARCHAR(255) NOT NULL,
+                            status stocktake_status NOT NULL DEFAULT 'open',
+                            started_by VARCHAR(255) NOT NULL,
+                            finished_by VARCHAR(255),
+                            created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                            finished_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE UNIQUE INDEX idx_stocktakes_open_location ON stocktakes(location) WHERE status = 'open';
+
+-- 盤點的掃描記錄，批次 ID 與批次內的位置用來忽略重送的批次，序號用來忽略重複掃描的同一件商品
+CREATE TABLE stocktake_scans (
+                                 id SERIAL PRIMARY KEY,
+                                 stocktake_id INTEGER NOT NULL REFERENCES stocktakes(id) ON DELETE CASCADE,
+                                 batch_id VARCHAR(64) NOT NULL,
+                                 position INTEGER NOT NULL,
+                                 barcode VARCHAR(128) NOT NULL,
+                                 stock_id INTEGER NOT NULL REFERENCES stocks(id) ON DELETE CASCADE,
+                                 serial_number VARCHAR(255),
+                                 quantity INTEGER NOT NULL CHECK (quantity > 0),
+                                 scanned_at TIMESTAMP WITH TIME ZONE NOT NULL,
+                                 created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                                 UNIQUE (stocktake_id, batch_id, position)
+);
+
+CREATE INDEX idx_stocktake_scans_stock ON stocktake_scans(stocktake_id, stock_id);
+CREATE UNIQUE INDEX idx_stocktake_scans_serial ON stocktake_scans(stocktake_id, stock_id, serial_number) WHERE serial_number IS NOT NULL;
@@ -0,0 +1,8 @@
+ALTER TABLE orders_archive DROP COLUMN IF EXISTS channel;
+
+ALTER TABLE orders DROP COLUMN IF EXISTS channel;
+
+ALTER TABLE carts DROP COLUMN IF EXISTS channel;
+
+DROP TABLE IF EXISTS channel_prices;
+DROP TABLE IF EXISTS channel_products;
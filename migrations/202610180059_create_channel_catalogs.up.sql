@@ -0,0 +1,27 @@
+-- 銷售通路（例如門市 POS、批發、合作商城）的商品目錄，設定通路的購物車只能購買已上架且可供貨的商品
+CREATE TABLE channel_products (
+                                  channel VARCHAR(64) NOT NULL,
+                                  product_id VARCHAR(255) NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+                                  available BOOLEAN NOT NULL DEFAULT TRUE,
+                                  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                                  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                                  PRIMARY KEY (channel, product_id)
+);
+
+-- 銷售通路的價格表，未列在價格表的價格以原價格計算
+CREATE TABLE channel_prices (
+                                channel VARCHAR(64) NOT NULL,
+                                price_id VARCHAR(255) NOT NULL REFERENCES prices(id) ON DELETE CASCADE,
+                                currency currency NOT NULL,
+                                unit_price DECIMAL(10, 2) NOT NULL CHECK (unit_price >= 0),
+                                created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                                updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                                PRIMARY KEY (channel, price_id, currency)
+);
+
+-- 購物車的銷售通路決定可購買的商品與價格，結帳時記錄到訂單供通路別報表使用
+ALTER TABLE carts ADD COLUMN channel VARCHAR(64);
+
+ALTER TABLE orders ADD COLUMN channel VARCHAR(64);
+
+ALTER TABLE orders_archive ADD COLUMN channel VARCHAR(64);
@@ -0,0 +1,2 @@
+ALTER TABLE orders_archive DROP COLUMN IF EXISTS cart_snapshot;
+ALTER TABLE orders DROP COLUMN IF EXISTS cart_snapshot;
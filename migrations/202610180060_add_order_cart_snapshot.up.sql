@@ -0,0 +1,4 @@
+-- 結帳當下購物車的快照（項目、價格與套用的優惠），寫入後不再變更；非由購物車建立的訂單為 NULL
+ALTER TABLE orders ADD COLUMN cart_snapshot JSONB;
+
+ALTER TABLE orders_archive ADD COLUMN cart_snapshot JSONB;
@@ -0,0 +1,11 @@
+-- 訂單付款在 Stripe 上的收據與發票網址，記錄產生時對應的 payment intent 與 invoice；
+-- 不參照 orders，訂單封存後仍保留連結
+CREATE TABLE order_receipt_links (
+    order_id INTEGER PRIMARY KEY,
+    payment_intent_id VARCHAR(255),
+    invoice_id VARCHAR(255),
+    receipt_url TEXT,
+    invoice_url TEXT,
+    invoice_pdf_url TEXT,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,3 @@
+DELETE FROM coupon_redemptions cr WHERE NOT EXISTS (SELECT 1 FROM orders o WHERE o.id = cr.order_id);
+ALTER TABLE coupon_redemptions
+    ADD CONSTRAINT coupon_redemptions_order_id_fkey FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE;
@@ -0,0 +1,2 @@
+-- 優惠券兌換記錄需在訂單封存後保留，改為不受 orders 外鍵約束
+ALTER TABLE coupon_redemptions DROP CONSTRAINT IF EXISTS coupon_redemptions_order_id_fkey;
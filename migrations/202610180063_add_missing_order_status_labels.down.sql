@@ -0,0 +1 @@
+-- PostgreSQL 無法從 enum 中移除值，failed、paid、refund_pending、refund_failed、awaiting_stock、dispute 會保留在 order_status 中
@@ -0,0 +1,8 @@
+-- models/enum 的 OrderStatus 中有以下狀態，但 order_status 原本沒有這些值，寫入時會失敗
+-- failed：付款失敗的訂單（結帳恢復設定為標記失敗、付款失敗事件）
+ALTER TYPE order_status ADD VALUE IF NOT EXISTS 'failed';
+ALTER TYPE order_status ADD VALUE IF NOT EXISTS 'paid';
+ALTER TYPE order_status ADD VALUE IF NOT EXISTS 'refund_pending';
+ALTER TYPE order_status ADD VALUE IF NOT EXISTS 'refund_failed';
+ALTER TYPE order_status ADD VALUE IF NOT EXISTS 'awaiting_stock';
+ALTER TYPE order_status ADD VALUE IF NOT EXISTS 'dispute';
@@ -0,0 +1,24 @@
+package models
+
+import "gofalre.io/shop/models/enum"
+
+// BulkCancellationResult 為批次取消時單筆訂單的處理結果，Status 為處理前的訂單狀態
+type BulkCancellationResult struct {
+	OrderID     uint64                       `json:"order_id"`
+	OrderNumber string                       `json:"order_number,omitempty"`
+	Status      enum.OrderStatus             `json:"status,omitempty"`
+	Outcome     enum.BulkCancellationOutcome `json:"outcome"`
+	Error       string                       `json:"error,omitempty"`
+}
+
+// BulkCancellationReport 彙整含有某商品之訂單的批次取消結果，Results 依訂單 ID 排序
+type BulkCancellationReport struct {
+	ProductID       string                    `json:"product_id"`
+	Reason          string                    `json:"reason"`
+	Matched         int                       `json:"matched"`
+	Cancelled       int                       `json:"cancelled"`
+	RefundRequested int                       `json:"refund_requested"`
+	Skipped         int                       `json:"skipped"`
+	Failed          int                       `json:"failed"`
+	Results         []*BulkCancellationResult `json:"results"`
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"github.com/stripe/stripe-go/v79"
+	"gofalre.io/shop/sqlc"
+	"time"
+)
+
+// DiscountCampaign 代表分類折扣活動，活動期間內該分類（可包含子分類）的商品自動套用折扣
+type DiscountCampaign struct {
+	ID                   uint64    `json:"id"`
+	Name                 string    `json:"name"`
+	CategoryID           uint64    `json:"category_id"`
+	IncludeSubcategories bool      `json:"include_subcategories"`
+	PercentOff           float64   `json:"percent_off"`
+	StartsAt             time.Time `json:"starts_at"`
+	EndsAt               time.Time `json:"ends_at"`
+	CreatedAt            time.Time `json:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at"`
+}
+
+// ProductCampaign 表示商品目前適用的活動，同一商品符合多個活動時只取折扣最高者
+type ProductCampaign struct {
+	ProductID  string  `json:"product_id"`
+	CampaignID uint64  `json:"campaign_id"`
+	PercentOff float64 `json:"percent_off"`
+}
+
+// CampaignReport 彙整活動的成效，已取消、付款失敗或全額退款的訂單不列入計算；
+// 金額依訂單的匯率快照換算成 Currency，沒有快照的訂單以原幣別金額計入
+type CampaignReport struct {
+	Campaign     *DiscountCampaign `json:"campaign"`
+	Currency     stripe.Currency   `json:"currency,omitempty"`
+	Orders       uint64            `json:"orders"`
+	Units        uint64            `json:"units"`
+	GrossRevenue float64           `json:"gross_revenue"`
+	Discount     float64           `json:"discount"`
+	NetRevenue   float64           `json:"net_revenue"`
+}
+
+func (dc *DiscountCampaign) ConvertSqlcDiscountCampaign(sqlcDiscountCampaign any) *DiscountCampaign {
+
+	switch sp := sqlcDiscountCampaign.(type) {
+	case *sqlc.DiscountCampaign:
+		dc.ID = uint64(sp.ID)
+		dc.Name = sp.Name
+		dc.CategoryID = uint64(sp.CategoryID)
+		dc.IncludeSubcategories = sp.IncludeSubcategories
+		dc.PercentOff = sp.PercentOff
+		dc.StartsAt = sp.StartsAt.Time
+		dc.EndsAt = sp.EndsAt.Time
+		dc.CreatedAt = sp.CreatedAt.Time
+		dc.UpdatedAt = sp.UpdatedAt.Time
+	default:
+		return nil
+	}
+
+	return dc
+}
+
+// ActiveAt 判斷活動在指定時間是否有效
+func (dc *DiscountCampaign) ActiveAt(at time.Time) bool {
+	return !at.Before(dc.StartsAt) && at.Before(dc.EndsAt)
+}
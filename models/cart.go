@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/stripe/stripe-go/v79"
@@ -12,6 +13,8 @@ import (
 type Cart struct {
 	ID         uint64          `json:"id"`
 	CustomerID string          `json:"customer_id"`
+	Name       string          `json:"name"`
+	IsDefault  bool            `json:"is_default"`
 	Status     enum.CartStatus `json:"status"`
 	Currency   stripe.Currency `json:"currency"`
 	Subtotal   float64         `json:"subtotal"`
@@ -22,6 +25,19 @@ type Cart struct {
 	CreatedAt  time.Time       `json:"created_at"`
 	UpdatedAt  time.Time       `json:"updated_at"`
 	ExpiresAt  time.Time       `json:"expires_at"`
+	// ShippingAddress、BillingAddress 為結帳前填寫的地址原始 JSON，未填寫時為 nil，轉換為訂單時帶入訂單
+	ShippingAddress json.RawMessage `json:"shipping_address,omitempty"`
+	BillingAddress  json.RawMessage `json:"billing_address,omitempty"`
+	// AppliedPromotions 為最近一次計算金額時依疊加規則套用的優惠
+	AppliedPromotions []*AppliedPromotion `json:"applied_promotions,omitempty"`
+	// Channel 為購物車的銷售通路，決定可購買的商品與價格；空字串表示不限通路
+	Channel string `json:"channel,omitempty"`
+}
+
+// CartAddresses 為購物車上填寫的寄送與帳單地址原始 JSON，未填寫時為 nil
+type CartAddresses struct {
+	ShippingAddress json.RawMessage `json:"shipping_address,omitempty"`
+	BillingAddress  json.RawMessage `json:"billing_address,omitempty"`
 }
 
 // CartItem 代表購物車中的單個商品項目
@@ -34,19 +50,31 @@ type CartItem struct {
 	Quantity  uint64  `json:"quantity"`
 	UnitPrice float64 `json:"unit_price"`
 	Subtotal  float64 `json:"subtotal"`
+	TaxRate   float64 `json:"tax_rate"`
+	TaxAmount float64 `json:"tax_amount"`
+	Location  string  `json:"location,omitempty"`
+
+	// Customization 為客製化內容（例如刻字、組態），依商品的客製化 schema 驗證，沒有客製化時為 nil
+	Customization json.RawMessage `json:"customization,omitempty"`
+	// Note、Metadata 為項目的備註與自訂屬性（例如送禮訊息、尺寸備註），結帳時帶入訂單項目
+	Note     string            `json:"note,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
 }
 
 func (c *Cart) ConvertSqlcCart(sqlcCart any) *Cart {
 
 	var id uint64
-	var customerID string
+	var customerID, name, channel string
+	var isDefault bool
 	var status enum.CartStatus
 	var currency stripe.Currency
 	var subtotal, tax, discount, total float64
 	var createdAt, updatedAt, expiresAt time.Time
+	var shippingAddress, billingAddress json.RawMessage
+	var promotions []*AppliedPromotion
 
 	switch sp := sqlcCart.(type) {
-	case *sqlc.Cart:
+	case *sqlc.ListConvertedCartsWithoutOrderRow:
 		id = uint64(sp.ID)
 		customerID = sp.CustomerID
 		status = enum.CartStatus(sp.Status)
@@ -70,6 +98,14 @@ func (c *Cart) ConvertSqlcCart(sqlcCart any) *Cart {
 		createdAt = sp.CreatedAt.Time
 		updatedAt = sp.UpdatedAt.Time
 		expiresAt = sp.ExpiresAt.Time
+		shippingAddress = sp.ShippingAddress
+		billingAddress = sp.BillingAddress
+		promotions = appliedPromotions(sp.AppliedPromotions)
+		name = sp.Name
+		isDefault = sp.IsDefault
+		if sp.Channel != nil {
+			channel = *sp.Channel
+		}
 	case *sqlc.FindActiveCartByCustomerIDRow:
 		id = uint64(sp.ID)
 		customerID = sp.CustomerID
@@ -82,12 +118,26 @@ func (c *Cart) ConvertSqlcCart(sqlcCart any) *Cart {
 		createdAt = sp.CreatedAt.Time
 		updatedAt = sp.UpdatedAt.Time
 		expiresAt = sp.ExpiresAt.Time
+		shippingAddress = sp.ShippingAddress
+		billingAddress = sp.BillingAddress
+		promotions = appliedPromotions(sp.AppliedPromotions)
+		name = sp.Name
+		isDefault = sp.IsDefault
+		if sp.Channel != nil {
+			channel = *sp.Channel
+		}
+	case *sqlc.FindActiveCartByNameRow:
+		return c.ConvertSqlcCart((*sqlc.GetCartRow)(sp))
+	case *sqlc.ListActiveCartsByCustomerIDRow:
+		return c.ConvertSqlcCart((*sqlc.GetCartRow)(sp))
 	default:
 		return nil
 	}
 
 	c.ID = id
 	c.CustomerID = customerID
+	c.Name = name
+	c.IsDefault = isDefault
 	c.Status = status
 	c.Currency = currency
 	c.Subtotal = subtotal
@@ -97,6 +147,10 @@ func (c *Cart) ConvertSqlcCart(sqlcCart any) *Cart {
 	c.ExpiresAt = expiresAt
 	c.CreatedAt = createdAt
 	c.UpdatedAt = updatedAt
+	c.ShippingAddress = shippingAddress
+	c.BillingAddress = billingAddress
+	c.AppliedPromotions = promotions
+	c.Channel = channel
 
 	return c
 }
@@ -104,8 +158,10 @@ func (c *Cart) ConvertSqlcCart(sqlcCart any) *Cart {
 func (ci *CartItem) ConvertSqlcCartItem(sqlcCartItem any) *CartItem {
 
 	var id, cartID, stockID, quantity uint64
-	var productID, priceID string
-	var subtotal, unitPrice float64
+	var productID, priceID, location, note string
+	var subtotal, unitPrice, taxRate, taxAmount float64
+	var customization json.RawMessage
+	var metadata map[string]string
 
 	switch sp := sqlcCartItem.(type) {
 	case *sqlc.CartItem:
@@ -117,6 +173,16 @@ func (ci *CartItem) ConvertSqlcCartItem(sqlcCartItem any) *CartItem {
 		priceID = sp.PriceID
 		subtotal = sp.Subtotal
 		unitPrice = sp.UnitPrice
+		taxRate = sp.TaxRate
+		taxAmount = sp.TaxAmount
+		if sp.Location != nil {
+			location = *sp.Location
+		}
+		customization = sp.Customization
+		if sp.Note != nil {
+			note = *sp.Note
+		}
+		metadata = parseMetadata(sp.Metadata)
 	default:
 		return nil
 	}
@@ -129,6 +195,56 @@ func (ci *CartItem) ConvertSqlcCartItem(sqlcCartItem any) *CartItem {
 	ci.Quantity = quantity
 	ci.UnitPrice = unitPrice
 	ci.Subtotal = subtotal
+	ci.TaxRate = taxRate
+	ci.TaxAmount = taxAmount
+	ci.Location = location
+	ci.Customization = customization
+	ci.Note = note
+	ci.Metadata = metadata
 
 	return ci
 }
+
+// CartIssue 為結帳前檢查發現的單一問題，CartItemID 與 ProductID 為空時表示整台購物車的問題；
+// 其餘欄位依問題類型提供前端顯示修正建議所需的資料
+type CartIssue struct {
+	Code       enum.CartIssueCode `json:"code"`
+	Message    string             `json:"message"`
+	CartItemID uint64             `json:"cart_item_id,omitempty"`
+	ProductID  string             `json:"product_id,omitempty"`
+
+	// CartQuantity、AvailableQuantity 為庫存問題的購物車數量與目前可結帳的數量，AvailableQuantity 為 0 表示已無庫存
+	CartQuantity      uint64  `json:"cart_quantity,omitempty"`
+	AvailableQuantity *uint64 `json:"available_quantity,omitempty"`
+	// CartUnitPrice、CurrentUnitPrice 為價格變動時購物車的單價與目前生效的單價
+	CartUnitPrice    float64 `json:"cart_unit_price,omitempty"`
+	CurrentUnitPrice float64 `json:"current_unit_price,omitempty"`
+	// CouponCode 為優惠券問題的優惠券代碼
+	CouponCode string `json:"coupon_code,omitempty"`
+}
+
+// CartValidation 為結帳前的檢查結果，Blocking 不為空時無法結帳，Warnings 只需提示使用者；
+// 金額為以目前活動與稅率重新計算的結帳金額
+type CartValidation struct {
+	CartID     uint64              `json:"cart_id"`
+	Ready      bool                `json:"ready"`
+	Currency   stripe.Currency     `json:"currency"`
+	Subtotal   float64             `json:"subtotal"`
+	Tax        float64             `json:"tax"`
+	Discount   float64             `json:"discount"`
+	Total      float64             `json:"total"`
+	Blocking   []*CartIssue        `json:"blocking"`
+	Warnings   []*CartIssue        `json:"warnings"`
+	Promotions []*AppliedPromotion `json:"promotions"`
+	CheckedAt  time.Time           `json:"checked_at"`
+}
+
+// Block 記錄一個阻擋結帳的問題
+func (cv *CartValidation) Block(issue *CartIssue) {
+	cv.Blocking = append(cv.Blocking, issue)
+}
+
+// Warn 記錄一個不阻擋結帳的提醒
+func (cv *CartValidation) Warn(issue *CartIssue) {
+	cv.Warnings = append(cv.Warnings, issue)
+}
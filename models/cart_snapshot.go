@@ -0,0 +1,55 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/stripe/stripe-go/v79"
+)
+
+// CartSnapshot 為結帳當下購物車的快照，隨訂單保存且不再變更，之後購物車被修改或刪除都不影響客戶實際購買的內容
+type CartSnapshot struct {
+	CartID   uint64              `json:"cart_id"`
+	Name     string              `json:"name,omitempty"`
+	Channel  string              `json:"channel,omitempty"`
+	Currency stripe.Currency     `json:"currency"`
+	Items    []*CartSnapshotItem `json:"items"`
+	Subtotal float64             `json:"subtotal"`
+	Tax      float64             `json:"tax"`
+	Discount float64             `json:"discount"`
+	Total    float64             `json:"total"`
+	// Promotions 為結帳時依疊加規則套用的優惠，CouponCode 為套用的優惠券代碼
+	Promotions []*AppliedPromotion `json:"promotions,omitempty"`
+	CouponCode string              `json:"coupon_code,omitempty"`
+	CapturedAt time.Time           `json:"captured_at"`
+}
+
+// CartSnapshotItem 為快照中的購物車項目，Discount 為分攤到該項目的折扣
+type CartSnapshotItem struct {
+	CartItemID uint64  `json:"cart_item_id"`
+	ProductID  string  `json:"product_id"`
+	PriceID    string  `json:"price_id"`
+	StockID    uint64  `json:"stock_id"`
+	Location   string  `json:"location,omitempty"`
+	Quantity   uint64  `json:"quantity"`
+	UnitPrice  float64 `json:"unit_price"`
+	Subtotal   float64 `json:"subtotal"`
+	Discount   float64 `json:"discount,omitempty"`
+
+	Customization json.RawMessage   `json:"customization,omitempty"`
+	Note          string            `json:"note,omitempty"`
+	Metadata      map[string]string `json:"metadata,omitempty"`
+}
+
+// cartSnapshot 解析訂單的 JSONB cart_snapshot，沒有快照時回傳 nil
+func cartSnapshot(raw []byte) *CartSnapshot {
+	if len(raw) == 0 {
+		return nil
+	}
+
+	var snapshot CartSnapshot
+	if err := json.Unmarshal(raw, &snapshot); err != nil {
+		return nil
+	}
+	return &snapshot
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"github.com/stripe/stripe-go/v79"
+	"gofalre.io/shop/sqlc"
+)
+
+// CatalogEntry 商品列表頁所需的價格、可售數量與分類資訊，PriceID 為空表示商品尚未有生效的價格
+type CatalogEntry struct {
+	ProductID     string          `json:"product_id"`
+	PriceID       string          `json:"price_id,omitempty"`
+	UnitPrice     float64         `json:"unit_price"`
+	Currency      stripe.Currency `json:"currency,omitempty"`
+	Available     uint64          `json:"available"`
+	CategorySlugs []string        `json:"category_slugs"`
+	// Name、Description 為請求語系的商品翻譯，Locale 為實際套用的語系；沒有任何翻譯時皆為空白
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	Locale      string `json:"locale,omitempty"`
+}
+
+// InStock 表示商品目前是否還有可售數量
+func (ce *CatalogEntry) InStock() bool {
+	return ce.Available > 0
+}
+
+func (ce *CatalogEntry) ConvertSqlcCatalogEntry(sqlcCatalogEntry any) *CatalogEntry {
+
+	switch sp := sqlcCatalogEntry.(type) {
+	case *sqlc.GetCatalogSnapshotRow:
+		ce.ProductID = sp.ProductID
+		if sp.PriceID != nil {
+			ce.PriceID = *sp.PriceID
+		}
+		ce.UnitPrice = sp.UnitPrice
+		if sp.Currency.Valid {
+			ce.Currency = stripe.Currency(sp.Currency.Currency)
+		}
+		if sp.Available > 0 {
+			ce.Available = uint64(sp.Available)
+		}
+		ce.CategorySlugs = sp.CategorySlugs
+	default:
+		return nil
+	}
+
+	return ce
+}
@@ -8,10 +8,13 @@ import (
 type Category struct {
 	ID          uint64    `json:"id"`
 	Name        string    `json:"name"`
+	Slug        string    `json:"slug"`
 	Description string    `json:"description"`
 	ParentID    *uint64   `json:"parent_id,omitempty"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
+	// Locale 為 Name 與 Description 套用的翻譯語系，空白表示分類本身的名稱
+	Locale string `json:"locale,omitempty"`
 }
 
 type CategoryTree struct {
@@ -22,7 +25,7 @@ type CategoryTree struct {
 func (c *Category) ConvertSqlcCategory(sqlcCategory any) *Category {
 
 	var id uint64
-	var name, description string
+	var name, slug, description string
 	var parentID *uint64
 	var createdAt, updatedAt time.Time
 
@@ -30,6 +33,7 @@ func (c *Category) ConvertSqlcCategory(sqlcCategory any) *Category {
 	case *sqlc.Category:
 		id = uint64(sp.ID)
 		name = sp.Name
+		slug = sp.Slug
 		if sp.Description != nil {
 			description = *sp.Description
 		}
@@ -45,6 +49,7 @@ func (c *Category) ConvertSqlcCategory(sqlcCategory any) *Category {
 
 	c.ID = id
 	c.Name = name
+	c.Slug = slug
 	c.Description = description
 	c.ParentID = parentID
 	c.CreatedAt = createdAt
@@ -52,3 +57,29 @@ func (c *Category) ConvertSqlcCategory(sqlcCategory any) *Category {
 
 	return c
 }
+
+// CategoryTreeDocument 分類樹匯入匯出的 JSON 文件
+type CategoryTreeDocument struct {
+	Version    int             `json:"version"`
+	ExportedAt time.Time       `json:"exported_at"`
+	Categories []*CategoryNode `json:"categories"`
+}
+
+// CategoryNode 匯入匯出文件中的單一分類節點，以 slug 作為跨環境的識別
+type CategoryNode struct {
+	Slug        string          `json:"slug"`
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Children    []*CategoryNode `json:"children,omitempty"`
+}
+
+// CategoryImportResult 分類樹匯入的結果，DryRun 時僅描述將會執行的變更；
+// Stale 為讀取後被其他交易修改而未更新的分類，重新匯入即可套用
+type CategoryImportResult struct {
+	DryRun    bool     `json:"dry_run"`
+	Created   []string `json:"created"`
+	Updated   []string `json:"updated"`
+	Deleted   []string `json:"deleted"`
+	Unchanged []string `json:"unchanged"`
+	Stale     []string `json:"stale"`
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"time"
+
+	"github.com/stripe/stripe-go/v79"
+	"gofalre.io/shop/sqlc"
+)
+
+// ChannelProduct 代表上架到銷售通路的商品，Available 為 false 時商品仍在通路目錄中但暫停供貨
+type ChannelProduct struct {
+	Channel   string    `json:"channel"`
+	ProductID string    `json:"product_id"`
+	Available bool      `json:"available"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ChannelPrice 為銷售通路價格表中的單一價格，覆蓋該價格在通路與幣別下的單價
+type ChannelPrice struct {
+	Channel   string          `json:"channel"`
+	PriceID   string          `json:"price_id"`
+	Currency  stripe.Currency `json:"currency"`
+	UnitPrice float64         `json:"unit_price"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// ChannelSales 同一銷售通路與訂單幣別的銷售額，Channel 為空字串表示未設定通路的訂單
+type ChannelSales struct {
+	Channel  string          `json:"channel"`
+	Currency stripe.Currency `json:"currency"`
+	Orders   uint64          `json:"orders"`
+	Total    float64         `json:"total"`
+}
+
+func (cp *ChannelProduct) ConvertSqlcChannelProduct(sqlcChannelProduct any) *ChannelProduct {
+
+	switch sp := sqlcChannelProduct.(type) {
+	case *sqlc.ChannelProduct:
+		cp.Channel = sp.Channel
+		cp.ProductID = sp.ProductID
+		cp.Available = sp.Available
+		cp.CreatedAt = sp.CreatedAt.Time
+		cp.UpdatedAt = sp.UpdatedAt.Time
+	default:
+		return nil
+	}
+
+	return cp
+}
+
+func (cp *ChannelPrice) ConvertSqlcChannelPrice(sqlcChannelPrice any) *ChannelPrice {
+
+	switch sp := sqlcChannelPrice.(type) {
+	case *sqlc.ChannelPrice:
+		cp.Channel = sp.Channel
+		cp.PriceID = sp.PriceID
+		cp.Currency = stripe.Currency(sp.Currency)
+		cp.UnitPrice = sp.UnitPrice
+		cp.CreatedAt = sp.CreatedAt.Time
+		cp.UpdatedAt = sp.UpdatedAt.Time
+	default:
+		return nil
+	}
+
+	return cp
+}
+
+func (cs *ChannelSales) ConvertSqlcChannelSales(sqlcChannelSales any) *ChannelSales {
+
+	switch sp := sqlcChannelSales.(type) {
+	case *sqlc.GetChannelSalesReportRow:
+		cs.Channel = sp.Channel
+		cs.Currency = stripe.Currency(sp.Currency)
+		cs.Orders = uint64(sp.Orders)
+		cs.Total = sp.Total
+	default:
+		return nil
+	}
+
+	return cs
+}
@@ -0,0 +1,109 @@
+package models
+
+import (
+	"time"
+
+	"gofalre.io/shop/sqlc"
+)
+
+// ConsistencyReport 資料一致性檢查的結果，AutoFix 為 true 時可修正的項目會標記 Fixed
+type ConsistencyReport struct {
+	CheckedAt                  time.Time        `json:"checked_at"`
+	AutoFix                    bool             `json:"auto_fix"`
+	OrphanedCartItems          []*OrphanedItem  `json:"orphaned_cart_items"`
+	OrphanedOrderItems         []*OrphanedItem  `json:"orphaned_order_items"`
+	OverReservedStocks         []*Stock         `json:"over_reserved_stocks"`
+	ConvertedCartsWithoutOrder []*Cart          `json:"converted_carts_without_order"`
+	CartTotalMismatches        []*TotalMismatch `json:"cart_total_mismatches"`
+	OrderTotalMismatches       []*TotalMismatch `json:"order_total_mismatches"`
+}
+
+// Issues 回傳發現的問題總數
+func (r *ConsistencyReport) Issues() int {
+	return len(r.OrphanedCartItems) + len(r.OrphanedOrderItems) + len(r.OverReservedStocks) +
+		len(r.ConvertedCartsWithoutOrder) + len(r.CartTotalMismatches) + len(r.OrderTotalMismatches)
+}
+
+// OrphanedItem 代表購物車或訂單中指向不存在、或屬於其他商品之庫存的項目
+type OrphanedItem struct {
+	ID             uint64  `json:"id"`
+	ParentID       uint64  `json:"parent_id"`
+	ParentStatus   string  `json:"parent_status"`
+	ProductID      string  `json:"product_id"`
+	StockID        *uint64 `json:"stock_id,omitempty"`
+	StockProductID string  `json:"stock_product_id,omitempty"`
+	Fixed          bool    `json:"fixed"`
+}
+
+// StockMissing 表示項目指向的庫存不存在，而非屬於其他商品
+func (oi *OrphanedItem) StockMissing() bool {
+	return oi.StockProductID == ""
+}
+
+// TotalMismatch 代表小計與項目小計加總不符，或總額不等於小計加稅減折扣的購物車或訂單
+type TotalMismatch struct {
+	ID            uint64  `json:"id"`
+	Status        string  `json:"status"`
+	Subtotal      float64 `json:"subtotal"`
+	ItemsSubtotal float64 `json:"items_subtotal"`
+	Tax           float64 `json:"tax"`
+	Discount      float64 `json:"discount"`
+	Total         float64 `json:"total"`
+	Fixed         bool    `json:"fixed"`
+}
+
+func (oi *OrphanedItem) ConvertSqlcOrphanedItem(sqlcOrphanedItem any) *OrphanedItem {
+
+	var stockProductID *string
+
+	switch sp := sqlcOrphanedItem.(type) {
+	case *sqlc.ListOrphanedCartItemsRow:
+		oi.ID = uint64(sp.ID)
+		oi.ParentID = sp.CartID
+		oi.ParentStatus = string(sp.CartStatus)
+		oi.ProductID = sp.ProductID
+		oi.StockID = sp.StockID
+		stockProductID = sp.StockProductID
+	case *sqlc.ListOrphanedOrderItemsRow:
+		oi.ID = uint64(sp.ID)
+		oi.ParentID = uint64(sp.OrderID)
+		oi.ParentStatus = string(sp.OrderStatus)
+		oi.ProductID = sp.ProductID
+		oi.StockID = sp.StockID
+		stockProductID = sp.StockProductID
+	default:
+		return nil
+	}
+
+	if stockProductID != nil {
+		oi.StockProductID = *stockProductID
+	}
+
+	return oi
+}
+
+func (tm *TotalMismatch) ConvertSqlcTotalMismatch(sqlcTotalMismatch any) *TotalMismatch {
+
+	switch sp := sqlcTotalMismatch.(type) {
+	case *sqlc.ListCartTotalMismatchesRow:
+		tm.ID = uint64(sp.ID)
+		tm.Status = string(sqlc.CartStatusActive)
+		tm.Subtotal = sp.Subtotal
+		tm.ItemsSubtotal = sp.ItemsSubtotal
+		tm.Tax = sp.Tax
+		tm.Discount = sp.Discount
+		tm.Total = sp.Total
+	case *sqlc.ListOrderTotalMismatchesRow:
+		tm.ID = uint64(sp.ID)
+		tm.Status = string(sp.Status)
+		tm.Subtotal = sp.Subtotal
+		tm.ItemsSubtotal = sp.ItemsSubtotal
+		tm.Tax = sp.Tax
+		tm.Discount = sp.Discount
+		tm.Total = sp.Total
+	default:
+		return nil
+	}
+
+	return tm
+}
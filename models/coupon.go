@@ -0,0 +1,98 @@
+package models
+
+import (
+	"time"
+
+	"github.com/stripe/stripe-go/v79"
+
+	"gofalre.io/shop/models/enum"
+	"gofalre.io/shop/sqlc"
+)
+
+// Coupon 為優惠券定義，Code 不分大小寫，一律以大寫儲存；percentage 的 Amount 為折扣百分比，
+// fixed 的 Amount 為 Currency 幣別的折抵金額。StartsAt 與 ExpiresAt 為 nil 表示不限，
+// UsageLimit 為可兌換的總次數，nil 表示不限；RedeemedCount 於結帳成立訂單時累加。
+// CustomerID 與 CartID 不為空時只限該客戶用於該購物車（閒置購物車的挽回優惠券），RevokedAt 為作廢的時間
+type Coupon struct {
+	ID            uint64                  `json:"id"`
+	Code          string                  `json:"code"`
+	Name          string                  `json:"name,omitempty"`
+	DiscountType  enum.CouponDiscountType `json:"discount_type"`
+	Amount        float64                 `json:"amount"`
+	Currency      stripe.Currency         `json:"currency,omitempty"`
+	StartsAt      *time.Time              `json:"starts_at,omitempty"`
+	ExpiresAt     *time.Time              `json:"expires_at,omitempty"`
+	UsageLimit    *uint64                 `json:"usage_limit,omitempty"`
+	RedeemedCount uint64                  `json:"redeemed_count"`
+	CustomerID    string                  `json:"customer_id,omitempty"`
+	CartID        *uint64                 `json:"cart_id,omitempty"`
+	RevokedAt     *time.Time              `json:"revoked_at,omitempty"`
+	CreatedAt     time.Time               `json:"created_at"`
+	UpdatedAt     time.Time               `json:"updated_at"`
+}
+
+// Active 回傳優惠券在 at 時是否已開始、尚未過期且未作廢
+func (c *Coupon) Active(at time.Time) bool {
+	if c.RevokedAt != nil {
+		return false
+	}
+	if c.StartsAt != nil && at.Before(*c.StartsAt) {
+		return false
+	}
+	return c.ExpiresAt == nil || at.Before(*c.ExpiresAt)
+}
+
+// BoundTo 回傳優惠券是否可以用於購物車，沒有綁定客戶或購物車的優惠券適用所有購物車
+func (c *Coupon) BoundTo(cart *Cart) bool {
+	if c.CustomerID != "" && c.CustomerID != cart.CustomerID {
+		return false
+	}
+	return c.CartID == nil || *c.CartID == cart.ID
+}
+
+// Exhausted 回傳優惠券是否已達兌換次數上限
+func (c *Coupon) Exhausted() bool {
+	return c.UsageLimit != nil && c.RedeemedCount >= *c.UsageLimit
+}
+
+func (c *Coupon) ConvertSqlcCoupon(sqlcCoupon any) *Coupon {
+
+	switch sp := sqlcCoupon.(type) {
+	case *sqlc.Coupon:
+		c.ID = uint64(sp.ID)
+		c.Code = sp.Code
+		c.Name = sp.Name
+		c.DiscountType = enum.CouponDiscountType(sp.DiscountType)
+		c.Amount = sp.Amount
+		if sp.Currency.Valid {
+			c.Currency = stripe.Currency(sp.Currency.Currency)
+		}
+		if sp.StartsAt.Valid {
+			c.StartsAt = &sp.StartsAt.Time
+		}
+		if sp.ExpiresAt.Valid {
+			c.ExpiresAt = &sp.ExpiresAt.Time
+		}
+		if sp.UsageLimit != nil {
+			usageLimit := uint64(*sp.UsageLimit)
+			c.UsageLimit = &usageLimit
+		}
+		c.RedeemedCount = uint64(sp.RedeemedCount)
+		if sp.CustomerID != nil {
+			c.CustomerID = *sp.CustomerID
+		}
+		if sp.RecoveryCartID != nil {
+			cartID := uint64(*sp.RecoveryCartID)
+			c.CartID = &cartID
+		}
+		if sp.RevokedAt.Valid {
+			c.RevokedAt = &sp.RevokedAt.Time
+		}
+		c.CreatedAt = sp.CreatedAt.Time
+		c.UpdatedAt = sp.UpdatedAt.Time
+	default:
+		return nil
+	}
+
+	return c
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"time"
+
+	"gofalre.io/shop/models/enum"
+	"gofalre.io/shop/sqlc"
+)
+
+// CustomerOrderStats 客戶在統計期間內有效訂單的彙總，Spend 優先使用報表幣別的金額
+type CustomerOrderStats struct {
+	CustomerID   string    `json:"customer_id"`
+	Orders       uint64    `json:"orders"`
+	Spend        float64   `json:"spend"`
+	FirstOrderAt time.Time `json:"first_order_at"`
+	LastOrderAt  time.Time `json:"last_order_at"`
+}
+
+// RFMScore 客戶在所有客戶中的近期性、頻率與消費金額分數，皆為 1 到 5，5 為最佳
+type RFMScore struct {
+	Recency   int `json:"recency"`
+	Frequency int `json:"frequency"`
+	Monetary  int `json:"monetary"`
+}
+
+// CustomerProfile 客戶的分群結果，供促銷與通知模組鎖定對象
+type CustomerProfile struct {
+	CustomerOrderStats
+	SpendTier string               `json:"spend_tier"`
+	RFM       RFMScore             `json:"rfm"`
+	Segment   enum.CustomerSegment `json:"segment"`
+}
+
+// SegmentFilter 篩選客戶群，同一欄位內的條件為「或」，不同欄位之間為「且」，空白的欄位不限制
+type SegmentFilter struct {
+	Segments   []enum.CustomerSegment `json:"segments,omitempty"`
+	SpendTiers []string               `json:"spend_tiers,omitempty"`
+}
+
+// Matches 判斷客戶是否符合篩選條件
+func (f SegmentFilter) Matches(profile *CustomerProfile) bool {
+	if len(f.Segments) > 0 && !containsSegment(f.Segments, profile.Segment) {
+		return false
+	}
+	if len(f.SpendTiers) > 0 && !containsString(f.SpendTiers, profile.SpendTier) {
+		return false
+	}
+	return true
+}
+
+func containsSegment(segments []enum.CustomerSegment, segment enum.CustomerSegment) bool {
+	for _, s := range segments {
+		if s == segment {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (cs *CustomerOrderStats) ConvertSqlcCustomerOrderStats(sqlcStats any) *CustomerOrderStats {
+
+	switch sp := sqlcStats.(type) {
+	case *sqlc.ListCustomerOrderStatsRow:
+		cs.CustomerID = sp.CustomerID
+		cs.Orders = uint64(sp.Orders)
+		cs.Spend = sp.Spend
+		cs.FirstOrderAt = sp.FirstOrderAt.Time
+		cs.LastOrderAt = sp.LastOrderAt.Time
+	default:
+		return nil
+	}
+
+	return cs
+}
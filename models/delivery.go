@@ -0,0 +1,48 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// DeliveryDestination 代表估算送達日期的目的地，Country 為 ISO 3166-1 alpha-2 國碼
+type DeliveryDestination struct {
+	Country    string `json:"country"`
+	PostalCode string `json:"postal_code,omitempty"`
+}
+
+// ParseDeliveryDestination 從寄送地址 JSON 取出國碼與郵遞區號，地址無法解析或沒有國碼時回傳 false
+func ParseDeliveryDestination(address json.RawMessage) (DeliveryDestination, bool) {
+	var destination DeliveryDestination
+	if err := json.Unmarshal(address, &destination); err != nil {
+		return DeliveryDestination{}, false
+	}
+	destination.Country = strings.ToUpper(strings.TrimSpace(destination.Country))
+	destination.PostalCode = strings.TrimSpace(destination.PostalCode)
+	return destination, destination.Country != ""
+}
+
+// DeliveryWindow 代表預估送達的日期區間，Earliest 與 Latest 皆包含在內，只使用日期部分
+type DeliveryWindow struct {
+	Earliest time.Time `json:"earliest"`
+	Latest   time.Time `json:"latest"`
+}
+
+// ShipmentEstimate 代表從單一倉庫出貨的預估，ShipDate 考慮了出貨截止時間與備貨天數
+type ShipmentEstimate struct {
+	Location       string         `json:"location"`
+	ShipDate       time.Time      `json:"ship_date"`
+	TransitMinDays int            `json:"transit_min_days"`
+	TransitMaxDays int            `json:"transit_max_days"`
+	Window         DeliveryWindow `json:"window"`
+	ProductIDs     []string       `json:"product_ids"`
+}
+
+// DeliveryEstimate 代表購物車或訂單的預估送達區間；分批出貨時 Window 為所有出貨都送達的區間
+type DeliveryEstimate struct {
+	Destination DeliveryDestination `json:"destination"`
+	Window      DeliveryWindow      `json:"window"`
+	Shipments   []*ShipmentEstimate `json:"shipments"`
+	EstimatedAt time.Time           `json:"estimated_at"`
+}
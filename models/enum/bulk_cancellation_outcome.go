@@ -0,0 +1,11 @@
+package enum
+
+// BulkCancellationOutcome 表示批次取消訂單時每筆訂單的處理結果
+type BulkCancellationOutcome string
+
+const (
+	BulkCancellationOutcomeCancelled       BulkCancellationOutcome = "cancelled"        // 未付款的訂單已取消，已恢復庫存並取消 payment intent
+	BulkCancellationOutcomeRefundRequested BulkCancellationOutcome = "refund_requested" // 已付款的訂單已發起全額退款，退款完成時由 Stripe 事件恢復庫存
+	BulkCancellationOutcomeSkipped         BulkCancellationOutcome = "skipped"          // 處理前訂單狀態已改變或已出貨，未處理
+	BulkCancellationOutcomeFailed          BulkCancellationOutcome = "failed"           // 取消或退款失敗，Error 為失敗原因
+)
@@ -0,0 +1,13 @@
+package enum
+
+// CancellationReason 表示客戶自助取消訂單時選擇的原因
+type CancellationReason string
+
+const (
+	CancellationReasonChangedMind      CancellationReason = "changed_mind"       // 不想要了
+	CancellationReasonOrderedByMistake CancellationReason = "ordered_by_mistake" // 下錯單
+	CancellationReasonFoundBetterPrice CancellationReason = "found_better_price" // 找到更便宜的價格
+	CancellationReasonDeliveryTooSlow  CancellationReason = "delivery_too_slow"  // 配送時間太長
+	CancellationReasonPaymentIssue     CancellationReason = "payment_issue"      // 付款問題
+	CancellationReasonOther            CancellationReason = "other"              // 其他
+)
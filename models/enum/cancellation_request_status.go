@@ -0,0 +1,11 @@
+package enum
+
+// CancellationRequestStatus 表示訂單取消申請的處理結果
+type CancellationRequestStatus string
+
+const (
+	CancellationRequestStatusAutoCancelled CancellationRequestStatus = "auto_cancelled" // 符合自助取消條件，已直接取消
+	CancellationRequestStatusPendingReview CancellationRequestStatus = "pending_review" // 等待人員審核
+	CancellationRequestStatusApproved      CancellationRequestStatus = "approved"       // 人員核准並已取消或退款
+	CancellationRequestStatusRejected      CancellationRequestStatus = "rejected"       // 人員拒絕
+)
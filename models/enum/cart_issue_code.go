@@ -0,0 +1,20 @@
+package enum
+
+// CartIssueCode 表示結帳前檢查購物車時發現的問題類型
+type CartIssueCode string
+
+const (
+	CartIssueCodeCartNotActive          CartIssueCode = "cart_not_active"           // 購物車已結帳或已放棄
+	CartIssueCodeCartExpired            CartIssueCode = "cart_expired"              // 購物車已超過閒置期限
+	CartIssueCodeCartEmpty              CartIssueCode = "cart_empty"                // 購物車沒有商品
+	CartIssueCodeStockUnavailable       CartIssueCode = "stock_unavailable"         // 庫存不存在或為租借模式
+	CartIssueCodeInsufficientStock      CartIssueCode = "insufficient_stock"        // 庫存數量不足
+	CartIssueCodeQuantityLimitExceeded  CartIssueCode = "quantity_limit_exceeded"   // 超過搶購模式的數量上限
+	CartIssueCodePriceChanged           CartIssueCode = "price_changed"             // 目前生效的價格與加入購物車時不同
+	CartIssueCodeTotalsChanged          CartIssueCode = "totals_changed"            // 活動或稅率變動，結帳金額與購物車顯示不同
+	CartIssueCodeMissingShippingAddress CartIssueCode = "missing_shipping_address"  // 尚未填寫寄送地址
+	CartIssueCodeBelowMinimumOrderValue CartIssueCode = "below_minimum_order_value" // 未達最低訂單金額
+	CartIssueCodeCouponExpired          CartIssueCode = "coupon_expired"            // 套用的優惠券已過期
+	CartIssueCodeCouponUnavailable      CartIssueCode = "coupon_unavailable"        // 套用的優惠券已作廢、用完或不適用
+	CartIssueCodeNotInChannel           CartIssueCode = "not_in_channel"            // 商品已不在購物車的銷售通路或暫停供貨
+)
@@ -0,0 +1,9 @@
+package enum
+
+// CategoryImportMode 表示分類樹匯入的模式
+type CategoryImportMode string
+
+const (
+	CategoryImportModeMerge   CategoryImportMode = "merge"   // 合併：新增或更新檔案中的分類，保留檔案外的分類
+	CategoryImportModeReplace CategoryImportMode = "replace" // 取代：以檔案為準，刪除檔案中不存在的分類
+)
@@ -0,0 +1,11 @@
+package enum
+
+// CheckoutRecoveryPolicy 表示結帳付款失敗後的處理方式
+type CheckoutRecoveryPolicy string
+
+const (
+	CheckoutRecoveryPolicyFail         CheckoutRecoveryPolicy = "fail"          // 訂單標記為失敗，不恢復購物車
+	CheckoutRecoveryPolicyRetryPayment CheckoutRecoveryPolicy = "retry_payment" // 訂單保持待付款，客戶以同一筆 PaymentIntent 重新付款
+	CheckoutRecoveryPolicyRestoreCart  CheckoutRecoveryPolicy = "restore_cart"  // 訂單標記為失敗，恢復原購物車並重新預留庫存
+	CheckoutRecoveryPolicyCloneCart    CheckoutRecoveryPolicy = "clone_cart"    // 訂單標記為失敗，以訂單項目建立新的購物車並重新預留庫存
+)
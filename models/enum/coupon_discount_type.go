@@ -0,0 +1,9 @@
+package enum
+
+// CouponDiscountType 表示優惠券的折扣方式
+type CouponDiscountType string
+
+const (
+	CouponDiscountTypePercentage CouponDiscountType = "percentage" // 依項目小計折抵固定百分比
+	CouponDiscountTypeFixed      CouponDiscountType = "fixed"      // 整筆購物車折抵固定金額，依項目小計比例分攤
+)
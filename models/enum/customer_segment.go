@@ -0,0 +1,14 @@
+package enum
+
+// CustomerSegment 表示依 RFM 分數歸類的客戶群
+type CustomerSegment string
+
+const (
+	CustomerSegmentChampion    CustomerSegment = "champion"    // 近期購買、購買頻繁且消費高
+	CustomerSegmentLoyal       CustomerSegment = "loyal"       // 購買頻繁
+	CustomerSegmentNew         CustomerSegment = "new"         // 近期首次購買
+	CustomerSegmentPromising   CustomerSegment = "promising"   // 近期購買但頻率不高
+	CustomerSegmentAtRisk      CustomerSegment = "at_risk"     // 過去購買頻繁或消費高，但近期沒有購買
+	CustomerSegmentHibernating CustomerSegment = "hibernating" // 很久沒有購買且頻率低
+	CustomerSegmentRegular     CustomerSegment = "regular"     // 其他客戶
+)
@@ -0,0 +1,8 @@
+package enum
+
+// FraudReviewReason 表示訂單被列入詐欺審核的原因
+type FraudReviewReason string
+
+const (
+	FraudReviewReasonFingerprintVelocity FraudReviewReason = "fingerprint_velocity" // 同一張卡短時間內被多個客戶使用
+)
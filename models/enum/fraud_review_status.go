@@ -0,0 +1,10 @@
+package enum
+
+// FraudReviewStatus 表示可疑訂單的審核狀態
+type FraudReviewStatus string
+
+const (
+	FraudReviewStatusPending   FraudReviewStatus = "pending"   // 待審核
+	FraudReviewStatusCleared   FraudReviewStatus = "cleared"   // 審核後確認正常
+	FraudReviewStatusConfirmed FraudReviewStatus = "confirmed" // 審核後確認為詐欺
+)
@@ -0,0 +1,9 @@
+package enum
+
+// FulfillmentType 表示訂單的履約方式
+type FulfillmentType string
+
+const (
+	FulfillmentTypeShip   FulfillmentType = "ship"   // 寄送
+	FulfillmentTypePickup FulfillmentType = "pickup" // 門市自取
+)
@@ -0,0 +1,9 @@
+package enum
+
+// InvoiceType 表示單據的種類，發票與折讓單各自使用獨立的連續編號
+type InvoiceType string
+
+const (
+	InvoiceTypeInvoice    InvoiceType = "invoice"     // 發票
+	InvoiceTypeCreditNote InvoiceType = "credit_note" // 折讓單，退款時開立
+)
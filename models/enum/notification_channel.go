@@ -0,0 +1,10 @@
+package enum
+
+// NotificationChannel 表示發送客戶通知的管道
+type NotificationChannel string
+
+const (
+	NotificationChannelEmail NotificationChannel = "email" // 電子郵件
+	NotificationChannelSMS   NotificationChannel = "sms"   // 簡訊
+	NotificationChannelPush  NotificationChannel = "push"  // App 推播
+)
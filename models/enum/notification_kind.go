@@ -0,0 +1,9 @@
+package enum
+
+// NotificationKind 表示依客戶通知偏好發送的訊息類型
+type NotificationKind string
+
+const (
+	NotificationKindAbandonedCart NotificationKind = "abandoned_cart" // 購物車放棄提醒
+	NotificationKindBackInStock   NotificationKind = "back_in_stock"  // 商品重新到貨
+)
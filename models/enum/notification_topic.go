@@ -0,0 +1,9 @@
+package enum
+
+// NotificationTopic 表示客戶通知的主題，客戶可以分別退訂
+type NotificationTopic string
+
+const (
+	NotificationTopicMarketing     NotificationTopic = "marketing"     // 行銷通知，例如購物車放棄提醒
+	NotificationTopicTransactional NotificationTopic = "transactional" // 交易通知，例如客戶登記的到貨通知
+)
@@ -0,0 +1,10 @@
+package enum
+
+// OrderAddonType 表示訂單加購服務的種類
+type OrderAddonType string
+
+const (
+	OrderAddonTypeGiftWrap         OrderAddonType = "gift_wrap"         // 禮品包裝
+	OrderAddonTypeAssembly         OrderAddonType = "assembly"          // 組裝服務
+	OrderAddonTypeExtendedWarranty OrderAddonType = "extended_warranty" // 延長保固
+)
@@ -0,0 +1,10 @@
+package enum
+
+// OrderImportStatus 表示匯入外部訂單時每筆訂單的處理結果
+type OrderImportStatus string
+
+const (
+	OrderImportStatusCreated   OrderImportStatus = "created"   // 已建立訂單並扣除庫存
+	OrderImportStatusDuplicate OrderImportStatus = "duplicate" // 相同通路與外部編號的訂單已匯入過，未重複建立
+	OrderImportStatusFailed    OrderImportStatus = "failed"    // 驗證或建立失敗，Error 為失敗原因
+)
@@ -0,0 +1,12 @@
+package enum
+
+// OrderItemFulfillmentStatus 表示訂單項目的履約狀態，由項目的出貨與退貨數量決定
+type OrderItemFulfillmentStatus string
+
+const (
+	OrderItemFulfillmentStatusUnfulfilled       OrderItemFulfillmentStatus = "unfulfilled"        // 尚未出貨
+	OrderItemFulfillmentStatusPartiallyShipped  OrderItemFulfillmentStatus = "partially_shipped"  // 部分數量已出貨
+	OrderItemFulfillmentStatusShipped           OrderItemFulfillmentStatus = "shipped"            // 全部數量已出貨（門市自取為已取貨）
+	OrderItemFulfillmentStatusPartiallyReturned OrderItemFulfillmentStatus = "partially_returned" // 已出貨的數量部分退回
+	OrderItemFulfillmentStatusReturned          OrderItemFulfillmentStatus = "returned"           // 已出貨的數量全部退回
+)
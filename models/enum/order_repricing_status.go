@@ -0,0 +1,10 @@
+package enum
+
+// OrderRepricingStatus 表示待付款訂單因降價重新計價的結果
+type OrderRepricingStatus string
+
+const (
+	OrderRepricingStatusApplied       OrderRepricingStatus = "applied"        // 已套用新價格
+	OrderRepricingStatusPendingReview OrderRepricingStatus = "pending_review" // 等待人員審核
+	OrderRepricingStatusDismissed     OrderRepricingStatus = "dismissed"      // 人員決定不調整
+)
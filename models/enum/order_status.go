@@ -16,4 +16,6 @@ const (
 	OrderStatusRefunded          OrderStatus = "refunded"           // 訂單退款完成
 	OrderStatusAwaitingStock     OrderStatus = "awaiting_stock"     // 等待庫存補貨
 	OrderStatusDispute           OrderStatus = "dispute"            // 訂單爭議
+	OrderStatusReadyForPickup    OrderStatus = "ready_for_pickup"   // 訂單已備妥，等待門市取貨
+	OrderStatusOnHold            OrderStatus = "on_hold"            // 訂單由人員暫停履約
 )
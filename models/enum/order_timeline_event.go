@@ -0,0 +1,15 @@
+package enum
+
+// OrderTimelineEvent 表示訂單時間軸上的事件類型
+type OrderTimelineEvent string
+
+const (
+	OrderTimelineEventCreated            OrderTimelineEvent = "created"             // 訂單建立
+	OrderTimelineEventHoldPlaced         OrderTimelineEvent = "hold_placed"         // 人員暫停履約
+	OrderTimelineEventHoldReleased       OrderTimelineEvent = "hold_released"       // 人員解除暫停
+	OrderTimelineEventShipmentCreated    OrderTimelineEvent = "shipment_created"    // 建立出貨單
+	OrderTimelineEventRefunded           OrderTimelineEvent = "refunded"            // 退款
+	OrderTimelineEventRepriced           OrderTimelineEvent = "repriced"            // 價格調降後重新計價
+	OrderTimelineEventRepricingFlagged   OrderTimelineEvent = "repricing_flagged"   // 重新計價等待人員審核
+	OrderTimelineEventRepricingDismissed OrderTimelineEvent = "repricing_dismissed" // 人員略過重新計價
+)
@@ -0,0 +1,10 @@
+package enum
+
+// PriceChangeStatus 表示價格變動的狀態
+type PriceChangeStatus string
+
+const (
+	PriceChangeStatusScheduled PriceChangeStatus = "scheduled" // 已排程，尚未生效
+	PriceChangeStatusApplied   PriceChangeStatus = "applied"   // 已生效
+	PriceChangeStatusCancelled PriceChangeStatus = "cancelled" // 已取消
+)
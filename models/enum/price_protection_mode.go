@@ -0,0 +1,10 @@
+package enum
+
+// PriceProtectionMode 表示商品降價時如何處理含有該商品的待付款訂單
+type PriceProtectionMode string
+
+const (
+	PriceProtectionModeOff         PriceProtectionMode = "off"          // 不處理
+	PriceProtectionModeAutoReprice PriceProtectionMode = "auto_reprice" // 自動以新價格重新計價
+	PriceProtectionModeReview      PriceProtectionMode = "review"       // 建立重新計價的審核
+)
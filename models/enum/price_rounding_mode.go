@@ -0,0 +1,10 @@
+package enum
+
+// PriceRoundingMode 表示價格調整為尾數規則時的取捨方向
+type PriceRoundingMode string
+
+const (
+	PriceRoundingModeNearest PriceRoundingMode = "nearest" // 取最接近的價格
+	PriceRoundingModeUp      PriceRoundingMode = "up"      // 取不低於原價的價格
+	PriceRoundingModeDown    PriceRoundingMode = "down"    // 取不高於原價的價格
+)
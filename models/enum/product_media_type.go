@@ -0,0 +1,9 @@
+package enum
+
+// ProductMediaType 表示商品媒體的種類
+type ProductMediaType string
+
+const (
+	ProductMediaTypeImage ProductMediaType = "image" // 圖片
+	ProductMediaTypeVideo ProductMediaType = "video" // 影片
+)
@@ -0,0 +1,10 @@
+package enum
+
+// PromotionKind 表示優惠的來源
+type PromotionKind string
+
+const (
+	PromotionKindCampaign      PromotionKind = "campaign"       // 分類折扣活動
+	PromotionKindQuantityBreak PromotionKind = "quantity_break" // 數量折扣
+	PromotionKindCoupon        PromotionKind = "coupon"         // 優惠券
+)
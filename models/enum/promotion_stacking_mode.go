@@ -0,0 +1,9 @@
+package enum
+
+// PromotionStackingMode 表示多個優惠同時適用時的疊加方式
+type PromotionStackingMode string
+
+const (
+	PromotionStackingModeBestOnly PromotionStackingMode = "best_only" // 只套用折扣金額最高的一個優惠
+	PromotionStackingModeStack    PromotionStackingMode = "stack"     // 依序疊加，同一互斥群組只套用一個，受折扣上限限制
+)
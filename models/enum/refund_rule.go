@@ -0,0 +1,12 @@
+package enum
+
+// RefundRule 表示退款政策對訂單項目套用的規則
+type RefundRule string
+
+const (
+	RefundRuleFullRefund          RefundRule = "full_refund"           // 在全額退款期限內
+	RefundRuleRestockingFee       RefundRule = "restocking_fee"        // 超過全額退款期限，扣除重新上架費後退款
+	RefundRuleFinalSale           RefundRule = "final_sale"            // 屬於不可退款的分類
+	RefundRuleRefundWindowExpired RefundRule = "refund_window_expired" // 已超過可退款期限
+	RefundRuleAlreadyRefunded     RefundRule = "already_refunded"      // 項目已經退款
+)
@@ -0,0 +1,12 @@
+package enum
+
+// ReorderLineStatus 表示再次購買時每個訂單項目的處理結果
+type ReorderLineStatus string
+
+const (
+	ReorderLineStatusAdded        ReorderLineStatus = "added"        // 以原地點的庫存加入購物車
+	ReorderLineStatusSubstituted  ReorderLineStatus = "substituted"  // 原地點缺貨，改由其他地點出貨
+	ReorderLineStatusPartial      ReorderLineStatus = "partial"      // 庫存不足，只加入部分數量
+	ReorderLineStatusOutOfStock   ReorderLineStatus = "out_of_stock" // 所有地點皆缺貨，未加入購物車
+	ReorderLineStatusDiscontinued ReorderLineStatus = "discontinued" // 商品已停售（沒有可販售的庫存或已不在購物車的銷售通路），未加入購物車
+)
@@ -0,0 +1,13 @@
+package enum
+
+// ReturnStatus 表示退貨申請的處理狀態
+type ReturnStatus string
+
+const (
+	ReturnStatusRequested   ReturnStatus = "requested"    // 客戶已申請，等待審核
+	ReturnStatusApproved    ReturnStatus = "approved"     // 已核准，尚未取得退貨標籤
+	ReturnStatusLabelIssued ReturnStatus = "label_issued" // 已取得退貨標籤，等待客戶寄出
+	ReturnStatusInTransit   ReturnStatus = "in_transit"   // 物流運送中
+	ReturnStatusReceived    ReturnStatus = "received"     // 已送達倉庫
+	ReturnStatusRejected    ReturnStatus = "rejected"     // 已拒絕
+)
@@ -0,0 +1,9 @@
+package enum
+
+// RevenueEventType 表示營收認列匯出中的事件類型
+type RevenueEventType string
+
+const (
+	RevenueEventTypeSale   RevenueEventType = "sale"   // 訂單付款，認列銷售收入與應付稅額
+	RevenueEventTypeRefund RevenueEventType = "refund" // 退款，沖減銷售收入與應付稅額
+)
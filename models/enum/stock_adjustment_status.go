@@ -0,0 +1,10 @@
+package enum
+
+// StockAdjustmentStatus 表示人工庫存調整申請的審核狀態
+type StockAdjustmentStatus string
+
+const (
+	StockAdjustmentStatusPending  StockAdjustmentStatus = "pending"  // 待審核
+	StockAdjustmentStatusApproved StockAdjustmentStatus = "approved" // 已核准並入帳
+	StockAdjustmentStatusRejected StockAdjustmentStatus = "rejected" // 已駁回
+)
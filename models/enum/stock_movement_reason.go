@@ -0,0 +1,15 @@
+package enum
+
+// StockMovementReason 表示人工調整庫存的原因
+type StockMovementReason string
+
+const (
+	StockMovementReasonReceived   StockMovementReason = "received"   // 進貨
+	StockMovementReasonDamaged    StockMovementReason = "damaged"    // 損壞
+	StockMovementReasonLost       StockMovementReason = "lost"       // 遺失
+	StockMovementReasonTheft      StockMovementReason = "theft"      // 失竊
+	StockMovementReasonFound      StockMovementReason = "found"      // 尋回
+	StockMovementReasonRecount    StockMovementReason = "recount"    // 盤點
+	StockMovementReasonCorrection StockMovementReason = "correction" // 更正
+	StockMovementReasonOther      StockMovementReason = "other"      // 其他
+)
@@ -7,4 +7,7 @@ const (
 	StockMovementReferenceTypeOrder      StockMovementReferenceType = "order"
 	StockMovementReferenceTypeReturn     StockMovementReferenceType = "return"
 	StockMovementReferenceTypeAdjustment StockMovementReferenceType = "adjustment"
+	StockMovementReferenceTypeHold       StockMovementReferenceType = "hold"
+	StockMovementReferenceTypeTransfer   StockMovementReferenceType = "transfer"
+	StockMovementReferenceTypeManual     StockMovementReferenceType = "manual"
 )
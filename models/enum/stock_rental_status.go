@@ -0,0 +1,10 @@
+package enum
+
+// StockRentalStatus 表示租借預約的狀態
+type StockRentalStatus string
+
+const (
+	StockRentalStatusReserved  StockRentalStatus = "reserved"  // 已預約，期間內佔用庫存
+	StockRentalStatusReturned  StockRentalStatus = "returned"  // 已歸還
+	StockRentalStatusCancelled StockRentalStatus = "cancelled" // 已取消
+)
@@ -0,0 +1,10 @@
+package enum
+
+// StockTransferStatus 表示倉庫間調撥單的審核狀態
+type StockTransferStatus string
+
+const (
+	StockTransferStatusDraft    StockTransferStatus = "draft"    // 草稿，等待人員核准
+	StockTransferStatusApproved StockTransferStatus = "approved" // 已核准並移動庫存
+	StockTransferStatusRejected StockTransferStatus = "rejected" // 已駁回
+)
@@ -0,0 +1,12 @@
+package enum
+
+// StocktakeScanOutcome 表示盤點批次中單筆掃描的處理結果
+type StocktakeScanOutcome string
+
+const (
+	StocktakeScanOutcomeAccepted       StocktakeScanOutcome = "accepted"        // 已計入盤點數量
+	StocktakeScanOutcomeDuplicate      StocktakeScanOutcome = "duplicate"       // 重送的批次或重複掃描的序號，未重複計入
+	StocktakeScanOutcomeUnknownBarcode StocktakeScanOutcome = "unknown_barcode" // 條碼未對應到任何商品
+	StocktakeScanOutcomeNotStocked     StocktakeScanOutcome = "not_stocked"     // 商品在盤點地點沒有庫存記錄
+	StocktakeScanOutcomeInvalid        StocktakeScanOutcome = "invalid"         // 掃描內容不完整，例如序號掃描的數量不為 1
+)
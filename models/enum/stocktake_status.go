@@ -0,0 +1,10 @@
+package enum
+
+// StocktakeStatus 表示盤點單的狀態
+type StocktakeStatus string
+
+const (
+	StocktakeStatusOpen      StocktakeStatus = "open"      // 進行中，可持續上傳掃描批次
+	StocktakeStatusCompleted StocktakeStatus = "completed" // 已完成並依盤點數量建立庫存調整
+	StocktakeStatusCancelled StocktakeStatus = "cancelled" // 已取消，庫存不會有任何變動
+)
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/stripe/stripe-go/v79"
@@ -12,4 +13,22 @@ type Event struct {
 	Processed bool             `json:"processed"`
 	CreatedAt time.Time        `json:"created_at"`
 	UpdatedAt time.Time        `json:"updated_at"`
+	Payload   json.RawMessage  `json:"payload,omitempty"`
+}
+
+// ParkedEvent 因前一個事件（WaitingFor）尚未處理而暫緩的 Stripe 事件，Payload 為完整的事件 JSON
+type ParkedEvent struct {
+	EventID      string           `json:"event_id"`
+	ObjectID     string           `json:"object_id"`
+	Type         stripe.EventType `json:"type"`
+	WaitingFor   stripe.EventType `json:"waiting_for"`
+	EventCreated time.Time        `json:"event_created"`
+	Payload      json.RawMessage  `json:"payload"`
+	ParkedAt     time.Time        `json:"parked_at"`
+}
+
+// EventBacklog 事件處理的積壓量：Unprocessed 為處理失敗或逾時仍未完成的事件，Parked 為等待前一個事件而暫緩的事件
+type EventBacklog struct {
+	Unprocessed uint64 `json:"unprocessed"`
+	Parked      uint64 `json:"parked"`
 }
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"gofalre.io/shop/sqlc"
+)
+
+// ExperimentAssignment 購物車在實驗中被分配到的組別，OrderID 為購物車結帳成立的訂單，尚未結帳時為 nil
+type ExperimentAssignment struct {
+	CartID     uint64    `json:"cart_id"`
+	OrderID    *uint64   `json:"order_id,omitempty"`
+	Experiment string    `json:"experiment"`
+	Variant    string    `json:"variant"`
+	AssignedAt time.Time `json:"assigned_at"`
+}
+
+func (a *ExperimentAssignment) ConvertSqlcExperimentAssignment(sqlcAssignment any) *ExperimentAssignment {
+
+	switch sp := sqlcAssignment.(type) {
+	case *sqlc.ExperimentAssignment:
+		a.CartID = sp.CartID
+		a.Experiment = sp.Experiment
+		a.Variant = sp.Variant
+		a.AssignedAt = sp.AssignedAt.Time
+		if sp.OrderID != nil {
+			orderID := uint64(*sp.OrderID)
+			a.OrderID = &orderID
+		}
+	default:
+		return nil
+	}
+
+	return a
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"gofalre.io/shop/models/enum"
+	"gofalre.io/shop/sqlc"
+)
+
+// FraudReview 待人員審核的可疑訂單，Detail 為觸發審核的說明，ResolvedAt 為 nil 表示尚未審核
+type FraudReview struct {
+	ID             uint64                 `json:"id"`
+	OrderID        uint64                 `json:"order_id"`
+	Reason         enum.FraudReviewReason `json:"reason"`
+	Detail         string                 `json:"detail"`
+	Status         enum.FraudReviewStatus `json:"status"`
+	CreatedAt      time.Time              `json:"created_at"`
+	ResolvedAt     *time.Time             `json:"resolved_at,omitempty"`
+	ResolvedBy     string                 `json:"resolved_by,omitempty"`
+	ResolutionNote string                 `json:"resolution_note,omitempty"`
+}
+
+func (r *FraudReview) ConvertSqlcFraudReview(sqlcReview any) *FraudReview {
+
+	switch sp := sqlcReview.(type) {
+	case *sqlc.FraudReview:
+		r.ID = uint64(sp.ID)
+		r.OrderID = uint64(sp.OrderID)
+		r.Reason = enum.FraudReviewReason(sp.Reason)
+		r.Detail = sp.Detail
+		r.Status = enum.FraudReviewStatus(sp.Status)
+		r.CreatedAt = sp.CreatedAt.Time
+		if sp.ResolvedAt.Valid {
+			r.ResolvedAt = &sp.ResolvedAt.Time
+		}
+		if sp.ResolvedBy != nil {
+			r.ResolvedBy = *sp.ResolvedBy
+		}
+		if sp.ResolutionNote != nil {
+			r.ResolutionNote = *sp.ResolutionNote
+		}
+	default:
+		return nil
+	}
+
+	return r
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+
+	"github.com/stripe/stripe-go/v79"
+
+	"gofalre.io/shop/models/enum"
+	"gofalre.io/shop/sqlc"
+)
+
+// Invoice 訂單的發票或退款的折讓單，SequenceNumber 在同一租戶、國家與單據種類中連續不跳號；
+// 折讓單的金額為退款金額，RefundID 與 OriginalInvoiceID 指向對應的退款與原發票
+type Invoice struct {
+	ID                uint64           `json:"id"`
+	Tenant            string           `json:"tenant,omitempty"`
+	Country           string           `json:"country"`
+	Type              enum.InvoiceType `json:"type"`
+	SequenceNumber    uint64           `json:"sequence_number"`
+	InvoiceNumber     string           `json:"invoice_number"`
+	OrderID           uint64           `json:"order_id"`
+	RefundID          uint64           `json:"refund_id,omitempty"`
+	OriginalInvoiceID uint64           `json:"original_invoice_id,omitempty"`
+	Currency          stripe.Currency  `json:"currency"`
+	Subtotal          float64          `json:"subtotal"`
+	Tax               float64          `json:"tax"`
+	Total             float64          `json:"total"`
+	DocumentURL       string           `json:"document_url,omitempty"`
+	IssuedAt          time.Time        `json:"issued_at"`
+
+	// TaxExemptionNumber 為訂單下單時套用的免稅證明編號，沒有免稅時為空字串
+	TaxExemptionNumber string `json:"tax_exemption_number,omitempty"`
+}
+
+// InvoiceNumberGap 連續編號中缺少的區間，From 與 To 皆包含在內
+type InvoiceNumberGap struct {
+	Tenant  string           `json:"tenant,omitempty"`
+	Country string           `json:"country"`
+	Type    enum.InvoiceType `json:"type"`
+	From    uint64           `json:"from"`
+	To      uint64           `json:"to"`
+}
+
+func (i *Invoice) ConvertSqlcInvoice(sqlcInvoice any) *Invoice {
+
+	switch sp := sqlcInvoice.(type) {
+	case *sqlc.Invoice:
+		i.ID = uint64(sp.ID)
+		i.Tenant = sp.Tenant
+		i.Country = sp.Country
+		i.Type = enum.InvoiceType(sp.Type)
+		i.SequenceNumber = uint64(sp.SequenceNumber)
+		i.InvoiceNumber = sp.InvoiceNumber
+		i.OrderID = uint64(sp.OrderID)
+		i.RefundID = uint64Value(sp.RefundID)
+		i.OriginalInvoiceID = uint64Value(sp.OriginalInvoiceID)
+		i.Currency = stripe.Currency(sp.Currency)
+		i.Subtotal = sp.Subtotal
+		i.Tax = sp.Tax
+		i.Total = sp.Total
+		if sp.DocumentUrl != nil {
+			i.DocumentURL = *sp.DocumentUrl
+		}
+		i.IssuedAt = sp.IssuedAt.Time
+		if sp.TaxExemptionNumber != nil {
+			i.TaxExemptionNumber = *sp.TaxExemptionNumber
+		}
+	default:
+		return nil
+	}
+
+	return i
+}
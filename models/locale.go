@@ -0,0 +1,54 @@
+package models
+
+import "strings"
+
+// NormalizeLocale 將語系標籤整理為 BCP 47 的常見寫法：語言小寫、地區大寫、以連字號分隔，例如 zh_tw → zh-TW
+func NormalizeLocale(locale string) string {
+	parts := strings.FieldsFunc(strings.TrimSpace(locale), func(r rune) bool {
+		return r == '-' || r == '_'
+	})
+	if len(parts) == 0 {
+		return ""
+	}
+
+	parts[0] = strings.ToLower(parts[0])
+	for i := 1; i < len(parts); i++ {
+		switch len(parts[i]) {
+		case 2:
+			parts[i] = strings.ToUpper(parts[i]) // 地區，例如 TW
+		case 4:
+			parts[i] = strings.ToUpper(parts[i][:1]) + strings.ToLower(parts[i][1:]) // 文字，例如 Hant
+		default:
+			parts[i] = strings.ToLower(parts[i])
+		}
+	}
+	return strings.Join(parts, "-")
+}
+
+// LocaleFallbacks 回傳查詢翻譯時依序嘗試的語系：指定語系、逐步去掉子標籤的上層語系，最後是預設語系
+func LocaleFallbacks(locale, defaultLocale string) []string {
+	var fallbacks []string
+	add := func(l string) {
+		if l == "" {
+			return
+		}
+		for _, existing := range fallbacks {
+			if existing == l {
+				return
+			}
+		}
+		fallbacks = append(fallbacks, l)
+	}
+
+	for l := NormalizeLocale(locale); l != ""; {
+		add(l)
+		i := strings.LastIndex(l, "-")
+		if i < 0 {
+			break
+		}
+		l = l[:i]
+	}
+	add(NormalizeLocale(defaultLocale))
+
+	return fallbacks
+}
@@ -0,0 +1,82 @@
+package models
+
+import (
+	"time"
+
+	"gofalre.io/shop/models/enum"
+	"gofalre.io/shop/sqlc"
+)
+
+// NotificationPreferences 客戶的通知偏好，Email、SMS、Push 為各管道是否啟用；
+// MarketingOptedOutAt 與 TransactionalOptedOutAt 為退訂該主題的時間，nil 表示未退訂
+type NotificationPreferences struct {
+	CustomerID              string     `json:"customer_id"`
+	Email                   bool       `json:"email"`
+	SMS                     bool       `json:"sms"`
+	Push                    bool       `json:"push"`
+	MarketingOptedOutAt     *time.Time `json:"marketing_opted_out_at,omitempty"`
+	TransactionalOptedOutAt *time.Time `json:"transactional_opted_out_at,omitempty"`
+	UpdatedAt               time.Time  `json:"updated_at"`
+}
+
+// DefaultNotificationPreferences 回傳沒有記錄的客戶使用的偏好：啟用電子郵件與推播，未退訂任何主題
+func DefaultNotificationPreferences(customerID string) *NotificationPreferences {
+	return &NotificationPreferences{
+		CustomerID: customerID,
+		Email:      true,
+		Push:       true,
+	}
+}
+
+// OptedOut 回傳客戶是否已退訂 topic
+func (p *NotificationPreferences) OptedOut(topic enum.NotificationTopic) bool {
+	switch topic {
+	case enum.NotificationTopicMarketing:
+		return p.MarketingOptedOutAt != nil
+	case enum.NotificationTopicTransactional:
+		return p.TransactionalOptedOutAt != nil
+	default:
+		return false
+	}
+}
+
+// Channels 回傳 topic 的訊息可以使用的管道，已退訂時回傳 nil
+func (p *NotificationPreferences) Channels(topic enum.NotificationTopic) []enum.NotificationChannel {
+	if p.OptedOut(topic) {
+		return nil
+	}
+
+	var channels []enum.NotificationChannel
+	if p.Email {
+		channels = append(channels, enum.NotificationChannelEmail)
+	}
+	if p.SMS {
+		channels = append(channels, enum.NotificationChannelSMS)
+	}
+	if p.Push {
+		channels = append(channels, enum.NotificationChannelPush)
+	}
+	return channels
+}
+
+func (p *NotificationPreferences) ConvertSqlcNotificationPreferences(sqlcPreference any) *NotificationPreferences {
+
+	switch sp := sqlcPreference.(type) {
+	case *sqlc.CustomerNotificationPreference:
+		p.CustomerID = sp.CustomerID
+		p.Email = sp.EmailEnabled
+		p.SMS = sp.SmsEnabled
+		p.Push = sp.PushEnabled
+		if sp.MarketingOptedOutAt.Valid {
+			p.MarketingOptedOutAt = &sp.MarketingOptedOutAt.Time
+		}
+		if sp.TransactionalOptedOutAt.Valid {
+			p.TransactionalOptedOutAt = &sp.TransactionalOptedOutAt.Time
+		}
+		p.UpdatedAt = sp.UpdatedAt.Time
+	default:
+		return nil
+	}
+
+	return p
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// OpsDashboard 後台首頁的即時營運指標，Since 為今日的起始時間；
+// 各區塊分別載入，逾時或失敗的區塊保持零值並列在 Unavailable
+type OpsDashboard struct {
+	GeneratedAt     time.Time        `json:"generated_at"`
+	Since           time.Time        `json:"since"`
+	OrdersToday     uint64           `json:"orders_today"`
+	RevenueToday    []*CurrencySales `json:"revenue_today"`
+	PendingPayments uint64           `json:"pending_payments"`
+	FailedPayments  uint64           `json:"failed_payments"`
+	LowStock        []*Stock         `json:"low_stock"`
+	EventBacklog    *EventBacklog    `json:"event_backlog,omitempty"`
+	Queue           *QueueStats      `json:"queue,omitempty"`
+	Unavailable     []string         `json:"unavailable,omitempty"`
+}
+
+// QueueStats 事件處理佇列的使用狀況，Saturation 為 Queued 佔 Capacity 的比例，達到 1 時新的事件會阻塞
+type QueueStats struct {
+	Workers     int     `json:"workers"`
+	BusyWorkers int     `json:"busy_workers"`
+	Queued      int     `json:"queued"`
+	Capacity    int     `json:"capacity"`
+	Saturation  float64 `json:"saturation"`
+}
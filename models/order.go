@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stripe/stripe-go/v79"
 	"gofalre.io/shop/models/enum"
 	"gofalre.io/shop/sqlc"
@@ -13,24 +14,65 @@ import (
 
 // Order 代表訂單
 type Order struct {
-	ID              uint64           `json:"id"`
-	CustomerID      string           `json:"customer_id"`
-	CartID          *uint64          `json:"cart_id,omitempty"`
-	Status          enum.OrderStatus `json:"status"`
-	Currency        stripe.Currency  `json:"currency"`
-	Subtotal        float64          `json:"subtotal"`
-	Tax             float64          `json:"tax"`
-	Discount        float64          `json:"discount"`
-	Total           float64          `json:"total"`
-	PaymentIntentID string           `json:"payment_intent_id"`
-	SubscriptionID  string           `json:"subscription_id"`
-	InvoiceID       string           `json:"invoice_id"`
-	RefundID        string           `json:"refund_id"`
-	ShippingAddress json.RawMessage  `json:"shipping_address"`
-	BillingAddress  json.RawMessage  `json:"billing_address"`
-	Items           []*OrderItem     `json:"items"`
-	CreatedAt       time.Time        `json:"created_at"`
-	UpdatedAt       time.Time        `json:"updated_at"`
+	ID              uint64               `json:"id"`
+	OrderNumber     string               `json:"order_number"`
+	CustomerID      string               `json:"customer_id"`
+	CartID          *uint64              `json:"cart_id,omitempty"`
+	Status          enum.OrderStatus     `json:"status"`
+	Currency        stripe.Currency      `json:"currency"`
+	Subtotal        float64              `json:"subtotal"`
+	Tax             float64              `json:"tax"`
+	Discount        float64              `json:"discount"`
+	Total           float64              `json:"total"`
+	PaymentIntentID string               `json:"payment_intent_id"`
+	SubscriptionID  string               `json:"subscription_id"`
+	InvoiceID       string               `json:"invoice_id"`
+	RefundID        string               `json:"refund_id"`
+	ShippingAddress json.RawMessage      `json:"shipping_address"`
+	BillingAddress  json.RawMessage      `json:"billing_address"`
+	FulfillmentType enum.FulfillmentType `json:"fulfillment_type"`
+	PickupLocation  string               `json:"pickup_location,omitempty"`
+	Items           []*OrderItem         `json:"items"`
+	Addons          []*OrderAddon        `json:"addons,omitempty"`
+	CreatedAt       time.Time            `json:"created_at"`
+	UpdatedAt       time.Time            `json:"updated_at"`
+	ArchivedAt      *time.Time           `json:"archived_at,omitempty"`
+	DeletedAt       *time.Time           `json:"deleted_at,omitempty"`
+	Metadata        map[string]string    `json:"metadata,omitempty"`
+	// TaxCalculationID 與 TaxTransactionID 為外部稅務服務（Stripe Tax）的記錄，未使用時為空字串
+	TaxCalculationID string `json:"tax_calculation_id,omitempty"`
+	TaxTransactionID string `json:"tax_transaction_id,omitempty"`
+	// Reporting 為下單時換算成報表幣別的金額，未設定報表幣別時為 nil
+	Reporting *ReportingAmounts `json:"reporting,omitempty"`
+	// EstimatedDelivery 為下單時估算的送達日期區間，自取或未設定估算時為 nil
+	EstimatedDelivery *DeliveryWindow `json:"estimated_delivery,omitempty"`
+	// ExternalSource 與 ExternalOrderID 為匯入訂單的外部通路（例如 amazon）及該通路的訂單編號，一般訂單為空字串
+	ExternalSource  string `json:"external_source,omitempty"`
+	ExternalOrderID string `json:"external_order_id,omitempty"`
+	// AppliedPromotions 為結帳時依疊加規則套用的優惠
+	AppliedPromotions []*AppliedPromotion `json:"applied_promotions,omitempty"`
+	// Channel 為下單的銷售通路，購物車未設定通路時為空字串
+	Channel string `json:"channel,omitempty"`
+	// CartSnapshot 為結帳當下購物車的快照，非由購物車建立的訂單為 nil
+	CartSnapshot *CartSnapshot `json:"cart_snapshot,omitempty"`
+}
+
+// OrderFilter 匯出與報表查詢訂單的條件，零值的欄位不做篩選；CreatedTo 不包含該時間點
+type OrderFilter struct {
+	CustomerID  string             `json:"customer_id,omitempty"`
+	Statuses    []enum.OrderStatus `json:"statuses,omitempty"`
+	CreatedFrom time.Time          `json:"created_from,omitempty"`
+	CreatedTo   time.Time          `json:"created_to,omitempty"`
+}
+
+// ReportingAmounts 訂單金額換算成報表幣別的快照，ExchangeRate 為 1 單位訂單幣別等於多少報表幣別
+type ReportingAmounts struct {
+	Currency     stripe.Currency `json:"currency"`
+	ExchangeRate float64         `json:"exchange_rate"`
+	Subtotal     float64         `json:"subtotal"`
+	Tax          float64         `json:"tax"`
+	Discount     float64         `json:"discount"`
+	Total        float64         `json:"total"`
 }
 
 // OrderItem 代表訂單中的單個商品項目
@@ -43,47 +85,64 @@ type OrderItem struct {
 	Quantity  uint64  `json:"quantity"`
 	UnitPrice float64 `json:"unit_price"`
 	Subtotal  float64 `json:"subtotal"`
+	TaxRate   float64 `json:"tax_rate"`
+	TaxAmount float64 `json:"tax_amount"`
+	Location  string  `json:"location,omitempty"`
+
+	// 下單當時的商品資訊快照
+	ProductName string `json:"product_name,omitempty"`
+	SKU         string `json:"sku,omitempty"`
+	ImageURL    string `json:"image_url,omitempty"`
+	TaxClass    string `json:"tax_class,omitempty"`
+
+	// 單件商品的重量（公克）、尺寸（公釐）與報關資訊，未提供時為零值
+	WeightGrams   uint64 `json:"weight_grams,omitempty"`
+	LengthMM      uint64 `json:"length_mm,omitempty"`
+	WidthMM       uint64 `json:"width_mm,omitempty"`
+	HeightMM      uint64 `json:"height_mm,omitempty"`
+	HSCode        string `json:"hs_code,omitempty"`
+	OriginCountry string `json:"origin_country,omitempty"`
+
+	// Customization 為結帳時購物車項目的客製化內容，揀貨與裝箱時需依此處理
+	Customization json.RawMessage `json:"customization,omitempty"`
+	// Note、Metadata 為結帳時購物車項目的備註與自訂屬性，揀貨與出貨時一併參考
+	Note     string            `json:"note,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+
+	// 已出貨與已退回的數量，FulfillmentStatus 由兩者決定
+	ShippedQuantity   uint64                          `json:"shipped_quantity"`
+	ReturnedQuantity  uint64                          `json:"returned_quantity"`
+	FulfillmentStatus enum.OrderItemFulfillmentStatus `json:"fulfillment_status"`
 }
 
-var AllowedTransitions = map[enum.OrderStatus][]enum.OrderStatus{
-	enum.OrderStatusPending: {
-		enum.OrderStatusPaid,
-		enum.OrderStatusCancelled,
-		enum.OrderStatusFailed,
-	},
-	enum.OrderStatusPaid: {
-		enum.OrderStatusCompleted,
-		enum.OrderStatusRefunded,
-		enum.OrderStatusPartiallyRefunded,
-		enum.OrderStatusDispute,
-	},
-	enum.OrderStatusFailed: {
-		enum.OrderStatusPending, // 可能重試支付
-	},
-	enum.OrderStatusCancelled: {}, // 終止狀態
-	enum.OrderStatusRefunded:  {}, // 終止狀態
-	enum.OrderStatusPartiallyRefunded: {
-		enum.OrderStatusRefunded,
-	},
-	enum.OrderStatusDispute: {
-		enum.OrderStatusPaid,
-		enum.OrderStatusRefunded,
-	},
-	enum.OrderStatusCompleted: {}, // 終止狀態
-}
-
-func (o *Order) AllowChangeStatus(newStatus enum.OrderStatus) bool {
-	allowed, exists := AllowedTransitions[o.Status]
-	if !exists {
-		return false
-	}
+// ProductSnapshot 為下單時從商品目錄取得的商品資訊
+type ProductSnapshot struct {
+	Name     string `json:"name"`
+	SKU      string `json:"sku"`
+	ImageURL string `json:"image_url"`
+	TaxClass string `json:"tax_class"`
+	// WeightGrams 為單件重量（公克），LengthMM、WidthMM、HeightMM 為單件包裝尺寸（公釐）
+	WeightGrams uint64 `json:"weight_grams"`
+	LengthMM    uint64 `json:"length_mm"`
+	WidthMM     uint64 `json:"width_mm"`
+	HeightMM    uint64 `json:"height_mm"`
+	// HSCode 與 OriginCountry（ISO 3166-1 alpha-2）用於跨境出貨的報關資料
+	HSCode        string `json:"hs_code"`
+	OriginCountry string `json:"origin_country"`
+}
 
-	for _, status := range allowed {
-		if status == newStatus {
-			return true
-		}
-	}
-	return false
+// ApplySnapshot 將商品資訊快照寫入訂單項目
+func (oi *OrderItem) ApplySnapshot(snapshot *ProductSnapshot) {
+	oi.ProductName = snapshot.Name
+	oi.SKU = snapshot.SKU
+	oi.ImageURL = snapshot.ImageURL
+	oi.TaxClass = snapshot.TaxClass
+	oi.WeightGrams = snapshot.WeightGrams
+	oi.LengthMM = snapshot.LengthMM
+	oi.WidthMM = snapshot.WidthMM
+	oi.HeightMM = snapshot.HeightMM
+	oi.HSCode = snapshot.HSCode
+	oi.OriginCountry = snapshot.OriginCountry
 }
 
 func (o *Order) CanCancel() bool {
@@ -98,6 +157,11 @@ func (o *Order) CanCancel() bool {
 	}
 }
 
+// RequiresShipping 門市自取的訂單不需要計算運費及寄送地址
+func (o *Order) RequiresShipping() bool {
+	return o.FulfillmentType != enum.FulfillmentTypePickup
+}
+
 func (o *Order) Validate() error {
 	if o.CustomerID == "" {
 		return errors.New("customer ID is required")
@@ -154,6 +218,7 @@ func (o *Order) ConvertSqlcOrder(sqlcOrder any) *Order {
 	switch sp := sqlcOrder.(type) {
 	case *sqlc.Order:
 		o.ID = uint64(sp.ID)
+		o.OrderNumber = sp.OrderNumber
 		o.CustomerID = sp.CustomerID
 		o.CartID = &sp.CartID
 		o.Status = enum.OrderStatus(sp.Status)
@@ -176,10 +241,35 @@ func (o *Order) ConvertSqlcOrder(sqlcOrder any) *Order {
 		}
 		o.ShippingAddress = sp.ShippingAddress
 		o.BillingAddress = sp.BillingAddress
+		o.FulfillmentType = enum.FulfillmentType(sp.FulfillmentType)
+		if sp.PickupLocation != nil {
+			o.PickupLocation = *sp.PickupLocation
+		}
 		o.CreatedAt = sp.CreatedAt.Time
 		o.UpdatedAt = sp.UpdatedAt.Time
+		o.Metadata = parseMetadata(sp.Metadata)
+		if sp.TaxCalculationID != nil {
+			o.TaxCalculationID = *sp.TaxCalculationID
+		}
+		if sp.TaxTransactionID != nil {
+			o.TaxTransactionID = *sp.TaxTransactionID
+		}
+		o.Reporting = reportingAmounts(sp.ReportingCurrency, sp.ExchangeRate, sp.ReportingSubtotal, sp.ReportingTax, sp.ReportingDiscount, sp.ReportingTotal)
+		o.EstimatedDelivery = deliveryWindow(sp.EstimatedDeliveryEarliest, sp.EstimatedDeliveryLatest)
+		if sp.ExternalSource != nil && sp.ExternalOrderID != nil {
+			o.ExternalSource, o.ExternalOrderID = *sp.ExternalSource, *sp.ExternalOrderID
+		}
+		o.AppliedPromotions = appliedPromotions(sp.AppliedPromotions)
+		if sp.DeletedAt.Valid {
+			o.DeletedAt = &sp.DeletedAt.Time
+		}
+		if sp.Channel != nil {
+			o.Channel = *sp.Channel
+		}
+		o.CartSnapshot = cartSnapshot(sp.CartSnapshot)
 	case *sqlc.ListOrdersRow:
 		o.ID = uint64(sp.ID)
+		o.OrderNumber = sp.OrderNumber
 		o.CustomerID = sp.CustomerID
 		o.CartID = &sp.CartID
 		o.Status = enum.OrderStatus(sp.Status)
@@ -192,15 +282,209 @@ func (o *Order) ConvertSqlcOrder(sqlcOrder any) *Order {
 		o.UpdatedAt = sp.UpdatedAt.Time
 	case *sqlc.GetOrderRow:
 		o.ID = uint64(sp.ID)
+		o.OrderNumber = sp.OrderNumber
+		o.CustomerID = sp.CustomerID
+		o.CartID = &sp.CartID
+		o.Status = enum.OrderStatus(sp.Status)
+		o.Currency = stripe.Currency(sp.Currency)
+		o.Subtotal = sp.Subtotal
+		o.Tax = sp.Tax
+		o.Discount = sp.Discount
+		o.Total = sp.Total
+		o.ShippingAddress = sp.ShippingAddress
+		o.BillingAddress = sp.BillingAddress
+		o.FulfillmentType = enum.FulfillmentType(sp.FulfillmentType)
+		if sp.PickupLocation != nil {
+			o.PickupLocation = *sp.PickupLocation
+		}
+		o.CreatedAt = sp.CreatedAt.Time
+		o.UpdatedAt = sp.UpdatedAt.Time
+		o.Metadata = parseMetadata(sp.Metadata)
+		o.Reporting = reportingAmounts(sp.ReportingCurrency, sp.ExchangeRate, sp.ReportingSubtotal, sp.ReportingTax, sp.ReportingDiscount, sp.ReportingTotal)
+		o.EstimatedDelivery = deliveryWindow(sp.EstimatedDeliveryEarliest, sp.EstimatedDeliveryLatest)
+		if sp.ExternalSource != nil && sp.ExternalOrderID != nil {
+			o.ExternalSource, o.ExternalOrderID = *sp.ExternalSource, *sp.ExternalOrderID
+		}
+		o.AppliedPromotions = appliedPromotions(sp.AppliedPromotions)
+		if sp.DeletedAt.Valid {
+			o.DeletedAt = &sp.DeletedAt.Time
+		}
+		if sp.Channel != nil {
+			o.Channel = *sp.Channel
+		}
+		o.CartSnapshot = cartSnapshot(sp.CartSnapshot)
+	case *sqlc.FindOrdersByMetadataRow:
+		o.ID = uint64(sp.ID)
+		o.OrderNumber = sp.OrderNumber
+		o.CustomerID = sp.CustomerID
+		o.CartID = &sp.CartID
+		o.Status = enum.OrderStatus(sp.Status)
+		o.Currency = stripe.Currency(sp.Currency)
+		o.Subtotal = sp.Subtotal
+		o.Tax = sp.Tax
+		o.Discount = sp.Discount
+		o.Total = sp.Total
+		o.FulfillmentType = enum.FulfillmentType(sp.FulfillmentType)
+		if sp.PickupLocation != nil {
+			o.PickupLocation = *sp.PickupLocation
+		}
+		o.CreatedAt = sp.CreatedAt.Time
+		o.UpdatedAt = sp.UpdatedAt.Time
+		o.Metadata = parseMetadata(sp.Metadata)
+	case *sqlc.GetArchivedOrderRow:
+		o.ID = uint64(sp.ID)
+		o.OrderNumber = sp.OrderNumber
+		o.CustomerID = sp.CustomerID
+		o.CartID = &sp.CartID
+		o.Status = enum.OrderStatus(sp.Status)
+		o.Currency = stripe.Currency(sp.Currency)
+		o.Subtotal = sp.Subtotal
+		o.Tax = sp.Tax
+		o.Discount = sp.Discount
+		o.Total = sp.Total
+		o.ShippingAddress = sp.ShippingAddress
+		o.BillingAddress = sp.BillingAddress
+		o.FulfillmentType = enum.FulfillmentType(sp.FulfillmentType)
+		if sp.PickupLocation != nil {
+			o.PickupLocation = *sp.PickupLocation
+		}
+		o.CreatedAt = sp.CreatedAt.Time
+		o.UpdatedAt = sp.UpdatedAt.Time
+		o.Metadata = parseMetadata(sp.Metadata)
+		o.Reporting = reportingAmounts(sp.ReportingCurrency, sp.ExchangeRate, sp.ReportingSubtotal, sp.ReportingTax, sp.ReportingDiscount, sp.ReportingTotal)
+		o.EstimatedDelivery = deliveryWindow(sp.EstimatedDeliveryEarliest, sp.EstimatedDeliveryLatest)
+		if sp.ExternalSource != nil && sp.ExternalOrderID != nil {
+			o.ExternalSource, o.ExternalOrderID = *sp.ExternalSource, *sp.ExternalOrderID
+		}
+		o.AppliedPromotions = appliedPromotions(sp.AppliedPromotions)
+		archivedAt := sp.ArchivedAt.Time
+		o.ArchivedAt = &archivedAt
+		if sp.Channel != nil {
+			o.Channel = *sp.Channel
+		}
+		o.CartSnapshot = cartSnapshot(sp.CartSnapshot)
+	case *sqlc.GetOrderByPaymentIntentIDRow:
+		o.ID = uint64(sp.ID)
+		o.OrderNumber = sp.OrderNumber
+		o.CustomerID = sp.CustomerID
+		o.CartID = &sp.CartID
+		o.Status = enum.OrderStatus(sp.Status)
+		o.Currency = stripe.Currency(sp.Currency)
+		o.Subtotal = sp.Subtotal
+		o.Tax = sp.Tax
+		o.Discount = sp.Discount
+		o.Total = sp.Total
+		o.CreatedAt = sp.CreatedAt.Time
+		o.UpdatedAt = sp.UpdatedAt.Time
+	case *sqlc.GetOrderByRefundIDRow:
+		o.ID = uint64(sp.ID)
+		o.OrderNumber = sp.OrderNumber
+		o.CustomerID = sp.CustomerID
+		o.CartID = &sp.CartID
+		o.Status = enum.OrderStatus(sp.Status)
+		o.Currency = stripe.Currency(sp.Currency)
+		o.Subtotal = sp.Subtotal
+		o.Tax = sp.Tax
+		o.Discount = sp.Discount
+		o.Total = sp.Total
+		o.CreatedAt = sp.CreatedAt.Time
+		o.UpdatedAt = sp.UpdatedAt.Time
+	case *sqlc.GetOrderByInvoiceIDRow:
+		o.ID = uint64(sp.ID)
+		o.OrderNumber = sp.OrderNumber
+		o.CustomerID = sp.CustomerID
+		o.CartID = &sp.CartID
+		o.Status = enum.OrderStatus(sp.Status)
+		o.Currency = stripe.Currency(sp.Currency)
+		o.Subtotal = sp.Subtotal
+		o.Tax = sp.Tax
+		o.Discount = sp.Discount
+		o.Total = sp.Total
+		o.CreatedAt = sp.CreatedAt.Time
 		o.UpdatedAt = sp.UpdatedAt.Time
 	case *sqlc.GetOrderByCustomerIDAndSubscriptionIDRow:
 		o.ID = uint64(sp.ID)
+		o.OrderNumber = sp.OrderNumber
+		o.CustomerID = sp.CustomerID
+		o.CartID = &sp.CartID
+		o.Status = enum.OrderStatus(sp.Status)
+		o.Currency = stripe.Currency(sp.Currency)
+		o.Subtotal = sp.Subtotal
+		o.Tax = sp.Tax
+		o.Discount = sp.Discount
+		o.Total = sp.Total
 		o.CreatedAt = sp.CreatedAt.Time
 		o.UpdatedAt = sp.UpdatedAt.Time
 	}
 	return o
 }
 
+// reportingAmounts 組合報表幣別的金額快照，沒有匯率快照時回傳 nil
+func reportingAmounts(currency sqlc.NullCurrency, exchangeRate, subtotal, tax, discount, total *float64) *ReportingAmounts {
+	if !currency.Valid || exchangeRate == nil {
+		return nil
+	}
+
+	amounts := &ReportingAmounts{
+		Currency:     stripe.Currency(currency.Currency),
+		ExchangeRate: *exchangeRate,
+	}
+	if subtotal != nil {
+		amounts.Subtotal = *subtotal
+	}
+	if tax != nil {
+		amounts.Tax = *tax
+	}
+	if discount != nil {
+		amounts.Discount = *discount
+	}
+	if total != nil {
+		amounts.Total = *total
+	}
+
+	return amounts
+}
+
+// deliveryWindow 組合預估送達日期區間，沒有估算時回傳 nil
+func deliveryWindow(earliest, latest pgtype.Date) *DeliveryWindow {
+	if !earliest.Valid || !latest.Valid {
+		return nil
+	}
+	return &DeliveryWindow{
+		Earliest: earliest.Time,
+		Latest:   latest.Time,
+	}
+}
+
+// parseMetadata 解析訂單或項目的 JSONB metadata，非字串值會被忽略
+func parseMetadata(raw []byte) map[string]string {
+	if len(raw) == 0 {
+		return nil
+	}
+
+	var values map[string]any
+	if err := json.Unmarshal(raw, &values); err != nil || len(values) == 0 {
+		return nil
+	}
+
+	metadata := make(map[string]string, len(values))
+	for key, value := range values {
+		if s, ok := value.(string); ok {
+			metadata[key] = s
+		}
+	}
+	return metadata
+}
+
+// MarshalMetadata 將 metadata 轉為寫入 JSONB 欄位的內容，沒有任何鍵值時回傳 nil（寫入 NULL）
+func MarshalMetadata(metadata map[string]string) []byte {
+	if len(metadata) == 0 {
+		return nil
+	}
+	raw, _ := json.Marshal(metadata)
+	return raw
+}
+
 func (oi *OrderItem) ConvertSqlcOrderItem(sqlcOrderItem any) *OrderItem {
 
 	switch sp := sqlcOrderItem.(type) {
@@ -213,6 +497,40 @@ func (oi *OrderItem) ConvertSqlcOrderItem(sqlcOrderItem any) *OrderItem {
 		oi.Quantity = sp.Quantity
 		oi.UnitPrice = sp.UnitPrice
 		oi.Subtotal = sp.Subtotal
+		oi.TaxRate = sp.TaxRate
+		oi.TaxAmount = sp.TaxAmount
+		if sp.Location != nil {
+			oi.Location = *sp.Location
+		}
+		if sp.ProductName != nil {
+			oi.ProductName = *sp.ProductName
+		}
+		if sp.Sku != nil {
+			oi.SKU = *sp.Sku
+		}
+		if sp.ImageUrl != nil {
+			oi.ImageURL = *sp.ImageUrl
+		}
+		if sp.TaxClass != nil {
+			oi.TaxClass = *sp.TaxClass
+		}
+		oi.WeightGrams = uint64Value(sp.WeightGrams)
+		oi.LengthMM = uint64Value(sp.LengthMm)
+		oi.WidthMM = uint64Value(sp.WidthMm)
+		oi.HeightMM = uint64Value(sp.HeightMm)
+		if sp.HsCode != nil {
+			oi.HSCode = *sp.HsCode
+		}
+		if sp.OriginCountry != nil {
+			oi.OriginCountry = *sp.OriginCountry
+		}
+		oi.Customization = sp.Customization
+		if sp.Note != nil {
+			oi.Note = *sp.Note
+		}
+		oi.Metadata = parseMetadata(sp.Metadata)
+		oi.ShippedQuantity = sp.ShippedQuantity
+		oi.ReturnedQuantity = sp.ReturnedQuantity
 	case *sqlc.ListOrderItemsRow:
 		oi.ID = uint64(sp.ID)
 		oi.OrderID = uint64(sp.OrderID)
@@ -221,6 +539,109 @@ func (oi *OrderItem) ConvertSqlcOrderItem(sqlcOrderItem any) *OrderItem {
 		oi.StockID = sp.StockID
 		oi.Quantity = sp.Quantity
 		oi.UnitPrice = sp.UnitPrice
+		oi.Subtotal = sp.Subtotal
+		oi.TaxRate = sp.TaxRate
+		oi.TaxAmount = sp.TaxAmount
+		if sp.Location != nil {
+			oi.Location = *sp.Location
+		}
+		if sp.ProductName != nil {
+			oi.ProductName = *sp.ProductName
+		}
+		if sp.Sku != nil {
+			oi.SKU = *sp.Sku
+		}
+		if sp.ImageUrl != nil {
+			oi.ImageURL = *sp.ImageUrl
+		}
+		if sp.TaxClass != nil {
+			oi.TaxClass = *sp.TaxClass
+		}
+		oi.WeightGrams = uint64Value(sp.WeightGrams)
+		oi.LengthMM = uint64Value(sp.LengthMm)
+		oi.WidthMM = uint64Value(sp.WidthMm)
+		oi.HeightMM = uint64Value(sp.HeightMm)
+		if sp.HsCode != nil {
+			oi.HSCode = *sp.HsCode
+		}
+		if sp.OriginCountry != nil {
+			oi.OriginCountry = *sp.OriginCountry
+		}
+		oi.Customization = sp.Customization
+		if sp.Note != nil {
+			oi.Note = *sp.Note
+		}
+		oi.Metadata = parseMetadata(sp.Metadata)
+		oi.ShippedQuantity = sp.ShippedQuantity
+		oi.ReturnedQuantity = sp.ReturnedQuantity
+	case *sqlc.ListArchivedOrderItemsRow:
+		oi.ID = uint64(sp.ID)
+		oi.OrderID = uint64(sp.OrderID)
+		oi.ProductID = sp.ProductID
+		oi.PriceID = sp.PriceID
+		oi.StockID = sp.StockID
+		oi.Quantity = sp.Quantity
+		oi.UnitPrice = sp.UnitPrice
+		oi.Subtotal = sp.Subtotal
+		oi.TaxRate = sp.TaxRate
+		oi.TaxAmount = sp.TaxAmount
+		if sp.Location != nil {
+			oi.Location = *sp.Location
+		}
+		if sp.ProductName != nil {
+			oi.ProductName = *sp.ProductName
+		}
+		if sp.Sku != nil {
+			oi.SKU = *sp.Sku
+		}
+		if sp.ImageUrl != nil {
+			oi.ImageURL = *sp.ImageUrl
+		}
+		if sp.TaxClass != nil {
+			oi.TaxClass = *sp.TaxClass
+		}
+		oi.WeightGrams = uint64Value(sp.WeightGrams)
+		oi.LengthMM = uint64Value(sp.LengthMm)
+		oi.WidthMM = uint64Value(sp.WidthMm)
+		oi.HeightMM = uint64Value(sp.HeightMm)
+		if sp.HsCode != nil {
+			oi.HSCode = *sp.HsCode
+		}
+		if sp.OriginCountry != nil {
+			oi.OriginCountry = *sp.OriginCountry
+		}
+		oi.Customization = sp.Customization
+		if sp.Note != nil {
+			oi.Note = *sp.Note
+		}
+		oi.Metadata = parseMetadata(sp.Metadata)
+		oi.ShippedQuantity = sp.ShippedQuantity
+		oi.ReturnedQuantity = sp.ReturnedQuantity
 	}
+	oi.FulfillmentStatus = oi.fulfillmentStatus()
 	return oi
 }
+
+// fulfillmentStatus 依出貨與退貨數量決定項目的履約狀態，有退貨時以退貨狀態為準
+func (oi *OrderItem) fulfillmentStatus() enum.OrderItemFulfillmentStatus {
+	switch {
+	case oi.ReturnedQuantity > 0 && oi.ReturnedQuantity >= oi.ShippedQuantity:
+		return enum.OrderItemFulfillmentStatusReturned
+	case oi.ReturnedQuantity > 0:
+		return enum.OrderItemFulfillmentStatusPartiallyReturned
+	case oi.ShippedQuantity > 0 && oi.ShippedQuantity >= oi.Quantity:
+		return enum.OrderItemFulfillmentStatusShipped
+	case oi.ShippedQuantity > 0:
+		return enum.OrderItemFulfillmentStatusPartiallyShipped
+	default:
+		return enum.OrderItemFulfillmentStatusUnfulfilled
+	}
+}
+
+// uint64Value 將可為 NULL 的整數欄位轉為 uint64，NULL 時為 0
+func uint64Value(v *int32) uint64 {
+	if v == nil {
+		return 0
+	}
+	return uint64(*v)
+}
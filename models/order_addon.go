@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"gofalre.io/shop/models/enum"
+	"gofalre.io/shop/sqlc"
+)
+
+// OrderAddon 訂單的加購服務，不佔用庫存，金額與稅額計入訂單；OrderItemID 為 nil 表示整筆訂單的服務
+type OrderAddon struct {
+	ID          uint64              `json:"id"`
+	OrderID     uint64              `json:"order_id"`
+	OrderItemID *uint64             `json:"order_item_id,omitempty"`
+	Type        enum.OrderAddonType `json:"type"`
+	Description string              `json:"description,omitempty"`
+	Quantity    uint64              `json:"quantity"`
+	UnitPrice   float64             `json:"unit_price"`
+	Subtotal    float64             `json:"subtotal"`
+	TaxRate     float64             `json:"tax_rate"`
+	TaxAmount   float64             `json:"tax_amount"`
+	CreatedAt   time.Time           `json:"created_at"`
+}
+
+func (a *OrderAddon) ConvertSqlcOrderAddon(sqlcAddon any) *OrderAddon {
+
+	switch sp := sqlcAddon.(type) {
+	case *sqlc.OrderAddon:
+		a.ID = uint64(sp.ID)
+		a.OrderID = uint64(sp.OrderID)
+		if sp.OrderItemID != nil {
+			orderItemID := uint64(*sp.OrderItemID)
+			a.OrderItemID = &orderItemID
+		}
+		a.Type = enum.OrderAddonType(sp.Type)
+		if sp.Description != nil {
+			a.Description = *sp.Description
+		}
+		a.Quantity = sp.Quantity
+		a.UnitPrice = sp.UnitPrice
+		a.Subtotal = sp.Subtotal
+		a.TaxRate = sp.TaxRate
+		a.TaxAmount = sp.TaxAmount
+		a.CreatedAt = sp.CreatedAt.Time
+	default:
+		return nil
+	}
+
+	return a
+}
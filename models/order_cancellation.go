@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+
+	"gofalre.io/shop/models/enum"
+	"gofalre.io/shop/sqlc"
+)
+
+// OrderCancellationRequest 客戶自助取消訂單的申請，Status 為 auto_cancelled 表示申請時已直接取消；
+// ReviewedBy 在人員審核後寫入
+type OrderCancellationRequest struct {
+	ID         uint64                         `json:"id"`
+	OrderID    uint64                         `json:"order_id"`
+	CustomerID string                         `json:"customer_id"`
+	Reason     enum.CancellationReason        `json:"reason"`
+	Status     enum.CancellationRequestStatus `json:"status"`
+	ReviewedBy string                         `json:"reviewed_by,omitempty"`
+	CreatedAt  time.Time                      `json:"created_at"`
+	UpdatedAt  time.Time                      `json:"updated_at"`
+}
+
+// CancellationReasonStats 一段期間內單一取消原因的申請數，Cancelled 包含直接取消與人員核准的申請
+type CancellationReasonStats struct {
+	Reason    enum.CancellationReason `json:"reason"`
+	Requests  uint64                  `json:"requests"`
+	Cancelled uint64                  `json:"cancelled"`
+	Pending   uint64                  `json:"pending"`
+	Rejected  uint64                  `json:"rejected"`
+}
+
+func (r *OrderCancellationRequest) ConvertSqlcOrderCancellationRequest(sqlcRequest any) *OrderCancellationRequest {
+
+	switch sp := sqlcRequest.(type) {
+	case *sqlc.OrderCancellationRequest:
+		r.ID = uint64(sp.ID)
+		r.OrderID = uint64(sp.OrderID)
+		r.CustomerID = sp.CustomerID
+		r.Reason = enum.CancellationReason(sp.Reason)
+		r.Status = enum.CancellationRequestStatus(sp.Status)
+		if sp.ReviewedBy != nil {
+			r.ReviewedBy = *sp.ReviewedBy
+		}
+		r.CreatedAt = sp.CreatedAt.Time
+		r.UpdatedAt = sp.UpdatedAt.Time
+	default:
+		return nil
+	}
+
+	return r
+}
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"time"
+
+	"gofalre.io/shop/models/enum"
+	"gofalre.io/shop/sqlc"
+)
+
+// OrderFulfillmentSLA 訂單付款後到出貨（門市自取為備妥）的履約時限，WarnAt 起列為有違反風險，DueAt 為期限；
+// FulfilledAt 為 nil 表示尚未出貨，AtRiskAlertedAt 與 BreachAlertedAt 為送出警示的時間。
+// OrderNumber、CustomerID、Status 與 FulfillmentType 只在列出有風險的訂單時提供
+type OrderFulfillmentSLA struct {
+	OrderID         uint64               `json:"order_id"`
+	OrderNumber     string               `json:"order_number,omitempty"`
+	CustomerID      string               `json:"customer_id,omitempty"`
+	Status          enum.OrderStatus     `json:"status,omitempty"`
+	FulfillmentType enum.FulfillmentType `json:"fulfillment_type,omitempty"`
+	PaidAt          time.Time            `json:"paid_at"`
+	WarnAt          time.Time            `json:"warn_at"`
+	DueAt           time.Time            `json:"due_at"`
+	FulfilledAt     *time.Time           `json:"fulfilled_at,omitempty"`
+	AtRiskAlertedAt *time.Time           `json:"at_risk_alerted_at,omitempty"`
+	BreachAlertedAt *time.Time           `json:"breach_alerted_at,omitempty"`
+}
+
+// Elapsed 回傳付款到出貨的時間，尚未出貨時為付款到 now 的時間
+func (s *OrderFulfillmentSLA) Elapsed(now time.Time) time.Duration {
+	if s.FulfilledAt != nil {
+		return s.FulfilledAt.Sub(s.PaidAt)
+	}
+	return now.Sub(s.PaidAt)
+}
+
+// Breached 回傳訂單是否超過期限才出貨，或到 now 仍未出貨且已超過期限
+func (s *OrderFulfillmentSLA) Breached(now time.Time) bool {
+	if s.FulfilledAt != nil {
+		return s.FulfilledAt.After(s.DueAt)
+	}
+	return now.After(s.DueAt)
+}
+
+func (s *OrderFulfillmentSLA) ConvertSqlcOrderFulfillmentSLA(sqlcSLA any) *OrderFulfillmentSLA {
+
+	switch sp := sqlcSLA.(type) {
+	case *sqlc.OrderFulfillmentSla:
+		s.OrderID = uint64(sp.OrderID)
+		s.PaidAt = sp.PaidAt.Time
+		s.WarnAt = sp.WarnAt.Time
+		s.DueAt = sp.DueAt.Time
+		if sp.FulfilledAt.Valid {
+			s.FulfilledAt = &sp.FulfilledAt.Time
+		}
+		if sp.AtRiskAlertedAt.Valid {
+			s.AtRiskAlertedAt = &sp.AtRiskAlertedAt.Time
+		}
+		if sp.BreachAlertedAt.Valid {
+			s.BreachAlertedAt = &sp.BreachAlertedAt.Time
+		}
+	case *sqlc.ListOrdersAtRiskOfSLABreachRow:
+		s.OrderID = uint64(sp.OrderID)
+		s.OrderNumber = sp.OrderNumber
+		s.CustomerID = sp.CustomerID
+		s.Status = enum.OrderStatus(sp.Status)
+		s.FulfillmentType = enum.FulfillmentType(sp.FulfillmentType)
+		s.PaidAt = sp.PaidAt.Time
+		s.WarnAt = sp.WarnAt.Time
+		s.DueAt = sp.DueAt.Time
+		if sp.AtRiskAlertedAt.Valid {
+			s.AtRiskAlertedAt = &sp.AtRiskAlertedAt.Time
+		}
+		if sp.BreachAlertedAt.Valid {
+			s.BreachAlertedAt = &sp.BreachAlertedAt.Time
+		}
+	default:
+		return nil
+	}
+
+	return s
+}
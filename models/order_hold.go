@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"gofalre.io/shop/models/enum"
+	"gofalre.io/shop/sqlc"
+)
+
+// OrderHold 人員暫停訂單履約的記錄，PreviousStatus 為暫停前的狀態，ReleasedAt 為 nil 表示仍在暫停中
+type OrderHold struct {
+	ID             uint64           `json:"id"`
+	OrderID        uint64           `json:"order_id"`
+	Reason         string           `json:"reason"`
+	PreviousStatus enum.OrderStatus `json:"previous_status"`
+	CreatedAt      time.Time        `json:"created_at"`
+	ReleasedAt     *time.Time       `json:"released_at,omitempty"`
+}
+
+func (h *OrderHold) ConvertSqlcOrderHold(sqlcHold any) *OrderHold {
+
+	switch sp := sqlcHold.(type) {
+	case *sqlc.OrderHold:
+		h.ID = uint64(sp.ID)
+		h.OrderID = uint64(sp.OrderID)
+		h.Reason = sp.Reason
+		h.PreviousStatus = enum.OrderStatus(sp.PreviousStatus)
+		h.CreatedAt = sp.CreatedAt.Time
+		if sp.ReleasedAt.Valid {
+			h.ReleasedAt = &sp.ReleasedAt.Time
+		}
+	default:
+		return nil
+	}
+
+	return h
+}
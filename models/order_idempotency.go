@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"gofalre.io/shop/sqlc"
+)
+
+// OrderIdempotencyKey 記錄手動建立訂單時使用的 idempotency key，OrderID 為 nil 表示建立中的交易尚未完成
+type OrderIdempotencyKey struct {
+	Key         string    `json:"key"`
+	RequestHash string    `json:"request_hash"`
+	OrderID     *uint64   `json:"order_id,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func (k *OrderIdempotencyKey) ConvertSqlcOrderIdempotencyKey(sqlcKey any) *OrderIdempotencyKey {
+
+	switch sp := sqlcKey.(type) {
+	case *sqlc.OrderIdempotencyKey:
+		k.Key = sp.IdempotencyKey
+		k.RequestHash = sp.RequestHash
+		if sp.OrderID != nil {
+			orderID := uint64(*sp.OrderID)
+			k.OrderID = &orderID
+		}
+		k.CreatedAt = sp.CreatedAt.Time
+	default:
+		return nil
+	}
+
+	return k
+}
@@ -0,0 +1,15 @@
+package models
+
+import "gofalre.io/shop/models/enum"
+
+// OrderImportResult 為匯入單筆外部訂單的結果，Index 為該訂單在匯入清單中的位置；
+// 重複匯入時 OrderID 與 OrderNumber 為先前建立的訂單
+type OrderImportResult struct {
+	Index           int                    `json:"index"`
+	ExternalSource  string                 `json:"external_source"`
+	ExternalOrderID string                 `json:"external_order_id"`
+	Status          enum.OrderImportStatus `json:"status"`
+	OrderID         uint64                 `json:"order_id,omitempty"`
+	OrderNumber     string                 `json:"order_number,omitempty"`
+	Error           string                 `json:"error,omitempty"`
+}
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"time"
+
+	"gofalre.io/shop/sqlc"
+)
+
+// OrderPaymentReference 為訂單在 Stripe 上的客戶、payment intent 與 invoice，沒有時為空字串
+type OrderPaymentReference struct {
+	OrderID         uint64 `json:"order_id"`
+	CustomerID      string `json:"customer_id"`
+	PaymentIntentID string `json:"payment_intent_id,omitempty"`
+	InvoiceID       string `json:"invoice_id,omitempty"`
+}
+
+// OrderReceiptLinks 為訂單付款在 Stripe 上的收據與發票網址，客服與客戶可直接開啟而不需到 Stripe 後台查詢；
+// PaymentIntentID 與 InvoiceID 為產生連結時訂單對應的付款，付款尚未完成時 ReceiptURL 為空字串
+type OrderReceiptLinks struct {
+	OrderID         uint64    `json:"order_id"`
+	PaymentIntentID string    `json:"payment_intent_id,omitempty"`
+	InvoiceID       string    `json:"invoice_id,omitempty"`
+	ReceiptURL      string    `json:"receipt_url,omitempty"`
+	InvoiceURL      string    `json:"invoice_url,omitempty"`
+	InvoicePDFURL   string    `json:"invoice_pdf_url,omitempty"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// Covers 判斷連結是否為 ref 目前的付款產生，且付款與發票的連結都已取得
+func (l *OrderReceiptLinks) Covers(ref *OrderPaymentReference) bool {
+	if l.PaymentIntentID != ref.PaymentIntentID || l.InvoiceID != ref.InvoiceID {
+		return false
+	}
+	if ref.PaymentIntentID != "" && l.ReceiptURL == "" {
+		return false
+	}
+	if ref.InvoiceID != "" && l.InvoiceURL == "" {
+		return false
+	}
+	return true
+}
+
+func (r *OrderPaymentReference) ConvertSqlcOrderPaymentReference(orderID uint64, sqlcReference any) *OrderPaymentReference {
+
+	switch sp := sqlcReference.(type) {
+	case *sqlc.GetOrderPaymentReferenceRow:
+		r.OrderID = orderID
+		r.CustomerID = sp.CustomerID
+		if sp.PaymentIntentID != nil {
+			r.PaymentIntentID = *sp.PaymentIntentID
+		}
+		if sp.InvoiceID != nil {
+			r.InvoiceID = *sp.InvoiceID
+		}
+	default:
+		return nil
+	}
+
+	return r
+}
+
+func (l *OrderReceiptLinks) ConvertSqlcOrderReceiptLinks(sqlcLinks any) *OrderReceiptLinks {
+
+	switch sp := sqlcLinks.(type) {
+	case *sqlc.OrderReceiptLink:
+		l.OrderID = uint64(sp.OrderID)
+		if sp.PaymentIntentID != nil {
+			l.PaymentIntentID = *sp.PaymentIntentID
+		}
+		if sp.InvoiceID != nil {
+			l.InvoiceID = *sp.InvoiceID
+		}
+		if sp.ReceiptUrl != nil {
+			l.ReceiptURL = *sp.ReceiptUrl
+		}
+		if sp.InvoiceUrl != nil {
+			l.InvoiceURL = *sp.InvoiceUrl
+		}
+		if sp.InvoicePdfUrl != nil {
+			l.InvoicePDFURL = *sp.InvoicePdfUrl
+		}
+		l.UpdatedAt = sp.UpdatedAt.Time
+	default:
+		return nil
+	}
+
+	return l
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"gofalre.io/shop/models/enum"
+	"gofalre.io/shop/sqlc"
+)
+
+// OrderRepricing 待付款訂單因價格變動重新計價的記錄，PreviousTotal 與 NewTotal 為重新計價前後的訂單總額；
+// 等待審核時 NewTotal 為建立時試算的金額，核准後更新為實際套用的金額
+type OrderRepricing struct {
+	ID            uint64                    `json:"id"`
+	OrderID       uint64                    `json:"order_id"`
+	PriceChangeID uint64                    `json:"price_change_id"`
+	PriceID       string                    `json:"price_id"`
+	NewUnitPrice  float64                   `json:"new_unit_price"`
+	PreviousTotal float64                   `json:"previous_total"`
+	NewTotal      float64                   `json:"new_total"`
+	Status        enum.OrderRepricingStatus `json:"status"`
+	ReviewedBy    string                    `json:"reviewed_by,omitempty"`
+	CreatedAt     time.Time                 `json:"created_at"`
+	UpdatedAt     time.Time                 `json:"updated_at"`
+}
+
+func (r *OrderRepricing) ConvertSqlcOrderRepricing(sqlcRepricing any) *OrderRepricing {
+
+	switch sp := sqlcRepricing.(type) {
+	case *sqlc.OrderRepricing:
+		r.ID = uint64(sp.ID)
+		r.OrderID = uint64(sp.OrderID)
+		r.PriceChangeID = uint64(sp.PriceChangeID)
+		r.PriceID = sp.PriceID
+		r.NewUnitPrice = sp.NewUnitPrice
+		r.PreviousTotal = sp.PreviousTotal
+		r.NewTotal = sp.NewTotal
+		r.Status = enum.OrderRepricingStatus(sp.Status)
+		if sp.ReviewedBy != nil {
+			r.ReviewedBy = *sp.ReviewedBy
+		}
+		r.CreatedAt = sp.CreatedAt.Time
+		r.UpdatedAt = sp.UpdatedAt.Time
+	default:
+		return nil
+	}
+
+	return r
+}
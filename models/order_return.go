@@ -0,0 +1,76 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"gofalre.io/shop/models/enum"
+	"gofalre.io/shop/sqlc"
+)
+
+// OrderReturn 客戶的退貨申請，核准後向物流商取得退貨標籤；Carrier、TrackingNumber 與 LabelURL 在取得標籤前為空字串
+type OrderReturn struct {
+	ID             uint64            `json:"id"`
+	OrderID        uint64            `json:"order_id"`
+	Status         enum.ReturnStatus `json:"status"`
+	Reason         string            `json:"reason"`
+	Carrier        string            `json:"carrier,omitempty"`
+	TrackingNumber string            `json:"tracking_number,omitempty"`
+	LabelURL       string            `json:"label_url,omitempty"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+}
+
+// ReturnLabelRequest 向物流商申請退貨標籤所需的資料，ShipFrom 為客戶的地址原始 JSON，
+// 每個項目的 Location 為原本出貨的地點，即退貨寄回的地點
+type ReturnLabelRequest struct {
+	ReturnID         uint64          `json:"return_id"`
+	OrderID          uint64          `json:"order_id"`
+	OrderNumber      string          `json:"order_number"`
+	CustomerID       string          `json:"customer_id"`
+	ShipFrom         json.RawMessage `json:"ship_from"`
+	Items            []*ShippingItem `json:"items"`
+	TotalWeightGrams uint64          `json:"total_weight_grams"`
+}
+
+// ReturnLabel 物流商核發的退貨標籤
+type ReturnLabel struct {
+	Carrier        string `json:"carrier"`
+	TrackingNumber string `json:"tracking_number"`
+	LabelURL       string `json:"label_url"`
+}
+
+// ReturnTrackingEvent 物流商回報的退貨追蹤狀態，Status 只會是 in_transit 或 received，
+// 物流商的狀態代碼由各物流商的整合轉換
+type ReturnTrackingEvent struct {
+	Carrier        string            `json:"carrier"`
+	TrackingNumber string            `json:"tracking_number"`
+	Status         enum.ReturnStatus `json:"status"`
+	OccurredAt     time.Time         `json:"occurred_at"`
+}
+
+func (r *OrderReturn) ConvertSqlcOrderReturn(sqlcReturn any) *OrderReturn {
+
+	switch sp := sqlcReturn.(type) {
+	case *sqlc.OrderReturn:
+		r.ID = uint64(sp.ID)
+		r.OrderID = uint64(sp.OrderID)
+		r.Status = enum.ReturnStatus(sp.Status)
+		r.Reason = sp.Reason
+		if sp.Carrier != nil {
+			r.Carrier = *sp.Carrier
+		}
+		if sp.TrackingNumber != nil {
+			r.TrackingNumber = *sp.TrackingNumber
+		}
+		if sp.LabelUrl != nil {
+			r.LabelURL = *sp.LabelUrl
+		}
+		r.CreatedAt = sp.CreatedAt.Time
+		r.UpdatedAt = sp.UpdatedAt.Time
+	default:
+		return nil
+	}
+
+	return r
+}
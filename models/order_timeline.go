@@ -0,0 +1,15 @@
+package models
+
+import (
+	"time"
+
+	"gofalre.io/shop/models/enum"
+)
+
+// OrderTimelineEntry 訂單時間軸上的一筆事件，ReferenceID 為對應的暫停、出貨單或退款記錄 ID
+type OrderTimelineEntry struct {
+	Event       enum.OrderTimelineEvent `json:"event"`
+	At          time.Time               `json:"at"`
+	ReferenceID uint64                  `json:"reference_id,omitempty"`
+	Detail      string                  `json:"detail,omitempty"`
+}
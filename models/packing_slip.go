@@ -0,0 +1,70 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/stripe/stripe-go/v79"
+	"gofalre.io/shop/models/enum"
+)
+
+// ShippingItem 為揀貨單及物流標籤上的單行商品，重量與尺寸來自下單時的商品快照
+type ShippingItem struct {
+	OrderItemID     uint64  `json:"order_item_id"`
+	ProductID       string  `json:"product_id"`
+	ProductName     string  `json:"product_name,omitempty"`
+	SKU             string  `json:"sku,omitempty"`
+	Location        string  `json:"location"`
+	Quantity        uint64  `json:"quantity"`
+	UnitPrice       float64 `json:"unit_price"`
+	Subtotal        float64 `json:"subtotal"`
+	UnitWeightGrams uint64  `json:"unit_weight_grams,omitempty"`
+	WeightGrams     uint64  `json:"weight_grams,omitempty"`
+	LengthMM        uint64  `json:"length_mm,omitempty"`
+	WidthMM         uint64  `json:"width_mm,omitempty"`
+	HeightMM        uint64  `json:"height_mm,omitempty"`
+	HSCode          string  `json:"hs_code,omitempty"`
+	OriginCountry   string  `json:"origin_country,omitempty"`
+	// Customization 為項目的客製化內容，裝箱時需核對
+	Customization json.RawMessage `json:"customization,omitempty"`
+}
+
+// PackingSlip 為隨貨附上的裝箱單資料，列出訂單的地址、所有項目與已建立的出貨單
+type PackingSlip struct {
+	OrderID          uint64               `json:"order_id"`
+	OrderNumber      string               `json:"order_number"`
+	CustomerID       string               `json:"customer_id"`
+	Status           enum.OrderStatus     `json:"status"`
+	Currency         stripe.Currency      `json:"currency"`
+	FulfillmentType  enum.FulfillmentType `json:"fulfillment_type"`
+	PickupLocation   string               `json:"pickup_location,omitempty"`
+	ShippingAddress  json.RawMessage      `json:"shipping_address,omitempty"`
+	BillingAddress   json.RawMessage      `json:"billing_address,omitempty"`
+	Items            []*ShippingItem      `json:"items"`
+	Shipments        []*Shipment          `json:"shipments"`
+	TotalQuantity    uint64               `json:"total_quantity"`
+	TotalWeightGrams uint64               `json:"total_weight_grams"`
+	OrderedAt        time.Time            `json:"ordered_at"`
+}
+
+// NewShippingItem 由訂單項目建立出貨明細，location 為實際出貨地點
+func NewShippingItem(item *OrderItem, location string) *ShippingItem {
+	return &ShippingItem{
+		OrderItemID:     item.ID,
+		ProductID:       item.ProductID,
+		ProductName:     item.ProductName,
+		SKU:             item.SKU,
+		Location:        location,
+		Quantity:        item.Quantity,
+		UnitPrice:       item.UnitPrice,
+		Subtotal:        item.Subtotal,
+		UnitWeightGrams: item.WeightGrams,
+		WeightGrams:     item.WeightGrams * item.Quantity,
+		LengthMM:        item.LengthMM,
+		WidthMM:         item.WidthMM,
+		HeightMM:        item.HeightMM,
+		HSCode:          item.HSCode,
+		OriginCountry:   item.OriginCountry,
+		Customization:   item.Customization,
+	}
+}
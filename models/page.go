@@ -0,0 +1,27 @@
+package models
+
+// Page 為分頁列表的回傳結果，NextCursor 為下一頁的 offset，已是最後一頁時為 nil
+type Page[T any] struct {
+	Items      []T     `json:"items"`
+	TotalCount uint64  `json:"total_count"`
+	NextCursor *uint64 `json:"next_cursor,omitempty"`
+}
+
+// NewPage 依本頁的 limit、offset 與總筆數建立分頁結果
+func NewPage[T any](items []T, totalCount, limit, offset uint64) *Page[T] {
+	if items == nil {
+		items = []T{}
+	}
+
+	page := &Page[T]{
+		Items:      items,
+		TotalCount: totalCount,
+	}
+
+	next := offset + uint64(len(items))
+	if limit > 0 && len(items) > 0 && next < totalCount {
+		page.NextCursor = &next
+	}
+
+	return page
+}
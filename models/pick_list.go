@@ -0,0 +1,23 @@
+package models
+
+import "encoding/json"
+
+// PickListItem 代表揀貨單中的單個項目，依出貨地點分組
+type PickListItem struct {
+	OrderItemID uint64 `json:"order_item_id"`
+	ProductID   string `json:"product_id"`
+	StockID     uint64 `json:"stock_id"`
+	Location    string `json:"location"`
+	Quantity    uint64 `json:"quantity"`
+	// Customization 為項目的客製化內容，揀貨時需依此準備商品
+	Customization json.RawMessage `json:"customization,omitempty"`
+	// Note 為項目的備註，揀貨時一併參考
+	Note string `json:"note,omitempty"`
+}
+
+// PickList 代表某個地點需要揀貨的所有項目
+type PickList struct {
+	OrderID  uint64          `json:"order_id"`
+	Location string          `json:"location"`
+	Items    []*PickListItem `json:"items"`
+}
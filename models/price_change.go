@@ -0,0 +1,61 @@
+package models
+
+import (
+	"github.com/stripe/stripe-go/v79"
+	"gofalre.io/shop/models/enum"
+	"gofalre.io/shop/sqlc"
+	"time"
+)
+
+// PriceChange 代表一筆價格變動記錄，effective_at 之後才會生效
+type PriceChange struct {
+	ID          uint64                 `json:"id"`
+	PriceID     string                 `json:"price_id"`
+	ProductID   string                 `json:"product_id"`
+	UnitPrice   float64                `json:"unit_price"`
+	Currency    stripe.Currency        `json:"currency,omitempty"`
+	Status      enum.PriceChangeStatus `json:"status"`
+	Reason      string                 `json:"reason,omitempty"`
+	EffectiveAt time.Time              `json:"effective_at"`
+	AppliedAt   *time.Time             `json:"applied_at,omitempty"`
+	CreatedAt   time.Time              `json:"created_at"`
+}
+
+// PriceAuditLine 比對訂單項目的成交價與下單當時生效的價格
+type PriceAuditLine struct {
+	OrderItemID    uint64  `json:"order_item_id"`
+	PriceID        string  `json:"price_id"`
+	ChargedPrice   float64 `json:"charged_price"`
+	EffectivePrice float64 `json:"effective_price"`
+	// PriceChangeID 為 0 表示下單時沒有任何已生效的價格記錄
+	PriceChangeID uint64 `json:"price_change_id"`
+	Matched       bool   `json:"matched"`
+}
+
+func (pc *PriceChange) ConvertSqlcPriceChange(sqlcPriceChange any) *PriceChange {
+
+	switch sp := sqlcPriceChange.(type) {
+	case *sqlc.PriceChange:
+		pc.ID = uint64(sp.ID)
+		pc.PriceID = sp.PriceID
+		pc.ProductID = sp.ProductID
+		pc.UnitPrice = sp.UnitPrice
+		if sp.Currency.Valid {
+			pc.Currency = stripe.Currency(sp.Currency.Currency)
+		}
+		pc.Status = enum.PriceChangeStatus(sp.Status)
+		if sp.Reason != nil {
+			pc.Reason = *sp.Reason
+		}
+		pc.EffectiveAt = sp.EffectiveAt.Time
+		if sp.AppliedAt.Valid {
+			appliedAt := sp.AppliedAt.Time
+			pc.AppliedAt = &appliedAt
+		}
+		pc.CreatedAt = sp.CreatedAt.Time
+	default:
+		return nil
+	}
+
+	return pc
+}
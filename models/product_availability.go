@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"gofalre.io/shop/sqlc"
+)
+
+// ProductAvailability 商品在所有地點加總的庫存，取自定期更新的 product_availability，
+// 可能落後實際庫存至多一個更新間隔，RefreshedAt 為資料的時間；只用於顯示，預留庫存仍以 stocks 為準
+type ProductAvailability struct {
+	ProductID        string    `json:"product_id"`
+	Quantity         uint64    `json:"quantity"`
+	ReservedQuantity uint64    `json:"reserved_quantity"`
+	Available        uint64    `json:"available"`
+	Locations        uint64    `json:"locations"`
+	RefreshedAt      time.Time `json:"refreshed_at"`
+}
+
+// InStock 回傳商品是否有可售的庫存
+func (a *ProductAvailability) InStock() bool {
+	return a.Available > 0
+}
+
+func (a *ProductAvailability) ConvertSqlcProductAvailability(sqlcAvailability any) *ProductAvailability {
+
+	switch sp := sqlcAvailability.(type) {
+	case *sqlc.ProductAvailability:
+		a.ProductID = sp.ProductID
+		a.Quantity = sp.Quantity
+		a.ReservedQuantity = uint64(max(sp.ReservedQuantity, 0))
+		a.Available = uint64(max(sp.AvailableQuantity, 0))
+		a.Locations = uint64(sp.LocationCount)
+		a.RefreshedAt = sp.RefreshedAt.Time
+	default:
+		return nil
+	}
+
+	return a
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+
+	"gofalre.io/shop/models/enum"
+	"gofalre.io/shop/sqlc"
+)
+
+// ProductMedia 商品的圖片或影片，檔案存放在物件儲存；PriceID 為空表示商品共用的媒體，
+// 有值時只屬於該價格對應的變體。StorageKey 為空表示外部網址，不由 MediaStorage 管理
+type ProductMedia struct {
+	ID          uint64                `json:"id"`
+	ProductID   string                `json:"product_id"`
+	PriceID     string                `json:"price_id,omitempty"`
+	Type        enum.ProductMediaType `json:"type"`
+	URL         string                `json:"url"`
+	StorageKey  string                `json:"storage_key,omitempty"`
+	AltText     string                `json:"alt_text,omitempty"`
+	Position    uint32                `json:"position"`
+	ContentType string                `json:"content_type,omitempty"`
+	CreatedAt   time.Time             `json:"created_at"`
+	UpdatedAt   time.Time             `json:"updated_at"`
+}
+
+func (m *ProductMedia) ConvertSqlcProductMedia(sqlcMedia any) *ProductMedia {
+
+	switch sp := sqlcMedia.(type) {
+	case *sqlc.ProductMedium:
+		m.ID = uint64(sp.ID)
+		m.ProductID = sp.ProductID
+		if sp.PriceID != nil {
+			m.PriceID = *sp.PriceID
+		}
+		m.Type = enum.ProductMediaType(sp.Type)
+		m.URL = sp.Url
+		if sp.StorageKey != nil {
+			m.StorageKey = *sp.StorageKey
+		}
+		if sp.AltText != nil {
+			m.AltText = *sp.AltText
+		}
+		m.Position = uint32(sp.Position)
+		if sp.ContentType != nil {
+			m.ContentType = *sp.ContentType
+		}
+		m.CreatedAt = sp.CreatedAt.Time
+		m.UpdatedAt = sp.UpdatedAt.Time
+	default:
+		return nil
+	}
+
+	return m
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"encoding/json"
+
+	"gofalre.io/shop/models/enum"
+)
+
+// Promotion 為購物車適用的優惠，由分類折扣活動或 PromotionSource（優惠券、數量折扣等）產生。
+// Priority 較高的優惠先套用；ExclusivityGroup 相同的優惠疊加時只會套用一個，空字串表示不屬於任何群組。
+// LineDiscounts 以購物車項目 ID 為 key，為單獨套用此優惠時每個項目的折扣金額，整筆購物車的折扣須由來源分攤到各項目
+type Promotion struct {
+	Kind             enum.PromotionKind `json:"kind"`
+	Reference        string             `json:"reference"`
+	Name             string             `json:"name,omitempty"`
+	Priority         int                `json:"priority"`
+	ExclusivityGroup string             `json:"exclusivity_group,omitempty"`
+	LineDiscounts    map[uint64]float64 `json:"line_discounts"`
+}
+
+// AppliedPromotion 為依疊加規則實際套用的優惠，Discount 為受上限限制後的折扣金額；
+// 記錄在購物車與訂單上，順序即套用的順序
+type AppliedPromotion struct {
+	Kind      enum.PromotionKind `json:"kind"`
+	Reference string             `json:"reference"`
+	Name      string             `json:"name,omitempty"`
+	Discount  float64            `json:"discount"`
+}
+
+// appliedPromotions 解析購物車或訂單的 JSONB applied_promotions
+func appliedPromotions(raw []byte) []*AppliedPromotion {
+	if len(raw) == 0 {
+		return nil
+	}
+
+	var promotions []*AppliedPromotion
+	if err := json.Unmarshal(raw, &promotions); err != nil || len(promotions) == 0 {
+		return nil
+	}
+	return promotions
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// QuotaUsage 單一呼叫端自服務啟動以來的配額使用量，Remaining 為最近一次允許的請求後視窗內剩餘的次數
+type QuotaUsage struct {
+	Caller     string        `json:"caller"`
+	Limit      int           `json:"limit"`
+	Window     time.Duration `json:"window"`
+	Allowed    uint64        `json:"allowed"`
+	Rejected   uint64        `json:"rejected"`
+	Remaining  int           `json:"remaining"`
+	LastSeenAt time.Time     `json:"last_seen_at"`
+}
@@ -0,0 +1,106 @@
+package models
+
+import (
+	"time"
+
+	"github.com/stripe/stripe-go/v79"
+	"gofalre.io/shop/models/enum"
+	"gofalre.io/shop/sqlc"
+)
+
+// Refund 代表一筆已向金流服務發起的退款，PolicyVersion 為退款當時套用的退款政策版本，供稽核使用
+type Refund struct {
+	ID             uint64          `json:"id"`
+	OrderID        uint64          `json:"order_id"`
+	StripeRefundID string          `json:"stripe_refund_id"`
+	Currency       stripe.Currency `json:"currency"`
+	Amount         float64         `json:"amount"`
+	RestockingFee  float64         `json:"restocking_fee"`
+	Reason         string          `json:"reason,omitempty"`
+	PolicyVersion  string          `json:"policy_version"`
+	Items          []*RefundItem   `json:"items"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// RefundItem 退款政策對單一訂單項目或加購服務的判定，OrderItemID 與 OrderAddonID 擇一；
+// Amount 為扣除重新上架費後的退款金額，不可退款時為 0
+type RefundItem struct {
+	ID            uint64          `json:"id,omitempty"`
+	RefundID      uint64          `json:"refund_id,omitempty"`
+	OrderItemID   uint64          `json:"order_item_id,omitempty"`
+	OrderAddonID  uint64          `json:"order_addon_id,omitempty"`
+	Quantity      uint64          `json:"quantity"`
+	Amount        float64         `json:"amount"`
+	RestockingFee float64         `json:"restocking_fee"`
+	Rule          enum.RefundRule `json:"rule"`
+}
+
+// RefundEvaluation 退款政策對訂單的評估結果，Items 包含所有要求退款的項目，Amount 與 RestockingFee 只計入可退款的項目
+type RefundEvaluation struct {
+	OrderID       uint64          `json:"order_id"`
+	PolicyVersion string          `json:"policy_version"`
+	Currency      stripe.Currency `json:"currency"`
+	Amount        float64         `json:"amount"`
+	RestockingFee float64         `json:"restocking_fee"`
+	Items         []*RefundItem   `json:"items"`
+	EvaluatedAt   time.Time       `json:"evaluated_at"`
+}
+
+// Refundable 回傳項目套用的規則是否允許退款
+func (ri *RefundItem) Refundable() bool {
+	return ri.Rule == enum.RefundRuleFullRefund || ri.Rule == enum.RefundRuleRestockingFee
+}
+
+// RefundableItems 回傳評估結果中可以退款的項目
+func (re *RefundEvaluation) RefundableItems() []*RefundItem {
+	items := make([]*RefundItem, 0, len(re.Items))
+	for _, item := range re.Items {
+		if item.Refundable() {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func (r *Refund) ConvertSqlcRefund(sqlcRefund any) *Refund {
+
+	switch sp := sqlcRefund.(type) {
+	case *sqlc.Refund:
+		r.ID = uint64(sp.ID)
+		r.OrderID = uint64(sp.OrderID)
+		if sp.StripeRefundID != nil {
+			r.StripeRefundID = *sp.StripeRefundID
+		}
+		r.Currency = stripe.Currency(sp.Currency)
+		r.Amount = sp.Amount
+		r.RestockingFee = sp.RestockingFee
+		if sp.Reason != nil {
+			r.Reason = *sp.Reason
+		}
+		r.PolicyVersion = sp.PolicyVersion
+		r.CreatedAt = sp.CreatedAt.Time
+	default:
+		return nil
+	}
+
+	return r
+}
+
+func (ri *RefundItem) ConvertSqlcRefundItem(sqlcRefundItem any) *RefundItem {
+
+	switch sp := sqlcRefundItem.(type) {
+	case *sqlc.RefundItem:
+		ri.ID = uint64(sp.ID)
+		ri.RefundID = uint64(sp.RefundID)
+		ri.OrderItemID = uint64Value(sp.OrderItemID)
+		ri.OrderAddonID = uint64Value(sp.OrderAddonID)
+		ri.Quantity = sp.Quantity
+		ri.Amount = sp.Amount
+		ri.RestockingFee = sp.RestockingFee
+		ri.Rule = enum.RefundRule(sp.Rule)
+	default:
+		return nil
+	}
+
+	return ri
+}
@@ -0,0 +1,39 @@
+package models
+
+import "gofalre.io/shop/models/enum"
+
+// ReorderLine 描述再次購買時單一訂單項目的處理結果
+type ReorderLine struct {
+	OrderItemID       uint64                 `json:"order_item_id"`
+	ProductID         string                 `json:"product_id"`
+	PriceID           string                 `json:"price_id"`
+	Status            enum.ReorderLineStatus `json:"status"`
+	RequestedQuantity uint64                 `json:"requested_quantity"`
+	Quantity          uint64                 `json:"quantity"`
+	PreviousUnitPrice float64                `json:"previous_unit_price"`
+	UnitPrice         float64                `json:"unit_price"`
+	PreviousLocation  string                 `json:"previous_location,omitempty"`
+	Location          string                 `json:"location,omitempty"`
+}
+
+// PriceChanged 回傳目前售價是否與原訂單不同
+func (rl *ReorderLine) PriceChanged() bool {
+	return rl.UnitPrice != rl.PreviousUnitPrice
+}
+
+// ReorderResult 為再次購買的結果，CartID 為商品加入的購物車，沒有任何商品可加入而未建立購物車時為 0
+type ReorderResult struct {
+	CartID uint64         `json:"cart_id"`
+	Lines  []*ReorderLine `json:"lines"`
+}
+
+// Unavailable 回傳因缺貨或停售而未加入購物車的項目
+func (rr *ReorderResult) Unavailable() []*ReorderLine {
+	var lines []*ReorderLine
+	for _, line := range rr.Lines {
+		if line.Quantity == 0 {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"github.com/stripe/stripe-go/v79"
+
+	"gofalre.io/shop/models/enum"
+	"gofalre.io/shop/sqlc"
+)
+
+// RevenueEvent 營收認列的來源事件；銷售的金額取自訂單，退款的 Subtotal 與 Tax 依訂單的稅額比例自退款金額拆出，
+// Total 為實際退還的金額。已封存的訂單也包含在內
+type RevenueEvent struct {
+	Type        enum.RevenueEventType `json:"type"`
+	OrderID     uint64                `json:"order_id"`
+	OrderNumber string                `json:"order_number"`
+	RefundID    uint64                `json:"refund_id,omitempty"`
+	Currency    stripe.Currency       `json:"currency"`
+	OccurredAt  time.Time             `json:"occurred_at"`
+	Subtotal    float64               `json:"subtotal"`
+	Tax         float64               `json:"tax"`
+	Discount    float64               `json:"discount"`
+	Total       float64               `json:"total"`
+}
+
+// JournalLine 分錄中的一行，同一個 JournalID 的借方與貸方合計相等
+type JournalLine struct {
+	JournalID   string          `json:"journal_id"`
+	Date        time.Time       `json:"date"`
+	Account     string          `json:"account"`
+	Debit       float64         `json:"debit"`
+	Credit      float64         `json:"credit"`
+	Currency    stripe.Currency `json:"currency"`
+	OrderNumber string          `json:"order_number"`
+	Description string          `json:"description"`
+}
+
+func (e *RevenueEvent) ConvertSqlcRevenueEvent(sqlcEvent any) *RevenueEvent {
+
+	switch sp := sqlcEvent.(type) {
+	case *sqlc.ListRevenueEventsRow:
+		e.Type = enum.RevenueEventType(sp.Kind)
+		e.OrderID = uint64(sp.OrderID)
+		e.OrderNumber = sp.OrderNumber
+		e.RefundID = uint64(sp.RefundID)
+		e.Currency = stripe.Currency(sp.Currency)
+		e.OccurredAt = sp.OccurredAt.Time
+		e.Subtotal = sp.Subtotal
+		e.Tax = sp.Tax
+		e.Discount = sp.Discount
+		e.Total = sp.Total
+	default:
+		return nil
+	}
+
+	return e
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"github.com/stripe/stripe-go/v79"
+	"gofalre.io/shop/sqlc"
+)
+
+// SalesReport 以報表幣別彙整期間內的訂單金額，已取消、付款失敗或全額退款的訂單不列入計算；
+// 沒有匯率快照或快照使用其他報表幣別的訂單只計入 UnconvertedOrders，不計入金額
+type SalesReport struct {
+	Currency          stripe.Currency  `json:"currency"`
+	From              time.Time        `json:"from"`
+	To                time.Time        `json:"to"`
+	Orders            uint64           `json:"orders"`
+	UnconvertedOrders uint64           `json:"unconverted_orders"`
+	Subtotal          float64          `json:"subtotal"`
+	Tax               float64          `json:"tax"`
+	Discount          float64          `json:"discount"`
+	Total             float64          `json:"total"`
+	ByCurrency        []*CurrencySales `json:"by_currency"`
+	// ByChannel 為各銷售通路以訂單幣別計算的銷售額，不同幣別不換算加總
+	ByChannel []*ChannelSales `json:"by_channel"`
+}
+
+// CurrencySales 同一訂單幣別與報表幣別組合的銷售額，Total 為訂單幣別的金額，Reporting* 為換算後的金額
+type CurrencySales struct {
+	Currency          stripe.Currency `json:"currency"`
+	ReportingCurrency stripe.Currency `json:"reporting_currency,omitempty"`
+	Orders            uint64          `json:"orders"`
+	ConvertedOrders   uint64          `json:"converted_orders"`
+	Total             float64         `json:"total"`
+	ReportingSubtotal float64         `json:"reporting_subtotal"`
+	ReportingTax      float64         `json:"reporting_tax"`
+	ReportingDiscount float64         `json:"reporting_discount"`
+	ReportingTotal    float64         `json:"reporting_total"`
+}
+
+func (cs *CurrencySales) ConvertSqlcCurrencySales(sqlcCurrencySales any) *CurrencySales {
+
+	switch sp := sqlcCurrencySales.(type) {
+	case *sqlc.GetSalesReportRow:
+		cs.Currency = stripe.Currency(sp.Currency)
+		if sp.ReportingCurrency.Valid {
+			cs.ReportingCurrency = stripe.Currency(sp.ReportingCurrency.Currency)
+		}
+		cs.Orders = uint64(sp.Orders)
+		cs.ConvertedOrders = uint64(sp.ConvertedOrders)
+		cs.Total = sp.Total
+		cs.ReportingSubtotal = sp.ReportingSubtotal
+		cs.ReportingTax = sp.ReportingTax
+		cs.ReportingDiscount = sp.ReportingDiscount
+		cs.ReportingTotal = sp.ReportingTotal
+	default:
+		return nil
+	}
+
+	return cs
+}
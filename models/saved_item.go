@@ -0,0 +1,53 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/stripe/stripe-go/v79"
+
+	"gofalre.io/shop/sqlc"
+)
+
+// SavedItem 為客戶「稍後購買」清單中的商品，不預留庫存；UnitPrice 為存入時的售價，移回購物車時以當時的售價為準
+type SavedItem struct {
+	ID         uint64          `json:"id"`
+	CustomerID string          `json:"customer_id"`
+	ProductID  string          `json:"product_id"`
+	PriceID    string          `json:"price_id"`
+	StockID    uint64          `json:"stock_id"`
+	Quantity   uint64          `json:"quantity"`
+	UnitPrice  float64         `json:"unit_price"`
+	Currency   stripe.Currency `json:"currency"`
+	Location   string          `json:"location,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+
+	// Customization 為購物車項目原本的客製化內容，沒有客製化時為 nil
+	Customization json.RawMessage `json:"customization,omitempty"`
+}
+
+func (si *SavedItem) ConvertSqlcSavedItem(sqlcSavedItem any) *SavedItem {
+
+	switch sp := sqlcSavedItem.(type) {
+	case *sqlc.SavedItem:
+		si.ID = uint64(sp.ID)
+		si.CustomerID = sp.CustomerID
+		si.ProductID = sp.ProductID
+		si.PriceID = sp.PriceID
+		si.StockID = sp.StockID
+		si.Quantity = sp.Quantity
+		si.UnitPrice = sp.UnitPrice
+		si.Currency = stripe.Currency(sp.Currency)
+		if sp.Location != nil {
+			si.Location = *sp.Location
+		}
+		si.Customization = sp.Customization
+		si.CreatedAt = sp.CreatedAt.Time
+		si.UpdatedAt = sp.UpdatedAt.Time
+	default:
+		return nil
+	}
+
+	return si
+}
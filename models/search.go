@@ -0,0 +1,102 @@
+package models
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/stripe/stripe-go/v79"
+
+	"gofalre.io/shop/models/enum"
+)
+
+// SearchQuery 搜尋訂單或商品的條件，Locale 只用於商品，空字串表示搜尋所有語系
+type SearchQuery struct {
+	Text   string `json:"text"`
+	Locale string `json:"locale,omitempty"`
+	Limit  uint64 `json:"limit"`
+	Offset uint64 `json:"offset"`
+}
+
+// SearchHits 搜尋結果的文件 ID 與符合條件的總數；訂單的 ID 為訂單 ID，商品的 ID 為商品 ID
+type SearchHits struct {
+	IDs        []string `json:"ids"`
+	TotalCount uint64   `json:"total_count"`
+}
+
+// OrderDocument 寫入搜尋索引的訂單，ProductNames 為訂單項目下單當時的商品名稱
+type OrderDocument struct {
+	ID             uint64           `json:"id"`
+	OrderNumber    string           `json:"order_number"`
+	CustomerID     string           `json:"customer_id"`
+	Status         enum.OrderStatus `json:"status"`
+	Currency       stripe.Currency  `json:"currency"`
+	Total          float64          `json:"total"`
+	ProductNames   []string         `json:"product_names,omitempty"`
+	MetadataValues []string         `json:"metadata_values,omitempty"`
+	CreatedAt      time.Time        `json:"created_at"`
+}
+
+// NewOrderDocument 以訂單與訂單項目建立搜尋文件
+func NewOrderDocument(order *Order, items []*OrderItem) *OrderDocument {
+	doc := &OrderDocument{
+		ID:          order.ID,
+		OrderNumber: order.OrderNumber,
+		CustomerID:  order.CustomerID,
+		Status:      order.Status,
+		Currency:    order.Currency,
+		Total:       order.Total,
+		CreatedAt:   order.CreatedAt,
+	}
+	for _, item := range items {
+		if item.ProductName != "" {
+			doc.ProductNames = append(doc.ProductNames, item.ProductName)
+		}
+	}
+	for _, value := range order.Metadata {
+		doc.MetadataValues = append(doc.MetadataValues, value)
+	}
+	sort.Strings(doc.MetadataValues)
+
+	return doc
+}
+
+// DocumentID 回傳訂單在搜尋索引中的文件 ID
+func (d *OrderDocument) DocumentID() string {
+	return OrderDocumentID(d.ID)
+}
+
+// OrderDocumentID 回傳訂單在搜尋索引中的文件 ID
+func OrderDocumentID(orderID uint64) string {
+	return strconv.FormatUint(orderID, 10)
+}
+
+// ProductDocument 寫入搜尋索引的商品翻譯，每個商品的每個語系各為一份文件
+type ProductDocument struct {
+	ProductID   string    `json:"product_id"`
+	Locale      string    `json:"locale"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// NewProductDocument 以商品翻譯建立搜尋文件
+func NewProductDocument(translation *ProductTranslation) *ProductDocument {
+	return &ProductDocument{
+		ProductID:   translation.ProductID,
+		Locale:      translation.Locale,
+		Name:        translation.Name,
+		Description: translation.Description,
+		UpdatedAt:   translation.UpdatedAt,
+	}
+}
+
+// DocumentID 回傳商品翻譯在搜尋索引中的文件 ID
+func (d *ProductDocument) DocumentID() string {
+	return ProductDocumentID(d.ProductID, d.Locale)
+}
+
+// ProductDocumentID 回傳商品翻譯在搜尋索引中的文件 ID
+func ProductDocumentID(productID, locale string) string {
+	return productID + "/" + locale
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/stripe/stripe-go/v79"
+	"gofalre.io/shop/sqlc"
+)
+
+// Shipment 代表訂單從某個出貨地點寄出的一批商品，包含該地點出貨的所有訂單項目
+type Shipment struct {
+	ID        uint64    `json:"id"`
+	OrderID   uint64    `json:"order_id"`
+	Location  string    `json:"location"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ShipmentLabelData 為物流標籤列印服務所需的出貨資料，ShipTo 為訂單的寄送地址原始 JSON
+type ShipmentLabelData struct {
+	ShipmentID       uint64          `json:"shipment_id"`
+	OrderID          uint64          `json:"order_id"`
+	OrderNumber      string          `json:"order_number"`
+	CustomerID       string          `json:"customer_id"`
+	ShipFrom         string          `json:"ship_from"`
+	ShipTo           json.RawMessage `json:"ship_to"`
+	Currency         stripe.Currency `json:"currency"`
+	Items            []*ShippingItem `json:"items"`
+	TotalQuantity    uint64          `json:"total_quantity"`
+	TotalWeightGrams uint64          `json:"total_weight_grams"`
+	DeclaredValue    float64         `json:"declared_value"`
+	// MissingWeight 為 true 時表示有項目沒有重量資料，TotalWeightGrams 會低於實際重量
+	MissingWeight bool `json:"missing_weight"`
+}
+
+func (s *Shipment) ConvertSqlcShipment(sqlcShipment any) *Shipment {
+
+	switch sp := sqlcShipment.(type) {
+	case *sqlc.Shipment:
+		s.ID = uint64(sp.ID)
+		s.OrderID = uint64(sp.OrderID)
+		s.Location = sp.Location
+		s.CreatedAt = sp.CreatedAt.Time
+	default:
+		return nil
+	}
+
+	return s
+}
@@ -11,6 +11,8 @@ type Stock struct {
 	Quantity         uint64    `json:"quantity"`
 	ReservedQuantity uint64    `json:"reserved_quantity"`
 	Location         string    `json:"location"`
+	RentalEnabled    bool      `json:"rental_enabled"`
+	EventSourced     bool      `json:"event_sourced"` // 為 true 時數量已包含尚未投影的庫存變動
 	CreatedAt        time.Time `json:"created_at"`
 	UpdatedAt        time.Time `json:"updated_at"`
 }
@@ -19,6 +21,7 @@ func (s *Stock) ConvertSqlcStock(sqlcStock any) *Stock {
 
 	var id, quantity, reservedQuantity uint64
 	var productID, location string
+	var rentalEnabled bool
 	var createdAt, updatedAt time.Time
 
 	switch sp := sqlcStock.(type) {
@@ -30,6 +33,7 @@ func (s *Stock) ConvertSqlcStock(sqlcStock any) *Stock {
 		if sp.Location != nil {
 			location = *sp.Location
 		}
+		rentalEnabled = sp.RentalEnabled
 		createdAt = sp.CreatedAt.Time
 		updatedAt = sp.UpdatedAt.Time
 	default:
@@ -41,8 +45,72 @@ func (s *Stock) ConvertSqlcStock(sqlcStock any) *Stock {
 	s.Quantity = quantity
 	s.ReservedQuantity = reservedQuantity
 	s.Location = location
+	s.RentalEnabled = rentalEnabled
 	s.CreatedAt = createdAt
 	s.UpdatedAt = updatedAt
 
 	return s
 }
+
+// StockLevel 某個時間點的庫存水位，由目前的庫存數量扣回該時間點之後的庫存變動推算而得，
+// 變動記錄不完整時可能出現負數，因此使用有號整數
+type StockLevel struct {
+	StockID          uint64    `json:"stock_id"`
+	ProductID        string    `json:"product_id"`
+	Location         string    `json:"location"`
+	At               time.Time `json:"at"`
+	Quantity         int64     `json:"quantity"`
+	ReservedQuantity int64     `json:"reserved_quantity"`
+}
+
+// Available 回傳該時間點可售的數量
+func (sl *StockLevel) Available() int64 {
+	return sl.Quantity - sl.ReservedQuantity
+}
+
+func (sl *StockLevel) ConvertSqlcStockLevel(sqlcStockLevel any) *StockLevel {
+
+	switch sp := sqlcStockLevel.(type) {
+	case *sqlc.GetStockLevelAtRow:
+		sl.StockID = uint64(sp.ID)
+		sl.ProductID = sp.ProductID
+		if sp.Location != nil {
+			sl.Location = *sp.Location
+		}
+		sl.Quantity = sp.Quantity
+		sl.ReservedQuantity = sp.ReservedQuantity
+	default:
+		return nil
+	}
+
+	return sl
+}
+
+// StockProjection 事件溯源模式的庫存投影狀態，Base* 為啟用時的快照，重建投影時由此重播之後的庫存變動
+type StockProjection struct {
+	StockID              uint64    `json:"stock_id"`
+	BaseQuantity         int64     `json:"base_quantity"`
+	BaseReservedQuantity int64     `json:"base_reserved_quantity"`
+	BaseMovementID       uint64    `json:"base_movement_id"`
+	ProjectedMovementID  uint64    `json:"projected_movement_id"`
+	EnabledAt            time.Time `json:"enabled_at"`
+	ProjectedAt          time.Time `json:"projected_at"`
+}
+
+func (sp *StockProjection) ConvertSqlcStockProjection(sqlcStockProjection any) *StockProjection {
+
+	switch p := sqlcStockProjection.(type) {
+	case *sqlc.StockProjection:
+		sp.StockID = p.StockID
+		sp.BaseQuantity = int64(p.BaseQuantity)
+		sp.BaseReservedQuantity = int64(p.BaseReservedQuantity)
+		sp.BaseMovementID = uint64(p.BaseMovementID)
+		sp.ProjectedMovementID = uint64(p.ProjectedMovementID)
+		sp.EnabledAt = p.EnabledAt.Time
+		sp.ProjectedAt = p.ProjectedAt.Time
+	default:
+		return nil
+	}
+
+	return sp
+}
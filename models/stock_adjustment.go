@@ -0,0 +1,61 @@
+package models
+
+import (
+	"gofalre.io/shop/models/enum"
+	"gofalre.io/shop/sqlc"
+	"time"
+)
+
+// StockAdjustment 代表一筆人工庫存調整申請，QuantityDelta 為正表示增加庫存，為負表示減少
+type StockAdjustment struct {
+	ID            uint64                     `json:"id"`
+	StockID       uint64                     `json:"stock_id"`
+	QuantityDelta int64                      `json:"quantity_delta"`
+	Reason        enum.StockMovementReason   `json:"reason"`
+	Note          string                     `json:"note,omitempty"`
+	Status        enum.StockAdjustmentStatus `json:"status"`
+	RequestedBy   string                     `json:"requested_by"`
+	ReviewedBy    string                     `json:"reviewed_by,omitempty"`
+	ReviewNote    string                     `json:"review_note,omitempty"`
+	CreatedAt     time.Time                  `json:"created_at"`
+	ReviewedAt    *time.Time                 `json:"reviewed_at,omitempty"`
+}
+
+func (sa *StockAdjustment) ConvertSqlcStockAdjustment(sqlcStockAdjustment any) *StockAdjustment {
+
+	switch sp := sqlcStockAdjustment.(type) {
+	case *sqlc.StockAdjustment:
+		sa.ID = uint64(sp.ID)
+		sa.StockID = sp.StockID
+		sa.QuantityDelta = int64(sp.QuantityDelta)
+		sa.Reason = enum.StockMovementReason(sp.Reason)
+		if sp.Note != nil {
+			sa.Note = *sp.Note
+		}
+		sa.Status = enum.StockAdjustmentStatus(sp.Status)
+		sa.RequestedBy = sp.RequestedBy
+		if sp.ReviewedBy != nil {
+			sa.ReviewedBy = *sp.ReviewedBy
+		}
+		if sp.ReviewNote != nil {
+			sa.ReviewNote = *sp.ReviewNote
+		}
+		sa.CreatedAt = sp.CreatedAt.Time
+		if sp.ReviewedAt.Valid {
+			reviewedAt := sp.ReviewedAt.Time
+			sa.ReviewedAt = &reviewedAt
+		}
+	default:
+		return nil
+	}
+
+	return sa
+}
+
+// Quantity 回傳調整數量的絕對值
+func (sa *StockAdjustment) Quantity() uint64 {
+	if sa.QuantityDelta < 0 {
+		return uint64(-sa.QuantityDelta)
+	}
+	return uint64(sa.QuantityDelta)
+}
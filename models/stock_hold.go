@@ -0,0 +1,41 @@
+package models
+
+import (
+	"gofalre.io/shop/sqlc"
+	"time"
+)
+
+// StockHold 代表員工手動保留的庫存
+type StockHold struct {
+	ID         uint64     `json:"id"`
+	StockID    uint64     `json:"stock_id"`
+	Quantity   uint64     `json:"quantity"`
+	Reason     string     `json:"reason"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	ReleasedAt *time.Time `json:"released_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func (sh *StockHold) ConvertSqlcStockHold(sqlcStockHold any) *StockHold {
+
+	switch sp := sqlcStockHold.(type) {
+	case *sqlc.StockHold:
+		sh.ID = uint64(sp.ID)
+		sh.StockID = sp.StockID
+		sh.Quantity = sp.Quantity
+		sh.Reason = sp.Reason
+		if sp.ExpiresAt.Valid {
+			expiresAt := sp.ExpiresAt.Time
+			sh.ExpiresAt = &expiresAt
+		}
+		if sp.ReleasedAt.Valid {
+			releasedAt := sp.ReleasedAt.Time
+			sh.ReleasedAt = &releasedAt
+		}
+		sh.CreatedAt = sp.CreatedAt.Time
+	default:
+		return nil
+	}
+
+	return sh
+}
@@ -7,20 +7,30 @@ import (
 )
 
 type StockMovement struct {
-	ID            uint64                          `json:"id"`
-	StockID       uint64                          `json:"stock_id"`
-	Quantity      uint64                          `json:"quantity"`
-	Type          enum.StockMovementType          `json:"type"`
-	ReferenceType enum.StockMovementReferenceType `json:"reference_type"`
-	ReferenceID   uint64                          `json:"reference_id"`
-	CreatedAt     time.Time                       `json:"created_at"`
+	ID              uint64                          `json:"id"`
+	StockID         uint64                          `json:"stock_id"`
+	Quantity        uint64                          `json:"quantity"`
+	Type            enum.StockMovementType          `json:"type"`
+	ReferenceType   enum.StockMovementReferenceType `json:"reference_type"`
+	ReferenceID     uint64                          `json:"reference_id"`
+	ReferenceItemID uint64                          `json:"reference_item_id,omitempty"`
+	Actor           string                          `json:"actor,omitempty"`
+	Note            string                          `json:"note,omitempty"`
+	UnitCost        *float64                        `json:"unit_cost,omitempty"`
+	Reason          enum.StockMovementReason        `json:"reason,omitempty"`
+	ReversalOfID    *uint64                         `json:"reversal_of_id,omitempty"`
+	CreatedAt       time.Time                       `json:"created_at"`
 }
 
 func (sm *StockMovement) ConvertSqlcStockMovement(sqlcStockMovement any) *StockMovement {
 
-	var id, stockID, referenceID, quantity uint64
+	var id, stockID, referenceID, referenceItemID, quantity uint64
 	var stockMovementType enum.StockMovementType
 	var referenceType enum.StockMovementReferenceType
+	var actor, note string
+	var unitCost *float64
+	var reason enum.StockMovementReason
+	var reversalOfID *uint64
 	var createdAt time.Time
 
 	switch sp := sqlcStockMovement.(type) {
@@ -36,6 +46,23 @@ func (sm *StockMovement) ConvertSqlcStockMovement(sqlcStockMovement any) *StockM
 			referenceType = enum.StockMovementReferenceType(
 				sp.ReferenceType.StockMovementReferenceType)
 		}
+		if sp.Actor != nil {
+			actor = *sp.Actor
+		}
+		if sp.Note != nil {
+			note = *sp.Note
+		}
+		unitCost = sp.UnitCost
+		if sp.Reason.Valid {
+			reason = enum.StockMovementReason(sp.Reason.StockMovementReason)
+		}
+		if sp.ReversalOfID != nil {
+			id := uint64(*sp.ReversalOfID)
+			reversalOfID = &id
+		}
+		if sp.ReferenceItemID != nil {
+			referenceItemID = uint64(*sp.ReferenceItemID)
+		}
 		createdAt = sp.CreatedAt.Time
 	default:
 		return nil
@@ -46,7 +73,13 @@ func (sm *StockMovement) ConvertSqlcStockMovement(sqlcStockMovement any) *StockM
 	sm.Quantity = quantity
 	sm.ReferenceID = referenceID
 	sm.ReferenceType = referenceType
+	sm.ReferenceItemID = referenceItemID
 	sm.Type = stockMovementType
+	sm.Actor = actor
+	sm.Note = note
+	sm.UnitCost = unitCost
+	sm.Reason = reason
+	sm.ReversalOfID = reversalOfID
 	sm.CreatedAt = createdAt
 
 	return sm
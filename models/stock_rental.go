@@ -0,0 +1,52 @@
+package models
+
+import (
+	"gofalre.io/shop/models/enum"
+	"gofalre.io/shop/sqlc"
+	"time"
+)
+
+// StockRental 代表一筆租借預約，佔用 StartsOn 至 EndsOn（不含）之間的庫存
+type StockRental struct {
+	ID         uint64                 `json:"id"`
+	StockID    uint64                 `json:"stock_id"`
+	CustomerID string                 `json:"customer_id"`
+	Quantity   uint64                 `json:"quantity"`
+	StartsOn   time.Time              `json:"starts_on"`
+	EndsOn     time.Time              `json:"ends_on"`
+	Status     enum.StockRentalStatus `json:"status"`
+	Note       string                 `json:"note,omitempty"`
+	CreatedAt  time.Time              `json:"created_at"`
+	UpdatedAt  time.Time              `json:"updated_at"`
+}
+
+// RentalAvailability 表示租借庫存在某一天的可租數量
+type RentalAvailability struct {
+	Date      time.Time `json:"date"`
+	Quantity  uint64    `json:"quantity"`
+	Booked    uint64    `json:"booked"`
+	Available uint64    `json:"available"`
+}
+
+func (sr *StockRental) ConvertSqlcStockRental(sqlcStockRental any) *StockRental {
+
+	switch sp := sqlcStockRental.(type) {
+	case *sqlc.StockRental:
+		sr.ID = uint64(sp.ID)
+		sr.StockID = sp.StockID
+		sr.CustomerID = sp.CustomerID
+		sr.Quantity = sp.Quantity
+		sr.StartsOn = sp.StartsOn.Time
+		sr.EndsOn = sp.EndsOn.Time
+		sr.Status = enum.StockRentalStatus(sp.Status)
+		if sp.Note != nil {
+			sr.Note = *sp.Note
+		}
+		sr.CreatedAt = sp.CreatedAt.Time
+		sr.UpdatedAt = sp.UpdatedAt.Time
+	default:
+		return nil
+	}
+
+	return sr
+}
@@ -0,0 +1,108 @@
+package models
+
+import (
+	"time"
+
+	"gofalre.io/shop/models/enum"
+	"gofalre.io/shop/sqlc"
+)
+
+// StockTransfer 代表一筆倉庫間的調撥單，核准後從 FromStockID 移出並移入 ToStockID
+type StockTransfer struct {
+	ID          uint64                   `json:"id"`
+	FromStockID uint64                   `json:"from_stock_id"`
+	ToStockID   uint64                   `json:"to_stock_id"`
+	Quantity    uint64                   `json:"quantity"`
+	Note        string                   `json:"note,omitempty"`
+	Status      enum.StockTransferStatus `json:"status"`
+	RequestedBy string                   `json:"requested_by"`
+	ReviewedBy  string                   `json:"reviewed_by,omitempty"`
+	ReviewNote  string                   `json:"review_note,omitempty"`
+	CreatedAt   time.Time                `json:"created_at"`
+	ReviewedAt  *time.Time               `json:"reviewed_at,omitempty"`
+}
+
+// StockOutflow 為同一商品在各出貨地點的庫存與統計期間內的訂單出貨數量
+type StockOutflow struct {
+	StockID          uint64 `json:"stock_id"`
+	ProductID        string `json:"product_id"`
+	Location         string `json:"location"`
+	Quantity         uint64 `json:"quantity"`
+	ReservedQuantity uint64 `json:"reserved_quantity"`
+	OutQuantity      uint64 `json:"out_quantity"`
+}
+
+// TransferSuggestion 為調撥建議，依各地點的出貨速度將庫存從存貨過剩的地點移往不足的地點
+type TransferSuggestion struct {
+	ProductID    string  `json:"product_id"`
+	FromStockID  uint64  `json:"from_stock_id"`
+	FromLocation string  `json:"from_location"`
+	ToStockID    uint64  `json:"to_stock_id"`
+	ToLocation   string  `json:"to_location"`
+	Quantity     uint64  `json:"quantity"`
+	FromDailyOut float64 `json:"from_daily_out"`
+	ToDailyOut   float64 `json:"to_daily_out"`
+	// FromAvailable 與 ToAvailable 為建議時的可用數量（扣除預留），ToAvailable 為 0 表示目的地點已缺貨
+	FromAvailable uint64 `json:"from_available"`
+	ToAvailable   uint64 `json:"to_available"`
+	// TransferID 為自動建立的調撥單草稿，未建立時為 nil
+	TransferID *uint64 `json:"transfer_id,omitempty"`
+}
+
+// Available 回傳未被預留的數量
+func (so *StockOutflow) Available() uint64 {
+	if so.ReservedQuantity >= so.Quantity {
+		return 0
+	}
+	return so.Quantity - so.ReservedQuantity
+}
+
+func (st *StockTransfer) ConvertSqlcStockTransfer(sqlcStockTransfer any) *StockTransfer {
+
+	switch sp := sqlcStockTransfer.(type) {
+	case *sqlc.StockTransfer:
+		st.ID = uint64(sp.ID)
+		st.FromStockID = uint64(sp.FromStockID)
+		st.ToStockID = uint64(sp.ToStockID)
+		st.Quantity = sp.Quantity
+		if sp.Note != nil {
+			st.Note = *sp.Note
+		}
+		st.Status = enum.StockTransferStatus(sp.Status)
+		st.RequestedBy = sp.RequestedBy
+		if sp.ReviewedBy != nil {
+			st.ReviewedBy = *sp.ReviewedBy
+		}
+		if sp.ReviewNote != nil {
+			st.ReviewNote = *sp.ReviewNote
+		}
+		st.CreatedAt = sp.CreatedAt.Time
+		if sp.ReviewedAt.Valid {
+			reviewedAt := sp.ReviewedAt.Time
+			st.ReviewedAt = &reviewedAt
+		}
+	default:
+		return nil
+	}
+
+	return st
+}
+
+func (so *StockOutflow) ConvertSqlcStockOutflow(sqlcStockOutflow any) *StockOutflow {
+
+	switch sp := sqlcStockOutflow.(type) {
+	case *sqlc.ListStockOutflowsRow:
+		so.StockID = uint64(sp.ID)
+		so.ProductID = sp.ProductID
+		if sp.Location != nil {
+			so.Location = *sp.Location
+		}
+		so.Quantity = sp.Quantity
+		so.ReservedQuantity = uint64(max(sp.ReservedQuantity, 0))
+		so.OutQuantity = uint64(max(sp.OutQuantity, 0))
+	default:
+		return nil
+	}
+
+	return so
+}
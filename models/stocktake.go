@@ -0,0 +1,106 @@
+package models
+
+import (
+	"time"
+
+	"gofalre.io/shop/models/enum"
+	"gofalre.io/shop/sqlc"
+)
+
+// Stocktake 代表某個地點的一次盤點，進行中時可由掃描器分批上傳掃描結果
+type Stocktake struct {
+	ID         uint64               `json:"id"`
+	Location   string               `json:"location"`
+	Status     enum.StocktakeStatus `json:"status"`
+	StartedBy  string               `json:"started_by"`
+	FinishedBy string               `json:"finished_by,omitempty"`
+	CreatedAt  time.Time            `json:"created_at"`
+	FinishedAt *time.Time           `json:"finished_at,omitempty"`
+}
+
+// StocktakeScan 為掃描器回傳的單筆掃描，SerialNumber 不為空時表示單件商品的序號掃描
+type StocktakeScan struct {
+	Barcode      string `json:"barcode"`
+	SerialNumber string `json:"serial_number,omitempty"`
+	// Quantity 為這次掃描代表的數量，為 0 時視為 1；序號掃描只能為 1
+	Quantity  uint64    `json:"quantity,omitempty"`
+	ScannedAt time.Time `json:"scanned_at"`
+}
+
+// StocktakeScanBatch 為掃描器一次上傳的掃描批次，BatchID 由掃描器產生，重送同一個批次不會重複計入
+type StocktakeScanBatch struct {
+	BatchID string           `json:"batch_id"`
+	Scans   []*StocktakeScan `json:"scans"`
+}
+
+// StocktakeScanResult 為批次中單筆掃描的處理結果，Position 為該掃描在批次中的位置
+type StocktakeScanResult struct {
+	Position  int                       `json:"position"`
+	Barcode   string                    `json:"barcode"`
+	Outcome   enum.StocktakeScanOutcome `json:"outcome"`
+	StockID   uint64                    `json:"stock_id,omitempty"`
+	ProductID string                    `json:"product_id,omitempty"`
+}
+
+// StocktakeBatchResult 為掃描批次的處理結果
+type StocktakeBatchResult struct {
+	StocktakeID uint64                 `json:"stocktake_id"`
+	BatchID     string                 `json:"batch_id"`
+	Accepted    int                    `json:"accepted"`
+	Duplicates  int                    `json:"duplicates"`
+	Rejected    int                    `json:"rejected"`
+	Results     []*StocktakeScanResult `json:"results"`
+}
+
+// StocktakeCount 為盤點地點中單一庫存目前的盤點數量與系統數量
+type StocktakeCount struct {
+	StockID          uint64 `json:"stock_id"`
+	ProductID        string `json:"product_id"`
+	Quantity         uint64 `json:"quantity"`
+	ReservedQuantity uint64 `json:"reserved_quantity"`
+	CountedQuantity  uint64 `json:"counted_quantity"`
+}
+
+// Variance 回傳盤點數量與系統數量的差異，為正表示盤盈，為負表示盤虧
+func (sc *StocktakeCount) Variance() int64 {
+	return int64(sc.CountedQuantity) - int64(sc.Quantity)
+}
+
+func (st *Stocktake) ConvertSqlcStocktake(sqlcStocktake any) *Stocktake {
+
+	switch sp := sqlcStocktake.(type) {
+	case *sqlc.Stocktake:
+		st.ID = uint64(sp.ID)
+		st.Location = sp.Location
+		st.Status = enum.StocktakeStatus(sp.Status)
+		st.StartedBy = sp.StartedBy
+		if sp.FinishedBy != nil {
+			st.FinishedBy = *sp.FinishedBy
+		}
+		st.CreatedAt = sp.CreatedAt.Time
+		if sp.FinishedAt.Valid {
+			finishedAt := sp.FinishedAt.Time
+			st.FinishedAt = &finishedAt
+		}
+	default:
+		return nil
+	}
+
+	return st
+}
+
+func (sc *StocktakeCount) ConvertSqlcStocktakeCount(sqlcStocktakeCount any) *StocktakeCount {
+
+	switch sp := sqlcStocktakeCount.(type) {
+	case *sqlc.ListStocktakeCountsRow:
+		sc.StockID = sp.StockID
+		sc.ProductID = sp.ProductID
+		sc.Quantity = sp.Quantity
+		sc.ReservedQuantity = uint64(max(sp.ReservedQuantity, 0))
+		sc.CountedQuantity = uint64(max(sp.CountedQuantity, 0))
+	default:
+		return nil
+	}
+
+	return sc
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"gofalre.io/shop/sqlc"
+)
+
+// Store 實體門市，Location 對應庫存的地點，Address 為地址原始 JSON
+type Store struct {
+	ID        uint64          `json:"id"`
+	Name      string          `json:"name"`
+	Location  string          `json:"location"`
+	Address   json.RawMessage `json:"address,omitempty"`
+	Latitude  float64         `json:"latitude"`
+	Longitude float64         `json:"longitude"`
+	Hours     []StoreHours    `json:"hours"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// StoreHours 門市在一週中某一天的營業時間，Opens、Closes 為門市當地時間，格式為 15:04
+type StoreHours struct {
+	Weekday time.Weekday `json:"weekday"`
+	Opens   string       `json:"opens"`
+	Closes  string       `json:"closes"`
+}
+
+// StoreAvailability 附近門市的可用庫存，DistanceKm 為與查詢位置的直線距離（公里）
+type StoreAvailability struct {
+	Store      *Store  `json:"store"`
+	StockID    uint64  `json:"stock_id"`
+	Available  uint64  `json:"available"`
+	DistanceKm float64 `json:"distance_km"`
+}
+
+func (s *Store) ConvertSqlcStore(sqlcStore any) *Store {
+
+	var id int32
+	var name, location string
+	var address, hours []byte
+	var latitude, longitude float64
+	var createdAt, updatedAt time.Time
+
+	switch sp := sqlcStore.(type) {
+	case *sqlc.Store:
+		id, name, location = sp.ID, sp.Name, sp.Location
+		address, hours = sp.Address, sp.Hours
+		latitude, longitude = sp.Latitude, sp.Longitude
+		createdAt, updatedAt = sp.CreatedAt.Time, sp.UpdatedAt.Time
+	case *sqlc.FindStoresWithinRadiusRow:
+		id, name, location = sp.ID, sp.Name, sp.Location
+		address, hours = sp.Address, sp.Hours
+		latitude, longitude = sp.Latitude, sp.Longitude
+		createdAt, updatedAt = sp.CreatedAt.Time, sp.UpdatedAt.Time
+	default:
+		return nil
+	}
+
+	s.ID = uint64(id)
+	s.Name = name
+	s.Location = location
+	s.Address = address
+	s.Latitude = latitude
+	s.Longitude = longitude
+	s.Hours = []StoreHours{}
+	if len(hours) > 0 {
+		// 營業時間由 service 驗證後寫入，格式錯誤時視為未設定
+		_ = json.Unmarshal(hours, &s.Hours)
+	}
+	s.CreatedAt = createdAt
+	s.UpdatedAt = updatedAt
+
+	return s
+}
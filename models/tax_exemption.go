@@ -0,0 +1,61 @@
+package models
+
+import (
+	"slices"
+	"time"
+
+	"gofalre.io/shop/sqlc"
+)
+
+// TaxExemptionCertificate 客戶的免稅證明，Jurisdiction 為適用的國家代碼；TaxClasses 為空表示適用所有稅別，
+// ValidUntil 為 nil 表示沒有到期日，RevokedAt 不為 nil 表示證明已撤銷
+type TaxExemptionCertificate struct {
+	ID                uint64     `json:"id"`
+	CustomerID        string     `json:"customer_id"`
+	CertificateNumber string     `json:"certificate_number"`
+	Jurisdiction      string     `json:"jurisdiction"`
+	TaxClasses        []string   `json:"tax_classes,omitempty"`
+	ValidFrom         time.Time  `json:"valid_from"`
+	ValidUntil        *time.Time `json:"valid_until,omitempty"`
+	RevokedAt         *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+// ValidAt 回傳證明在 t 時是否有效（已生效、未到期且未撤銷）
+func (c *TaxExemptionCertificate) ValidAt(t time.Time) bool {
+	if c.RevokedAt != nil || t.Before(c.ValidFrom) {
+		return false
+	}
+	return c.ValidUntil == nil || t.Before(*c.ValidUntil)
+}
+
+// Covers 回傳證明是否適用於 taxClass 稅別的項目
+func (c *TaxExemptionCertificate) Covers(taxClass string) bool {
+	return len(c.TaxClasses) == 0 || slices.Contains(c.TaxClasses, taxClass)
+}
+
+func (c *TaxExemptionCertificate) ConvertSqlcTaxExemptionCertificate(sqlcCertificate any) *TaxExemptionCertificate {
+
+	switch sp := sqlcCertificate.(type) {
+	case *sqlc.TaxExemptionCertificate:
+		c.ID = uint64(sp.ID)
+		c.CustomerID = sp.CustomerID
+		c.CertificateNumber = sp.CertificateNumber
+		c.Jurisdiction = sp.Jurisdiction
+		c.TaxClasses = sp.TaxClasses
+		c.ValidFrom = sp.ValidFrom.Time
+		if sp.ValidUntil.Valid {
+			c.ValidUntil = &sp.ValidUntil.Time
+		}
+		if sp.RevokedAt.Valid {
+			c.RevokedAt = &sp.RevokedAt.Time
+		}
+		c.CreatedAt = sp.CreatedAt.Time
+		c.UpdatedAt = sp.UpdatedAt.Time
+	default:
+		return nil
+	}
+
+	return c
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+
+	"gofalre.io/shop/sqlc"
+)
+
+// CategoryTranslation 分類在特定語系的名稱與描述
+type CategoryTranslation struct {
+	CategoryID  uint64    `json:"category_id"`
+	Locale      string    `json:"locale"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// ProductTranslation 商品在特定語系的名稱與描述
+type ProductTranslation struct {
+	ProductID   string    `json:"product_id"`
+	Locale      string    `json:"locale"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (ct *CategoryTranslation) ConvertSqlcCategoryTranslation(sqlcTranslation any) *CategoryTranslation {
+
+	switch sp := sqlcTranslation.(type) {
+	case *sqlc.CategoryTranslation:
+		ct.CategoryID = uint64(sp.CategoryID)
+		ct.Locale = sp.Locale
+		ct.Name = sp.Name
+		if sp.Description != nil {
+			ct.Description = *sp.Description
+		}
+		ct.UpdatedAt = sp.UpdatedAt.Time
+	default:
+		return nil
+	}
+
+	return ct
+}
+
+func (pt *ProductTranslation) ConvertSqlcProductTranslation(sqlcTranslation any) *ProductTranslation {
+
+	switch sp := sqlcTranslation.(type) {
+	case *sqlc.ProductTranslation:
+		pt.ProductID = sp.ProductID
+		pt.Locale = sp.Locale
+		pt.Name = sp.Name
+		if sp.Description != nil {
+			pt.Description = *sp.Description
+		}
+		pt.UpdatedAt = sp.UpdatedAt.Time
+	default:
+		return nil
+	}
+
+	return pt
+}
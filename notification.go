@@ -0,0 +1,104 @@
+package shop
+
+import (
+	"time"
+
+	"github.com/stripe/stripe-go/v79"
+
+	"gofalre.io/shop/models"
+	"gofalre.io/shop/models/enum"
+)
+
+const (
+	// SubjectOrderReadyForPickup 訂單已可到門市取貨
+	SubjectOrderReadyForPickup = "shop.order.ready_for_pickup"
+	// SubjectPriceChanged 排程的價格變動已生效
+	SubjectPriceChanged = "shop.price.changed"
+	// SubjectCartCleared 購物車已被清空
+	SubjectCartCleared = "shop.cart.cleared"
+	// SubjectCartAbandoned 購物車已被放棄，或閒置超過門檻仍為 active（Status 為 active）
+	SubjectCartAbandoned = "shop.cart.abandoned"
+	// SubjectCartExpired 購物車已到期，預留庫存已釋放並標記為 abandoned
+	SubjectCartExpired = "shop.cart.expired"
+	// SubjectOrderDeleted 訂單已被刪除
+	SubjectOrderDeleted = "shop.order.deleted"
+	// SubjectOrderRestored 軟刪除的訂單已被還原，事件內容為 OrderDeletedEvent
+	SubjectOrderRestored = "shop.order.restored"
+	// SubjectPaymentEvents 金流服務轉發的 Stripe 事件，由 shop 訂閱
+	SubjectPaymentEvents = "payment.service.event.>"
+	// SubjectReturnTracking 物流整合發佈的退貨追蹤事件（models.ReturnTrackingEvent），由 shop 訂閱
+	SubjectReturnTracking = "shop.return.tracking"
+	// SubjectCustomerNotification 依客戶通知偏好篩選後要發送給客戶的訊息（CustomerNotificationEvent），由通知服務訂閱並發送
+	SubjectCustomerNotification = "shop.customer.notification"
+	// SubjectFulfillmentSLAAtRisk 訂單已進入履約時限的警示期間但尚未出貨
+	SubjectFulfillmentSLAAtRisk = "shop.order.sla_at_risk"
+	// SubjectFulfillmentSLABreached 訂單已超過履約時限仍未出貨
+	SubjectFulfillmentSLABreached = "shop.order.sla_breached"
+)
+
+// OrderReadyForPickupEvent 通知客戶訂單已備妥，可前往門市取貨
+type OrderReadyForPickupEvent struct {
+	OrderID        uint64    `json:"order_id"`
+	OrderNumber    string    `json:"order_number"`
+	CustomerID     string    `json:"customer_id"`
+	PickupLocation string    `json:"pickup_location"`
+	OccurredAt     time.Time `json:"occurred_at"`
+}
+
+// PriceChangedEvent 通知商品目錄價格已變動
+type PriceChangedEvent struct {
+	PriceChangeID uint64          `json:"price_change_id"`
+	PriceID       string          `json:"price_id"`
+	ProductID     string          `json:"product_id"`
+	UnitPrice     float64         `json:"unit_price"`
+	Currency      stripe.Currency `json:"currency,omitempty"`
+	EffectiveAt   time.Time       `json:"effective_at"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+}
+
+// CartEvent 通知購物車狀態變更；偵測到閒置購物車時另帶項目、金額、最後異動時間與挽回優惠券，供行銷服務發送挽回通知
+type CartEvent struct {
+	CartID         uint64             `json:"cart_id"`
+	CustomerID     string             `json:"customer_id"`
+	Status         enum.CartStatus    `json:"status"`
+	Items          []*models.CartItem `json:"items,omitempty"`
+	Total          float64            `json:"total,omitempty"`
+	Currency       stripe.Currency    `json:"currency,omitempty"`
+	LastActivityAt *time.Time         `json:"last_activity_at,omitempty"`
+	RecoveryCoupon *models.Coupon     `json:"recovery_coupon,omitempty"`
+	OccurredAt     time.Time          `json:"occurred_at"`
+}
+
+// OrderDeletedEvent 通知訂單已被刪除或還原，Status 為刪除前的狀態
+type OrderDeletedEvent struct {
+	OrderID     uint64           `json:"order_id"`
+	OrderNumber string           `json:"order_number"`
+	CustomerID  string           `json:"customer_id"`
+	Status      enum.OrderStatus `json:"status"`
+	OccurredAt  time.Time        `json:"occurred_at"`
+}
+
+// CustomerNotificationEvent 要發送給客戶的訊息，Channels 為客戶啟用的管道，通知服務只能使用這些管道；
+// UnsubscribeToken 供退訂連結使用，未設定退訂金鑰時為空字串
+type CustomerNotificationEvent struct {
+	Kind             enum.NotificationKind      `json:"kind"`
+	Topic            enum.NotificationTopic     `json:"topic"`
+	CustomerID       string                     `json:"customer_id"`
+	Channels         []enum.NotificationChannel `json:"channels"`
+	CartID           uint64                     `json:"cart_id,omitempty"`
+	ProductID        string                     `json:"product_id,omitempty"`
+	UnsubscribeToken string                     `json:"unsubscribe_token,omitempty"`
+	OccurredAt       time.Time                  `json:"occurred_at"`
+}
+
+// FulfillmentSLAAlertEvent 通知營運人員訂單有違反履約時限的風險或已違反
+type FulfillmentSLAAlertEvent struct {
+	OrderID         uint64               `json:"order_id"`
+	OrderNumber     string               `json:"order_number"`
+	CustomerID      string               `json:"customer_id"`
+	Status          enum.OrderStatus     `json:"status"`
+	FulfillmentType enum.FulfillmentType `json:"fulfillment_type"`
+	PaidAt          time.Time            `json:"paid_at"`
+	DueAt           time.Time            `json:"due_at"`
+	OccurredAt      time.Time            `json:"occurred_at"`
+}
@@ -0,0 +1,278 @@
+package shop
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+
+	"gofalre.io/shop/models"
+	"gofalre.io/shop/models/enum"
+)
+
+// ErrInvalidUnsubscribeToken 表示退訂連結的 token 格式錯誤或簽章不符
+var ErrInvalidUnsubscribeToken = errors.New("invalid unsubscribe token")
+
+// notificationTopics 為各類訊息所屬的主題，客戶退訂主題後不再收到該主題的訊息
+var notificationTopics = map[enum.NotificationKind]enum.NotificationTopic{
+	enum.NotificationKindAbandonedCart: enum.NotificationTopicMarketing,
+	enum.NotificationKindBackInStock:   enum.NotificationTopicTransactional,
+}
+
+// WithUnsubscribeSecret 設定簽署退訂連結 token 的金鑰，設定後送出的通知會帶有 UnsubscribeToken；
+// 未設定時不產生 token，Unsubscribe 一律回傳 ErrInvalidUnsubscribeToken
+func WithUnsubscribeSecret(secret []byte) Option {
+	return func(s *service) {
+		s.unsubscribeSecret = secret
+	}
+}
+
+// GetNotificationPreferences 取得客戶的通知偏好，客戶沒有設定時回傳預設偏好
+func (s *service) GetNotificationPreferences(ctx context.Context, customerID string) (*models.NotificationPreferences, error) {
+	if customerID == "" {
+		return nil, errors.New("customer ID is required")
+	}
+
+	var preferences *models.NotificationPreferences
+
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		var err error
+		preferences, err = s.getNotificationPreferences(ctx, tx, customerID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return preferences, nil
+}
+
+// UpdateNotificationChannels 設定客戶啟用的通知管道，未列出的管道停用；退訂的主題不受影響
+func (s *service) UpdateNotificationChannels(ctx context.Context, customerID string, channels []enum.NotificationChannel) (*models.NotificationPreferences, error) {
+	enabled := make(map[enum.NotificationChannel]bool, len(channels))
+	for _, channel := range channels {
+		switch channel {
+		case enum.NotificationChannelEmail, enum.NotificationChannelSMS, enum.NotificationChannelPush:
+			enabled[channel] = true
+		default:
+			return nil, fmt.Errorf("invalid notification channel: %q", channel)
+		}
+	}
+
+	return s.updateNotificationPreferences(ctx, customerID, true, func(preferences *models.NotificationPreferences) {
+		preferences.Email = enabled[enum.NotificationChannelEmail]
+		preferences.SMS = enabled[enum.NotificationChannelSMS]
+		preferences.Push = enabled[enum.NotificationChannelPush]
+	})
+}
+
+// SetNotificationOptOut 退訂或重新訂閱客戶的通知主題，重複退訂時保留第一次退訂的時間
+func (s *service) SetNotificationOptOut(ctx context.Context, customerID string, topic enum.NotificationTopic, optedOut bool) (*models.NotificationPreferences, error) {
+	if topic != enum.NotificationTopicMarketing && topic != enum.NotificationTopicTransactional {
+		return nil, fmt.Errorf("invalid notification topic: %q", topic)
+	}
+
+	return s.updateNotificationPreferences(ctx, customerID, true, func(preferences *models.NotificationPreferences) {
+		setTopicOptOut(preferences, topic, optedOut)
+	})
+}
+
+// Unsubscribe 以通知中的退訂 token 退訂該主題，供不需登入的退訂連結使用；token 由 WithUnsubscribeSecret 設定的金鑰簽署
+func (s *service) Unsubscribe(ctx context.Context, token string) (*models.NotificationPreferences, error) {
+	customerID, topic, err := s.parseUnsubscribeToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	preferences, err := s.updateNotificationPreferences(ctx, customerID, false, func(preferences *models.NotificationPreferences) {
+		setTopicOptOut(preferences, topic, true)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log(ctx).Info("Customer unsubscribed from notifications", zap.String("customer_id", customerID), zap.String("topic", string(topic)))
+	return preferences, nil
+}
+
+// NotifyBackInStock 通知登記到貨通知的客戶商品已重新到貨，登記名單由商品目錄維護；
+// 依各客戶的通知偏好決定管道，已退訂交易通知的客戶不會收到。回傳實際送出的通知數
+func (s *service) NotifyBackInStock(ctx context.Context, productID string, customerIDs []string) (int, error) {
+	if productID == "" {
+		return 0, errors.New("product ID is required")
+	}
+
+	sent := 0
+	for _, customerID := range customerIDs {
+		ok, err := s.dispatchNotification(ctx, &CustomerNotificationEvent{
+			Kind:       enum.NotificationKindBackInStock,
+			CustomerID: customerID,
+			ProductID:  productID,
+		})
+		if err != nil {
+			return sent, fmt.Errorf("failed to notify customer %s: %w", customerID, err)
+		}
+		if ok {
+			sent++
+		}
+	}
+
+	return sent, nil
+}
+
+// notifyAbandonedCart 依客戶的通知偏好發送購物車放棄提醒，沒有客戶的購物車不發送；發送失敗不影響購物車狀態
+func (s *service) notifyAbandonedCart(ctx context.Context, cartModel *models.Cart) {
+	if cartModel.CustomerID == "" {
+		return
+	}
+
+	if _, err := s.dispatchNotification(ctx, &CustomerNotificationEvent{
+		Kind:       enum.NotificationKindAbandonedCart,
+		CustomerID: cartModel.CustomerID,
+		CartID:     cartModel.ID,
+	}); err != nil {
+		s.log(ctx).Warn("Failed to send abandoned cart notification", zap.Uint64("cart_id", cartModel.ID), zap.Error(err))
+	}
+}
+
+// dispatchNotification 依客戶的通知偏好設定 event 的主題與管道後發佈，客戶已退訂或沒有啟用的管道時不發佈並回傳 false
+func (s *service) dispatchNotification(ctx context.Context, event *CustomerNotificationEvent) (bool, error) {
+	// 1. 取得客戶的通知偏好
+	preferences, err := s.GetNotificationPreferences(ctx, event.CustomerID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get notification preferences: %w", err)
+	}
+
+	// 2. 決定主題與可以使用的管道
+	event.Topic = notificationTopics[event.Kind]
+	event.Channels = preferences.Channels(event.Topic)
+	if len(event.Channels) == 0 {
+		s.log(ctx).Debug("Notification suppressed by customer preferences",
+			zap.String("customer_id", event.CustomerID), zap.String("kind", string(event.Kind)))
+		return false, nil
+	}
+
+	// 3. 發佈給通知服務
+	event.UnsubscribeToken = s.unsubscribeToken(event.CustomerID, event.Topic)
+	event.OccurredAt = time.Now()
+	if err = s.eventManager.Publish(ctx, SubjectCustomerNotification, event); err != nil {
+		return false, fmt.Errorf("failed to publish customer notification: %w", err)
+	}
+
+	return true, nil
+}
+
+// getNotificationPreferences 取得客戶的通知偏好，客戶沒有設定時回傳預設偏好
+func (s *service) getNotificationPreferences(ctx context.Context, tx pgx.Tx, customerID string) (*models.NotificationPreferences, error) {
+	preferences, err := s.cart.GetNotificationPreferences(ctx, tx, customerID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.DefaultNotificationPreferences(customerID), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification preferences: %w", err)
+	}
+	return preferences, nil
+}
+
+// updateNotificationPreferences 以 update 修改客戶目前的通知偏好後儲存；authorize 為 false 時由呼叫端負責驗證（例如退訂 token）
+func (s *service) updateNotificationPreferences(ctx context.Context, customerID string, authorize bool, update func(*models.NotificationPreferences)) (*models.NotificationPreferences, error) {
+	if customerID == "" {
+		return nil, errors.New("customer ID is required")
+	}
+
+	var preferences *models.NotificationPreferences
+
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		if authorize {
+			if err := s.checkAuthorization(ctx, AuthorizationRequest{
+				Action:     ActionUpdateNotificationPreferences,
+				CustomerID: customerID,
+			}); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		if preferences, err = s.getNotificationPreferences(ctx, tx, customerID); err != nil {
+			return err
+		}
+		update(preferences)
+
+		if err = s.cart.SaveNotificationPreferences(ctx, tx, preferences); err != nil {
+			return fmt.Errorf("failed to save notification preferences: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return preferences, nil
+}
+
+// setTopicOptOut 設定主題的退訂時間，已退訂時保留原本的時間
+func setTopicOptOut(preferences *models.NotificationPreferences, topic enum.NotificationTopic, optedOut bool) {
+	optedOutAt := &preferences.MarketingOptedOutAt
+	if topic == enum.NotificationTopicTransactional {
+		optedOutAt = &preferences.TransactionalOptedOutAt
+	}
+
+	switch {
+	case !optedOut:
+		*optedOutAt = nil
+	case *optedOutAt == nil:
+		now := time.Now()
+		*optedOutAt = &now
+	}
+}
+
+// unsubscribeToken 產生退訂 topic 的 token，格式為 base64(客戶 ID).主題.base64(HMAC-SHA256)；未設定金鑰時回傳空字串
+func (s *service) unsubscribeToken(customerID string, topic enum.NotificationTopic) string {
+	if len(s.unsubscribeSecret) == 0 {
+		return ""
+	}
+
+	encodedID := base64.RawURLEncoding.EncodeToString([]byte(customerID))
+	return encodedID + "." + string(topic) + "." + base64.RawURLEncoding.EncodeToString(s.signUnsubscribe(customerID, topic))
+}
+
+// parseUnsubscribeToken 驗證退訂 token 的簽章並回傳客戶 ID 與主題
+func (s *service) parseUnsubscribeToken(token string) (string, enum.NotificationTopic, error) {
+	if len(s.unsubscribeSecret) == 0 {
+		return "", "", ErrInvalidUnsubscribeToken
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", "", ErrInvalidUnsubscribeToken
+	}
+	customerID, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil || len(customerID) == 0 {
+		return "", "", ErrInvalidUnsubscribeToken
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", "", ErrInvalidUnsubscribeToken
+	}
+
+	topic := enum.NotificationTopic(parts[1])
+	if !hmac.Equal(signature, s.signUnsubscribe(string(customerID), topic)) {
+		return "", "", ErrInvalidUnsubscribeToken
+	}
+
+	return string(customerID), topic, nil
+}
+
+// signUnsubscribe 以金鑰簽署客戶 ID 與主題
+func (s *service) signUnsubscribe(customerID string, topic enum.NotificationTopic) []byte {
+	mac := hmac.New(sha256.New, s.unsubscribeSecret)
+	mac.Write([]byte(customerID))
+	mac.Write([]byte{0})
+	mac.Write([]byte(topic))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,140 @@
+package shop
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"gofalre.io/shop/models"
+	"gofalre.io/shop/models/enum"
+)
+
+const (
+	// opsDashboardTimeout 為組合營運指標的時限，逾時的區塊不等待
+	opsDashboardTimeout = 3 * time.Second
+	// defaultLowStockThreshold 為預設的低庫存門檻，可用數量不超過此值即列入低庫存
+	defaultLowStockThreshold = 5
+	// opsDashboardLowStockLimit 為營運指標列出的低庫存筆數上限
+	opsDashboardLowStockLimit = 20
+	// eventBacklogGrace 為事件收到後視為積壓前的處理時間
+	eventBacklogGrace = 5 * time.Minute
+)
+
+// WithLowStockThreshold 設定營運指標的低庫存門檻，可用數量不超過 threshold 的庫存列入低庫存
+func WithLowStockThreshold(threshold uint64) Option {
+	return func(s *service) {
+		s.lowStockThreshold = threshold
+	}
+}
+
+// opsDashboardSection 載入營運指標的一個區塊，回傳的函式在呼叫端的 goroutine 寫入結果，避免逾時的區塊與回傳值競爭
+type opsDashboardSection struct {
+	name string
+	load func(ctx context.Context) (func(*models.OpsDashboard), error)
+}
+
+// GetOpsDashboard 同時載入後台首頁的營運指標：今日訂單數與營收、待付款與今日付款失敗的訂單數、低庫存、
+// 事件積壓與處理佇列的使用狀況；整體在 opsDashboardTimeout 內回傳，逾時或失敗的區塊列在 Unavailable
+func (s *service) GetOpsDashboard(ctx context.Context) (*models.OpsDashboard, error) {
+	now := time.Now()
+	since := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	dashboard := &models.OpsDashboard{
+		GeneratedAt: now,
+		Since:       since,
+	}
+	if s.workerPool != nil {
+		dashboard.Queue = s.workerPool.Stats()
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, opsDashboardTimeout)
+	defer cancel()
+
+	sections := s.opsDashboardSections(since, now)
+	type result struct {
+		name  string
+		apply func(*models.OpsDashboard)
+		err   error
+	}
+	results := make(chan result, len(sections))
+	for _, section := range sections {
+		go func() {
+			apply, err := section.load(ctx)
+			results <- result{name: section.name, apply: apply, err: err}
+		}()
+	}
+
+	pending := make(map[string]struct{}, len(sections))
+	for _, section := range sections {
+		pending[section.name] = struct{}{}
+	}
+	for len(pending) > 0 {
+		select {
+		case r := <-results:
+			delete(pending, r.name)
+			if r.err != nil {
+				s.log(ctx).Warn("Failed to load ops dashboard section", zap.String("section", r.name), zap.Error(r.err))
+				dashboard.Unavailable = append(dashboard.Unavailable, r.name)
+				continue
+			}
+			r.apply(dashboard)
+		case <-ctx.Done():
+			for _, section := range sections {
+				if _, ok := pending[section.name]; ok {
+					s.log(ctx).Warn("Ops dashboard section timed out", zap.String("section", section.name))
+					dashboard.Unavailable = append(dashboard.Unavailable, section.name)
+				}
+			}
+			return dashboard, nil
+		}
+	}
+
+	return dashboard, nil
+}
+
+func (s *service) opsDashboardSections(since, now time.Time) []opsDashboardSection {
+	return []opsDashboardSection{
+		{name: "sales", load: func(ctx context.Context) (func(*models.OpsDashboard), error) {
+			sales, err := s.order.GetSalesReport(ctx, nil, since, now)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get sales report: %w", err)
+			}
+			return func(d *models.OpsDashboard) {
+				d.RevenueToday = sales
+				for _, currencySales := range sales {
+					d.OrdersToday += currencySales.Orders
+				}
+			}, nil
+		}},
+		{name: "pending_payments", load: func(ctx context.Context) (func(*models.OpsDashboard), error) {
+			count, err := s.order.CountOrdersByStatus(ctx, nil, enum.OrderStatusPending, time.Time{})
+			if err != nil {
+				return nil, fmt.Errorf("failed to count pending orders: %w", err)
+			}
+			return func(d *models.OpsDashboard) { d.PendingPayments = count }, nil
+		}},
+		{name: "failed_payments", load: func(ctx context.Context) (func(*models.OpsDashboard), error) {
+			count, err := s.order.CountOrdersByStatus(ctx, nil, enum.OrderStatusFailed, since)
+			if err != nil {
+				return nil, fmt.Errorf("failed to count failed orders: %w", err)
+			}
+			return func(d *models.OpsDashboard) { d.FailedPayments = count }, nil
+		}},
+		{name: "low_stock", load: func(ctx context.Context) (func(*models.OpsDashboard), error) {
+			stocks, err := s.stock.ListLowStocks(ctx, nil, s.lowStockThreshold, opsDashboardLowStockLimit)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list low stocks: %w", err)
+			}
+			return func(d *models.OpsDashboard) { d.LowStock = stocks }, nil
+		}},
+		{name: "event_backlog", load: func(ctx context.Context) (func(*models.OpsDashboard), error) {
+			backlog, err := s.event.GetBacklog(ctx, now.Add(-eventBacklogGrace))
+			if err != nil {
+				return nil, fmt.Errorf("failed to get event backlog: %w", err)
+			}
+			return func(d *models.OpsDashboard) { d.EventBacklog = backlog }, nil
+		}},
+	}
+}
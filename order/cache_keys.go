@@ -0,0 +1,56 @@
+package order
+
+import (
+	"fmt"
+	"strings"
+)
+
+// orderLookup 為以外部 ID 查詢訂單時的索引種類
+type orderLookup string
+
+const (
+	orderLookupPaymentIntent orderLookup = "payment_intent"
+	orderLookupRefund        orderLookup = "refund"
+	orderLookupInvoice       orderLookup = "invoice"
+	orderLookupSubscription  orderLookup = "subscription"
+)
+
+// orderLookups 為所有索引種類，訂單快取失效時逐一清除
+var orderLookups = []orderLookup{
+	orderLookupPaymentIntent,
+	orderLookupRefund,
+	orderLookupInvoice,
+	orderLookupSubscription,
+}
+
+// key 回傳外部 ID 對應訂單 ID 的索引 key；外部 ID 與訂單的對應不會改變，索引不需要失效
+func (l orderLookup) key(ids ...string) string {
+	return fmt.Sprintf("order:%s:%s", l, strings.Join(ids, ":"))
+}
+
+// orderCacheKey 以訂單 ID 產生該訂單所有快取的 key，讀取與失效共用同一組 key，
+// 以外部 ID 查詢的結果也存在訂單 ID 底下，只知道訂單 ID 的失效路徑才能清除
+type orderCacheKey uint64
+
+func (k orderCacheKey) order() string {
+	return fmt.Sprintf("order:%d", uint64(k))
+}
+
+func (k orderCacheKey) items() string {
+	return fmt.Sprintf("order_items:%d", uint64(k))
+}
+
+// lookup 回傳以外部 ID 查詢到的訂單在訂單 ID 底下的 key
+func (k orderCacheKey) lookup(l orderLookup) string {
+	return fmt.Sprintf("order:%d:%s", uint64(k), l)
+}
+
+// orderKeys 回傳訂單本身與所有索引查詢結果的 key，不含訂單項目
+func (k orderCacheKey) orderKeys() []string {
+	keys := make([]string, 0, len(orderLookups)+1)
+	keys = append(keys, k.order())
+	for _, l := range orderLookups {
+		keys = append(keys, k.lookup(l))
+	}
+	return keys
+}
@@ -0,0 +1,77 @@
+package order
+
+import (
+	"slices"
+	"testing"
+)
+
+// allOrderLookups 列出所有索引種類，新增索引種類時須同時加入此處與 orderLookups
+var allOrderLookups = []orderLookup{
+	orderLookupPaymentIntent,
+	orderLookupRefund,
+	orderLookupInvoice,
+	orderLookupSubscription,
+}
+
+func TestOrderLookupsCoverAllLookups(t *testing.T) {
+	for _, l := range allOrderLookups {
+		if !slices.Contains(orderLookups, l) {
+			t.Errorf("orderLookups is missing %q, its cached orders would never be invalidated", l)
+		}
+	}
+}
+
+// TestOrderKeysInvalidateReadKeys 確認讀取路徑寫入快取的 key 都會被失效路徑清除
+func TestOrderKeysInvalidateReadKeys(t *testing.T) {
+	const orderID = 42
+	keys := orderCacheKey(orderID).orderKeys()
+
+	readKeys := []string{orderCacheKey(orderID).order()}
+	for _, l := range allOrderLookups {
+		readKeys = append(readKeys, orderCacheKey(orderID).lookup(l))
+	}
+
+	for _, key := range readKeys {
+		if !slices.Contains(keys, key) {
+			t.Errorf("orderKeys() = %v, missing read key %q", keys, key)
+		}
+	}
+	if len(keys) != len(readKeys) {
+		t.Errorf("orderKeys() = %v, want exactly %v", keys, readKeys)
+	}
+}
+
+func TestOrderKeysAreUniquePerOrder(t *testing.T) {
+	seen := make(map[string]uint64)
+	for _, orderID := range []uint64{1, 2, 12, 21} {
+		key := orderCacheKey(orderID)
+		for _, k := range append(key.orderKeys(), key.items()) {
+			if other, ok := seen[k]; ok {
+				t.Errorf("key %q is shared by orders %d and %d", k, other, orderID)
+			}
+			seen[k] = orderID
+		}
+	}
+}
+
+// TestOrderLookupKeysDoNotCollide 確認索引 key 不會與訂單 ID 底下的 key 重複，
+// 索引不需要失效，與訂單的 key 重複時會被失效路徑誤刪或覆寫
+func TestOrderLookupKeysDoNotCollide(t *testing.T) {
+	const orderID = 42
+	key := orderCacheKey(orderID)
+	orderKeys := append(key.orderKeys(), key.items())
+
+	seen := make(map[string]orderLookup)
+	for _, l := range allOrderLookups {
+		for _, ids := range [][]string{{"42"}, {"cus_1", "sub_1"}} {
+			lookupKey := l.key(ids...)
+			if slices.Contains(orderKeys, lookupKey) {
+				t.Errorf("lookup key %q collides with a key of order %d", lookupKey, orderID)
+			}
+			if other, ok := seen[lookupKey]; ok {
+				t.Errorf("lookup key %q is shared by %q and %q", lookupKey, other, l)
+			}
+			seen[lookupKey] = l
+		}
+	}
+}
@@ -2,6 +2,8 @@ package order
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/jackc/pgx/v5/pgtype"
 	"go.uber.org/zap"
@@ -9,6 +11,8 @@ import (
 	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stripe/stripe-go/v79"
 	"gofalre.io/shop/driver"
 	"gofalre.io/shop/models"
 	"gofalre.io/shop/models/enum"
@@ -17,35 +21,181 @@ import (
 
 var _ Repository = (*repository)(nil)
 
+// ErrStaleOrder 表示更新時訂單的 updated_at 已被其他交易修改，呼叫端應重新讀取後重試
+var ErrStaleOrder = errors.New("order was modified concurrently")
+
+// ErrOrderNumberTaken 表示訂單編號已被其他訂單（包含封存的訂單）使用，呼叫端應換一個編號重試
+var ErrOrderNumberTaken = errors.New("order number is already taken")
+
+// ErrOrderAddonExists 表示同一個訂單項目（或整筆訂單）已經加購過相同的服務
+var ErrOrderAddonExists = errors.New("order addon already exists")
+
+// ErrExternalOrderExists 表示相同通路與外部編號的訂單已經存在
+var ErrExternalOrderExists = errors.New("external order already exists")
+
+// ErrOrderReturnExists 表示訂單已有處理中的退貨
+var ErrOrderReturnExists = errors.New("order already has an open return")
+
+// ErrOrderRepricingExists 表示同一筆價格變動已對訂單重新計價過
+var ErrOrderRepricingExists = errors.New("order already repriced for the price change")
+
+// ErrTaxExemptionCertificateExists 表示客戶在同一個稅務轄區已登記相同編號的免稅證明
+var ErrTaxExemptionCertificateExists = errors.New("tax exemption certificate already exists")
+
+// ErrCancellationRequestPending 表示訂單已有等待審核的取消申請
+var ErrCancellationRequestPending = errors.New("order already has a pending cancellation request")
+
+// ErrCartSnapshotExists 表示訂單已經記錄購物車快照，快照寫入後不可變更
+var ErrCartSnapshotExists = errors.New("order cart snapshot already exists")
+
 type Repository interface {
 	CreateOrder(ctx context.Context, tx pgx.Tx, order *models.Order) (*models.Order, error)
 	GetOrder(ctx context.Context, tx pgx.Tx, orderID uint64) (*models.Order, error)
+	GetOrderByNumber(ctx context.Context, tx pgx.Tx, orderNumber string) (*models.Order, error)
+	GetOrderByExternalID(ctx context.Context, tx pgx.Tx, source, externalOrderID string) (*models.Order, error)
+	GetOrderForUpdate(ctx context.Context, tx pgx.Tx, orderID uint64) (*models.Order, error)
 	GetOrderByPaymentIntentID(ctx context.Context, tx pgx.Tx, paymentIntentID string) (*models.Order, error)
 	GetOrderByRefundID(ctx context.Context, tx pgx.Tx, chargeID string) (*models.Order, error)
 	GetOrderByInvoiceID(ctx context.Context, tx pgx.Tx, invoiceID string) (*models.Order, error)
 	GetOrderByCustomerIDAndSubscriptionID(ctx context.Context, tx pgx.Tx, customerID, subscriptionID string) (*models.Order, error)
 	UpdateOrderStatus(ctx context.Context, tx pgx.Tx, orderID uint64, status enum.OrderStatus, updatedAt time.Time) error
 	UpdateOrderTotals(ctx context.Context, tx pgx.Tx, orderID uint64, tax, subtotal, discount, total float64, updatedAt time.Time) error
+	UpdateOrderFulfillment(ctx context.Context, tx pgx.Tx, orderID uint64, fulfillmentType enum.FulfillmentType, pickupLocation string, updatedAt time.Time) error
 	ListOrders(ctx context.Context, tx pgx.Tx, customerID string, limit, offset uint64) ([]*models.Order, error)
-	DeleteOrder(ctx context.Context, tx pgx.Tx, orderID uint64) error
-
+	CountOrders(ctx context.Context, tx pgx.Tx, customerID string) (uint64, error)
+	CountOrdersByStatus(ctx context.Context, tx pgx.Tx, status enum.OrderStatus, since time.Time) (uint64, error)
+	StreamOrders(ctx context.Context, tx pgx.Tx, filter models.OrderFilter, fn func(*models.Order) error) error
+	StreamRevenueEvents(ctx context.Context, tx pgx.Tx, from, to time.Time, statuses []enum.OrderStatus, fn func(*models.RevenueEvent) error) error
+	DeleteOrder(ctx context.Context, tx pgx.Tx, orderID uint64) (bool, error)
+	RestoreOrder(ctx context.Context, tx pgx.Tx, orderID uint64) (bool, error)
+	PurgeDeletedOrders(ctx context.Context, tx pgx.Tx, cutoff time.Time, batchSize int32) (int64, error)
+
+	// AddOrderItems 批次寫入訂單項目，寫入後設定每個項目的 ID
 	AddOrderItems(ctx context.Context, tx pgx.Tx, items []*models.OrderItem) error
 	ListOrderItems(ctx context.Context, tx pgx.Tx, orderID uint64) ([]*models.OrderItem, error)
 	UpdateOrderItem(ctx context.Context, tx pgx.Tx, item *models.OrderItem) error
 	DeleteOrderItem(ctx context.Context, tx pgx.Tx, orderItemID uint64) error
+
+	CreateOrderAddon(ctx context.Context, tx pgx.Tx, addon *models.OrderAddon) error
+	ListOrderAddons(ctx context.Context, tx pgx.Tx, orderID uint64) ([]*models.OrderAddon, error)
+	DeleteOrderAddon(ctx context.Context, tx pgx.Tx, orderID, addonID uint64) (*models.OrderAddon, error)
+
+	ArchiveOrders(ctx context.Context, tx pgx.Tx, cutoff time.Time, batchSize int32) (int64, error)
+	MergeOrderMetadata(ctx context.Context, tx pgx.Tx, orderID uint64, metadata map[string]string) error
+	SetOrderTaxCalculation(ctx context.Context, tx pgx.Tx, orderID uint64, tax float64, calculationID string) error
+	SetOrderTaxTransaction(ctx context.Context, tx pgx.Tx, orderID uint64, transactionID string) (bool, error)
+	ListOrdersPendingTaxCommit(ctx context.Context, tx pgx.Tx, before time.Time, limit int32) (map[uint64]string, error)
+	FindOrdersByMetadata(ctx context.Context, tx pgx.Tx, metadata map[string]string, limit, offset uint64) ([]*models.Order, error)
+	SearchOrderIDs(ctx context.Context, tx pgx.Tx, pattern string, limit, offset uint64) ([]uint64, error)
+	CountSearchOrders(ctx context.Context, tx pgx.Tx, pattern string) (uint64, error)
+	SetOrderReportingSnapshot(ctx context.Context, tx pgx.Tx, orderID uint64, reporting *models.ReportingAmounts) error
+	SetOrderDeliveryEstimate(ctx context.Context, tx pgx.Tx, orderID uint64, window models.DeliveryWindow) error
+	SetOrderExternalID(ctx context.Context, tx pgx.Tx, orderID uint64, source, externalOrderID string) error
+	SetOrderPromotions(ctx context.Context, tx pgx.Tx, orderID uint64, promotions []*models.AppliedPromotion) error
+	SetOrderChannel(ctx context.Context, tx pgx.Tx, orderID uint64, channel string) error
+	SetOrderCartSnapshot(ctx context.Context, tx pgx.Tx, orderID uint64, snapshot *models.CartSnapshot) error
+	GetOrderPaymentReference(ctx context.Context, tx pgx.Tx, orderID uint64) (*models.OrderPaymentReference, error)
+	GetOrderReceiptLinks(ctx context.Context, tx pgx.Tx, orderID uint64) (*models.OrderReceiptLinks, error)
+	SetOrderReceiptLinks(ctx context.Context, tx pgx.Tx, links *models.OrderReceiptLinks) error
+	GetSalesReport(ctx context.Context, tx pgx.Tx, from, to time.Time) ([]*models.CurrencySales, error)
+	GetChannelSalesReport(ctx context.Context, tx pgx.Tx, from, to time.Time) ([]*models.ChannelSales, error)
+	ListCustomerOrderStats(ctx context.Context, tx pgx.Tx, since time.Time) ([]*models.CustomerOrderStats, error)
+
+	ClaimOrderIdempotencyKey(ctx context.Context, tx pgx.Tx, key, requestHash string, expiredBefore time.Time) (bool, error)
+	GetOrderIdempotencyKey(ctx context.Context, tx pgx.Tx, key string) (*models.OrderIdempotencyKey, error)
+	SetOrderIdempotencyKeyOrder(ctx context.Context, tx pgx.Tx, key string, orderID uint64) error
+
+	CreateOrderHold(ctx context.Context, tx pgx.Tx, orderID uint64, reason string, previousStatus enum.OrderStatus) (*models.OrderHold, error)
+	GetActiveOrderHold(ctx context.Context, tx pgx.Tx, orderID uint64) (*models.OrderHold, error)
+	ReleaseOrderHold(ctx context.Context, tx pgx.Tx, holdID uint64) (bool, error)
+	ListOrderHolds(ctx context.Context, tx pgx.Tx, orderID uint64) ([]*models.OrderHold, error)
+
+	RecordPaymentFingerprint(ctx context.Context, tx pgx.Tx, paymentIntentID, fingerprint, customerID string, orderID uint64) error
+	CountFingerprintCustomers(ctx context.Context, tx pgx.Tx, fingerprint string, since time.Time) (uint64, error)
+	CreateFraudReview(ctx context.Context, tx pgx.Tx, orderID uint64, reason enum.FraudReviewReason, detail string) (bool, error)
+	GetFraudReview(ctx context.Context, tx pgx.Tx, reviewID uint64) (*models.FraudReview, error)
+	ListFraudReviewsByStatus(ctx context.Context, tx pgx.Tx, status enum.FraudReviewStatus) ([]*models.FraudReview, error)
+	ResolveFraudReview(ctx context.Context, tx pgx.Tx, reviewID uint64, status enum.FraudReviewStatus, resolvedBy, note string) (bool, error)
+
+	CreateShipment(ctx context.Context, tx pgx.Tx, orderID uint64, location string) (*models.Shipment, error)
+	GetShipment(ctx context.Context, tx pgx.Tx, shipmentID uint64) (*models.Shipment, error)
+	ListShipments(ctx context.Context, tx pgx.Tx, orderID uint64) ([]*models.Shipment, error)
+	ShipOrderItems(ctx context.Context, tx pgx.Tx, orderID uint64, location string) error
+	ReturnShippedOrderItems(ctx context.Context, tx pgx.Tx, orderID uint64) error
+	AddOrderItemReturnedQuantity(ctx context.Context, tx pgx.Tx, orderID, orderItemID, quantity uint64) error
+
+	CreateRefund(ctx context.Context, tx pgx.Tx, refund *models.Refund) error
+	ListRefunds(ctx context.Context, tx pgx.Tx, orderID uint64) ([]*models.Refund, error)
+	SetOrderRefund(ctx context.Context, tx pgx.Tx, orderID uint64, refundID string, status enum.OrderStatus, updatedAt time.Time) error
+
+	NextInvoiceSequenceNumber(ctx context.Context, tx pgx.Tx, tenant, country string, invoiceType enum.InvoiceType) (uint64, error)
+	CreateInvoice(ctx context.Context, tx pgx.Tx, invoice *models.Invoice) error
+	GetInvoice(ctx context.Context, tx pgx.Tx, invoiceID uint64) (*models.Invoice, error)
+	GetOrderInvoice(ctx context.Context, tx pgx.Tx, orderID uint64) (*models.Invoice, error)
+	ListInvoices(ctx context.Context, tx pgx.Tx, orderID uint64) ([]*models.Invoice, error)
+	SetInvoiceDocument(ctx context.Context, tx pgx.Tx, invoiceID uint64, documentURL string) error
+	ListInvoiceNumberGaps(ctx context.Context, tx pgx.Tx) ([]*models.InvoiceNumberGap, error)
+
+	CreateTaxExemptionCertificate(ctx context.Context, tx pgx.Tx, certificate *models.TaxExemptionCertificate) (*models.TaxExemptionCertificate, error)
+	ListTaxExemptionCertificates(ctx context.Context, tx pgx.Tx, customerID string) ([]*models.TaxExemptionCertificate, error)
+	GetActiveTaxExemptionCertificate(ctx context.Context, tx pgx.Tx, customerID, jurisdiction string, at time.Time) (*models.TaxExemptionCertificate, error)
+	RevokeTaxExemptionCertificate(ctx context.Context, tx pgx.Tx, customerID string, certificateID uint64) (bool, error)
+	SetOrderTaxExemption(ctx context.Context, tx pgx.Tx, orderID uint64, certificate *models.TaxExemptionCertificate) error
+	GetOrderTaxExemptionNumber(ctx context.Context, tx pgx.Tx, orderID uint64) (string, error)
+
+	CreateOrderReturn(ctx context.Context, tx pgx.Tx, orderID uint64, reason string) (*models.OrderReturn, error)
+	GetOrderReturn(ctx context.Context, tx pgx.Tx, returnID uint64) (*models.OrderReturn, error)
+	GetOrderReturnForUpdate(ctx context.Context, tx pgx.Tx, returnID uint64) (*models.OrderReturn, error)
+	GetOrderReturnIDByTracking(ctx context.Context, tx pgx.Tx, carrier, trackingNumber string) (uint64, error)
+	ListOrderReturns(ctx context.Context, tx pgx.Tx, orderID uint64) ([]*models.OrderReturn, error)
+	ListTrackedOrderReturns(ctx context.Context, tx pgx.Tx, afterID uint64, limit int) ([]*models.OrderReturn, error)
+	UpdateOrderReturnStatus(ctx context.Context, tx pgx.Tx, returnID uint64, status enum.ReturnStatus) error
+	SetOrderReturnLabel(ctx context.Context, tx pgx.Tx, returnID uint64, label *models.ReturnLabel) (bool, error)
+
+	CreateOrderCancellationRequest(ctx context.Context, tx pgx.Tx, orderID uint64, customerID string, reason enum.CancellationReason, status enum.CancellationRequestStatus) (*models.OrderCancellationRequest, error)
+	GetOrderCancellationRequestForUpdate(ctx context.Context, tx pgx.Tx, requestID uint64) (*models.OrderCancellationRequest, error)
+	ListPendingOrderCancellationRequests(ctx context.Context, tx pgx.Tx) ([]*models.OrderCancellationRequest, error)
+	ResolveOrderCancellationRequest(ctx context.Context, tx pgx.Tx, requestID uint64, status enum.CancellationRequestStatus, reviewedBy string) (bool, error)
+	GetCancellationReasonStats(ctx context.Context, tx pgx.Tx, from, to time.Time) ([]*models.CancellationReasonStats, error)
+
+	ListPendingOrderIDsByPrice(ctx context.Context, tx pgx.Tx, priceID string, currency stripe.Currency, unitPrice float64) ([]uint64, error)
+	CreateOrderRepricing(ctx context.Context, tx pgx.Tx, repricing *models.OrderRepricing) error
+	GetOrderRepricingForUpdate(ctx context.Context, tx pgx.Tx, repricingID uint64) (*models.OrderRepricing, error)
+	ListOrderRepricings(ctx context.Context, tx pgx.Tx, orderID uint64) ([]*models.OrderRepricing, error)
+	ListPendingOrderRepricings(ctx context.Context, tx pgx.Tx) ([]*models.OrderRepricing, error)
+	ResolveOrderRepricing(ctx context.Context, tx pgx.Tx, repricingID uint64, status enum.OrderRepricingStatus, reviewedBy string, newTotal float64) (bool, error)
+
+	StartOrderFulfillmentSLA(ctx context.Context, tx pgx.Tx, sla *models.OrderFulfillmentSLA) error
+	CompleteOrderFulfillmentSLA(ctx context.Context, tx pgx.Tx, orderID uint64, fulfilledAt time.Time) (bool, error)
+	GetOrderFulfillmentSLA(ctx context.Context, tx pgx.Tx, orderID uint64) (*models.OrderFulfillmentSLA, error)
+	ListOrdersAtRiskOfSLABreach(ctx context.Context, tx pgx.Tx, now time.Time, statuses []enum.OrderStatus) ([]*models.OrderFulfillmentSLA, error)
+	MarkFulfillmentSLAAtRiskAlerted(ctx context.Context, tx pgx.Tx, orderID uint64) (bool, error)
+	MarkFulfillmentSLABreachAlerted(ctx context.Context, tx pgx.Tx, orderID uint64) (bool, error)
+
+	ListUnshippedOrderIDsByProduct(ctx context.Context, tx pgx.Tx, productID string, statuses []enum.OrderStatus) ([]uint64, error)
+
+	ListOrphanedOrderItems(ctx context.Context, tx pgx.Tx) ([]*models.OrphanedItem, error)
+	ListOrderTotalMismatches(ctx context.Context, tx pgx.Tx) ([]*models.TotalMismatch, error)
 }
 
 type repository struct {
-	conn   driver.PostgresPool
-	cache  *ember.Ember
-	logger *zap.Logger
+	queries *driver.Queries
+	cache   *ember.Ember
+	shadow  *driver.CacheShadow
+	logger  *zap.Logger
+}
+
+func NewRepository(conn driver.PostgresPool, cache *ember.Ember, logger *zap.Logger, opts ...driver.RepositoryOption) Repository {
+	return NewRepositoryWithQuerier(sqlc.New(conn), cache, logger, opts...)
 }
 
-func NewRepository(conn driver.PostgresPool, cache *ember.Ember, logger *zap.Logger) Repository {
+// NewRepositoryWithQuerier 以注入的 sqlc.Querier 建立 repository，交易中的查詢見 driver.TxBinder
+func NewRepositoryWithQuerier(querier sqlc.Querier, cache *ember.Ember, logger *zap.Logger, opts ...driver.RepositoryOption) Repository {
 	return &repository{
-		conn:   conn,
-		cache:  cache,
-		logger: logger,
+		queries: driver.NewQueries(querier),
+		cache:   cache,
+		shadow:  driver.NewRepositoryOptions(opts...).CacheShadow,
+		logger:  logger,
 	}
 }
 
@@ -54,25 +204,37 @@ func (r *repository) CreateOrder(ctx context.Context, tx pgx.Tx, order *models.O
 	if order.CartID != nil {
 		cartID = *order.CartID
 	}
-	sqlcOrder, err := sqlc.New(r.conn).WithTx(tx).CreateOrder(ctx, sqlc.CreateOrderParams{
-		CustomerID: order.CustomerID,
-		CartID:     cartID,
-		Status:     sqlc.OrderStatus(order.Status),
-		Currency:   sqlc.Currency(order.Currency),
-		Subtotal:   order.Subtotal,
-		Tax:        order.Tax,
-		Total:      order.Total,
-		Discount:   order.Discount,
+	sqlcOrder, err := r.queries.WithTx(tx).CreateOrder(ctx, sqlc.CreateOrderParams{
+		OrderNumber: order.OrderNumber,
+		CustomerID:  order.CustomerID,
+		CartID:      cartID,
+		Status:      sqlc.OrderStatus(order.Status),
+		Currency:    sqlc.Currency(order.Currency),
+		Subtotal:    order.Subtotal,
+		Tax:         order.Tax,
+		Total:       order.Total,
+		Discount:    order.Discount,
+
+		ShippingAddress: addressJSON(order.ShippingAddress),
+		BillingAddress:  addressJSON(order.BillingAddress),
 	})
 	if err != nil {
+		// 編號重複時不會寫入任何資料列
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrOrderNumberTaken
+		}
 		r.logger.Error("Failed to create order", zap.Error(err))
 		return nil, err
 	}
 
-	createdOrder := new(models.Order).ConvertSqlcOrder(sqlcOrder)
+	// 回寫資料庫產生的欄位，呼叫端會繼續使用傳入的訂單（例如以訂單 ID 建立訂單項目）
+	order.ID = uint64(sqlcOrder.ID)
+	order.UpdatedAt = sqlcOrder.UpdatedAt.Time
+	order.CreatedAt = order.UpdatedAt // 兩者皆為 NOW()，同一交易內相同
+	createdOrder := order
 
 	// 更新快取
-	cacheKey := fmt.Sprintf("order:%d", createdOrder.ID)
+	cacheKey := orderCacheKey(createdOrder.ID).order()
 	if err := r.cache.Set(ctx, cacheKey, createdOrder, 30*time.Minute); err != nil {
 		r.logger.Warn("Failed to cache order", zap.Error(err))
 	}
@@ -81,7 +243,7 @@ func (r *repository) CreateOrder(ctx context.Context, tx pgx.Tx, order *models.O
 }
 
 func (r *repository) GetOrder(ctx context.Context, tx pgx.Tx, orderID uint64) (*models.Order, error) {
-	cacheKey := fmt.Sprintf("order:%d", orderID)
+	cacheKey := orderCacheKey(orderID).order()
 	var order models.Order
 
 	// 嘗試從快取中獲取
@@ -90,17 +252,43 @@ func (r *repository) GetOrder(ctx context.Context, tx pgx.Tx, orderID uint64) (*
 		r.logger.Warn("Failed to get order from cache", zap.Error(err))
 	}
 	if found {
+		r.shadow.Verify(ctx, cacheKey, &order, func(ctx context.Context) (any, error) {
+			queries := r.queries.Querier()
+			sqlcOrder, err := queries.GetOrder(ctx, int32(orderID))
+			if errors.Is(err, pgx.ErrNoRows) {
+				sqlcArchivedOrder, err := queries.GetArchivedOrder(ctx, int32(orderID))
+				if err != nil {
+					return nil, err
+				}
+				return new(models.Order).ConvertSqlcOrder(sqlcArchivedOrder), nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			return new(models.Order).ConvertSqlcOrder(sqlcOrder), nil
+		})
 		return &order, nil
 	}
 
-	sqlcOrder, err := sqlc.New(r.conn).WithTx(tx).GetOrder(ctx, int32(orderID))
-	if err != nil {
+	sqlcOrder, err := r.queries.WithTx(tx).GetOrder(ctx, int32(orderID))
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		// 不在 orders 中時改查封存表，封存的訂單為唯讀
+		sqlcArchivedOrder, err := r.queries.WithTx(tx).GetArchivedOrder(ctx, int32(orderID))
+		if err != nil {
+			if !errors.Is(err, pgx.ErrNoRows) {
+				r.logger.Error("Failed to get archived order", zap.Error(err))
+			}
+			return nil, err
+		}
+		order = *new(models.Order).ConvertSqlcOrder(sqlcArchivedOrder)
+	case err != nil:
 		r.logger.Error("Failed to get order", zap.Error(err))
 		return nil, err
+	default:
+		order = *new(models.Order).ConvertSqlcOrder(sqlcOrder)
 	}
 
-	order = *new(models.Order).ConvertSqlcOrder(sqlcOrder)
-
 	// 更新快取
 	if err := r.cache.Set(ctx, cacheKey, order, 30*time.Minute); err != nil {
 		r.logger.Warn("Failed to cache order", zap.Error(err))
@@ -109,95 +297,100 @@ func (r *repository) GetOrder(ctx context.Context, tx pgx.Tx, orderID uint64) (*
 	return &order, nil
 }
 
-func (r *repository) GetOrderByPaymentIntentID(ctx context.Context, tx pgx.Tx, paymentIntentID string) (*models.Order, error) {
-	cacheKey := fmt.Sprintf("order:payment_intent:%s", paymentIntentID)
-	var order models.Order
-
-	// 嘗試從快取中獲取
-	found, err := r.cache.Get(ctx, cacheKey, &order)
+// GetOrderForUpdate 略過快取直接從資料庫讀取訂單並鎖定該列，供後續以 updated_at 做樂觀更新
+// GetOrderByNumber 以訂單編號查詢訂單，包含已封存的訂單
+func (r *repository) GetOrderByNumber(ctx context.Context, tx pgx.Tx, orderNumber string) (*models.Order, error) {
+	orderID, err := r.queries.WithTx(tx).GetOrderIDByNumber(ctx, orderNumber)
 	if err != nil {
-		r.logger.Warn("Failed to get order by payment intent from cache", zap.Error(err))
-	}
-	if found {
-		return &order, nil
+		if !errors.Is(err, pgx.ErrNoRows) {
+			r.logger.Error("Failed to get order by number", zap.String("order_number", orderNumber), zap.Error(err))
+		}
+		return nil, err
 	}
 
-	sqlcOrder, err := sqlc.New(r.conn).WithTx(tx).GetOrderByPaymentIntentID(ctx, &paymentIntentID)
+	return r.GetOrder(ctx, tx, uint64(orderID))
+}
+
+// GetOrderByExternalID 以外部通路與該通路的訂單編號查詢訂單，包含已封存的訂單
+func (r *repository) GetOrderByExternalID(ctx context.Context, tx pgx.Tx, source, externalOrderID string) (*models.Order, error) {
+	orderID, err := r.queries.WithTx(tx).GetOrderIDByExternalID(ctx, sqlc.GetOrderIDByExternalIDParams{
+		ExternalSource:  &source,
+		ExternalOrderID: &externalOrderID,
+	})
 	if err != nil {
-		r.logger.Error("Failed to get order by payment intent", zap.Error(err))
+		if !errors.Is(err, pgx.ErrNoRows) {
+			r.logger.Error("Failed to get order by external id",
+				zap.String("external_source", source), zap.String("external_order_id", externalOrderID), zap.Error(err))
+		}
 		return nil, err
 	}
 
-	order = *new(models.Order).ConvertSqlcOrder(sqlcOrder)
+	return r.GetOrder(ctx, tx, uint64(orderID))
+}
 
-	// 更新快取
-	if err := r.cache.Set(ctx, cacheKey, order, 30*time.Minute); err != nil {
-		r.logger.Warn("Failed to cache order by payment intent", zap.Error(err))
+func (r *repository) GetOrderForUpdate(ctx context.Context, tx pgx.Tx, orderID uint64) (*models.Order, error) {
+	sqlcOrder, err := r.queries.WithTx(tx).GetOrderForUpdate(ctx, int32(orderID))
+	if err != nil {
+		r.logger.Error("Failed to get order for update", zap.Uint64("order_id", orderID), zap.Error(err))
+		return nil, err
 	}
 
-	return &order, nil
+	return new(models.Order).ConvertSqlcOrder(sqlcOrder), nil
 }
 
-func (r *repository) GetOrderByRefundID(ctx context.Context, tx pgx.Tx, chargeID string) (*models.Order, error) {
-	cacheKey := fmt.Sprintf("order:refund:%s", chargeID)
-	var order models.Order
-
-	// 嘗試從快取中獲取
-	found, err := r.cache.Get(ctx, cacheKey, &order)
+func (r *repository) GetOrderByPaymentIntentID(ctx context.Context, tx pgx.Tx, paymentIntentID string) (*models.Order, error) {
+	order, err := r.getOrderByLookup(ctx, tx, orderLookupPaymentIntent, orderLookupPaymentIntent.key(paymentIntentID),
+		func(ctx context.Context, querier sqlc.Querier) (*models.Order, error) {
+			sqlcOrder, err := querier.GetOrderByPaymentIntentID(ctx, &paymentIntentID)
+			if err != nil {
+				return nil, err
+			}
+			return new(models.Order).ConvertSqlcOrder(sqlcOrder), nil
+		})
 	if err != nil {
-		r.logger.Warn("Failed to get order by refund from cache", zap.Error(err))
-	}
-	if found {
-		return &order, nil
+		r.logger.Error("Failed to get order by payment intent", zap.Error(err))
+		return nil, err
 	}
 
-	sqlcOrder, err := sqlc.New(r.conn).WithTx(tx).GetOrderByRefundID(ctx, &chargeID)
+	return order, nil
+}
+
+func (r *repository) GetOrderByRefundID(ctx context.Context, tx pgx.Tx, chargeID string) (*models.Order, error) {
+	order, err := r.getOrderByLookup(ctx, tx, orderLookupRefund, orderLookupRefund.key(chargeID),
+		func(ctx context.Context, querier sqlc.Querier) (*models.Order, error) {
+			sqlcOrder, err := querier.GetOrderByRefundID(ctx, &chargeID)
+			if err != nil {
+				return nil, err
+			}
+			return new(models.Order).ConvertSqlcOrder(sqlcOrder), nil
+		})
 	if err != nil {
 		r.logger.Error("Failed to get order by refund", zap.Error(err))
 		return nil, err
 	}
 
-	order = *new(models.Order).ConvertSqlcOrder(sqlcOrder)
-
-	// 更新快取
-	if err := r.cache.Set(ctx, cacheKey, order, 30*time.Minute); err != nil {
-		r.logger.Warn("Failed to cache order by refund", zap.Error(err))
-	}
-
-	return &order, nil
+	return order, nil
 }
 
 func (r *repository) GetOrderByInvoiceID(ctx context.Context, tx pgx.Tx, invoiceID string) (*models.Order, error) {
-	cacheKey := fmt.Sprintf("order:invoice:%s", invoiceID)
-	var order models.Order
-
-	// 嘗試從快取中獲取
-	found, err := r.cache.Get(ctx, cacheKey, &order)
-	if err != nil {
-		r.logger.Warn("Failed to get order by invoice from cache", zap.Error(err))
-	}
-	if found {
-		return &order, nil
-	}
-
-	sqlcOrder, err := sqlc.New(r.conn).WithTx(tx).GetOrderByInvoiceID(ctx, &invoiceID)
+	order, err := r.getOrderByLookup(ctx, tx, orderLookupInvoice, orderLookupInvoice.key(invoiceID),
+		func(ctx context.Context, querier sqlc.Querier) (*models.Order, error) {
+			sqlcOrder, err := querier.GetOrderByInvoiceID(ctx, &invoiceID)
+			if err != nil {
+				return nil, err
+			}
+			return new(models.Order).ConvertSqlcOrder(sqlcOrder), nil
+		})
 	if err != nil {
 		r.logger.Error("Failed to get order by invoice", zap.Error(err))
 		return nil, err
 	}
 
-	order = *new(models.Order).ConvertSqlcOrder(sqlcOrder)
-
-	// 更新快取
-	if err := r.cache.Set(ctx, cacheKey, order, 30*time.Minute); err != nil {
-		r.logger.Warn("Failed to cache order by invoice", zap.Error(err))
-	}
-
-	return &order, nil
+	return order, nil
 }
 
 func (r *repository) UpdateOrderStatus(ctx context.Context, tx pgx.Tx, orderID uint64, status enum.OrderStatus, updatedAt time.Time) error {
-	err := sqlc.New(r.conn).WithTx(tx).UpdateOrderStatus(ctx, sqlc.UpdateOrderStatusParams{
+	rows, err := r.queries.WithTx(tx).UpdateOrderStatus(ctx, sqlc.UpdateOrderStatusParams{
 		ID:        int32(orderID),
 		Status:    sqlc.OrderStatus(status),
 		UpdatedAt: pgtype.Timestamptz{Time: updatedAt, Valid: true},
@@ -206,6 +399,10 @@ func (r *repository) UpdateOrderStatus(ctx context.Context, tx pgx.Tx, orderID u
 		r.logger.Error("Failed to update order status", zap.Error(err))
 		return err
 	}
+	if rows == 0 {
+		r.logger.Warn("Order status update conflicted with a concurrent change", zap.Uint64("order_id", orderID))
+		return ErrStaleOrder
+	}
 
 	// 使相關的快取失效
 	r.invalidateOrderCache(ctx, orderID)
@@ -213,7 +410,7 @@ func (r *repository) UpdateOrderStatus(ctx context.Context, tx pgx.Tx, orderID u
 }
 
 func (r *repository) UpdateOrderTotals(ctx context.Context, tx pgx.Tx, orderID uint64, tax, subtotal, discount, total float64, updatedAt time.Time) error {
-	err := sqlc.New(r.conn).WithTx(tx).UpdateOrderTotals(ctx, sqlc.UpdateOrderTotalsParams{
+	rows, err := r.queries.WithTx(tx).UpdateOrderTotals(ctx, sqlc.UpdateOrderTotalsParams{
 		ID:        int32(orderID),
 		Tax:       tax,
 		Subtotal:  subtotal,
@@ -225,42 +422,111 @@ func (r *repository) UpdateOrderTotals(ctx context.Context, tx pgx.Tx, orderID u
 		r.logger.Error("Failed to update order totals", zap.Error(err))
 		return err
 	}
+	if rows == 0 {
+		r.logger.Warn("Order totals update conflicted with a concurrent change", zap.Uint64("order_id", orderID))
+		return ErrStaleOrder
+	}
 
 	// 使相關的快取失效
 	r.invalidateOrderCache(ctx, orderID)
 	return nil
 }
 
-func (r *repository) GetOrderByCustomerIDAndSubscriptionID(ctx context.Context, tx pgx.Tx, subscriptionID, customerID string) (*models.Order, error) {
-	cacheKey := fmt.Sprintf("order:customer:%s:subscription:%s", customerID, subscriptionID)
-	var order models.Order
+func (r *repository) UpdateOrderFulfillment(ctx context.Context, tx pgx.Tx, orderID uint64, fulfillmentType enum.FulfillmentType, pickupLocation string, updatedAt time.Time) error {
+	var location *string
+	if pickupLocation != "" {
+		location = &pickupLocation
+	}
 
-	// 嘗試從快取中獲取
-	found, err := r.cache.Get(ctx, cacheKey, &order)
+	rows, err := r.queries.WithTx(tx).UpdateOrderFulfillment(ctx, sqlc.UpdateOrderFulfillmentParams{
+		ID:              int32(orderID),
+		FulfillmentType: sqlc.FulfillmentType(fulfillmentType),
+		PickupLocation:  location,
+		UpdatedAt:       pgtype.Timestamptz{Time: updatedAt, Valid: true},
+	})
 	if err != nil {
-		r.logger.Warn("Failed to get order by customer and subscription from cache", zap.Error(err))
+		r.logger.Error("Failed to update order fulfillment", zap.Error(err))
+		return err
 	}
-	if found {
-		return &order, nil
+	if rows == 0 {
+		r.logger.Warn("Order fulfillment update conflicted with a concurrent change", zap.Uint64("order_id", orderID))
+		return ErrStaleOrder
 	}
 
-	sqlcOrder, err := sqlc.New(r.conn).WithTx(tx).GetOrderByCustomerIDAndSubscriptionID(ctx, sqlc.GetOrderByCustomerIDAndSubscriptionIDParams{
+	// 使相關的快取失效
+	r.invalidateOrderCache(ctx, orderID)
+	return nil
+}
+
+func (r *repository) GetOrderByCustomerIDAndSubscriptionID(ctx context.Context, tx pgx.Tx, subscriptionID, customerID string) (*models.Order, error) {
+	params := sqlc.GetOrderByCustomerIDAndSubscriptionIDParams{
 		CustomerID:     customerID,
 		SubscriptionID: &subscriptionID,
-	})
+	}
+	order, err := r.getOrderByLookup(ctx, tx, orderLookupSubscription, orderLookupSubscription.key(customerID, subscriptionID),
+		func(ctx context.Context, querier sqlc.Querier) (*models.Order, error) {
+			sqlcOrder, err := querier.GetOrderByCustomerIDAndSubscriptionID(ctx, params)
+			if err != nil {
+				return nil, err
+			}
+			return new(models.Order).ConvertSqlcOrder(sqlcOrder), nil
+		})
 	if err != nil {
 		r.logger.Error("Failed to get order by customer and subscription", zap.Error(err))
 		return nil, err
 	}
 
-	order = *new(models.Order).ConvertSqlcOrder(sqlcOrder)
+	return order, nil
+}
+
+// orderLookupEntry 為以外部 ID 查詢到的訂單快取，Key 記錄查詢時的索引 key，
+// 訂單改用其他外部 ID 後，舊索引仍指向同一個訂單 ID，但不會讀到以新外部 ID 查詢的結果
+type orderLookupEntry struct {
+	Key   string        `json:"key"`
+	Order *models.Order `json:"order"`
+}
 
-	// 更新快取
-	if err := r.cache.Set(ctx, cacheKey, order, 30*time.Minute); err != nil {
-		r.logger.Warn("Failed to cache order by customer and subscription", zap.Error(err))
+// getOrderByLookup 以外部 ID 查詢訂單：先由索引 key 取得訂單 ID，再讀取存在訂單 ID 底下的查詢結果，
+// 該結果會隨 invalidateOrderCache 失效；未命中時以 load 從資料庫讀取，先寫入索引再寫入結果，重複寫入的內容相同
+func (r *repository) getOrderByLookup(ctx context.Context, tx pgx.Tx, lookup orderLookup, lookupKey string, load func(ctx context.Context, querier sqlc.Querier) (*models.Order, error)) (*models.Order, error) {
+	// 1. 由索引取得訂單 ID，再讀取訂單 ID 底下的查詢結果
+	var orderID uint64
+	found, err := r.cache.Get(ctx, lookupKey, &orderID)
+	if err != nil {
+		r.logger.Warn("Failed to get order lookup from cache", zap.String("key", lookupKey), zap.Error(err))
+	}
+	if found {
+		entryKey := orderCacheKey(orderID).lookup(lookup)
+		var entry orderLookupEntry
+		found, err = r.cache.Get(ctx, entryKey, &entry)
+		if err != nil {
+			r.logger.Warn("Failed to get order from cache", zap.String("key", entryKey), zap.Error(err))
+		}
+		if found && entry.Key == lookupKey && entry.Order != nil {
+			r.shadow.Verify(ctx, entryKey, entry.Order, func(ctx context.Context) (any, error) {
+				return load(ctx, r.queries.Querier())
+			})
+			return entry.Order, nil
+		}
 	}
 
-	return &order, nil
+	// 2. 從資料庫讀取
+	order, err := load(ctx, r.queries.WithTx(tx))
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. 先寫入索引再寫入查詢結果，結果失效後索引仍可重複使用
+	if err := r.cache.Set(ctx, lookupKey, order.ID, 30*time.Minute); err != nil {
+		r.logger.Warn("Failed to cache order lookup", zap.String("key", lookupKey), zap.Error(err))
+		return order, nil
+	}
+	entryKey := orderCacheKey(order.ID).lookup(lookup)
+	if err := r.cache.Set(ctx, entryKey, orderLookupEntry{Key: lookupKey, Order: order}, 30*time.Minute); err != nil {
+		r.logger.Warn("Failed to cache order", zap.String("key", entryKey), zap.Error(err))
+	}
+
+	return order, nil
 }
 
 func (r *repository) ListOrders(ctx context.Context, tx pgx.Tx, customerID string, limit, offset uint64) ([]*models.Order, error) {
@@ -272,15 +538,27 @@ func (r *repository) ListOrders(ctx context.Context, tx pgx.Tx, customerID strin
 	if err != nil {
 		r.logger.Warn("Failed to get orders from cache", zap.Error(err))
 	}
+	params := sqlc.ListOrdersParams{
+		CustomerID: customerID,
+		Limit:      int64(limit),
+		Offset:     int64(offset),
+	}
 	if found {
+		r.shadow.Verify(ctx, cacheKey, &orders, func(ctx context.Context) (any, error) {
+			sqlcOrders, err := r.queries.Querier().ListOrders(ctx, params)
+			if err != nil {
+				return nil, err
+			}
+			results := make([]*models.Order, 0, len(sqlcOrders))
+			for _, sqlcOrder := range sqlcOrders {
+				results = append(results, new(models.Order).ConvertSqlcOrder(sqlcOrder))
+			}
+			return results, nil
+		})
 		return orders, nil
 	}
 
-	sqlcOrders, err := sqlc.New(r.conn).WithTx(tx).ListOrders(ctx, sqlc.ListOrdersParams{
-		CustomerID: customerID,
-		Limit:      int64(limit),
-		Offset:     int64(offset),
-	})
+	sqlcOrders, err := r.queries.WithTx(tx).ListOrders(ctx, params)
 	if err != nil {
 		r.logger.Error("Failed to list orders", zap.Error(err))
 		return nil, err
@@ -299,16 +577,164 @@ func (r *repository) ListOrders(ctx context.Context, tx pgx.Tx, customerID strin
 	return orders, nil
 }
 
-func (r *repository) DeleteOrder(ctx context.Context, tx pgx.Tx, orderID uint64) error {
-	err := sqlc.New(r.conn).WithTx(tx).DeleteOrder(ctx, int32(orderID))
+// StreamOrders 依 filter 逐筆讀取訂單並交給 fn 處理，不會一次載入所有結果也不經過快取；
+// fn 執行時交易仍在讀取結果，不可在 fn 中使用同一個交易查詢，訂單不包含項目
+func (r *repository) StreamOrders(ctx context.Context, tx pgx.Tx, filter models.OrderFilter, fn func(*models.Order) error) error {
+	params := sqlc.ListOrdersByFilterParams{
+		Statuses:    make([]string, 0, len(filter.Statuses)),
+		CreatedFrom: pgtype.Timestamptz{Time: filter.CreatedFrom, Valid: !filter.CreatedFrom.IsZero()},
+		CreatedTo:   pgtype.Timestamptz{Time: filter.CreatedTo, Valid: !filter.CreatedTo.IsZero()},
+	}
+	if filter.CustomerID != "" {
+		params.CustomerID = &filter.CustomerID
+	}
+	for _, status := range filter.Statuses {
+		params.Statuses = append(params.Statuses, string(status))
+	}
+
+	streamer, err := r.queries.Streamer(tx)
+	if err != nil {
+		r.logger.Error("Failed to stream orders", zap.Error(err))
+		return err
+	}
+
+	err = streamer.StreamOrdersByFilter(ctx, params, func(sqlcOrder *sqlc.Order) error {
+		return fn(new(models.Order).ConvertSqlcOrder(sqlcOrder))
+	})
+	if err != nil {
+		r.logger.Error("Failed to stream orders", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// StreamRevenueEvents 依發生時間逐筆串流 [from, to) 期間的銷售與退款事件，銷售只包含 statuses 中的訂單；
+// fn 執行期間不可使用同一個交易發出其他查詢
+func (r *repository) StreamRevenueEvents(ctx context.Context, tx pgx.Tx, from, to time.Time, statuses []enum.OrderStatus, fn func(*models.RevenueEvent) error) error {
+	params := sqlc.ListRevenueEventsParams{
+		PeriodStart: pgtype.Timestamptz{Time: from, Valid: true},
+		PeriodEnd:   pgtype.Timestamptz{Time: to, Valid: true},
+		Statuses:    make([]string, 0, len(statuses)),
+	}
+	for _, status := range statuses {
+		params.Statuses = append(params.Statuses, string(status))
+	}
+
+	streamer, err := r.queries.Streamer(tx)
 	if err != nil {
-		r.logger.Error("Failed to delete order", zap.Error(err))
+		r.logger.Error("Failed to stream revenue events", zap.Error(err))
+		return err
+	}
+
+	err = streamer.StreamRevenueEvents(ctx, params, func(row *sqlc.ListRevenueEventsRow) error {
+		return fn(new(models.RevenueEvent).ConvertSqlcRevenueEvent(row))
+	})
+	if err != nil {
+		r.logger.Error("Failed to stream revenue events", zap.Error(err))
 		return err
 	}
 
+	return nil
+}
+
+// CountOrders 計算指定客戶的訂單總數，用於分頁
+func (r *repository) CountOrders(ctx context.Context, tx pgx.Tx, customerID string) (uint64, error) {
+	count, err := r.queries.WithTx(tx).CountOrders(ctx, customerID)
+	if err != nil {
+		r.logger.Error("Failed to count orders", zap.String("customer_id", customerID), zap.Error(err))
+		return 0, err
+	}
+
+	return uint64(count), nil
+}
+
+// CountOrdersByStatus 計算 since 之後建立且目前為 status 的訂單數，不含已刪除的訂單
+func (r *repository) CountOrdersByStatus(ctx context.Context, tx pgx.Tx, status enum.OrderStatus, since time.Time) (uint64, error) {
+	count, err := r.queries.WithTx(tx).CountOrdersByStatus(ctx, sqlc.CountOrdersByStatusParams{
+		Status:    sqlc.OrderStatus(status),
+		CreatedAt: pgtype.Timestamptz{Time: since, Valid: true},
+	})
+	if err != nil {
+		r.logger.Error("Failed to count orders by status", zap.String("status", string(status)), zap.Error(err))
+		return 0, err
+	}
+
+	return uint64(count), nil
+}
+
+// SearchOrderIDs 依建立時間由新到舊列出訂單編號、客戶 ID、metadata 或商品名稱符合 pattern（ILIKE）的訂單 ID
+func (r *repository) SearchOrderIDs(ctx context.Context, tx pgx.Tx, pattern string, limit, offset uint64) ([]uint64, error) {
+	rows, err := r.queries.WithTx(tx).SearchOrderIDs(ctx, sqlc.SearchOrderIDsParams{
+		Pattern:   pattern,
+		RowLimit:  int32(limit),
+		RowOffset: int32(offset),
+	})
+	if err != nil {
+		r.logger.Error("Failed to search orders", zap.Error(err))
+		return nil, err
+	}
+
+	ids := make([]uint64, 0, len(rows))
+	for _, id := range rows {
+		ids = append(ids, uint64(id))
+	}
+
+	return ids, nil
+}
+
+// CountSearchOrders 計算訂單編號、客戶 ID、metadata 或商品名稱符合 pattern（ILIKE）的訂單數
+func (r *repository) CountSearchOrders(ctx context.Context, tx pgx.Tx, pattern string) (uint64, error) {
+	count, err := r.queries.WithTx(tx).CountSearchOrders(ctx, pattern)
+	if err != nil {
+		r.logger.Error("Failed to count searched orders", zap.Error(err))
+		return 0, err
+	}
+
+	return uint64(count), nil
+}
+
+// DeleteOrder 軟刪除訂單，資料保留到 PurgeDeletedOrders 清除為止；回傳 false 表示訂單已被刪除
+func (r *repository) DeleteOrder(ctx context.Context, tx pgx.Tx, orderID uint64) (bool, error) {
+	rows, err := r.queries.WithTx(tx).SoftDeleteOrder(ctx, int32(orderID))
+	if err != nil {
+		r.logger.Error("Failed to delete order", zap.Error(err))
+		return false, err
+	}
+
 	// 使相關的快取失效
 	r.invalidateOrderCache(ctx, orderID)
-	return nil
+	return rows > 0, nil
+}
+
+// RestoreOrder 還原軟刪除的訂單，回傳 false 表示訂單沒有被刪除
+func (r *repository) RestoreOrder(ctx context.Context, tx pgx.Tx, orderID uint64) (bool, error) {
+	rows, err := r.queries.WithTx(tx).RestoreOrder(ctx, int32(orderID))
+	if err != nil {
+		r.logger.Error("Failed to restore order", zap.Uint64("order_id", orderID), zap.Error(err))
+		return false, err
+	}
+
+	r.invalidateOrderCache(ctx, orderID)
+	return rows > 0, nil
+}
+
+// PurgeDeletedOrders 永久刪除軟刪除時間早於 cutoff 的訂單（最多 batchSize 筆）及其項目，回傳刪除的訂單數
+func (r *repository) PurgeDeletedOrders(ctx context.Context, tx pgx.Tx, cutoff time.Time, batchSize int32) (int64, error) {
+	ids, err := r.queries.WithTx(tx).PurgeDeletedOrders(ctx, sqlc.PurgeDeletedOrdersParams{
+		Cutoff:    pgtype.Timestamptz{Time: cutoff, Valid: true},
+		BatchSize: batchSize,
+	})
+	if err != nil {
+		r.logger.Error("Failed to purge deleted orders", zap.Time("cutoff", cutoff), zap.Error(err))
+		return 0, err
+	}
+
+	for _, id := range ids {
+		r.invalidateOrderCache(ctx, uint64(id))
+		r.invalidateOrderItemsCache(ctx, uint64(id))
+	}
+	return int64(len(ids)), nil
 }
 
 func (r *repository) AddOrderItems(ctx context.Context, tx pgx.Tx, items []*models.OrderItem) error {
@@ -316,26 +742,46 @@ func (r *repository) AddOrderItems(ctx context.Context, tx pgx.Tx, items []*mode
 	batch := make([]sqlc.AddOrderItemsParams, 0, len(items))
 	for _, item := range items {
 		batch = append(batch, sqlc.AddOrderItemsParams{
-			OrderID:   int32(item.OrderID),
-			ProductID: item.ProductID,
-			Quantity:  item.Quantity,
-			PriceID:   item.PriceID,
-			StockID:   item.StockID,
-			UnitPrice: item.UnitPrice,
-			Subtotal:  item.Subtotal,
+			OrderID:     int32(item.OrderID),
+			ProductID:   item.ProductID,
+			Quantity:    item.Quantity,
+			PriceID:     item.PriceID,
+			StockID:     item.StockID,
+			UnitPrice:   item.UnitPrice,
+			Subtotal:    item.Subtotal,
+			TaxRate:     item.TaxRate,
+			TaxAmount:   item.TaxAmount,
+			Location:    nullableString(item.Location),
+			ProductName: nullableString(item.ProductName),
+			Sku:         nullableString(item.SKU),
+			ImageUrl:    nullableString(item.ImageURL),
+			TaxClass:    nullableString(item.TaxClass),
+
+			WeightGrams:   nullableInt32(item.WeightGrams),
+			LengthMm:      nullableInt32(item.LengthMM),
+			WidthMm:       nullableInt32(item.WidthMM),
+			HeightMm:      nullableInt32(item.HeightMM),
+			HsCode:        nullableString(item.HSCode),
+			OriginCountry: nullableString(item.OriginCountry),
+			Customization: item.Customization,
+			Note:          nullableString(item.Note),
+			Metadata:      models.MarshalMetadata(item.Metadata),
 		})
 	}
-	batchResults := sqlc.New(r.conn).WithTx(tx).AddOrderItems(ctx, batch)
+	batchResults := r.queries.WithTx(tx).AddOrderItems(ctx, batch)
 	defer func(batchResults *sqlc.AddOrderItemsBatchResults) {
 		if err := batchResults.Close(); err != nil {
 			batchError = err
 		}
 	}(batchResults)
 
-	batchResults.Exec(func(index int, err error) {
+	batchResults.QueryRow(func(index int, id int32, err error) {
 		if err != nil {
 			batchError = err
+			return
 		}
+		items[index].ID = uint64(id)
+		items[index].FulfillmentStatus = enum.OrderItemFulfillmentStatusUnfulfilled
 	})
 
 	if batchError != nil {
@@ -351,7 +797,7 @@ func (r *repository) AddOrderItems(ctx context.Context, tx pgx.Tx, items []*mode
 }
 
 func (r *repository) ListOrderItems(ctx context.Context, tx pgx.Tx, orderID uint64) ([]*models.OrderItem, error) {
-	cacheKey := fmt.Sprintf("order_items:%d", orderID)
+	cacheKey := orderCacheKey(orderID).items()
 	var orderItems []*models.OrderItem
 
 	// 嘗試從快取中獲取
@@ -360,10 +806,31 @@ func (r *repository) ListOrderItems(ctx context.Context, tx pgx.Tx, orderID uint
 		r.logger.Warn("Failed to get order items from cache", zap.Error(err))
 	}
 	if found {
+		r.shadow.Verify(ctx, cacheKey, &orderItems, func(ctx context.Context) (any, error) {
+			queries := r.queries.Querier()
+			sqlcOrderItems, err := queries.ListOrderItems(ctx, int32(orderID))
+			if err != nil {
+				return nil, err
+			}
+			results := make([]*models.OrderItem, 0, len(sqlcOrderItems))
+			for _, sqlcOrderItem := range sqlcOrderItems {
+				results = append(results, new(models.OrderItem).ConvertSqlcOrderItem(sqlcOrderItem))
+			}
+			if len(results) == 0 {
+				sqlcArchivedItems, err := queries.ListArchivedOrderItems(ctx, int32(orderID))
+				if err != nil {
+					return nil, err
+				}
+				for _, sqlcArchivedItem := range sqlcArchivedItems {
+					results = append(results, new(models.OrderItem).ConvertSqlcOrderItem(sqlcArchivedItem))
+				}
+			}
+			return results, nil
+		})
 		return orderItems, nil
 	}
 
-	sqlcOrderItems, err := sqlc.New(r.conn).WithTx(tx).ListOrderItems(ctx, int32(orderID))
+	sqlcOrderItems, err := r.queries.WithTx(tx).ListOrderItems(ctx, int32(orderID))
 	if err != nil {
 		r.logger.Error("Failed to list order items", zap.Error(err))
 		return nil, err
@@ -374,6 +841,18 @@ func (r *repository) ListOrderItems(ctx context.Context, tx pgx.Tx, orderID uint
 		orderItems = append(orderItems, new(models.OrderItem).ConvertSqlcOrderItem(sqlcOrderItem))
 	}
 
+	// 訂單必定有項目，查無項目時可能已封存
+	if len(orderItems) == 0 {
+		sqlcArchivedItems, err := r.queries.WithTx(tx).ListArchivedOrderItems(ctx, int32(orderID))
+		if err != nil {
+			r.logger.Error("Failed to list archived order items", zap.Error(err))
+			return nil, err
+		}
+		for _, sqlcArchivedItem := range sqlcArchivedItems {
+			orderItems = append(orderItems, new(models.OrderItem).ConvertSqlcOrderItem(sqlcArchivedItem))
+		}
+	}
+
 	// 更新快取
 	if err := r.cache.Set(ctx, cacheKey, orderItems, 30*time.Minute); err != nil {
 		r.logger.Warn("Failed to cache order items", zap.Error(err))
@@ -383,11 +862,12 @@ func (r *repository) ListOrderItems(ctx context.Context, tx pgx.Tx, orderID uint
 }
 
 func (r *repository) UpdateOrderItem(ctx context.Context, tx pgx.Tx, item *models.OrderItem) error {
-	err := sqlc.New(r.conn).WithTx(tx).UpdateOrderItem(ctx, sqlc.UpdateOrderItemParams{
+	err := r.queries.WithTx(tx).UpdateOrderItem(ctx, sqlc.UpdateOrderItemParams{
 		ID:        int32(item.ID),
 		Quantity:  item.Quantity,
 		UnitPrice: item.UnitPrice,
 		Subtotal:  item.Subtotal,
+		TaxAmount: item.TaxAmount,
 	})
 	if err != nil {
 		r.logger.Error("Failed to update order item", zap.Error(err))
@@ -402,13 +882,13 @@ func (r *repository) UpdateOrderItem(ctx context.Context, tx pgx.Tx, item *model
 
 func (r *repository) DeleteOrderItem(ctx context.Context, tx pgx.Tx, orderItemID uint64) error {
 	// 先獲取 order item 以獲得 order ID
-	orderItem, err := sqlc.New(r.conn).WithTx(tx).GetOrderItem(ctx, int32(orderItemID))
+	orderItem, err := r.queries.WithTx(tx).GetOrderItem(ctx, int32(orderItemID))
 	if err != nil {
 		r.logger.Error("Failed to get order item", zap.Error(err))
 		return err
 	}
 
-	err = sqlc.New(r.conn).WithTx(tx).DeleteOrderItem(ctx, int32(orderItemID))
+	err = r.queries.WithTx(tx).DeleteOrderItem(ctx, int32(orderItemID))
 	if err != nil {
 		r.logger.Error("Failed to delete order item", zap.Error(err))
 		return err
@@ -420,14 +900,72 @@ func (r *repository) DeleteOrderItem(ctx context.Context, tx pgx.Tx, orderItemID
 	return nil
 }
 
-func (r *repository) invalidateOrderCache(ctx context.Context, orderID uint64) {
-	cacheKeys := []string{
-		fmt.Sprintf("order:%d", orderID),
-		fmt.Sprintf("order:payment_intent:%d", orderID),
-		fmt.Sprintf("order:refund:%d", orderID),
-		fmt.Sprintf("order:invoice:%d", orderID),
+// CreateOrderAddon 新增訂單的加購服務，並將產生的 ID 與時間寫回 addon；同一個項目重複加購同一種服務時回傳 ErrOrderAddonExists
+func (r *repository) CreateOrderAddon(ctx context.Context, tx pgx.Tx, addon *models.OrderAddon) error {
+	var orderItemID *int32
+	if addon.OrderItemID != nil {
+		orderItemID = nullableInt32(*addon.OrderItemID)
+	}
+
+	sqlcAddon, err := r.queries.WithTx(tx).CreateOrderAddon(ctx, sqlc.CreateOrderAddonParams{
+		OrderID:     int32(addon.OrderID),
+		OrderItemID: orderItemID,
+		Type:        sqlc.OrderAddonType(addon.Type),
+		Description: nullableString(addon.Description),
+		Quantity:    addon.Quantity,
+		UnitPrice:   addon.UnitPrice,
+		Subtotal:    addon.Subtotal,
+		TaxRate:     addon.TaxRate,
+		TaxAmount:   addon.TaxAmount,
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return ErrOrderAddonExists
+		}
+		r.logger.Error("Failed to create order addon", zap.Uint64("order_id", addon.OrderID), zap.Error(err))
+		return err
+	}
+
+	*addon = *new(models.OrderAddon).ConvertSqlcOrderAddon(sqlcAddon)
+	return nil
+}
+
+// ListOrderAddons 列出訂單的加購服務，包含已封存的訂單
+func (r *repository) ListOrderAddons(ctx context.Context, tx pgx.Tx, orderID uint64) ([]*models.OrderAddon, error) {
+	sqlcAddons, err := r.queries.WithTx(tx).ListOrderAddons(ctx, int32(orderID))
+	if err != nil {
+		r.logger.Error("Failed to list order addons", zap.Uint64("order_id", orderID), zap.Error(err))
+		return nil, err
+	}
+
+	addons := make([]*models.OrderAddon, 0, len(sqlcAddons))
+	for _, sqlcAddon := range sqlcAddons {
+		addons = append(addons, new(models.OrderAddon).ConvertSqlcOrderAddon(sqlcAddon))
+	}
+
+	return addons, nil
+}
+
+// DeleteOrderAddon 移除訂單的加購服務並回傳被移除的服務，服務不屬於該訂單時回傳 pgx.ErrNoRows
+func (r *repository) DeleteOrderAddon(ctx context.Context, tx pgx.Tx, orderID, addonID uint64) (*models.OrderAddon, error) {
+	sqlcAddon, err := r.queries.WithTx(tx).DeleteOrderAddon(ctx, sqlc.DeleteOrderAddonParams{
+		ID:      int32(addonID),
+		OrderID: int32(orderID),
+	})
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			r.logger.Error("Failed to delete order addon", zap.Uint64("addon_id", addonID), zap.Error(err))
+		}
+		return nil, err
 	}
-	for _, key := range cacheKeys {
+
+	return new(models.OrderAddon).ConvertSqlcOrderAddon(sqlcAddon), nil
+}
+
+// invalidateOrderCache 清除訂單本身與以外部 ID 查詢到的結果，外部 ID 的索引指向不變的訂單 ID，不需清除
+func (r *repository) invalidateOrderCache(ctx context.Context, orderID uint64) {
+	for _, key := range orderCacheKey(orderID).orderKeys() {
 		if err := r.cache.Delete(ctx, key); err != nil {
 			r.logger.Warn("Failed to invalidate order cache", zap.Error(err), zap.String("key", key))
 		}
@@ -435,8 +973,1463 @@ func (r *repository) invalidateOrderCache(ctx context.Context, orderID uint64) {
 }
 
 func (r *repository) invalidateOrderItemsCache(ctx context.Context, orderID uint64) {
-	cacheKey := fmt.Sprintf("order_items:%d", orderID)
+	cacheKey := orderCacheKey(orderID).items()
 	if err := r.cache.Delete(ctx, cacheKey); err != nil {
 		r.logger.Warn("Failed to invalidate order items cache", zap.Error(err), zap.String("key", cacheKey))
 	}
 }
+
+// CreateShipment 建立訂單在指定地點的出貨單，已存在時回傳原本的出貨單
+func (r *repository) CreateShipment(ctx context.Context, tx pgx.Tx, orderID uint64, location string) (*models.Shipment, error) {
+	sqlcShipment, err := r.queries.WithTx(tx).CreateShipment(ctx, sqlc.CreateShipmentParams{
+		OrderID:  int32(orderID),
+		Location: location,
+	})
+	if err != nil {
+		r.logger.Error("failed to create shipment", zap.Uint64("order_id", orderID), zap.String("location", location), zap.Error(err))
+		return nil, err
+	}
+
+	return new(models.Shipment).ConvertSqlcShipment(sqlcShipment), nil
+}
+
+func (r *repository) GetShipment(ctx context.Context, tx pgx.Tx, shipmentID uint64) (*models.Shipment, error) {
+	sqlcShipment, err := r.queries.WithTx(tx).GetShipment(ctx, int32(shipmentID))
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			r.logger.Error("failed to get shipment", zap.Uint64("shipment_id", shipmentID), zap.Error(err))
+		}
+		return nil, err
+	}
+
+	return new(models.Shipment).ConvertSqlcShipment(sqlcShipment), nil
+}
+
+func (r *repository) ListShipments(ctx context.Context, tx pgx.Tx, orderID uint64) ([]*models.Shipment, error) {
+	sqlcShipments, err := r.queries.WithTx(tx).ListShipmentsByOrderID(ctx, int32(orderID))
+	if err != nil {
+		r.logger.Error("failed to list shipments", zap.Uint64("order_id", orderID), zap.Error(err))
+		return nil, err
+	}
+
+	shipments := make([]*models.Shipment, 0, len(sqlcShipments))
+	for _, sqlcShipment := range sqlcShipments {
+		shipments = append(shipments, new(models.Shipment).ConvertSqlcShipment(sqlcShipment))
+	}
+
+	return shipments, nil
+}
+
+// ShipOrderItems 將訂單在 location 出貨的項目標記為全部出貨，location 為空字串時標記訂單的所有項目
+func (r *repository) ShipOrderItems(ctx context.Context, tx pgx.Tx, orderID uint64, location string) error {
+	var err error
+	if location == "" {
+		_, err = r.queries.WithTx(tx).ShipOrderItems(ctx, int32(orderID))
+	} else {
+		_, err = r.queries.WithTx(tx).ShipOrderItemsAtLocation(ctx, sqlc.ShipOrderItemsAtLocationParams{
+			OrderID:  int32(orderID),
+			Location: &location,
+		})
+	}
+	if err != nil {
+		r.logger.Error("Failed to ship order items", zap.Uint64("order_id", orderID), zap.String("location", location), zap.Error(err))
+		return err
+	}
+
+	r.invalidateOrderItemsCache(ctx, orderID)
+	return nil
+}
+
+// ReturnShippedOrderItems 將訂單所有已出貨的數量標記為已退回
+func (r *repository) ReturnShippedOrderItems(ctx context.Context, tx pgx.Tx, orderID uint64) error {
+	if _, err := r.queries.WithTx(tx).ReturnShippedOrderItems(ctx, int32(orderID)); err != nil {
+		r.logger.Error("Failed to return order items", zap.Uint64("order_id", orderID), zap.Error(err))
+		return err
+	}
+
+	r.invalidateOrderItemsCache(ctx, orderID)
+	return nil
+}
+
+// AddOrderItemReturnedQuantity 增加訂單項目的退回數量，最多到已出貨的數量；尚未出貨的項目不受影響
+func (r *repository) AddOrderItemReturnedQuantity(ctx context.Context, tx pgx.Tx, orderID, orderItemID, quantity uint64) error {
+	if _, err := r.queries.WithTx(tx).AddOrderItemReturnedQuantity(ctx, sqlc.AddOrderItemReturnedQuantityParams{
+		ID:               int32(orderItemID),
+		ReturnedQuantity: quantity,
+	}); err != nil {
+		r.logger.Error("Failed to add order item returned quantity", zap.Uint64("order_item_id", orderItemID), zap.Error(err))
+		return err
+	}
+
+	r.invalidateOrderItemsCache(ctx, orderID)
+	return nil
+}
+
+// CreateRefund 新增退款紀錄及其涵蓋的項目，並將產生的 ID 與時間寫回 refund
+func (r *repository) CreateRefund(ctx context.Context, tx pgx.Tx, refund *models.Refund) error {
+	queries := r.queries.WithTx(tx)
+
+	sqlcRefund, err := queries.CreateRefund(ctx, sqlc.CreateRefundParams{
+		OrderID:        int32(refund.OrderID),
+		StripeRefundID: nullableString(refund.StripeRefundID),
+		Currency:       sqlc.Currency(refund.Currency),
+		Amount:         refund.Amount,
+		RestockingFee:  refund.RestockingFee,
+		Reason:         nullableString(refund.Reason),
+		PolicyVersion:  refund.PolicyVersion,
+	})
+	if err != nil {
+		r.logger.Error("failed to create refund", zap.Uint64("order_id", refund.OrderID), zap.Error(err))
+		return err
+	}
+	refund.ID = uint64(sqlcRefund.ID)
+	refund.CreatedAt = sqlcRefund.CreatedAt.Time
+
+	var batchError error
+	batch := make([]sqlc.AddRefundItemsParams, 0, len(refund.Items))
+	for _, item := range refund.Items {
+		item.RefundID = refund.ID
+		batch = append(batch, sqlc.AddRefundItemsParams{
+			RefundID:      int32(refund.ID),
+			OrderItemID:   nullableInt32(item.OrderItemID),
+			OrderAddonID:  nullableInt32(item.OrderAddonID),
+			Quantity:      item.Quantity,
+			Amount:        item.Amount,
+			RestockingFee: item.RestockingFee,
+			Rule:          string(item.Rule),
+		})
+	}
+	batchResults := queries.AddRefundItems(ctx, batch)
+	defer func(batchResults *sqlc.AddRefundItemsBatchResults) {
+		if err := batchResults.Close(); err != nil {
+			batchError = err
+		}
+	}(batchResults)
+
+	batchResults.Exec(func(index int, err error) {
+		if err != nil {
+			batchError = err
+		}
+	})
+
+	if batchError != nil {
+		r.logger.Error("failed to add refund items", zap.Uint64("refund_id", refund.ID), zap.Error(batchError))
+		return batchError
+	}
+
+	return nil
+}
+
+// ListRefunds 回傳訂單的所有退款紀錄及其項目，依建立順序排列
+func (r *repository) ListRefunds(ctx context.Context, tx pgx.Tx, orderID uint64) ([]*models.Refund, error) {
+	queries := r.queries.WithTx(tx)
+
+	sqlcRefunds, err := queries.ListRefundsByOrderID(ctx, int32(orderID))
+	if err != nil {
+		r.logger.Error("failed to list refunds", zap.Uint64("order_id", orderID), zap.Error(err))
+		return nil, err
+	}
+	sqlcItems, err := queries.ListRefundItemsByOrderID(ctx, int32(orderID))
+	if err != nil {
+		r.logger.Error("failed to list refund items", zap.Uint64("order_id", orderID), zap.Error(err))
+		return nil, err
+	}
+
+	refunds := make([]*models.Refund, 0, len(sqlcRefunds))
+	byID := make(map[uint64]*models.Refund, len(sqlcRefunds))
+	for _, sqlcRefund := range sqlcRefunds {
+		refund := new(models.Refund).ConvertSqlcRefund(sqlcRefund)
+		refunds = append(refunds, refund)
+		byID[refund.ID] = refund
+	}
+	for _, sqlcItem := range sqlcItems {
+		item := new(models.RefundItem).ConvertSqlcRefundItem(sqlcItem)
+		if refund, ok := byID[item.RefundID]; ok {
+			refund.Items = append(refund.Items, item)
+		}
+	}
+
+	return refunds, nil
+}
+
+// SetOrderRefund 記錄訂單最近一次發起的退款並更新訂單狀態
+func (r *repository) SetOrderRefund(ctx context.Context, tx pgx.Tx, orderID uint64, refundID string, status enum.OrderStatus, updatedAt time.Time) error {
+	rows, err := r.queries.WithTx(tx).SetOrderRefund(ctx, sqlc.SetOrderRefundParams{
+		ID:        int32(orderID),
+		RefundID:  nullableString(refundID),
+		Status:    sqlc.OrderStatus(status),
+		UpdatedAt: pgtype.Timestamptz{Time: updatedAt, Valid: true},
+	})
+	if err != nil {
+		r.logger.Error("failed to set order refund", zap.Uint64("order_id", orderID), zap.Error(err))
+		return err
+	}
+	if rows == 0 {
+		r.logger.Warn("Order refund update conflicted with a concurrent change", zap.Uint64("order_id", orderID))
+		return ErrStaleOrder
+	}
+
+	// 使相關的快取失效
+	r.invalidateOrderCache(ctx, orderID)
+	return nil
+}
+
+// addressJSON 地址欄位不可為 NULL，未提供地址時寫入空物件
+func addressJSON(address json.RawMessage) []byte {
+	if len(address) == 0 {
+		return []byte("{}")
+	}
+	return address
+}
+
+// nullableString 將空字串轉為 NULL
+func nullableString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// nullableInt32 將 0 轉為 NULL，表示未提供
+func nullableInt32(v uint64) *int32 {
+	if v == 0 {
+		return nil
+	}
+	n := int32(v)
+	return &n
+}
+
+// NextInvoiceSequenceNumber 取得並遞增租戶、國家與單據種類的下一個編號，編號的遞增會鎖定該組編號直到交易結束，
+// 必須與 CreateInvoice 在同一個交易中呼叫，交易回復時編號一併回復
+func (r *repository) NextInvoiceSequenceNumber(ctx context.Context, tx pgx.Tx, tenant, country string, invoiceType enum.InvoiceType) (uint64, error) {
+	number, err := r.queries.WithTx(tx).NextInvoiceSequenceNumber(ctx, sqlc.NextInvoiceSequenceNumberParams{
+		Tenant:  tenant,
+		Country: country,
+		Type:    sqlc.InvoiceType(invoiceType),
+	})
+	if err != nil {
+		r.logger.Error("Failed to get next invoice sequence number",
+			zap.String("tenant", tenant), zap.String("country", country), zap.String("type", string(invoiceType)), zap.Error(err))
+		return 0, err
+	}
+
+	return uint64(number), nil
+}
+
+// CreateInvoice 新增發票或折讓單，並將產生的 ID 與開立時間寫回 invoice
+func (r *repository) CreateInvoice(ctx context.Context, tx pgx.Tx, invoice *models.Invoice) error {
+	row, err := r.queries.WithTx(tx).CreateInvoice(ctx, sqlc.CreateInvoiceParams{
+		Tenant:            invoice.Tenant,
+		Country:           invoice.Country,
+		Type:              sqlc.InvoiceType(invoice.Type),
+		SequenceNumber:    int64(invoice.SequenceNumber),
+		InvoiceNumber:     invoice.InvoiceNumber,
+		OrderID:           int32(invoice.OrderID),
+		RefundID:          nullableInt32(invoice.RefundID),
+		OriginalInvoiceID: nullableInt32(invoice.OriginalInvoiceID),
+		Currency:          sqlc.Currency(invoice.Currency),
+		Subtotal:          invoice.Subtotal,
+		Tax:               invoice.Tax,
+		Total:             invoice.Total,
+
+		TaxExemptionNumber: nullableString(invoice.TaxExemptionNumber),
+	})
+	if err != nil {
+		r.logger.Error("Failed to create invoice", zap.Uint64("order_id", invoice.OrderID), zap.String("invoice_number", invoice.InvoiceNumber), zap.Error(err))
+		return err
+	}
+
+	invoice.ID = uint64(row.ID)
+	invoice.IssuedAt = row.IssuedAt.Time
+	return nil
+}
+
+// GetInvoice 取得發票或折讓單，不存在時回傳 pgx.ErrNoRows
+func (r *repository) GetInvoice(ctx context.Context, tx pgx.Tx, invoiceID uint64) (*models.Invoice, error) {
+	row, err := r.queries.WithTx(tx).GetInvoice(ctx, int32(invoiceID))
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			r.logger.Error("Failed to get invoice", zap.Uint64("invoice_id", invoiceID), zap.Error(err))
+		}
+		return nil, err
+	}
+
+	return new(models.Invoice).ConvertSqlcInvoice(row), nil
+}
+
+// GetOrderInvoice 取得訂單的發票，尚未開立時回傳 pgx.ErrNoRows
+func (r *repository) GetOrderInvoice(ctx context.Context, tx pgx.Tx, orderID uint64) (*models.Invoice, error) {
+	row, err := r.queries.WithTx(tx).GetOrderInvoice(ctx, int32(orderID))
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			r.logger.Error("Failed to get order invoice", zap.Uint64("order_id", orderID), zap.Error(err))
+		}
+		return nil, err
+	}
+
+	return new(models.Invoice).ConvertSqlcInvoice(row), nil
+}
+
+// ListInvoices 依開立順序列出訂單的發票與折讓單
+func (r *repository) ListInvoices(ctx context.Context, tx pgx.Tx, orderID uint64) ([]*models.Invoice, error) {
+	rows, err := r.queries.WithTx(tx).ListInvoicesByOrderID(ctx, int32(orderID))
+	if err != nil {
+		r.logger.Error("Failed to list invoices", zap.Uint64("order_id", orderID), zap.Error(err))
+		return nil, err
+	}
+
+	invoices := make([]*models.Invoice, 0, len(rows))
+	for _, row := range rows {
+		invoices = append(invoices, new(models.Invoice).ConvertSqlcInvoice(row))
+	}
+
+	return invoices, nil
+}
+
+// SetInvoiceDocument 記錄單據產生的文件網址，單據不存在時回傳 pgx.ErrNoRows
+func (r *repository) SetInvoiceDocument(ctx context.Context, tx pgx.Tx, invoiceID uint64, documentURL string) error {
+	rows, err := r.queries.WithTx(tx).SetInvoiceDocument(ctx, sqlc.SetInvoiceDocumentParams{
+		ID:          int32(invoiceID),
+		DocumentUrl: nullableString(documentURL),
+	})
+	if err != nil {
+		r.logger.Error("Failed to set invoice document", zap.Uint64("invoice_id", invoiceID), zap.Error(err))
+		return err
+	}
+	if rows == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
+
+// ListInvoiceNumberGaps 列出各組編號中缺少的區間，包含編號已遞增但沒有對應單據的尾段
+func (r *repository) ListInvoiceNumberGaps(ctx context.Context, tx pgx.Tx) ([]*models.InvoiceNumberGap, error) {
+	rows, err := r.queries.WithTx(tx).ListInvoiceNumberGaps(ctx)
+	if err != nil {
+		r.logger.Error("Failed to list invoice number gaps", zap.Error(err))
+		return nil, err
+	}
+
+	gaps := make([]*models.InvoiceNumberGap, 0, len(rows))
+	for _, row := range rows {
+		gaps = append(gaps, &models.InvoiceNumberGap{
+			Tenant:  row.Tenant,
+			Country: row.Country,
+			Type:    enum.InvoiceType(row.Type),
+			From:    uint64(row.GapStart),
+			To:      uint64(row.GapEnd),
+		})
+	}
+
+	return gaps, nil
+}
+
+// CreateTaxExemptionCertificate 新增客戶的免稅證明，同一個轄區已有相同編號時回傳 ErrTaxExemptionCertificateExists
+func (r *repository) CreateTaxExemptionCertificate(ctx context.Context, tx pgx.Tx, certificate *models.TaxExemptionCertificate) (*models.TaxExemptionCertificate, error) {
+	params := sqlc.CreateTaxExemptionCertificateParams{
+		CustomerID:        certificate.CustomerID,
+		CertificateNumber: certificate.CertificateNumber,
+		Jurisdiction:      certificate.Jurisdiction,
+		TaxClasses:        certificate.TaxClasses,
+		ValidFrom:         pgtype.Timestamptz{Time: certificate.ValidFrom, Valid: true},
+	}
+	if params.TaxClasses == nil {
+		params.TaxClasses = []string{}
+	}
+	if certificate.ValidUntil != nil {
+		params.ValidUntil = pgtype.Timestamptz{Time: *certificate.ValidUntil, Valid: true}
+	}
+
+	row, err := r.queries.WithTx(tx).CreateTaxExemptionCertificate(ctx, params)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, ErrTaxExemptionCertificateExists
+		}
+		r.logger.Error("Failed to create tax exemption certificate",
+			zap.String("customer_id", certificate.CustomerID), zap.String("certificate_number", certificate.CertificateNumber), zap.Error(err))
+		return nil, err
+	}
+
+	return new(models.TaxExemptionCertificate).ConvertSqlcTaxExemptionCertificate(row), nil
+}
+
+// ListTaxExemptionCertificates 依新增時間由新到舊列出客戶的免稅證明，包含已到期與已撤銷的證明
+func (r *repository) ListTaxExemptionCertificates(ctx context.Context, tx pgx.Tx, customerID string) ([]*models.TaxExemptionCertificate, error) {
+	rows, err := r.queries.WithTx(tx).ListTaxExemptionCertificates(ctx, customerID)
+	if err != nil {
+		r.logger.Error("Failed to list tax exemption certificates", zap.String("customer_id", customerID), zap.Error(err))
+		return nil, err
+	}
+
+	certificates := make([]*models.TaxExemptionCertificate, 0, len(rows))
+	for _, row := range rows {
+		certificates = append(certificates, new(models.TaxExemptionCertificate).ConvertSqlcTaxExemptionCertificate(row))
+	}
+
+	return certificates, nil
+}
+
+// GetActiveTaxExemptionCertificate 取得客戶在 jurisdiction 於 at 時有效的免稅證明，多筆有效時取最晚生效的一筆；
+// 沒有有效證明時回傳 pgx.ErrNoRows
+func (r *repository) GetActiveTaxExemptionCertificate(ctx context.Context, tx pgx.Tx, customerID, jurisdiction string, at time.Time) (*models.TaxExemptionCertificate, error) {
+	row, err := r.queries.WithTx(tx).GetActiveTaxExemptionCertificate(ctx, sqlc.GetActiveTaxExemptionCertificateParams{
+		CustomerID:   customerID,
+		Jurisdiction: jurisdiction,
+		At:           pgtype.Timestamptz{Time: at, Valid: true},
+	})
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			r.logger.Error("Failed to get active tax exemption certificate",
+				zap.String("customer_id", customerID), zap.String("jurisdiction", jurisdiction), zap.Error(err))
+		}
+		return nil, err
+	}
+
+	return new(models.TaxExemptionCertificate).ConvertSqlcTaxExemptionCertificate(row), nil
+}
+
+// RevokeTaxExemptionCertificate 撤銷客戶的免稅證明，回傳 false 表示證明不存在、不屬於該客戶或已撤銷
+func (r *repository) RevokeTaxExemptionCertificate(ctx context.Context, tx pgx.Tx, customerID string, certificateID uint64) (bool, error) {
+	rows, err := r.queries.WithTx(tx).RevokeTaxExemptionCertificate(ctx, sqlc.RevokeTaxExemptionCertificateParams{
+		ID:         int32(certificateID),
+		CustomerID: customerID,
+	})
+	if err != nil {
+		r.logger.Error("Failed to revoke tax exemption certificate", zap.Uint64("certificate_id", certificateID), zap.Error(err))
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+// SetOrderTaxExemption 記錄訂單套用的免稅證明，重複記錄時覆寫
+func (r *repository) SetOrderTaxExemption(ctx context.Context, tx pgx.Tx, orderID uint64, certificate *models.TaxExemptionCertificate) error {
+	if err := r.queries.WithTx(tx).SetOrderTaxExemption(ctx, sqlc.SetOrderTaxExemptionParams{
+		OrderID:           int32(orderID),
+		CertificateID:     int32(certificate.ID),
+		CertificateNumber: certificate.CertificateNumber,
+	}); err != nil {
+		r.logger.Error("Failed to set order tax exemption",
+			zap.Uint64("order_id", orderID), zap.Uint64("certificate_id", certificate.ID), zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// GetOrderTaxExemptionNumber 取得訂單套用的免稅證明編號，訂單沒有免稅時回傳 pgx.ErrNoRows
+func (r *repository) GetOrderTaxExemptionNumber(ctx context.Context, tx pgx.Tx, orderID uint64) (string, error) {
+	number, err := r.queries.WithTx(tx).GetOrderTaxExemptionNumber(ctx, int32(orderID))
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			r.logger.Error("Failed to get order tax exemption number", zap.Uint64("order_id", orderID), zap.Error(err))
+		}
+		return "", err
+	}
+
+	return number, nil
+}
+
+// CreateOrderReturn 新增退貨申請，訂單已有處理中的退貨時回傳 ErrOrderReturnExists
+func (r *repository) CreateOrderReturn(ctx context.Context, tx pgx.Tx, orderID uint64, reason string) (*models.OrderReturn, error) {
+	row, err := r.queries.WithTx(tx).CreateOrderReturn(ctx, sqlc.CreateOrderReturnParams{
+		OrderID: int32(orderID),
+		Reason:  reason,
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, ErrOrderReturnExists
+		}
+		r.logger.Error("Failed to create order return", zap.Uint64("order_id", orderID), zap.Error(err))
+		return nil, err
+	}
+
+	return new(models.OrderReturn).ConvertSqlcOrderReturn(row), nil
+}
+
+// GetOrderReturn 取得退貨申請，不存在時回傳 pgx.ErrNoRows
+func (r *repository) GetOrderReturn(ctx context.Context, tx pgx.Tx, returnID uint64) (*models.OrderReturn, error) {
+	row, err := r.queries.WithTx(tx).GetOrderReturn(ctx, int32(returnID))
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			r.logger.Error("Failed to get order return", zap.Uint64("return_id", returnID), zap.Error(err))
+		}
+		return nil, err
+	}
+
+	return new(models.OrderReturn).ConvertSqlcOrderReturn(row), nil
+}
+
+// GetOrderReturnForUpdate 取得並鎖定退貨申請直到交易結束，不存在時回傳 pgx.ErrNoRows
+func (r *repository) GetOrderReturnForUpdate(ctx context.Context, tx pgx.Tx, returnID uint64) (*models.OrderReturn, error) {
+	row, err := r.queries.WithTx(tx).GetOrderReturnForUpdate(ctx, int32(returnID))
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			r.logger.Error("Failed to get order return for update", zap.Uint64("return_id", returnID), zap.Error(err))
+		}
+		return nil, err
+	}
+
+	return new(models.OrderReturn).ConvertSqlcOrderReturn(row), nil
+}
+
+// GetOrderReturnIDByTracking 以物流商與追蹤編號查詢退貨申請的 ID，不存在時回傳 pgx.ErrNoRows
+func (r *repository) GetOrderReturnIDByTracking(ctx context.Context, tx pgx.Tx, carrier, trackingNumber string) (uint64, error) {
+	id, err := r.queries.WithTx(tx).GetOrderReturnIDByTracking(ctx, sqlc.GetOrderReturnIDByTrackingParams{
+		Carrier:        &carrier,
+		TrackingNumber: &trackingNumber,
+	})
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			r.logger.Error("Failed to get order return by tracking number",
+				zap.String("carrier", carrier), zap.String("tracking_number", trackingNumber), zap.Error(err))
+		}
+		return 0, err
+	}
+
+	return uint64(id), nil
+}
+
+// ListOrderReturns 依申請順序列出訂單的退貨
+func (r *repository) ListOrderReturns(ctx context.Context, tx pgx.Tx, orderID uint64) ([]*models.OrderReturn, error) {
+	rows, err := r.queries.WithTx(tx).ListOrderReturns(ctx, int32(orderID))
+	if err != nil {
+		r.logger.Error("Failed to list order returns", zap.Uint64("order_id", orderID), zap.Error(err))
+		return nil, err
+	}
+
+	returns := make([]*models.OrderReturn, 0, len(rows))
+	for _, row := range rows {
+		returns = append(returns, new(models.OrderReturn).ConvertSqlcOrderReturn(row))
+	}
+
+	return returns, nil
+}
+
+// ListTrackedOrderReturns 依 ID 順序列出 ID 大於 afterID、已取得標籤但尚未送達的退貨，用於分頁輪詢物流狀態
+func (r *repository) ListTrackedOrderReturns(ctx context.Context, tx pgx.Tx, afterID uint64, limit int) ([]*models.OrderReturn, error) {
+	rows, err := r.queries.WithTx(tx).ListTrackedOrderReturns(ctx, sqlc.ListTrackedOrderReturnsParams{
+		ID:    int32(afterID),
+		Limit: int32(limit),
+	})
+	if err != nil {
+		r.logger.Error("Failed to list tracked order returns", zap.Error(err))
+		return nil, err
+	}
+
+	returns := make([]*models.OrderReturn, 0, len(rows))
+	for _, row := range rows {
+		returns = append(returns, new(models.OrderReturn).ConvertSqlcOrderReturn(row))
+	}
+
+	return returns, nil
+}
+
+// UpdateOrderReturnStatus 更新退貨申請的狀態，不存在時回傳 pgx.ErrNoRows
+func (r *repository) UpdateOrderReturnStatus(ctx context.Context, tx pgx.Tx, returnID uint64, status enum.ReturnStatus) error {
+	rows, err := r.queries.WithTx(tx).UpdateOrderReturnStatus(ctx, sqlc.UpdateOrderReturnStatusParams{
+		ID:     int32(returnID),
+		Status: sqlc.ReturnStatus(status),
+	})
+	if err != nil {
+		r.logger.Error("Failed to update order return status", zap.Uint64("return_id", returnID), zap.Error(err))
+		return err
+	}
+	if rows == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
+
+// SetOrderReturnLabel 記錄退貨標籤並將狀態更新為 label_issued，回傳 false 表示退貨已不是 approved 狀態
+func (r *repository) SetOrderReturnLabel(ctx context.Context, tx pgx.Tx, returnID uint64, label *models.ReturnLabel) (bool, error) {
+	rows, err := r.queries.WithTx(tx).SetOrderReturnLabel(ctx, sqlc.SetOrderReturnLabelParams{
+		ID:             int32(returnID),
+		Carrier:        &label.Carrier,
+		TrackingNumber: &label.TrackingNumber,
+		LabelUrl:       nullableString(label.LabelURL),
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return false, fmt.Errorf("tracking number %s of %s is already used by another return", label.TrackingNumber, label.Carrier)
+		}
+		r.logger.Error("Failed to set order return label", zap.Uint64("return_id", returnID), zap.Error(err))
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+// CreateOrderCancellationRequest 記錄客戶的取消申請，訂單已有等待審核的申請時回傳 ErrCancellationRequestPending
+func (r *repository) CreateOrderCancellationRequest(ctx context.Context, tx pgx.Tx, orderID uint64, customerID string, reason enum.CancellationReason, status enum.CancellationRequestStatus) (*models.OrderCancellationRequest, error) {
+	row, err := r.queries.WithTx(tx).CreateOrderCancellationRequest(ctx, sqlc.CreateOrderCancellationRequestParams{
+		OrderID:    int32(orderID),
+		CustomerID: customerID,
+		Reason:     sqlc.CancellationReason(reason),
+		Status:     sqlc.CancellationRequestStatus(status),
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, ErrCancellationRequestPending
+		}
+		r.logger.Error("Failed to create order cancellation request", zap.Uint64("order_id", orderID), zap.Error(err))
+		return nil, err
+	}
+
+	return new(models.OrderCancellationRequest).ConvertSqlcOrderCancellationRequest(row), nil
+}
+
+// GetOrderCancellationRequestForUpdate 取得並鎖定取消申請直到交易結束，不存在時回傳 pgx.ErrNoRows
+func (r *repository) GetOrderCancellationRequestForUpdate(ctx context.Context, tx pgx.Tx, requestID uint64) (*models.OrderCancellationRequest, error) {
+	row, err := r.queries.WithTx(tx).GetOrderCancellationRequestForUpdate(ctx, int32(requestID))
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			r.logger.Error("Failed to get order cancellation request for update", zap.Uint64("request_id", requestID), zap.Error(err))
+		}
+		return nil, err
+	}
+
+	return new(models.OrderCancellationRequest).ConvertSqlcOrderCancellationRequest(row), nil
+}
+
+// ListPendingOrderCancellationRequests 依申請順序列出等待審核的取消申請
+func (r *repository) ListPendingOrderCancellationRequests(ctx context.Context, tx pgx.Tx) ([]*models.OrderCancellationRequest, error) {
+	rows, err := r.queries.WithTx(tx).ListPendingOrderCancellationRequests(ctx)
+	if err != nil {
+		r.logger.Error("Failed to list pending order cancellation requests", zap.Error(err))
+		return nil, err
+	}
+
+	requests := make([]*models.OrderCancellationRequest, 0, len(rows))
+	for _, row := range rows {
+		requests = append(requests, new(models.OrderCancellationRequest).ConvertSqlcOrderCancellationRequest(row))
+	}
+
+	return requests, nil
+}
+
+// ResolveOrderCancellationRequest 記錄人員的審核結果，回傳 false 表示申請已不是等待審核的狀態
+func (r *repository) ResolveOrderCancellationRequest(ctx context.Context, tx pgx.Tx, requestID uint64, status enum.CancellationRequestStatus, reviewedBy string) (bool, error) {
+	rows, err := r.queries.WithTx(tx).ResolveOrderCancellationRequest(ctx, sqlc.ResolveOrderCancellationRequestParams{
+		ID:         int32(requestID),
+		Status:     sqlc.CancellationRequestStatus(status),
+		ReviewedBy: &reviewedBy,
+	})
+	if err != nil {
+		r.logger.Error("Failed to resolve order cancellation request", zap.Uint64("request_id", requestID), zap.Error(err))
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+// GetCancellationReasonStats 依取消原因彙整 from 至 to（不含）期間的取消申請，依申請數由多到少排序
+func (r *repository) GetCancellationReasonStats(ctx context.Context, tx pgx.Tx, from, to time.Time) ([]*models.CancellationReasonStats, error) {
+	rows, err := r.queries.WithTx(tx).GetCancellationReasonStats(ctx, sqlc.GetCancellationReasonStatsParams{
+		RangeStart: pgtype.Timestamptz{Time: from, Valid: true},
+		RangeEnd:   pgtype.Timestamptz{Time: to, Valid: true},
+	})
+	if err != nil {
+		r.logger.Error("Failed to get cancellation reason stats", zap.Time("from", from), zap.Time("to", to), zap.Error(err))
+		return nil, err
+	}
+
+	stats := make([]*models.CancellationReasonStats, 0, len(rows))
+	for _, row := range rows {
+		stats = append(stats, &models.CancellationReasonStats{
+			Reason:    enum.CancellationReason(row.Reason),
+			Requests:  uint64(row.Requests),
+			Cancelled: uint64(row.Cancelled),
+			Pending:   uint64(row.Pending),
+			Rejected:  uint64(row.Rejected),
+		})
+	}
+
+	return stats, nil
+}
+
+// ListPendingOrderIDsByPrice 列出含有 priceID 且成交單價高於 unitPrice 的待付款訂單 ID，currency 為空字串時不限幣別
+func (r *repository) ListPendingOrderIDsByPrice(ctx context.Context, tx pgx.Tx, priceID string, currency stripe.Currency, unitPrice float64) ([]uint64, error) {
+	params := sqlc.ListPendingOrderIDsByPriceParams{
+		PriceID:   priceID,
+		UnitPrice: unitPrice,
+	}
+	if currency != "" {
+		params.Currency = nullableString(string(currency))
+	}
+
+	ids, err := r.queries.WithTx(tx).ListPendingOrderIDsByPrice(ctx, params)
+	if err != nil {
+		r.logger.Error("Failed to list pending orders by price", zap.String("price_id", priceID), zap.Error(err))
+		return nil, err
+	}
+
+	orderIDs := make([]uint64, 0, len(ids))
+	for _, id := range ids {
+		orderIDs = append(orderIDs, uint64(id))
+	}
+
+	return orderIDs, nil
+}
+
+// CreateOrderRepricing 記錄訂單的重新計價並設定 ID 與建立時間，同一筆價格變動已重新計價過時回傳 ErrOrderRepricingExists
+func (r *repository) CreateOrderRepricing(ctx context.Context, tx pgx.Tx, repricing *models.OrderRepricing) error {
+	row, err := r.queries.WithTx(tx).CreateOrderRepricing(ctx, sqlc.CreateOrderRepricingParams{
+		OrderID:       int32(repricing.OrderID),
+		PriceChangeID: int32(repricing.PriceChangeID),
+		PriceID:       repricing.PriceID,
+		NewUnitPrice:  repricing.NewUnitPrice,
+		PreviousTotal: repricing.PreviousTotal,
+		NewTotal:      repricing.NewTotal,
+		Status:        sqlc.OrderRepricingStatus(repricing.Status),
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return ErrOrderRepricingExists
+		}
+		r.logger.Error("Failed to create order repricing", zap.Uint64("order_id", repricing.OrderID), zap.Error(err))
+		return err
+	}
+
+	repricing.ConvertSqlcOrderRepricing(row)
+	return nil
+}
+
+// GetOrderRepricingForUpdate 取得並鎖定重新計價的記錄直到交易結束，不存在時回傳 pgx.ErrNoRows
+func (r *repository) GetOrderRepricingForUpdate(ctx context.Context, tx pgx.Tx, repricingID uint64) (*models.OrderRepricing, error) {
+	row, err := r.queries.WithTx(tx).GetOrderRepricingForUpdate(ctx, int32(repricingID))
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			r.logger.Error("Failed to get order repricing for update", zap.Uint64("repricing_id", repricingID), zap.Error(err))
+		}
+		return nil, err
+	}
+
+	return new(models.OrderRepricing).ConvertSqlcOrderRepricing(row), nil
+}
+
+// ListOrderRepricings 依建立順序列出訂單的重新計價記錄
+func (r *repository) ListOrderRepricings(ctx context.Context, tx pgx.Tx, orderID uint64) ([]*models.OrderRepricing, error) {
+	rows, err := r.queries.WithTx(tx).ListOrderRepricings(ctx, int32(orderID))
+	if err != nil {
+		r.logger.Error("Failed to list order repricings", zap.Uint64("order_id", orderID), zap.Error(err))
+		return nil, err
+	}
+
+	repricings := make([]*models.OrderRepricing, 0, len(rows))
+	for _, row := range rows {
+		repricings = append(repricings, new(models.OrderRepricing).ConvertSqlcOrderRepricing(row))
+	}
+
+	return repricings, nil
+}
+
+// ListPendingOrderRepricings 依建立順序列出等待審核的重新計價
+func (r *repository) ListPendingOrderRepricings(ctx context.Context, tx pgx.Tx) ([]*models.OrderRepricing, error) {
+	rows, err := r.queries.WithTx(tx).ListPendingOrderRepricings(ctx)
+	if err != nil {
+		r.logger.Error("Failed to list pending order repricings", zap.Error(err))
+		return nil, err
+	}
+
+	repricings := make([]*models.OrderRepricing, 0, len(rows))
+	for _, row := range rows {
+		repricings = append(repricings, new(models.OrderRepricing).ConvertSqlcOrderRepricing(row))
+	}
+
+	return repricings, nil
+}
+
+// ResolveOrderRepricing 記錄人員的審核結果與實際的訂單總額，回傳 false 表示記錄已不是等待審核的狀態
+func (r *repository) ResolveOrderRepricing(ctx context.Context, tx pgx.Tx, repricingID uint64, status enum.OrderRepricingStatus, reviewedBy string, newTotal float64) (bool, error) {
+	rows, err := r.queries.WithTx(tx).ResolveOrderRepricing(ctx, sqlc.ResolveOrderRepricingParams{
+		ID:         int32(repricingID),
+		Status:     sqlc.OrderRepricingStatus(status),
+		ReviewedBy: &reviewedBy,
+		NewTotal:   newTotal,
+	})
+	if err != nil {
+		r.logger.Error("Failed to resolve order repricing", zap.Uint64("repricing_id", repricingID), zap.Error(err))
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+// StartOrderFulfillmentSLA 記錄訂單的履約時限，訂單已有記錄時保留原本的時限（例如爭議後恢復為已付款）
+func (r *repository) StartOrderFulfillmentSLA(ctx context.Context, tx pgx.Tx, sla *models.OrderFulfillmentSLA) error {
+	if err := r.queries.WithTx(tx).StartOrderFulfillmentSLA(ctx, sqlc.StartOrderFulfillmentSLAParams{
+		OrderID: int32(sla.OrderID),
+		PaidAt:  pgtype.Timestamptz{Time: sla.PaidAt, Valid: true},
+		WarnAt:  pgtype.Timestamptz{Time: sla.WarnAt, Valid: true},
+		DueAt:   pgtype.Timestamptz{Time: sla.DueAt, Valid: true},
+	}); err != nil {
+		r.logger.Error("Failed to start order fulfillment SLA", zap.Uint64("order_id", sla.OrderID), zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// CompleteOrderFulfillmentSLA 記錄訂單的出貨時間，回傳 false 表示訂單沒有履約時限或已記錄過出貨
+func (r *repository) CompleteOrderFulfillmentSLA(ctx context.Context, tx pgx.Tx, orderID uint64, fulfilledAt time.Time) (bool, error) {
+	rows, err := r.queries.WithTx(tx).CompleteOrderFulfillmentSLA(ctx, sqlc.CompleteOrderFulfillmentSLAParams{
+		OrderID:     int32(orderID),
+		FulfilledAt: pgtype.Timestamptz{Time: fulfilledAt, Valid: true},
+	})
+	if err != nil {
+		r.logger.Error("Failed to complete order fulfillment SLA", zap.Uint64("order_id", orderID), zap.Error(err))
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+// GetOrderFulfillmentSLA 取得訂單的履約時限，訂單沒有記錄時回傳 pgx.ErrNoRows
+func (r *repository) GetOrderFulfillmentSLA(ctx context.Context, tx pgx.Tx, orderID uint64) (*models.OrderFulfillmentSLA, error) {
+	row, err := r.queries.WithTx(tx).GetOrderFulfillmentSLA(ctx, int32(orderID))
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			r.logger.Error("Failed to get order fulfillment SLA", zap.Uint64("order_id", orderID), zap.Error(err))
+		}
+		return nil, err
+	}
+
+	return new(models.OrderFulfillmentSLA).ConvertSqlcOrderFulfillmentSLA(row), nil
+}
+
+// ListOrdersAtRiskOfSLABreach 依期限先後列出在 now 時已進入警示期間、尚未出貨且狀態為 statuses 之一的訂單
+func (r *repository) ListOrdersAtRiskOfSLABreach(ctx context.Context, tx pgx.Tx, now time.Time, statuses []enum.OrderStatus) ([]*models.OrderFulfillmentSLA, error) {
+	params := sqlc.ListOrdersAtRiskOfSLABreachParams{
+		WarnAt:   pgtype.Timestamptz{Time: now, Valid: true},
+		Statuses: make([]string, 0, len(statuses)),
+	}
+	for _, status := range statuses {
+		params.Statuses = append(params.Statuses, string(status))
+	}
+
+	rows, err := r.queries.WithTx(tx).ListOrdersAtRiskOfSLABreach(ctx, params)
+	if err != nil {
+		r.logger.Error("Failed to list orders at risk of SLA breach", zap.Error(err))
+		return nil, err
+	}
+
+	slas := make([]*models.OrderFulfillmentSLA, 0, len(rows))
+	for _, row := range rows {
+		slas = append(slas, new(models.OrderFulfillmentSLA).ConvertSqlcOrderFulfillmentSLA(row))
+	}
+
+	return slas, nil
+}
+
+// MarkFulfillmentSLAAtRiskAlerted 記錄已送出違反風險的警示，回傳 false 表示已送出過
+func (r *repository) MarkFulfillmentSLAAtRiskAlerted(ctx context.Context, tx pgx.Tx, orderID uint64) (bool, error) {
+	rows, err := r.queries.WithTx(tx).MarkFulfillmentSLAAtRiskAlerted(ctx, int32(orderID))
+	if err != nil {
+		r.logger.Error("Failed to mark fulfillment SLA at-risk alert", zap.Uint64("order_id", orderID), zap.Error(err))
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+// MarkFulfillmentSLABreachAlerted 記錄已送出違反時限的警示，回傳 false 表示已送出過
+func (r *repository) MarkFulfillmentSLABreachAlerted(ctx context.Context, tx pgx.Tx, orderID uint64) (bool, error) {
+	rows, err := r.queries.WithTx(tx).MarkFulfillmentSLABreachAlerted(ctx, int32(orderID))
+	if err != nil {
+		r.logger.Error("Failed to mark fulfillment SLA breach alert", zap.Uint64("order_id", orderID), zap.Error(err))
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+// ListUnshippedOrderIDsByProduct 列出含有 productID、狀態為 statuses 之一且尚未建立任何出貨單的訂單 ID
+func (r *repository) ListUnshippedOrderIDsByProduct(ctx context.Context, tx pgx.Tx, productID string, statuses []enum.OrderStatus) ([]uint64, error) {
+	params := sqlc.ListUnshippedOrderIDsByProductParams{
+		ProductID: productID,
+		Statuses:  make([]string, 0, len(statuses)),
+	}
+	for _, status := range statuses {
+		params.Statuses = append(params.Statuses, string(status))
+	}
+
+	ids, err := r.queries.WithTx(tx).ListUnshippedOrderIDsByProduct(ctx, params)
+	if err != nil {
+		r.logger.Error("Failed to list unshipped orders by product", zap.String("product_id", productID), zap.Error(err))
+		return nil, err
+	}
+
+	orderIDs := make([]uint64, 0, len(ids))
+	for _, id := range ids {
+		orderIDs = append(orderIDs, uint64(id))
+	}
+
+	return orderIDs, nil
+}
+
+// ListOrphanedOrderItems 列出指向不存在或屬於其他商品之庫存的訂單項目
+func (r *repository) ListOrphanedOrderItems(ctx context.Context, tx pgx.Tx) ([]*models.OrphanedItem, error) {
+	rows, err := r.queries.WithTx(tx).ListOrphanedOrderItems(ctx)
+	if err != nil {
+		r.logger.Error("failed to list orphaned order items", zap.Error(err))
+		return nil, err
+	}
+
+	result := make([]*models.OrphanedItem, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, new(models.OrphanedItem).ConvertSqlcOrphanedItem(row))
+	}
+
+	return result, nil
+}
+
+// ListOrderTotalMismatches 列出金額與項目不一致的訂單
+func (r *repository) ListOrderTotalMismatches(ctx context.Context, tx pgx.Tx) ([]*models.TotalMismatch, error) {
+	rows, err := r.queries.WithTx(tx).ListOrderTotalMismatches(ctx)
+	if err != nil {
+		r.logger.Error("failed to list order total mismatches", zap.Error(err))
+		return nil, err
+	}
+
+	result := make([]*models.TotalMismatch, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, new(models.TotalMismatch).ConvertSqlcTotalMismatch(row))
+	}
+
+	return result, nil
+}
+
+// ArchiveOrders 將最後更新早於 cutoff 且未被軟刪除的已完成或已取消訂單（最多 batchSize 筆）連同項目搬移到封存表，回傳搬移的訂單數
+func (r *repository) ArchiveOrders(ctx context.Context, tx pgx.Tx, cutoff time.Time, batchSize int32) (int64, error) {
+	archived, err := r.queries.WithTx(tx).ArchiveOrders(ctx, sqlc.ArchiveOrdersParams{
+		Cutoff:    pgtype.Timestamptz{Time: cutoff, Valid: true},
+		BatchSize: batchSize,
+	})
+	if err != nil {
+		r.logger.Error("failed to archive orders", zap.Time("cutoff", cutoff), zap.Error(err))
+		return 0, err
+	}
+
+	return archived, nil
+}
+
+// MergeOrderMetadata 將 metadata 合併到訂單既有的 metadata，相同的 key 以新值覆蓋
+func (r *repository) MergeOrderMetadata(ctx context.Context, tx pgx.Tx, orderID uint64, metadata map[string]string) error {
+	raw, err := json.Marshal(metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal order metadata: %w", err)
+	}
+
+	rows, err := r.queries.WithTx(tx).MergeOrderMetadata(ctx, sqlc.MergeOrderMetadataParams{
+		ID:       int32(orderID),
+		Metadata: raw,
+	})
+	if err != nil {
+		r.logger.Error("failed to merge order metadata", zap.Uint64("order_id", orderID), zap.Error(err))
+		return err
+	}
+	if rows == 0 {
+		return pgx.ErrNoRows
+	}
+
+	r.invalidateOrderCache(ctx, orderID)
+	return nil
+}
+
+// SetOrderTaxCalculation 以外部稅務服務的計算結果更新訂單稅額與總額，並記錄計算 ID
+func (r *repository) SetOrderTaxCalculation(ctx context.Context, tx pgx.Tx, orderID uint64, tax float64, calculationID string) error {
+	rows, err := r.queries.WithTx(tx).SetOrderTaxCalculation(ctx, sqlc.SetOrderTaxCalculationParams{
+		ID:               int32(orderID),
+		Tax:              tax,
+		TaxCalculationID: &calculationID,
+	})
+	if err != nil {
+		r.logger.Error("failed to set order tax calculation", zap.Uint64("order_id", orderID), zap.Error(err))
+		return err
+	}
+	if rows == 0 {
+		return pgx.ErrNoRows
+	}
+
+	r.invalidateOrderCache(ctx, orderID)
+	return nil
+}
+
+// SetOrderTaxTransaction 記錄付款後建立的稅務交易 ID，回傳 false 表示訂單已有稅務交易
+func (r *repository) SetOrderTaxTransaction(ctx context.Context, tx pgx.Tx, orderID uint64, transactionID string) (bool, error) {
+	rows, err := r.queries.WithTx(tx).SetOrderTaxTransaction(ctx, sqlc.SetOrderTaxTransactionParams{
+		ID:               int32(orderID),
+		TaxTransactionID: &transactionID,
+	})
+	if err != nil {
+		r.logger.Error("failed to set order tax transaction", zap.Uint64("order_id", orderID), zap.Error(err))
+		return false, err
+	}
+
+	r.invalidateOrderCache(ctx, orderID)
+	return rows > 0, nil
+}
+
+// ListOrdersPendingTaxCommit 列出已付款、有稅額計算但尚未建立稅務交易，且在 before 之前最後更新的訂單，
+// 回傳訂單 ID 對應的稅額計算 ID
+func (r *repository) ListOrdersPendingTaxCommit(ctx context.Context, tx pgx.Tx, before time.Time, limit int32) (map[uint64]string, error) {
+	rows, err := r.queries.WithTx(tx).ListOrdersPendingTaxCommit(ctx, sqlc.ListOrdersPendingTaxCommitParams{
+		UpdatedAt: pgtype.Timestamptz{Time: before, Valid: true},
+		Limit:     limit,
+	})
+	if err != nil {
+		r.logger.Error("failed to list orders pending tax commit", zap.Error(err))
+		return nil, err
+	}
+
+	calculations := make(map[uint64]string, len(rows))
+	for _, row := range rows {
+		if row.TaxCalculationID != nil {
+			calculations[uint64(row.ID)] = *row.TaxCalculationID
+		}
+	}
+	return calculations, nil
+}
+
+// FindOrdersByMetadata 查詢 metadata 包含所有指定 key/value 的訂單，依建立時間由新到舊排序
+func (r *repository) FindOrdersByMetadata(ctx context.Context, tx pgx.Tx, metadata map[string]string, limit, offset uint64) ([]*models.Order, error) {
+	raw, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal order metadata: %w", err)
+	}
+
+	sqlcOrders, err := r.queries.WithTx(tx).FindOrdersByMetadata(ctx, sqlc.FindOrdersByMetadataParams{
+		Metadata: raw,
+		Limit:    int64(limit),
+		Offset:   int64(offset),
+	})
+	if err != nil {
+		r.logger.Error("failed to find orders by metadata", zap.Error(err))
+		return nil, err
+	}
+
+	orders := make([]*models.Order, 0, len(sqlcOrders))
+	for _, sqlcOrder := range sqlcOrders {
+		orders = append(orders, new(models.Order).ConvertSqlcOrder(sqlcOrder))
+	}
+
+	return orders, nil
+}
+
+// SetOrderReportingSnapshot 記錄訂單換算成報表幣別的匯率與金額
+func (r *repository) SetOrderReportingSnapshot(ctx context.Context, tx pgx.Tx, orderID uint64, reporting *models.ReportingAmounts) error {
+	rows, err := r.queries.WithTx(tx).SetOrderReportingSnapshot(ctx, sqlc.SetOrderReportingSnapshotParams{
+		ID: int32(orderID),
+		ReportingCurrency: sqlc.NullCurrency{
+			Currency: sqlc.Currency(reporting.Currency),
+			Valid:    true,
+		},
+		ExchangeRate:      &reporting.ExchangeRate,
+		ReportingSubtotal: &reporting.Subtotal,
+		ReportingTax:      &reporting.Tax,
+		ReportingDiscount: &reporting.Discount,
+		ReportingTotal:    &reporting.Total,
+	})
+	if err != nil {
+		r.logger.Error("failed to set order reporting snapshot", zap.Uint64("order_id", orderID), zap.Error(err))
+		return err
+	}
+	if rows == 0 {
+		return pgx.ErrNoRows
+	}
+
+	r.invalidateOrderCache(ctx, orderID)
+	return nil
+}
+
+// SetOrderDeliveryEstimate 記錄訂單的預估送達日期區間
+func (r *repository) SetOrderDeliveryEstimate(ctx context.Context, tx pgx.Tx, orderID uint64, window models.DeliveryWindow) error {
+	rows, err := r.queries.WithTx(tx).SetOrderDeliveryEstimate(ctx, sqlc.SetOrderDeliveryEstimateParams{
+		ID:                        int32(orderID),
+		EstimatedDeliveryEarliest: pgtype.Date{Time: window.Earliest, Valid: true},
+		EstimatedDeliveryLatest:   pgtype.Date{Time: window.Latest, Valid: true},
+	})
+	if err != nil {
+		r.logger.Error("failed to set order delivery estimate", zap.Uint64("order_id", orderID), zap.Error(err))
+		return err
+	}
+	if rows == 0 {
+		return pgx.ErrNoRows
+	}
+
+	r.invalidateOrderCache(ctx, orderID)
+	return nil
+}
+
+// SetOrderExternalID 記錄訂單的外部通路與外部編號，相同的通路與編號已被其他訂單使用時回傳 ErrExternalOrderExists
+// SetOrderCartSnapshot 記錄訂單結帳當下的購物車快照，已有快照時回傳 ErrCartSnapshotExists
+func (r *repository) SetOrderCartSnapshot(ctx context.Context, tx pgx.Tx, orderID uint64, snapshot *models.CartSnapshot) error {
+	raw, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to marshal order cart snapshot: %w", err)
+	}
+
+	rows, err := r.queries.WithTx(tx).SetOrderCartSnapshot(ctx, sqlc.SetOrderCartSnapshotParams{
+		ID:           int32(orderID),
+		CartSnapshot: raw,
+	})
+	if err != nil {
+		r.logger.Error("failed to set order cart snapshot", zap.Uint64("order_id", orderID), zap.Error(err))
+		return err
+	}
+	if rows == 0 {
+		return ErrCartSnapshotExists
+	}
+
+	r.invalidateOrderCache(ctx, orderID)
+	return nil
+}
+
+// GetOrderPaymentReference 取得訂單在 Stripe 上的客戶、payment intent 與 invoice，包含已封存的訂單
+func (r *repository) GetOrderPaymentReference(ctx context.Context, tx pgx.Tx, orderID uint64) (*models.OrderPaymentReference, error) {
+	sqlcReference, err := r.queries.WithTx(tx).GetOrderPaymentReference(ctx, int32(orderID))
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			r.logger.Error("failed to get order payment reference", zap.Uint64("order_id", orderID), zap.Error(err))
+		}
+		return nil, err
+	}
+
+	return new(models.OrderPaymentReference).ConvertSqlcOrderPaymentReference(orderID, sqlcReference), nil
+}
+
+// GetOrderReceiptLinks 取得已儲存的收據與發票網址，尚未產生時回傳 pgx.ErrNoRows
+func (r *repository) GetOrderReceiptLinks(ctx context.Context, tx pgx.Tx, orderID uint64) (*models.OrderReceiptLinks, error) {
+	sqlcLinks, err := r.queries.WithTx(tx).GetOrderReceiptLink(ctx, int32(orderID))
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			r.logger.Error("failed to get order receipt links", zap.Uint64("order_id", orderID), zap.Error(err))
+		}
+		return nil, err
+	}
+
+	return new(models.OrderReceiptLinks).ConvertSqlcOrderReceiptLinks(sqlcLinks), nil
+}
+
+// SetOrderReceiptLinks 儲存訂單的收據與發票網址，已有記錄時覆蓋，並回寫更新時間
+func (r *repository) SetOrderReceiptLinks(ctx context.Context, tx pgx.Tx, links *models.OrderReceiptLinks) error {
+	sqlcLinks, err := r.queries.WithTx(tx).UpsertOrderReceiptLink(ctx, sqlc.UpsertOrderReceiptLinkParams{
+		OrderID:         int32(links.OrderID),
+		PaymentIntentID: nullableString(links.PaymentIntentID),
+		InvoiceID:       nullableString(links.InvoiceID),
+		ReceiptUrl:      nullableString(links.ReceiptURL),
+		InvoiceUrl:      nullableString(links.InvoiceURL),
+		InvoicePdfUrl:   nullableString(links.InvoicePDFURL),
+	})
+	if err != nil {
+		r.logger.Error("failed to set order receipt links", zap.Uint64("order_id", links.OrderID), zap.Error(err))
+		return err
+	}
+
+	links.UpdatedAt = sqlcLinks.UpdatedAt.Time
+	return nil
+}
+
+// SetOrderChannel 記錄訂單的銷售通路
+func (r *repository) SetOrderChannel(ctx context.Context, tx pgx.Tx, orderID uint64, channel string) error {
+	if err := r.queries.WithTx(tx).SetOrderChannel(ctx, sqlc.SetOrderChannelParams{
+		ID:      int32(orderID),
+		Channel: &channel,
+	}); err != nil {
+		r.logger.Error("failed to set order channel", zap.Uint64("order_id", orderID), zap.String("channel", channel), zap.Error(err))
+		return err
+	}
+
+	r.invalidateOrderCache(ctx, orderID)
+	return nil
+}
+
+func (r *repository) SetOrderExternalID(ctx context.Context, tx pgx.Tx, orderID uint64, source, externalOrderID string) error {
+	rows, err := r.queries.WithTx(tx).SetOrderExternalID(ctx, sqlc.SetOrderExternalIDParams{
+		ID:              int32(orderID),
+		ExternalSource:  &source,
+		ExternalOrderID: &externalOrderID,
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return ErrExternalOrderExists
+		}
+		r.logger.Error("failed to set order external id", zap.Uint64("order_id", orderID), zap.Error(err))
+		return err
+	}
+	if rows == 0 {
+		return pgx.ErrNoRows
+	}
+
+	r.invalidateOrderCache(ctx, orderID)
+	return nil
+}
+
+// SetOrderPromotions 記錄訂單結帳時套用的優惠
+func (r *repository) SetOrderPromotions(ctx context.Context, tx pgx.Tx, orderID uint64, promotions []*models.AppliedPromotion) error {
+	raw := []byte("[]")
+	if len(promotions) > 0 {
+		var err error
+		if raw, err = json.Marshal(promotions); err != nil {
+			return fmt.Errorf("failed to marshal order promotions: %w", err)
+		}
+	}
+
+	if err := r.queries.WithTx(tx).SetOrderPromotions(ctx, sqlc.SetOrderPromotionsParams{
+		ID:                int32(orderID),
+		AppliedPromotions: raw,
+	}); err != nil {
+		r.logger.Error("failed to set order promotions", zap.Uint64("order_id", orderID), zap.Error(err))
+		return err
+	}
+
+	r.invalidateOrderCache(ctx, orderID)
+	return nil
+}
+
+// GetSalesReport 依訂單幣別與報表幣別彙整 from 至 to（不含）期間成立的訂單，包含已封存的訂單
+func (r *repository) GetSalesReport(ctx context.Context, tx pgx.Tx, from, to time.Time) ([]*models.CurrencySales, error) {
+	rows, err := r.queries.WithTx(tx).GetSalesReport(ctx, sqlc.GetSalesReportParams{
+		RangeStart: pgtype.Timestamptz{Time: from, Valid: true},
+		RangeEnd:   pgtype.Timestamptz{Time: to, Valid: true},
+	})
+	if err != nil {
+		r.logger.Error("failed to get sales report", zap.Time("from", from), zap.Time("to", to), zap.Error(err))
+		return nil, err
+	}
+
+	sales := make([]*models.CurrencySales, 0, len(rows))
+	for _, row := range rows {
+		sales = append(sales, new(models.CurrencySales).ConvertSqlcCurrencySales(row))
+	}
+
+	return sales, nil
+}
+
+// GetChannelSalesReport 依銷售通路與訂單幣別彙整 from 至 to（不含）期間成立的訂單，包含已封存的訂單
+func (r *repository) GetChannelSalesReport(ctx context.Context, tx pgx.Tx, from, to time.Time) ([]*models.ChannelSales, error) {
+	rows, err := r.queries.WithTx(tx).GetChannelSalesReport(ctx, sqlc.GetChannelSalesReportParams{
+		RangeStart: pgtype.Timestamptz{Time: from, Valid: true},
+		RangeEnd:   pgtype.Timestamptz{Time: to, Valid: true},
+	})
+	if err != nil {
+		r.logger.Error("failed to get channel sales report", zap.Time("from", from), zap.Time("to", to), zap.Error(err))
+		return nil, err
+	}
+
+	sales := make([]*models.ChannelSales, 0, len(rows))
+	for _, row := range rows {
+		sales = append(sales, new(models.ChannelSales).ConvertSqlcChannelSales(row))
+	}
+
+	return sales, nil
+}
+
+// ListCustomerOrderStats 彙總每位客戶自 since 起的有效訂單數、消費金額與首末次下單時間，包含已封存的訂單
+func (r *repository) ListCustomerOrderStats(ctx context.Context, tx pgx.Tx, since time.Time) ([]*models.CustomerOrderStats, error) {
+	rows, err := r.queries.WithTx(tx).ListCustomerOrderStats(ctx, pgtype.Timestamptz{Time: since, Valid: true})
+	if err != nil {
+		r.logger.Error("failed to list customer order stats", zap.Time("since", since), zap.Error(err))
+		return nil, err
+	}
+
+	stats := make([]*models.CustomerOrderStats, 0, len(rows))
+	for _, row := range rows {
+		stats = append(stats, new(models.CustomerOrderStats).ConvertSqlcCustomerOrderStats(row))
+	}
+
+	return stats, nil
+}
+
+// ClaimOrderIdempotencyKey 取得 idempotency key 的使用權，早於 expiredBefore 的記錄視為過期可重新使用；
+// 回傳 false 表示 key 已被使用。另一個交易正持有相同 key 時會等待該交易結束
+func (r *repository) ClaimOrderIdempotencyKey(ctx context.Context, tx pgx.Tx, key, requestHash string, expiredBefore time.Time) (bool, error) {
+	rows, err := r.queries.WithTx(tx).ClaimOrderIdempotencyKey(ctx, sqlc.ClaimOrderIdempotencyKeyParams{
+		IdempotencyKey: key,
+		RequestHash:    requestHash,
+		CreatedAt:      pgtype.Timestamptz{Time: expiredBefore, Valid: true},
+	})
+	if err != nil {
+		r.logger.Error("failed to claim order idempotency key", zap.String("key", key), zap.Error(err))
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+func (r *repository) GetOrderIdempotencyKey(ctx context.Context, tx pgx.Tx, key string) (*models.OrderIdempotencyKey, error) {
+	sqlcKey, err := r.queries.WithTx(tx).GetOrderIdempotencyKey(ctx, key)
+	if err != nil {
+		r.logger.Error("failed to get order idempotency key", zap.String("key", key), zap.Error(err))
+		return nil, err
+	}
+
+	return new(models.OrderIdempotencyKey).ConvertSqlcOrderIdempotencyKey(sqlcKey), nil
+}
+
+// SetOrderIdempotencyKeyOrder 記錄 idempotency key 建立的訂單，供之後的重試回傳同一筆訂單
+func (r *repository) SetOrderIdempotencyKeyOrder(ctx context.Context, tx pgx.Tx, key string, orderID uint64) error {
+	id := int32(orderID)
+	if err := r.queries.WithTx(tx).SetOrderIdempotencyKeyOrder(ctx, sqlc.SetOrderIdempotencyKeyOrderParams{
+		IdempotencyKey: key,
+		OrderID:        &id,
+	}); err != nil {
+		r.logger.Error("failed to set order idempotency key order", zap.String("key", key), zap.Uint64("order_id", orderID), zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// CreateOrderHold 新增訂單的暫停記錄，訂單已有未解除的暫停時回傳 unique violation
+func (r *repository) CreateOrderHold(ctx context.Context, tx pgx.Tx, orderID uint64, reason string, previousStatus enum.OrderStatus) (*models.OrderHold, error) {
+	sqlcHold, err := r.queries.WithTx(tx).CreateOrderHold(ctx, sqlc.CreateOrderHoldParams{
+		OrderID:        int32(orderID),
+		Reason:         reason,
+		PreviousStatus: sqlc.OrderStatus(previousStatus),
+	})
+	if err != nil {
+		r.logger.Error("failed to create order hold", zap.Uint64("order_id", orderID), zap.Error(err))
+		return nil, err
+	}
+
+	return new(models.OrderHold).ConvertSqlcOrderHold(sqlcHold), nil
+}
+
+// GetActiveOrderHold 取得訂單未解除的暫停記錄，沒有暫停時回傳 pgx.ErrNoRows
+func (r *repository) GetActiveOrderHold(ctx context.Context, tx pgx.Tx, orderID uint64) (*models.OrderHold, error) {
+	sqlcHold, err := r.queries.WithTx(tx).GetActiveOrderHold(ctx, int32(orderID))
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			r.logger.Error("failed to get active order hold", zap.Uint64("order_id", orderID), zap.Error(err))
+		}
+		return nil, err
+	}
+
+	return new(models.OrderHold).ConvertSqlcOrderHold(sqlcHold), nil
+}
+
+// ReleaseOrderHold 標記暫停記錄為已解除，回傳 false 表示記錄不存在或已經解除
+func (r *repository) ReleaseOrderHold(ctx context.Context, tx pgx.Tx, holdID uint64) (bool, error) {
+	rows, err := r.queries.WithTx(tx).ReleaseOrderHold(ctx, int32(holdID))
+	if err != nil {
+		r.logger.Error("failed to release order hold", zap.Uint64("hold_id", holdID), zap.Error(err))
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+func (r *repository) ListOrderHolds(ctx context.Context, tx pgx.Tx, orderID uint64) ([]*models.OrderHold, error) {
+	sqlcHolds, err := r.queries.WithTx(tx).ListOrderHolds(ctx, int32(orderID))
+	if err != nil {
+		r.logger.Error("failed to list order holds", zap.Uint64("order_id", orderID), zap.Error(err))
+		return nil, err
+	}
+
+	holds := make([]*models.OrderHold, 0, len(sqlcHolds))
+	for _, sqlcHold := range sqlcHolds {
+		holds = append(holds, new(models.OrderHold).ConvertSqlcOrderHold(sqlcHold))
+	}
+
+	return holds, nil
+}
+
+// RecordPaymentFingerprint 記錄 PaymentIntent 使用的卡片指紋，同一筆 PaymentIntent 重複記錄時略過
+func (r *repository) RecordPaymentFingerprint(ctx context.Context, tx pgx.Tx, paymentIntentID, fingerprint, customerID string, orderID uint64) error {
+	if err := r.queries.WithTx(tx).RecordPaymentFingerprint(ctx, sqlc.RecordPaymentFingerprintParams{
+		PaymentIntentID: paymentIntentID,
+		Fingerprint:     fingerprint,
+		CustomerID:      customerID,
+		OrderID:         int32(orderID),
+	}); err != nil {
+		r.logger.Error("failed to record payment fingerprint", zap.String("payment_intent_id", paymentIntentID), zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// CountFingerprintCustomers 計算 since 之後使用過指定卡片指紋的不同客戶數
+func (r *repository) CountFingerprintCustomers(ctx context.Context, tx pgx.Tx, fingerprint string, since time.Time) (uint64, error) {
+	count, err := r.queries.WithTx(tx).CountFingerprintCustomers(ctx, sqlc.CountFingerprintCustomersParams{
+		Fingerprint: fingerprint,
+		CreatedAt:   pgtype.Timestamptz{Time: since, Valid: true},
+	})
+	if err != nil {
+		r.logger.Error("failed to count fingerprint customers", zap.Error(err))
+		return 0, err
+	}
+
+	return uint64(count), nil
+}
+
+// CreateFraudReview 將訂單列入詐欺審核，回傳 false 表示訂單已有相同原因且待審核的記錄
+func (r *repository) CreateFraudReview(ctx context.Context, tx pgx.Tx, orderID uint64, reason enum.FraudReviewReason, detail string) (bool, error) {
+	rows, err := r.queries.WithTx(tx).CreateFraudReview(ctx, sqlc.CreateFraudReviewParams{
+		OrderID: int32(orderID),
+		Reason:  sqlc.FraudReviewReason(reason),
+		Detail:  detail,
+	})
+	if err != nil {
+		r.logger.Error("failed to create fraud review", zap.Uint64("order_id", orderID), zap.Error(err))
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+func (r *repository) GetFraudReview(ctx context.Context, tx pgx.Tx, reviewID uint64) (*models.FraudReview, error) {
+	sqlcReview, err := r.queries.WithTx(tx).GetFraudReview(ctx, int32(reviewID))
+	if err != nil {
+		r.logger.Error("failed to get fraud review", zap.Uint64("review_id", reviewID), zap.Error(err))
+		return nil, err
+	}
+
+	return new(models.FraudReview).ConvertSqlcFraudReview(sqlcReview), nil
+}
+
+func (r *repository) ListFraudReviewsByStatus(ctx context.Context, tx pgx.Tx, status enum.FraudReviewStatus) ([]*models.FraudReview, error) {
+	sqlcReviews, err := r.queries.WithTx(tx).ListFraudReviewsByStatus(ctx, sqlc.FraudReviewStatus(status))
+	if err != nil {
+		r.logger.Error("failed to list fraud reviews", zap.String("status", string(status)), zap.Error(err))
+		return nil, err
+	}
+
+	reviews := make([]*models.FraudReview, 0, len(sqlcReviews))
+	for _, sqlcReview := range sqlcReviews {
+		reviews = append(reviews, new(models.FraudReview).ConvertSqlcFraudReview(sqlcReview))
+	}
+
+	return reviews, nil
+}
+
+// ResolveFraudReview 記錄審核結果，回傳 false 表示記錄不存在或已經審核
+func (r *repository) ResolveFraudReview(ctx context.Context, tx pgx.Tx, reviewID uint64, status enum.FraudReviewStatus, resolvedBy, note string) (bool, error) {
+	var resolutionNote *string
+	if note != "" {
+		resolutionNote = &note
+	}
+
+	rows, err := r.queries.WithTx(tx).ResolveFraudReview(ctx, sqlc.ResolveFraudReviewParams{
+		ID:             int32(reviewID),
+		Status:         sqlc.FraudReviewStatus(status),
+		ResolvedBy:     &resolvedBy,
+		ResolutionNote: resolutionNote,
+	})
+	if err != nil {
+		r.logger.Error("failed to resolve fraud review", zap.Uint64("review_id", reviewID), zap.Error(err))
+		return false, err
+	}
+
+	return rows > 0, nil
+}
@@ -0,0 +1,180 @@
+package shop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stripe/stripe-go/v79"
+	"go.uber.org/zap"
+
+	"gofalre.io/shop/models"
+	"gofalre.io/shop/models/enum"
+)
+
+// ErrOrderAddonNotAllowed 表示訂單已付款或已進入付款流程，不能再變更加購服務
+var ErrOrderAddonNotAllowed = errors.New("order addons can only be changed before payment")
+
+// ErrOrderAddonNotAvailable 表示訂單幣別未設定該服務的價格
+var ErrOrderAddonNotAvailable = errors.New("order addon is not available for the order currency")
+
+// WithOrderAddonPrice 設定幣別的加購服務單價，未設定價格的幣別不提供該服務
+func WithOrderAddonPrice(currency stripe.Currency, addonType enum.OrderAddonType, unitPrice float64) Option {
+	return func(s *service) {
+		if s.orderAddonPrices == nil {
+			s.orderAddonPrices = make(map[stripe.Currency]map[enum.OrderAddonType]float64)
+		}
+		if s.orderAddonPrices[currency] == nil {
+			s.orderAddonPrices[currency] = make(map[enum.OrderAddonType]float64)
+		}
+		s.orderAddonPrices[currency][addonType] = unitPrice
+	}
+}
+
+// addonRequiresItem 組裝與延長保固必須指定訂單項目，禮品包裝可以套用在整筆訂單
+func addonRequiresItem(addonType enum.OrderAddonType) bool {
+	return addonType == enum.OrderAddonTypeAssembly || addonType == enum.OrderAddonTypeExtendedWarranty
+}
+
+// AddOrderAddon 為尚未付款的訂單加購服務，orderItemID 為 0 表示整筆訂單的服務；
+// 服務不佔用庫存，以服務類型作為稅別逐項計算稅額（不經過外部稅務服務的整筆訂單計算），並計入訂單的小計、稅額與總額
+func (s *service) AddOrderAddon(ctx context.Context, orderID uint64, addonType enum.OrderAddonType, orderItemID, quantity uint64, description string) (*models.OrderAddon, error) {
+	if quantity == 0 {
+		return nil, errors.New("addon quantity must be greater than zero")
+	}
+	if addonRequiresItem(addonType) && orderItemID == 0 {
+		return nil, fmt.Errorf("addon %s requires an order item", addonType)
+	}
+
+	var addon *models.OrderAddon
+
+	if err := s.executeOrderTransaction(ctx, func(tx pgx.Tx) error {
+		// 1. 鎖定訂單並確認仍可變更
+		orderModel, err := s.lockOrderForAddons(ctx, tx, orderID)
+		if err != nil {
+			return err
+		}
+
+		unitPrice, ok := s.orderAddonPrices[orderModel.Currency][addonType]
+		if !ok {
+			return fmt.Errorf("%w: %s in %s", ErrOrderAddonNotAvailable, addonType, orderModel.Currency)
+		}
+
+		// 2. 確認服務套用的訂單項目，數量不能超過項目的數量
+		var productID string
+		if orderItemID != 0 {
+			items, err := s.order.ListOrderItems(ctx, tx, orderID)
+			if err != nil {
+				return fmt.Errorf("failed to list order items: %w", err)
+			}
+			var item *models.OrderItem
+			for _, candidate := range items {
+				if candidate.ID == orderItemID {
+					item = candidate
+					break
+				}
+			}
+			if item == nil {
+				return fmt.Errorf("order item %d does not belong to order %d", orderItemID, orderID)
+			}
+			if quantity > item.Quantity {
+				return fmt.Errorf("addon quantity %d exceeds order item quantity %d", quantity, item.Quantity)
+			}
+			productID = item.ProductID
+		}
+
+		// 3. 計算金額與稅額
+		addon = &models.OrderAddon{
+			OrderID:     orderID,
+			Type:        addonType,
+			Description: description,
+			Quantity:    quantity,
+			UnitPrice:   unitPrice,
+			Subtotal:    roundCurrencyAmount(float64(quantity)*unitPrice, orderModel.Currency),
+		}
+		if orderItemID != 0 {
+			addon.OrderItemID = &orderItemID
+		}
+		exemption, err := s.taxExemption(ctx, tx, orderModel.CustomerID, orderModel.ShippingAddress, orderModel.BillingAddress)
+		if err != nil {
+			return err
+		}
+		addon.TaxRate, addon.TaxAmount, err = s.lineTax(ctx, exemption, productID, string(addonType), addon.Subtotal, orderModel.Currency)
+		if err != nil {
+			return err
+		}
+
+		// 4. 新增服務
+		if err = s.order.CreateOrderAddon(ctx, tx, addon); err != nil {
+			return fmt.Errorf("failed to create order addon: %w", err)
+		}
+
+		// 5. 更新訂單總計
+		return s.adjustOrderTotalsForAddon(ctx, tx, orderModel, addon.Subtotal, addon.TaxAmount)
+	}); err != nil {
+		return nil, err
+	}
+
+	s.log(ctx).Info("Order addon added",
+		zap.Uint64("order_id", orderID), zap.Uint64("addon_id", addon.ID), zap.String("type", string(addonType)))
+
+	return addon, nil
+}
+
+// RemoveOrderAddon 移除尚未付款訂單的加購服務，並從訂單總計扣除其金額與稅額
+func (s *service) RemoveOrderAddon(ctx context.Context, orderID, addonID uint64) error {
+	return s.executeOrderTransaction(ctx, func(tx pgx.Tx) error {
+		// 1. 鎖定訂單並確認仍可變更
+		orderModel, err := s.lockOrderForAddons(ctx, tx, orderID)
+		if err != nil {
+			return err
+		}
+
+		// 2. 移除服務
+		addon, err := s.order.DeleteOrderAddon(ctx, tx, orderID, addonID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return fmt.Errorf("addon %d does not belong to order %d", addonID, orderID)
+			}
+			return fmt.Errorf("failed to delete order addon: %w", err)
+		}
+
+		// 3. 更新訂單總計
+		return s.adjustOrderTotalsForAddon(ctx, tx, orderModel, -addon.Subtotal, -addon.TaxAmount)
+	})
+}
+
+// ListOrderAddons 列出訂單的加購服務
+func (s *service) ListOrderAddons(ctx context.Context, orderID uint64) ([]*models.OrderAddon, error) {
+	addons, err := s.order.ListOrderAddons(ctx, nil, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list order addons: %w", err)
+	}
+	return addons, nil
+}
+
+// lockOrderForAddons 鎖定訂單，只有尚未建立 PaymentIntent 的待付款訂單可以變更加購服務，避免金額與已送出的付款不一致
+func (s *service) lockOrderForAddons(ctx context.Context, tx pgx.Tx, orderID uint64) (*models.Order, error) {
+	orderModel, err := s.order.GetOrderForUpdate(ctx, tx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order for update: %w", err)
+	}
+	if orderModel.Status != enum.OrderStatusPending || orderModel.PaymentIntentID != "" {
+		return nil, fmt.Errorf("%w: order %d is %s", ErrOrderAddonNotAllowed, orderID, orderModel.Status)
+	}
+	return orderModel, nil
+}
+
+// adjustOrderTotalsForAddon 將服務的金額與稅額加到訂單總計（移除時為負數），並更新報表幣別的快照
+func (s *service) adjustOrderTotalsForAddon(ctx context.Context, tx pgx.Tx, orderModel *models.Order, subtotal, tax float64) error {
+	orderModel.Subtotal = roundCurrencyAmount(orderModel.Subtotal+subtotal, orderModel.Currency)
+	orderModel.Tax = roundCurrencyAmount(orderModel.Tax+tax, orderModel.Currency)
+	orderModel.Total = roundCurrencyAmount(orderModel.Subtotal+orderModel.Tax-orderModel.Discount, orderModel.Currency)
+
+	if err := s.order.UpdateOrderTotals(ctx, tx, orderModel.ID, orderModel.Tax, orderModel.Subtotal, orderModel.Discount, orderModel.Total, orderModel.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to update order totals: %w", err)
+	}
+
+	return s.recordReportingSnapshot(ctx, tx, orderModel)
+}
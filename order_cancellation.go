@@ -0,0 +1,386 @@
+package shop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+
+	"gofalre.io/shop/models"
+	"gofalre.io/shop/models/enum"
+	"gofalre.io/shop/stock"
+)
+
+// defaultCancellationWindow 未設定時客戶下單後可以自行取消未付款訂單的期間
+const defaultCancellationWindow = time.Hour
+
+// ErrOrderNotOwned 表示訂單不屬於提出要求的客戶
+var ErrOrderNotOwned = errors.New("order does not belong to the customer")
+
+// ErrCancellationRequestNotFound 表示取消申請不存在
+var ErrCancellationRequestNotFound = errors.New("cancellation request not found")
+
+// PaymentCanceller 取消尚未完成付款的 payment intent，IdempotencyKey 相同的要求只會取消一次
+type PaymentCanceller interface {
+	CancelPayment(ctx context.Context, req PaymentCancelRequest) error
+}
+
+// PaymentCancelRequest 描述一筆要向金流服務取消的付款
+type PaymentCancelRequest struct {
+	OrderID         uint64
+	PaymentIntentID string
+	IdempotencyKey  string
+}
+
+// WithPaymentCanceller 設定取消訂單時取消 payment intent 使用的金流服務；
+// 未設定時已建立 payment intent 的訂單無法自助取消，申請會等待人員審核
+func WithPaymentCanceller(canceller PaymentCanceller) Option {
+	return func(s *service) {
+		s.paymentCanceller = canceller
+	}
+}
+
+// WithCancellationWindow 設定客戶下單後可以自行取消未付款訂單的期間，超過期間的申請需人員審核
+func WithCancellationWindow(window time.Duration) Option {
+	return func(s *service) {
+		if window > 0 {
+			s.cancellationWindow = window
+		}
+	}
+}
+
+// cancellableOrderStatuses 為客戶可以申請取消的訂單狀態，pending 以外的訂單已付款，須經人員審核後退款
+var cancellableOrderStatuses = map[enum.OrderStatus]bool{
+	enum.OrderStatusPending:        true,
+	enum.OrderStatusPaid:           true,
+	enum.OrderStatusReadyForPickup: true,
+}
+
+// validCancellationReasons 為客戶可以選擇的取消原因
+var validCancellationReasons = map[enum.CancellationReason]bool{
+	enum.CancellationReasonChangedMind:      true,
+	enum.CancellationReasonOrderedByMistake: true,
+	enum.CancellationReasonFoundBetterPrice: true,
+	enum.CancellationReasonDeliveryTooSlow:  true,
+	enum.CancellationReasonPaymentIssue:     true,
+	enum.CancellationReasonOther:            true,
+}
+
+// RequestOrderCancellation 由客戶申請取消自己的訂單。未付款且在取消期間內的訂單直接取消：恢復庫存並取消 payment intent；
+// 其餘訂單建立等待人員審核的申請。每筆申請都會記錄原因，可透過 GetCancellationReasonStats 分析
+func (s *service) RequestOrderCancellation(ctx context.Context, orderID uint64, customerID string, reason enum.CancellationReason) (*models.OrderCancellationRequest, error) {
+	if customerID == "" {
+		return nil, errors.New("customer ID is required")
+	}
+	if !validCancellationReasons[reason] {
+		return nil, fmt.Errorf("invalid cancellation reason: %q", reason)
+	}
+
+	var request *models.OrderCancellationRequest
+
+	if err := s.executeOrderTransaction(ctx, func(tx pgx.Tx) error {
+		// 1. 鎖定訂單並確認為客戶本人的訂單
+		orderModel, err := s.order.GetOrderForUpdate(ctx, tx, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to get order: %w", err)
+		}
+		if orderModel.CustomerID != customerID {
+			return fmt.Errorf("%w: order %d", ErrOrderNotOwned, orderID)
+		}
+
+		if err = s.checkAuthorization(ctx, AuthorizationRequest{
+			Action:     ActionRequestOrderCancellation,
+			ResourceID: orderID,
+			CustomerID: customerID,
+		}); err != nil {
+			return err
+		}
+
+		// 2. 檢查訂單是否可以取消
+		if !cancellableOrderStatuses[orderModel.Status] {
+			return fmt.Errorf("order %d is %s and cannot be cancelled", orderID, orderModel.Status)
+		}
+
+		// 3. 記錄申請與原因，已有等待審核的申請時不重複建立
+		status := enum.CancellationRequestStatusPendingReview
+		autoCancel := s.canAutoCancel(orderModel, time.Now())
+		if autoCancel {
+			status = enum.CancellationRequestStatusAutoCancelled
+		}
+		request, err = s.order.CreateOrderCancellationRequest(ctx, tx, orderID, customerID, reason, status)
+		if err != nil {
+			return fmt.Errorf("failed to create cancellation request: %w", err)
+		}
+
+		// 4. 符合條件時直接取消
+		if autoCancel {
+			return s.cancelUnpaidOrder(ctx, tx, orderModel)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	s.log(ctx).Info("Order cancellation requested",
+		zap.Uint64("order_id", orderID), zap.Uint64("request_id", request.ID),
+		zap.String("reason", string(reason)), zap.String("status", string(request.Status)))
+	return request, nil
+}
+
+// ListPendingOrderCancellations 依申請順序列出等待人員審核的取消申請
+func (s *service) ListPendingOrderCancellations(ctx context.Context) ([]*models.OrderCancellationRequest, error) {
+	var requests []*models.OrderCancellationRequest
+
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		var err error
+		if requests, err = s.order.ListPendingOrderCancellationRequests(ctx, tx); err != nil {
+			return fmt.Errorf("failed to list pending cancellation requests: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return requests, nil
+}
+
+// ApproveOrderCancellation 核准等待審核的取消申請：未付款的訂單直接取消；已付款的訂單先依退款政策全額退款，
+// 退款結果由 Stripe 事件更新訂單狀態，已出貨的商品不會自動恢復庫存。退款後核准失敗時可以重新呼叫，不會重複退款
+func (s *service) ApproveOrderCancellation(ctx context.Context, requestID uint64, reviewedBy string) (*models.OrderCancellationRequest, error) {
+	if reviewedBy == "" {
+		return nil, errors.New("cancellation reviewer is required")
+	}
+
+	// 1. 已付款的訂單先退款，退款在獨立的交易中進行
+	var request *models.OrderCancellationRequest
+	var orderStatus enum.OrderStatus
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		var err error
+		if request, err = s.getPendingCancellationRequest(ctx, tx, requestID, reviewedBy); err != nil {
+			return err
+		}
+		orderModel, err := s.order.GetOrder(ctx, tx, request.OrderID)
+		if err != nil {
+			return fmt.Errorf("failed to get order: %w", err)
+		}
+		orderStatus = orderModel.Status
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	if orderStatus != enum.OrderStatusPending && cancellableOrderStatuses[orderStatus] {
+		if _, err := s.RefundOrder(ctx, request.OrderID, nil, "customer cancellation: "+string(request.Reason)); err != nil {
+			return nil, fmt.Errorf("failed to refund order: %w", err)
+		}
+	}
+
+	// 2. 取消未付款的訂單並記錄審核結果
+	if err := s.executeOrderTransaction(ctx, func(tx pgx.Tx) error {
+		var err error
+		if request, err = s.getPendingCancellationRequest(ctx, tx, requestID, reviewedBy); err != nil {
+			return err
+		}
+
+		orderModel, err := s.order.GetOrderForUpdate(ctx, tx, request.OrderID)
+		if err != nil {
+			return fmt.Errorf("failed to get order: %w", err)
+		}
+		switch orderModel.Status {
+		case enum.OrderStatusPending:
+			if !s.statusMachine.CanTransition(orderModel.Status, enum.OrderStatusCancelled) {
+				return fmt.Errorf("invalid status transition from %s to %s", orderModel.Status, enum.OrderStatusCancelled)
+			}
+			if orderModel.PaymentIntentID != "" && s.paymentCanceller == nil {
+				return fmt.Errorf("order %d has a payment intent but no payment canceller is configured", orderModel.ID)
+			}
+			if err = s.cancelUnpaidOrder(ctx, tx, orderModel); err != nil {
+				return err
+			}
+		case enum.OrderStatusRefundPending, enum.OrderStatusRefunded, enum.OrderStatusCancelled:
+			// 已退款或已取消
+		default:
+			return fmt.Errorf("order %d is %s and cannot be cancelled", orderModel.ID, orderModel.Status)
+		}
+
+		return s.resolveCancellationRequest(ctx, tx, request, enum.CancellationRequestStatusApproved, reviewedBy)
+	}); err != nil {
+		return nil, err
+	}
+
+	s.log(ctx).Info("Order cancellation approved",
+		zap.Uint64("order_id", request.OrderID), zap.Uint64("request_id", requestID), zap.String("reviewed_by", reviewedBy))
+	return request, nil
+}
+
+// RejectOrderCancellation 拒絕等待審核的取消申請，訂單維持原本的狀態
+func (s *service) RejectOrderCancellation(ctx context.Context, requestID uint64, reviewedBy string) (*models.OrderCancellationRequest, error) {
+	if reviewedBy == "" {
+		return nil, errors.New("cancellation reviewer is required")
+	}
+
+	var request *models.OrderCancellationRequest
+
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		var err error
+		if request, err = s.getPendingCancellationRequest(ctx, tx, requestID, reviewedBy); err != nil {
+			return err
+		}
+		return s.resolveCancellationRequest(ctx, tx, request, enum.CancellationRequestStatusRejected, reviewedBy)
+	}); err != nil {
+		return nil, err
+	}
+
+	s.log(ctx).Info("Order cancellation rejected",
+		zap.Uint64("order_id", request.OrderID), zap.Uint64("request_id", requestID), zap.String("reviewed_by", reviewedBy))
+	return request, nil
+}
+
+// GetCancellationReasonStats 依取消原因彙整 from 至 to（不含）期間的取消申請，包含直接取消與等待審核的申請
+func (s *service) GetCancellationReasonStats(ctx context.Context, from, to time.Time) ([]*models.CancellationReasonStats, error) {
+	if !to.After(from) {
+		return nil, errors.New("stats range must end after it starts")
+	}
+
+	var stats []*models.CancellationReasonStats
+
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		var err error
+		if stats, err = s.order.GetCancellationReasonStats(ctx, tx, from, to); err != nil {
+			return fmt.Errorf("failed to get cancellation reason stats: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// canAutoCancel 判斷訂單是否可以不經審核直接取消：尚未付款、在取消期間內，且 payment intent 可以被取消
+func (s *service) canAutoCancel(orderModel *models.Order, now time.Time) bool {
+	if orderModel.Status != enum.OrderStatusPending || now.Sub(orderModel.CreatedAt) > s.cancellationWindow {
+		return false
+	}
+	if !s.statusMachine.CanTransition(orderModel.Status, enum.OrderStatusCancelled) {
+		return false
+	}
+	return orderModel.PaymentIntentID == "" || s.paymentCanceller != nil
+}
+
+// cancelUnpaidOrder 取消未付款的訂單、恢復庫存並取消 payment intent。payment intent 在最後取消，
+// 交易因此回復時，Stripe 的 payment_intent.canceled 事件仍會取消訂單並恢復庫存
+func (s *service) cancelUnpaidOrder(ctx context.Context, tx pgx.Tx, orderModel *models.Order) error {
+	// 1. 更新訂單狀態並執行狀態機的 hook
+	if err := s.order.UpdateOrderStatus(ctx, tx, orderModel.ID, enum.OrderStatusCancelled, orderModel.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to update order status: %w", err)
+	}
+	if err := s.statusMachine.enter(ctx, tx, orderModel, enum.OrderStatusCancelled); err != nil {
+		return err
+	}
+
+	// 2. 恢復庫存
+	if err := s.restockOrderItems(ctx, tx, orderModel.ID); err != nil {
+		return err
+	}
+
+	// 3. 取消 payment intent，交易重試時以相同的 idempotency key 取消
+	if orderModel.PaymentIntentID == "" {
+		return nil
+	}
+	if err := s.paymentCanceller.CancelPayment(ctx, PaymentCancelRequest{
+		OrderID:         orderModel.ID,
+		PaymentIntentID: orderModel.PaymentIntentID,
+		IdempotencyKey:  "order-cancel-" + strconv.FormatUint(orderModel.ID, 10),
+	}); err != nil {
+		return fmt.Errorf("failed to cancel payment: %w", err)
+	}
+
+	return nil
+}
+
+// restockOrderItems 將訂單項目的數量加回庫存並記錄庫存變動
+func (s *service) restockOrderItems(ctx context.Context, tx pgx.Tx, orderID uint64) error {
+	items, err := s.order.ListOrderItems(ctx, tx, orderID)
+	if err != nil {
+		return fmt.Errorf("failed to list order items: %w", err)
+	}
+
+	adjustParams := make([]stock.AdjustStockParams, len(items))
+	moveParams := make([]stock.CreateStockMovementParams, len(items))
+
+	for i, item := range items {
+		stockModel, err := s.stock.GetStock(ctx, tx, item.StockID)
+		if err != nil {
+			return fmt.Errorf("failed to get stock for item %s: %w", item.ProductID, err)
+		}
+
+		adjustParams[i] = stock.AdjustStockParams{
+			StockID:     item.StockID,
+			Quantity:    item.Quantity,
+			LastUpdated: stockModel.UpdatedAt,
+		}
+
+		moveParams[i] = stock.CreateStockMovementParams{
+			StockID:         item.StockID,
+			Quantity:        item.Quantity,
+			Type:            enum.StockMovementTypeIn,
+			ReferenceID:     orderID,
+			ReferenceType:   enum.StockMovementReferenceTypeOrder,
+			ReferenceItemID: item.ID,
+		}
+	}
+
+	if err = s.stock.AdjustStock(ctx, tx, adjustParams); err != nil {
+		return fmt.Errorf("failed to adjust stock: %w", err)
+	}
+	if err = s.stock.CreateStockMovements(ctx, tx, moveParams); err != nil {
+		return fmt.Errorf("failed to create stock movements: %w", err)
+	}
+
+	return nil
+}
+
+// getPendingCancellationRequest 鎖定等待審核的取消申請並檢查審核者的權限，不存在時回傳 ErrCancellationRequestNotFound
+func (s *service) getPendingCancellationRequest(ctx context.Context, tx pgx.Tx, requestID uint64, reviewedBy string) (*models.OrderCancellationRequest, error) {
+	request, err := s.order.GetOrderCancellationRequestForUpdate(ctx, tx, requestID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%w: %d", ErrCancellationRequestNotFound, requestID)
+		}
+		return nil, fmt.Errorf("failed to get cancellation request: %w", err)
+	}
+	if request.Status != enum.CancellationRequestStatusPendingReview {
+		return nil, fmt.Errorf("cancellation request %d is already %s", requestID, request.Status)
+	}
+
+	if err = s.checkAuthorization(ctx, AuthorizationRequest{
+		Action:     ActionReviewOrderCancellation,
+		ResourceID: request.ID,
+		CustomerID: request.CustomerID,
+		Actor:      reviewedBy,
+	}); err != nil {
+		return nil, err
+	}
+
+	return request, nil
+}
+
+// resolveCancellationRequest 記錄審核結果並更新 request
+func (s *service) resolveCancellationRequest(ctx context.Context, tx pgx.Tx, request *models.OrderCancellationRequest, status enum.CancellationRequestStatus, reviewedBy string) error {
+	ok, err := s.order.ResolveOrderCancellationRequest(ctx, tx, request.ID, status, reviewedBy)
+	if err != nil {
+		return fmt.Errorf("failed to resolve cancellation request: %w", err)
+	}
+	if !ok {
+		return fmt.Errorf("cancellation request %d has already been reviewed", request.ID)
+	}
+
+	request.Status = status
+	request.ReviewedBy = reviewedBy
+	return nil
+}
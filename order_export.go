@@ -0,0 +1,65 @@
+package shop
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+
+	"gofalre.io/shop/models"
+)
+
+// orderExportHeader 為訂單匯出 CSV 的欄位
+var orderExportHeader = []string{
+	"order_number", "customer_id", "status", "currency", "subtotal", "tax", "discount", "total",
+	"fulfillment_type", "payment_intent_id", "created_at",
+}
+
+// ExportOrders 將符合 filter 的訂單以 CSV 寫入 w，第一列為欄位名稱；
+// 訂單逐筆從資料庫串流寫出，記憶體用量不隨訂單數量增加
+func (s *service) ExportOrders(ctx context.Context, filter models.OrderFilter, w io.Writer) error {
+	if !filter.CreatedFrom.IsZero() && !filter.CreatedTo.IsZero() && !filter.CreatedFrom.Before(filter.CreatedTo) {
+		return fmt.Errorf("invalid export period: %s to %s", filter.CreatedFrom.Format(time.RFC3339), filter.CreatedTo.Format(time.RFC3339))
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(orderExportHeader); err != nil {
+		return fmt.Errorf("failed to write order export header: %w", err)
+	}
+
+	var exported int
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		return s.order.StreamOrders(ctx, tx, filter, func(order *models.Order) error {
+			exported++
+			return writer.Write([]string{
+				order.OrderNumber,
+				order.CustomerID,
+				string(order.Status),
+				string(order.Currency),
+				strconv.FormatFloat(order.Subtotal, 'f', 2, 64),
+				strconv.FormatFloat(order.Tax, 'f', 2, 64),
+				strconv.FormatFloat(order.Discount, 'f', 2, 64),
+				strconv.FormatFloat(order.Total, 'f', 2, 64),
+				string(order.FulfillmentType),
+				order.PaymentIntentID,
+				order.CreatedAt.UTC().Format(time.RFC3339),
+			})
+		})
+	}); err != nil {
+		return fmt.Errorf("failed to export orders: %w", err)
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write order export: %w", err)
+	}
+
+	s.log(ctx).Info("Exported orders", zap.Int("orders", exported))
+
+	return nil
+}
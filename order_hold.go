@@ -0,0 +1,208 @@
+package shop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+
+	"gofalre.io/shop/models"
+	"gofalre.io/shop/models/enum"
+)
+
+// ErrOrderOnHold 表示訂單已被人員暫停，暫停期間不能揀貨或出貨
+var ErrOrderOnHold = errors.New("order is on hold")
+
+// ErrOrderNotOnHold 表示訂單沒有未解除的暫停
+var ErrOrderNotOnHold = errors.New("order is not on hold")
+
+// HoldOrder 暫停訂單履約，暫停期間不能產生揀貨單或建立出貨單；暫停前的狀態會保留，解除時恢復
+func (s *service) HoldOrder(ctx context.Context, orderID uint64, reason string) error {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return errors.New("hold reason is required")
+	}
+
+	return s.executeOrderTransaction(ctx, func(tx pgx.Tx) error {
+		// 1. 鎖定訂單並確認可以暫停
+		orderModel, err := s.order.GetOrderForUpdate(ctx, tx, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to get order: %w", err)
+		}
+		if orderModel.Status == enum.OrderStatusOnHold {
+			return ErrOrderOnHold
+		}
+		if !s.statusMachine.CanTransition(orderModel.Status, enum.OrderStatusOnHold) {
+			return fmt.Errorf("order %d is %s and cannot be put on hold", orderID, orderModel.Status)
+		}
+
+		// 2. 記錄暫停原因與暫停前的狀態
+		hold, err := s.order.CreateOrderHold(ctx, tx, orderID, reason, orderModel.Status)
+		if err != nil {
+			return fmt.Errorf("failed to create order hold: %w", err)
+		}
+
+		// 3. 更新訂單狀態
+		if err = s.order.UpdateOrderStatus(ctx, tx, orderID, enum.OrderStatusOnHold, orderModel.UpdatedAt); err != nil {
+			return fmt.Errorf("failed to update order status: %w", err)
+		}
+		if err = s.statusMachine.enter(ctx, tx, orderModel, enum.OrderStatusOnHold); err != nil {
+			return err
+		}
+
+		s.log(ctx).Info("Order put on hold", zap.Uint64("order_id", orderID), zap.Uint64("hold_id", hold.ID), zap.String("reason", reason))
+
+		return nil
+	})
+}
+
+// ReleaseOrderHold 解除訂單的暫停並恢復暫停前的狀態；暫停期間狀態已被金流事件改變時只解除暫停，不覆蓋目前的狀態
+func (s *service) ReleaseOrderHold(ctx context.Context, orderID uint64) error {
+	return s.executeOrderTransaction(ctx, func(tx pgx.Tx) error {
+		// 1. 鎖定訂單並取得未解除的暫停
+		orderModel, err := s.order.GetOrderForUpdate(ctx, tx, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to get order: %w", err)
+		}
+		hold, err := s.order.GetActiveOrderHold(ctx, tx, orderID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrOrderNotOnHold
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get order hold: %w", err)
+		}
+
+		// 2. 解除暫停
+		released, err := s.order.ReleaseOrderHold(ctx, tx, hold.ID)
+		if err != nil {
+			return fmt.Errorf("failed to release order hold: %w", err)
+		}
+		if !released {
+			return ErrOrderNotOnHold
+		}
+
+		// 3. 恢復暫停前的狀態
+		if orderModel.Status == enum.OrderStatusOnHold {
+			if err = s.order.UpdateOrderStatus(ctx, tx, orderID, hold.PreviousStatus, orderModel.UpdatedAt); err != nil {
+				return fmt.Errorf("failed to update order status: %w", err)
+			}
+		}
+
+		s.log(ctx).Info("Order hold released", zap.Uint64("order_id", orderID), zap.Uint64("hold_id", hold.ID))
+
+		return nil
+	})
+}
+
+// GetOrderTimeline 依時間先後回傳訂單的建立、暫停、出貨、退款與重新計價事件
+func (s *service) GetOrderTimeline(ctx context.Context, orderID uint64) ([]*models.OrderTimelineEntry, error) {
+	var timeline []*models.OrderTimelineEntry
+
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		// 1. 訂單建立
+		orderModel, err := s.order.GetOrder(ctx, tx, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to get order: %w", err)
+		}
+		timeline = append(timeline, &models.OrderTimelineEntry{
+			Event: enum.OrderTimelineEventCreated,
+			At:    orderModel.CreatedAt,
+		})
+
+		// 2. 暫停與解除
+		holds, err := s.order.ListOrderHolds(ctx, tx, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to list order holds: %w", err)
+		}
+		for _, hold := range holds {
+			timeline = append(timeline, &models.OrderTimelineEntry{
+				Event:       enum.OrderTimelineEventHoldPlaced,
+				At:          hold.CreatedAt,
+				ReferenceID: hold.ID,
+				Detail:      hold.Reason,
+			})
+			if hold.ReleasedAt != nil {
+				timeline = append(timeline, &models.OrderTimelineEntry{
+					Event:       enum.OrderTimelineEventHoldReleased,
+					At:          *hold.ReleasedAt,
+					ReferenceID: hold.ID,
+				})
+			}
+		}
+
+		// 3. 出貨單
+		shipments, err := s.order.ListShipments(ctx, tx, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to list shipments: %w", err)
+		}
+		for _, shipment := range shipments {
+			timeline = append(timeline, &models.OrderTimelineEntry{
+				Event:       enum.OrderTimelineEventShipmentCreated,
+				At:          shipment.CreatedAt,
+				ReferenceID: shipment.ID,
+				Detail:      shipment.Location,
+			})
+		}
+
+		// 4. 退款
+		refunds, err := s.order.ListRefunds(ctx, tx, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to list refunds: %w", err)
+		}
+		for _, refund := range refunds {
+			timeline = append(timeline, &models.OrderTimelineEntry{
+				Event:       enum.OrderTimelineEventRefunded,
+				At:          refund.CreatedAt,
+				ReferenceID: refund.ID,
+				Detail:      refund.Reason,
+			})
+		}
+
+		// 5. 重新計價，經人員審核的記錄同時列出送審與審核結果
+		repricings, err := s.order.ListOrderRepricings(ctx, tx, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to list order repricings: %w", err)
+		}
+		for _, repricing := range repricings {
+			detail := fmt.Sprintf("%s %.2f: %.2f -> %.2f", repricing.PriceID, repricing.NewUnitPrice, repricing.PreviousTotal, repricing.NewTotal)
+			if repricing.Status == enum.OrderRepricingStatusPendingReview || repricing.ReviewedBy != "" {
+				timeline = append(timeline, &models.OrderTimelineEntry{
+					Event:       enum.OrderTimelineEventRepricingFlagged,
+					At:          repricing.CreatedAt,
+					ReferenceID: repricing.ID,
+					Detail:      detail,
+				})
+			}
+			switch repricing.Status {
+			case enum.OrderRepricingStatusApplied:
+				timeline = append(timeline, &models.OrderTimelineEntry{
+					Event:       enum.OrderTimelineEventRepriced,
+					At:          repricing.UpdatedAt,
+					ReferenceID: repricing.ID,
+					Detail:      detail,
+				})
+			case enum.OrderRepricingStatusDismissed:
+				timeline = append(timeline, &models.OrderTimelineEntry{
+					Event:       enum.OrderTimelineEventRepricingDismissed,
+					At:          repricing.UpdatedAt,
+					ReferenceID: repricing.ID,
+					Detail:      repricing.ReviewedBy,
+				})
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(timeline, func(i, j int) bool {
+		return timeline[i].At.Before(timeline[j].At)
+	})
+
+	return timeline, nil
+}
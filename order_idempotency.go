@@ -0,0 +1,74 @@
+package shop
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"gofalre.io/shop/models"
+)
+
+// orderIdempotencyKeyTTL 為 idempotency key 的保存期限，與 Stripe 相同，過期後相同的 key 視為新的請求
+const orderIdempotencyKeyTTL = 24 * time.Hour
+
+// ErrIdempotencyKeyReused 表示 idempotency key 已被內容不同的請求使用
+var ErrIdempotencyKeyReused = errors.New("idempotency key was already used with a different request")
+
+// ErrIdempotencyKeyInUse 表示相同 idempotency key 的請求正在處理中，呼叫端應稍後重試
+var ErrIdempotencyKeyInUse = errors.New("a request with the same idempotency key is in progress")
+
+// orderRequestHash 計算手動建立訂單請求內容的 SHA-256，須在訂單被寫入 ID 等欄位前呼叫
+func orderRequestHash(order *models.Order) (string, error) {
+	payload, err := json.Marshal(order)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode order request: %w", err)
+	}
+
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// claimOrderIdempotencyKey 在交易內取得 idempotency key；key 已建立過訂單時回傳該訂單（含項目），
+// 呼叫端應直接回傳而不是再建立一筆
+func (s *service) claimOrderIdempotencyKey(ctx context.Context, tx pgx.Tx, key, requestHash string) (*models.Order, error) {
+	// 1. 取得 key，另一個交易同時使用相同的 key 時會在提交後發生序列化衝突
+	claimed, err := s.order.ClaimOrderIdempotencyKey(ctx, tx, key, requestHash, time.Now().Add(-orderIdempotencyKeyTTL))
+	if err != nil {
+		if isOrderConflict(err) {
+			return nil, ErrIdempotencyKeyInUse
+		}
+		return nil, fmt.Errorf("failed to claim idempotency key: %w", err)
+	}
+	if claimed {
+		return nil, nil
+	}
+
+	// 2. key 已被使用，只有內容相同的請求可以取回原本的結果
+	existing, err := s.order.GetOrderIdempotencyKey(ctx, tx, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+	if existing.RequestHash != requestHash {
+		return nil, ErrIdempotencyKeyReused
+	}
+	if existing.OrderID == nil {
+		return nil, ErrIdempotencyKeyInUse
+	}
+
+	// 3. 取回第一次建立的訂單
+	orderModel, err := s.order.GetOrder(ctx, tx, *existing.OrderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get idempotent order: %w", err)
+	}
+	if orderModel.Items, err = s.order.ListOrderItems(ctx, tx, orderModel.ID); err != nil {
+		return nil, fmt.Errorf("failed to list idempotent order items: %w", err)
+	}
+
+	return orderModel, nil
+}
@@ -0,0 +1,268 @@
+package shop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+
+	"gofalre.io/shop/models"
+	"gofalre.io/shop/models/enum"
+	"gofalre.io/shop/order"
+	"gofalre.io/shop/stock"
+)
+
+// orderImportChunkSize 為匯入外部訂單時每個交易處理的訂單數
+const orderImportChunkSize = 50
+
+// errExternalOrderDuplicate 表示相同通路與外部編號的訂單已存在
+var errExternalOrderDuplicate = errors.New("external order already imported")
+
+// ImportOrders 匯入已在外部通路（例如 Amazon、eBay）付款的訂單，每筆訂單必須有 ExternalSource 與 ExternalOrderID。
+// 訂單以每 50 筆為一個交易建立並扣除庫存，每筆訂單在各自的 savepoint 中執行，單筆失敗不影響同一批的其他訂單；
+// 已匯入過的訂單不會重複建立，結果會帶回先前建立的訂單。
+// 匯入的訂單狀態為 paid，金額與稅額以外部通路為準，不經過 Stripe、不開立發票也不執行狀態機的 hook；
+// 回傳的結果與 orders 的順序相同，只有 ctx 取消時才會回傳錯誤，此時尚未處理的訂單標記為失敗
+func (s *service) ImportOrders(ctx context.Context, orders []*models.Order) ([]*models.OrderImportResult, error) {
+	results := make([]*models.OrderImportResult, len(orders))
+	for i, orderModel := range orders {
+		results[i] = &models.OrderImportResult{Index: i, Status: enum.OrderImportStatusFailed}
+		if orderModel != nil {
+			results[i].ExternalSource = orderModel.ExternalSource
+			results[i].ExternalOrderID = orderModel.ExternalOrderID
+		}
+	}
+
+	for start := 0; start < len(orders); start += orderImportChunkSize {
+		end := min(start+orderImportChunkSize, len(orders))
+		chunk := results[start:end]
+
+		if err := ctx.Err(); err != nil {
+			for _, result := range results[start:] {
+				result.Error = err.Error()
+			}
+			return results, err
+		}
+
+		if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+			for i := start; i < end; i++ {
+				if err := s.importOrder(ctx, tx, orders[i], results[i]); err != nil {
+					return err
+				}
+			}
+			return nil
+		}); err != nil {
+			// 交易沒有提交，這一批新建立的訂單都已回復
+			for _, result := range chunk {
+				if result.Status == enum.OrderImportStatusCreated || (result.Status == enum.OrderImportStatusFailed && result.Error == "") {
+					result.Status = enum.OrderImportStatusFailed
+					result.OrderID, result.OrderNumber = 0, ""
+					result.Error = err.Error()
+				}
+			}
+			s.log(ctx).Error("Failed to import order chunk", zap.Int("from", start), zap.Int("to", end), zap.Error(err))
+		}
+	}
+
+	var created, duplicates, failed int
+	for _, result := range results {
+		switch result.Status {
+		case enum.OrderImportStatusCreated:
+			created++
+		case enum.OrderImportStatusDuplicate:
+			duplicates++
+		default:
+			failed++
+		}
+	}
+	s.log(ctx).Info("Imported external orders",
+		zap.Int("orders", len(orders)), zap.Int("created", created), zap.Int("duplicates", duplicates), zap.Int("failed", failed))
+
+	return results, nil
+}
+
+// importOrder 在 savepoint 中匯入單筆訂單並寫入結果，訂單本身的錯誤只記錄在結果中；
+// 回傳錯誤表示外層交易已無法繼續使用
+func (s *service) importOrder(ctx context.Context, tx pgx.Tx, orderModel *models.Order, result *models.OrderImportResult) error {
+	if orderModel == nil {
+		result.Error = "order is nil"
+		return nil
+	}
+
+	savepoint, err := tx.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create savepoint: %w", err)
+	}
+
+	imported, err := s.importOrderTx(ctx, savepoint, orderModel)
+	if err != nil {
+		if rollbackErr := savepoint.Rollback(ctx); rollbackErr != nil {
+			return fmt.Errorf("failed to roll back savepoint: %w", rollbackErr)
+		}
+		if errors.Is(err, errExternalOrderDuplicate) {
+			result.Status = enum.OrderImportStatusDuplicate
+			if imported != nil {
+				result.OrderID, result.OrderNumber = imported.ID, imported.OrderNumber
+			}
+			return nil
+		}
+		result.Error = err.Error()
+		s.log(ctx).Warn("Failed to import external order",
+			zap.String("external_source", orderModel.ExternalSource), zap.String("external_order_id", orderModel.ExternalOrderID), zap.Error(err))
+		return nil
+	}
+
+	if err = savepoint.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to release savepoint: %w", err)
+	}
+
+	result.Status = enum.OrderImportStatusCreated
+	result.OrderID, result.OrderNumber = imported.ID, imported.OrderNumber
+	return nil
+}
+
+// importOrderTx 建立匯入的訂單、訂單項目與庫存變動，不修改傳入的訂單；
+// 訂單已匯入過時回傳既有的訂單與 errExternalOrderDuplicate
+func (s *service) importOrderTx(ctx context.Context, tx pgx.Tx, source *models.Order) (*models.Order, error) {
+	// 1. 驗證訂單數據
+	if source.ExternalSource == "" || source.ExternalOrderID == "" {
+		return nil, errors.New("external source and external order ID are required")
+	}
+	if err := source.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid order data: %w", err)
+	}
+
+	// 2. 檢查是否已匯入過
+	existing, err := s.order.GetOrderByExternalID(ctx, tx, source.ExternalSource, source.ExternalOrderID)
+	if err == nil {
+		return existing, errExternalOrderDuplicate
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get order by external id: %w", err)
+	}
+
+	// 3. 創建已付款的訂單並記錄外部編號，唯一索引避免同時匯入的重複訂單
+	orderModel := &models.Order{
+		OrderNumber:     source.OrderNumber,
+		CustomerID:      source.CustomerID,
+		Status:          enum.OrderStatusPaid,
+		Currency:        source.Currency,
+		Subtotal:        source.Subtotal,
+		Tax:             source.Tax,
+		Discount:        source.Discount,
+		Total:           source.Total,
+		ShippingAddress: source.ShippingAddress,
+		BillingAddress:  source.BillingAddress,
+		ExternalSource:  source.ExternalSource,
+		ExternalOrderID: source.ExternalOrderID,
+	}
+	if _, err = s.createOrder(ctx, tx, orderModel); err != nil {
+		return nil, fmt.Errorf("failed to create order: %w", err)
+	}
+	if err = s.order.SetOrderExternalID(ctx, tx, orderModel.ID, orderModel.ExternalSource, orderModel.ExternalOrderID); err != nil {
+		if errors.Is(err, order.ErrExternalOrderExists) {
+			return nil, errExternalOrderDuplicate
+		}
+		return nil, fmt.Errorf("failed to set external order id: %w", err)
+	}
+	if len(source.Metadata) > 0 {
+		if err = s.order.MergeOrderMetadata(ctx, tx, orderModel.ID, source.Metadata); err != nil {
+			return nil, fmt.Errorf("failed to set order metadata: %w", err)
+		}
+		orderModel.Metadata = source.Metadata
+	}
+
+	// 4. 準備訂單項目並扣除庫存，外部通路的訂單沒有預留，只能扣除未預留的數量
+	orderItems := make([]*models.OrderItem, len(source.Items))
+	stockMoveParams := make([]stock.CreateStockMovementParams, len(source.Items))
+
+	for i, item := range source.Items {
+		orderItems[i] = &models.OrderItem{
+			OrderID:     orderModel.ID,
+			ProductID:   item.ProductID,
+			PriceID:     item.PriceID,
+			Quantity:    item.Quantity,
+			UnitPrice:   item.UnitPrice,
+			Subtotal:    item.Subtotal,
+			ProductName: item.ProductName,
+			SKU:         item.SKU,
+			ImageURL:    item.ImageURL,
+			TaxClass:    item.TaxClass,
+			TaxRate:     item.TaxRate,
+			TaxAmount:   item.TaxAmount,
+		}
+		s.snapshotOrderItem(ctx, orderItems[i])
+
+		orderItems[i].Customization, err = s.validateCustomization(ctx, item.ProductID, item.Customization)
+		if err != nil {
+			return nil, fmt.Errorf("failed to validate customization for item %s: %w", item.ProductID, err)
+		}
+
+		stockModel, err := s.resolveItemStock(ctx, tx, &models.CartItem{
+			ProductID: item.ProductID,
+			StockID:   item.StockID,
+			Location:  item.Location,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get stock for item %s: %w", item.ProductID, err)
+		}
+		if stockModel.RentalEnabled {
+			return nil, fmt.Errorf("stock %d is reserved for rentals and cannot fulfil item %s", stockModel.ID, item.ProductID)
+		}
+		orderItems[i].StockID, orderItems[i].Location = stockModel.ID, stockModel.Location
+
+		// 鎖定後重新讀取，同一訂單中多個項目使用相同庫存時取得前一個項目扣除後的數量
+		stockModel, err = s.stock.LockStock(ctx, tx, stockModel.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to lock stock: %w", err)
+		}
+		ok, err := s.stock.UpdateStockQuantity(ctx, tx, stock.UpdateStockQuantityParams{
+			StockID:     stockModel.ID,
+			Delta:       -int64(item.Quantity),
+			LastUpdated: stockModel.UpdatedAt,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to update stock quantity: %w", err)
+		}
+		if !ok {
+			return nil, fmt.Errorf("insufficient stock for product %s: available %d, required %d",
+				item.ProductID, availableQuantity(stockModel), item.Quantity)
+		}
+
+		stockMoveParams[i] = stock.CreateStockMovementParams{
+			StockID:       stockModel.ID,
+			Quantity:      item.Quantity,
+			Type:          enum.StockMovementTypeOut,
+			ReferenceID:   orderModel.ID,
+			ReferenceType: enum.StockMovementReferenceTypeOrder,
+			Note:          "imported from " + orderModel.ExternalSource,
+		}
+	}
+
+	// 5. 批量創建訂單項目與庫存變動記錄，庫存變動記錄對應到新建立的項目
+	if err = s.order.AddOrderItems(ctx, tx, orderItems); err != nil {
+		return nil, fmt.Errorf("failed to add order items: %w", err)
+	}
+	for i, orderItem := range orderItems {
+		stockMoveParams[i].ReferenceItemID = orderItem.ID
+	}
+	if err = s.stock.CreateStockMovements(ctx, tx, stockMoveParams); err != nil {
+		return nil, fmt.Errorf("failed to create stock movements: %w", err)
+	}
+
+	// 6. 記錄換算成報表幣別的匯率快照
+	if err = s.recordReportingSnapshot(ctx, tx, orderModel); err != nil {
+		return nil, err
+	}
+
+	// 7. 匯入的訂單已付款，開始計算履約時限
+	if err = s.startFulfillmentSLA(ctx, tx, orderModel, time.Now()); err != nil {
+		return nil, err
+	}
+
+	orderModel.Items = orderItems
+	return orderModel, nil
+}
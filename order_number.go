@@ -0,0 +1,154 @@
+package shop
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"gofalre.io/shop/models"
+	"gofalre.io/shop/order"
+)
+
+const (
+	// defaultOrderNumberDigits 為預設訂單編號的隨機位數（不含檢查碼）
+	defaultOrderNumberDigits = 10
+	// maxOrderNumberAttempts 為訂單編號重複時最多嘗試產生的次數
+	maxOrderNumberAttempts = 5
+)
+
+// OrderNumberGenerator 產生對外顯示的訂單編號，編號寫入時若已被使用會再呼叫一次產生新的編號
+type OrderNumberGenerator interface {
+	NewOrderNumber(ctx context.Context, order *models.Order) (string, error)
+}
+
+// orderNumberFormat 以「前綴 + 日期（選用）+ 隨機數字 + Luhn 檢查碼」組成訂單編號
+type orderNumberFormat struct {
+	prefix     string
+	dateLayout string
+	digits     int
+}
+
+// RandomOrderNumbers 產生 digits 位隨機數字加一位 Luhn 檢查碼的訂單編號，例如 48213907561
+func RandomOrderNumbers(digits int) OrderNumberGenerator {
+	return orderNumberFormat{digits: digits}
+}
+
+// PrefixedOrderNumbers 在隨機訂單編號前加上固定前綴，例如 SHOP-48213907561
+func PrefixedOrderNumbers(prefix string, digits int) OrderNumberGenerator {
+	return orderNumberFormat{prefix: prefix, digits: digits}
+}
+
+// DateOrderNumbers 以下單日期（UTC）開頭，後接隨機數字與檢查碼，例如 SHOP-20261018-4821397
+func DateOrderNumbers(prefix string, digits int) OrderNumberGenerator {
+	return orderNumberFormat{prefix: prefix, dateLayout: "20060102", digits: digits}
+}
+
+func (f orderNumberFormat) NewOrderNumber(context.Context, *models.Order) (string, error) {
+	digits := f.digits
+	if digits <= 0 {
+		digits = defaultOrderNumberDigits
+	}
+
+	var b strings.Builder
+	b.WriteString(f.prefix)
+	if f.dateLayout != "" {
+		b.WriteString(time.Now().UTC().Format(f.dateLayout))
+		b.WriteByte('-')
+	}
+
+	number := make([]byte, digits, digits+1)
+	for i := range number {
+		n, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", fmt.Errorf("failed to generate order number: %w", err)
+		}
+		number[i] = byte('0' + n.Int64())
+	}
+	b.Write(append(number, luhnCheckDigit(number)))
+
+	return b.String(), nil
+}
+
+// luhnCheckDigit 計算數字字串的 Luhn 檢查碼，可偵測單一數字打錯或相鄰數字對調
+func luhnCheckDigit(number []byte) byte {
+	sum := 0
+	for i := len(number) - 1; i >= 0; i-- {
+		d := int(number[i] - '0')
+		// 由右往左數，加上檢查碼後位於偶數位的數字要加倍
+		if (len(number)-i)%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+// WithOrderNumberGenerator 設定訂單編號的產生方式，預設為 10 位隨機數字加檢查碼
+func WithOrderNumberGenerator(generator OrderNumberGenerator) Option {
+	return func(s *service) {
+		if generator != nil {
+			s.orderNumbers = generator
+		}
+	}
+}
+
+// createOrder 在呼叫端的交易內建立訂單並指派訂單編號；訂單已帶編號時直接使用，不會重試
+func (s *service) createOrder(ctx context.Context, tx pgx.Tx, orderModel *models.Order) (*models.Order, error) {
+	if orderModel.OrderNumber != "" {
+		return s.order.CreateOrder(ctx, tx, orderModel)
+	}
+
+	for attempt := 0; attempt < maxOrderNumberAttempts; attempt++ {
+		number, err := s.orderNumbers.NewOrderNumber(ctx, orderModel)
+		if err != nil {
+			return nil, err
+		}
+
+		orderModel.OrderNumber = number
+		created, err := s.order.CreateOrder(ctx, tx, orderModel)
+		if errors.Is(err, order.ErrOrderNumberTaken) {
+			continue
+		}
+		if err != nil {
+			orderModel.OrderNumber = ""
+			return nil, err
+		}
+		return created, nil
+	}
+
+	orderModel.OrderNumber = ""
+	return nil, fmt.Errorf("failed to assign order number after %d attempts: %w", maxOrderNumberAttempts, order.ErrOrderNumberTaken)
+}
+
+// GetOrderByNumber 以對外顯示的訂單編號查詢訂單及其項目
+func (s *service) GetOrderByNumber(ctx context.Context, orderNumber string) (*models.Order, error) {
+	var orderModel *models.Order
+
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		var err error
+		orderModel, err = s.order.GetOrderByNumber(ctx, tx, orderNumber)
+		if err != nil {
+			return fmt.Errorf("failed to get order by number: %w", err)
+		}
+
+		orderModel.Items, err = s.order.ListOrderItems(ctx, tx, orderModel.ID)
+		if err != nil {
+			return fmt.Errorf("failed to list order items: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return orderModel, nil
+}
@@ -0,0 +1,217 @@
+package shop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+
+	"gofalre.io/shop/models"
+	"gofalre.io/shop/models/enum"
+)
+
+// bulkCancellationChunkSize 為批次取消訂單時每個交易處理的訂單數
+const bulkCancellationChunkSize = 50
+
+// bulkCancellableStatuses 為可以批次取消的訂單狀態：pending 直接取消，其餘為已付款但尚未出貨的訂單，以全額退款取消
+var bulkCancellableStatuses = map[enum.OrderStatus]bool{
+	enum.OrderStatusPending:        true,
+	enum.OrderStatusPaid:           true,
+	enum.OrderStatusReadyForPickup: true,
+}
+
+// defaultBulkCancellationStatuses 為未指定 statusFilter 時處理的訂單狀態
+var defaultBulkCancellationStatuses = []enum.OrderStatus{
+	enum.OrderStatusPending,
+	enum.OrderStatusPaid,
+	enum.OrderStatusReadyForPickup,
+}
+
+// CancelOrdersContainingProduct 取消所有含有 productID 且尚未出貨的訂單，用於商品召回或上架錯誤。
+// statusFilter 限定處理的訂單狀態，只能包含 pending、paid 與 ready_for_pickup，為空時處理全部三種狀態。
+// 未付款的訂單以每 50 筆為一個交易取消、恢復庫存並取消 payment intent，每筆訂單在各自的 savepoint 中執行；
+// 已付款的訂單在該批交易提交後依退款政策全額退款，退款完成時由 Stripe 事件恢復庫存。
+// 單筆訂單失敗只記錄在報告中，只有查詢訂單失敗或 ctx 取消時才會回傳錯誤
+func (s *service) CancelOrdersContainingProduct(ctx context.Context, productID, reason string, statusFilter []enum.OrderStatus) (*models.BulkCancellationReport, error) {
+	if productID == "" {
+		return nil, errors.New("product ID is required")
+	}
+	if reason == "" {
+		return nil, errors.New("cancellation reason is required")
+	}
+	statuses := statusFilter
+	if len(statuses) == 0 {
+		statuses = defaultBulkCancellationStatuses
+	}
+	filter := make(map[enum.OrderStatus]bool, len(statuses))
+	for _, status := range statuses {
+		if !bulkCancellableStatuses[status] {
+			return nil, fmt.Errorf("orders in status %s cannot be bulk cancelled", status)
+		}
+		filter[status] = true
+	}
+
+	if err := s.checkAuthorization(ctx, AuthorizationRequest{Action: ActionCancelOrdersForRecall}); err != nil {
+		return nil, err
+	}
+
+	// 1. 找出受影響的訂單
+	var orderIDs []uint64
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		var err error
+		if orderIDs, err = s.order.ListUnshippedOrderIDsByProduct(ctx, tx, productID, statuses); err != nil {
+			return fmt.Errorf("failed to list orders containing product: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	report := &models.BulkCancellationReport{
+		ProductID: productID,
+		Reason:    reason,
+		Matched:   len(orderIDs),
+		Results:   make([]*models.BulkCancellationResult, len(orderIDs)),
+	}
+	for i, orderID := range orderIDs {
+		report.Results[i] = &models.BulkCancellationResult{OrderID: orderID, Outcome: enum.BulkCancellationOutcomeFailed}
+	}
+
+	// 2. 分批取消，已付款的訂單在該批交易提交後退款
+	var ctxErr error
+	for start := 0; start < len(orderIDs); start += bulkCancellationChunkSize {
+		end := min(start+bulkCancellationChunkSize, len(orderIDs))
+		chunk := report.Results[start:end]
+
+		if ctxErr = ctx.Err(); ctxErr != nil {
+			for _, result := range report.Results[start:] {
+				result.Error = ctxErr.Error()
+			}
+			break
+		}
+
+		var refunds []*models.BulkCancellationResult
+		if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+			refunds = refunds[:0]
+			for _, result := range chunk {
+				refund, err := s.cancelOrderForRecall(ctx, tx, productID, filter, result)
+				if err != nil {
+					return err
+				}
+				if refund {
+					refunds = append(refunds, result)
+				}
+			}
+			return nil
+		}); err != nil {
+			// 交易沒有提交，這一批取消的訂單都已回復
+			for _, result := range chunk {
+				if result.Outcome == enum.BulkCancellationOutcomeCancelled || (result.Outcome == enum.BulkCancellationOutcomeFailed && result.Error == "") {
+					result.Outcome = enum.BulkCancellationOutcomeFailed
+					result.Error = err.Error()
+				}
+			}
+			s.log(ctx).Error("Failed to cancel order chunk", zap.String("product_id", productID), zap.Int("from", start), zap.Int("to", end), zap.Error(err))
+			continue
+		}
+
+		for _, result := range refunds {
+			if _, err := s.RefundOrder(ctx, result.OrderID, nil, "product recall: "+reason); err != nil {
+				result.Error = err.Error()
+				s.log(ctx).Warn("Failed to refund recalled order", zap.Uint64("order_id", result.OrderID), zap.Error(err))
+				continue
+			}
+			result.Outcome = enum.BulkCancellationOutcomeRefundRequested
+		}
+	}
+
+	// 3. 彙整結果
+	for _, result := range report.Results {
+		switch result.Outcome {
+		case enum.BulkCancellationOutcomeCancelled:
+			report.Cancelled++
+		case enum.BulkCancellationOutcomeRefundRequested:
+			report.RefundRequested++
+		case enum.BulkCancellationOutcomeSkipped:
+			report.Skipped++
+		default:
+			report.Failed++
+		}
+	}
+	s.log(ctx).Info("Cancelled orders containing product",
+		zap.String("product_id", productID), zap.String("reason", reason), zap.Int("matched", report.Matched),
+		zap.Int("cancelled", report.Cancelled), zap.Int("refund_requested", report.RefundRequested),
+		zap.Int("skipped", report.Skipped), zap.Int("failed", report.Failed))
+
+	return report, ctxErr
+}
+
+// cancelOrderForRecall 在 savepoint 中重新檢查訂單並取消未付款的訂單，訂單本身的錯誤只記錄在 result 中；
+// 回傳 true 表示訂單已付款，須在交易提交後退款；回傳錯誤表示外層交易已無法繼續使用
+func (s *service) cancelOrderForRecall(ctx context.Context, tx pgx.Tx, productID string, filter map[enum.OrderStatus]bool, result *models.BulkCancellationResult) (bool, error) {
+	savepoint, err := tx.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to create savepoint: %w", err)
+	}
+
+	refund, err := s.cancelOrderForRecallTx(ctx, savepoint, filter, result)
+	if err != nil {
+		if rollbackErr := savepoint.Rollback(ctx); rollbackErr != nil {
+			return false, fmt.Errorf("failed to roll back savepoint: %w", rollbackErr)
+		}
+		result.Outcome = enum.BulkCancellationOutcomeFailed
+		result.Error = err.Error()
+		s.log(ctx).Warn("Failed to cancel recalled order",
+			zap.String("product_id", productID), zap.Uint64("order_id", result.OrderID), zap.Error(err))
+		return false, nil
+	}
+
+	if err = savepoint.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to release savepoint: %w", err)
+	}
+	return refund, nil
+}
+
+// cancelOrderForRecallTx 鎖定訂單，訂單狀態已不符或已出貨時標記為略過；未付款的訂單直接取消，已付款的訂單回傳 true
+func (s *service) cancelOrderForRecallTx(ctx context.Context, tx pgx.Tx, filter map[enum.OrderStatus]bool, result *models.BulkCancellationResult) (bool, error) {
+	// 1. 鎖定訂單並重新檢查狀態，列出訂單後可能已付款或出貨
+	orderModel, err := s.order.GetOrderForUpdate(ctx, tx, result.OrderID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get order: %w", err)
+	}
+	result.OrderNumber, result.Status = orderModel.OrderNumber, orderModel.Status
+
+	if !filter[orderModel.Status] {
+		result.Outcome = enum.BulkCancellationOutcomeSkipped
+		return false, nil
+	}
+	shipments, err := s.order.ListShipments(ctx, tx, orderModel.ID)
+	if err != nil {
+		return false, fmt.Errorf("failed to list shipments: %w", err)
+	}
+	if len(shipments) > 0 {
+		result.Outcome = enum.BulkCancellationOutcomeSkipped
+		return false, nil
+	}
+
+	// 2. 已付款的訂單在交易提交後退款
+	if orderModel.Status != enum.OrderStatusPending {
+		return true, nil
+	}
+
+	// 3. 取消未付款的訂單、恢復庫存並取消 payment intent
+	if !s.statusMachine.CanTransition(orderModel.Status, enum.OrderStatusCancelled) {
+		return false, fmt.Errorf("invalid status transition from %s to %s", orderModel.Status, enum.OrderStatusCancelled)
+	}
+	if orderModel.PaymentIntentID != "" && s.paymentCanceller == nil {
+		return false, fmt.Errorf("order %d has a payment intent but no payment canceller is configured", orderModel.ID)
+	}
+	if err = s.cancelUnpaidOrder(ctx, tx, orderModel); err != nil {
+		return false, err
+	}
+
+	result.Outcome = enum.BulkCancellationOutcomeCancelled
+	return false, nil
+}
@@ -0,0 +1,100 @@
+package shop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+
+	"gofalre.io/shop/models"
+)
+
+// ErrReceiptLinksNotConfigured 表示未設定 ReceiptLinkProvider，無法向金流服務取得收據網址
+var ErrReceiptLinksNotConfigured = errors.New("receipt link provider is not configured")
+
+// ErrOrderHasNoPayment 表示訂單沒有 payment intent 也沒有 invoice，沒有可產生的收據
+var ErrOrderHasNoPayment = errors.New("order has no payment")
+
+// ReceiptLinkProvider 向金流服務取得訂單付款的收據、發票與客戶帳單入口網址
+type ReceiptLinkProvider interface {
+	// ReceiptLinks 回傳 ref 中 payment intent 的收據網址與 invoice 的發票網址，沒有的項目留空
+	ReceiptLinks(ctx context.Context, ref *models.OrderPaymentReference) (*models.OrderReceiptLinks, error)
+	// BillingPortalURL 為客戶建立帳單入口的連結，客戶可在入口中檢視所有收據與發票，returnURL 為離開入口時返回的網址
+	BillingPortalURL(ctx context.Context, customerID, returnURL string) (string, error)
+}
+
+// WithReceiptLinkProvider 設定取得訂單收據與帳單入口網址使用的金流服務
+func WithReceiptLinkProvider(provider ReceiptLinkProvider) Option {
+	return func(s *service) {
+		s.receiptLinks = provider
+	}
+}
+
+// GetOrderReceiptLinks 取得訂單付款的收據與發票網址：已儲存且對應訂單目前的付款時直接回傳，
+// 否則向金流服務取得後儲存；refresh 為 true 時一律重新取得。付款尚未完成時收據網址為空，之後再查詢會重新取得
+func (s *service) GetOrderReceiptLinks(ctx context.Context, orderID uint64, refresh bool) (*models.OrderReceiptLinks, error) {
+	// 1. 取得訂單的付款
+	ref, err := s.order.GetOrderPaymentReference(ctx, nil, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order payment reference: %w", err)
+	}
+	if ref.PaymentIntentID == "" && ref.InvoiceID == "" {
+		return nil, fmt.Errorf("%w: order %d", ErrOrderHasNoPayment, orderID)
+	}
+
+	// 2. 已儲存的連結仍適用時直接回傳
+	if !refresh {
+		stored, err := s.order.GetOrderReceiptLinks(ctx, nil, orderID)
+		switch {
+		case err == nil && stored.Covers(ref):
+			return stored, nil
+		case err != nil && !errors.Is(err, pgx.ErrNoRows):
+			return nil, fmt.Errorf("failed to get order receipt links: %w", err)
+		}
+	}
+
+	// 3. 向金流服務取得連結後儲存
+	if s.receiptLinks == nil {
+		return nil, ErrReceiptLinksNotConfigured
+	}
+	links, err := s.receiptLinks.ReceiptLinks(ctx, ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get receipt links: %w", err)
+	}
+	links.OrderID = orderID
+	links.PaymentIntentID = ref.PaymentIntentID
+	links.InvoiceID = ref.InvoiceID
+
+	if err = s.order.SetOrderReceiptLinks(ctx, nil, links); err != nil {
+		return nil, fmt.Errorf("failed to set order receipt links: %w", err)
+	}
+
+	s.log(ctx).Info("Stored order receipt links",
+		zap.Uint64("order_id", orderID), zap.Bool("has_receipt", links.ReceiptURL != ""), zap.Bool("has_invoice", links.InvoiceURL != ""))
+
+	return links, nil
+}
+
+// CreateBillingPortalLink 為訂單的客戶建立金流服務的帳單入口連結，讓客戶自行下載收據與發票；
+// 入口連結短時間內有效，每次需要時重新建立，不會儲存
+func (s *service) CreateBillingPortalLink(ctx context.Context, orderID uint64, returnURL string) (string, error) {
+	if s.receiptLinks == nil {
+		return "", ErrReceiptLinksNotConfigured
+	}
+
+	ref, err := s.order.GetOrderPaymentReference(ctx, nil, orderID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get order payment reference: %w", err)
+	}
+	if ref.CustomerID == "" {
+		return "", fmt.Errorf("order %d has no customer", orderID)
+	}
+
+	url, err := s.receiptLinks.BillingPortalURL(ctx, ref.CustomerID, returnURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to create billing portal link: %w", err)
+	}
+	return url, nil
+}
@@ -0,0 +1,378 @@
+package shop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+
+	"gofalre.io/shop/models"
+	"gofalre.io/shop/models/enum"
+)
+
+// returnTrackingBatchSize 為 PollReturnTracking 每次讀取的退貨數
+const returnTrackingBatchSize = 100
+
+// ErrReturnNotFound 表示退貨申請不存在
+var ErrReturnNotFound = errors.New("return not found")
+
+// ErrReturnCarrierNotConfigured 表示未設定 WithReturnCarrier，無法取得退貨標籤或追蹤物流
+var ErrReturnCarrierNotConfigured = errors.New("return carrier is not configured")
+
+// ReturnCarrier 向物流商申請退貨標籤並查詢追蹤狀態；TrackReturn 供沒有推播事件的物流商輪詢，
+// 尚無新的狀態時回傳 nil
+type ReturnCarrier interface {
+	CreateReturnLabel(ctx context.Context, request *models.ReturnLabelRequest) (*models.ReturnLabel, error)
+	TrackReturn(ctx context.Context, carrier, trackingNumber string) (*models.ReturnTrackingEvent, error)
+}
+
+// WithReturnCarrier 設定退貨標籤使用的物流商，設定後核准退貨時會自動申請標籤，
+// 並訂閱 SubjectReturnTracking 接收物流商的追蹤事件
+func WithReturnCarrier(carrier ReturnCarrier) Option {
+	return func(s *service) {
+		s.returnCarrier = carrier
+	}
+}
+
+// returnableOrderStatuses 為可以申請退貨的訂單狀態
+var returnableOrderStatuses = map[enum.OrderStatus]bool{
+	enum.OrderStatusPaid:              true,
+	enum.OrderStatusCompleted:         true,
+	enum.OrderStatusPartiallyRefunded: true,
+}
+
+// returnTrackingProgress 為退貨在物流追蹤中的先後順序，追蹤事件只會讓退貨往後推進
+var returnTrackingProgress = map[enum.ReturnStatus]int{
+	enum.ReturnStatusLabelIssued: 1,
+	enum.ReturnStatusInTransit:   2,
+	enum.ReturnStatusReceived:    3,
+}
+
+// RequestReturn 為訂單建立退貨申請，每筆訂單同時只能有一筆處理中的退貨
+func (s *service) RequestReturn(ctx context.Context, orderID uint64, reason string) (*models.OrderReturn, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, errors.New("return reason is required")
+	}
+
+	var orderReturn *models.OrderReturn
+
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		// 1. 確認訂單可以退貨
+		orderModel, err := s.order.GetOrder(ctx, tx, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to get order: %w", err)
+		}
+		if !returnableOrderStatuses[orderModel.Status] {
+			return fmt.Errorf("order %d is %s and cannot be returned", orderID, orderModel.Status)
+		}
+
+		// 2. 建立退貨申請
+		orderReturn, err = s.order.CreateOrderReturn(ctx, tx, orderID, reason)
+		if err != nil {
+			return fmt.Errorf("failed to create order return: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	s.log(ctx).Info("Return requested", zap.Uint64("order_id", orderID), zap.Uint64("return_id", orderReturn.ID))
+	return orderReturn, nil
+}
+
+// ApproveReturn 核准退貨申請，設定 WithReturnCarrier 時於交易完成後申請退貨標籤；
+// 標籤申請失敗只記錄警告，退貨維持 approved，可透過 IssueReturnLabel 重試
+func (s *service) ApproveReturn(ctx context.Context, returnID uint64) (*models.OrderReturn, error) {
+	orderReturn, err := s.setReturnStatus(ctx, returnID, enum.ReturnStatusRequested, enum.ReturnStatusApproved)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.returnCarrier == nil {
+		return orderReturn, nil
+	}
+	issued, err := s.issueReturnLabel(ctx, orderReturn)
+	if err != nil {
+		s.log(ctx).Warn("Failed to issue return label", zap.Uint64("return_id", returnID), zap.Error(err))
+		return orderReturn, nil
+	}
+
+	return issued, nil
+}
+
+// RejectReturn 拒絕尚未核准的退貨申請
+func (s *service) RejectReturn(ctx context.Context, returnID uint64) (*models.OrderReturn, error) {
+	return s.setReturnStatus(ctx, returnID, enum.ReturnStatusRequested, enum.ReturnStatusRejected)
+}
+
+// IssueReturnLabel 為已核准但尚未取得標籤的退貨申請標籤，用於標籤申請失敗後重試
+func (s *service) IssueReturnLabel(ctx context.Context, returnID uint64) (*models.OrderReturn, error) {
+	if s.returnCarrier == nil {
+		return nil, ErrReturnCarrierNotConfigured
+	}
+
+	orderReturn, err := s.getOrderReturn(ctx, returnID)
+	if err != nil {
+		return nil, err
+	}
+	if orderReturn.Status != enum.ReturnStatusApproved {
+		return nil, fmt.Errorf("return %d is %s and cannot be issued a label", returnID, orderReturn.Status)
+	}
+
+	return s.issueReturnLabel(ctx, orderReturn)
+}
+
+// ListOrderReturns 依申請順序列出訂單的退貨
+func (s *service) ListOrderReturns(ctx context.Context, orderID uint64) ([]*models.OrderReturn, error) {
+	var returns []*models.OrderReturn
+
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		var err error
+		if returns, err = s.order.ListOrderReturns(ctx, tx, orderID); err != nil {
+			return fmt.Errorf("failed to list order returns: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return returns, nil
+}
+
+// HandleReturnTrackingEvent 依物流商的追蹤事件更新退貨狀態，重複或較舊的事件會被略過；
+// 訂閱 SubjectReturnTracking 時會自動呼叫，也可由物流商的 webhook 直接呼叫
+func (s *service) HandleReturnTrackingEvent(ctx context.Context, event *models.ReturnTrackingEvent) error {
+	_, err := s.applyReturnTracking(ctx, event)
+	return err
+}
+
+// PollReturnTracking 向物流商查詢所有運送中退貨的追蹤狀態，供排程定期呼叫，回傳狀態有更新的退貨數；
+// 單筆查詢失敗只記錄警告
+func (s *service) PollReturnTracking(ctx context.Context) (int, error) {
+	if s.returnCarrier == nil {
+		return 0, ErrReturnCarrierNotConfigured
+	}
+
+	var updated int
+	var afterID uint64
+	for {
+		var returns []*models.OrderReturn
+		if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+			var err error
+			if returns, err = s.order.ListTrackedOrderReturns(ctx, tx, afterID, returnTrackingBatchSize); err != nil {
+				return fmt.Errorf("failed to list tracked order returns: %w", err)
+			}
+			return nil
+		}); err != nil {
+			return updated, err
+		}
+
+		for _, orderReturn := range returns {
+			if err := ctx.Err(); err != nil {
+				return updated, err
+			}
+
+			event, err := s.returnCarrier.TrackReturn(ctx, orderReturn.Carrier, orderReturn.TrackingNumber)
+			if err != nil {
+				s.log(ctx).Warn("Failed to track return",
+					zap.Uint64("return_id", orderReturn.ID), zap.String("tracking_number", orderReturn.TrackingNumber), zap.Error(err))
+				continue
+			}
+			if event == nil {
+				continue
+			}
+
+			changed, err := s.applyReturnTracking(ctx, event)
+			if err != nil {
+				s.log(ctx).Warn("Failed to apply return tracking",
+					zap.Uint64("return_id", orderReturn.ID), zap.String("tracking_number", orderReturn.TrackingNumber), zap.Error(err))
+				continue
+			}
+			if changed {
+				updated++
+			}
+		}
+
+		if len(returns) < returnTrackingBatchSize {
+			return updated, nil
+		}
+		afterID = returns[len(returns)-1].ID
+	}
+}
+
+// setReturnStatus 在退貨為 from 狀態時更新為 to
+func (s *service) setReturnStatus(ctx context.Context, returnID uint64, from, to enum.ReturnStatus) (*models.OrderReturn, error) {
+	var orderReturn *models.OrderReturn
+
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		var err error
+		orderReturn, err = s.order.GetOrderReturnForUpdate(ctx, tx, returnID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return fmt.Errorf("%w: %d", ErrReturnNotFound, returnID)
+			}
+			return fmt.Errorf("failed to get order return: %w", err)
+		}
+		if orderReturn.Status != from {
+			return fmt.Errorf("return %d is %s and cannot be %s", returnID, orderReturn.Status, to)
+		}
+
+		if err = s.order.UpdateOrderReturnStatus(ctx, tx, returnID, to); err != nil {
+			return fmt.Errorf("failed to update order return status: %w", err)
+		}
+		orderReturn.Status = to
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	s.log(ctx).Info("Return status updated", zap.Uint64("return_id", returnID), zap.String("status", string(to)))
+	return orderReturn, nil
+}
+
+// getOrderReturn 取得退貨申請，不存在時回傳 ErrReturnNotFound
+func (s *service) getOrderReturn(ctx context.Context, returnID uint64) (*models.OrderReturn, error) {
+	var orderReturn *models.OrderReturn
+
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		var err error
+		orderReturn, err = s.order.GetOrderReturn(ctx, tx, returnID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return fmt.Errorf("%w: %d", ErrReturnNotFound, returnID)
+			}
+			return fmt.Errorf("failed to get order return: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return orderReturn, nil
+}
+
+// issueReturnLabel 向物流商申請退貨標籤並記錄，物流商的呼叫不在交易中進行；
+// 申請期間退貨已取得其他標籤時不覆蓋，回傳目前的退貨
+func (s *service) issueReturnLabel(ctx context.Context, orderReturn *models.OrderReturn) (*models.OrderReturn, error) {
+	// 1. 準備寄件地址與退回的項目
+	request := &models.ReturnLabelRequest{ReturnID: orderReturn.ID, OrderID: orderReturn.OrderID}
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		orderModel, err := s.order.GetOrder(ctx, tx, orderReturn.OrderID)
+		if err != nil {
+			return fmt.Errorf("failed to get order: %w", err)
+		}
+		items, err := s.order.ListOrderItems(ctx, tx, orderReturn.OrderID)
+		if err != nil {
+			return fmt.Errorf("failed to list order items: %w", err)
+		}
+		request.Items, err = s.shippingItems(ctx, tx, items)
+		if err != nil {
+			return err
+		}
+
+		request.OrderNumber = orderModel.OrderNumber
+		request.CustomerID = orderModel.CustomerID
+		request.ShipFrom = orderModel.ShippingAddress
+		if len(request.ShipFrom) == 0 {
+			request.ShipFrom = orderModel.BillingAddress
+		}
+		for _, item := range request.Items {
+			request.TotalWeightGrams += item.WeightGrams
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	// 2. 向物流商申請標籤
+	label, err := s.returnCarrier.CreateReturnLabel(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create return label: %w", err)
+	}
+	if label == nil || label.Carrier == "" || label.TrackingNumber == "" {
+		return nil, errors.New("return label has no carrier or tracking number")
+	}
+
+	// 3. 記錄標籤
+	if err = s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		ok, err := s.order.SetOrderReturnLabel(ctx, tx, orderReturn.ID, label)
+		if err != nil {
+			return fmt.Errorf("failed to set order return label: %w", err)
+		}
+		if !ok {
+			s.log(ctx).Warn("Return is no longer awaiting a label, discarding issued label",
+				zap.Uint64("return_id", orderReturn.ID), zap.String("carrier", label.Carrier), zap.String("tracking_number", label.TrackingNumber))
+		}
+
+		orderReturn, err = s.order.GetOrderReturn(ctx, tx, orderReturn.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get order return: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	s.log(ctx).Info("Return label issued",
+		zap.Uint64("return_id", orderReturn.ID), zap.String("carrier", orderReturn.Carrier), zap.String("tracking_number", orderReturn.TrackingNumber))
+	return orderReturn, nil
+}
+
+// applyReturnTracking 依追蹤事件推進退貨狀態，回傳 false 表示事件沒有造成變更
+func (s *service) applyReturnTracking(ctx context.Context, event *models.ReturnTrackingEvent) (bool, error) {
+	if event.Status != enum.ReturnStatusInTransit && event.Status != enum.ReturnStatusReceived {
+		return false, fmt.Errorf("unsupported return tracking status %q", event.Status)
+	}
+
+	var orderReturn *models.OrderReturn
+	var changed bool
+
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		// 1. 以追蹤編號找到並鎖定退貨
+		returnID, err := s.order.GetOrderReturnIDByTracking(ctx, tx, event.Carrier, event.TrackingNumber)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return fmt.Errorf("%w: %s %s", ErrReturnNotFound, event.Carrier, event.TrackingNumber)
+			}
+			return fmt.Errorf("failed to get order return by tracking number: %w", err)
+		}
+		orderReturn, err = s.order.GetOrderReturnForUpdate(ctx, tx, returnID)
+		if err != nil {
+			return fmt.Errorf("failed to get order return: %w", err)
+		}
+
+		// 2. 只往後推進，重複或亂序的事件不會讓狀態倒退
+		current, ok := returnTrackingProgress[orderReturn.Status]
+		if !ok || returnTrackingProgress[event.Status] <= current {
+			return nil
+		}
+
+		if err = s.order.UpdateOrderReturnStatus(ctx, tx, orderReturn.ID, event.Status); err != nil {
+			return fmt.Errorf("failed to update order return status: %w", err)
+		}
+		changed = true
+
+		// 3. 退貨送達倉庫時，訂單已出貨的項目都視為已退回
+		if event.Status == enum.ReturnStatusReceived {
+			if err = s.order.ReturnShippedOrderItems(ctx, tx, orderReturn.OrderID); err != nil {
+				return fmt.Errorf("failed to mark order items returned: %w", err)
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return false, err
+	}
+
+	if changed {
+		s.log(ctx).Info("Return tracking updated",
+			zap.Uint64("return_id", orderReturn.ID), zap.String("status", string(event.Status)), zap.Time("occurred_at", event.OccurredAt))
+	}
+	return changed, nil
+}
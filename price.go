@@ -0,0 +1,198 @@
+package shop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stripe/stripe-go/v79"
+	"go.uber.org/zap"
+
+	"gofalre.io/shop/models"
+	"gofalre.io/shop/price"
+)
+
+// SchedulePriceChange 排程一筆價格變動，effectiveAt 為零值時於下次排程執行時立即生效
+func (s *service) SchedulePriceChange(ctx context.Context, priceID, productID string, unitPrice float64, currency stripe.Currency, reason string, effectiveAt time.Time) (*models.PriceChange, error) {
+	if priceID == "" || productID == "" {
+		return nil, errors.New("price id and product id are required")
+	}
+	if currency == "" {
+		return nil, errors.New("currency is required")
+	}
+	if unitPrice < 0 {
+		return nil, errors.New("unit price must not be negative")
+	}
+	if effectiveAt.IsZero() {
+		effectiveAt = time.Now()
+	}
+
+	return s.price.CreatePriceChange(ctx, nil, price.CreatePriceChangeParams{
+		PriceID:     priceID,
+		ProductID:   productID,
+		UnitPrice:   unitPrice,
+		Currency:    currency,
+		Reason:      reason,
+		EffectiveAt: effectiveAt,
+	})
+}
+
+// CancelPriceChange 取消尚未生效的價格變動
+func (s *service) CancelPriceChange(ctx context.Context, priceChangeID uint64) error {
+	ok, err := s.price.CancelPriceChange(ctx, nil, priceChangeID)
+	if err != nil {
+		return fmt.Errorf("failed to cancel price change: %w", err)
+	}
+	if !ok {
+		return fmt.Errorf("price change %d is not scheduled", priceChangeID)
+	}
+
+	return nil
+}
+
+// ListPriceChanges 列出價格的所有變動記錄（含排程中與已取消）
+func (s *service) ListPriceChanges(ctx context.Context, priceID string) ([]*models.PriceChange, error) {
+	return s.price.ListPriceChanges(ctx, nil, priceID)
+}
+
+// ApplyDuePriceChanges 套用所有已到生效時間的價格變動，供排程定期呼叫，回傳套用的數量
+func (s *service) ApplyDuePriceChanges(ctx context.Context) (int, error) {
+	changes, err := s.price.ListDuePriceChanges(ctx, nil, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("failed to list due price changes: %w", err)
+	}
+
+	applied := 0
+	for _, change := range changes {
+		// 1. 在交易中標記為已生效，鎖定變動記錄避免多個排程重複套用
+		// 2. 通知商品目錄更新售價，發布失敗時回滾，變動保持排程中並於下次排程重試
+		var ok bool
+		if err = s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+			if ok, err = s.price.MarkPriceChangeApplied(ctx, tx, change.ID); err != nil || !ok {
+				return err
+			}
+			return s.eventManager.Publish(ctx, SubjectPriceChanged, PriceChangedEvent{
+				PriceChangeID: change.ID,
+				PriceID:       change.PriceID,
+				ProductID:     change.ProductID,
+				UnitPrice:     change.UnitPrice,
+				Currency:      change.Currency,
+				EffectiveAt:   change.EffectiveAt,
+				OccurredAt:    time.Now(),
+			})
+		}); err != nil {
+			s.log(ctx).Error("Failed to apply price change", zap.Uint64("price_change_id", change.ID), zap.Error(err))
+			continue
+		}
+		if !ok {
+			continue
+		}
+		applied++
+
+		// 3. 依價格保護設定處理成交單價較高的待付款訂單
+		s.protectPendingOrders(ctx, change)
+	}
+
+	return applied, nil
+}
+
+// GetPriceAt 取得價格在指定時間點的生效記錄
+func (s *service) GetPriceAt(ctx context.Context, priceID string, at time.Time) (*models.PriceChange, error) {
+	return s.price.GetPriceInEffect(ctx, nil, priceID, at)
+}
+
+// AuditOrderPrices 比對訂單每個項目的成交價與下單當時生效的價格
+func (s *service) AuditOrderPrices(ctx context.Context, orderID uint64) ([]*models.PriceAuditLine, error) {
+	var lines []*models.PriceAuditLine
+
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		// 1. 獲取訂單
+		orderModel, err := s.order.GetOrder(ctx, tx, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to get order: %w", err)
+		}
+
+		// 2. 獲取訂單項目
+		items, err := s.order.ListOrderItems(ctx, tx, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to list order items: %w", err)
+		}
+
+		// 3. 逐項比對下單當時的價格
+		for _, item := range items {
+			line := &models.PriceAuditLine{
+				OrderItemID:  item.ID,
+				PriceID:      item.PriceID,
+				ChargedPrice: item.UnitPrice,
+			}
+
+			inEffect, err := s.price.GetPriceInEffect(ctx, tx, item.PriceID, orderModel.CreatedAt)
+			switch {
+			case errors.Is(err, pgx.ErrNoRows):
+				// 沒有價格記錄時無從比對，視為一致
+				line.EffectivePrice = item.UnitPrice
+				line.Matched = true
+			case err != nil:
+				return fmt.Errorf("failed to get price in effect for %s: %w", item.PriceID, err)
+			default:
+				line.PriceChangeID = inEffect.ID
+				line.EffectivePrice = inEffect.UnitPrice
+				line.Matched = math.Abs(inEffect.UnitPrice-item.UnitPrice) < 0.005
+			}
+
+			lines = append(lines, line)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return lines, nil
+}
+
+// maxCatalogSnapshotProducts 單次 GetCatalogSnapshot 可查詢的商品數量上限
+const maxCatalogSnapshotProducts = 500
+
+// GetCatalogSnapshot 批次取得商品列表頁需要的價格、幣別、可售數量與分類 slug，回傳順序與 productIDs 相同，
+// 重複的商品只查詢一次，名稱與描述依 context 的語系翻譯；資料會短暫快取，可售數量可能落後實際庫存數十秒，下單時仍以購物車的預留檢查為準
+func (s *service) GetCatalogSnapshot(ctx context.Context, productIDs []string) ([]*models.CatalogEntry, error) {
+	unique := make([]string, 0, len(productIDs))
+	seen := make(map[string]struct{}, len(productIDs))
+	for _, productID := range productIDs {
+		if _, ok := seen[productID]; ok || productID == "" {
+			continue
+		}
+		seen[productID] = struct{}{}
+		unique = append(unique, productID)
+	}
+	if len(unique) == 0 {
+		return []*models.CatalogEntry{}, nil
+	}
+	if len(unique) > maxCatalogSnapshotProducts {
+		return nil, fmt.Errorf("catalog snapshot supports at most %d products, got %d", maxCatalogSnapshotProducts, len(unique))
+	}
+
+	var entries map[string]*models.CatalogEntry
+	result := make([]*models.CatalogEntry, 0, len(unique))
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		var err error
+		if entries, err = s.price.GetCatalogSnapshot(ctx, tx, unique); err != nil {
+			return err
+		}
+
+		for _, productID := range unique {
+			if entry, ok := entries[productID]; ok {
+				result = append(result, entry)
+			}
+		}
+		return s.localizeCatalogEntries(ctx, tx, result)
+	}); err != nil {
+		return nil, fmt.Errorf("failed to get catalog snapshot: %w", err)
+	}
+
+	return result, nil
+}
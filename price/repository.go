@@ -0,0 +1,350 @@
+package price
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stripe/stripe-go/v79"
+	"go.uber.org/zap"
+	"gofalre.io/shop/driver"
+	"gofalre.io/shop/models"
+	"gofalre.io/shop/sqlc"
+	"goflare.io/ember"
+	"time"
+)
+
+// catalogCacheTTL 商品列表頁的價格與庫存快照只快取很短的時間，避免顯示過時的可售數量
+const catalogCacheTTL = 30 * time.Second
+
+type Repository interface {
+	CreatePriceChange(ctx context.Context, tx pgx.Tx, params CreatePriceChangeParams) (*models.PriceChange, error)
+	ListPriceChanges(ctx context.Context, tx pgx.Tx, priceID string) ([]*models.PriceChange, error)
+	ListDuePriceChanges(ctx context.Context, tx pgx.Tx, now time.Time) ([]*models.PriceChange, error)
+	GetPriceInEffect(ctx context.Context, tx pgx.Tx, priceID string, at time.Time) (*models.PriceChange, error)
+	MarkPriceChangeApplied(ctx context.Context, tx pgx.Tx, priceChangeID uint64) (bool, error)
+	CancelPriceChange(ctx context.Context, tx pgx.Tx, priceChangeID uint64) (bool, error)
+	GetCatalogSnapshot(ctx context.Context, tx pgx.Tx, productIDs []string) (map[string]*models.CatalogEntry, error)
+
+	SetChannelProduct(ctx context.Context, tx pgx.Tx, channel, productID string, available bool) error
+	RemoveChannelProduct(ctx context.Context, tx pgx.Tx, channel, productID string) (bool, error)
+	GetChannelProduct(ctx context.Context, tx pgx.Tx, channel, productID string) (*models.ChannelProduct, error)
+	ListChannelProducts(ctx context.Context, tx pgx.Tx, channel string) ([]*models.ChannelProduct, error)
+	SetChannelPrice(ctx context.Context, tx pgx.Tx, channel, priceID string, currency stripe.Currency, unitPrice float64) error
+	RemoveChannelPrice(ctx context.Context, tx pgx.Tx, channel, priceID string, currency stripe.Currency) (bool, error)
+	GetChannelPrice(ctx context.Context, tx pgx.Tx, channel, priceID string, currency stripe.Currency) (*models.ChannelPrice, error)
+	ListChannelPrices(ctx context.Context, tx pgx.Tx, channel string) ([]*models.ChannelPrice, error)
+}
+
+type repository struct {
+	queries *driver.Queries
+	cache   *ember.Ember
+	shadow  *driver.CacheShadow
+	logger  *zap.Logger
+}
+
+func NewRepository(conn driver.PostgresPool, cache *ember.Ember, logger *zap.Logger, opts ...driver.RepositoryOption) Repository {
+	return NewRepositoryWithQuerier(sqlc.New(conn), cache, logger, opts...)
+}
+
+// NewRepositoryWithQuerier 以注入的 sqlc.Querier 建立 repository，交易中的查詢見 driver.TxBinder
+func NewRepositoryWithQuerier(querier sqlc.Querier, cache *ember.Ember, logger *zap.Logger, opts ...driver.RepositoryOption) Repository {
+	return &repository{
+		queries: driver.NewQueries(querier),
+		cache:   cache,
+		shadow:  driver.NewRepositoryOptions(opts...).CacheShadow,
+		logger:  logger,
+	}
+}
+
+var _ Repository = (*repository)(nil)
+
+func (r *repository) CreatePriceChange(ctx context.Context, tx pgx.Tx, params CreatePriceChangeParams) (*models.PriceChange, error) {
+	var reason *string
+	if params.Reason != "" {
+		reason = &params.Reason
+	}
+
+	var currency sqlc.NullCurrency
+	if params.Currency != "" {
+		currency = sqlc.NullCurrency{Currency: sqlc.Currency(params.Currency), Valid: true}
+	}
+
+	sqlcPriceChange, err := r.queries.WithTx(tx).CreatePriceChange(ctx, sqlc.CreatePriceChangeParams{
+		PriceID:     params.PriceID,
+		ProductID:   params.ProductID,
+		UnitPrice:   params.UnitPrice,
+		Status:      sqlc.PriceChangeStatusScheduled,
+		Reason:      reason,
+		EffectiveAt: pgtype.Timestamptz{Time: params.EffectiveAt, Valid: true},
+		Currency:    currency,
+	})
+	if err != nil {
+		r.logger.Error("failed to create price change", zap.String("price_id", params.PriceID), zap.Error(err))
+		return nil, err
+	}
+
+	return new(models.PriceChange).ConvertSqlcPriceChange(sqlcPriceChange), nil
+}
+
+func (r *repository) ListPriceChanges(ctx context.Context, tx pgx.Tx, priceID string) ([]*models.PriceChange, error) {
+	sqlcPriceChanges, err := r.queries.WithTx(tx).ListPriceChanges(ctx, priceID)
+	if err != nil {
+		r.logger.Error("failed to list price changes", zap.String("price_id", priceID), zap.Error(err))
+		return nil, err
+	}
+
+	priceChanges := make([]*models.PriceChange, 0, len(sqlcPriceChanges))
+	for _, sqlcPriceChange := range sqlcPriceChanges {
+		priceChanges = append(priceChanges, new(models.PriceChange).ConvertSqlcPriceChange(sqlcPriceChange))
+	}
+
+	return priceChanges, nil
+}
+
+func (r *repository) ListDuePriceChanges(ctx context.Context, tx pgx.Tx, now time.Time) ([]*models.PriceChange, error) {
+	sqlcPriceChanges, err := r.queries.WithTx(tx).ListDuePriceChanges(ctx, pgtype.Timestamptz{Time: now, Valid: true})
+	if err != nil {
+		r.logger.Error("failed to list due price changes", zap.Error(err))
+		return nil, err
+	}
+
+	priceChanges := make([]*models.PriceChange, 0, len(sqlcPriceChanges))
+	for _, sqlcPriceChange := range sqlcPriceChanges {
+		priceChanges = append(priceChanges, new(models.PriceChange).ConvertSqlcPriceChange(sqlcPriceChange))
+	}
+
+	return priceChanges, nil
+}
+
+// GetPriceInEffect 取得指定時間點已生效的最新價格，沒有記錄時回傳 pgx.ErrNoRows
+func (r *repository) GetPriceInEffect(ctx context.Context, tx pgx.Tx, priceID string, at time.Time) (*models.PriceChange, error) {
+	sqlcPriceChange, err := r.queries.WithTx(tx).GetPriceInEffect(ctx, sqlc.GetPriceInEffectParams{
+		PriceID:     priceID,
+		EffectiveAt: pgtype.Timestamptz{Time: at, Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return new(models.PriceChange).ConvertSqlcPriceChange(sqlcPriceChange), nil
+}
+
+// MarkPriceChangeApplied 將排程中的價格變動標記為已生效，並使該商品的目錄快照在所有實例失效；
+// 回傳 false 表示該變動已不是排程狀態
+func (r *repository) MarkPriceChangeApplied(ctx context.Context, tx pgx.Tx, priceChangeID uint64) (bool, error) {
+	productID, err := r.queries.WithTx(tx).MarkPriceChangeApplied(ctx, int32(priceChangeID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		r.logger.Error("failed to mark price change applied", zap.Uint64("price_change_id", priceChangeID), zap.Error(err))
+		return false, err
+	}
+
+	cacheKey := CatalogCacheKey(productID)
+	if err = r.cache.Delete(ctx, cacheKey); err != nil {
+		r.logger.Warn("Failed to invalidate catalog entry", zap.String("product_id", productID), zap.Error(err))
+	}
+	if err = driver.NotifyCacheInvalidation(ctx, tx, cacheKey); err != nil {
+		r.logger.Error("failed to notify catalog cache invalidation", zap.String("product_id", productID), zap.Error(err))
+		return false, err
+	}
+
+	return true, nil
+}
+
+// CancelPriceChange 取消排程中的價格變動，回傳 false 表示該變動已不是排程狀態
+func (r *repository) CancelPriceChange(ctx context.Context, tx pgx.Tx, priceChangeID uint64) (bool, error) {
+	rows, err := r.queries.WithTx(tx).CancelPriceChange(ctx, int32(priceChangeID))
+	if err != nil {
+		r.logger.Error("failed to cancel price change", zap.Uint64("price_change_id", priceChangeID), zap.Error(err))
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+// GetCatalogSnapshot 批次取得商品目前生效的價格、可售數量與分類 slug，先讀快取，未命中的商品以單一查詢補齊
+func (r *repository) GetCatalogSnapshot(ctx context.Context, tx pgx.Tx, productIDs []string) (map[string]*models.CatalogEntry, error) {
+	entries := make(map[string]*models.CatalogEntry, len(productIDs))
+	var missing []string
+
+	// 嘗試從快取中獲取
+	for _, productID := range productIDs {
+		var entry models.CatalogEntry
+		found, err := r.cache.Get(ctx, CatalogCacheKey(productID), &entry)
+		if err != nil {
+			r.logger.Warn("Failed to get catalog entry from cache", zap.String("product_id", productID), zap.Error(err))
+		}
+		if found {
+			r.shadow.Verify(ctx, CatalogCacheKey(productID), &entry, func(ctx context.Context) (any, error) {
+				rows, err := r.queries.Querier().GetCatalogSnapshot(ctx, []string{productID})
+				if err != nil {
+					return nil, err
+				}
+				if len(rows) == 0 {
+					return nil, pgx.ErrNoRows
+				}
+				return new(models.CatalogEntry).ConvertSqlcCatalogEntry(rows[0]), nil
+			})
+			entries[productID] = &entry
+			continue
+		}
+		missing = append(missing, productID)
+	}
+	if len(missing) == 0 {
+		return entries, nil
+	}
+
+	// 從資料庫中獲取
+	rows, err := r.queries.WithTx(tx).GetCatalogSnapshot(ctx, missing)
+	if err != nil {
+		r.logger.Error("failed to get catalog snapshot", zap.Int("products", len(missing)), zap.Error(err))
+		return nil, err
+	}
+
+	for _, row := range rows {
+		entry := new(models.CatalogEntry).ConvertSqlcCatalogEntry(row)
+		entries[entry.ProductID] = entry
+
+		if err = r.cache.Set(ctx, CatalogCacheKey(entry.ProductID), entry, catalogCacheTTL); err != nil {
+			r.logger.Warn("Failed to cache catalog entry", zap.String("product_id", entry.ProductID), zap.Error(err))
+		}
+	}
+
+	return entries, nil
+}
+
+// CatalogCacheKey 回傳商品目錄快照的快取鍵，分類異動時也用來使快照失效
+func CatalogCacheKey(productID string) string {
+	return fmt.Sprintf("catalog:product:%s", productID)
+}
+
+// SetChannelProduct 將商品上架到銷售通路，已上架時更新是否可供貨
+func (r *repository) SetChannelProduct(ctx context.Context, tx pgx.Tx, channel, productID string, available bool) error {
+	if err := r.queries.WithTx(tx).UpsertChannelProduct(ctx, sqlc.UpsertChannelProductParams{
+		Channel:   channel,
+		ProductID: productID,
+		Available: available,
+	}); err != nil {
+		r.logger.Error("failed to set channel product",
+			zap.String("channel", channel), zap.String("product_id", productID), zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// RemoveChannelProduct 將商品從銷售通路下架，回傳 false 表示商品原本就不在該通路
+func (r *repository) RemoveChannelProduct(ctx context.Context, tx pgx.Tx, channel, productID string) (bool, error) {
+	rows, err := r.queries.WithTx(tx).DeleteChannelProduct(ctx, sqlc.DeleteChannelProductParams{
+		Channel:   channel,
+		ProductID: productID,
+	})
+	if err != nil {
+		r.logger.Error("failed to remove channel product",
+			zap.String("channel", channel), zap.String("product_id", productID), zap.Error(err))
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+// GetChannelProduct 取得商品在銷售通路的上架資料，未上架時回傳 pgx.ErrNoRows
+func (r *repository) GetChannelProduct(ctx context.Context, tx pgx.Tx, channel, productID string) (*models.ChannelProduct, error) {
+	sqlcChannelProduct, err := r.queries.WithTx(tx).GetChannelProduct(ctx, sqlc.GetChannelProductParams{
+		Channel:   channel,
+		ProductID: productID,
+	})
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			r.logger.Error("failed to get channel product",
+				zap.String("channel", channel), zap.String("product_id", productID), zap.Error(err))
+		}
+		return nil, err
+	}
+
+	return new(models.ChannelProduct).ConvertSqlcChannelProduct(sqlcChannelProduct), nil
+}
+
+func (r *repository) ListChannelProducts(ctx context.Context, tx pgx.Tx, channel string) ([]*models.ChannelProduct, error) {
+	sqlcChannelProducts, err := r.queries.WithTx(tx).ListChannelProducts(ctx, channel)
+	if err != nil {
+		r.logger.Error("failed to list channel products", zap.String("channel", channel), zap.Error(err))
+		return nil, err
+	}
+
+	channelProducts := make([]*models.ChannelProduct, 0, len(sqlcChannelProducts))
+	for _, sqlcChannelProduct := range sqlcChannelProducts {
+		channelProducts = append(channelProducts, new(models.ChannelProduct).ConvertSqlcChannelProduct(sqlcChannelProduct))
+	}
+
+	return channelProducts, nil
+}
+
+// SetChannelPrice 設定價格在銷售通路與幣別下的單價，已設定時覆蓋
+func (r *repository) SetChannelPrice(ctx context.Context, tx pgx.Tx, channel, priceID string, currency stripe.Currency, unitPrice float64) error {
+	if err := r.queries.WithTx(tx).UpsertChannelPrice(ctx, sqlc.UpsertChannelPriceParams{
+		Channel:   channel,
+		PriceID:   priceID,
+		Currency:  sqlc.Currency(currency),
+		UnitPrice: unitPrice,
+	}); err != nil {
+		r.logger.Error("failed to set channel price",
+			zap.String("channel", channel), zap.String("price_id", priceID), zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// RemoveChannelPrice 移除銷售通路的價格，之後以原價格計算；回傳 false 表示原本就沒有設定
+func (r *repository) RemoveChannelPrice(ctx context.Context, tx pgx.Tx, channel, priceID string, currency stripe.Currency) (bool, error) {
+	rows, err := r.queries.WithTx(tx).DeleteChannelPrice(ctx, sqlc.DeleteChannelPriceParams{
+		Channel:  channel,
+		PriceID:  priceID,
+		Currency: sqlc.Currency(currency),
+	})
+	if err != nil {
+		r.logger.Error("failed to remove channel price",
+			zap.String("channel", channel), zap.String("price_id", priceID), zap.Error(err))
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+// GetChannelPrice 取得價格在銷售通路與幣別下的單價，沒有設定時回傳 pgx.ErrNoRows
+func (r *repository) GetChannelPrice(ctx context.Context, tx pgx.Tx, channel, priceID string, currency stripe.Currency) (*models.ChannelPrice, error) {
+	sqlcChannelPrice, err := r.queries.WithTx(tx).GetChannelPrice(ctx, sqlc.GetChannelPriceParams{
+		Channel:  channel,
+		PriceID:  priceID,
+		Currency: sqlc.Currency(currency),
+	})
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			r.logger.Error("failed to get channel price",
+				zap.String("channel", channel), zap.String("price_id", priceID), zap.Error(err))
+		}
+		return nil, err
+	}
+
+	return new(models.ChannelPrice).ConvertSqlcChannelPrice(sqlcChannelPrice), nil
+}
+
+func (r *repository) ListChannelPrices(ctx context.Context, tx pgx.Tx, channel string) ([]*models.ChannelPrice, error) {
+	sqlcChannelPrices, err := r.queries.WithTx(tx).ListChannelPrices(ctx, channel)
+	if err != nil {
+		r.logger.Error("failed to list channel prices", zap.String("channel", channel), zap.Error(err))
+		return nil, err
+	}
+
+	channelPrices := make([]*models.ChannelPrice, 0, len(sqlcChannelPrices))
+	for _, sqlcChannelPrice := range sqlcChannelPrices {
+		channelPrices = append(channelPrices, new(models.ChannelPrice).ConvertSqlcChannelPrice(sqlcChannelPrice))
+	}
+
+	return channelPrices, nil
+}
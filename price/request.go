@@ -0,0 +1,16 @@
+package price
+
+import (
+	"time"
+
+	"github.com/stripe/stripe-go/v79"
+)
+
+type CreatePriceChangeParams struct {
+	PriceID     string
+	ProductID   string
+	UnitPrice   float64
+	Currency    stripe.Currency
+	Reason      string
+	EffectiveAt time.Time
+}
@@ -0,0 +1,350 @@
+package shop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stripe/stripe-go/v79"
+	"go.uber.org/zap"
+
+	"gofalre.io/shop/models"
+	"gofalre.io/shop/models/enum"
+)
+
+// ErrOrderRepricingNotFound 表示重新計價的記錄不存在
+var ErrOrderRepricingNotFound = errors.New("order repricing not found")
+
+// PaymentAmountUpdater 更新尚未完成付款的 payment intent 金額，IdempotencyKey 相同的要求只會更新一次
+type PaymentAmountUpdater interface {
+	UpdatePaymentAmount(ctx context.Context, req PaymentAmountUpdateRequest) error
+}
+
+// PaymentAmountUpdateRequest 描述一筆要向金流服務更新金額的付款
+type PaymentAmountUpdateRequest struct {
+	OrderID         uint64
+	PaymentIntentID string
+	Amount          float64
+	Currency        stripe.Currency
+	IdempotencyKey  string
+}
+
+// WithPriceProtection 設定價格調降時待付款訂單的處理方式：auto_reprice 直接以新價格重新計價，
+// review 建立等待人員審核的重新計價記錄；未設定時為 off，不處理已建立的訂單
+func WithPriceProtection(mode enum.PriceProtectionMode) Option {
+	return func(s *service) {
+		if mode != "" {
+			s.priceProtection = mode
+		}
+	}
+}
+
+// WithPaymentAmountUpdater 設定重新計價時更新 payment intent 金額使用的金流服務；
+// 未設定時已建立 payment intent 的訂單不會自動重新計價，改為等待人員審核
+func WithPaymentAmountUpdater(updater PaymentAmountUpdater) Option {
+	return func(s *service) {
+		s.paymentAmountUpdater = updater
+	}
+}
+
+// protectPendingOrders 依價格保護設定處理成交單價高於新價格的待付款訂單，每筆訂單在各自的交易中處理，
+// 單筆失敗只記錄錯誤，不影響價格變動的套用
+func (s *service) protectPendingOrders(ctx context.Context, change *models.PriceChange) {
+	if s.priceProtection == enum.PriceProtectionModeOff {
+		return
+	}
+
+	var orderIDs []uint64
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		var err error
+		orderIDs, err = s.order.ListPendingOrderIDsByPrice(ctx, tx, change.PriceID, change.Currency, change.UnitPrice)
+		return err
+	}); err != nil {
+		s.log(ctx).Error("Failed to list pending orders for price protection",
+			zap.Uint64("price_change_id", change.ID), zap.Error(err))
+		return
+	}
+
+	for _, orderID := range orderIDs {
+		repricing, err := s.protectOrderPrice(ctx, orderID, change)
+		if err != nil {
+			s.log(ctx).Error("Failed to reprice pending order",
+				zap.Uint64("order_id", orderID), zap.Uint64("price_change_id", change.ID), zap.Error(err))
+			continue
+		}
+		if repricing != nil {
+			s.log(ctx).Info("Pending order repriced after price drop",
+				zap.Uint64("order_id", orderID), zap.Uint64("price_change_id", change.ID),
+				zap.String("status", string(repricing.Status)),
+				zap.Float64("previous_total", repricing.PreviousTotal), zap.Float64("new_total", repricing.NewTotal))
+		}
+	}
+}
+
+// protectOrderPrice 對單筆待付款訂單套用價格變動，訂單已不是待付款、沒有需要調降的項目或已處理過此價格變動時回傳 nil
+func (s *service) protectOrderPrice(ctx context.Context, orderID uint64, change *models.PriceChange) (*models.OrderRepricing, error) {
+	var repricing *models.OrderRepricing
+
+	if err := s.executeOrderTransaction(ctx, func(tx pgx.Tx) error {
+		repricing = nil
+
+		// 1. 鎖定訂單，付款或取消後不再重新計價
+		orderModel, err := s.order.GetOrderForUpdate(ctx, tx, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to get order: %w", err)
+		}
+		if orderModel.Status != enum.OrderStatusPending {
+			return nil
+		}
+
+		// 2. 同一筆價格變動只處理一次，訂單已鎖定，不會有同時建立的記錄
+		existing, err := s.order.ListOrderRepricings(ctx, tx, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to list order repricings: %w", err)
+		}
+		for _, previous := range existing {
+			if previous.PriceChangeID == change.ID {
+				return nil
+			}
+		}
+
+		// 3. 試算新的金額
+		items, err := s.order.ListOrderItems(ctx, tx, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to list order items: %w", err)
+		}
+		repriced, lines := repriceOrder(orderModel, items, change.PriceID, change.UnitPrice)
+		if len(lines) == 0 {
+			return nil
+		}
+
+		// 4. 記錄重新計價，已建立 payment intent 但無法更新金額時改為等待審核
+		autoReprice := s.priceProtection == enum.PriceProtectionModeAutoReprice &&
+			(orderModel.PaymentIntentID == "" || s.paymentAmountUpdater != nil)
+		repricing = &models.OrderRepricing{
+			OrderID:       orderID,
+			PriceChangeID: change.ID,
+			PriceID:       change.PriceID,
+			NewUnitPrice:  change.UnitPrice,
+			PreviousTotal: orderModel.Total,
+			NewTotal:      repriced.Total,
+			Status:        enum.OrderRepricingStatusPendingReview,
+		}
+		if autoReprice {
+			repricing.Status = enum.OrderRepricingStatusApplied
+		}
+		if err = s.order.CreateOrderRepricing(ctx, tx, repricing); err != nil {
+			return fmt.Errorf("failed to create order repricing: %w", err)
+		}
+
+		// 5. 自動重新計價時更新訂單與 payment intent
+		if autoReprice {
+			return s.applyOrderRepricing(ctx, tx, repriced, lines, repricing.ID)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return repricing, nil
+}
+
+// ListPendingOrderRepricings 依建立順序列出等待人員審核的重新計價
+func (s *service) ListPendingOrderRepricings(ctx context.Context) ([]*models.OrderRepricing, error) {
+	var repricings []*models.OrderRepricing
+
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		var err error
+		if repricings, err = s.order.ListPendingOrderRepricings(ctx, tx); err != nil {
+			return fmt.Errorf("failed to list pending order repricings: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return repricings, nil
+}
+
+// ApproveOrderRepricing 核准等待審核的重新計價，以記錄的新單價重新計算訂單並更新 payment intent 的金額；
+// 訂單已付款或取消時無法核准，只能略過
+func (s *service) ApproveOrderRepricing(ctx context.Context, repricingID uint64, reviewedBy string) (*models.OrderRepricing, error) {
+	if reviewedBy == "" {
+		return nil, errors.New("repricing reviewer is required")
+	}
+
+	var repricing *models.OrderRepricing
+
+	if err := s.executeOrderTransaction(ctx, func(tx pgx.Tx) error {
+		var err error
+		if repricing, err = s.getPendingOrderRepricing(ctx, tx, repricingID, reviewedBy); err != nil {
+			return err
+		}
+
+		// 1. 鎖定訂單並確認仍未付款
+		orderModel, err := s.order.GetOrderForUpdate(ctx, tx, repricing.OrderID)
+		if err != nil {
+			return fmt.Errorf("failed to get order: %w", err)
+		}
+		if orderModel.Status != enum.OrderStatusPending {
+			return fmt.Errorf("order %d is %s and can no longer be repriced", orderModel.ID, orderModel.Status)
+		}
+		if orderModel.PaymentIntentID != "" && s.paymentAmountUpdater == nil {
+			return fmt.Errorf("order %d has a payment intent but no payment amount updater is configured", orderModel.ID)
+		}
+
+		// 2. 以目前的訂單項目重新計算，審核期間訂單可能已變動
+		items, err := s.order.ListOrderItems(ctx, tx, orderModel.ID)
+		if err != nil {
+			return fmt.Errorf("failed to list order items: %w", err)
+		}
+		repriced, lines := repriceOrder(orderModel, items, repricing.PriceID, repricing.NewUnitPrice)
+
+		// 3. 記錄審核結果後更新訂單，payment intent 在最後更新
+		if err = s.resolveOrderRepricing(ctx, tx, repricing, enum.OrderRepricingStatusApplied, reviewedBy, repriced.Total); err != nil {
+			return err
+		}
+		if len(lines) == 0 {
+			return nil
+		}
+		return s.applyOrderRepricing(ctx, tx, repriced, lines, repricing.ID)
+	}); err != nil {
+		return nil, err
+	}
+
+	s.log(ctx).Info("Order repricing approved",
+		zap.Uint64("order_id", repricing.OrderID), zap.Uint64("repricing_id", repricingID),
+		zap.String("reviewed_by", reviewedBy), zap.Float64("new_total", repricing.NewTotal))
+	return repricing, nil
+}
+
+// DismissOrderRepricing 略過等待審核的重新計價，訂單維持原本的金額
+func (s *service) DismissOrderRepricing(ctx context.Context, repricingID uint64, reviewedBy string) (*models.OrderRepricing, error) {
+	if reviewedBy == "" {
+		return nil, errors.New("repricing reviewer is required")
+	}
+
+	var repricing *models.OrderRepricing
+
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		var err error
+		if repricing, err = s.getPendingOrderRepricing(ctx, tx, repricingID, reviewedBy); err != nil {
+			return err
+		}
+		return s.resolveOrderRepricing(ctx, tx, repricing, enum.OrderRepricingStatusDismissed, reviewedBy, repricing.NewTotal)
+	}); err != nil {
+		return nil, err
+	}
+
+	s.log(ctx).Info("Order repricing dismissed",
+		zap.Uint64("order_id", repricing.OrderID), zap.Uint64("repricing_id", repricingID), zap.String("reviewed_by", reviewedBy))
+	return repricing, nil
+}
+
+// repriceOrder 以新單價試算 priceID 的項目，只調降成交單價高於新單價的項目，稅額依小計等比例調整；
+// 不修改傳入的訂單與項目，回傳試算後的訂單及有變動的項目
+func repriceOrder(orderModel *models.Order, items []*models.OrderItem, priceID string, unitPrice float64) (*models.Order, []*models.OrderItem) {
+	repriced := *orderModel
+	var lines []*models.OrderItem
+
+	for _, item := range items {
+		if item.PriceID != priceID || roundCurrency(item.UnitPrice-unitPrice) <= 0 {
+			continue
+		}
+
+		line := *item
+		line.UnitPrice = unitPrice
+		line.Subtotal = roundCurrencyAmount(unitPrice*float64(item.Quantity), orderModel.Currency)
+		if item.Subtotal > 0 {
+			line.TaxAmount = roundCurrencyAmount(item.TaxAmount*line.Subtotal/item.Subtotal, orderModel.Currency)
+		}
+
+		repriced.Subtotal -= item.Subtotal - line.Subtotal
+		repriced.Tax -= item.TaxAmount - line.TaxAmount
+		lines = append(lines, &line)
+	}
+
+	repriced.Subtotal = roundCurrencyAmount(repriced.Subtotal, orderModel.Currency)
+	repriced.Tax = roundCurrencyAmount(repriced.Tax, orderModel.Currency)
+	repriced.Discount = min(repriced.Discount, repriced.Subtotal)
+	repriced.Total = roundCurrencyAmount(repriced.Subtotal+repriced.Tax-repriced.Discount, orderModel.Currency)
+
+	return &repriced, lines
+}
+
+// applyOrderRepricing 更新訂單項目與總計，並更新報表幣別的快照與 payment intent 的金額。payment intent 在最後更新，
+// 交易重試時以相同的 idempotency key 更新
+func (s *service) applyOrderRepricing(ctx context.Context, tx pgx.Tx, repriced *models.Order, lines []*models.OrderItem, repricingID uint64) error {
+	// 1. 更新訂單項目
+	for _, line := range lines {
+		if err := s.order.UpdateOrderItem(ctx, tx, line); err != nil {
+			return fmt.Errorf("failed to update order item: %w", err)
+		}
+	}
+
+	// 2. 更新訂單總計與報表快照
+	if err := s.order.UpdateOrderTotals(ctx, tx, repriced.ID, repriced.Tax, repriced.Subtotal, repriced.Discount, repriced.Total, repriced.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to update order totals: %w", err)
+	}
+	if err := s.recordReportingSnapshot(ctx, tx, repriced); err != nil {
+		return err
+	}
+
+	// 3. 更新 payment intent 的金額
+	if repriced.PaymentIntentID == "" {
+		return nil
+	}
+	if err := s.paymentAmountUpdater.UpdatePaymentAmount(ctx, PaymentAmountUpdateRequest{
+		OrderID:         repriced.ID,
+		PaymentIntentID: repriced.PaymentIntentID,
+		Amount:          repriced.Total,
+		Currency:        repriced.Currency,
+		IdempotencyKey:  "order-reprice-" + strconv.FormatUint(repricingID, 10),
+	}); err != nil {
+		return fmt.Errorf("failed to update payment amount: %w", err)
+	}
+
+	return nil
+}
+
+// getPendingOrderRepricing 鎖定等待審核的重新計價並檢查審核者的權限，不存在時回傳 ErrOrderRepricingNotFound
+func (s *service) getPendingOrderRepricing(ctx context.Context, tx pgx.Tx, repricingID uint64, reviewedBy string) (*models.OrderRepricing, error) {
+	repricing, err := s.order.GetOrderRepricingForUpdate(ctx, tx, repricingID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%w: %d", ErrOrderRepricingNotFound, repricingID)
+		}
+		return nil, fmt.Errorf("failed to get order repricing: %w", err)
+	}
+	if repricing.Status != enum.OrderRepricingStatusPendingReview {
+		return nil, fmt.Errorf("order repricing %d is already %s", repricingID, repricing.Status)
+	}
+
+	if err = s.checkAuthorization(ctx, AuthorizationRequest{
+		Action:     ActionReviewOrderRepricing,
+		ResourceID: repricing.ID,
+		Actor:      reviewedBy,
+	}); err != nil {
+		return nil, err
+	}
+
+	return repricing, nil
+}
+
+// resolveOrderRepricing 記錄審核結果並更新 repricing
+func (s *service) resolveOrderRepricing(ctx context.Context, tx pgx.Tx, repricing *models.OrderRepricing, status enum.OrderRepricingStatus, reviewedBy string, newTotal float64) error {
+	ok, err := s.order.ResolveOrderRepricing(ctx, tx, repricing.ID, status, reviewedBy, newTotal)
+	if err != nil {
+		return fmt.Errorf("failed to resolve order repricing: %w", err)
+	}
+	if !ok {
+		return fmt.Errorf("order repricing %d has already been reviewed", repricing.ID)
+	}
+
+	repricing.Status = status
+	repricing.ReviewedBy = reviewedBy
+	repricing.NewTotal = newTotal
+	return nil
+}
@@ -0,0 +1,131 @@
+package shop
+
+import (
+	"context"
+	"math"
+
+	"github.com/stripe/stripe-go/v79"
+
+	"gofalre.io/shop/models"
+	"gofalre.io/shop/models/enum"
+)
+
+// PriceRoundingRule 為一個市場的價格尾數規則：價格調整為 Step 的倍數加上 Ending，
+// 例如 Step 1、Ending 0.99 為 .99 結尾，Step 0.05 為最接近的 5 分（瑞士法郎）；Mode 為空字串時取最接近的價格
+type PriceRoundingRule struct {
+	Step   float64
+	Ending float64
+	Mode   enum.PriceRoundingMode
+}
+
+// Apply 回傳 amount 依規則調整後的價格；Step 不是正數或調整後不是正數時只四捨五入到分
+func (r PriceRoundingRule) Apply(amount float64) float64 {
+	if r.Step <= 0 || amount <= 0 {
+		return roundCurrency(amount)
+	}
+
+	// 容許浮點誤差，已符合規則的價格不會被進位或捨去
+	const epsilon = 1e-9
+	units := (amount - r.Ending) / r.Step
+	switch r.Mode {
+	case enum.PriceRoundingModeUp:
+		units = math.Ceil(units - epsilon)
+	case enum.PriceRoundingModeDown:
+		units = math.Floor(units + epsilon)
+	default:
+		units = math.Round(units)
+	}
+
+	rounded := roundCurrency(units*r.Step + r.Ending)
+	if rounded <= 0 {
+		return roundCurrency(amount)
+	}
+	return rounded
+}
+
+// WithPriceRounding 設定幣別的價格尾數規則，套用於所有租戶；同一幣別重複設定時以最後一次為準
+func WithPriceRounding(currency stripe.Currency, rule PriceRoundingRule) Option {
+	return WithTenantPriceRounding("", currency, rule)
+}
+
+// WithTenantPriceRounding 設定單一租戶（見 WithTenant）在幣別的價格尾數規則，優先於 WithPriceRounding 的設定
+func WithTenantPriceRounding(tenant string, currency stripe.Currency, rule PriceRoundingRule) Option {
+	return func(s *service) {
+		if s.priceRounding == nil {
+			s.priceRounding = make(map[string]map[stripe.Currency]PriceRoundingRule)
+		}
+		if s.priceRounding[tenant] == nil {
+			s.priceRounding[tenant] = make(map[stripe.Currency]PriceRoundingRule)
+		}
+		s.priceRounding[tenant][currency] = rule
+	}
+}
+
+// RoundPrice 依 ctx 的租戶與幣別的尾數規則調整價格，供換算幣別後的價格使用；沒有規則時依幣別的小數位數四捨五入，
+// 例如日圓取整數
+func (s *service) RoundPrice(ctx context.Context, amount float64, currency stripe.Currency) float64 {
+	rule, ok := s.priceRoundingRule(ctx, currency)
+	if !ok {
+		return roundCurrencyAmount(amount, currency)
+	}
+	return roundCurrencyAmount(rule.Apply(amount), currency)
+}
+
+// priceRoundingRule 取得 ctx 的租戶在幣別的尾數規則，租戶沒有設定時使用所有租戶共用的規則
+func (s *service) priceRoundingRule(ctx context.Context, currency stripe.Currency) (PriceRoundingRule, bool) {
+	if tenant := TenantFromContext(ctx); tenant != "" {
+		if rule, ok := s.priceRounding[tenant][currency]; ok {
+			return rule, true
+		}
+	}
+	rule, ok := s.priceRounding[""][currency]
+	return rule, ok
+}
+
+// roundUnitPrices 以 RoundPrice 調整每個項目的單價並重新計算小計，已符合規則的單價不變；
+// 購物車項目的單價可能來自通路價格表或在規則設定前加入，結帳轉換訂單前在此統一調整
+func (s *service) roundUnitPrices(ctx context.Context, currency stripe.Currency, items []*models.CartItem) {
+	for _, item := range items {
+		unitPrice := s.RoundPrice(ctx, item.UnitPrice, currency)
+		if unitPrice == item.UnitPrice {
+			continue
+		}
+		item.UnitPrice = unitPrice
+		item.Subtotal = roundCurrencyAmount(float64(item.Quantity)*unitPrice, currency)
+	}
+}
+
+// roundDiscountedPrices 依尾數規則調整有折扣項目的折扣後單價，調整的差額從該項目最後套用的優惠開始增減，
+// 讓各優惠的折扣與 LineDiscounts 一致；折扣不會小於 0 或超過項目小計
+func (s *service) roundDiscountedPrices(ctx context.Context, currency stripe.Currency, items []*models.CartItem, resolution *promotionResolution) {
+	rule, ok := s.priceRoundingRule(ctx, currency)
+	if !ok {
+		return
+	}
+
+	for _, item := range items {
+		discount := resolution.LineDiscounts[item.ID]
+		if discount <= 0 || item.Quantity == 0 {
+			continue
+		}
+
+		unitPrice := rule.Apply((item.Subtotal - discount) / float64(item.Quantity))
+		rounded := roundCurrencyAmount(min(max(item.Subtotal-unitPrice*float64(item.Quantity), 0), item.Subtotal), currency)
+		delta := roundCurrencyAmount(rounded-discount, currency)
+		if delta == 0 {
+			continue
+		}
+		resolution.LineDiscounts[item.ID] = rounded
+
+		for j := len(resolution.Applied) - 1; j >= 0 && delta != 0; j-- {
+			line, ok := resolution.Lines[j][item.ID]
+			if !ok {
+				continue
+			}
+			adjusted := roundCurrencyAmount(max(line+delta, 0), currency)
+			resolution.Lines[j][item.ID] = adjusted
+			resolution.Applied[j].Discount = roundCurrencyAmount(resolution.Applied[j].Discount+adjusted-line, currency)
+			delta = roundCurrencyAmount(delta-(adjusted-line), currency)
+		}
+	}
+}
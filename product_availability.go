@@ -0,0 +1,72 @@
+package shop
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"gofalre.io/shop/models"
+)
+
+// defaultAvailabilityRefreshInterval 為預設的商品庫存彙總更新間隔
+const defaultAvailabilityRefreshInterval = 5 * time.Minute
+
+// WithAvailabilityRefreshInterval 設定 RunProductAvailabilityRefresher 更新商品庫存彙總的間隔
+func WithAvailabilityRefreshInterval(d time.Duration) Option {
+	return func(s *service) {
+		if d > 0 {
+			s.stockViewRefresh = d
+		}
+	}
+}
+
+// RefreshProductAvailability 立即重新計算商品在所有地點加總的庫存，例如大量匯入庫存後
+func (s *service) RefreshProductAvailability(ctx context.Context) error {
+	if err := s.stock.RefreshProductAvailability(ctx, nil); err != nil {
+		return fmt.Errorf("failed to refresh product availability: %w", err)
+	}
+	return nil
+}
+
+// ListProductAvailability 批次取得商品列表與搜尋頁顯示用的庫存，資料取自定期更新的彙總而非逐筆查詢 stocks；
+// 每個 productIDs 都會有對應的結果，沒有庫存記錄的商品可用數量為 0
+func (s *service) ListProductAvailability(ctx context.Context, productIDs []string) (map[string]*models.ProductAvailability, error) {
+	result := make(map[string]*models.ProductAvailability, len(productIDs))
+	if len(productIDs) == 0 {
+		return result, nil
+	}
+
+	availability, err := s.stock.ListProductAvailability(ctx, nil, productIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list product availability: %w", err)
+	}
+	for _, a := range availability {
+		result[a.ProductID] = a
+	}
+	for _, productID := range productIDs {
+		if _, ok := result[productID]; !ok {
+			result[productID] = &models.ProductAvailability{ProductID: productID}
+		}
+	}
+
+	return result, nil
+}
+
+// RunProductAvailabilityRefresher 依 WithAvailabilityRefreshInterval 設定的間隔定期更新商品庫存彙總，直到 ctx 結束
+func (s *service) RunProductAvailabilityRefresher(ctx context.Context) error {
+	ticker := time.NewTicker(s.stockViewRefresh)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := s.RefreshProductAvailability(ctx); err != nil {
+				s.log(ctx).Error("Failed to refresh product availability", zap.Error(err))
+			}
+		}
+	}
+}
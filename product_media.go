@@ -0,0 +1,197 @@
+package shop
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+
+	"gofalre.io/shop/models"
+	"gofalre.io/shop/models/enum"
+)
+
+// ErrProductMediaNotFound 表示商品媒體不存在
+var ErrProductMediaNotFound = errors.New("product media not found")
+
+// ErrMediaStorageNotConfigured 表示未設定 MediaStorage，無法上傳媒體檔案
+var ErrMediaStorageNotConfigured = errors.New("media storage is not configured")
+
+// MediaStorage 存放商品媒體的檔案（例如 S3、GCS），metadata 保存在資料庫；
+// Put 回傳檔案對外的網址，Delete 刪除不存在的檔案時應回傳 nil
+type MediaStorage interface {
+	Put(ctx context.Context, key, contentType string, body io.Reader) (string, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// WithMediaStorage 設定上傳與刪除商品媒體檔案使用的物件儲存
+func WithMediaStorage(storage MediaStorage) Option {
+	return func(s *service) {
+		s.mediaStorage = storage
+	}
+}
+
+// UploadProductMedia 將檔案上傳到物件儲存並新增商品媒體，media.Type 為空時依 ContentType 判斷；
+// 寫入資料庫失敗時會刪除已上傳的檔案
+func (s *service) UploadProductMedia(ctx context.Context, media *models.ProductMedia, body io.Reader) error {
+	if s.mediaStorage == nil {
+		return ErrMediaStorageNotConfigured
+	}
+	if media.Type == "" {
+		media.Type = mediaTypeFromContentType(media.ContentType)
+	}
+	if err := validateProductMedia(media); err != nil {
+		return err
+	}
+	if media.ContentType == "" {
+		return errors.New("content type is required")
+	}
+
+	// 1. 以隨機的 key 上傳檔案，避免覆蓋同名的檔案
+	key, err := newMediaStorageKey(media.ProductID)
+	if err != nil {
+		return err
+	}
+	url, err := s.mediaStorage.Put(ctx, key, media.ContentType, body)
+	if err != nil {
+		return fmt.Errorf("failed to upload media: %w", err)
+	}
+	media.URL, media.StorageKey = url, key
+
+	// 2. 新增 metadata
+	if err = s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		if err := s.category.CreateProductMedia(ctx, tx, media); err != nil {
+			return fmt.Errorf("failed to create product media: %w", err)
+		}
+		return nil
+	}); err != nil {
+		s.deleteMediaFile(ctx, key)
+		return err
+	}
+
+	return nil
+}
+
+// CreateProductMedia 以外部網址新增商品媒體，檔案不由 MediaStorage 管理
+func (s *service) CreateProductMedia(ctx context.Context, media *models.ProductMedia) error {
+	media.StorageKey = ""
+	if err := validateProductMedia(media); err != nil {
+		return err
+	}
+	if media.URL == "" {
+		return errors.New("media url is required")
+	}
+
+	return s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		if err := s.category.CreateProductMedia(ctx, tx, media); err != nil {
+			return fmt.Errorf("failed to create product media: %w", err)
+		}
+		return nil
+	})
+}
+
+// UpdateProductMedia 更新商品媒體的變體、替代文字與排序，網址與檔案不會變更
+func (s *service) UpdateProductMedia(ctx context.Context, media *models.ProductMedia) error {
+	return s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		if err := s.category.UpdateProductMedia(ctx, tx, media); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return fmt.Errorf("%w: %d", ErrProductMediaNotFound, media.ID)
+			}
+			return fmt.Errorf("failed to update product media: %w", err)
+		}
+		return nil
+	})
+}
+
+// DeleteProductMedia 刪除商品媒體，交易完成後再刪除物件儲存中的檔案；
+// 檔案刪除失敗只記錄警告，留下的檔案不會再被引用
+func (s *service) DeleteProductMedia(ctx context.Context, mediaID uint64) error {
+	var media *models.ProductMedia
+
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		var err error
+		media, err = s.category.DeleteProductMedia(ctx, tx, mediaID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return fmt.Errorf("%w: %d", ErrProductMediaNotFound, mediaID)
+			}
+			return fmt.Errorf("failed to delete product media: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if media.StorageKey != "" {
+		s.deleteMediaFile(ctx, media.StorageKey)
+	}
+
+	return nil
+}
+
+// ListProductMedia 依排序列出商品的媒體，priceID 不為空時只列出共用的媒體與該變體的媒體
+func (s *service) ListProductMedia(ctx context.Context, productID, priceID string) ([]*models.ProductMedia, error) {
+	var media []*models.ProductMedia
+
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		var err error
+		if media, err = s.category.ListProductMedia(ctx, tx, productID, priceID); err != nil {
+			return fmt.Errorf("failed to list product media: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return media, nil
+}
+
+// deleteMediaFile 刪除物件儲存中的檔案，失敗時只記錄警告
+func (s *service) deleteMediaFile(ctx context.Context, key string) {
+	if s.mediaStorage == nil {
+		s.log(ctx).Warn("Media storage is not configured, media file is left in storage", zap.String("key", key))
+		return
+	}
+	if err := s.mediaStorage.Delete(ctx, key); err != nil {
+		s.log(ctx).Warn("Failed to delete media file", zap.String("key", key), zap.Error(err))
+	}
+}
+
+// validateProductMedia 檢查商品媒體的商品與種類
+func validateProductMedia(media *models.ProductMedia) error {
+	if media.ProductID == "" {
+		return errors.New("product id is required")
+	}
+	switch media.Type {
+	case enum.ProductMediaTypeImage, enum.ProductMediaTypeVideo:
+		return nil
+	default:
+		return fmt.Errorf("unsupported media type %q", media.Type)
+	}
+}
+
+// mediaTypeFromContentType 依 MIME 類型判斷媒體種類，無法判斷時回傳空字串
+func mediaTypeFromContentType(contentType string) enum.ProductMediaType {
+	switch {
+	case strings.HasPrefix(contentType, "image/"):
+		return enum.ProductMediaTypeImage
+	case strings.HasPrefix(contentType, "video/"):
+		return enum.ProductMediaTypeVideo
+	default:
+		return ""
+	}
+}
+
+// newMediaStorageKey 產生商品媒體在物件儲存中的路徑，例如 products/prod_123/9f86d081884c7d65
+func newMediaStorageKey(productID string) (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate media storage key: %w", err)
+	}
+	return fmt.Sprintf("products/%s/%s", productID, hex.EncodeToString(b)), nil
+}
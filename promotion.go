@@ -0,0 +1,232 @@
+package shop
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/stripe/stripe-go/v79"
+
+	"gofalre.io/shop/models"
+	"gofalre.io/shop/models/enum"
+)
+
+// PromotionSource 提供分類折扣活動以外的優惠（例如優惠券、數量折扣），優惠的資料由外部服務維護；
+// 回傳購物車目前適用的所有優惠，實際套用哪些由 PromotionStackingPolicy 決定
+type PromotionSource interface {
+	ListPromotions(ctx context.Context, cart *models.Cart, items []*models.CartItem, at time.Time) ([]*models.Promotion, error)
+}
+
+// PromotionStackingPolicy 為多個優惠同時適用時的疊加規則。
+// MaxDiscountPercent 為每個項目的折扣總額佔項目小計的上限，0 表示不限（最多折抵整個小計）；
+// MaxPromotions 為 stack 模式下最多套用的優惠數，0 表示不限
+type PromotionStackingPolicy struct {
+	Mode               enum.PromotionStackingMode
+	MaxDiscountPercent float64
+	MaxPromotions      int
+}
+
+// DefaultPromotionStackingPolicy 為未設定疊加規則時使用的規則：所有優惠依序疊加，同一互斥群組只套用一個
+var DefaultPromotionStackingPolicy = PromotionStackingPolicy{
+	Mode: enum.PromotionStackingModeStack,
+}
+
+// WithPromotionSources 設定分類折扣活動以外的優惠來源，未設定時只套用分類折扣活動
+func WithPromotionSources(sources ...PromotionSource) Option {
+	return func(s *service) {
+		s.promotionSources = append(s.promotionSources, sources...)
+	}
+}
+
+// WithPromotionStackingPolicy 設定多個優惠同時適用時的疊加規則，Mode 為空字串時使用 stack
+func WithPromotionStackingPolicy(policy PromotionStackingPolicy) Option {
+	return func(s *service) {
+		if policy.Mode == "" {
+			policy.Mode = enum.PromotionStackingModeStack
+		}
+		s.promotionPolicy = policy
+	}
+}
+
+// promotionResolution 為依疊加規則套用優惠的結果；Lines 與 Applied 的順序相同，
+// 為每個優惠實際折抵各項目（以項目 ID 為 key）的金額，LineDiscounts 為各項目的折扣總額
+type promotionResolution struct {
+	Applied       []*models.AppliedPromotion
+	Lines         []map[uint64]float64
+	LineDiscounts map[uint64]float64
+}
+
+// promotionCandidate 為過濾無效項目後的優惠，total 為單獨套用時的折扣總額
+type promotionCandidate struct {
+	promotion *models.Promotion
+	offered   map[uint64]float64
+	total     float64
+}
+
+// listPromotions 收集購物車項目在 at 時適用的所有優惠，包含分類折扣活動及各優惠來源
+func (s *service) listPromotions(ctx context.Context, cartModel *models.Cart, items []*models.CartItem, campaigns map[string]*models.ProductCampaign, at time.Time) ([]*models.Promotion, error) {
+	promotions := campaignPromotions(items, campaigns, cartModel.Currency)
+
+	for _, source := range s.promotionSources {
+		sourcePromotions, err := source.ListPromotions(ctx, cartModel, items, at)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list promotions: %w", err)
+		}
+		for _, promotion := range sourcePromotions {
+			if promotion != nil {
+				promotions = append(promotions, promotion)
+			}
+		}
+	}
+
+	return promotions, nil
+}
+
+// campaignPromotions 將商品適用的分類折扣活動轉換為優惠，同一活動的項目合併為一個優惠，折扣依幣別的小數位數四捨五入
+func campaignPromotions(items []*models.CartItem, campaigns map[string]*models.ProductCampaign, currency stripe.Currency) []*models.Promotion {
+	var promotions []*models.Promotion
+	byCampaign := make(map[uint64]*models.Promotion)
+
+	for _, item := range items {
+		productCampaign, ok := campaigns[item.ProductID]
+		if !ok {
+			continue
+		}
+
+		promotion, ok := byCampaign[productCampaign.CampaignID]
+		if !ok {
+			promotion = &models.Promotion{
+				Kind:          enum.PromotionKindCampaign,
+				Reference:     strconv.FormatUint(productCampaign.CampaignID, 10),
+				LineDiscounts: make(map[uint64]float64),
+			}
+			byCampaign[productCampaign.CampaignID] = promotion
+			promotions = append(promotions, promotion)
+		}
+		promotion.LineDiscounts[item.ID] = roundCurrencyAmount(item.Subtotal*productCampaign.PercentOff/100, currency)
+	}
+
+	return promotions
+}
+
+// resolve 依疊加規則決定套用的優惠。優惠依 Priority 由高到低、折扣總額由高到低、
+// 來源（活動、數量折扣、優惠券）與 Reference 排序，相同的輸入一定得到相同的結果；折扣依 currency 的小數位數四捨五入
+func (p PromotionStackingPolicy) resolve(items []*models.CartItem, promotions []*models.Promotion, currency stripe.Currency) *promotionResolution {
+	resolution := &promotionResolution{LineDiscounts: make(map[uint64]float64, len(items))}
+
+	// 1. 每個項目可折抵的上限
+	maxPercent := p.MaxDiscountPercent
+	if maxPercent <= 0 || maxPercent > 100 {
+		maxPercent = 100
+	}
+	remaining := make(map[uint64]float64, len(items))
+	for _, item := range items {
+		remaining[item.ID] = roundCurrencyAmount(item.Subtotal*maxPercent/100, currency)
+	}
+
+	// 2. 忽略不屬於購物車的項目與負數折扣，單一項目的折扣不超過其小計
+	candidates := make([]*promotionCandidate, 0, len(promotions))
+	for _, promotion := range promotions {
+		candidate := &promotionCandidate{promotion: promotion, offered: make(map[uint64]float64)}
+		for _, item := range items {
+			discount := roundCurrencyAmount(min(promotion.LineDiscounts[item.ID], item.Subtotal), currency)
+			if discount > 0 {
+				candidate.offered[item.ID] = discount
+				candidate.total += discount
+			}
+		}
+		if candidate.total > 0 {
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.promotion.Priority != b.promotion.Priority {
+			return a.promotion.Priority > b.promotion.Priority
+		}
+		if a.total != b.total {
+			return a.total > b.total
+		}
+		if promotionKindRank(a.promotion.Kind) != promotionKindRank(b.promotion.Kind) {
+			return promotionKindRank(a.promotion.Kind) < promotionKindRank(b.promotion.Kind)
+		}
+		return a.promotion.Reference < b.promotion.Reference
+	})
+
+	// 3. best_only 只保留受上限限制後折扣最高的優惠，相同時取排序在前者
+	if p.Mode == enum.PromotionStackingModeBestOnly {
+		var best *promotionCandidate
+		var bestTotal float64
+		for _, candidate := range candidates {
+			var total float64
+			for _, item := range items {
+				total += min(candidate.offered[item.ID], remaining[item.ID])
+			}
+			if total > bestTotal {
+				best, bestTotal = candidate, total
+			}
+		}
+		candidates = nil
+		if best != nil {
+			candidates = []*promotionCandidate{best}
+		}
+	}
+
+	// 4. 依序套用，同一互斥群組只套用第一個，已達上限的項目不再折抵
+	usedGroups := make(map[string]bool)
+	for _, candidate := range candidates {
+		if p.MaxPromotions > 0 && len(resolution.Applied) >= p.MaxPromotions {
+			break
+		}
+		group := candidate.promotion.ExclusivityGroup
+		if group != "" && usedGroups[group] {
+			continue
+		}
+
+		lines := make(map[uint64]float64)
+		var total float64
+		for _, item := range items {
+			discount := roundCurrencyAmount(min(candidate.offered[item.ID], remaining[item.ID]), currency)
+			if discount <= 0 {
+				continue
+			}
+			lines[item.ID] = discount
+			remaining[item.ID] = roundCurrencyAmount(remaining[item.ID]-discount, currency)
+			resolution.LineDiscounts[item.ID] = roundCurrencyAmount(resolution.LineDiscounts[item.ID]+discount, currency)
+			total += discount
+		}
+		if total <= 0 {
+			continue
+		}
+
+		if group != "" {
+			usedGroups[group] = true
+		}
+		resolution.Applied = append(resolution.Applied, &models.AppliedPromotion{
+			Kind:      candidate.promotion.Kind,
+			Reference: candidate.promotion.Reference,
+			Name:      candidate.promotion.Name,
+			Discount:  roundCurrencyAmount(total, currency),
+		})
+		resolution.Lines = append(resolution.Lines, lines)
+	}
+
+	return resolution
+}
+
+// promotionKindRank 為相同優先順序與折扣金額時的套用順序，商品層級的優惠先於整筆訂單的優惠
+func promotionKindRank(kind enum.PromotionKind) int {
+	switch kind {
+	case enum.PromotionKindCampaign:
+		return 0
+	case enum.PromotionKindQuantityBreak:
+		return 1
+	case enum.PromotionKindCoupon:
+		return 2
+	default:
+		return 3
+	}
+}
@@ -0,0 +1,192 @@
+package shop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"gofalre.io/shop/models"
+)
+
+// ErrQuotaExceeded 表示呼叫端在配額視窗內的請求數已達上限，呼叫端應稍後重試
+var ErrQuotaExceeded = errors.New("caller quota exceeded")
+
+// QuotaExceededError 呼叫端超過配額時回傳的錯誤，RetryAfter 為建議的重試等待時間，
+// 可用 errors.Is(err, ErrQuotaExceeded) 判斷並以 errors.As 取得配額與等待時間（例如轉為 HTTP 429 的 Retry-After）
+type QuotaExceededError struct {
+	Caller     string
+	Limit      int
+	Window     time.Duration
+	RetryAfter time.Duration
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("%s: %s is limited to %d requests per %s, retry after %s", ErrQuotaExceeded, e.Caller, e.Limit, e.Window, e.RetryAfter)
+}
+
+func (e *QuotaExceededError) Is(target error) bool {
+	return target == ErrQuotaExceeded
+}
+
+// CallerQuota 為呼叫端在 Window 內可以發出的請求數，Limit 為 0 表示不限制
+type CallerQuota struct {
+	Limit  int
+	Window time.Duration
+}
+
+// RateLimiter 以 key 計算滑動視窗內的請求數，例如 driver.RedisSlidingWindow；
+// Take 允許請求時回傳 true 與剩餘次數，否則回傳 false 與建議的重試等待時間
+type RateLimiter interface {
+	Take(ctx context.Context, key string, limit int, window time.Duration) (bool, int, time.Duration, error)
+}
+
+type callerContextKey struct{}
+
+// WithCaller 將呼叫端識別（例如 API key 的 ID）放入 ctx，設定 WithRateLimiter 時依此計算配額
+func WithCaller(ctx context.Context, caller string) context.Context {
+	return context.WithValue(ctx, callerContextKey{}, caller)
+}
+
+// CallerFromContext 取得 ctx 中的呼叫端識別，未設定時回傳空字串
+func CallerFromContext(ctx context.Context) string {
+	caller, _ := ctx.Value(callerContextKey{}).(string)
+	return caller
+}
+
+// WithRateLimiter 依呼叫端限制購物車與結帳 API 的請求數，quota 為未個別設定的呼叫端使用的配額；
+// ctx 中沒有呼叫端識別的請求（例如內部排程）不受限制
+func WithRateLimiter(limiter RateLimiter, quota CallerQuota) Option {
+	return func(s *service) {
+		if limiter == nil {
+			return
+		}
+		s.rateLimiter = limiter
+		s.defaultQuota = quota
+		if s.quotaUsage == nil {
+			s.quotaUsage = newQuotaUsageTracker()
+		}
+	}
+}
+
+// WithCallerQuota 設定個別呼叫端的配額，覆寫 WithRateLimiter 的預設配額；Limit 為 0 表示該呼叫端不受限制
+func WithCallerQuota(caller string, quota CallerQuota) Option {
+	return func(s *service) {
+		if s.callerQuotas == nil {
+			s.callerQuotas = make(map[string]CallerQuota)
+		}
+		s.callerQuotas[caller] = quota
+	}
+}
+
+// ConsumeQuota 為 ctx 中的呼叫端計入一次請求，超過配額時回傳 *QuotaExceededError；
+// 購物車與結帳 API 已自行計入，供 HTTP 或 gRPC 層在其他端點的 middleware 使用
+func (s *service) ConsumeQuota(ctx context.Context) error {
+	return s.enforceQuota(ctx)
+}
+
+// GetQuotaUsage 依呼叫端列出自服務啟動以來的配額使用量，只包含這個實例處理的請求；未設定 WithRateLimiter 時回傳 nil
+func (s *service) GetQuotaUsage(context.Context) []*models.QuotaUsage {
+	if s.quotaUsage == nil {
+		return nil
+	}
+	return s.quotaUsage.snapshot()
+}
+
+// enforceQuota 向 RateLimiter 計入 ctx 中呼叫端的一次請求；
+// limiter 本身無法使用時（例如 Redis 中斷）記錄警告後放行，避免限流元件故障造成 API 無法使用
+func (s *service) enforceQuota(ctx context.Context) error {
+	caller := CallerFromContext(ctx)
+	if s.rateLimiter == nil || caller == "" {
+		return nil
+	}
+
+	quota := s.callerQuota(caller)
+	if quota.Limit <= 0 || quota.Window <= 0 {
+		return nil
+	}
+
+	key := caller
+	if tenant := TenantFromContext(ctx); tenant != "" {
+		key = tenant + ":" + caller
+	}
+
+	ok, remaining, retryAfter, err := s.rateLimiter.Take(ctx, key, quota.Limit, quota.Window)
+	if err != nil {
+		s.log(ctx).Warn("Rate limiter unavailable, allowing request", zap.String("caller", caller), zap.Error(err))
+		return nil
+	}
+
+	s.quotaUsage.record(key, quota, ok, remaining)
+	if !ok {
+		s.log(ctx).Info("Request rejected by caller quota",
+			zap.String("caller", key), zap.Int("limit", quota.Limit), zap.Duration("retry_after", retryAfter))
+		return &QuotaExceededError{
+			Caller:     caller,
+			Limit:      quota.Limit,
+			Window:     quota.Window,
+			RetryAfter: retryAfter,
+		}
+	}
+
+	return nil
+}
+
+// callerQuota 回傳呼叫端的配額，未個別設定時使用預設配額
+func (s *service) callerQuota(caller string) CallerQuota {
+	if quota, ok := s.callerQuotas[caller]; ok {
+		return quota
+	}
+	return s.defaultQuota
+}
+
+// quotaUsageTracker 累計各呼叫端的配額使用量
+type quotaUsageTracker struct {
+	mu     sync.Mutex
+	usages map[string]*models.QuotaUsage
+}
+
+func newQuotaUsageTracker() *quotaUsageTracker {
+	return &quotaUsageTracker{usages: make(map[string]*models.QuotaUsage)}
+}
+
+// record 記錄一次請求的結果
+func (t *quotaUsageTracker) record(caller string, quota CallerQuota, allowed bool, remaining int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	usage, ok := t.usages[caller]
+	if !ok {
+		usage = &models.QuotaUsage{Caller: caller}
+		t.usages[caller] = usage
+	}
+	usage.Limit, usage.Window = quota.Limit, quota.Window
+	usage.LastSeenAt = time.Now()
+	if allowed {
+		usage.Allowed++
+		usage.Remaining = remaining
+	} else {
+		usage.Rejected++
+		usage.Remaining = 0
+	}
+}
+
+// snapshot 依呼叫端排序回傳目前使用量的複本
+func (t *quotaUsageTracker) snapshot() []*models.QuotaUsage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	usages := make([]*models.QuotaUsage, 0, len(t.usages))
+	for _, usage := range t.usages {
+		copied := *usage
+		usages = append(usages, &copied)
+	}
+	sort.Slice(usages, func(i, j int) bool {
+		return usages[i].Caller < usages[j].Caller
+	})
+	return usages
+}
@@ -0,0 +1,397 @@
+package shop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stripe/stripe-go/v79"
+	"go.uber.org/zap"
+
+	"gofalre.io/shop/models"
+	"gofalre.io/shop/models/enum"
+)
+
+// ErrRefundNotAllowed 表示退款政策不允許退款要求中的任何項目，各項目套用的規則可透過 EvaluateRefund 取得
+var ErrRefundNotAllowed = errors.New("refund is not allowed by the refund policy")
+
+// ErrPaymentRefunderNotConfigured 表示未設定 PaymentRefunder，無法向金流服務發起退款
+var ErrPaymentRefunderNotConfigured = errors.New("payment refunder is not configured")
+
+// RefundPolicy 退款規則：下單後 FullRefundDays 天內全額退款；之後的 RestockingFeeDays 天內退款須扣除 RestockingFeeRate（0.15 表示 15%）的重新上架費，
+// 超過期限不予退款。FinalSaleCategoryIDs 中的分類（包含子分類）為最終銷售商品，不可退款。Version 會記錄在每筆退款上供稽核，規則變更時應一併更新
+type RefundPolicy struct {
+	Version              string
+	FullRefundDays       int
+	RestockingFeeDays    int
+	RestockingFeeRate    float64
+	FinalSaleCategoryIDs []uint64
+}
+
+// DefaultRefundPolicy 為未設定退款政策時使用的規則：下單後 30 天內全額退款
+var DefaultRefundPolicy = RefundPolicy{
+	Version:        "default",
+	FullRefundDays: 30,
+}
+
+// rule 依下單後經過的時間回傳適用的規則及重新上架費比例
+func (p RefundPolicy) rule(age time.Duration) (enum.RefundRule, float64) {
+	days := age.Hours() / 24
+	switch {
+	case days <= float64(p.FullRefundDays):
+		return enum.RefundRuleFullRefund, 0
+	case days <= float64(p.FullRefundDays+p.RestockingFeeDays):
+		return enum.RefundRuleRestockingFee, p.RestockingFeeRate
+	default:
+		return enum.RefundRuleRefundWindowExpired, 0
+	}
+}
+
+// WithRefundPolicy 設定 RefundOrder 與退貨流程評估退款時使用的退款政策
+func WithRefundPolicy(policy RefundPolicy) Option {
+	return func(s *service) {
+		s.refundPolicy = policy
+	}
+}
+
+// PaymentRefunder 向金流服務發起退款並回傳退款 ID，IdempotencyKey 相同的要求必須回傳同一筆退款
+type PaymentRefunder interface {
+	RefundPayment(ctx context.Context, req PaymentRefundRequest) (string, error)
+}
+
+// PaymentRefundRequest 描述一筆要向金流服務發起的退款
+type PaymentRefundRequest struct {
+	OrderID         uint64
+	PaymentIntentID string
+	Amount          float64
+	Currency        stripe.Currency
+	Reason          string
+	IdempotencyKey  string
+}
+
+// WithPaymentRefunder 設定 RefundOrder 發起退款使用的金流服務
+func WithPaymentRefunder(refunder PaymentRefunder) Option {
+	return func(s *service) {
+		s.refunder = refunder
+	}
+}
+
+// refundableOrderStatuses 為可以發起退款的訂單狀態
+var refundableOrderStatuses = map[enum.OrderStatus]bool{
+	enum.OrderStatusPaid:              true,
+	enum.OrderStatusReadyForPickup:    true,
+	enum.OrderStatusCompleted:         true,
+	enum.OrderStatusPartiallyRefunded: true,
+}
+
+// EvaluateRefund 依目前的退款政策評估訂單項目可退款的金額，orderItemIDs 為空時評估整筆訂單；不會發起退款
+func (s *service) EvaluateRefund(ctx context.Context, orderID uint64, orderItemIDs []uint64) (*models.RefundEvaluation, error) {
+	var evaluation *models.RefundEvaluation
+
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		orderModel, err := s.order.GetOrder(ctx, tx, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to get order: %w", err)
+		}
+
+		evaluation, err = s.evaluateRefund(ctx, tx, orderModel, orderItemIDs, time.Now())
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return evaluation, nil
+}
+
+// RefundOrder 依退款政策退還訂單項目的款項，項目的加購服務一併退還；orderItemIDs 為空時退還整筆訂單中所有可退款的項目與訂單層級的服務；
+// 不可退款的項目會被略過，沒有任何項目可退款時回傳 ErrRefundNotAllowed。退款紀錄會保存套用的政策版本與各項目的規則
+func (s *service) RefundOrder(ctx context.Context, orderID uint64, orderItemIDs []uint64, reason string) (*models.Refund, error) {
+	if s.refunder == nil {
+		return nil, ErrPaymentRefunderNotConfigured
+	}
+
+	var refund *models.Refund
+	var creditNote *models.Invoice
+
+	if err := s.executeOrderTransaction(ctx, func(tx pgx.Tx) error {
+		// 1. 鎖定訂單並確認可以退款
+		orderModel, err := s.order.GetOrderForUpdate(ctx, tx, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to get order for update: %w", err)
+		}
+		if !refundableOrderStatuses[orderModel.Status] {
+			return fmt.Errorf("order %d is %s and cannot be refunded", orderID, orderModel.Status)
+		}
+		if orderModel.PaymentIntentID == "" {
+			return fmt.Errorf("order %d has no payment to refund", orderID)
+		}
+
+		// 2. 依退款政策評估要求退款的項目
+		evaluation, err := s.evaluateRefund(ctx, tx, orderModel, orderItemIDs, time.Now())
+		if err != nil {
+			return err
+		}
+		items := evaluation.RefundableItems()
+		if len(items) == 0 || evaluation.Amount <= 0 {
+			return fmt.Errorf("%w: order %d", ErrRefundNotAllowed, orderID)
+		}
+
+		// 3. 向金流服務發起退款，交易重試時以相同的 idempotency key 取回同一筆退款
+		refundID, err := s.refunder.RefundPayment(ctx, PaymentRefundRequest{
+			OrderID:         orderID,
+			PaymentIntentID: orderModel.PaymentIntentID,
+			Amount:          evaluation.Amount,
+			Currency:        orderModel.Currency,
+			Reason:          reason,
+			IdempotencyKey:  refundIdempotencyKey(orderID, items),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to refund payment: %w", err)
+		}
+
+		// 4. 記錄退款與套用的政策版本，並將訂單標記為退款中，退款結果由 Stripe 事件更新
+		refund = &models.Refund{
+			OrderID:        orderID,
+			StripeRefundID: refundID,
+			Currency:       orderModel.Currency,
+			Amount:         evaluation.Amount,
+			RestockingFee:  evaluation.RestockingFee,
+			Reason:         reason,
+			PolicyVersion:  evaluation.PolicyVersion,
+			Items:          items,
+		}
+		if err = s.order.CreateRefund(ctx, tx, refund); err != nil {
+			return fmt.Errorf("failed to create refund: %w", err)
+		}
+
+		// 已出貨項目的退款視為該數量已退回，尚未出貨的項目不受影響
+		for _, item := range items {
+			if item.OrderItemID == 0 {
+				continue
+			}
+			if err = s.order.AddOrderItemReturnedQuantity(ctx, tx, orderID, item.OrderItemID, item.Quantity); err != nil {
+				return fmt.Errorf("failed to add order item returned quantity: %w", err)
+			}
+		}
+
+		if err = s.order.SetOrderRefund(ctx, tx, orderID, refundID, enum.OrderStatusRefundPending, orderModel.UpdatedAt); err != nil {
+			return fmt.Errorf("failed to set order refund: %w", err)
+		}
+
+		// 5. 開立折讓單，文件在交易完成後產生
+		if creditNote, err = s.issueCreditNote(ctx, tx, orderModel, refund); err != nil {
+			return fmt.Errorf("failed to issue credit note: %w", err)
+		}
+
+		s.log(ctx).Info("Order refund requested",
+			zap.Uint64("order_id", orderID), zap.String("refund_id", refundID),
+			zap.Float64("amount", refund.Amount), zap.String("policy_version", refund.PolicyVersion))
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	s.renderInvoiceDocument(ctx, creditNote)
+	return refund, nil
+}
+
+// ListRefunds 回傳訂單的所有退款紀錄及各項目套用的規則
+func (s *service) ListRefunds(ctx context.Context, orderID uint64) ([]*models.Refund, error) {
+	var refunds []*models.Refund
+
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		var err error
+		refunds, err = s.order.ListRefunds(ctx, tx, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to list refunds: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return refunds, nil
+}
+
+// evaluateRefund 依退款政策判定訂單項目適用的規則與可退款金額，已退款的項目不會重複退款，總額不超過訂單尚未退還的金額
+func (s *service) evaluateRefund(ctx context.Context, tx pgx.Tx, orderModel *models.Order, orderItemIDs []uint64, now time.Time) (*models.RefundEvaluation, error) {
+	// 1. 獲取要求退款的項目
+	items, err := s.order.ListOrderItems(ctx, tx, orderModel.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list order items: %w", err)
+	}
+	if len(orderItemIDs) > 0 {
+		itemsByID := make(map[uint64]*models.OrderItem, len(items))
+		for _, item := range items {
+			itemsByID[item.ID] = item
+		}
+		requested := make([]*models.OrderItem, 0, len(orderItemIDs))
+		seen := make(map[uint64]bool, len(orderItemIDs))
+		for _, id := range orderItemIDs {
+			item, ok := itemsByID[id]
+			if !ok {
+				return nil, fmt.Errorf("order item %d does not belong to order %d", id, orderModel.ID)
+			}
+			// 重複的項目只評估一次
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			requested = append(requested, item)
+		}
+		items = requested
+	}
+
+	// 2. 獲取要求退款項目的加購服務，整筆訂單退款時一併包含訂單層級的服務
+	addons, err := s.order.ListOrderAddons(ctx, tx, orderModel.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list order addons: %w", err)
+	}
+	itemProducts := make(map[uint64]string, len(items))
+	for _, item := range items {
+		itemProducts[item.ID] = item.ProductID
+	}
+	requestedAddons := make([]*models.OrderAddon, 0, len(addons))
+	for _, addon := range addons {
+		if addon.OrderItemID == nil {
+			if len(orderItemIDs) == 0 {
+				requestedAddons = append(requestedAddons, addon)
+			}
+			continue
+		}
+		if _, ok := itemProducts[*addon.OrderItemID]; ok {
+			requestedAddons = append(requestedAddons, addon)
+		}
+	}
+
+	// 3. 獲取已退款的項目與金額
+	refunds, err := s.order.ListRefunds(ctx, tx, orderModel.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list refunds: %w", err)
+	}
+	refunded := make(map[uint64]bool)
+	refundedAddons := make(map[uint64]bool)
+	var refundedAmount float64
+	for _, refund := range refunds {
+		refundedAmount += refund.Amount
+		for _, item := range refund.Items {
+			if item.OrderAddonID != 0 {
+				refundedAddons[item.OrderAddonID] = true
+				continue
+			}
+			refunded[item.OrderItemID] = true
+		}
+	}
+
+	// 4. 找出屬於最終銷售分類的商品
+	policy := s.refundPolicy
+	finalSale := make(map[string]bool)
+	if len(policy.FinalSaleCategoryIDs) > 0 {
+		productIDs := make([]string, 0, len(items))
+		for _, item := range items {
+			productIDs = append(productIDs, item.ProductID)
+		}
+		finalSaleProducts, err := s.category.ListProductsInCategories(ctx, tx, productIDs, policy.FinalSaleCategoryIDs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list final sale products: %w", err)
+		}
+		for _, productID := range finalSaleProducts {
+			finalSale[productID] = true
+		}
+	}
+
+	// 5. 逐項套用規則，訂單折扣依小計比例分攤到各項目
+	rule, feeRate := policy.rule(now.Sub(orderModel.CreatedAt))
+	evaluation := &models.RefundEvaluation{
+		OrderID:       orderModel.ID,
+		PolicyVersion: policy.Version,
+		Currency:      orderModel.Currency,
+		Items:         make([]*models.RefundItem, 0, len(items)+len(requestedAddons)),
+		EvaluatedAt:   now,
+	}
+	var last *models.RefundItem
+	for _, item := range items {
+		refundItem := &models.RefundItem{
+			OrderItemID: item.ID,
+			Quantity:    item.Quantity,
+			Rule:        rule,
+		}
+		switch {
+		case refunded[item.ID]:
+			refundItem.Rule = enum.RefundRuleAlreadyRefunded
+		case finalSale[item.ProductID]:
+			refundItem.Rule = enum.RefundRuleFinalSale
+		}
+		evaluation.Items = append(evaluation.Items, refundItem)
+		if !refundItem.Refundable() {
+			continue
+		}
+
+		net := item.Subtotal
+		if orderModel.Subtotal > 0 {
+			net -= orderModel.Discount * item.Subtotal / orderModel.Subtotal
+		}
+		refundItem.RestockingFee = roundCurrency(net * feeRate)
+		refundItem.Amount = roundCurrency(net + item.TaxAmount - refundItem.RestockingFee)
+
+		evaluation.Amount += refundItem.Amount
+		evaluation.RestockingFee += refundItem.RestockingFee
+		last = refundItem
+	}
+
+	// 6. 加購服務沿用所屬項目的規則，不收重新上架費；訂單層級的服務沿用整筆訂單的規則
+	for _, addon := range requestedAddons {
+		refundItem := &models.RefundItem{
+			OrderAddonID: addon.ID,
+			Quantity:     addon.Quantity,
+			Rule:         rule,
+		}
+		switch {
+		case refundedAddons[addon.ID]:
+			refundItem.Rule = enum.RefundRuleAlreadyRefunded
+		case addon.OrderItemID != nil && finalSale[itemProducts[*addon.OrderItemID]]:
+			refundItem.Rule = enum.RefundRuleFinalSale
+		}
+		evaluation.Items = append(evaluation.Items, refundItem)
+		if !refundItem.Refundable() {
+			continue
+		}
+
+		net := addon.Subtotal
+		if orderModel.Subtotal > 0 {
+			net -= orderModel.Discount * addon.Subtotal / orderModel.Subtotal
+		}
+		refundItem.Amount = roundCurrency(net + addon.TaxAmount)
+
+		evaluation.Amount += refundItem.Amount
+		last = refundItem
+	}
+
+	// 7. 分攤的進位誤差可能讓總額超過訂單尚未退還的金額，差額由最後一個項目吸收
+	remaining := roundCurrency(orderModel.Total - refundedAmount)
+	if excess := roundCurrency(evaluation.Amount - remaining); excess > 0 && last != nil {
+		last.Amount = roundCurrency(max(last.Amount-excess, 0))
+		evaluation.Amount = max(remaining, 0)
+	}
+	evaluation.Amount = roundCurrency(evaluation.Amount)
+	evaluation.RestockingFee = roundCurrency(evaluation.RestockingFee)
+
+	return evaluation, nil
+}
+
+// refundIdempotencyKey 以訂單與退款項目產生 idempotency key，加購服務以 a 開頭與訂單項目區分
+func refundIdempotencyKey(orderID uint64, items []*models.RefundItem) string {
+	ids := make([]string, len(items))
+	for i, item := range items {
+		if item.OrderAddonID != 0 {
+			ids[i] = "a" + strconv.FormatUint(item.OrderAddonID, 10)
+			continue
+		}
+		ids[i] = strconv.FormatUint(item.OrderItemID, 10)
+	}
+	return fmt.Sprintf("order-%d-refund-%s", orderID, strings.Join(ids, "-"))
+}
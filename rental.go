@@ -0,0 +1,205 @@
+package shop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"gofalre.io/shop/models"
+	"gofalre.io/shop/models/enum"
+	"gofalre.io/shop/stock"
+)
+
+// maxRentalCalendarDays 為單次查詢或預約可涵蓋的最多天數
+const maxRentalCalendarDays = 366
+
+// ErrRentalUnavailable 表示預約期間內有某天的可租數量不足
+var ErrRentalUnavailable = errors.New("rental stock is not available for the requested dates")
+
+// SetStockRentalMode 切換庫存的租借模式，租借模式的庫存以日期區間預約，不能再透過購物車販售
+func (s *service) SetStockRentalMode(ctx context.Context, stockID uint64, enabled bool) error {
+	return s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		// 1. 鎖定庫存
+		stockModel, err := s.stock.LockStock(ctx, tx, stockID)
+		if err != nil {
+			return fmt.Errorf("failed to lock stock: %w", err)
+		}
+		if stockModel.RentalEnabled == enabled {
+			return nil
+		}
+
+		// 2. 檢查目前的佔用狀況：開啟時不可有購物車或保留中的預留庫存，關閉時不可有未結束的預約
+		if enabled && stockModel.ReservedQuantity > 0 {
+			return fmt.Errorf("stock %d has %d reserved units and cannot switch to rental mode", stockID, stockModel.ReservedQuantity)
+		}
+		if !enabled {
+			today := rentalDate(time.Now())
+			rentals, err := s.stock.ListStockRentals(ctx, tx, stockID, today, today.AddDate(100, 0, 0))
+			if err != nil {
+				return fmt.Errorf("failed to list stock rentals: %w", err)
+			}
+			if len(rentals) > 0 {
+				return fmt.Errorf("stock %d has %d open rentals and cannot leave rental mode", stockID, len(rentals))
+			}
+		}
+
+		// 3. 更新租借模式
+		ok, err := s.stock.SetStockRentalEnabled(ctx, tx, stockID, enabled)
+		if err != nil {
+			return fmt.Errorf("failed to set stock rental mode: %w", err)
+		}
+		if !ok {
+			return fmt.Errorf("stock %d not found", stockID)
+		}
+
+		return nil
+	})
+}
+
+// CreateRental 為客戶預約租借庫存，期間為 startsOn 至 endsOn（不含），同一庫存的預約在交易內序列化檢查以避免超租
+func (s *service) CreateRental(ctx context.Context, stockID uint64, customerID string, quantity uint64, startsOn, endsOn time.Time, note string) (*models.StockRental, error) {
+	startsOn, endsOn = rentalDate(startsOn), rentalDate(endsOn)
+	if quantity == 0 {
+		return nil, errors.New("rental quantity must be greater than zero")
+	}
+	if customerID == "" {
+		return nil, errors.New("rental customer is required")
+	}
+	if err := validateRentalRange(startsOn, endsOn); err != nil {
+		return nil, err
+	}
+	if startsOn.Before(rentalDate(time.Now())) {
+		return nil, errors.New("rental cannot start in the past")
+	}
+
+	var rental *models.StockRental
+
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		// 1. 鎖定庫存，讓同一庫存的預約依序檢查
+		stockModel, err := s.stock.LockStock(ctx, tx, stockID)
+		if err != nil {
+			return fmt.Errorf("failed to lock stock: %w", err)
+		}
+		if !stockModel.RentalEnabled {
+			return fmt.Errorf("stock %d is not in rental mode", stockID)
+		}
+
+		// 2. 檢查期間內每天的可租數量
+		days, err := s.stock.ListRentalBookings(ctx, tx, stockID, startsOn, endsOn)
+		if err != nil {
+			return fmt.Errorf("failed to list rental bookings: %w", err)
+		}
+		for _, day := range days {
+			if day.Booked+quantity > stockModel.Quantity {
+				return fmt.Errorf("%w: %s has %d of %d units booked", ErrRentalUnavailable, day.Date.Format(time.DateOnly), day.Booked, stockModel.Quantity)
+			}
+		}
+
+		// 3. 建立預約
+		rental, err = s.stock.CreateStockRental(ctx, tx, stock.CreateStockRentalParams{
+			StockID:    stockID,
+			CustomerID: customerID,
+			Quantity:   quantity,
+			StartsOn:   startsOn,
+			EndsOn:     endsOn,
+			Note:       note,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create stock rental: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return rental, nil
+}
+
+// CancelRental 取消租借預約並釋放期間內的庫存
+func (s *service) CancelRental(ctx context.Context, rentalID uint64) error {
+	return s.closeRental(ctx, rentalID, enum.StockRentalStatusCancelled)
+}
+
+// ReturnRental 標記租借已歸還，釋放剩餘期間的庫存
+func (s *service) ReturnRental(ctx context.Context, rentalID uint64) error {
+	return s.closeRental(ctx, rentalID, enum.StockRentalStatusReturned)
+}
+
+// ListRentals 列出與 from 至 to（不含）期間重疊的有效租借預約
+func (s *service) ListRentals(ctx context.Context, stockID uint64, from, to time.Time) ([]*models.StockRental, error) {
+	from, to = rentalDate(from), rentalDate(to)
+	if err := validateRentalRange(from, to); err != nil {
+		return nil, err
+	}
+
+	return s.stock.ListStockRentals(ctx, nil, stockID, from, to)
+}
+
+// GetRentalAvailability 回傳租借庫存在 from 至 to（不含）期間每天的可租數量，用於顯示預約日曆
+func (s *service) GetRentalAvailability(ctx context.Context, stockID uint64, from, to time.Time) ([]*models.RentalAvailability, error) {
+	from, to = rentalDate(from), rentalDate(to)
+	if err := validateRentalRange(from, to); err != nil {
+		return nil, err
+	}
+
+	stockModel, err := s.stock.GetStock(ctx, nil, stockID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stock: %w", err)
+	}
+	if !stockModel.RentalEnabled {
+		return nil, fmt.Errorf("stock %d is not in rental mode", stockID)
+	}
+
+	days, err := s.stock.ListRentalBookings(ctx, nil, stockID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rental bookings: %w", err)
+	}
+
+	for _, day := range days {
+		day.Quantity = stockModel.Quantity
+		if day.Booked < stockModel.Quantity {
+			day.Available = stockModel.Quantity - day.Booked
+		}
+	}
+
+	return days, nil
+}
+
+func (s *service) closeRental(ctx context.Context, rentalID uint64, status enum.StockRentalStatus) error {
+	return s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		rental, err := s.stock.GetStockRental(ctx, tx, rentalID)
+		if err != nil {
+			return fmt.Errorf("failed to get stock rental: %w", err)
+		}
+
+		ok, err := s.stock.UpdateStockRentalStatus(ctx, tx, rental.ID, status)
+		if err != nil {
+			return fmt.Errorf("failed to update stock rental status: %w", err)
+		}
+		if !ok {
+			return fmt.Errorf("stock rental %d is already %s", rental.ID, rental.Status)
+		}
+
+		return nil
+	})
+}
+
+// rentalDate 將時間截斷為 UTC 的日期，租借以整天為單位計算
+func rentalDate(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func validateRentalRange(from, to time.Time) error {
+	if !to.After(from) {
+		return errors.New("rental end date must be after the start date")
+	}
+	if to.Sub(from) > maxRentalCalendarDays*24*time.Hour {
+		return fmt.Errorf("rental date range cannot exceed %d days", maxRentalCalendarDays)
+	}
+	return nil
+}
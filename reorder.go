@@ -0,0 +1,263 @@
+package shop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"gofalre.io/shop/models"
+	"gofalre.io/shop/models/enum"
+)
+
+// ReorderFromOrder 將過去訂單的商品再次加入客戶的 active 購物車，價格與庫存以目前為準，並回報每個項目的處理結果；
+// Authorizer 拒絕時回傳 ErrForbidden
+func (s *service) ReorderFromOrder(ctx context.Context, orderID uint64) (*models.ReorderResult, error) {
+	result := new(models.ReorderResult)
+
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		// 1. 獲取原訂單並檢查權限，再獲取其項目
+		orderModel, err := s.order.GetOrder(ctx, tx, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to get order: %w", err)
+		}
+		if err = s.checkAuthorization(ctx, AuthorizationRequest{
+			Action:     ActionReorderOrder,
+			ResourceID: orderID,
+			CustomerID: orderModel.CustomerID,
+		}); err != nil {
+			return err
+		}
+
+		orderItems, err := s.order.ListOrderItems(ctx, tx, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to list order items: %w", err)
+		}
+
+		// 2. 取得或建立 active 購物車，已存在的購物車會與原訂單商品合併
+		cartModel, err := s.getOrCreateActiveCart(ctx, tx, orderModel.CustomerID, orderModel.Currency)
+		if err != nil {
+			return err
+		}
+		result.CartID = cartModel.ID
+
+		// 3. 以目前的價格與庫存重新解析每個項目，購物車內已有相同商品時必須沿用同一個庫存
+		cartItems := make([]*models.CartItem, 0, len(orderItems))
+		for _, orderItem := range orderItems {
+			var pinnedStockID uint64
+			existingItem, err := s.cart.GetCartItemByProductID(ctx, tx, cartModel.ID, orderItem.ProductID)
+			switch {
+			case err == nil:
+				pinnedStockID = existingItem.StockID
+			case !errors.Is(err, pgx.ErrNoRows):
+				return fmt.Errorf("failed to check existing cart item %s: %w", orderItem.ProductID, err)
+			}
+
+			line, cartItem, err := s.resolveReorderLine(ctx, tx, cartModel, orderItem, pinnedStockID)
+			if err != nil {
+				return err
+			}
+			result.Lines = append(result.Lines, line)
+			if cartItem != nil {
+				cartItems = append(cartItems, cartItem)
+			}
+		}
+
+		if len(cartItems) == 0 {
+			return nil
+		}
+
+		// 4. 加入購物車並預留庫存
+		return s.addItemsToCart(ctx, tx, cartModel.ID, cartItems)
+	}); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// CreateCartFromOrder 以客戶過去訂單的商品建立新的 active 購物車，價格與庫存以目前為準，購物車沿用訂單的銷售通路；
+// 缺貨或停售的項目不會加入並在結果中回報，沒有任何項目可加入時不建立購物車，結果的 CartID 為 0。
+// 購物車以「reorder-訂單 ID」命名，已有同名的 active 購物車時回傳 cart.ErrCartNameTaken；訂單不屬於該客戶時回傳 ErrOrderNotOwned
+func (s *service) CreateCartFromOrder(ctx context.Context, customerID string, orderID uint64) (*models.ReorderResult, error) {
+	if err := s.enforceQuota(ctx); err != nil {
+		return nil, err
+	}
+
+	var result *models.ReorderResult
+
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		result = new(models.ReorderResult)
+
+		// 1. 獲取原訂單並確認為客戶本人的訂單
+		orderModel, err := s.order.GetOrder(ctx, tx, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to get order: %w", err)
+		}
+		if orderModel.CustomerID != customerID {
+			return fmt.Errorf("%w: order %d", ErrOrderNotOwned, orderID)
+		}
+
+		orderItems, err := s.order.ListOrderItems(ctx, tx, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to list order items: %w", err)
+		}
+
+		// 2. 以新購物車的幣別與銷售通路重新解析每個項目
+		cartModel := &models.Cart{
+			CustomerID: customerID,
+			Currency:   orderModel.Currency,
+			Channel:    orderModel.Channel,
+		}
+		cartItems := make([]*models.CartItem, 0, len(orderItems))
+		for _, orderItem := range orderItems {
+			line, cartItem, err := s.resolveReorderLine(ctx, tx, cartModel, orderItem, 0)
+			if err != nil {
+				return err
+			}
+			result.Lines = append(result.Lines, line)
+			if cartItem != nil {
+				cartItems = append(cartItems, cartItem)
+			}
+		}
+
+		if len(cartItems) == 0 {
+			return nil
+		}
+
+		// 3. 建立購物車，客戶沒有預設購物車時成為預設購物車
+		hasDefault, err := s.hasDefaultCart(ctx, tx, customerID)
+		if err != nil {
+			return err
+		}
+		newCart, err := s.createCart(ctx, tx, customerID, fmt.Sprintf("reorder-%d", orderID), !hasDefault, orderModel.Currency)
+		if err != nil {
+			return err
+		}
+		if err = s.carryCartChannel(ctx, tx, newCart, orderModel.Channel); err != nil {
+			return err
+		}
+		result.CartID = newCart.ID
+
+		// 4. 加入購物車並預留庫存
+		return s.addItemsToCart(ctx, tx, newCart.ID, cartItems)
+	}); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// resolveReorderLine 決定訂單項目再次購買時的價格、出貨地點與數量，pinnedStockID 不為 0 時只能使用該庫存；
+// 缺貨或停售時回傳的 CartItem 為 nil
+func (s *service) resolveReorderLine(ctx context.Context, tx pgx.Tx, cartModel *models.Cart, orderItem *models.OrderItem, pinnedStockID uint64) (*models.ReorderLine, *models.CartItem, error) {
+	line := &models.ReorderLine{
+		OrderItemID:       orderItem.ID,
+		ProductID:         orderItem.ProductID,
+		PriceID:           orderItem.PriceID,
+		RequestedQuantity: orderItem.Quantity,
+		PreviousUnitPrice: orderItem.UnitPrice,
+		UnitPrice:         orderItem.UnitPrice,
+		PreviousLocation:  orderItem.Location,
+	}
+
+	// 1. 有銷售通路時商品須仍在通路目錄中，有通路價格時以通路價格為準
+	var hasChannelPrice bool
+	if cartModel.Channel != "" {
+		unitPrice, ok, err := s.channelUnitPrice(ctx, tx, cartModel, orderItem.ProductID, orderItem.PriceID)
+		switch {
+		case errors.Is(err, ErrProductNotInChannel):
+			line.Status = enum.ReorderLineStatusDiscontinued
+			return line, nil, nil
+		case err != nil:
+			return nil, nil, err
+		case ok:
+			line.UnitPrice = unitPrice
+			hasChannelPrice = true
+		}
+	}
+
+	// 2. 目前售價，沒有價格記錄時沿用原價
+	if !hasChannelPrice {
+		current, err := s.price.GetPriceInEffect(ctx, tx, orderItem.PriceID, time.Now())
+		switch {
+		case err == nil:
+			line.UnitPrice = current.UnitPrice
+		case !errors.Is(err, pgx.ErrNoRows):
+			return nil, nil, fmt.Errorf("failed to get current price for %s: %w", orderItem.PriceID, err)
+		}
+	}
+
+	// 3. 選擇出貨的庫存：優先原庫存，不足時改用其他可完整出貨的地點；租借模式的庫存不能加入購物車
+	stocks, err := s.stock.ListStocksByProductID(ctx, tx, orderItem.ProductID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list stocks for %s: %w", orderItem.ProductID, err)
+	}
+
+	var sellable int
+	var original, substitute *models.Stock
+	for _, stockModel := range stocks {
+		if stockModel.RentalEnabled {
+			continue
+		}
+		sellable++
+		if pinnedStockID != 0 && stockModel.ID != pinnedStockID {
+			continue
+		}
+		if stockModel.ID == orderItem.StockID || pinnedStockID != 0 {
+			original = stockModel
+			continue
+		}
+		if substitute == nil && availableQuantity(stockModel) >= orderItem.Quantity {
+			substitute = stockModel
+		}
+	}
+
+	// 沒有任何可販售的庫存表示商品已停售
+	if sellable == 0 {
+		line.Status = enum.ReorderLineStatusDiscontinued
+		return line, nil, nil
+	}
+
+	chosen := original
+	line.Status = enum.ReorderLineStatusAdded
+	switch {
+	case original != nil && availableQuantity(original) >= orderItem.Quantity:
+		line.Quantity = orderItem.Quantity
+	case substitute != nil:
+		chosen = substitute
+		line.Status = enum.ReorderLineStatusSubstituted
+		line.Quantity = orderItem.Quantity
+	case original != nil && availableQuantity(original) > 0:
+		line.Status = enum.ReorderLineStatusPartial
+		line.Quantity = availableQuantity(original)
+	default:
+		line.Status = enum.ReorderLineStatusOutOfStock
+		return line, nil, nil
+	}
+	line.Location = chosen.Location
+
+	return line, &models.CartItem{
+		CartID:    cartModel.ID,
+		ProductID: orderItem.ProductID,
+		PriceID:   orderItem.PriceID,
+		StockID:   chosen.ID,
+		Quantity:  line.Quantity,
+		UnitPrice: line.UnitPrice,
+		Subtotal:  float64(line.Quantity) * line.UnitPrice,
+
+		Customization: orderItem.Customization,
+		Note:          orderItem.Note,
+		Metadata:      orderItem.Metadata,
+	}, nil
+}
+
+// availableQuantity 回傳庫存扣除預留後的可用數量
+func availableQuantity(stockModel *models.Stock) uint64 {
+	if stockModel.ReservedQuantity >= stockModel.Quantity {
+		return 0
+	}
+	return stockModel.Quantity - stockModel.ReservedQuantity
+}
@@ -0,0 +1,121 @@
+package shop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stripe/stripe-go/v79"
+	"go.uber.org/zap"
+
+	"gofalre.io/shop/models"
+)
+
+// ErrReportingCurrencyNotConfigured 表示尚未以 WithReportingCurrency 設定報表幣別
+var ErrReportingCurrencyNotConfigured = errors.New("reporting currency is not configured")
+
+// ExchangeRateProvider 提供幣別之間的匯率，回傳 1 單位 from 等於多少 to
+type ExchangeRateProvider interface {
+	ExchangeRate(ctx context.Context, from, to stripe.Currency) (float64, error)
+}
+
+// WithReportingCurrency 設定報表幣別與匯率來源，訂單成立時會記錄換算成報表幣別的匯率快照
+func WithReportingCurrency(currency stripe.Currency, rates ExchangeRateProvider) Option {
+	return func(s *service) {
+		if currency != "" && rates != nil {
+			s.reportingCurrency = currency
+			s.exchangeRates = rates
+		}
+	}
+}
+
+// recordReportingSnapshot 以訂單目前的金額記錄換算成報表幣別的快照；
+// 取得匯率失敗只記錄警告，不影響下單，該訂單在報表中會列為未換算
+func (s *service) recordReportingSnapshot(ctx context.Context, tx pgx.Tx, order *models.Order) error {
+	if s.reportingCurrency == "" {
+		return nil
+	}
+
+	rate := 1.0
+	if order.Currency != s.reportingCurrency {
+		var err error
+		rate, err = s.exchangeRates.ExchangeRate(ctx, order.Currency, s.reportingCurrency)
+		if err != nil || rate <= 0 {
+			s.log(ctx).Warn("Failed to get exchange rate for order",
+				zap.Uint64("order_id", order.ID),
+				zap.String("from", string(order.Currency)),
+				zap.String("to", string(s.reportingCurrency)),
+				zap.Float64("rate", rate),
+				zap.Error(err))
+			return nil
+		}
+	}
+
+	reporting := &models.ReportingAmounts{
+		Currency:     s.reportingCurrency,
+		ExchangeRate: rate,
+		Subtotal:     roundCurrency(order.Subtotal * rate),
+		Tax:          roundCurrency(order.Tax * rate),
+		Discount:     roundCurrency(order.Discount * rate),
+		Total:        roundCurrency(order.Total * rate),
+	}
+	if err := s.order.SetOrderReportingSnapshot(ctx, tx, order.ID, reporting); err != nil {
+		return fmt.Errorf("failed to set order reporting snapshot: %w", err)
+	}
+	order.Reporting = reporting
+
+	return nil
+}
+
+// GetSalesReport 以報表幣別彙整 from 至 to（不含）期間成立的訂單金額，並列出各訂單幣別的原始與換算後金額
+func (s *service) GetSalesReport(ctx context.Context, from, to time.Time) (*models.SalesReport, error) {
+	if s.reportingCurrency == "" {
+		return nil, ErrReportingCurrencyNotConfigured
+	}
+	if !to.After(from) {
+		return nil, errors.New("report range must end after it starts")
+	}
+
+	report := &models.SalesReport{
+		Currency: s.reportingCurrency,
+		From:     from,
+		To:       to,
+	}
+
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		var err error
+		report.ByCurrency, err = s.order.GetSalesReport(ctx, tx, from, to)
+		if err != nil {
+			return fmt.Errorf("failed to get sales report: %w", err)
+		}
+		report.ByChannel, err = s.order.GetChannelSalesReport(ctx, tx, from, to)
+		if err != nil {
+			return fmt.Errorf("failed to get channel sales report: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	for _, sales := range report.ByCurrency {
+		report.Orders += sales.Orders
+		// 以其他報表幣別換算的快照無法直接加總
+		if sales.ReportingCurrency != s.reportingCurrency {
+			report.UnconvertedOrders += sales.Orders
+			continue
+		}
+		report.UnconvertedOrders += sales.Orders - sales.ConvertedOrders
+		report.Subtotal += sales.ReportingSubtotal
+		report.Tax += sales.ReportingTax
+		report.Discount += sales.ReportingDiscount
+		report.Total += sales.ReportingTotal
+	}
+	report.Subtotal = roundCurrency(report.Subtotal)
+	report.Tax = roundCurrency(report.Tax)
+	report.Discount = roundCurrency(report.Discount)
+	report.Total = roundCurrency(report.Total)
+
+	return report, nil
+}
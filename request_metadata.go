@@ -0,0 +1,111 @@
+package shop
+
+import (
+	"context"
+
+	"github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+)
+
+// 發佈 NATS 訊息時攜帶請求資訊的 header 名稱
+const (
+	HeaderRequestID = "X-Request-Id"
+	HeaderActor     = "X-Actor"
+	HeaderTenant    = "X-Tenant"
+)
+
+// RequestMetadata 為隨 context 傳遞的請求資訊，會帶入發佈的 NATS 訊息與每一筆日誌
+type RequestMetadata struct {
+	RequestID string
+	Actor     string
+	Tenant    string
+}
+
+type (
+	requestIDContextKey struct{}
+	actorContextKey     struct{}
+)
+
+// ContextWithRequestID 回傳帶有請求 ID 的 context
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey{}, requestID)
+}
+
+// ContextWithActor 回傳帶有操作人員的 context
+func ContextWithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorContextKey{}, actor)
+}
+
+// ContextWithRequestMetadata 回傳帶有完整請求資訊的 context，租戶與功能開關共用 WithTenant 的設定
+func ContextWithRequestMetadata(ctx context.Context, md RequestMetadata) context.Context {
+	if md.RequestID != "" {
+		ctx = ContextWithRequestID(ctx, md.RequestID)
+	}
+	if md.Actor != "" {
+		ctx = ContextWithActor(ctx, md.Actor)
+	}
+	if md.Tenant != "" {
+		ctx = WithTenant(ctx, md.Tenant)
+	}
+	return ctx
+}
+
+// RequestMetadataFromContext 取得 context 中的請求資訊，未設定的欄位為空字串
+func RequestMetadataFromContext(ctx context.Context) RequestMetadata {
+	requestID, _ := ctx.Value(requestIDContextKey{}).(string)
+	actor, _ := ctx.Value(actorContextKey{}).(string)
+	return RequestMetadata{
+		RequestID: requestID,
+		Actor:     actor,
+		Tenant:    TenantFromContext(ctx),
+	}
+}
+
+// LoggerFromContext 回傳附加了 context 中請求資訊的 logger，沒有請求資訊時直接回傳 logger
+func LoggerFromContext(ctx context.Context, logger *zap.Logger) *zap.Logger {
+	md := RequestMetadataFromContext(ctx)
+
+	fields := make([]zap.Field, 0, 3)
+	if md.RequestID != "" {
+		fields = append(fields, zap.String("request_id", md.RequestID))
+	}
+	if md.Actor != "" {
+		fields = append(fields, zap.String("actor", md.Actor))
+	}
+	if md.Tenant != "" {
+		fields = append(fields, zap.String("tenant", md.Tenant))
+	}
+	if len(fields) == 0 {
+		return logger
+	}
+
+	return logger.With(fields...)
+}
+
+// log 回傳附加了請求資訊的 service logger
+func (s *service) log(ctx context.Context) *zap.Logger {
+	return LoggerFromContext(ctx, s.logger)
+}
+
+// injectRequestMetadata 將 context 中的請求資訊寫入 NATS header
+func injectRequestMetadata(ctx context.Context, header nats.Header) {
+	md := RequestMetadataFromContext(ctx)
+	if md.RequestID != "" {
+		header.Set(HeaderRequestID, md.RequestID)
+	}
+	if md.Actor != "" {
+		header.Set(HeaderActor, md.Actor)
+	}
+	if md.Tenant != "" {
+		header.Set(HeaderTenant, md.Tenant)
+	}
+}
+
+// extractRequestMetadata 從 NATS header 讀取請求資訊並帶入 context
+func extractRequestMetadata(ctx context.Context, header nats.Header) context.Context {
+	return ContextWithRequestMetadata(ctx, RequestMetadata{
+		RequestID: header.Get(HeaderRequestID),
+		Actor:     header.Get(HeaderActor),
+		Tenant:    header.Get(HeaderTenant),
+	})
+}
@@ -0,0 +1,154 @@
+package shop
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+
+	"gofalre.io/shop/models"
+	"gofalre.io/shop/models/enum"
+)
+
+// RevenueAccounts 營收認列分錄使用的會計科目代號，需與匯入的 ERP 科目表一致
+type RevenueAccounts struct {
+	Receivable   string // 應收帳款或金流清算帳戶
+	Sales        string // 銷售收入
+	Discounts    string // 銷貨折讓
+	SalesReturns string // 銷貨退回
+	TaxPayable   string // 應付稅額
+}
+
+// DefaultRevenueAccounts 為未設定科目時使用的科目代號
+var DefaultRevenueAccounts = RevenueAccounts{
+	Receivable:   "1100",
+	Sales:        "4000",
+	Discounts:    "4050",
+	SalesReturns: "4100",
+	TaxPayable:   "2200",
+}
+
+// WithRevenueAccounts 設定 ExportJournalEntries 使用的會計科目
+func WithRevenueAccounts(accounts RevenueAccounts) Option {
+	return func(s *service) {
+		s.revenueAccounts = accounts
+	}
+}
+
+// journalExportHeader 為分錄匯出 CSV 的欄位
+var journalExportHeader = []string{
+	"journal_id", "date", "account", "debit", "credit", "currency", "order_number", "description",
+}
+
+// revenueOrderStatuses 為認列銷售收入的訂單狀態，已退款的訂單仍認列銷售，退款另以退款分錄沖減
+var revenueOrderStatuses = []enum.OrderStatus{
+	enum.OrderStatusPaid,
+	enum.OrderStatusReadyForPickup,
+	enum.OrderStatusCompleted,
+	enum.OrderStatusRefundPending,
+	enum.OrderStatusPartiallyRefunded,
+	enum.OrderStatusRefunded,
+}
+
+// ExportJournalEntries 將 [from, to) 期間的銷售與退款以分錄格式寫成 CSV，第一列為欄位名稱；
+// 每筆銷售與退款各為一組借貸平衡的分錄，金額為訂單幣別，日期為 UTC。
+// 目前沒有禮品卡與購物金的帳本，因此不產生相關的負債分錄
+func (s *service) ExportJournalEntries(ctx context.Context, from, to time.Time, w io.Writer) error {
+	if from.IsZero() || to.IsZero() || !from.Before(to) {
+		return fmt.Errorf("invalid export period: %s to %s", from.Format(time.RFC3339), to.Format(time.RFC3339))
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(journalExportHeader); err != nil {
+		return fmt.Errorf("failed to write journal export header: %w", err)
+	}
+
+	var sales, refunds int
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		return s.order.StreamRevenueEvents(ctx, tx, from, to, revenueOrderStatuses, func(event *models.RevenueEvent) error {
+			if event.Type == enum.RevenueEventTypeRefund {
+				refunds++
+			} else {
+				sales++
+			}
+
+			for _, line := range s.journalLines(event) {
+				if err := writer.Write([]string{
+					line.JournalID,
+					line.Date.UTC().Format(time.DateOnly),
+					line.Account,
+					strconv.FormatFloat(line.Debit, 'f', 2, 64),
+					strconv.FormatFloat(line.Credit, 'f', 2, 64),
+					string(line.Currency),
+					line.OrderNumber,
+					line.Description,
+				}); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}); err != nil {
+		return fmt.Errorf("failed to export journal entries: %w", err)
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write journal export: %w", err)
+	}
+
+	s.log(ctx).Info("Exported journal entries",
+		zap.Time("from", from), zap.Time("to", to), zap.Int("sales", sales), zap.Int("refunds", refunds))
+
+	return nil
+}
+
+// journalLines 將銷售或退款轉換為借貸平衡的分錄，金額為 0 的行會略過
+func (s *service) journalLines(event *models.RevenueEvent) []*models.JournalLine {
+	accounts := s.revenueAccounts
+
+	journalID := "SALE-" + event.OrderNumber
+	description := "Sale for order " + event.OrderNumber
+	if event.Type == enum.RevenueEventTypeRefund {
+		journalID = fmt.Sprintf("REFUND-%d", event.RefundID)
+		description = fmt.Sprintf("Refund %d for order %s", event.RefundID, event.OrderNumber)
+	}
+
+	lines := make([]*models.JournalLine, 0, 4)
+	add := func(account string, debit, credit float64) {
+		debit, credit = roundCurrency(debit), roundCurrency(credit)
+		if debit == 0 && credit == 0 {
+			return
+		}
+		lines = append(lines, &models.JournalLine{
+			JournalID:   journalID,
+			Date:        event.OccurredAt,
+			Account:     account,
+			Debit:       debit,
+			Credit:      credit,
+			Currency:    event.Currency,
+			OrderNumber: event.OrderNumber,
+			Description: description,
+		})
+	}
+
+	if event.Type == enum.RevenueEventTypeRefund {
+		// 借：銷貨退回、應付稅額；貸：應收帳款
+		add(accounts.SalesReturns, event.Subtotal, 0)
+		add(accounts.TaxPayable, event.Tax, 0)
+		add(accounts.Receivable, 0, event.Total)
+		return lines
+	}
+
+	// 借：應收帳款、銷貨折讓；貸：銷售收入、應付稅額
+	add(accounts.Receivable, event.Total, 0)
+	add(accounts.Discounts, event.Discount, 0)
+	add(accounts.Sales, 0, event.Subtotal)
+	add(accounts.TaxPayable, 0, event.Tax)
+	return lines
+}
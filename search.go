@@ -0,0 +1,422 @@
+package shop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+
+	"gofalre.io/shop/category"
+	"gofalre.io/shop/models"
+	"gofalre.io/shop/models/enum"
+	"gofalre.io/shop/order"
+)
+
+const (
+	// defaultSearchLimit 為未指定 limit 時每頁的搜尋結果數
+	defaultSearchLimit = 20
+	// maxSearchLimit 為每頁搜尋結果數的上限
+	maxSearchLimit = 100
+	// searchReindexBatchSize 為重建索引時每次寫入的文件數
+	searchReindexBatchSize = 200
+)
+
+// ErrSearchTextRequired 表示搜尋文字為空
+var ErrSearchTextRequired = errors.New("search text is required")
+
+// SearchIndexer 提供訂單與商品的搜尋。預設以 Postgres ILIKE 查詢資料庫，不需要另外維護索引；
+// 需要容錯拼字或大量資料的部署可改用 OpenSearch 等搜尋引擎（driver.OpenSearchIndexer），
+// 索引會在異動訂單或商品翻譯的交易 commit 後更新，更新失敗只記錄錯誤，可以 ReindexSearch 重建。
+// 刪除不存在的文件應回傳 nil
+type SearchIndexer interface {
+	IndexOrders(ctx context.Context, docs []*models.OrderDocument) error
+	DeleteOrders(ctx context.Context, ids []string) error
+	SearchOrders(ctx context.Context, query models.SearchQuery) (*models.SearchHits, error)
+	IndexProducts(ctx context.Context, docs []*models.ProductDocument) error
+	DeleteProducts(ctx context.Context, ids []string) error
+	SearchProducts(ctx context.Context, query models.SearchQuery) (*models.SearchHits, error)
+}
+
+// WithSearchIndexer 設定搜尋訂單與商品使用的搜尋引擎
+func WithSearchIndexer(indexer SearchIndexer) Option {
+	return func(s *service) {
+		s.searchIndexer = indexer
+	}
+}
+
+// setupSearchIndexer 未設定 SearchIndexer 時使用 Postgres 查詢；使用外部搜尋引擎時包裝 order 與 category repository，
+// 在交易 commit 後將異動的訂單與商品翻譯寫入索引
+func (s *service) setupSearchIndexer() {
+	if s.searchIndexer == nil {
+		s.searchIndexer = &postgresSearchIndexer{order: s.order, category: s.category}
+		return
+	}
+
+	s.order = &searchIndexedOrders{Repository: s.order, s: s}
+	s.category = &searchIndexedCategories{Repository: s.category, s: s}
+}
+
+// SearchOrders 以訂單編號、客戶 ID、商品名稱或 metadata 搜尋訂單；
+// 索引中已不存在於資料庫的訂單會略過，因此本頁的筆數可能少於 limit
+func (s *service) SearchOrders(ctx context.Context, query models.SearchQuery) (*models.Page[*models.Order], error) {
+	query, err := normalizeSearchQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. 從搜尋引擎取得符合的訂單 ID
+	hits, err := s.searchIndexer.SearchOrders(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search orders: %w", err)
+	}
+
+	// 2. 依搜尋結果的順序讀取訂單
+	orders := make([]*models.Order, 0, len(hits.IDs))
+	for _, id := range hits.IDs {
+		orderID, err := strconv.ParseUint(id, 10, 64)
+		if err != nil {
+			s.log(ctx).Warn("Invalid order search hit", zap.String("id", id))
+			continue
+		}
+		orderModel, err := s.order.GetOrder(ctx, nil, orderID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get order: %w", err)
+		}
+		if orderModel.DeletedAt != nil {
+			continue
+		}
+		orders = append(orders, orderModel)
+	}
+
+	return models.NewPage(orders, hits.TotalCount, query.Limit, query.Offset), nil
+}
+
+// SearchProducts 以商品翻譯的名稱與描述搜尋商品，回傳商品 ID；query.Locale 為空時搜尋所有語系
+func (s *service) SearchProducts(ctx context.Context, query models.SearchQuery) (*models.Page[string], error) {
+	query, err := normalizeSearchQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	if query.Locale != "" {
+		query.Locale = models.NormalizeLocale(query.Locale)
+	}
+
+	hits, err := s.searchIndexer.SearchProducts(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search products: %w", err)
+	}
+
+	return models.NewPage(hits.IDs, hits.TotalCount, query.Limit, query.Offset), nil
+}
+
+// ReindexSearch 將所有訂單與商品翻譯重新寫入搜尋引擎，用於第一次啟用、更換搜尋引擎或索引更新失敗後；
+// 重建期間的異動仍會在 commit 後更新索引。使用預設的 Postgres 查詢時不需要索引，直接回傳 0。
+// 回傳寫入的文件數
+func (s *service) ReindexSearch(ctx context.Context) (int, error) {
+	if _, ok := s.searchIndexer.(*postgresSearchIndexer); ok {
+		return 0, nil
+	}
+
+	orders, err := s.reindexOrders(ctx)
+	if err != nil {
+		return orders, err
+	}
+
+	products, err := s.reindexProducts(ctx)
+	return orders + products, err
+}
+
+// reindexOrders 逐批將所有訂單寫入搜尋引擎，已刪除的訂單從索引移除
+func (s *service) reindexOrders(ctx context.Context) (int, error) {
+	indexed := 0
+	docs := make([]*models.OrderDocument, 0, searchReindexBatchSize)
+	var deleted []string
+
+	flush := func() error {
+		if err := s.searchIndexer.IndexOrders(ctx, docs); err != nil {
+			return fmt.Errorf("failed to index orders: %w", err)
+		}
+		if err := s.searchIndexer.DeleteOrders(ctx, deleted); err != nil {
+			return fmt.Errorf("failed to delete orders from index: %w", err)
+		}
+		indexed += len(docs)
+		docs, deleted = docs[:0], deleted[:0]
+		return nil
+	}
+
+	err := s.order.StreamOrders(ctx, nil, models.OrderFilter{}, func(orderModel *models.Order) error {
+		if orderModel.DeletedAt != nil {
+			deleted = append(deleted, models.OrderDocumentID(orderModel.ID))
+		} else {
+			items, err := s.order.ListOrderItems(ctx, nil, orderModel.ID)
+			if err != nil {
+				return fmt.Errorf("failed to list order items: %w", err)
+			}
+			docs = append(docs, models.NewOrderDocument(orderModel, items))
+		}
+
+		if len(docs)+len(deleted) < searchReindexBatchSize {
+			return nil
+		}
+		return flush()
+	})
+	if err != nil {
+		return indexed, fmt.Errorf("failed to stream orders: %w", err)
+	}
+	if err := flush(); err != nil {
+		return indexed, err
+	}
+
+	return indexed, nil
+}
+
+// reindexProducts 依商品 ID 與語系的順序逐批將所有商品翻譯寫入搜尋引擎
+func (s *service) reindexProducts(ctx context.Context) (int, error) {
+	indexed := 0
+	var afterProductID, afterLocale string
+
+	for {
+		translations, err := s.category.ListProductTranslationsAfter(ctx, nil, afterProductID, afterLocale, searchReindexBatchSize)
+		if err != nil {
+			return indexed, fmt.Errorf("failed to list product translations: %w", err)
+		}
+		if len(translations) == 0 {
+			return indexed, nil
+		}
+
+		docs := make([]*models.ProductDocument, 0, len(translations))
+		for _, translation := range translations {
+			docs = append(docs, models.NewProductDocument(translation))
+		}
+		if err := s.searchIndexer.IndexProducts(ctx, docs); err != nil {
+			return indexed, fmt.Errorf("failed to index products: %w", err)
+		}
+		indexed += len(docs)
+
+		last := translations[len(translations)-1]
+		afterProductID, afterLocale = last.ProductID, last.Locale
+	}
+}
+
+// indexOrderAfterCommit 在 tx commit 後將訂單目前的內容寫入搜尋引擎，同一個交易中只更新一次
+func (s *service) indexOrderAfterCommit(ctx context.Context, tx pgx.Tx, orderID uint64) {
+	ctx = context.WithoutCancel(ctx)
+	s.transactionManager.AfterCommit(tx, "search:order:"+models.OrderDocumentID(orderID), func() {
+		if err := s.syncOrderSearch(ctx, orderID); err != nil {
+			s.log(ctx).Error("Failed to update order search index", zap.Uint64("order_id", orderID), zap.Error(err))
+		}
+	})
+}
+
+// syncOrderSearch 重新讀取訂單並寫入搜尋引擎，訂單不存在或已刪除時從索引移除
+func (s *service) syncOrderSearch(ctx context.Context, orderID uint64) error {
+	orderModel, err := s.order.GetOrder(ctx, nil, orderID)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && orderModel.DeletedAt != nil) {
+		return s.searchIndexer.DeleteOrders(ctx, []string{models.OrderDocumentID(orderID)})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get order: %w", err)
+	}
+
+	items, err := s.order.ListOrderItems(ctx, nil, orderID)
+	if err != nil {
+		return fmt.Errorf("failed to list order items: %w", err)
+	}
+
+	return s.searchIndexer.IndexOrders(ctx, []*models.OrderDocument{models.NewOrderDocument(orderModel, items)})
+}
+
+// indexProductAfterCommit 在 tx commit 後將商品翻譯寫入搜尋引擎，doc 為 nil 時從索引移除 productID 在 locale 的翻譯
+func (s *service) indexProductAfterCommit(ctx context.Context, tx pgx.Tx, productID, locale string, doc *models.ProductDocument) {
+	ctx = context.WithoutCancel(ctx)
+	id := models.ProductDocumentID(productID, locale)
+	s.transactionManager.AfterCommit(tx, "search:product:"+id, func() {
+		var err error
+		if doc != nil {
+			err = s.searchIndexer.IndexProducts(ctx, []*models.ProductDocument{doc})
+		} else {
+			err = s.searchIndexer.DeleteProducts(ctx, []string{id})
+		}
+		if err != nil {
+			s.log(ctx).Error("Failed to update product search index",
+				zap.String("product_id", productID), zap.String("locale", locale), zap.Error(err))
+		}
+	})
+}
+
+// normalizeSearchQuery 去除搜尋文字前後的空白並套用預設與最大的 limit
+func normalizeSearchQuery(query models.SearchQuery) (models.SearchQuery, error) {
+	query.Text = strings.TrimSpace(query.Text)
+	if query.Text == "" {
+		return query, ErrSearchTextRequired
+	}
+	if query.Limit == 0 {
+		query.Limit = defaultSearchLimit
+	}
+	query.Limit = min(query.Limit, maxSearchLimit)
+	return query, nil
+}
+
+// postgresSearchIndexer 為預設的搜尋實作，直接以 ILIKE 查詢資料庫，因此不需要寫入索引
+type postgresSearchIndexer struct {
+	order    order.Repository
+	category category.Repository
+}
+
+func (p *postgresSearchIndexer) IndexOrders(context.Context, []*models.OrderDocument) error {
+	return nil
+}
+
+func (p *postgresSearchIndexer) DeleteOrders(context.Context, []string) error {
+	return nil
+}
+
+// SearchOrders 依建立時間由新到舊列出符合的訂單
+func (p *postgresSearchIndexer) SearchOrders(ctx context.Context, query models.SearchQuery) (*models.SearchHits, error) {
+	pattern := likePattern(query.Text)
+
+	ids, err := p.order.SearchOrderIDs(ctx, nil, pattern, query.Limit, query.Offset)
+	if err != nil {
+		return nil, err
+	}
+	total, err := p.order.CountSearchOrders(ctx, nil, pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	hits := &models.SearchHits{IDs: make([]string, 0, len(ids)), TotalCount: total}
+	for _, id := range ids {
+		hits.IDs = append(hits.IDs, models.OrderDocumentID(id))
+	}
+	return hits, nil
+}
+
+func (p *postgresSearchIndexer) IndexProducts(context.Context, []*models.ProductDocument) error {
+	return nil
+}
+
+func (p *postgresSearchIndexer) DeleteProducts(context.Context, []string) error {
+	return nil
+}
+
+// SearchProducts 依商品 ID 排序列出符合的商品
+func (p *postgresSearchIndexer) SearchProducts(ctx context.Context, query models.SearchQuery) (*models.SearchHits, error) {
+	pattern := likePattern(query.Text)
+
+	ids, err := p.category.SearchProductIDs(ctx, nil, query.Locale, pattern, query.Limit, query.Offset)
+	if err != nil {
+		return nil, err
+	}
+	total, err := p.category.CountSearchProducts(ctx, nil, query.Locale, pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.SearchHits{IDs: ids, TotalCount: total}, nil
+}
+
+// likePattern 將搜尋文字轉為部分比對的 LIKE 樣式，跳脫文字中的萬用字元
+func likePattern(text string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(text)
+	return "%" + escaped + "%"
+}
+
+// searchIndexedOrders 在異動訂單的交易 commit 後更新訂單的搜尋索引
+type searchIndexedOrders struct {
+	order.Repository
+	s *service
+}
+
+func (r *searchIndexedOrders) CreateOrder(ctx context.Context, tx pgx.Tx, orderModel *models.Order) (*models.Order, error) {
+	created, err := r.Repository.CreateOrder(ctx, tx, orderModel)
+	if err == nil {
+		r.s.indexOrderAfterCommit(ctx, tx, created.ID)
+	}
+	return created, err
+}
+
+func (r *searchIndexedOrders) UpdateOrderStatus(ctx context.Context, tx pgx.Tx, orderID uint64, status enum.OrderStatus, updatedAt time.Time) error {
+	err := r.Repository.UpdateOrderStatus(ctx, tx, orderID, status, updatedAt)
+	if err == nil {
+		r.s.indexOrderAfterCommit(ctx, tx, orderID)
+	}
+	return err
+}
+
+func (r *searchIndexedOrders) UpdateOrderTotals(ctx context.Context, tx pgx.Tx, orderID uint64, tax, subtotal, discount, total float64, updatedAt time.Time) error {
+	err := r.Repository.UpdateOrderTotals(ctx, tx, orderID, tax, subtotal, discount, total, updatedAt)
+	if err == nil {
+		r.s.indexOrderAfterCommit(ctx, tx, orderID)
+	}
+	return err
+}
+
+func (r *searchIndexedOrders) SetOrderRefund(ctx context.Context, tx pgx.Tx, orderID uint64, refundID string, status enum.OrderStatus, updatedAt time.Time) error {
+	err := r.Repository.SetOrderRefund(ctx, tx, orderID, refundID, status, updatedAt)
+	if err == nil {
+		r.s.indexOrderAfterCommit(ctx, tx, orderID)
+	}
+	return err
+}
+
+func (r *searchIndexedOrders) DeleteOrder(ctx context.Context, tx pgx.Tx, orderID uint64) (bool, error) {
+	deleted, err := r.Repository.DeleteOrder(ctx, tx, orderID)
+	if deleted {
+		r.s.indexOrderAfterCommit(ctx, tx, orderID)
+	}
+	return deleted, err
+}
+
+func (r *searchIndexedOrders) RestoreOrder(ctx context.Context, tx pgx.Tx, orderID uint64) (bool, error) {
+	restored, err := r.Repository.RestoreOrder(ctx, tx, orderID)
+	if restored {
+		r.s.indexOrderAfterCommit(ctx, tx, orderID)
+	}
+	return restored, err
+}
+
+func (r *searchIndexedOrders) AddOrderItems(ctx context.Context, tx pgx.Tx, items []*models.OrderItem) error {
+	err := r.Repository.AddOrderItems(ctx, tx, items)
+	if err == nil && len(items) > 0 {
+		r.s.indexOrderAfterCommit(ctx, tx, items[0].OrderID)
+	}
+	return err
+}
+
+func (r *searchIndexedOrders) MergeOrderMetadata(ctx context.Context, tx pgx.Tx, orderID uint64, metadata map[string]string) error {
+	err := r.Repository.MergeOrderMetadata(ctx, tx, orderID, metadata)
+	if err == nil {
+		r.s.indexOrderAfterCommit(ctx, tx, orderID)
+	}
+	return err
+}
+
+// searchIndexedCategories 在異動商品翻譯的交易 commit 後更新商品的搜尋索引
+type searchIndexedCategories struct {
+	category.Repository
+	s *service
+}
+
+func (r *searchIndexedCategories) UpsertProductTranslation(ctx context.Context, tx pgx.Tx, translation *models.ProductTranslation) error {
+	err := r.Repository.UpsertProductTranslation(ctx, tx, translation)
+	if err == nil {
+		r.s.indexProductAfterCommit(ctx, tx, translation.ProductID, translation.Locale, models.NewProductDocument(translation))
+	}
+	return err
+}
+
+func (r *searchIndexedCategories) DeleteProductTranslation(ctx context.Context, tx pgx.Tx, productID, locale string) (bool, error) {
+	deleted, err := r.Repository.DeleteProductTranslation(ctx, tx, productID, locale)
+	if deleted {
+		r.s.indexProductAfterCommit(ctx, tx, productID, locale, nil)
+	}
+	return deleted, err
+}
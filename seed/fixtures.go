@@ -0,0 +1,84 @@
+package seed
+
+// CategoryFixture 示範用的分類，Parent 為上層分類的 slug，空白表示頂層分類
+type CategoryFixture struct {
+	Name        string
+	Slug        string
+	Description string
+	Parent      string
+}
+
+// Product 示範用的商品與其預設價格，Category 為所屬分類的 slug
+type Product struct {
+	ID          string
+	PriceID     string
+	Name        string
+	Description string
+	UnitPrice   float64
+	Category    string
+}
+
+// Customer 示範用的顧客
+type Customer struct {
+	ID      string
+	Name    string
+	Email   string
+	Address Address
+}
+
+// Address 示範用的寄送地址，欄位與結帳時解析的地址 JSON 相同
+type Address struct {
+	Name       string `json:"name"`
+	Line1      string `json:"line1"`
+	City       string `json:"city"`
+	PostalCode string `json:"postal_code"`
+	Country    string `json:"country"`
+}
+
+// DemoCategories 示範用的分類樹，上層分類排在子分類之前
+var DemoCategories = []CategoryFixture{
+	{Name: "Apparel", Slug: "apparel", Description: "Clothing for every season"},
+	{Name: "T-Shirts", Slug: "t-shirts", Description: "Cotton and blended tees", Parent: "apparel"},
+	{Name: "Outerwear", Slug: "outerwear", Description: "Jackets and coats", Parent: "apparel"},
+	{Name: "Home & Kitchen", Slug: "home-kitchen", Description: "Everyday essentials for the home"},
+	{Name: "Cookware", Slug: "cookware", Description: "Pots, pans and bakeware", Parent: "home-kitchen"},
+	{Name: "Coffee & Tea", Slug: "coffee-tea", Description: "Brewing equipment and accessories", Parent: "home-kitchen"},
+	{Name: "Outdoors", Slug: "outdoors", Description: "Gear for hiking and camping"},
+	{Name: "Camping", Slug: "camping", Description: "Tents, sleeping bags and stoves", Parent: "outdoors"},
+}
+
+// DemoProducts 示範用的商品，ID 以 demo_ 開頭以便與正式資料區分
+var DemoProducts = []Product{
+	{ID: "demo_prod_basic_tee", PriceID: "demo_price_basic_tee", Name: "Basic Crew Tee", Description: "Midweight cotton crew neck", UnitPrice: 19.00, Category: "t-shirts"},
+	{ID: "demo_prod_pocket_tee", PriceID: "demo_price_pocket_tee", Name: "Pocket Tee", Description: "Garment-dyed tee with chest pocket", UnitPrice: 24.00, Category: "t-shirts"},
+	{ID: "demo_prod_longsleeve", PriceID: "demo_price_longsleeve", Name: "Long Sleeve Tee", Description: "Heavyweight long sleeve", UnitPrice: 32.00, Category: "t-shirts"},
+	{ID: "demo_prod_rain_shell", PriceID: "demo_price_rain_shell", Name: "Packable Rain Shell", Description: "Waterproof 2.5-layer shell", UnitPrice: 129.00, Category: "outerwear"},
+	{ID: "demo_prod_down_jacket", PriceID: "demo_price_down_jacket", Name: "Down Jacket", Description: "800-fill responsibly sourced down", UnitPrice: 249.00, Category: "outerwear"},
+	{ID: "demo_prod_skillet", PriceID: "demo_price_skillet", Name: "Cast Iron Skillet 26cm", Description: "Pre-seasoned cast iron", UnitPrice: 45.00, Category: "cookware"},
+	{ID: "demo_prod_dutch_oven", PriceID: "demo_price_dutch_oven", Name: "Enameled Dutch Oven 5L", Description: "Enameled cast iron with lid", UnitPrice: 159.00, Category: "cookware"},
+	{ID: "demo_prod_sheet_pan", PriceID: "demo_price_sheet_pan", Name: "Half Sheet Pan", Description: "Aluminized steel baking sheet", UnitPrice: 18.50, Category: "cookware"},
+	{ID: "demo_prod_pour_over", PriceID: "demo_price_pour_over", Name: "Ceramic Pour-Over Dripper", Description: "Single cup ceramic dripper", UnitPrice: 28.00, Category: "coffee-tea"},
+	{ID: "demo_prod_burr_grinder", PriceID: "demo_price_burr_grinder", Name: "Burr Grinder", Description: "Conical burr grinder with 40 settings", UnitPrice: 139.00, Category: "coffee-tea"},
+	{ID: "demo_prod_teapot", PriceID: "demo_price_teapot", Name: "Glass Teapot 1L", Description: "Borosilicate teapot with infuser", UnitPrice: 34.00, Category: "coffee-tea"},
+	{ID: "demo_prod_tent_2p", PriceID: "demo_price_tent_2p", Name: "Two-Person Backpacking Tent", Description: "Freestanding three-season tent", UnitPrice: 299.00, Category: "camping"},
+	{ID: "demo_prod_sleeping_bag", PriceID: "demo_price_sleeping_bag", Name: "Synthetic Sleeping Bag", Description: "Rated to -5°C", UnitPrice: 119.00, Category: "camping"},
+	{ID: "demo_prod_camp_stove", PriceID: "demo_price_camp_stove", Name: "Canister Camp Stove", Description: "Lightweight stove with piezo ignition", UnitPrice: 59.00, Category: "camping"},
+}
+
+// demoFirstNames 與 demoLastNames 用於組合示範顧客的姓名
+var (
+	demoFirstNames = []string{"Alex", "Jordan", "Taylor", "Morgan", "Casey", "Riley", "Jamie", "Avery", "Quinn", "Skyler"}
+	demoLastNames  = []string{"Chen", "Lin", "Wang", "Smith", "Garcia", "Nguyen", "Kim", "Müller", "Rossi", "Tanaka"}
+)
+
+// demoCities 示範顧客的寄送城市
+var demoCities = []Address{
+	{City: "Taipei", PostalCode: "100", Country: "TW"},
+	{City: "Kaohsiung", PostalCode: "800", Country: "TW"},
+	{City: "Tokyo", PostalCode: "100-0001", Country: "JP"},
+	{City: "Seattle", PostalCode: "98101", Country: "US"},
+	{City: "Berlin", PostalCode: "10115", Country: "DE"},
+}
+
+// demoStreets 示範顧客的街道名稱
+var demoStreets = []string{"Main St", "Park Ave", "Station Rd", "Harbor Blvd", "Maple Ln"}
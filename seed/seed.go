@@ -0,0 +1,312 @@
+// Package seed 以固定的亂數種子在資料庫中建立示範用的分類、商品、庫存、購物車與訂單，
+// 供整合測試與 staging 環境使用；相同的設定會產生相同的資料內容
+package seed
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+
+	"github.com/stripe/stripe-go/v79"
+	"go.uber.org/zap"
+
+	"gofalre.io/shop"
+	"gofalre.io/shop/models"
+	"gofalre.io/shop/models/enum"
+)
+
+// Catalog 建立商品、價格與顧客；這些資料表由外部服務管理，因此由呼叫端提供實作，
+// 資料已存在時應直接回傳 nil
+type Catalog interface {
+	EnsureProduct(ctx context.Context, product Product, currency stripe.Currency) error
+	EnsureCustomer(ctx context.Context, customer Customer) error
+}
+
+// Config 示範資料的數量與亂數種子
+type Config struct {
+	Seed               uint64
+	Currency           stripe.Currency
+	Locations          []string // 每個商品在每個地點各建立一筆庫存
+	MinStock           uint64
+	MaxStock           uint64
+	Customers          int
+	Orders             int // 由購物車結帳建立的訂單數量
+	ActiveCarts        int // 保留為未結帳狀態的購物車數量
+	MaxItemsPerCart    int
+	MaxQuantityPerItem uint64
+}
+
+// DefaultConfig 回傳適合本機開發與 staging 的預設數量
+func DefaultConfig() Config {
+	return Config{
+		Seed:               1,
+		Currency:           stripe.CurrencyUSD,
+		Locations:          []string{"warehouse-north", "warehouse-south"},
+		MinStock:           20,
+		MaxStock:           200,
+		Customers:          10,
+		Orders:             25,
+		ActiveCarts:        5,
+		MaxItemsPerCart:    4,
+		MaxQuantityPerItem: 3,
+	}
+}
+
+func (c Config) validate() error {
+	if c.Currency == "" {
+		return errors.New("currency is required")
+	}
+	if len(c.Locations) == 0 {
+		return errors.New("at least one stock location is required")
+	}
+	if c.MinStock > c.MaxStock {
+		return errors.New("minimum stock cannot exceed maximum stock")
+	}
+	if c.Customers <= 0 && c.Orders+c.ActiveCarts > 0 {
+		return errors.New("customers are required to create carts and orders")
+	}
+	if c.Orders < 0 || c.ActiveCarts < 0 {
+		return errors.New("order and cart counts cannot be negative")
+	}
+	if c.ActiveCarts > c.Customers {
+		return errors.New("active carts cannot exceed customers")
+	}
+	if c.MaxItemsPerCart <= 0 || c.MaxQuantityPerItem == 0 {
+		return errors.New("cart item limits must be greater than zero")
+	}
+	return nil
+}
+
+// Result 本次建立的示範資料
+type Result struct {
+	Categories []*models.Category
+	Products   []Product
+	Stocks     []*models.Stock
+	Customers  []Customer
+	Carts      []*models.Cart
+	Orders     []*models.Order
+}
+
+// Seeder 透過 shop.Service 建立示範資料，讓庫存預留、價格計算與訂單建立都走正式的流程
+type Seeder struct {
+	service shop.Service
+	catalog Catalog
+	config  Config
+	rng     *rand.Rand
+	logger  *zap.Logger
+}
+
+func NewSeeder(service shop.Service, catalog Catalog, config Config, logger *zap.Logger) *Seeder {
+	return &Seeder{
+		service: service,
+		catalog: catalog,
+		config:  config,
+		rng:     rand.New(rand.NewPCG(config.Seed, config.Seed)),
+		logger:  logger,
+	}
+}
+
+// orderOutcomes 結帳後訂單依序套用的狀態，各種結果輪流出現以涵蓋常見的訂單狀態
+var orderOutcomes = [][]enum.OrderStatus{
+	{},
+	{enum.OrderStatusPaid},
+	{enum.OrderStatusPaid, enum.OrderStatusCompleted},
+	{enum.OrderStatusCancelled},
+}
+
+// Run 依序建立分類、商品、庫存、顧客、訂單與購物車；預期在尚未建立示範資料的資料庫上執行，
+// 分類 slug 重複時會失敗。訂單編號由 Service 設定的產生器決定，需要固定編號時請搭配
+// shop.WithOrderNumberGenerator 使用
+func (s *Seeder) Run(ctx context.Context) (*Result, error) {
+	if err := s.config.validate(); err != nil {
+		return nil, fmt.Errorf("invalid seed config: %w", err)
+	}
+
+	result := &Result{}
+
+	// 1. 建立分類樹
+	categories, err := s.seedCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, fixture := range DemoCategories {
+		result.Categories = append(result.Categories, categories[fixture.Slug])
+	}
+
+	// 2. 建立商品並指派分類
+	for _, product := range DemoProducts {
+		if err = s.catalog.EnsureProduct(ctx, product, s.config.Currency); err != nil {
+			return nil, fmt.Errorf("failed to ensure product %s: %w", product.ID, err)
+		}
+		category, ok := categories[product.Category]
+		if !ok {
+			return nil, fmt.Errorf("product %s references unknown category %q", product.ID, product.Category)
+		}
+		if err = s.service.AssignProductToCategory(ctx, product.ID, category.ID); err != nil {
+			return nil, fmt.Errorf("failed to assign product %s to category: %w", product.ID, err)
+		}
+		result.Products = append(result.Products, product)
+	}
+
+	// 3. 建立各地點的庫存
+	stocks := make(map[string][]*models.Stock, len(DemoProducts))
+	for _, product := range DemoProducts {
+		for _, location := range s.config.Locations {
+			quantity := s.config.MinStock + s.rng.Uint64N(s.config.MaxStock-s.config.MinStock+1)
+			stockModel, err := s.service.CreateStock(ctx, product.ID, location, quantity)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create stock for product %s at %s: %w", product.ID, location, err)
+			}
+			stocks[product.ID] = append(stocks[product.ID], stockModel)
+			result.Stocks = append(result.Stocks, stockModel)
+		}
+	}
+
+	// 4. 建立顧客
+	for i := range s.config.Customers {
+		customer := s.newCustomer(i)
+		if err = s.catalog.EnsureCustomer(ctx, customer); err != nil {
+			return nil, fmt.Errorf("failed to ensure customer %s: %w", customer.ID, err)
+		}
+		result.Customers = append(result.Customers, customer)
+	}
+
+	// 5. 以購物車結帳建立訂單，並依序推進到不同的狀態
+	for i := range s.config.Orders {
+		customer := result.Customers[s.rng.IntN(len(result.Customers))]
+		cart, err := s.seedCart(ctx, customer, stocks)
+		if err != nil {
+			return nil, err
+		}
+
+		order, err := s.service.ConvertCartToOrder(ctx, cart.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert cart %d to order: %w", cart.ID, err)
+		}
+
+		for _, status := range orderOutcomes[i%len(orderOutcomes)] {
+			if err = s.service.UpdateOrderStatus(ctx, order.ID, status); err != nil {
+				return nil, fmt.Errorf("failed to update order %d to %s: %w", order.ID, status, err)
+			}
+			order.Status = status
+		}
+		result.Orders = append(result.Orders, order)
+	}
+
+	// 6. 建立尚未結帳的購物車，每位顧客只會有一個 active 購物車，因此分配給不同的顧客
+	for _, index := range s.rng.Perm(len(result.Customers))[:s.config.ActiveCarts] {
+		customer := result.Customers[index]
+		cart, err := s.seedCart(ctx, customer, stocks)
+		if err != nil {
+			return nil, err
+		}
+		result.Carts = append(result.Carts, cart)
+	}
+
+	s.logger.Info("Seeded demo data",
+		zap.Uint64("seed", s.config.Seed),
+		zap.Int("categories", len(result.Categories)),
+		zap.Int("products", len(result.Products)),
+		zap.Int("stocks", len(result.Stocks)),
+		zap.Int("customers", len(result.Customers)),
+		zap.Int("orders", len(result.Orders)),
+		zap.Int("carts", len(result.Carts)))
+
+	return result, nil
+}
+
+// seedCategories 依序建立示範分類，回傳以 slug 為索引的分類
+func (s *Seeder) seedCategories(ctx context.Context) (map[string]*models.Category, error) {
+	categories := make(map[string]*models.Category, len(DemoCategories))
+
+	for _, fixture := range DemoCategories {
+		category := &models.Category{
+			Name:        fixture.Name,
+			Slug:        fixture.Slug,
+			Description: fixture.Description,
+		}
+		if fixture.Parent != "" {
+			parent, ok := categories[fixture.Parent]
+			if !ok {
+				return nil, fmt.Errorf("category %s references unknown parent %q", fixture.Slug, fixture.Parent)
+			}
+			category.ParentID = &parent.ID
+		}
+
+		if err := s.service.CreateCategory(ctx, category); err != nil {
+			return nil, fmt.Errorf("failed to create category %s: %w", fixture.Slug, err)
+		}
+		categories[fixture.Slug] = category
+	}
+
+	return categories, nil
+}
+
+// seedCart 為顧客建立一個含隨機商品與寄送地址的購物車，商品從可售數量足夠的庫存中挑選
+func (s *Seeder) seedCart(ctx context.Context, customer Customer, stocks map[string][]*models.Stock) (*models.Cart, error) {
+	cart, err := s.service.CreateCart(ctx, customer.ID, s.config.Currency)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cart for customer %s: %w", customer.ID, err)
+	}
+
+	itemCount := 1 + s.rng.IntN(s.config.MaxItemsPerCart)
+	items := make([]*models.CartItem, 0, itemCount)
+	for _, index := range s.rng.Perm(len(DemoProducts))[:min(itemCount, len(DemoProducts))] {
+		product := DemoProducts[index]
+		candidates := stocks[product.ID]
+		stockModel := candidates[s.rng.IntN(len(candidates))]
+
+		quantity := 1 + s.rng.Uint64N(s.config.MaxQuantityPerItem)
+		if available := stockModel.Quantity - stockModel.ReservedQuantity; available < quantity {
+			continue
+		}
+		stockModel.ReservedQuantity += quantity
+
+		items = append(items, &models.CartItem{
+			ProductID: product.ID,
+			PriceID:   product.PriceID,
+			StockID:   stockModel.ID,
+			Quantity:  quantity,
+			UnitPrice: product.UnitPrice,
+			Subtotal:  float64(quantity) * product.UnitPrice,
+			Location:  stockModel.Location,
+		})
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("not enough demo stock left to fill cart %d", cart.ID)
+	}
+
+	if _, err = s.service.AddItemsToCart(ctx, customer.ID, cart.ID, items, s.config.Currency); err != nil {
+		return nil, fmt.Errorf("failed to add items to cart %d: %w", cart.ID, err)
+	}
+
+	address, err := json.Marshal(customer.Address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal address: %w", err)
+	}
+	if err = s.service.SetCartAddresses(ctx, cart.ID, address, nil); err != nil {
+		return nil, fmt.Errorf("failed to set addresses for cart %d: %w", cart.ID, err)
+	}
+
+	return cart, nil
+}
+
+// newCustomer 以亂數組合示範顧客的姓名與地址，ID 依序編號
+func (s *Seeder) newCustomer(index int) Customer {
+	firstName := demoFirstNames[s.rng.IntN(len(demoFirstNames))]
+	lastName := demoLastNames[s.rng.IntN(len(demoLastNames))]
+	name := firstName + " " + lastName
+
+	address := demoCities[s.rng.IntN(len(demoCities))]
+	address.Name = name
+	address.Line1 = fmt.Sprintf("%d %s", 1+s.rng.IntN(999), demoStreets[s.rng.IntN(len(demoStreets))])
+
+	return Customer{
+		ID:      fmt.Sprintf("demo_cus_%04d", index+1),
+		Name:    name,
+		Email:   fmt.Sprintf("demo+%04d@example.com", index+1),
+		Address: address,
+	}
+}
@@ -0,0 +1,222 @@
+package shop
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"gofalre.io/shop/models"
+	"gofalre.io/shop/models/enum"
+)
+
+// defaultSegmentLookback 為預設計算客戶分群的訂單統計期間
+const defaultSegmentLookback = 365 * 24 * time.Hour
+
+// rfmScoreLevels 為 RFM 各項分數的級距數
+const rfmScoreLevels = 5
+
+// ErrCustomerHasNoOrders 表示客戶在統計期間內沒有有效訂單，無法分群
+var ErrCustomerHasNoOrders = errors.New("customer has no orders in the segmentation period")
+
+// SpendTier 為消費等級，MinSpend 為統計期間內達到該等級的最低消費金額（報表幣別）
+type SpendTier struct {
+	Name     string
+	MinSpend float64
+}
+
+// DefaultSpendTiers 為未設定消費等級時使用的預設值
+var DefaultSpendTiers = []SpendTier{
+	{Name: "standard", MinSpend: 0},
+	{Name: "silver", MinSpend: 500},
+	{Name: "gold", MinSpend: 2000},
+	{Name: "platinum", MinSpend: 5000},
+}
+
+// segmentExportHeader 為客戶群匯出 CSV 的欄位
+var segmentExportHeader = []string{
+	"customer_id", "segment", "spend_tier", "recency", "frequency", "monetary",
+	"orders", "spend", "first_order_at", "last_order_at",
+}
+
+// WithCustomerSegmentation 設定客戶分群的訂單統計期間與消費等級，lookback 為 0 或 tiers 為空時保留預設值
+func WithCustomerSegmentation(lookback time.Duration, tiers []SpendTier) Option {
+	return func(s *service) {
+		if lookback > 0 {
+			s.segmentLookback = lookback
+		}
+		if len(tiers) > 0 {
+			sorted := append([]SpendTier(nil), tiers...)
+			sort.SliceStable(sorted, func(i, j int) bool {
+				return sorted[i].MinSpend < sorted[j].MinSpend
+			})
+			s.spendTiers = sorted
+		}
+	}
+}
+
+// ListCustomerProfiles 以統計期間內的有效訂單計算每位客戶的消費等級與 RFM 分群，回傳符合 filter 的客戶
+func (s *service) ListCustomerProfiles(ctx context.Context, filter models.SegmentFilter) ([]*models.CustomerProfile, error) {
+	var profiles []*models.CustomerProfile
+
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		all, err := s.customerProfiles(ctx, tx, time.Now())
+		if err != nil {
+			return err
+		}
+
+		for _, profile := range all {
+			if filter.Matches(profile) {
+				profiles = append(profiles, profile)
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return profiles, nil
+}
+
+// GetCustomerProfile 取得單一客戶的分群結果，RFM 分數仍相對於所有客戶計算
+func (s *service) GetCustomerProfile(ctx context.Context, customerID string) (*models.CustomerProfile, error) {
+	profiles, err := s.ListCustomerProfiles(ctx, models.SegmentFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, profile := range profiles {
+		if profile.CustomerID == customerID {
+			return profile, nil
+		}
+	}
+
+	return nil, ErrCustomerHasNoOrders
+}
+
+// ExportCustomerSegment 將符合 filter 的客戶群以 CSV 寫入 w，第一列為欄位名稱
+func (s *service) ExportCustomerSegment(ctx context.Context, filter models.SegmentFilter, w io.Writer) error {
+	profiles, err := s.ListCustomerProfiles(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("failed to list customer profiles: %w", err)
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(segmentExportHeader); err != nil {
+		return fmt.Errorf("failed to write segment export header: %w", err)
+	}
+	for _, profile := range profiles {
+		if err := writer.Write([]string{
+			profile.CustomerID,
+			string(profile.Segment),
+			profile.SpendTier,
+			strconv.Itoa(profile.RFM.Recency),
+			strconv.Itoa(profile.RFM.Frequency),
+			strconv.Itoa(profile.RFM.Monetary),
+			strconv.FormatUint(profile.Orders, 10),
+			strconv.FormatFloat(profile.Spend, 'f', 2, 64),
+			profile.FirstOrderAt.UTC().Format(time.RFC3339),
+			profile.LastOrderAt.UTC().Format(time.RFC3339),
+		}); err != nil {
+			return fmt.Errorf("failed to write segment export: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write segment export: %w", err)
+	}
+
+	return nil
+}
+
+// customerProfiles 彙總統計期間內每位客戶的訂單，依所有客戶的分佈計算 RFM 分數後歸類
+func (s *service) customerProfiles(ctx context.Context, tx pgx.Tx, now time.Time) ([]*models.CustomerProfile, error) {
+	// 1. 彙總每位客戶的訂單
+	stats, err := s.order.ListCustomerOrderStats(ctx, tx, now.Add(-s.segmentLookback))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list customer order stats: %w", err)
+	}
+
+	// 2. 依所有客戶的分佈計算 RFM 分數，最近一次下單越晚近期性分數越高
+	recency := make([]float64, len(stats))
+	frequency := make([]float64, len(stats))
+	monetary := make([]float64, len(stats))
+	for i, stat := range stats {
+		recency[i] = float64(stat.LastOrderAt.Unix())
+		frequency[i] = float64(stat.Orders)
+		monetary[i] = stat.Spend
+	}
+	recencyScores := rankScores(recency)
+	frequencyScores := rankScores(frequency)
+	monetaryScores := rankScores(monetary)
+
+	// 3. 歸類消費等級與客戶群
+	profiles := make([]*models.CustomerProfile, len(stats))
+	for i, stat := range stats {
+		profile := &models.CustomerProfile{
+			CustomerOrderStats: *stat,
+			SpendTier:          s.spendTier(stat.Spend),
+			RFM: models.RFMScore{
+				Recency:   recencyScores[i],
+				Frequency: frequencyScores[i],
+				Monetary:  monetaryScores[i],
+			},
+		}
+		profile.Segment = customerSegment(profile)
+		profiles[i] = profile
+	}
+
+	return profiles, nil
+}
+
+// spendTier 回傳消費金額達到的最高等級
+func (s *service) spendTier(spend float64) string {
+	tier := ""
+	for _, t := range s.spendTiers {
+		if spend >= t.MinSpend {
+			tier = t.Name
+		}
+	}
+	return tier
+}
+
+// rankScores 依數值在所有值中的排名給 1 到 rfmScoreLevels 分，數值相同的客戶分數相同
+func rankScores(values []float64) []int {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	scores := make([]int, len(values))
+	for i, value := range values {
+		lower := sort.SearchFloat64s(sorted, value)
+		scores[i] = 1 + lower*rfmScoreLevels/len(values)
+	}
+	return scores
+}
+
+// customerSegment 依 RFM 分數歸類客戶群，規則由上而下比對
+func customerSegment(profile *models.CustomerProfile) enum.CustomerSegment {
+	rfm := profile.RFM
+	switch {
+	case profile.Orders == 1 && rfm.Recency >= 4:
+		return enum.CustomerSegmentNew
+	case rfm.Recency >= 4 && rfm.Frequency >= 4 && rfm.Monetary >= 4:
+		return enum.CustomerSegmentChampion
+	case rfm.Recency >= 3 && rfm.Frequency >= 4:
+		return enum.CustomerSegmentLoyal
+	case rfm.Recency <= 2 && (rfm.Frequency >= 3 || rfm.Monetary >= 4):
+		return enum.CustomerSegmentAtRisk
+	case rfm.Recency <= 2:
+		return enum.CustomerSegmentHibernating
+	case rfm.Recency >= 4:
+		return enum.CustomerSegmentPromising
+	default:
+		return enum.CustomerSegmentRegular
+	}
+}
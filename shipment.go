@@ -0,0 +1,204 @@
+package shop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"gofalre.io/shop/models"
+	"gofalre.io/shop/models/enum"
+)
+
+// ErrShipmentNotRequired 表示訂單為門市自取，不需要建立出貨單
+var ErrShipmentNotRequired = errors.New("order does not require shipping")
+
+// CreateShipment 建立訂單從指定地點出貨的出貨單，只有已付款且需要寄送的訂單可以出貨；重複建立時回傳原本的出貨單
+func (s *service) CreateShipment(ctx context.Context, orderID uint64, location string) (*models.Shipment, error) {
+	if location == "" {
+		return nil, errors.New("shipment location is required")
+	}
+
+	var shipment *models.Shipment
+
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		// 1. 鎖定訂單並確認可以出貨
+		orderModel, err := s.order.GetOrderForUpdate(ctx, tx, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to get order: %w", err)
+		}
+		if !orderModel.RequiresShipping() {
+			return ErrShipmentNotRequired
+		}
+		if orderModel.Status == enum.OrderStatusOnHold {
+			return ErrOrderOnHold
+		}
+		if orderModel.Status != enum.OrderStatusPaid {
+			return fmt.Errorf("order %d is %s and cannot be shipped", orderID, orderModel.Status)
+		}
+
+		// 2. 確認該地點有需要出貨的項目
+		items, err := s.order.ListOrderItems(ctx, tx, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to list order items: %w", err)
+		}
+		shippingItems, err := s.shippingItems(ctx, tx, items)
+		if err != nil {
+			return err
+		}
+		if len(itemsAtLocation(shippingItems, location)) == 0 {
+			return fmt.Errorf("order %d has no items to ship from %s", orderID, location)
+		}
+
+		// 3. 建立出貨單
+		shipment, err = s.order.CreateShipment(ctx, tx, orderID, location)
+		if err != nil {
+			return fmt.Errorf("failed to create shipment: %w", err)
+		}
+
+		// 4. 標記該地點的項目已出貨，第一批出貨時記錄履約時限的完成時間
+		if err = s.order.ShipOrderItems(ctx, tx, orderID, location); err != nil {
+			return fmt.Errorf("failed to ship order items: %w", err)
+		}
+		return s.completeFulfillmentSLA(ctx, tx, orderID, shipment.CreatedAt)
+	}); err != nil {
+		return nil, err
+	}
+
+	return shipment, nil
+}
+
+// GetPackingSlip 回傳訂單的裝箱單資料，包含地址、所有項目的重量與報關資訊及已建立的出貨單
+func (s *service) GetPackingSlip(ctx context.Context, orderID uint64) (*models.PackingSlip, error) {
+	var packingSlip *models.PackingSlip
+
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		// 1. 獲取訂單及項目
+		orderModel, err := s.order.GetOrder(ctx, tx, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to get order: %w", err)
+		}
+		items, err := s.order.ListOrderItems(ctx, tx, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to list order items: %w", err)
+		}
+		shippingItems, err := s.shippingItems(ctx, tx, items)
+		if err != nil {
+			return err
+		}
+
+		// 2. 獲取已建立的出貨單
+		shipments, err := s.order.ListShipments(ctx, tx, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to list shipments: %w", err)
+		}
+
+		packingSlip = &models.PackingSlip{
+			OrderID:         orderModel.ID,
+			OrderNumber:     orderModel.OrderNumber,
+			CustomerID:      orderModel.CustomerID,
+			Status:          orderModel.Status,
+			Currency:        orderModel.Currency,
+			FulfillmentType: orderModel.FulfillmentType,
+			PickupLocation:  orderModel.PickupLocation,
+			ShippingAddress: orderModel.ShippingAddress,
+			BillingAddress:  orderModel.BillingAddress,
+			Items:           shippingItems,
+			Shipments:       shipments,
+			OrderedAt:       orderModel.CreatedAt,
+		}
+		for _, item := range shippingItems {
+			packingSlip.TotalQuantity += item.Quantity
+			packingSlip.TotalWeightGrams += item.WeightGrams
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return packingSlip, nil
+}
+
+// GetShipmentLabelData 回傳物流標籤所需的寄件地點、收件地址、總重量與報關明細
+func (s *service) GetShipmentLabelData(ctx context.Context, shipmentID uint64) (*models.ShipmentLabelData, error) {
+	var labelData *models.ShipmentLabelData
+
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		// 1. 獲取出貨單及訂單
+		shipment, err := s.order.GetShipment(ctx, tx, shipmentID)
+		if err != nil {
+			return fmt.Errorf("failed to get shipment: %w", err)
+		}
+		orderModel, err := s.order.GetOrder(ctx, tx, shipment.OrderID)
+		if err != nil {
+			return fmt.Errorf("failed to get order: %w", err)
+		}
+
+		// 2. 取出從該地點出貨的項目
+		items, err := s.order.ListOrderItems(ctx, tx, shipment.OrderID)
+		if err != nil {
+			return fmt.Errorf("failed to list order items: %w", err)
+		}
+		shippingItems, err := s.shippingItems(ctx, tx, items)
+		if err != nil {
+			return err
+		}
+
+		labelData = &models.ShipmentLabelData{
+			ShipmentID:  shipment.ID,
+			OrderID:     orderModel.ID,
+			OrderNumber: orderModel.OrderNumber,
+			CustomerID:  orderModel.CustomerID,
+			ShipFrom:    shipment.Location,
+			ShipTo:      orderModel.ShippingAddress,
+			Currency:    orderModel.Currency,
+			Items:       itemsAtLocation(shippingItems, shipment.Location),
+		}
+		for _, item := range labelData.Items {
+			labelData.TotalQuantity += item.Quantity
+			labelData.TotalWeightGrams += item.WeightGrams
+			labelData.DeclaredValue += item.Subtotal
+			if item.UnitWeightGrams == 0 {
+				labelData.MissingWeight = true
+			}
+		}
+		labelData.DeclaredValue = roundCurrency(labelData.DeclaredValue)
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return labelData, nil
+}
+
+// shippingItems 將訂單項目轉為出貨明細，舊訂單沒有記錄地點時從庫存資料補齊
+func (s *service) shippingItems(ctx context.Context, tx pgx.Tx, items []*models.OrderItem) ([]*models.ShippingItem, error) {
+	shippingItems := make([]*models.ShippingItem, 0, len(items))
+	for _, item := range items {
+		location := item.Location
+		if location == "" {
+			stockModel, err := s.stock.GetStock(ctx, tx, item.StockID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get stock for item %s: %w", item.ProductID, err)
+			}
+			location = stockModel.Location
+		}
+		shippingItems = append(shippingItems, models.NewShippingItem(item, location))
+	}
+
+	return shippingItems, nil
+}
+
+// itemsAtLocation 篩選出從指定地點出貨的明細
+func itemsAtLocation(items []*models.ShippingItem, location string) []*models.ShippingItem {
+	var result []*models.ShippingItem
+	for _, item := range items {
+		if item.Location == location {
+			result = append(result, item)
+		}
+	}
+	return result
+}
@@ -2,49 +2,242 @@ package shop
 
 import (
 	"context"
-	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/nats-io/nats.go"
 	"github.com/stripe/stripe-go/v79"
 	"go.uber.org/zap"
 
+	"gofalre.io/shop/campaign"
 	"gofalre.io/shop/cart"
 	"gofalre.io/shop/category"
+	"gofalre.io/shop/coupon"
 	"gofalre.io/shop/driver"
 	"gofalre.io/shop/event"
 	"gofalre.io/shop/models"
 	"gofalre.io/shop/models/enum"
 	"gofalre.io/shop/order"
+	"gofalre.io/shop/price"
 	"gofalre.io/shop/stock"
+	"gofalre.io/shop/wishlist"
 )
 
 type Service interface {
 	CreateCart(ctx context.Context, customerID string, currency stripe.Currency) (*models.Cart, error)
 	GetOrCreateActiveCart(ctx context.Context, customerID string, currency stripe.Currency) (*models.Cart, error)
-	AddItemsToCart(ctx context.Context, customerID string, cartID uint64, items []*models.CartItem, currency stripe.Currency) error
+	CreateNamedCart(ctx context.Context, customerID, name string, currency stripe.Currency) (*models.Cart, error)
+	ListCarts(ctx context.Context, customerID string) ([]*models.Cart, error)
+	SetDefaultCart(ctx context.Context, customerID string, cartID uint64) error
+	AddItemsToCart(ctx context.Context, customerID string, cartID uint64, items []*models.CartItem, currency stripe.Currency) (uint64, error)
 	RemoveItemFromCart(ctx context.Context, cartID, itemID uint64) error
 	UpdateCartItemQuantity(ctx context.Context, cartID, itemID, quantity uint64) error
+	UpdateCartItems(ctx context.Context, cartID uint64, updates []CartItemUpdate) error
+	UpdateCartItemMetadata(ctx context.Context, cartID, itemID uint64, note string, metadata map[string]string) error
+	SaveCartItemForLater(ctx context.Context, cartID, itemID uint64) (*models.SavedItem, error)
+	ListSavedItems(ctx context.Context, customerID string) ([]*models.SavedItem, error)
+	RemoveSavedItem(ctx context.Context, customerID string, savedItemID uint64) error
+	MoveSavedItemToCart(ctx context.Context, customerID string, savedItemID uint64) (uint64, error)
+	RecalculateCart(ctx context.Context, cartID uint64) (*models.Cart, error)
+	ClearCart(ctx context.Context, cartID uint64, status enum.CartStatus) error
+	AbandonCart(ctx context.Context, cartID uint64) error
+	ExtendCartExpiry(ctx context.Context, cartID uint64, d time.Duration) (time.Time, error)
+	ExpireCarts(ctx context.Context) (int, error)
+	RunCartExpirySweeper(ctx context.Context) error
+	DetectAbandonedCarts(ctx context.Context) (int, error)
+	SetCartAddresses(ctx context.Context, cartID uint64, shippingAddress, billingAddress json.RawMessage) error
+	SetCartChannel(ctx context.Context, cartID uint64, channel string) error
+	ClearCartAddresses(ctx context.Context, cartID uint64) error
+	ValidateCartForCheckout(ctx context.Context, cartID uint64) (*models.CartValidation, error)
+	ApplyCoupon(ctx context.Context, cartID uint64, code string) (*models.Cart, error)
+	RemoveCoupon(ctx context.Context, cartID uint64) (*models.Cart, error)
+	ListCartExperiments(ctx context.Context, cartID uint64) ([]*models.ExperimentAssignment, error)
+	ListOrderExperiments(ctx context.Context, orderID uint64) ([]*models.ExperimentAssignment, error)
+	ExperimentVariant(ctx context.Context, cartID uint64, experiment string) (string, bool, error)
 
 	ConvertCartToOrder(ctx context.Context, cartID uint64) (*models.Order, error)
-	CreateOrder(ctx context.Context, order *models.Order) error
+	CreateOrder(ctx context.Context, order *models.Order, idempotencyKey string) error
+	ImportOrders(ctx context.Context, orders []*models.Order) ([]*models.OrderImportResult, error)
 	GetOrder(ctx context.Context, orderID uint64) (*models.Order, error)
+	GetOrderByNumber(ctx context.Context, orderNumber string) (*models.Order, error)
+	AddOrderAddon(ctx context.Context, orderID uint64, addonType enum.OrderAddonType, orderItemID, quantity uint64, description string) (*models.OrderAddon, error)
+	RemoveOrderAddon(ctx context.Context, orderID, addonID uint64) error
+	ListOrderAddons(ctx context.Context, orderID uint64) ([]*models.OrderAddon, error)
 	UpdateOrderStatus(ctx context.Context, orderID uint64, status enum.OrderStatus) error
-	ListOrders(ctx context.Context, customerID string, limit, offset uint64) ([]*models.Order, error)
+	ListOrders(ctx context.Context, customerID string, limit, offset uint64) (*models.Page[*models.Order], error)
+	FindOrdersByMetadata(ctx context.Context, metadata map[string]string, limit, offset uint64) ([]*models.Order, error)
+	SearchOrders(ctx context.Context, query models.SearchQuery) (*models.Page[*models.Order], error)
 	CancelOrder(ctx context.Context, orderID uint64) error
+	DeleteOrder(ctx context.Context, orderID uint64) error
+	RestoreOrder(ctx context.Context, orderID uint64) error
+	ReorderFromOrder(ctx context.Context, orderID uint64) (*models.ReorderResult, error)
+	CreateCartFromOrder(ctx context.Context, customerID string, orderID uint64) (*models.ReorderResult, error)
+	GetPickList(ctx context.Context, orderID uint64) ([]*models.PickList, error)
+	GetPackingSlip(ctx context.Context, orderID uint64) (*models.PackingSlip, error)
+	CreateShipment(ctx context.Context, orderID uint64, location string) (*models.Shipment, error)
+	GetShipmentLabelData(ctx context.Context, shipmentID uint64) (*models.ShipmentLabelData, error)
+	SetOrderFulfillment(ctx context.Context, orderID uint64, fulfillmentType enum.FulfillmentType, pickupLocation string) error
+	MarkOrderReadyForPickup(ctx context.Context, orderID uint64) error
+	HoldOrder(ctx context.Context, orderID uint64, reason string) error
+	ReleaseOrderHold(ctx context.Context, orderID uint64) error
+	GetOrderTimeline(ctx context.Context, orderID uint64) ([]*models.OrderTimelineEntry, error)
+	ListPendingFraudReviews(ctx context.Context) ([]*models.FraudReview, error)
+	ResolveFraudReview(ctx context.Context, reviewID uint64, status enum.FraudReviewStatus, resolvedBy, note string) error
+	EstimateDelivery(ctx context.Context, source DeliverySource, destination *models.DeliveryDestination) (*models.DeliveryEstimate, error)
+	EvaluateRefund(ctx context.Context, orderID uint64, orderItemIDs []uint64) (*models.RefundEvaluation, error)
+	RefundOrder(ctx context.Context, orderID uint64, orderItemIDs []uint64, reason string) (*models.Refund, error)
+	ListRefunds(ctx context.Context, orderID uint64) ([]*models.Refund, error)
+	GetOrderReceiptLinks(ctx context.Context, orderID uint64, refresh bool) (*models.OrderReceiptLinks, error)
+	CreateBillingPortalLink(ctx context.Context, orderID uint64, returnURL string) (string, error)
+	IssueInvoice(ctx context.Context, orderID uint64) (*models.Invoice, error)
+	ListOrderInvoices(ctx context.Context, orderID uint64) ([]*models.Invoice, error)
+	RenderInvoiceDocument(ctx context.Context, invoiceID uint64) (*models.Invoice, error)
+	ListInvoiceNumberGaps(ctx context.Context) ([]*models.InvoiceNumberGap, error)
+	AddTaxExemptionCertificate(ctx context.Context, certificate *models.TaxExemptionCertificate) (*models.TaxExemptionCertificate, error)
+	ListTaxExemptionCertificates(ctx context.Context, customerID string) ([]*models.TaxExemptionCertificate, error)
+	RevokeTaxExemptionCertificate(ctx context.Context, customerID string, certificateID uint64) error
+	CommitPendingOrderTaxes(ctx context.Context) (int, error)
+	RequestReturn(ctx context.Context, orderID uint64, reason string) (*models.OrderReturn, error)
+	ApproveReturn(ctx context.Context, returnID uint64) (*models.OrderReturn, error)
+	RejectReturn(ctx context.Context, returnID uint64) (*models.OrderReturn, error)
+	IssueReturnLabel(ctx context.Context, returnID uint64) (*models.OrderReturn, error)
+	ListOrderReturns(ctx context.Context, orderID uint64) ([]*models.OrderReturn, error)
+	HandleReturnTrackingEvent(ctx context.Context, event *models.ReturnTrackingEvent) error
+	PollReturnTracking(ctx context.Context) (int, error)
+
+	RequestOrderCancellation(ctx context.Context, orderID uint64, customerID string, reason enum.CancellationReason) (*models.OrderCancellationRequest, error)
+	ListPendingOrderCancellations(ctx context.Context) ([]*models.OrderCancellationRequest, error)
+	ApproveOrderCancellation(ctx context.Context, requestID uint64, reviewedBy string) (*models.OrderCancellationRequest, error)
+	RejectOrderCancellation(ctx context.Context, requestID uint64, reviewedBy string) (*models.OrderCancellationRequest, error)
+	GetCancellationReasonStats(ctx context.Context, from, to time.Time) ([]*models.CancellationReasonStats, error)
+	CancelOrdersContainingProduct(ctx context.Context, productID, reason string, statusFilter []enum.OrderStatus) (*models.BulkCancellationReport, error)
+
+	ListPendingOrderRepricings(ctx context.Context) ([]*models.OrderRepricing, error)
+	ApproveOrderRepricing(ctx context.Context, repricingID uint64, reviewedBy string) (*models.OrderRepricing, error)
+	DismissOrderRepricing(ctx context.Context, repricingID uint64, reviewedBy string) (*models.OrderRepricing, error)
+
+	GetNotificationPreferences(ctx context.Context, customerID string) (*models.NotificationPreferences, error)
+	UpdateNotificationChannels(ctx context.Context, customerID string, channels []enum.NotificationChannel) (*models.NotificationPreferences, error)
+	SetNotificationOptOut(ctx context.Context, customerID string, topic enum.NotificationTopic, optedOut bool) (*models.NotificationPreferences, error)
+	Unsubscribe(ctx context.Context, token string) (*models.NotificationPreferences, error)
+	NotifyBackInStock(ctx context.Context, productID string, customerIDs []string) (int, error)
+
+	CreateStockHold(ctx context.Context, stockID, quantity uint64, reason string, expiresAt time.Time) (*models.StockHold, error)
+	ListStockHolds(ctx context.Context, stockID uint64) ([]*models.StockHold, error)
+	ReleaseStockHold(ctx context.Context, holdID uint64) error
+	ReleaseExpiredStockHolds(ctx context.Context) (int, error)
+	GetOrderFulfillmentSLA(ctx context.Context, orderID uint64) (*models.OrderFulfillmentSLA, error)
+	ListOrdersAtRiskOfSLABreach(ctx context.Context) ([]*models.OrderFulfillmentSLA, error)
+	CheckFulfillmentSLAs(ctx context.Context) (int, error)
+
+	RequestStockAdjustment(ctx context.Context, stockID uint64, delta int64, reason enum.StockMovementReason, note, requestedBy string) (*models.StockAdjustment, error)
+	ApproveStockAdjustment(ctx context.Context, adjustmentID uint64, approvedBy, note string) error
+	RejectStockAdjustment(ctx context.Context, adjustmentID uint64, rejectedBy, note string) error
+	ListPendingStockAdjustments(ctx context.Context) ([]*models.StockAdjustment, error)
+	ReverseStockMovement(ctx context.Context, movementID uint64, reason string) (*models.StockMovement, error)
+	ListStockMovements(ctx context.Context, stockID uint64, limit, offset uint64) (*models.Page[*models.StockMovement], error)
+	CreateStock(ctx context.Context, productID, location string, quantity uint64) (*models.Stock, error)
+	CreateManualStockMovement(ctx context.Context, stockID, qty uint64, movementType enum.StockMovementType, reasonCode enum.StockMovementReason, note string) (*models.StockMovement, error)
+	GetStockLevelAt(ctx context.Context, stockID uint64, at time.Time) (*models.StockLevel, error)
+	SuggestTransfers(ctx context.Context, createDrafts bool) ([]*models.TransferSuggestion, error)
+	ApproveStockTransfer(ctx context.Context, transferID uint64, approvedBy, note string) error
+	RejectStockTransfer(ctx context.Context, transferID uint64, rejectedBy, note string) error
+	ListDraftStockTransfers(ctx context.Context) ([]*models.StockTransfer, error)
+
+	RegisterProductBarcode(ctx context.Context, barcode, productID string) error
+	StartStocktake(ctx context.Context, location, startedBy string) (*models.Stocktake, error)
+	SubmitStocktakeScans(ctx context.Context, stocktakeID uint64, batch *models.StocktakeScanBatch) (*models.StocktakeBatchResult, error)
+	ListStocktakeCounts(ctx context.Context, stocktakeID uint64) ([]*models.StocktakeCount, error)
+	CompleteStocktake(ctx context.Context, stocktakeID uint64, completedBy string) ([]*models.StockAdjustment, error)
+	CancelStocktake(ctx context.Context, stocktakeID uint64, cancelledBy string) error
+
+	PublishProductToChannel(ctx context.Context, channel, productID string, available bool) error
+	UnpublishProductFromChannel(ctx context.Context, channel, productID string) error
+	ListChannelProducts(ctx context.Context, channel string) ([]*models.ChannelProduct, error)
+	SetChannelPrice(ctx context.Context, channel, priceID string, currency stripe.Currency, unitPrice float64) error
+	RemoveChannelPrice(ctx context.Context, channel, priceID string, currency stripe.Currency) error
+	ListChannelPrices(ctx context.Context, channel string) ([]*models.ChannelPrice, error)
+
+	EnableStockEventSourcing(ctx context.Context, stockID uint64) (*models.StockProjection, error)
+	DisableStockEventSourcing(ctx context.Context, stockID uint64) error
+	ProjectStocks(ctx context.Context) (int, error)
+	RebuildStockProjection(ctx context.Context, stockID uint64) (*models.Stock, error)
+
+	SetStockRentalMode(ctx context.Context, stockID uint64, enabled bool) error
+	CreateRental(ctx context.Context, stockID uint64, customerID string, quantity uint64, startsOn, endsOn time.Time, note string) (*models.StockRental, error)
+	CancelRental(ctx context.Context, rentalID uint64) error
+	ReturnRental(ctx context.Context, rentalID uint64) error
+	ListRentals(ctx context.Context, stockID uint64, from, to time.Time) ([]*models.StockRental, error)
+	GetRentalAvailability(ctx context.Context, stockID uint64, from, to time.Time) ([]*models.RentalAvailability, error)
+
+	CreateStore(ctx context.Context, store *models.Store) error
+	UpdateStore(ctx context.Context, store *models.Store) error
+	ListStores(ctx context.Context) ([]*models.Store, error)
+	FindNearbyStoresWithStock(ctx context.Context, productID string, lat, lng, radius float64) ([]*models.StoreAvailability, error)
+	RefreshProductAvailability(ctx context.Context) error
+	ListProductAvailability(ctx context.Context, productIDs []string) (map[string]*models.ProductAvailability, error)
+	RunProductAvailabilityRefresher(ctx context.Context) error
+	SearchProducts(ctx context.Context, query models.SearchQuery) (*models.Page[string], error)
+	ReindexSearch(ctx context.Context) (int, error)
+
+	SchedulePriceChange(ctx context.Context, priceID, productID string, unitPrice float64, currency stripe.Currency, reason string, effectiveAt time.Time) (*models.PriceChange, error)
+	CancelPriceChange(ctx context.Context, priceChangeID uint64) error
+	ListPriceChanges(ctx context.Context, priceID string) ([]*models.PriceChange, error)
+	ApplyDuePriceChanges(ctx context.Context) (int, error)
+	GetPriceAt(ctx context.Context, priceID string, at time.Time) (*models.PriceChange, error)
+	AuditOrderPrices(ctx context.Context, orderID uint64) ([]*models.PriceAuditLine, error)
+	RoundPrice(ctx context.Context, amount float64, currency stripe.Currency) float64
+	GetCatalogSnapshot(ctx context.Context, productIDs []string) ([]*models.CatalogEntry, error)
+
+	CreateCoupon(ctx context.Context, coupon *models.Coupon) error
+	CreateDiscountCampaign(ctx context.Context, name string, categoryID uint64, includeSubcategories bool, percentOff float64, startsAt, endsAt time.Time) (*models.DiscountCampaign, error)
+	ListDiscountCampaigns(ctx context.Context, limit, offset uint64) ([]*models.DiscountCampaign, error)
+	GetCampaignReport(ctx context.Context, campaignID uint64) (*models.CampaignReport, error)
+	GetSalesReport(ctx context.Context, from, to time.Time) (*models.SalesReport, error)
+	GetOpsDashboard(ctx context.Context) (*models.OpsDashboard, error)
+	ListCustomerProfiles(ctx context.Context, filter models.SegmentFilter) ([]*models.CustomerProfile, error)
+	GetCustomerProfile(ctx context.Context, customerID string) (*models.CustomerProfile, error)
+	ExportCustomerSegment(ctx context.Context, filter models.SegmentFilter, w io.Writer) error
+	ExportOrders(ctx context.Context, filter models.OrderFilter, w io.Writer) error
+	ExportJournalEntries(ctx context.Context, from, to time.Time, w io.Writer) error
 
 	CreateCategory(ctx context.Context, category *models.Category) error
 	GetCategoryByID(ctx context.Context, id uint64) (*models.Category, error)
 	UpdateCategory(ctx context.Context, category *models.Category) error
 	DeleteCategory(ctx context.Context, id uint64) error
-	ListCategory(ctx context.Context, limit, offset uint64) ([]*models.Category, error)
+	ListCategory(ctx context.Context, limit, offset uint64) (*models.Page[*models.Category], error)
 	ListSubcategories(ctx context.Context, parentID uint64) ([]*models.Category, error)
 	GetCategoryTree(ctx context.Context) ([]*models.CategoryTree, error)
+	SetCategoryTranslation(ctx context.Context, categoryID uint64, locale, name, description string) (*models.CategoryTranslation, error)
+	DeleteCategoryTranslation(ctx context.Context, categoryID uint64, locale string) error
+	SetProductTranslation(ctx context.Context, productID, locale, name, description string) (*models.ProductTranslation, error)
+	DeleteProductTranslation(ctx context.Context, productID, locale string) error
+	UploadProductMedia(ctx context.Context, media *models.ProductMedia, body io.Reader) error
+	CreateProductMedia(ctx context.Context, media *models.ProductMedia) error
+	UpdateProductMedia(ctx context.Context, media *models.ProductMedia) error
+	DeleteProductMedia(ctx context.Context, mediaID uint64) error
+	ListProductMedia(ctx context.Context, productID, priceID string) ([]*models.ProductMedia, error)
+	ExportCategoryTree(ctx context.Context, w io.Writer) error
+	ImportCategoryTree(ctx context.Context, r io.Reader, mode enum.CategoryImportMode, dryRun bool) (*models.CategoryImportResult, error)
 	AssignProductToCategory(ctx context.Context, productID string, categoryID uint64) error
 	RemoveProductFromCategory(ctx context.Context, productID string, categoryID uint64) error
+
+	RunConsistencyChecks(ctx context.Context, autoFix bool) (*models.ConsistencyReport, error)
+	ArchiveOrders(ctx context.Context, olderThanMonths int) (uint64, error)
+	PurgeDeletedOrders(ctx context.Context, retention time.Duration) (uint64, error)
+
+	GetEventPayload(ctx context.Context, eventID string) (json.RawMessage, error)
+	PurgeEventPayloads(ctx context.Context, retention time.Duration) (int64, error)
+	RedriveParkedEvents(ctx context.Context) (int, error)
+
+	ConsumeQuota(ctx context.Context) error
+	GetQuotaUsage(ctx context.Context) []*models.QuotaUsage
+	CheckDependencies(ctx context.Context) error
 }
 
 type service struct {
@@ -53,94 +246,226 @@ type service struct {
 	order    order.Repository
 	event    event.Repository
 	stock    stock.Repository
-
-	transactionManager *driver.TransactionManager
-	eventManager       *EventManager
-	workerPool         *WorkerPool
+	price    price.Repository
+	campaign campaign.Repository
+	coupon   coupon.Repository
+	wishlist wishlist.Repository
+
+	transactionManager   *driver.TransactionManager
+	eventManager         *EventManager
+	workerPool           *WorkerPool
+	eventLocks           *keyedMutex
+	authorize            Authorizer
+	catalog              ProductCatalog
+	customizationSchemas CustomizationSchemas
+	taxCalculator        TaxCalculator
+	featureFlags         FeatureFlags
+	experiments          ExperimentAssigner
+	cartTTL              time.Duration
+	cartSweepInterval    time.Duration
+	abandonThreshold     time.Duration
+	recoveryCoupon       RecoveryCoupon
+	stockViewRefresh     time.Duration
+	lowStockThreshold    uint64
+	searchIndexer        SearchIndexer
+	reportingCurrency    stripe.Currency
+	exchangeRates        ExchangeRateProvider
+	minimumOrderValues   map[stripe.Currency]float64
+	orderNumbers         OrderNumberGenerator
+	refundPolicy         RefundPolicy
+	refunder             PaymentRefunder
+	receiptLinks         ReceiptLinkProvider
+	rebalanceLookback    time.Duration
+	rebalanceCoverDays   int
+	transitTable         CarrierTransitTable
+	warehouseSchedules   map[string]WarehouseSchedule
+	segmentLookback      time.Duration
+	spendTiers           []SpendTier
+	checkoutRecovery     enum.CheckoutRecoveryPolicy
+	defaultLocale        string
+	eventParkTimeout     time.Duration
+	logOptions           *driver.LogOptions
+	checkoutGate         CheckoutGate
+	rateLimiter          RateLimiter
+	defaultQuota         CallerQuota
+	callerQuotas         map[string]CallerQuota
+	quotaUsage           *quotaUsageTracker
+	orderAddonPrices     map[stripe.Currency]map[enum.OrderAddonType]float64
+	mediaStorage         MediaStorage
+	invoicing            *InvoiceConfig
+	revenueAccounts      RevenueAccounts
+	statusMachine        *StatusMachine
+	returnCarrier        ReturnCarrier
+	paymentCanceller     PaymentCanceller
+	cancellationWindow   time.Duration
+	promotionSources     []PromotionSource
+	promotionPolicy      PromotionStackingPolicy
+	priceProtection      enum.PriceProtectionMode
+	paymentAmountUpdater PaymentAmountUpdater
+	unsubscribeSecret    []byte
+	priceRounding        map[string]map[stripe.Currency]PriceRoundingRule
+	fulfillmentSLAs      map[enum.FulfillmentType]FulfillmentSLA
+	startupRetry         StartupRetry
+	dependencyChecks     []DependencyCheck
+
+	fingerprintVelocityLimit  uint64
+	fingerprintVelocityWindow time.Duration
+
+	adjustmentApprovalThreshold uint64
 
 	natsConn *nats.Conn
 	logger   *zap.Logger
 }
 
+// Option 用於調整 service 的選用設定
+type Option func(*service)
+
 func NewService(
-	category category.Repository, cart cart.Repository, order order.Repository, stock stock.Repository, tm *driver.TransactionManager,
+	category category.Repository, cart cart.Repository, order order.Repository, stock stock.Repository, price price.Repository, campaign campaign.Repository, coupon coupon.Repository, tm *driver.TransactionManager,
 	natsConn *nats.Conn,
-	logger *zap.Logger) Service {
+	logger *zap.Logger, opts ...Option) (Service, error) {
 	s := &service{
 		category:           category,
 		cart:               cart,
 		order:              order,
 		stock:              stock,
+		price:              price,
+		campaign:           campaign,
+		coupon:             coupon,
 		transactionManager: tm,
+		eventLocks:         newKeyedMutex(),
+		authorize:          allowAll,
+		taxCalculator:      flatTaxCalculator(defaultTaxRate),
+		featureFlags:       noFeatureFlags{},
+		experiments:        noExperiments{},
+		cartTTL:            defaultCartTTL,
+		cartSweepInterval:  defaultCartExpirySweepInterval,
+		abandonThreshold:   defaultCartAbandonThreshold,
+		stockViewRefresh:   defaultAvailabilityRefreshInterval,
+		lowStockThreshold:  defaultLowStockThreshold,
+		cancellationWindow: defaultCancellationWindow,
+		orderNumbers:       RandomOrderNumbers(defaultOrderNumberDigits),
+		refundPolicy:       DefaultRefundPolicy,
+		revenueAccounts:    DefaultRevenueAccounts,
+		statusMachine:      DefaultStatusMachine(),
+		rebalanceLookback:  defaultRebalanceLookback,
+		rebalanceCoverDays: defaultRebalanceCoverDays,
+		segmentLookback:    defaultSegmentLookback,
+		spendTiers:         DefaultSpendTiers,
+		promotionPolicy:    DefaultPromotionStackingPolicy,
+		priceProtection:    enum.PriceProtectionModeOff,
+		checkoutRecovery:   enum.CheckoutRecoveryPolicyFail,
+		startupRetry:       DefaultStartupRetry,
+		natsConn:           natsConn,
 		logger:             logger,
+
+		adjustmentApprovalThreshold: defaultAdjustmentApprovalThreshold,
+		fingerprintVelocityLimit:    defaultFingerprintVelocityLimit,
+		fingerprintVelocityWindow:   defaultFingerprintVelocityWindow,
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
-	s.eventManager = NewEventManager(natsConn, logger)
-	s.workerPool = NewWorkerPool(10, s, logger)
+	s.setupSearchIndexer()
+	s.logger = s.moduleLogger(logger, driver.LogModuleShop)
+	s.eventManager = NewEventManager(natsConn, s.moduleLogger(logger, driver.LogModuleNats))
+	s.workerPool = NewWorkerPool(10, s, s.moduleLogger(logger, driver.LogModuleWorker))
 	s.registerEventHandlers()
 
-	// 訂閱事件
-	if err := s.eventManager.SubscribeToEvents(s.workerPool); err != nil {
-		logger.Error("Failed to subscribe to events", zap.Error(err))
+	// 確認相依服務可以使用，必要的相依服務重試後仍無法使用時不建立 service
+	ctx := context.Background()
+	if err := s.verifyDependencies(ctx); err != nil {
+		return nil, err
 	}
 
-	return s
-}
-func (s *service) CreateCart(ctx context.Context, customerID string, currency stripe.Currency) (*models.Cart, error) {
+	// 訂閱事件，重新連線後重新建立失效的訂閱
+	if err := s.subscribeEvents(ctx); err != nil {
+		return nil, err
+	}
+	s.watchNatsConnection()
 
-	cartModel := new(models.Cart)
-	err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+	return s, nil
+}
 
-		existingCart, err := s.cart.GetActiveCartByCustomerID(ctx, tx, customerID)
-		if err == nil {
-			cartModel = existingCart
-			return nil
-		}
+// CreateCart 建立購物車，若客戶已有預設的 active 購物車則直接回傳
+func (s *service) CreateCart(ctx context.Context, customerID string, currency stripe.Currency) (*models.Cart, error) {
+	return s.GetOrCreateActiveCart(ctx, customerID, currency)
+}
 
-		newCart := &models.Cart{
-			CustomerID: customerID,
-			Currency:   currency,
-			Status:     enum.CartStatusActive,
-			CreatedAt:  time.Now(),
-			ExpiresAt:  time.Now().AddDate(0, 0, 7),
-		}
+// GetOrCreateActiveCart 取得客戶的預設 active 購物車，不存在時建立新的預設購物車
+func (s *service) GetOrCreateActiveCart(ctx context.Context, customerID string, currency stripe.Currency) (*models.Cart, error) {
+	if err := s.enforceQuota(ctx); err != nil {
+		return nil, err
+	}
 
-		if err = s.cart.CreateCart(ctx, tx, newCart); err != nil {
-			return err
-		}
-		cartModel = newCart
+	var cartModel *models.Cart
 
-		return nil
-	})
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		var err error
+		cartModel, err = s.getOrCreateActiveCart(ctx, tx, customerID, currency)
+		return err
+	}); err != nil {
+		return nil, err
+	}
 
-	return cartModel, err
+	return cartModel, nil
 }
 
-func (s *service) GetOrCreateActiveCart(ctx context.Context, customerID string, currency stripe.Currency) (*models.Cart, error) {
+// getOrCreateActiveCart 在呼叫端的交易內取得或建立預設的 active 購物車
+func (s *service) getOrCreateActiveCart(ctx context.Context, tx pgx.Tx, customerID string, currency stripe.Currency) (*models.Cart, error) {
+	existingCart, err := s.cart.GetActiveCartByCustomerID(ctx, tx, customerID)
+	if err == nil && existingCart.Status == enum.CartStatusActive {
+		return existingCart, nil
+	}
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get active cart: %w", err)
+	}
 
-	cartModel, err := s.cart.GetActiveCartByCustomerID(ctx, nil, customerID)
-	if err == nil {
-		return cartModel, nil
+	// 沒有預設購物車時，以預設名稱命名的 active 購物車（預設曾改為其他購物車，而該購物車已結帳）改回預設購物車
+	namedCart, err := s.cart.GetActiveCartByName(ctx, tx, customerID, defaultCartName)
+	switch {
+	case err == nil:
+		if _, err = s.cart.SetDefaultCart(ctx, tx, customerID, namedCart.ID); err != nil {
+			return nil, fmt.Errorf("failed to set default cart: %w", err)
+		}
+		namedCart.IsDefault = true
+		return namedCart, nil
+	case !errors.Is(err, pgx.ErrNoRows):
+		return nil, fmt.Errorf("failed to get cart %q: %w", defaultCartName, err)
 	}
 
+	return s.createCart(ctx, tx, customerID, defaultCartName, true, currency)
+}
+
+// createCart 在呼叫端的交易內建立 active 購物車並分派實驗組別
+func (s *service) createCart(ctx context.Context, tx pgx.Tx, customerID, name string, isDefault bool, currency stripe.Currency) (*models.Cart, error) {
 	newCart := &models.Cart{
 		CustomerID: customerID,
+		Name:       name,
+		IsDefault:  isDefault,
 		Currency:   currency,
 		Status:     enum.CartStatusActive,
 		CreatedAt:  time.Now(),
-		ExpiresAt:  time.Now().AddDate(0, 0, 7),
+		ExpiresAt:  time.Now().Add(s.cartTTL),
 	}
 
-	if err = s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
-		return s.cart.CreateCart(ctx, tx, newCart)
-	}); err != nil {
+	if err := s.cart.CreateCart(ctx, tx, newCart); err != nil {
+		return nil, fmt.Errorf("failed to create cart: %w", err)
+	}
+	if err := s.assignExperiments(ctx, tx, newCart); err != nil {
 		return nil, err
 	}
 
 	return newCart, nil
 }
 
-func (s *service) AddItemsToCart(ctx context.Context, customerID string, cartID uint64, items []*models.CartItem, currency stripe.Currency) error {
-	return s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+// AddItemsToCart 將商品加入購物車並預留庫存，購物車已非 active 時會改加到同名的新購物車，回傳實際使用的購物車 ID
+func (s *service) AddItemsToCart(ctx context.Context, customerID string, cartID uint64, items []*models.CartItem, currency stripe.Currency) (uint64, error) {
+	if err := s.enforceQuota(ctx); err != nil {
+		return 0, err
+	}
+
+	err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
 		// 1. 獲得購物車
 		cartModel, err := s.cart.GetCart(ctx, tx, cartID)
 		if err != nil {
@@ -149,285 +474,431 @@ func (s *service) AddItemsToCart(ctx context.Context, customerID string, cartID
 
 		// 2. 檢查購物車狀態
 		if cartModel.Status != enum.CartStatusActive {
-			// 如果購物車狀態不是 active，創建新的購物車
-			newCart, err := s.GetOrCreateActiveCart(ctx, customerID, currency)
+			// 如果購物車狀態不是 active，在同一個交易內取得或創建同名的新購物車
+			newCart, err := s.getOrCreateNamedCart(ctx, tx, customerID, cartModel.Name, currency)
 			if err != nil {
 				return fmt.Errorf("failed to create new cart: %w", err)
 			}
-			cartModel = newCart
+			if err = s.carryCartChannel(ctx, tx, newCart, cartModel.Channel); err != nil {
+				return err
+			}
 			cartID = newCart.ID
 		}
 
-		adjustParams := make([]stock.AdjustStockParams, 0, len(items))
-		moveParams := make([]stock.CreateStockMovementParams, 0, len(items))
+		return s.addItemsToCart(ctx, tx, cartID, items)
+	})
+	if err != nil {
+		return 0, err
+	}
 
-		for _, item := range items {
-			// 3. 檢查庫存
-			stockModel, err := s.stock.GetStock(ctx, tx, item.StockID)
+	return cartID, nil
+}
+
+// addItemsToCart 在呼叫端的交易內將商品加入指定的購物車並預留庫存
+func (s *service) addItemsToCart(ctx context.Context, tx pgx.Tx, cartID uint64, items []*models.CartItem) error {
+	adjustParams := make([]stock.AdjustStockParams, 0, len(items))
+	moveParams := make([]stock.CreateStockMovementParams, 0, len(items))
+
+	cartModel, err := s.cart.GetCart(ctx, tx, cartID)
+	if err != nil {
+		return fmt.Errorf("failed to get cart: %w", err)
+	}
+
+	// 同一庫存可能有多個項目（例如客製化商品各自一行），可用量以同一批加入的總數量檢查
+	requested := make(map[uint64]uint64, len(items))
+	for _, item := range items {
+		// 1. 有銷售通路的購物車只能加入通路目錄中的商品，並以通路價格表的單價計算
+		if cartModel.Channel != "" {
+			unitPrice, ok, err := s.channelUnitPrice(ctx, tx, cartModel, item.ProductID, item.PriceID)
 			if err != nil {
-				return fmt.Errorf("failed to get stock for item %s: %w", item.ProductID, err)
+				return err
 			}
-			if stockModel.Quantity-stockModel.ReservedQuantity < item.Quantity {
-				return fmt.Errorf("insufficient stock for item %s", item.ProductID)
+			if ok {
+				item.UnitPrice = unitPrice
+				item.Subtotal = float64(item.Quantity) * unitPrice
 			}
+		}
 
-			// 4. 檢查是否已存在相同商品
-			existingItem, err := s.cart.GetCartItemByProductID(ctx, tx, cartID, item.ProductID)
-			if err == nil {
-				// 商品已存在，更新數量和小計
-				existingItem.Quantity += item.Quantity
-				existingItem.Subtotal = float64(existingItem.Quantity) * existingItem.UnitPrice
+		// 2. 檢查庫存（如有指定地點，只檢查該地點的庫存）
+		stockModel, err := s.resolveItemStock(ctx, tx, item)
+		if err != nil {
+			return fmt.Errorf("failed to get stock for item %s: %w", item.ProductID, err)
+		}
+		if stockModel.RentalEnabled {
+			return fmt.Errorf("item %s is rental-only and cannot be added to a cart", item.ProductID)
+		}
+		requested[stockModel.ID] += item.Quantity
+		if stockModel.Quantity < stockModel.ReservedQuantity || stockModel.Quantity-stockModel.ReservedQuantity < requested[stockModel.ID] {
+			return fmt.Errorf("insufficient stock for item %s at location %q", item.ProductID, stockModel.Location)
+		}
 
-				if err = s.cart.UpdateCartItem(ctx, tx, existingItem); err != nil {
-					return fmt.Errorf("failed to update cart item %s: %w", item.ProductID, err)
-				}
-			} else if err != nil && !errors.Is(err, sql.ErrNoRows) {
-				return fmt.Errorf("failed to check existing cart item %s: %w", item.ProductID, err)
-			} else {
-				// 商品不存在，添加新的購物車項目
-				if err = s.cart.AddCartItem(ctx, tx, cartID, item); err != nil {
-					return fmt.Errorf("failed to add cart item %s: %w", item.ProductID, err)
-				}
+		// 3. 驗證客製化內容、備註與自訂屬性
+		if item.Customization, err = s.validateCustomization(ctx, item.ProductID, item.Customization); err != nil {
+			return fmt.Errorf("failed to validate customization for item %s: %w", item.ProductID, err)
+		}
+		if item.Note, err = validateCartItemMetadata(item.Note, item.Metadata); err != nil {
+			return fmt.Errorf("invalid note or metadata for item %s: %w", item.ProductID, err)
+		}
+
+		// 4. 檢查是否已存在相同商品，帶有備註、自訂屬性時只合併到備註與自訂屬性完全相同的項目，
+		// 客製化的商品每次都新增為獨立的項目
+		var existingItem *models.CartItem
+		var itemID uint64
+		err = pgx.ErrNoRows
+		switch {
+		case item.Customization != nil:
+		case item.Note == "" && len(item.Metadata) == 0:
+			existingItem, err = s.cart.GetCartItemByProductID(ctx, tx, cartID, item.ProductID)
+		default:
+			existingItem, err = s.findCartItemWithMetadata(ctx, tx, cartID, item)
+		}
+		if err == nil {
+			if existingItem.StockID != item.StockID {
+				return fmt.Errorf("item %s is already in cart from location %q", item.ProductID, existingItem.Location)
 			}
 
-			// 準備庫存調整參數
-			adjustParams = append(adjustParams, stock.AdjustStockParams{
-				StockID:     item.StockID,
-				Quantity:    item.Quantity,
-				LastUpdated: stockModel.UpdatedAt,
-			})
+			// 商品已存在，更新數量和小計
+			existingItem.Quantity += item.Quantity
+			if err = s.checkFlashSaleLimit(ctx, item.ProductID, existingItem.Quantity); err != nil {
+				return err
+			}
+			existingItem.Subtotal = float64(existingItem.Quantity) * existingItem.UnitPrice
 
-			// 準備庫存變動記錄參數
-			moveParams = append(moveParams, stock.CreateStockMovementParams{
-				StockID:       item.StockID,
-				Quantity:      item.Quantity,
-				Type:          enum.StockMovementTypeReserve,
-				ReferenceID:   cartID,
-				ReferenceType: enum.StockMovementReferenceTypeCart,
-			})
+			if err = s.cart.UpdateCartItem(ctx, tx, existingItem); err != nil {
+				return fmt.Errorf("failed to update cart item %s: %w", item.ProductID, err)
+			}
+			itemID = existingItem.ID
+		} else if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("failed to check existing cart item %s: %w", item.ProductID, err)
+		} else {
+			// 商品不存在，添加新的購物車項目
+			if err = s.checkFlashSaleLimit(ctx, item.ProductID, item.Quantity); err != nil {
+				return err
+			}
+			if err = s.cart.AddCartItem(ctx, tx, cartID, item); err != nil {
+				return fmt.Errorf("failed to add cart item %s: %w", item.ProductID, err)
+			}
+			itemID = item.ID
 		}
 
-		// 5. 批量調整庫存
-		if err = s.stock.AdjustStock(ctx, tx, adjustParams); err != nil {
-			return fmt.Errorf("failed to adjust stock: %w", err)
-		}
+		// 準備庫存調整參數
+		adjustParams = append(adjustParams, stock.AdjustStockParams{
+			StockID:     item.StockID,
+			Quantity:    item.Quantity,
+			LastUpdated: stockModel.UpdatedAt,
+		})
+
+		// 準備庫存變動記錄參數
+		moveParams = append(moveParams, stock.CreateStockMovementParams{
+			StockID:         item.StockID,
+			Quantity:        item.Quantity,
+			Type:            enum.StockMovementTypeReserve,
+			ReferenceID:     cartID,
+			ReferenceType:   enum.StockMovementReferenceTypeCart,
+			ReferenceItemID: itemID,
+		})
+	}
 
-		// 6. 批量創建庫存變動記錄
-		if err = s.stock.CreateStockMovements(ctx, tx, moveParams); err != nil {
-			return fmt.Errorf("failed to create stock movements: %w", err)
-		}
+	// 5. 批量調整庫存，同一庫存的項目合併後一次調整
+	if err := s.stock.AdjustStock(ctx, tx, adjustParams); err != nil {
+		return fmt.Errorf("failed to adjust stock: %w", err)
+	}
 
-		return nil
-	})
+	// 6. 批量創建庫存變動記錄
+	if err := s.stock.CreateStockMovements(ctx, tx, moveParams); err != nil {
+		return fmt.Errorf("failed to create stock movements: %w", err)
+	}
+
+	// 7. 延長購物車期限
+	if err := s.touchCart(ctx, tx, cartID); err != nil {
+		return err
+	}
+
+	// 8. 重新計算購物車金額
+	return s.recalculateCartTotals(ctx, tx, cartID)
 }
 
+// resolveItemStock 根據購物車項目指定的 StockID 或地點取得庫存，並將實際的 StockID 與地點寫回項目
+func (s *service) resolveItemStock(ctx context.Context, tx pgx.Tx, item *models.CartItem) (*models.Stock, error) {
+	var stockModel *models.Stock
+	var err error
+
+	if item.StockID == 0 && item.Location != "" {
+		stockModel, err = s.stock.GetStockByProductAndLocation(ctx, tx, item.ProductID, item.Location)
+	} else {
+		stockModel, err = s.stock.GetStock(ctx, tx, item.StockID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if stockModel.ProductID != item.ProductID {
+		return nil, fmt.Errorf("stock %d does not belong to product %s", stockModel.ID, item.ProductID)
+	}
+	if item.Location != "" && stockModel.Location != item.Location {
+		return nil, fmt.Errorf("stock %d is not at location %q", stockModel.ID, item.Location)
+	}
+
+	item.StockID = stockModel.ID
+	item.Location = stockModel.Location
+
+	return stockModel, nil
+}
+
+// RemoveItemFromCart 從購物車移除商品，並釋放該商品預留的庫存
 func (s *service) RemoveItemFromCart(ctx context.Context, cartID, itemID uint64) error {
 	return s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
-		item, err := s.cart.GetCartItem(ctx, tx, itemID)
-		if err != nil {
-			return err
-		}
+		_, err := s.removeCartItem(ctx, tx, cartID, itemID)
+		return err
+	})
+}
 
-		stockModel, err := s.stock.GetStock(ctx, tx, item.StockID)
-		if err != nil {
-			return fmt.Errorf("failed to get stock: %w", err)
-		}
-		if stockModel.Quantity-stockModel.ReservedQuantity < item.Quantity {
-			return errors.New("insufficient stock")
-		}
+// removeCartItem 在呼叫端的交易內從購物車移除商品並釋放預留的庫存，回傳被移除的項目
+func (s *service) removeCartItem(ctx context.Context, tx pgx.Tx, cartID, itemID uint64) (*models.CartItem, error) {
+	// 1. 獲取購物車項目
+	item, err := s.cart.GetCartItem(ctx, tx, itemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cart item: %w", err)
+	}
+	if item.CartID != cartID {
+		return nil, fmt.Errorf("item %d does not belong to cart %d", itemID, cartID)
+	}
 
-		if err = s.cart.RemoveCartItem(ctx, tx, itemID); err != nil {
-			return err
-		}
+	stockModel, err := s.stock.GetStock(ctx, tx, item.StockID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stock: %w", err)
+	}
 
-		adjustParams := []stock.AdjustStockParams{
-			{
-				StockID:     item.StockID,
-				Quantity:    item.Quantity,
-				LastUpdated: stockModel.UpdatedAt,
-			},
-		}
-		if err = s.stock.AdjustStock(ctx, tx, adjustParams); err != nil {
-			return fmt.Errorf("failed to adjust stock: %w", err)
-		}
+	// 2. 移除購物車項目
+	if err = s.cart.RemoveCartItem(ctx, tx, itemID); err != nil {
+		return nil, fmt.Errorf("failed to remove cart item: %w", err)
+	}
 
-		moveParams := []stock.CreateStockMovementParams{
-			{
-				StockID:       item.StockID,
-				Quantity:      item.Quantity,
-				Type:          enum.StockMovementTypeReserve,
-				ReferenceID:   cartID,
-				ReferenceType: enum.StockMovementReferenceTypeCart,
-			},
-		}
-		if err = s.stock.CreateStockMovements(ctx, tx, moveParams); err != nil {
-			return fmt.Errorf("failed to create stock movement: %w", err)
-		}
+	// 3. 釋放預留庫存
+	if err = s.stock.ReleaseStock(ctx, tx, []stock.ReleaseStockParams{
+		{
+			StockID:     item.StockID,
+			Quantity:    item.Quantity,
+			LastUpdated: stockModel.UpdatedAt,
+		},
+	}); err != nil {
+		return nil, fmt.Errorf("failed to release stock: %w", err)
+	}
 
-		return nil
-	})
+	// 4. 創建庫存變動記錄
+	if err = s.stock.CreateStockMovements(ctx, tx, []stock.CreateStockMovementParams{
+		{
+			StockID:         item.StockID,
+			Quantity:        item.Quantity,
+			Type:            enum.StockMovementTypeRelease,
+			ReferenceID:     cartID,
+			ReferenceType:   enum.StockMovementReferenceTypeCart,
+			ReferenceItemID: item.ID,
+		},
+	}); err != nil {
+		return nil, fmt.Errorf("failed to create stock movement: %w", err)
+	}
+
+	// 5. 延長購物車期限
+	if err = s.touchCart(ctx, tx, cartID); err != nil {
+		return nil, err
+	}
+
+	// 6. 重新計算購物車金額
+	if err = s.recalculateCartTotals(ctx, tx, cartID); err != nil {
+		return nil, err
+	}
+
+	return item, nil
 }
 
+// ClearCart 清空購物車並釋放預留庫存，最後將購物車更新為指定狀態
 func (s *service) ClearCart(ctx context.Context, cartID uint64, status enum.CartStatus) error {
-	return s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
-		// 1. 獲取購物車
-		if _, err := s.cart.GetCart(ctx, tx, cartID); err != nil {
-			return fmt.Errorf("failed to get cart: %w", err)
-		}
+	cartModel, err := s.clearCart(ctx, cartID, status, ActionClearCart)
+	if err != nil {
+		return err
+	}
 
-		// 2. 獲取購物車項目
-		items, err := s.cart.ListCartItems(ctx, tx, cartID)
+	s.publishCartEvent(ctx, SubjectCartCleared, cartModel)
+	return nil
+}
+
+// AbandonCart 放棄購物車，釋放預留庫存並標記為 abandoned，再依客戶的通知偏好發送購物車放棄提醒
+func (s *service) AbandonCart(ctx context.Context, cartID uint64) error {
+	cartModel, err := s.clearCart(ctx, cartID, enum.CartStatusAbandoned, ActionAbandonCart)
+	if err != nil {
+		return err
+	}
+
+	s.publishCartEvent(ctx, SubjectCartAbandoned, cartModel)
+	s.notifyAbandonedCart(ctx, cartModel)
+	return nil
+}
+
+func (s *service) clearCart(ctx context.Context, cartID uint64, status enum.CartStatus, action Action) (*models.Cart, error) {
+	var cartModel *models.Cart
+
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		// 1. 獲取購物車並檢查權限
+		var err error
+		cartModel, err = s.cart.GetCart(ctx, tx, cartID)
 		if err != nil {
-			return fmt.Errorf("failed to list cart items: %w", err)
+			return fmt.Errorf("failed to get cart: %w", err)
+		}
+		if err = s.checkAuthorization(ctx, AuthorizationRequest{
+			Action:     action,
+			ResourceID: cartID,
+			CustomerID: cartModel.CustomerID,
+		}); err != nil {
+			return err
 		}
 
-		if len(items) > 0 {
-			// 3. 準備庫存釋放參數
-			releaseParams := make([]stock.ReleaseStockParams, len(items))
-			moveParams := make([]stock.CreateStockMovementParams, len(items))
+		// 2. 釋放預留庫存、清空購物車並更新狀態
+		return s.releaseCart(ctx, tx, cartID, status)
+	}); err != nil {
+		return nil, err
+	}
 
-			for i, item := range items {
-				stockModel, err := s.stock.GetStock(ctx, tx, item.StockID)
-				if err != nil {
-					return fmt.Errorf("failed to get stock for item %s: %w", item.ProductID, err)
-				}
+	cartModel.Status = status
+	return cartModel, nil
+}
 
-				releaseParams[i] = stock.ReleaseStockParams{
-					StockID:     item.StockID,
-					Quantity:    item.Quantity,
-					LastUpdated: stockModel.UpdatedAt,
-				}
+// releaseCart 在呼叫端的交易內釋放購物車項目預留的庫存並記錄 release 庫存變動，清空項目與金額後將購物車標記為 status
+func (s *service) releaseCart(ctx context.Context, tx pgx.Tx, cartID uint64, status enum.CartStatus) error {
+	// 1. 獲取購物車項目
+	items, err := s.cart.ListCartItems(ctx, tx, cartID)
+	if err != nil {
+		return fmt.Errorf("failed to list cart items: %w", err)
+	}
 
-				moveParams[i] = stock.CreateStockMovementParams{
-					StockID:       item.StockID,
-					Quantity:      item.Quantity,
-					Type:          enum.StockMovementTypeRelease,
-					ReferenceID:   cartID,
-					ReferenceType: enum.StockMovementReferenceTypeCart,
-				}
+	if len(items) > 0 {
+		// 2. 準備庫存釋放參數
+		releaseParams := make([]stock.ReleaseStockParams, len(items))
+		moveParams := make([]stock.CreateStockMovementParams, len(items))
+
+		for i, item := range items {
+			stockModel, err := s.stock.GetStock(ctx, tx, item.StockID)
+			if err != nil {
+				return fmt.Errorf("failed to get stock for item %s: %w", item.ProductID, err)
 			}
 
-			// 4. 批量釋放庫存
-			if err = s.stock.ReleaseStock(ctx, tx, releaseParams); err != nil {
-				return fmt.Errorf("failed to release stock: %w", err)
+			releaseParams[i] = stock.ReleaseStockParams{
+				StockID:     item.StockID,
+				Quantity:    item.Quantity,
+				LastUpdated: stockModel.UpdatedAt,
 			}
 
-			// 5. 批量創建庫存變動記錄
-			if err = s.stock.CreateStockMovements(ctx, tx, moveParams); err != nil {
-				return fmt.Errorf("failed to create stock movements: %w", err)
+			moveParams[i] = stock.CreateStockMovementParams{
+				StockID:         item.StockID,
+				Quantity:        item.Quantity,
+				Type:            enum.StockMovementTypeRelease,
+				ReferenceID:     cartID,
+				ReferenceType:   enum.StockMovementReferenceTypeCart,
+				ReferenceItemID: item.ID,
 			}
 		}
 
-		// 6. 清空購物車項目
-		if err = s.cart.ClearCartItems(ctx, tx, cartID); err != nil {
-			return fmt.Errorf("failed to clear cart items: %w", err)
+		// 3. 批量釋放庫存，同一庫存的項目合併後一次釋放
+		if err = s.stock.ReleaseStock(ctx, tx, releaseParams); err != nil {
+			return fmt.Errorf("failed to release stock: %w", err)
 		}
 
-		// 7. 更新購物車狀態
-		if err = s.cart.UpdateCartStatus(ctx, tx, cartID, status); err != nil {
-			return fmt.Errorf("failed to update cart status: %w", err)
+		// 4. 批量創建庫存變動記錄
+		if err = s.stock.CreateStockMovements(ctx, tx, moveParams); err != nil {
+			return fmt.Errorf("failed to create stock movements: %w", err)
 		}
+	}
 
-		return nil
-	})
+	// 5. 清空購物車項目並歸零金額
+	if err = s.cart.ClearCartItems(ctx, tx, cartID); err != nil {
+		return fmt.Errorf("failed to clear cart items: %w", err)
+	}
+	if err = s.cart.UpdateCartTotals(ctx, tx, cartID, 0, 0, 0); err != nil {
+		return fmt.Errorf("failed to update cart totals: %w", err)
+	}
+	if err = s.cart.SetCartPromotions(ctx, tx, cartID, nil); err != nil {
+		return fmt.Errorf("failed to set cart promotions: %w", err)
+	}
+
+	// 6. 更新購物車狀態
+	if err = s.cart.UpdateCartStatus(ctx, tx, cartID, status); err != nil {
+		return fmt.Errorf("failed to update cart status: %w", err)
+	}
+
+	return nil
+}
+
+func (s *service) publishCartEvent(ctx context.Context, subject string, cartModel *models.Cart) {
+	// 通知失敗不影響購物車狀態
+	if err := s.eventManager.Publish(ctx, subject, &CartEvent{
+		CartID:     cartModel.ID,
+		CustomerID: cartModel.CustomerID,
+		Status:     cartModel.Status,
+		OccurredAt: time.Now(),
+	}); err != nil {
+		s.log(ctx).Warn("Failed to publish cart event", zap.String("subject", subject), zap.Uint64("cart_id", cartModel.ID), zap.Error(err))
+	}
 }
 
+// UpdateCartItemQuantity 變更單一購物車項目的數量，newQuantity 為 0 時移除該項目；
+// 與 UpdateCartItems 使用相同的流程，以帶正負號的差額調整預留量
 func (s *service) UpdateCartItemQuantity(ctx context.Context, cartID, itemID, newQuantity uint64) error {
-	return s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
-		// 1. 獲取購物車項目
-		item, err := s.cart.GetCartItem(ctx, tx, itemID)
-		if err != nil {
-			return fmt.Errorf("failed to get cart item: %w", err)
-		}
+	return s.UpdateCartItems(ctx, cartID, []CartItemUpdate{{ItemID: itemID, Quantity: newQuantity}})
+}
 
-		if item.CartID != cartID {
-			return fmt.Errorf("cart item does not belong to the specified cart")
-		}
+// ErrCheckoutInProgress 表示同一位客戶已有另一個結帳流程正在進行
+var ErrCheckoutInProgress = errors.New("checkout already in progress for customer")
 
-		// 2. 計算數量差異
-		quantityDiff := newQuantity - item.Quantity
+// ConvertCartToOrder 這個功能將會從購物車生成訂單，並且扣減庫存；
+// 同一位客戶同時只能有一個結帳流程，重複送出（例如兩個分頁同時結帳）會回傳 ErrCheckoutInProgress；
+// 設定 CheckoutGate 時，結帳流量超過上限會回傳 *CheckoutSaturatedError
+func (s *service) ConvertCartToOrder(ctx context.Context, cartID uint64) (*models.Order, error) {
+	if err := s.enforceQuota(ctx); err != nil {
+		return nil, err
+	}
+	if err := s.enterCheckout(ctx); err != nil {
+		return nil, err
+	}
 
-		// 3. 獲取庫存信息
-		stockModel, err := s.stock.GetStock(ctx, tx, item.StockID)
+	var customerID string
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		cartModel, err := s.cart.GetCart(ctx, tx, cartID)
 		if err != nil {
-			return fmt.Errorf("failed to get stock: %w", err)
-		}
-
-		// 4. 檢查庫存是否足夠（如果是增加數量）
-		if quantityDiff > 0 && stockModel.Quantity-stockModel.ReservedQuantity < quantityDiff {
-			return fmt.Errorf("insufficient stock")
+			return fmt.Errorf("failed to get cart: %w", err)
 		}
+		customerID = cartModel.CustomerID
+		return nil
+	}); err != nil {
+		return nil, err
+	}
 
-		// 5. 更新購物車項目
-		item.Quantity = newQuantity
-		item.Subtotal = float64(newQuantity) * item.UnitPrice
+	newOrder, err := s.convertCartToOrder(ctx, customerID, cartID)
+	if errors.Is(err, driver.ErrLockNotAcquired) {
+		return nil, ErrCheckoutInProgress
+	}
+	if err != nil {
+		return nil, err
+	}
 
-		if err = s.cart.UpdateCartItem(ctx, tx, item); err != nil {
-			return fmt.Errorf("failed to update cart item: %w", err)
-		}
+	return newOrder, nil
+}
 
-		// 6. 調整庫存
-		var adjustParams []stock.AdjustStockParams
-		var moveParams []stock.CreateStockMovementParams
+// convertCartToOrder 在交易內取得客戶結帳鎖後建立訂單，購物車狀態在交易內重新檢查；鎖隨交易結束釋放。
+// 使用外部稅務服務時先在交易外計算稅額，交易內的訂單內容與計算時不同時回傳包裝後的 ErrOrderTaxQuoteStale
+func (s *service) convertCartToOrder(ctx context.Context, customerID string, cartID uint64) (*models.Order, error) {
+	taxQuote, err := s.quoteOrderTax(ctx, cartID)
+	if err != nil {
+		return nil, err
+	}
 
-		if quantityDiff > 0 {
-			adjustParams = []stock.AdjustStockParams{
-				{
-					StockID:     item.StockID,
-					Quantity:    quantityDiff,
-					LastUpdated: stockModel.UpdatedAt,
-				},
-			}
-			moveParams = []stock.CreateStockMovementParams{
-				{
-					StockID:       item.StockID,
-					Quantity:      quantityDiff,
-					Type:          enum.StockMovementTypeReserve,
-					ReferenceID:   cartID,
-					ReferenceType: enum.StockMovementReferenceTypeCart,
-				},
-			}
-			if err = s.stock.AdjustStock(ctx, tx, adjustParams); err != nil {
-				return fmt.Errorf("failed to adjust stock: %w", err)
-			}
-		} else if quantityDiff < 0 {
-			releaseParams := []stock.ReleaseStockParams{
-				{
-					StockID:     item.StockID,
-					Quantity:    -quantityDiff,
-					LastUpdated: stockModel.UpdatedAt,
-				},
-			}
-			moveParams = []stock.CreateStockMovementParams{
-				{
-					StockID:       item.StockID,
-					Quantity:      -quantityDiff,
-					Type:          enum.StockMovementTypeRelease,
-					ReferenceID:   cartID,
-					ReferenceType: enum.StockMovementReferenceTypeCart,
-				},
-			}
-			if err = s.stock.ReleaseStock(ctx, tx, releaseParams); err != nil {
-				return fmt.Errorf("failed to release stock: %w", err)
-			}
-		}
+	var newOrder *models.Order
 
-		// 7. 創建庫存變動記錄（如果數量有變化）
-		if quantityDiff != 0 {
-			if err = s.stock.CreateStockMovements(ctx, tx, moveParams); err != nil {
-				return fmt.Errorf("failed to create stock movement: %w", err)
-			}
+	if err = s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		// 結帳鎖須為交易的第一個查詢，交易快照才會包含前一個結帳已提交的購物車狀態
+		if err := s.transactionManager.TryAdvisoryXactLock(ctx, tx, "checkout:"+customerID); err != nil {
+			return err
 		}
 
-		return nil
-	})
-}
-
-// ConvertCartToOrder 這個功能將會從購物車生成訂單，並且扣減庫存
-func (s *service) ConvertCartToOrder(ctx context.Context, cartID uint64) (*models.Order, error) {
-	var newOrder *models.Order
-
-	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
 		var err error
 
 		// 1. 獲取購物車
@@ -450,23 +921,72 @@ func (s *service) ConvertCartToOrder(ctx context.Context, cartID uint64) (*model
 			return fmt.Errorf("cart is empty")
 		}
 
-		// 3. 創建訂單
+		// 3. 以下單當下有效的優惠重新計算金額
+		pricing, err := s.priceCartItems(ctx, tx, cartModel, cartItems, time.Now())
+		if err != nil {
+			return err
+		}
+
+		// 4. 結帳前檢查（庫存、數量上限、地址與最低金額）
+		addresses, err := s.cart.GetCartAddresses(ctx, tx, cartID)
+		if err != nil {
+			return fmt.Errorf("failed to get cart addresses: %w", err)
+		}
+		validation, err := s.validateCart(ctx, tx, cartModel, cartItems, addresses, pricing, time.Now())
+		if err != nil {
+			return err
+		}
+		if !validation.Ready {
+			return cartNotReadyError(validation)
+		}
+
+		// 5. 創建訂單
 		newOrder = &models.Order{
 			CustomerID: cartModel.CustomerID,
 			CartID:     &cartID,
 			Status:     enum.OrderStatusPending,
 			Currency:   cartModel.Currency,
-			Subtotal:   cartModel.Subtotal,
-			Tax:        cartModel.Tax,
-			Discount:   cartModel.Discount,
-			Total:      cartModel.Total,
+			Subtotal:   pricing.Subtotal,
+			Tax:        pricing.Tax,
+			Discount:   pricing.Discount,
+			Total:      pricing.Subtotal + pricing.Tax - pricing.Discount,
+
+			ShippingAddress: addresses.ShippingAddress,
+			BillingAddress:  addresses.BillingAddress,
+		}
+
+		if _, err = s.createOrder(ctx, tx, newOrder); err != nil {
+			return fmt.Errorf("failed to create order: %w", err)
+		}
+		if cartModel.Channel != "" {
+			if err = s.order.SetOrderChannel(ctx, tx, newOrder.ID, cartModel.Channel); err != nil {
+				return fmt.Errorf("failed to set order channel: %w", err)
+			}
+			newOrder.Channel = cartModel.Channel
 		}
 
-		if _, err = s.order.CreateOrder(ctx, tx, newOrder); err != nil {
-			return fmt.Errorf("failed to create order: %w", err)
+		// 6. 記錄套用的優惠、活動折扣明細與購物車快照
+		if err = s.order.SetOrderPromotions(ctx, tx, newOrder.ID, pricing.Promotions); err != nil {
+			return fmt.Errorf("failed to set order promotions: %w", err)
+		}
+		newOrder.AppliedPromotions = pricing.Promotions
+		if newOrder.CartSnapshot, err = s.recordCartSnapshot(ctx, tx, newOrder.ID, cartModel, cartItems, pricing, time.Now()); err != nil {
+			return err
+		}
+		if err = s.recordOrderTaxExemption(ctx, tx, newOrder.ID, pricing.Exemption); err != nil {
+			return err
+		}
+		if err = s.recordCampaignRedemptions(ctx, tx, newOrder.ID, pricing); err != nil {
+			return err
+		}
+		if err = s.redeemCoupon(ctx, tx, newOrder, pricing); err != nil {
+			return err
+		}
+		if err = s.revokeRecoveryCoupons(ctx, tx, cartID); err != nil {
+			return err
 		}
 
-		// 4. 創建訂單項目並調整庫存
+		// 7. 創建訂單項目並調整庫存
 		orderItems := make([]*models.OrderItem, len(cartItems))
 		reduceStockParams := make([]stock.ReduceStockParams, len(cartItems))
 		stockMoveParams := make([]stock.CreateStockMovementParams, len(cartItems))
@@ -480,7 +1000,15 @@ func (s *service) ConvertCartToOrder(ctx context.Context, cartID uint64) (*model
 				Quantity:  item.Quantity,
 				UnitPrice: item.UnitPrice,
 				Subtotal:  item.Subtotal,
+				TaxRate:   item.TaxRate,
+				TaxAmount: item.TaxAmount,
+				Location:  item.Location,
+
+				Customization: item.Customization,
+				Note:          item.Note,
+				Metadata:      item.Metadata,
 			}
+			s.snapshotOrderItem(ctx, orderItems[i])
 
 			stockModel, err := s.stock.GetStock(ctx, tx, item.StockID)
 			if err != nil {
@@ -502,26 +1030,49 @@ func (s *service) ConvertCartToOrder(ctx context.Context, cartID uint64) (*model
 			}
 		}
 
-		// 5. 批量創建訂單項目
+		// 8. 使用外部稅務服務時，以交易外對整筆訂單計算的稅額取代預估稅額
+		if err = s.applyOrderTax(ctx, tx, newOrder, orderItems, pricing.LineDiscounts, pricing.Exemption, taxQuote); err != nil {
+			return err
+		}
+
+		// 9. 記錄換算成報表幣別的匯率快照
+		if err = s.recordReportingSnapshot(ctx, tx, newOrder); err != nil {
+			return err
+		}
+
+		// 10. 記錄預估送達日期
+		if err = s.recordDeliveryEstimate(ctx, tx, newOrder, orderItems); err != nil {
+			return err
+		}
+
+		// 11. 批量創建訂單項目，庫存變動記錄對應到新建立的項目
 		if err = s.order.AddOrderItems(ctx, tx, orderItems); err != nil {
 			return fmt.Errorf("failed to add order items: %w", err)
 		}
+		for i, orderItem := range orderItems {
+			stockMoveParams[i].ReferenceItemID = orderItem.ID
+		}
 
-		// 6. 批量減少庫存
+		// 12. 批量減少庫存，同一庫存的項目合併後一次扣除
 		if err = s.stock.ReduceStock(ctx, tx, reduceStockParams); err != nil {
 			return fmt.Errorf("failed to reduce stock: %w", err)
 		}
 
-		// 7. 批量創建庫存變動記錄
+		// 13. 批量創建庫存變動記錄
 		if err = s.stock.CreateStockMovements(ctx, tx, stockMoveParams); err != nil {
 			return fmt.Errorf("failed to create stock movements: %w", err)
 		}
 
-		// 8. 更新購物車狀態
+		// 14. 更新購物車狀態
 		if err = s.cart.UpdateCartStatus(ctx, tx, cartID, enum.CartStatusConverted); err != nil {
 			return fmt.Errorf("failed to update cart status: %w", err)
 		}
 
+		// 15. 將購物車的實驗組別記錄到訂單
+		if err = s.cart.AttachExperimentAssignmentsToOrder(ctx, tx, cartID, newOrder.ID); err != nil {
+			return fmt.Errorf("failed to attach experiment assignments: %w", err)
+		}
+
 		return nil
 	}); err != nil {
 		return nil, err
@@ -530,22 +1081,57 @@ func (s *service) ConvertCartToOrder(ctx context.Context, cartID uint64) (*model
 	return newOrder, nil
 }
 
-// CreateOrder 手動創建訂單，這可能適用於後台或特殊業務需求
-func (s *service) CreateOrder(ctx context.Context, order *models.Order) error {
+// CreateOrder 手動創建訂單，這可能適用於後台或特殊業務需求；
+// idempotencyKey 不為空時，24 小時內以相同 key 與相同內容重試會回傳第一次建立的訂單而不會重複建立
+func (s *service) CreateOrder(ctx context.Context, order *models.Order, idempotencyKey string) error {
+	if err := s.enforceQuota(ctx); err != nil {
+		return err
+	}
+
 	return s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
 		// 1. 驗證訂單數據
 		if err := order.Validate(); err != nil {
 			return fmt.Errorf("invalid order data: %w", err)
 		}
 
+		// 2. 檢查 idempotency key，重試的請求直接回傳原本的訂單
+		if idempotencyKey != "" {
+			requestHash, err := orderRequestHash(order)
+			if err != nil {
+				return err
+			}
+			existing, err := s.claimOrderIdempotencyKey(ctx, tx, idempotencyKey, requestHash)
+			if err != nil {
+				return err
+			}
+			if existing != nil {
+				*order = *existing
+				return nil
+			}
+		}
+
 		var subtotal, tax, discount, total float64
-		// 2. 創建訂單
-		orderModel, err := s.order.CreateOrder(ctx, tx, order)
+		// 3. 創建訂單
+		orderModel, err := s.createOrder(ctx, tx, order)
 		if err != nil {
 			return fmt.Errorf("failed to create order: %w", err)
 		}
+		if idempotencyKey != "" {
+			if err = s.order.SetOrderIdempotencyKeyOrder(ctx, tx, idempotencyKey, order.ID); err != nil {
+				return fmt.Errorf("failed to record idempotency key: %w", err)
+			}
+		}
+
+		// 客戶有適用的免稅證明時，涵蓋的項目以零稅率計算
+		exemption, err := s.taxExemption(ctx, tx, order.CustomerID, order.ShippingAddress, order.BillingAddress)
+		if err != nil {
+			return err
+		}
+		if err = s.recordOrderTaxExemption(ctx, tx, order.ID, exemption); err != nil {
+			return err
+		}
 
-		// 3. 準備訂單項目、庫存調整和庫存變動記錄的參數
+		// 4. 準備訂單項目、庫存調整和庫存變動記錄的參數
 		orderItems := make([]*models.OrderItem, len(order.Items))
 		reduceStockParams := make([]stock.ReduceStockParams, len(order.Items))
 		stockMoveParams := make([]stock.CreateStockMovementParams, len(order.Items))
@@ -554,15 +1140,33 @@ func (s *service) CreateOrder(ctx context.Context, order *models.Order) error {
 			subtotal += item.Subtotal
 			// 設置訂單項目
 			orderItems[i] = &models.OrderItem{
-				OrderID:   order.ID,
-				ProductID: item.ProductID,
-				PriceID:   item.PriceID,
-				StockID:   item.StockID,
-				Quantity:  item.Quantity,
-				UnitPrice: item.UnitPrice,
-				Subtotal:  item.Subtotal,
+				OrderID:     order.ID,
+				ProductID:   item.ProductID,
+				PriceID:     item.PriceID,
+				StockID:     item.StockID,
+				Quantity:    item.Quantity,
+				UnitPrice:   item.UnitPrice,
+				Subtotal:    item.Subtotal,
+				Location:    item.Location,
+				ProductName: item.ProductName,
+				SKU:         item.SKU,
+				ImageURL:    item.ImageURL,
+				TaxClass:    item.TaxClass,
+			}
+			s.snapshotOrderItem(ctx, orderItems[i])
+
+			orderItems[i].Customization, err = s.validateCustomization(ctx, item.ProductID, item.Customization)
+			if err != nil {
+				return fmt.Errorf("failed to validate customization for item %s: %w", item.ProductID, err)
 			}
 
+			// 依快照後的稅別計算項目稅額
+			orderItems[i].TaxRate, orderItems[i].TaxAmount, err = s.lineTax(ctx, exemption, item.ProductID, orderItems[i].TaxClass, item.Subtotal, order.Currency)
+			if err != nil {
+				return err
+			}
+			tax += orderItems[i].TaxAmount
+
 			// 獲取當前庫存信息
 			stockModel, err := s.stock.GetStock(ctx, tx, item.StockID)
 			if err != nil {
@@ -591,30 +1195,35 @@ func (s *service) CreateOrder(ctx context.Context, order *models.Order) error {
 			}
 		}
 
-		// 4. 批量創建訂單項目
+		// 5. 批量創建訂單項目，庫存變動記錄對應到新建立的項目
 		if err := s.order.AddOrderItems(ctx, tx, orderItems); err != nil {
 			return fmt.Errorf("failed to add order items: %w", err)
 		}
+		for i, orderItem := range orderItems {
+			stockMoveParams[i].ReferenceItemID = orderItem.ID
+		}
 
-		// 5. 批量減少庫存
+		// 6. 批量減少庫存
 		if err := s.stock.ReduceStock(ctx, tx, reduceStockParams); err != nil {
 			return fmt.Errorf("failed to reduce stock: %w", err)
 		}
 
-		// 6. 批量創建庫存變動記錄
+		// 7. 批量創建庫存變動記錄
 		if err := s.stock.CreateStockMovements(ctx, tx, stockMoveParams); err != nil {
 			return fmt.Errorf("failed to create stock movements: %w", err)
 		}
 
-		tax = subtotal * 0.1 // 假设稅率為 10%
-		discount = 0         // 根據實際情況算折扣 coupon 等等
+		tax = roundCurrency(tax)
+		discount = 0 // 根據實際情況算折扣 coupon 等等
 		total = subtotal + tax - discount
-		// 7. 更新訂單總計
+		// 8. 更新訂單總計
 		if err := s.order.UpdateOrderTotals(ctx, tx, order.ID, tax, subtotal, discount, total, orderModel.UpdatedAt); err != nil {
 			return fmt.Errorf("failed to update order totals: %w", err)
 		}
 
-		return nil
+		// 9. 記錄換算成報表幣別的匯率快照
+		orderModel.Subtotal, orderModel.Tax, orderModel.Discount, orderModel.Total = subtotal, tax, discount, total
+		return s.recordReportingSnapshot(ctx, tx, orderModel)
 	})
 }
 
@@ -631,31 +1240,56 @@ func (s *service) GetOrder(ctx context.Context, orderID uint64) (*models.Order,
 		return nil, fmt.Errorf("獲取訂單項目失敗: %w", err)
 	}
 
+	addons, err := s.order.ListOrderAddons(ctx, nil, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("獲取訂單加購服務失敗: %w", err)
+	}
+
 	orderModel.Items = items
+	orderModel.Addons = addons
 	return orderModel, nil
 }
 
 // UpdateOrderStatus 用於更新訂單狀態，如 pending、paid、cancelled、completed 等
 func (s *service) UpdateOrderStatus(ctx context.Context, orderID uint64, newStatus enum.OrderStatus) error {
-	return s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+	return s.executeOrderTransaction(ctx, func(tx pgx.Tx) error {
 		// 1. 獲取訂單
-		orderModel, err := s.order.GetOrder(ctx, tx, orderID)
+		orderModel, err := s.order.GetOrderForUpdate(ctx, tx, orderID)
 		if err != nil {
 			return fmt.Errorf("failed to get order: %w", err)
 		}
 
 		// 2. 檢查狀態轉換是否有效
-		if !orderModel.AllowChangeStatus(newStatus) {
+		if !s.statusMachine.CanTransition(orderModel.Status, newStatus) {
 			return fmt.Errorf("invalid status transition from %s to %s", orderModel.Status, newStatus)
 		}
 
-		// 3. 更新訂單狀態
+		// 3. 更新訂單狀態並執行狀態機的 hook
 		if err = s.order.UpdateOrderStatus(ctx, tx, orderID, newStatus, orderModel.UpdatedAt); err != nil {
 			return fmt.Errorf("failed to update order status: %w", err)
 		}
+		if err = s.statusMachine.enter(ctx, tx, orderModel, newStatus); err != nil {
+			return err
+		}
 
 		// 4. 處理特定狀態轉換的邏輯
 		switch newStatus {
+		case enum.OrderStatusPaid:
+			if err = s.startFulfillmentSLA(ctx, tx, orderModel, time.Now()); err != nil {
+				return err
+			}
+		case enum.OrderStatusReadyForPickup:
+			if err = s.completeFulfillmentSLA(ctx, tx, orderID, time.Now()); err != nil {
+				return err
+			}
+		case enum.OrderStatusCompleted:
+			if err = s.completeFulfillmentSLA(ctx, tx, orderID, time.Now()); err != nil {
+				return err
+			}
+			// 訂單完成表示所有項目都已交付，包含門市自取的訂單
+			if err = s.order.ShipOrderItems(ctx, tx, orderID, ""); err != nil {
+				return fmt.Errorf("failed to ship order items: %w", err)
+			}
 		case enum.OrderStatusCancelled, enum.OrderStatusRefunded:
 			// 獲取訂單項目
 			items, err := s.order.ListOrderItems(ctx, tx, orderID)
@@ -680,11 +1314,12 @@ func (s *service) UpdateOrderStatus(ctx context.Context, orderID uint64, newStat
 				}
 
 				moveParams[i] = stock.CreateStockMovementParams{
-					StockID:       item.StockID,
-					Quantity:      item.Quantity,
-					Type:          enum.StockMovementTypeIn,
-					ReferenceID:   orderID,
-					ReferenceType: enum.StockMovementReferenceTypeOrder,
+					StockID:         item.StockID,
+					Quantity:        item.Quantity,
+					Type:            enum.StockMovementTypeIn,
+					ReferenceID:     orderID,
+					ReferenceType:   enum.StockMovementReferenceTypeOrder,
+					ReferenceItemID: item.ID,
 				}
 			}
 
@@ -703,28 +1338,142 @@ func (s *service) UpdateOrderStatus(ctx context.Context, orderID uint64, newStat
 	})
 }
 
-// ListOrders 列出指定客戶的訂單
-func (s *service) ListOrders(ctx context.Context, customerID string, limit, offset uint64) ([]*models.Order, error) {
-	orders, err := s.order.ListOrders(ctx, nil, customerID, limit, offset)
-	if err != nil {
-		return nil, fmt.Errorf("列出訂單失敗: %w", err)
+// ListOrders 分頁列出指定客戶的訂單及訂單總數
+func (s *service) ListOrders(ctx context.Context, customerID string, limit, offset uint64) (*models.Page[*models.Order], error) {
+	var page *models.Page[*models.Order]
+
+	// 在同一個快照中讀取列表與總數，避免期間新增的訂單造成分頁錯位
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		orders, err := s.order.ListOrders(ctx, tx, customerID, limit, offset)
+		if err != nil {
+			return fmt.Errorf("列出訂單失敗: %w", err)
+		}
+
+		total, err := s.order.CountOrders(ctx, tx, customerID)
+		if err != nil {
+			return fmt.Errorf("failed to count orders: %w", err)
+		}
+
+		page = models.NewPage(orders, total, limit, offset)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// FindOrdersByMetadata 查詢 metadata 包含所有指定鍵值的訂單，例如從 Stripe 帶入的內部參考編號
+func (s *service) FindOrdersByMetadata(ctx context.Context, metadata map[string]string, limit, offset uint64) ([]*models.Order, error) {
+	var orders []*models.Order
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		var err error
+		orders, err = s.order.FindOrdersByMetadata(ctx, tx, metadata, limit, offset)
+		return err
+	}); err != nil {
+		return nil, fmt.Errorf("依 metadata 查詢訂單失敗: %w", err)
 	}
 	return orders, nil
 }
 
-// DeleteOrder 刪除訂單，這適用於測試或後台操作
+// DeleteOrder 軟刪除訂單，這適用於測試或後台操作；刪除的訂單不再出現在客戶的訂單列表，但仍計入會計報表，
+// 可以用 RestoreOrder 還原，超過保留期限後由 PurgeDeletedOrders 永久刪除
 func (s *service) DeleteOrder(ctx context.Context, orderID uint64) error {
-	return s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
-		// 刪除訂單
-		return s.order.DeleteOrder(ctx, tx, orderID)
-	})
+	var orderModel *models.Order
+
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		// 1. 獲取訂單並檢查權限
+		var err error
+		orderModel, err = s.order.GetOrderForUpdate(ctx, tx, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to get order: %w", err)
+		}
+		if err = s.checkAuthorization(ctx, AuthorizationRequest{
+			Action:     ActionDeleteOrder,
+			ResourceID: orderID,
+			CustomerID: orderModel.CustomerID,
+		}); err != nil {
+			return err
+		}
+
+		// 2. 軟刪除訂單
+		ok, err := s.order.DeleteOrder(ctx, tx, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to delete order: %w", err)
+		}
+		if !ok {
+			return fmt.Errorf("order %d is already deleted", orderID)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	// 通知失敗不影響刪除結果
+	if err := s.eventManager.Publish(ctx, SubjectOrderDeleted, &OrderDeletedEvent{
+		OrderID:     orderModel.ID,
+		OrderNumber: orderModel.OrderNumber,
+		CustomerID:  orderModel.CustomerID,
+		Status:      orderModel.Status,
+		OccurredAt:  time.Now(),
+	}); err != nil {
+		s.log(ctx).Warn("Failed to publish order deleted event", zap.Uint64("order_id", orderID), zap.Error(err))
+	}
+
+	return nil
+}
+
+// RestoreOrder 還原軟刪除的訂單，訂單恢復刪除前的狀態並重新出現在客戶的訂單列表
+func (s *service) RestoreOrder(ctx context.Context, orderID uint64) error {
+	var orderModel *models.Order
+
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		// 1. 獲取訂單並檢查權限
+		var err error
+		orderModel, err = s.order.GetOrderForUpdate(ctx, tx, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to get order: %w", err)
+		}
+		if err = s.checkAuthorization(ctx, AuthorizationRequest{
+			Action:     ActionRestoreOrder,
+			ResourceID: orderID,
+			CustomerID: orderModel.CustomerID,
+		}); err != nil {
+			return err
+		}
+
+		// 2. 還原訂單
+		ok, err := s.order.RestoreOrder(ctx, tx, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to restore order: %w", err)
+		}
+		if !ok {
+			return fmt.Errorf("order %d is not deleted", orderID)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	// 通知失敗不影響還原結果
+	if err := s.eventManager.Publish(ctx, SubjectOrderRestored, &OrderDeletedEvent{
+		OrderID:     orderModel.ID,
+		OrderNumber: orderModel.OrderNumber,
+		CustomerID:  orderModel.CustomerID,
+		Status:      orderModel.Status,
+		OccurredAt:  time.Now(),
+	}); err != nil {
+		s.log(ctx).Warn("Failed to publish order restored event", zap.Uint64("order_id", orderID), zap.Error(err))
+	}
+
+	return nil
 }
 
 // CancelOrder 取消訂單
 func (s *service) CancelOrder(ctx context.Context, orderID uint64) error {
-	return s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+	return s.executeOrderTransaction(ctx, func(tx pgx.Tx) error {
 		// 1. 獲取訂單
-		orderModel, err := s.order.GetOrder(ctx, tx, orderID)
+		orderModel, err := s.order.GetOrderForUpdate(ctx, tx, orderID)
 		if err != nil {
 			return fmt.Errorf("failed to get order: %w", err)
 		}
@@ -739,63 +1488,190 @@ func (s *service) CancelOrder(ctx context.Context, orderID uint64) error {
 			return fmt.Errorf("failed to update order status: %w", err)
 		}
 
-		// 4. 獲取訂單項目
-		items, err := s.order.ListOrderItems(ctx, tx, orderID)
-		if err != nil {
-			return fmt.Errorf("failed to list order items: %w", err)
-		}
+		// 4. 恢復庫存並記錄庫存變動
+		return s.restockOrderItems(ctx, tx, orderID)
+	})
+}
 
-		// 5. 準備庫存調整參數
-		adjustParams := make([]stock.AdjustStockParams, len(items))
-		moveParams := make([]stock.CreateStockMovementParams, len(items))
+// GetPickList 依出貨地點將訂單項目分組，產生揀貨單；暫停中的訂單回傳 ErrOrderOnHold
+func (s *service) GetPickList(ctx context.Context, orderID uint64) ([]*models.PickList, error) {
+	orderModel, err := s.order.GetOrder(ctx, nil, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+	if orderModel.Status == enum.OrderStatusOnHold {
+		return nil, ErrOrderOnHold
+	}
 
-		for i, item := range items {
-			stockModel, err := s.stock.GetStock(ctx, tx, item.StockID)
+	items, err := s.order.ListOrderItems(ctx, nil, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list order items: %w", err)
+	}
+
+	var pickLists []*models.PickList
+	pickListMap := make(map[string]*models.PickList)
+
+	for _, item := range items {
+		location := item.Location
+		if location == "" {
+			// 舊訂單沒有記錄地點，從庫存資料補齊
+			stockModel, err := s.stock.GetStock(ctx, nil, item.StockID)
 			if err != nil {
-				return fmt.Errorf("failed to get stock for item %s: %w", item.ProductID, err)
+				return nil, fmt.Errorf("failed to get stock for item %s: %w", item.ProductID, err)
 			}
+			location = stockModel.Location
+		}
 
-			adjustParams[i] = stock.AdjustStockParams{
-				StockID:     item.StockID,
-				Quantity:    item.Quantity,
-				LastUpdated: stockModel.UpdatedAt,
+		pickList, exists := pickListMap[location]
+		if !exists {
+			pickList = &models.PickList{
+				OrderID:  orderID,
+				Location: location,
 			}
+			pickListMap[location] = pickList
+			pickLists = append(pickLists, pickList)
+		}
 
-			moveParams[i] = stock.CreateStockMovementParams{
-				StockID:       item.StockID,
-				Quantity:      item.Quantity,
-				Type:          enum.StockMovementTypeIn,
-				ReferenceID:   orderID,
-				ReferenceType: enum.StockMovementReferenceTypeOrder,
-			}
+		pickList.Items = append(pickList.Items, &models.PickListItem{
+			OrderItemID:   item.ID,
+			ProductID:     item.ProductID,
+			StockID:       item.StockID,
+			Location:      location,
+			Quantity:      item.Quantity,
+			Customization: item.Customization,
+			Note:          item.Note,
+		})
+	}
+
+	return pickLists, nil
+}
+
+// SetOrderFulfillment 設定訂單的履約方式，門市自取時需指定取貨地點且所有商品都必須在該地點有庫存
+func (s *service) SetOrderFulfillment(ctx context.Context, orderID uint64, fulfillmentType enum.FulfillmentType, pickupLocation string) error {
+	return s.executeOrderTransaction(ctx, func(tx pgx.Tx) error {
+		// 1. 獲取訂單
+		orderModel, err := s.order.GetOrderForUpdate(ctx, tx, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to get order: %w", err)
 		}
 
-		// 6. 批量調整庫存
-		if err = s.stock.AdjustStock(ctx, tx, adjustParams); err != nil {
-			return fmt.Errorf("failed to adjust stock: %w", err)
+		if orderModel.Status != enum.OrderStatusPending {
+			return fmt.Errorf("fulfillment can only be changed on pending orders, current status is %s", orderModel.Status)
 		}
 
-		// 7. 批量創建庫存變動記錄
-		if err = s.stock.CreateStockMovements(ctx, tx, moveParams); err != nil {
-			return fmt.Errorf("failed to create stock movements: %w", err)
+		// 2. 驗證履約方式
+		switch fulfillmentType {
+		case enum.FulfillmentTypeShip:
+			pickupLocation = ""
+		case enum.FulfillmentTypePickup:
+			if pickupLocation == "" {
+				return errors.New("pickup location is required for pickup orders")
+			}
+
+			items, err := s.order.ListOrderItems(ctx, tx, orderID)
+			if err != nil {
+				return fmt.Errorf("failed to list order items: %w", err)
+			}
+
+			// 3. 確認每個商品的庫存都在取貨地點
+			for _, item := range items {
+				stockModel, err := s.stock.GetStock(ctx, tx, item.StockID)
+				if err != nil {
+					return fmt.Errorf("failed to get stock for item %s: %w", item.ProductID, err)
+				}
+				if stockModel.Location != pickupLocation {
+					return fmt.Errorf("item %s is not stocked at pickup location %q", item.ProductID, pickupLocation)
+				}
+			}
+		default:
+			return fmt.Errorf("unsupported fulfillment type: %s", fulfillmentType)
+		}
+
+		// 4. 更新訂單
+		if err = s.order.UpdateOrderFulfillment(ctx, tx, orderID, fulfillmentType, pickupLocation, orderModel.UpdatedAt); err != nil {
+			return fmt.Errorf("failed to update order fulfillment: %w", err)
 		}
 
 		return nil
 	})
 }
 
+// MarkOrderReadyForPickup 將門市自取訂單標記為可取貨，並通知客戶
+func (s *service) MarkOrderReadyForPickup(ctx context.Context, orderID uint64) error {
+	var orderModel *models.Order
+
+	if err := s.executeOrderTransaction(ctx, func(tx pgx.Tx) error {
+		var err error
+
+		orderModel, err = s.order.GetOrderForUpdate(ctx, tx, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to get order: %w", err)
+		}
+
+		if orderModel.FulfillmentType != enum.FulfillmentTypePickup {
+			return errors.New("order is not a pickup order")
+		}
+
+		if !s.statusMachine.CanTransition(orderModel.Status, enum.OrderStatusReadyForPickup) {
+			return fmt.Errorf("invalid status transition from %s to %s", orderModel.Status, enum.OrderStatusReadyForPickup)
+		}
+
+		if err = s.order.UpdateOrderStatus(ctx, tx, orderID, enum.OrderStatusReadyForPickup, orderModel.UpdatedAt); err != nil {
+			return fmt.Errorf("failed to update order status: %w", err)
+		}
+		if err = s.completeFulfillmentSLA(ctx, tx, orderID, time.Now()); err != nil {
+			return err
+		}
+
+		return s.statusMachine.enter(ctx, tx, orderModel, enum.OrderStatusReadyForPickup)
+	}); err != nil {
+		return err
+	}
+
+	// 通知失敗不影響訂單狀態
+	if err := s.eventManager.Publish(ctx, SubjectOrderReadyForPickup, &OrderReadyForPickupEvent{
+		OrderID:        orderModel.ID,
+		OrderNumber:    orderModel.OrderNumber,
+		CustomerID:     orderModel.CustomerID,
+		PickupLocation: orderModel.PickupLocation,
+		OccurredAt:     time.Now(),
+	}); err != nil {
+		s.log(ctx).Warn("Failed to publish ready for pickup notification", zap.Uint64("order_id", orderID), zap.Error(err))
+	}
+
+	return nil
+}
+
 func (s *service) CreateCategory(ctx context.Context, category *models.Category) error {
+	if err := normalizeCategorySlug(category); err != nil {
+		return err
+	}
+
 	return s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
 		return s.category.Create(ctx, tx, category)
 	})
 }
 
+// GetCategoryByID 取得分類，名稱與描述依 context 的語系翻譯
 func (s *service) GetCategoryByID(ctx context.Context, id uint64) (*models.Category, error) {
-
-	return s.category.GetByID(ctx, nil, id)
+	var category *models.Category
+	err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		var err error
+		if category, err = s.category.GetByID(ctx, tx, id); err != nil {
+			return err
+		}
+		return s.localizeCategories(ctx, tx, []*models.Category{category})
+	})
+	return category, err
 }
 
+// UpdateCategory 更新分類，slug 為空時由名稱產生；category 的 updated_at 須為讀取時的值，
+// 讀取後已被其他交易修改時回傳 category.ErrStaleCategory
 func (s *service) UpdateCategory(ctx context.Context, category *models.Category) error {
+	if err := normalizeCategorySlug(category); err != nil {
+		return err
+	}
+
 	return s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
 		return s.category.Update(ctx, tx, category)
 	})
@@ -807,21 +1683,51 @@ func (s *service) DeleteCategory(ctx context.Context, id uint64) error {
 	})
 }
 
-func (s *service) ListCategory(ctx context.Context, limit, offset uint64) ([]*models.Category, error) {
-	return s.category.List(ctx, nil, limit, offset)
+// ListCategory 分頁列出分類及分類總數，名稱與描述依 context 的語系翻譯
+func (s *service) ListCategory(ctx context.Context, limit, offset uint64) (*models.Page[*models.Category], error) {
+	var page *models.Page[*models.Category]
+	err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		categories, err := s.category.List(ctx, tx, limit, offset)
+		if err != nil {
+			return err
+		}
+		total, err := s.category.Count(ctx, tx)
+		if err != nil {
+			return err
+		}
+		if err = s.localizeCategories(ctx, tx, categories); err != nil {
+			return err
+		}
+		page = models.NewPage(categories, total, limit, offset)
+		return nil
+	})
+	return page, err
 }
 
+// ListSubcategories 列出子分類，名稱與描述依 context 的語系翻譯
 func (s *service) ListSubcategories(ctx context.Context, parentID uint64) ([]*models.Category, error) {
-	return s.category.ListSubcategories(ctx, nil, parentID)
+	var categories []*models.Category
+	err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		var err error
+		if categories, err = s.category.ListSubcategories(ctx, tx, parentID); err != nil {
+			return err
+		}
+		return s.localizeCategories(ctx, tx, categories)
+	})
+	return categories, err
 }
 
+// GetCategoryTree 取得完整的分類樹，名稱與描述依 context 的語系翻譯
 func (s *service) GetCategoryTree(ctx context.Context) ([]*models.CategoryTree, error) {
 	var categoryTree []*models.CategoryTree
 	err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
-		categories, err := s.category.List(ctx, tx, 0, 0) // Get all categories
+		categories, err := s.category.ListAll(ctx, tx)
 		if err != nil {
 			return err
 		}
+		if err = s.localizeCategories(ctx, tx, categories); err != nil {
+			return err
+		}
 		categoryTree = buildCategoryTree(categories)
 		return nil
 	})
@@ -863,3 +1769,48 @@ func buildCategoryTree(categories []*models.Category) []*models.CategoryTree {
 
 	return roots
 }
+
+// maxOrderUpdateAttempts 訂單並發更新衝突時最多執行的次數
+const maxOrderUpdateAttempts = 3
+
+// executeOrderTransaction 執行會以 updated_at 樂觀更新訂單的交易，遇到並發衝突時重新執行整個交易；
+// context 帶有 advisory lock key 時（例如同一個 PaymentIntent 的事件），交易的第一個查詢先取得此鎖
+func (s *service) executeOrderTransaction(ctx context.Context, fn func(tx pgx.Tx) error) error {
+	lockKey := orderLockFromContext(ctx)
+	if lockKey != "" {
+		locked := fn
+		fn = func(tx pgx.Tx) error {
+			if err := s.transactionManager.AdvisoryXactLock(ctx, tx, lockKey); err != nil {
+				return err
+			}
+			return locked(tx)
+		}
+	}
+
+	var err error
+	for attempt := 1; attempt <= maxOrderUpdateAttempts; attempt++ {
+		if err = s.transactionManager.ExecuteTransaction(ctx, fn); err == nil || !isOrderConflict(err) {
+			return err
+		}
+
+		s.log(ctx).Warn("Order update conflicted, retrying", zap.Int("attempt", attempt), zap.Error(err))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(attempt*50) * time.Millisecond):
+		}
+	}
+
+	return fmt.Errorf("order update failed after %d attempts: %w", maxOrderUpdateAttempts, err)
+}
+
+// isOrderConflict 判斷錯誤是否為可重試的並發衝突
+func isOrderConflict(err error) bool {
+	if errors.Is(err, order.ErrStaleOrder) {
+		return true
+	}
+
+	// 40001 serialization_failure、40P01 deadlock_detected
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && (pgErr.Code == "40001" || pgErr.Code == "40P01")
+}
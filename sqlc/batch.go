@@ -17,9 +17,10 @@ var (
 	ErrBatchAlreadyClosed = errors.New("batch already closed")
 )
 
-const addOrderItems = `-- name: AddOrderItems :batchexec
-INSERT INTO order_items (order_id, product_id, price_id, stock_id, quantity, unit_price, subtotal)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
+const addOrderItems = `-- name: AddOrderItems :batchone
+INSERT INTO order_items (order_id, product_id, price_id, stock_id, quantity, unit_price, subtotal, location, product_name, sku, image_url, tax_class, tax_rate, tax_amount, weight_grams, length_mm, width_mm, height_mm, hs_code, origin_country, customization, note, metadata)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23)
+RETURNING id
 `
 
 type AddOrderItemsBatchResults struct {
@@ -29,13 +30,29 @@ type AddOrderItemsBatchResults struct {
 }
 
 type AddOrderItemsParams struct {
-	OrderID   int32   `json:"orderId"`
-	ProductID string  `json:"productId"`
-	PriceID   string  `json:"priceId"`
-	StockID   uint64  `json:"stockId"`
-	Quantity  uint64  `json:"quantity"`
-	UnitPrice float64 `json:"unitPrice"`
-	Subtotal  float64 `json:"subtotal"`
+	OrderID       int32   `json:"orderId"`
+	ProductID     string  `json:"productId"`
+	PriceID       string  `json:"priceId"`
+	StockID       uint64  `json:"stockId"`
+	Quantity      uint64  `json:"quantity"`
+	UnitPrice     float64 `json:"unitPrice"`
+	Subtotal      float64 `json:"subtotal"`
+	Location      *string `json:"location"`
+	ProductName   *string `json:"productName"`
+	Sku           *string `json:"sku"`
+	ImageUrl      *string `json:"imageUrl"`
+	TaxClass      *string `json:"taxClass"`
+	TaxRate       float64 `json:"taxRate"`
+	TaxAmount     float64 `json:"taxAmount"`
+	WeightGrams   *int32  `json:"weightGrams"`
+	LengthMm      *int32  `json:"lengthMm"`
+	WidthMm       *int32  `json:"widthMm"`
+	HeightMm      *int32  `json:"heightMm"`
+	HsCode        *string `json:"hsCode"`
+	OriginCountry *string `json:"originCountry"`
+	Customization []byte  `json:"customization"`
+	Note          *string `json:"note"`
+	Metadata      []byte  `json:"metadata"`
 }
 
 func (q *Queries) AddOrderItems(ctx context.Context, arg []AddOrderItemsParams) *AddOrderItemsBatchResults {
@@ -49,6 +66,22 @@ func (q *Queries) AddOrderItems(ctx context.Context, arg []AddOrderItemsParams)
 			a.Quantity,
 			a.UnitPrice,
 			a.Subtotal,
+			a.Location,
+			a.ProductName,
+			a.Sku,
+			a.ImageUrl,
+			a.TaxClass,
+			a.TaxRate,
+			a.TaxAmount,
+			a.WeightGrams,
+			a.LengthMm,
+			a.WidthMm,
+			a.HeightMm,
+			a.HsCode,
+			a.OriginCountry,
+			a.Customization,
+			a.Note,
+			a.Metadata,
 		}
 		batch.Queue(addOrderItems, vals...)
 	}
@@ -56,7 +89,69 @@ func (q *Queries) AddOrderItems(ctx context.Context, arg []AddOrderItemsParams)
 	return &AddOrderItemsBatchResults{br, len(arg), false}
 }
 
-func (b *AddOrderItemsBatchResults) Exec(f func(int, error)) {
+func (b *AddOrderItemsBatchResults) QueryRow(f func(int, int32, error)) {
+	defer b.br.Close()
+	for t := 0; t < b.tot; t++ {
+		var id int32
+		if b.closed {
+			if f != nil {
+				f(t, id, ErrBatchAlreadyClosed)
+			}
+			continue
+		}
+		row := b.br.QueryRow()
+		err := row.Scan(&id)
+		if f != nil {
+			f(t, id, err)
+		}
+	}
+}
+
+func (b *AddOrderItemsBatchResults) Close() error {
+	b.closed = true
+	return b.br.Close()
+}
+
+const addRefundItems = `-- name: AddRefundItems :batchexec
+INSERT INTO refund_items (refund_id, order_item_id, order_addon_id, quantity, amount, restocking_fee, rule)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+`
+
+type AddRefundItemsBatchResults struct {
+	br     pgx.BatchResults
+	tot    int
+	closed bool
+}
+
+type AddRefundItemsParams struct {
+	RefundID      int32   `json:"refundId"`
+	OrderItemID   *int32  `json:"orderItemId"`
+	OrderAddonID  *int32  `json:"orderAddonId"`
+	Quantity      uint64  `json:"quantity"`
+	Amount        float64 `json:"amount"`
+	RestockingFee float64 `json:"restockingFee"`
+	Rule          string  `json:"rule"`
+}
+
+func (q *Queries) AddRefundItems(ctx context.Context, arg []AddRefundItemsParams) *AddRefundItemsBatchResults {
+	batch := &pgx.Batch{}
+	for _, a := range arg {
+		vals := []interface{}{
+			a.RefundID,
+			a.OrderItemID,
+			a.OrderAddonID,
+			a.Quantity,
+			a.Amount,
+			a.RestockingFee,
+			a.Rule,
+		}
+		batch.Queue(addRefundItems, vals...)
+	}
+	br := q.db.SendBatch(ctx, batch)
+	return &AddRefundItemsBatchResults{br, len(arg), false}
+}
+
+func (b *AddRefundItemsBatchResults) Exec(f func(int, error)) {
 	defer b.br.Close()
 	for t := 0; t < b.tot; t++ {
 		if b.closed {
@@ -72,15 +167,16 @@ func (b *AddOrderItemsBatchResults) Exec(f func(int, error)) {
 	}
 }
 
-func (b *AddOrderItemsBatchResults) Close() error {
+func (b *AddRefundItemsBatchResults) Close() error {
 	b.closed = true
 	return b.br.Close()
 }
 
-const adjustStock = `-- name: AdjustStock :batchexec
+const adjustStock = `-- name: AdjustStock :batchone
 UPDATE stocks
-SET reserved_quantity = reserved_quantity + $2, updated_at = NOW()
+SET reserved_quantity = reserved_quantity + CASE WHEN EXISTS (SELECT 1 FROM stock_projections p WHERE p.stock_id = stocks.id) THEN 0 ELSE $2 END, updated_at = NOW()
 WHERE id = $1 AND updated_at = $3
+RETURNING id
 `
 
 type AdjustStockBatchResults struct {
@@ -109,7 +205,67 @@ func (q *Queries) AdjustStock(ctx context.Context, arg []AdjustStockParams) *Adj
 	return &AdjustStockBatchResults{br, len(arg), false}
 }
 
-func (b *AdjustStockBatchResults) Exec(f func(int, error)) {
+func (b *AdjustStockBatchResults) QueryRow(f func(int, int32, error)) {
+	defer b.br.Close()
+	for t := 0; t < b.tot; t++ {
+		var id int32
+		if b.closed {
+			if f != nil {
+				f(t, id, ErrBatchAlreadyClosed)
+			}
+			continue
+		}
+		row := b.br.QueryRow()
+		err := row.Scan(&id)
+		if f != nil {
+			f(t, id, err)
+		}
+	}
+}
+
+func (b *AdjustStockBatchResults) Close() error {
+	b.closed = true
+	return b.br.Close()
+}
+
+const createCampaignRedemptions = `-- name: CreateCampaignRedemptions :batchexec
+INSERT INTO campaign_redemptions (campaign_id, order_id, product_id, quantity, subtotal, discount, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, NOW())
+`
+
+type CreateCampaignRedemptionsBatchResults struct {
+	br     pgx.BatchResults
+	tot    int
+	closed bool
+}
+
+type CreateCampaignRedemptionsParams struct {
+	CampaignID int32   `json:"campaignId"`
+	OrderID    int32   `json:"orderId"`
+	ProductID  string  `json:"productId"`
+	Quantity   uint64  `json:"quantity"`
+	Subtotal   float64 `json:"subtotal"`
+	Discount   float64 `json:"discount"`
+}
+
+func (q *Queries) CreateCampaignRedemptions(ctx context.Context, arg []CreateCampaignRedemptionsParams) *CreateCampaignRedemptionsBatchResults {
+	batch := &pgx.Batch{}
+	for _, a := range arg {
+		vals := []interface{}{
+			a.CampaignID,
+			a.OrderID,
+			a.ProductID,
+			a.Quantity,
+			a.Subtotal,
+			a.Discount,
+		}
+		batch.Queue(createCampaignRedemptions, vals...)
+	}
+	br := q.db.SendBatch(ctx, batch)
+	return &CreateCampaignRedemptionsBatchResults{br, len(arg), false}
+}
+
+func (b *CreateCampaignRedemptionsBatchResults) Exec(f func(int, error)) {
 	defer b.br.Close()
 	for t := 0; t < b.tot; t++ {
 		if b.closed {
@@ -125,14 +281,14 @@ func (b *AdjustStockBatchResults) Exec(f func(int, error)) {
 	}
 }
 
-func (b *AdjustStockBatchResults) Close() error {
+func (b *CreateCampaignRedemptionsBatchResults) Close() error {
 	b.closed = true
 	return b.br.Close()
 }
 
 const createStockMovement = `-- name: CreateStockMovement :batchexec
-INSERT INTO stock_movements (stock_id, quantity, type, reference_id, reference_type, created_at)
-VALUES ($1, $2, $3, $4, $5, NOW())
+INSERT INTO stock_movements (stock_id, quantity, type, reference_id, reference_type, actor, note, unit_cost, reason, reference_item_id, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW())
 `
 
 type CreateStockMovementBatchResults struct {
@@ -142,11 +298,16 @@ type CreateStockMovementBatchResults struct {
 }
 
 type CreateStockMovementParams struct {
-	StockID       uint64                         `json:"stockId"`
-	Quantity      uint64                         `json:"quantity"`
-	Type          StockMovementType              `json:"type"`
-	ReferenceID   *int32                         `json:"referenceId"`
-	ReferenceType NullStockMovementReferenceType `json:"referenceType"`
+	StockID         uint64                         `json:"stockId"`
+	Quantity        uint64                         `json:"quantity"`
+	Type            StockMovementType              `json:"type"`
+	ReferenceID     *int32                         `json:"referenceId"`
+	ReferenceType   NullStockMovementReferenceType `json:"referenceType"`
+	Actor           *string                        `json:"actor"`
+	Note            *string                        `json:"note"`
+	UnitCost        *float64                       `json:"unitCost"`
+	Reason          NullStockMovementReason        `json:"reason"`
+	ReferenceItemID *int32                         `json:"referenceItemId"`
 }
 
 func (q *Queries) CreateStockMovement(ctx context.Context, arg []CreateStockMovementParams) *CreateStockMovementBatchResults {
@@ -158,6 +319,11 @@ func (q *Queries) CreateStockMovement(ctx context.Context, arg []CreateStockMove
 			a.Type,
 			a.ReferenceID,
 			a.ReferenceType,
+			a.Actor,
+			a.Note,
+			a.UnitCost,
+			a.Reason,
+			a.ReferenceItemID,
 		}
 		batch.Queue(createStockMovement, vals...)
 	}
@@ -186,10 +352,13 @@ func (b *CreateStockMovementBatchResults) Close() error {
 	return b.br.Close()
 }
 
-const reduceStock = `-- name: ReduceStock :batchexec
+const reduceStock = `-- name: ReduceStock :batchone
 UPDATE stocks
-SET quantity = quantity - $2, reserved_quantity = reserved_quantity - $2, updated_at = NOW()
+SET quantity = quantity - CASE WHEN EXISTS (SELECT 1 FROM stock_projections p WHERE p.stock_id = stocks.id) THEN 0 ELSE $2 END,
+    reserved_quantity = reserved_quantity - CASE WHEN EXISTS (SELECT 1 FROM stock_projections p WHERE p.stock_id = stocks.id) THEN 0 ELSE $2 END,
+    updated_at = NOW()
 WHERE id = $1 AND updated_at = $3
+RETURNING id
 `
 
 type ReduceStockBatchResults struct {
@@ -218,18 +387,20 @@ func (q *Queries) ReduceStock(ctx context.Context, arg []ReduceStockParams) *Red
 	return &ReduceStockBatchResults{br, len(arg), false}
 }
 
-func (b *ReduceStockBatchResults) Exec(f func(int, error)) {
+func (b *ReduceStockBatchResults) QueryRow(f func(int, int32, error)) {
 	defer b.br.Close()
 	for t := 0; t < b.tot; t++ {
+		var id int32
 		if b.closed {
 			if f != nil {
-				f(t, ErrBatchAlreadyClosed)
+				f(t, id, ErrBatchAlreadyClosed)
 			}
 			continue
 		}
-		_, err := b.br.Exec()
+		row := b.br.QueryRow()
+		err := row.Scan(&id)
 		if f != nil {
-			f(t, err)
+			f(t, id, err)
 		}
 	}
 }
@@ -239,10 +410,11 @@ func (b *ReduceStockBatchResults) Close() error {
 	return b.br.Close()
 }
 
-const releaseStock = `-- name: ReleaseStock :batchexec
+const releaseStock = `-- name: ReleaseStock :batchone
 UPDATE stocks
-SET reserved_quantity = reserved_quantity - $2, updated_at = NOW()
+SET reserved_quantity = reserved_quantity - CASE WHEN EXISTS (SELECT 1 FROM stock_projections p WHERE p.stock_id = stocks.id) THEN 0 ELSE $2 END, updated_at = NOW()
 WHERE id = $1 AND updated_at = $3
+RETURNING id
 `
 
 type ReleaseStockBatchResults struct {
@@ -271,18 +443,20 @@ func (q *Queries) ReleaseStock(ctx context.Context, arg []ReleaseStockParams) *R
 	return &ReleaseStockBatchResults{br, len(arg), false}
 }
 
-func (b *ReleaseStockBatchResults) Exec(f func(int, error)) {
+func (b *ReleaseStockBatchResults) QueryRow(f func(int, int32, error)) {
 	defer b.br.Close()
 	for t := 0; t < b.tot; t++ {
+		var id int32
 		if b.closed {
 			if f != nil {
-				f(t, ErrBatchAlreadyClosed)
+				f(t, id, ErrBatchAlreadyClosed)
 			}
 			continue
 		}
-		_, err := b.br.Exec()
+		row := b.br.QueryRow()
+		err := row.Scan(&id)
 		if f != nil {
-			f(t, err)
+			f(t, id, err)
 		}
 	}
 }
@@ -291,3 +465,61 @@ func (b *ReleaseStockBatchResults) Close() error {
 	b.closed = true
 	return b.br.Close()
 }
+
+const restoreReservedStock = `-- name: RestoreReservedStock :batchone
+UPDATE stocks
+SET quantity = quantity + CASE WHEN EXISTS (SELECT 1 FROM stock_projections p WHERE p.stock_id = stocks.id) THEN 0 ELSE $2 END,
+    reserved_quantity = reserved_quantity + CASE WHEN EXISTS (SELECT 1 FROM stock_projections p WHERE p.stock_id = stocks.id) THEN 0 ELSE $2 END,
+    updated_at = NOW()
+WHERE id = $1 AND updated_at = $3
+RETURNING id
+`
+
+type RestoreReservedStockBatchResults struct {
+	br     pgx.BatchResults
+	tot    int
+	closed bool
+}
+
+type RestoreReservedStockParams struct {
+	ID        int32              `json:"id"`
+	Quantity  uint64             `json:"quantity"`
+	UpdatedAt pgtype.Timestamptz `json:"updatedAt"`
+}
+
+func (q *Queries) RestoreReservedStock(ctx context.Context, arg []RestoreReservedStockParams) *RestoreReservedStockBatchResults {
+	batch := &pgx.Batch{}
+	for _, a := range arg {
+		vals := []interface{}{
+			a.ID,
+			a.Quantity,
+			a.UpdatedAt,
+		}
+		batch.Queue(restoreReservedStock, vals...)
+	}
+	br := q.db.SendBatch(ctx, batch)
+	return &RestoreReservedStockBatchResults{br, len(arg), false}
+}
+
+func (b *RestoreReservedStockBatchResults) QueryRow(f func(int, int32, error)) {
+	defer b.br.Close()
+	for t := 0; t < b.tot; t++ {
+		var id int32
+		if b.closed {
+			if f != nil {
+				f(t, id, ErrBatchAlreadyClosed)
+			}
+			continue
+		}
+		row := b.br.QueryRow()
+		err := row.Scan(&id)
+		if f != nil {
+			f(t, id, err)
+		}
+	}
+}
+
+func (b *RestoreReservedStockBatchResults) Close() error {
+	b.closed = true
+	return b.br.Close()
+}
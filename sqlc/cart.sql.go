@@ -11,23 +11,28 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-const addCartItem = `-- name: AddCartItem :exec
-INSERT INTO cart_items (cart_id, product_id, price_id, stock_id, quantity, unit_price, subtotal, created_at, updated_at)
-VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+const addCartItem = `-- name: AddCartItem :one
+INSERT INTO cart_items (cart_id, product_id, price_id, stock_id, quantity, unit_price, subtotal, location, customization, note, metadata, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NOW(), NOW())
+RETURNING id
 `
 
 type AddCartItemParams struct {
-	CartID    uint64  `json:"cartId"`
-	ProductID string  `json:"productId"`
-	PriceID   string  `json:"priceId"`
-	StockID   uint64  `json:"stockId"`
-	Quantity  uint64  `json:"quantity"`
-	UnitPrice float64 `json:"unitPrice"`
-	Subtotal  float64 `json:"subtotal"`
+	CartID        uint64  `json:"cartId"`
+	ProductID     string  `json:"productId"`
+	PriceID       string  `json:"priceId"`
+	StockID       uint64  `json:"stockId"`
+	Quantity      uint64  `json:"quantity"`
+	UnitPrice     float64 `json:"unitPrice"`
+	Subtotal      float64 `json:"subtotal"`
+	Location      *string `json:"location"`
+	Customization []byte  `json:"customization"`
+	Note          *string `json:"note"`
+	Metadata      []byte  `json:"metadata"`
 }
 
-func (q *Queries) AddCartItem(ctx context.Context, arg AddCartItemParams) error {
-	_, err := q.db.Exec(ctx, addCartItem,
+func (q *Queries) AddCartItem(ctx context.Context, arg AddCartItemParams) (int32, error) {
+	row := q.db.QueryRow(ctx, addCartItem,
 		arg.CartID,
 		arg.ProductID,
 		arg.PriceID,
@@ -35,10 +40,66 @@ func (q *Queries) AddCartItem(ctx context.Context, arg AddCartItemParams) error
 		arg.Quantity,
 		arg.UnitPrice,
 		arg.Subtotal,
+		arg.Location,
+		arg.Customization,
+		arg.Note,
+		arg.Metadata,
 	)
+	var id int32
+	err := row.Scan(&id)
+	return id, err
+}
+
+const assignCartExperiment = `-- name: AssignCartExperiment :execrows
+INSERT INTO experiment_assignments (cart_id, experiment, variant, assigned_at)
+VALUES ($1, $2, $3, NOW())
+ON CONFLICT (cart_id, experiment) DO NOTHING
+`
+
+type AssignCartExperimentParams struct {
+	CartID     uint64 `json:"cartId"`
+	Experiment string `json:"experiment"`
+	Variant    string `json:"variant"`
+}
+
+func (q *Queries) AssignCartExperiment(ctx context.Context, arg AssignCartExperimentParams) (int64, error) {
+	result, err := q.db.Exec(ctx, assignCartExperiment, arg.CartID, arg.Experiment, arg.Variant)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const attachExperimentAssignmentsToOrder = `-- name: AttachExperimentAssignmentsToOrder :exec
+UPDATE experiment_assignments
+SET order_id = $2
+WHERE cart_id = $1
+`
+
+type AttachExperimentAssignmentsToOrderParams struct {
+	CartID  uint64 `json:"cartId"`
+	OrderID *int32 `json:"orderId"`
+}
+
+func (q *Queries) AttachExperimentAssignmentsToOrder(ctx context.Context, arg AttachExperimentAssignmentsToOrderParams) error {
+	_, err := q.db.Exec(ctx, attachExperimentAssignmentsToOrder, arg.CartID, arg.OrderID)
 	return err
 }
 
+const clearCartAddresses = `-- name: ClearCartAddresses :execrows
+UPDATE carts
+SET shipping_address = NULL, billing_address = NULL, updated_at = NOW()
+WHERE id = $1 AND status = 'active'
+`
+
+func (q *Queries) ClearCartAddresses(ctx context.Context, id int32) (int64, error) {
+	result, err := q.db.Exec(ctx, clearCartAddresses, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const clearCartItems = `-- name: ClearCartItems :exec
 DELETE FROM cart_items WHERE cart_id = $1
 `
@@ -48,9 +109,37 @@ func (q *Queries) ClearCartItems(ctx context.Context, cartID uint64) error {
 	return err
 }
 
-const createCart = `-- name: CreateCart :exec
-INSERT INTO carts (customer_id, status, currency, subtotal, tax, discount, total, expires_at, created_at, updated_at)
-VALUES ($1, $2, $3, 0, 0, 0, 0, $4, NOW(), NOW())
+const clearDefaultCart = `-- name: ClearDefaultCart :many
+UPDATE carts
+SET is_default = FALSE
+WHERE customer_id = $1 AND status = 'active' AND is_default
+RETURNING id
+`
+
+func (q *Queries) ClearDefaultCart(ctx context.Context, customerID string) ([]int32, error) {
+	rows, err := q.db.Query(ctx, clearDefaultCart, customerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int32{}
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const createCart = `-- name: CreateCart :one
+INSERT INTO carts (customer_id, status, currency, subtotal, tax, discount, total, expires_at, name, is_default, created_at, updated_at)
+VALUES ($1, $2, $3, 0, 0, 0, 0, $4, $5, $6, NOW(), NOW())
+RETURNING id, created_at, updated_at
 `
 
 type CreateCartParams struct {
@@ -58,36 +147,77 @@ type CreateCartParams struct {
 	Status     CartStatus         `json:"status"`
 	Currency   Currency           `json:"currency"`
 	ExpiresAt  pgtype.Timestamptz `json:"expiresAt"`
+	Name       string             `json:"name"`
+	IsDefault  bool               `json:"isDefault"`
 }
 
-func (q *Queries) CreateCart(ctx context.Context, arg CreateCartParams) error {
-	_, err := q.db.Exec(ctx, createCart,
+type CreateCartRow struct {
+	ID        int32              `json:"id"`
+	CreatedAt pgtype.Timestamptz `json:"createdAt"`
+	UpdatedAt pgtype.Timestamptz `json:"updatedAt"`
+}
+
+func (q *Queries) CreateCart(ctx context.Context, arg CreateCartParams) (*CreateCartRow, error) {
+	row := q.db.QueryRow(ctx, createCart,
 		arg.CustomerID,
 		arg.Status,
 		arg.Currency,
 		arg.ExpiresAt,
+		arg.Name,
+		arg.IsDefault,
 	)
-	return err
+	var i CreateCartRow
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return &i, err
+}
+
+const extendCartExpiry = `-- name: ExtendCartExpiry :execrows
+UPDATE carts
+SET expires_at = GREATEST(expires_at, $2)
+WHERE id = $1 AND status = 'active'
+`
+
+type ExtendCartExpiryParams struct {
+	ID        int32              `json:"id"`
+	ExpiresAt pgtype.Timestamptz `json:"expiresAt"`
+}
+
+func (q *Queries) ExtendCartExpiry(ctx context.Context, arg ExtendCartExpiryParams) (int64, error) {
+	result, err := q.db.Exec(ctx, extendCartExpiry, arg.ID, arg.ExpiresAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
 const findActiveCartByCustomerID = `-- name: FindActiveCartByCustomerID :one
-SELECT id, customer_id, status, currency, subtotal, tax, discount, total, expires_at, created_at, updated_at
+SELECT id, customer_id, status, currency, subtotal, tax, discount, total, expires_at, created_at, updated_at, shipping_address, billing_address, applied_promotions, name, is_default, channel
 FROM carts
-WHERE customer_id = $1 AND status = 'active' LIMIT 1
+WHERE customer_id = $1 AND status = 'active' AND is_default
 `
 
 type FindActiveCartByCustomerIDRow struct {
-	ID         int32              `json:"id"`
-	CustomerID string             `json:"customerId"`
-	Status     CartStatus         `json:"status"`
-	Currency   Currency           `json:"currency"`
-	Subtotal   float64            `json:"subtotal"`
-	Tax        float64            `json:"tax"`
-	Discount   float64            `json:"discount"`
-	Total      float64            `json:"total"`
-	ExpiresAt  pgtype.Timestamptz `json:"expiresAt"`
-	CreatedAt  pgtype.Timestamptz `json:"createdAt"`
-	UpdatedAt  pgtype.Timestamptz `json:"updatedAt"`
+	ID                int32              `json:"id"`
+	CustomerID        string             `json:"customerId"`
+	Status            CartStatus         `json:"status"`
+	Currency          Currency           `json:"currency"`
+	Subtotal          float64            `json:"subtotal"`
+	Tax               float64            `json:"tax"`
+	Discount          float64            `json:"discount"`
+	Total             float64            `json:"total"`
+	ExpiresAt         pgtype.Timestamptz `json:"expiresAt"`
+	CreatedAt         pgtype.Timestamptz `json:"createdAt"`
+	UpdatedAt         pgtype.Timestamptz `json:"updatedAt"`
+	ShippingAddress   []byte             `json:"shippingAddress"`
+	BillingAddress    []byte             `json:"billingAddress"`
+	AppliedPromotions []byte             `json:"appliedPromotions"`
+	Name              string             `json:"name"`
+	IsDefault         bool               `json:"isDefault"`
+	Channel           *string            `json:"channel"`
 }
 
 func (q *Queries) FindActiveCartByCustomerID(ctx context.Context, customerID string) (*FindActiveCartByCustomerIDRow, error) {
@@ -105,14 +235,76 @@ func (q *Queries) FindActiveCartByCustomerID(ctx context.Context, customerID str
 		&i.ExpiresAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ShippingAddress,
+		&i.BillingAddress,
+		&i.AppliedPromotions,
+		&i.Name,
+		&i.IsDefault,
+		&i.Channel,
+	)
+	return &i, err
+}
+
+const findActiveCartByName = `-- name: FindActiveCartByName :one
+SELECT id, customer_id, status, currency, subtotal, tax, discount, total, expires_at, created_at, updated_at, shipping_address, billing_address, applied_promotions, name, is_default, channel
+FROM carts
+WHERE customer_id = $1 AND status = 'active' AND name = $2
+`
+
+type FindActiveCartByNameParams struct {
+	CustomerID string `json:"customerId"`
+	Name       string `json:"name"`
+}
+
+type FindActiveCartByNameRow struct {
+	ID                int32              `json:"id"`
+	CustomerID        string             `json:"customerId"`
+	Status            CartStatus         `json:"status"`
+	Currency          Currency           `json:"currency"`
+	Subtotal          float64            `json:"subtotal"`
+	Tax               float64            `json:"tax"`
+	Discount          float64            `json:"discount"`
+	Total             float64            `json:"total"`
+	ExpiresAt         pgtype.Timestamptz `json:"expiresAt"`
+	CreatedAt         pgtype.Timestamptz `json:"createdAt"`
+	UpdatedAt         pgtype.Timestamptz `json:"updatedAt"`
+	ShippingAddress   []byte             `json:"shippingAddress"`
+	BillingAddress    []byte             `json:"billingAddress"`
+	AppliedPromotions []byte             `json:"appliedPromotions"`
+	Name              string             `json:"name"`
+	IsDefault         bool               `json:"isDefault"`
+	Channel           *string            `json:"channel"`
+}
+
+func (q *Queries) FindActiveCartByName(ctx context.Context, arg FindActiveCartByNameParams) (*FindActiveCartByNameRow, error) {
+	row := q.db.QueryRow(ctx, findActiveCartByName, arg.CustomerID, arg.Name)
+	var i FindActiveCartByNameRow
+	err := row.Scan(
+		&i.ID,
+		&i.CustomerID,
+		&i.Status,
+		&i.Currency,
+		&i.Subtotal,
+		&i.Tax,
+		&i.Discount,
+		&i.Total,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ShippingAddress,
+		&i.BillingAddress,
+		&i.AppliedPromotions,
+		&i.Name,
+		&i.IsDefault,
+		&i.Channel,
 	)
 	return &i, err
 }
 
 const findCartItemByProductID = `-- name: FindCartItemByProductID :one
-SELECT id, cart_id, product_id, price_id, stock_id, quantity, unit_price, subtotal, created_at, updated_at
+SELECT id, cart_id, product_id, price_id, stock_id, quantity, unit_price, subtotal, created_at, updated_at, location, tax_rate, tax_amount, customization, note, metadata
 FROM cart_items
-WHERE cart_id = $1 AND product_id = $2
+WHERE cart_id = $1 AND product_id = $2 AND customization IS NULL AND note IS NULL AND metadata IS NULL
 `
 
 type FindCartItemByProductIDParams struct {
@@ -134,28 +326,40 @@ func (q *Queries) FindCartItemByProductID(ctx context.Context, arg FindCartItemB
 		&i.Subtotal,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Location,
+		&i.TaxRate,
+		&i.TaxAmount,
+		&i.Customization,
+		&i.Note,
+		&i.Metadata,
 	)
 	return &i, err
 }
 
 const getCart = `-- name: GetCart :one
-SELECT id, customer_id, status, currency, subtotal, tax, discount, total, expires_at, created_at, updated_at
+SELECT id, customer_id, status, currency, subtotal, tax, discount, total, expires_at, created_at, updated_at, shipping_address, billing_address, applied_promotions, name, is_default, channel
 FROM carts
 WHERE id = $1
 `
 
 type GetCartRow struct {
-	ID         int32              `json:"id"`
-	CustomerID string             `json:"customerId"`
-	Status     CartStatus         `json:"status"`
-	Currency   Currency           `json:"currency"`
-	Subtotal   float64            `json:"subtotal"`
-	Tax        float64            `json:"tax"`
-	Discount   float64            `json:"discount"`
-	Total      float64            `json:"total"`
-	ExpiresAt  pgtype.Timestamptz `json:"expiresAt"`
-	CreatedAt  pgtype.Timestamptz `json:"createdAt"`
-	UpdatedAt  pgtype.Timestamptz `json:"updatedAt"`
+	ID                int32              `json:"id"`
+	CustomerID        string             `json:"customerId"`
+	Status            CartStatus         `json:"status"`
+	Currency          Currency           `json:"currency"`
+	Subtotal          float64            `json:"subtotal"`
+	Tax               float64            `json:"tax"`
+	Discount          float64            `json:"discount"`
+	Total             float64            `json:"total"`
+	ExpiresAt         pgtype.Timestamptz `json:"expiresAt"`
+	CreatedAt         pgtype.Timestamptz `json:"createdAt"`
+	UpdatedAt         pgtype.Timestamptz `json:"updatedAt"`
+	ShippingAddress   []byte             `json:"shippingAddress"`
+	BillingAddress    []byte             `json:"billingAddress"`
+	AppliedPromotions []byte             `json:"appliedPromotions"`
+	Name              string             `json:"name"`
+	IsDefault         bool               `json:"isDefault"`
+	Channel           *string            `json:"channel"`
 }
 
 func (q *Queries) GetCart(ctx context.Context, id int32) (*GetCartRow, error) {
@@ -173,12 +377,39 @@ func (q *Queries) GetCart(ctx context.Context, id int32) (*GetCartRow, error) {
 		&i.ExpiresAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ShippingAddress,
+		&i.BillingAddress,
+		&i.AppliedPromotions,
+		&i.Name,
+		&i.IsDefault,
+		&i.Channel,
+	)
+	return &i, err
+}
+
+const getCartAddresses = `-- name: GetCartAddresses :one
+SELECT shipping_address, billing_address
+FROM carts
+WHERE id = $1
+`
+
+type GetCartAddressesRow struct {
+	ShippingAddress []byte `json:"shippingAddress"`
+	BillingAddress  []byte `json:"billingAddress"`
+}
+
+func (q *Queries) GetCartAddresses(ctx context.Context, id int32) (*GetCartAddressesRow, error) {
+	row := q.db.QueryRow(ctx, getCartAddresses, id)
+	var i GetCartAddressesRow
+	err := row.Scan(
+		&i.ShippingAddress,
+		&i.BillingAddress,
 	)
 	return &i, err
 }
 
 const getCartItem = `-- name: GetCartItem :one
-SELECT id, cart_id, product_id, price_id, stock_id, quantity, unit_price, subtotal, created_at, updated_at
+SELECT id, cart_id, product_id, price_id, stock_id, quantity, unit_price, subtotal, created_at, updated_at, location, tax_rate, tax_amount, customization, note, metadata
 FROM cart_items
 WHERE id = $1
 `
@@ -197,12 +428,138 @@ func (q *Queries) GetCartItem(ctx context.Context, id int32) (*CartItem, error)
 		&i.Subtotal,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Location,
+		&i.TaxRate,
+		&i.TaxAmount,
+		&i.Customization,
+		&i.Note,
+		&i.Metadata,
+	)
+	return &i, err
+}
+
+const getCustomerNotificationPreference = `-- name: GetCustomerNotificationPreference :one
+SELECT customer_id, email_enabled, sms_enabled, push_enabled, marketing_opted_out_at, transactional_opted_out_at, created_at, updated_at
+FROM customer_notification_preferences
+WHERE customer_id = $1
+`
+
+func (q *Queries) GetCustomerNotificationPreference(ctx context.Context, customerID string) (*CustomerNotificationPreference, error) {
+	row := q.db.QueryRow(ctx, getCustomerNotificationPreference, customerID)
+	var i CustomerNotificationPreference
+	err := row.Scan(
+		&i.CustomerID,
+		&i.EmailEnabled,
+		&i.SmsEnabled,
+		&i.PushEnabled,
+		&i.MarketingOptedOutAt,
+		&i.TransactionalOptedOutAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
 	)
 	return &i, err
 }
 
+const listActiveCartsByCustomerID = `-- name: ListActiveCartsByCustomerID :many
+SELECT id, customer_id, status, currency, subtotal, tax, discount, total, expires_at, created_at, updated_at, shipping_address, billing_address, applied_promotions, name, is_default, channel
+FROM carts
+WHERE customer_id = $1 AND status = 'active'
+ORDER BY is_default DESC, name
+`
+
+type ListActiveCartsByCustomerIDRow struct {
+	ID                int32              `json:"id"`
+	CustomerID        string             `json:"customerId"`
+	Status            CartStatus         `json:"status"`
+	Currency          Currency           `json:"currency"`
+	Subtotal          float64            `json:"subtotal"`
+	Tax               float64            `json:"tax"`
+	Discount          float64            `json:"discount"`
+	Total             float64            `json:"total"`
+	ExpiresAt         pgtype.Timestamptz `json:"expiresAt"`
+	CreatedAt         pgtype.Timestamptz `json:"createdAt"`
+	UpdatedAt         pgtype.Timestamptz `json:"updatedAt"`
+	ShippingAddress   []byte             `json:"shippingAddress"`
+	BillingAddress    []byte             `json:"billingAddress"`
+	AppliedPromotions []byte             `json:"appliedPromotions"`
+	Name              string             `json:"name"`
+	IsDefault         bool               `json:"isDefault"`
+	Channel           *string            `json:"channel"`
+}
+
+func (q *Queries) ListActiveCartsByCustomerID(ctx context.Context, customerID string) ([]*ListActiveCartsByCustomerIDRow, error) {
+	rows, err := q.db.Query(ctx, listActiveCartsByCustomerID, customerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*ListActiveCartsByCustomerIDRow{}
+	for rows.Next() {
+		var i ListActiveCartsByCustomerIDRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CustomerID,
+			&i.Status,
+			&i.Currency,
+			&i.Subtotal,
+			&i.Tax,
+			&i.Discount,
+			&i.Total,
+			&i.ExpiresAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ShippingAddress,
+			&i.BillingAddress,
+			&i.AppliedPromotions,
+			&i.Name,
+			&i.IsDefault,
+			&i.Channel,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCartExperimentAssignments = `-- name: ListCartExperimentAssignments :many
+SELECT cart_id, experiment, variant, order_id, assigned_at
+FROM experiment_assignments
+WHERE cart_id = $1
+ORDER BY experiment
+`
+
+func (q *Queries) ListCartExperimentAssignments(ctx context.Context, cartID uint64) ([]*ExperimentAssignment, error) {
+	rows, err := q.db.Query(ctx, listCartExperimentAssignments, cartID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*ExperimentAssignment{}
+	for rows.Next() {
+		var i ExperimentAssignment
+		if err := rows.Scan(
+			&i.CartID,
+			&i.Experiment,
+			&i.Variant,
+			&i.OrderID,
+			&i.AssignedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listCartItems = `-- name: ListCartItems :many
-SELECT id, cart_id, product_id, price_id, stock_id, quantity, unit_price, subtotal, created_at, updated_at
+SELECT id, cart_id, product_id, price_id, stock_id, quantity, unit_price, subtotal, created_at, updated_at, location, tax_rate, tax_amount, customization, note, metadata
 FROM cart_items
 WHERE cart_id = $1
 `
@@ -227,6 +584,12 @@ func (q *Queries) ListCartItems(ctx context.Context, cartID uint64) ([]*CartItem
 			&i.Subtotal,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Location,
+			&i.TaxRate,
+			&i.TaxAmount,
+			&i.Customization,
+			&i.Note,
+			&i.Metadata,
 		); err != nil {
 			return nil, err
 		}
@@ -238,6 +601,301 @@ func (q *Queries) ListCartItems(ctx context.Context, cartID uint64) ([]*CartItem
 	return items, nil
 }
 
+const listCartTotalMismatches = `-- name: ListCartTotalMismatches :many
+SELECT c.id, c.subtotal, c.tax, c.discount, c.total, COALESCE(SUM(ci.subtotal), 0)::float8 AS items_subtotal
+FROM carts c
+LEFT JOIN cart_items ci ON ci.cart_id = c.id
+WHERE c.status = 'active'
+GROUP BY c.id
+HAVING c.subtotal <> COALESCE(SUM(ci.subtotal), 0) OR c.total <> c.subtotal + c.tax - c.discount
+ORDER BY c.id
+`
+
+type ListCartTotalMismatchesRow struct {
+	ID            int32   `json:"id"`
+	Subtotal      float64 `json:"subtotal"`
+	Tax           float64 `json:"tax"`
+	Discount      float64 `json:"discount"`
+	Total         float64 `json:"total"`
+	ItemsSubtotal float64 `json:"itemsSubtotal"`
+}
+
+func (q *Queries) ListCartTotalMismatches(ctx context.Context) ([]*ListCartTotalMismatchesRow, error) {
+	rows, err := q.db.Query(ctx, listCartTotalMismatches)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*ListCartTotalMismatchesRow{}
+	for rows.Next() {
+		var i ListCartTotalMismatchesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Subtotal,
+			&i.Tax,
+			&i.Discount,
+			&i.Total,
+			&i.ItemsSubtotal,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listConvertedCartsWithoutOrder = `-- name: ListConvertedCartsWithoutOrder :many
+SELECT c.id, c.customer_id, c.status, c.currency, c.subtotal, c.tax, c.discount, c.total, c.created_at, c.updated_at, c.expires_at
+FROM carts c
+WHERE c.status = 'converted'
+  AND NOT EXISTS (SELECT 1 FROM orders o WHERE o.cart_id = c.id)
+  AND NOT EXISTS (SELECT 1 FROM orders_archive oa WHERE oa.cart_id = c.id)
+ORDER BY c.id
+`
+
+type ListConvertedCartsWithoutOrderRow struct {
+	ID         int32              `json:"id"`
+	CustomerID string             `json:"customerId"`
+	Status     CartStatus         `json:"status"`
+	Currency   Currency           `json:"currency"`
+	Subtotal   float64            `json:"subtotal"`
+	Tax        float64            `json:"tax"`
+	Discount   float64            `json:"discount"`
+	Total      float64            `json:"total"`
+	CreatedAt  pgtype.Timestamptz `json:"createdAt"`
+	UpdatedAt  pgtype.Timestamptz `json:"updatedAt"`
+	ExpiresAt  pgtype.Timestamptz `json:"expiresAt"`
+}
+
+func (q *Queries) ListConvertedCartsWithoutOrder(ctx context.Context) ([]*ListConvertedCartsWithoutOrderRow, error) {
+	rows, err := q.db.Query(ctx, listConvertedCartsWithoutOrder)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*ListConvertedCartsWithoutOrderRow{}
+	for rows.Next() {
+		var i ListConvertedCartsWithoutOrderRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CustomerID,
+			&i.Status,
+			&i.Currency,
+			&i.Subtotal,
+			&i.Tax,
+			&i.Discount,
+			&i.Total,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listExpiredActiveCartIDs = `-- name: ListExpiredActiveCartIDs :many
+SELECT id
+FROM carts
+WHERE status = 'active' AND expires_at < $1
+ORDER BY expires_at, id
+LIMIT $2
+`
+
+type ListExpiredActiveCartIDsParams struct {
+	ExpiresAt pgtype.Timestamptz `json:"expiresAt"`
+	Limit     int32              `json:"limit"`
+}
+
+func (q *Queries) ListExpiredActiveCartIDs(ctx context.Context, arg ListExpiredActiveCartIDsParams) ([]int32, error) {
+	rows, err := q.db.Query(ctx, listExpiredActiveCartIDs, arg.ExpiresAt, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int32{}
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listInactiveCartIDs = `-- name: ListInactiveCartIDs :many
+SELECT c.id
+FROM carts c
+WHERE c.status = 'active' AND c.updated_at < $1
+  AND (c.abandoned_notified_at IS NULL OR c.abandoned_notified_at < c.updated_at)
+  AND EXISTS (SELECT 1 FROM cart_items ci WHERE ci.cart_id = c.id)
+ORDER BY c.updated_at, c.id
+LIMIT $2
+`
+
+type ListInactiveCartIDsParams struct {
+	UpdatedAt pgtype.Timestamptz `json:"updatedAt"`
+	Limit     int32              `json:"limit"`
+}
+
+func (q *Queries) ListInactiveCartIDs(ctx context.Context, arg ListInactiveCartIDsParams) ([]int32, error) {
+	rows, err := q.db.Query(ctx, listInactiveCartIDs, arg.UpdatedAt, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int32{}
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOrderExperimentAssignments = `-- name: ListOrderExperimentAssignments :many
+SELECT cart_id, experiment, variant, order_id, assigned_at
+FROM experiment_assignments
+WHERE order_id = $1
+ORDER BY experiment
+`
+
+func (q *Queries) ListOrderExperimentAssignments(ctx context.Context, orderID *int32) ([]*ExperimentAssignment, error) {
+	rows, err := q.db.Query(ctx, listOrderExperimentAssignments, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*ExperimentAssignment{}
+	for rows.Next() {
+		var i ExperimentAssignment
+		if err := rows.Scan(
+			&i.CartID,
+			&i.Experiment,
+			&i.Variant,
+			&i.OrderID,
+			&i.AssignedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOrphanedCartItems = `-- name: ListOrphanedCartItems :many
+SELECT ci.id, ci.cart_id, c.status AS cart_status, ci.product_id, ci.stock_id, s.product_id AS stock_product_id
+FROM cart_items ci
+JOIN carts c ON c.id = ci.cart_id
+LEFT JOIN stocks s ON s.id = ci.stock_id
+WHERE s.id IS NULL OR s.product_id <> ci.product_id
+ORDER BY ci.id
+`
+
+type ListOrphanedCartItemsRow struct {
+	ID             int32      `json:"id"`
+	CartID         uint64     `json:"cartId"`
+	CartStatus     CartStatus `json:"cartStatus"`
+	ProductID      string     `json:"productId"`
+	StockID        *uint64    `json:"stockId"`
+	StockProductID *string    `json:"stockProductId"`
+}
+
+func (q *Queries) ListOrphanedCartItems(ctx context.Context) ([]*ListOrphanedCartItemsRow, error) {
+	rows, err := q.db.Query(ctx, listOrphanedCartItems)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*ListOrphanedCartItemsRow{}
+	for rows.Next() {
+		var i ListOrphanedCartItemsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CartID,
+			&i.CartStatus,
+			&i.ProductID,
+			&i.StockID,
+			&i.StockProductID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markCartAbandonedNotified = `-- name: MarkCartAbandonedNotified :execrows
+UPDATE carts
+SET abandoned_notified_at = $2
+WHERE id = $1 AND status = 'active' AND updated_at < $3
+  AND (abandoned_notified_at IS NULL OR abandoned_notified_at < updated_at)
+`
+
+type MarkCartAbandonedNotifiedParams struct {
+	ID                  int32              `json:"id"`
+	AbandonedNotifiedAt pgtype.Timestamptz `json:"abandonedNotifiedAt"`
+	UpdatedAt           pgtype.Timestamptz `json:"updatedAt"`
+}
+
+func (q *Queries) MarkCartAbandonedNotified(ctx context.Context, arg MarkCartAbandonedNotifiedParams) (int64, error) {
+	result, err := q.db.Exec(ctx, markCartAbandonedNotified, arg.ID, arg.AbandonedNotifiedAt, arg.UpdatedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const reactivateCart = `-- name: ReactivateCart :execrows
+UPDATE carts c
+SET status = 'active', expires_at = $2, updated_at = NOW(),
+    is_default = c.is_default AND NOT EXISTS (
+        SELECT 1 FROM carts a WHERE a.customer_id = c.customer_id AND a.status = 'active' AND a.is_default
+    ),
+    name = CASE
+        WHEN EXISTS (SELECT 1 FROM carts a WHERE a.customer_id = c.customer_id AND a.status = 'active' AND a.name = c.name)
+            THEN c.name || '-' || c.id
+        ELSE c.name
+    END
+WHERE c.id = $1 AND c.status = 'converted'
+`
+
+type ReactivateCartParams struct {
+	ID        int32              `json:"id"`
+	ExpiresAt pgtype.Timestamptz `json:"expiresAt"`
+}
+
+func (q *Queries) ReactivateCart(ctx context.Context, arg ReactivateCartParams) (int64, error) {
+	result, err := q.db.Exec(ctx, reactivateCart, arg.ID, arg.ExpiresAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const removeCartItem = `-- name: RemoveCartItem :exec
 DELETE FROM cart_items WHERE id = $1
 `
@@ -247,6 +905,80 @@ func (q *Queries) RemoveCartItem(ctx context.Context, id int32) error {
 	return err
 }
 
+const setCartAddresses = `-- name: SetCartAddresses :execrows
+UPDATE carts
+SET shipping_address = $2, billing_address = $3, updated_at = NOW()
+WHERE id = $1 AND status = 'active'
+`
+
+type SetCartAddressesParams struct {
+	ID              int32  `json:"id"`
+	ShippingAddress []byte `json:"shippingAddress"`
+	BillingAddress  []byte `json:"billingAddress"`
+}
+
+func (q *Queries) SetCartAddresses(ctx context.Context, arg SetCartAddressesParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setCartAddresses, arg.ID, arg.ShippingAddress, arg.BillingAddress)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const setCartChannel = `-- name: SetCartChannel :execrows
+UPDATE carts
+SET channel = $2, updated_at = NOW()
+WHERE id = $1 AND status = 'active'
+`
+
+type SetCartChannelParams struct {
+	ID      uint64  `json:"id"`
+	Channel *string `json:"channel"`
+}
+
+func (q *Queries) SetCartChannel(ctx context.Context, arg SetCartChannelParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setCartChannel, arg.ID, arg.Channel)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const setCartPromotions = `-- name: SetCartPromotions :exec
+UPDATE carts
+SET applied_promotions = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type SetCartPromotionsParams struct {
+	ID                int32  `json:"id"`
+	AppliedPromotions []byte `json:"appliedPromotions"`
+}
+
+func (q *Queries) SetCartPromotions(ctx context.Context, arg SetCartPromotionsParams) error {
+	_, err := q.db.Exec(ctx, setCartPromotions, arg.ID, arg.AppliedPromotions)
+	return err
+}
+
+const setDefaultCart = `-- name: SetDefaultCart :execrows
+UPDATE carts
+SET is_default = TRUE
+WHERE id = $1 AND customer_id = $2 AND status = 'active'
+`
+
+type SetDefaultCartParams struct {
+	ID         int32  `json:"id"`
+	CustomerID string `json:"customerId"`
+}
+
+func (q *Queries) SetDefaultCart(ctx context.Context, arg SetDefaultCartParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setDefaultCart, arg.ID, arg.CustomerID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const updateCartItem = `-- name: UpdateCartItem :exec
 UPDATE cart_items
 SET quantity = $2, subtotal = $3, updated_at = NOW()
@@ -270,6 +1002,32 @@ func (q *Queries) UpdateCartItem(ctx context.Context, arg UpdateCartItemParams)
 	return err
 }
 
+const updateCartItemMetadata = `-- name: UpdateCartItemMetadata :execrows
+UPDATE cart_items
+SET note = $3, metadata = $4, updated_at = NOW()
+WHERE id = $1 AND cart_id = $2
+`
+
+type UpdateCartItemMetadataParams struct {
+	ID       int32   `json:"id"`
+	CartID   uint64  `json:"cartId"`
+	Note     *string `json:"note"`
+	Metadata []byte  `json:"metadata"`
+}
+
+func (q *Queries) UpdateCartItemMetadata(ctx context.Context, arg UpdateCartItemMetadataParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateCartItemMetadata,
+		arg.ID,
+		arg.CartID,
+		arg.Note,
+		arg.Metadata,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const updateCartItemQuantity = `-- name: UpdateCartItemQuantity :exec
 UPDATE cart_items
 SET quantity = $2, subtotal = $3, updated_at = NOW()
@@ -287,6 +1045,23 @@ func (q *Queries) UpdateCartItemQuantity(ctx context.Context, arg UpdateCartItem
 	return err
 }
 
+const updateCartItemTax = `-- name: UpdateCartItemTax :exec
+UPDATE cart_items
+SET tax_rate = $2, tax_amount = $3
+WHERE id = $1
+`
+
+type UpdateCartItemTaxParams struct {
+	ID        int32   `json:"id"`
+	TaxRate   float64 `json:"taxRate"`
+	TaxAmount float64 `json:"taxAmount"`
+}
+
+func (q *Queries) UpdateCartItemTax(ctx context.Context, arg UpdateCartItemTaxParams) error {
+	_, err := q.db.Exec(ctx, updateCartItemTax, arg.ID, arg.TaxRate, arg.TaxAmount)
+	return err
+}
+
 const updateCartStatus = `-- name: UpdateCartStatus :exec
 UPDATE carts
 SET status = $2, updated_at = NOW()
@@ -306,18 +1081,68 @@ func (q *Queries) UpdateCartStatus(ctx context.Context, arg UpdateCartStatusPara
 
 const updateCartTotals = `-- name: UpdateCartTotals :exec
 UPDATE carts
-SET subtotal = (SELECT COALESCE(SUM(subtotal), 0) FROM cart_items WHERE cart_id = $1),
-    total = subtotal + tax - discount,
-    updated_at = NOW()
-WHERE id = $1 AND updated_at = $2
+SET subtotal = $2, tax = $3, discount = $4, total = $2 + $3 - $4, updated_at = NOW()
+WHERE id = $1
 `
 
 type UpdateCartTotalsParams struct {
-	Column1 *int32             `json:"column1"`
-	Column2 pgtype.Timestamptz `json:"column2"`
+	ID       int32   `json:"id"`
+	Subtotal float64 `json:"subtotal"`
+	Tax      float64 `json:"tax"`
+	Discount float64 `json:"discount"`
 }
 
 func (q *Queries) UpdateCartTotals(ctx context.Context, arg UpdateCartTotalsParams) error {
-	_, err := q.db.Exec(ctx, updateCartTotals, arg.Column1, arg.Column2)
+	_, err := q.db.Exec(ctx, updateCartTotals,
+		arg.ID,
+		arg.Subtotal,
+		arg.Tax,
+		arg.Discount,
+	)
 	return err
 }
+
+const upsertCustomerNotificationPreference = `-- name: UpsertCustomerNotificationPreference :one
+INSERT INTO customer_notification_preferences (customer_id, email_enabled, sms_enabled, push_enabled, marketing_opted_out_at, transactional_opted_out_at, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
+ON CONFLICT (customer_id) DO UPDATE
+SET email_enabled = EXCLUDED.email_enabled,
+    sms_enabled = EXCLUDED.sms_enabled,
+    push_enabled = EXCLUDED.push_enabled,
+    marketing_opted_out_at = EXCLUDED.marketing_opted_out_at,
+    transactional_opted_out_at = EXCLUDED.transactional_opted_out_at,
+    updated_at = NOW()
+RETURNING customer_id, email_enabled, sms_enabled, push_enabled, marketing_opted_out_at, transactional_opted_out_at, created_at, updated_at
+`
+
+type UpsertCustomerNotificationPreferenceParams struct {
+	CustomerID              string             `json:"customerId"`
+	EmailEnabled            bool               `json:"emailEnabled"`
+	SmsEnabled              bool               `json:"smsEnabled"`
+	PushEnabled             bool               `json:"pushEnabled"`
+	MarketingOptedOutAt     pgtype.Timestamptz `json:"marketingOptedOutAt"`
+	TransactionalOptedOutAt pgtype.Timestamptz `json:"transactionalOptedOutAt"`
+}
+
+func (q *Queries) UpsertCustomerNotificationPreference(ctx context.Context, arg UpsertCustomerNotificationPreferenceParams) (*CustomerNotificationPreference, error) {
+	row := q.db.QueryRow(ctx, upsertCustomerNotificationPreference,
+		arg.CustomerID,
+		arg.EmailEnabled,
+		arg.SmsEnabled,
+		arg.PushEnabled,
+		arg.MarketingOptedOutAt,
+		arg.TransactionalOptedOutAt,
+	)
+	var i CustomerNotificationPreference
+	err := row.Scan(
+		&i.CustomerID,
+		&i.EmailEnabled,
+		&i.SmsEnabled,
+		&i.PushEnabled,
+		&i.MarketingOptedOutAt,
+		&i.TransactionalOptedOutAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return &i, err
+}
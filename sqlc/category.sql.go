@@ -27,20 +27,115 @@ func (q *Queries) AssignProductToCategory(ctx context.Context, arg AssignProduct
 	return err
 }
 
-const createCategory = `-- name: CreateCategory :exec
-INSERT INTO categories (name, description, parent_id, created_at, updated_at)
-VALUES ($1, $2, $3, NOW(), NOW())
+const countCategories = `-- name: CountCategories :one
+SELECT COUNT(*)
+FROM categories
+`
+
+func (q *Queries) CountCategories(ctx context.Context) (int64, error) {
+	row := q.db.QueryRow(ctx, countCategories)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countSearchProducts = `-- name: CountSearchProducts :one
+SELECT COUNT(DISTINCT product_id)
+FROM product_translations
+WHERE ($1::text = '' OR locale = $1::text)
+  AND (name ILIKE $2::text OR description ILIKE $2::text)
+`
+
+type CountSearchProductsParams struct {
+	Locale  string `json:"locale"`
+	Pattern string `json:"pattern"`
+}
+
+func (q *Queries) CountSearchProducts(ctx context.Context, arg CountSearchProductsParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countSearchProducts, arg.Locale, arg.Pattern)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createCategory = `-- name: CreateCategory :one
+INSERT INTO categories (name, slug, description, parent_id, created_at, updated_at)
+VALUES ($1, $2, $3, $4, NOW(), NOW())
+RETURNING id, created_at, updated_at
 `
 
 type CreateCategoryParams struct {
 	Name        string  `json:"name"`
+	Slug        string  `json:"slug"`
 	Description *string `json:"description"`
 	ParentID    *int32  `json:"parentId"`
 }
 
-func (q *Queries) CreateCategory(ctx context.Context, arg CreateCategoryParams) error {
-	_, err := q.db.Exec(ctx, createCategory, arg.Name, arg.Description, arg.ParentID)
-	return err
+type CreateCategoryRow struct {
+	ID        int32              `json:"id"`
+	CreatedAt pgtype.Timestamptz `json:"createdAt"`
+	UpdatedAt pgtype.Timestamptz `json:"updatedAt"`
+}
+
+func (q *Queries) CreateCategory(ctx context.Context, arg CreateCategoryParams) (*CreateCategoryRow, error) {
+	row := q.db.QueryRow(ctx, createCategory,
+		arg.Name,
+		arg.Slug,
+		arg.Description,
+		arg.ParentID,
+	)
+	var i CreateCategoryRow
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return &i, err
+}
+
+const createProductMedia = `-- name: CreateProductMedia :one
+INSERT INTO product_media (product_id, price_id, type, url, storage_key, alt_text, position, content_type, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW())
+RETURNING id, product_id, price_id, type, url, storage_key, alt_text, position, content_type, created_at, updated_at
+`
+
+type CreateProductMediaParams struct {
+	ProductID   string           `json:"productId"`
+	PriceID     *string          `json:"priceId"`
+	Type        ProductMediaType `json:"type"`
+	Url         string           `json:"url"`
+	StorageKey  *string          `json:"storageKey"`
+	AltText     *string          `json:"altText"`
+	Position    int32            `json:"position"`
+	ContentType *string          `json:"contentType"`
+}
+
+func (q *Queries) CreateProductMedia(ctx context.Context, arg CreateProductMediaParams) (*ProductMedium, error) {
+	row := q.db.QueryRow(ctx, createProductMedia,
+		arg.ProductID,
+		arg.PriceID,
+		arg.Type,
+		arg.Url,
+		arg.StorageKey,
+		arg.AltText,
+		arg.Position,
+		arg.ContentType,
+	)
+	var i ProductMedium
+	err := row.Scan(
+		&i.ID,
+		&i.ProductID,
+		&i.PriceID,
+		&i.Type,
+		&i.Url,
+		&i.StorageKey,
+		&i.AltText,
+		&i.Position,
+		&i.ContentType,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return &i, err
 }
 
 const deleteCategory = `-- name: DeleteCategory :exec
@@ -52,8 +147,69 @@ func (q *Queries) DeleteCategory(ctx context.Context, id int32) error {
 	return err
 }
 
+const deleteCategoryTranslation = `-- name: DeleteCategoryTranslation :execrows
+DELETE FROM category_translations
+WHERE category_id = $1 AND locale = $2
+`
+
+type DeleteCategoryTranslationParams struct {
+	CategoryID int32  `json:"categoryId"`
+	Locale     string `json:"locale"`
+}
+
+func (q *Queries) DeleteCategoryTranslation(ctx context.Context, arg DeleteCategoryTranslationParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteCategoryTranslation, arg.CategoryID, arg.Locale)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteProductMedia = `-- name: DeleteProductMedia :one
+DELETE FROM product_media
+WHERE id = $1
+RETURNING id, product_id, price_id, type, url, storage_key, alt_text, position, content_type, created_at, updated_at
+`
+
+func (q *Queries) DeleteProductMedia(ctx context.Context, id int32) (*ProductMedium, error) {
+	row := q.db.QueryRow(ctx, deleteProductMedia, id)
+	var i ProductMedium
+	err := row.Scan(
+		&i.ID,
+		&i.ProductID,
+		&i.PriceID,
+		&i.Type,
+		&i.Url,
+		&i.StorageKey,
+		&i.AltText,
+		&i.Position,
+		&i.ContentType,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return &i, err
+}
+
+const deleteProductTranslation = `-- name: DeleteProductTranslation :execrows
+DELETE FROM product_translations
+WHERE product_id = $1 AND locale = $2
+`
+
+type DeleteProductTranslationParams struct {
+	ProductID string `json:"productId"`
+	Locale    string `json:"locale"`
+}
+
+func (q *Queries) DeleteProductTranslation(ctx context.Context, arg DeleteProductTranslationParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteProductTranslation, arg.ProductID, arg.Locale)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const getCategoryByID = `-- name: GetCategoryByID :one
-SELECT id, name, description, parent_id, created_at, updated_at
+SELECT id, name, description, parent_id, created_at, updated_at, slug
 FROM categories
 WHERE id = $1
 `
@@ -68,12 +224,72 @@ func (q *Queries) GetCategoryByID(ctx context.Context, id int32) (*Category, err
 		&i.ParentID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Slug,
 	)
 	return &i, err
 }
 
+const getProductMedia = `-- name: GetProductMedia :one
+SELECT id, product_id, price_id, type, url, storage_key, alt_text, position, content_type, created_at, updated_at
+FROM product_media
+WHERE id = $1
+`
+
+func (q *Queries) GetProductMedia(ctx context.Context, id int32) (*ProductMedium, error) {
+	row := q.db.QueryRow(ctx, getProductMedia, id)
+	var i ProductMedium
+	err := row.Scan(
+		&i.ID,
+		&i.ProductID,
+		&i.PriceID,
+		&i.Type,
+		&i.Url,
+		&i.StorageKey,
+		&i.AltText,
+		&i.Position,
+		&i.ContentType,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return &i, err
+}
+
+const listAllCategories = `-- name: ListAllCategories :many
+SELECT id, name, description, parent_id, created_at, updated_at, slug
+FROM categories
+ORDER BY id
+`
+
+func (q *Queries) ListAllCategories(ctx context.Context) ([]*Category, error) {
+	rows, err := q.db.Query(ctx, listAllCategories)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*Category{}
+	for rows.Next() {
+		var i Category
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.ParentID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Slug,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listCategories = `-- name: ListCategories :many
-SELECT id, name, description, parent_id, created_at, updated_at
+SELECT id, name, description, parent_id, created_at, updated_at, slug
 FROM categories
 ORDER BY created_at DESC
 LIMIT $1 OFFSET $2
@@ -100,6 +316,90 @@ func (q *Queries) ListCategories(ctx context.Context, arg ListCategoriesParams)
 			&i.ParentID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Slug,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCategoryTranslations = `-- name: ListCategoryTranslations :many
+SELECT category_id, locale, name, description, updated_at
+FROM category_translations
+WHERE category_id = ANY($1::int[]) AND locale = ANY($2::text[])
+ORDER BY category_id, locale
+`
+
+type ListCategoryTranslationsParams struct {
+	CategoryIds []int32  `json:"categoryIds"`
+	Locales     []string `json:"locales"`
+}
+
+func (q *Queries) ListCategoryTranslations(ctx context.Context, arg ListCategoryTranslationsParams) ([]*CategoryTranslation, error) {
+	rows, err := q.db.Query(ctx, listCategoryTranslations, arg.CategoryIds, arg.Locales)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*CategoryTranslation{}
+	for rows.Next() {
+		var i CategoryTranslation
+		if err := rows.Scan(
+			&i.CategoryID,
+			&i.Locale,
+			&i.Name,
+			&i.Description,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProductMedia = `-- name: ListProductMedia :many
+SELECT id, product_id, price_id, type, url, storage_key, alt_text, position, content_type, created_at, updated_at
+FROM product_media
+WHERE product_id = $1
+  AND ($2::text IS NULL OR price_id IS NULL OR price_id = $2)
+ORDER BY position, id
+`
+
+type ListProductMediaParams struct {
+	ProductID string  `json:"productId"`
+	PriceID   *string `json:"priceId"`
+}
+
+func (q *Queries) ListProductMedia(ctx context.Context, arg ListProductMediaParams) ([]*ProductMedium, error) {
+	rows, err := q.db.Query(ctx, listProductMedia, arg.ProductID, arg.PriceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*ProductMedium{}
+	for rows.Next() {
+		var i ProductMedium
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProductID,
+			&i.PriceID,
+			&i.Type,
+			&i.Url,
+			&i.StorageKey,
+			&i.AltText,
+			&i.Position,
+			&i.ContentType,
+			&i.CreatedAt,
+			&i.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -111,8 +411,126 @@ func (q *Queries) ListCategories(ctx context.Context, arg ListCategoriesParams)
 	return items, nil
 }
 
+const listProductTranslations = `-- name: ListProductTranslations :many
+SELECT product_id, locale, name, description, updated_at
+FROM product_translations
+WHERE product_id = ANY($1::text[]) AND locale = ANY($2::text[])
+ORDER BY product_id, locale
+`
+
+type ListProductTranslationsParams struct {
+	ProductIds []string `json:"productIds"`
+	Locales    []string `json:"locales"`
+}
+
+func (q *Queries) ListProductTranslations(ctx context.Context, arg ListProductTranslationsParams) ([]*ProductTranslation, error) {
+	rows, err := q.db.Query(ctx, listProductTranslations, arg.ProductIds, arg.Locales)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*ProductTranslation{}
+	for rows.Next() {
+		var i ProductTranslation
+		if err := rows.Scan(
+			&i.ProductID,
+			&i.Locale,
+			&i.Name,
+			&i.Description,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProductTranslationsAfter = `-- name: ListProductTranslationsAfter :many
+SELECT product_id, locale, name, description, updated_at
+FROM product_translations
+WHERE (product_id, locale) > ($1::text, $2::text)
+ORDER BY product_id, locale
+LIMIT $3
+`
+
+type ListProductTranslationsAfterParams struct {
+	AfterProductID string `json:"afterProductId"`
+	AfterLocale    string `json:"afterLocale"`
+	RowLimit       int32  `json:"rowLimit"`
+}
+
+func (q *Queries) ListProductTranslationsAfter(ctx context.Context, arg ListProductTranslationsAfterParams) ([]*ProductTranslation, error) {
+	rows, err := q.db.Query(ctx, listProductTranslationsAfter, arg.AfterProductID, arg.AfterLocale, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*ProductTranslation{}
+	for rows.Next() {
+		var i ProductTranslation
+		if err := rows.Scan(
+			&i.ProductID,
+			&i.Locale,
+			&i.Name,
+			&i.Description,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProductsInCategories = `-- name: ListProductsInCategories :many
+WITH RECURSIVE category_tree AS (
+    SELECT id FROM categories WHERE id = ANY($1::int[])
+    UNION
+    SELECT c.id
+    FROM category_tree ct
+    JOIN categories c ON c.parent_id = ct.id
+)
+SELECT DISTINCT pc.product_id
+FROM product_categories pc
+JOIN category_tree ct ON ct.id = pc.category_id
+WHERE pc.product_id = ANY($2::text[])
+ORDER BY pc.product_id
+`
+
+type ListProductsInCategoriesParams struct {
+	CategoryIds []int32  `json:"categoryIds"`
+	ProductIds  []string `json:"productIds"`
+}
+
+func (q *Queries) ListProductsInCategories(ctx context.Context, arg ListProductsInCategoriesParams) ([]string, error) {
+	rows, err := q.db.Query(ctx, listProductsInCategories, arg.CategoryIds, arg.ProductIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []string{}
+	for rows.Next() {
+		var product_id string
+		if err := rows.Scan(&product_id); err != nil {
+			return nil, err
+		}
+		items = append(items, product_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listSubcategories = `-- name: ListSubcategories :many
-SELECT id, name, description, parent_id, created_at, updated_at
+SELECT id, name, description, parent_id, created_at, updated_at, slug
 FROM categories
 WHERE parent_id = $1
 ORDER BY created_at DESC
@@ -134,6 +552,7 @@ func (q *Queries) ListSubcategories(ctx context.Context, parentID *int32) ([]*Ca
 			&i.ParentID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Slug,
 		); err != nil {
 			return nil, err
 		}
@@ -160,27 +579,177 @@ func (q *Queries) RemoveProductFromCategory(ctx context.Context, arg RemoveProdu
 	return err
 }
 
-const updateCategory = `-- name: UpdateCategory :exec
+const searchProductIDs = `-- name: SearchProductIDs :many
+SELECT DISTINCT product_id
+FROM product_translations
+WHERE ($1::text = '' OR locale = $1::text)
+  AND (name ILIKE $2::text OR description ILIKE $2::text)
+ORDER BY product_id
+LIMIT $3 OFFSET $4
+`
+
+type SearchProductIDsParams struct {
+	Locale    string `json:"locale"`
+	Pattern   string `json:"pattern"`
+	RowLimit  int32  `json:"rowLimit"`
+	RowOffset int32  `json:"rowOffset"`
+}
+
+func (q *Queries) SearchProductIDs(ctx context.Context, arg SearchProductIDsParams) ([]string, error) {
+	rows, err := q.db.Query(ctx, searchProductIDs,
+		arg.Locale,
+		arg.Pattern,
+		arg.RowLimit,
+		arg.RowOffset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []string{}
+	for rows.Next() {
+		var productID string
+		if err := rows.Scan(&productID); err != nil {
+			return nil, err
+		}
+		items = append(items, productID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateCategory = `-- name: UpdateCategory :execrows
 UPDATE categories
-SET name = $2, description = $3, parent_id = $4, updated_at = NOW()
-WHERE id = $1 AND updated_at = $5
+SET name = $2, slug = $3, description = $4, parent_id = $5, updated_at = NOW()
+WHERE id = $1 AND updated_at = $6
 `
 
 type UpdateCategoryParams struct {
 	ID          int32              `json:"id"`
 	Name        string             `json:"name"`
+	Slug        string             `json:"slug"`
 	Description *string            `json:"description"`
 	ParentID    *int32             `json:"parentId"`
 	UpdatedAt   pgtype.Timestamptz `json:"updatedAt"`
 }
 
-func (q *Queries) UpdateCategory(ctx context.Context, arg UpdateCategoryParams) error {
-	_, err := q.db.Exec(ctx, updateCategory,
+func (q *Queries) UpdateCategory(ctx context.Context, arg UpdateCategoryParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateCategory,
 		arg.ID,
 		arg.Name,
+		arg.Slug,
 		arg.Description,
 		arg.ParentID,
 		arg.UpdatedAt,
 	)
-	return err
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updateProductMedia = `-- name: UpdateProductMedia :one
+UPDATE product_media
+SET price_id = $2, alt_text = $3, position = $4, updated_at = NOW()
+WHERE id = $1
+RETURNING id, product_id, price_id, type, url, storage_key, alt_text, position, content_type, created_at, updated_at
+`
+
+type UpdateProductMediaParams struct {
+	ID       int32   `json:"id"`
+	PriceID  *string `json:"priceId"`
+	AltText  *string `json:"altText"`
+	Position int32   `json:"position"`
+}
+
+func (q *Queries) UpdateProductMedia(ctx context.Context, arg UpdateProductMediaParams) (*ProductMedium, error) {
+	row := q.db.QueryRow(ctx, updateProductMedia,
+		arg.ID,
+		arg.PriceID,
+		arg.AltText,
+		arg.Position,
+	)
+	var i ProductMedium
+	err := row.Scan(
+		&i.ID,
+		&i.ProductID,
+		&i.PriceID,
+		&i.Type,
+		&i.Url,
+		&i.StorageKey,
+		&i.AltText,
+		&i.Position,
+		&i.ContentType,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return &i, err
+}
+
+const upsertCategoryTranslation = `-- name: UpsertCategoryTranslation :one
+INSERT INTO category_translations (category_id, locale, name, description, updated_at)
+VALUES ($1, $2, $3, $4, NOW())
+ON CONFLICT (category_id, locale) DO UPDATE
+SET name = EXCLUDED.name, description = EXCLUDED.description, updated_at = EXCLUDED.updated_at
+RETURNING category_id, locale, name, description, updated_at
+`
+
+type UpsertCategoryTranslationParams struct {
+	CategoryID  int32   `json:"categoryId"`
+	Locale      string  `json:"locale"`
+	Name        string  `json:"name"`
+	Description *string `json:"description"`
+}
+
+func (q *Queries) UpsertCategoryTranslation(ctx context.Context, arg UpsertCategoryTranslationParams) (*CategoryTranslation, error) {
+	row := q.db.QueryRow(ctx, upsertCategoryTranslation,
+		arg.CategoryID,
+		arg.Locale,
+		arg.Name,
+		arg.Description,
+	)
+	var i CategoryTranslation
+	err := row.Scan(
+		&i.CategoryID,
+		&i.Locale,
+		&i.Name,
+		&i.Description,
+		&i.UpdatedAt,
+	)
+	return &i, err
+}
+
+const upsertProductTranslation = `-- name: UpsertProductTranslation :one
+INSERT INTO product_translations (product_id, locale, name, description, updated_at)
+VALUES ($1, $2, $3, $4, NOW())
+ON CONFLICT (product_id, locale) DO UPDATE
+SET name = EXCLUDED.name, description = EXCLUDED.description, updated_at = EXCLUDED.updated_at
+RETURNING product_id, locale, name, description, updated_at
+`
+
+type UpsertProductTranslationParams struct {
+	ProductID   string  `json:"productId"`
+	Locale      string  `json:"locale"`
+	Name        string  `json:"name"`
+	Description *string `json:"description"`
+}
+
+func (q *Queries) UpsertProductTranslation(ctx context.Context, arg UpsertProductTranslationParams) (*ProductTranslation, error) {
+	row := q.db.QueryRow(ctx, upsertProductTranslation,
+		arg.ProductID,
+		arg.Locale,
+		arg.Name,
+		arg.Description,
+	)
+	var i ProductTranslation
+	err := row.Scan(
+		&i.ProductID,
+		&i.Locale,
+		&i.Name,
+		&i.Description,
+		&i.UpdatedAt,
+	)
+	return &i, err
 }
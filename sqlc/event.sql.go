@@ -13,18 +13,20 @@ import (
 
 const createEvent = `-- name: CreateEvent :exec
 INSERT INTO events (
-    id, type, processed, created_at, updated_at
+    id, type, processed, created_at, updated_at, payload, payload_compressed
 ) VALUES (
-             $1, $2, $3, $4, $5
+             $1, $2, $3, $4, $5, $6, $7
          )
 `
 
 type CreateEventParams struct {
-	ID        string             `json:"id"`
-	Type      EventType          `json:"type"`
-	Processed bool               `json:"processed"`
-	CreatedAt pgtype.Timestamptz `json:"createdAt"`
-	UpdatedAt pgtype.Timestamptz `json:"updatedAt"`
+	ID                string             `json:"id"`
+	Type              EventType          `json:"type"`
+	Processed         bool               `json:"processed"`
+	CreatedAt         pgtype.Timestamptz `json:"createdAt"`
+	UpdatedAt         pgtype.Timestamptz `json:"updatedAt"`
+	Payload           []byte             `json:"payload"`
+	PayloadCompressed bool               `json:"payloadCompressed"`
 }
 
 func (q *Queries) CreateEvent(ctx context.Context, arg CreateEventParams) error {
@@ -34,19 +36,63 @@ func (q *Queries) CreateEvent(ctx context.Context, arg CreateEventParams) error
 		arg.Processed,
 		arg.CreatedAt,
 		arg.UpdatedAt,
+		arg.Payload,
+		arg.PayloadCompressed,
 	)
 	return err
 }
 
+const deleteParkedEvent = `-- name: DeleteParkedEvent :execrows
+DELETE FROM parked_events
+WHERE event_id = $1
+`
+
+func (q *Queries) DeleteParkedEvent(ctx context.Context, eventID string) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteParkedEvent, eventID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const getEventBacklog = `-- name: GetEventBacklog :one
+SELECT
+    (SELECT COUNT(*) FROM events WHERE processed = FALSE AND created_at < $1::timestamptz)::bigint AS unprocessed,
+    (SELECT COUNT(*) FROM parked_events)::bigint AS parked
+`
+
+type GetEventBacklogRow struct {
+	Unprocessed int64 `json:"unprocessed"`
+	Parked      int64 `json:"parked"`
+}
+
+func (q *Queries) GetEventBacklog(ctx context.Context, before pgtype.Timestamptz) (*GetEventBacklogRow, error) {
+	row := q.db.QueryRow(ctx, getEventBacklog, before)
+	var i GetEventBacklogRow
+	err := row.Scan(
+		&i.Unprocessed,
+		&i.Parked,
+	)
+	return &i, err
+}
+
 const getEventByID = `-- name: GetEventByID :one
 SELECT id, type, processed, created_at, updated_at
 FROM events
 WHERE id = $1
 `
 
-func (q *Queries) GetEventByID(ctx context.Context, id string) (*Event, error) {
+type GetEventByIDRow struct {
+	ID        string             `json:"id"`
+	Type      EventType          `json:"type"`
+	Processed bool               `json:"processed"`
+	CreatedAt pgtype.Timestamptz `json:"createdAt"`
+	UpdatedAt pgtype.Timestamptz `json:"updatedAt"`
+}
+
+func (q *Queries) GetEventByID(ctx context.Context, id string) (*GetEventByIDRow, error) {
 	row := q.db.QueryRow(ctx, getEventByID, id)
-	var i Event
+	var i GetEventByIDRow
 	err := row.Scan(
 		&i.ID,
 		&i.Type,
@@ -57,6 +103,141 @@ func (q *Queries) GetEventByID(ctx context.Context, id string) (*Event, error) {
 	return &i, err
 }
 
+const getEventPayload = `-- name: GetEventPayload :one
+SELECT payload, payload_compressed
+FROM events
+WHERE id = $1
+`
+
+type GetEventPayloadRow struct {
+	Payload           []byte `json:"payload"`
+	PayloadCompressed bool   `json:"payloadCompressed"`
+}
+
+func (q *Queries) GetEventPayload(ctx context.Context, id string) (*GetEventPayloadRow, error) {
+	row := q.db.QueryRow(ctx, getEventPayload, id)
+	var i GetEventPayloadRow
+	err := row.Scan(
+		&i.Payload,
+		&i.PayloadCompressed,
+	)
+	return &i, err
+}
+
+const getLastStripeObjectEventCreated = `-- name: GetLastStripeObjectEventCreated :one
+SELECT MAX(event_created)::timestamptz AS last_event_created
+FROM stripe_object_events
+WHERE object_id = $1
+`
+
+func (q *Queries) GetLastStripeObjectEventCreated(ctx context.Context, objectID string) (pgtype.Timestamptz, error) {
+	row := q.db.QueryRow(ctx, getLastStripeObjectEventCreated, objectID)
+	var lastEventCreated pgtype.Timestamptz
+	err := row.Scan(&lastEventCreated)
+	return lastEventCreated, err
+}
+
+const hasStripeObjectEvent = `-- name: HasStripeObjectEvent :one
+SELECT EXISTS (
+    SELECT 1
+    FROM stripe_object_events
+    WHERE object_id = $1 AND event_type = $2
+)
+`
+
+type HasStripeObjectEventParams struct {
+	ObjectID  string `json:"objectId"`
+	EventType string `json:"eventType"`
+}
+
+func (q *Queries) HasStripeObjectEvent(ctx context.Context, arg HasStripeObjectEventParams) (bool, error) {
+	row := q.db.QueryRow(ctx, hasStripeObjectEvent, arg.ObjectID, arg.EventType)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const listExpiredParkedEvents = `-- name: ListExpiredParkedEvents :many
+SELECT event_id, object_id, event_type, waiting_for, event_created, payload, parked_at
+FROM parked_events
+WHERE event_created < $1
+ORDER BY event_created, event_id
+LIMIT $2
+`
+
+type ListExpiredParkedEventsParams struct {
+	EventCreated pgtype.Timestamptz `json:"eventCreated"`
+	Limit        int64              `json:"limit"`
+}
+
+func (q *Queries) ListExpiredParkedEvents(ctx context.Context, arg ListExpiredParkedEventsParams) ([]*ParkedEvent, error) {
+	rows, err := q.db.Query(ctx, listExpiredParkedEvents, arg.EventCreated, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*ParkedEvent{}
+	for rows.Next() {
+		var i ParkedEvent
+		if err := rows.Scan(
+			&i.EventID,
+			&i.ObjectID,
+			&i.EventType,
+			&i.WaitingFor,
+			&i.EventCreated,
+			&i.Payload,
+			&i.ParkedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listParkedEventsByObject = `-- name: ListParkedEventsByObject :many
+SELECT event_id, object_id, event_type, waiting_for, event_created, payload, parked_at
+FROM parked_events
+WHERE object_id = $1 AND waiting_for = $2
+ORDER BY event_created, event_id
+`
+
+type ListParkedEventsByObjectParams struct {
+	ObjectID   string `json:"objectId"`
+	WaitingFor string `json:"waitingFor"`
+}
+
+func (q *Queries) ListParkedEventsByObject(ctx context.Context, arg ListParkedEventsByObjectParams) ([]*ParkedEvent, error) {
+	rows, err := q.db.Query(ctx, listParkedEventsByObject, arg.ObjectID, arg.WaitingFor)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*ParkedEvent{}
+	for rows.Next() {
+		var i ParkedEvent
+		if err := rows.Scan(
+			&i.EventID,
+			&i.ObjectID,
+			&i.EventType,
+			&i.WaitingFor,
+			&i.EventCreated,
+			&i.Payload,
+			&i.ParkedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const markEventAsProcessed = `-- name: MarkEventAsProcessed :exec
 UPDATE events
 SET processed = true, updated_at = $2
@@ -72,3 +253,67 @@ func (q *Queries) MarkEventAsProcessed(ctx context.Context, arg MarkEventAsProce
 	_, err := q.db.Exec(ctx, markEventAsProcessed, arg.ID, arg.UpdatedAt)
 	return err
 }
+
+const parkEvent = `-- name: ParkEvent :exec
+INSERT INTO parked_events (event_id, object_id, event_type, waiting_for, event_created, payload, parked_at)
+VALUES ($1, $2, $3, $4, $5, $6, NOW())
+ON CONFLICT (event_id) DO NOTHING
+`
+
+type ParkEventParams struct {
+	EventID      string             `json:"eventId"`
+	ObjectID     string             `json:"objectId"`
+	EventType    string             `json:"eventType"`
+	WaitingFor   string             `json:"waitingFor"`
+	EventCreated pgtype.Timestamptz `json:"eventCreated"`
+	Payload      []byte             `json:"payload"`
+}
+
+func (q *Queries) ParkEvent(ctx context.Context, arg ParkEventParams) error {
+	_, err := q.db.Exec(ctx, parkEvent,
+		arg.EventID,
+		arg.ObjectID,
+		arg.EventType,
+		arg.WaitingFor,
+		arg.EventCreated,
+		arg.Payload,
+	)
+	return err
+}
+
+const purgeEventPayloads = `-- name: PurgeEventPayloads :execrows
+UPDATE events
+SET payload = NULL, payload_compressed = FALSE
+WHERE created_at < $1 AND payload IS NOT NULL
+`
+
+func (q *Queries) PurgeEventPayloads(ctx context.Context, createdAt pgtype.Timestamptz) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeEventPayloads, createdAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const recordStripeObjectEvent = `-- name: RecordStripeObjectEvent :exec
+INSERT INTO stripe_object_events (object_id, event_id, event_type, event_created)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (object_id, event_id) DO NOTHING
+`
+
+type RecordStripeObjectEventParams struct {
+	ObjectID     string             `json:"objectId"`
+	EventID      string             `json:"eventId"`
+	EventType    string             `json:"eventType"`
+	EventCreated pgtype.Timestamptz `json:"eventCreated"`
+}
+
+func (q *Queries) RecordStripeObjectEvent(ctx context.Context, arg RecordStripeObjectEventParams) error {
+	_, err := q.db.Exec(ctx, recordStripeObjectEvent,
+		arg.ObjectID,
+		arg.EventID,
+		arg.EventType,
+		arg.EventCreated,
+	)
+	return err
+}
@@ -11,6 +11,242 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type CancellationReason string
+
+const (
+	CancellationReasonChangedMind      CancellationReason = "changed_mind"
+	CancellationReasonOrderedByMistake CancellationReason = "ordered_by_mistake"
+	CancellationReasonFoundBetterPrice CancellationReason = "found_better_price"
+	CancellationReasonDeliveryTooSlow  CancellationReason = "delivery_too_slow"
+	CancellationReasonPaymentIssue     CancellationReason = "payment_issue"
+	CancellationReasonOther            CancellationReason = "other"
+)
+
+func (e *CancellationReason) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = CancellationReason(s)
+	case string:
+		*e = CancellationReason(s)
+	default:
+		return fmt.Errorf("unsupported scan type for CancellationReason: %T", src)
+	}
+	return nil
+}
+
+type StocktakeStatus string
+
+const (
+	StocktakeStatusOpen      StocktakeStatus = "open"
+	StocktakeStatusCompleted StocktakeStatus = "completed"
+	StocktakeStatusCancelled StocktakeStatus = "cancelled"
+)
+
+func (e *StocktakeStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = StocktakeStatus(s)
+	case string:
+		*e = StocktakeStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for StocktakeStatus: %T", src)
+	}
+	return nil
+}
+
+type ChannelPrice struct {
+	Channel   string             `json:"channel"`
+	PriceID   string             `json:"priceId"`
+	Currency  Currency           `json:"currency"`
+	UnitPrice float64            `json:"unitPrice"`
+	CreatedAt pgtype.Timestamptz `json:"createdAt"`
+	UpdatedAt pgtype.Timestamptz `json:"updatedAt"`
+}
+
+type ChannelProduct struct {
+	Channel   string             `json:"channel"`
+	ProductID string             `json:"productId"`
+	Available bool               `json:"available"`
+	CreatedAt pgtype.Timestamptz `json:"createdAt"`
+	UpdatedAt pgtype.Timestamptz `json:"updatedAt"`
+}
+
+type NullStocktakeStatus struct {
+	StocktakeStatus StocktakeStatus `json:"stocktakeStatus"`
+	Valid           bool            `json:"valid"` // Valid is true if StocktakeStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullStocktakeStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.StocktakeStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.StocktakeStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullStocktakeStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.StocktakeStatus), nil
+}
+
+func (e StocktakeStatus) Valid() bool {
+	switch e {
+	case StocktakeStatusOpen,
+		StocktakeStatusCompleted,
+		StocktakeStatusCancelled:
+		return true
+	}
+	return false
+}
+
+type CartCoupon struct {
+	CartID    uint64             `json:"cartId"`
+	CouponID  int32              `json:"couponId"`
+	AppliedAt pgtype.Timestamptz `json:"appliedAt"`
+}
+
+type Coupon struct {
+	ID             int32              `json:"id"`
+	Code           string             `json:"code"`
+	Name           string             `json:"name"`
+	DiscountType   CouponDiscountType `json:"discountType"`
+	Amount         float64            `json:"amount"`
+	Currency       NullCurrency       `json:"currency"`
+	StartsAt       pgtype.Timestamptz `json:"startsAt"`
+	ExpiresAt      pgtype.Timestamptz `json:"expiresAt"`
+	UsageLimit     *int32             `json:"usageLimit"`
+	RedeemedCount  int32              `json:"redeemedCount"`
+	CreatedAt      pgtype.Timestamptz `json:"createdAt"`
+	UpdatedAt      pgtype.Timestamptz `json:"updatedAt"`
+	CustomerID     *string            `json:"customerId"`
+	RecoveryCartID *int32             `json:"recoveryCartId"`
+	RevokedAt      pgtype.Timestamptz `json:"revokedAt"`
+}
+
+type CouponRedemption struct {
+	ID         int32              `json:"id"`
+	CouponID   int32              `json:"couponId"`
+	OrderID    int32              `json:"orderId"`
+	CustomerID string             `json:"customerId"`
+	Discount   float64            `json:"discount"`
+	CreatedAt  pgtype.Timestamptz `json:"createdAt"`
+}
+
+type CustomerNotificationPreference struct {
+	CustomerID              string             `json:"customerId"`
+	EmailEnabled            bool               `json:"emailEnabled"`
+	SmsEnabled              bool               `json:"smsEnabled"`
+	PushEnabled             bool               `json:"pushEnabled"`
+	MarketingOptedOutAt     pgtype.Timestamptz `json:"marketingOptedOutAt"`
+	TransactionalOptedOutAt pgtype.Timestamptz `json:"transactionalOptedOutAt"`
+	CreatedAt               pgtype.Timestamptz `json:"createdAt"`
+	UpdatedAt               pgtype.Timestamptz `json:"updatedAt"`
+}
+
+type ExperimentAssignment struct {
+	CartID     uint64             `json:"cartId"`
+	Experiment string             `json:"experiment"`
+	Variant    string             `json:"variant"`
+	OrderID    *int32             `json:"orderId"`
+	AssignedAt pgtype.Timestamptz `json:"assignedAt"`
+}
+
+type NullCancellationReason struct {
+	CancellationReason CancellationReason `json:"cancellationReason"`
+	Valid              bool               `json:"valid"` // Valid is true if CancellationReason is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullCancellationReason) Scan(value interface{}) error {
+	if value == nil {
+		ns.CancellationReason, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.CancellationReason.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullCancellationReason) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.CancellationReason), nil
+}
+
+func (e CancellationReason) Valid() bool {
+	switch e {
+	case CancellationReasonChangedMind,
+		CancellationReasonOrderedByMistake,
+		CancellationReasonFoundBetterPrice,
+		CancellationReasonDeliveryTooSlow,
+		CancellationReasonPaymentIssue,
+		CancellationReasonOther:
+		return true
+	}
+	return false
+}
+
+type CancellationRequestStatus string
+
+const (
+	CancellationRequestStatusAutoCancelled CancellationRequestStatus = "auto_cancelled"
+	CancellationRequestStatusPendingReview CancellationRequestStatus = "pending_review"
+	CancellationRequestStatusApproved      CancellationRequestStatus = "approved"
+	CancellationRequestStatusRejected      CancellationRequestStatus = "rejected"
+)
+
+func (e *CancellationRequestStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = CancellationRequestStatus(s)
+	case string:
+		*e = CancellationRequestStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for CancellationRequestStatus: %T", src)
+	}
+	return nil
+}
+
+type NullCancellationRequestStatus struct {
+	CancellationRequestStatus CancellationRequestStatus `json:"cancellationRequestStatus"`
+	Valid                     bool                      `json:"valid"` // Valid is true if CancellationRequestStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullCancellationRequestStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.CancellationRequestStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.CancellationRequestStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullCancellationRequestStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.CancellationRequestStatus), nil
+}
+
+func (e CancellationRequestStatus) Valid() bool {
+	switch e {
+	case CancellationRequestStatusAutoCancelled,
+		CancellationRequestStatusPendingReview,
+		CancellationRequestStatusApproved,
+		CancellationRequestStatusRejected:
+		return true
+	}
+	return false
+}
+
 type CartStatus string
 
 const (
@@ -31,6 +267,76 @@ func (e *CartStatus) Scan(src interface{}) error {
 	return nil
 }
 
+type CampaignRedemption struct {
+	ID         int32              `json:"id"`
+	CampaignID int32              `json:"campaignId"`
+	OrderID    int32              `json:"orderId"`
+	ProductID  string             `json:"productId"`
+	Quantity   uint64             `json:"quantity"`
+	Subtotal   float64            `json:"subtotal"`
+	Discount   float64            `json:"discount"`
+	CreatedAt  pgtype.Timestamptz `json:"createdAt"`
+}
+
+type CategoryTranslation struct {
+	CategoryID  int32              `json:"categoryId"`
+	Locale      string             `json:"locale"`
+	Name        string             `json:"name"`
+	Description *string            `json:"description"`
+	UpdatedAt   pgtype.Timestamptz `json:"updatedAt"`
+}
+
+type DiscountCampaign struct {
+	ID                   int32              `json:"id"`
+	Name                 string             `json:"name"`
+	CategoryID           int32              `json:"categoryId"`
+	IncludeSubcategories bool               `json:"includeSubcategories"`
+	PercentOff           float64            `json:"percentOff"`
+	StartsAt             pgtype.Timestamptz `json:"startsAt"`
+	EndsAt               pgtype.Timestamptz `json:"endsAt"`
+	CreatedAt            pgtype.Timestamptz `json:"createdAt"`
+	UpdatedAt            pgtype.Timestamptz `json:"updatedAt"`
+}
+
+type FraudReview struct {
+	ID             int32              `json:"id"`
+	OrderID        int32              `json:"orderId"`
+	Reason         FraudReviewReason  `json:"reason"`
+	Detail         string             `json:"detail"`
+	Status         FraudReviewStatus  `json:"status"`
+	CreatedAt      pgtype.Timestamptz `json:"createdAt"`
+	ResolvedAt     pgtype.Timestamptz `json:"resolvedAt"`
+	ResolvedBy     *string            `json:"resolvedBy"`
+	ResolutionNote *string            `json:"resolutionNote"`
+}
+
+type Invoice struct {
+	ID                 int32              `json:"id"`
+	Tenant             string             `json:"tenant"`
+	Country            string             `json:"country"`
+	Type               InvoiceType        `json:"type"`
+	SequenceNumber     int64              `json:"sequenceNumber"`
+	InvoiceNumber      string             `json:"invoiceNumber"`
+	OrderID            int32              `json:"orderId"`
+	RefundID           *int32             `json:"refundId"`
+	OriginalInvoiceID  *int32             `json:"originalInvoiceId"`
+	Currency           Currency           `json:"currency"`
+	Subtotal           float64            `json:"subtotal"`
+	Tax                float64            `json:"tax"`
+	Total              float64            `json:"total"`
+	DocumentUrl        *string            `json:"documentUrl"`
+	IssuedAt           pgtype.Timestamptz `json:"issuedAt"`
+	TaxExemptionNumber *string            `json:"taxExemptionNumber"`
+}
+
+type InvoiceSequence struct {
+	Tenant     string             `json:"tenant"`
+	Country    string             `json:"country"`
+	Type       InvoiceType        `json:"type"`
+	NextNumber int64              `json:"nextNumber"`
+	UpdatedAt  pgtype.Timestamptz `json:"updatedAt"`
+}
+
 type NullCartStatus struct {
 	CartStatus CartStatus `json:"cartStatus"`
 	Valid      bool       `json:"valid"` // Valid is true if CartStatus is not NULL
@@ -64,6 +370,57 @@ func (e CartStatus) Valid() bool {
 	return false
 }
 
+type CouponDiscountType string
+
+const (
+	CouponDiscountTypePercentage CouponDiscountType = "percentage"
+	CouponDiscountTypeFixed      CouponDiscountType = "fixed"
+)
+
+func (e *CouponDiscountType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = CouponDiscountType(s)
+	case string:
+		*e = CouponDiscountType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for CouponDiscountType: %T", src)
+	}
+	return nil
+}
+
+type NullCouponDiscountType struct {
+	CouponDiscountType CouponDiscountType `json:"couponDiscountType"`
+	Valid              bool               `json:"valid"` // Valid is true if CouponDiscountType is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullCouponDiscountType) Scan(value interface{}) error {
+	if value == nil {
+		ns.CouponDiscountType, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.CouponDiscountType.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullCouponDiscountType) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.CouponDiscountType), nil
+}
+
+func (e CouponDiscountType) Valid() bool {
+	switch e {
+	case CouponDiscountTypePercentage,
+		CouponDiscountTypeFixed:
+		return true
+	}
+	return false
+}
+
 type Currency string
 
 const (
@@ -910,202 +1267,932 @@ func (e EventType) Valid() bool {
 	return false
 }
 
-type OrderStatus string
+type FraudReviewReason string
 
 const (
-	OrderStatusPending           OrderStatus = "pending"
-	OrderStatusProcessing        OrderStatus = "processing"
-	OrderStatusCompleted         OrderStatus = "completed"
-	OrderStatusCancelled         OrderStatus = "cancelled"
-	OrderStatusRefunded          OrderStatus = "refunded"
-	OrderStatusDisputed          OrderStatus = "disputed"
-	OrderStatusPartiallyRefunded OrderStatus = "partially_refunded"
+	FraudReviewReasonFingerprintVelocity FraudReviewReason = "fingerprint_velocity"
 )
 
-func (e *OrderStatus) Scan(src interface{}) error {
+func (e *FraudReviewReason) Scan(src interface{}) error {
 	switch s := src.(type) {
 	case []byte:
-		*e = OrderStatus(s)
+		*e = FraudReviewReason(s)
 	case string:
-		*e = OrderStatus(s)
+		*e = FraudReviewReason(s)
 	default:
-		return fmt.Errorf("unsupported scan type for OrderStatus: %T", src)
+		return fmt.Errorf("unsupported scan type for FraudReviewReason: %T", src)
 	}
 	return nil
 }
 
-type NullOrderStatus struct {
-	OrderStatus OrderStatus `json:"orderStatus"`
-	Valid       bool        `json:"valid"` // Valid is true if OrderStatus is not NULL
+type NullFraudReviewReason struct {
+	FraudReviewReason FraudReviewReason `json:"fraudReviewReason"`
+	Valid             bool              `json:"valid"` // Valid is true if FraudReviewReason is not NULL
 }
 
 // Scan implements the Scanner interface.
-func (ns *NullOrderStatus) Scan(value interface{}) error {
+func (ns *NullFraudReviewReason) Scan(value interface{}) error {
 	if value == nil {
-		ns.OrderStatus, ns.Valid = "", false
+		ns.FraudReviewReason, ns.Valid = "", false
 		return nil
 	}
 	ns.Valid = true
-	return ns.OrderStatus.Scan(value)
+	return ns.FraudReviewReason.Scan(value)
 }
 
 // Value implements the driver Valuer interface.
-func (ns NullOrderStatus) Value() (driver.Value, error) {
+func (ns NullFraudReviewReason) Value() (driver.Value, error) {
 	if !ns.Valid {
 		return nil, nil
 	}
-	return string(ns.OrderStatus), nil
+	return string(ns.FraudReviewReason), nil
 }
 
-func (e OrderStatus) Valid() bool {
+func (e FraudReviewReason) Valid() bool {
 	switch e {
-	case OrderStatusPending,
-		OrderStatusProcessing,
-		OrderStatusCompleted,
-		OrderStatusCancelled,
-		OrderStatusRefunded,
-		OrderStatusDisputed,
-		OrderStatusPartiallyRefunded:
+	case FraudReviewReasonFingerprintVelocity:
 		return true
 	}
 	return false
 }
 
-type StockMovementReferenceType string
+type FraudReviewStatus string
 
 const (
-	StockMovementReferenceTypeOrder      StockMovementReferenceType = "order"
-	StockMovementReferenceTypeReturn     StockMovementReferenceType = "return"
-	StockMovementReferenceTypeAdjustment StockMovementReferenceType = "adjustment"
-	StockMovementReferenceTypeCart       StockMovementReferenceType = "cart"
+	FraudReviewStatusPending   FraudReviewStatus = "pending"
+	FraudReviewStatusCleared   FraudReviewStatus = "cleared"
+	FraudReviewStatusConfirmed FraudReviewStatus = "confirmed"
 )
 
-func (e *StockMovementReferenceType) Scan(src interface{}) error {
+func (e *FraudReviewStatus) Scan(src interface{}) error {
 	switch s := src.(type) {
 	case []byte:
-		*e = StockMovementReferenceType(s)
+		*e = FraudReviewStatus(s)
 	case string:
-		*e = StockMovementReferenceType(s)
+		*e = FraudReviewStatus(s)
 	default:
-		return fmt.Errorf("unsupported scan type for StockMovementReferenceType: %T", src)
+		return fmt.Errorf("unsupported scan type for FraudReviewStatus: %T", src)
 	}
 	return nil
 }
 
-type NullStockMovementReferenceType struct {
-	StockMovementReferenceType StockMovementReferenceType `json:"stockMovementReferenceType"`
-	Valid                      bool                       `json:"valid"` // Valid is true if StockMovementReferenceType is not NULL
+type NullFraudReviewStatus struct {
+	FraudReviewStatus FraudReviewStatus `json:"fraudReviewStatus"`
+	Valid             bool              `json:"valid"` // Valid is true if FraudReviewStatus is not NULL
 }
 
 // Scan implements the Scanner interface.
-func (ns *NullStockMovementReferenceType) Scan(value interface{}) error {
+func (ns *NullFraudReviewStatus) Scan(value interface{}) error {
 	if value == nil {
-		ns.StockMovementReferenceType, ns.Valid = "", false
+		ns.FraudReviewStatus, ns.Valid = "", false
 		return nil
 	}
 	ns.Valid = true
-	return ns.StockMovementReferenceType.Scan(value)
+	return ns.FraudReviewStatus.Scan(value)
 }
 
 // Value implements the driver Valuer interface.
-func (ns NullStockMovementReferenceType) Value() (driver.Value, error) {
+func (ns NullFraudReviewStatus) Value() (driver.Value, error) {
 	if !ns.Valid {
 		return nil, nil
 	}
-	return string(ns.StockMovementReferenceType), nil
+	return string(ns.FraudReviewStatus), nil
 }
 
-func (e StockMovementReferenceType) Valid() bool {
+func (e FraudReviewStatus) Valid() bool {
 	switch e {
-	case StockMovementReferenceTypeOrder,
-		StockMovementReferenceTypeReturn,
-		StockMovementReferenceTypeAdjustment,
-		StockMovementReferenceTypeCart:
+	case FraudReviewStatusPending,
+		FraudReviewStatusCleared,
+		FraudReviewStatusConfirmed:
 		return true
 	}
 	return false
 }
 
-type StockMovementType string
+type FulfillmentType string
 
 const (
-	StockMovementTypeIn      StockMovementType = "in"
-	StockMovementTypeOut     StockMovementType = "out"
-	StockMovementTypeReserve StockMovementType = "reserve"
-	StockMovementTypeRelease StockMovementType = "release"
+	FulfillmentTypeShip   FulfillmentType = "ship"
+	FulfillmentTypePickup FulfillmentType = "pickup"
 )
 
-func (e *StockMovementType) Scan(src interface{}) error {
+func (e *FulfillmentType) Scan(src interface{}) error {
 	switch s := src.(type) {
 	case []byte:
-		*e = StockMovementType(s)
+		*e = FulfillmentType(s)
 	case string:
-		*e = StockMovementType(s)
+		*e = FulfillmentType(s)
 	default:
-		return fmt.Errorf("unsupported scan type for StockMovementType: %T", src)
+		return fmt.Errorf("unsupported scan type for FulfillmentType: %T", src)
 	}
 	return nil
 }
 
-type NullStockMovementType struct {
-	StockMovementType StockMovementType `json:"stockMovementType"`
-	Valid             bool              `json:"valid"` // Valid is true if StockMovementType is not NULL
+type NullFulfillmentType struct {
+	FulfillmentType FulfillmentType `json:"fulfillmentType"`
+	Valid           bool            `json:"valid"` // Valid is true if FulfillmentType is not NULL
 }
 
 // Scan implements the Scanner interface.
-func (ns *NullStockMovementType) Scan(value interface{}) error {
+func (ns *NullFulfillmentType) Scan(value interface{}) error {
 	if value == nil {
-		ns.StockMovementType, ns.Valid = "", false
+		ns.FulfillmentType, ns.Valid = "", false
 		return nil
 	}
 	ns.Valid = true
-	return ns.StockMovementType.Scan(value)
+	return ns.FulfillmentType.Scan(value)
 }
 
 // Value implements the driver Valuer interface.
-func (ns NullStockMovementType) Value() (driver.Value, error) {
+func (ns NullFulfillmentType) Value() (driver.Value, error) {
 	if !ns.Valid {
 		return nil, nil
 	}
-	return string(ns.StockMovementType), nil
+	return string(ns.FulfillmentType), nil
 }
 
-func (e StockMovementType) Valid() bool {
+func (e FulfillmentType) Valid() bool {
 	switch e {
-	case StockMovementTypeIn,
-		StockMovementTypeOut,
-		StockMovementTypeReserve,
-		StockMovementTypeRelease:
+	case FulfillmentTypeShip,
+		FulfillmentTypePickup:
 		return true
 	}
 	return false
 }
 
-type Cart struct {
-	ID         int32              `json:"id"`
-	CustomerID string             `json:"customerId"`
-	Status     CartStatus         `json:"status"`
-	Currency   Currency           `json:"currency"`
-	Subtotal   float64            `json:"subtotal"`
-	Tax        float64            `json:"tax"`
-	Discount   float64            `json:"discount"`
-	Total      float64            `json:"total"`
-	CreatedAt  pgtype.Timestamptz `json:"createdAt"`
-	UpdatedAt  pgtype.Timestamptz `json:"updatedAt"`
-	ExpiresAt  pgtype.Timestamptz `json:"expiresAt"`
+type InvoiceType string
+
+const (
+	InvoiceTypeInvoice    InvoiceType = "invoice"
+	InvoiceTypeCreditNote InvoiceType = "credit_note"
+)
+
+func (e *InvoiceType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = InvoiceType(s)
+	case string:
+		*e = InvoiceType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for InvoiceType: %T", src)
+	}
+	return nil
+}
+
+type NullInvoiceType struct {
+	InvoiceType InvoiceType `json:"invoiceType"`
+	Valid       bool        `json:"valid"` // Valid is true if InvoiceType is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullInvoiceType) Scan(value interface{}) error {
+	if value == nil {
+		ns.InvoiceType, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.InvoiceType.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullInvoiceType) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.InvoiceType), nil
+}
+
+func (e InvoiceType) Valid() bool {
+	switch e {
+	case InvoiceTypeInvoice,
+		InvoiceTypeCreditNote:
+		return true
+	}
+	return false
+}
+
+type OrderAddonType string
+
+const (
+	OrderAddonTypeGiftWrap         OrderAddonType = "gift_wrap"
+	OrderAddonTypeAssembly         OrderAddonType = "assembly"
+	OrderAddonTypeExtendedWarranty OrderAddonType = "extended_warranty"
+)
+
+func (e *OrderAddonType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = OrderAddonType(s)
+	case string:
+		*e = OrderAddonType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for OrderAddonType: %T", src)
+	}
+	return nil
+}
+
+type NullOrderAddonType struct {
+	OrderAddonType OrderAddonType `json:"orderAddonType"`
+	Valid          bool           `json:"valid"` // Valid is true if OrderAddonType is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullOrderAddonType) Scan(value interface{}) error {
+	if value == nil {
+		ns.OrderAddonType, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.OrderAddonType.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullOrderAddonType) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.OrderAddonType), nil
+}
+
+func (e OrderAddonType) Valid() bool {
+	switch e {
+	case OrderAddonTypeGiftWrap,
+		OrderAddonTypeAssembly,
+		OrderAddonTypeExtendedWarranty:
+		return true
+	}
+	return false
+}
+
+type OrderRepricingStatus string
+
+const (
+	OrderRepricingStatusApplied       OrderRepricingStatus = "applied"
+	OrderRepricingStatusPendingReview OrderRepricingStatus = "pending_review"
+	OrderRepricingStatusDismissed     OrderRepricingStatus = "dismissed"
+)
+
+func (e *OrderRepricingStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = OrderRepricingStatus(s)
+	case string:
+		*e = OrderRepricingStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for OrderRepricingStatus: %T", src)
+	}
+	return nil
+}
+
+type NullOrderRepricingStatus struct {
+	OrderRepricingStatus OrderRepricingStatus `json:"orderRepricingStatus"`
+	Valid                bool                 `json:"valid"` // Valid is true if OrderRepricingStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullOrderRepricingStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.OrderRepricingStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.OrderRepricingStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullOrderRepricingStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.OrderRepricingStatus), nil
+}
+
+func (e OrderRepricingStatus) Valid() bool {
+	switch e {
+	case OrderRepricingStatusApplied,
+		OrderRepricingStatusPendingReview,
+		OrderRepricingStatusDismissed:
+		return true
+	}
+	return false
+}
+
+type OrderStatus string
+
+const (
+	OrderStatusPending           OrderStatus = "pending"
+	OrderStatusProcessing        OrderStatus = "processing"
+	OrderStatusCompleted         OrderStatus = "completed"
+	OrderStatusCancelled         OrderStatus = "cancelled"
+	OrderStatusRefunded          OrderStatus = "refunded"
+	OrderStatusDisputed          OrderStatus = "disputed"
+	OrderStatusPartiallyRefunded OrderStatus = "partially_refunded"
+	OrderStatusReadyForPickup    OrderStatus = "ready_for_pickup"
+	OrderStatusOnHold            OrderStatus = "on_hold"
+	OrderStatusFailed            OrderStatus = "failed"
+	OrderStatusPaid              OrderStatus = "paid"
+	OrderStatusRefundPending     OrderStatus = "refund_pending"
+	OrderStatusRefundFailed      OrderStatus = "refund_failed"
+	OrderStatusAwaitingStock     OrderStatus = "awaiting_stock"
+	OrderStatusDispute           OrderStatus = "dispute"
+)
+
+func (e *OrderStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = OrderStatus(s)
+	case string:
+		*e = OrderStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for OrderStatus: %T", src)
+	}
+	return nil
+}
+
+type NullOrderStatus struct {
+	OrderStatus OrderStatus `json:"orderStatus"`
+	Valid       bool        `json:"valid"` // Valid is true if OrderStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullOrderStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.OrderStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.OrderStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullOrderStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.OrderStatus), nil
+}
+
+func (e OrderStatus) Valid() bool {
+	switch e {
+	case OrderStatusPending,
+		OrderStatusProcessing,
+		OrderStatusCompleted,
+		OrderStatusCancelled,
+		OrderStatusRefunded,
+		OrderStatusDisputed,
+		OrderStatusPartiallyRefunded,
+		OrderStatusReadyForPickup,
+		OrderStatusOnHold,
+		OrderStatusFailed,
+		OrderStatusPaid,
+		OrderStatusRefundPending,
+		OrderStatusRefundFailed,
+		OrderStatusAwaitingStock,
+		OrderStatusDispute:
+		return true
+	}
+	return false
+}
+
+type PriceChangeStatus string
+
+const (
+	PriceChangeStatusScheduled PriceChangeStatus = "scheduled"
+	PriceChangeStatusApplied   PriceChangeStatus = "applied"
+	PriceChangeStatusCancelled PriceChangeStatus = "cancelled"
+)
+
+func (e *PriceChangeStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = PriceChangeStatus(s)
+	case string:
+		*e = PriceChangeStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for PriceChangeStatus: %T", src)
+	}
+	return nil
+}
+
+type NullPriceChangeStatus struct {
+	PriceChangeStatus PriceChangeStatus `json:"priceChangeStatus"`
+	Valid             bool              `json:"valid"` // Valid is true if PriceChangeStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullPriceChangeStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.PriceChangeStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.PriceChangeStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullPriceChangeStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.PriceChangeStatus), nil
+}
+
+func (e PriceChangeStatus) Valid() bool {
+	switch e {
+	case PriceChangeStatusScheduled,
+		PriceChangeStatusApplied,
+		PriceChangeStatusCancelled:
+		return true
+	}
+	return false
+}
+
+type ProductMediaType string
+
+const (
+	ProductMediaTypeImage ProductMediaType = "image"
+	ProductMediaTypeVideo ProductMediaType = "video"
+)
+
+func (e *ProductMediaType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ProductMediaType(s)
+	case string:
+		*e = ProductMediaType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ProductMediaType: %T", src)
+	}
+	return nil
+}
+
+type NullProductMediaType struct {
+	ProductMediaType ProductMediaType `json:"productMediaType"`
+	Valid            bool             `json:"valid"` // Valid is true if ProductMediaType is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullProductMediaType) Scan(value interface{}) error {
+	if value == nil {
+		ns.ProductMediaType, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ProductMediaType.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullProductMediaType) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ProductMediaType), nil
+}
+
+func (e ProductMediaType) Valid() bool {
+	switch e {
+	case ProductMediaTypeImage,
+		ProductMediaTypeVideo:
+		return true
+	}
+	return false
+}
+
+type ReturnStatus string
+
+const (
+	ReturnStatusRequested   ReturnStatus = "requested"
+	ReturnStatusApproved    ReturnStatus = "approved"
+	ReturnStatusLabelIssued ReturnStatus = "label_issued"
+	ReturnStatusInTransit   ReturnStatus = "in_transit"
+	ReturnStatusReceived    ReturnStatus = "received"
+	ReturnStatusRejected    ReturnStatus = "rejected"
+)
+
+func (e *ReturnStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ReturnStatus(s)
+	case string:
+		*e = ReturnStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ReturnStatus: %T", src)
+	}
+	return nil
+}
+
+type NullReturnStatus struct {
+	ReturnStatus ReturnStatus `json:"returnStatus"`
+	Valid        bool         `json:"valid"` // Valid is true if ReturnStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullReturnStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.ReturnStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ReturnStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullReturnStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ReturnStatus), nil
+}
+
+func (e ReturnStatus) Valid() bool {
+	switch e {
+	case ReturnStatusRequested,
+		ReturnStatusApproved,
+		ReturnStatusLabelIssued,
+		ReturnStatusInTransit,
+		ReturnStatusReceived,
+		ReturnStatusRejected:
+		return true
+	}
+	return false
+}
+
+type StockAdjustmentStatus string
+
+const (
+	StockAdjustmentStatusPending  StockAdjustmentStatus = "pending"
+	StockAdjustmentStatusApproved StockAdjustmentStatus = "approved"
+	StockAdjustmentStatusRejected StockAdjustmentStatus = "rejected"
+)
+
+func (e *StockAdjustmentStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = StockAdjustmentStatus(s)
+	case string:
+		*e = StockAdjustmentStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for StockAdjustmentStatus: %T", src)
+	}
+	return nil
+}
+
+type NullStockAdjustmentStatus struct {
+	StockAdjustmentStatus StockAdjustmentStatus `json:"stockAdjustmentStatus"`
+	Valid                 bool                  `json:"valid"` // Valid is true if StockAdjustmentStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullStockAdjustmentStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.StockAdjustmentStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.StockAdjustmentStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullStockAdjustmentStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.StockAdjustmentStatus), nil
+}
+
+func (e StockAdjustmentStatus) Valid() bool {
+	switch e {
+	case StockAdjustmentStatusPending,
+		StockAdjustmentStatusApproved,
+		StockAdjustmentStatusRejected:
+		return true
+	}
+	return false
+}
+
+type StockMovementReason string
+
+const (
+	StockMovementReasonReceived   StockMovementReason = "received"
+	StockMovementReasonDamaged    StockMovementReason = "damaged"
+	StockMovementReasonLost       StockMovementReason = "lost"
+	StockMovementReasonFound      StockMovementReason = "found"
+	StockMovementReasonRecount    StockMovementReason = "recount"
+	StockMovementReasonCorrection StockMovementReason = "correction"
+	StockMovementReasonOther      StockMovementReason = "other"
+	StockMovementReasonTheft      StockMovementReason = "theft"
+)
+
+func (e *StockMovementReason) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = StockMovementReason(s)
+	case string:
+		*e = StockMovementReason(s)
+	default:
+		return fmt.Errorf("unsupported scan type for StockMovementReason: %T", src)
+	}
+	return nil
+}
+
+type NullStockMovementReason struct {
+	StockMovementReason StockMovementReason `json:"stockMovementReason"`
+	Valid               bool                `json:"valid"` // Valid is true if StockMovementReason is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullStockMovementReason) Scan(value interface{}) error {
+	if value == nil {
+		ns.StockMovementReason, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.StockMovementReason.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullStockMovementReason) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.StockMovementReason), nil
+}
+
+func (e StockMovementReason) Valid() bool {
+	switch e {
+	case StockMovementReasonReceived,
+		StockMovementReasonDamaged,
+		StockMovementReasonLost,
+		StockMovementReasonFound,
+		StockMovementReasonRecount,
+		StockMovementReasonCorrection,
+		StockMovementReasonOther,
+		StockMovementReasonTheft:
+		return true
+	}
+	return false
+}
+
+type StockMovementReferenceType string
+
+const (
+	StockMovementReferenceTypeOrder      StockMovementReferenceType = "order"
+	StockMovementReferenceTypeReturn     StockMovementReferenceType = "return"
+	StockMovementReferenceTypeAdjustment StockMovementReferenceType = "adjustment"
+	StockMovementReferenceTypeCart       StockMovementReferenceType = "cart"
+	StockMovementReferenceTypeHold       StockMovementReferenceType = "hold"
+	StockMovementReferenceTypeTransfer   StockMovementReferenceType = "transfer"
+	StockMovementReferenceTypeManual     StockMovementReferenceType = "manual"
+)
+
+func (e *StockMovementReferenceType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = StockMovementReferenceType(s)
+	case string:
+		*e = StockMovementReferenceType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for StockMovementReferenceType: %T", src)
+	}
+	return nil
+}
+
+type NullStockMovementReferenceType struct {
+	StockMovementReferenceType StockMovementReferenceType `json:"stockMovementReferenceType"`
+	Valid                      bool                       `json:"valid"` // Valid is true if StockMovementReferenceType is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullStockMovementReferenceType) Scan(value interface{}) error {
+	if value == nil {
+		ns.StockMovementReferenceType, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.StockMovementReferenceType.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullStockMovementReferenceType) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.StockMovementReferenceType), nil
+}
+
+func (e StockMovementReferenceType) Valid() bool {
+	switch e {
+	case StockMovementReferenceTypeOrder,
+		StockMovementReferenceTypeReturn,
+		StockMovementReferenceTypeAdjustment,
+		StockMovementReferenceTypeCart,
+		StockMovementReferenceTypeHold,
+		StockMovementReferenceTypeTransfer,
+		StockMovementReferenceTypeManual:
+		return true
+	}
+	return false
+}
+
+type StockMovementType string
+
+const (
+	StockMovementTypeIn      StockMovementType = "in"
+	StockMovementTypeOut     StockMovementType = "out"
+	StockMovementTypeReserve StockMovementType = "reserve"
+	StockMovementTypeRelease StockMovementType = "release"
+)
+
+func (e *StockMovementType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = StockMovementType(s)
+	case string:
+		*e = StockMovementType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for StockMovementType: %T", src)
+	}
+	return nil
+}
+
+type NullStockMovementType struct {
+	StockMovementType StockMovementType `json:"stockMovementType"`
+	Valid             bool              `json:"valid"` // Valid is true if StockMovementType is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullStockMovementType) Scan(value interface{}) error {
+	if value == nil {
+		ns.StockMovementType, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.StockMovementType.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullStockMovementType) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.StockMovementType), nil
+}
+
+func (e StockMovementType) Valid() bool {
+	switch e {
+	case StockMovementTypeIn,
+		StockMovementTypeOut,
+		StockMovementTypeReserve,
+		StockMovementTypeRelease:
+		return true
+	}
+	return false
+}
+
+type StockRentalStatus string
+
+const (
+	StockRentalStatusReserved  StockRentalStatus = "reserved"
+	StockRentalStatusReturned  StockRentalStatus = "returned"
+	StockRentalStatusCancelled StockRentalStatus = "cancelled"
+)
+
+func (e *StockRentalStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = StockRentalStatus(s)
+	case string:
+		*e = StockRentalStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for StockRentalStatus: %T", src)
+	}
+	return nil
+}
+
+type NullStockRentalStatus struct {
+	StockRentalStatus StockRentalStatus `json:"stockRentalStatus"`
+	Valid             bool              `json:"valid"` // Valid is true if StockRentalStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullStockRentalStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.StockRentalStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.StockRentalStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullStockRentalStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.StockRentalStatus), nil
+}
+
+func (e StockRentalStatus) Valid() bool {
+	switch e {
+	case StockRentalStatusReserved,
+		StockRentalStatusReturned,
+		StockRentalStatusCancelled:
+		return true
+	}
+	return false
+}
+
+type StockTransferStatus string
+
+const (
+	StockTransferStatusDraft    StockTransferStatus = "draft"
+	StockTransferStatusApproved StockTransferStatus = "approved"
+	StockTransferStatusRejected StockTransferStatus = "rejected"
+)
+
+func (e *StockTransferStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = StockTransferStatus(s)
+	case string:
+		*e = StockTransferStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for StockTransferStatus: %T", src)
+	}
+	return nil
+}
+
+type NullStockTransferStatus struct {
+	StockTransferStatus StockTransferStatus `json:"stockTransferStatus"`
+	Valid               bool                `json:"valid"` // Valid is true if StockTransferStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullStockTransferStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.StockTransferStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.StockTransferStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullStockTransferStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.StockTransferStatus), nil
+}
+
+func (e StockTransferStatus) Valid() bool {
+	switch e {
+	case StockTransferStatusDraft,
+		StockTransferStatusApproved,
+		StockTransferStatusRejected:
+		return true
+	}
+	return false
+}
+
+type Cart struct {
+	ID                  int32              `json:"id"`
+	CustomerID          string             `json:"customerId"`
+	Status              CartStatus         `json:"status"`
+	Currency            Currency           `json:"currency"`
+	Subtotal            float64            `json:"subtotal"`
+	Tax                 float64            `json:"tax"`
+	Discount            float64            `json:"discount"`
+	Total               float64            `json:"total"`
+	CreatedAt           pgtype.Timestamptz `json:"createdAt"`
+	UpdatedAt           pgtype.Timestamptz `json:"updatedAt"`
+	ExpiresAt           pgtype.Timestamptz `json:"expiresAt"`
+	ShippingAddress     []byte             `json:"shippingAddress"`
+	BillingAddress      []byte             `json:"billingAddress"`
+	AppliedPromotions   []byte             `json:"appliedPromotions"`
+	AbandonedNotifiedAt pgtype.Timestamptz `json:"abandonedNotifiedAt"`
+	Name                string             `json:"name"`
+	IsDefault           bool               `json:"isDefault"`
+	Channel             *string            `json:"channel"`
 }
 
 type CartItem struct {
-	ID        int32              `json:"id"`
-	CartID    uint64             `json:"cartId"`
-	ProductID string             `json:"productId"`
-	PriceID   string             `json:"priceId"`
-	StockID   uint64             `json:"stockId"`
-	Quantity  uint64             `json:"quantity"`
-	UnitPrice float64            `json:"unitPrice"`
-	Subtotal  float64            `json:"subtotal"`
-	CreatedAt pgtype.Timestamptz `json:"createdAt"`
-	UpdatedAt pgtype.Timestamptz `json:"updatedAt"`
+	ID            int32              `json:"id"`
+	CartID        uint64             `json:"cartId"`
+	ProductID     string             `json:"productId"`
+	PriceID       string             `json:"priceId"`
+	StockID       uint64             `json:"stockId"`
+	Quantity      uint64             `json:"quantity"`
+	UnitPrice     float64            `json:"unitPrice"`
+	Subtotal      float64            `json:"subtotal"`
+	CreatedAt     pgtype.Timestamptz `json:"createdAt"`
+	UpdatedAt     pgtype.Timestamptz `json:"updatedAt"`
+	Location      *string            `json:"location"`
+	TaxRate       float64            `json:"taxRate"`
+	TaxAmount     float64            `json:"taxAmount"`
+	Customization []byte             `json:"customization"`
+	Note          *string            `json:"note"`
+	Metadata      []byte             `json:"metadata"`
 }
 
 type Category struct {
@@ -1115,47 +2202,314 @@ type Category struct {
 	ParentID    *int32             `json:"parentId"`
 	CreatedAt   pgtype.Timestamptz `json:"createdAt"`
 	UpdatedAt   pgtype.Timestamptz `json:"updatedAt"`
+	Slug        string             `json:"slug"`
 }
 
 type Event struct {
-	ID        string             `json:"id"`
-	Type      EventType          `json:"type"`
-	Processed bool               `json:"processed"`
-	CreatedAt pgtype.Timestamptz `json:"createdAt"`
-	UpdatedAt pgtype.Timestamptz `json:"updatedAt"`
+	ID                string             `json:"id"`
+	Type              EventType          `json:"type"`
+	Processed         bool               `json:"processed"`
+	CreatedAt         pgtype.Timestamptz `json:"createdAt"`
+	UpdatedAt         pgtype.Timestamptz `json:"updatedAt"`
+	Payload           []byte             `json:"payload"`
+	PayloadCompressed bool               `json:"payloadCompressed"`
 }
 
 type Order struct {
-	ID              int32              `json:"id"`
-	CustomerID      string             `json:"customerId"`
-	CartID          uint64             `json:"cartId"`
-	Status          OrderStatus        `json:"status"`
-	Currency        Currency           `json:"currency"`
-	Subtotal        float64            `json:"subtotal"`
-	Tax             float64            `json:"tax"`
-	Discount        float64            `json:"discount"`
-	Total           float64            `json:"total"`
+	ID                        int32              `json:"id"`
+	CustomerID                string             `json:"customerId"`
+	CartID                    uint64             `json:"cartId"`
+	Status                    OrderStatus        `json:"status"`
+	Currency                  Currency           `json:"currency"`
+	Subtotal                  float64            `json:"subtotal"`
+	Tax                       float64            `json:"tax"`
+	Discount                  float64            `json:"discount"`
+	Total                     float64            `json:"total"`
+	PaymentIntentID           *string            `json:"paymentIntentId"`
+	InvoiceID                 *string            `json:"invoiceId"`
+	SubscriptionID            *string            `json:"subscriptionId"`
+	RefundID                  *string            `json:"refundId"`
+	ShippingAddress           []byte             `json:"shippingAddress"`
+	BillingAddress            []byte             `json:"billingAddress"`
+	CreatedAt                 pgtype.Timestamptz `json:"createdAt"`
+	UpdatedAt                 pgtype.Timestamptz `json:"updatedAt"`
+	FulfillmentType           FulfillmentType    `json:"fulfillmentType"`
+	PickupLocation            *string            `json:"pickupLocation"`
+	Metadata                  []byte             `json:"metadata"`
+	TaxCalculationID          *string            `json:"taxCalculationId"`
+	TaxTransactionID          *string            `json:"taxTransactionId"`
+	ReportingCurrency         NullCurrency       `json:"reportingCurrency"`
+	ExchangeRate              *float64           `json:"exchangeRate"`
+	ReportingSubtotal         *float64           `json:"reportingSubtotal"`
+	ReportingTax              *float64           `json:"reportingTax"`
+	ReportingDiscount         *float64           `json:"reportingDiscount"`
+	ReportingTotal            *float64           `json:"reportingTotal"`
+	OrderNumber               string             `json:"orderNumber"`
+	EstimatedDeliveryEarliest pgtype.Date        `json:"estimatedDeliveryEarliest"`
+	EstimatedDeliveryLatest   pgtype.Date        `json:"estimatedDeliveryLatest"`
+	ExternalSource            *string            `json:"externalSource"`
+	ExternalOrderID           *string            `json:"externalOrderId"`
+	AppliedPromotions         []byte             `json:"appliedPromotions"`
+	DeletedAt                 pgtype.Timestamptz `json:"deletedAt"`
+	Channel                   *string            `json:"channel"`
+	CartSnapshot              []byte             `json:"cartSnapshot"`
+}
+
+type OrderAddon struct {
+	ID          int32              `json:"id"`
+	OrderID     int32              `json:"orderId"`
+	OrderItemID *int32             `json:"orderItemId"`
+	Type        OrderAddonType     `json:"type"`
+	Description *string            `json:"description"`
+	Quantity    uint64             `json:"quantity"`
+	UnitPrice   float64            `json:"unitPrice"`
+	Subtotal    float64            `json:"subtotal"`
+	TaxRate     float64            `json:"taxRate"`
+	TaxAmount   float64            `json:"taxAmount"`
+	CreatedAt   pgtype.Timestamptz `json:"createdAt"`
+}
+
+type OrderAddonsArchive struct {
+	ID          int32              `json:"id"`
+	OrderID     int32              `json:"orderId"`
+	OrderItemID *int32             `json:"orderItemId"`
+	Type        OrderAddonType     `json:"type"`
+	Description *string            `json:"description"`
+	Quantity    uint64             `json:"quantity"`
+	UnitPrice   float64            `json:"unitPrice"`
+	Subtotal    float64            `json:"subtotal"`
+	TaxRate     float64            `json:"taxRate"`
+	TaxAmount   float64            `json:"taxAmount"`
+	CreatedAt   pgtype.Timestamptz `json:"createdAt"`
+}
+
+type OrderCancellationRequest struct {
+	ID         int32                     `json:"id"`
+	OrderID    int32                     `json:"orderId"`
+	CustomerID string                    `json:"customerId"`
+	Reason     CancellationReason        `json:"reason"`
+	Status     CancellationRequestStatus `json:"status"`
+	ReviewedBy *string                   `json:"reviewedBy"`
+	CreatedAt  pgtype.Timestamptz        `json:"createdAt"`
+	UpdatedAt  pgtype.Timestamptz        `json:"updatedAt"`
+}
+
+type OrderFulfillmentSla struct {
+	OrderID         int32              `json:"orderId"`
+	PaidAt          pgtype.Timestamptz `json:"paidAt"`
+	WarnAt          pgtype.Timestamptz `json:"warnAt"`
+	DueAt           pgtype.Timestamptz `json:"dueAt"`
+	FulfilledAt     pgtype.Timestamptz `json:"fulfilledAt"`
+	AtRiskAlertedAt pgtype.Timestamptz `json:"atRiskAlertedAt"`
+	BreachAlertedAt pgtype.Timestamptz `json:"breachAlertedAt"`
+	CreatedAt       pgtype.Timestamptz `json:"createdAt"`
+}
+
+type OrderHold struct {
+	ID             int32              `json:"id"`
+	OrderID        int32              `json:"orderId"`
+	Reason         string             `json:"reason"`
+	PreviousStatus OrderStatus        `json:"previousStatus"`
+	CreatedAt      pgtype.Timestamptz `json:"createdAt"`
+	ReleasedAt     pgtype.Timestamptz `json:"releasedAt"`
+}
+
+type OrderIdempotencyKey struct {
+	IdempotencyKey string             `json:"idempotencyKey"`
+	RequestHash    string             `json:"requestHash"`
+	OrderID        *int32             `json:"orderId"`
+	CreatedAt      pgtype.Timestamptz `json:"createdAt"`
+}
+
+type OrderItem struct {
+	ID               int32              `json:"id"`
+	OrderID          int32              `json:"orderId"`
+	ProductID        string             `json:"productId"`
+	PriceID          string             `json:"priceId"`
+	StockID          uint64             `json:"stockId"`
+	Quantity         uint64             `json:"quantity"`
+	UnitPrice        float64            `json:"unitPrice"`
+	Subtotal         float64            `json:"subtotal"`
+	CreatedAt        pgtype.Timestamptz `json:"createdAt"`
+	UpdatedAt        pgtype.Timestamptz `json:"updatedAt"`
+	Location         *string            `json:"location"`
+	ProductName      *string            `json:"productName"`
+	Sku              *string            `json:"sku"`
+	ImageUrl         *string            `json:"imageUrl"`
+	TaxClass         *string            `json:"taxClass"`
+	TaxRate          float64            `json:"taxRate"`
+	TaxAmount        float64            `json:"taxAmount"`
+	WeightGrams      *int32             `json:"weightGrams"`
+	LengthMm         *int32             `json:"lengthMm"`
+	WidthMm          *int32             `json:"widthMm"`
+	HeightMm         *int32             `json:"heightMm"`
+	HsCode           *string            `json:"hsCode"`
+	OriginCountry    *string            `json:"originCountry"`
+	Customization    []byte             `json:"customization"`
+	ShippedQuantity  uint64             `json:"shippedQuantity"`
+	ReturnedQuantity uint64             `json:"returnedQuantity"`
+	Note             *string            `json:"note"`
+	Metadata         []byte             `json:"metadata"`
+}
+
+type OrderItemsArchive struct {
+	ID               int32              `json:"id"`
+	OrderID          int32              `json:"orderId"`
+	ProductID        string             `json:"productId"`
+	PriceID          string             `json:"priceId"`
+	StockID          uint64             `json:"stockId"`
+	Quantity         uint64             `json:"quantity"`
+	UnitPrice        float64            `json:"unitPrice"`
+	Subtotal         float64            `json:"subtotal"`
+	Location         *string            `json:"location"`
+	ProductName      *string            `json:"productName"`
+	Sku              *string            `json:"sku"`
+	ImageUrl         *string            `json:"imageUrl"`
+	TaxClass         *string            `json:"taxClass"`
+	TaxRate          float64            `json:"taxRate"`
+	TaxAmount        float64            `json:"taxAmount"`
+	CreatedAt        pgtype.Timestamptz `json:"createdAt"`
+	UpdatedAt        pgtype.Timestamptz `json:"updatedAt"`
+	WeightGrams      *int32             `json:"weightGrams"`
+	LengthMm         *int32             `json:"lengthMm"`
+	WidthMm          *int32             `json:"widthMm"`
+	HeightMm         *int32             `json:"heightMm"`
+	HsCode           *string            `json:"hsCode"`
+	OriginCountry    *string            `json:"originCountry"`
+	Customization    []byte             `json:"customization"`
+	ShippedQuantity  uint64             `json:"shippedQuantity"`
+	ReturnedQuantity uint64             `json:"returnedQuantity"`
+	Note             *string            `json:"note"`
+	Metadata         []byte             `json:"metadata"`
+}
+
+type OrderReceiptLink struct {
+	OrderID         int32              `json:"orderId"`
 	PaymentIntentID *string            `json:"paymentIntentId"`
 	InvoiceID       *string            `json:"invoiceId"`
-	SubscriptionID  *string            `json:"subscriptionId"`
-	RefundID        *string            `json:"refundId"`
-	ShippingAddress []byte             `json:"shippingAddress"`
-	BillingAddress  []byte             `json:"billingAddress"`
-	CreatedAt       pgtype.Timestamptz `json:"createdAt"`
+	ReceiptUrl      *string            `json:"receiptUrl"`
+	InvoiceUrl      *string            `json:"invoiceUrl"`
+	InvoicePdfUrl   *string            `json:"invoicePdfUrl"`
 	UpdatedAt       pgtype.Timestamptz `json:"updatedAt"`
 }
 
-type OrderItem struct {
-	ID        int32              `json:"id"`
-	OrderID   int32              `json:"orderId"`
+type OrderRepricing struct {
+	ID            int32                `json:"id"`
+	OrderID       int32                `json:"orderId"`
+	PriceChangeID int32                `json:"priceChangeId"`
+	PriceID       string               `json:"priceId"`
+	NewUnitPrice  float64              `json:"newUnitPrice"`
+	PreviousTotal float64              `json:"previousTotal"`
+	NewTotal      float64              `json:"newTotal"`
+	Status        OrderRepricingStatus `json:"status"`
+	ReviewedBy    *string              `json:"reviewedBy"`
+	CreatedAt     pgtype.Timestamptz   `json:"createdAt"`
+	UpdatedAt     pgtype.Timestamptz   `json:"updatedAt"`
+}
+
+type OrderReturn struct {
+	ID             int32              `json:"id"`
+	OrderID        int32              `json:"orderId"`
+	Status         ReturnStatus       `json:"status"`
+	Reason         string             `json:"reason"`
+	Carrier        *string            `json:"carrier"`
+	TrackingNumber *string            `json:"trackingNumber"`
+	LabelUrl       *string            `json:"labelUrl"`
+	CreatedAt      pgtype.Timestamptz `json:"createdAt"`
+	UpdatedAt      pgtype.Timestamptz `json:"updatedAt"`
+}
+
+type OrderTaxExemption struct {
+	OrderID           int32              `json:"orderId"`
+	CertificateID     int32              `json:"certificateId"`
+	CertificateNumber string             `json:"certificateNumber"`
+	CreatedAt         pgtype.Timestamptz `json:"createdAt"`
+}
+
+type OrdersArchive struct {
+	ID                        int32              `json:"id"`
+	CustomerID                string             `json:"customerId"`
+	CartID                    uint64             `json:"cartId"`
+	Status                    OrderStatus        `json:"status"`
+	Currency                  Currency           `json:"currency"`
+	Subtotal                  float64            `json:"subtotal"`
+	Tax                       float64            `json:"tax"`
+	Discount                  float64            `json:"discount"`
+	Total                     float64            `json:"total"`
+	PaymentIntentID           *string            `json:"paymentIntentId"`
+	InvoiceID                 *string            `json:"invoiceId"`
+	SubscriptionID            *string            `json:"subscriptionId"`
+	RefundID                  *string            `json:"refundId"`
+	ShippingAddress           []byte             `json:"shippingAddress"`
+	BillingAddress            []byte             `json:"billingAddress"`
+	FulfillmentType           FulfillmentType    `json:"fulfillmentType"`
+	PickupLocation            *string            `json:"pickupLocation"`
+	CreatedAt                 pgtype.Timestamptz `json:"createdAt"`
+	UpdatedAt                 pgtype.Timestamptz `json:"updatedAt"`
+	ExternalSource            *string            `json:"externalSource"`
+	ExternalOrderID           *string            `json:"externalOrderId"`
+	ArchivedAt                pgtype.Timestamptz `json:"archivedAt"`
+	Metadata                  []byte             `json:"metadata"`
+	TaxCalculationID          *string            `json:"taxCalculationId"`
+	TaxTransactionID          *string            `json:"taxTransactionId"`
+	ReportingCurrency         NullCurrency       `json:"reportingCurrency"`
+	ExchangeRate              *float64           `json:"exchangeRate"`
+	ReportingSubtotal         *float64           `json:"reportingSubtotal"`
+	ReportingTax              *float64           `json:"reportingTax"`
+	ReportingDiscount         *float64           `json:"reportingDiscount"`
+	ReportingTotal            *float64           `json:"reportingTotal"`
+	OrderNumber               string             `json:"orderNumber"`
+	EstimatedDeliveryEarliest pgtype.Date        `json:"estimatedDeliveryEarliest"`
+	EstimatedDeliveryLatest   pgtype.Date        `json:"estimatedDeliveryLatest"`
+	AppliedPromotions         []byte             `json:"appliedPromotions"`
+	Channel                   *string            `json:"channel"`
+	CartSnapshot              []byte             `json:"cartSnapshot"`
+}
+
+type ParkedEvent struct {
+	EventID      string             `json:"eventId"`
+	ObjectID     string             `json:"objectId"`
+	EventType    string             `json:"eventType"`
+	WaitingFor   string             `json:"waitingFor"`
+	EventCreated pgtype.Timestamptz `json:"eventCreated"`
+	Payload      []byte             `json:"payload"`
+	ParkedAt     pgtype.Timestamptz `json:"parkedAt"`
+}
+
+type PaymentFingerprint struct {
+	PaymentIntentID string             `json:"paymentIntentId"`
+	Fingerprint     string             `json:"fingerprint"`
+	CustomerID      string             `json:"customerId"`
+	OrderID         int32              `json:"orderId"`
+	CreatedAt       pgtype.Timestamptz `json:"createdAt"`
+}
+
+type PriceChange struct {
+	ID          int32              `json:"id"`
+	PriceID     string             `json:"priceId"`
+	ProductID   string             `json:"productId"`
+	UnitPrice   float64            `json:"unitPrice"`
+	Status      PriceChangeStatus  `json:"status"`
+	Reason      *string            `json:"reason"`
+	EffectiveAt pgtype.Timestamptz `json:"effectiveAt"`
+	AppliedAt   pgtype.Timestamptz `json:"appliedAt"`
+	CreatedAt   pgtype.Timestamptz `json:"createdAt"`
+	Currency    NullCurrency       `json:"currency"`
+}
+
+type ProductAvailability struct {
+	ProductID         string             `json:"productId"`
+	Quantity          uint64             `json:"quantity"`
+	ReservedQuantity  int32              `json:"reservedQuantity"`
+	AvailableQuantity int32              `json:"availableQuantity"`
+	LocationCount     int32              `json:"locationCount"`
+	RefreshedAt       pgtype.Timestamptz `json:"refreshedAt"`
+}
+
+type ProductBarcode struct {
+	Barcode   string             `json:"barcode"`
 	ProductID string             `json:"productId"`
-	PriceID   string             `json:"priceId"`
-	StockID   uint64             `json:"stockId"`
-	Quantity  uint64             `json:"quantity"`
-	UnitPrice float64            `json:"unitPrice"`
-	Subtotal  float64            `json:"subtotal"`
 	CreatedAt pgtype.Timestamptz `json:"createdAt"`
-	UpdatedAt pgtype.Timestamptz `json:"updatedAt"`
 }
 
 type ProductCategory struct {
@@ -1164,6 +2518,73 @@ type ProductCategory struct {
 	CreatedAt  pgtype.Timestamptz `json:"createdAt"`
 }
 
+type ProductMedium struct {
+	ID          int32              `json:"id"`
+	ProductID   string             `json:"productId"`
+	PriceID     *string            `json:"priceId"`
+	Type        ProductMediaType   `json:"type"`
+	Url         string             `json:"url"`
+	StorageKey  *string            `json:"storageKey"`
+	AltText     *string            `json:"altText"`
+	Position    int32              `json:"position"`
+	ContentType *string            `json:"contentType"`
+	CreatedAt   pgtype.Timestamptz `json:"createdAt"`
+	UpdatedAt   pgtype.Timestamptz `json:"updatedAt"`
+}
+
+type ProductTranslation struct {
+	ProductID   string             `json:"productId"`
+	Locale      string             `json:"locale"`
+	Name        string             `json:"name"`
+	Description *string            `json:"description"`
+	UpdatedAt   pgtype.Timestamptz `json:"updatedAt"`
+}
+
+type Refund struct {
+	ID             int32              `json:"id"`
+	OrderID        int32              `json:"orderId"`
+	StripeRefundID *string            `json:"stripeRefundId"`
+	Currency       Currency           `json:"currency"`
+	Amount         float64            `json:"amount"`
+	RestockingFee  float64            `json:"restockingFee"`
+	Reason         *string            `json:"reason"`
+	PolicyVersion  string             `json:"policyVersion"`
+	CreatedAt      pgtype.Timestamptz `json:"createdAt"`
+}
+
+type RefundItem struct {
+	ID            int32   `json:"id"`
+	RefundID      int32   `json:"refundId"`
+	OrderItemID   *int32  `json:"orderItemId"`
+	Quantity      uint64  `json:"quantity"`
+	Amount        float64 `json:"amount"`
+	RestockingFee float64 `json:"restockingFee"`
+	Rule          string  `json:"rule"`
+	OrderAddonID  *int32  `json:"orderAddonId"`
+}
+
+type SavedItem struct {
+	ID            int32              `json:"id"`
+	CustomerID    string             `json:"customerId"`
+	ProductID     string             `json:"productId"`
+	PriceID       string             `json:"priceId"`
+	StockID       uint64             `json:"stockId"`
+	Quantity      uint64             `json:"quantity"`
+	UnitPrice     float64            `json:"unitPrice"`
+	Currency      Currency           `json:"currency"`
+	Location      *string            `json:"location"`
+	Customization []byte             `json:"customization"`
+	CreatedAt     pgtype.Timestamptz `json:"createdAt"`
+	UpdatedAt     pgtype.Timestamptz `json:"updatedAt"`
+}
+
+type Shipment struct {
+	ID        int32              `json:"id"`
+	OrderID   int32              `json:"orderId"`
+	Location  string             `json:"location"`
+	CreatedAt pgtype.Timestamptz `json:"createdAt"`
+}
+
 type Stock struct {
 	ID               int32              `json:"id"`
 	ProductID        string             `json:"productId"`
@@ -1172,14 +2593,137 @@ type Stock struct {
 	Location         *string            `json:"location"`
 	CreatedAt        pgtype.Timestamptz `json:"createdAt"`
 	UpdatedAt        pgtype.Timestamptz `json:"updatedAt"`
+	RentalEnabled    bool               `json:"rentalEnabled"`
+}
+
+type StockAdjustment struct {
+	ID            int32                 `json:"id"`
+	StockID       uint64                `json:"stockId"`
+	QuantityDelta int32                 `json:"quantityDelta"`
+	Reason        StockMovementReason   `json:"reason"`
+	Note          *string               `json:"note"`
+	Status        StockAdjustmentStatus `json:"status"`
+	RequestedBy   string                `json:"requestedBy"`
+	ReviewedBy    *string               `json:"reviewedBy"`
+	ReviewNote    *string               `json:"reviewNote"`
+	CreatedAt     pgtype.Timestamptz    `json:"createdAt"`
+	ReviewedAt    pgtype.Timestamptz    `json:"reviewedAt"`
+}
+
+type StockHold struct {
+	ID         int32              `json:"id"`
+	StockID    uint64             `json:"stockId"`
+	Quantity   uint64             `json:"quantity"`
+	Reason     string             `json:"reason"`
+	ExpiresAt  pgtype.Timestamptz `json:"expiresAt"`
+	ReleasedAt pgtype.Timestamptz `json:"releasedAt"`
+	CreatedAt  pgtype.Timestamptz `json:"createdAt"`
 }
 
 type StockMovement struct {
-	ID            int32                          `json:"id"`
-	StockID       uint64                         `json:"stockId"`
-	Quantity      uint64                         `json:"quantity"`
-	Type          StockMovementType              `json:"type"`
-	ReferenceID   *int32                         `json:"referenceId"`
-	ReferenceType NullStockMovementReferenceType `json:"referenceType"`
-	CreatedAt     pgtype.Timestamptz             `json:"createdAt"`
+	ID              int32                          `json:"id"`
+	StockID         uint64                         `json:"stockId"`
+	Quantity        uint64                         `json:"quantity"`
+	Type            StockMovementType              `json:"type"`
+	ReferenceID     *int32                         `json:"referenceId"`
+	ReferenceType   NullStockMovementReferenceType `json:"referenceType"`
+	CreatedAt       pgtype.Timestamptz             `json:"createdAt"`
+	Actor           *string                        `json:"actor"`
+	Note            *string                        `json:"note"`
+	UnitCost        *float64                       `json:"unitCost"`
+	Reason          NullStockMovementReason        `json:"reason"`
+	ReversalOfID    *int32                         `json:"reversalOfId"`
+	ReferenceItemID *int32                         `json:"referenceItemId"`
+}
+
+type StockProjection struct {
+	StockID              uint64             `json:"stockId"`
+	BaseQuantity         int32              `json:"baseQuantity"`
+	BaseReservedQuantity int32              `json:"baseReservedQuantity"`
+	BaseMovementID       int32              `json:"baseMovementId"`
+	ProjectedMovementID  int32              `json:"projectedMovementId"`
+	EnabledAt            pgtype.Timestamptz `json:"enabledAt"`
+	ProjectedAt          pgtype.Timestamptz `json:"projectedAt"`
+}
+
+type StockRental struct {
+	ID         int32              `json:"id"`
+	StockID    uint64             `json:"stockId"`
+	CustomerID string             `json:"customerId"`
+	Quantity   uint64             `json:"quantity"`
+	StartsOn   pgtype.Date        `json:"startsOn"`
+	EndsOn     pgtype.Date        `json:"endsOn"`
+	Status     StockRentalStatus  `json:"status"`
+	Note       *string            `json:"note"`
+	CreatedAt  pgtype.Timestamptz `json:"createdAt"`
+	UpdatedAt  pgtype.Timestamptz `json:"updatedAt"`
+}
+
+type StockTransfer struct {
+	ID          int32               `json:"id"`
+	FromStockID int32               `json:"fromStockId"`
+	ToStockID   int32               `json:"toStockId"`
+	Quantity    uint64              `json:"quantity"`
+	Note        *string             `json:"note"`
+	Status      StockTransferStatus `json:"status"`
+	RequestedBy string              `json:"requestedBy"`
+	ReviewedBy  *string             `json:"reviewedBy"`
+	ReviewNote  *string             `json:"reviewNote"`
+	CreatedAt   pgtype.Timestamptz  `json:"createdAt"`
+	ReviewedAt  pgtype.Timestamptz  `json:"reviewedAt"`
+}
+
+type Stocktake struct {
+	ID         int32              `json:"id"`
+	Location   string             `json:"location"`
+	Status     StocktakeStatus    `json:"status"`
+	StartedBy  string             `json:"startedBy"`
+	FinishedBy *string            `json:"finishedBy"`
+	CreatedAt  pgtype.Timestamptz `json:"createdAt"`
+	FinishedAt pgtype.Timestamptz `json:"finishedAt"`
+}
+
+type StocktakeScan struct {
+	ID           int32              `json:"id"`
+	StocktakeID  int32              `json:"stocktakeId"`
+	BatchID      string             `json:"batchId"`
+	Position     int32              `json:"position"`
+	Barcode      string             `json:"barcode"`
+	StockID      uint64             `json:"stockId"`
+	SerialNumber *string            `json:"serialNumber"`
+	Quantity     uint64             `json:"quantity"`
+	ScannedAt    pgtype.Timestamptz `json:"scannedAt"`
+	CreatedAt    pgtype.Timestamptz `json:"createdAt"`
+}
+
+type Store struct {
+	ID        int32              `json:"id"`
+	Name      string             `json:"name"`
+	Location  string             `json:"location"`
+	Address   []byte             `json:"address"`
+	Latitude  float64            `json:"latitude"`
+	Longitude float64            `json:"longitude"`
+	Hours     []byte             `json:"hours"`
+	CreatedAt pgtype.Timestamptz `json:"createdAt"`
+	UpdatedAt pgtype.Timestamptz `json:"updatedAt"`
+}
+
+type StripeObjectEvent struct {
+	ObjectID     string             `json:"objectId"`
+	EventID      string             `json:"eventId"`
+	EventType    string             `json:"eventType"`
+	EventCreated pgtype.Timestamptz `json:"eventCreated"`
+}
+
+type TaxExemptionCertificate struct {
+	ID                int32              `json:"id"`
+	CustomerID        string             `json:"customerId"`
+	CertificateNumber string             `json:"certificateNumber"`
+	Jurisdiction      string             `json:"jurisdiction"`
+	TaxClasses        []string           `json:"taxClasses"`
+	ValidFrom         pgtype.Timestamptz `json:"validFrom"`
+	ValidUntil        pgtype.Timestamptz `json:"validUntil"`
+	RevokedAt         pgtype.Timestamptz `json:"revokedAt"`
+	CreatedAt         pgtype.Timestamptz `json:"createdAt"`
+	UpdatedAt         pgtype.Timestamptz `json:"updatedAt"`
 }
@@ -11,21 +11,274 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const addOrderItemReturnedQuantity = `-- name: AddOrderItemReturnedQuantity :execrows
+UPDATE order_items
+SET returned_quantity = LEAST(shipped_quantity, returned_quantity + $2), updated_at = NOW()
+WHERE id = $1 AND returned_quantity < shipped_quantity
+`
+
+type AddOrderItemReturnedQuantityParams struct {
+	ID               int32  `json:"id"`
+	ReturnedQuantity uint64 `json:"returnedQuantity"`
+}
+
+func (q *Queries) AddOrderItemReturnedQuantity(ctx context.Context, arg AddOrderItemReturnedQuantityParams) (int64, error) {
+	result, err := q.db.Exec(ctx, addOrderItemReturnedQuantity, arg.ID, arg.ReturnedQuantity)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const archiveOrders = `-- name: ArchiveOrders :execrows
+WITH candidates AS (
+    SELECT id
+    FROM orders
+    WHERE status IN ('completed', 'cancelled') AND deleted_at IS NULL AND updated_at < $1
+    ORDER BY id
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+), archived_orders AS (
+    INSERT INTO orders_archive (id, customer_id, cart_id, status, currency, subtotal, tax, discount, total, payment_intent_id, invoice_id, subscription_id, refund_id, shipping_address, billing_address, fulfillment_type, pickup_location, metadata, tax_calculation_id, tax_transaction_id, reporting_currency, exchange_rate, reporting_subtotal, reporting_tax, reporting_discount, reporting_total, order_number, estimated_delivery_earliest, estimated_delivery_latest, external_source, external_order_id, applied_promotions, channel, cart_snapshot, created_at, updated_at)
+    SELECT id, customer_id, cart_id, status, currency, subtotal, tax, discount, total, payment_intent_id, invoice_id, subscription_id, refund_id, shipping_address, billing_address, fulfillment_type, pickup_location, metadata, tax_calculation_id, tax_transaction_id, reporting_currency, exchange_rate, reporting_subtotal, reporting_tax, reporting_discount, reporting_total, order_number, estimated_delivery_earliest, estimated_delivery_latest, external_source, external_order_id, applied_promotions, channel, cart_snapshot, created_at, updated_at
+    FROM orders
+    WHERE id IN (SELECT id FROM candidates)
+    RETURNING id
+), archived_items AS (
+    INSERT INTO order_items_archive (id, order_id, product_id, price_id, stock_id, quantity, unit_price, subtotal, location, product_name, sku, image_url, tax_class, tax_rate, tax_amount, weight_grams, length_mm, width_mm, height_mm, hs_code, origin_country, customization, shipped_quantity, returned_quantity, note, metadata, created_at, updated_at)
+    SELECT id, order_id, product_id, price_id, stock_id, quantity, unit_price, subtotal, location, product_name, sku, image_url, tax_class, tax_rate, tax_amount, weight_grams, length_mm, width_mm, height_mm, hs_code, origin_country, customization, shipped_quantity, returned_quantity, note, metadata, created_at, updated_at
+    FROM order_items
+    WHERE order_id IN (SELECT id FROM archived_orders)
+), archived_addons AS (
+    INSERT INTO order_addons_archive (id, order_id, order_item_id, type, description, quantity, unit_price, subtotal, tax_rate, tax_amount, created_at)
+    SELECT id, order_id, order_item_id, type, description, quantity, unit_price, subtotal, tax_rate, tax_amount, created_at
+    FROM order_addons
+    WHERE order_id IN (SELECT id FROM archived_orders)
+)
+DELETE FROM orders
+WHERE id IN (SELECT id FROM archived_orders)
+`
+
+type ArchiveOrdersParams struct {
+	Cutoff    pgtype.Timestamptz `json:"cutoff"`
+	BatchSize int32              `json:"batchSize"`
+}
+
+func (q *Queries) ArchiveOrders(ctx context.Context, arg ArchiveOrdersParams) (int64, error) {
+	result, err := q.db.Exec(ctx, archiveOrders, arg.Cutoff, arg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const claimOrderIdempotencyKey = `-- name: ClaimOrderIdempotencyKey :execrows
+INSERT INTO order_idempotency_keys (idempotency_key, request_hash, created_at)
+VALUES ($1, $2, NOW())
+ON CONFLICT (idempotency_key) DO UPDATE
+SET request_hash = EXCLUDED.request_hash, order_id = NULL, created_at = EXCLUDED.created_at
+WHERE order_idempotency_keys.created_at < $3
+`
+
+type ClaimOrderIdempotencyKeyParams struct {
+	IdempotencyKey string             `json:"idempotencyKey"`
+	RequestHash    string             `json:"requestHash"`
+	CreatedAt      pgtype.Timestamptz `json:"createdAt"`
+}
+
+func (q *Queries) ClaimOrderIdempotencyKey(ctx context.Context, arg ClaimOrderIdempotencyKeyParams) (int64, error) {
+	result, err := q.db.Exec(ctx, claimOrderIdempotencyKey, arg.IdempotencyKey, arg.RequestHash, arg.CreatedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const completeOrderFulfillmentSLA = `-- name: CompleteOrderFulfillmentSLA :execrows
+UPDATE order_fulfillment_slas
+SET fulfilled_at = $2
+WHERE order_id = $1 AND fulfilled_at IS NULL
+`
+
+type CompleteOrderFulfillmentSLAParams struct {
+	OrderID     int32              `json:"orderId"`
+	FulfilledAt pgtype.Timestamptz `json:"fulfilledAt"`
+}
+
+func (q *Queries) CompleteOrderFulfillmentSLA(ctx context.Context, arg CompleteOrderFulfillmentSLAParams) (int64, error) {
+	result, err := q.db.Exec(ctx, completeOrderFulfillmentSLA, arg.OrderID, arg.FulfilledAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const countFingerprintCustomers = `-- name: CountFingerprintCustomers :one
+SELECT COUNT(DISTINCT customer_id)
+FROM payment_fingerprints
+WHERE fingerprint = $1 AND created_at >= $2
+`
+
+type CountFingerprintCustomersParams struct {
+	Fingerprint string             `json:"fingerprint"`
+	CreatedAt   pgtype.Timestamptz `json:"createdAt"`
+}
+
+func (q *Queries) CountFingerprintCustomers(ctx context.Context, arg CountFingerprintCustomersParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countFingerprintCustomers, arg.Fingerprint, arg.CreatedAt)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countOrders = `-- name: CountOrders :one
+SELECT COUNT(*)
+FROM orders
+WHERE customer_id = $1 AND deleted_at IS NULL
+`
+
+func (q *Queries) CountOrders(ctx context.Context, customerID string) (int64, error) {
+	row := q.db.QueryRow(ctx, countOrders, customerID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countOrdersByStatus = `-- name: CountOrdersByStatus :one
+SELECT COUNT(*)
+FROM orders
+WHERE status = $1 AND created_at >= $2 AND deleted_at IS NULL
+`
+
+type CountOrdersByStatusParams struct {
+	Status    OrderStatus        `json:"status"`
+	CreatedAt pgtype.Timestamptz `json:"createdAt"`
+}
+
+func (q *Queries) CountOrdersByStatus(ctx context.Context, arg CountOrdersByStatusParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countOrdersByStatus, arg.Status, arg.CreatedAt)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countSearchOrders = `-- name: CountSearchOrders :one
+SELECT COUNT(*)
+FROM orders o
+WHERE o.deleted_at IS NULL
+  AND (o.order_number ILIKE $1::text
+    OR o.customer_id ILIKE $1::text
+    OR o.metadata::text ILIKE $1::text
+    OR EXISTS (SELECT 1 FROM order_items oi WHERE oi.order_id = o.id AND oi.product_name ILIKE $1::text))
+`
+
+func (q *Queries) CountSearchOrders(ctx context.Context, pattern string) (int64, error) {
+	row := q.db.QueryRow(ctx, countSearchOrders, pattern)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createFraudReview = `-- name: CreateFraudReview :execrows
+INSERT INTO fraud_reviews (order_id, reason, detail, status, created_at)
+VALUES ($1, $2, $3, 'pending', NOW())
+ON CONFLICT (order_id, reason) WHERE status = 'pending' DO NOTHING
+`
+
+type CreateFraudReviewParams struct {
+	OrderID int32             `json:"orderId"`
+	Reason  FraudReviewReason `json:"reason"`
+	Detail  string            `json:"detail"`
+}
+
+func (q *Queries) CreateFraudReview(ctx context.Context, arg CreateFraudReviewParams) (int64, error) {
+	result, err := q.db.Exec(ctx, createFraudReview, arg.OrderID, arg.Reason, arg.Detail)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const createInvoice = `-- name: CreateInvoice :one
+INSERT INTO invoices (tenant, country, type, sequence_number, invoice_number, order_id, refund_id, original_invoice_id, currency, subtotal, tax, total, tax_exemption_number, issued_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, NOW())
+RETURNING id, tenant, country, type, sequence_number, invoice_number, order_id, refund_id, original_invoice_id, currency, subtotal, tax, total, document_url, issued_at, tax_exemption_number
+`
+
+type CreateInvoiceParams struct {
+	Tenant             string      `json:"tenant"`
+	Country            string      `json:"country"`
+	Type               InvoiceType `json:"type"`
+	SequenceNumber     int64       `json:"sequenceNumber"`
+	InvoiceNumber      string      `json:"invoiceNumber"`
+	OrderID            int32       `json:"orderId"`
+	RefundID           *int32      `json:"refundId"`
+	OriginalInvoiceID  *int32      `json:"originalInvoiceId"`
+	Currency           Currency    `json:"currency"`
+	Subtotal           float64     `json:"subtotal"`
+	Tax                float64     `json:"tax"`
+	Total              float64     `json:"total"`
+	TaxExemptionNumber *string     `json:"taxExemptionNumber"`
+}
+
+func (q *Queries) CreateInvoice(ctx context.Context, arg CreateInvoiceParams) (*Invoice, error) {
+	row := q.db.QueryRow(ctx, createInvoice,
+		arg.Tenant,
+		arg.Country,
+		arg.Type,
+		arg.SequenceNumber,
+		arg.InvoiceNumber,
+		arg.OrderID,
+		arg.RefundID,
+		arg.OriginalInvoiceID,
+		arg.Currency,
+		arg.Subtotal,
+		arg.Tax,
+		arg.Total,
+		arg.TaxExemptionNumber,
+	)
+	var i Invoice
+	err := row.Scan(
+		&i.ID,
+		&i.Tenant,
+		&i.Country,
+		&i.Type,
+		&i.SequenceNumber,
+		&i.InvoiceNumber,
+		&i.OrderID,
+		&i.RefundID,
+		&i.OriginalInvoiceID,
+		&i.Currency,
+		&i.Subtotal,
+		&i.Tax,
+		&i.Total,
+		&i.DocumentUrl,
+		&i.IssuedAt,
+		&i.TaxExemptionNumber,
+	)
+	return &i, err
+}
+
 const createOrder = `-- name: CreateOrder :one
-INSERT INTO orders (customer_id, cart_id, status, currency, subtotal, tax, discount, total, created_at, updated_at)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW())
+INSERT INTO orders (order_number, customer_id, cart_id, status, currency, subtotal, tax, discount, total, shipping_address, billing_address, created_at, updated_at)
+SELECT $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NOW(), NOW()
+WHERE NOT EXISTS (SELECT 1 FROM orders_archive WHERE order_number = $1)
+ON CONFLICT (order_number) DO NOTHING
 RETURNING id, updated_at
 `
 
 type CreateOrderParams struct {
-	CustomerID string      `json:"customerId"`
-	CartID     uint64      `json:"cartId"`
-	Status     OrderStatus `json:"status"`
-	Currency   Currency    `json:"currency"`
-	Subtotal   float64     `json:"subtotal"`
-	Tax        float64     `json:"tax"`
-	Discount   float64     `json:"discount"`
-	Total      float64     `json:"total"`
+	OrderNumber     string      `json:"orderNumber"`
+	CustomerID      string      `json:"customerId"`
+	CartID          uint64      `json:"cartId"`
+	Status          OrderStatus `json:"status"`
+	Currency        Currency    `json:"currency"`
+	Subtotal        float64     `json:"subtotal"`
+	Tax             float64     `json:"tax"`
+	Discount        float64     `json:"discount"`
+	Total           float64     `json:"total"`
+	ShippingAddress []byte      `json:"shippingAddress"`
+	BillingAddress  []byte      `json:"billingAddress"`
 }
 
 type CreateOrderRow struct {
@@ -35,6 +288,7 @@ type CreateOrderRow struct {
 
 func (q *Queries) CreateOrder(ctx context.Context, arg CreateOrderParams) (*CreateOrderRow, error) {
 	row := q.db.QueryRow(ctx, createOrder,
+		arg.OrderNumber,
 		arg.CustomerID,
 		arg.CartID,
 		arg.Status,
@@ -43,298 +297,2681 @@ func (q *Queries) CreateOrder(ctx context.Context, arg CreateOrderParams) (*Crea
 		arg.Tax,
 		arg.Discount,
 		arg.Total,
+		arg.ShippingAddress,
+		arg.BillingAddress,
 	)
 	var i CreateOrderRow
 	err := row.Scan(&i.ID, &i.UpdatedAt)
 	return &i, err
 }
 
-const deleteOrder = `-- name: DeleteOrder :exec
-DELETE FROM orders WHERE id = $1
+const createOrderAddon = `-- name: CreateOrderAddon :one
+INSERT INTO order_addons (order_id, order_item_id, type, description, quantity, unit_price, subtotal, tax_rate, tax_amount, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW())
+RETURNING id, order_id, order_item_id, type, description, quantity, unit_price, subtotal, tax_rate, tax_amount, created_at
 `
 
-func (q *Queries) DeleteOrder(ctx context.Context, id int32) error {
-	_, err := q.db.Exec(ctx, deleteOrder, id)
-	return err
+type CreateOrderAddonParams struct {
+	OrderID     int32          `json:"orderId"`
+	OrderItemID *int32         `json:"orderItemId"`
+	Type        OrderAddonType `json:"type"`
+	Description *string        `json:"description"`
+	Quantity    uint64         `json:"quantity"`
+	UnitPrice   float64        `json:"unitPrice"`
+	Subtotal    float64        `json:"subtotal"`
+	TaxRate     float64        `json:"taxRate"`
+	TaxAmount   float64        `json:"taxAmount"`
 }
 
-const deleteOrderItem = `-- name: DeleteOrderItem :exec
-DELETE FROM order_items WHERE id = $1
-`
-
-func (q *Queries) DeleteOrderItem(ctx context.Context, id int32) error {
-	_, err := q.db.Exec(ctx, deleteOrderItem, id)
-	return err
+func (q *Queries) CreateOrderAddon(ctx context.Context, arg CreateOrderAddonParams) (*OrderAddon, error) {
+	row := q.db.QueryRow(ctx, createOrderAddon,
+		arg.OrderID,
+		arg.OrderItemID,
+		arg.Type,
+		arg.Description,
+		arg.Quantity,
+		arg.UnitPrice,
+		arg.Subtotal,
+		arg.TaxRate,
+		arg.TaxAmount,
+	)
+	var i OrderAddon
+	err := row.Scan(
+		&i.ID,
+		&i.OrderID,
+		&i.OrderItemID,
+		&i.Type,
+		&i.Description,
+		&i.Quantity,
+		&i.UnitPrice,
+		&i.Subtotal,
+		&i.TaxRate,
+		&i.TaxAmount,
+		&i.CreatedAt,
+	)
+	return &i, err
 }
 
-const getOrder = `-- name: GetOrder :one
-SELECT id, customer_id, cart_id, status, currency, subtotal, tax, discount, total, created_at, updated_at
-FROM orders
-WHERE id = $1
+const createOrderCancellationRequest = `-- name: CreateOrderCancellationRequest :one
+INSERT INTO order_cancellation_requests (order_id, customer_id, reason, status, created_at, updated_at)
+VALUES ($1, $2, $3, $4, NOW(), NOW())
+RETURNING id, order_id, customer_id, reason, status, reviewed_by, created_at, updated_at
 `
 
-type GetOrderRow struct {
-	ID         int32              `json:"id"`
-	CustomerID string             `json:"customerId"`
-	CartID     uint64             `json:"cartId"`
-	Status     OrderStatus        `json:"status"`
-	Currency   Currency           `json:"currency"`
-	Subtotal   float64            `json:"subtotal"`
-	Tax        float64            `json:"tax"`
-	Discount   float64            `json:"discount"`
-	Total      float64            `json:"total"`
-	CreatedAt  pgtype.Timestamptz `json:"createdAt"`
-	UpdatedAt  pgtype.Timestamptz `json:"updatedAt"`
+type CreateOrderCancellationRequestParams struct {
+	OrderID    int32                     `json:"orderId"`
+	CustomerID string                    `json:"customerId"`
+	Reason     CancellationReason        `json:"reason"`
+	Status     CancellationRequestStatus `json:"status"`
 }
 
-func (q *Queries) GetOrder(ctx context.Context, id int32) (*GetOrderRow, error) {
-	row := q.db.QueryRow(ctx, getOrder, id)
-	var i GetOrderRow
+func (q *Queries) CreateOrderCancellationRequest(ctx context.Context, arg CreateOrderCancellationRequestParams) (*OrderCancellationRequest, error) {
+	row := q.db.QueryRow(ctx, createOrderCancellationRequest,
+		arg.OrderID,
+		arg.CustomerID,
+		arg.Reason,
+		arg.Status,
+	)
+	var i OrderCancellationRequest
 	err := row.Scan(
 		&i.ID,
+		&i.OrderID,
 		&i.CustomerID,
-		&i.CartID,
+		&i.Reason,
 		&i.Status,
-		&i.Currency,
-		&i.Subtotal,
-		&i.Tax,
-		&i.Discount,
-		&i.Total,
+		&i.ReviewedBy,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
 	return &i, err
 }
 
-const getOrderByCustomerIDAndSubscriptionID = `-- name: GetOrderByCustomerIDAndSubscriptionID :one
-SELECT id, customer_id, cart_id, status, currency, subtotal, tax, discount, total, created_at, updated_at
-FROM orders
-WHERE subscription_id = $1 AND customer_id = $2
+const createOrderHold = `-- name: CreateOrderHold :one
+INSERT INTO order_holds (order_id, reason, previous_status, created_at)
+VALUES ($1, $2, $3, NOW())
+RETURNING id, order_id, reason, previous_status, created_at, released_at
 `
 
-type GetOrderByCustomerIDAndSubscriptionIDParams struct {
-	SubscriptionID *string `json:"subscriptionId"`
-	CustomerID     string  `json:"customerId"`
+type CreateOrderHoldParams struct {
+	OrderID        int32       `json:"orderId"`
+	Reason         string      `json:"reason"`
+	PreviousStatus OrderStatus `json:"previousStatus"`
 }
 
-type GetOrderByCustomerIDAndSubscriptionIDRow struct {
-	ID         int32              `json:"id"`
-	CustomerID string             `json:"customerId"`
-	CartID     uint64             `json:"cartId"`
-	Status     OrderStatus        `json:"status"`
-	Currency   Currency           `json:"currency"`
-	Subtotal   float64            `json:"subtotal"`
-	Tax        float64            `json:"tax"`
-	Discount   float64            `json:"discount"`
-	Total      float64            `json:"total"`
-	CreatedAt  pgtype.Timestamptz `json:"createdAt"`
-	UpdatedAt  pgtype.Timestamptz `json:"updatedAt"`
+func (q *Queries) CreateOrderHold(ctx context.Context, arg CreateOrderHoldParams) (*OrderHold, error) {
+	row := q.db.QueryRow(ctx, createOrderHold, arg.OrderID, arg.Reason, arg.PreviousStatus)
+	var i OrderHold
+	err := row.Scan(
+		&i.ID,
+		&i.OrderID,
+		&i.Reason,
+		&i.PreviousStatus,
+		&i.CreatedAt,
+		&i.ReleasedAt,
+	)
+	return &i, err
 }
 
-func (q *Queries) GetOrderByCustomerIDAndSubscriptionID(ctx context.Context, arg GetOrderByCustomerIDAndSubscriptionIDParams) (*GetOrderByCustomerIDAndSubscriptionIDRow, error) {
-	row := q.db.QueryRow(ctx, getOrderByCustomerIDAndSubscriptionID, arg.SubscriptionID, arg.CustomerID)
-	var i GetOrderByCustomerIDAndSubscriptionIDRow
+const createOrderRepricing = `-- name: CreateOrderRepricing :one
+INSERT INTO order_repricings (order_id, price_change_id, price_id, new_unit_price, previous_total, new_total, status, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+RETURNING id, order_id, price_change_id, price_id, new_unit_price, previous_total, new_total, status, reviewed_by, created_at, updated_at
+`
+
+type CreateOrderRepricingParams struct {
+	OrderID       int32                `json:"orderId"`
+	PriceChangeID int32                `json:"priceChangeId"`
+	PriceID       string               `json:"priceId"`
+	NewUnitPrice  float64              `json:"newUnitPrice"`
+	PreviousTotal float64              `json:"previousTotal"`
+	NewTotal      float64              `json:"newTotal"`
+	Status        OrderRepricingStatus `json:"status"`
+}
+
+func (q *Queries) CreateOrderRepricing(ctx context.Context, arg CreateOrderRepricingParams) (*OrderRepricing, error) {
+	row := q.db.QueryRow(ctx, createOrderRepricing,
+		arg.OrderID,
+		arg.PriceChangeID,
+		arg.PriceID,
+		arg.NewUnitPrice,
+		arg.PreviousTotal,
+		arg.NewTotal,
+		arg.Status,
+	)
+	var i OrderRepricing
 	err := row.Scan(
 		&i.ID,
-		&i.CustomerID,
-		&i.CartID,
+		&i.OrderID,
+		&i.PriceChangeID,
+		&i.PriceID,
+		&i.NewUnitPrice,
+		&i.PreviousTotal,
+		&i.NewTotal,
 		&i.Status,
-		&i.Currency,
-		&i.Subtotal,
-		&i.Tax,
-		&i.Discount,
-		&i.Total,
+		&i.ReviewedBy,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
 	return &i, err
 }
 
-const getOrderByInvoiceID = `-- name: GetOrderByInvoiceID :one
-SELECT id, customer_id, cart_id, status, currency, subtotal, tax, discount, total, created_at, updated_at
-FROM orders
-WHERE invoice_id = $1
+const createOrderReturn = `-- name: CreateOrderReturn :one
+INSERT INTO order_returns (order_id, reason, created_at, updated_at)
+VALUES ($1, $2, NOW(), NOW())
+RETURNING id, order_id, status, reason, carrier, tracking_number, label_url, created_at, updated_at
 `
 
-type GetOrderByInvoiceIDRow struct {
-	ID         int32              `json:"id"`
-	CustomerID string             `json:"customerId"`
-	CartID     uint64             `json:"cartId"`
-	Status     OrderStatus        `json:"status"`
-	Currency   Currency           `json:"currency"`
-	Subtotal   float64            `json:"subtotal"`
-	Tax        float64            `json:"tax"`
-	Discount   float64            `json:"discount"`
-	Total      float64            `json:"total"`
-	CreatedAt  pgtype.Timestamptz `json:"createdAt"`
-	UpdatedAt  pgtype.Timestamptz `json:"updatedAt"`
+type CreateOrderReturnParams struct {
+	OrderID int32  `json:"orderId"`
+	Reason  string `json:"reason"`
 }
 
-func (q *Queries) GetOrderByInvoiceID(ctx context.Context, invoiceID *string) (*GetOrderByInvoiceIDRow, error) {
-	row := q.db.QueryRow(ctx, getOrderByInvoiceID, invoiceID)
-	var i GetOrderByInvoiceIDRow
+func (q *Queries) CreateOrderReturn(ctx context.Context, arg CreateOrderReturnParams) (*OrderReturn, error) {
+	row := q.db.QueryRow(ctx, createOrderReturn, arg.OrderID, arg.Reason)
+	var i OrderReturn
 	err := row.Scan(
 		&i.ID,
-		&i.CustomerID,
-		&i.CartID,
+		&i.OrderID,
 		&i.Status,
-		&i.Currency,
-		&i.Subtotal,
-		&i.Tax,
-		&i.Discount,
-		&i.Total,
+		&i.Reason,
+		&i.Carrier,
+		&i.TrackingNumber,
+		&i.LabelUrl,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
 	return &i, err
 }
 
-const getOrderByPaymentIntentID = `-- name: GetOrderByPaymentIntentID :one
-SELECT id, customer_id, cart_id, status, currency, subtotal, tax, discount, total, created_at, updated_at
-FROM orders
-WHERE payment_intent_id = $1
+const createRefund = `-- name: CreateRefund :one
+INSERT INTO refunds (order_id, stripe_refund_id, currency, amount, restocking_fee, reason, policy_version, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+RETURNING id, order_id, stripe_refund_id, currency, amount, restocking_fee, reason, policy_version, created_at
 `
 
-type GetOrderByPaymentIntentIDRow struct {
-	ID         int32              `json:"id"`
-	CustomerID string             `json:"customerId"`
-	CartID     uint64             `json:"cartId"`
-	Status     OrderStatus        `json:"status"`
-	Currency   Currency           `json:"currency"`
-	Subtotal   float64            `json:"subtotal"`
-	Tax        float64            `json:"tax"`
-	Discount   float64            `json:"discount"`
-	Total      float64            `json:"total"`
-	CreatedAt  pgtype.Timestamptz `json:"createdAt"`
-	UpdatedAt  pgtype.Timestamptz `json:"updatedAt"`
+type CreateRefundParams struct {
+	OrderID        int32    `json:"orderId"`
+	StripeRefundID *string  `json:"stripeRefundId"`
+	Currency       Currency `json:"currency"`
+	Amount         float64  `json:"amount"`
+	RestockingFee  float64  `json:"restockingFee"`
+	Reason         *string  `json:"reason"`
+	PolicyVersion  string   `json:"policyVersion"`
 }
 
-func (q *Queries) GetOrderByPaymentIntentID(ctx context.Context, paymentIntentID *string) (*GetOrderByPaymentIntentIDRow, error) {
-	row := q.db.QueryRow(ctx, getOrderByPaymentIntentID, paymentIntentID)
-	var i GetOrderByPaymentIntentIDRow
+func (q *Queries) CreateRefund(ctx context.Context, arg CreateRefundParams) (*Refund, error) {
+	row := q.db.QueryRow(ctx, createRefund,
+		arg.OrderID,
+		arg.StripeRefundID,
+		arg.Currency,
+		arg.Amount,
+		arg.RestockingFee,
+		arg.Reason,
+		arg.PolicyVersion,
+	)
+	var i Refund
 	err := row.Scan(
 		&i.ID,
-		&i.CustomerID,
-		&i.CartID,
-		&i.Status,
+		&i.OrderID,
+		&i.StripeRefundID,
 		&i.Currency,
-		&i.Subtotal,
-		&i.Tax,
-		&i.Discount,
-		&i.Total,
+		&i.Amount,
+		&i.RestockingFee,
+		&i.Reason,
+		&i.PolicyVersion,
 		&i.CreatedAt,
-		&i.UpdatedAt,
 	)
 	return &i, err
 }
 
-const getOrderByRefundID = `-- name: GetOrderByRefundID :one
-SELECT id, customer_id, cart_id, status, currency, subtotal, tax, discount, total, created_at, updated_at
-FROM orders
-WHERE refund_id = $1
+const createShipment = `-- name: CreateShipment :one
+INSERT INTO shipments (order_id, location, created_at)
+VALUES ($1, $2, NOW())
+ON CONFLICT (order_id, location) DO UPDATE SET location = EXCLUDED.location
+RETURNING id, order_id, location, created_at
 `
 
-type GetOrderByRefundIDRow struct {
-	ID         int32              `json:"id"`
-	CustomerID string             `json:"customerId"`
-	CartID     uint64             `json:"cartId"`
-	Status     OrderStatus        `json:"status"`
-	Currency   Currency           `json:"currency"`
-	Subtotal   float64            `json:"subtotal"`
-	Tax        float64            `json:"tax"`
-	Discount   float64            `json:"discount"`
-	Total      float64            `json:"total"`
-	CreatedAt  pgtype.Timestamptz `json:"createdAt"`
-	UpdatedAt  pgtype.Timestamptz `json:"updatedAt"`
+type CreateShipmentParams struct {
+	OrderID  int32  `json:"orderId"`
+	Location string `json:"location"`
 }
 
-func (q *Queries) GetOrderByRefundID(ctx context.Context, refundID *string) (*GetOrderByRefundIDRow, error) {
-	row := q.db.QueryRow(ctx, getOrderByRefundID, refundID)
-	var i GetOrderByRefundIDRow
+func (q *Queries) CreateShipment(ctx context.Context, arg CreateShipmentParams) (*Shipment, error) {
+	row := q.db.QueryRow(ctx, createShipment, arg.OrderID, arg.Location)
+	var i Shipment
+	err := row.Scan(
+		&i.ID,
+		&i.OrderID,
+		&i.Location,
+		&i.CreatedAt,
+	)
+	return &i, err
+}
+
+const createTaxExemptionCertificate = `-- name: CreateTaxExemptionCertificate :one
+INSERT INTO tax_exemption_certificates (customer_id, certificate_number, jurisdiction, tax_classes, valid_from, valid_until)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, customer_id, certificate_number, jurisdiction, tax_classes, valid_from, valid_until, revoked_at, created_at, updated_at
+`
+
+type CreateTaxExemptionCertificateParams struct {
+	CustomerID        string             `json:"customerId"`
+	CertificateNumber string             `json:"certificateNumber"`
+	Jurisdiction      string             `json:"jurisdiction"`
+	TaxClasses        []string           `json:"taxClasses"`
+	ValidFrom         pgtype.Timestamptz `json:"validFrom"`
+	ValidUntil        pgtype.Timestamptz `json:"validUntil"`
+}
+
+func (q *Queries) CreateTaxExemptionCertificate(ctx context.Context, arg CreateTaxExemptionCertificateParams) (*TaxExemptionCertificate, error) {
+	row := q.db.QueryRow(ctx, createTaxExemptionCertificate,
+		arg.CustomerID,
+		arg.CertificateNumber,
+		arg.Jurisdiction,
+		arg.TaxClasses,
+		arg.ValidFrom,
+		arg.ValidUntil,
+	)
+	var i TaxExemptionCertificate
 	err := row.Scan(
 		&i.ID,
 		&i.CustomerID,
-		&i.CartID,
-		&i.Status,
-		&i.Currency,
-		&i.Subtotal,
-		&i.Tax,
-		&i.Discount,
-		&i.Total,
+		&i.CertificateNumber,
+		&i.Jurisdiction,
+		&i.TaxClasses,
+		&i.ValidFrom,
+		&i.ValidUntil,
+		&i.RevokedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
 	return &i, err
 }
 
-const getOrderItem = `-- name: GetOrderItem :one
-SELECT id, order_id, product_id, price_id, stock_id, quantity, unit_price, subtotal
-FROM order_items
-WHERE id = $1
+const deleteOrderAddon = `-- name: DeleteOrderAddon :one
+DELETE FROM order_addons
+WHERE id = $1 AND order_id = $2
+RETURNING id, order_id, order_item_id, type, description, quantity, unit_price, subtotal, tax_rate, tax_amount, created_at
 `
 
-type GetOrderItemRow struct {
-	ID        int32   `json:"id"`
-	OrderID   int32   `json:"orderId"`
-	ProductID string  `json:"productId"`
-	PriceID   string  `json:"priceId"`
-	StockID   uint64  `json:"stockId"`
-	Quantity  uint64  `json:"quantity"`
-	UnitPrice float64 `json:"unitPrice"`
-	Subtotal  float64 `json:"subtotal"`
+type DeleteOrderAddonParams struct {
+	ID      int32 `json:"id"`
+	OrderID int32 `json:"orderId"`
 }
 
-func (q *Queries) GetOrderItem(ctx context.Context, id int32) (*GetOrderItemRow, error) {
-	row := q.db.QueryRow(ctx, getOrderItem, id)
-	var i GetOrderItemRow
+func (q *Queries) DeleteOrderAddon(ctx context.Context, arg DeleteOrderAddonParams) (*OrderAddon, error) {
+	row := q.db.QueryRow(ctx, deleteOrderAddon, arg.ID, arg.OrderID)
+	var i OrderAddon
 	err := row.Scan(
 		&i.ID,
 		&i.OrderID,
-		&i.ProductID,
-		&i.PriceID,
-		&i.StockID,
+		&i.OrderItemID,
+		&i.Type,
+		&i.Description,
 		&i.Quantity,
 		&i.UnitPrice,
 		&i.Subtotal,
+		&i.TaxRate,
+		&i.TaxAmount,
+		&i.CreatedAt,
 	)
 	return &i, err
 }
 
-const listOrderItems = `-- name: ListOrderItems :many
-SELECT id, order_id, product_id, price_id, stock_id, quantity, unit_price, subtotal
-FROM order_items
-WHERE order_id = $1
+const deleteOrderItem = `-- name: DeleteOrderItem :exec
+DELETE FROM order_items WHERE id = $1
 `
 
-type ListOrderItemsRow struct {
-	ID        int32   `json:"id"`
-	OrderID   int32   `json:"orderId"`
-	ProductID string  `json:"productId"`
-	PriceID   string  `json:"priceId"`
-	StockID   uint64  `json:"stockId"`
-	Quantity  uint64  `json:"quantity"`
-	UnitPrice float64 `json:"unitPrice"`
-	Subtotal  float64 `json:"subtotal"`
+func (q *Queries) DeleteOrderItem(ctx context.Context, id int32) error {
+	_, err := q.db.Exec(ctx, deleteOrderItem, id)
+	return err
 }
 
-func (q *Queries) ListOrderItems(ctx context.Context, orderID int32) ([]*ListOrderItemsRow, error) {
-	rows, err := q.db.Query(ctx, listOrderItems, orderID)
+const findOrdersByMetadata = `-- name: FindOrdersByMetadata :many
+SELECT id, order_number, customer_id, cart_id, status, currency, subtotal, tax, discount, total, created_at, updated_at, fulfillment_type, pickup_location, metadata
+FROM orders
+WHERE metadata @> $1::jsonb
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3
+`
+
+type FindOrdersByMetadataParams struct {
+	Metadata []byte `json:"metadata"`
+	Limit    int64  `json:"limit"`
+	Offset   int64  `json:"offset"`
+}
+
+type FindOrdersByMetadataRow struct {
+	ID              int32              `json:"id"`
+	OrderNumber     string             `json:"orderNumber"`
+	CustomerID      string             `json:"customerId"`
+	CartID          uint64             `json:"cartId"`
+	Status          OrderStatus        `json:"status"`
+	Currency        Currency           `json:"currency"`
+	Subtotal        float64            `json:"subtotal"`
+	Tax             float64            `json:"tax"`
+	Discount        float64            `json:"discount"`
+	Total           float64            `json:"total"`
+	CreatedAt       pgtype.Timestamptz `json:"createdAt"`
+	UpdatedAt       pgtype.Timestamptz `json:"updatedAt"`
+	FulfillmentType FulfillmentType    `json:"fulfillmentType"`
+	PickupLocation  *string            `json:"pickupLocation"`
+	Metadata        []byte             `json:"metadata"`
+}
+
+func (q *Queries) FindOrdersByMetadata(ctx context.Context, arg FindOrdersByMetadataParams) ([]*FindOrdersByMetadataRow, error) {
+	rows, err := q.db.Query(ctx, findOrdersByMetadata, arg.Metadata, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*FindOrdersByMetadataRow{}
+	for rows.Next() {
+		var i FindOrdersByMetadataRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrderNumber,
+			&i.CustomerID,
+			&i.CartID,
+			&i.Status,
+			&i.Currency,
+			&i.Subtotal,
+			&i.Tax,
+			&i.Discount,
+			&i.Total,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.FulfillmentType,
+			&i.PickupLocation,
+			&i.Metadata,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getActiveOrderHold = `-- name: GetActiveOrderHold :one
+SELECT id, order_id, reason, previous_status, created_at, released_at
+FROM order_holds
+WHERE order_id = $1 AND released_at IS NULL
+`
+
+func (q *Queries) GetActiveOrderHold(ctx context.Context, orderID int32) (*OrderHold, error) {
+	row := q.db.QueryRow(ctx, getActiveOrderHold, orderID)
+	var i OrderHold
+	err := row.Scan(
+		&i.ID,
+		&i.OrderID,
+		&i.Reason,
+		&i.PreviousStatus,
+		&i.CreatedAt,
+		&i.ReleasedAt,
+	)
+	return &i, err
+}
+
+const getActiveTaxExemptionCertificate = `-- name: GetActiveTaxExemptionCertificate :one
+SELECT id, customer_id, certificate_number, jurisdiction, tax_classes, valid_from, valid_until, revoked_at, created_at, updated_at
+FROM tax_exemption_certificates
+WHERE customer_id = $1
+  AND jurisdiction = $2
+  AND revoked_at IS NULL
+  AND valid_from <= $3::timestamptz
+  AND (valid_until IS NULL OR valid_until > $3::timestamptz)
+ORDER BY valid_from DESC, id DESC
+LIMIT 1
+`
+
+type GetActiveTaxExemptionCertificateParams struct {
+	CustomerID   string             `json:"customerId"`
+	Jurisdiction string             `json:"jurisdiction"`
+	At           pgtype.Timestamptz `json:"at"`
+}
+
+func (q *Queries) GetActiveTaxExemptionCertificate(ctx context.Context, arg GetActiveTaxExemptionCertificateParams) (*TaxExemptionCertificate, error) {
+	row := q.db.QueryRow(ctx, getActiveTaxExemptionCertificate, arg.CustomerID, arg.Jurisdiction, arg.At)
+	var i TaxExemptionCertificate
+	err := row.Scan(
+		&i.ID,
+		&i.CustomerID,
+		&i.CertificateNumber,
+		&i.Jurisdiction,
+		&i.TaxClasses,
+		&i.ValidFrom,
+		&i.ValidUntil,
+		&i.RevokedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return &i, err
+}
+
+const getArchivedOrder = `-- name: GetArchivedOrder :one
+SELECT id, order_number, customer_id, cart_id, status, currency, subtotal, tax, discount, total, shipping_address, billing_address, created_at, updated_at, fulfillment_type, pickup_location, metadata, reporting_currency, exchange_rate, reporting_subtotal, reporting_tax, reporting_discount, reporting_total, estimated_delivery_earliest, estimated_delivery_latest, external_source, external_order_id, applied_promotions, archived_at, channel, cart_snapshot
+FROM orders_archive
+WHERE id = $1
+`
+
+type GetArchivedOrderRow struct {
+	ID                        int32              `json:"id"`
+	OrderNumber               string             `json:"orderNumber"`
+	CustomerID                string             `json:"customerId"`
+	CartID                    uint64             `json:"cartId"`
+	Status                    OrderStatus        `json:"status"`
+	Currency                  Currency           `json:"currency"`
+	Subtotal                  float64            `json:"subtotal"`
+	Tax                       float64            `json:"tax"`
+	Discount                  float64            `json:"discount"`
+	Total                     float64            `json:"total"`
+	ShippingAddress           []byte             `json:"shippingAddress"`
+	BillingAddress            []byte             `json:"billingAddress"`
+	CreatedAt                 pgtype.Timestamptz `json:"createdAt"`
+	UpdatedAt                 pgtype.Timestamptz `json:"updatedAt"`
+	FulfillmentType           FulfillmentType    `json:"fulfillmentType"`
+	PickupLocation            *string            `json:"pickupLocation"`
+	Metadata                  []byte             `json:"metadata"`
+	ReportingCurrency         NullCurrency       `json:"reportingCurrency"`
+	ExchangeRate              *float64           `json:"exchangeRate"`
+	ReportingSubtotal         *float64           `json:"reportingSubtotal"`
+	ReportingTax              *float64           `json:"reportingTax"`
+	ReportingDiscount         *float64           `json:"reportingDiscount"`
+	ReportingTotal            *float64           `json:"reportingTotal"`
+	EstimatedDeliveryEarliest pgtype.Date        `json:"estimatedDeliveryEarliest"`
+	EstimatedDeliveryLatest   pgtype.Date        `json:"estimatedDeliveryLatest"`
+	ExternalSource            *string            `json:"externalSource"`
+	ExternalOrderID           *string            `json:"externalOrderId"`
+	AppliedPromotions         []byte             `json:"appliedPromotions"`
+	ArchivedAt                pgtype.Timestamptz `json:"archivedAt"`
+	Channel                   *string            `json:"channel"`
+	CartSnapshot              []byte             `json:"cartSnapshot"`
+}
+
+func (q *Queries) GetArchivedOrder(ctx context.Context, id int32) (*GetArchivedOrderRow, error) {
+	row := q.db.QueryRow(ctx, getArchivedOrder, id)
+	var i GetArchivedOrderRow
+	err := row.Scan(
+		&i.ID,
+		&i.OrderNumber,
+		&i.CustomerID,
+		&i.CartID,
+		&i.Status,
+		&i.Currency,
+		&i.Subtotal,
+		&i.Tax,
+		&i.Discount,
+		&i.Total,
+		&i.ShippingAddress,
+		&i.BillingAddress,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.FulfillmentType,
+		&i.PickupLocation,
+		&i.Metadata,
+		&i.ReportingCurrency,
+		&i.ExchangeRate,
+		&i.ReportingSubtotal,
+		&i.ReportingTax,
+		&i.ReportingDiscount,
+		&i.ReportingTotal,
+		&i.EstimatedDeliveryEarliest,
+		&i.EstimatedDeliveryLatest,
+		&i.ExternalSource,
+		&i.ExternalOrderID,
+		&i.AppliedPromotions,
+		&i.ArchivedAt,
+		&i.Channel,
+		&i.CartSnapshot,
+	)
+	return &i, err
+}
+
+const getCancellationReasonStats = `-- name: GetCancellationReasonStats :many
+SELECT reason,
+       COUNT(*)::bigint AS requests,
+       COUNT(*) FILTER (WHERE status IN ('auto_cancelled', 'approved'))::bigint AS cancelled,
+       COUNT(*) FILTER (WHERE status = 'pending_review')::bigint AS pending,
+       COUNT(*) FILTER (WHERE status = 'rejected')::bigint AS rejected
+FROM order_cancellation_requests
+WHERE created_at >= $1 AND created_at < $2
+GROUP BY reason
+ORDER BY requests DESC, reason
+`
+
+type GetCancellationReasonStatsParams struct {
+	RangeStart pgtype.Timestamptz `json:"rangeStart"`
+	RangeEnd   pgtype.Timestamptz `json:"rangeEnd"`
+}
+
+type GetCancellationReasonStatsRow struct {
+	Reason    CancellationReason `json:"reason"`
+	Requests  int64              `json:"requests"`
+	Cancelled int64              `json:"cancelled"`
+	Pending   int64              `json:"pending"`
+	Rejected  int64              `json:"rejected"`
+}
+
+func (q *Queries) GetCancellationReasonStats(ctx context.Context, arg GetCancellationReasonStatsParams) ([]*GetCancellationReasonStatsRow, error) {
+	rows, err := q.db.Query(ctx, getCancellationReasonStats, arg.RangeStart, arg.RangeEnd)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*GetCancellationReasonStatsRow{}
+	for rows.Next() {
+		var i GetCancellationReasonStatsRow
+		if err := rows.Scan(
+			&i.Reason,
+			&i.Requests,
+			&i.Cancelled,
+			&i.Pending,
+			&i.Rejected,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getChannelSalesReport = `-- name: GetChannelSalesReport :many
+SELECT COALESCE(o.channel, '')::text AS channel, o.currency,
+       COUNT(*)::bigint AS orders,
+       COALESCE(SUM(o.total), 0)::float8 AS total
+FROM (
+    SELECT status, currency, total, channel, created_at FROM orders
+    UNION ALL
+    SELECT status, currency, total, channel, created_at FROM orders_archive
+) o
+WHERE o.created_at >= $1 AND o.created_at < $2 AND o.status NOT IN ('cancelled', 'failed', 'refunded')
+GROUP BY o.channel, o.currency
+ORDER BY o.channel NULLS FIRST, o.currency
+`
+
+type GetChannelSalesReportParams struct {
+	RangeStart pgtype.Timestamptz `json:"rangeStart"`
+	RangeEnd   pgtype.Timestamptz `json:"rangeEnd"`
+}
+
+type GetChannelSalesReportRow struct {
+	Channel  string   `json:"channel"`
+	Currency Currency `json:"currency"`
+	Orders   int64    `json:"orders"`
+	Total    float64  `json:"total"`
+}
+
+func (q *Queries) GetChannelSalesReport(ctx context.Context, arg GetChannelSalesReportParams) ([]*GetChannelSalesReportRow, error) {
+	rows, err := q.db.Query(ctx, getChannelSalesReport, arg.RangeStart, arg.RangeEnd)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*GetChannelSalesReportRow{}
+	for rows.Next() {
+		var i GetChannelSalesReportRow
+		if err := rows.Scan(
+			&i.Channel,
+			&i.Currency,
+			&i.Orders,
+			&i.Total,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFraudReview = `-- name: GetFraudReview :one
+SELECT id, order_id, reason, detail, status, created_at, resolved_at, resolved_by, resolution_note
+FROM fraud_reviews
+WHERE id = $1
+`
+
+func (q *Queries) GetFraudReview(ctx context.Context, id int32) (*FraudReview, error) {
+	row := q.db.QueryRow(ctx, getFraudReview, id)
+	var i FraudReview
+	err := row.Scan(
+		&i.ID,
+		&i.OrderID,
+		&i.Reason,
+		&i.Detail,
+		&i.Status,
+		&i.CreatedAt,
+		&i.ResolvedAt,
+		&i.ResolvedBy,
+		&i.ResolutionNote,
+	)
+	return &i, err
+}
+
+const getInvoice = `-- name: GetInvoice :one
+SELECT id, tenant, country, type, sequence_number, invoice_number, order_id, refund_id, original_invoice_id, currency, subtotal, tax, total, document_url, issued_at, tax_exemption_number
+FROM invoices
+WHERE id = $1
+`
+
+func (q *Queries) GetInvoice(ctx context.Context, id int32) (*Invoice, error) {
+	row := q.db.QueryRow(ctx, getInvoice, id)
+	var i Invoice
+	err := row.Scan(
+		&i.ID,
+		&i.Tenant,
+		&i.Country,
+		&i.Type,
+		&i.SequenceNumber,
+		&i.InvoiceNumber,
+		&i.OrderID,
+		&i.RefundID,
+		&i.OriginalInvoiceID,
+		&i.Currency,
+		&i.Subtotal,
+		&i.Tax,
+		&i.Total,
+		&i.DocumentUrl,
+		&i.IssuedAt,
+		&i.TaxExemptionNumber,
+	)
+	return &i, err
+}
+
+const getOrder = `-- name: GetOrder :one
+SELECT id, order_number, customer_id, cart_id, status, currency, subtotal, tax, discount, total, shipping_address, billing_address, created_at, updated_at, fulfillment_type, pickup_location, metadata, reporting_currency, exchange_rate, reporting_subtotal, reporting_tax, reporting_discount, reporting_total, estimated_delivery_earliest, estimated_delivery_latest, external_source, external_order_id, applied_promotions, deleted_at, channel, cart_snapshot
+FROM orders
+WHERE id = $1
+`
+
+type GetOrderRow struct {
+	ID                        int32              `json:"id"`
+	OrderNumber               string             `json:"orderNumber"`
+	CustomerID                string             `json:"customerId"`
+	CartID                    uint64             `json:"cartId"`
+	Status                    OrderStatus        `json:"status"`
+	Currency                  Currency           `json:"currency"`
+	Subtotal                  float64            `json:"subtotal"`
+	Tax                       float64            `json:"tax"`
+	Discount                  float64            `json:"discount"`
+	Total                     float64            `json:"total"`
+	ShippingAddress           []byte             `json:"shippingAddress"`
+	BillingAddress            []byte             `json:"billingAddress"`
+	CreatedAt                 pgtype.Timestamptz `json:"createdAt"`
+	UpdatedAt                 pgtype.Timestamptz `json:"updatedAt"`
+	FulfillmentType           FulfillmentType    `json:"fulfillmentType"`
+	PickupLocation            *string            `json:"pickupLocation"`
+	Metadata                  []byte             `json:"metadata"`
+	ReportingCurrency         NullCurrency       `json:"reportingCurrency"`
+	ExchangeRate              *float64           `json:"exchangeRate"`
+	ReportingSubtotal         *float64           `json:"reportingSubtotal"`
+	ReportingTax              *float64           `json:"reportingTax"`
+	ReportingDiscount         *float64           `json:"reportingDiscount"`
+	ReportingTotal            *float64           `json:"reportingTotal"`
+	EstimatedDeliveryEarliest pgtype.Date        `json:"estimatedDeliveryEarliest"`
+	EstimatedDeliveryLatest   pgtype.Date        `json:"estimatedDeliveryLatest"`
+	ExternalSource            *string            `json:"externalSource"`
+	ExternalOrderID           *string            `json:"externalOrderId"`
+	AppliedPromotions         []byte             `json:"appliedPromotions"`
+	DeletedAt                 pgtype.Timestamptz `json:"deletedAt"`
+	Channel                   *string            `json:"channel"`
+	CartSnapshot              []byte             `json:"cartSnapshot"`
+}
+
+func (q *Queries) GetOrder(ctx context.Context, id int32) (*GetOrderRow, error) {
+	row := q.db.QueryRow(ctx, getOrder, id)
+	var i GetOrderRow
+	err := row.Scan(
+		&i.ID,
+		&i.OrderNumber,
+		&i.CustomerID,
+		&i.CartID,
+		&i.Status,
+		&i.Currency,
+		&i.Subtotal,
+		&i.Tax,
+		&i.Discount,
+		&i.Total,
+		&i.ShippingAddress,
+		&i.BillingAddress,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.FulfillmentType,
+		&i.PickupLocation,
+		&i.Metadata,
+		&i.ReportingCurrency,
+		&i.ExchangeRate,
+		&i.ReportingSubtotal,
+		&i.ReportingTax,
+		&i.ReportingDiscount,
+		&i.ReportingTotal,
+		&i.EstimatedDeliveryEarliest,
+		&i.EstimatedDeliveryLatest,
+		&i.ExternalSource,
+		&i.ExternalOrderID,
+		&i.AppliedPromotions,
+		&i.DeletedAt,
+		&i.Channel,
+		&i.CartSnapshot,
+	)
+	return &i, err
+}
+
+const getOrderByCustomerIDAndSubscriptionID = `-- name: GetOrderByCustomerIDAndSubscriptionID :one
+SELECT id, order_number, customer_id, cart_id, status, currency, subtotal, tax, discount, total, created_at, updated_at
+FROM orders
+WHERE subscription_id = $1 AND customer_id = $2
+`
+
+type GetOrderByCustomerIDAndSubscriptionIDParams struct {
+	SubscriptionID *string `json:"subscriptionId"`
+	CustomerID     string  `json:"customerId"`
+}
+
+type GetOrderByCustomerIDAndSubscriptionIDRow struct {
+	ID          int32              `json:"id"`
+	OrderNumber string             `json:"orderNumber"`
+	CustomerID  string             `json:"customerId"`
+	CartID      uint64             `json:"cartId"`
+	Status      OrderStatus        `json:"status"`
+	Currency    Currency           `json:"currency"`
+	Subtotal    float64            `json:"subtotal"`
+	Tax         float64            `json:"tax"`
+	Discount    float64            `json:"discount"`
+	Total       float64            `json:"total"`
+	CreatedAt   pgtype.Timestamptz `json:"createdAt"`
+	UpdatedAt   pgtype.Timestamptz `json:"updatedAt"`
+}
+
+func (q *Queries) GetOrderByCustomerIDAndSubscriptionID(ctx context.Context, arg GetOrderByCustomerIDAndSubscriptionIDParams) (*GetOrderByCustomerIDAndSubscriptionIDRow, error) {
+	row := q.db.QueryRow(ctx, getOrderByCustomerIDAndSubscriptionID, arg.SubscriptionID, arg.CustomerID)
+	var i GetOrderByCustomerIDAndSubscriptionIDRow
+	err := row.Scan(
+		&i.ID,
+		&i.OrderNumber,
+		&i.CustomerID,
+		&i.CartID,
+		&i.Status,
+		&i.Currency,
+		&i.Subtotal,
+		&i.Tax,
+		&i.Discount,
+		&i.Total,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return &i, err
+}
+
+const getOrderByInvoiceID = `-- name: GetOrderByInvoiceID :one
+SELECT id, order_number, customer_id, cart_id, status, currency, subtotal, tax, discount, total, created_at, updated_at
+FROM orders
+WHERE invoice_id = $1
+`
+
+type GetOrderByInvoiceIDRow struct {
+	ID          int32              `json:"id"`
+	OrderNumber string             `json:"orderNumber"`
+	CustomerID  string             `json:"customerId"`
+	CartID      uint64             `json:"cartId"`
+	Status      OrderStatus        `json:"status"`
+	Currency    Currency           `json:"currency"`
+	Subtotal    float64            `json:"subtotal"`
+	Tax         float64            `json:"tax"`
+	Discount    float64            `json:"discount"`
+	Total       float64            `json:"total"`
+	CreatedAt   pgtype.Timestamptz `json:"createdAt"`
+	UpdatedAt   pgtype.Timestamptz `json:"updatedAt"`
+}
+
+func (q *Queries) GetOrderByInvoiceID(ctx context.Context, invoiceID *string) (*GetOrderByInvoiceIDRow, error) {
+	row := q.db.QueryRow(ctx, getOrderByInvoiceID, invoiceID)
+	var i GetOrderByInvoiceIDRow
+	err := row.Scan(
+		&i.ID,
+		&i.OrderNumber,
+		&i.CustomerID,
+		&i.CartID,
+		&i.Status,
+		&i.Currency,
+		&i.Subtotal,
+		&i.Tax,
+		&i.Discount,
+		&i.Total,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return &i, err
+}
+
+const getOrderByPaymentIntentID = `-- name: GetOrderByPaymentIntentID :one
+SELECT id, order_number, customer_id, cart_id, status, currency, subtotal, tax, discount, total, created_at, updated_at
+FROM orders
+WHERE payment_intent_id = $1
+`
+
+type GetOrderByPaymentIntentIDRow struct {
+	ID          int32              `json:"id"`
+	OrderNumber string             `json:"orderNumber"`
+	CustomerID  string             `json:"customerId"`
+	CartID      uint64             `json:"cartId"`
+	Status      OrderStatus        `json:"status"`
+	Currency    Currency           `json:"currency"`
+	Subtotal    float64            `json:"subtotal"`
+	Tax         float64            `json:"tax"`
+	Discount    float64            `json:"discount"`
+	Total       float64            `json:"total"`
+	CreatedAt   pgtype.Timestamptz `json:"createdAt"`
+	UpdatedAt   pgtype.Timestamptz `json:"updatedAt"`
+}
+
+func (q *Queries) GetOrderByPaymentIntentID(ctx context.Context, paymentIntentID *string) (*GetOrderByPaymentIntentIDRow, error) {
+	row := q.db.QueryRow(ctx, getOrderByPaymentIntentID, paymentIntentID)
+	var i GetOrderByPaymentIntentIDRow
+	err := row.Scan(
+		&i.ID,
+		&i.OrderNumber,
+		&i.CustomerID,
+		&i.CartID,
+		&i.Status,
+		&i.Currency,
+		&i.Subtotal,
+		&i.Tax,
+		&i.Discount,
+		&i.Total,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return &i, err
+}
+
+const getOrderByRefundID = `-- name: GetOrderByRefundID :one
+SELECT id, order_number, customer_id, cart_id, status, currency, subtotal, tax, discount, total, created_at, updated_at
+FROM orders
+WHERE refund_id = $1
+`
+
+type GetOrderByRefundIDRow struct {
+	ID          int32              `json:"id"`
+	OrderNumber string             `json:"orderNumber"`
+	CustomerID  string             `json:"customerId"`
+	CartID      uint64             `json:"cartId"`
+	Status      OrderStatus        `json:"status"`
+	Currency    Currency           `json:"currency"`
+	Subtotal    float64            `json:"subtotal"`
+	Tax         float64            `json:"tax"`
+	Discount    float64            `json:"discount"`
+	Total       float64            `json:"total"`
+	CreatedAt   pgtype.Timestamptz `json:"createdAt"`
+	UpdatedAt   pgtype.Timestamptz `json:"updatedAt"`
+}
+
+func (q *Queries) GetOrderByRefundID(ctx context.Context, refundID *string) (*GetOrderByRefundIDRow, error) {
+	row := q.db.QueryRow(ctx, getOrderByRefundID, refundID)
+	var i GetOrderByRefundIDRow
+	err := row.Scan(
+		&i.ID,
+		&i.OrderNumber,
+		&i.CustomerID,
+		&i.CartID,
+		&i.Status,
+		&i.Currency,
+		&i.Subtotal,
+		&i.Tax,
+		&i.Discount,
+		&i.Total,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return &i, err
+}
+
+const getOrderCancellationRequestForUpdate = `-- name: GetOrderCancellationRequestForUpdate :one
+SELECT id, order_id, customer_id, reason, status, reviewed_by, created_at, updated_at
+FROM order_cancellation_requests
+WHERE id = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetOrderCancellationRequestForUpdate(ctx context.Context, id int32) (*OrderCancellationRequest, error) {
+	row := q.db.QueryRow(ctx, getOrderCancellationRequestForUpdate, id)
+	var i OrderCancellationRequest
+	err := row.Scan(
+		&i.ID,
+		&i.OrderID,
+		&i.CustomerID,
+		&i.Reason,
+		&i.Status,
+		&i.ReviewedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return &i, err
+}
+
+const getOrderForUpdate = `-- name: GetOrderForUpdate :one
+SELECT id, customer_id, cart_id, status, currency, subtotal, tax, discount, total, payment_intent_id, invoice_id, subscription_id, refund_id, shipping_address, billing_address, created_at, updated_at, fulfillment_type, pickup_location, metadata, tax_calculation_id, tax_transaction_id, reporting_currency, exchange_rate, reporting_subtotal, reporting_tax, reporting_discount, reporting_total, order_number, estimated_delivery_earliest, estimated_delivery_latest, external_source, external_order_id, applied_promotions, deleted_at, channel, cart_snapshot
+FROM orders
+WHERE id = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetOrderForUpdate(ctx context.Context, id int32) (*Order, error) {
+	row := q.db.QueryRow(ctx, getOrderForUpdate, id)
+	var i Order
+	err := row.Scan(
+		&i.ID,
+		&i.CustomerID,
+		&i.CartID,
+		&i.Status,
+		&i.Currency,
+		&i.Subtotal,
+		&i.Tax,
+		&i.Discount,
+		&i.Total,
+		&i.PaymentIntentID,
+		&i.InvoiceID,
+		&i.SubscriptionID,
+		&i.RefundID,
+		&i.ShippingAddress,
+		&i.BillingAddress,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.FulfillmentType,
+		&i.PickupLocation,
+		&i.Metadata,
+		&i.TaxCalculationID,
+		&i.TaxTransactionID,
+		&i.ReportingCurrency,
+		&i.ExchangeRate,
+		&i.ReportingSubtotal,
+		&i.ReportingTax,
+		&i.ReportingDiscount,
+		&i.ReportingTotal,
+		&i.OrderNumber,
+		&i.EstimatedDeliveryEarliest,
+		&i.EstimatedDeliveryLatest,
+		&i.ExternalSource,
+		&i.ExternalOrderID,
+		&i.AppliedPromotions,
+		&i.DeletedAt,
+		&i.Channel,
+		&i.CartSnapshot,
+	)
+	return &i, err
+}
+
+const getOrderFulfillmentSLA = `-- name: GetOrderFulfillmentSLA :one
+SELECT order_id, paid_at, warn_at, due_at, fulfilled_at, at_risk_alerted_at, breach_alerted_at, created_at
+FROM order_fulfillment_slas
+WHERE order_id = $1
+`
+
+func (q *Queries) GetOrderFulfillmentSLA(ctx context.Context, orderID int32) (*OrderFulfillmentSla, error) {
+	row := q.db.QueryRow(ctx, getOrderFulfillmentSLA, orderID)
+	var i OrderFulfillmentSla
+	err := row.Scan(
+		&i.OrderID,
+		&i.PaidAt,
+		&i.WarnAt,
+		&i.DueAt,
+		&i.FulfilledAt,
+		&i.AtRiskAlertedAt,
+		&i.BreachAlertedAt,
+		&i.CreatedAt,
+	)
+	return &i, err
+}
+
+const getOrderIDByExternalID = `-- name: GetOrderIDByExternalID :one
+SELECT id FROM orders WHERE external_source = $1 AND external_order_id = $2
+UNION ALL
+SELECT id FROM orders_archive WHERE external_source = $1 AND external_order_id = $2
+LIMIT 1
+`
+
+type GetOrderIDByExternalIDParams struct {
+	ExternalSource  *string `json:"externalSource"`
+	ExternalOrderID *string `json:"externalOrderId"`
+}
+
+func (q *Queries) GetOrderIDByExternalID(ctx context.Context, arg GetOrderIDByExternalIDParams) (int32, error) {
+	row := q.db.QueryRow(ctx, getOrderIDByExternalID, arg.ExternalSource, arg.ExternalOrderID)
+	var id int32
+	err := row.Scan(&id)
+	return id, err
+}
+
+const getOrderIDByNumber = `-- name: GetOrderIDByNumber :one
+SELECT id FROM orders WHERE order_number = $1
+UNION ALL
+SELECT id FROM orders_archive WHERE order_number = $1
+LIMIT 1
+`
+
+func (q *Queries) GetOrderIDByNumber(ctx context.Context, orderNumber string) (int32, error) {
+	row := q.db.QueryRow(ctx, getOrderIDByNumber, orderNumber)
+	var id int32
+	err := row.Scan(&id)
+	return id, err
+}
+
+const getOrderIdempotencyKey = `-- name: GetOrderIdempotencyKey :one
+SELECT idempotency_key, request_hash, order_id, created_at
+FROM order_idempotency_keys
+WHERE idempotency_key = $1
+`
+
+func (q *Queries) GetOrderIdempotencyKey(ctx context.Context, idempotencyKey string) (*OrderIdempotencyKey, error) {
+	row := q.db.QueryRow(ctx, getOrderIdempotencyKey, idempotencyKey)
+	var i OrderIdempotencyKey
+	err := row.Scan(
+		&i.IdempotencyKey,
+		&i.RequestHash,
+		&i.OrderID,
+		&i.CreatedAt,
+	)
+	return &i, err
+}
+
+const getOrderInvoice = `-- name: GetOrderInvoice :one
+SELECT id, tenant, country, type, sequence_number, invoice_number, order_id, refund_id, original_invoice_id, currency, subtotal, tax, total, document_url, issued_at, tax_exemption_number
+FROM invoices
+WHERE order_id = $1 AND type = 'invoice'
+`
+
+func (q *Queries) GetOrderInvoice(ctx context.Context, orderID int32) (*Invoice, error) {
+	row := q.db.QueryRow(ctx, getOrderInvoice, orderID)
+	var i Invoice
+	err := row.Scan(
+		&i.ID,
+		&i.Tenant,
+		&i.Country,
+		&i.Type,
+		&i.SequenceNumber,
+		&i.InvoiceNumber,
+		&i.OrderID,
+		&i.RefundID,
+		&i.OriginalInvoiceID,
+		&i.Currency,
+		&i.Subtotal,
+		&i.Tax,
+		&i.Total,
+		&i.DocumentUrl,
+		&i.IssuedAt,
+		&i.TaxExemptionNumber,
+	)
+	return &i, err
+}
+
+const getOrderItem = `-- name: GetOrderItem :one
+SELECT id, order_id, product_id, price_id, stock_id, quantity, unit_price, subtotal, location, product_name, sku, image_url, tax_class, tax_rate, tax_amount, weight_grams, length_mm, width_mm, height_mm, hs_code, origin_country, customization, shipped_quantity, returned_quantity, note, metadata
+FROM order_items
+WHERE id = $1
+`
+
+type GetOrderItemRow struct {
+	ID               int32   `json:"id"`
+	OrderID          int32   `json:"orderId"`
+	ProductID        string  `json:"productId"`
+	PriceID          string  `json:"priceId"`
+	StockID          uint64  `json:"stockId"`
+	Quantity         uint64  `json:"quantity"`
+	UnitPrice        float64 `json:"unitPrice"`
+	Subtotal         float64 `json:"subtotal"`
+	Location         *string `json:"location"`
+	ProductName      *string `json:"productName"`
+	Sku              *string `json:"sku"`
+	ImageUrl         *string `json:"imageUrl"`
+	TaxClass         *string `json:"taxClass"`
+	TaxRate          float64 `json:"taxRate"`
+	TaxAmount        float64 `json:"taxAmount"`
+	WeightGrams      *int32  `json:"weightGrams"`
+	LengthMm         *int32  `json:"lengthMm"`
+	WidthMm          *int32  `json:"widthMm"`
+	HeightMm         *int32  `json:"heightMm"`
+	HsCode           *string `json:"hsCode"`
+	OriginCountry    *string `json:"originCountry"`
+	Customization    []byte  `json:"customization"`
+	ShippedQuantity  uint64  `json:"shippedQuantity"`
+	ReturnedQuantity uint64  `json:"returnedQuantity"`
+	Note             *string `json:"note"`
+	Metadata         []byte  `json:"metadata"`
+}
+
+func (q *Queries) GetOrderItem(ctx context.Context, id int32) (*GetOrderItemRow, error) {
+	row := q.db.QueryRow(ctx, getOrderItem, id)
+	var i GetOrderItemRow
+	err := row.Scan(
+		&i.ID,
+		&i.OrderID,
+		&i.ProductID,
+		&i.PriceID,
+		&i.StockID,
+		&i.Quantity,
+		&i.UnitPrice,
+		&i.Subtotal,
+		&i.Location,
+		&i.ProductName,
+		&i.Sku,
+		&i.ImageUrl,
+		&i.TaxClass,
+		&i.TaxRate,
+		&i.TaxAmount,
+		&i.WeightGrams,
+		&i.LengthMm,
+		&i.WidthMm,
+		&i.HeightMm,
+		&i.HsCode,
+		&i.OriginCountry,
+		&i.Customization,
+		&i.ShippedQuantity,
+		&i.ReturnedQuantity,
+		&i.Note,
+		&i.Metadata,
+	)
+	return &i, err
+}
+
+const getOrderPaymentReference = `-- name: GetOrderPaymentReference :one
+SELECT customer_id, payment_intent_id, invoice_id FROM orders WHERE id = $1
+UNION ALL
+SELECT customer_id, payment_intent_id, invoice_id FROM orders_archive WHERE id = $1
+LIMIT 1
+`
+
+type GetOrderPaymentReferenceRow struct {
+	CustomerID      string  `json:"customerId"`
+	PaymentIntentID *string `json:"paymentIntentId"`
+	InvoiceID       *string `json:"invoiceId"`
+}
+
+func (q *Queries) GetOrderPaymentReference(ctx context.Context, id int32) (*GetOrderPaymentReferenceRow, error) {
+	row := q.db.QueryRow(ctx, getOrderPaymentReference, id)
+	var i GetOrderPaymentReferenceRow
+	err := row.Scan(
+		&i.CustomerID,
+		&i.PaymentIntentID,
+		&i.InvoiceID,
+	)
+	return &i, err
+}
+
+const getOrderReceiptLink = `-- name: GetOrderReceiptLink :one
+SELECT order_id, payment_intent_id, invoice_id, receipt_url, invoice_url, invoice_pdf_url, updated_at
+FROM order_receipt_links
+WHERE order_id = $1
+`
+
+func (q *Queries) GetOrderReceiptLink(ctx context.Context, orderID int32) (*OrderReceiptLink, error) {
+	row := q.db.QueryRow(ctx, getOrderReceiptLink, orderID)
+	var i OrderReceiptLink
+	err := row.Scan(
+		&i.OrderID,
+		&i.PaymentIntentID,
+		&i.InvoiceID,
+		&i.ReceiptUrl,
+		&i.InvoiceUrl,
+		&i.InvoicePdfUrl,
+		&i.UpdatedAt,
+	)
+	return &i, err
+}
+
+const getOrderRepricingForUpdate = `-- name: GetOrderRepricingForUpdate :one
+SELECT id, order_id, price_change_id, price_id, new_unit_price, previous_total, new_total, status, reviewed_by, created_at, updated_at
+FROM order_repricings
+WHERE id = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetOrderRepricingForUpdate(ctx context.Context, id int32) (*OrderRepricing, error) {
+	row := q.db.QueryRow(ctx, getOrderRepricingForUpdate, id)
+	var i OrderRepricing
+	err := row.Scan(
+		&i.ID,
+		&i.OrderID,
+		&i.PriceChangeID,
+		&i.PriceID,
+		&i.NewUnitPrice,
+		&i.PreviousTotal,
+		&i.NewTotal,
+		&i.Status,
+		&i.ReviewedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return &i, err
+}
+
+const getOrderReturn = `-- name: GetOrderReturn :one
+SELECT id, order_id, status, reason, carrier, tracking_number, label_url, created_at, updated_at
+FROM order_returns
+WHERE id = $1
+`
+
+func (q *Queries) GetOrderReturn(ctx context.Context, id int32) (*OrderReturn, error) {
+	row := q.db.QueryRow(ctx, getOrderReturn, id)
+	var i OrderReturn
+	err := row.Scan(
+		&i.ID,
+		&i.OrderID,
+		&i.Status,
+		&i.Reason,
+		&i.Carrier,
+		&i.TrackingNumber,
+		&i.LabelUrl,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return &i, err
+}
+
+const getOrderReturnForUpdate = `-- name: GetOrderReturnForUpdate :one
+SELECT id, order_id, status, reason, carrier, tracking_number, label_url, created_at, updated_at
+FROM order_returns
+WHERE id = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetOrderReturnForUpdate(ctx context.Context, id int32) (*OrderReturn, error) {
+	row := q.db.QueryRow(ctx, getOrderReturnForUpdate, id)
+	var i OrderReturn
+	err := row.Scan(
+		&i.ID,
+		&i.OrderID,
+		&i.Status,
+		&i.Reason,
+		&i.Carrier,
+		&i.TrackingNumber,
+		&i.LabelUrl,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return &i, err
+}
+
+const getOrderReturnIDByTracking = `-- name: GetOrderReturnIDByTracking :one
+SELECT id
+FROM order_returns
+WHERE carrier = $1 AND tracking_number = $2
+`
+
+type GetOrderReturnIDByTrackingParams struct {
+	Carrier        *string `json:"carrier"`
+	TrackingNumber *string `json:"trackingNumber"`
+}
+
+func (q *Queries) GetOrderReturnIDByTracking(ctx context.Context, arg GetOrderReturnIDByTrackingParams) (int32, error) {
+	row := q.db.QueryRow(ctx, getOrderReturnIDByTracking, arg.Carrier, arg.TrackingNumber)
+	var id int32
+	err := row.Scan(&id)
+	return id, err
+}
+
+const getOrderTaxExemptionNumber = `-- name: GetOrderTaxExemptionNumber :one
+SELECT certificate_number
+FROM order_tax_exemptions
+WHERE order_id = $1
+`
+
+func (q *Queries) GetOrderTaxExemptionNumber(ctx context.Context, orderID int32) (string, error) {
+	row := q.db.QueryRow(ctx, getOrderTaxExemptionNumber, orderID)
+	var certificateNumber string
+	err := row.Scan(&certificateNumber)
+	return certificateNumber, err
+}
+
+const getSalesReport = `-- name: GetSalesReport :many
+SELECT o.currency, o.reporting_currency,
+       COUNT(*)::bigint AS orders,
+       COUNT(o.exchange_rate)::bigint AS converted_orders,
+       COALESCE(SUM(o.total), 0)::float8 AS total,
+       COALESCE(SUM(o.reporting_subtotal), 0)::float8 AS reporting_subtotal,
+       COALESCE(SUM(o.reporting_tax), 0)::float8 AS reporting_tax,
+       COALESCE(SUM(o.reporting_discount), 0)::float8 AS reporting_discount,
+       COALESCE(SUM(o.reporting_total), 0)::float8 AS reporting_total
+FROM (
+    SELECT status, currency, total, reporting_currency, exchange_rate, reporting_subtotal, reporting_tax, reporting_discount, reporting_total, created_at FROM orders
+    UNION ALL
+    SELECT status, currency, total, reporting_currency, exchange_rate, reporting_subtotal, reporting_tax, reporting_discount, reporting_total, created_at FROM orders_archive
+) o
+WHERE o.created_at >= $1 AND o.created_at < $2 AND o.status NOT IN ('cancelled', 'failed', 'refunded')
+GROUP BY o.currency, o.reporting_currency
+ORDER BY o.currency, o.reporting_currency
+`
+
+type GetSalesReportParams struct {
+	RangeStart pgtype.Timestamptz `json:"rangeStart"`
+	RangeEnd   pgtype.Timestamptz `json:"rangeEnd"`
+}
+
+type GetSalesReportRow struct {
+	Currency          Currency     `json:"currency"`
+	ReportingCurrency NullCurrency `json:"reportingCurrency"`
+	Orders            int64        `json:"orders"`
+	ConvertedOrders   int64        `json:"convertedOrders"`
+	Total             float64      `json:"total"`
+	ReportingSubtotal float64      `json:"reportingSubtotal"`
+	ReportingTax      float64      `json:"reportingTax"`
+	ReportingDiscount float64      `json:"reportingDiscount"`
+	ReportingTotal    float64      `json:"reportingTotal"`
+}
+
+func (q *Queries) GetSalesReport(ctx context.Context, arg GetSalesReportParams) ([]*GetSalesReportRow, error) {
+	rows, err := q.db.Query(ctx, getSalesReport, arg.RangeStart, arg.RangeEnd)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*GetSalesReportRow{}
+	for rows.Next() {
+		var i GetSalesReportRow
+		if err := rows.Scan(
+			&i.Currency,
+			&i.ReportingCurrency,
+			&i.Orders,
+			&i.ConvertedOrders,
+			&i.Total,
+			&i.ReportingSubtotal,
+			&i.ReportingTax,
+			&i.ReportingDiscount,
+			&i.ReportingTotal,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getShipment = `-- name: GetShipment :one
+SELECT id, order_id, location, created_at
+FROM shipments
+WHERE id = $1
+`
+
+func (q *Queries) GetShipment(ctx context.Context, id int32) (*Shipment, error) {
+	row := q.db.QueryRow(ctx, getShipment, id)
+	var i Shipment
+	err := row.Scan(
+		&i.ID,
+		&i.OrderID,
+		&i.Location,
+		&i.CreatedAt,
+	)
+	return &i, err
+}
+
+const listArchivedOrderItems = `-- name: ListArchivedOrderItems :many
+SELECT id, order_id, product_id, price_id, stock_id, quantity, unit_price, subtotal, location, product_name, sku, image_url, tax_class, tax_rate, tax_amount, weight_grams, length_mm, width_mm, height_mm, hs_code, origin_country, customization, shipped_quantity, returned_quantity, note, metadata
+FROM order_items_archive
+WHERE order_id = $1
+`
+
+type ListArchivedOrderItemsRow struct {
+	ID               int32   `json:"id"`
+	OrderID          int32   `json:"orderId"`
+	ProductID        string  `json:"productId"`
+	PriceID          string  `json:"priceId"`
+	StockID          uint64  `json:"stockId"`
+	Quantity         uint64  `json:"quantity"`
+	UnitPrice        float64 `json:"unitPrice"`
+	Subtotal         float64 `json:"subtotal"`
+	Location         *string `json:"location"`
+	ProductName      *string `json:"productName"`
+	Sku              *string `json:"sku"`
+	ImageUrl         *string `json:"imageUrl"`
+	TaxClass         *string `json:"taxClass"`
+	TaxRate          float64 `json:"taxRate"`
+	TaxAmount        float64 `json:"taxAmount"`
+	WeightGrams      *int32  `json:"weightGrams"`
+	LengthMm         *int32  `json:"lengthMm"`
+	WidthMm          *int32  `json:"widthMm"`
+	HeightMm         *int32  `json:"heightMm"`
+	HsCode           *string `json:"hsCode"`
+	OriginCountry    *string `json:"originCountry"`
+	Customization    []byte  `json:"customization"`
+	ShippedQuantity  uint64  `json:"shippedQuantity"`
+	ReturnedQuantity uint64  `json:"returnedQuantity"`
+	Note             *string `json:"note"`
+	Metadata         []byte  `json:"metadata"`
+}
+
+func (q *Queries) ListArchivedOrderItems(ctx context.Context, orderID int32) ([]*ListArchivedOrderItemsRow, error) {
+	rows, err := q.db.Query(ctx, listArchivedOrderItems, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*ListArchivedOrderItemsRow{}
+	for rows.Next() {
+		var i ListArchivedOrderItemsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrderID,
+			&i.ProductID,
+			&i.PriceID,
+			&i.StockID,
+			&i.Quantity,
+			&i.UnitPrice,
+			&i.Subtotal,
+			&i.Location,
+			&i.ProductName,
+			&i.Sku,
+			&i.ImageUrl,
+			&i.TaxClass,
+			&i.TaxRate,
+			&i.TaxAmount,
+			&i.WeightGrams,
+			&i.LengthMm,
+			&i.WidthMm,
+			&i.HeightMm,
+			&i.HsCode,
+			&i.OriginCountry,
+			&i.Customization,
+			&i.ShippedQuantity,
+			&i.ReturnedQuantity,
+			&i.Note,
+			&i.Metadata,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCustomerOrderStats = `-- name: ListCustomerOrderStats :many
+SELECT o.customer_id,
+       COUNT(*)::bigint AS orders,
+       COALESCE(SUM(COALESCE(o.reporting_total, o.total)), 0)::float8 AS spend,
+       MIN(o.created_at)::timestamptz AS first_order_at,
+       MAX(o.created_at)::timestamptz AS last_order_at
+FROM (
+    SELECT customer_id, status, total, reporting_total, created_at FROM orders
+    UNION ALL
+    SELECT customer_id, status, total, reporting_total, created_at FROM orders_archive
+) o
+WHERE o.created_at >= $1 AND o.status NOT IN ('pending', 'cancelled', 'failed', 'refunded')
+GROUP BY o.customer_id
+ORDER BY o.customer_id
+`
+
+type ListCustomerOrderStatsRow struct {
+	CustomerID   string             `json:"customerId"`
+	Orders       int64              `json:"orders"`
+	Spend        float64            `json:"spend"`
+	FirstOrderAt pgtype.Timestamptz `json:"firstOrderAt"`
+	LastOrderAt  pgtype.Timestamptz `json:"lastOrderAt"`
+}
+
+func (q *Queries) ListCustomerOrderStats(ctx context.Context, since pgtype.Timestamptz) ([]*ListCustomerOrderStatsRow, error) {
+	rows, err := q.db.Query(ctx, listCustomerOrderStats, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*ListCustomerOrderStatsRow{}
+	for rows.Next() {
+		var i ListCustomerOrderStatsRow
+		if err := rows.Scan(
+			&i.CustomerID,
+			&i.Orders,
+			&i.Spend,
+			&i.FirstOrderAt,
+			&i.LastOrderAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listFraudReviewsByStatus = `-- name: ListFraudReviewsByStatus :many
+SELECT id, order_id, reason, detail, status, created_at, resolved_at, resolved_by, resolution_note
+FROM fraud_reviews
+WHERE status = $1
+ORDER BY created_at
+`
+
+func (q *Queries) ListFraudReviewsByStatus(ctx context.Context, status FraudReviewStatus) ([]*FraudReview, error) {
+	rows, err := q.db.Query(ctx, listFraudReviewsByStatus, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*FraudReview{}
+	for rows.Next() {
+		var i FraudReview
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrderID,
+			&i.Reason,
+			&i.Detail,
+			&i.Status,
+			&i.CreatedAt,
+			&i.ResolvedAt,
+			&i.ResolvedBy,
+			&i.ResolutionNote,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listInvoiceNumberGaps = `-- name: ListInvoiceNumberGaps :many
+SELECT tenant, country, type, gap_start, gap_end
+FROM (
+    SELECT tenant, country, type,
+           COALESCE(LAG(sequence_number) OVER (PARTITION BY tenant, country, type ORDER BY sequence_number), 0) + 1 AS gap_start,
+           sequence_number - 1 AS gap_end
+    FROM invoices
+) AS gaps
+WHERE gap_start <= gap_end
+UNION ALL
+SELECT s.tenant, s.country, s.type, COALESCE(MAX(i.sequence_number), 0) + 1 AS gap_start, s.next_number - 1 AS gap_end
+FROM invoice_sequences s
+LEFT JOIN invoices i ON i.tenant = s.tenant AND i.country = s.country AND i.type = s.type
+GROUP BY s.tenant, s.country, s.type, s.next_number
+HAVING COALESCE(MAX(i.sequence_number), 0) + 1 <= s.next_number - 1
+ORDER BY tenant, country, type, gap_start
+`
+
+type ListInvoiceNumberGapsRow struct {
+	Tenant   string      `json:"tenant"`
+	Country  string      `json:"country"`
+	Type     InvoiceType `json:"type"`
+	GapStart int64       `json:"gapStart"`
+	GapEnd   int64       `json:"gapEnd"`
+}
+
+func (q *Queries) ListInvoiceNumberGaps(ctx context.Context) ([]*ListInvoiceNumberGapsRow, error) {
+	rows, err := q.db.Query(ctx, listInvoiceNumberGaps)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*ListInvoiceNumberGapsRow{}
+	for rows.Next() {
+		var i ListInvoiceNumberGapsRow
+		if err := rows.Scan(
+			&i.Tenant,
+			&i.Country,
+			&i.Type,
+			&i.GapStart,
+			&i.GapEnd,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listInvoicesByOrderID = `-- name: ListInvoicesByOrderID :many
+SELECT id, tenant, country, type, sequence_number, invoice_number, order_id, refund_id, original_invoice_id, currency, subtotal, tax, total, document_url, issued_at, tax_exemption_number
+FROM invoices
+WHERE order_id = $1
+ORDER BY issued_at, id
+`
+
+func (q *Queries) ListInvoicesByOrderID(ctx context.Context, orderID int32) ([]*Invoice, error) {
+	rows, err := q.db.Query(ctx, listInvoicesByOrderID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*Invoice{}
+	for rows.Next() {
+		var i Invoice
+		if err := rows.Scan(
+			&i.ID,
+			&i.Tenant,
+			&i.Country,
+			&i.Type,
+			&i.SequenceNumber,
+			&i.InvoiceNumber,
+			&i.OrderID,
+			&i.RefundID,
+			&i.OriginalInvoiceID,
+			&i.Currency,
+			&i.Subtotal,
+			&i.Tax,
+			&i.Total,
+			&i.DocumentUrl,
+			&i.IssuedAt,
+			&i.TaxExemptionNumber,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOrderAddons = `-- name: ListOrderAddons :many
+SELECT id, order_id, order_item_id, type, description, quantity, unit_price, subtotal, tax_rate, tax_amount, created_at
+FROM order_addons
+WHERE order_id = $1
+UNION ALL
+SELECT id, order_id, order_item_id, type, description, quantity, unit_price, subtotal, tax_rate, tax_amount, created_at
+FROM order_addons_archive
+WHERE order_id = $1
+ORDER BY id
+`
+
+func (q *Queries) ListOrderAddons(ctx context.Context, orderID int32) ([]*OrderAddon, error) {
+	rows, err := q.db.Query(ctx, listOrderAddons, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*OrderAddon{}
+	for rows.Next() {
+		var i OrderAddon
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrderID,
+			&i.OrderItemID,
+			&i.Type,
+			&i.Description,
+			&i.Quantity,
+			&i.UnitPrice,
+			&i.Subtotal,
+			&i.TaxRate,
+			&i.TaxAmount,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOrderHolds = `-- name: ListOrderHolds :many
+SELECT id, order_id, reason, previous_status, created_at, released_at
+FROM order_holds
+WHERE order_id = $1
+ORDER BY id
+`
+
+func (q *Queries) ListOrderHolds(ctx context.Context, orderID int32) ([]*OrderHold, error) {
+	rows, err := q.db.Query(ctx, listOrderHolds, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*OrderHold{}
+	for rows.Next() {
+		var i OrderHold
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrderID,
+			&i.Reason,
+			&i.PreviousStatus,
+			&i.CreatedAt,
+			&i.ReleasedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOrderItems = `-- name: ListOrderItems :many
+SELECT id, order_id, product_id, price_id, stock_id, quantity, unit_price, subtotal, location, product_name, sku, image_url, tax_class, tax_rate, tax_amount, weight_grams, length_mm, width_mm, height_mm, hs_code, origin_country, customization, shipped_quantity, returned_quantity, note, metadata
+FROM order_items
+WHERE order_id = $1
+`
+
+type ListOrderItemsRow struct {
+	ID               int32   `json:"id"`
+	OrderID          int32   `json:"orderId"`
+	ProductID        string  `json:"productId"`
+	PriceID          string  `json:"priceId"`
+	StockID          uint64  `json:"stockId"`
+	Quantity         uint64  `json:"quantity"`
+	UnitPrice        float64 `json:"unitPrice"`
+	Subtotal         float64 `json:"subtotal"`
+	Location         *string `json:"location"`
+	ProductName      *string `json:"productName"`
+	Sku              *string `json:"sku"`
+	ImageUrl         *string `json:"imageUrl"`
+	TaxClass         *string `json:"taxClass"`
+	TaxRate          float64 `json:"taxRate"`
+	TaxAmount        float64 `json:"taxAmount"`
+	WeightGrams      *int32  `json:"weightGrams"`
+	LengthMm         *int32  `json:"lengthMm"`
+	WidthMm          *int32  `json:"widthMm"`
+	HeightMm         *int32  `json:"heightMm"`
+	HsCode           *string `json:"hsCode"`
+	OriginCountry    *string `json:"originCountry"`
+	Customization    []byte  `json:"customization"`
+	ShippedQuantity  uint64  `json:"shippedQuantity"`
+	ReturnedQuantity uint64  `json:"returnedQuantity"`
+	Note             *string `json:"note"`
+	Metadata         []byte  `json:"metadata"`
+}
+
+func (q *Queries) ListOrderItems(ctx context.Context, orderID int32) ([]*ListOrderItemsRow, error) {
+	rows, err := q.db.Query(ctx, listOrderItems, orderID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 	items := []*ListOrderItemsRow{}
 	for rows.Next() {
-		var i ListOrderItemsRow
+		var i ListOrderItemsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrderID,
+			&i.ProductID,
+			&i.PriceID,
+			&i.StockID,
+			&i.Quantity,
+			&i.UnitPrice,
+			&i.Subtotal,
+			&i.Location,
+			&i.ProductName,
+			&i.Sku,
+			&i.ImageUrl,
+			&i.TaxClass,
+			&i.TaxRate,
+			&i.TaxAmount,
+			&i.WeightGrams,
+			&i.LengthMm,
+			&i.WidthMm,
+			&i.HeightMm,
+			&i.HsCode,
+			&i.OriginCountry,
+			&i.Customization,
+			&i.ShippedQuantity,
+			&i.ReturnedQuantity,
+			&i.Note,
+			&i.Metadata,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOrderRepricings = `-- name: ListOrderRepricings :many
+SELECT id, order_id, price_change_id, price_id, new_unit_price, previous_total, new_total, status, reviewed_by, created_at, updated_at
+FROM order_repricings
+WHERE order_id = $1
+ORDER BY created_at, id
+`
+
+func (q *Queries) ListOrderRepricings(ctx context.Context, orderID int32) ([]*OrderRepricing, error) {
+	rows, err := q.db.Query(ctx, listOrderRepricings, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*OrderRepricing{}
+	for rows.Next() {
+		var i OrderRepricing
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrderID,
+			&i.PriceChangeID,
+			&i.PriceID,
+			&i.NewUnitPrice,
+			&i.PreviousTotal,
+			&i.NewTotal,
+			&i.Status,
+			&i.ReviewedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOrderReturns = `-- name: ListOrderReturns :many
+SELECT id, order_id, status, reason, carrier, tracking_number, label_url, created_at, updated_at
+FROM order_returns
+WHERE order_id = $1
+ORDER BY created_at, id
+`
+
+func (q *Queries) ListOrderReturns(ctx context.Context, orderID int32) ([]*OrderReturn, error) {
+	rows, err := q.db.Query(ctx, listOrderReturns, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*OrderReturn{}
+	for rows.Next() {
+		var i OrderReturn
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrderID,
+			&i.Status,
+			&i.Reason,
+			&i.Carrier,
+			&i.TrackingNumber,
+			&i.LabelUrl,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOrderTotalMismatches = `-- name: ListOrderTotalMismatches :many
+SELECT id, status, subtotal, tax, discount, total, items_subtotal
+FROM (
+    SELECT o.id, o.status, o.subtotal, o.tax, o.discount, o.total,
+           (COALESCE((SELECT SUM(oi.subtotal) FROM order_items oi WHERE oi.order_id = o.id), 0) +
+            COALESCE((SELECT SUM(oa.subtotal) FROM order_addons oa WHERE oa.order_id = o.id), 0))::float8 AS items_subtotal
+    FROM orders o
+) AS totals
+WHERE subtotal <> items_subtotal OR total <> subtotal + tax - discount
+ORDER BY id
+`
+
+type ListOrderTotalMismatchesRow struct {
+	ID            int32       `json:"id"`
+	Status        OrderStatus `json:"status"`
+	Subtotal      float64     `json:"subtotal"`
+	Tax           float64     `json:"tax"`
+	Discount      float64     `json:"discount"`
+	Total         float64     `json:"total"`
+	ItemsSubtotal float64     `json:"itemsSubtotal"`
+}
+
+func (q *Queries) ListOrderTotalMismatches(ctx context.Context) ([]*ListOrderTotalMismatchesRow, error) {
+	rows, err := q.db.Query(ctx, listOrderTotalMismatches)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*ListOrderTotalMismatchesRow{}
+	for rows.Next() {
+		var i ListOrderTotalMismatchesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Status,
+			&i.Subtotal,
+			&i.Tax,
+			&i.Discount,
+			&i.Total,
+			&i.ItemsSubtotal,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOrders = `-- name: ListOrders :many
+SELECT id, order_number, customer_id, cart_id, status, currency, subtotal, tax, discount, total, created_at, updated_at
+FROM orders
+WHERE customer_id = $1 AND deleted_at IS NULL
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3
+`
+
+type ListOrdersParams struct {
+	CustomerID string `json:"customerId"`
+	Limit      int64  `json:"limit"`
+	Offset     int64  `json:"offset"`
+}
+
+type ListOrdersRow struct {
+	ID          int32              `json:"id"`
+	OrderNumber string             `json:"orderNumber"`
+	CustomerID  string             `json:"customerId"`
+	CartID      uint64             `json:"cartId"`
+	Status      OrderStatus        `json:"status"`
+	Currency    Currency           `json:"currency"`
+	Subtotal    float64            `json:"subtotal"`
+	Tax         float64            `json:"tax"`
+	Discount    float64            `json:"discount"`
+	Total       float64            `json:"total"`
+	CreatedAt   pgtype.Timestamptz `json:"createdAt"`
+	UpdatedAt   pgtype.Timestamptz `json:"updatedAt"`
+}
+
+func (q *Queries) ListOrders(ctx context.Context, arg ListOrdersParams) ([]*ListOrdersRow, error) {
+	rows, err := q.db.Query(ctx, listOrders, arg.CustomerID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*ListOrdersRow{}
+	for rows.Next() {
+		var i ListOrdersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrderNumber,
+			&i.CustomerID,
+			&i.CartID,
+			&i.Status,
+			&i.Currency,
+			&i.Subtotal,
+			&i.Tax,
+			&i.Discount,
+			&i.Total,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOrdersAtRiskOfSLABreach = `-- name: ListOrdersAtRiskOfSLABreach :many
+SELECT s.order_id, o.order_number, o.customer_id, o.status, o.fulfillment_type, s.paid_at, s.warn_at, s.due_at, s.at_risk_alerted_at, s.breach_alerted_at
+FROM order_fulfillment_slas s
+JOIN orders o ON o.id = s.order_id
+WHERE s.fulfilled_at IS NULL AND s.warn_at <= $1 AND o.status::text = ANY($2::text[])
+ORDER BY s.due_at, s.order_id
+`
+
+type ListOrdersAtRiskOfSLABreachParams struct {
+	WarnAt   pgtype.Timestamptz `json:"warnAt"`
+	Statuses []string           `json:"statuses"`
+}
+
+type ListOrdersAtRiskOfSLABreachRow struct {
+	OrderID         int32              `json:"orderId"`
+	OrderNumber     string             `json:"orderNumber"`
+	CustomerID      string             `json:"customerId"`
+	Status          OrderStatus        `json:"status"`
+	FulfillmentType FulfillmentType    `json:"fulfillmentType"`
+	PaidAt          pgtype.Timestamptz `json:"paidAt"`
+	WarnAt          pgtype.Timestamptz `json:"warnAt"`
+	DueAt           pgtype.Timestamptz `json:"dueAt"`
+	AtRiskAlertedAt pgtype.Timestamptz `json:"atRiskAlertedAt"`
+	BreachAlertedAt pgtype.Timestamptz `json:"breachAlertedAt"`
+}
+
+func (q *Queries) ListOrdersAtRiskOfSLABreach(ctx context.Context, arg ListOrdersAtRiskOfSLABreachParams) ([]*ListOrdersAtRiskOfSLABreachRow, error) {
+	rows, err := q.db.Query(ctx, listOrdersAtRiskOfSLABreach, arg.WarnAt, arg.Statuses)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*ListOrdersAtRiskOfSLABreachRow{}
+	for rows.Next() {
+		var i ListOrdersAtRiskOfSLABreachRow
+		if err := rows.Scan(
+			&i.OrderID,
+			&i.OrderNumber,
+			&i.CustomerID,
+			&i.Status,
+			&i.FulfillmentType,
+			&i.PaidAt,
+			&i.WarnAt,
+			&i.DueAt,
+			&i.AtRiskAlertedAt,
+			&i.BreachAlertedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOrdersByFilter = `-- name: ListOrdersByFilter :many
+SELECT id, customer_id, cart_id, status, currency, subtotal, tax, discount, total, payment_intent_id, invoice_id, subscription_id, refund_id, shipping_address, billing_address, created_at, updated_at, fulfillment_type, pickup_location, metadata, tax_calculation_id, tax_transaction_id, reporting_currency, exchange_rate, reporting_subtotal, reporting_tax, reporting_discount, reporting_total, order_number, estimated_delivery_earliest, estimated_delivery_latest, external_source, external_order_id, applied_promotions, deleted_at, channel, cart_snapshot
+FROM orders
+WHERE ($1::varchar IS NULL OR customer_id = $1::varchar)
+  AND (cardinality($2::text[]) = 0 OR status::text = ANY($2::text[]))
+  AND ($3::timestamptz IS NULL OR created_at >= $3::timestamptz)
+  AND ($4::timestamptz IS NULL OR created_at < $4::timestamptz)
+ORDER BY id
+`
+
+type ListOrdersByFilterParams struct {
+	CustomerID  *string            `json:"customerId"`
+	Statuses    []string           `json:"statuses"`
+	CreatedFrom pgtype.Timestamptz `json:"createdFrom"`
+	CreatedTo   pgtype.Timestamptz `json:"createdTo"`
+}
+
+func (q *Queries) ListOrdersByFilter(ctx context.Context, arg ListOrdersByFilterParams) ([]*Order, error) {
+	rows, err := q.db.Query(ctx, listOrdersByFilter,
+		arg.CustomerID,
+		arg.Statuses,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*Order{}
+	for rows.Next() {
+		var i Order
+		if err := rows.Scan(
+			&i.ID,
+			&i.CustomerID,
+			&i.CartID,
+			&i.Status,
+			&i.Currency,
+			&i.Subtotal,
+			&i.Tax,
+			&i.Discount,
+			&i.Total,
+			&i.PaymentIntentID,
+			&i.InvoiceID,
+			&i.SubscriptionID,
+			&i.RefundID,
+			&i.ShippingAddress,
+			&i.BillingAddress,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.FulfillmentType,
+			&i.PickupLocation,
+			&i.Metadata,
+			&i.TaxCalculationID,
+			&i.TaxTransactionID,
+			&i.ReportingCurrency,
+			&i.ExchangeRate,
+			&i.ReportingSubtotal,
+			&i.ReportingTax,
+			&i.ReportingDiscount,
+			&i.ReportingTotal,
+			&i.OrderNumber,
+			&i.EstimatedDeliveryEarliest,
+			&i.EstimatedDeliveryLatest,
+			&i.ExternalSource,
+			&i.ExternalOrderID,
+			&i.AppliedPromotions,
+			&i.DeletedAt,
+			&i.Channel,
+			&i.CartSnapshot,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOrdersByStatus = `-- name: ListOrdersByStatus :many
+SELECT id, order_number, customer_id, cart_id, status, currency, subtotal, tax, discount, total, created_at, updated_at
+FROM orders
+WHERE status = $1
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3
+`
+
+type ListOrdersByStatusParams struct {
+	Status OrderStatus `json:"status"`
+	Limit  int64       `json:"limit"`
+	Offset int64       `json:"offset"`
+}
+
+type ListOrdersByStatusRow struct {
+	ID          int32              `json:"id"`
+	OrderNumber string             `json:"orderNumber"`
+	CustomerID  string             `json:"customerId"`
+	CartID      uint64             `json:"cartId"`
+	Status      OrderStatus        `json:"status"`
+	Currency    Currency           `json:"currency"`
+	Subtotal    float64            `json:"subtotal"`
+	Tax         float64            `json:"tax"`
+	Discount    float64            `json:"discount"`
+	Total       float64            `json:"total"`
+	CreatedAt   pgtype.Timestamptz `json:"createdAt"`
+	UpdatedAt   pgtype.Timestamptz `json:"updatedAt"`
+}
+
+func (q *Queries) ListOrdersByStatus(ctx context.Context, arg ListOrdersByStatusParams) ([]*ListOrdersByStatusRow, error) {
+	rows, err := q.db.Query(ctx, listOrdersByStatus, arg.Status, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*ListOrdersByStatusRow{}
+	for rows.Next() {
+		var i ListOrdersByStatusRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrderNumber,
+			&i.CustomerID,
+			&i.CartID,
+			&i.Status,
+			&i.Currency,
+			&i.Subtotal,
+			&i.Tax,
+			&i.Discount,
+			&i.Total,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOrdersPendingTaxCommit = `-- name: ListOrdersPendingTaxCommit :many
+SELECT id, tax_calculation_id
+FROM orders
+WHERE tax_calculation_id IS NOT NULL AND tax_transaction_id IS NULL
+  AND status NOT IN ('pending', 'cancelled', 'failed') AND updated_at < $1 AND deleted_at IS NULL
+ORDER BY id
+LIMIT $2
+`
+
+type ListOrdersPendingTaxCommitParams struct {
+	UpdatedAt pgtype.Timestamptz `json:"updatedAt"`
+	Limit     int32              `json:"limit"`
+}
+
+type ListOrdersPendingTaxCommitRow struct {
+	ID               int32   `json:"id"`
+	TaxCalculationID *string `json:"taxCalculationId"`
+}
+
+func (q *Queries) ListOrdersPendingTaxCommit(ctx context.Context, arg ListOrdersPendingTaxCommitParams) ([]*ListOrdersPendingTaxCommitRow, error) {
+	rows, err := q.db.Query(ctx, listOrdersPendingTaxCommit, arg.UpdatedAt, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*ListOrdersPendingTaxCommitRow{}
+	for rows.Next() {
+		var i ListOrdersPendingTaxCommitRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.TaxCalculationID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOrphanedOrderItems = `-- name: ListOrphanedOrderItems :many
+SELECT oi.id, oi.order_id, o.status AS order_status, oi.product_id, oi.stock_id, s.product_id AS stock_product_id
+FROM order_items oi
+JOIN orders o ON o.id = oi.order_id
+LEFT JOIN stocks s ON s.id = oi.stock_id
+WHERE s.id IS NULL OR s.product_id <> oi.product_id
+ORDER BY oi.id
+`
+
+type ListOrphanedOrderItemsRow struct {
+	ID             int32       `json:"id"`
+	OrderID        int32       `json:"orderId"`
+	OrderStatus    OrderStatus `json:"orderStatus"`
+	ProductID      string      `json:"productId"`
+	StockID        *uint64     `json:"stockId"`
+	StockProductID *string     `json:"stockProductId"`
+}
+
+func (q *Queries) ListOrphanedOrderItems(ctx context.Context) ([]*ListOrphanedOrderItemsRow, error) {
+	rows, err := q.db.Query(ctx, listOrphanedOrderItems)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*ListOrphanedOrderItemsRow{}
+	for rows.Next() {
+		var i ListOrphanedOrderItemsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrderID,
+			&i.OrderStatus,
+			&i.ProductID,
+			&i.StockID,
+			&i.StockProductID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPendingOrderCancellationRequests = `-- name: ListPendingOrderCancellationRequests :many
+SELECT id, order_id, customer_id, reason, status, reviewed_by, created_at, updated_at
+FROM order_cancellation_requests
+WHERE status = 'pending_review'
+ORDER BY created_at, id
+`
+
+func (q *Queries) ListPendingOrderCancellationRequests(ctx context.Context) ([]*OrderCancellationRequest, error) {
+	rows, err := q.db.Query(ctx, listPendingOrderCancellationRequests)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*OrderCancellationRequest{}
+	for rows.Next() {
+		var i OrderCancellationRequest
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrderID,
+			&i.CustomerID,
+			&i.Reason,
+			&i.Status,
+			&i.ReviewedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPendingOrderIDsByPrice = `-- name: ListPendingOrderIDsByPrice :many
+SELECT DISTINCT o.id
+FROM orders o
+JOIN order_items oi ON oi.order_id = o.id
+WHERE o.status = 'pending' AND oi.price_id = $1 AND oi.unit_price > $2
+  AND ($3::text IS NULL OR o.currency::text = $3::text)
+ORDER BY o.id
+`
+
+type ListPendingOrderIDsByPriceParams struct {
+	PriceID   string  `json:"priceId"`
+	UnitPrice float64 `json:"unitPrice"`
+	Currency  *string `json:"currency"`
+}
+
+func (q *Queries) ListPendingOrderIDsByPrice(ctx context.Context, arg ListPendingOrderIDsByPriceParams) ([]int32, error) {
+	rows, err := q.db.Query(ctx, listPendingOrderIDsByPrice, arg.PriceID, arg.UnitPrice, arg.Currency)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int32{}
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPendingOrderRepricings = `-- name: ListPendingOrderRepricings :many
+SELECT id, order_id, price_change_id, price_id, new_unit_price, previous_total, new_total, status, reviewed_by, created_at, updated_at
+FROM order_repricings
+WHERE status = 'pending_review'
+ORDER BY created_at, id
+`
+
+func (q *Queries) ListPendingOrderRepricings(ctx context.Context) ([]*OrderRepricing, error) {
+	rows, err := q.db.Query(ctx, listPendingOrderRepricings)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*OrderRepricing{}
+	for rows.Next() {
+		var i OrderRepricing
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrderID,
+			&i.PriceChangeID,
+			&i.PriceID,
+			&i.NewUnitPrice,
+			&i.PreviousTotal,
+			&i.NewTotal,
+			&i.Status,
+			&i.ReviewedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRefundItemsByOrderID = `-- name: ListRefundItemsByOrderID :many
+SELECT ri.id, ri.refund_id, ri.order_item_id, ri.quantity, ri.amount, ri.restocking_fee, ri.rule, ri.order_addon_id
+FROM refund_items ri
+JOIN refunds r ON r.id = ri.refund_id
+WHERE r.order_id = $1
+ORDER BY ri.id
+`
+
+func (q *Queries) ListRefundItemsByOrderID(ctx context.Context, orderID int32) ([]*RefundItem, error) {
+	rows, err := q.db.Query(ctx, listRefundItemsByOrderID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*RefundItem{}
+	for rows.Next() {
+		var i RefundItem
 		if err := rows.Scan(
 			&i.ID,
-			&i.OrderID,
-			&i.ProductID,
-			&i.PriceID,
-			&i.StockID,
+			&i.RefundID,
+			&i.OrderItemID,
 			&i.Quantity,
-			&i.UnitPrice,
+			&i.Amount,
+			&i.RestockingFee,
+			&i.Rule,
+			&i.OrderAddonID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRefundsByOrderID = `-- name: ListRefundsByOrderID :many
+SELECT id, order_id, stripe_refund_id, currency, amount, restocking_fee, reason, policy_version, created_at
+FROM refunds
+WHERE order_id = $1
+ORDER BY id
+`
+
+func (q *Queries) ListRefundsByOrderID(ctx context.Context, orderID int32) ([]*Refund, error) {
+	rows, err := q.db.Query(ctx, listRefundsByOrderID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*Refund{}
+	for rows.Next() {
+		var i Refund
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrderID,
+			&i.StripeRefundID,
+			&i.Currency,
+			&i.Amount,
+			&i.RestockingFee,
+			&i.Reason,
+			&i.PolicyVersion,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRevenueEvents = `-- name: ListRevenueEvents :many
+WITH all_orders AS (
+    SELECT id, order_number, status, currency, subtotal, tax, discount, total, created_at FROM orders
+    UNION ALL
+    SELECT id, order_number, status, currency, subtotal, tax, discount, total, created_at FROM orders_archive
+), refund_taxes AS (
+    SELECT r.id, r.order_id, r.currency, r.amount, r.created_at,
+           CASE WHEN o.total > 0 THEN ROUND(r.amount * o.tax / o.total, 2) ELSE 0 END AS tax
+    FROM refunds r
+    JOIN all_orders o ON o.id = r.order_id
+    WHERE r.created_at >= $1 AND r.created_at < $2
+)
+SELECT 'sale'::text AS kind, o.id AS order_id, o.order_number, 0::int AS refund_id, o.currency, o.created_at AS occurred_at,
+       o.subtotal::float8 AS subtotal, o.tax::float8 AS tax, o.discount::float8 AS discount, o.total::float8 AS total
+FROM all_orders o
+WHERE o.created_at >= $1 AND o.created_at < $2
+  AND o.status::text = ANY($3::text[])
+UNION ALL
+SELECT 'refund'::text, rt.order_id, o.order_number, rt.id, rt.currency, rt.created_at,
+       (rt.amount - rt.tax)::float8, rt.tax::float8, 0::float8, rt.amount::float8
+FROM refund_taxes rt
+JOIN all_orders o ON o.id = rt.order_id
+ORDER BY occurred_at, order_id, refund_id
+`
+
+type ListRevenueEventsParams struct {
+	PeriodStart pgtype.Timestamptz `json:"periodStart"`
+	PeriodEnd   pgtype.Timestamptz `json:"periodEnd"`
+	Statuses    []string           `json:"statuses"`
+}
+
+type ListRevenueEventsRow struct {
+	Kind        string             `json:"kind"`
+	OrderID     int32              `json:"orderId"`
+	OrderNumber string             `json:"orderNumber"`
+	RefundID    int32              `json:"refundId"`
+	Currency    Currency           `json:"currency"`
+	OccurredAt  pgtype.Timestamptz `json:"occurredAt"`
+	Subtotal    float64            `json:"subtotal"`
+	Tax         float64            `json:"tax"`
+	Discount    float64            `json:"discount"`
+	Total       float64            `json:"total"`
+}
+
+func (q *Queries) ListRevenueEvents(ctx context.Context, arg ListRevenueEventsParams) ([]*ListRevenueEventsRow, error) {
+	rows, err := q.db.Query(ctx, listRevenueEvents, arg.PeriodStart, arg.PeriodEnd, arg.Statuses)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*ListRevenueEventsRow{}
+	for rows.Next() {
+		var i ListRevenueEventsRow
+		if err := rows.Scan(
+			&i.Kind,
+			&i.OrderID,
+			&i.OrderNumber,
+			&i.RefundID,
+			&i.Currency,
+			&i.OccurredAt,
 			&i.Subtotal,
+			&i.Tax,
+			&i.Discount,
+			&i.Total,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listShipmentsByOrderID = `-- name: ListShipmentsByOrderID :many
+SELECT id, order_id, location, created_at
+FROM shipments
+WHERE order_id = $1
+ORDER BY id
+`
+
+func (q *Queries) ListShipmentsByOrderID(ctx context.Context, orderID int32) ([]*Shipment, error) {
+	rows, err := q.db.Query(ctx, listShipmentsByOrderID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*Shipment{}
+	for rows.Next() {
+		var i Shipment
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrderID,
+			&i.Location,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTaxExemptionCertificates = `-- name: ListTaxExemptionCertificates :many
+SELECT id, customer_id, certificate_number, jurisdiction, tax_classes, valid_from, valid_until, revoked_at, created_at, updated_at
+FROM tax_exemption_certificates
+WHERE customer_id = $1
+ORDER BY created_at DESC, id DESC
+`
+
+func (q *Queries) ListTaxExemptionCertificates(ctx context.Context, customerID string) ([]*TaxExemptionCertificate, error) {
+	rows, err := q.db.Query(ctx, listTaxExemptionCertificates, customerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*TaxExemptionCertificate{}
+	for rows.Next() {
+		var i TaxExemptionCertificate
+		if err := rows.Scan(
+			&i.ID,
+			&i.CustomerID,
+			&i.CertificateNumber,
+			&i.Jurisdiction,
+			&i.TaxClasses,
+			&i.ValidFrom,
+			&i.ValidUntil,
+			&i.RevokedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTrackedOrderReturns = `-- name: ListTrackedOrderReturns :many
+SELECT id, order_id, status, reason, carrier, tracking_number, label_url, created_at, updated_at
+FROM order_returns
+WHERE status IN ('label_issued', 'in_transit') AND id > $1
+ORDER BY id
+LIMIT $2
+`
+
+type ListTrackedOrderReturnsParams struct {
+	ID    int32 `json:"id"`
+	Limit int32 `json:"limit"`
+}
+
+func (q *Queries) ListTrackedOrderReturns(ctx context.Context, arg ListTrackedOrderReturnsParams) ([]*OrderReturn, error) {
+	rows, err := q.db.Query(ctx, listTrackedOrderReturns, arg.ID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*OrderReturn{}
+	for rows.Next() {
+		var i OrderReturn
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrderID,
+			&i.Status,
+			&i.Reason,
+			&i.Carrier,
+			&i.TrackingNumber,
+			&i.LabelUrl,
+			&i.CreatedAt,
+			&i.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -346,59 +2983,333 @@ func (q *Queries) ListOrderItems(ctx context.Context, orderID int32) ([]*ListOrd
 	return items, nil
 }
 
-const listOrders = `-- name: ListOrders :many
-SELECT id, customer_id, cart_id, status, currency, subtotal, tax, discount, total, created_at, updated_at
-FROM orders
-WHERE customer_id = $1
-ORDER BY created_at DESC
-LIMIT $2 OFFSET $3
+const listUnshippedOrderIDsByProduct = `-- name: ListUnshippedOrderIDsByProduct :many
+SELECT DISTINCT o.id
+FROM orders o
+JOIN order_items oi ON oi.order_id = o.id
+WHERE oi.product_id = $1 AND o.status::text = ANY($2::text[])
+  AND NOT EXISTS (SELECT 1 FROM shipments s WHERE s.order_id = o.id)
+ORDER BY o.id
+`
+
+type ListUnshippedOrderIDsByProductParams struct {
+	ProductID string   `json:"productId"`
+	Statuses  []string `json:"statuses"`
+}
+
+func (q *Queries) ListUnshippedOrderIDsByProduct(ctx context.Context, arg ListUnshippedOrderIDsByProductParams) ([]int32, error) {
+	rows, err := q.db.Query(ctx, listUnshippedOrderIDsByProduct, arg.ProductID, arg.Statuses)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int32{}
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markFulfillmentSLAAtRiskAlerted = `-- name: MarkFulfillmentSLAAtRiskAlerted :execrows
+UPDATE order_fulfillment_slas
+SET at_risk_alerted_at = NOW()
+WHERE order_id = $1 AND at_risk_alerted_at IS NULL
+`
+
+func (q *Queries) MarkFulfillmentSLAAtRiskAlerted(ctx context.Context, orderID int32) (int64, error) {
+	result, err := q.db.Exec(ctx, markFulfillmentSLAAtRiskAlerted, orderID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const markFulfillmentSLABreachAlerted = `-- name: MarkFulfillmentSLABreachAlerted :execrows
+UPDATE order_fulfillment_slas
+SET breach_alerted_at = NOW()
+WHERE order_id = $1 AND breach_alerted_at IS NULL
+`
+
+func (q *Queries) MarkFulfillmentSLABreachAlerted(ctx context.Context, orderID int32) (int64, error) {
+	result, err := q.db.Exec(ctx, markFulfillmentSLABreachAlerted, orderID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const mergeOrderMetadata = `-- name: MergeOrderMetadata :execrows
+UPDATE orders
+SET metadata = metadata || $2::jsonb, updated_at = NOW()
+WHERE id = $1
+`
+
+type MergeOrderMetadataParams struct {
+	ID       int32  `json:"id"`
+	Metadata []byte `json:"metadata"`
+}
+
+func (q *Queries) MergeOrderMetadata(ctx context.Context, arg MergeOrderMetadataParams) (int64, error) {
+	result, err := q.db.Exec(ctx, mergeOrderMetadata, arg.ID, arg.Metadata)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const nextInvoiceSequenceNumber = `-- name: NextInvoiceSequenceNumber :one
+INSERT INTO invoice_sequences (tenant, country, type, next_number, updated_at)
+VALUES ($1, $2, $3, 2, NOW())
+ON CONFLICT (tenant, country, type) DO UPDATE
+SET next_number = invoice_sequences.next_number + 1, updated_at = NOW()
+RETURNING (next_number - 1)::bigint AS sequence_number
+`
+
+type NextInvoiceSequenceNumberParams struct {
+	Tenant  string      `json:"tenant"`
+	Country string      `json:"country"`
+	Type    InvoiceType `json:"type"`
+}
+
+func (q *Queries) NextInvoiceSequenceNumber(ctx context.Context, arg NextInvoiceSequenceNumberParams) (int64, error) {
+	row := q.db.QueryRow(ctx, nextInvoiceSequenceNumber, arg.Tenant, arg.Country, arg.Type)
+	var sequenceNumber int64
+	err := row.Scan(&sequenceNumber)
+	return sequenceNumber, err
+}
+
+const purgeDeletedOrders = `-- name: PurgeDeletedOrders :many
+DELETE FROM orders
+WHERE id IN (
+    SELECT id
+    FROM orders
+    WHERE deleted_at < $1
+    ORDER BY id
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id
+`
+
+type PurgeDeletedOrdersParams struct {
+	Cutoff    pgtype.Timestamptz `json:"cutoff"`
+	BatchSize int32              `json:"batchSize"`
+}
+
+func (q *Queries) PurgeDeletedOrders(ctx context.Context, arg PurgeDeletedOrdersParams) ([]int32, error) {
+	rows, err := q.db.Query(ctx, purgeDeletedOrders, arg.Cutoff, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int32{}
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const recordPaymentFingerprint = `-- name: RecordPaymentFingerprint :exec
+INSERT INTO payment_fingerprints (payment_intent_id, fingerprint, customer_id, order_id, created_at)
+VALUES ($1, $2, $3, $4, NOW())
+ON CONFLICT (payment_intent_id, fingerprint) DO NOTHING
+`
+
+type RecordPaymentFingerprintParams struct {
+	PaymentIntentID string `json:"paymentIntentId"`
+	Fingerprint     string `json:"fingerprint"`
+	CustomerID      string `json:"customerId"`
+	OrderID         int32  `json:"orderId"`
+}
+
+func (q *Queries) RecordPaymentFingerprint(ctx context.Context, arg RecordPaymentFingerprintParams) error {
+	_, err := q.db.Exec(ctx, recordPaymentFingerprint,
+		arg.PaymentIntentID,
+		arg.Fingerprint,
+		arg.CustomerID,
+		arg.OrderID,
+	)
+	return err
+}
+
+const releaseOrderHold = `-- name: ReleaseOrderHold :execrows
+UPDATE order_holds
+SET released_at = NOW()
+WHERE id = $1 AND released_at IS NULL
+`
+
+func (q *Queries) ReleaseOrderHold(ctx context.Context, id int32) (int64, error) {
+	result, err := q.db.Exec(ctx, releaseOrderHold, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const resolveFraudReview = `-- name: ResolveFraudReview :execrows
+UPDATE fraud_reviews
+SET status = $2, resolved_by = $3, resolution_note = $4, resolved_at = NOW()
+WHERE id = $1 AND status = 'pending'
+`
+
+type ResolveFraudReviewParams struct {
+	ID             int32             `json:"id"`
+	Status         FraudReviewStatus `json:"status"`
+	ResolvedBy     *string           `json:"resolvedBy"`
+	ResolutionNote *string           `json:"resolutionNote"`
+}
+
+func (q *Queries) ResolveFraudReview(ctx context.Context, arg ResolveFraudReviewParams) (int64, error) {
+	result, err := q.db.Exec(ctx, resolveFraudReview,
+		arg.ID,
+		arg.Status,
+		arg.ResolvedBy,
+		arg.ResolutionNote,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const resolveOrderCancellationRequest = `-- name: ResolveOrderCancellationRequest :execrows
+UPDATE order_cancellation_requests
+SET status = $2, reviewed_by = $3, updated_at = NOW()
+WHERE id = $1 AND status = 'pending_review'
+`
+
+type ResolveOrderCancellationRequestParams struct {
+	ID         int32                     `json:"id"`
+	Status     CancellationRequestStatus `json:"status"`
+	ReviewedBy *string                   `json:"reviewedBy"`
+}
+
+func (q *Queries) ResolveOrderCancellationRequest(ctx context.Context, arg ResolveOrderCancellationRequestParams) (int64, error) {
+	result, err := q.db.Exec(ctx, resolveOrderCancellationRequest, arg.ID, arg.Status, arg.ReviewedBy)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const resolveOrderRepricing = `-- name: ResolveOrderRepricing :execrows
+UPDATE order_repricings
+SET status = $2, reviewed_by = $3, new_total = $4, updated_at = NOW()
+WHERE id = $1 AND status = 'pending_review'
+`
+
+type ResolveOrderRepricingParams struct {
+	ID         int32                `json:"id"`
+	Status     OrderRepricingStatus `json:"status"`
+	ReviewedBy *string              `json:"reviewedBy"`
+	NewTotal   float64              `json:"newTotal"`
+}
+
+func (q *Queries) ResolveOrderRepricing(ctx context.Context, arg ResolveOrderRepricingParams) (int64, error) {
+	result, err := q.db.Exec(ctx, resolveOrderRepricing,
+		arg.ID,
+		arg.Status,
+		arg.ReviewedBy,
+		arg.NewTotal,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const restoreOrder = `-- name: RestoreOrder :execrows
+UPDATE orders
+SET deleted_at = NULL, updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NOT NULL
+`
+
+func (q *Queries) RestoreOrder(ctx context.Context, id int32) (int64, error) {
+	result, err := q.db.Exec(ctx, restoreOrder, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const returnShippedOrderItems = `-- name: ReturnShippedOrderItems :execrows
+UPDATE order_items
+SET returned_quantity = shipped_quantity, updated_at = NOW()
+WHERE order_id = $1 AND returned_quantity < shipped_quantity
+`
+
+func (q *Queries) ReturnShippedOrderItems(ctx context.Context, orderID int32) (int64, error) {
+	result, err := q.db.Exec(ctx, returnShippedOrderItems, orderID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const revokeTaxExemptionCertificate = `-- name: RevokeTaxExemptionCertificate :execrows
+UPDATE tax_exemption_certificates
+SET revoked_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND customer_id = $2 AND revoked_at IS NULL
 `
 
-type ListOrdersParams struct {
+type RevokeTaxExemptionCertificateParams struct {
+	ID         int32  `json:"id"`
 	CustomerID string `json:"customerId"`
-	Limit      int64  `json:"limit"`
-	Offset     int64  `json:"offset"`
 }
 
-type ListOrdersRow struct {
-	ID         int32              `json:"id"`
-	CustomerID string             `json:"customerId"`
-	CartID     uint64             `json:"cartId"`
-	Status     OrderStatus        `json:"status"`
-	Currency   Currency           `json:"currency"`
-	Subtotal   float64            `json:"subtotal"`
-	Tax        float64            `json:"tax"`
-	Discount   float64            `json:"discount"`
-	Total      float64            `json:"total"`
-	CreatedAt  pgtype.Timestamptz `json:"createdAt"`
-	UpdatedAt  pgtype.Timestamptz `json:"updatedAt"`
+func (q *Queries) RevokeTaxExemptionCertificate(ctx context.Context, arg RevokeTaxExemptionCertificateParams) (int64, error) {
+	result, err := q.db.Exec(ctx, revokeTaxExemptionCertificate, arg.ID, arg.CustomerID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
-func (q *Queries) ListOrders(ctx context.Context, arg ListOrdersParams) ([]*ListOrdersRow, error) {
-	rows, err := q.db.Query(ctx, listOrders, arg.CustomerID, arg.Limit, arg.Offset)
+const searchOrderIDs = `-- name: SearchOrderIDs :many
+SELECT o.id
+FROM orders o
+WHERE o.deleted_at IS NULL
+  AND (o.order_number ILIKE $1::text
+    OR o.customer_id ILIKE $1::text
+    OR o.metadata::text ILIKE $1::text
+    OR EXISTS (SELECT 1 FROM order_items oi WHERE oi.order_id = o.id AND oi.product_name ILIKE $1::text))
+ORDER BY o.created_at DESC, o.id DESC
+LIMIT $2 OFFSET $3
+`
+
+type SearchOrderIDsParams struct {
+	Pattern   string `json:"pattern"`
+	RowLimit  int32  `json:"rowLimit"`
+	RowOffset int32  `json:"rowOffset"`
+}
+
+func (q *Queries) SearchOrderIDs(ctx context.Context, arg SearchOrderIDsParams) ([]int32, error) {
+	rows, err := q.db.Query(ctx, searchOrderIDs, arg.Pattern, arg.RowLimit, arg.RowOffset)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []*ListOrdersRow{}
+	items := []int32{}
 	for rows.Next() {
-		var i ListOrdersRow
-		if err := rows.Scan(
-			&i.ID,
-			&i.CustomerID,
-			&i.CartID,
-			&i.Status,
-			&i.Currency,
-			&i.Subtotal,
-			&i.Tax,
-			&i.Discount,
-			&i.Total,
-			&i.CreatedAt,
-			&i.UpdatedAt,
-		); err != nil {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
 			return nil, err
 		}
-		items = append(items, &i)
+		items = append(items, id)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, err
@@ -406,69 +3317,374 @@ func (q *Queries) ListOrders(ctx context.Context, arg ListOrdersParams) ([]*List
 	return items, nil
 }
 
-const listOrdersByStatus = `-- name: ListOrdersByStatus :many
-SELECT id, customer_id, cart_id, status, currency, subtotal, tax, discount, total, created_at, updated_at
-FROM orders
-WHERE status = $1
-ORDER BY created_at DESC
-LIMIT $2 OFFSET $3
+const setInvoiceDocument = `-- name: SetInvoiceDocument :execrows
+UPDATE invoices
+SET document_url = $2
+WHERE id = $1
 `
 
-type ListOrdersByStatusParams struct {
-	Status OrderStatus `json:"status"`
-	Limit  int64       `json:"limit"`
-	Offset int64       `json:"offset"`
+type SetInvoiceDocumentParams struct {
+	ID          int32   `json:"id"`
+	DocumentUrl *string `json:"documentUrl"`
 }
 
-type ListOrdersByStatusRow struct {
-	ID         int32              `json:"id"`
-	CustomerID string             `json:"customerId"`
-	CartID     uint64             `json:"cartId"`
-	Status     OrderStatus        `json:"status"`
-	Currency   Currency           `json:"currency"`
-	Subtotal   float64            `json:"subtotal"`
-	Tax        float64            `json:"tax"`
-	Discount   float64            `json:"discount"`
-	Total      float64            `json:"total"`
-	CreatedAt  pgtype.Timestamptz `json:"createdAt"`
-	UpdatedAt  pgtype.Timestamptz `json:"updatedAt"`
+func (q *Queries) SetInvoiceDocument(ctx context.Context, arg SetInvoiceDocumentParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setInvoiceDocument, arg.ID, arg.DocumentUrl)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
-func (q *Queries) ListOrdersByStatus(ctx context.Context, arg ListOrdersByStatusParams) ([]*ListOrdersByStatusRow, error) {
-	rows, err := q.db.Query(ctx, listOrdersByStatus, arg.Status, arg.Limit, arg.Offset)
+const setOrderCartSnapshot = `-- name: SetOrderCartSnapshot :execrows
+UPDATE orders
+SET cart_snapshot = $2
+WHERE id = $1 AND cart_snapshot IS NULL
+`
+
+type SetOrderCartSnapshotParams struct {
+	ID           int32  `json:"id"`
+	CartSnapshot []byte `json:"cartSnapshot"`
+}
+
+func (q *Queries) SetOrderCartSnapshot(ctx context.Context, arg SetOrderCartSnapshotParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setOrderCartSnapshot, arg.ID, arg.CartSnapshot)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
-	defer rows.Close()
-	items := []*ListOrdersByStatusRow{}
-	for rows.Next() {
-		var i ListOrdersByStatusRow
-		if err := rows.Scan(
-			&i.ID,
-			&i.CustomerID,
-			&i.CartID,
-			&i.Status,
-			&i.Currency,
-			&i.Subtotal,
-			&i.Tax,
-			&i.Discount,
-			&i.Total,
-			&i.CreatedAt,
-			&i.UpdatedAt,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, &i)
+	return result.RowsAffected(), nil
+}
+
+const setOrderChannel = `-- name: SetOrderChannel :exec
+UPDATE orders
+SET channel = $2
+WHERE id = $1
+`
+
+type SetOrderChannelParams struct {
+	ID      int32   `json:"id"`
+	Channel *string `json:"channel"`
+}
+
+func (q *Queries) SetOrderChannel(ctx context.Context, arg SetOrderChannelParams) error {
+	_, err := q.db.Exec(ctx, setOrderChannel, arg.ID, arg.Channel)
+	return err
+}
+
+const setOrderDeliveryEstimate = `-- name: SetOrderDeliveryEstimate :execrows
+UPDATE orders
+SET estimated_delivery_earliest = $2, estimated_delivery_latest = $3
+WHERE id = $1
+`
+
+type SetOrderDeliveryEstimateParams struct {
+	ID                        int32       `json:"id"`
+	EstimatedDeliveryEarliest pgtype.Date `json:"estimatedDeliveryEarliest"`
+	EstimatedDeliveryLatest   pgtype.Date `json:"estimatedDeliveryLatest"`
+}
+
+func (q *Queries) SetOrderDeliveryEstimate(ctx context.Context, arg SetOrderDeliveryEstimateParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setOrderDeliveryEstimate, arg.ID, arg.EstimatedDeliveryEarliest, arg.EstimatedDeliveryLatest)
+	if err != nil {
+		return 0, err
 	}
-	if err := rows.Err(); err != nil {
-		return nil, err
+	return result.RowsAffected(), nil
+}
+
+const setOrderExternalID = `-- name: SetOrderExternalID :execrows
+UPDATE orders
+SET external_source = $2, external_order_id = $3
+WHERE id = $1
+`
+
+type SetOrderExternalIDParams struct {
+	ID              int32   `json:"id"`
+	ExternalSource  *string `json:"externalSource"`
+	ExternalOrderID *string `json:"externalOrderId"`
+}
+
+func (q *Queries) SetOrderExternalID(ctx context.Context, arg SetOrderExternalIDParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setOrderExternalID, arg.ID, arg.ExternalSource, arg.ExternalOrderID)
+	if err != nil {
+		return 0, err
 	}
-	return items, nil
+	return result.RowsAffected(), nil
+}
+
+const setOrderIdempotencyKeyOrder = `-- name: SetOrderIdempotencyKeyOrder :exec
+UPDATE order_idempotency_keys
+SET order_id = $2
+WHERE idempotency_key = $1
+`
+
+type SetOrderIdempotencyKeyOrderParams struct {
+	IdempotencyKey string `json:"idempotencyKey"`
+	OrderID        *int32 `json:"orderId"`
+}
+
+func (q *Queries) SetOrderIdempotencyKeyOrder(ctx context.Context, arg SetOrderIdempotencyKeyOrderParams) error {
+	_, err := q.db.Exec(ctx, setOrderIdempotencyKeyOrder, arg.IdempotencyKey, arg.OrderID)
+	return err
+}
+
+const setOrderPromotions = `-- name: SetOrderPromotions :exec
+UPDATE orders
+SET applied_promotions = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type SetOrderPromotionsParams struct {
+	ID                int32  `json:"id"`
+	AppliedPromotions []byte `json:"appliedPromotions"`
+}
+
+func (q *Queries) SetOrderPromotions(ctx context.Context, arg SetOrderPromotionsParams) error {
+	_, err := q.db.Exec(ctx, setOrderPromotions, arg.ID, arg.AppliedPromotions)
+	return err
+}
+
+const setOrderRefund = `-- name: SetOrderRefund :execrows
+UPDATE orders
+SET refund_id = $2, status = $3, updated_at = NOW()
+WHERE id = $1 AND updated_at = $4
+`
+
+type SetOrderRefundParams struct {
+	ID        int32              `json:"id"`
+	RefundID  *string            `json:"refundId"`
+	Status    OrderStatus        `json:"status"`
+	UpdatedAt pgtype.Timestamptz `json:"updatedAt"`
+}
+
+func (q *Queries) SetOrderRefund(ctx context.Context, arg SetOrderRefundParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setOrderRefund,
+		arg.ID,
+		arg.RefundID,
+		arg.Status,
+		arg.UpdatedAt,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const setOrderReportingSnapshot = `-- name: SetOrderReportingSnapshot :execrows
+UPDATE orders
+SET reporting_currency = $2, exchange_rate = $3, reporting_subtotal = $4, reporting_tax = $5, reporting_discount = $6, reporting_total = $7
+WHERE id = $1
+`
+
+type SetOrderReportingSnapshotParams struct {
+	ID                int32        `json:"id"`
+	ReportingCurrency NullCurrency `json:"reportingCurrency"`
+	ExchangeRate      *float64     `json:"exchangeRate"`
+	ReportingSubtotal *float64     `json:"reportingSubtotal"`
+	ReportingTax      *float64     `json:"reportingTax"`
+	ReportingDiscount *float64     `json:"reportingDiscount"`
+	ReportingTotal    *float64     `json:"reportingTotal"`
+}
+
+func (q *Queries) SetOrderReportingSnapshot(ctx context.Context, arg SetOrderReportingSnapshotParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setOrderReportingSnapshot,
+		arg.ID,
+		arg.ReportingCurrency,
+		arg.ExchangeRate,
+		arg.ReportingSubtotal,
+		arg.ReportingTax,
+		arg.ReportingDiscount,
+		arg.ReportingTotal,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const setOrderReturnLabel = `-- name: SetOrderReturnLabel :execrows
+UPDATE order_returns
+SET status = 'label_issued', carrier = $2, tracking_number = $3, label_url = $4, updated_at = NOW()
+WHERE id = $1 AND status = 'approved'
+`
+
+type SetOrderReturnLabelParams struct {
+	ID             int32   `json:"id"`
+	Carrier        *string `json:"carrier"`
+	TrackingNumber *string `json:"trackingNumber"`
+	LabelUrl       *string `json:"labelUrl"`
+}
+
+func (q *Queries) SetOrderReturnLabel(ctx context.Context, arg SetOrderReturnLabelParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setOrderReturnLabel,
+		arg.ID,
+		arg.Carrier,
+		arg.TrackingNumber,
+		arg.LabelUrl,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const setOrderTaxCalculation = `-- name: SetOrderTaxCalculation :execrows
+UPDATE orders
+SET tax = $2, total = subtotal + $2 - discount, tax_calculation_id = $3,
+    reporting_tax = ROUND($2 * exchange_rate, 2), reporting_total = ROUND((subtotal + $2 - discount) * exchange_rate, 2),
+    updated_at = NOW()
+WHERE id = $1
+`
+
+type SetOrderTaxCalculationParams struct {
+	ID               int32   `json:"id"`
+	Tax              float64 `json:"tax"`
+	TaxCalculationID *string `json:"taxCalculationId"`
+}
+
+func (q *Queries) SetOrderTaxCalculation(ctx context.Context, arg SetOrderTaxCalculationParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setOrderTaxCalculation, arg.ID, arg.Tax, arg.TaxCalculationID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const setOrderTaxExemption = `-- name: SetOrderTaxExemption :exec
+INSERT INTO order_tax_exemptions (order_id, certificate_id, certificate_number)
+VALUES ($1, $2, $3)
+ON CONFLICT (order_id) DO UPDATE
+SET certificate_id = EXCLUDED.certificate_id, certificate_number = EXCLUDED.certificate_number
+`
+
+type SetOrderTaxExemptionParams struct {
+	OrderID           int32  `json:"orderId"`
+	CertificateID     int32  `json:"certificateId"`
+	CertificateNumber string `json:"certificateNumber"`
+}
+
+func (q *Queries) SetOrderTaxExemption(ctx context.Context, arg SetOrderTaxExemptionParams) error {
+	_, err := q.db.Exec(ctx, setOrderTaxExemption, arg.OrderID, arg.CertificateID, arg.CertificateNumber)
+	return err
+}
+
+const setOrderTaxTransaction = `-- name: SetOrderTaxTransaction :execrows
+UPDATE orders
+SET tax_transaction_id = $2, updated_at = NOW()
+WHERE id = $1 AND tax_transaction_id IS NULL
+`
+
+type SetOrderTaxTransactionParams struct {
+	ID               int32   `json:"id"`
+	TaxTransactionID *string `json:"taxTransactionId"`
+}
+
+func (q *Queries) SetOrderTaxTransaction(ctx context.Context, arg SetOrderTaxTransactionParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setOrderTaxTransaction, arg.ID, arg.TaxTransactionID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const shipOrderItems = `-- name: ShipOrderItems :execrows
+UPDATE order_items
+SET shipped_quantity = quantity, updated_at = NOW()
+WHERE order_id = $1 AND shipped_quantity < quantity
+`
+
+func (q *Queries) ShipOrderItems(ctx context.Context, orderID int32) (int64, error) {
+	result, err := q.db.Exec(ctx, shipOrderItems, orderID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const shipOrderItemsAtLocation = `-- name: ShipOrderItemsAtLocation :execrows
+UPDATE order_items
+SET shipped_quantity = quantity, updated_at = NOW()
+WHERE order_id = $1 AND location = $2 AND shipped_quantity < quantity
+`
+
+type ShipOrderItemsAtLocationParams struct {
+	OrderID  int32   `json:"orderId"`
+	Location *string `json:"location"`
+}
+
+func (q *Queries) ShipOrderItemsAtLocation(ctx context.Context, arg ShipOrderItemsAtLocationParams) (int64, error) {
+	result, err := q.db.Exec(ctx, shipOrderItemsAtLocation, arg.OrderID, arg.Location)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const softDeleteOrder = `-- name: SoftDeleteOrder :execrows
+UPDATE orders
+SET deleted_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+`
+
+func (q *Queries) SoftDeleteOrder(ctx context.Context, id int32) (int64, error) {
+	result, err := q.db.Exec(ctx, softDeleteOrder, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const startOrderFulfillmentSLA = `-- name: StartOrderFulfillmentSLA :exec
+INSERT INTO order_fulfillment_slas (order_id, paid_at, warn_at, due_at, created_at)
+VALUES ($1, $2, $3, $4, NOW())
+ON CONFLICT (order_id) DO NOTHING
+`
+
+type StartOrderFulfillmentSLAParams struct {
+	OrderID int32              `json:"orderId"`
+	PaidAt  pgtype.Timestamptz `json:"paidAt"`
+	WarnAt  pgtype.Timestamptz `json:"warnAt"`
+	DueAt   pgtype.Timestamptz `json:"dueAt"`
+}
+
+func (q *Queries) StartOrderFulfillmentSLA(ctx context.Context, arg StartOrderFulfillmentSLAParams) error {
+	_, err := q.db.Exec(ctx, startOrderFulfillmentSLA,
+		arg.OrderID,
+		arg.PaidAt,
+		arg.WarnAt,
+		arg.DueAt,
+	)
+	return err
+}
+
+const updateOrderFulfillment = `-- name: UpdateOrderFulfillment :execrows
+UPDATE orders
+SET fulfillment_type = $2, pickup_location = $3, updated_at = NOW()
+WHERE id = $1 AND updated_at = $4
+`
+
+type UpdateOrderFulfillmentParams struct {
+	ID              int32              `json:"id"`
+	FulfillmentType FulfillmentType    `json:"fulfillmentType"`
+	PickupLocation  *string            `json:"pickupLocation"`
+	UpdatedAt       pgtype.Timestamptz `json:"updatedAt"`
+}
+
+func (q *Queries) UpdateOrderFulfillment(ctx context.Context, arg UpdateOrderFulfillmentParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateOrderFulfillment,
+		arg.ID,
+		arg.FulfillmentType,
+		arg.PickupLocation,
+		arg.UpdatedAt,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
 const updateOrderItem = `-- name: UpdateOrderItem :exec
 UPDATE order_items
-SET quantity = $2, unit_price = $3, subtotal = $4
+SET quantity = $2, unit_price = $3, subtotal = $4, tax_amount = $5
 WHERE id = $1
 `
 
@@ -477,6 +3693,7 @@ type UpdateOrderItemParams struct {
 	Quantity  uint64  `json:"quantity"`
 	UnitPrice float64 `json:"unitPrice"`
 	Subtotal  float64 `json:"subtotal"`
+	TaxAmount float64 `json:"taxAmount"`
 }
 
 func (q *Queries) UpdateOrderItem(ctx context.Context, arg UpdateOrderItemParams) error {
@@ -485,11 +3702,31 @@ func (q *Queries) UpdateOrderItem(ctx context.Context, arg UpdateOrderItemParams
 		arg.Quantity,
 		arg.UnitPrice,
 		arg.Subtotal,
+		arg.TaxAmount,
 	)
 	return err
 }
 
-const updateOrderStatus = `-- name: UpdateOrderStatus :exec
+const updateOrderReturnStatus = `-- name: UpdateOrderReturnStatus :execrows
+UPDATE order_returns
+SET status = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type UpdateOrderReturnStatusParams struct {
+	ID     int32        `json:"id"`
+	Status ReturnStatus `json:"status"`
+}
+
+func (q *Queries) UpdateOrderReturnStatus(ctx context.Context, arg UpdateOrderReturnStatusParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateOrderReturnStatus, arg.ID, arg.Status)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updateOrderStatus = `-- name: UpdateOrderStatus :execrows
 UPDATE orders
 SET status = $2, updated_at = NOW()
 WHERE id = $1 AND updated_at = $3
@@ -501,14 +3738,20 @@ type UpdateOrderStatusParams struct {
 	UpdatedAt pgtype.Timestamptz `json:"updatedAt"`
 }
 
-func (q *Queries) UpdateOrderStatus(ctx context.Context, arg UpdateOrderStatusParams) error {
-	_, err := q.db.Exec(ctx, updateOrderStatus, arg.ID, arg.Status, arg.UpdatedAt)
-	return err
+func (q *Queries) UpdateOrderStatus(ctx context.Context, arg UpdateOrderStatusParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateOrderStatus, arg.ID, arg.Status, arg.UpdatedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
-const updateOrderTotals = `-- name: UpdateOrderTotals :exec
+const updateOrderTotals = `-- name: UpdateOrderTotals :execrows
 UPDATE orders
-SET subtotal = $2, tax = $3, discount = $4, total = $5, updated_at = NOW()
+SET subtotal = $2, tax = $3, discount = $4, total = $5,
+    reporting_subtotal = ROUND($2 * exchange_rate, 2), reporting_tax = ROUND($3 * exchange_rate, 2),
+    reporting_discount = ROUND($4 * exchange_rate, 2), reporting_total = ROUND($5 * exchange_rate, 2),
+    updated_at = NOW()
 WHERE id = $1 AND updated_at = $6
 `
 
@@ -521,8 +3764,8 @@ type UpdateOrderTotalsParams struct {
 	UpdatedAt pgtype.Timestamptz `json:"updatedAt"`
 }
 
-func (q *Queries) UpdateOrderTotals(ctx context.Context, arg UpdateOrderTotalsParams) error {
-	_, err := q.db.Exec(ctx, updateOrderTotals,
+func (q *Queries) UpdateOrderTotals(ctx context.Context, arg UpdateOrderTotalsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateOrderTotals,
 		arg.ID,
 		arg.Subtotal,
 		arg.Tax,
@@ -530,5 +3773,48 @@ func (q *Queries) UpdateOrderTotals(ctx context.Context, arg UpdateOrderTotalsPa
 		arg.Total,
 		arg.UpdatedAt,
 	)
-	return err
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const upsertOrderReceiptLink = `-- name: UpsertOrderReceiptLink :one
+INSERT INTO order_receipt_links (order_id, payment_intent_id, invoice_id, receipt_url, invoice_url, invoice_pdf_url, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, NOW())
+ON CONFLICT (order_id) DO UPDATE
+SET payment_intent_id = EXCLUDED.payment_intent_id, invoice_id = EXCLUDED.invoice_id, receipt_url = EXCLUDED.receipt_url,
+    invoice_url = EXCLUDED.invoice_url, invoice_pdf_url = EXCLUDED.invoice_pdf_url, updated_at = NOW()
+RETURNING order_id, payment_intent_id, invoice_id, receipt_url, invoice_url, invoice_pdf_url, updated_at
+`
+
+type UpsertOrderReceiptLinkParams struct {
+	OrderID         int32   `json:"orderId"`
+	PaymentIntentID *string `json:"paymentIntentId"`
+	InvoiceID       *string `json:"invoiceId"`
+	ReceiptUrl      *string `json:"receiptUrl"`
+	InvoiceUrl      *string `json:"invoiceUrl"`
+	InvoicePdfUrl   *string `json:"invoicePdfUrl"`
+}
+
+func (q *Queries) UpsertOrderReceiptLink(ctx context.Context, arg UpsertOrderReceiptLinkParams) (*OrderReceiptLink, error) {
+	row := q.db.QueryRow(ctx, upsertOrderReceiptLink,
+		arg.OrderID,
+		arg.PaymentIntentID,
+		arg.InvoiceID,
+		arg.ReceiptUrl,
+		arg.InvoiceUrl,
+		arg.InvoicePdfUrl,
+	)
+	var i OrderReceiptLink
+	err := row.Scan(
+		&i.OrderID,
+		&i.PaymentIntentID,
+		&i.InvoiceID,
+		&i.ReceiptUrl,
+		&i.InvoiceUrl,
+		&i.InvoicePdfUrl,
+		&i.UpdatedAt,
+	)
+	return &i, err
 }
@@ -6,56 +6,298 @@ package sqlc
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type Querier interface {
-	AddCartItem(ctx context.Context, arg AddCartItemParams) error
+	AddCartItem(ctx context.Context, arg AddCartItemParams) (int32, error)
+	AddOrderItemReturnedQuantity(ctx context.Context, arg AddOrderItemReturnedQuantityParams) (int64, error)
 	AddOrderItems(ctx context.Context, arg []AddOrderItemsParams) *AddOrderItemsBatchResults
+	AddRefundItems(ctx context.Context, arg []AddRefundItemsParams) *AddRefundItemsBatchResults
+	AddStocktakeScan(ctx context.Context, arg AddStocktakeScanParams) (int32, error)
 	AdjustStock(ctx context.Context, arg []AdjustStockParams) *AdjustStockBatchResults
+	ArchiveOrders(ctx context.Context, arg ArchiveOrdersParams) (int64, error)
+	AssignCartExperiment(ctx context.Context, arg AssignCartExperimentParams) (int64, error)
 	AssignProductToCategory(ctx context.Context, arg AssignProductToCategoryParams) error
+	AttachExperimentAssignmentsToOrder(ctx context.Context, arg AttachExperimentAssignmentsToOrderParams) error
+	CancelPriceChange(ctx context.Context, id int32) (int64, error)
+	ClaimOrderIdempotencyKey(ctx context.Context, arg ClaimOrderIdempotencyKeyParams) (int64, error)
+	ClearCartAddresses(ctx context.Context, id int32) (int64, error)
 	ClearCartItems(ctx context.Context, cartID uint64) error
-	CreateCart(ctx context.Context, arg CreateCartParams) error
-	CreateCategory(ctx context.Context, arg CreateCategoryParams) error
+	ClearDefaultCart(ctx context.Context, customerID string) ([]int32, error)
+	CompleteOrderFulfillmentSLA(ctx context.Context, arg CompleteOrderFulfillmentSLAParams) (int64, error)
+	CountCategories(ctx context.Context) (int64, error)
+	CountFingerprintCustomers(ctx context.Context, arg CountFingerprintCustomersParams) (int64, error)
+	CountOrders(ctx context.Context, customerID string) (int64, error)
+	CountOrdersByStatus(ctx context.Context, arg CountOrdersByStatusParams) (int64, error)
+	CountSearchOrders(ctx context.Context, pattern string) (int64, error)
+	CountSearchProducts(ctx context.Context, arg CountSearchProductsParams) (int64, error)
+	CountStockMovements(ctx context.Context, stockID uint64) (int64, error)
+	CreateCampaignRedemptions(ctx context.Context, arg []CreateCampaignRedemptionsParams) *CreateCampaignRedemptionsBatchResults
+	CreateCart(ctx context.Context, arg CreateCartParams) (*CreateCartRow, error)
+	CreateCategory(ctx context.Context, arg CreateCategoryParams) (*CreateCategoryRow, error)
+	CreateCoupon(ctx context.Context, arg CreateCouponParams) (*Coupon, error)
+	CreateCouponRedemption(ctx context.Context, arg CreateCouponRedemptionParams) error
+	CreateDiscountCampaign(ctx context.Context, arg CreateDiscountCampaignParams) (*DiscountCampaign, error)
 	CreateEvent(ctx context.Context, arg CreateEventParams) error
+	CreateFraudReview(ctx context.Context, arg CreateFraudReviewParams) (int64, error)
+	CreateInvoice(ctx context.Context, arg CreateInvoiceParams) (*Invoice, error)
+	CreateManualStockMovement(ctx context.Context, arg CreateManualStockMovementParams) (*StockMovement, error)
 	CreateOrder(ctx context.Context, arg CreateOrderParams) (*CreateOrderRow, error)
+	CreateOrderAddon(ctx context.Context, arg CreateOrderAddonParams) (*OrderAddon, error)
+	CreateOrderCancellationRequest(ctx context.Context, arg CreateOrderCancellationRequestParams) (*OrderCancellationRequest, error)
+	CreateOrderHold(ctx context.Context, arg CreateOrderHoldParams) (*OrderHold, error)
+	CreateOrderRepricing(ctx context.Context, arg CreateOrderRepricingParams) (*OrderRepricing, error)
+	CreateOrderReturn(ctx context.Context, arg CreateOrderReturnParams) (*OrderReturn, error)
+	CreatePriceChange(ctx context.Context, arg CreatePriceChangeParams) (*PriceChange, error)
+	CreateProductBarcode(ctx context.Context, arg CreateProductBarcodeParams) error
+	CreateProductMedia(ctx context.Context, arg CreateProductMediaParams) (*ProductMedium, error)
+	CreateRefund(ctx context.Context, arg CreateRefundParams) (*Refund, error)
+	CreateShipment(ctx context.Context, arg CreateShipmentParams) (*Shipment, error)
+	CreateStock(ctx context.Context, arg CreateStockParams) (*Stock, error)
+	CreateStockAdjustment(ctx context.Context, arg CreateStockAdjustmentParams) (*StockAdjustment, error)
+	CreateStockHold(ctx context.Context, arg CreateStockHoldParams) (*StockHold, error)
 	CreateStockMovement(ctx context.Context, arg []CreateStockMovementParams) *CreateStockMovementBatchResults
+	CreateStockMovementReversal(ctx context.Context, arg CreateStockMovementReversalParams) (*StockMovement, error)
+	CreateStockRental(ctx context.Context, arg CreateStockRentalParams) (*StockRental, error)
+	CreateStockTransfer(ctx context.Context, arg CreateStockTransferParams) (*StockTransfer, error)
+	CreateStocktake(ctx context.Context, arg CreateStocktakeParams) (*Stocktake, error)
+	CreateStore(ctx context.Context, arg CreateStoreParams) (*Store, error)
+	CreateTaxExemptionCertificate(ctx context.Context, arg CreateTaxExemptionCertificateParams) (*TaxExemptionCertificate, error)
+	DeleteCartCoupon(ctx context.Context, cartID uint64) (int64, error)
 	DeleteCategory(ctx context.Context, id int32) error
-	DeleteOrder(ctx context.Context, id int32) error
+	DeleteCategoryTranslation(ctx context.Context, arg DeleteCategoryTranslationParams) (int64, error)
+	DeleteChannelPrice(ctx context.Context, arg DeleteChannelPriceParams) (int64, error)
+	DeleteChannelProduct(ctx context.Context, arg DeleteChannelProductParams) (int64, error)
+	DeleteOrderAddon(ctx context.Context, arg DeleteOrderAddonParams) (*OrderAddon, error)
 	DeleteOrderItem(ctx context.Context, id int32) error
+	DeleteParkedEvent(ctx context.Context, eventID string) (int64, error)
+	DeleteProductMedia(ctx context.Context, id int32) (*ProductMedium, error)
+	DeleteProductTranslation(ctx context.Context, arg DeleteProductTranslationParams) (int64, error)
+	DeleteSavedItem(ctx context.Context, arg DeleteSavedItemParams) (int64, error)
+	DeleteStockProjection(ctx context.Context, stockID uint64) (int64, error)
+	EnableStockEventSourcing(ctx context.Context, id int32) (*StockProjection, error)
+	ExtendCartExpiry(ctx context.Context, arg ExtendCartExpiryParams) (int64, error)
 	FindActiveCartByCustomerID(ctx context.Context, customerID string) (*FindActiveCartByCustomerIDRow, error)
+	FindActiveCartByName(ctx context.Context, arg FindActiveCartByNameParams) (*FindActiveCartByNameRow, error)
 	FindCartItemByProductID(ctx context.Context, arg FindCartItemByProductIDParams) (*CartItem, error)
+	FindOrdersByMetadata(ctx context.Context, arg FindOrdersByMetadataParams) ([]*FindOrdersByMetadataRow, error)
+	FindStoresWithinRadius(ctx context.Context, arg FindStoresWithinRadiusParams) ([]*FindStoresWithinRadiusRow, error)
+	FinishStocktake(ctx context.Context, arg FinishStocktakeParams) (int64, error)
+	GetActiveOrderHold(ctx context.Context, orderID int32) (*OrderHold, error)
+	GetActiveTaxExemptionCertificate(ctx context.Context, arg GetActiveTaxExemptionCertificateParams) (*TaxExemptionCertificate, error)
+	GetArchivedOrder(ctx context.Context, id int32) (*GetArchivedOrderRow, error)
+	GetCampaignReport(ctx context.Context, campaignID int32) (*GetCampaignReportRow, error)
+	GetCancellationReasonStats(ctx context.Context, arg GetCancellationReasonStatsParams) ([]*GetCancellationReasonStatsRow, error)
 	GetCart(ctx context.Context, id int32) (*GetCartRow, error)
+	GetCartAddresses(ctx context.Context, id int32) (*GetCartAddressesRow, error)
+	GetCartCoupon(ctx context.Context, cartID uint64) (*Coupon, error)
 	GetCartItem(ctx context.Context, id int32) (*CartItem, error)
+	GetCatalogSnapshot(ctx context.Context, productIds []string) ([]*GetCatalogSnapshotRow, error)
 	GetCategoryByID(ctx context.Context, id int32) (*Category, error)
-	GetEventByID(ctx context.Context, id string) (*Event, error)
+	GetChannelPrice(ctx context.Context, arg GetChannelPriceParams) (*ChannelPrice, error)
+	GetChannelProduct(ctx context.Context, arg GetChannelProductParams) (*ChannelProduct, error)
+	GetChannelSalesReport(ctx context.Context, arg GetChannelSalesReportParams) ([]*GetChannelSalesReportRow, error)
+	GetCouponByCode(ctx context.Context, code string) (*Coupon, error)
+	GetCustomerNotificationPreference(ctx context.Context, customerID string) (*CustomerNotificationPreference, error)
+	GetDiscountCampaign(ctx context.Context, id int32) (*DiscountCampaign, error)
+	GetEventBacklog(ctx context.Context, before pgtype.Timestamptz) (*GetEventBacklogRow, error)
+	GetEventByID(ctx context.Context, id string) (*GetEventByIDRow, error)
+	GetEventPayload(ctx context.Context, id string) (*GetEventPayloadRow, error)
+	GetFraudReview(ctx context.Context, id int32) (*FraudReview, error)
+	GetInvoice(ctx context.Context, id int32) (*Invoice, error)
+	GetLastStripeObjectEventCreated(ctx context.Context, objectID string) (pgtype.Timestamptz, error)
 	GetOrder(ctx context.Context, id int32) (*GetOrderRow, error)
 	GetOrderByCustomerIDAndSubscriptionID(ctx context.Context, arg GetOrderByCustomerIDAndSubscriptionIDParams) (*GetOrderByCustomerIDAndSubscriptionIDRow, error)
 	GetOrderByInvoiceID(ctx context.Context, invoiceID *string) (*GetOrderByInvoiceIDRow, error)
 	GetOrderByPaymentIntentID(ctx context.Context, paymentIntentID *string) (*GetOrderByPaymentIntentIDRow, error)
 	GetOrderByRefundID(ctx context.Context, refundID *string) (*GetOrderByRefundIDRow, error)
+	GetOrderCancellationRequestForUpdate(ctx context.Context, id int32) (*OrderCancellationRequest, error)
+	GetOrderForUpdate(ctx context.Context, id int32) (*Order, error)
+	GetOrderFulfillmentSLA(ctx context.Context, orderID int32) (*OrderFulfillmentSla, error)
+	GetOrderIDByExternalID(ctx context.Context, arg GetOrderIDByExternalIDParams) (int32, error)
+	GetOrderIDByNumber(ctx context.Context, orderNumber string) (int32, error)
+	GetOrderIdempotencyKey(ctx context.Context, idempotencyKey string) (*OrderIdempotencyKey, error)
+	GetOrderInvoice(ctx context.Context, orderID int32) (*Invoice, error)
 	GetOrderItem(ctx context.Context, id int32) (*GetOrderItemRow, error)
+	GetOrderPaymentReference(ctx context.Context, id int32) (*GetOrderPaymentReferenceRow, error)
+	GetOrderReceiptLink(ctx context.Context, orderID int32) (*OrderReceiptLink, error)
+	GetOrderRepricingForUpdate(ctx context.Context, id int32) (*OrderRepricing, error)
+	GetOrderReturn(ctx context.Context, id int32) (*OrderReturn, error)
+	GetOrderReturnForUpdate(ctx context.Context, id int32) (*OrderReturn, error)
+	GetOrderReturnIDByTracking(ctx context.Context, arg GetOrderReturnIDByTrackingParams) (int32, error)
+	GetOrderTaxExemptionNumber(ctx context.Context, orderID int32) (string, error)
+	GetPriceInEffect(ctx context.Context, arg GetPriceInEffectParams) (*PriceChange, error)
+	GetProductMedia(ctx context.Context, id int32) (*ProductMedium, error)
+	GetSalesReport(ctx context.Context, arg GetSalesReportParams) ([]*GetSalesReportRow, error)
+	GetSavedItem(ctx context.Context, id int32) (*SavedItem, error)
+	GetShipment(ctx context.Context, id int32) (*Shipment, error)
 	GetStock(ctx context.Context, id int32) (*Stock, error)
+	GetStockAdjustment(ctx context.Context, id int32) (*StockAdjustment, error)
+	GetStockByProductAndLocation(ctx context.Context, arg GetStockByProductAndLocationParams) (*Stock, error)
+	GetStockHold(ctx context.Context, id int32) (*StockHold, error)
+	GetStockLevelAt(ctx context.Context, arg GetStockLevelAtParams) (*GetStockLevelAtRow, error)
+	GetStockMovementForUpdate(ctx context.Context, id int32) (*StockMovement, error)
 	GetStockMovementsByReference(ctx context.Context, arg GetStockMovementsByReferenceParams) ([]*StockMovement, error)
+	GetStockProjection(ctx context.Context, stockID uint64) (*StockProjection, error)
+	GetStockRental(ctx context.Context, id int32) (*StockRental, error)
+	GetStockTransfer(ctx context.Context, id int32) (*StockTransfer, error)
+	GetStocktake(ctx context.Context, id int32) (*Stocktake, error)
+	GetUnprojectedStockDelta(ctx context.Context, stockID uint64) (*GetUnprojectedStockDeltaRow, error)
+	HasStripeObjectEvent(ctx context.Context, arg HasStripeObjectEventParams) (bool, error)
+	IncrementCouponRedemptions(ctx context.Context, id int32) (int64, error)
+	ListActiveCampaignsForProducts(ctx context.Context, arg ListActiveCampaignsForProductsParams) ([]*ListActiveCampaignsForProductsRow, error)
+	ListActiveCartsByCustomerID(ctx context.Context, customerID string) ([]*ListActiveCartsByCustomerIDRow, error)
+	ListAllCategories(ctx context.Context) ([]*Category, error)
+	ListArchivedOrderItems(ctx context.Context, orderID int32) ([]*ListArchivedOrderItemsRow, error)
+	ListCartExperimentAssignments(ctx context.Context, cartID uint64) ([]*ExperimentAssignment, error)
 	ListCartItems(ctx context.Context, cartID uint64) ([]*CartItem, error)
+	ListCartTotalMismatches(ctx context.Context) ([]*ListCartTotalMismatchesRow, error)
 	ListCategories(ctx context.Context, arg ListCategoriesParams) ([]*Category, error)
+	ListCategoryTranslations(ctx context.Context, arg ListCategoryTranslationsParams) ([]*CategoryTranslation, error)
+	ListChannelPrices(ctx context.Context, channel string) ([]*ChannelPrice, error)
+	ListChannelProducts(ctx context.Context, channel string) ([]*ChannelProduct, error)
+	ListConvertedCartsWithoutOrder(ctx context.Context) ([]*ListConvertedCartsWithoutOrderRow, error)
+	ListCustomerOrderStats(ctx context.Context, since pgtype.Timestamptz) ([]*ListCustomerOrderStatsRow, error)
+	ListDiscountCampaigns(ctx context.Context, arg ListDiscountCampaignsParams) ([]*DiscountCampaign, error)
+	ListDuePriceChanges(ctx context.Context, effectiveAt pgtype.Timestamptz) ([]*PriceChange, error)
+	ListExpiredActiveCartIDs(ctx context.Context, arg ListExpiredActiveCartIDsParams) ([]int32, error)
+	ListExpiredParkedEvents(ctx context.Context, arg ListExpiredParkedEventsParams) ([]*ParkedEvent, error)
+	ListExpiredStockHolds(ctx context.Context, expiresAt pgtype.Timestamptz) ([]*StockHold, error)
+	ListFraudReviewsByStatus(ctx context.Context, status FraudReviewStatus) ([]*FraudReview, error)
+	ListInactiveCartIDs(ctx context.Context, arg ListInactiveCartIDsParams) ([]int32, error)
+	ListInvoiceNumberGaps(ctx context.Context) ([]*ListInvoiceNumberGapsRow, error)
+	ListInvoicesByOrderID(ctx context.Context, orderID int32) ([]*Invoice, error)
+	ListLowStocks(ctx context.Context, arg ListLowStocksParams) ([]*Stock, error)
+	ListOrderAddons(ctx context.Context, orderID int32) ([]*OrderAddon, error)
+	ListOrderExperimentAssignments(ctx context.Context, orderID *int32) ([]*ExperimentAssignment, error)
+	ListOrderHolds(ctx context.Context, orderID int32) ([]*OrderHold, error)
 	ListOrderItems(ctx context.Context, orderID int32) ([]*ListOrderItemsRow, error)
+	ListOrderRepricings(ctx context.Context, orderID int32) ([]*OrderRepricing, error)
+	ListOrderReturns(ctx context.Context, orderID int32) ([]*OrderReturn, error)
+	ListOrderTotalMismatches(ctx context.Context) ([]*ListOrderTotalMismatchesRow, error)
 	ListOrders(ctx context.Context, arg ListOrdersParams) ([]*ListOrdersRow, error)
+	ListOrdersAtRiskOfSLABreach(ctx context.Context, arg ListOrdersAtRiskOfSLABreachParams) ([]*ListOrdersAtRiskOfSLABreachRow, error)
+	ListOrdersByFilter(ctx context.Context, arg ListOrdersByFilterParams) ([]*Order, error)
 	ListOrdersByStatus(ctx context.Context, arg ListOrdersByStatusParams) ([]*ListOrdersByStatusRow, error)
+	ListOrdersPendingTaxCommit(ctx context.Context, arg ListOrdersPendingTaxCommitParams) ([]*ListOrdersPendingTaxCommitRow, error)
+	ListOrphanedCartItems(ctx context.Context) ([]*ListOrphanedCartItemsRow, error)
+	ListOrphanedOrderItems(ctx context.Context) ([]*ListOrphanedOrderItemsRow, error)
+	ListOverReservedStocks(ctx context.Context) ([]*Stock, error)
+	ListParkedEventsByObject(ctx context.Context, arg ListParkedEventsByObjectParams) ([]*ParkedEvent, error)
+	ListPendingOrderCancellationRequests(ctx context.Context) ([]*OrderCancellationRequest, error)
+	ListPendingOrderIDsByPrice(ctx context.Context, arg ListPendingOrderIDsByPriceParams) ([]int32, error)
+	ListPendingOrderRepricings(ctx context.Context) ([]*OrderRepricing, error)
+	ListPriceChanges(ctx context.Context, priceID string) ([]*PriceChange, error)
+	ListProductAvailability(ctx context.Context, productIds []string) ([]*ProductAvailability, error)
+	ListProductBarcodes(ctx context.Context, barcodes []string) ([]*ProductBarcode, error)
+	ListProductMedia(ctx context.Context, arg ListProductMediaParams) ([]*ProductMedium, error)
+	ListProductTranslations(ctx context.Context, arg ListProductTranslationsParams) ([]*ProductTranslation, error)
+	ListProductTranslationsAfter(ctx context.Context, arg ListProductTranslationsAfterParams) ([]*ProductTranslation, error)
+	ListProductsInCategories(ctx context.Context, arg ListProductsInCategoriesParams) ([]string, error)
+	ListRefundItemsByOrderID(ctx context.Context, orderID int32) ([]*RefundItem, error)
+	ListRefundsByOrderID(ctx context.Context, orderID int32) ([]*Refund, error)
+	ListRentalBookings(ctx context.Context, arg ListRentalBookingsParams) ([]*ListRentalBookingsRow, error)
+	ListRevenueEvents(ctx context.Context, arg ListRevenueEventsParams) ([]*ListRevenueEventsRow, error)
+	ListSavedItems(ctx context.Context, customerID string) ([]*SavedItem, error)
+	ListShipmentsByOrderID(ctx context.Context, orderID int32) ([]*Shipment, error)
+	ListStockAdjustmentsByStatus(ctx context.Context, status StockAdjustmentStatus) ([]*StockAdjustment, error)
+	ListStockHolds(ctx context.Context, stockID uint64) ([]*StockHold, error)
 	ListStockMovements(ctx context.Context, arg ListStockMovementsParams) ([]*StockMovement, error)
+	ListStockOutflows(ctx context.Context, since pgtype.Timestamptz) ([]*ListStockOutflowsRow, error)
+	ListStockRentals(ctx context.Context, arg ListStockRentalsParams) ([]*StockRental, error)
+	ListStockTransfersByStatus(ctx context.Context, status StockTransferStatus) ([]*StockTransfer, error)
+	ListStocksByProductID(ctx context.Context, productID string) ([]*Stock, error)
+	ListStocksPendingProjection(ctx context.Context, limit int32) ([]uint64, error)
+	ListStocktakeCounts(ctx context.Context, stocktakeID int32) ([]*ListStocktakeCountsRow, error)
+	ListStores(ctx context.Context) ([]*Store, error)
 	ListSubcategories(ctx context.Context, parentID *int32) ([]*Category, error)
+	ListTaxExemptionCertificates(ctx context.Context, customerID string) ([]*TaxExemptionCertificate, error)
+	ListTrackedOrderReturns(ctx context.Context, arg ListTrackedOrderReturnsParams) ([]*OrderReturn, error)
+	ListUnshippedOrderIDsByProduct(ctx context.Context, arg ListUnshippedOrderIDsByProductParams) ([]int32, error)
+	LockStock(ctx context.Context, id int32) (*Stock, error)
+	MarkCartAbandonedNotified(ctx context.Context, arg MarkCartAbandonedNotifiedParams) (int64, error)
 	MarkEventAsProcessed(ctx context.Context, arg MarkEventAsProcessedParams) error
+	MarkFulfillmentSLAAtRiskAlerted(ctx context.Context, orderID int32) (int64, error)
+	MarkFulfillmentSLABreachAlerted(ctx context.Context, orderID int32) (int64, error)
+	MarkPriceChangeApplied(ctx context.Context, id int32) (string, error)
+	MergeOrderMetadata(ctx context.Context, arg MergeOrderMetadataParams) (int64, error)
+	NextInvoiceSequenceNumber(ctx context.Context, arg NextInvoiceSequenceNumberParams) (int64, error)
+	NotifyCacheInvalidation(ctx context.Context, arg NotifyCacheInvalidationParams) error
+	ParkEvent(ctx context.Context, arg ParkEventParams) error
+	ProjectStock(ctx context.Context, stockID uint64) (int64, error)
+	PurgeDeletedOrders(ctx context.Context, arg PurgeDeletedOrdersParams) ([]int32, error)
+	PurgeEventPayloads(ctx context.Context, createdAt pgtype.Timestamptz) (int64, error)
+	ReactivateCart(ctx context.Context, arg ReactivateCartParams) (int64, error)
+	RebuildStockProjection(ctx context.Context, stockID uint64) (int64, error)
+	RecordPaymentFingerprint(ctx context.Context, arg RecordPaymentFingerprintParams) error
+	RecordStripeObjectEvent(ctx context.Context, arg RecordStripeObjectEventParams) error
 	ReduceStock(ctx context.Context, arg []ReduceStockParams) *ReduceStockBatchResults
+	RefreshProductAvailability(ctx context.Context) error
+	ReleaseOrderHold(ctx context.Context, id int32) (int64, error)
 	ReleaseStock(ctx context.Context, arg []ReleaseStockParams) *ReleaseStockBatchResults
+	ReleaseStockHold(ctx context.Context, id int32) (int64, error)
 	RemoveCartItem(ctx context.Context, id int32) error
 	RemoveProductFromCategory(ctx context.Context, arg RemoveProductFromCategoryParams) error
+	ResolveFraudReview(ctx context.Context, arg ResolveFraudReviewParams) (int64, error)
+	ResolveOrderCancellationRequest(ctx context.Context, arg ResolveOrderCancellationRequestParams) (int64, error)
+	ResolveOrderRepricing(ctx context.Context, arg ResolveOrderRepricingParams) (int64, error)
+	RestoreOrder(ctx context.Context, id int32) (int64, error)
+	RestoreReservedStock(ctx context.Context, arg []RestoreReservedStockParams) *RestoreReservedStockBatchResults
+	ReturnShippedOrderItems(ctx context.Context, orderID int32) (int64, error)
+	ReviewStockAdjustment(ctx context.Context, arg ReviewStockAdjustmentParams) (int64, error)
+	ReviewStockTransfer(ctx context.Context, arg ReviewStockTransferParams) (int64, error)
+	RevokeCartRecoveryCoupons(ctx context.Context, recoveryCartID *int32) (int64, error)
+	RevokeTaxExemptionCertificate(ctx context.Context, arg RevokeTaxExemptionCertificateParams) (int64, error)
+	SaveItem(ctx context.Context, arg SaveItemParams) (*SavedItem, error)
+	SearchOrderIDs(ctx context.Context, arg SearchOrderIDsParams) ([]int32, error)
+	SearchProductIDs(ctx context.Context, arg SearchProductIDsParams) ([]string, error)
+	SetCartAddresses(ctx context.Context, arg SetCartAddressesParams) (int64, error)
+	SetCartChannel(ctx context.Context, arg SetCartChannelParams) (int64, error)
+	SetCartCoupon(ctx context.Context, arg SetCartCouponParams) error
+	SetCartPromotions(ctx context.Context, arg SetCartPromotionsParams) error
+	SetDefaultCart(ctx context.Context, arg SetDefaultCartParams) (int64, error)
+	SetInvoiceDocument(ctx context.Context, arg SetInvoiceDocumentParams) (int64, error)
+	SetOrderCartSnapshot(ctx context.Context, arg SetOrderCartSnapshotParams) (int64, error)
+	SetOrderChannel(ctx context.Context, arg SetOrderChannelParams) error
+	SetOrderDeliveryEstimate(ctx context.Context, arg SetOrderDeliveryEstimateParams) (int64, error)
+	SetOrderExternalID(ctx context.Context, arg SetOrderExternalIDParams) (int64, error)
+	SetOrderIdempotencyKeyOrder(ctx context.Context, arg SetOrderIdempotencyKeyOrderParams) error
+	SetOrderPromotions(ctx context.Context, arg SetOrderPromotionsParams) error
+	SetOrderRefund(ctx context.Context, arg SetOrderRefundParams) (int64, error)
+	SetOrderReportingSnapshot(ctx context.Context, arg SetOrderReportingSnapshotParams) (int64, error)
+	SetOrderReturnLabel(ctx context.Context, arg SetOrderReturnLabelParams) (int64, error)
+	SetOrderTaxCalculation(ctx context.Context, arg SetOrderTaxCalculationParams) (int64, error)
+	SetOrderTaxExemption(ctx context.Context, arg SetOrderTaxExemptionParams) error
+	SetOrderTaxTransaction(ctx context.Context, arg SetOrderTaxTransactionParams) (int64, error)
+	SetStockRentalEnabled(ctx context.Context, arg SetStockRentalEnabledParams) (int64, error)
+	ShipOrderItems(ctx context.Context, orderID int32) (int64, error)
+	ShipOrderItemsAtLocation(ctx context.Context, arg ShipOrderItemsAtLocationParams) (int64, error)
+	SoftDeleteOrder(ctx context.Context, id int32) (int64, error)
+	StartOrderFulfillmentSLA(ctx context.Context, arg StartOrderFulfillmentSLAParams) error
 	UpdateCartItem(ctx context.Context, arg UpdateCartItemParams) error
+	UpdateCartItemMetadata(ctx context.Context, arg UpdateCartItemMetadataParams) (int64, error)
 	UpdateCartItemQuantity(ctx context.Context, arg UpdateCartItemQuantityParams) error
+	UpdateCartItemTax(ctx context.Context, arg UpdateCartItemTaxParams) error
 	UpdateCartStatus(ctx context.Context, arg UpdateCartStatusParams) error
 	UpdateCartTotals(ctx context.Context, arg UpdateCartTotalsParams) error
-	UpdateCategory(ctx context.Context, arg UpdateCategoryParams) error
+	UpdateCategory(ctx context.Context, arg UpdateCategoryParams) (int64, error)
+	UpdateOrderFulfillment(ctx context.Context, arg UpdateOrderFulfillmentParams) (int64, error)
 	UpdateOrderItem(ctx context.Context, arg UpdateOrderItemParams) error
-	UpdateOrderStatus(ctx context.Context, arg UpdateOrderStatusParams) error
-	UpdateOrderTotals(ctx context.Context, arg UpdateOrderTotalsParams) error
+	UpdateOrderReturnStatus(ctx context.Context, arg UpdateOrderReturnStatusParams) (int64, error)
+	UpdateOrderStatus(ctx context.Context, arg UpdateOrderStatusParams) (int64, error)
+	UpdateOrderTotals(ctx context.Context, arg UpdateOrderTotalsParams) (int64, error)
+	UpdateProductMedia(ctx context.Context, arg UpdateProductMediaParams) (*ProductMedium, error)
+	UpdateStockQuantity(ctx context.Context, arg UpdateStockQuantityParams) (int64, error)
+	UpdateStockRentalStatus(ctx context.Context, arg UpdateStockRentalStatusParams) (int64, error)
+	UpdateStore(ctx context.Context, arg UpdateStoreParams) (int64, error)
+	UpsertCategoryTranslation(ctx context.Context, arg UpsertCategoryTranslationParams) (*CategoryTranslation, error)
+	UpsertChannelPrice(ctx context.Context, arg UpsertChannelPriceParams) error
+	UpsertChannelProduct(ctx context.Context, arg UpsertChannelProductParams) error
+	UpsertCustomerNotificationPreference(ctx context.Context, arg UpsertCustomerNotificationPreferenceParams) (*CustomerNotificationPreference, error)
+	UpsertOrderReceiptLink(ctx context.Context, arg UpsertOrderReceiptLinkParams) (*OrderReceiptLink, error)
+	UpsertProductTranslation(ctx context.Context, arg UpsertProductTranslationParams) (*ProductTranslation, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -0,0 +1,2 @@
+-- name: NotifyCacheInvalidation :exec
+SELECT pg_notify(sqlc.arg(channel)::text, sqlc.arg(payload)::text);
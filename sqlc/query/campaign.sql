@@ -0,0 +1,50 @@
+-- name: CreateDiscountCampaign :one
+INSERT INTO discount_campaigns (name, category_id, include_subcategories, percent_off, starts_at, ends_at, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
+RETURNING id, name, category_id, include_subcategories, percent_off, starts_at, ends_at, created_at, updated_at;
+
+-- name: GetDiscountCampaign :one
+SELECT id, name, category_id, include_subcategories, percent_off, starts_at, ends_at, created_at, updated_at
+FROM discount_campaigns
+WHERE id = $1;
+
+-- name: ListDiscountCampaigns :many
+SELECT id, name, category_id, include_subcategories, percent_off, starts_at, ends_at, created_at, updated_at
+FROM discount_campaigns
+ORDER BY starts_at DESC
+LIMIT $1 OFFSET $2;
+
+-- name: ListActiveCampaignsForProducts :many
+WITH RECURSIVE campaign_categories AS (
+    SELECT dc.id AS campaign_id, dc.category_id
+    FROM discount_campaigns dc
+    WHERE dc.starts_at <= sqlc.arg(at) AND dc.ends_at > sqlc.arg(at)
+    UNION
+    SELECT cc.campaign_id, c.id
+    FROM campaign_categories cc
+    JOIN discount_campaigns dc ON dc.id = cc.campaign_id AND dc.include_subcategories
+    JOIN categories c ON c.parent_id = cc.category_id
+)
+SELECT DISTINCT pc.product_id, dc.id AS campaign_id, dc.percent_off
+FROM campaign_categories cc
+JOIN discount_campaigns dc ON dc.id = cc.campaign_id
+JOIN product_categories pc ON pc.category_id = cc.category_id
+WHERE pc.product_id = ANY(sqlc.arg(product_ids)::text[])
+ORDER BY pc.product_id, dc.percent_off DESC, dc.id;
+
+-- name: GetCampaignReport :one
+SELECT COUNT(DISTINCT cr.order_id)::bigint AS orders,
+       COALESCE(SUM(cr.quantity), 0)::bigint AS units,
+       COALESCE(SUM(cr.subtotal * COALESCE(o.exchange_rate, 1)), 0)::float8 AS gross_revenue,
+       COALESCE(SUM(cr.discount * COALESCE(o.exchange_rate, 1)), 0)::float8 AS discount_total
+FROM campaign_redemptions cr
+JOIN (
+    SELECT id, status, exchange_rate FROM orders
+    UNION ALL
+    SELECT id, status, exchange_rate FROM orders_archive
+) o ON o.id = cr.order_id
+WHERE cr.campaign_id = $1 AND o.status NOT IN ('cancelled', 'failed', 'refunded');
+
+-- name: CreateCampaignRedemptions :batchexec
+INSERT INTO campaign_redemptions (campaign_id, order_id, product_id, quantity, subtotal, discount, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, NOW());
@@ -1,35 +1,37 @@
--- name: CreateCart :exec
-INSERT INTO carts (customer_id, status, currency, subtotal, tax, discount, total, expires_at, created_at, updated_at)
-VALUES ($1, $2, $3, 0, 0, 0, 0, $4, NOW(), NOW());
+-- name: CreateCart :one
+INSERT INTO carts (customer_id, status, currency, subtotal, tax, discount, total, expires_at, name, is_default, created_at, updated_at)
+VALUES ($1, $2, $3, 0, 0, 0, 0, $4, $5, $6, NOW(), NOW())
+RETURNING id, created_at, updated_at;
 
 -- name: GetCart :one
-SELECT id, customer_id, status, currency, subtotal, tax, discount, total, expires_at, created_at, updated_at
+SELECT id, customer_id, status, currency, subtotal, tax, discount, total, expires_at, created_at, updated_at, shipping_address, billing_address, applied_promotions, name, is_default, channel
 FROM carts
 WHERE id = $1;
 
 -- name: FindActiveCartByCustomerID :one
-SELECT id, customer_id, status, currency, subtotal, tax, discount, total, expires_at, created_at, updated_at
+SELECT id, customer_id, status, currency, subtotal, tax, discount, total, expires_at, created_at, updated_at, shipping_address, billing_address, applied_promotions, name, is_default, channel
 FROM carts
-WHERE customer_id = $1 AND status = 'active' LIMIT 1;
+WHERE customer_id = $1 AND status = 'active' AND is_default;
 
--- name: AddCartItem :exec
-INSERT INTO cart_items (cart_id, product_id, price_id, stock_id, quantity, unit_price, subtotal, created_at, updated_at)
-VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW());
+-- name: AddCartItem :one
+INSERT INTO cart_items (cart_id, product_id, price_id, stock_id, quantity, unit_price, subtotal, location, customization, note, metadata, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NOW(), NOW())
+RETURNING id;
 
 -- name: ListCartItems :many
-SELECT id, cart_id, product_id, price_id, stock_id, quantity, unit_price, subtotal, created_at, updated_at
+SELECT id, cart_id, product_id, price_id, stock_id, quantity, unit_price, subtotal, created_at, updated_at, location, tax_rate, tax_amount, customization, note, metadata
 FROM cart_items
 WHERE cart_id = $1;
 
 -- name: GetCartItem :one
-SELECT id, cart_id, product_id, price_id, stock_id, quantity, unit_price, subtotal, created_at, updated_at
+SELECT id, cart_id, product_id, price_id, stock_id, quantity, unit_price, subtotal, created_at, updated_at, location, tax_rate, tax_amount, customization, note, metadata
 FROM cart_items
 WHERE id = $1;
 
 -- name: FindCartItemByProductID :one
-SELECT id, cart_id, product_id, price_id, stock_id, quantity, unit_price, subtotal, created_at, updated_at
+SELECT id, cart_id, product_id, price_id, stock_id, quantity, unit_price, subtotal, created_at, updated_at, location, tax_rate, tax_amount, customization, note, metadata
 FROM cart_items
-WHERE cart_id = $1 AND product_id = $2;
+WHERE cart_id = $1 AND product_id = $2 AND customization IS NULL AND note IS NULL AND metadata IS NULL;
 
 -- name: UpdateCartItem :exec
 UPDATE cart_items
@@ -45,10 +47,8 @@ DELETE FROM cart_items WHERE cart_id = $1;
 
 -- name: UpdateCartTotals :exec
 UPDATE carts
-SET subtotal = (SELECT COALESCE(SUM(subtotal), 0) FROM cart_items WHERE cart_id = $1),
-    total = subtotal + tax - discount,
-    updated_at = NOW()
-WHERE id = $1 AND updated_at = $2;
+SET subtotal = $2, tax = $3, discount = $4, total = $2 + $3 - $4, updated_at = NOW()
+WHERE id = $1;
 
 
 -- name: UpdateCartStatus :exec
@@ -60,3 +60,164 @@ WHERE id = $1 AND updated_at = $3;
 UPDATE cart_items
 SET quantity = $2, subtotal = $3, updated_at = NOW()
 WHERE id = $1;
+
+-- name: UpdateCartItemTax :exec
+UPDATE cart_items
+SET tax_rate = $2, tax_amount = $3
+WHERE id = $1;
+
+-- name: ListOrphanedCartItems :many
+SELECT ci.id, ci.cart_id, c.status AS cart_status, ci.product_id, ci.stock_id, s.product_id AS stock_product_id
+FROM cart_items ci
+JOIN carts c ON c.id = ci.cart_id
+LEFT JOIN stocks s ON s.id = ci.stock_id
+WHERE s.id IS NULL OR s.product_id <> ci.product_id
+ORDER BY ci.id;
+
+-- name: ListConvertedCartsWithoutOrder :many
+SELECT c.id, c.customer_id, c.status, c.currency, c.subtotal, c.tax, c.discount, c.total, c.created_at, c.updated_at, c.expires_at
+FROM carts c
+WHERE c.status = 'converted'
+  AND NOT EXISTS (SELECT 1 FROM orders o WHERE o.cart_id = c.id)
+  AND NOT EXISTS (SELECT 1 FROM orders_archive oa WHERE oa.cart_id = c.id)
+ORDER BY c.id;
+
+-- name: ListCartTotalMismatches :many
+SELECT c.id, c.subtotal, c.tax, c.discount, c.total, COALESCE(SUM(ci.subtotal), 0)::float8 AS items_subtotal
+FROM carts c
+LEFT JOIN cart_items ci ON ci.cart_id = c.id
+WHERE c.status = 'active'
+GROUP BY c.id
+HAVING c.subtotal <> COALESCE(SUM(ci.subtotal), 0) OR c.total <> c.subtotal + c.tax - c.discount
+ORDER BY c.id;
+
+-- name: ExtendCartExpiry :execrows
+UPDATE carts
+SET expires_at = GREATEST(expires_at, $2)
+WHERE id = $1 AND status = 'active';
+
+-- name: SetCartAddresses :execrows
+UPDATE carts
+SET shipping_address = $2, billing_address = $3, updated_at = NOW()
+WHERE id = $1 AND status = 'active';
+
+-- name: ClearCartAddresses :execrows
+UPDATE carts
+SET shipping_address = NULL, billing_address = NULL, updated_at = NOW()
+WHERE id = $1 AND status = 'active';
+
+-- name: GetCartAddresses :one
+SELECT shipping_address, billing_address
+FROM carts
+WHERE id = $1;
+
+-- name: ReactivateCart :execrows
+UPDATE carts c
+SET status = 'active', expires_at = $2, updated_at = NOW(),
+    is_default = c.is_default AND NOT EXISTS (
+        SELECT 1 FROM carts a WHERE a.customer_id = c.customer_id AND a.status = 'active' AND a.is_default
+    ),
+    name = CASE
+        WHEN EXISTS (SELECT 1 FROM carts a WHERE a.customer_id = c.customer_id AND a.status = 'active' AND a.name = c.name)
+            THEN c.name || '-' || c.id
+        ELSE c.name
+    END
+WHERE c.id = $1 AND c.status = 'converted';
+
+-- name: SetCartPromotions :exec
+UPDATE carts
+SET applied_promotions = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: GetCustomerNotificationPreference :one
+SELECT customer_id, email_enabled, sms_enabled, push_enabled, marketing_opted_out_at, transactional_opted_out_at, created_at, updated_at
+FROM customer_notification_preferences
+WHERE customer_id = $1;
+
+-- name: UpsertCustomerNotificationPreference :one
+INSERT INTO customer_notification_preferences (customer_id, email_enabled, sms_enabled, push_enabled, marketing_opted_out_at, transactional_opted_out_at, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
+ON CONFLICT (customer_id) DO UPDATE
+SET email_enabled = EXCLUDED.email_enabled,
+    sms_enabled = EXCLUDED.sms_enabled,
+    push_enabled = EXCLUDED.push_enabled,
+    marketing_opted_out_at = EXCLUDED.marketing_opted_out_at,
+    transactional_opted_out_at = EXCLUDED.transactional_opted_out_at,
+    updated_at = NOW()
+RETURNING customer_id, email_enabled, sms_enabled, push_enabled, marketing_opted_out_at, transactional_opted_out_at, created_at, updated_at;
+
+-- name: AssignCartExperiment :execrows
+INSERT INTO experiment_assignments (cart_id, experiment, variant, assigned_at)
+VALUES ($1, $2, $3, NOW())
+ON CONFLICT (cart_id, experiment) DO NOTHING;
+
+-- name: ListCartExperimentAssignments :many
+SELECT cart_id, experiment, variant, order_id, assigned_at
+FROM experiment_assignments
+WHERE cart_id = $1
+ORDER BY experiment;
+
+-- name: ListOrderExperimentAssignments :many
+SELECT cart_id, experiment, variant, order_id, assigned_at
+FROM experiment_assignments
+WHERE order_id = $1
+ORDER BY experiment;
+
+-- name: AttachExperimentAssignmentsToOrder :exec
+UPDATE experiment_assignments
+SET order_id = $2
+WHERE cart_id = $1;
+
+-- name: ListExpiredActiveCartIDs :many
+SELECT id
+FROM carts
+WHERE status = 'active' AND expires_at < $1
+ORDER BY expires_at, id
+LIMIT $2;
+
+-- name: ListInactiveCartIDs :many
+SELECT c.id
+FROM carts c
+WHERE c.status = 'active' AND c.updated_at < $1
+  AND (c.abandoned_notified_at IS NULL OR c.abandoned_notified_at < c.updated_at)
+  AND EXISTS (SELECT 1 FROM cart_items ci WHERE ci.cart_id = c.id)
+ORDER BY c.updated_at, c.id
+LIMIT $2;
+
+-- name: MarkCartAbandonedNotified :execrows
+UPDATE carts
+SET abandoned_notified_at = $2
+WHERE id = $1 AND status = 'active' AND updated_at < $3
+  AND (abandoned_notified_at IS NULL OR abandoned_notified_at < updated_at);
+
+-- name: FindActiveCartByName :one
+SELECT id, customer_id, status, currency, subtotal, tax, discount, total, expires_at, created_at, updated_at, shipping_address, billing_address, applied_promotions, name, is_default, channel
+FROM carts
+WHERE customer_id = $1 AND status = 'active' AND name = $2;
+
+-- name: ListActiveCartsByCustomerID :many
+SELECT id, customer_id, status, currency, subtotal, tax, discount, total, expires_at, created_at, updated_at, shipping_address, billing_address, applied_promotions, name, is_default, channel
+FROM carts
+WHERE customer_id = $1 AND status = 'active'
+ORDER BY is_default DESC, name;
+
+-- name: ClearDefaultCart :many
+UPDATE carts
+SET is_default = FALSE
+WHERE customer_id = $1 AND status = 'active' AND is_default
+RETURNING id;
+
+-- name: SetDefaultCart :execrows
+UPDATE carts
+SET is_default = TRUE
+WHERE id = $1 AND customer_id = $2 AND status = 'active';
+
+-- name: UpdateCartItemMetadata :execrows
+UPDATE cart_items
+SET note = $3, metadata = $4, updated_at = NOW()
+WHERE id = $1 AND cart_id = $2;
+
+-- name: SetCartChannel :execrows
+UPDATE carts
+SET channel = $2, updated_at = NOW()
+WHERE id = $1 AND status = 'active';
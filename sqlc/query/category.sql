@@ -1,28 +1,29 @@
--- name: CreateCategory :exec
-INSERT INTO categories (name, description, parent_id, created_at, updated_at)
-VALUES ($1, $2, $3, NOW(), NOW());
+-- name: CreateCategory :one
+INSERT INTO categories (name, slug, description, parent_id, created_at, updated_at)
+VALUES ($1, $2, $3, $4, NOW(), NOW())
+RETURNING id, created_at, updated_at;
 
 -- name: GetCategoryByID :one
-SELECT id, name, description, parent_id, created_at, updated_at
+SELECT id, name, description, parent_id, created_at, updated_at, slug
 FROM categories
 WHERE id = $1;
 
--- name: UpdateCategory :exec
+-- name: UpdateCategory :execrows
 UPDATE categories
-SET name = $2, description = $3, parent_id = $4, updated_at = NOW()
-WHERE id = $1 AND updated_at = $5;
+SET name = $2, slug = $3, description = $4, parent_id = $5, updated_at = NOW()
+WHERE id = $1 AND updated_at = $6;
 
 -- name: DeleteCategory :exec
 DELETE FROM categories WHERE id = $1;
 
 -- name: ListCategories :many
-SELECT id, name, description, parent_id, created_at, updated_at
+SELECT id, name, description, parent_id, created_at, updated_at, slug
 FROM categories
 ORDER BY created_at DESC
 LIMIT $1 OFFSET $2;
 
 -- name: ListSubcategories :many
-SELECT id, name, description, parent_id, created_at, updated_at
+SELECT id, name, description, parent_id, created_at, updated_at, slug
 FROM categories
 WHERE parent_id = $1
 ORDER BY created_at DESC;
@@ -34,4 +35,111 @@ ON CONFLICT (product_id, category_id) DO NOTHING;
 
 -- name: RemoveProductFromCategory :exec
 DELETE FROM product_categories
-WHERE product_id = $1 AND category_id = $2;
\ No newline at end of file
+WHERE product_id = $1 AND category_id = $2;
+
+-- name: ListAllCategories :many
+SELECT id, name, description, parent_id, created_at, updated_at, slug
+FROM categories
+ORDER BY id;
+
+-- name: ListProductsInCategories :many
+WITH RECURSIVE category_tree AS (
+    SELECT id FROM categories WHERE id = ANY(sqlc.arg(category_ids)::int[])
+    UNION
+    SELECT c.id
+    FROM category_tree ct
+    JOIN categories c ON c.parent_id = ct.id
+)
+SELECT DISTINCT pc.product_id
+FROM product_categories pc
+JOIN category_tree ct ON ct.id = pc.category_id
+WHERE pc.product_id = ANY(sqlc.arg(product_ids)::text[])
+ORDER BY pc.product_id;
+
+-- name: UpsertCategoryTranslation :one
+INSERT INTO category_translations (category_id, locale, name, description, updated_at)
+VALUES ($1, $2, $3, $4, NOW())
+ON CONFLICT (category_id, locale) DO UPDATE
+SET name = EXCLUDED.name, description = EXCLUDED.description, updated_at = EXCLUDED.updated_at
+RETURNING category_id, locale, name, description, updated_at;
+
+-- name: DeleteCategoryTranslation :execrows
+DELETE FROM category_translations
+WHERE category_id = $1 AND locale = $2;
+
+-- name: ListCategoryTranslations :many
+SELECT category_id, locale, name, description, updated_at
+FROM category_translations
+WHERE category_id = ANY(sqlc.arg(category_ids)::int[]) AND locale = ANY(sqlc.arg(locales)::text[])
+ORDER BY category_id, locale;
+
+
+-- name: UpsertProductTranslation :one
+INSERT INTO product_translations (product_id, locale, name, description, updated_at)
+VALUES ($1, $2, $3, $4, NOW())
+ON CONFLICT (product_id, locale) DO UPDATE
+SET name = EXCLUDED.name, description = EXCLUDED.description, updated_at = EXCLUDED.updated_at
+RETURNING product_id, locale, name, description, updated_at;
+
+-- name: DeleteProductTranslation :execrows
+DELETE FROM product_translations
+WHERE product_id = $1 AND locale = $2;
+
+-- name: ListProductTranslations :many
+SELECT product_id, locale, name, description, updated_at
+FROM product_translations
+WHERE product_id = ANY(sqlc.arg(product_ids)::text[]) AND locale = ANY(sqlc.arg(locales)::text[])
+ORDER BY product_id, locale;
+
+-- name: CountCategories :one
+SELECT COUNT(*)
+FROM categories;
+
+-- name: CreateProductMedia :one
+INSERT INTO product_media (product_id, price_id, type, url, storage_key, alt_text, position, content_type, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW())
+RETURNING id, product_id, price_id, type, url, storage_key, alt_text, position, content_type, created_at, updated_at;
+
+-- name: GetProductMedia :one
+SELECT id, product_id, price_id, type, url, storage_key, alt_text, position, content_type, created_at, updated_at
+FROM product_media
+WHERE id = $1;
+
+-- name: UpdateProductMedia :one
+UPDATE product_media
+SET price_id = $2, alt_text = $3, position = $4, updated_at = NOW()
+WHERE id = $1
+RETURNING id, product_id, price_id, type, url, storage_key, alt_text, position, content_type, created_at, updated_at;
+
+-- name: DeleteProductMedia :one
+DELETE FROM product_media
+WHERE id = $1
+RETURNING id, product_id, price_id, type, url, storage_key, alt_text, position, content_type, created_at, updated_at;
+
+-- name: ListProductMedia :many
+SELECT id, product_id, price_id, type, url, storage_key, alt_text, position, content_type, created_at, updated_at
+FROM product_media
+WHERE product_id = sqlc.arg(product_id)
+  AND (sqlc.narg(price_id)::text IS NULL OR price_id IS NULL OR price_id = sqlc.narg(price_id))
+ORDER BY position, id;
+
+-- name: SearchProductIDs :many
+SELECT DISTINCT product_id
+FROM product_translations
+WHERE (sqlc.arg(locale)::text = '' OR locale = sqlc.arg(locale)::text)
+  AND (name ILIKE sqlc.arg(pattern)::text OR description ILIKE sqlc.arg(pattern)::text)
+ORDER BY product_id
+LIMIT sqlc.arg(row_limit) OFFSET sqlc.arg(row_offset);
+
+-- name: CountSearchProducts :one
+SELECT COUNT(DISTINCT product_id)
+FROM product_translations
+WHERE (sqlc.arg(locale)::text = '' OR locale = sqlc.arg(locale)::text)
+  AND (name ILIKE sqlc.arg(pattern)::text OR description ILIKE sqlc.arg(pattern)::text);
+
+-- name: ListProductTranslationsAfter :many
+SELECT product_id, locale, name, description, updated_at
+FROM product_translations
+WHERE (product_id, locale) > (sqlc.arg(after_product_id)::text, sqlc.arg(after_locale)::text)
+ORDER BY product_id, locale
+LIMIT sqlc.arg(row_limit);
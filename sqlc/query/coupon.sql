@@ -0,0 +1,40 @@
+-- name: CreateCoupon :one
+INSERT INTO coupons (code, name, discount_type, amount, currency, starts_at, expires_at, usage_limit, customer_id, recovery_cart_id, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW(), NOW())
+RETURNING id, code, name, discount_type, amount, currency, starts_at, expires_at, usage_limit, redeemed_count, created_at, updated_at, customer_id, recovery_cart_id, revoked_at;
+
+-- name: GetCouponByCode :one
+SELECT id, code, name, discount_type, amount, currency, starts_at, expires_at, usage_limit, redeemed_count, created_at, updated_at, customer_id, recovery_cart_id, revoked_at
+FROM coupons
+WHERE code = $1;
+
+-- name: GetCartCoupon :one
+SELECT c.id, c.code, c.name, c.discount_type, c.amount, c.currency, c.starts_at, c.expires_at, c.usage_limit, c.redeemed_count, c.created_at, c.updated_at, c.customer_id, c.recovery_cart_id, c.revoked_at
+FROM cart_coupons cc
+JOIN coupons c ON c.id = cc.coupon_id
+WHERE cc.cart_id = $1;
+
+-- name: SetCartCoupon :exec
+INSERT INTO cart_coupons (cart_id, coupon_id, applied_at)
+VALUES ($1, $2, NOW())
+ON CONFLICT (cart_id) DO UPDATE
+SET coupon_id = EXCLUDED.coupon_id,
+    applied_at = NOW();
+
+-- name: DeleteCartCoupon :execrows
+DELETE FROM cart_coupons
+WHERE cart_id = $1;
+
+-- name: IncrementCouponRedemptions :execrows
+UPDATE coupons
+SET redeemed_count = redeemed_count + 1, updated_at = NOW()
+WHERE id = $1 AND (usage_limit IS NULL OR redeemed_count < usage_limit);
+
+-- name: CreateCouponRedemption :exec
+INSERT INTO coupon_redemptions (coupon_id, order_id, customer_id, discount, created_at)
+VALUES ($1, $2, $3, $4, NOW());
+
+-- name: RevokeCartRecoveryCoupons :execrows
+UPDATE coupons
+SET revoked_at = NOW(), updated_at = NOW()
+WHERE recovery_cart_id = $1 AND revoked_at IS NULL AND redeemed_count = 0;
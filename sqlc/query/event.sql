@@ -1,8 +1,8 @@
 -- name: CreateEvent :exec
 INSERT INTO events (
-    id, type, processed, created_at, updated_at
+    id, type, processed, created_at, updated_at, payload, payload_compressed
 ) VALUES (
-             $1, $2, $3, $4, $5
+             $1, $2, $3, $4, $5, $6, $7
          );
 
 -- name: GetEventByID :one
@@ -13,4 +13,58 @@ WHERE id = $1;
 -- name: MarkEventAsProcessed :exec
 UPDATE events
 SET processed = true, updated_at = $2
-WHERE id = $1;
\ No newline at end of file
+WHERE id = $1;
+
+-- name: GetEventPayload :one
+SELECT payload, payload_compressed
+FROM events
+WHERE id = $1;
+
+-- name: PurgeEventPayloads :execrows
+UPDATE events
+SET payload = NULL, payload_compressed = FALSE
+WHERE created_at < $1 AND payload IS NOT NULL;
+
+-- name: RecordStripeObjectEvent :exec
+INSERT INTO stripe_object_events (object_id, event_id, event_type, event_created)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (object_id, event_id) DO NOTHING;
+
+-- name: GetLastStripeObjectEventCreated :one
+SELECT MAX(event_created)::timestamptz AS last_event_created
+FROM stripe_object_events
+WHERE object_id = $1;
+
+-- name: HasStripeObjectEvent :one
+SELECT EXISTS (
+    SELECT 1
+    FROM stripe_object_events
+    WHERE object_id = $1 AND event_type = $2
+);
+
+-- name: ParkEvent :exec
+INSERT INTO parked_events (event_id, object_id, event_type, waiting_for, event_created, payload, parked_at)
+VALUES ($1, $2, $3, $4, $5, $6, NOW())
+ON CONFLICT (event_id) DO NOTHING;
+
+-- name: ListParkedEventsByObject :many
+SELECT event_id, object_id, event_type, waiting_for, event_created, payload, parked_at
+FROM parked_events
+WHERE object_id = $1 AND waiting_for = $2
+ORDER BY event_created, event_id;
+
+-- name: ListExpiredParkedEvents :many
+SELECT event_id, object_id, event_type, waiting_for, event_created, payload, parked_at
+FROM parked_events
+WHERE event_created < $1
+ORDER BY event_created, event_id
+LIMIT $2;
+
+-- name: DeleteParkedEvent :execrows
+DELETE FROM parked_events
+WHERE event_id = $1;
+
+-- name: GetEventBacklog :one
+SELECT
+    (SELECT COUNT(*) FROM events WHERE processed = FALSE AND created_at < sqlc.arg(before)::timestamptz)::bigint AS unprocessed,
+    (SELECT COUNT(*) FROM parked_events)::bigint AS parked;
@@ -1,78 +1,767 @@
 -- name: CreateOrder :one
-INSERT INTO orders (customer_id, cart_id, status, currency, subtotal, tax, discount, total, created_at, updated_at)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW())
+INSERT INTO orders (order_number, customer_id, cart_id, status, currency, subtotal, tax, discount, total, shipping_address, billing_address, created_at, updated_at)
+SELECT $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NOW(), NOW()
+WHERE NOT EXISTS (SELECT 1 FROM orders_archive WHERE order_number = $1)
+ON CONFLICT (order_number) DO NOTHING
 RETURNING id, updated_at;
 
 -- name: GetOrder :one
-SELECT id, customer_id, cart_id, status, currency, subtotal, tax, discount, total, created_at, updated_at
+SELECT id, order_number, customer_id, cart_id, status, currency, subtotal, tax, discount, total, shipping_address, billing_address, created_at, updated_at, fulfillment_type, pickup_location, metadata, reporting_currency, exchange_rate, reporting_subtotal, reporting_tax, reporting_discount, reporting_total, estimated_delivery_earliest, estimated_delivery_latest, external_source, external_order_id, applied_promotions, deleted_at, channel, cart_snapshot
 FROM orders
 WHERE id = $1;
 
--- name: UpdateOrderStatus :exec
+-- name: GetOrderForUpdate :one
+SELECT id, customer_id, cart_id, status, currency, subtotal, tax, discount, total, payment_intent_id, invoice_id, subscription_id, refund_id, shipping_address, billing_address, created_at, updated_at, fulfillment_type, pickup_location, metadata, tax_calculation_id, tax_transaction_id, reporting_currency, exchange_rate, reporting_subtotal, reporting_tax, reporting_discount, reporting_total, order_number, estimated_delivery_earliest, estimated_delivery_latest, external_source, external_order_id, applied_promotions, deleted_at, channel, cart_snapshot
+FROM orders
+WHERE id = $1
+FOR UPDATE;
+
+-- name: UpdateOrderStatus :execrows
 UPDATE orders
 SET status = $2, updated_at = NOW()
 WHERE id = $1 AND updated_at = $3;
 
--- name: UpdateOrderTotals :exec
+-- name: UpdateOrderFulfillment :execrows
 UPDATE orders
-SET subtotal = $2, tax = $3, discount = $4, total = $5, updated_at = NOW()
+SET fulfillment_type = $2, pickup_location = $3, updated_at = NOW()
+WHERE id = $1 AND updated_at = $4;
+
+-- name: UpdateOrderTotals :execrows
+UPDATE orders
+SET subtotal = $2, tax = $3, discount = $4, total = $5,
+    reporting_subtotal = ROUND($2 * exchange_rate, 2), reporting_tax = ROUND($3 * exchange_rate, 2),
+    reporting_discount = ROUND($4 * exchange_rate, 2), reporting_total = ROUND($5 * exchange_rate, 2),
+    updated_at = NOW()
 WHERE id = $1 AND updated_at = $6;
 
 -- name: ListOrders :many
-SELECT id, customer_id, cart_id, status, currency, subtotal, tax, discount, total, created_at, updated_at
+SELECT id, order_number, customer_id, cart_id, status, currency, subtotal, tax, discount, total, created_at, updated_at
 FROM orders
-WHERE customer_id = $1
+WHERE customer_id = $1 AND deleted_at IS NULL
 ORDER BY created_at DESC
 LIMIT $2 OFFSET $3;
 
--- name: DeleteOrder :exec
-DELETE FROM orders WHERE id = $1;
+-- name: SoftDeleteOrder :execrows
+UPDATE orders
+SET deleted_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: RestoreOrder :execrows
+UPDATE orders
+SET deleted_at = NULL, updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NOT NULL;
 
--- name: AddOrderItems :batchexec
-INSERT INTO order_items (order_id, product_id, price_id, stock_id, quantity, unit_price, subtotal)
-VALUES ($1, $2, $3, $4, $5, $6, $7);
+-- name: PurgeDeletedOrders :many
+DELETE FROM orders
+WHERE id IN (
+    SELECT id
+    FROM orders
+    WHERE deleted_at < sqlc.arg(cutoff)
+    ORDER BY id
+    LIMIT sqlc.arg(batch_size)
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id;
+
+-- name: AddOrderItems :batchone
+INSERT INTO order_items (order_id, product_id, price_id, stock_id, quantity, unit_price, subtotal, location, product_name, sku, image_url, tax_class, tax_rate, tax_amount, weight_grams, length_mm, width_mm, height_mm, hs_code, origin_country, customization, note, metadata)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23)
+RETURNING id;
 
 -- name: GetOrderItem :one
-SELECT id, order_id, product_id, price_id, stock_id, quantity, unit_price, subtotal
+SELECT id, order_id, product_id, price_id, stock_id, quantity, unit_price, subtotal, location, product_name, sku, image_url, tax_class, tax_rate, tax_amount, weight_grams, length_mm, width_mm, height_mm, hs_code, origin_country, customization, shipped_quantity, returned_quantity, note, metadata
 FROM order_items
 WHERE id = $1;
 
 -- name: ListOrderItems :many
-SELECT id, order_id, product_id, price_id, stock_id, quantity, unit_price, subtotal
+SELECT id, order_id, product_id, price_id, stock_id, quantity, unit_price, subtotal, location, product_name, sku, image_url, tax_class, tax_rate, tax_amount, weight_grams, length_mm, width_mm, height_mm, hs_code, origin_country, customization, shipped_quantity, returned_quantity, note, metadata
 FROM order_items
 WHERE order_id = $1;
 
 -- name: UpdateOrderItem :exec
 UPDATE order_items
-SET quantity = $2, unit_price = $3, subtotal = $4
+SET quantity = $2, unit_price = $3, subtotal = $4, tax_amount = $5
 WHERE id = $1;
 
 -- name: DeleteOrderItem :exec
 DELETE FROM order_items WHERE id = $1;
 
 -- name: GetOrderByPaymentIntentID :one
-SELECT id, customer_id, cart_id, status, currency, subtotal, tax, discount, total, created_at, updated_at
+SELECT id, order_number, customer_id, cart_id, status, currency, subtotal, tax, discount, total, created_at, updated_at
 FROM orders
 WHERE payment_intent_id = $1;
 
 -- name: GetOrderByRefundID :one
-SELECT id, customer_id, cart_id, status, currency, subtotal, tax, discount, total, created_at, updated_at
+SELECT id, order_number, customer_id, cart_id, status, currency, subtotal, tax, discount, total, created_at, updated_at
 FROM orders
 WHERE refund_id = $1;
 
 -- name: GetOrderByInvoiceID :one
-SELECT id, customer_id, cart_id, status, currency, subtotal, tax, discount, total, created_at, updated_at
+SELECT id, order_number, customer_id, cart_id, status, currency, subtotal, tax, discount, total, created_at, updated_at
 FROM orders
 WHERE invoice_id = $1;
 
 -- name: GetOrderByCustomerIDAndSubscriptionID :one
-SELECT id, customer_id, cart_id, status, currency, subtotal, tax, discount, total, created_at, updated_at
+SELECT id, order_number, customer_id, cart_id, status, currency, subtotal, tax, discount, total, created_at, updated_at
 FROM orders
 WHERE subscription_id = $1 AND customer_id = $2;
 
 -- name: ListOrdersByStatus :many
-SELECT id, customer_id, cart_id, status, currency, subtotal, tax, discount, total, created_at, updated_at
+SELECT id, order_number, customer_id, cart_id, status, currency, subtotal, tax, discount, total, created_at, updated_at
 FROM orders
 WHERE status = $1
 ORDER BY created_at DESC
-LIMIT $2 OFFSET $3;
\ No newline at end of file
+LIMIT $2 OFFSET $3;
+
+-- name: ListOrphanedOrderItems :many
+SELECT oi.id, oi.order_id, o.status AS order_status, oi.product_id, oi.stock_id, s.product_id AS stock_product_id
+FROM order_items oi
+JOIN orders o ON o.id = oi.order_id
+LEFT JOIN stocks s ON s.id = oi.stock_id
+WHERE s.id IS NULL OR s.product_id <> oi.product_id
+ORDER BY oi.id;
+
+-- name: ListOrderTotalMismatches :many
+SELECT id, status, subtotal, tax, discount, total, items_subtotal
+FROM (
+    SELECT o.id, o.status, o.subtotal, o.tax, o.discount, o.total,
+           (COALESCE((SELECT SUM(oi.subtotal) FROM order_items oi WHERE oi.order_id = o.id), 0) +
+            COALESCE((SELECT SUM(oa.subtotal) FROM order_addons oa WHERE oa.order_id = o.id), 0))::float8 AS items_subtotal
+    FROM orders o
+) AS totals
+WHERE subtotal <> items_subtotal OR total <> subtotal + tax - discount
+ORDER BY id;
+
+-- name: ArchiveOrders :execrows
+WITH candidates AS (
+    SELECT id
+    FROM orders
+    WHERE status IN ('completed', 'cancelled') AND deleted_at IS NULL AND updated_at < sqlc.arg(cutoff)
+    ORDER BY id
+    LIMIT sqlc.arg(batch_size)
+    FOR UPDATE SKIP LOCKED
+), archived_orders AS (
+    INSERT INTO orders_archive (id, customer_id, cart_id, status, currency, subtotal, tax, discount, total, payment_intent_id, invoice_id, subscription_id, refund_id, shipping_address, billing_address, fulfillment_type, pickup_location, metadata, tax_calculation_id, tax_transaction_id, reporting_currency, exchange_rate, reporting_subtotal, reporting_tax, reporting_discount, reporting_total, order_number, estimated_delivery_earliest, estimated_delivery_latest, external_source, external_order_id, applied_promotions, channel, cart_snapshot, created_at, updated_at)
+    SELECT id, customer_id, cart_id, status, currency, subtotal, tax, discount, total, payment_intent_id, invoice_id, subscription_id, refund_id, shipping_address, billing_address, fulfillment_type, pickup_location, metadata, tax_calculation_id, tax_transaction_id, reporting_currency, exchange_rate, reporting_subtotal, reporting_tax, reporting_discount, reporting_total, order_number, estimated_delivery_earliest, estimated_delivery_latest, external_source, external_order_id, applied_promotions, channel, cart_snapshot, created_at, updated_at
+    FROM orders
+    WHERE id IN (SELECT id FROM candidates)
+    RETURNING id
+), archived_items AS (
+    INSERT INTO order_items_archive (id, order_id, product_id, price_id, stock_id, quantity, unit_price, subtotal, location, product_name, sku, image_url, tax_class, tax_rate, tax_amount, weight_grams, length_mm, width_mm, height_mm, hs_code, origin_country, customization, shipped_quantity, returned_quantity, note, metadata, created_at, updated_at)
+    SELECT id, order_id, product_id, price_id, stock_id, quantity, unit_price, subtotal, location, product_name, sku, image_url, tax_class, tax_rate, tax_amount, weight_grams, length_mm, width_mm, height_mm, hs_code, origin_country, customization, shipped_quantity, returned_quantity, note, metadata, created_at, updated_at
+    FROM order_items
+    WHERE order_id IN (SELECT id FROM archived_orders)
+), archived_addons AS (
+    INSERT INTO order_addons_archive (id, order_id, order_item_id, type, description, quantity, unit_price, subtotal, tax_rate, tax_amount, created_at)
+    SELECT id, order_id, order_item_id, type, description, quantity, unit_price, subtotal, tax_rate, tax_amount, created_at
+    FROM order_addons
+    WHERE order_id IN (SELECT id FROM archived_orders)
+)
+DELETE FROM orders
+WHERE id IN (SELECT id FROM archived_orders);
+
+-- name: GetArchivedOrder :one
+SELECT id, order_number, customer_id, cart_id, status, currency, subtotal, tax, discount, total, shipping_address, billing_address, created_at, updated_at, fulfillment_type, pickup_location, metadata, reporting_currency, exchange_rate, reporting_subtotal, reporting_tax, reporting_discount, reporting_total, estimated_delivery_earliest, estimated_delivery_latest, external_source, external_order_id, applied_promotions, archived_at, channel, cart_snapshot
+FROM orders_archive
+WHERE id = $1;
+
+-- name: ListArchivedOrderItems :many
+SELECT id, order_id, product_id, price_id, stock_id, quantity, unit_price, subtotal, location, product_name, sku, image_url, tax_class, tax_rate, tax_amount, weight_grams, length_mm, width_mm, height_mm, hs_code, origin_country, customization, shipped_quantity, returned_quantity, note, metadata
+FROM order_items_archive
+WHERE order_id = $1;
+
+-- name: MergeOrderMetadata :execrows
+UPDATE orders
+SET metadata = metadata || $2::jsonb, updated_at = NOW()
+WHERE id = $1;
+
+-- name: FindOrdersByMetadata :many
+SELECT id, order_number, customer_id, cart_id, status, currency, subtotal, tax, discount, total, created_at, updated_at, fulfillment_type, pickup_location, metadata
+FROM orders
+WHERE metadata @> $1::jsonb
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3;
+
+-- name: SetOrderTaxCalculation :execrows
+UPDATE orders
+SET tax = $2, total = subtotal + $2 - discount, tax_calculation_id = $3,
+    reporting_tax = ROUND($2 * exchange_rate, 2), reporting_total = ROUND((subtotal + $2 - discount) * exchange_rate, 2),
+    updated_at = NOW()
+WHERE id = $1;
+
+-- name: SetOrderTaxTransaction :execrows
+UPDATE orders
+SET tax_transaction_id = $2, updated_at = NOW()
+WHERE id = $1 AND tax_transaction_id IS NULL;
+
+-- name: ListOrdersPendingTaxCommit :many
+SELECT id, tax_calculation_id
+FROM orders
+WHERE tax_calculation_id IS NOT NULL AND tax_transaction_id IS NULL
+  AND status NOT IN ('pending', 'cancelled', 'failed') AND updated_at < $1 AND deleted_at IS NULL
+ORDER BY id
+LIMIT $2;
+
+-- name: SetOrderReportingSnapshot :execrows
+UPDATE orders
+SET reporting_currency = $2, exchange_rate = $3, reporting_subtotal = $4, reporting_tax = $5, reporting_discount = $6, reporting_total = $7
+WHERE id = $1;
+
+-- name: GetSalesReport :many
+SELECT o.currency, o.reporting_currency,
+       COUNT(*)::bigint AS orders,
+       COUNT(o.exchange_rate)::bigint AS converted_orders,
+       COALESCE(SUM(o.total), 0)::float8 AS total,
+       COALESCE(SUM(o.reporting_subtotal), 0)::float8 AS reporting_subtotal,
+       COALESCE(SUM(o.reporting_tax), 0)::float8 AS reporting_tax,
+       COALESCE(SUM(o.reporting_discount), 0)::float8 AS reporting_discount,
+       COALESCE(SUM(o.reporting_total), 0)::float8 AS reporting_total
+FROM (
+    SELECT status, currency, total, reporting_currency, exchange_rate, reporting_subtotal, reporting_tax, reporting_discount, reporting_total, created_at FROM orders
+    UNION ALL
+    SELECT status, currency, total, reporting_currency, exchange_rate, reporting_subtotal, reporting_tax, reporting_discount, reporting_total, created_at FROM orders_archive
+) o
+WHERE o.created_at >= sqlc.arg(range_start) AND o.created_at < sqlc.arg(range_end) AND o.status NOT IN ('cancelled', 'failed', 'refunded')
+GROUP BY o.currency, o.reporting_currency
+ORDER BY o.currency, o.reporting_currency;
+
+-- name: CreateShipment :one
+INSERT INTO shipments (order_id, location, created_at)
+VALUES ($1, $2, NOW())
+ON CONFLICT (order_id, location) DO UPDATE SET location = EXCLUDED.location
+RETURNING id, order_id, location, created_at;
+
+-- name: GetShipment :one
+SELECT id, order_id, location, created_at
+FROM shipments
+WHERE id = $1;
+
+-- name: ListShipmentsByOrderID :many
+SELECT id, order_id, location, created_at
+FROM shipments
+WHERE order_id = $1
+ORDER BY id;
+
+-- name: GetOrderIDByNumber :one
+SELECT id FROM orders WHERE order_number = $1
+UNION ALL
+SELECT id FROM orders_archive WHERE order_number = $1
+LIMIT 1;
+
+-- name: CreateRefund :one
+INSERT INTO refunds (order_id, stripe_refund_id, currency, amount, restocking_fee, reason, policy_version, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+RETURNING id, order_id, stripe_refund_id, currency, amount, restocking_fee, reason, policy_version, created_at;
+
+-- name: AddRefundItems :batchexec
+INSERT INTO refund_items (refund_id, order_item_id, order_addon_id, quantity, amount, restocking_fee, rule)
+VALUES ($1, $2, $3, $4, $5, $6, $7);
+
+-- name: ListRefundsByOrderID :many
+SELECT id, order_id, stripe_refund_id, currency, amount, restocking_fee, reason, policy_version, created_at
+FROM refunds
+WHERE order_id = $1
+ORDER BY id;
+
+-- name: ListRefundItemsByOrderID :many
+SELECT ri.id, ri.refund_id, ri.order_item_id, ri.quantity, ri.amount, ri.restocking_fee, ri.rule, ri.order_addon_id
+FROM refund_items ri
+JOIN refunds r ON r.id = ri.refund_id
+WHERE r.order_id = $1
+ORDER BY ri.id;
+
+-- name: SetOrderRefund :execrows
+UPDATE orders
+SET refund_id = $2, status = $3, updated_at = NOW()
+WHERE id = $1 AND updated_at = $4;
+
+-- name: SetOrderDeliveryEstimate :execrows
+UPDATE orders
+SET estimated_delivery_earliest = $2, estimated_delivery_latest = $3
+WHERE id = $1;
+
+-- name: ListCustomerOrderStats :many
+SELECT o.customer_id,
+       COUNT(*)::bigint AS orders,
+       COALESCE(SUM(COALESCE(o.reporting_total, o.total)), 0)::float8 AS spend,
+       MIN(o.created_at)::timestamptz AS first_order_at,
+       MAX(o.created_at)::timestamptz AS last_order_at
+FROM (
+    SELECT customer_id, status, total, reporting_total, created_at FROM orders
+    UNION ALL
+    SELECT customer_id, status, total, reporting_total, created_at FROM orders_archive
+) o
+WHERE o.created_at >= sqlc.arg(since) AND o.status NOT IN ('pending', 'cancelled', 'failed', 'refunded')
+GROUP BY o.customer_id
+ORDER BY o.customer_id;
+
+-- name: ClaimOrderIdempotencyKey :execrows
+INSERT INTO order_idempotency_keys (idempotency_key, request_hash, created_at)
+VALUES ($1, $2, NOW())
+ON CONFLICT (idempotency_key) DO UPDATE
+SET request_hash = EXCLUDED.request_hash, order_id = NULL, created_at = EXCLUDED.created_at
+WHERE order_idempotency_keys.created_at < $3;
+
+-- name: GetOrderIdempotencyKey :one
+SELECT idempotency_key, request_hash, order_id, created_at
+FROM order_idempotency_keys
+WHERE idempotency_key = $1;
+
+-- name: SetOrderIdempotencyKeyOrder :exec
+UPDATE order_idempotency_keys
+SET order_id = $2
+WHERE idempotency_key = $1;
+
+-- name: CreateOrderHold :one
+INSERT INTO order_holds (order_id, reason, previous_status, created_at)
+VALUES ($1, $2, $3, NOW())
+RETURNING id, order_id, reason, previous_status, created_at, released_at;
+
+-- name: GetActiveOrderHold :one
+SELECT id, order_id, reason, previous_status, created_at, released_at
+FROM order_holds
+WHERE order_id = $1 AND released_at IS NULL;
+
+-- name: ReleaseOrderHold :execrows
+UPDATE order_holds
+SET released_at = NOW()
+WHERE id = $1 AND released_at IS NULL;
+
+-- name: ListOrderHolds :many
+SELECT id, order_id, reason, previous_status, created_at, released_at
+FROM order_holds
+WHERE order_id = $1
+ORDER BY id;
+
+-- name: CountOrders :one
+SELECT COUNT(*)
+FROM orders
+WHERE customer_id = $1 AND deleted_at IS NULL;
+
+-- name: RecordPaymentFingerprint :exec
+INSERT INTO payment_fingerprints (payment_intent_id, fingerprint, customer_id, order_id, created_at)
+VALUES ($1, $2, $3, $4, NOW())
+ON CONFLICT (payment_intent_id, fingerprint) DO NOTHING;
+
+-- name: CountFingerprintCustomers :one
+SELECT COUNT(DISTINCT customer_id)
+FROM payment_fingerprints
+WHERE fingerprint = $1 AND created_at >= $2;
+
+-- name: CreateFraudReview :execrows
+INSERT INTO fraud_reviews (order_id, reason, detail, status, created_at)
+VALUES ($1, $2, $3, 'pending', NOW())
+ON CONFLICT (order_id, reason) WHERE status = 'pending' DO NOTHING;
+
+-- name: GetFraudReview :one
+SELECT id, order_id, reason, detail, status, created_at, resolved_at, resolved_by, resolution_note
+FROM fraud_reviews
+WHERE id = $1;
+
+-- name: ListFraudReviewsByStatus :many
+SELECT id, order_id, reason, detail, status, created_at, resolved_at, resolved_by, resolution_note
+FROM fraud_reviews
+WHERE status = $1
+ORDER BY created_at;
+
+-- name: ResolveFraudReview :execrows
+UPDATE fraud_reviews
+SET status = $2, resolved_by = $3, resolution_note = $4, resolved_at = NOW()
+WHERE id = $1 AND status = 'pending';
+
+-- name: ListOrdersByFilter :many
+SELECT id, customer_id, cart_id, status, currency, subtotal, tax, discount, total, payment_intent_id, invoice_id, subscription_id, refund_id, shipping_address, billing_address, created_at, updated_at, fulfillment_type, pickup_location, metadata, tax_calculation_id, tax_transaction_id, reporting_currency, exchange_rate, reporting_subtotal, reporting_tax, reporting_discount, reporting_total, order_number, estimated_delivery_earliest, estimated_delivery_latest, external_source, external_order_id, applied_promotions, deleted_at, channel, cart_snapshot
+FROM orders
+WHERE (sqlc.narg(customer_id)::varchar IS NULL OR customer_id = sqlc.narg(customer_id)::varchar)
+  AND (cardinality(sqlc.arg(statuses)::text[]) = 0 OR status::text = ANY(sqlc.arg(statuses)::text[]))
+  AND (sqlc.narg(created_from)::timestamptz IS NULL OR created_at >= sqlc.narg(created_from)::timestamptz)
+  AND (sqlc.narg(created_to)::timestamptz IS NULL OR created_at < sqlc.narg(created_to)::timestamptz)
+ORDER BY id;
+
+-- name: CreateOrderAddon :one
+INSERT INTO order_addons (order_id, order_item_id, type, description, quantity, unit_price, subtotal, tax_rate, tax_amount, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW())
+RETURNING id, order_id, order_item_id, type, description, quantity, unit_price, subtotal, tax_rate, tax_amount, created_at;
+
+-- name: ListOrderAddons :many
+SELECT id, order_id, order_item_id, type, description, quantity, unit_price, subtotal, tax_rate, tax_amount, created_at
+FROM order_addons
+WHERE order_id = $1
+UNION ALL
+SELECT id, order_id, order_item_id, type, description, quantity, unit_price, subtotal, tax_rate, tax_amount, created_at
+FROM order_addons_archive
+WHERE order_id = $1
+ORDER BY id;
+
+-- name: DeleteOrderAddon :one
+DELETE FROM order_addons
+WHERE id = $1 AND order_id = $2
+RETURNING id, order_id, order_item_id, type, description, quantity, unit_price, subtotal, tax_rate, tax_amount, created_at;
+
+-- name: NextInvoiceSequenceNumber :one
+INSERT INTO invoice_sequences (tenant, country, type, next_number, updated_at)
+VALUES ($1, $2, $3, 2, NOW())
+ON CONFLICT (tenant, country, type) DO UPDATE
+SET next_number = invoice_sequences.next_number + 1, updated_at = NOW()
+RETURNING (next_number - 1)::bigint AS sequence_number;
+
+-- name: CreateInvoice :one
+INSERT INTO invoices (tenant, country, type, sequence_number, invoice_number, order_id, refund_id, original_invoice_id, currency, subtotal, tax, total, tax_exemption_number, issued_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, NOW())
+RETURNING id, tenant, country, type, sequence_number, invoice_number, order_id, refund_id, original_invoice_id, currency, subtotal, tax, total, document_url, issued_at, tax_exemption_number;
+
+-- name: GetInvoice :one
+SELECT id, tenant, country, type, sequence_number, invoice_number, order_id, refund_id, original_invoice_id, currency, subtotal, tax, total, document_url, issued_at, tax_exemption_number
+FROM invoices
+WHERE id = $1;
+
+-- name: GetOrderInvoice :one
+SELECT id, tenant, country, type, sequence_number, invoice_number, order_id, refund_id, original_invoice_id, currency, subtotal, tax, total, document_url, issued_at, tax_exemption_number
+FROM invoices
+WHERE order_id = $1 AND type = 'invoice';
+
+-- name: ListInvoicesByOrderID :many
+SELECT id, tenant, country, type, sequence_number, invoice_number, order_id, refund_id, original_invoice_id, currency, subtotal, tax, total, document_url, issued_at, tax_exemption_number
+FROM invoices
+WHERE order_id = $1
+ORDER BY issued_at, id;
+
+-- name: SetInvoiceDocument :execrows
+UPDATE invoices
+SET document_url = $2
+WHERE id = $1;
+
+-- name: ListInvoiceNumberGaps :many
+SELECT tenant, country, type, gap_start, gap_end
+FROM (
+    SELECT tenant, country, type,
+           COALESCE(LAG(sequence_number) OVER (PARTITION BY tenant, country, type ORDER BY sequence_number), 0) + 1 AS gap_start,
+           sequence_number - 1 AS gap_end
+    FROM invoices
+) AS gaps
+WHERE gap_start <= gap_end
+UNION ALL
+SELECT s.tenant, s.country, s.type, COALESCE(MAX(i.sequence_number), 0) + 1 AS gap_start, s.next_number - 1 AS gap_end
+FROM invoice_sequences s
+LEFT JOIN invoices i ON i.tenant = s.tenant AND i.country = s.country AND i.type = s.type
+GROUP BY s.tenant, s.country, s.type, s.next_number
+HAVING COALESCE(MAX(i.sequence_number), 0) + 1 <= s.next_number - 1
+ORDER BY tenant, country, type, gap_start;
+
+-- name: ListRevenueEvents :many
+WITH all_orders AS (
+    SELECT id, order_number, status, currency, subtotal, tax, discount, total, created_at FROM orders
+    UNION ALL
+    SELECT id, order_number, status, currency, subtotal, tax, discount, total, created_at FROM orders_archive
+), refund_taxes AS (
+    SELECT r.id, r.order_id, r.currency, r.amount, r.created_at,
+           CASE WHEN o.total > 0 THEN ROUND(r.amount * o.tax / o.total, 2) ELSE 0 END AS tax
+    FROM refunds r
+    JOIN all_orders o ON o.id = r.order_id
+    WHERE r.created_at >= sqlc.arg(period_start) AND r.created_at < sqlc.arg(period_end)
+)
+SELECT 'sale'::text AS kind, o.id AS order_id, o.order_number, 0::int AS refund_id, o.currency, o.created_at AS occurred_at,
+       o.subtotal::float8 AS subtotal, o.tax::float8 AS tax, o.discount::float8 AS discount, o.total::float8 AS total
+FROM all_orders o
+WHERE o.created_at >= sqlc.arg(period_start) AND o.created_at < sqlc.arg(period_end)
+  AND o.status::text = ANY(sqlc.arg(statuses)::text[])
+UNION ALL
+SELECT 'refund'::text, rt.order_id, o.order_number, rt.id, rt.currency, rt.created_at,
+       (rt.amount - rt.tax)::float8, rt.tax::float8, 0::float8, rt.amount::float8
+FROM refund_taxes rt
+JOIN all_orders o ON o.id = rt.order_id
+ORDER BY occurred_at, order_id, refund_id;
+
+-- name: SetOrderExternalID :execrows
+UPDATE orders
+SET external_source = $2, external_order_id = $3
+WHERE id = $1;
+
+-- name: GetOrderIDByExternalID :one
+SELECT id FROM orders WHERE external_source = $1 AND external_order_id = $2
+UNION ALL
+SELECT id FROM orders_archive WHERE external_source = $1 AND external_order_id = $2
+LIMIT 1;
+
+-- name: CreateOrderReturn :one
+INSERT INTO order_returns (order_id, reason, created_at, updated_at)
+VALUES ($1, $2, NOW(), NOW())
+RETURNING id, order_id, status, reason, carrier, tracking_number, label_url, created_at, updated_at;
+
+-- name: GetOrderReturn :one
+SELECT id, order_id, status, reason, carrier, tracking_number, label_url, created_at, updated_at
+FROM order_returns
+WHERE id = $1;
+
+-- name: GetOrderReturnForUpdate :one
+SELECT id, order_id, status, reason, carrier, tracking_number, label_url, created_at, updated_at
+FROM order_returns
+WHERE id = $1
+FOR UPDATE;
+
+-- name: GetOrderReturnIDByTracking :one
+SELECT id
+FROM order_returns
+WHERE carrier = $1 AND tracking_number = $2;
+
+-- name: ListOrderReturns :many
+SELECT id, order_id, status, reason, carrier, tracking_number, label_url, created_at, updated_at
+FROM order_returns
+WHERE order_id = $1
+ORDER BY created_at, id;
+
+-- name: ListTrackedOrderReturns :many
+SELECT id, order_id, status, reason, carrier, tracking_number, label_url, created_at, updated_at
+FROM order_returns
+WHERE status IN ('label_issued', 'in_transit') AND id > $1
+ORDER BY id
+LIMIT $2;
+
+-- name: UpdateOrderReturnStatus :execrows
+UPDATE order_returns
+SET status = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: SetOrderReturnLabel :execrows
+UPDATE order_returns
+SET status = 'label_issued', carrier = $2, tracking_number = $3, label_url = $4, updated_at = NOW()
+WHERE id = $1 AND status = 'approved';
+
+-- name: SetOrderPromotions :exec
+UPDATE orders
+SET applied_promotions = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: CreateOrderCancellationRequest :one
+INSERT INTO order_cancellation_requests (order_id, customer_id, reason, status, created_at, updated_at)
+VALUES ($1, $2, $3, $4, NOW(), NOW())
+RETURNING id, order_id, customer_id, reason, status, reviewed_by, created_at, updated_at;
+
+-- name: GetOrderCancellationRequestForUpdate :one
+SELECT id, order_id, customer_id, reason, status, reviewed_by, created_at, updated_at
+FROM order_cancellation_requests
+WHERE id = $1
+FOR UPDATE;
+
+-- name: ListPendingOrderCancellationRequests :many
+SELECT id, order_id, customer_id, reason, status, reviewed_by, created_at, updated_at
+FROM order_cancellation_requests
+WHERE status = 'pending_review'
+ORDER BY created_at, id;
+
+-- name: ResolveOrderCancellationRequest :execrows
+UPDATE order_cancellation_requests
+SET status = $2, reviewed_by = $3, updated_at = NOW()
+WHERE id = $1 AND status = 'pending_review';
+
+-- name: GetCancellationReasonStats :many
+SELECT reason,
+       COUNT(*)::bigint AS requests,
+       COUNT(*) FILTER (WHERE status IN ('auto_cancelled', 'approved'))::bigint AS cancelled,
+       COUNT(*) FILTER (WHERE status = 'pending_review')::bigint AS pending,
+       COUNT(*) FILTER (WHERE status = 'rejected')::bigint AS rejected
+FROM order_cancellation_requests
+WHERE created_at >= sqlc.arg(range_start) AND created_at < sqlc.arg(range_end)
+GROUP BY reason
+ORDER BY requests DESC, reason;
+
+-- name: ListPendingOrderIDsByPrice :many
+SELECT DISTINCT o.id
+FROM orders o
+JOIN order_items oi ON oi.order_id = o.id
+WHERE o.status = 'pending' AND oi.price_id = sqlc.arg(price_id) AND oi.unit_price > sqlc.arg(unit_price)
+  AND (sqlc.narg(currency)::text IS NULL OR o.currency::text = sqlc.narg(currency)::text)
+ORDER BY o.id;
+
+-- name: CreateOrderRepricing :one
+INSERT INTO order_repricings (order_id, price_change_id, price_id, new_unit_price, previous_total, new_total, status, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+RETURNING id, order_id, price_change_id, price_id, new_unit_price, previous_total, new_total, status, reviewed_by, created_at, updated_at;
+
+-- name: GetOrderRepricingForUpdate :one
+SELECT id, order_id, price_change_id, price_id, new_unit_price, previous_total, new_total, status, reviewed_by, created_at, updated_at
+FROM order_repricings
+WHERE id = $1
+FOR UPDATE;
+
+-- name: ListOrderRepricings :many
+SELECT id, order_id, price_change_id, price_id, new_unit_price, previous_total, new_total, status, reviewed_by, created_at, updated_at
+FROM order_repricings
+WHERE order_id = $1
+ORDER BY created_at, id;
+
+-- name: ListPendingOrderRepricings :many
+SELECT id, order_id, price_change_id, price_id, new_unit_price, previous_total, new_total, status, reviewed_by, created_at, updated_at
+FROM order_repricings
+WHERE status = 'pending_review'
+ORDER BY created_at, id;
+
+-- name: ResolveOrderRepricing :execrows
+UPDATE order_repricings
+SET status = $2, reviewed_by = $3, new_total = $4, updated_at = NOW()
+WHERE id = $1 AND status = 'pending_review';
+
+-- name: StartOrderFulfillmentSLA :exec
+INSERT INTO order_fulfillment_slas (order_id, paid_at, warn_at, due_at, created_at)
+VALUES ($1, $2, $3, $4, NOW())
+ON CONFLICT (order_id) DO NOTHING;
+
+-- name: CompleteOrderFulfillmentSLA :execrows
+UPDATE order_fulfillment_slas
+SET fulfilled_at = $2
+WHERE order_id = $1 AND fulfilled_at IS NULL;
+
+-- name: GetOrderFulfillmentSLA :one
+SELECT order_id, paid_at, warn_at, due_at, fulfilled_at, at_risk_alerted_at, breach_alerted_at, created_at
+FROM order_fulfillment_slas
+WHERE order_id = $1;
+
+-- name: ListOrdersAtRiskOfSLABreach :many
+SELECT s.order_id, o.order_number, o.customer_id, o.status, o.fulfillment_type, s.paid_at, s.warn_at, s.due_at, s.at_risk_alerted_at, s.breach_alerted_at
+FROM order_fulfillment_slas s
+JOIN orders o ON o.id = s.order_id
+WHERE s.fulfilled_at IS NULL AND s.warn_at <= $1 AND o.status::text = ANY($2::text[])
+ORDER BY s.due_at, s.order_id;
+
+-- name: MarkFulfillmentSLAAtRiskAlerted :execrows
+UPDATE order_fulfillment_slas
+SET at_risk_alerted_at = NOW()
+WHERE order_id = $1 AND at_risk_alerted_at IS NULL;
+
+-- name: MarkFulfillmentSLABreachAlerted :execrows
+UPDATE order_fulfillment_slas
+SET breach_alerted_at = NOW()
+WHERE order_id = $1 AND breach_alerted_at IS NULL;
+
+-- name: ListUnshippedOrderIDsByProduct :many
+SELECT DISTINCT o.id
+FROM orders o
+JOIN order_items oi ON oi.order_id = o.id
+WHERE oi.product_id = sqlc.arg(product_id) AND o.status::text = ANY(sqlc.arg(statuses)::text[])
+  AND NOT EXISTS (SELECT 1 FROM shipments s WHERE s.order_id = o.id)
+ORDER BY o.id;
+
+-- name: ShipOrderItemsAtLocation :execrows
+UPDATE order_items
+SET shipped_quantity = quantity, updated_at = NOW()
+WHERE order_id = $1 AND location = $2 AND shipped_quantity < quantity;
+
+-- name: ShipOrderItems :execrows
+UPDATE order_items
+SET shipped_quantity = quantity, updated_at = NOW()
+WHERE order_id = $1 AND shipped_quantity < quantity;
+
+-- name: ReturnShippedOrderItems :execrows
+UPDATE order_items
+SET returned_quantity = shipped_quantity, updated_at = NOW()
+WHERE order_id = $1 AND returned_quantity < shipped_quantity;
+
+-- name: AddOrderItemReturnedQuantity :execrows
+UPDATE order_items
+SET returned_quantity = LEAST(shipped_quantity, returned_quantity + $2), updated_at = NOW()
+WHERE id = $1 AND returned_quantity < shipped_quantity;
+
+-- name: CountOrdersByStatus :one
+SELECT COUNT(*)
+FROM orders
+WHERE status = $1 AND created_at >= $2 AND deleted_at IS NULL;
+
+-- name: SearchOrderIDs :many
+SELECT o.id
+FROM orders o
+WHERE o.deleted_at IS NULL
+  AND (o.order_number ILIKE sqlc.arg(pattern)::text
+    OR o.customer_id ILIKE sqlc.arg(pattern)::text
+    OR o.metadata::text ILIKE sqlc.arg(pattern)::text
+    OR EXISTS (SELECT 1 FROM order_items oi WHERE oi.order_id = o.id AND oi.product_name ILIKE sqlc.arg(pattern)::text))
+ORDER BY o.created_at DESC, o.id DESC
+LIMIT sqlc.arg(row_limit) OFFSET sqlc.arg(row_offset);
+
+-- name: CountSearchOrders :one
+SELECT COUNT(*)
+FROM orders o
+WHERE o.deleted_at IS NULL
+  AND (o.order_number ILIKE sqlc.arg(pattern)::text
+    OR o.customer_id ILIKE sqlc.arg(pattern)::text
+    OR o.metadata::text ILIKE sqlc.arg(pattern)::text
+    OR EXISTS (SELECT 1 FROM order_items oi WHERE oi.order_id = o.id AND oi.product_name ILIKE sqlc.arg(pattern)::text));
+
+-- name: CreateTaxExemptionCertificate :one
+INSERT INTO tax_exemption_certificates (customer_id, certificate_number, jurisdiction, tax_classes, valid_from, valid_until)
+VALUES (sqlc.arg(customer_id), sqlc.arg(certificate_number), sqlc.arg(jurisdiction), sqlc.arg(tax_classes), sqlc.arg(valid_from), sqlc.narg(valid_until))
+RETURNING id, customer_id, certificate_number, jurisdiction, tax_classes, valid_from, valid_until, revoked_at, created_at, updated_at;
+
+-- name: ListTaxExemptionCertificates :many
+SELECT id, customer_id, certificate_number, jurisdiction, tax_classes, valid_from, valid_until, revoked_at, created_at, updated_at
+FROM tax_exemption_certificates
+WHERE customer_id = sqlc.arg(customer_id)
+ORDER BY created_at DESC, id DESC;
+
+-- name: GetActiveTaxExemptionCertificate :one
+SELECT id, customer_id, certificate_number, jurisdiction, tax_classes, valid_from, valid_until, revoked_at, created_at, updated_at
+FROM tax_exemption_certificates
+WHERE customer_id = sqlc.arg(customer_id)
+  AND jurisdiction = sqlc.arg(jurisdiction)
+  AND revoked_at IS NULL
+  AND valid_from <= sqlc.arg(at)::timestamptz
+  AND (valid_until IS NULL OR valid_until > sqlc.arg(at)::timestamptz)
+ORDER BY valid_from DESC, id DESC
+LIMIT 1;
+
+-- name: RevokeTaxExemptionCertificate :execrows
+UPDATE tax_exemption_certificates
+SET revoked_at = NOW(), updated_at = NOW()
+WHERE id = sqlc.arg(id) AND customer_id = sqlc.arg(customer_id) AND revoked_at IS NULL;
+
+-- name: SetOrderTaxExemption :exec
+INSERT INTO order_tax_exemptions (order_id, certificate_id, certificate_number)
+VALUES (sqlc.arg(order_id), sqlc.arg(certificate_id), sqlc.arg(certificate_number))
+ON CONFLICT (order_id) DO UPDATE
+SET certificate_id = EXCLUDED.certificate_id, certificate_number = EXCLUDED.certificate_number;
+
+-- name: GetOrderTaxExemptionNumber :one
+SELECT certificate_number
+FROM order_tax_exemptions
+WHERE order_id = sqlc.arg(order_id);
+
+-- name: SetOrderChannel :exec
+UPDATE orders
+SET channel = $2
+WHERE id = $1;
+
+-- name: GetChannelSalesReport :many
+SELECT COALESCE(o.channel, '')::text AS channel, o.currency,
+       COUNT(*)::bigint AS orders,
+       COALESCE(SUM(o.total), 0)::float8 AS total
+FROM (
+    SELECT status, currency, total, channel, created_at FROM orders
+    UNION ALL
+    SELECT status, currency, total, channel, created_at FROM orders_archive
+) o
+WHERE o.created_at >= sqlc.arg(range_start) AND o.created_at < sqlc.arg(range_end) AND o.status NOT IN ('cancelled', 'failed', 'refunded')
+GROUP BY o.channel, o.currency
+ORDER BY o.channel NULLS FIRST, o.currency;
+
+-- name: SetOrderCartSnapshot :execrows
+UPDATE orders
+SET cart_snapshot = $2
+WHERE id = $1 AND cart_snapshot IS NULL;
+
+-- name: GetOrderPaymentReference :one
+SELECT customer_id, payment_intent_id, invoice_id FROM orders WHERE id = $1
+UNION ALL
+SELECT customer_id, payment_intent_id, invoice_id FROM orders_archive WHERE id = $1
+LIMIT 1;
+
+-- name: UpsertOrderReceiptLink :one
+INSERT INTO order_receipt_links (order_id, payment_intent_id, invoice_id, receipt_url, invoice_url, invoice_pdf_url, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, NOW())
+ON CONFLICT (order_id) DO UPDATE
+SET payment_intent_id = EXCLUDED.payment_intent_id, invoice_id = EXCLUDED.invoice_id, receipt_url = EXCLUDED.receipt_url,
+    invoice_url = EXCLUDED.invoice_url, invoice_pdf_url = EXCLUDED.invoice_pdf_url, updated_at = NOW()
+RETURNING order_id, payment_intent_id, invoice_id, receipt_url, invoice_url, invoice_pdf_url, updated_at;
+
+-- name: GetOrderReceiptLink :one
+SELECT order_id, payment_intent_id, invoice_id, receipt_url, invoice_url, invoice_pdf_url, updated_at
+FROM order_receipt_links
+WHERE order_id = $1;
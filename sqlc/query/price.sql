@@ -0,0 +1,103 @@
+-- name: CreatePriceChange :one
+INSERT INTO price_changes (price_id, product_id, unit_price, status, reason, effective_at, currency, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+RETURNING id, price_id, product_id, unit_price, status, reason, effective_at, applied_at, created_at, currency;
+
+-- name: ListPriceChanges :many
+SELECT id, price_id, product_id, unit_price, status, reason, effective_at, applied_at, created_at, currency
+FROM price_changes
+WHERE price_id = $1
+ORDER BY effective_at DESC;
+
+-- name: ListDuePriceChanges :many
+SELECT id, price_id, product_id, unit_price, status, reason, effective_at, applied_at, created_at, currency
+FROM price_changes
+WHERE status = 'scheduled' AND effective_at <= $1
+ORDER BY effective_at;
+
+-- name: GetPriceInEffect :one
+SELECT id, price_id, product_id, unit_price, status, reason, effective_at, applied_at, created_at, currency
+FROM price_changes
+WHERE price_id = $1 AND status = 'applied' AND effective_at <= $2
+ORDER BY effective_at DESC
+LIMIT 1;
+
+-- name: MarkPriceChangeApplied :one
+UPDATE price_changes
+SET status = 'applied', applied_at = NOW()
+WHERE id = $1 AND status = 'scheduled'
+RETURNING product_id;
+
+-- name: CancelPriceChange :execrows
+UPDATE price_changes
+SET status = 'cancelled'
+WHERE id = $1 AND status = 'scheduled';
+
+-- name: GetCatalogSnapshot :many
+SELECT p.product_id::text AS product_id,
+       pc.price_id,
+       COALESCE(pc.unit_price, 0)::float8 AS unit_price,
+       pc.currency,
+       COALESCE(st.available, 0)::bigint AS available,
+       COALESCE(cs.slugs, '{}')::text[] AS category_slugs
+FROM unnest(sqlc.arg(product_ids)::text[]) AS p(product_id)
+LEFT JOIN LATERAL (
+    SELECT price_id, unit_price, currency
+    FROM price_changes
+    WHERE product_id = p.product_id AND status = 'applied' AND effective_at <= NOW()
+    ORDER BY effective_at DESC
+    LIMIT 1
+) pc ON TRUE
+LEFT JOIN LATERAL (
+    SELECT SUM(GREATEST(quantity - reserved_quantity, 0)) AS available
+    FROM stocks
+    WHERE product_id = p.product_id AND NOT rental_enabled
+) st ON TRUE
+LEFT JOIN LATERAL (
+    SELECT array_agg(c.slug ORDER BY c.slug) AS slugs
+    FROM product_categories pcat
+    JOIN categories c ON c.id = pcat.category_id
+    WHERE pcat.product_id = p.product_id
+) cs ON TRUE;
+
+-- name: UpsertChannelProduct :exec
+INSERT INTO channel_products (channel, product_id, available, created_at, updated_at)
+VALUES ($1, $2, $3, NOW(), NOW())
+ON CONFLICT (channel, product_id) DO UPDATE
+SET available = EXCLUDED.available, updated_at = NOW();
+
+-- name: DeleteChannelProduct :execrows
+DELETE FROM channel_products
+WHERE channel = $1 AND product_id = $2;
+
+-- name: GetChannelProduct :one
+SELECT channel, product_id, available, created_at, updated_at
+FROM channel_products
+WHERE channel = $1 AND product_id = $2;
+
+-- name: ListChannelProducts :many
+SELECT channel, product_id, available, created_at, updated_at
+FROM channel_products
+WHERE channel = $1
+ORDER BY product_id;
+
+-- name: UpsertChannelPrice :exec
+INSERT INTO channel_prices (channel, price_id, currency, unit_price, created_at, updated_at)
+VALUES ($1, $2, $3, $4, NOW(), NOW())
+ON CONFLICT (channel, price_id, currency) DO UPDATE
+SET unit_price = EXCLUDED.unit_price, updated_at = NOW();
+
+-- name: DeleteChannelPrice :execrows
+DELETE FROM channel_prices
+WHERE channel = $1 AND price_id = $2 AND currency = $3;
+
+-- name: GetChannelPrice :one
+SELECT channel, price_id, currency, unit_price, created_at, updated_at
+FROM channel_prices
+WHERE channel = $1 AND price_id = $2 AND currency = $3;
+
+-- name: ListChannelPrices :many
+SELECT channel, price_id, currency, unit_price, created_at, updated_at
+FROM channel_prices
+WHERE channel = $1
+ORDER BY price_id, currency;
@@ -1,36 +1,410 @@
--- name: AdjustStock :batchexec
+-- name: AdjustStock :batchone
 UPDATE stocks
-SET reserved_quantity = reserved_quantity + $2, updated_at = NOW()
-WHERE id = $1 AND updated_at = $3;
+SET reserved_quantity = reserved_quantity + CASE WHEN EXISTS (SELECT 1 FROM stock_projections p WHERE p.stock_id = stocks.id) THEN 0 ELSE $2 END, updated_at = NOW()
+WHERE id = $1 AND updated_at = $3
+RETURNING id;
 
--- name: ReleaseStock :batchexec
+-- name: ReleaseStock :batchone
 UPDATE stocks
-SET reserved_quantity = reserved_quantity - $2, updated_at = NOW()
-WHERE id = $1 AND updated_at = $3;
+SET reserved_quantity = reserved_quantity - CASE WHEN EXISTS (SELECT 1 FROM stock_projections p WHERE p.stock_id = stocks.id) THEN 0 ELSE $2 END, updated_at = NOW()
+WHERE id = $1 AND updated_at = $3
+RETURNING id;
 
--- name: ReduceStock :batchexec
+-- name: ReduceStock :batchone
 UPDATE stocks
-SET quantity = quantity - $2, reserved_quantity = reserved_quantity - $2, updated_at = NOW()
-WHERE id = $1 AND updated_at = $3;
+SET quantity = quantity - CASE WHEN EXISTS (SELECT 1 FROM stock_projections p WHERE p.stock_id = stocks.id) THEN 0 ELSE $2 END,
+    reserved_quantity = reserved_quantity - CASE WHEN EXISTS (SELECT 1 FROM stock_projections p WHERE p.stock_id = stocks.id) THEN 0 ELSE $2 END,
+    updated_at = NOW()
+WHERE id = $1 AND updated_at = $3
+RETURNING id;
+
+-- name: RestoreReservedStock :batchone
+UPDATE stocks
+SET quantity = quantity + CASE WHEN EXISTS (SELECT 1 FROM stock_projections p WHERE p.stock_id = stocks.id) THEN 0 ELSE $2 END,
+    reserved_quantity = reserved_quantity + CASE WHEN EXISTS (SELECT 1 FROM stock_projections p WHERE p.stock_id = stocks.id) THEN 0 ELSE $2 END,
+    updated_at = NOW()
+WHERE id = $1 AND updated_at = $3
+RETURNING id;
 
 -- name: GetStock :one
-SELECT id, product_id, quantity, reserved_quantity, location, created_at, updated_at
+SELECT id, product_id, quantity, reserved_quantity, location, created_at, updated_at, rental_enabled
 FROM stocks
 WHERE id = $1;
 
+-- name: GetStockByProductAndLocation :one
+SELECT id, product_id, quantity, reserved_quantity, location, created_at, updated_at, rental_enabled
+FROM stocks
+WHERE product_id = $1 AND location = $2
+LIMIT 1;
+
+-- name: ListStocksByProductID :many
+SELECT id, product_id, quantity, reserved_quantity, location, created_at, updated_at, rental_enabled
+FROM stocks
+WHERE product_id = $1
+ORDER BY quantity - reserved_quantity DESC;
+
 -- name: CreateStockMovement :batchexec
-INSERT INTO stock_movements (stock_id, quantity, type, reference_id, reference_type, created_at)
-VALUES ($1, $2, $3, $4, $5, NOW());
+INSERT INTO stock_movements (stock_id, quantity, type, reference_id, reference_type, actor, note, unit_cost, reason, reference_item_id, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW());
 
 -- name: ListStockMovements :many
-SELECT id, stock_id, quantity, type, reference_id, reference_type, created_at
+SELECT id, stock_id, quantity, type, reference_id, reference_type, created_at, actor, note, unit_cost, reason, reversal_of_id, reference_item_id
 FROM stock_movements
 WHERE stock_id = $1
 ORDER BY created_at DESC
 LIMIT $2 OFFSET $3;
 
 -- name: GetStockMovementsByReference :many
-SELECT id, stock_id, quantity, type, reference_id, reference_type, created_at
+SELECT id, stock_id, quantity, type, reference_id, reference_type, created_at, actor, note, unit_cost, reason, reversal_of_id, reference_item_id
 FROM stock_movements
 WHERE reference_type = $1 AND reference_id = $2
-ORDER BY created_at DESC;
\ No newline at end of file
+ORDER BY created_at DESC;
+
+-- name: CreateStockHold :one
+INSERT INTO stock_holds (stock_id, quantity, reason, expires_at, created_at)
+VALUES ($1, $2, $3, $4, NOW())
+RETURNING id, stock_id, quantity, reason, expires_at, released_at, created_at;
+
+-- name: GetStockHold :one
+SELECT id, stock_id, quantity, reason, expires_at, released_at, created_at
+FROM stock_holds
+WHERE id = $1;
+
+-- name: ListStockHolds :many
+SELECT id, stock_id, quantity, reason, expires_at, released_at, created_at
+FROM stock_holds
+WHERE stock_id = $1 AND released_at IS NULL
+ORDER BY created_at DESC;
+
+-- name: ListExpiredStockHolds :many
+SELECT id, stock_id, quantity, reason, expires_at, released_at, created_at
+FROM stock_holds
+WHERE released_at IS NULL AND expires_at IS NOT NULL AND expires_at <= $1
+ORDER BY expires_at;
+
+-- name: ReleaseStockHold :execrows
+UPDATE stock_holds
+SET released_at = NOW()
+WHERE id = $1 AND released_at IS NULL;
+
+-- name: CreateStockAdjustment :one
+INSERT INTO stock_adjustments (stock_id, quantity_delta, reason, note, status, requested_by, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, NOW())
+RETURNING id, stock_id, quantity_delta, reason, note, status, requested_by, reviewed_by, review_note, created_at, reviewed_at;
+
+-- name: GetStockAdjustment :one
+SELECT id, stock_id, quantity_delta, reason, note, status, requested_by, reviewed_by, review_note, created_at, reviewed_at
+FROM stock_adjustments
+WHERE id = $1;
+
+-- name: ListStockAdjustmentsByStatus :many
+SELECT id, stock_id, quantity_delta, reason, note, status, requested_by, reviewed_by, review_note, created_at, reviewed_at
+FROM stock_adjustments
+WHERE status = $1
+ORDER BY created_at;
+
+-- name: ReviewStockAdjustment :execrows
+UPDATE stock_adjustments
+SET status = $2, reviewed_by = $3, review_note = $4, reviewed_at = NOW()
+WHERE id = $1 AND status = 'pending';
+
+-- name: UpdateStockQuantity :execrows
+UPDATE stocks
+SET quantity = quantity + CASE WHEN EXISTS (SELECT 1 FROM stock_projections p WHERE p.stock_id = stocks.id) THEN 0 ELSE sqlc.arg(delta)::integer END, updated_at = NOW()
+WHERE id = sqlc.arg(id) AND updated_at = sqlc.arg(updated_at)
+  AND (EXISTS (SELECT 1 FROM stock_projections p WHERE p.stock_id = stocks.id) OR quantity + sqlc.arg(delta)::integer >= reserved_quantity);
+
+-- name: LockStock :one
+SELECT id, product_id, quantity, reserved_quantity, location, created_at, updated_at, rental_enabled
+FROM stocks
+WHERE id = $1
+FOR UPDATE;
+
+-- name: SetStockRentalEnabled :execrows
+UPDATE stocks
+SET rental_enabled = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: CreateStockRental :one
+INSERT INTO stock_rentals (stock_id, customer_id, quantity, starts_on, ends_on, status, note, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, 'reserved', $6, NOW(), NOW())
+RETURNING id, stock_id, customer_id, quantity, starts_on, ends_on, status, note, created_at, updated_at;
+
+-- name: GetStockRental :one
+SELECT id, stock_id, customer_id, quantity, starts_on, ends_on, status, note, created_at, updated_at
+FROM stock_rentals
+WHERE id = $1;
+
+-- name: ListStockRentals :many
+SELECT id, stock_id, customer_id, quantity, starts_on, ends_on, status, note, created_at, updated_at
+FROM stock_rentals
+WHERE stock_id = sqlc.arg(stock_id) AND status = 'reserved' AND ends_on > sqlc.arg(range_start)::date AND starts_on < sqlc.arg(range_end)::date
+ORDER BY starts_on, id;
+
+-- name: UpdateStockRentalStatus :execrows
+UPDATE stock_rentals
+SET status = $2, updated_at = NOW()
+WHERE id = $1 AND status = 'reserved';
+
+-- name: ListRentalBookings :many
+SELECT d::date AS day, COALESCE(SUM(r.quantity), 0)::bigint AS booked
+FROM generate_series(sqlc.arg(range_start)::date, sqlc.arg(range_end)::date - 1, interval '1 day') AS d
+LEFT JOIN stock_rentals r ON r.stock_id = sqlc.arg(stock_id) AND r.status = 'reserved' AND r.starts_on <= d AND r.ends_on > d
+GROUP BY d
+ORDER BY d;
+
+-- name: GetStockMovementForUpdate :one
+SELECT id, stock_id, quantity, type, reference_id, reference_type, created_at, actor, note, unit_cost, reason, reversal_of_id, reference_item_id
+FROM stock_movements
+WHERE id = $1
+FOR UPDATE;
+
+-- name: CreateStockMovementReversal :one
+INSERT INTO stock_movements (stock_id, quantity, type, reference_id, reference_type, actor, note, unit_cost, reason, reversal_of_id, reference_item_id, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NOW())
+RETURNING id, stock_id, quantity, type, reference_id, reference_type, created_at, actor, note, unit_cost, reason, reversal_of_id, reference_item_id;
+
+-- name: ListOverReservedStocks :many
+SELECT id, product_id, quantity, reserved_quantity, location, created_at, updated_at, rental_enabled
+FROM stocks
+WHERE reserved_quantity > quantity OR reserved_quantity < 0
+ORDER BY id;
+
+-- name: GetStockLevelAt :one
+SELECT s.id, s.product_id, s.location, s.created_at,
+       (s.quantity - COALESCE(SUM(CASE m.type WHEN 'in' THEN m.quantity WHEN 'out' THEN -m.quantity ELSE 0 END), 0))::bigint AS quantity,
+       (s.reserved_quantity - COALESCE(SUM(CASE
+           WHEN m.type = 'reserve' THEN m.quantity
+           WHEN m.type = 'release' THEN -m.quantity
+           WHEN m.type = 'out' AND m.reference_type = 'order' THEN -m.quantity
+           ELSE 0 END), 0))::bigint AS reserved_quantity
+FROM stocks s
+LEFT JOIN stock_movements m ON m.stock_id = s.id AND m.created_at > sqlc.arg(at)
+WHERE s.id = sqlc.arg(stock_id)
+GROUP BY s.id;
+
+-- name: EnableStockEventSourcing :one
+INSERT INTO stock_projections (stock_id, base_quantity, base_reserved_quantity, base_movement_id, projected_movement_id, enabled_at, projected_at)
+SELECT s.id, s.quantity, s.reserved_quantity, COALESCE(MAX(m.id), 0), COALESCE(MAX(m.id), 0), NOW(), NOW()
+FROM stocks s
+LEFT JOIN stock_movements m ON m.stock_id = s.id
+WHERE s.id = $1
+GROUP BY s.id
+ON CONFLICT (stock_id) DO NOTHING
+RETURNING stock_id, base_quantity, base_reserved_quantity, base_movement_id, projected_movement_id, enabled_at, projected_at;
+
+-- name: GetStockProjection :one
+SELECT stock_id, base_quantity, base_reserved_quantity, base_movement_id, projected_movement_id, enabled_at, projected_at
+FROM stock_projections
+WHERE stock_id = $1;
+
+-- name: DeleteStockProjection :execrows
+DELETE FROM stock_projections
+WHERE stock_id = $1;
+
+-- name: GetUnprojectedStockDelta :one
+SELECT (COUNT(p.stock_id) > 0)::boolean AS event_sourced,
+       COALESCE(SUM(CASE m.type WHEN 'in' THEN m.quantity WHEN 'out' THEN -m.quantity ELSE 0 END), 0)::bigint AS quantity_delta,
+       COALESCE(SUM(CASE
+           WHEN m.type = 'reserve' THEN m.quantity
+           WHEN m.type = 'release' THEN -m.quantity
+           WHEN m.type = 'out' AND m.reference_type = 'order' AND m.reversal_of_id IS NULL THEN -m.quantity
+           ELSE 0 END), 0)::bigint AS reserved_quantity_delta
+FROM stock_projections p
+LEFT JOIN stock_movements m ON m.stock_id = p.stock_id AND m.id > p.projected_movement_id
+WHERE p.stock_id = $1;
+
+-- name: ProjectStock :execrows
+WITH pending AS (
+    SELECT p.stock_id,
+           COALESCE(SUM(CASE m.type WHEN 'in' THEN m.quantity WHEN 'out' THEN -m.quantity ELSE 0 END), 0)::integer AS quantity_delta,
+           COALESCE(SUM(CASE
+               WHEN m.type = 'reserve' THEN m.quantity
+               WHEN m.type = 'release' THEN -m.quantity
+               WHEN m.type = 'out' AND m.reference_type = 'order' AND m.reversal_of_id IS NULL THEN -m.quantity
+               ELSE 0 END), 0)::integer AS reserved_quantity_delta,
+           MAX(m.id) AS last_movement_id
+    FROM stock_projections p
+    JOIN stock_movements m ON m.stock_id = p.stock_id AND m.id > p.projected_movement_id
+    WHERE p.stock_id = $1
+    GROUP BY p.stock_id
+), advanced AS (
+    UPDATE stock_projections p
+    SET projected_movement_id = pending.last_movement_id, projected_at = NOW()
+    FROM pending
+    WHERE p.stock_id = pending.stock_id
+)
+UPDATE stocks s
+SET quantity = s.quantity + pending.quantity_delta, reserved_quantity = s.reserved_quantity + pending.reserved_quantity_delta
+FROM pending
+WHERE s.id = pending.stock_id;
+
+-- name: RebuildStockProjection :execrows
+WITH replayed AS (
+    SELECT p.stock_id,
+           (p.base_quantity + COALESCE(SUM(CASE m.type WHEN 'in' THEN m.quantity WHEN 'out' THEN -m.quantity ELSE 0 END), 0))::integer AS quantity,
+           (p.base_reserved_quantity + COALESCE(SUM(CASE
+               WHEN m.type = 'reserve' THEN m.quantity
+               WHEN m.type = 'release' THEN -m.quantity
+               WHEN m.type = 'out' AND m.reference_type = 'order' AND m.reversal_of_id IS NULL THEN -m.quantity
+               ELSE 0 END), 0))::integer AS reserved_quantity,
+           COALESCE(MAX(m.id), p.base_movement_id) AS last_movement_id
+    FROM stock_projections p
+    LEFT JOIN stock_movements m ON m.stock_id = p.stock_id AND m.id > p.base_movement_id
+    WHERE p.stock_id = $1
+    GROUP BY p.stock_id, p.base_quantity, p.base_reserved_quantity, p.base_movement_id
+), advanced AS (
+    UPDATE stock_projections p
+    SET projected_movement_id = replayed.last_movement_id, projected_at = NOW()
+    FROM replayed
+    WHERE p.stock_id = replayed.stock_id
+)
+UPDATE stocks s
+SET quantity = replayed.quantity, reserved_quantity = replayed.reserved_quantity
+FROM replayed
+WHERE s.id = replayed.stock_id;
+
+-- name: ListStocksPendingProjection :many
+SELECT p.stock_id
+FROM stock_projections p
+WHERE EXISTS (SELECT 1 FROM stock_movements m WHERE m.stock_id = p.stock_id AND m.id > p.projected_movement_id)
+ORDER BY p.projected_at
+LIMIT $1;
+
+-- name: CreateStockTransfer :one
+INSERT INTO stock_transfers (from_stock_id, to_stock_id, quantity, note, status, requested_by, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, NOW())
+RETURNING id, from_stock_id, to_stock_id, quantity, note, status, requested_by, reviewed_by, review_note, created_at, reviewed_at;
+
+-- name: GetStockTransfer :one
+SELECT id, from_stock_id, to_stock_id, quantity, note, status, requested_by, reviewed_by, review_note, created_at, reviewed_at
+FROM stock_transfers
+WHERE id = $1;
+
+-- name: ListStockTransfersByStatus :many
+SELECT id, from_stock_id, to_stock_id, quantity, note, status, requested_by, reviewed_by, review_note, created_at, reviewed_at
+FROM stock_transfers
+WHERE status = $1
+ORDER BY created_at;
+
+-- name: ReviewStockTransfer :execrows
+UPDATE stock_transfers
+SET status = $2, reviewed_by = $3, review_note = $4, reviewed_at = NOW()
+WHERE id = $1 AND status = 'draft';
+
+-- name: ListStockOutflows :many
+SELECT s.id, s.product_id, s.location, s.quantity, s.reserved_quantity,
+       COALESCE(SUM(m.quantity), 0)::bigint AS out_quantity
+FROM stocks s
+LEFT JOIN stock_movements m ON m.stock_id = s.id AND m.type = 'out' AND m.reference_type = 'order'
+    AND m.created_at >= sqlc.arg(since) AND m.reversal_of_id IS NULL
+    AND NOT EXISTS (SELECT 1 FROM stock_movements r WHERE r.reversal_of_id = m.id)
+WHERE s.location IS NOT NULL AND NOT s.rental_enabled
+  AND s.product_id IN (
+      SELECT product_id FROM stocks
+      WHERE location IS NOT NULL AND NOT rental_enabled
+      GROUP BY product_id
+      HAVING COUNT(*) > 1
+  )
+GROUP BY s.id
+ORDER BY s.product_id, s.id;
+
+-- name: CreateManualStockMovement :one
+INSERT INTO stock_movements (stock_id, quantity, type, reference_type, note, reason, created_at)
+VALUES ($1, $2, $3, 'manual', $4, $5, NOW())
+RETURNING id, stock_id, quantity, type, reference_id, reference_type, created_at, actor, note, unit_cost, reason, reversal_of_id, reference_item_id;
+
+-- name: CountStockMovements :one
+SELECT COUNT(*)
+FROM stock_movements
+WHERE stock_id = $1;
+
+-- name: CreateStore :one
+INSERT INTO stores (name, location, address, latitude, longitude, hours, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
+RETURNING id, name, location, address, latitude, longitude, hours, created_at, updated_at;
+
+-- name: UpdateStore :execrows
+UPDATE stores
+SET name = $2, location = $3, address = $4, latitude = $5, longitude = $6, hours = $7, updated_at = NOW()
+WHERE id = $1;
+
+-- name: ListStores :many
+SELECT id, name, location, address, latitude, longitude, hours, created_at, updated_at
+FROM stores
+ORDER BY name, id;
+
+-- name: FindStoresWithinRadius :many
+SELECT id, name, location, address, latitude, longitude, hours, created_at, updated_at, distance_km::float8 AS distance_km
+FROM (
+    SELECT id, name, location, address, latitude, longitude, hours, created_at, updated_at,
+           6371 * 2 * ASIN(LEAST(1, SQRT(
+               POWER(SIN(RADIANS(latitude - sqlc.arg(lat)::float8) / 2), 2) +
+               COS(RADIANS(sqlc.arg(lat)::float8)) * COS(RADIANS(latitude)) *
+               POWER(SIN(RADIANS(longitude - sqlc.arg(lng)::float8) / 2), 2)
+           ))) AS distance_km
+    FROM stores
+) AS s
+WHERE distance_km <= sqlc.arg(radius_km)::float8
+ORDER BY distance_km, id;
+
+-- name: CreateStock :one
+INSERT INTO stocks (product_id, quantity, location, created_at, updated_at)
+VALUES ($1, $2, $3, NOW(), NOW())
+RETURNING id, product_id, quantity, reserved_quantity, location, created_at, updated_at, rental_enabled;
+
+-- name: RefreshProductAvailability :exec
+REFRESH MATERIALIZED VIEW CONCURRENTLY product_availability;
+
+-- name: ListProductAvailability :many
+SELECT product_id, quantity, reserved_quantity, available_quantity, location_count, refreshed_at
+FROM product_availability
+WHERE product_id = ANY(sqlc.arg(product_ids)::text[])
+ORDER BY product_id;
+
+-- name: ListLowStocks :many
+SELECT id, product_id, quantity, reserved_quantity, location, created_at, updated_at, rental_enabled
+FROM stocks
+WHERE quantity - reserved_quantity <= sqlc.arg(threshold)::int
+ORDER BY quantity - reserved_quantity, id
+LIMIT sqlc.arg(row_limit);
+
+-- name: CreateProductBarcode :exec
+INSERT INTO product_barcodes (barcode, product_id)
+VALUES ($1, $2);
+
+-- name: ListProductBarcodes :many
+SELECT barcode, product_id, created_at
+FROM product_barcodes
+WHERE barcode = ANY($1::text[]);
+
+-- name: CreateStocktake :one
+INSERT INTO stocktakes (location, started_by, created_at)
+VALUES ($1, $2, NOW())
+RETURNING id, location, status, started_by, finished_by, created_at, finished_at;
+
+-- name: GetStocktake :one
+SELECT id, location, status, started_by, finished_by, created_at, finished_at
+FROM stocktakes
+WHERE id = $1;
+
+-- name: FinishStocktake :execrows
+UPDATE stocktakes
+SET status = $2, finished_by = $3, finished_at = NOW()
+WHERE id = $1 AND status = 'open';
+
+-- name: AddStocktakeScan :one
+INSERT INTO stocktake_scans (stocktake_id, batch_id, position, barcode, stock_id, serial_number, quantity, scanned_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT DO NOTHING
+RETURNING id;
+
+-- name: ListStocktakeCounts :many
+SELECT s.id AS stock_id, s.product_id, s.quantity, s.reserved_quantity,
+       COALESCE(SUM(c.quantity), 0)::bigint AS counted_quantity
+FROM stocktakes t
+JOIN stocks s ON s.location = t.location
+LEFT JOIN stocktake_scans c ON c.stocktake_id = t.id AND c.stock_id = s.id
+WHERE t.id = $1
+GROUP BY s.id
+ORDER BY s.product_id, s.id;
@@ -0,0 +1,21 @@
+-- name: SaveItem :one
+INSERT INTO saved_items (customer_id, product_id, price_id, stock_id, quantity, unit_price, currency, location, customization, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW(), NOW())
+ON CONFLICT (customer_id, stock_id, price_id) WHERE customization IS NULL
+DO UPDATE SET quantity = saved_items.quantity + EXCLUDED.quantity, unit_price = EXCLUDED.unit_price, updated_at = NOW()
+RETURNING id, customer_id, product_id, price_id, stock_id, quantity, unit_price, currency, location, customization, created_at, updated_at;
+
+-- name: GetSavedItem :one
+SELECT id, customer_id, product_id, price_id, stock_id, quantity, unit_price, currency, location, customization, created_at, updated_at
+FROM saved_items
+WHERE id = $1;
+
+-- name: ListSavedItems :many
+SELECT id, customer_id, product_id, price_id, stock_id, quantity, unit_price, currency, location, customization, created_at, updated_at
+FROM saved_items
+WHERE customer_id = $1
+ORDER BY created_at DESC, id DESC;
+
+-- name: DeleteSavedItem :execrows
+DELETE FROM saved_items
+WHERE id = $1 AND customer_id = $2;
@@ -7,10 +7,540 @@ package sqlc
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const addStocktakeScan = `-- name: AddStocktakeScan :one
+INSERT INTO stocktake_scans (stocktake_id, batch_id, position, barcode, stock_id, serial_number, quantity, scanned_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT DO NOTHING
+RETURNING id
+`
+
+type AddStocktakeScanParams struct {
+	StocktakeID  int32              `json:"stocktakeId"`
+	BatchID      string             `json:"batchId"`
+	Position     int32              `json:"position"`
+	Barcode      string             `json:"barcode"`
+	StockID      uint64             `json:"stockId"`
+	SerialNumber *string            `json:"serialNumber"`
+	Quantity     uint64             `json:"quantity"`
+	ScannedAt    pgtype.Timestamptz `json:"scannedAt"`
+}
+
+func (q *Queries) AddStocktakeScan(ctx context.Context, arg AddStocktakeScanParams) (int32, error) {
+	row := q.db.QueryRow(ctx, addStocktakeScan,
+		arg.StocktakeID,
+		arg.BatchID,
+		arg.Position,
+		arg.Barcode,
+		arg.StockID,
+		arg.SerialNumber,
+		arg.Quantity,
+		arg.ScannedAt,
+	)
+	var id int32
+	err := row.Scan(&id)
+	return id, err
+}
+
+const countStockMovements = `-- name: CountStockMovements :one
+SELECT COUNT(*)
+FROM stock_movements
+WHERE stock_id = $1
+`
+
+func (q *Queries) CountStockMovements(ctx context.Context, stockID uint64) (int64, error) {
+	row := q.db.QueryRow(ctx, countStockMovements, stockID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createManualStockMovement = `-- name: CreateManualStockMovement :one
+INSERT INTO stock_movements (stock_id, quantity, type, reference_type, note, reason, created_at)
+VALUES ($1, $2, $3, 'manual', $4, $5, NOW())
+RETURNING id, stock_id, quantity, type, reference_id, reference_type, created_at, actor, note, unit_cost, reason, reversal_of_id, reference_item_id
+`
+
+type CreateManualStockMovementParams struct {
+	StockID  uint64                  `json:"stockId"`
+	Quantity uint64                  `json:"quantity"`
+	Type     StockMovementType       `json:"type"`
+	Note     *string                 `json:"note"`
+	Reason   NullStockMovementReason `json:"reason"`
+}
+
+func (q *Queries) CreateManualStockMovement(ctx context.Context, arg CreateManualStockMovementParams) (*StockMovement, error) {
+	row := q.db.QueryRow(ctx, createManualStockMovement,
+		arg.StockID,
+		arg.Quantity,
+		arg.Type,
+		arg.Note,
+		arg.Reason,
+	)
+	var i StockMovement
+	err := row.Scan(
+		&i.ID,
+		&i.StockID,
+		&i.Quantity,
+		&i.Type,
+		&i.ReferenceID,
+		&i.ReferenceType,
+		&i.CreatedAt,
+		&i.Actor,
+		&i.Note,
+		&i.UnitCost,
+		&i.Reason,
+		&i.ReversalOfID,
+		&i.ReferenceItemID,
+	)
+	return &i, err
+}
+
+const createProductBarcode = `-- name: CreateProductBarcode :exec
+INSERT INTO product_barcodes (barcode, product_id)
+VALUES ($1, $2)
+`
+
+type CreateProductBarcodeParams struct {
+	Barcode   string `json:"barcode"`
+	ProductID string `json:"productId"`
+}
+
+func (q *Queries) CreateProductBarcode(ctx context.Context, arg CreateProductBarcodeParams) error {
+	_, err := q.db.Exec(ctx, createProductBarcode, arg.Barcode, arg.ProductID)
+	return err
+}
+
+const createStock = `-- name: CreateStock :one
+INSERT INTO stocks (product_id, quantity, location, created_at, updated_at)
+VALUES ($1, $2, $3, NOW(), NOW())
+RETURNING id, product_id, quantity, reserved_quantity, location, created_at, updated_at, rental_enabled
+`
+
+type CreateStockParams struct {
+	ProductID string  `json:"productId"`
+	Quantity  uint64  `json:"quantity"`
+	Location  *string `json:"location"`
+}
+
+func (q *Queries) CreateStock(ctx context.Context, arg CreateStockParams) (*Stock, error) {
+	row := q.db.QueryRow(ctx, createStock, arg.ProductID, arg.Quantity, arg.Location)
+	var i Stock
+	err := row.Scan(
+		&i.ID,
+		&i.ProductID,
+		&i.Quantity,
+		&i.ReservedQuantity,
+		&i.Location,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.RentalEnabled,
+	)
+	return &i, err
+}
+
+const createStockAdjustment = `-- name: CreateStockAdjustment :one
+INSERT INTO stock_adjustments (stock_id, quantity_delta, reason, note, status, requested_by, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, NOW())
+RETURNING id, stock_id, quantity_delta, reason, note, status, requested_by, reviewed_by, review_note, created_at, reviewed_at
+`
+
+type CreateStockAdjustmentParams struct {
+	StockID       uint64                `json:"stockId"`
+	QuantityDelta int32                 `json:"quantityDelta"`
+	Reason        StockMovementReason   `json:"reason"`
+	Note          *string               `json:"note"`
+	Status        StockAdjustmentStatus `json:"status"`
+	RequestedBy   string                `json:"requestedBy"`
+}
+
+func (q *Queries) CreateStockAdjustment(ctx context.Context, arg CreateStockAdjustmentParams) (*StockAdjustment, error) {
+	row := q.db.QueryRow(ctx, createStockAdjustment,
+		arg.StockID,
+		arg.QuantityDelta,
+		arg.Reason,
+		arg.Note,
+		arg.Status,
+		arg.RequestedBy,
+	)
+	var i StockAdjustment
+	err := row.Scan(
+		&i.ID,
+		&i.StockID,
+		&i.QuantityDelta,
+		&i.Reason,
+		&i.Note,
+		&i.Status,
+		&i.RequestedBy,
+		&i.ReviewedBy,
+		&i.ReviewNote,
+		&i.CreatedAt,
+		&i.ReviewedAt,
+	)
+	return &i, err
+}
+
+const createStockHold = `-- name: CreateStockHold :one
+INSERT INTO stock_holds (stock_id, quantity, reason, expires_at, created_at)
+VALUES ($1, $2, $3, $4, NOW())
+RETURNING id, stock_id, quantity, reason, expires_at, released_at, created_at
+`
+
+type CreateStockHoldParams struct {
+	StockID   uint64             `json:"stockId"`
+	Quantity  uint64             `json:"quantity"`
+	Reason    string             `json:"reason"`
+	ExpiresAt pgtype.Timestamptz `json:"expiresAt"`
+}
+
+func (q *Queries) CreateStockHold(ctx context.Context, arg CreateStockHoldParams) (*StockHold, error) {
+	row := q.db.QueryRow(ctx, createStockHold,
+		arg.StockID,
+		arg.Quantity,
+		arg.Reason,
+		arg.ExpiresAt,
+	)
+	var i StockHold
+	err := row.Scan(
+		&i.ID,
+		&i.StockID,
+		&i.Quantity,
+		&i.Reason,
+		&i.ExpiresAt,
+		&i.ReleasedAt,
+		&i.CreatedAt,
+	)
+	return &i, err
+}
+
+const createStockMovementReversal = `-- name: CreateStockMovementReversal :one
+INSERT INTO stock_movements (stock_id, quantity, type, reference_id, reference_type, actor, note, unit_cost, reason, reversal_of_id, reference_item_id, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NOW())
+RETURNING id, stock_id, quantity, type, reference_id, reference_type, created_at, actor, note, unit_cost, reason, reversal_of_id, reference_item_id
+`
+
+type CreateStockMovementReversalParams struct {
+	StockID         uint64                         `json:"stockId"`
+	Quantity        uint64                         `json:"quantity"`
+	Type            StockMovementType              `json:"type"`
+	ReferenceID     *int32                         `json:"referenceId"`
+	ReferenceType   NullStockMovementReferenceType `json:"referenceType"`
+	Actor           *string                        `json:"actor"`
+	Note            *string                        `json:"note"`
+	UnitCost        *float64                       `json:"unitCost"`
+	Reason          NullStockMovementReason        `json:"reason"`
+	ReversalOfID    *int32                         `json:"reversalOfId"`
+	ReferenceItemID *int32                         `json:"referenceItemId"`
+}
+
+func (q *Queries) CreateStockMovementReversal(ctx context.Context, arg CreateStockMovementReversalParams) (*StockMovement, error) {
+	row := q.db.QueryRow(ctx, createStockMovementReversal,
+		arg.StockID,
+		arg.Quantity,
+		arg.Type,
+		arg.ReferenceID,
+		arg.ReferenceType,
+		arg.Actor,
+		arg.Note,
+		arg.UnitCost,
+		arg.Reason,
+		arg.ReversalOfID,
+		arg.ReferenceItemID,
+	)
+	var i StockMovement
+	err := row.Scan(
+		&i.ID,
+		&i.StockID,
+		&i.Quantity,
+		&i.Type,
+		&i.ReferenceID,
+		&i.ReferenceType,
+		&i.CreatedAt,
+		&i.Actor,
+		&i.Note,
+		&i.UnitCost,
+		&i.Reason,
+		&i.ReversalOfID,
+		&i.ReferenceItemID,
+	)
+	return &i, err
+}
+
+const createStockRental = `-- name: CreateStockRental :one
+INSERT INTO stock_rentals (stock_id, customer_id, quantity, starts_on, ends_on, status, note, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, 'reserved', $6, NOW(), NOW())
+RETURNING id, stock_id, customer_id, quantity, starts_on, ends_on, status, note, created_at, updated_at
+`
+
+type CreateStockRentalParams struct {
+	StockID    uint64      `json:"stockId"`
+	CustomerID string      `json:"customerId"`
+	Quantity   uint64      `json:"quantity"`
+	StartsOn   pgtype.Date `json:"startsOn"`
+	EndsOn     pgtype.Date `json:"endsOn"`
+	Note       *string     `json:"note"`
+}
+
+func (q *Queries) CreateStockRental(ctx context.Context, arg CreateStockRentalParams) (*StockRental, error) {
+	row := q.db.QueryRow(ctx, createStockRental,
+		arg.StockID,
+		arg.CustomerID,
+		arg.Quantity,
+		arg.StartsOn,
+		arg.EndsOn,
+		arg.Note,
+	)
+	var i StockRental
+	err := row.Scan(
+		&i.ID,
+		&i.StockID,
+		&i.CustomerID,
+		&i.Quantity,
+		&i.StartsOn,
+		&i.EndsOn,
+		&i.Status,
+		&i.Note,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return &i, err
+}
+
+const createStockTransfer = `-- name: CreateStockTransfer :one
+INSERT INTO stock_transfers (from_stock_id, to_stock_id, quantity, note, status, requested_by, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, NOW())
+RETURNING id, from_stock_id, to_stock_id, quantity, note, status, requested_by, reviewed_by, review_note, created_at, reviewed_at
+`
+
+type CreateStockTransferParams struct {
+	FromStockID int32               `json:"fromStockId"`
+	ToStockID   int32               `json:"toStockId"`
+	Quantity    uint64              `json:"quantity"`
+	Note        *string             `json:"note"`
+	Status      StockTransferStatus `json:"status"`
+	RequestedBy string              `json:"requestedBy"`
+}
+
+func (q *Queries) CreateStockTransfer(ctx context.Context, arg CreateStockTransferParams) (*StockTransfer, error) {
+	row := q.db.QueryRow(ctx, createStockTransfer,
+		arg.FromStockID,
+		arg.ToStockID,
+		arg.Quantity,
+		arg.Note,
+		arg.Status,
+		arg.RequestedBy,
+	)
+	var i StockTransfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromStockID,
+		&i.ToStockID,
+		&i.Quantity,
+		&i.Note,
+		&i.Status,
+		&i.RequestedBy,
+		&i.ReviewedBy,
+		&i.ReviewNote,
+		&i.CreatedAt,
+		&i.ReviewedAt,
+	)
+	return &i, err
+}
+
+const createStocktake = `-- name: CreateStocktake :one
+INSERT INTO stocktakes (location, started_by, created_at)
+VALUES ($1, $2, NOW())
+RETURNING id, location, status, started_by, finished_by, created_at, finished_at
+`
+
+type CreateStocktakeParams struct {
+	Location  string `json:"location"`
+	StartedBy string `json:"startedBy"`
+}
+
+func (q *Queries) CreateStocktake(ctx context.Context, arg CreateStocktakeParams) (*Stocktake, error) {
+	row := q.db.QueryRow(ctx, createStocktake, arg.Location, arg.StartedBy)
+	var i Stocktake
+	err := row.Scan(
+		&i.ID,
+		&i.Location,
+		&i.Status,
+		&i.StartedBy,
+		&i.FinishedBy,
+		&i.CreatedAt,
+		&i.FinishedAt,
+	)
+	return &i, err
+}
+
+const createStore = `-- name: CreateStore :one
+INSERT INTO stores (name, location, address, latitude, longitude, hours, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
+RETURNING id, name, location, address, latitude, longitude, hours, created_at, updated_at
+`
+
+type CreateStoreParams struct {
+	Name      string  `json:"name"`
+	Location  string  `json:"location"`
+	Address   []byte  `json:"address"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Hours     []byte  `json:"hours"`
+}
+
+func (q *Queries) CreateStore(ctx context.Context, arg CreateStoreParams) (*Store, error) {
+	row := q.db.QueryRow(ctx, createStore,
+		arg.Name,
+		arg.Location,
+		arg.Address,
+		arg.Latitude,
+		arg.Longitude,
+		arg.Hours,
+	)
+	var i Store
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Location,
+		&i.Address,
+		&i.Latitude,
+		&i.Longitude,
+		&i.Hours,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return &i, err
+}
+
+const deleteStockProjection = `-- name: DeleteStockProjection :execrows
+DELETE FROM stock_projections
+WHERE stock_id = $1
+`
+
+func (q *Queries) DeleteStockProjection(ctx context.Context, stockID uint64) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteStockProjection, stockID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const enableStockEventSourcing = `-- name: EnableStockEventSourcing :one
+INSERT INTO stock_projections (stock_id, base_quantity, base_reserved_quantity, base_movement_id, projected_movement_id, enabled_at, projected_at)
+SELECT s.id, s.quantity, s.reserved_quantity, COALESCE(MAX(m.id), 0), COALESCE(MAX(m.id), 0), NOW(), NOW()
+FROM stocks s
+LEFT JOIN stock_movements m ON m.stock_id = s.id
+WHERE s.id = $1
+GROUP BY s.id
+ON CONFLICT (stock_id) DO NOTHING
+RETURNING stock_id, base_quantity, base_reserved_quantity, base_movement_id, projected_movement_id, enabled_at, projected_at
+`
+
+func (q *Queries) EnableStockEventSourcing(ctx context.Context, id int32) (*StockProjection, error) {
+	row := q.db.QueryRow(ctx, enableStockEventSourcing, id)
+	var i StockProjection
+	err := row.Scan(
+		&i.StockID,
+		&i.BaseQuantity,
+		&i.BaseReservedQuantity,
+		&i.BaseMovementID,
+		&i.ProjectedMovementID,
+		&i.EnabledAt,
+		&i.ProjectedAt,
+	)
+	return &i, err
+}
+
+const findStoresWithinRadius = `-- name: FindStoresWithinRadius :many
+SELECT id, name, location, address, latitude, longitude, hours, created_at, updated_at, distance_km::float8 AS distance_km
+FROM (
+    SELECT id, name, location, address, latitude, longitude, hours, created_at, updated_at,
+           6371 * 2 * ASIN(LEAST(1, SQRT(
+               POWER(SIN(RADIANS(latitude - $1::float8) / 2), 2) +
+               COS(RADIANS($1::float8)) * COS(RADIANS(latitude)) *
+               POWER(SIN(RADIANS(longitude - $2::float8) / 2), 2)
+           ))) AS distance_km
+    FROM stores
+) AS s
+WHERE distance_km <= $3::float8
+ORDER BY distance_km, id
+`
+
+type FindStoresWithinRadiusParams struct {
+	Lat      float64 `json:"lat"`
+	Lng      float64 `json:"lng"`
+	RadiusKm float64 `json:"radiusKm"`
+}
+
+type FindStoresWithinRadiusRow struct {
+	ID         int32              `json:"id"`
+	Name       string             `json:"name"`
+	Location   string             `json:"location"`
+	Address    []byte             `json:"address"`
+	Latitude   float64            `json:"latitude"`
+	Longitude  float64            `json:"longitude"`
+	Hours      []byte             `json:"hours"`
+	CreatedAt  pgtype.Timestamptz `json:"createdAt"`
+	UpdatedAt  pgtype.Timestamptz `json:"updatedAt"`
+	DistanceKm float64            `json:"distanceKm"`
+}
+
+func (q *Queries) FindStoresWithinRadius(ctx context.Context, arg FindStoresWithinRadiusParams) ([]*FindStoresWithinRadiusRow, error) {
+	rows, err := q.db.Query(ctx, findStoresWithinRadius, arg.Lat, arg.Lng, arg.RadiusKm)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*FindStoresWithinRadiusRow{}
+	for rows.Next() {
+		var i FindStoresWithinRadiusRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Location,
+			&i.Address,
+			&i.Latitude,
+			&i.Longitude,
+			&i.Hours,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DistanceKm,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const finishStocktake = `-- name: FinishStocktake :execrows
+UPDATE stocktakes
+SET status = $2, finished_by = $3, finished_at = NOW()
+WHERE id = $1 AND status = 'open'
+`
+
+type FinishStocktakeParams struct {
+	ID         int32           `json:"id"`
+	Status     StocktakeStatus `json:"status"`
+	FinishedBy *string         `json:"finishedBy"`
+}
+
+func (q *Queries) FinishStocktake(ctx context.Context, arg FinishStocktakeParams) (int64, error) {
+	result, err := q.db.Exec(ctx, finishStocktake, arg.ID, arg.Status, arg.FinishedBy)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const getStock = `-- name: GetStock :one
-SELECT id, product_id, quantity, reserved_quantity, location, created_at, updated_at
+SELECT id, product_id, quantity, reserved_quantity, location, created_at, updated_at, rental_enabled
 FROM stocks
 WHERE id = $1
 `
@@ -26,12 +556,157 @@ func (q *Queries) GetStock(ctx context.Context, id int32) (*Stock, error) {
 		&i.Location,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.RentalEnabled,
+	)
+	return &i, err
+}
+
+const getStockAdjustment = `-- name: GetStockAdjustment :one
+SELECT id, stock_id, quantity_delta, reason, note, status, requested_by, reviewed_by, review_note, created_at, reviewed_at
+FROM stock_adjustments
+WHERE id = $1
+`
+
+func (q *Queries) GetStockAdjustment(ctx context.Context, id int32) (*StockAdjustment, error) {
+	row := q.db.QueryRow(ctx, getStockAdjustment, id)
+	var i StockAdjustment
+	err := row.Scan(
+		&i.ID,
+		&i.StockID,
+		&i.QuantityDelta,
+		&i.Reason,
+		&i.Note,
+		&i.Status,
+		&i.RequestedBy,
+		&i.ReviewedBy,
+		&i.ReviewNote,
+		&i.CreatedAt,
+		&i.ReviewedAt,
+	)
+	return &i, err
+}
+
+const getStockByProductAndLocation = `-- name: GetStockByProductAndLocation :one
+SELECT id, product_id, quantity, reserved_quantity, location, created_at, updated_at, rental_enabled
+FROM stocks
+WHERE product_id = $1 AND location = $2
+LIMIT 1
+`
+
+type GetStockByProductAndLocationParams struct {
+	ProductID string  `json:"productId"`
+	Location  *string `json:"location"`
+}
+
+func (q *Queries) GetStockByProductAndLocation(ctx context.Context, arg GetStockByProductAndLocationParams) (*Stock, error) {
+	row := q.db.QueryRow(ctx, getStockByProductAndLocation, arg.ProductID, arg.Location)
+	var i Stock
+	err := row.Scan(
+		&i.ID,
+		&i.ProductID,
+		&i.Quantity,
+		&i.ReservedQuantity,
+		&i.Location,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.RentalEnabled,
+	)
+	return &i, err
+}
+
+const getStockHold = `-- name: GetStockHold :one
+SELECT id, stock_id, quantity, reason, expires_at, released_at, created_at
+FROM stock_holds
+WHERE id = $1
+`
+
+func (q *Queries) GetStockHold(ctx context.Context, id int32) (*StockHold, error) {
+	row := q.db.QueryRow(ctx, getStockHold, id)
+	var i StockHold
+	err := row.Scan(
+		&i.ID,
+		&i.StockID,
+		&i.Quantity,
+		&i.Reason,
+		&i.ExpiresAt,
+		&i.ReleasedAt,
+		&i.CreatedAt,
+	)
+	return &i, err
+}
+
+const getStockLevelAt = `-- name: GetStockLevelAt :one
+SELECT s.id, s.product_id, s.location, s.created_at,
+       (s.quantity - COALESCE(SUM(CASE m.type WHEN 'in' THEN m.quantity WHEN 'out' THEN -m.quantity ELSE 0 END), 0))::bigint AS quantity,
+       (s.reserved_quantity - COALESCE(SUM(CASE
+           WHEN m.type = 'reserve' THEN m.quantity
+           WHEN m.type = 'release' THEN -m.quantity
+           WHEN m.type = 'out' AND m.reference_type = 'order' THEN -m.quantity
+           ELSE 0 END), 0))::bigint AS reserved_quantity
+FROM stocks s
+LEFT JOIN stock_movements m ON m.stock_id = s.id AND m.created_at > $1
+WHERE s.id = $2
+GROUP BY s.id
+`
+
+type GetStockLevelAtParams struct {
+	At      pgtype.Timestamptz `json:"at"`
+	StockID int32              `json:"stockId"`
+}
+
+type GetStockLevelAtRow struct {
+	ID               int32              `json:"id"`
+	ProductID        string             `json:"productId"`
+	Location         *string            `json:"location"`
+	CreatedAt        pgtype.Timestamptz `json:"createdAt"`
+	Quantity         int64              `json:"quantity"`
+	ReservedQuantity int64              `json:"reservedQuantity"`
+}
+
+func (q *Queries) GetStockLevelAt(ctx context.Context, arg GetStockLevelAtParams) (*GetStockLevelAtRow, error) {
+	row := q.db.QueryRow(ctx, getStockLevelAt, arg.At, arg.StockID)
+	var i GetStockLevelAtRow
+	err := row.Scan(
+		&i.ID,
+		&i.ProductID,
+		&i.Location,
+		&i.CreatedAt,
+		&i.Quantity,
+		&i.ReservedQuantity,
+	)
+	return &i, err
+}
+
+const getStockMovementForUpdate = `-- name: GetStockMovementForUpdate :one
+SELECT id, stock_id, quantity, type, reference_id, reference_type, created_at, actor, note, unit_cost, reason, reversal_of_id, reference_item_id
+FROM stock_movements
+WHERE id = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetStockMovementForUpdate(ctx context.Context, id int32) (*StockMovement, error) {
+	row := q.db.QueryRow(ctx, getStockMovementForUpdate, id)
+	var i StockMovement
+	err := row.Scan(
+		&i.ID,
+		&i.StockID,
+		&i.Quantity,
+		&i.Type,
+		&i.ReferenceID,
+		&i.ReferenceType,
+		&i.CreatedAt,
+		&i.Actor,
+		&i.Note,
+		&i.UnitCost,
+		&i.Reason,
+		&i.ReversalOfID,
+		&i.ReferenceItemID,
 	)
 	return &i, err
 }
 
 const getStockMovementsByReference = `-- name: GetStockMovementsByReference :many
-SELECT id, stock_id, quantity, type, reference_id, reference_type, created_at
+SELECT id, stock_id, quantity, type, reference_id, reference_type, created_at, actor, note, unit_cost, reason, reversal_of_id, reference_item_id
 FROM stock_movements
 WHERE reference_type = $1 AND reference_id = $2
 ORDER BY created_at DESC
@@ -42,22 +717,442 @@ type GetStockMovementsByReferenceParams struct {
 	ReferenceID   *int32                         `json:"referenceId"`
 }
 
-func (q *Queries) GetStockMovementsByReference(ctx context.Context, arg GetStockMovementsByReferenceParams) ([]*StockMovement, error) {
-	rows, err := q.db.Query(ctx, getStockMovementsByReference, arg.ReferenceType, arg.ReferenceID)
+func (q *Queries) GetStockMovementsByReference(ctx context.Context, arg GetStockMovementsByReferenceParams) ([]*StockMovement, error) {
+	rows, err := q.db.Query(ctx, getStockMovementsByReference, arg.ReferenceType, arg.ReferenceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*StockMovement{}
+	for rows.Next() {
+		var i StockMovement
+		if err := rows.Scan(
+			&i.ID,
+			&i.StockID,
+			&i.Quantity,
+			&i.Type,
+			&i.ReferenceID,
+			&i.ReferenceType,
+			&i.CreatedAt,
+			&i.Actor,
+			&i.Note,
+			&i.UnitCost,
+			&i.Reason,
+			&i.ReversalOfID,
+			&i.ReferenceItemID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getStockProjection = `-- name: GetStockProjection :one
+SELECT stock_id, base_quantity, base_reserved_quantity, base_movement_id, projected_movement_id, enabled_at, projected_at
+FROM stock_projections
+WHERE stock_id = $1
+`
+
+func (q *Queries) GetStockProjection(ctx context.Context, stockID uint64) (*StockProjection, error) {
+	row := q.db.QueryRow(ctx, getStockProjection, stockID)
+	var i StockProjection
+	err := row.Scan(
+		&i.StockID,
+		&i.BaseQuantity,
+		&i.BaseReservedQuantity,
+		&i.BaseMovementID,
+		&i.ProjectedMovementID,
+		&i.EnabledAt,
+		&i.ProjectedAt,
+	)
+	return &i, err
+}
+
+const getStockRental = `-- name: GetStockRental :one
+SELECT id, stock_id, customer_id, quantity, starts_on, ends_on, status, note, created_at, updated_at
+FROM stock_rentals
+WHERE id = $1
+`
+
+func (q *Queries) GetStockRental(ctx context.Context, id int32) (*StockRental, error) {
+	row := q.db.QueryRow(ctx, getStockRental, id)
+	var i StockRental
+	err := row.Scan(
+		&i.ID,
+		&i.StockID,
+		&i.CustomerID,
+		&i.Quantity,
+		&i.StartsOn,
+		&i.EndsOn,
+		&i.Status,
+		&i.Note,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return &i, err
+}
+
+const getStockTransfer = `-- name: GetStockTransfer :one
+SELECT id, from_stock_id, to_stock_id, quantity, note, status, requested_by, reviewed_by, review_note, created_at, reviewed_at
+FROM stock_transfers
+WHERE id = $1
+`
+
+func (q *Queries) GetStockTransfer(ctx context.Context, id int32) (*StockTransfer, error) {
+	row := q.db.QueryRow(ctx, getStockTransfer, id)
+	var i StockTransfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromStockID,
+		&i.ToStockID,
+		&i.Quantity,
+		&i.Note,
+		&i.Status,
+		&i.RequestedBy,
+		&i.ReviewedBy,
+		&i.ReviewNote,
+		&i.CreatedAt,
+		&i.ReviewedAt,
+	)
+	return &i, err
+}
+
+const getStocktake = `-- name: GetStocktake :one
+SELECT id, location, status, started_by, finished_by, created_at, finished_at
+FROM stocktakes
+WHERE id = $1
+`
+
+func (q *Queries) GetStocktake(ctx context.Context, id int32) (*Stocktake, error) {
+	row := q.db.QueryRow(ctx, getStocktake, id)
+	var i Stocktake
+	err := row.Scan(
+		&i.ID,
+		&i.Location,
+		&i.Status,
+		&i.StartedBy,
+		&i.FinishedBy,
+		&i.CreatedAt,
+		&i.FinishedAt,
+	)
+	return &i, err
+}
+
+const getUnprojectedStockDelta = `-- name: GetUnprojectedStockDelta :one
+SELECT (COUNT(p.stock_id) > 0)::boolean AS event_sourced,
+       COALESCE(SUM(CASE m.type WHEN 'in' THEN m.quantity WHEN 'out' THEN -m.quantity ELSE 0 END), 0)::bigint AS quantity_delta,
+       COALESCE(SUM(CASE
+           WHEN m.type = 'reserve' THEN m.quantity
+           WHEN m.type = 'release' THEN -m.quantity
+           WHEN m.type = 'out' AND m.reference_type = 'order' AND m.reversal_of_id IS NULL THEN -m.quantity
+           ELSE 0 END), 0)::bigint AS reserved_quantity_delta
+FROM stock_projections p
+LEFT JOIN stock_movements m ON m.stock_id = p.stock_id AND m.id > p.projected_movement_id
+WHERE p.stock_id = $1
+`
+
+type GetUnprojectedStockDeltaRow struct {
+	EventSourced          bool  `json:"eventSourced"`
+	QuantityDelta         int64 `json:"quantityDelta"`
+	ReservedQuantityDelta int64 `json:"reservedQuantityDelta"`
+}
+
+func (q *Queries) GetUnprojectedStockDelta(ctx context.Context, stockID uint64) (*GetUnprojectedStockDeltaRow, error) {
+	row := q.db.QueryRow(ctx, getUnprojectedStockDelta, stockID)
+	var i GetUnprojectedStockDeltaRow
+	err := row.Scan(
+		&i.EventSourced,
+		&i.QuantityDelta,
+		&i.ReservedQuantityDelta,
+	)
+	return &i, err
+}
+
+const listExpiredStockHolds = `-- name: ListExpiredStockHolds :many
+SELECT id, stock_id, quantity, reason, expires_at, released_at, created_at
+FROM stock_holds
+WHERE released_at IS NULL AND expires_at IS NOT NULL AND expires_at <= $1
+ORDER BY expires_at
+`
+
+func (q *Queries) ListExpiredStockHolds(ctx context.Context, expiresAt pgtype.Timestamptz) ([]*StockHold, error) {
+	rows, err := q.db.Query(ctx, listExpiredStockHolds, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*StockHold{}
+	for rows.Next() {
+		var i StockHold
+		if err := rows.Scan(
+			&i.ID,
+			&i.StockID,
+			&i.Quantity,
+			&i.Reason,
+			&i.ExpiresAt,
+			&i.ReleasedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listLowStocks = `-- name: ListLowStocks :many
+SELECT id, product_id, quantity, reserved_quantity, location, created_at, updated_at, rental_enabled
+FROM stocks
+WHERE quantity - reserved_quantity <= $1::int
+ORDER BY quantity - reserved_quantity, id
+LIMIT $2
+`
+
+type ListLowStocksParams struct {
+	Threshold int32 `json:"threshold"`
+	RowLimit  int32 `json:"rowLimit"`
+}
+
+func (q *Queries) ListLowStocks(ctx context.Context, arg ListLowStocksParams) ([]*Stock, error) {
+	rows, err := q.db.Query(ctx, listLowStocks, arg.Threshold, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*Stock{}
+	for rows.Next() {
+		var i Stock
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProductID,
+			&i.Quantity,
+			&i.ReservedQuantity,
+			&i.Location,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.RentalEnabled,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOverReservedStocks = `-- name: ListOverReservedStocks :many
+SELECT id, product_id, quantity, reserved_quantity, location, created_at, updated_at, rental_enabled
+FROM stocks
+WHERE reserved_quantity > quantity OR reserved_quantity < 0
+ORDER BY id
+`
+
+func (q *Queries) ListOverReservedStocks(ctx context.Context) ([]*Stock, error) {
+	rows, err := q.db.Query(ctx, listOverReservedStocks)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*Stock{}
+	for rows.Next() {
+		var i Stock
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProductID,
+			&i.Quantity,
+			&i.ReservedQuantity,
+			&i.Location,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.RentalEnabled,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProductAvailability = `-- name: ListProductAvailability :many
+SELECT product_id, quantity, reserved_quantity, available_quantity, location_count, refreshed_at
+FROM product_availability
+WHERE product_id = ANY($1::text[])
+ORDER BY product_id
+`
+
+func (q *Queries) ListProductAvailability(ctx context.Context, productIds []string) ([]*ProductAvailability, error) {
+	rows, err := q.db.Query(ctx, listProductAvailability, productIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*ProductAvailability{}
+	for rows.Next() {
+		var i ProductAvailability
+		if err := rows.Scan(
+			&i.ProductID,
+			&i.Quantity,
+			&i.ReservedQuantity,
+			&i.AvailableQuantity,
+			&i.LocationCount,
+			&i.RefreshedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProductBarcodes = `-- name: ListProductBarcodes :many
+SELECT barcode, product_id, created_at
+FROM product_barcodes
+WHERE barcode = ANY($1::text[])
+`
+
+func (q *Queries) ListProductBarcodes(ctx context.Context, barcodes []string) ([]*ProductBarcode, error) {
+	rows, err := q.db.Query(ctx, listProductBarcodes, barcodes)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*ProductBarcode{}
+	for rows.Next() {
+		var i ProductBarcode
+		if err := rows.Scan(
+			&i.Barcode,
+			&i.ProductID,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRentalBookings = `-- name: ListRentalBookings :many
+SELECT d::date AS day, COALESCE(SUM(r.quantity), 0)::bigint AS booked
+FROM generate_series($1::date, $2::date - 1, interval '1 day') AS d
+LEFT JOIN stock_rentals r ON r.stock_id = $3 AND r.status = 'reserved' AND r.starts_on <= d AND r.ends_on > d
+GROUP BY d
+ORDER BY d
+`
+
+type ListRentalBookingsParams struct {
+	RangeStart pgtype.Date `json:"rangeStart"`
+	RangeEnd   pgtype.Date `json:"rangeEnd"`
+	StockID    uint64      `json:"stockId"`
+}
+
+type ListRentalBookingsRow struct {
+	Day    pgtype.Date `json:"day"`
+	Booked int64       `json:"booked"`
+}
+
+func (q *Queries) ListRentalBookings(ctx context.Context, arg ListRentalBookingsParams) ([]*ListRentalBookingsRow, error) {
+	rows, err := q.db.Query(ctx, listRentalBookings, arg.RangeStart, arg.RangeEnd, arg.StockID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*ListRentalBookingsRow{}
+	for rows.Next() {
+		var i ListRentalBookingsRow
+		if err := rows.Scan(
+			&i.Day,
+			&i.Booked,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listStockAdjustmentsByStatus = `-- name: ListStockAdjustmentsByStatus :many
+SELECT id, stock_id, quantity_delta, reason, note, status, requested_by, reviewed_by, review_note, created_at, reviewed_at
+FROM stock_adjustments
+WHERE status = $1
+ORDER BY created_at
+`
+
+func (q *Queries) ListStockAdjustmentsByStatus(ctx context.Context, status StockAdjustmentStatus) ([]*StockAdjustment, error) {
+	rows, err := q.db.Query(ctx, listStockAdjustmentsByStatus, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*StockAdjustment{}
+	for rows.Next() {
+		var i StockAdjustment
+		if err := rows.Scan(
+			&i.ID,
+			&i.StockID,
+			&i.QuantityDelta,
+			&i.Reason,
+			&i.Note,
+			&i.Status,
+			&i.RequestedBy,
+			&i.ReviewedBy,
+			&i.ReviewNote,
+			&i.CreatedAt,
+			&i.ReviewedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listStockHolds = `-- name: ListStockHolds :many
+SELECT id, stock_id, quantity, reason, expires_at, released_at, created_at
+FROM stock_holds
+WHERE stock_id = $1 AND released_at IS NULL
+ORDER BY created_at DESC
+`
+
+func (q *Queries) ListStockHolds(ctx context.Context, stockID uint64) ([]*StockHold, error) {
+	rows, err := q.db.Query(ctx, listStockHolds, stockID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []*StockMovement{}
+	items := []*StockHold{}
 	for rows.Next() {
-		var i StockMovement
+		var i StockHold
 		if err := rows.Scan(
 			&i.ID,
 			&i.StockID,
 			&i.Quantity,
-			&i.Type,
-			&i.ReferenceID,
-			&i.ReferenceType,
+			&i.Reason,
+			&i.ExpiresAt,
+			&i.ReleasedAt,
 			&i.CreatedAt,
 		); err != nil {
 			return nil, err
@@ -71,7 +1166,7 @@ func (q *Queries) GetStockMovementsByReference(ctx context.Context, arg GetStock
 }
 
 const listStockMovements = `-- name: ListStockMovements :many
-SELECT id, stock_id, quantity, type, reference_id, reference_type, created_at
+SELECT id, stock_id, quantity, type, reference_id, reference_type, created_at, actor, note, unit_cost, reason, reversal_of_id, reference_item_id
 FROM stock_movements
 WHERE stock_id = $1
 ORDER BY created_at DESC
@@ -101,6 +1196,294 @@ func (q *Queries) ListStockMovements(ctx context.Context, arg ListStockMovements
 			&i.ReferenceID,
 			&i.ReferenceType,
 			&i.CreatedAt,
+			&i.Actor,
+			&i.Note,
+			&i.UnitCost,
+			&i.Reason,
+			&i.ReversalOfID,
+			&i.ReferenceItemID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listStockOutflows = `-- name: ListStockOutflows :many
+SELECT s.id, s.product_id, s.location, s.quantity, s.reserved_quantity,
+       COALESCE(SUM(m.quantity), 0)::bigint AS out_quantity
+FROM stocks s
+LEFT JOIN stock_movements m ON m.stock_id = s.id AND m.type = 'out' AND m.reference_type = 'order'
+    AND m.created_at >= $1 AND m.reversal_of_id IS NULL
+    AND NOT EXISTS (SELECT 1 FROM stock_movements r WHERE r.reversal_of_id = m.id)
+WHERE s.location IS NOT NULL AND NOT s.rental_enabled
+  AND s.product_id IN (
+      SELECT product_id FROM stocks
+      WHERE location IS NOT NULL AND NOT rental_enabled
+      GROUP BY product_id
+      HAVING COUNT(*) > 1
+  )
+GROUP BY s.id
+ORDER BY s.product_id, s.id
+`
+
+type ListStockOutflowsRow struct {
+	ID               int32   `json:"id"`
+	ProductID        string  `json:"productId"`
+	Location         *string `json:"location"`
+	Quantity         uint64  `json:"quantity"`
+	ReservedQuantity int32   `json:"reservedQuantity"`
+	OutQuantity      int64   `json:"outQuantity"`
+}
+
+func (q *Queries) ListStockOutflows(ctx context.Context, since pgtype.Timestamptz) ([]*ListStockOutflowsRow, error) {
+	rows, err := q.db.Query(ctx, listStockOutflows, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*ListStockOutflowsRow{}
+	for rows.Next() {
+		var i ListStockOutflowsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProductID,
+			&i.Location,
+			&i.Quantity,
+			&i.ReservedQuantity,
+			&i.OutQuantity,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listStockRentals = `-- name: ListStockRentals :many
+SELECT id, stock_id, customer_id, quantity, starts_on, ends_on, status, note, created_at, updated_at
+FROM stock_rentals
+WHERE stock_id = $1 AND status = 'reserved' AND ends_on > $2::date AND starts_on < $3::date
+ORDER BY starts_on, id
+`
+
+type ListStockRentalsParams struct {
+	StockID    uint64      `json:"stockId"`
+	RangeStart pgtype.Date `json:"rangeStart"`
+	RangeEnd   pgtype.Date `json:"rangeEnd"`
+}
+
+func (q *Queries) ListStockRentals(ctx context.Context, arg ListStockRentalsParams) ([]*StockRental, error) {
+	rows, err := q.db.Query(ctx, listStockRentals, arg.StockID, arg.RangeStart, arg.RangeEnd)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*StockRental{}
+	for rows.Next() {
+		var i StockRental
+		if err := rows.Scan(
+			&i.ID,
+			&i.StockID,
+			&i.CustomerID,
+			&i.Quantity,
+			&i.StartsOn,
+			&i.EndsOn,
+			&i.Status,
+			&i.Note,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listStockTransfersByStatus = `-- name: ListStockTransfersByStatus :many
+SELECT id, from_stock_id, to_stock_id, quantity, note, status, requested_by, reviewed_by, review_note, created_at, reviewed_at
+FROM stock_transfers
+WHERE status = $1
+ORDER BY created_at
+`
+
+func (q *Queries) ListStockTransfersByStatus(ctx context.Context, status StockTransferStatus) ([]*StockTransfer, error) {
+	rows, err := q.db.Query(ctx, listStockTransfersByStatus, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*StockTransfer{}
+	for rows.Next() {
+		var i StockTransfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromStockID,
+			&i.ToStockID,
+			&i.Quantity,
+			&i.Note,
+			&i.Status,
+			&i.RequestedBy,
+			&i.ReviewedBy,
+			&i.ReviewNote,
+			&i.CreatedAt,
+			&i.ReviewedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listStocksByProductID = `-- name: ListStocksByProductID :many
+SELECT id, product_id, quantity, reserved_quantity, location, created_at, updated_at, rental_enabled
+FROM stocks
+WHERE product_id = $1
+ORDER BY quantity - reserved_quantity DESC
+`
+
+func (q *Queries) ListStocksByProductID(ctx context.Context, productID string) ([]*Stock, error) {
+	rows, err := q.db.Query(ctx, listStocksByProductID, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*Stock{}
+	for rows.Next() {
+		var i Stock
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProductID,
+			&i.Quantity,
+			&i.ReservedQuantity,
+			&i.Location,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.RentalEnabled,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listStocksPendingProjection = `-- name: ListStocksPendingProjection :many
+SELECT p.stock_id
+FROM stock_projections p
+WHERE EXISTS (SELECT 1 FROM stock_movements m WHERE m.stock_id = p.stock_id AND m.id > p.projected_movement_id)
+ORDER BY p.projected_at
+LIMIT $1
+`
+
+func (q *Queries) ListStocksPendingProjection(ctx context.Context, limit int32) ([]uint64, error) {
+	rows, err := q.db.Query(ctx, listStocksPendingProjection, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []uint64{}
+	for rows.Next() {
+		var stock_id uint64
+		if err := rows.Scan(&stock_id); err != nil {
+			return nil, err
+		}
+		items = append(items, stock_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listStocktakeCounts = `-- name: ListStocktakeCounts :many
+SELECT s.id AS stock_id, s.product_id, s.quantity, s.reserved_quantity,
+       COALESCE(SUM(c.quantity), 0)::bigint AS counted_quantity
+FROM stocktakes t
+JOIN stocks s ON s.location = t.location
+LEFT JOIN stocktake_scans c ON c.stocktake_id = t.id AND c.stock_id = s.id
+WHERE t.id = $1
+GROUP BY s.id
+ORDER BY s.product_id, s.id
+`
+
+type ListStocktakeCountsRow struct {
+	StockID          uint64 `json:"stockId"`
+	ProductID        string `json:"productId"`
+	Quantity         uint64 `json:"quantity"`
+	ReservedQuantity int32  `json:"reservedQuantity"`
+	CountedQuantity  int64  `json:"countedQuantity"`
+}
+
+func (q *Queries) ListStocktakeCounts(ctx context.Context, stocktakeID int32) ([]*ListStocktakeCountsRow, error) {
+	rows, err := q.db.Query(ctx, listStocktakeCounts, stocktakeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*ListStocktakeCountsRow{}
+	for rows.Next() {
+		var i ListStocktakeCountsRow
+		if err := rows.Scan(
+			&i.StockID,
+			&i.ProductID,
+			&i.Quantity,
+			&i.ReservedQuantity,
+			&i.CountedQuantity,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listStores = `-- name: ListStores :many
+SELECT id, name, location, address, latitude, longitude, hours, created_at, updated_at
+FROM stores
+ORDER BY name, id
+`
+
+func (q *Queries) ListStores(ctx context.Context) ([]*Store, error) {
+	rows, err := q.db.Query(ctx, listStores)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*Store{}
+	for rows.Next() {
+		var i Store
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Location,
+			&i.Address,
+			&i.Latitude,
+			&i.Longitude,
+			&i.Hours,
+			&i.CreatedAt,
+			&i.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -111,3 +1494,260 @@ func (q *Queries) ListStockMovements(ctx context.Context, arg ListStockMovements
 	}
 	return items, nil
 }
+
+const lockStock = `-- name: LockStock :one
+SELECT id, product_id, quantity, reserved_quantity, location, created_at, updated_at, rental_enabled
+FROM stocks
+WHERE id = $1
+FOR UPDATE
+`
+
+func (q *Queries) LockStock(ctx context.Context, id int32) (*Stock, error) {
+	row := q.db.QueryRow(ctx, lockStock, id)
+	var i Stock
+	err := row.Scan(
+		&i.ID,
+		&i.ProductID,
+		&i.Quantity,
+		&i.ReservedQuantity,
+		&i.Location,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.RentalEnabled,
+	)
+	return &i, err
+}
+
+const projectStock = `-- name: ProjectStock :execrows
+WITH pending AS (
+    SELECT p.stock_id,
+           COALESCE(SUM(CASE m.type WHEN 'in' THEN m.quantity WHEN 'out' THEN -m.quantity ELSE 0 END), 0)::integer AS quantity_delta,
+           COALESCE(SUM(CASE
+               WHEN m.type = 'reserve' THEN m.quantity
+               WHEN m.type = 'release' THEN -m.quantity
+               WHEN m.type = 'out' AND m.reference_type = 'order' AND m.reversal_of_id IS NULL THEN -m.quantity
+               ELSE 0 END), 0)::integer AS reserved_quantity_delta,
+           MAX(m.id) AS last_movement_id
+    FROM stock_projections p
+    JOIN stock_movements m ON m.stock_id = p.stock_id AND m.id > p.projected_movement_id
+    WHERE p.stock_id = $1
+    GROUP BY p.stock_id
+), advanced AS (
+    UPDATE stock_projections p
+    SET projected_movement_id = pending.last_movement_id, projected_at = NOW()
+    FROM pending
+    WHERE p.stock_id = pending.stock_id
+)
+UPDATE stocks s
+SET quantity = s.quantity + pending.quantity_delta, reserved_quantity = s.reserved_quantity + pending.reserved_quantity_delta
+FROM pending
+WHERE s.id = pending.stock_id
+`
+
+func (q *Queries) ProjectStock(ctx context.Context, stockID uint64) (int64, error) {
+	result, err := q.db.Exec(ctx, projectStock, stockID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const rebuildStockProjection = `-- name: RebuildStockProjection :execrows
+WITH replayed AS (
+    SELECT p.stock_id,
+           (p.base_quantity + COALESCE(SUM(CASE m.type WHEN 'in' THEN m.quantity WHEN 'out' THEN -m.quantity ELSE 0 END), 0))::integer AS quantity,
+           (p.base_reserved_quantity + COALESCE(SUM(CASE
+               WHEN m.type = 'reserve' THEN m.quantity
+               WHEN m.type = 'release' THEN -m.quantity
+               WHEN m.type = 'out' AND m.reference_type = 'order' AND m.reversal_of_id IS NULL THEN -m.quantity
+               ELSE 0 END), 0))::integer AS reserved_quantity,
+           COALESCE(MAX(m.id), p.base_movement_id) AS last_movement_id
+    FROM stock_projections p
+    LEFT JOIN stock_movements m ON m.stock_id = p.stock_id AND m.id > p.base_movement_id
+    WHERE p.stock_id = $1
+    GROUP BY p.stock_id, p.base_quantity, p.base_reserved_quantity, p.base_movement_id
+), advanced AS (
+    UPDATE stock_projections p
+    SET projected_movement_id = replayed.last_movement_id, projected_at = NOW()
+    FROM replayed
+    WHERE p.stock_id = replayed.stock_id
+)
+UPDATE stocks s
+SET quantity = replayed.quantity, reserved_quantity = replayed.reserved_quantity
+FROM replayed
+WHERE s.id = replayed.stock_id
+`
+
+func (q *Queries) RebuildStockProjection(ctx context.Context, stockID uint64) (int64, error) {
+	result, err := q.db.Exec(ctx, rebuildStockProjection, stockID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const refreshProductAvailability = `-- name: RefreshProductAvailability :exec
+REFRESH MATERIALIZED VIEW CONCURRENTLY product_availability
+`
+
+func (q *Queries) RefreshProductAvailability(ctx context.Context) error {
+	_, err := q.db.Exec(ctx, refreshProductAvailability)
+	return err
+}
+
+const releaseStockHold = `-- name: ReleaseStockHold :execrows
+UPDATE stock_holds
+SET released_at = NOW()
+WHERE id = $1 AND released_at IS NULL
+`
+
+func (q *Queries) ReleaseStockHold(ctx context.Context, id int32) (int64, error) {
+	result, err := q.db.Exec(ctx, releaseStockHold, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const reviewStockAdjustment = `-- name: ReviewStockAdjustment :execrows
+UPDATE stock_adjustments
+SET status = $2, reviewed_by = $3, review_note = $4, reviewed_at = NOW()
+WHERE id = $1 AND status = 'pending'
+`
+
+type ReviewStockAdjustmentParams struct {
+	ID         int32                 `json:"id"`
+	Status     StockAdjustmentStatus `json:"status"`
+	ReviewedBy *string               `json:"reviewedBy"`
+	ReviewNote *string               `json:"reviewNote"`
+}
+
+func (q *Queries) ReviewStockAdjustment(ctx context.Context, arg ReviewStockAdjustmentParams) (int64, error) {
+	result, err := q.db.Exec(ctx, reviewStockAdjustment,
+		arg.ID,
+		arg.Status,
+		arg.ReviewedBy,
+		arg.ReviewNote,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const reviewStockTransfer = `-- name: ReviewStockTransfer :execrows
+UPDATE stock_transfers
+SET status = $2, reviewed_by = $3, review_note = $4, reviewed_at = NOW()
+WHERE id = $1 AND status = 'draft'
+`
+
+type ReviewStockTransferParams struct {
+	ID         int32               `json:"id"`
+	Status     StockTransferStatus `json:"status"`
+	ReviewedBy *string             `json:"reviewedBy"`
+	ReviewNote *string             `json:"reviewNote"`
+}
+
+func (q *Queries) ReviewStockTransfer(ctx context.Context, arg ReviewStockTransferParams) (int64, error) {
+	result, err := q.db.Exec(ctx, reviewStockTransfer,
+		arg.ID,
+		arg.Status,
+		arg.ReviewedBy,
+		arg.ReviewNote,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const setStockRentalEnabled = `-- name: SetStockRentalEnabled :execrows
+UPDATE stocks
+SET rental_enabled = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type SetStockRentalEnabledParams struct {
+	ID            int32 `json:"id"`
+	RentalEnabled bool  `json:"rentalEnabled"`
+}
+
+func (q *Queries) SetStockRentalEnabled(ctx context.Context, arg SetStockRentalEnabledParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setStockRentalEnabled, arg.ID, arg.RentalEnabled)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updateStockQuantity = `-- name: UpdateStockQuantity :execrows
+UPDATE stocks
+SET quantity = quantity + CASE WHEN EXISTS (SELECT 1 FROM stock_projections p WHERE p.stock_id = stocks.id) THEN 0 ELSE $1::integer END, updated_at = NOW()
+WHERE id = $2 AND updated_at = $3
+  AND (EXISTS (SELECT 1 FROM stock_projections p WHERE p.stock_id = stocks.id) OR quantity + $1::integer >= reserved_quantity)
+`
+
+type UpdateStockQuantityParams struct {
+	Delta     int32              `json:"delta"`
+	ID        int32              `json:"id"`
+	UpdatedAt pgtype.Timestamptz `json:"updatedAt"`
+}
+
+func (q *Queries) UpdateStockQuantity(ctx context.Context, arg UpdateStockQuantityParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateStockQuantity, arg.Delta, arg.ID, arg.UpdatedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updateStockRentalStatus = `-- name: UpdateStockRentalStatus :execrows
+UPDATE stock_rentals
+SET status = $2, updated_at = NOW()
+WHERE id = $1 AND status = 'reserved'
+`
+
+type UpdateStockRentalStatusParams struct {
+	ID     int32             `json:"id"`
+	Status StockRentalStatus `json:"status"`
+}
+
+func (q *Queries) UpdateStockRentalStatus(ctx context.Context, arg UpdateStockRentalStatusParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateStockRentalStatus, arg.ID, arg.Status)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updateStore = `-- name: UpdateStore :execrows
+UPDATE stores
+SET name = $2, location = $3, address = $4, latitude = $5, longitude = $6, hours = $7, updated_at = NOW()
+WHERE id = $1
+`
+
+type UpdateStoreParams struct {
+	ID        int32   `json:"id"`
+	Name      string  `json:"name"`
+	Location  string  `json:"location"`
+	Address   []byte  `json:"address"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Hours     []byte  `json:"hours"`
+}
+
+func (q *Queries) UpdateStore(ctx context.Context, arg UpdateStoreParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateStore,
+		arg.ID,
+		arg.Name,
+		arg.Location,
+		arg.Address,
+		arg.Latitude,
+		arg.Longitude,
+		arg.Hours,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
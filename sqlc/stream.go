@@ -0,0 +1,55 @@
+package sqlc
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// 本檔案不是由 sqlc 產生：sqlc 的 :many 查詢會先把所有結果載入 slice，
+// 以下的串流版本沿用同一段查詢，逐列掃描後交給 fn 處理，匯出大量資料時記憶體用量維持固定。
+// fn 執行期間連線仍在讀取結果，因此 fn 不可使用同一個連線或交易發出其他查詢；fn 回傳錯誤時停止讀取並回傳該錯誤。
+// 每一列依欄位順序掃描到 sqlc 產生的結構，不另外維護掃描清單；查詢的欄位數與結構不符時 streamRows 回傳錯誤，
+// stream_test.go 在不連線資料庫的情況下檢查兩者一致
+
+// Streamer 為串流版本的查詢，不包含在 sqlc 產生的 Querier 中
+type Streamer interface {
+	StreamOrdersByFilter(ctx context.Context, arg ListOrdersByFilterParams, fn func(*Order) error) error
+	StreamRevenueEvents(ctx context.Context, arg ListRevenueEventsParams, fn func(*ListRevenueEventsRow) error) error
+}
+
+var _ Streamer = (*Queries)(nil)
+
+// StreamOrdersByFilter 以 ListOrdersByFilter 的查詢逐筆串流訂單
+func (q *Queries) StreamOrdersByFilter(ctx context.Context, arg ListOrdersByFilterParams, fn func(*Order) error) error {
+	return streamRows(ctx, q.db, listOrdersByFilter, []any{
+		arg.CustomerID,
+		arg.Statuses,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+	}, fn)
+}
+
+// StreamRevenueEvents 以 ListRevenueEvents 的查詢逐筆串流銷售與退款事件
+func (q *Queries) StreamRevenueEvents(ctx context.Context, arg ListRevenueEventsParams, fn func(*ListRevenueEventsRow) error) error {
+	return streamRows(ctx, q.db, listRevenueEvents, []any{arg.PeriodStart, arg.PeriodEnd, arg.Statuses}, fn)
+}
+
+// streamRows 執行查詢並將每一列依欄位順序掃描到 T 後交給 fn，T 的欄位數須與查詢的欄位數相同
+func streamRows[T any](ctx context.Context, db DBTX, query string, args []any, fn func(*T) error) error {
+	rows, err := db.Query(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		i, err := pgx.RowToAddrOfStructByPos[T](rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(i); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,97 @@
+package sqlc
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"unicode"
+)
+
+// TestStreamQueryColumns 確認串流查詢的欄位數與掃描的結構欄位數相同，
+// 查詢新增欄位但結構未重新產生（或相反）時，串流在執行時才會失敗
+func TestStreamQueryColumns(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		dst   any
+	}{
+		{name: "ListOrdersByFilter", query: listOrdersByFilter, dst: Order{}},
+		{name: "ListRevenueEvents", query: listRevenueEvents, dst: ListRevenueEventsRow{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			columns := selectColumns(t, tt.query)
+			if fields := reflect.TypeOf(tt.dst).NumField(); columns != fields {
+				t.Errorf("query selects %d columns, %T has %d fields", columns, tt.dst, fields)
+			}
+		})
+	}
+}
+
+func TestSelectColumns(t *testing.T) {
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{query: "SELECT id FROM orders", want: 1},
+		{query: "SELECT id, ROUND(total, 2), 'a,b' AS label\nFROM orders", want: 3},
+		{query: "WITH o AS (SELECT id, total FROM orders) SELECT id FROM o UNION ALL SELECT 1 FROM o", want: 1},
+		{query: "-- name: X :many\nSELECT COALESCE(a, b) AS c, d FROM t", want: 2},
+	}
+
+	for _, tt := range tests {
+		if got := selectColumns(t, tt.query); got != tt.want {
+			t.Errorf("selectColumns(%q) = %d, want %d", tt.query, got, tt.want)
+		}
+	}
+}
+
+// selectColumns 回傳查詢最外層第一個 SELECT 的欄位數，略過註解、括號與字串內的逗號
+func selectColumns(t *testing.T, query string) int {
+	t.Helper()
+
+	var lines []string
+	for _, line := range strings.Split(query, "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(line), "--") {
+			lines = append(lines, line)
+		}
+	}
+	query = strings.Join(lines, "\n")
+
+	depth, columns := 0, 0
+	inString, selecting := false, false
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case inString:
+			inString = c != '\''
+		case c == '\'':
+			inString = true
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case depth != 0:
+		case !selecting && keywordAt(query, i, "SELECT"):
+			selecting, columns = true, 1
+		case selecting && keywordAt(query, i, "FROM"):
+			return columns
+		case selecting && c == ',':
+			columns++
+		}
+	}
+
+	t.Fatalf("no top-level SELECT ... FROM in query %q", query)
+	return 0
+}
+
+// keywordAt 回傳 query 在位置 i 是否為完整的關鍵字（不分大小寫）
+func keywordAt(query string, i int, keyword string) bool {
+	end := i + len(keyword)
+	if end > len(query) || !strings.EqualFold(query[i:end], keyword) {
+		return false
+	}
+	isWord := func(r byte) bool { return r == '_' || unicode.IsLetter(rune(r)) || unicode.IsDigit(rune(r)) }
+	return (i == 0 || !isWord(query[i-1])) && (end == len(query) || !isWord(query[end]))
+}
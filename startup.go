@@ -0,0 +1,198 @@
+package shop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+)
+
+// dependencyCheckTimeout 為每次檢查相依服務的時限
+const dependencyCheckTimeout = 5 * time.Second
+
+// ErrDependencyUnavailable 表示必要的相依服務在重試後仍無法使用
+var ErrDependencyUnavailable = errors.New("dependency unavailable")
+
+// StartupRetry 為 NewService 檢查相依服務與建立訂閱時的重試設定，每次失敗後等待時間加倍直到 MaxBackoff
+type StartupRetry struct {
+	Attempts       int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// DefaultStartupRetry 為未設定時使用的重試設定：最多嘗試 5 次，從 500 毫秒開始退避，最長等待 10 秒
+var DefaultStartupRetry = StartupRetry{
+	Attempts:       5,
+	InitialBackoff: 500 * time.Millisecond,
+	MaxBackoff:     10 * time.Second,
+}
+
+// DependencyCheck 為啟動時檢查的相依服務；Critical 的相依服務在重試後仍無法使用時 NewService 回傳錯誤，其餘只記錄警告
+type DependencyCheck struct {
+	Name     string
+	Critical bool
+	Check    func(ctx context.Context) error
+}
+
+// WithStartupRetry 設定 NewService 檢查相依服務與建立訂閱時的重試次數與退避時間
+func WithStartupRetry(retry StartupRetry) Option {
+	return func(s *service) {
+		if retry.Attempts > 0 {
+			s.startupRetry = retry
+		}
+	}
+}
+
+// WithDependencyCheck 加入啟動時檢查的相依服務，例如儲存庫快取使用的 Redis；資料庫與 NATS 一律會檢查
+func WithDependencyCheck(check DependencyCheck) Option {
+	return func(s *service) {
+		if check.Check != nil {
+			s.dependencyChecks = append(s.dependencyChecks, check)
+		}
+	}
+}
+
+// CheckDependencies 檢查資料庫、NATS 與 WithDependencyCheck 設定的相依服務各一次並重新建立失效的訂閱，供 readiness probe 使用；
+// 回傳所有無法使用的相依服務的錯誤，不重試
+func (s *service) CheckDependencies(ctx context.Context) error {
+	var errs []error
+	for _, check := range s.allDependencyChecks() {
+		if err := runDependencyCheck(ctx, check); err != nil {
+			errs = append(errs, fmt.Errorf("%w: %s: %w", ErrDependencyUnavailable, check.Name, err))
+		}
+	}
+	if _, err := s.eventManager.EnsureSubscriptions(); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// verifyDependencies 依 startupRetry 重試每個相依服務，必要的相依服務最後仍無法使用時回傳錯誤
+func (s *service) verifyDependencies(ctx context.Context) error {
+	var errs []error
+	for _, check := range s.allDependencyChecks() {
+		err := s.retryStartup(ctx, check.Name, func(ctx context.Context) error {
+			return runDependencyCheck(ctx, check)
+		})
+		if err == nil {
+			continue
+		}
+		if !check.Critical {
+			s.logger.Warn("Optional dependency unavailable, continuing startup", zap.String("dependency", check.Name), zap.Error(err))
+			continue
+		}
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// allDependencyChecks 回傳內建的資料庫與 NATS 檢查，以及 WithDependencyCheck 設定的檢查
+func (s *service) allDependencyChecks() []DependencyCheck {
+	checks := []DependencyCheck{
+		{Name: "postgres", Critical: true, Check: s.transactionManager.Ping},
+		{Name: "nats", Critical: true, Check: s.pingNats},
+	}
+	return append(checks, s.dependencyChecks...)
+}
+
+// runDependencyCheck 以 dependencyCheckTimeout 為時限執行一次檢查
+func runDependencyCheck(ctx context.Context, check DependencyCheck) error {
+	ctx, cancel := context.WithTimeout(ctx, dependencyCheckTimeout)
+	defer cancel()
+
+	return check.Check(ctx)
+}
+
+// pingNats 確認 NATS 連線可以往返伺服器，連線中斷或重新連線中時回傳錯誤
+func (s *service) pingNats(ctx context.Context) error {
+	if s.natsConn == nil {
+		return errors.New("nats connection is not configured")
+	}
+	if s.natsConn.IsClosed() {
+		return nats.ErrConnectionClosed
+	}
+	return s.natsConn.FlushWithContext(ctx)
+}
+
+// retryStartup 執行 fn 直到成功或用完 startupRetry 的次數，每次失敗後依指數退避等待
+func (s *service) retryStartup(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+	backoff := s.startupRetry.InitialBackoff
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = fn(ctx); err == nil {
+			return nil
+		}
+		if attempt >= s.startupRetry.Attempts {
+			break
+		}
+
+		s.logger.Warn("Dependency not ready, retrying",
+			zap.String("dependency", name), zap.Int("attempt", attempt), zap.Duration("backoff", backoff), zap.Error(err))
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: %s: %w", ErrDependencyUnavailable, name, ctx.Err())
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, s.startupRetry.MaxBackoff)
+	}
+
+	return fmt.Errorf("%w: %s after %d attempts: %w", ErrDependencyUnavailable, name, s.startupRetry.Attempts, err)
+}
+
+// subscribeEvents 以重試訂閱金流事件與退貨追蹤事件，任一訂閱最後仍失敗時回傳錯誤
+func (s *service) subscribeEvents(ctx context.Context) error {
+	if err := s.retryStartup(ctx, "payment event subscription", func(context.Context) error {
+		return s.eventManager.SubscribeToEvents(s.workerPool)
+	}); err != nil {
+		return err
+	}
+
+	if s.returnCarrier != nil {
+		if err := s.retryStartup(ctx, "return tracking subscription", func(context.Context) error {
+			return s.eventManager.SubscribeToReturnTracking(s.HandleReturnTrackingEvent)
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// watchNatsConnection 記錄 NATS 斷線與關閉，重新連線後重新建立失效的訂閱；
+// 保留呼叫端原本設定的 handler 並在之後呼叫
+func (s *service) watchNatsConnection() {
+	previousReconnect := s.natsConn.ReconnectHandler()
+	s.natsConn.SetReconnectHandler(func(nc *nats.Conn) {
+		restored, err := s.eventManager.EnsureSubscriptions()
+		if err != nil {
+			s.logger.Error("Failed to restore subscriptions after reconnect", zap.Error(err))
+		} else {
+			s.logger.Info("NATS reconnected", zap.String("url", nc.ConnectedUrlRedacted()), zap.Int("restored_subscriptions", restored))
+		}
+		if previousReconnect != nil {
+			previousReconnect(nc)
+		}
+	})
+
+	previousDisconnect := s.natsConn.DisconnectErrHandler()
+	s.natsConn.SetDisconnectErrHandler(func(nc *nats.Conn, err error) {
+		s.logger.Warn("NATS disconnected, payment events are paused until reconnect", zap.Error(err))
+		if previousDisconnect != nil {
+			previousDisconnect(nc, err)
+		}
+	})
+
+	previousClosed := s.natsConn.ClosedHandler()
+	s.natsConn.SetClosedHandler(func(nc *nats.Conn) {
+		s.logger.Error("NATS connection closed, payment events will no longer be received", zap.Error(nc.LastError()))
+		if previousClosed != nil {
+			previousClosed(nc)
+		}
+	})
+}
@@ -0,0 +1,222 @@
+package shop
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"gofalre.io/shop/models"
+	"gofalre.io/shop/models/enum"
+)
+
+// StatusHook 在訂單進入狀態時於同一個交易中執行，order 為更新前的訂單；回傳錯誤會讓整個狀態更新回復
+type StatusHook func(ctx context.Context, tx pgx.Tx, order *models.Order, from, to enum.OrderStatus) error
+
+// StatusGraph 描述訂單狀態機：Transitions 列出每個狀態可以轉換到的狀態，所有出現的狀態都必須有自己的項目（終止狀態為空）；
+// 自訂狀態（例如 packed）必須先以 migration 加入資料庫的 order_status 型別
+type StatusGraph struct {
+	Transitions map[enum.OrderStatus][]enum.OrderStatus
+	// Terminal 為終止狀態，不能有任何轉換
+	Terminal []enum.OrderStatus
+	// Acyclic 中的狀態彼此之間的轉換不可形成循環，例如履約流程不能回到先前的步驟；
+	// 不在其中的狀態（例如爭議後恢復為已付款）不受限制
+	Acyclic []enum.OrderStatus
+	// OnEnter 在訂單進入狀態時執行的 hook，依註冊順序執行
+	OnEnter map[enum.OrderStatus][]StatusHook
+}
+
+// StatusMachine 檢查訂單狀態轉換並執行轉換的 hook，建立後不可修改，可在多個 goroutine 間共用
+type StatusMachine struct {
+	transitions map[enum.OrderStatus][]enum.OrderStatus
+	onEnter     map[enum.OrderStatus][]StatusHook
+}
+
+// DefaultStatusGraph 回傳預設的訂單狀態機，可複製後加入自訂狀態與轉換
+func DefaultStatusGraph() StatusGraph {
+	return StatusGraph{
+		Transitions: map[enum.OrderStatus][]enum.OrderStatus{
+			enum.OrderStatusPending: {
+				enum.OrderStatusPaid,
+				enum.OrderStatusCancelled,
+				enum.OrderStatusFailed,
+			},
+			enum.OrderStatusPaid: {
+				enum.OrderStatusCompleted,
+				enum.OrderStatusRefunded,
+				enum.OrderStatusPartiallyRefunded,
+				enum.OrderStatusDispute,
+				enum.OrderStatusReadyForPickup,
+				enum.OrderStatusOnHold,
+			},
+			enum.OrderStatusReadyForPickup: {
+				enum.OrderStatusCompleted,
+				enum.OrderStatusRefunded,
+				enum.OrderStatusOnHold,
+			},
+			enum.OrderStatusOnHold: {}, // 只能解除暫停，恢復暫停前的狀態
+			enum.OrderStatusFailed: {
+				enum.OrderStatusPending, // 可能重試支付
+			},
+			enum.OrderStatusCancelled: {},
+			enum.OrderStatusRefunded:  {},
+			enum.OrderStatusPartiallyRefunded: {
+				enum.OrderStatusRefunded,
+			},
+			enum.OrderStatusDispute: {
+				enum.OrderStatusPaid,
+				enum.OrderStatusRefunded,
+			},
+			enum.OrderStatusCompleted: {},
+		},
+		Terminal: []enum.OrderStatus{
+			enum.OrderStatusCancelled,
+			enum.OrderStatusRefunded,
+			enum.OrderStatusCompleted,
+		},
+		Acyclic: []enum.OrderStatus{
+			enum.OrderStatusPaid,
+			enum.OrderStatusReadyForPickup,
+			enum.OrderStatusCompleted,
+			enum.OrderStatusPartiallyRefunded,
+			enum.OrderStatusRefunded,
+		},
+	}
+}
+
+// DefaultStatusMachine 回傳預設的訂單狀態機
+func DefaultStatusMachine() *StatusMachine {
+	machine, err := NewStatusMachine(DefaultStatusGraph())
+	if err != nil {
+		panic(fmt.Sprintf("invalid default status graph: %v", err))
+	}
+	return machine
+}
+
+// NewStatusMachine 檢查狀態機後建立 StatusMachine：pending 必須存在、轉換的目標狀態都必須有項目、
+// 終止狀態不能有轉換、Acyclic 中的狀態之間不能形成循環
+func NewStatusMachine(graph StatusGraph) (*StatusMachine, error) {
+	if _, ok := graph.Transitions[enum.OrderStatusPending]; !ok {
+		return nil, fmt.Errorf("status graph must include %s", enum.OrderStatusPending)
+	}
+
+	transitions := make(map[enum.OrderStatus][]enum.OrderStatus, len(graph.Transitions))
+	for from, targets := range graph.Transitions {
+		for _, to := range targets {
+			if _, ok := graph.Transitions[to]; !ok {
+				return nil, fmt.Errorf("status %s transitions to undefined status %s", from, to)
+			}
+			if to == from {
+				return nil, fmt.Errorf("status %s cannot transition to itself", from)
+			}
+		}
+		transitions[from] = slices.Clone(targets)
+	}
+
+	for _, status := range graph.Terminal {
+		targets, ok := transitions[status]
+		if !ok {
+			return nil, fmt.Errorf("terminal status %s is not defined", status)
+		}
+		if len(targets) > 0 {
+			return nil, fmt.Errorf("terminal status %s cannot have transitions", status)
+		}
+	}
+
+	if cycle := findStatusCycle(transitions, graph.Acyclic); cycle != nil {
+		parts := make([]string, len(cycle))
+		for i, status := range cycle {
+			parts[i] = string(status)
+		}
+		return nil, fmt.Errorf("status graph has a cycle among acyclic statuses: %s", strings.Join(parts, " -> "))
+	}
+
+	onEnter := make(map[enum.OrderStatus][]StatusHook, len(graph.OnEnter))
+	for status, hooks := range graph.OnEnter {
+		if _, ok := transitions[status]; !ok {
+			return nil, fmt.Errorf("hook registered for undefined status %s", status)
+		}
+		onEnter[status] = slices.Clone(hooks)
+	}
+
+	return &StatusMachine{transitions: transitions, onEnter: onEnter}, nil
+}
+
+// WithStatusMachine 設定訂單狀態轉換使用的狀態機，未設定時使用 DefaultStatusMachine
+func WithStatusMachine(machine *StatusMachine) Option {
+	return func(s *service) {
+		if machine != nil {
+			s.statusMachine = machine
+		}
+	}
+}
+
+// CanTransition 回傳訂單是否可以從 from 轉換到 to
+func (m *StatusMachine) CanTransition(from, to enum.OrderStatus) bool {
+	return slices.Contains(m.transitions[from], to)
+}
+
+// Next 回傳 from 可以轉換到的狀態
+func (m *StatusMachine) Next(from enum.OrderStatus) []enum.OrderStatus {
+	return slices.Clone(m.transitions[from])
+}
+
+// enter 依序執行訂單進入 to 狀態的 hook
+func (m *StatusMachine) enter(ctx context.Context, tx pgx.Tx, order *models.Order, to enum.OrderStatus) error {
+	for _, hook := range m.onEnter[to] {
+		if err := hook(ctx, tx, order, order.Status, to); err != nil {
+			return fmt.Errorf("status hook for %s failed: %w", to, err)
+		}
+	}
+	return nil
+}
+
+// findStatusCycle 以深度優先搜尋找出 statuses 之間的循環，回傳循環經過的狀態（首尾相同），沒有循環時回傳 nil
+func findStatusCycle(transitions map[enum.OrderStatus][]enum.OrderStatus, statuses []enum.OrderStatus) []enum.OrderStatus {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+
+	state := make(map[enum.OrderStatus]int, len(statuses))
+	for _, status := range statuses {
+		state[status] = unvisited
+	}
+
+	var path []enum.OrderStatus
+	var visit func(status enum.OrderStatus) []enum.OrderStatus
+	visit = func(status enum.OrderStatus) []enum.OrderStatus {
+		state[status] = visiting
+		path = append(path, status)
+		for _, next := range transitions[status] {
+			nextState, ok := state[next]
+			if !ok {
+				continue
+			}
+			switch nextState {
+			case visiting:
+				start := slices.Index(path, next)
+				return append(slices.Clone(path[start:]), next)
+			case unvisited:
+				if cycle := visit(next); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[status] = done
+		return nil
+	}
+
+	for _, status := range statuses {
+		if state[status] == unvisited {
+			if cycle := visit(status); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
@@ -2,8 +2,11 @@ package stock
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
 	"go.uber.org/zap"
 	"gofalre.io/shop/driver"
@@ -14,27 +17,115 @@ import (
 	"time"
 )
 
+// ErrStaleStock 表示調整庫存時庫存的 updated_at 已被其他交易修改，呼叫端應重新讀取後重試
+var ErrStaleStock = errors.New("stock was modified concurrently")
+
+// ErrMovementAlreadyReversed 表示庫存變動已經有對應的沖銷記錄
+var ErrMovementAlreadyReversed = errors.New("stock movement is already reversed")
+
+// ErrStockNotCreatedYet 表示查詢的時間點早於庫存建立的時間
+var ErrStockNotCreatedYet = errors.New("stock did not exist at the requested time")
+
+// ErrStockAlreadyEventSourced 表示庫存已經啟用事件溯源模式
+var ErrStockAlreadyEventSourced = errors.New("stock is already event-sourced")
+
+// ErrStockNotEventSourced 表示庫存未啟用事件溯源模式
+var ErrStockNotEventSourced = errors.New("stock is not event-sourced")
+
+// ErrBarcodeTaken 表示條碼已經對應到其他商品
+var ErrBarcodeTaken = errors.New("barcode is already registered")
+
+// ErrStocktakeInProgress 表示該地點已有進行中的盤點
+var ErrStocktakeInProgress = errors.New("a stocktake is already in progress at this location")
+
 type Repository interface {
 	GetStock(ctx context.Context, tx pgx.Tx, stockID uint64) (*models.Stock, error)
+	GetStockByProductAndLocation(ctx context.Context, tx pgx.Tx, productID, location string) (*models.Stock, error)
+	ListStocksByProductID(ctx context.Context, tx pgx.Tx, productID string) ([]*models.Stock, error)
+	CreateStock(ctx context.Context, tx pgx.Tx, productID, location string, quantity uint64) (*models.Stock, error)
+	ListOverReservedStocks(ctx context.Context, tx pgx.Tx) ([]*models.Stock, error)
+	ListLowStocks(ctx context.Context, tx pgx.Tx, threshold uint64, limit int32) ([]*models.Stock, error)
+	GetStockLevelAt(ctx context.Context, tx pgx.Tx, stockID uint64, at time.Time) (*models.StockLevel, error)
 	AdjustStock(ctx context.Context, tx pgx.Tx, params []AdjustStockParams) error
 	ReleaseStock(ctx context.Context, tx pgx.Tx, params []ReleaseStockParams) error
 	ReduceStock(ctx context.Context, tx pgx.Tx, params []ReduceStockParams) error
+	RestoreReservedStock(ctx context.Context, tx pgx.Tx, params []RestoreReservedStockParams) error
 	CreateStockMovements(ctx context.Context, tx pgx.Tx, params []CreateStockMovementParams) error
 	ListStockMovements(ctx context.Context, tx pgx.Tx, stockID uint64, limit, offset uint64) ([]*models.StockMovement, error)
+	CountStockMovements(ctx context.Context, tx pgx.Tx, stockID uint64) (uint64, error)
 	GetStockMovementsByReference(ctx context.Context, tx pgx.Tx, referenceType enum.StockMovementReferenceType, referenceID uint64) ([]*models.StockMovement, error)
+	GetStockMovementForUpdate(ctx context.Context, tx pgx.Tx, movementID uint64) (*models.StockMovement, error)
+	CreateStockMovementReversal(ctx context.Context, tx pgx.Tx, reversalOfID uint64, params CreateStockMovementParams) (*models.StockMovement, error)
+	CreateManualStockMovement(ctx context.Context, tx pgx.Tx, params CreateManualStockMovementParams) (*models.StockMovement, error)
+
+	CreateStockHold(ctx context.Context, tx pgx.Tx, params CreateStockHoldParams) (*models.StockHold, error)
+	GetStockHold(ctx context.Context, tx pgx.Tx, holdID uint64) (*models.StockHold, error)
+	ListStockHolds(ctx context.Context, tx pgx.Tx, stockID uint64) ([]*models.StockHold, error)
+	ListExpiredStockHolds(ctx context.Context, tx pgx.Tx, now time.Time) ([]*models.StockHold, error)
+	ReleaseStockHold(ctx context.Context, tx pgx.Tx, holdID uint64) (bool, error)
+
+	UpdateStockQuantity(ctx context.Context, tx pgx.Tx, params UpdateStockQuantityParams) (bool, error)
+	CreateStockAdjustment(ctx context.Context, tx pgx.Tx, params CreateStockAdjustmentParams) (*models.StockAdjustment, error)
+	GetStockAdjustment(ctx context.Context, tx pgx.Tx, adjustmentID uint64) (*models.StockAdjustment, error)
+	ListStockAdjustmentsByStatus(ctx context.Context, tx pgx.Tx, status enum.StockAdjustmentStatus) ([]*models.StockAdjustment, error)
+	ReviewStockAdjustment(ctx context.Context, tx pgx.Tx, params ReviewStockAdjustmentParams) (bool, error)
+
+	ListStockOutflows(ctx context.Context, tx pgx.Tx, since time.Time) ([]*models.StockOutflow, error)
+	CreateStockTransfer(ctx context.Context, tx pgx.Tx, params CreateStockTransferParams) (*models.StockTransfer, error)
+	GetStockTransfer(ctx context.Context, tx pgx.Tx, transferID uint64) (*models.StockTransfer, error)
+	ListStockTransfersByStatus(ctx context.Context, tx pgx.Tx, status enum.StockTransferStatus) ([]*models.StockTransfer, error)
+	ReviewStockTransfer(ctx context.Context, tx pgx.Tx, params ReviewStockTransferParams) (bool, error)
+
+	CreateProductBarcode(ctx context.Context, tx pgx.Tx, barcode, productID string) error
+	ResolveProductBarcodes(ctx context.Context, tx pgx.Tx, barcodes []string) (map[string]string, error)
+	CreateStocktake(ctx context.Context, tx pgx.Tx, location, startedBy string) (*models.Stocktake, error)
+	GetStocktake(ctx context.Context, tx pgx.Tx, stocktakeID uint64) (*models.Stocktake, error)
+	FinishStocktake(ctx context.Context, tx pgx.Tx, stocktakeID uint64, status enum.StocktakeStatus, finishedBy string) (bool, error)
+	AddStocktakeScan(ctx context.Context, tx pgx.Tx, params AddStocktakeScanParams) (bool, error)
+	ListStocktakeCounts(ctx context.Context, tx pgx.Tx, stocktakeID uint64) ([]*models.StocktakeCount, error)
+
+	LockStock(ctx context.Context, tx pgx.Tx, stockID uint64) (*models.Stock, error)
+	SetStockRentalEnabled(ctx context.Context, tx pgx.Tx, stockID uint64, enabled bool) (bool, error)
+	CreateStockRental(ctx context.Context, tx pgx.Tx, params CreateStockRentalParams) (*models.StockRental, error)
+	GetStockRental(ctx context.Context, tx pgx.Tx, rentalID uint64) (*models.StockRental, error)
+	ListStockRentals(ctx context.Context, tx pgx.Tx, stockID uint64, from, to time.Time) ([]*models.StockRental, error)
+	UpdateStockRentalStatus(ctx context.Context, tx pgx.Tx, rentalID uint64, status enum.StockRentalStatus) (bool, error)
+	ListRentalBookings(ctx context.Context, tx pgx.Tx, stockID uint64, from, to time.Time) ([]*models.RentalAvailability, error)
+
+	EnableEventSourcing(ctx context.Context, tx pgx.Tx, stockID uint64) (*models.StockProjection, error)
+	DisableEventSourcing(ctx context.Context, tx pgx.Tx, stockID uint64) (bool, error)
+	GetStockProjection(ctx context.Context, tx pgx.Tx, stockID uint64) (*models.StockProjection, error)
+	ListStocksPendingProjection(ctx context.Context, tx pgx.Tx, limit uint64) ([]uint64, error)
+	ProjectStock(ctx context.Context, tx pgx.Tx, stockID uint64) (bool, error)
+	RebuildStockProjection(ctx context.Context, tx pgx.Tx, stockID uint64) (bool, error)
+
+	CreateStore(ctx context.Context, tx pgx.Tx, store *models.Store) error
+	UpdateStore(ctx context.Context, tx pgx.Tx, store *models.Store) (bool, error)
+	ListStores(ctx context.Context, tx pgx.Tx) ([]*models.Store, error)
+	FindStoresWithinRadius(ctx context.Context, tx pgx.Tx, lat, lng, radiusKm float64) ([]*models.StoreAvailability, error)
+
+	RefreshProductAvailability(ctx context.Context, tx pgx.Tx) error
+	ListProductAvailability(ctx context.Context, tx pgx.Tx, productIDs []string) ([]*models.ProductAvailability, error)
 }
 
 type repository struct {
-	conn   driver.PostgresPool
-	cache  *ember.Ember
-	logger *zap.Logger
+	queries *driver.Queries
+	cache   *ember.Ember
+	shadow  *driver.CacheShadow
+	logger  *zap.Logger
+}
+
+func NewRepository(conn driver.PostgresPool, cache *ember.Ember, logger *zap.Logger, opts ...driver.RepositoryOption) Repository {
+	return NewRepositoryWithQuerier(sqlc.New(conn), cache, logger, opts...)
 }
 
-func NewRepository(conn driver.PostgresPool, cache *ember.Ember, logger *zap.Logger) Repository {
+// NewRepositoryWithQuerier 以注入的 sqlc.Querier 建立 repository，交易中的查詢見 driver.TxBinder
+func NewRepositoryWithQuerier(querier sqlc.Querier, cache *ember.Ember, logger *zap.Logger, opts ...driver.RepositoryOption) Repository {
 	return &repository{
-		conn:   conn,
-		cache:  cache,
-		logger: logger,
+		queries: driver.NewQueries(querier),
+		cache:   cache,
+		shadow:  driver.NewRepositoryOptions(opts...).CacheShadow,
+		logger:  logger,
 	}
 }
 
@@ -45,31 +136,113 @@ func (r *repository) GetStock(ctx context.Context, tx pgx.Tx, stockID uint64) (*
 	// 嘗試從快取中獲取
 	found, err := r.cache.Get(ctx, cacheKey, &stock)
 	if err != nil {
-		r.logger.Error("failed to get stock", zap.Uint64("stock_id", stockID), zap.Error(err))
+		r.logger.Warn("failed to get stock from cache", zap.Uint64("stock_id", stockID), zap.Error(err))
 	}
 	if found {
-		r.logger.Info("found stock", zap.Uint64("stock_id", stockID))
+		r.logger.Debug("found stock in cache", zap.Uint64("stock_id", stockID))
+		r.shadow.Verify(ctx, cacheKey, &stock, func(ctx context.Context) (any, error) {
+			queries := r.queries.Querier()
+			sqlcStock, err := queries.GetStock(ctx, int32(stockID))
+			if err != nil {
+				return nil, err
+			}
+			fresh := new(models.Stock).ConvertSqlcStock(sqlcStock)
+			delta, err := queries.GetUnprojectedStockDelta(ctx, fresh.ID)
+			if err != nil {
+				return nil, err
+			}
+			if delta.EventSourced {
+				fresh.EventSourced = true
+				fresh.Quantity = uint64(max(int64(fresh.Quantity)+delta.QuantityDelta, 0))
+				fresh.ReservedQuantity = uint64(max(int64(fresh.ReservedQuantity)+delta.ReservedQuantityDelta, 0))
+			}
+			return fresh, nil
+		})
 		return &stock, nil
 	}
 
 	// 從資料庫中獲取
-	sqlcStock, err := sqlc.New(r.conn).WithTx(tx).GetStock(ctx, int32(stockID))
+	sqlcStock, err := r.queries.WithTx(tx).GetStock(ctx, int32(stockID))
 	if err != nil {
 		r.logger.Error("failed to get stock", zap.Uint64("stock_id", stockID), zap.Error(err))
 		return nil, err
 	}
 
 	stock = *new(models.Stock).ConvertSqlcStock(sqlcStock)
+	if err = r.applyUnprojectedMovements(ctx, tx, &stock); err != nil {
+		return nil, err
+	}
 
 	if err = r.cache.Set(ctx, cacheKey, stock); err != nil {
-		r.logger.Error("failed to cache stock", zap.Uint64("stock_id", stockID), zap.Error(err))
+		r.logger.Warn("failed to cache stock", zap.Uint64("stock_id", stockID), zap.Error(err))
 	}
 
 	return &stock, nil
 }
 
+func (r *repository) GetStockByProductAndLocation(ctx context.Context, tx pgx.Tx, productID, location string) (*models.Stock, error) {
+	sqlcStock, err := r.queries.WithTx(tx).GetStockByProductAndLocation(ctx, sqlc.GetStockByProductAndLocationParams{
+		ProductID: productID,
+		Location:  &location,
+	})
+	if err != nil {
+		r.logger.Error("failed to get stock by location",
+			zap.String("product_id", productID), zap.String("location", location), zap.Error(err))
+		return nil, err
+	}
+
+	stock := new(models.Stock).ConvertSqlcStock(sqlcStock)
+	if err = r.applyUnprojectedMovements(ctx, tx, stock); err != nil {
+		return nil, err
+	}
+
+	return stock, nil
+}
+
+// ListStocksByProductID 列出商品在各地點的庫存，可用數量多的排在前面
+func (r *repository) ListStocksByProductID(ctx context.Context, tx pgx.Tx, productID string) ([]*models.Stock, error) {
+	sqlcStocks, err := r.queries.WithTx(tx).ListStocksByProductID(ctx, productID)
+	if err != nil {
+		r.logger.Error("failed to list stocks by product", zap.String("product_id", productID), zap.Error(err))
+		return nil, err
+	}
+
+	stocks := make([]*models.Stock, 0, len(sqlcStocks))
+	for _, sqlcStock := range sqlcStocks {
+		stock := new(models.Stock).ConvertSqlcStock(sqlcStock)
+		if err = r.applyUnprojectedMovements(ctx, tx, stock); err != nil {
+			return nil, err
+		}
+		stocks = append(stocks, stock)
+	}
+
+	return stocks, nil
+}
+
+// CreateStock 為商品在指定地點建立庫存，初始數量的入庫記錄由呼叫端寫入
+func (r *repository) CreateStock(ctx context.Context, tx pgx.Tx, productID, location string, quantity uint64) (*models.Stock, error) {
+	var loc *string
+	if location != "" {
+		loc = &location
+	}
+
+	sqlcStock, err := r.queries.WithTx(tx).CreateStock(ctx, sqlc.CreateStockParams{
+		ProductID: productID,
+		Quantity:  quantity,
+		Location:  loc,
+	})
+	if err != nil {
+		r.logger.Error("failed to create stock", zap.String("product_id", productID), zap.String("location", location), zap.Error(err))
+		return nil, err
+	}
+
+	return new(models.Stock).ConvertSqlcStock(sqlcStock), nil
+}
+
+// AdjustStock 批量增加預留量，同一庫存的參數合併後一次調整；庫存已被其他交易修改時回傳 ErrStaleStock
 func (r *repository) AdjustStock(ctx context.Context, tx pgx.Tx, params []AdjustStockParams) error {
 	var batchError error
+	params = mergeStockParams(params)
 	batch := make([]sqlc.AdjustStockParams, 0, len(params))
 	for _, param := range params {
 		batch = append(batch, sqlc.AdjustStockParams{
@@ -78,29 +251,36 @@ func (r *repository) AdjustStock(ctx context.Context, tx pgx.Tx, params []Adjust
 			UpdatedAt:        pgtype.Timestamptz{Time: param.LastUpdated, Valid: true},
 		})
 	}
-	batchResults := sqlc.New(r.conn).WithTx(tx).AdjustStock(ctx, batch)
+	batchResults := r.queries.WithTx(tx).AdjustStock(ctx, batch)
 	defer func(batchResults *sqlc.AdjustStockBatchResults) {
 		if err := batchResults.Close(); err != nil {
 			r.logger.Error("failed to close batch", zap.Error(err))
 		}
 	}(batchResults)
 
-	batchResults.Exec(func(index int, err error) {
+	batchResults.QueryRow(func(index int, _ int32, err error) {
+		if errors.Is(err, pgx.ErrNoRows) {
+			r.logger.Warn("Stock update conflicted with a concurrent change", zap.Uint64("stock_id", params[index].StockID))
+			batchError = ErrStaleStock
+			return
+		}
 		if err != nil {
 			r.logger.Error("failed to execute batch", zap.Error(err))
 			batchError = err
 			return
 		}
-		// 更新快取
+		// 清除快取
 		stockID := params[index].StockID
-		r.updateStockCache(ctx, stockID)
+		r.invalidateStockCache(ctx, stockID)
 	})
 
 	return batchError
 }
 
+// ReleaseStock 批量釋放預留量，同一庫存的參數合併後一次調整；庫存已被其他交易修改時回傳 ErrStaleStock
 func (r *repository) ReleaseStock(ctx context.Context, tx pgx.Tx, params []ReleaseStockParams) error {
 	var batchError error
+	params = mergeStockParams(params)
 	batch := make([]sqlc.ReleaseStockParams, 0, len(params))
 	for _, param := range params {
 		batch = append(batch, sqlc.ReleaseStockParams{
@@ -109,29 +289,36 @@ func (r *repository) ReleaseStock(ctx context.Context, tx pgx.Tx, params []Relea
 			UpdatedAt:        pgtype.Timestamptz{Time: param.LastUpdated, Valid: true},
 		})
 	}
-	batchResults := sqlc.New(r.conn).WithTx(tx).ReleaseStock(ctx, batch)
+	batchResults := r.queries.WithTx(tx).ReleaseStock(ctx, batch)
 	defer func(batchResults *sqlc.ReleaseStockBatchResults) {
 		if err := batchResults.Close(); err != nil {
 			r.logger.Error("failed to close batch", zap.Error(err))
 		}
 	}(batchResults)
 
-	batchResults.Exec(func(index int, err error) {
+	batchResults.QueryRow(func(index int, _ int32, err error) {
+		if errors.Is(err, pgx.ErrNoRows) {
+			r.logger.Warn("Stock update conflicted with a concurrent change", zap.Uint64("stock_id", params[index].StockID))
+			batchError = ErrStaleStock
+			return
+		}
 		if err != nil {
 			r.logger.Error("failed to execute batch", zap.Error(err))
 			batchError = err
 			return
 		}
-		// 更新快取
+		// 清除快取
 		stockID := params[index].StockID
-		r.updateStockCache(ctx, stockID)
+		r.invalidateStockCache(ctx, stockID)
 	})
 
 	return batchError
 }
 
+// ReduceStock 批量扣除庫存與預留量，同一庫存的參數合併後一次調整；庫存已被其他交易修改時回傳 ErrStaleStock
 func (r *repository) ReduceStock(ctx context.Context, tx pgx.Tx, params []ReduceStockParams) error {
 	var batchError error
+	params = mergeStockParams(params)
 	batch := make([]sqlc.ReduceStockParams, 0, len(params))
 	for _, param := range params {
 		batch = append(batch, sqlc.ReduceStockParams{
@@ -140,37 +327,100 @@ func (r *repository) ReduceStock(ctx context.Context, tx pgx.Tx, params []Reduce
 			UpdatedAt: pgtype.Timestamptz{Time: param.LastUpdated, Valid: true},
 		})
 	}
-	batchResults := sqlc.New(r.conn).WithTx(tx).ReduceStock(ctx, batch)
+	batchResults := r.queries.WithTx(tx).ReduceStock(ctx, batch)
 	defer func(batchResults *sqlc.ReduceStockBatchResults) {
 		if err := batchResults.Close(); err != nil {
 			r.logger.Error("failed to close batch", zap.Error(err))
 		}
 	}(batchResults)
 
-	batchResults.Exec(func(index int, err error) {
+	batchResults.QueryRow(func(index int, _ int32, err error) {
+		if errors.Is(err, pgx.ErrNoRows) {
+			r.logger.Warn("Stock update conflicted with a concurrent change", zap.Uint64("stock_id", params[index].StockID))
+			batchError = ErrStaleStock
+			return
+		}
 		if err != nil {
 			r.logger.Error("failed to execute batch", zap.Error(err))
 			batchError = err
 			return
 		}
-		// 更新快取
+		// 清除快取
 		stockID := params[index].StockID
-		r.updateStockCache(ctx, stockID)
+		r.invalidateStockCache(ctx, stockID)
 	})
 
 	return batchError
 }
 
-func (r *repository) updateStockCache(ctx context.Context, stockID uint64) {
-	stock, err := r.GetStock(ctx, nil, stockID)
-	if err != nil {
-		r.logger.Error("failed to get stock", zap.Uint64("stock_id", stockID), zap.Error(err))
-		return
+// stockParams 為以 updated_at 樂觀鎖調整庫存的批次參數
+type stockParams interface {
+	AdjustStockParams | ReleaseStockParams | ReduceStockParams | RestoreReservedStockParams
+}
+
+// mergeStockParams 將同一庫存的參數合併為一筆，數量相加並保留第一筆的 LastUpdated；
+// 同一批次中同一庫存的第二個語句會因 updated_at 已被第一個語句更新而不符合條件
+func mergeStockParams[T stockParams](params []T) []T {
+	merged := make([]T, 0, len(params))
+	index := make(map[uint64]int, len(params))
+	for _, param := range params {
+		p := AdjustStockParams(param)
+		if i, ok := index[p.StockID]; ok {
+			m := AdjustStockParams(merged[i])
+			m.Quantity += p.Quantity
+			merged[i] = T(m)
+			continue
+		}
+		index[p.StockID] = len(merged)
+		merged = append(merged, param)
+	}
+	return merged
+}
+
+// RestoreReservedStock 將已扣除的庫存加回並重新預留，用於訂單付款失敗後把商品還給購物車；
+// 同一庫存的參數合併後一次調整，庫存已被其他交易修改時回傳 ErrStaleStock
+func (r *repository) RestoreReservedStock(ctx context.Context, tx pgx.Tx, params []RestoreReservedStockParams) error {
+	var batchError error
+	params = mergeStockParams(params)
+	batch := make([]sqlc.RestoreReservedStockParams, 0, len(params))
+	for _, param := range params {
+		batch = append(batch, sqlc.RestoreReservedStockParams{
+			ID:        int32(param.StockID),
+			Quantity:  param.Quantity,
+			UpdatedAt: pgtype.Timestamptz{Time: param.LastUpdated, Valid: true},
+		})
 	}
+	batchResults := r.queries.WithTx(tx).RestoreReservedStock(ctx, batch)
+	defer func(batchResults *sqlc.RestoreReservedStockBatchResults) {
+		if err := batchResults.Close(); err != nil {
+			r.logger.Error("failed to close batch", zap.Error(err))
+		}
+	}(batchResults)
+
+	batchResults.QueryRow(func(index int, _ int32, err error) {
+		if errors.Is(err, pgx.ErrNoRows) {
+			r.logger.Warn("Stock update conflicted with a concurrent change", zap.Uint64("stock_id", params[index].StockID))
+			batchError = ErrStaleStock
+			return
+		}
+		if err != nil {
+			r.logger.Error("failed to execute batch", zap.Error(err))
+			batchError = err
+			return
+		}
+		// 清除快取
+		stockID := params[index].StockID
+		r.invalidateStockCache(ctx, stockID)
+	})
 
+	return batchError
+}
+
+// invalidateStockCache 清除庫存快取，下次讀取時重新載入；寫入的交易尚未提交，無法在此讀取新的資料
+func (r *repository) invalidateStockCache(ctx context.Context, stockID uint64) {
 	cacheKey := fmt.Sprintf("stock:%d", stockID)
-	if err = r.cache.Set(ctx, cacheKey, stock, 5*time.Minute); err != nil {
-		r.logger.Error("failed to cache stock", zap.Uint64("stock_id", stockID), zap.Error(err))
+	if err := r.cache.Delete(ctx, cacheKey); err != nil {
+		r.logger.Warn("failed to invalidate stock cache", zap.Uint64("stock_id", stockID), zap.Error(err))
 	}
 }
 
@@ -179,18 +429,33 @@ func (r *repository) CreateStockMovements(ctx context.Context, tx pgx.Tx, params
 	batch := make([]sqlc.CreateStockMovementParams, 0, len(params))
 	for _, param := range params {
 		refID := int32(param.ReferenceID)
+		var actor, note *string
+		if param.Actor != "" {
+			actor = &param.Actor
+		}
+		if param.Note != "" {
+			note = &param.Note
+		}
 		batch = append(batch, sqlc.CreateStockMovementParams{
 			StockID:     param.StockID,
 			Quantity:    param.Quantity,
-			Type:        sqlc.StockMovementType(param.ReferenceType),
+			Type:        sqlc.StockMovementType(param.Type),
 			ReferenceID: &refID,
 			ReferenceType: sqlc.NullStockMovementReferenceType{
 				StockMovementReferenceType: sqlc.StockMovementReferenceType(param.ReferenceType),
 				Valid:                      param.ReferenceType != "",
 			},
+			Actor:    actor,
+			Note:     note,
+			UnitCost: param.UnitCost,
+			Reason: sqlc.NullStockMovementReason{
+				StockMovementReason: sqlc.StockMovementReason(param.Reason),
+				Valid:               param.Reason != "",
+			},
+			ReferenceItemID: referenceItemID(param.ReferenceItemID),
 		})
 	}
-	batchResults := sqlc.New(r.conn).WithTx(tx).CreateStockMovement(ctx, batch)
+	batchResults := r.queries.WithTx(tx).CreateStockMovement(ctx, batch)
 	defer func(batchResults *sqlc.CreateStockMovementBatchResults) {
 		if err := batchResults.Close(); err != nil {
 			r.logger.Error("failed to close batch", zap.Error(err))
@@ -203,9 +468,9 @@ func (r *repository) CreateStockMovements(ctx context.Context, tx pgx.Tx, params
 			batchError = err
 			return
 		}
-		// 更新相關的庫存快取
+		// 清除相關的庫存快取
 		stockID := params[index].StockID
-		r.updateStockCache(ctx, stockID)
+		r.invalidateStockCache(ctx, stockID)
 	})
 
 	return batchError
@@ -218,18 +483,30 @@ func (r *repository) ListStockMovements(ctx context.Context, tx pgx.Tx, stockID
 	// 嘗試從快取中獲取
 	found, err := r.cache.Get(ctx, cacheKey, &stockMovements)
 	if err != nil {
-		r.logger.Error("failed to get stock movements", zap.Uint64("stock_id", stockID), zap.Error(err))
+		r.logger.Warn("failed to get stock movements from cache", zap.Uint64("stock_id", stockID), zap.Error(err))
+	}
+	params := sqlc.ListStockMovementsParams{
+		StockID: stockID,
+		Limit:   int64(limit),
+		Offset:  int64(offset),
 	}
 	if found {
-		r.logger.Info("found stock movements", zap.Uint64("stock_id", stockID))
+		r.logger.Debug("found stock movements in cache", zap.Uint64("stock_id", stockID))
+		r.shadow.Verify(ctx, cacheKey, &stockMovements, func(ctx context.Context) (any, error) {
+			sqlcStockMovements, err := r.queries.Querier().ListStockMovements(ctx, params)
+			if err != nil {
+				return nil, err
+			}
+			results := make([]*models.StockMovement, 0, len(sqlcStockMovements))
+			for _, sqlcStockMovement := range sqlcStockMovements {
+				results = append(results, new(models.StockMovement).ConvertSqlcStockMovement(sqlcStockMovement))
+			}
+			return results, nil
+		})
 		return stockMovements, nil
 	}
 
-	sqlcStockMovements, err := sqlc.New(r.conn).WithTx(tx).ListStockMovements(ctx, sqlc.ListStockMovementsParams{
-		StockID: stockID,
-		Limit:   int64(limit),
-		Offset:  int64(offset),
-	})
+	sqlcStockMovements, err := r.queries.WithTx(tx).ListStockMovements(ctx, params)
 
 	if err != nil {
 		r.logger.Error("failed to list stock movements", zap.Error(err))
@@ -244,12 +521,23 @@ func (r *repository) ListStockMovements(ctx context.Context, tx pgx.Tx, stockID
 
 	// 設置快取
 	if err = r.cache.Set(ctx, cacheKey, stockMovements, 5*time.Minute); err != nil {
-		r.logger.Error("failed to cache stock movements", zap.Error(err))
+		r.logger.Warn("failed to cache stock movements", zap.Error(err))
 	}
 
 	return stockMovements, nil
 }
 
+// CountStockMovements 計算庫存的變動記錄總數，用於分頁
+func (r *repository) CountStockMovements(ctx context.Context, tx pgx.Tx, stockID uint64) (uint64, error) {
+	count, err := r.queries.WithTx(tx).CountStockMovements(ctx, stockID)
+	if err != nil {
+		r.logger.Error("failed to count stock movements", zap.Uint64("stock_id", stockID), zap.Error(err))
+		return 0, err
+	}
+
+	return uint64(count), nil
+}
+
 func (r *repository) GetStockMovementsByReference(ctx context.Context, tx pgx.Tx, referenceType enum.StockMovementReferenceType, referenceID uint64) ([]*models.StockMovement, error) {
 	cacheKey := fmt.Sprintf("stock_movements_ref:%s:%d", referenceType, referenceID)
 	var stockMovements []*models.StockMovement
@@ -257,22 +545,33 @@ func (r *repository) GetStockMovementsByReference(ctx context.Context, tx pgx.Tx
 	// 嘗試從快取中獲取
 	found, err := r.cache.Get(ctx, cacheKey, &stockMovements)
 	if err != nil {
-		r.logger.Error("failed to get stock movements", zap.Error(err))
+		r.logger.Warn("failed to get stock movements from cache", zap.Error(err))
+	}
+	refID := int32(referenceID)
+	params := sqlc.GetStockMovementsByReferenceParams{
+		ReferenceID: &refID,
+		ReferenceType: sqlc.NullStockMovementReferenceType{
+			StockMovementReferenceType: sqlc.StockMovementReferenceType(referenceType),
+			Valid:                      referenceType != "",
+		},
 	}
 	if found {
-		r.logger.Info("found stock movements", zap.Uint64("stock_id", referenceID))
+		r.logger.Debug("found stock movements in cache", zap.Uint64("reference_id", referenceID))
+		r.shadow.Verify(ctx, cacheKey, &stockMovements, func(ctx context.Context) (any, error) {
+			sqlcStockMovements, err := r.queries.Querier().GetStockMovementsByReference(ctx, params)
+			if err != nil {
+				return nil, err
+			}
+			results := make([]*models.StockMovement, 0, len(sqlcStockMovements))
+			for _, sqlcStockMovement := range sqlcStockMovements {
+				results = append(results, new(models.StockMovement).ConvertSqlcStockMovement(sqlcStockMovement))
+			}
+			return results, nil
+		})
 		return stockMovements, nil
 	}
 
-	refID := int32(referenceID)
-	sqlcStockMovements, err := sqlc.New(r.conn).WithTx(tx).GetStockMovementsByReference(ctx,
-		sqlc.GetStockMovementsByReferenceParams{
-			ReferenceID: &refID,
-			ReferenceType: sqlc.NullStockMovementReferenceType{
-				StockMovementReferenceType: sqlc.StockMovementReferenceType(referenceType),
-				Valid:                      referenceType != "",
-			},
-		})
+	sqlcStockMovements, err := r.queries.WithTx(tx).GetStockMovementsByReference(ctx, params)
 	if err != nil {
 		r.logger.Error("failed to get stock movements", zap.Error(err))
 		return nil, err
@@ -286,8 +585,882 @@ func (r *repository) GetStockMovementsByReference(ctx context.Context, tx pgx.Tx
 
 	// 設置快取
 	if err = r.cache.Set(ctx, cacheKey, stockMovements, 5*time.Minute); err != nil {
-		r.logger.Error("failed to cache stock movements", zap.Error(err))
+		r.logger.Warn("failed to cache stock movements", zap.Error(err))
 	}
 
 	return stockMovements, nil
 }
+
+// GetStockMovementForUpdate 讀取並鎖定單筆庫存變動，避免同時沖銷
+func (r *repository) GetStockMovementForUpdate(ctx context.Context, tx pgx.Tx, movementID uint64) (*models.StockMovement, error) {
+	sqlcStockMovement, err := r.queries.WithTx(tx).GetStockMovementForUpdate(ctx, int32(movementID))
+	if err != nil {
+		r.logger.Error("failed to get stock movement", zap.Uint64("movement_id", movementID), zap.Error(err))
+		return nil, err
+	}
+
+	return new(models.StockMovement).ConvertSqlcStockMovement(sqlcStockMovement), nil
+}
+
+// CreateStockMovementReversal 建立指向原始變動的沖銷記錄，原始變動已被沖銷時回傳 ErrMovementAlreadyReversed
+func (r *repository) CreateStockMovementReversal(ctx context.Context, tx pgx.Tx, reversalOfID uint64, params CreateStockMovementParams) (*models.StockMovement, error) {
+	refID := int32(params.ReferenceID)
+	reversalOf := int32(reversalOfID)
+	var actor, note *string
+	if params.Actor != "" {
+		actor = &params.Actor
+	}
+	if params.Note != "" {
+		note = &params.Note
+	}
+
+	sqlcStockMovement, err := r.queries.WithTx(tx).CreateStockMovementReversal(ctx, sqlc.CreateStockMovementReversalParams{
+		StockID:     params.StockID,
+		Quantity:    params.Quantity,
+		Type:        sqlc.StockMovementType(params.Type),
+		ReferenceID: &refID,
+		ReferenceType: sqlc.NullStockMovementReferenceType{
+			StockMovementReferenceType: sqlc.StockMovementReferenceType(params.ReferenceType),
+			Valid:                      params.ReferenceType != "",
+		},
+		Actor:    actor,
+		Note:     note,
+		UnitCost: params.UnitCost,
+		Reason: sqlc.NullStockMovementReason{
+			StockMovementReason: sqlc.StockMovementReason(params.Reason),
+			Valid:               params.Reason != "",
+		},
+		ReversalOfID:    &reversalOf,
+		ReferenceItemID: referenceItemID(params.ReferenceItemID),
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, ErrMovementAlreadyReversed
+		}
+		r.logger.Error("failed to create stock movement reversal", zap.Uint64("reversal_of_id", reversalOfID), zap.Error(err))
+		return nil, err
+	}
+
+	r.invalidateStockCache(ctx, params.StockID)
+
+	return new(models.StockMovement).ConvertSqlcStockMovement(sqlcStockMovement), nil
+}
+
+// CreateManualStockMovement 建立人員手動登錄的庫存變動，不對應任何購物車或訂單
+func (r *repository) CreateManualStockMovement(ctx context.Context, tx pgx.Tx, params CreateManualStockMovementParams) (*models.StockMovement, error) {
+	var note *string
+	if params.Note != "" {
+		note = &params.Note
+	}
+
+	sqlcStockMovement, err := r.queries.WithTx(tx).CreateManualStockMovement(ctx, sqlc.CreateManualStockMovementParams{
+		StockID:  params.StockID,
+		Quantity: params.Quantity,
+		Type:     sqlc.StockMovementType(params.Type),
+		Note:     note,
+		Reason: sqlc.NullStockMovementReason{
+			StockMovementReason: sqlc.StockMovementReason(params.Reason),
+			Valid:               params.Reason != "",
+		},
+	})
+	if err != nil {
+		r.logger.Error("failed to create manual stock movement", zap.Uint64("stock_id", params.StockID), zap.Error(err))
+		return nil, err
+	}
+
+	r.invalidateStockCache(ctx, params.StockID)
+
+	return new(models.StockMovement).ConvertSqlcStockMovement(sqlcStockMovement), nil
+}
+
+func (r *repository) CreateStockHold(ctx context.Context, tx pgx.Tx, params CreateStockHoldParams) (*models.StockHold, error) {
+	sqlcStockHold, err := r.queries.WithTx(tx).CreateStockHold(ctx, sqlc.CreateStockHoldParams{
+		StockID:   params.StockID,
+		Quantity:  params.Quantity,
+		Reason:    params.Reason,
+		ExpiresAt: pgtype.Timestamptz{Time: params.ExpiresAt, Valid: !params.ExpiresAt.IsZero()},
+	})
+	if err != nil {
+		r.logger.Error("failed to create stock hold", zap.Uint64("stock_id", params.StockID), zap.Error(err))
+		return nil, err
+	}
+
+	return new(models.StockHold).ConvertSqlcStockHold(sqlcStockHold), nil
+}
+
+func (r *repository) GetStockHold(ctx context.Context, tx pgx.Tx, holdID uint64) (*models.StockHold, error) {
+	sqlcStockHold, err := r.queries.WithTx(tx).GetStockHold(ctx, int32(holdID))
+	if err != nil {
+		r.logger.Error("failed to get stock hold", zap.Uint64("hold_id", holdID), zap.Error(err))
+		return nil, err
+	}
+
+	return new(models.StockHold).ConvertSqlcStockHold(sqlcStockHold), nil
+}
+
+func (r *repository) ListStockHolds(ctx context.Context, tx pgx.Tx, stockID uint64) ([]*models.StockHold, error) {
+	sqlcStockHolds, err := r.queries.WithTx(tx).ListStockHolds(ctx, stockID)
+	if err != nil {
+		r.logger.Error("failed to list stock holds", zap.Uint64("stock_id", stockID), zap.Error(err))
+		return nil, err
+	}
+
+	stockHolds := make([]*models.StockHold, 0, len(sqlcStockHolds))
+	for _, sqlcStockHold := range sqlcStockHolds {
+		stockHolds = append(stockHolds, new(models.StockHold).ConvertSqlcStockHold(sqlcStockHold))
+	}
+
+	return stockHolds, nil
+}
+
+func (r *repository) ListExpiredStockHolds(ctx context.Context, tx pgx.Tx, now time.Time) ([]*models.StockHold, error) {
+	sqlcStockHolds, err := r.queries.WithTx(tx).ListExpiredStockHolds(ctx, pgtype.Timestamptz{Time: now, Valid: true})
+	if err != nil {
+		r.logger.Error("failed to list expired stock holds", zap.Error(err))
+		return nil, err
+	}
+
+	stockHolds := make([]*models.StockHold, 0, len(sqlcStockHolds))
+	for _, sqlcStockHold := range sqlcStockHolds {
+		stockHolds = append(stockHolds, new(models.StockHold).ConvertSqlcStockHold(sqlcStockHold))
+	}
+
+	return stockHolds, nil
+}
+
+// ReleaseStockHold 標記保留已釋放，回傳 false 表示該保留已經被釋放過
+func (r *repository) ReleaseStockHold(ctx context.Context, tx pgx.Tx, holdID uint64) (bool, error) {
+	rows, err := r.queries.WithTx(tx).ReleaseStockHold(ctx, int32(holdID))
+	if err != nil {
+		r.logger.Error("failed to release stock hold", zap.Uint64("hold_id", holdID), zap.Error(err))
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+// UpdateStockQuantity 增減實際庫存數量，回傳 false 表示庫存已被其他操作更新或調整後會低於預留數量；
+// 事件溯源模式的庫存只推進 updated_at，實際數量由呼叫端寫入的庫存變動決定
+func (r *repository) UpdateStockQuantity(ctx context.Context, tx pgx.Tx, params UpdateStockQuantityParams) (bool, error) {
+	// 事件溯源模式的投影可能落後，改以即時數量檢查調整後是否低於預留數量
+	delta, err := r.queries.WithTx(tx).GetUnprojectedStockDelta(ctx, params.StockID)
+	if err != nil {
+		r.logger.Error("failed to get unprojected stock delta", zap.Uint64("stock_id", params.StockID), zap.Error(err))
+		return false, err
+	}
+	if delta.EventSourced {
+		stock, err := r.LockStock(ctx, tx, params.StockID)
+		if err != nil {
+			return false, err
+		}
+		if int64(stock.Quantity)+params.Delta < int64(stock.ReservedQuantity) {
+			return false, nil
+		}
+	}
+
+	rows, err := r.queries.WithTx(tx).UpdateStockQuantity(ctx, sqlc.UpdateStockQuantityParams{
+		Delta:     int32(params.Delta),
+		ID:        int32(params.StockID),
+		UpdatedAt: pgtype.Timestamptz{Time: params.LastUpdated, Valid: true},
+	})
+	if err != nil {
+		r.logger.Error("failed to update stock quantity", zap.Uint64("stock_id", params.StockID), zap.Error(err))
+		return false, err
+	}
+	if rows == 0 {
+		return false, nil
+	}
+
+	r.invalidateStockCache(ctx, params.StockID)
+
+	return true, nil
+}
+
+func (r *repository) CreateStockAdjustment(ctx context.Context, tx pgx.Tx, params CreateStockAdjustmentParams) (*models.StockAdjustment, error) {
+	var note *string
+	if params.Note != "" {
+		note = &params.Note
+	}
+
+	sqlcStockAdjustment, err := r.queries.WithTx(tx).CreateStockAdjustment(ctx, sqlc.CreateStockAdjustmentParams{
+		StockID:       params.StockID,
+		QuantityDelta: int32(params.QuantityDelta),
+		Reason:        sqlc.StockMovementReason(params.Reason),
+		Note:          note,
+		Status:        sqlc.StockAdjustmentStatus(params.Status),
+		RequestedBy:   params.RequestedBy,
+	})
+	if err != nil {
+		r.logger.Error("failed to create stock adjustment", zap.Uint64("stock_id", params.StockID), zap.Error(err))
+		return nil, err
+	}
+
+	return new(models.StockAdjustment).ConvertSqlcStockAdjustment(sqlcStockAdjustment), nil
+}
+
+func (r *repository) GetStockAdjustment(ctx context.Context, tx pgx.Tx, adjustmentID uint64) (*models.StockAdjustment, error) {
+	sqlcStockAdjustment, err := r.queries.WithTx(tx).GetStockAdjustment(ctx, int32(adjustmentID))
+	if err != nil {
+		r.logger.Error("failed to get stock adjustment", zap.Uint64("adjustment_id", adjustmentID), zap.Error(err))
+		return nil, err
+	}
+
+	return new(models.StockAdjustment).ConvertSqlcStockAdjustment(sqlcStockAdjustment), nil
+}
+
+func (r *repository) ListStockAdjustmentsByStatus(ctx context.Context, tx pgx.Tx, status enum.StockAdjustmentStatus) ([]*models.StockAdjustment, error) {
+	sqlcStockAdjustments, err := r.queries.WithTx(tx).ListStockAdjustmentsByStatus(ctx, sqlc.StockAdjustmentStatus(status))
+	if err != nil {
+		r.logger.Error("failed to list stock adjustments", zap.String("status", string(status)), zap.Error(err))
+		return nil, err
+	}
+
+	stockAdjustments := make([]*models.StockAdjustment, 0, len(sqlcStockAdjustments))
+	for _, sqlcStockAdjustment := range sqlcStockAdjustments {
+		stockAdjustments = append(stockAdjustments, new(models.StockAdjustment).ConvertSqlcStockAdjustment(sqlcStockAdjustment))
+	}
+
+	return stockAdjustments, nil
+}
+
+// ReviewStockAdjustment 記錄審核結果，回傳 false 表示該申請已經被審核過
+func (r *repository) ReviewStockAdjustment(ctx context.Context, tx pgx.Tx, params ReviewStockAdjustmentParams) (bool, error) {
+	var reviewNote *string
+	if params.ReviewNote != "" {
+		reviewNote = &params.ReviewNote
+	}
+
+	rows, err := r.queries.WithTx(tx).ReviewStockAdjustment(ctx, sqlc.ReviewStockAdjustmentParams{
+		ID:         int32(params.AdjustmentID),
+		Status:     sqlc.StockAdjustmentStatus(params.Status),
+		ReviewedBy: &params.ReviewedBy,
+		ReviewNote: reviewNote,
+	})
+	if err != nil {
+		r.logger.Error("failed to review stock adjustment", zap.Uint64("adjustment_id", params.AdjustmentID), zap.Error(err))
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+// ListStockOutflows 列出在多個出貨地點都有庫存的商品，各地點的庫存數量及 since 之後的訂單出貨數量（不含已沖銷的出貨）
+func (r *repository) ListStockOutflows(ctx context.Context, tx pgx.Tx, since time.Time) ([]*models.StockOutflow, error) {
+	rows, err := r.queries.WithTx(tx).ListStockOutflows(ctx, pgtype.Timestamptz{Time: since, Valid: true})
+	if err != nil {
+		r.logger.Error("failed to list stock outflows", zap.Time("since", since), zap.Error(err))
+		return nil, err
+	}
+
+	outflows := make([]*models.StockOutflow, 0, len(rows))
+	for _, row := range rows {
+		outflows = append(outflows, new(models.StockOutflow).ConvertSqlcStockOutflow(row))
+	}
+
+	return outflows, nil
+}
+
+func (r *repository) CreateStockTransfer(ctx context.Context, tx pgx.Tx, params CreateStockTransferParams) (*models.StockTransfer, error) {
+	var note *string
+	if params.Note != "" {
+		note = &params.Note
+	}
+
+	sqlcStockTransfer, err := r.queries.WithTx(tx).CreateStockTransfer(ctx, sqlc.CreateStockTransferParams{
+		FromStockID: int32(params.FromStockID),
+		ToStockID:   int32(params.ToStockID),
+		Quantity:    params.Quantity,
+		Note:        note,
+		Status:      sqlc.StockTransferStatus(params.Status),
+		RequestedBy: params.RequestedBy,
+	})
+	if err != nil {
+		r.logger.Error("failed to create stock transfer",
+			zap.Uint64("from_stock_id", params.FromStockID), zap.Uint64("to_stock_id", params.ToStockID), zap.Error(err))
+		return nil, err
+	}
+
+	return new(models.StockTransfer).ConvertSqlcStockTransfer(sqlcStockTransfer), nil
+}
+
+func (r *repository) GetStockTransfer(ctx context.Context, tx pgx.Tx, transferID uint64) (*models.StockTransfer, error) {
+	sqlcStockTransfer, err := r.queries.WithTx(tx).GetStockTransfer(ctx, int32(transferID))
+	if err != nil {
+		r.logger.Error("failed to get stock transfer", zap.Uint64("transfer_id", transferID), zap.Error(err))
+		return nil, err
+	}
+
+	return new(models.StockTransfer).ConvertSqlcStockTransfer(sqlcStockTransfer), nil
+}
+
+func (r *repository) ListStockTransfersByStatus(ctx context.Context, tx pgx.Tx, status enum.StockTransferStatus) ([]*models.StockTransfer, error) {
+	sqlcStockTransfers, err := r.queries.WithTx(tx).ListStockTransfersByStatus(ctx, sqlc.StockTransferStatus(status))
+	if err != nil {
+		r.logger.Error("failed to list stock transfers", zap.String("status", string(status)), zap.Error(err))
+		return nil, err
+	}
+
+	stockTransfers := make([]*models.StockTransfer, 0, len(sqlcStockTransfers))
+	for _, sqlcStockTransfer := range sqlcStockTransfers {
+		stockTransfers = append(stockTransfers, new(models.StockTransfer).ConvertSqlcStockTransfer(sqlcStockTransfer))
+	}
+
+	return stockTransfers, nil
+}
+
+// ReviewStockTransfer 記錄審核結果，回傳 false 表示該調撥單已經被審核過
+func (r *repository) ReviewStockTransfer(ctx context.Context, tx pgx.Tx, params ReviewStockTransferParams) (bool, error) {
+	var reviewNote *string
+	if params.ReviewNote != "" {
+		reviewNote = &params.ReviewNote
+	}
+
+	rows, err := r.queries.WithTx(tx).ReviewStockTransfer(ctx, sqlc.ReviewStockTransferParams{
+		ID:         int32(params.TransferID),
+		Status:     sqlc.StockTransferStatus(params.Status),
+		ReviewedBy: &params.ReviewedBy,
+		ReviewNote: reviewNote,
+	})
+	if err != nil {
+		r.logger.Error("failed to review stock transfer", zap.Uint64("transfer_id", params.TransferID), zap.Error(err))
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+// CreateProductBarcode 登記商品條碼，條碼已登記過時回傳 ErrBarcodeTaken
+func (r *repository) CreateProductBarcode(ctx context.Context, tx pgx.Tx, barcode, productID string) error {
+	err := r.queries.WithTx(tx).CreateProductBarcode(ctx, sqlc.CreateProductBarcodeParams{
+		Barcode:   barcode,
+		ProductID: productID,
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return ErrBarcodeTaken
+		}
+		r.logger.Error("failed to create product barcode", zap.String("barcode", barcode), zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// ResolveProductBarcodes 回傳條碼對應的商品 ID，未登記的條碼不會出現在結果中
+func (r *repository) ResolveProductBarcodes(ctx context.Context, tx pgx.Tx, barcodes []string) (map[string]string, error) {
+	sqlcProductBarcodes, err := r.queries.WithTx(tx).ListProductBarcodes(ctx, barcodes)
+	if err != nil {
+		r.logger.Error("failed to list product barcodes", zap.Int("barcodes", len(barcodes)), zap.Error(err))
+		return nil, err
+	}
+
+	productIDs := make(map[string]string, len(sqlcProductBarcodes))
+	for _, sqlcProductBarcode := range sqlcProductBarcodes {
+		productIDs[sqlcProductBarcode.Barcode] = sqlcProductBarcode.ProductID
+	}
+
+	return productIDs, nil
+}
+
+// CreateStocktake 建立進行中的盤點，該地點已有進行中的盤點時回傳 ErrStocktakeInProgress
+func (r *repository) CreateStocktake(ctx context.Context, tx pgx.Tx, location, startedBy string) (*models.Stocktake, error) {
+	sqlcStocktake, err := r.queries.WithTx(tx).CreateStocktake(ctx, sqlc.CreateStocktakeParams{
+		Location:  location,
+		StartedBy: startedBy,
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, ErrStocktakeInProgress
+		}
+		r.logger.Error("failed to create stocktake", zap.String("location", location), zap.Error(err))
+		return nil, err
+	}
+
+	return new(models.Stocktake).ConvertSqlcStocktake(sqlcStocktake), nil
+}
+
+func (r *repository) GetStocktake(ctx context.Context, tx pgx.Tx, stocktakeID uint64) (*models.Stocktake, error) {
+	sqlcStocktake, err := r.queries.WithTx(tx).GetStocktake(ctx, int32(stocktakeID))
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			r.logger.Error("failed to get stocktake", zap.Uint64("stocktake_id", stocktakeID), zap.Error(err))
+		}
+		return nil, err
+	}
+
+	return new(models.Stocktake).ConvertSqlcStocktake(sqlcStocktake), nil
+}
+
+// FinishStocktake 將進行中的盤點標記為完成或取消，回傳 false 表示該盤點已經結束
+func (r *repository) FinishStocktake(ctx context.Context, tx pgx.Tx, stocktakeID uint64, status enum.StocktakeStatus, finishedBy string) (bool, error) {
+	rows, err := r.queries.WithTx(tx).FinishStocktake(ctx, sqlc.FinishStocktakeParams{
+		ID:         int32(stocktakeID),
+		Status:     sqlc.StocktakeStatus(status),
+		FinishedBy: &finishedBy,
+	})
+	if err != nil {
+		r.logger.Error("failed to finish stocktake", zap.Uint64("stocktake_id", stocktakeID), zap.Error(err))
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+// AddStocktakeScan 記錄一筆盤點掃描，回傳 false 表示同一批次的同一位置已經記錄過，或同一件序號商品已經掃描過
+func (r *repository) AddStocktakeScan(ctx context.Context, tx pgx.Tx, params AddStocktakeScanParams) (bool, error) {
+	var serialNumber *string
+	if params.SerialNumber != "" {
+		serialNumber = &params.SerialNumber
+	}
+
+	_, err := r.queries.WithTx(tx).AddStocktakeScan(ctx, sqlc.AddStocktakeScanParams{
+		StocktakeID:  int32(params.StocktakeID),
+		BatchID:      params.BatchID,
+		Position:     int32(params.Position),
+		Barcode:      params.Barcode,
+		StockID:      params.StockID,
+		SerialNumber: serialNumber,
+		Quantity:     params.Quantity,
+		ScannedAt:    pgtype.Timestamptz{Time: params.ScannedAt, Valid: true},
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		r.logger.Error("failed to add stocktake scan",
+			zap.Uint64("stocktake_id", params.StocktakeID), zap.String("batch_id", params.BatchID), zap.Error(err))
+		return false, err
+	}
+
+	return true, nil
+}
+
+// ListStocktakeCounts 列出盤點地點所有庫存的系統數量與目前累計的盤點數量，尚未掃描到的庫存盤點數量為 0
+func (r *repository) ListStocktakeCounts(ctx context.Context, tx pgx.Tx, stocktakeID uint64) ([]*models.StocktakeCount, error) {
+	sqlcStocktakeCounts, err := r.queries.WithTx(tx).ListStocktakeCounts(ctx, int32(stocktakeID))
+	if err != nil {
+		r.logger.Error("failed to list stocktake counts", zap.Uint64("stocktake_id", stocktakeID), zap.Error(err))
+		return nil, err
+	}
+
+	counts := make([]*models.StocktakeCount, 0, len(sqlcStocktakeCounts))
+	for _, sqlcStocktakeCount := range sqlcStocktakeCounts {
+		counts = append(counts, new(models.StocktakeCount).ConvertSqlcStocktakeCount(sqlcStocktakeCount))
+	}
+
+	return counts, nil
+}
+
+// LockStock 在交易內鎖定庫存列並讀取最新資料（不經過快取），用於需要序列化的檢查
+func (r *repository) LockStock(ctx context.Context, tx pgx.Tx, stockID uint64) (*models.Stock, error) {
+	sqlcStock, err := r.queries.WithTx(tx).LockStock(ctx, int32(stockID))
+	if err != nil {
+		r.logger.Error("failed to lock stock", zap.Uint64("stock_id", stockID), zap.Error(err))
+		return nil, err
+	}
+
+	stock := new(models.Stock).ConvertSqlcStock(sqlcStock)
+	if err = r.applyUnprojectedMovements(ctx, tx, stock); err != nil {
+		return nil, err
+	}
+
+	return stock, nil
+}
+
+// SetStockRentalEnabled 切換庫存的租借模式，回傳 false 表示庫存不存在
+func (r *repository) SetStockRentalEnabled(ctx context.Context, tx pgx.Tx, stockID uint64, enabled bool) (bool, error) {
+	rows, err := r.queries.WithTx(tx).SetStockRentalEnabled(ctx, sqlc.SetStockRentalEnabledParams{
+		ID:            int32(stockID),
+		RentalEnabled: enabled,
+	})
+	if err != nil {
+		r.logger.Error("failed to set stock rental mode", zap.Uint64("stock_id", stockID), zap.Error(err))
+		return false, err
+	}
+
+	// 清除快取，避免讀到舊的租借模式
+	cacheKey := fmt.Sprintf("stock:%d", stockID)
+	if err = r.cache.Delete(ctx, cacheKey); err != nil {
+		r.logger.Warn("failed to invalidate stock cache", zap.Uint64("stock_id", stockID), zap.Error(err))
+	}
+
+	return rows > 0, nil
+}
+
+func (r *repository) CreateStockRental(ctx context.Context, tx pgx.Tx, params CreateStockRentalParams) (*models.StockRental, error) {
+	var note *string
+	if params.Note != "" {
+		note = &params.Note
+	}
+
+	sqlcStockRental, err := r.queries.WithTx(tx).CreateStockRental(ctx, sqlc.CreateStockRentalParams{
+		StockID:    params.StockID,
+		CustomerID: params.CustomerID,
+		Quantity:   params.Quantity,
+		StartsOn:   pgtype.Date{Time: params.StartsOn, Valid: true},
+		EndsOn:     pgtype.Date{Time: params.EndsOn, Valid: true},
+		Note:       note,
+	})
+	if err != nil {
+		r.logger.Error("failed to create stock rental", zap.Uint64("stock_id", params.StockID), zap.Error(err))
+		return nil, err
+	}
+
+	return new(models.StockRental).ConvertSqlcStockRental(sqlcStockRental), nil
+}
+
+func (r *repository) GetStockRental(ctx context.Context, tx pgx.Tx, rentalID uint64) (*models.StockRental, error) {
+	sqlcStockRental, err := r.queries.WithTx(tx).GetStockRental(ctx, int32(rentalID))
+	if err != nil {
+		r.logger.Error("failed to get stock rental", zap.Uint64("rental_id", rentalID), zap.Error(err))
+		return nil, err
+	}
+
+	return new(models.StockRental).ConvertSqlcStockRental(sqlcStockRental), nil
+}
+
+// ListStockRentals 列出與 from 至 to（不含）期間重疊的有效租借預約
+func (r *repository) ListStockRentals(ctx context.Context, tx pgx.Tx, stockID uint64, from, to time.Time) ([]*models.StockRental, error) {
+	sqlcStockRentals, err := r.queries.WithTx(tx).ListStockRentals(ctx, sqlc.ListStockRentalsParams{
+		StockID:    stockID,
+		RangeStart: pgtype.Date{Time: from, Valid: true},
+		RangeEnd:   pgtype.Date{Time: to, Valid: true},
+	})
+	if err != nil {
+		r.logger.Error("failed to list stock rentals", zap.Uint64("stock_id", stockID), zap.Error(err))
+		return nil, err
+	}
+
+	stockRentals := make([]*models.StockRental, 0, len(sqlcStockRentals))
+	for _, sqlcStockRental := range sqlcStockRentals {
+		stockRentals = append(stockRentals, new(models.StockRental).ConvertSqlcStockRental(sqlcStockRental))
+	}
+
+	return stockRentals, nil
+}
+
+// UpdateStockRentalStatus 結束租借預約（歸還或取消），回傳 false 表示該預約已經結束
+func (r *repository) UpdateStockRentalStatus(ctx context.Context, tx pgx.Tx, rentalID uint64, status enum.StockRentalStatus) (bool, error) {
+	rows, err := r.queries.WithTx(tx).UpdateStockRentalStatus(ctx, sqlc.UpdateStockRentalStatusParams{
+		ID:     int32(rentalID),
+		Status: sqlc.StockRentalStatus(status),
+	})
+	if err != nil {
+		r.logger.Error("failed to update stock rental status", zap.Uint64("rental_id", rentalID), zap.Error(err))
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+// ListRentalBookings 回傳 from 至 to（不含）期間每天已被預約的數量，只填入 Date 與 Booked
+func (r *repository) ListRentalBookings(ctx context.Context, tx pgx.Tx, stockID uint64, from, to time.Time) ([]*models.RentalAvailability, error) {
+	rows, err := r.queries.WithTx(tx).ListRentalBookings(ctx, sqlc.ListRentalBookingsParams{
+		RangeStart: pgtype.Date{Time: from, Valid: true},
+		RangeEnd:   pgtype.Date{Time: to, Valid: true},
+		StockID:    stockID,
+	})
+	if err != nil {
+		r.logger.Error("failed to list rental bookings", zap.Uint64("stock_id", stockID), zap.Error(err))
+		return nil, err
+	}
+
+	days := make([]*models.RentalAvailability, 0, len(rows))
+	for _, row := range rows {
+		days = append(days, &models.RentalAvailability{
+			Date:   row.Day.Time,
+			Booked: uint64(row.Booked),
+		})
+	}
+
+	return days, nil
+}
+
+// ListLowStocks 依可用數量由少到多列出可用數量不超過 threshold 的庫存，最多 limit 筆
+func (r *repository) ListLowStocks(ctx context.Context, tx pgx.Tx, threshold uint64, limit int32) ([]*models.Stock, error) {
+	rows, err := r.queries.WithTx(tx).ListLowStocks(ctx, sqlc.ListLowStocksParams{
+		Threshold: int32(threshold),
+		RowLimit:  limit,
+	})
+	if err != nil {
+		r.logger.Error("failed to list low stocks", zap.Uint64("threshold", threshold), zap.Error(err))
+		return nil, err
+	}
+
+	result := make([]*models.Stock, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, new(models.Stock).ConvertSqlcStock(row))
+	}
+
+	return result, nil
+}
+
+// ListOverReservedStocks 列出預留數量超過庫存數量（或為負數）的庫存
+func (r *repository) ListOverReservedStocks(ctx context.Context, tx pgx.Tx) ([]*models.Stock, error) {
+	rows, err := r.queries.WithTx(tx).ListOverReservedStocks(ctx)
+	if err != nil {
+		r.logger.Error("failed to list over-reserved stocks", zap.Error(err))
+		return nil, err
+	}
+
+	result := make([]*models.Stock, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, new(models.Stock).ConvertSqlcStock(row))
+	}
+
+	return result, nil
+}
+
+// GetStockLevelAt 由目前的庫存數量扣回 at 之後的庫存變動，推算 at 當下的庫存水位
+func (r *repository) GetStockLevelAt(ctx context.Context, tx pgx.Tx, stockID uint64, at time.Time) (*models.StockLevel, error) {
+	row, err := r.queries.WithTx(tx).GetStockLevelAt(ctx, sqlc.GetStockLevelAtParams{
+		At:      pgtype.Timestamptz{Time: at, Valid: true},
+		StockID: int32(stockID),
+	})
+	if err != nil {
+		r.logger.Error("failed to get stock level", zap.Uint64("stock_id", stockID), zap.Time("at", at), zap.Error(err))
+		return nil, err
+	}
+
+	if row.CreatedAt.Time.After(at) {
+		return nil, fmt.Errorf("%w: stock %d was created at %s", ErrStockNotCreatedYet, stockID, row.CreatedAt.Time.Format(time.RFC3339))
+	}
+
+	level := new(models.StockLevel).ConvertSqlcStockLevel(row)
+	level.At = at
+
+	// 事件溯源模式中尚未投影的變動已在回推時扣除，需補回投影落後的部分
+	delta, err := r.queries.WithTx(tx).GetUnprojectedStockDelta(ctx, stockID)
+	if err != nil {
+		r.logger.Error("failed to get unprojected stock delta", zap.Uint64("stock_id", stockID), zap.Error(err))
+		return nil, err
+	}
+	level.Quantity += delta.QuantityDelta
+	level.ReservedQuantity += delta.ReservedQuantityDelta
+
+	return level, nil
+}
+
+// applyUnprojectedMovements 將事件溯源模式中尚未投影的庫存變動加到 stock 上，一般模式的庫存維持不變
+func (r *repository) applyUnprojectedMovements(ctx context.Context, tx pgx.Tx, stock *models.Stock) error {
+	delta, err := r.queries.WithTx(tx).GetUnprojectedStockDelta(ctx, stock.ID)
+	if err != nil {
+		r.logger.Error("failed to get unprojected stock delta", zap.Uint64("stock_id", stock.ID), zap.Error(err))
+		return err
+	}
+	if !delta.EventSourced {
+		return nil
+	}
+
+	stock.EventSourced = true
+	stock.Quantity = uint64(max(int64(stock.Quantity)+delta.QuantityDelta, 0))
+	stock.ReservedQuantity = uint64(max(int64(stock.ReservedQuantity)+delta.ReservedQuantityDelta, 0))
+
+	return nil
+}
+
+// EnableEventSourcing 讓庫存改為事件溯源模式，以目前的數量與最後一筆變動作為投影的起點，
+// 呼叫端須先以 LockStock 鎖定庫存，確保快照與變動記錄一致
+func (r *repository) EnableEventSourcing(ctx context.Context, tx pgx.Tx, stockID uint64) (*models.StockProjection, error) {
+	sqlcStockProjection, err := r.queries.WithTx(tx).EnableStockEventSourcing(ctx, int32(stockID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%w: stock %d", ErrStockAlreadyEventSourced, stockID)
+		}
+		r.logger.Error("failed to enable stock event sourcing", zap.Uint64("stock_id", stockID), zap.Error(err))
+		return nil, err
+	}
+
+	r.invalidateStockCache(ctx, stockID)
+
+	return new(models.StockProjection).ConvertSqlcStockProjection(sqlcStockProjection), nil
+}
+
+// DisableEventSourcing 讓庫存回到一般模式，呼叫端須先以 ProjectStock 套用所有變動，回傳 false 表示庫存未啟用事件溯源模式
+func (r *repository) DisableEventSourcing(ctx context.Context, tx pgx.Tx, stockID uint64) (bool, error) {
+	rows, err := r.queries.WithTx(tx).DeleteStockProjection(ctx, stockID)
+	if err != nil {
+		r.logger.Error("failed to disable stock event sourcing", zap.Uint64("stock_id", stockID), zap.Error(err))
+		return false, err
+	}
+
+	r.invalidateStockCache(ctx, stockID)
+
+	return rows > 0, nil
+}
+
+func (r *repository) GetStockProjection(ctx context.Context, tx pgx.Tx, stockID uint64) (*models.StockProjection, error) {
+	sqlcStockProjection, err := r.queries.WithTx(tx).GetStockProjection(ctx, stockID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%w: stock %d", ErrStockNotEventSourced, stockID)
+		}
+		r.logger.Error("failed to get stock projection", zap.Uint64("stock_id", stockID), zap.Error(err))
+		return nil, err
+	}
+
+	return new(models.StockProjection).ConvertSqlcStockProjection(sqlcStockProjection), nil
+}
+
+// ListStocksPendingProjection 列出仍有未投影變動的事件溯源庫存，最久未投影的排在前面
+func (r *repository) ListStocksPendingProjection(ctx context.Context, tx pgx.Tx, limit uint64) ([]uint64, error) {
+	stockIDs, err := r.queries.WithTx(tx).ListStocksPendingProjection(ctx, int32(limit))
+	if err != nil {
+		r.logger.Error("failed to list stocks pending projection", zap.Error(err))
+		return nil, err
+	}
+
+	return stockIDs, nil
+}
+
+// ProjectStock 將未投影的庫存變動套用到 stocks，回傳 false 表示沒有需要套用的變動；
+// 寫入變動的交易都會先更新或鎖定庫存列，呼叫端須先以 LockStock 鎖定庫存，才不會略過尚未提交的變動
+func (r *repository) ProjectStock(ctx context.Context, tx pgx.Tx, stockID uint64) (bool, error) {
+	rows, err := r.queries.WithTx(tx).ProjectStock(ctx, stockID)
+	if err != nil {
+		r.logger.Error("failed to project stock", zap.Uint64("stock_id", stockID), zap.Error(err))
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+// RebuildStockProjection 捨棄目前的投影，由啟用時的快照重播所有庫存變動，回傳 false 表示庫存未啟用事件溯源模式
+func (r *repository) RebuildStockProjection(ctx context.Context, tx pgx.Tx, stockID uint64) (bool, error) {
+	rows, err := r.queries.WithTx(tx).RebuildStockProjection(ctx, stockID)
+	if err != nil {
+		r.logger.Error("failed to rebuild stock projection", zap.Uint64("stock_id", stockID), zap.Error(err))
+		return false, err
+	}
+
+	// 重播可能修正了錯誤的投影，清除快取中依舊投影計算的數量
+	r.invalidateStockCache(ctx, stockID)
+
+	return rows > 0, nil
+}
+
+// CreateStore 新增門市並以資料庫產生的 ID 與時間更新 store
+func (r *repository) CreateStore(ctx context.Context, tx pgx.Tx, store *models.Store) error {
+	hours, err := json.Marshal(store.Hours)
+	if err != nil {
+		return fmt.Errorf("failed to marshal store hours: %w", err)
+	}
+
+	sqlcStore, err := r.queries.WithTx(tx).CreateStore(ctx, sqlc.CreateStoreParams{
+		Name:      store.Name,
+		Location:  store.Location,
+		Address:   store.Address,
+		Latitude:  store.Latitude,
+		Longitude: store.Longitude,
+		Hours:     hours,
+	})
+	if err != nil {
+		r.logger.Error("failed to create store", zap.String("location", store.Location), zap.Error(err))
+		return err
+	}
+
+	*store = *new(models.Store).ConvertSqlcStore(sqlcStore)
+
+	return nil
+}
+
+// UpdateStore 更新門市資料，回傳 false 表示門市不存在
+func (r *repository) UpdateStore(ctx context.Context, tx pgx.Tx, store *models.Store) (bool, error) {
+	hours, err := json.Marshal(store.Hours)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal store hours: %w", err)
+	}
+
+	rows, err := r.queries.WithTx(tx).UpdateStore(ctx, sqlc.UpdateStoreParams{
+		ID:        int32(store.ID),
+		Name:      store.Name,
+		Location:  store.Location,
+		Address:   store.Address,
+		Latitude:  store.Latitude,
+		Longitude: store.Longitude,
+		Hours:     hours,
+	})
+	if err != nil {
+		r.logger.Error("failed to update store", zap.Uint64("store_id", store.ID), zap.Error(err))
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+func (r *repository) ListStores(ctx context.Context, tx pgx.Tx) ([]*models.Store, error) {
+	sqlcStores, err := r.queries.WithTx(tx).ListStores(ctx)
+	if err != nil {
+		r.logger.Error("failed to list stores", zap.Error(err))
+		return nil, err
+	}
+
+	stores := make([]*models.Store, 0, len(sqlcStores))
+	for _, sqlcStore := range sqlcStores {
+		stores = append(stores, new(models.Store).ConvertSqlcStore(sqlcStore))
+	}
+
+	return stores, nil
+}
+
+// FindStoresWithinRadius 以 haversine 公式列出距離 (lat, lng) radiusKm 公里內的門市，近的排在前面；
+// 回傳結果只包含門市與距離，可用庫存由呼叫端填入
+func (r *repository) FindStoresWithinRadius(ctx context.Context, tx pgx.Tx, lat, lng, radiusKm float64) ([]*models.StoreAvailability, error) {
+	rows, err := r.queries.WithTx(tx).FindStoresWithinRadius(ctx, sqlc.FindStoresWithinRadiusParams{
+		Lat:      lat,
+		Lng:      lng,
+		RadiusKm: radiusKm,
+	})
+	if err != nil {
+		r.logger.Error("failed to find stores within radius", zap.Float64("radius_km", radiusKm), zap.Error(err))
+		return nil, err
+	}
+
+	stores := make([]*models.StoreAvailability, 0, len(rows))
+	for _, row := range rows {
+		stores = append(stores, &models.StoreAvailability{
+			Store:      new(models.Store).ConvertSqlcStore(row),
+			DistanceKm: row.DistanceKm,
+		})
+	}
+
+	return stores, nil
+}
+
+// RefreshProductAvailability 重新計算 product_availability，更新期間仍可讀取舊的資料
+func (r *repository) RefreshProductAvailability(ctx context.Context, tx pgx.Tx) error {
+	if err := r.queries.WithTx(tx).RefreshProductAvailability(ctx); err != nil {
+		r.logger.Error("failed to refresh product availability", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// ListProductAvailability 取得商品加總的庫存，沒有庫存記錄或在最後一次更新後才建立庫存的商品不會列出
+func (r *repository) ListProductAvailability(ctx context.Context, tx pgx.Tx, productIDs []string) ([]*models.ProductAvailability, error) {
+	rows, err := r.queries.WithTx(tx).ListProductAvailability(ctx, productIDs)
+	if err != nil {
+		r.logger.Error("failed to list product availability", zap.Int("products", len(productIDs)), zap.Error(err))
+		return nil, err
+	}
+
+	result := make([]*models.ProductAvailability, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, new(models.ProductAvailability).ConvertSqlcProductAvailability(row))
+	}
+
+	return result, nil
+}
+
+// referenceItemID 將項目 ID 轉為可為 NULL 的欄位值，0 表示不對應單一項目
+func referenceItemID(itemID uint64) *int32 {
+	if itemID == 0 {
+		return nil
+	}
+	id := int32(itemID)
+	return &id
+}
@@ -23,10 +23,97 @@ type ReduceStockParams struct {
 	LastUpdated time.Time
 }
 
+type RestoreReservedStockParams struct {
+	StockID     uint64
+	Quantity    uint64
+	LastUpdated time.Time
+}
+
 type CreateStockMovementParams struct {
 	StockID       uint64
 	Quantity      uint64
 	Type          enum.StockMovementType
 	ReferenceID   uint64
 	ReferenceType enum.StockMovementReferenceType
+	// ReferenceItemID 為對應的購物車項目或訂單項目，0 表示變動不對應單一項目
+	ReferenceItemID uint64
+
+	// 以下為選填的補充資訊
+	Actor    string
+	Note     string
+	UnitCost *float64
+	Reason   enum.StockMovementReason
+}
+
+type CreateManualStockMovementParams struct {
+	StockID  uint64
+	Quantity uint64
+	Type     enum.StockMovementType
+	Reason   enum.StockMovementReason
+	Note     string
+}
+
+type CreateStockHoldParams struct {
+	StockID   uint64
+	Quantity  uint64
+	Reason    string
+	ExpiresAt time.Time
+}
+
+type CreateStockAdjustmentParams struct {
+	StockID       uint64
+	QuantityDelta int64
+	Reason        enum.StockMovementReason
+	Note          string
+	Status        enum.StockAdjustmentStatus
+	RequestedBy   string
+}
+
+type ReviewStockAdjustmentParams struct {
+	AdjustmentID uint64
+	Status       enum.StockAdjustmentStatus
+	ReviewedBy   string
+	ReviewNote   string
+}
+
+type CreateStockTransferParams struct {
+	FromStockID uint64
+	ToStockID   uint64
+	Quantity    uint64
+	Note        string
+	Status      enum.StockTransferStatus
+	RequestedBy string
+}
+
+type ReviewStockTransferParams struct {
+	TransferID uint64
+	Status     enum.StockTransferStatus
+	ReviewedBy string
+	ReviewNote string
+}
+
+type AddStocktakeScanParams struct {
+	StocktakeID  uint64
+	BatchID      string
+	Position     int
+	Barcode      string
+	StockID      uint64
+	SerialNumber string
+	Quantity     uint64
+	ScannedAt    time.Time
+}
+
+type UpdateStockQuantityParams struct {
+	StockID     uint64
+	Delta       int64
+	LastUpdated time.Time
+}
+
+type CreateStockRentalParams struct {
+	StockID    uint64
+	CustomerID string
+	Quantity   uint64
+	StartsOn   time.Time
+	EndsOn     time.Time
+	Note       string
 }
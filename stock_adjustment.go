@@ -0,0 +1,215 @@
+package shop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"gofalre.io/shop/models"
+	"gofalre.io/shop/models/enum"
+	"gofalre.io/shop/stock"
+)
+
+// defaultAdjustmentApprovalThreshold 為預設的審核門檻，調整數量超過此值時需要第二位人員核准
+const defaultAdjustmentApprovalThreshold uint64 = 50
+
+// ErrSelfApproval 表示審核者與申請者為同一人
+var ErrSelfApproval = errors.New("stock adjustment must be reviewed by someone other than the requester")
+
+// WithAdjustmentApprovalThreshold 設定人工庫存調整需要審核的數量門檻，設為 0 表示所有調整都需要審核
+func WithAdjustmentApprovalThreshold(threshold uint64) Option {
+	return func(s *service) {
+		s.adjustmentApprovalThreshold = threshold
+	}
+}
+
+// RequestStockAdjustment 申請人工調整庫存，數量未超過門檻時直接入帳，否則建立待審核的申請
+func (s *service) RequestStockAdjustment(ctx context.Context, stockID uint64, delta int64, reason enum.StockMovementReason, note, requestedBy string) (*models.StockAdjustment, error) {
+	if delta == 0 {
+		return nil, errors.New("adjustment quantity must not be zero")
+	}
+	if reason == "" {
+		return nil, errors.New("adjustment reason is required")
+	}
+	if requestedBy == "" {
+		return nil, errors.New("adjustment requester is required")
+	}
+
+	var adjustment *models.StockAdjustment
+
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		var err error
+		adjustment, err = s.requestStockAdjustment(ctx, tx, stockID, delta, reason, note, requestedBy)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return adjustment, nil
+}
+
+// requestStockAdjustment 在呼叫端的交易內建立庫存調整申請，未超過門檻的調整直接入帳
+func (s *service) requestStockAdjustment(ctx context.Context, tx pgx.Tx, stockID uint64, delta int64, reason enum.StockMovementReason, note, requestedBy string) (*models.StockAdjustment, error) {
+	// 1. 檢查庫存是否足夠扣減
+	stockModel, err := s.stock.GetStock(ctx, tx, stockID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stock: %w", err)
+	}
+	if delta < 0 && stockModel.Quantity-stockModel.ReservedQuantity < uint64(-delta) {
+		return nil, fmt.Errorf("insufficient stock: available %d, requested %d", stockModel.Quantity-stockModel.ReservedQuantity, -delta)
+	}
+
+	// 2. 建立調整申請，未超過門檻的調整直接視為已核准
+	status := enum.StockAdjustmentStatusPending
+	if uint64(max(delta, -delta)) <= s.adjustmentApprovalThreshold {
+		status = enum.StockAdjustmentStatusApproved
+	}
+	adjustment, err := s.stock.CreateStockAdjustment(ctx, tx, stock.CreateStockAdjustmentParams{
+		StockID:       stockID,
+		QuantityDelta: delta,
+		Reason:        reason,
+		Note:          note,
+		Status:        status,
+		RequestedBy:   requestedBy,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stock adjustment: %w", err)
+	}
+
+	// 3. 已核准的調整立即入帳
+	if adjustment.Status == enum.StockAdjustmentStatusApproved {
+		if err = s.applyStockAdjustment(ctx, tx, adjustment, requestedBy); err != nil {
+			return nil, err
+		}
+	}
+
+	return adjustment, nil
+}
+
+// ApproveStockAdjustment 核准待審核的庫存調整並入帳，審核者不得為申請者本人
+func (s *service) ApproveStockAdjustment(ctx context.Context, adjustmentID uint64, approvedBy, note string) error {
+	return s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		// 1. 獲取申請並檢查審核權限
+		adjustment, err := s.getReviewableStockAdjustment(ctx, tx, adjustmentID, approvedBy, ActionApproveStockAdjustment)
+		if err != nil {
+			return err
+		}
+
+		// 2. 標記為已核准
+		if err = s.reviewStockAdjustment(ctx, tx, adjustment, enum.StockAdjustmentStatusApproved, approvedBy, note); err != nil {
+			return err
+		}
+
+		// 3. 入帳並建立庫存變動記錄
+		return s.applyStockAdjustment(ctx, tx, adjustment, approvedBy)
+	})
+}
+
+// RejectStockAdjustment 駁回待審核的庫存調整，庫存不會有任何變動
+func (s *service) RejectStockAdjustment(ctx context.Context, adjustmentID uint64, rejectedBy, note string) error {
+	return s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		adjustment, err := s.getReviewableStockAdjustment(ctx, tx, adjustmentID, rejectedBy, ActionRejectStockAdjustment)
+		if err != nil {
+			return err
+		}
+
+		return s.reviewStockAdjustment(ctx, tx, adjustment, enum.StockAdjustmentStatusRejected, rejectedBy, note)
+	})
+}
+
+// ListPendingStockAdjustments 列出所有待審核的庫存調整
+func (s *service) ListPendingStockAdjustments(ctx context.Context) ([]*models.StockAdjustment, error) {
+	return s.stock.ListStockAdjustmentsByStatus(ctx, nil, enum.StockAdjustmentStatusPending)
+}
+
+func (s *service) getReviewableStockAdjustment(ctx context.Context, tx pgx.Tx, adjustmentID uint64, reviewer string, action Action) (*models.StockAdjustment, error) {
+	if reviewer == "" {
+		return nil, errors.New("adjustment reviewer is required")
+	}
+
+	adjustment, err := s.stock.GetStockAdjustment(ctx, tx, adjustmentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stock adjustment: %w", err)
+	}
+	if adjustment.Status != enum.StockAdjustmentStatusPending {
+		return nil, fmt.Errorf("stock adjustment %d is already %s", adjustment.ID, adjustment.Status)
+	}
+	if adjustment.RequestedBy == reviewer {
+		return nil, ErrSelfApproval
+	}
+
+	if err = s.checkAuthorization(ctx, AuthorizationRequest{
+		Action:     action,
+		ResourceID: adjustment.ID,
+		Actor:      reviewer,
+	}); err != nil {
+		return nil, err
+	}
+
+	return adjustment, nil
+}
+
+func (s *service) reviewStockAdjustment(ctx context.Context, tx pgx.Tx, adjustment *models.StockAdjustment, status enum.StockAdjustmentStatus, reviewer, note string) error {
+	ok, err := s.stock.ReviewStockAdjustment(ctx, tx, stock.ReviewStockAdjustmentParams{
+		AdjustmentID: adjustment.ID,
+		Status:       status,
+		ReviewedBy:   reviewer,
+		ReviewNote:   note,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to review stock adjustment: %w", err)
+	}
+	if !ok {
+		return fmt.Errorf("stock adjustment %d is already reviewed", adjustment.ID)
+	}
+
+	adjustment.Status = status
+	adjustment.ReviewedBy = reviewer
+	adjustment.ReviewNote = note
+
+	return nil
+}
+
+// applyStockAdjustment 將調整數量寫入庫存並建立對應的庫存變動記錄
+func (s *service) applyStockAdjustment(ctx context.Context, tx pgx.Tx, adjustment *models.StockAdjustment, actor string) error {
+	stockModel, err := s.stock.GetStock(ctx, tx, adjustment.StockID)
+	if err != nil {
+		return fmt.Errorf("failed to get stock: %w", err)
+	}
+
+	ok, err := s.stock.UpdateStockQuantity(ctx, tx, stock.UpdateStockQuantityParams{
+		StockID:     adjustment.StockID,
+		Delta:       adjustment.QuantityDelta,
+		LastUpdated: stockModel.UpdatedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update stock quantity: %w", err)
+	}
+	if !ok {
+		return fmt.Errorf("stock %d was modified or has insufficient unreserved quantity for adjustment %d", adjustment.StockID, adjustment.ID)
+	}
+
+	movementType := enum.StockMovementTypeIn
+	if adjustment.QuantityDelta < 0 {
+		movementType = enum.StockMovementTypeOut
+	}
+
+	if err = s.stock.CreateStockMovements(ctx, tx, []stock.CreateStockMovementParams{
+		{
+			StockID:       adjustment.StockID,
+			Quantity:      adjustment.Quantity(),
+			Type:          movementType,
+			ReferenceID:   adjustment.ID,
+			ReferenceType: enum.StockMovementReferenceTypeAdjustment,
+			Actor:         actor,
+			Note:          adjustment.Note,
+			Reason:        adjustment.Reason,
+		},
+	}); err != nil {
+		return fmt.Errorf("failed to create stock movement: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,162 @@
+package shop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+
+	"gofalre.io/shop/models"
+	"gofalre.io/shop/models/enum"
+	"gofalre.io/shop/stock"
+)
+
+// CreateStockHold 讓員工保留部分庫存（活動、拍攝、品檢等），保留的數量會計入預留庫存，不再對外販售
+func (s *service) CreateStockHold(ctx context.Context, stockID, quantity uint64, reason string, expiresAt time.Time) (*models.StockHold, error) {
+	if quantity == 0 {
+		return nil, errors.New("hold quantity must be greater than zero")
+	}
+	if reason == "" {
+		return nil, errors.New("hold reason is required")
+	}
+	if !expiresAt.IsZero() && expiresAt.Before(time.Now()) {
+		return nil, errors.New("hold expiry must be in the future")
+	}
+
+	var hold *models.StockHold
+
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		// 1. 檢查可用庫存
+		stockModel, err := s.stock.GetStock(ctx, tx, stockID)
+		if err != nil {
+			return fmt.Errorf("failed to get stock: %w", err)
+		}
+		if stockModel.Quantity-stockModel.ReservedQuantity < quantity {
+			return fmt.Errorf("insufficient stock: available %d, requested %d", stockModel.Quantity-stockModel.ReservedQuantity, quantity)
+		}
+
+		// 2. 建立保留記錄
+		hold, err = s.stock.CreateStockHold(ctx, tx, stock.CreateStockHoldParams{
+			StockID:   stockID,
+			Quantity:  quantity,
+			Reason:    reason,
+			ExpiresAt: expiresAt,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create stock hold: %w", err)
+		}
+
+		// 3. 預留庫存
+		if err = s.stock.AdjustStock(ctx, tx, []stock.AdjustStockParams{
+			{
+				StockID:     stockID,
+				Quantity:    quantity,
+				LastUpdated: stockModel.UpdatedAt,
+			},
+		}); err != nil {
+			return fmt.Errorf("failed to adjust stock: %w", err)
+		}
+
+		// 4. 創建庫存變動記錄
+		if err = s.stock.CreateStockMovements(ctx, tx, []stock.CreateStockMovementParams{
+			{
+				StockID:       stockID,
+				Quantity:      quantity,
+				Type:          enum.StockMovementTypeReserve,
+				ReferenceID:   hold.ID,
+				ReferenceType: enum.StockMovementReferenceTypeHold,
+			},
+		}); err != nil {
+			return fmt.Errorf("failed to create stock movement: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return hold, nil
+}
+
+// ListStockHolds 列出庫存目前仍有效的保留
+func (s *service) ListStockHolds(ctx context.Context, stockID uint64) ([]*models.StockHold, error) {
+	return s.stock.ListStockHolds(ctx, nil, stockID)
+}
+
+// ReleaseStockHold 釋放保留的庫存
+func (s *service) ReleaseStockHold(ctx context.Context, holdID uint64) error {
+	return s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		hold, err := s.stock.GetStockHold(ctx, tx, holdID)
+		if err != nil {
+			return fmt.Errorf("failed to get stock hold: %w", err)
+		}
+
+		return s.releaseStockHold(ctx, tx, hold)
+	})
+}
+
+// ReleaseExpiredStockHolds 釋放所有已過期的保留，供排程定期呼叫，回傳釋放的數量
+func (s *service) ReleaseExpiredStockHolds(ctx context.Context) (int, error) {
+	holds, err := s.stock.ListExpiredStockHolds(ctx, nil, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("failed to list expired stock holds: %w", err)
+	}
+
+	released := 0
+	for _, hold := range holds {
+		if err = s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+			return s.releaseStockHold(ctx, tx, hold)
+		}); err != nil {
+			s.log(ctx).Error("Failed to release expired stock hold", zap.Uint64("hold_id", hold.ID), zap.Error(err))
+			continue
+		}
+		released++
+	}
+
+	return released, nil
+}
+
+func (s *service) releaseStockHold(ctx context.Context, tx pgx.Tx, hold *models.StockHold) error {
+	// 1. 標記保留已釋放，避免重複釋放
+	ok, err := s.stock.ReleaseStockHold(ctx, tx, hold.ID)
+	if err != nil {
+		return fmt.Errorf("failed to release stock hold: %w", err)
+	}
+	if !ok {
+		return fmt.Errorf("stock hold %d is already released", hold.ID)
+	}
+
+	stockModel, err := s.stock.GetStock(ctx, tx, hold.StockID)
+	if err != nil {
+		return fmt.Errorf("failed to get stock: %w", err)
+	}
+
+	// 2. 釋放預留庫存
+	if err = s.stock.ReleaseStock(ctx, tx, []stock.ReleaseStockParams{
+		{
+			StockID:     hold.StockID,
+			Quantity:    hold.Quantity,
+			LastUpdated: stockModel.UpdatedAt,
+		},
+	}); err != nil {
+		return fmt.Errorf("failed to release stock: %w", err)
+	}
+
+	// 3. 創建庫存變動記錄
+	if err = s.stock.CreateStockMovements(ctx, tx, []stock.CreateStockMovementParams{
+		{
+			StockID:       hold.StockID,
+			Quantity:      hold.Quantity,
+			Type:          enum.StockMovementTypeRelease,
+			ReferenceID:   hold.ID,
+			ReferenceType: enum.StockMovementReferenceTypeHold,
+		},
+	}); err != nil {
+		return fmt.Errorf("failed to create stock movement: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,248 @@
+package shop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"gofalre.io/shop/models"
+	"gofalre.io/shop/models/enum"
+	"gofalre.io/shop/stock"
+)
+
+// ErrInvalidManualMovement 表示手動庫存變動的類型與原因代碼不相符
+var ErrInvalidManualMovement = errors.New("invalid manual stock movement")
+
+// manualMovementTypes 列出手動庫存變動可使用的原因代碼及其允許的變動類型，預留與釋放由購物車及保留流程管理
+var manualMovementTypes = map[enum.StockMovementReason][]enum.StockMovementType{
+	enum.StockMovementReasonDamaged:    {enum.StockMovementTypeOut},
+	enum.StockMovementReasonTheft:      {enum.StockMovementTypeOut},
+	enum.StockMovementReasonFound:      {enum.StockMovementTypeIn},
+	enum.StockMovementReasonCorrection: {enum.StockMovementTypeIn, enum.StockMovementTypeOut},
+}
+
+// CreateStock 為商品在指定地點建立庫存，初始數量以一筆進貨記錄入帳
+func (s *service) CreateStock(ctx context.Context, productID, location string, quantity uint64) (*models.Stock, error) {
+	if productID == "" {
+		return nil, errors.New("product ID is required")
+	}
+
+	var stockModel *models.Stock
+
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		var err error
+
+		// 1. 建立庫存
+		stockModel, err = s.stock.CreateStock(ctx, tx, productID, location, quantity)
+		if err != nil {
+			return fmt.Errorf("failed to create stock: %w", err)
+		}
+		if quantity == 0 {
+			return nil
+		}
+
+		// 2. 記錄初始數量的進貨
+		if _, err = s.stock.CreateManualStockMovement(ctx, tx, stock.CreateManualStockMovementParams{
+			StockID:  stockModel.ID,
+			Quantity: quantity,
+			Type:     enum.StockMovementTypeIn,
+			Reason:   enum.StockMovementReasonReceived,
+			Note:     "initial stock",
+		}); err != nil {
+			return fmt.Errorf("failed to create initial stock movement: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return stockModel, nil
+}
+
+// CreateManualStockMovement 由倉庫人員手動登錄庫存進出（損壞、失竊、尋回、更正），數量與變動記錄在同一筆交易中入帳
+func (s *service) CreateManualStockMovement(ctx context.Context, stockID, qty uint64, movementType enum.StockMovementType, reasonCode enum.StockMovementReason, note string) (*models.StockMovement, error) {
+	if qty == 0 {
+		return nil, fmt.Errorf("%w: quantity must be greater than zero", ErrInvalidManualMovement)
+	}
+	allowed, ok := manualMovementTypes[reasonCode]
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported reason code %q", ErrInvalidManualMovement, reasonCode)
+	}
+	if !slices.Contains(allowed, movementType) {
+		return nil, fmt.Errorf("%w: reason code %s does not allow movement type %q", ErrInvalidManualMovement, reasonCode, movementType)
+	}
+
+	// 1. 檢查操作權限，呼叫者身分由 ctx 提供
+	if err := s.checkAuthorization(ctx, AuthorizationRequest{
+		Action:     ActionCreateManualMovement,
+		ResourceID: stockID,
+	}); err != nil {
+		return nil, err
+	}
+
+	var movement *models.StockMovement
+
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		// 2. 先鎖定庫存再寫入變動，事件溯源模式的投影程序依此確保不會略過尚未提交的變動
+		stockModel, err := s.stock.LockStock(ctx, tx, stockID)
+		if err != nil {
+			return fmt.Errorf("failed to lock stock: %w", err)
+		}
+
+		// 3. 建立庫存變動記錄
+		movement, err = s.stock.CreateManualStockMovement(ctx, tx, stock.CreateManualStockMovementParams{
+			StockID:  stockID,
+			Quantity: qty,
+			Type:     movementType,
+			Reason:   reasonCode,
+			Note:     note,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create manual stock movement: %w", err)
+		}
+
+		// 4. 增減庫存數量，出庫不得扣到已預留的數量
+		delta := int64(qty)
+		if movementType == enum.StockMovementTypeOut {
+			delta = -delta
+		}
+		ok, err := s.stock.UpdateStockQuantity(ctx, tx, stock.UpdateStockQuantityParams{
+			StockID:     stockID,
+			Delta:       delta,
+			LastUpdated: stockModel.UpdatedAt,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update stock quantity: %w", err)
+		}
+		if !ok {
+			return fmt.Errorf("stock %d has insufficient unreserved quantity for manual movement", stockID)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return movement, nil
+}
+
+// ReverseStockMovement 以一筆反向的庫存變動沖銷誤植的進出貨記錄，每筆變動只能沖銷一次
+func (s *service) ReverseStockMovement(ctx context.Context, movementID uint64, reason string) (*models.StockMovement, error) {
+	if reason == "" {
+		return nil, errors.New("reversal reason is required")
+	}
+
+	var reversal *models.StockMovement
+
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		// 1. 鎖定原始變動並決定反向的類型
+		original, err := s.stock.GetStockMovementForUpdate(ctx, tx, movementID)
+		if err != nil {
+			return fmt.Errorf("failed to get stock movement: %w", err)
+		}
+		if original.ReversalOfID != nil {
+			return fmt.Errorf("stock movement %d is itself a reversal and cannot be reversed", original.ID)
+		}
+
+		var reversalType enum.StockMovementType
+		var delta int64
+		switch original.Type {
+		case enum.StockMovementTypeIn:
+			reversalType, delta = enum.StockMovementTypeOut, -int64(original.Quantity)
+		case enum.StockMovementTypeOut:
+			reversalType, delta = enum.StockMovementTypeIn, int64(original.Quantity)
+		default:
+			// 預留與釋放由購物車及保留流程管理，沖銷會造成兩邊資料不一致
+			return fmt.Errorf("stock movement %d of type %s cannot be reversed", original.ID, original.Type)
+		}
+
+		// 2. 先鎖定庫存再寫入變動，事件溯源模式的投影程序依此確保不會略過尚未提交的變動
+		stockModel, err := s.stock.LockStock(ctx, tx, original.StockID)
+		if err != nil {
+			return fmt.Errorf("failed to lock stock: %w", err)
+		}
+
+		// 3. 建立沖銷記錄，已被沖銷過的變動會在此失敗
+		reversal, err = s.stock.CreateStockMovementReversal(ctx, tx, original.ID, stock.CreateStockMovementParams{
+			StockID:         original.StockID,
+			Quantity:        original.Quantity,
+			Type:            reversalType,
+			ReferenceID:     original.ReferenceID,
+			ReferenceType:   original.ReferenceType,
+			ReferenceItemID: original.ReferenceItemID,
+			Note:            reason,
+			UnitCost:        original.UnitCost,
+			Reason:          enum.StockMovementReasonCorrection,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create stock movement reversal: %w", err)
+		}
+
+		// 4. 回補或扣除庫存數量
+		ok, err := s.stock.UpdateStockQuantity(ctx, tx, stock.UpdateStockQuantityParams{
+			StockID:     original.StockID,
+			Delta:       delta,
+			LastUpdated: stockModel.UpdatedAt,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update stock quantity: %w", err)
+		}
+		if !ok {
+			return fmt.Errorf("stock %d has insufficient unreserved quantity to reverse movement %d", original.StockID, original.ID)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return reversal, nil
+}
+
+// ListStockMovements 分頁列出庫存的變動記錄及總筆數，新的記錄在前
+func (s *service) ListStockMovements(ctx context.Context, stockID uint64, limit, offset uint64) (*models.Page[*models.StockMovement], error) {
+	var page *models.Page[*models.StockMovement]
+
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		movements, err := s.stock.ListStockMovements(ctx, tx, stockID, limit, offset)
+		if err != nil {
+			return fmt.Errorf("failed to list stock movements: %w", err)
+		}
+
+		total, err := s.stock.CountStockMovements(ctx, tx, stockID)
+		if err != nil {
+			return fmt.Errorf("failed to count stock movements: %w", err)
+		}
+
+		page = models.NewPage(movements, total, limit, offset)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// GetStockLevelAt 依庫存變動記錄推算指定時間點的庫存水位，用於月底盤點等報表，不需要凍結庫存操作
+func (s *service) GetStockLevelAt(ctx context.Context, stockID uint64, at time.Time) (*models.StockLevel, error) {
+	var level *models.StockLevel
+
+	// 在同一個快照中讀取目前數量與變動記錄，避免期間的變動造成推算錯誤
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		var err error
+		level, err = s.stock.GetStockLevelAt(ctx, tx, stockID, at)
+		if err != nil {
+			return fmt.Errorf("failed to get stock level at %s: %w", at.Format(time.RFC3339), err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return level, nil
+}
@@ -0,0 +1,128 @@
+package shop
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+
+	"gofalre.io/shop/models"
+	"gofalre.io/shop/stock"
+)
+
+// stockProjectionBatchSize 每次 ProjectStocks 最多處理的庫存數
+const stockProjectionBatchSize = 100
+
+// EnableStockEventSourcing 讓庫存改為事件溯源模式：之後庫存變動為唯一的寫入來源，stocks 的數量只是由 ProjectStocks 非同步更新的投影；
+// 單筆讀取（GetStock、LockStock 等）會即時加上未投影的變動，列表與商品快照則讀取投影，可能落後到下一次 ProjectStocks
+func (s *service) EnableStockEventSourcing(ctx context.Context, stockID uint64) (*models.StockProjection, error) {
+	var projection *models.StockProjection
+
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		// 1. 鎖定庫存，確保快照與變動記錄一致
+		if _, err := s.stock.LockStock(ctx, tx, stockID); err != nil {
+			return fmt.Errorf("failed to lock stock: %w", err)
+		}
+
+		// 2. 以目前的數量作為投影的起點
+		var err error
+		projection, err = s.stock.EnableEventSourcing(ctx, tx, stockID)
+		if err != nil {
+			return fmt.Errorf("failed to enable stock event sourcing: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return projection, nil
+}
+
+// DisableStockEventSourcing 套用所有未投影的變動後讓庫存回到一般模式
+func (s *service) DisableStockEventSourcing(ctx context.Context, stockID uint64) error {
+	return s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		// 1. 鎖定庫存並套用未投影的變動
+		if _, err := s.stock.LockStock(ctx, tx, stockID); err != nil {
+			return fmt.Errorf("failed to lock stock: %w", err)
+		}
+		if _, err := s.stock.ProjectStock(ctx, tx, stockID); err != nil {
+			return fmt.Errorf("failed to project stock: %w", err)
+		}
+
+		// 2. 移除投影狀態
+		ok, err := s.stock.DisableEventSourcing(ctx, tx, stockID)
+		if err != nil {
+			return fmt.Errorf("failed to disable stock event sourcing: %w", err)
+		}
+		if !ok {
+			return fmt.Errorf("%w: stock %d", stock.ErrStockNotEventSourced, stockID)
+		}
+
+		return nil
+	})
+}
+
+// ProjectStocks 將事件溯源庫存未投影的變動套用到 stocks，供排程定期呼叫，回傳更新的庫存數
+func (s *service) ProjectStocks(ctx context.Context) (int, error) {
+	stockIDs, err := s.stock.ListStocksPendingProjection(ctx, nil, stockProjectionBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list stocks pending projection: %w", err)
+	}
+
+	projected := 0
+	for _, stockID := range stockIDs {
+		var ok bool
+		if err = s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+			// 等待寫入中的交易提交，避免略過尚未提交的變動
+			if _, err := s.stock.LockStock(ctx, tx, stockID); err != nil {
+				return fmt.Errorf("failed to lock stock: %w", err)
+			}
+
+			var err error
+			ok, err = s.stock.ProjectStock(ctx, tx, stockID)
+			return err
+		}); err != nil {
+			s.log(ctx).Error("Failed to project stock", zap.Uint64("stock_id", stockID), zap.Error(err))
+			continue
+		}
+		if ok {
+			projected++
+		}
+	}
+
+	return projected, nil
+}
+
+// RebuildStockProjection 由啟用時的快照重播所有庫存變動重建投影，用於修正錯誤的投影邏輯或資料後的重算
+func (s *service) RebuildStockProjection(ctx context.Context, stockID uint64) (*models.Stock, error) {
+	var stockModel *models.Stock
+
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		// 1. 鎖定庫存，避免重播期間寫入新的變動
+		if _, err := s.stock.LockStock(ctx, tx, stockID); err != nil {
+			return fmt.Errorf("failed to lock stock: %w", err)
+		}
+
+		// 2. 重播變動
+		ok, err := s.stock.RebuildStockProjection(ctx, tx, stockID)
+		if err != nil {
+			return fmt.Errorf("failed to rebuild stock projection: %w", err)
+		}
+		if !ok {
+			return fmt.Errorf("%w: stock %d", stock.ErrStockNotEventSourced, stockID)
+		}
+
+		// 3. 讀取重建後的庫存
+		if stockModel, err = s.stock.LockStock(ctx, tx, stockID); err != nil {
+			return fmt.Errorf("failed to get stock: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return stockModel, nil
+}
@@ -0,0 +1,335 @@
+package shop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+
+	"gofalre.io/shop/models"
+	"gofalre.io/shop/models/enum"
+	"gofalre.io/shop/stock"
+)
+
+const (
+	// defaultRebalanceLookback 為預設計算各地點出貨速度的統計期間
+	defaultRebalanceLookback = 30 * 24 * time.Hour
+	// defaultRebalanceCoverDays 為預設各地點應保有的庫存天數
+	defaultRebalanceCoverDays = 14
+)
+
+// transferSuggestionRequester 為調撥建議自動建立的草稿所記錄的申請者
+const transferSuggestionRequester = "system:rebalance"
+
+// WithStockRebalancing 設定調撥建議計算出貨速度的統計期間，以及各地點依出貨速度應保有的庫存天數
+func WithStockRebalancing(lookback time.Duration, targetCoverDays int) Option {
+	return func(s *service) {
+		if lookback > 0 {
+			s.rebalanceLookback = lookback
+		}
+		if targetCoverDays > 0 {
+			s.rebalanceCoverDays = targetCoverDays
+		}
+	}
+}
+
+// SuggestTransfers 依各地點在統計期間內的訂單出貨速度與目前可用數量，建議將庫存從過剩的地點調撥到不足或已缺貨的地點；
+// createDrafts 為 true 時為每個建議建立待人員核准的調撥單草稿。已有待核准草稿的地點組合不會重複建議
+func (s *service) SuggestTransfers(ctx context.Context, createDrafts bool) ([]*models.TransferSuggestion, error) {
+	var suggestions []*models.TransferSuggestion
+
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		// 1. 獲取各地點的庫存與出貨數量
+		outflows, err := s.stock.ListStockOutflows(ctx, tx, time.Now().Add(-s.rebalanceLookback))
+		if err != nil {
+			return fmt.Errorf("failed to list stock outflows: %w", err)
+		}
+
+		// 2. 略過已有待核准草稿的地點組合
+		drafts, err := s.stock.ListStockTransfersByStatus(ctx, tx, enum.StockTransferStatusDraft)
+		if err != nil {
+			return fmt.Errorf("failed to list draft stock transfers: %w", err)
+		}
+		pending := make(map[[2]uint64]bool, len(drafts))
+		for _, draft := range drafts {
+			pending[[2]uint64{draft.FromStockID, draft.ToStockID}] = true
+		}
+
+		lookbackDays := s.rebalanceLookback.Hours() / 24
+		for _, suggestion := range planTransfers(outflows, lookbackDays, s.rebalanceCoverDays) {
+			if !pending[[2]uint64{suggestion.FromStockID, suggestion.ToStockID}] {
+				suggestions = append(suggestions, suggestion)
+			}
+		}
+		if !createDrafts {
+			return nil
+		}
+
+		// 3. 建立調撥單草稿
+		for _, suggestion := range suggestions {
+			transfer, err := s.stock.CreateStockTransfer(ctx, tx, stock.CreateStockTransferParams{
+				FromStockID: suggestion.FromStockID,
+				ToStockID:   suggestion.ToStockID,
+				Quantity:    suggestion.Quantity,
+				Note: fmt.Sprintf("rebalance %s: %s (%.1f/day) -> %s (%.1f/day)",
+					suggestion.ProductID, suggestion.FromLocation, suggestion.FromDailyOut, suggestion.ToLocation, suggestion.ToDailyOut),
+				Status:      enum.StockTransferStatusDraft,
+				RequestedBy: transferSuggestionRequester,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to create stock transfer: %w", err)
+			}
+			suggestion.TransferID = &transfer.ID
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	s.log(ctx).Info("Stock transfer suggestions generated",
+		zap.Int("suggestions", len(suggestions)), zap.Bool("drafts_created", createDrafts))
+
+	return suggestions, nil
+}
+
+// ApproveStockTransfer 核准調撥單草稿並移動庫存，審核者不得為申請者本人
+func (s *service) ApproveStockTransfer(ctx context.Context, transferID uint64, approvedBy, note string) error {
+	return s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		// 1. 獲取調撥單並檢查審核權限
+		transfer, err := s.getReviewableStockTransfer(ctx, tx, transferID, approvedBy, ActionApproveStockTransfer)
+		if err != nil {
+			return err
+		}
+
+		// 2. 標記為已核准
+		if err = s.reviewStockTransfer(ctx, tx, transfer, enum.StockTransferStatusApproved, approvedBy, note); err != nil {
+			return err
+		}
+
+		// 3. 移動庫存並建立庫存變動記錄
+		return s.applyStockTransfer(ctx, tx, transfer, approvedBy)
+	})
+}
+
+// RejectStockTransfer 駁回調撥單草稿，庫存不會有任何變動
+func (s *service) RejectStockTransfer(ctx context.Context, transferID uint64, rejectedBy, note string) error {
+	return s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		transfer, err := s.getReviewableStockTransfer(ctx, tx, transferID, rejectedBy, ActionRejectStockTransfer)
+		if err != nil {
+			return err
+		}
+
+		return s.reviewStockTransfer(ctx, tx, transfer, enum.StockTransferStatusRejected, rejectedBy, note)
+	})
+}
+
+// ListDraftStockTransfers 列出所有待核准的調撥單草稿
+func (s *service) ListDraftStockTransfers(ctx context.Context) ([]*models.StockTransfer, error) {
+	var transfers []*models.StockTransfer
+
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		var err error
+		transfers, err = s.stock.ListStockTransfersByStatus(ctx, tx, enum.StockTransferStatusDraft)
+		if err != nil {
+			return fmt.Errorf("failed to list draft stock transfers: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return transfers, nil
+}
+
+func (s *service) getReviewableStockTransfer(ctx context.Context, tx pgx.Tx, transferID uint64, reviewer string, action Action) (*models.StockTransfer, error) {
+	if reviewer == "" {
+		return nil, errors.New("transfer reviewer is required")
+	}
+
+	transfer, err := s.stock.GetStockTransfer(ctx, tx, transferID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stock transfer: %w", err)
+	}
+	if transfer.Status != enum.StockTransferStatusDraft {
+		return nil, fmt.Errorf("stock transfer %d is already %s", transfer.ID, transfer.Status)
+	}
+	if transfer.RequestedBy == reviewer {
+		return nil, ErrSelfApproval
+	}
+
+	if err = s.checkAuthorization(ctx, AuthorizationRequest{
+		Action:     action,
+		ResourceID: transfer.ID,
+		Actor:      reviewer,
+	}); err != nil {
+		return nil, err
+	}
+
+	return transfer, nil
+}
+
+func (s *service) reviewStockTransfer(ctx context.Context, tx pgx.Tx, transfer *models.StockTransfer, status enum.StockTransferStatus, reviewer, note string) error {
+	ok, err := s.stock.ReviewStockTransfer(ctx, tx, stock.ReviewStockTransferParams{
+		TransferID: transfer.ID,
+		Status:     status,
+		ReviewedBy: reviewer,
+		ReviewNote: note,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to review stock transfer: %w", err)
+	}
+	if !ok {
+		return fmt.Errorf("stock transfer %d is already reviewed", transfer.ID)
+	}
+
+	transfer.Status = status
+	transfer.ReviewedBy = reviewer
+	transfer.ReviewNote = note
+
+	return nil
+}
+
+// applyStockTransfer 從來源地點扣除調撥數量並加到目的地點，各建立一筆庫存變動記錄
+func (s *service) applyStockTransfer(ctx context.Context, tx pgx.Tx, transfer *models.StockTransfer, actor string) error {
+	from, err := s.stock.GetStock(ctx, tx, transfer.FromStockID)
+	if err != nil {
+		return fmt.Errorf("failed to get source stock: %w", err)
+	}
+	to, err := s.stock.GetStock(ctx, tx, transfer.ToStockID)
+	if err != nil {
+		return fmt.Errorf("failed to get destination stock: %w", err)
+	}
+	if from.ProductID != to.ProductID {
+		return fmt.Errorf("stock transfer %d moves product %s into stock of product %s", transfer.ID, from.ProductID, to.ProductID)
+	}
+
+	ok, err := s.stock.UpdateStockQuantity(ctx, tx, stock.UpdateStockQuantityParams{
+		StockID:     from.ID,
+		Delta:       -int64(transfer.Quantity),
+		LastUpdated: from.UpdatedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update source stock quantity: %w", err)
+	}
+	if !ok {
+		return fmt.Errorf("stock %d was modified or has insufficient unreserved quantity for transfer %d", from.ID, transfer.ID)
+	}
+
+	ok, err = s.stock.UpdateStockQuantity(ctx, tx, stock.UpdateStockQuantityParams{
+		StockID:     to.ID,
+		Delta:       int64(transfer.Quantity),
+		LastUpdated: to.UpdatedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update destination stock quantity: %w", err)
+	}
+	if !ok {
+		return fmt.Errorf("stock %d was modified during transfer %d", to.ID, transfer.ID)
+	}
+
+	movements := []stock.CreateStockMovementParams{
+		{StockID: from.ID, Type: enum.StockMovementTypeOut},
+		{StockID: to.ID, Type: enum.StockMovementTypeIn},
+	}
+	for i := range movements {
+		movements[i].Quantity = transfer.Quantity
+		movements[i].ReferenceID = transfer.ID
+		movements[i].ReferenceType = enum.StockMovementReferenceTypeTransfer
+		movements[i].Actor = actor
+		movements[i].Note = transfer.Note
+	}
+	if err = s.stock.CreateStockMovements(ctx, tx, movements); err != nil {
+		return fmt.Errorf("failed to create stock movements: %w", err)
+	}
+
+	return nil
+}
+
+// stockBalance 為單一地點相對目標庫存的差額，balance 為負數表示不足
+type stockBalance struct {
+	*models.StockOutflow
+	dailyOut float64
+	balance  int64
+}
+
+// planTransfers 為每個商品配對過剩與不足的地點，outflows 需依商品排序
+func planTransfers(outflows []*models.StockOutflow, lookbackDays float64, coverDays int) []*models.TransferSuggestion {
+	var suggestions []*models.TransferSuggestion
+
+	for start := 0; start < len(outflows); {
+		end := start + 1
+		for end < len(outflows) && outflows[end].ProductID == outflows[start].ProductID {
+			end++
+		}
+		suggestions = append(suggestions, planProductTransfers(outflows[start:end], lookbackDays, coverDays)...)
+		start = end
+	}
+
+	return suggestions
+}
+
+// planProductTransfers 以出貨速度乘上庫存天數作為各地點的目標數量，優先補足已缺貨的地點，再依不足數量由多到少分配
+func planProductTransfers(outflows []*models.StockOutflow, lookbackDays float64, coverDays int) []*models.TransferSuggestion {
+	var shortages, surpluses []*stockBalance
+	for _, outflow := range outflows {
+		dailyOut := float64(outflow.OutQuantity) / lookbackDays
+		target := int64(math.Ceil(dailyOut * float64(coverDays)))
+		balance := &stockBalance{
+			StockOutflow: outflow,
+			dailyOut:     dailyOut,
+			balance:      int64(outflow.Available()) - target,
+		}
+		switch {
+		case balance.balance < 0 && dailyOut > 0:
+			shortages = append(shortages, balance)
+		case balance.balance > 0:
+			surpluses = append(surpluses, balance)
+		}
+	}
+
+	sort.SliceStable(shortages, func(i, j int) bool {
+		iOut, jOut := shortages[i].Available() == 0, shortages[j].Available() == 0
+		if iOut != jOut {
+			return iOut
+		}
+		return shortages[i].balance < shortages[j].balance
+	})
+	sort.SliceStable(surpluses, func(i, j int) bool {
+		return surpluses[i].balance > surpluses[j].balance
+	})
+
+	var suggestions []*models.TransferSuggestion
+	for i, j := 0, 0; i < len(shortages) && j < len(surpluses); {
+		shortage, surplus := shortages[i], surpluses[j]
+		quantity := min(-shortage.balance, surplus.balance)
+		suggestions = append(suggestions, &models.TransferSuggestion{
+			ProductID:     shortage.ProductID,
+			FromStockID:   surplus.StockID,
+			FromLocation:  surplus.Location,
+			ToStockID:     shortage.StockID,
+			ToLocation:    shortage.Location,
+			Quantity:      uint64(quantity),
+			FromDailyOut:  math.Round(surplus.dailyOut*100) / 100,
+			ToDailyOut:    math.Round(shortage.dailyOut*100) / 100,
+			FromAvailable: surplus.Available(),
+			ToAvailable:   shortage.Available(),
+		})
+
+		shortage.balance += quantity
+		surplus.balance -= quantity
+		if shortage.balance == 0 {
+			i++
+		}
+		if surplus.balance == 0 {
+			j++
+		}
+	}
+
+	return suggestions
+}
@@ -0,0 +1,262 @@
+package shop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"gofalre.io/shop/models"
+	"gofalre.io/shop/models/enum"
+	"gofalre.io/shop/stock"
+)
+
+const (
+	// maxStocktakeScanBatchSize 為單一掃描批次的最大掃描筆數
+	maxStocktakeScanBatchSize = 500
+	// maxStocktakeBatchIDLength 為掃描器產生的批次 ID 最大長度
+	maxStocktakeBatchIDLength = 64
+)
+
+// ErrStocktakeNotOpen 表示盤點已完成或已取消，無法再上傳掃描或結束
+var ErrStocktakeNotOpen = errors.New("stocktake is not open")
+
+// RegisterProductBarcode 登記商品條碼供盤點掃描對應商品，條碼已登記過時回傳 stock.ErrBarcodeTaken
+func (s *service) RegisterProductBarcode(ctx context.Context, barcode, productID string) error {
+	barcode = strings.TrimSpace(barcode)
+	if barcode == "" {
+		return errors.New("barcode is required")
+	}
+	if productID == "" {
+		return errors.New("product ID is required")
+	}
+
+	if err := s.stock.CreateProductBarcode(ctx, nil, barcode, productID); err != nil {
+		return fmt.Errorf("failed to create product barcode: %w", err)
+	}
+	return nil
+}
+
+// StartStocktake 在指定地點開始盤點，該地點已有進行中的盤點時回傳 stock.ErrStocktakeInProgress
+func (s *service) StartStocktake(ctx context.Context, location, startedBy string) (*models.Stocktake, error) {
+	if location == "" {
+		return nil, errors.New("stocktake location is required")
+	}
+	if startedBy == "" {
+		return nil, errors.New("stocktake starter is required")
+	}
+
+	stocktake, err := s.stock.CreateStocktake(ctx, nil, location, startedBy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stocktake: %w", err)
+	}
+	return stocktake, nil
+}
+
+// SubmitStocktakeScans 將掃描器上傳的掃描批次對應到盤點地點的庫存並累加盤點數量；
+// 重送的批次與重複掃描的序號會標記為 duplicate 而不重複計入，無法對應的條碼會標記後略過，不影響同批次的其他掃描
+func (s *service) SubmitStocktakeScans(ctx context.Context, stocktakeID uint64, batch *models.StocktakeScanBatch) (*models.StocktakeBatchResult, error) {
+	// 1. 驗證批次內容
+	if batch.BatchID == "" {
+		return nil, errors.New("scan batch ID is required")
+	}
+	if len(batch.BatchID) > maxStocktakeBatchIDLength {
+		return nil, fmt.Errorf("scan batch ID exceeds %d characters", maxStocktakeBatchIDLength)
+	}
+	if len(batch.Scans) == 0 {
+		return nil, errors.New("scan batch is empty")
+	}
+	if len(batch.Scans) > maxStocktakeScanBatchSize {
+		return nil, fmt.Errorf("scan batch exceeds %d scans", maxStocktakeScanBatchSize)
+	}
+
+	result := &models.StocktakeBatchResult{
+		StocktakeID: stocktakeID,
+		BatchID:     batch.BatchID,
+		Results:     make([]*models.StocktakeScanResult, 0, len(batch.Scans)),
+	}
+
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		// 2. 檢查盤點狀態
+		stocktake, err := s.openStocktake(ctx, tx, stocktakeID)
+		if err != nil {
+			return err
+		}
+
+		// 3. 將條碼對應到商品
+		barcodes := make([]string, 0, len(batch.Scans))
+		for _, scan := range batch.Scans {
+			barcodes = append(barcodes, strings.TrimSpace(scan.Barcode))
+		}
+		productIDs, err := s.stock.ResolveProductBarcodes(ctx, tx, barcodes)
+		if err != nil {
+			return fmt.Errorf("failed to resolve barcodes: %w", err)
+		}
+
+		// 4. 逐筆對應到盤點地點的庫存並記錄
+		stocks := make(map[string]*models.Stock)
+		for position, scan := range batch.Scans {
+			scanResult := &models.StocktakeScanResult{
+				Position: position,
+				Barcode:  barcodes[position],
+			}
+			result.Results = append(result.Results, scanResult)
+
+			quantity := max(scan.Quantity, 1)
+			if scanResult.Barcode == "" || (scan.SerialNumber != "" && quantity != 1) {
+				scanResult.Outcome = enum.StocktakeScanOutcomeInvalid
+				result.Rejected++
+				continue
+			}
+
+			productID, ok := productIDs[scanResult.Barcode]
+			if !ok {
+				scanResult.Outcome = enum.StocktakeScanOutcomeUnknownBarcode
+				result.Rejected++
+				continue
+			}
+			scanResult.ProductID = productID
+
+			stockModel, ok := stocks[productID]
+			if !ok {
+				stockModel, err = s.stock.GetStockByProductAndLocation(ctx, tx, productID, stocktake.Location)
+				if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+					return fmt.Errorf("failed to get stock for product %s: %w", productID, err)
+				}
+				stocks[productID] = stockModel
+			}
+			if stockModel == nil {
+				scanResult.Outcome = enum.StocktakeScanOutcomeNotStocked
+				result.Rejected++
+				continue
+			}
+			scanResult.StockID = stockModel.ID
+
+			scannedAt := scan.ScannedAt
+			if scannedAt.IsZero() {
+				scannedAt = time.Now()
+			}
+			added, err := s.stock.AddStocktakeScan(ctx, tx, stock.AddStocktakeScanParams{
+				StocktakeID:  stocktakeID,
+				BatchID:      batch.BatchID,
+				Position:     position,
+				Barcode:      scanResult.Barcode,
+				StockID:      stockModel.ID,
+				SerialNumber: strings.TrimSpace(scan.SerialNumber),
+				Quantity:     quantity,
+				ScannedAt:    scannedAt,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to add stocktake scan: %w", err)
+			}
+			if !added {
+				scanResult.Outcome = enum.StocktakeScanOutcomeDuplicate
+				result.Duplicates++
+				continue
+			}
+
+			scanResult.Outcome = enum.StocktakeScanOutcomeAccepted
+			result.Accepted++
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ListStocktakeCounts 列出盤點地點所有庫存目前累計的盤點數量與系統數量，供盤點期間檢視進度與差異
+func (s *service) ListStocktakeCounts(ctx context.Context, stocktakeID uint64) ([]*models.StocktakeCount, error) {
+	counts, err := s.stock.ListStocktakeCounts(ctx, nil, stocktakeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stocktake counts: %w", err)
+	}
+	return counts, nil
+}
+
+// CompleteStocktake 結束盤點，並為盤點數量與系統數量不一致的庫存建立盤點原因的庫存調整；
+// 未被掃描到的庫存視為盤點數量為 0，超過審核門檻的調整需由其他人員核准後才會入帳
+func (s *service) CompleteStocktake(ctx context.Context, stocktakeID uint64, completedBy string) ([]*models.StockAdjustment, error) {
+	if completedBy == "" {
+		return nil, errors.New("stocktake completer is required")
+	}
+
+	var adjustments []*models.StockAdjustment
+
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		// 1. 標記盤點為已完成
+		if err := s.finishStocktake(ctx, tx, stocktakeID, enum.StocktakeStatusCompleted, completedBy); err != nil {
+			return err
+		}
+
+		// 2. 計算各庫存的盤點差異
+		counts, err := s.stock.ListStocktakeCounts(ctx, tx, stocktakeID)
+		if err != nil {
+			return fmt.Errorf("failed to list stocktake counts: %w", err)
+		}
+
+		// 3. 依差異建立庫存調整
+		note := fmt.Sprintf("stocktake %d", stocktakeID)
+		for _, count := range counts {
+			variance := count.Variance()
+			if variance == 0 {
+				continue
+			}
+
+			adjustment, err := s.requestStockAdjustment(ctx, tx, count.StockID, variance, enum.StockMovementReasonRecount, note, completedBy)
+			if err != nil {
+				return fmt.Errorf("failed to adjust stock %d: %w", count.StockID, err)
+			}
+			adjustments = append(adjustments, adjustment)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return adjustments, nil
+}
+
+// CancelStocktake 取消進行中的盤點，已上傳的掃描不會影響庫存
+func (s *service) CancelStocktake(ctx context.Context, stocktakeID uint64, cancelledBy string) error {
+	if cancelledBy == "" {
+		return errors.New("stocktake canceller is required")
+	}
+
+	return s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		return s.finishStocktake(ctx, tx, stocktakeID, enum.StocktakeStatusCancelled, cancelledBy)
+	})
+}
+
+// openStocktake 取得盤點並確認仍在進行中
+func (s *service) openStocktake(ctx context.Context, tx pgx.Tx, stocktakeID uint64) (*models.Stocktake, error) {
+	stocktake, err := s.stock.GetStocktake(ctx, tx, stocktakeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stocktake: %w", err)
+	}
+	if stocktake.Status != enum.StocktakeStatusOpen {
+		return nil, fmt.Errorf("%w: stocktake %d is %s", ErrStocktakeNotOpen, stocktakeID, stocktake.Status)
+	}
+	return stocktake, nil
+}
+
+func (s *service) finishStocktake(ctx context.Context, tx pgx.Tx, stocktakeID uint64, status enum.StocktakeStatus, finishedBy string) error {
+	if _, err := s.openStocktake(ctx, tx, stocktakeID); err != nil {
+		return err
+	}
+
+	finished, err := s.stock.FinishStocktake(ctx, tx, stocktakeID, status, finishedBy)
+	if err != nil {
+		return fmt.Errorf("failed to finish stocktake: %w", err)
+	}
+	if !finished {
+		return fmt.Errorf("%w: stocktake %d was finished concurrently", ErrStocktakeNotOpen, stocktakeID)
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package shop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"gofalre.io/shop/models"
+)
+
+// maxNearbyStoreRadiusKm 為查詢附近門市的半徑上限（公里）
+const maxNearbyStoreRadiusKm = 500
+
+// ErrStoreNotFound 表示門市不存在
+var ErrStoreNotFound = errors.New("store not found")
+
+// CreateStore 新增門市，門市的地點須與庫存的 location 相同才能查到門市庫存
+func (s *service) CreateStore(ctx context.Context, store *models.Store) error {
+	if err := validateStore(store); err != nil {
+		return err
+	}
+
+	return s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		if err := s.stock.CreateStore(ctx, tx, store); err != nil {
+			return fmt.Errorf("failed to create store: %w", err)
+		}
+		return nil
+	})
+}
+
+// UpdateStore 更新門市的名稱、地點、地址、座標與營業時間
+func (s *service) UpdateStore(ctx context.Context, store *models.Store) error {
+	if err := validateStore(store); err != nil {
+		return err
+	}
+
+	return s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		ok, err := s.stock.UpdateStore(ctx, tx, store)
+		if err != nil {
+			return fmt.Errorf("failed to update store: %w", err)
+		}
+		if !ok {
+			return fmt.Errorf("%w: %d", ErrStoreNotFound, store.ID)
+		}
+		return nil
+	})
+}
+
+// ListStores 依名稱列出所有門市
+func (s *service) ListStores(ctx context.Context) ([]*models.Store, error) {
+	var stores []*models.Store
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		var err error
+		if stores, err = s.stock.ListStores(ctx, tx); err != nil {
+			return fmt.Errorf("failed to list stores: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return stores, nil
+}
+
+// FindNearbyStoresWithStock 列出距離 (lat, lng) radius 公里內、商品有可用庫存的門市，近的排在前面
+func (s *service) FindNearbyStoresWithStock(ctx context.Context, productID string, lat, lng, radius float64) ([]*models.StoreAvailability, error) {
+	if err := validateCoordinates(lat, lng); err != nil {
+		return nil, err
+	}
+	if radius <= 0 || radius > maxNearbyStoreRadiusKm {
+		return nil, fmt.Errorf("radius must be between 0 and %d km", maxNearbyStoreRadiusKm)
+	}
+
+	var result []*models.StoreAvailability
+
+	// 在同一個快照中讀取門市與庫存
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		// 1. 找出範圍內的門市
+		stores, err := s.stock.FindStoresWithinRadius(ctx, tx, lat, lng, radius)
+		if err != nil {
+			return fmt.Errorf("failed to find nearby stores: %w", err)
+		}
+		if len(stores) == 0 {
+			return nil
+		}
+
+		// 2. 取得商品在各地點的庫存，事件溯源的庫存已包含尚未投影的變動
+		stocks, err := s.stock.ListStocksByProductID(ctx, tx, productID)
+		if err != nil {
+			return fmt.Errorf("failed to list stocks: %w", err)
+		}
+		stockByLocation := make(map[string]*models.Stock, len(stocks))
+		for _, stockModel := range stocks {
+			stockByLocation[stockModel.Location] = stockModel
+		}
+
+		// 3. 只保留有可用庫存的門市
+		for _, store := range stores {
+			stockModel, ok := stockByLocation[store.Store.Location]
+			if !ok || stockModel.Quantity <= stockModel.ReservedQuantity {
+				continue
+			}
+			store.StockID = stockModel.ID
+			store.Available = stockModel.Quantity - stockModel.ReservedQuantity
+			result = append(result, store)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func validateStore(store *models.Store) error {
+	store.Name = strings.TrimSpace(store.Name)
+	store.Location = strings.TrimSpace(store.Location)
+	if store.Name == "" {
+		return errors.New("store name is required")
+	}
+	if store.Location == "" {
+		return errors.New("store location is required")
+	}
+	if err := validateCoordinates(store.Latitude, store.Longitude); err != nil {
+		return err
+	}
+
+	for _, hours := range store.Hours {
+		if hours.Weekday < time.Sunday || hours.Weekday > time.Saturday {
+			return fmt.Errorf("invalid store weekday: %d", hours.Weekday)
+		}
+		opens, err := time.Parse("15:04", hours.Opens)
+		if err != nil {
+			return fmt.Errorf("invalid store opening time %q: %w", hours.Opens, err)
+		}
+		closes, err := time.Parse("15:04", hours.Closes)
+		if err != nil {
+			return fmt.Errorf("invalid store closing time %q: %w", hours.Closes, err)
+		}
+		if !closes.After(opens) {
+			return fmt.Errorf("store closing time %s must be after opening time %s on %s", hours.Closes, hours.Opens, hours.Weekday)
+		}
+	}
+
+	return nil
+}
+
+func validateCoordinates(lat, lng float64) error {
+	if lat < -90 || lat > 90 {
+		return fmt.Errorf("invalid latitude: %f", lat)
+	}
+	if lng < -180 || lng > 180 {
+		return fmt.Errorf("invalid longitude: %f", lng)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package shop
+
+import (
+	"math"
+	"strings"
+
+	"github.com/stripe/stripe-go/v79"
+)
+
+// zeroDecimalCurrencies 為 Stripe 以整數單位計價、沒有小數位的幣別
+var zeroDecimalCurrencies = map[stripe.Currency]struct{}{
+	stripe.CurrencyBIF: {},
+	stripe.CurrencyCLP: {},
+	stripe.CurrencyDJF: {},
+	stripe.CurrencyGNF: {},
+	stripe.CurrencyJPY: {},
+	stripe.CurrencyKMF: {},
+	stripe.CurrencyKRW: {},
+	stripe.CurrencyMGA: {},
+	stripe.CurrencyPYG: {},
+	stripe.CurrencyRWF: {},
+	stripe.CurrencyUGX: {},
+	stripe.CurrencyVND: {},
+	stripe.CurrencyVUV: {},
+	stripe.CurrencyXAF: {},
+	stripe.CurrencyXOF: {},
+	stripe.CurrencyXPF: {},
+}
+
+// threeDecimalCurrencies 為 Stripe 以千分之一為最小單位的幣別，金額的最後一位必須為 0
+var threeDecimalCurrencies = map[stripe.Currency]struct{}{
+	"bhd": {},
+	"jod": {},
+	"kwd": {},
+	"omr": {},
+	"tnd": {},
+}
+
+// currencyExponent 回傳幣別在 Stripe 的小數位數，未列出的幣別為 2 位
+func currencyExponent(currency stripe.Currency) int {
+	currency = stripe.Currency(strings.ToLower(string(currency)))
+	if _, ok := zeroDecimalCurrencies[currency]; ok {
+		return 0
+	}
+	if _, ok := threeDecimalCurrencies[currency]; ok {
+		return 3
+	}
+	return 2
+}
+
+// toStripeAmount 依幣別的小數位數將金額轉換為 Stripe 使用的最小貨幣單位
+func toStripeAmount(amount float64, currency stripe.Currency) int64 {
+	exponent := currencyExponent(currency)
+	minor := int64(math.Round(amount * math.Pow10(exponent)))
+	if exponent == 3 {
+		// 三位小數的幣別只接受最後一位為 0 的金額
+		minor = int64(math.Round(float64(minor)/10)) * 10
+	}
+	return minor
+}
+
+// roundCurrencyAmount 依幣別的小數位數四捨五入金額，例如日圓取整數
+func roundCurrencyAmount(amount float64, currency stripe.Currency) float64 {
+	return fromStripeAmount(toStripeAmount(amount, currency), currency)
+}
+
+// fromStripeAmount 依幣別的小數位數將 Stripe 的最小貨幣單位轉換為金額
+func fromStripeAmount(amount int64, currency stripe.Currency) float64 {
+	return float64(amount) / math.Pow10(currencyExponent(currency))
+}
@@ -0,0 +1,44 @@
+package shop
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/stripe/stripe-go/v79"
+	"github.com/stripe/stripe-go/v79/paymentintent"
+	"go.uber.org/zap"
+)
+
+// StripePaymentCanceller 以 Stripe PaymentIntents API 取消尚未完成付款的 payment intent
+type StripePaymentCanceller struct {
+	paymentIntents paymentintent.Client
+
+	logger *zap.Logger
+}
+
+var _ PaymentCanceller = (*StripePaymentCanceller)(nil)
+
+// NewStripePaymentCanceller 建立以 Stripe 取消付款的 PaymentCanceller
+func NewStripePaymentCanceller(apiKey string, logger *zap.Logger) *StripePaymentCanceller {
+	return &StripePaymentCanceller{
+		paymentIntents: paymentintent.Client{B: stripe.GetBackend(stripe.APIBackend), Key: apiKey},
+		logger:         logger,
+	}
+}
+
+// CancelPayment 以 requested_by_customer 為原因取消 payment intent，已完成付款的 payment intent 會回傳錯誤
+func (c *StripePaymentCanceller) CancelPayment(ctx context.Context, req PaymentCancelRequest) error {
+	params := &stripe.PaymentIntentCancelParams{
+		CancellationReason: stripe.String(string(stripe.PaymentIntentCancellationReasonRequestedByCustomer)),
+	}
+	params.SetIdempotencyKey(req.IdempotencyKey)
+
+	if _, err := c.paymentIntents.Cancel(req.PaymentIntentID, params); err != nil {
+		return fmt.Errorf("failed to cancel stripe payment intent: %w", err)
+	}
+
+	LoggerFromContext(ctx, c.logger).Info("Cancelled stripe payment intent",
+		zap.Uint64("order_id", req.OrderID), zap.String("payment_intent_id", req.PaymentIntentID))
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package shop
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/stripe/stripe-go/v79"
+	"github.com/stripe/stripe-go/v79/paymentintent"
+	"go.uber.org/zap"
+)
+
+// StripePaymentAmountUpdater 以 Stripe PaymentIntents API 更新尚未完成付款的 payment intent 金額
+type StripePaymentAmountUpdater struct {
+	paymentIntents paymentintent.Client
+
+	logger *zap.Logger
+}
+
+var _ PaymentAmountUpdater = (*StripePaymentAmountUpdater)(nil)
+
+// NewStripePaymentAmountUpdater 建立以 Stripe 更新付款金額的 PaymentAmountUpdater
+func NewStripePaymentAmountUpdater(apiKey string, logger *zap.Logger) *StripePaymentAmountUpdater {
+	return &StripePaymentAmountUpdater{
+		paymentIntents: paymentintent.Client{B: stripe.GetBackend(stripe.APIBackend), Key: apiKey},
+		logger:         logger,
+	}
+}
+
+// UpdatePaymentAmount 更新 payment intent 的金額與幣別，已完成付款或已取消的 payment intent 會回傳錯誤
+func (u *StripePaymentAmountUpdater) UpdatePaymentAmount(ctx context.Context, req PaymentAmountUpdateRequest) error {
+	params := &stripe.PaymentIntentParams{
+		Amount:   stripe.Int64(toStripeAmount(req.Amount, req.Currency)),
+		Currency: stripe.String(string(req.Currency)),
+	}
+	params.SetIdempotencyKey(req.IdempotencyKey)
+
+	if _, err := u.paymentIntents.Update(req.PaymentIntentID, params); err != nil {
+		return fmt.Errorf("failed to update stripe payment intent: %w", err)
+	}
+
+	LoggerFromContext(ctx, u.logger).Info("Updated stripe payment intent amount",
+		zap.Uint64("order_id", req.OrderID), zap.String("payment_intent_id", req.PaymentIntentID), zap.Float64("amount", req.Amount))
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package shop
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/stripe/stripe-go/v79"
+	"github.com/stripe/stripe-go/v79/billingportal/session"
+	"github.com/stripe/stripe-go/v79/invoice"
+	"github.com/stripe/stripe-go/v79/paymentintent"
+	"go.uber.org/zap"
+
+	"gofalre.io/shop/models"
+)
+
+// StripeReceiptLinker 以 Stripe 的 charge 收據、hosted invoice 與 billing portal 提供訂單的收據連結
+type StripeReceiptLinker struct {
+	paymentIntents paymentintent.Client
+	invoices       invoice.Client
+	portalSessions session.Client
+
+	logger *zap.Logger
+}
+
+var _ ReceiptLinkProvider = (*StripeReceiptLinker)(nil)
+
+// NewStripeReceiptLinker 建立以 Stripe 取得收據連結的 ReceiptLinkProvider
+func NewStripeReceiptLinker(apiKey string, logger *zap.Logger) *StripeReceiptLinker {
+	backend := stripe.GetBackend(stripe.APIBackend)
+	return &StripeReceiptLinker{
+		paymentIntents: paymentintent.Client{B: backend, Key: apiKey},
+		invoices:       invoice.Client{B: backend, Key: apiKey},
+		portalSessions: session.Client{B: backend, Key: apiKey},
+		logger:         logger,
+	}
+}
+
+// ReceiptLinks 取得 payment intent 最近一筆 charge 的收據網址，以及 invoice 的 hosted invoice 與 PDF 網址
+func (l *StripeReceiptLinker) ReceiptLinks(ctx context.Context, ref *models.OrderPaymentReference) (*models.OrderReceiptLinks, error) {
+	links := &models.OrderReceiptLinks{}
+
+	if ref.PaymentIntentID != "" {
+		params := &stripe.PaymentIntentParams{}
+		params.AddExpand("latest_charge")
+		paymentIntent, err := l.paymentIntents.Get(ref.PaymentIntentID, params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get stripe payment intent: %w", err)
+		}
+		if paymentIntent.LatestCharge != nil {
+			links.ReceiptURL = paymentIntent.LatestCharge.ReceiptURL
+		}
+	}
+
+	if ref.InvoiceID != "" {
+		stripeInvoice, err := l.invoices.Get(ref.InvoiceID, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get stripe invoice: %w", err)
+		}
+		links.InvoiceURL = stripeInvoice.HostedInvoiceURL
+		links.InvoicePDFURL = stripeInvoice.InvoicePDF
+	}
+
+	LoggerFromContext(ctx, l.logger).Debug("Fetched stripe receipt links",
+		zap.Uint64("order_id", ref.OrderID), zap.String("payment_intent_id", ref.PaymentIntentID), zap.String("invoice_id", ref.InvoiceID))
+
+	return links, nil
+}
+
+// BillingPortalURL 建立 Stripe billing portal session，returnURL 為空時使用入口設定的預設網址
+func (l *StripeReceiptLinker) BillingPortalURL(ctx context.Context, customerID, returnURL string) (string, error) {
+	params := &stripe.BillingPortalSessionParams{
+		Customer: stripe.String(customerID),
+	}
+	if returnURL != "" {
+		params.ReturnURL = stripe.String(returnURL)
+	}
+
+	portalSession, err := l.portalSessions.New(params)
+	if err != nil {
+		return "", fmt.Errorf("failed to create stripe billing portal session: %w", err)
+	}
+
+	LoggerFromContext(ctx, l.logger).Info("Created stripe billing portal session", zap.String("customer_id", customerID))
+
+	return portalSession.URL, nil
+}
@@ -0,0 +1,51 @@
+package shop
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/stripe/stripe-go/v79"
+	"github.com/stripe/stripe-go/v79/refund"
+	"go.uber.org/zap"
+)
+
+// StripeRefunder 以 Stripe Refunds API 退還 payment intent 的款項，退款結果由 refund 事件更新訂單狀態
+type StripeRefunder struct {
+	refunds refund.Client
+
+	logger *zap.Logger
+}
+
+var _ PaymentRefunder = (*StripeRefunder)(nil)
+
+// NewStripeRefunder 建立以 Stripe 發起退款的 PaymentRefunder
+func NewStripeRefunder(apiKey string, logger *zap.Logger) *StripeRefunder {
+	return &StripeRefunder{
+		refunds: refund.Client{B: stripe.GetBackend(stripe.APIBackend), Key: apiKey},
+		logger:  logger,
+	}
+}
+
+// RefundPayment 建立 Stripe refund，退款原因與訂單 ID 記錄在 metadata
+func (r *StripeRefunder) RefundPayment(ctx context.Context, req PaymentRefundRequest) (string, error) {
+	params := &stripe.RefundParams{
+		PaymentIntent: stripe.String(req.PaymentIntentID),
+		Amount:        stripe.Int64(toStripeAmount(req.Amount, req.Currency)),
+	}
+	params.SetIdempotencyKey(req.IdempotencyKey)
+	params.AddMetadata("order_id", strconv.FormatUint(req.OrderID, 10))
+	if req.Reason != "" {
+		params.AddMetadata("reason", req.Reason)
+	}
+
+	stripeRefund, err := r.refunds.New(params)
+	if err != nil {
+		return "", fmt.Errorf("failed to create stripe refund: %w", err)
+	}
+
+	LoggerFromContext(ctx, r.logger).Info("Created stripe refund",
+		zap.Uint64("order_id", req.OrderID), zap.String("refund_id", stripeRefund.ID), zap.Float64("amount", req.Amount))
+
+	return stripeRefund.ID, nil
+}
@@ -0,0 +1,121 @@
+package shop
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/stripe/stripe-go/v79"
+	"github.com/stripe/stripe-go/v79/tax/calculation"
+	"github.com/stripe/stripe-go/v79/tax/transaction"
+	"go.uber.org/zap"
+)
+
+// stripeNontaxableTaxCode 為 Stripe Tax 的免稅商品稅碼，用於客戶免稅證明涵蓋的項目
+const stripeNontaxableTaxCode = "txcd_00000000"
+
+// StripeTaxCalculator 以 Stripe Tax 計算稅額：結帳時建立 tax calculation，付款成功後以該計算建立 tax transaction，
+// 稅務申報所需的記錄由 Stripe 保存。客戶地址取自 Stripe customer，購物車預覽沒有地址，TaxRate 使用預估稅率
+type StripeTaxCalculator struct {
+	calculations calculation.Client
+	transactions transaction.Client
+	estimate     TaxCalculator
+
+	logger *zap.Logger
+}
+
+var _ OrderTaxCalculator = (*StripeTaxCalculator)(nil)
+
+// NewStripeTaxCalculator 建立 Stripe Tax 的稅率來源，estimateRate 為購物車預覽使用的預估稅率
+func NewStripeTaxCalculator(apiKey string, estimateRate float64, logger *zap.Logger) *StripeTaxCalculator {
+	backend := stripe.GetBackend(stripe.APIBackend)
+
+	return &StripeTaxCalculator{
+		calculations: calculation.Client{B: backend, Key: apiKey},
+		transactions: transaction.Client{B: backend, Key: apiKey},
+		estimate:     flatTaxCalculator(estimateRate),
+		logger:       logger,
+	}
+}
+
+// TaxRate 回傳購物車預覽使用的預估稅率
+func (c *StripeTaxCalculator) TaxRate(ctx context.Context, productID, taxClass string) (float64, error) {
+	return c.estimate.TaxRate(ctx, productID, taxClass)
+}
+
+// CalculateOrderTax 建立 Stripe tax calculation，taxClass 視為 Stripe 的 tax code（例如 txcd_99999999），為空時使用帳戶預設值
+func (c *StripeTaxCalculator) CalculateOrderTax(ctx context.Context, req OrderTaxRequest) (*OrderTax, error) {
+	params := &stripe.TaxCalculationParams{
+		Currency: stripe.String(string(req.Currency)),
+		Customer: stripe.String(req.CustomerID),
+	}
+	for _, line := range req.Lines {
+		lineParams := &stripe.TaxCalculationLineItemParams{
+			Amount:    stripe.Int64(toStripeAmount(line.Amount, req.Currency)),
+			Quantity:  stripe.Int64(int64(line.Quantity)),
+			Reference: stripe.String(line.Reference),
+		}
+		switch {
+		case line.Exempt:
+			lineParams.TaxCode = stripe.String(stripeNontaxableTaxCode)
+		case line.TaxClass != "":
+			lineParams.TaxCode = stripe.String(line.TaxClass)
+		}
+		params.LineItems = append(params.LineItems, lineParams)
+	}
+
+	calc, err := c.calculations.New(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stripe tax calculation: %w", err)
+	}
+
+	// 逐頁讀取項目的稅額，項目多時 calculation 本身只會帶回第一頁
+	taxByReference := make(map[string]int64, len(req.Lines))
+	iter := c.calculations.ListLineItems(&stripe.TaxCalculationListLineItemsParams{
+		Calculation: stripe.String(calc.ID),
+	})
+	for iter.Next() {
+		item := iter.TaxCalculationLineItem()
+		taxByReference[item.Reference] = item.AmountTax
+	}
+	if err = iter.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list stripe tax calculation %s line items: %w", calc.ID, err)
+	}
+
+	result := &OrderTax{
+		CalculationID: calc.ID,
+		Tax:           fromStripeAmount(calc.TaxAmountExclusive, calc.Currency),
+		LineTax:       make([]float64, len(req.Lines)),
+	}
+	for i, line := range req.Lines {
+		amountTax, ok := taxByReference[line.Reference]
+		if !ok {
+			return nil, fmt.Errorf("stripe tax calculation %s is missing line %s", calc.ID, line.Reference)
+		}
+		result.LineTax[i] = fromStripeAmount(amountTax, calc.Currency)
+	}
+
+	LoggerFromContext(ctx, c.logger).Info("Created stripe tax calculation",
+		zap.Uint64("cart_id", req.CartID), zap.String("tax_calculation_id", calc.ID), zap.Float64("tax", result.Tax))
+
+	return result, nil
+}
+
+// CommitOrderTax 以 tax calculation 建立 tax transaction，reference 使用訂單 ID；
+// idempotency key 也由訂單 ID 產生，同一筆訂單重複提交時 Stripe 回傳第一次建立的 tax transaction
+func (c *StripeTaxCalculator) CommitOrderTax(ctx context.Context, calculationID string, orderID uint64) (string, error) {
+	params := &stripe.TaxTransactionCreateFromCalculationParams{
+		Calculation: stripe.String(calculationID),
+		Reference:   stripe.String(fmt.Sprintf("order-%d", orderID)),
+	}
+	params.SetIdempotencyKey(fmt.Sprintf("order-%d-tax-transaction", orderID))
+
+	taxTransaction, err := c.transactions.CreateFromCalculation(params)
+	if err != nil {
+		return "", fmt.Errorf("failed to create stripe tax transaction: %w", err)
+	}
+
+	LoggerFromContext(ctx, c.logger).Info("Created stripe tax transaction",
+		zap.Uint64("order_id", orderID), zap.String("tax_transaction_id", taxTransaction.ID))
+
+	return taxTransaction.ID, nil
+}
@@ -0,0 +1,297 @@
+package shop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stripe/stripe-go/v79"
+	"go.uber.org/zap"
+
+	"gofalre.io/shop/models"
+	"gofalre.io/shop/models/enum"
+)
+
+// defaultTaxRate 為未設定 TaxCalculator 時使用的稅率
+const defaultTaxRate = 0.1
+
+// ErrOrderTaxQuoteStale 表示在交易外計算稅額後購物車內容或適用的優惠已變更，重新結帳即可
+var ErrOrderTaxQuoteStale = errors.New("cart changed while calculating order tax")
+
+// TaxCalculator 提供商品適用的稅率（0.05 表示 5%），taxClass 可能為空字串，稅額由 service 依折扣後金額逐項計算
+type TaxCalculator interface {
+	TaxRate(ctx context.Context, productID, taxClass string) (float64, error)
+}
+
+// OrderTaxCalculator 為 TaxCalculator 的選用擴充，同時實作時結帳改以整筆訂單向外部稅務服務（如 Stripe Tax）計算稅額，
+// 付款成功後再以計算結果建立稅務交易；購物車預覽仍使用 TaxRate 估算
+type OrderTaxCalculator interface {
+	TaxCalculator
+	CalculateOrderTax(ctx context.Context, req OrderTaxRequest) (*OrderTax, error)
+	CommitOrderTax(ctx context.Context, calculationID string, orderID uint64) (string, error)
+}
+
+// OrderTaxRequest 描述一筆待計算稅額的訂單；結帳時在建立訂單前計算，OrderID 為 0，CartID 為結帳的購物車
+type OrderTaxRequest struct {
+	OrderID    uint64
+	CartID     uint64
+	CustomerID string
+	Currency   stripe.Currency
+	Lines      []TaxLine
+}
+
+// TaxLine 為訂單中的單一項目，Amount 為扣除折扣後的金額，Reference 在同一筆訂單內不可重複；
+// Exempt 為 true 表示客戶的免稅證明適用此項目，稅額必須為 0
+type TaxLine struct {
+	Reference string
+	ProductID string
+	TaxClass  string
+	Quantity  uint64
+	Amount    float64
+	Exempt    bool
+}
+
+// OrderTax 為外部稅務服務的計算結果，LineTax 與 OrderTaxRequest.Lines 的順序相同
+type OrderTax struct {
+	CalculationID string
+	Tax           float64
+	LineTax       []float64
+}
+
+// flatTaxCalculator 對所有商品使用同一個稅率
+type flatTaxCalculator float64
+
+func (rate flatTaxCalculator) TaxRate(context.Context, string, string) (float64, error) {
+	return float64(rate), nil
+}
+
+// WithTaxCalculator 設定計算購物車與訂單項目稅額的稅率來源
+func WithTaxCalculator(calculator TaxCalculator) Option {
+	return func(s *service) {
+		if calculator != nil {
+			s.taxCalculator = calculator
+		}
+	}
+}
+
+// lineTax 計算單一項目的稅率與稅額，taxable 為扣除折扣後的金額，稅額依幣別的小數位數四捨五入；
+// exemption 為客戶適用的免稅證明，證明涵蓋項目的稅別時以零稅率計算
+func (s *service) lineTax(ctx context.Context, exemption *models.TaxExemptionCertificate, productID, taxClass string, taxable float64, currency stripe.Currency) (float64, float64, error) {
+	if exemption != nil && exemption.Covers(taxClass) {
+		return 0, 0, nil
+	}
+
+	calculator := s.taxCalculator
+	if !s.flagBool(ctx, FlagNewTaxEngine, true) {
+		calculator = flatTaxCalculator(defaultTaxRate)
+	}
+
+	rate, err := calculator.TaxRate(ctx, productID, taxClass)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get tax rate for product %s: %w", productID, err)
+	}
+
+	return rate, roundCurrencyAmount(taxable*rate, currency), nil
+}
+
+// orderTaxCalculator 回傳支援整筆訂單計算的稅率來源，未設定或新稅務引擎關閉時回傳 nil
+func (s *service) orderTaxCalculator(ctx context.Context) OrderTaxCalculator {
+	calculator, ok := s.taxCalculator.(OrderTaxCalculator)
+	if !ok || !s.flagBool(ctx, FlagNewTaxEngine, true) {
+		return nil
+	}
+	return calculator
+}
+
+// orderTaxQuote 為在交易外向外部稅務服務計算的稅額，request 為計算時的訂單內容
+type orderTaxQuote struct {
+	request OrderTaxRequest
+	result  *OrderTax
+}
+
+// quoteOrderTax 在交易外以購物車目前的內容向外部稅務服務計算稅額，避免資料庫交易等待外部服務；
+// 未使用外部稅務服務，或購物車已非 active、沒有項目時回傳 nil，由結帳交易回報錯誤
+func (s *service) quoteOrderTax(ctx context.Context, cartID uint64) (*orderTaxQuote, error) {
+	calculator := s.orderTaxCalculator(ctx)
+	if calculator == nil {
+		return nil, nil
+	}
+
+	// 1. 讀取購物車並以目前有效的優惠計算金額
+	var cartModel *models.Cart
+	var items []*models.OrderItem
+	var pricing *cartPricing
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		var err error
+		if cartModel, err = s.cart.GetCart(ctx, tx, cartID); err != nil {
+			return fmt.Errorf("failed to get cart: %w", err)
+		}
+		if cartModel.Status != enum.CartStatusActive {
+			return nil
+		}
+
+		cartItems, err := s.cart.ListCartItems(ctx, tx, cartID)
+		if err != nil {
+			return fmt.Errorf("failed to list cart items: %w", err)
+		}
+		if len(cartItems) == 0 {
+			return nil
+		}
+		if pricing, err = s.priceCartItems(ctx, tx, cartModel, cartItems, time.Now()); err != nil {
+			return err
+		}
+
+		items = make([]*models.OrderItem, len(cartItems))
+		for i, item := range cartItems {
+			items[i] = &models.OrderItem{
+				ProductID: item.ProductID,
+				PriceID:   item.PriceID,
+				Quantity:  item.Quantity,
+				Subtotal:  item.Subtotal,
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	if pricing == nil {
+		return nil, nil
+	}
+
+	// 2. 以商品目錄的稅別向外部稅務服務計算，與建立訂單項目時的快照相同
+	for _, item := range items {
+		s.snapshotOrderItem(ctx, item)
+	}
+	req := orderTaxRequest(cartModel.CustomerID, cartModel.Currency, items, pricing.LineDiscounts, pricing.Exemption)
+	req.CartID = cartID
+
+	result, err := calculator.CalculateOrderTax(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate order tax: %w", err)
+	}
+	if len(result.LineTax) != len(items) {
+		return nil, fmt.Errorf("tax calculation %s returned %d lines, expected %d", result.CalculationID, len(result.LineTax), len(items))
+	}
+
+	return &orderTaxQuote{request: req, result: result}, nil
+}
+
+// applyOrderTax 將交易外計算的稅額寫回各項目與訂單並記錄計算 ID；discounts 為各項目的活動折扣，順序與 items 相同，
+// exemption 為客戶適用的免稅證明，沒有時為 nil。quote 為 nil 時不使用外部稅務服務；
+// 訂單內容與計算時不同時回傳包裝後的 ErrOrderTaxQuoteStale
+func (s *service) applyOrderTax(ctx context.Context, tx pgx.Tx, order *models.Order, items []*models.OrderItem, discounts []float64, exemption *models.TaxExemptionCertificate, quote *orderTaxQuote) error {
+	if quote == nil {
+		return nil
+	}
+
+	req := orderTaxRequest(order.CustomerID, order.Currency, items, discounts, exemption)
+	if req.Currency != quote.request.Currency || !slices.Equal(req.Lines, quote.request.Lines) {
+		return fmt.Errorf("%w: tax calculation %s", ErrOrderTaxQuoteStale, quote.result.CalculationID)
+	}
+
+	result := quote.result
+	for i, item := range items {
+		item.TaxAmount = result.LineTax[i]
+		item.TaxRate = 0
+		if req.Lines[i].Amount > 0 {
+			item.TaxRate = math.Round(item.TaxAmount/req.Lines[i].Amount*10000) / 10000
+		}
+	}
+
+	if err := s.order.SetOrderTaxCalculation(ctx, tx, order.ID, result.Tax, result.CalculationID); err != nil {
+		return fmt.Errorf("failed to set order tax calculation: %w", err)
+	}
+	order.Tax = result.Tax
+	order.Total = order.Subtotal + order.Tax - order.Discount
+	order.TaxCalculationID = result.CalculationID
+
+	return nil
+}
+
+// orderTaxRequest 以訂單項目建立外部稅務服務的計算內容，項目的 Reference 依順序編號
+func orderTaxRequest(customerID string, currency stripe.Currency, items []*models.OrderItem, discounts []float64, exemption *models.TaxExemptionCertificate) OrderTaxRequest {
+	req := OrderTaxRequest{
+		CustomerID: customerID,
+		Currency:   currency,
+		Lines:      make([]TaxLine, len(items)),
+	}
+	for i, item := range items {
+		req.Lines[i] = TaxLine{
+			Reference: fmt.Sprintf("line-%d", i+1),
+			ProductID: item.ProductID,
+			TaxClass:  item.TaxClass,
+			Quantity:  item.Quantity,
+			Amount:    roundCurrencyAmount(item.Subtotal-discounts[i], currency),
+			Exempt:    exemption != nil && exemption.Covers(item.TaxClass),
+		}
+	}
+	return req
+}
+
+// commitOrderTax 在訂單付款成功的交易 commit 後建立稅務交易，外部稅務服務不在資料庫交易內呼叫；
+// 失敗時只記錄警告，不影響付款流程，tax_transaction_id 保持空值，由 CommitPendingOrderTaxes 補送。
+// 同一筆訂單重複提交時（例如 payment_intent.succeeded 與 checkout.session.completed 都送達），
+// OrderTaxCalculator 以訂單 ID 確保只建立一筆稅務交易
+func (s *service) commitOrderTax(ctx context.Context, tx pgx.Tx, order *models.Order) {
+	calculator := s.orderTaxCalculator(ctx)
+	if calculator == nil || order.TaxCalculationID == "" || order.TaxTransactionID != "" {
+		return
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	orderID, calculationID := order.ID, order.TaxCalculationID
+	s.transactionManager.AfterCommit(tx, fmt.Sprintf("order_tax:%d", orderID), func() {
+		if err := s.commitOrderTaxTransaction(ctx, calculator, orderID, calculationID); err != nil {
+			s.log(ctx).Warn("Failed to commit order tax transaction",
+				zap.Uint64("order_id", orderID), zap.String("tax_calculation_id", calculationID), zap.Error(err))
+		}
+	})
+}
+
+// pendingOrderTaxCommitDelay 訂單最後更新超過此時間仍沒有稅務交易才補送，避免與付款後的提交同時執行
+const pendingOrderTaxCommitDelay = 10 * time.Minute
+
+// pendingOrderTaxCommitBatchSize 每次補送的最大訂單數
+const pendingOrderTaxCommitBatchSize = 100
+
+// CommitPendingOrderTaxes 為已付款但稅務交易建立失敗的訂單補送稅務交易，應定期執行；回傳補送成功的訂單數
+func (s *service) CommitPendingOrderTaxes(ctx context.Context) (int, error) {
+	calculator := s.orderTaxCalculator(ctx)
+	if calculator == nil {
+		return 0, nil
+	}
+
+	calculations, err := s.order.ListOrdersPendingTaxCommit(ctx, nil, time.Now().Add(-pendingOrderTaxCommitDelay), pendingOrderTaxCommitBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list orders pending tax commit: %w", err)
+	}
+
+	committed := 0
+	for orderID, calculationID := range calculations {
+		if err = s.commitOrderTaxTransaction(ctx, calculator, orderID, calculationID); err != nil {
+			s.log(ctx).Error("Failed to commit pending order tax transaction",
+				zap.Uint64("order_id", orderID), zap.String("tax_calculation_id", calculationID), zap.Error(err))
+			continue
+		}
+		committed++
+	}
+
+	return committed, nil
+}
+
+// commitOrderTaxTransaction 以稅額計算建立稅務交易並記錄到訂單
+func (s *service) commitOrderTaxTransaction(ctx context.Context, calculator OrderTaxCalculator, orderID uint64, calculationID string) error {
+	transactionID, err := calculator.CommitOrderTax(ctx, calculationID, orderID)
+	if err != nil {
+		return fmt.Errorf("failed to commit order tax: %w", err)
+	}
+
+	if _, err = s.order.SetOrderTaxTransaction(ctx, nil, orderID, transactionID); err != nil {
+		return fmt.Errorf("failed to record order tax transaction %s: %w", transactionID, err)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package shop
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"gofalre.io/shop/models"
+)
+
+// ErrTaxExemptionCertificateNotFound 表示免稅證明不存在、不屬於該客戶或已撤銷
+var ErrTaxExemptionCertificateNotFound = errors.New("tax exemption certificate not found")
+
+// AddTaxExemptionCertificate 登記客戶的免稅證明，ValidFrom 為零值時自即刻生效；
+// 同一個轄區已有相同編號的證明時回傳 order.ErrTaxExemptionCertificateExists
+func (s *service) AddTaxExemptionCertificate(ctx context.Context, certificate *models.TaxExemptionCertificate) (*models.TaxExemptionCertificate, error) {
+	// 1. 驗證證明內容
+	if certificate.CustomerID == "" {
+		return nil, errors.New("customer ID is required")
+	}
+	if certificate.CertificateNumber == "" {
+		return nil, errors.New("certificate number is required")
+	}
+	jurisdiction := strings.ToUpper(strings.TrimSpace(certificate.Jurisdiction))
+	if len(jurisdiction) != 2 {
+		return nil, fmt.Errorf("invalid jurisdiction %q: expected a two-letter country code", certificate.Jurisdiction)
+	}
+	validFrom := certificate.ValidFrom
+	if validFrom.IsZero() {
+		validFrom = time.Now()
+	}
+	if certificate.ValidUntil != nil && !certificate.ValidUntil.After(validFrom) {
+		return nil, errors.New("certificate must expire after it becomes valid")
+	}
+
+	// 2. 新增證明
+	created, err := s.order.CreateTaxExemptionCertificate(ctx, nil, &models.TaxExemptionCertificate{
+		CustomerID:        certificate.CustomerID,
+		CertificateNumber: certificate.CertificateNumber,
+		Jurisdiction:      jurisdiction,
+		TaxClasses:        certificate.TaxClasses,
+		ValidFrom:         validFrom,
+		ValidUntil:        certificate.ValidUntil,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tax exemption certificate: %w", err)
+	}
+
+	return created, nil
+}
+
+// ListTaxExemptionCertificates 列出客戶登記過的免稅證明，包含已到期與已撤銷的證明
+func (s *service) ListTaxExemptionCertificates(ctx context.Context, customerID string) ([]*models.TaxExemptionCertificate, error) {
+	certificates, err := s.order.ListTaxExemptionCertificates(ctx, nil, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tax exemption certificates: %w", err)
+	}
+	return certificates, nil
+}
+
+// RevokeTaxExemptionCertificate 撤銷客戶的免稅證明，之後的購物車與訂單不再免稅；已成立的訂單與發票不受影響
+func (s *service) RevokeTaxExemptionCertificate(ctx context.Context, customerID string, certificateID uint64) error {
+	revoked, err := s.order.RevokeTaxExemptionCertificate(ctx, nil, customerID, certificateID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke tax exemption certificate: %w", err)
+	}
+	if !revoked {
+		return ErrTaxExemptionCertificateNotFound
+	}
+	return nil
+}
+
+// taxExemption 取得客戶在寄送地的稅務轄區目前有效的免稅證明，沒有寄送地址時以帳單地址的國家為轄區；
+// 沒有客戶、地址或有效證明時回傳 nil
+func (s *service) taxExemption(ctx context.Context, tx pgx.Tx, customerID string, shippingAddress, billingAddress json.RawMessage) (*models.TaxExemptionCertificate, error) {
+	if customerID == "" {
+		return nil, nil
+	}
+
+	for _, address := range []json.RawMessage{shippingAddress, billingAddress} {
+		destination, ok := models.ParseDeliveryDestination(address)
+		if !ok {
+			continue
+		}
+
+		certificate, err := s.order.GetActiveTaxExemptionCertificate(ctx, tx, customerID, destination.Country, time.Now())
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tax exemption certificate: %w", err)
+		}
+		return certificate, nil
+	}
+
+	return nil, nil
+}
+
+// recordOrderTaxExemption 記錄訂單套用的免稅證明，供開立發票時註記證明編號
+func (s *service) recordOrderTaxExemption(ctx context.Context, tx pgx.Tx, orderID uint64, exemption *models.TaxExemptionCertificate) error {
+	if exemption == nil {
+		return nil
+	}
+	if err := s.order.SetOrderTaxExemption(ctx, tx, orderID, exemption); err != nil {
+		return fmt.Errorf("failed to set order tax exemption: %w", err)
+	}
+	return nil
+}
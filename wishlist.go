@@ -0,0 +1,182 @@
+package shop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"gofalre.io/shop/models"
+	"gofalre.io/shop/wishlist"
+)
+
+// ErrWishlistNotConfigured 表示未設定 wishlist.Repository，無法使用稍後購買清單
+var ErrWishlistNotConfigured = errors.New("wishlist repository is not configured")
+
+// ErrSavedItemNotFound 表示稍後購買清單中沒有該商品，或商品不屬於該客戶
+var ErrSavedItemNotFound = errors.New("saved item not found")
+
+// ErrSavedItemUnavailable 表示稍後購買的商品目前庫存不足，無法移回購物車；商品仍保留在清單中
+var ErrSavedItemUnavailable = errors.New("saved item is not available")
+
+// WithWishlistRepository 設定稍後購買清單使用的 repository
+func WithWishlistRepository(repo wishlist.Repository) Option {
+	return func(s *service) {
+		if repo != nil {
+			s.wishlist = repo
+		}
+	}
+}
+
+// SaveCartItemForLater 將購物車項目移到客戶的稍後購買清單，並釋放項目預留的庫存；
+// 清單中已有相同商品（沒有客製化且庫存與價格相同）時合併數量
+func (s *service) SaveCartItemForLater(ctx context.Context, cartID, itemID uint64) (*models.SavedItem, error) {
+	if s.wishlist == nil {
+		return nil, ErrWishlistNotConfigured
+	}
+
+	var saved *models.SavedItem
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		// 1. 獲取購物車
+		cartModel, err := s.cart.GetCart(ctx, tx, cartID)
+		if err != nil {
+			return fmt.Errorf("failed to get cart: %w", err)
+		}
+
+		// 2. 移除購物車項目並釋放預留庫存
+		item, err := s.removeCartItem(ctx, tx, cartID, itemID)
+		if err != nil {
+			return err
+		}
+
+		// 3. 加入稍後購買清單
+		saved = &models.SavedItem{
+			CustomerID:    cartModel.CustomerID,
+			ProductID:     item.ProductID,
+			PriceID:       item.PriceID,
+			StockID:       item.StockID,
+			Quantity:      item.Quantity,
+			UnitPrice:     item.UnitPrice,
+			Currency:      cartModel.Currency,
+			Location:      item.Location,
+			Customization: item.Customization,
+		}
+		if err = s.wishlist.SaveItem(ctx, tx, saved); err != nil {
+			return fmt.Errorf("failed to save item: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return saved, nil
+}
+
+// ListSavedItems 依加入時間由新到舊列出客戶的稍後購買清單
+func (s *service) ListSavedItems(ctx context.Context, customerID string) ([]*models.SavedItem, error) {
+	if s.wishlist == nil {
+		return nil, ErrWishlistNotConfigured
+	}
+
+	items, err := s.wishlist.ListSavedItems(ctx, nil, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list saved items: %w", err)
+	}
+	return items, nil
+}
+
+// RemoveSavedItem 從客戶的稍後購買清單移除商品
+func (s *service) RemoveSavedItem(ctx context.Context, customerID string, savedItemID uint64) error {
+	if s.wishlist == nil {
+		return ErrWishlistNotConfigured
+	}
+
+	deleted, err := s.wishlist.DeleteSavedItem(ctx, nil, customerID, savedItemID)
+	if err != nil {
+		return fmt.Errorf("failed to delete saved item: %w", err)
+	}
+	if !deleted {
+		return ErrSavedItemNotFound
+	}
+	return nil
+}
+
+// MoveSavedItemToCart 將稍後購買的商品移回客戶的 active 購物車並預留庫存，價格以目前售價為準；
+// 原庫存不足時回傳 ErrSavedItemUnavailable，商品保留在清單中。回傳商品加入的購物車 ID
+func (s *service) MoveSavedItemToCart(ctx context.Context, customerID string, savedItemID uint64) (uint64, error) {
+	if s.wishlist == nil {
+		return 0, ErrWishlistNotConfigured
+	}
+	if err := s.enforceQuota(ctx); err != nil {
+		return 0, err
+	}
+
+	var cartID uint64
+	if err := s.transactionManager.ExecuteTransaction(ctx, func(tx pgx.Tx) error {
+		// 1. 獲取稍後購買的商品
+		saved, err := s.wishlist.GetSavedItem(ctx, tx, savedItemID)
+		if errors.Is(err, pgx.ErrNoRows) || (err == nil && saved.CustomerID != customerID) {
+			return ErrSavedItemNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get saved item: %w", err)
+		}
+
+		// 2. 取得或建立 active 購物車，幣別必須與商品存入時相同
+		cartModel, err := s.getOrCreateActiveCart(ctx, tx, customerID, saved.Currency)
+		if err != nil {
+			return err
+		}
+		if cartModel.Currency != saved.Currency {
+			return fmt.Errorf("saved item currency %s does not match cart currency %s", saved.Currency, cartModel.Currency)
+		}
+		cartID = cartModel.ID
+
+		// 3. 重新檢查庫存，存入清單期間可能已售出
+		stockModel, err := s.stock.GetStock(ctx, tx, saved.StockID)
+		if err != nil {
+			return fmt.Errorf("failed to get stock: %w", err)
+		}
+		if available := availableQuantity(stockModel); available < saved.Quantity {
+			return fmt.Errorf("%w: product %s has %d available at location %q, %d requested",
+				ErrSavedItemUnavailable, saved.ProductID, available, stockModel.Location, saved.Quantity)
+		}
+
+		// 4. 目前售價，沒有價格記錄時沿用存入時的售價
+		unitPrice := saved.UnitPrice
+		current, err := s.price.GetPriceInEffect(ctx, tx, saved.PriceID, time.Now())
+		switch {
+		case err == nil:
+			unitPrice = current.UnitPrice
+		case !errors.Is(err, pgx.ErrNoRows):
+			return fmt.Errorf("failed to get current price for %s: %w", saved.PriceID, err)
+		}
+
+		// 5. 加入購物車並預留庫存
+		if err = s.addItemsToCart(ctx, tx, cartID, []*models.CartItem{{
+			CartID:    cartID,
+			ProductID: saved.ProductID,
+			PriceID:   saved.PriceID,
+			StockID:   saved.StockID,
+			Quantity:  saved.Quantity,
+			UnitPrice: unitPrice,
+			Subtotal:  float64(saved.Quantity) * unitPrice,
+
+			Customization: saved.Customization,
+		}}); err != nil {
+			return err
+		}
+
+		// 6. 從稍後購買清單移除
+		if _, err = s.wishlist.DeleteSavedItem(ctx, tx, customerID, savedItemID); err != nil {
+			return fmt.Errorf("failed to delete saved item: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+
+	return cartID, nil
+}
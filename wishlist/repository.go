@@ -0,0 +1,109 @@
+package wishlist
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+
+	"gofalre.io/shop/driver"
+	"gofalre.io/shop/models"
+	"gofalre.io/shop/sqlc"
+)
+
+type Repository interface {
+	SaveItem(ctx context.Context, tx pgx.Tx, item *models.SavedItem) error
+	GetSavedItem(ctx context.Context, tx pgx.Tx, id uint64) (*models.SavedItem, error)
+	ListSavedItems(ctx context.Context, tx pgx.Tx, customerID string) ([]*models.SavedItem, error)
+	DeleteSavedItem(ctx context.Context, tx pgx.Tx, customerID string, id uint64) (bool, error)
+}
+
+type repository struct {
+	queries *driver.Queries
+	logger  *zap.Logger
+}
+
+func NewRepository(conn driver.PostgresPool, logger *zap.Logger) Repository {
+	return NewRepositoryWithQuerier(sqlc.New(conn), logger)
+}
+
+// NewRepositoryWithQuerier 以注入的 sqlc.Querier 建立 repository，交易中的查詢見 driver.TxBinder
+func NewRepositoryWithQuerier(querier sqlc.Querier, logger *zap.Logger) Repository {
+	return &repository{
+		queries: driver.NewQueries(querier),
+		logger:  logger,
+	}
+}
+
+var _ Repository = (*repository)(nil)
+
+// SaveItem 將商品加入客戶的稍後購買清單，並寫回 ID 與時間；
+// 沒有客製化且清單中已有相同庫存與價格的商品時合併數量，並以 item 的售價更新
+func (r *repository) SaveItem(ctx context.Context, tx pgx.Tx, item *models.SavedItem) error {
+	params := sqlc.SaveItemParams{
+		CustomerID:    item.CustomerID,
+		ProductID:     item.ProductID,
+		PriceID:       item.PriceID,
+		StockID:       item.StockID,
+		Quantity:      item.Quantity,
+		UnitPrice:     item.UnitPrice,
+		Currency:      sqlc.Currency(item.Currency),
+		Customization: item.Customization,
+	}
+	if item.Location != "" {
+		params.Location = &item.Location
+	}
+
+	row, err := r.queries.WithTx(tx).SaveItem(ctx, params)
+	if err != nil {
+		r.logger.Error("Failed to save item", zap.String("customer_id", item.CustomerID), zap.String("product_id", item.ProductID), zap.Error(err))
+		return err
+	}
+
+	item.ConvertSqlcSavedItem(row)
+	return nil
+}
+
+// GetSavedItem 取得稍後購買清單中的商品，不存在時回傳 pgx.ErrNoRows
+func (r *repository) GetSavedItem(ctx context.Context, tx pgx.Tx, id uint64) (*models.SavedItem, error) {
+	row, err := r.queries.WithTx(tx).GetSavedItem(ctx, int32(id))
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			r.logger.Error("Failed to get saved item", zap.Uint64("saved_item_id", id), zap.Error(err))
+		}
+		return nil, err
+	}
+
+	return new(models.SavedItem).ConvertSqlcSavedItem(row), nil
+}
+
+// ListSavedItems 依加入時間由新到舊列出客戶的稍後購買清單
+func (r *repository) ListSavedItems(ctx context.Context, tx pgx.Tx, customerID string) ([]*models.SavedItem, error) {
+	rows, err := r.queries.WithTx(tx).ListSavedItems(ctx, customerID)
+	if err != nil {
+		r.logger.Error("Failed to list saved items", zap.String("customer_id", customerID), zap.Error(err))
+		return nil, err
+	}
+
+	items := make([]*models.SavedItem, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, new(models.SavedItem).ConvertSqlcSavedItem(row))
+	}
+
+	return items, nil
+}
+
+// DeleteSavedItem 從客戶的稍後購買清單移除商品，回傳 false 表示商品不存在或不屬於該客戶
+func (r *repository) DeleteSavedItem(ctx context.Context, tx pgx.Tx, customerID string, id uint64) (bool, error) {
+	rows, err := r.queries.WithTx(tx).DeleteSavedItem(ctx, sqlc.DeleteSavedItemParams{
+		ID:         int32(id),
+		CustomerID: customerID,
+	})
+	if err != nil {
+		r.logger.Error("Failed to delete saved item", zap.Uint64("saved_item_id", id), zap.Error(err))
+		return false, err
+	}
+
+	return rows > 0, nil
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"github.com/stripe/stripe-go/v79"
 	"go.uber.org/zap"
+
+	"gofalre.io/shop/models"
 )
 
 type EventProcessor interface {
@@ -43,7 +45,7 @@ func (wp *WorkerPool) worker() {
 func (wp *WorkerPool) Submit(ctx context.Context, event *stripe.Event) {
 	wp.tasks <- func() {
 		if err := wp.processor.ProcessEvent(ctx, event); err != nil {
-			wp.logger.Error("Failed to process event",
+			LoggerFromContext(ctx, wp.logger).Error("Failed to process event",
 				zap.Error(err),
 				zap.String("event_type", string(event.Type)),
 				zap.String("event_id", event.ID))
@@ -51,6 +53,20 @@ func (wp *WorkerPool) Submit(ctx context.Context, event *stripe.Event) {
 	}
 }
 
+// Stats 回傳目前的 worker 與佇列使用狀況
+func (wp *WorkerPool) Stats() *models.QueueStats {
+	stats := &models.QueueStats{
+		Workers:     cap(wp.workers),
+		BusyWorkers: len(wp.workers),
+		Queued:      len(wp.tasks),
+		Capacity:    cap(wp.tasks),
+	}
+	if stats.Capacity > 0 {
+		stats.Saturation = float64(stats.Queued) / float64(stats.Capacity)
+	}
+	return stats
+}
+
 func (wp *WorkerPool) Shutdown() {
 	close(wp.tasks)
 	for i := 0; i < cap(wp.workers); i++ {